@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/fpang/ai-social-media-helper/internal/flags"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Feature flags (DDR-132) ---
+
+type adminFlagRequest struct {
+	Name    string `json:"name"`
+	Enabled *bool  `json:"enabled"`
+}
+
+// GET  /api/admin/flags — current value of every known flag
+// POST /api/admin/flags — flip one flag
+// Body: {"name": "imagen", "enabled": false}
+//
+// Flags apply to the whole deployment, so only subs listed in ADMIN_SUBS may
+// read or change them. Other Lambdas pick up a change within flags.DefaultTTL.
+func handleAdminFlags(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleAdminFlags")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	sub := getUserSub(r)
+	if sub == "" || !adminSubs[sub] {
+		log.Warn().Str("sub", sub).Msg("Feature flag access not authorized")
+		httpError(w, http.StatusForbidden, "admin access required")
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		var req adminFlagRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httpError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		flag, ok := flags.Lookup(req.Name)
+		if !ok {
+			httpError(w, http.StatusBadRequest, "unknown flag")
+			return
+		}
+		if req.Enabled == nil {
+			httpError(w, http.StatusBadRequest, "enabled is required")
+			return
+		}
+		if err := featureFlags.Set(r.Context(), flag, *req.Enabled); err != nil {
+			log.Error().Err(err).Str("flag", req.Name).Msg("Failed to save feature flag")
+			httpError(w, http.StatusInternalServerError, "failed to save flag")
+			return
+		}
+		log.Info().Str("flag", req.Name).Bool("enabled", *req.Enabled).Str("sub", sub).Msg("Feature flag changed")
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"flags": featureFlags.All(r.Context()),
+	})
+}
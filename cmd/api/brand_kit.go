@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/fpang/ai-social-media-helper/internal/brand"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Brand kits (DDR-149) ---
+
+// GET    /api/brand-kit — the caller's brand kit (404 if none)
+// POST   /api/brand-kit — save the caller's brand kit
+// DELETE /api/brand-kit — remove it and its logo
+// Body: {"logo": "<base64 PNG>", "palette": ["#1a2b3c"], "hashtags": ["travel"], "signOff": "— Jane"}
+//
+// A POST replaces the kit, except that the stored logo is kept when the body
+// has no logo; send "removeLogo": true to drop it. Description generation
+// uses the hashtag bank and sign-off; watermarks use the logo and palette;
+// publish applies the sign-off when started with "brandKit": true.
+func handleBrandKit(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleBrandKit")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	owner, ok := signedInUser(w, r)
+	if !ok {
+		return
+	}
+
+	existing, err := sessionStore.GetBrandKit(r.Context(), owner)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read brand kit")
+		httpError(w, http.StatusInternalServerError, "failed to read brand kit")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if existing == nil {
+			httpError(w, http.StatusNotFound, "no brand kit is set")
+			return
+		}
+		respondJSON(w, http.StatusOK, existing)
+
+	case http.MethodDelete:
+		if err := sessionStore.DeleteBrandKit(r.Context(), owner); err != nil {
+			log.Error().Err(err).Msg("Failed to delete brand kit")
+			httpError(w, http.StatusInternalServerError, "failed to delete brand kit")
+			return
+		}
+		if existing != nil {
+			deleteBrandLogo(r, existing.LogoKey)
+		}
+		respondJSON(w, http.StatusOK, map[string]bool{"deleted": true})
+
+	default:
+		r.Body = http.MaxBytesReader(w, r.Body, 2*brand.MaxLogoBytes)
+		var req struct {
+			Logo       []byte   `json:"logo"`
+			RemoveLogo bool     `json:"removeLogo"`
+			Palette    []string `json:"palette"`
+			Hashtags   []string `json:"hashtags"`
+			SignOff    string   `json:"signOff"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httpError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		kit, err := brand.Normalize(store.BrandKit{Palette: req.Palette, Hashtags: req.Hashtags, SignOff: req.SignOff})
+		if err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		kit.UpdatedAt = time.Now().Unix()
+		var oldLogoKey string
+		if existing != nil {
+			kit.LogoKey = existing.LogoKey
+		}
+		if req.RemoveLogo || len(req.Logo) > 0 {
+			oldLogoKey, kit.LogoKey = kit.LogoKey, ""
+		}
+
+		if len(req.Logo) > 0 {
+			if brandAssetsBucket == "" {
+				httpError(w, http.StatusServiceUnavailable, "brand kit logos are not configured")
+				return
+			}
+			if err := (media.Overlay{Logo: req.Logo}).Validate(); err != nil {
+				httpError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			kit.LogoKey = brand.LogoKey(owner, kit.UpdatedAt)
+			if _, err := s3Client.PutObject(r.Context(), &s3.PutObjectInput{
+				Bucket:      aws.String(brandAssetsBucket),
+				Key:         aws.String(kit.LogoKey),
+				Body:        bytes.NewReader(req.Logo),
+				ContentType: aws.String("image/png"),
+				Tagging:     s3util.ProjectTagging(),
+			}); err != nil {
+				log.Error().Err(err).Str("key", kit.LogoKey).Msg("Failed to upload brand logo")
+				httpError(w, http.StatusInternalServerError, "failed to save brand kit")
+				return
+			}
+		}
+		if kit.LogoKey == "" && len(kit.Palette) == 0 && len(kit.Hashtags) == 0 && kit.SignOff == "" {
+			httpError(w, http.StatusBadRequest, "a brand kit needs a logo, palette, hashtags or sign-off")
+			return
+		}
+
+		if err := sessionStore.PutBrandKit(r.Context(), owner, kit); err != nil {
+			log.Error().Err(err).Msg("Failed to save brand kit")
+			httpError(w, http.StatusInternalServerError, "failed to save brand kit")
+			return
+		}
+		deleteBrandLogo(r, oldLogoKey)
+		log.Info().Bool("logo", kit.LogoKey != "").Int("colors", len(kit.Palette)).Int("hashtags", len(kit.Hashtags)).Msg("Brand kit saved")
+		respondJSON(w, http.StatusOK, kit)
+	}
+}
+
+// deleteBrandLogo removes a replaced or deleted logo. Failures only leave an
+// unused object behind, so they are logged and ignored.
+func deleteBrandLogo(r *http.Request, key string) {
+	if key == "" || brandAssetsBucket == "" {
+		return
+	}
+	if _, err := s3Client.DeleteObject(r.Context(), &s3.DeleteObjectInput{
+		Bucket: aws.String(brandAssetsBucket),
+		Key:    aws.String(key),
+	}); err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("Failed to delete old brand logo")
+	}
+}
+
+// callerBrandKit returns the signed-in caller's brand kit, or nil when the
+// caller is anonymous, has none, or it cannot be read. Callers treat the kit
+// as optional guidance.
+func callerBrandKit(r *http.Request) *store.BrandKit {
+	owner := getUserSub(r)
+	if owner == "" || sessionStore == nil {
+		return nil
+	}
+	kit, err := sessionStore.GetBrandKit(r.Context(), owner)
+	if err != nil {
+		log.Warn().Err(err).Msg("Brand kit not readable — continuing without it")
+		return nil
+	}
+	return kit
+}
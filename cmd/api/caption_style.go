@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/brand"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Caption style (DDR-159) ---
+
+// GET /api/settings/caption-style — the caller's caption persona (404 if none)
+// PUT /api/settings/caption-style — save the caller's caption persona
+// Body: {"emojiDensity": "light", "language": "English", "formality": "casual", "signOff": "— Jane"}
+//
+// emojiDensity is none, light or heavy; formality is casual, neutral or
+// formal. Empty fields keep the default voice. The description worker adds
+// the style to every caption prompt for the caller's sessions; the sign-off
+// applies only when the brand kit has none.
+func handleCaptionStyle(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleCaptionStyle")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPut {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	owner, ok := signedInUser(w, r)
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		style, err := sessionStore.GetCaptionStyle(r.Context(), owner)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to read caption style")
+			httpError(w, http.StatusInternalServerError, "failed to read caption style")
+			return
+		}
+		if style == nil {
+			httpError(w, http.StatusNotFound, "no caption style is set")
+			return
+		}
+		respondJSON(w, http.StatusOK, style)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 16<<10)
+	var req store.CaptionStyle
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	style, err := brand.NormalizeCaptionStyle(req)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	style.UpdatedAt = time.Now().Unix()
+	if err := sessionStore.PutCaptionStyle(r.Context(), owner, style); err != nil {
+		log.Error().Err(err).Msg("Failed to save caption style")
+		httpError(w, http.StatusInternalServerError, "failed to save caption style")
+		return
+	}
+	log.Info().Str("emojiDensity", style.EmojiDensity).Str("language", style.Language).Str("formality", style.Formality).Msg("Caption style saved")
+	respondJSON(w, http.StatusOK, style)
+}
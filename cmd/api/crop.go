@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/fpang/ai-social-media-helper/internal/jobs"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Subject-aware crop suggestions (DDR-130) ---
+
+// maxCropPhotos bounds one suggestion job to an Instagram carousel, which
+// keeps the sequential Gemini calls inside the Enhance Lambda's timeout.
+const maxCropPhotos = 20
+
+// croppedJPEGQuality is the quality of cropped copies. Publish re-encodes
+// them only if they exceed Instagram's limits (DDR-129).
+const croppedJPEGQuality = 92
+
+// POST /api/crop/start
+// Body: {"sessionId": "uuid", "keys": ["uuid/photo1.jpg", "uuid/enhanced/photo2.jpg"]}
+//
+// Dispatches a crop suggestion job to the Enhance Lambda, which proposes a
+// 1:1, 4:5 and 9:16 crop per photo centred on the subject Gemini detects.
+func handleCropStart(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleCropStart")
+
+	if r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		SessionID string   `json:"sessionId"`
+		Keys      []string `json:"keys"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validateSessionID(req.SessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !ensureSessionOwner(w, r, req.SessionID) {
+		return
+	}
+	if len(req.Keys) == 0 || len(req.Keys) > maxCropPhotos {
+		httpError(w, http.StatusBadRequest, fmt.Sprintf("request 1 to %d photos", maxCropPhotos))
+		return
+	}
+	for _, key := range req.Keys {
+		if err := validateCropKey(req.SessionID, key); err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+
+	ctx := context.Background()
+	jobID := jobs.GenerateID("crop-")
+	job := &store.CropJob{ID: jobID, Status: "pending"}
+	if err := sessionStore.PutCropJob(ctx, req.SessionID, job); err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to persist pending crop job")
+		httpError(w, http.StatusInternalServerError, "failed to create job")
+		return
+	}
+
+	payload := map[string]interface{}{
+		"type":      "crop-suggestions",
+		"sessionId": req.SessionID,
+		"jobId":     jobID,
+		"keys":      req.Keys,
+	}
+	if err := invokeAsync(ctx, enhanceLambdaArn, payload); err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Str("lambdaArn", enhanceLambdaArn).Msg("Failed to invoke enhance-lambda for crop suggestions")
+		job.Status = "error"
+		job.Error = fmt.Sprintf("failed to start processing: %v", err)
+		sessionStore.PutCropJob(ctx, req.SessionID, job)
+		httpError(w, http.StatusInternalServerError, job.Error)
+		return
+	}
+	log.Info().Str("jobId", jobID).Str("sessionId", req.SessionID).Int("photos", len(req.Keys)).Msg("Job dispatched to enhance-lambda (crop suggestions)")
+
+	respondJSON(w, http.StatusAccepted, map[string]string{"id": jobID})
+}
+
+func handleCropRoutes(w http.ResponseWriter, r *http.Request) {
+	jobID, action, ok := jobs.ParseRoute(r.URL.Path, "/api/crop/", "crop-")
+	if !ok {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	switch action {
+	case "results":
+		handleCropResults(w, r, jobID)
+	default:
+		httpError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// GET /api/crop/{id}/results?sessionId=...
+func handleCropResults(w http.ResponseWriter, r *http.Request, jobID string) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("jobId", jobID).Msg("Handler entry: handleCropResults")
+
+	if r.Method != http.MethodGet {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	sessionID := r.URL.Query().Get("sessionId")
+	if err := validateSessionID(sessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !ensureSessionOwner(w, r, sessionID) {
+		return
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+
+	job, err := sessionStore.GetCropJob(r.Context(), sessionID, jobID)
+	if err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to read crop job")
+		httpError(w, http.StatusInternalServerError, "failed to read job status")
+		return
+	}
+	if job == nil {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if job.Items == nil {
+		job.Items = []store.CropSuggestion{}
+	}
+	respondJSON(w, http.StatusOK, job)
+}
+
+// POST /api/media/crop
+// Body: {"sessionId": "uuid", "key": "uuid/photo.jpg", "jobId": "crop-...", "aspect": "4:5"}
+//
+//	or: {"sessionId": "uuid", "key": "uuid/photo.jpg", "rect": {"x": 0, "y": 0, "w": 1080, "h": 1350}}
+//
+// Crops the photo server-side — a suggested crop from a finished job, or an
+// explicit rectangle in displayed pixels — and stores the result under
+// {sessionId}/cropped/. The returned key replaces the original in the publish
+// request; the original is never modified.
+func handleMediaCrop(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleMediaCrop")
+
+	if r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		SessionID string          `json:"sessionId"`
+		Key       string          `json:"key"`
+		JobID     string          `json:"jobId"`
+		Aspect    string          `json:"aspect"`
+		Rect      *store.CropRect `json:"rect"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validateSessionID(req.SessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !ensureSessionOwner(w, r, req.SessionID) {
+		return
+	}
+	if err := validateCropKey(req.SessionID, req.Key); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var rect image.Rectangle
+	suffix := "custom"
+	switch {
+	case req.Rect != nil:
+		rect = image.Rect(req.Rect.X, req.Rect.Y, req.Rect.X+req.Rect.W, req.Rect.Y+req.Rect.H)
+		if req.Rect.W <= 0 || req.Rect.H <= 0 {
+			httpError(w, http.StatusBadRequest, "rect must have a positive width and height")
+			return
+		}
+	case req.JobID != "" && req.Aspect != "":
+		if !strings.HasPrefix(req.JobID, "crop-") {
+			httpError(w, http.StatusBadRequest, "invalid jobId")
+			return
+		}
+		if _, ok := media.LookupCropAspect(req.Aspect); !ok {
+			httpError(w, http.StatusBadRequest, fmt.Sprintf("unknown aspect %q", req.Aspect))
+			return
+		}
+		if sessionStore == nil {
+			httpError(w, http.StatusServiceUnavailable, "store not configured")
+			return
+		}
+		job, err := sessionStore.GetCropJob(r.Context(), req.SessionID, req.JobID)
+		if err != nil {
+			log.Error().Err(err).Str("jobId", req.JobID).Msg("Failed to read crop job")
+			httpError(w, http.StatusInternalServerError, "failed to read crop suggestions")
+			return
+		}
+		if job == nil {
+			httpError(w, http.StatusNotFound, "crop job not found")
+			return
+		}
+		_, c, ok := job.Crop(req.Key, req.Aspect)
+		if !ok {
+			httpError(w, http.StatusNotFound, "no suggested crop for this photo and aspect")
+			return
+		}
+		rect = image.Rect(c.X, c.Y, c.X+c.W, c.Y+c.H)
+		suffix = strings.ReplaceAll(req.Aspect, ":", "x")
+	default:
+		httpError(w, http.StatusBadRequest, "either rect or jobId and aspect are required")
+		return
+	}
+
+	ctx := r.Context()
+	tmpPath, cleanup, err := downloadFromS3(ctx, req.Key)
+	if err != nil {
+		log.Warn().Err(err).Str("key", req.Key).Msg("Failed to download photo for crop")
+		httpError(w, http.StatusNotFound, "file not found")
+		return
+	}
+	defer cleanup()
+	data, err := os.ReadFile(tmpPath)
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, "failed to read photo")
+		return
+	}
+
+	cropped, err := media.CropJPEG(data, rect, croppedJPEGQuality)
+	if err != nil {
+		log.Warn().Err(err).Str("key", req.Key).Msg("Failed to crop photo")
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	enc, err := sessionEncryption(ctx, req.SessionID)
+	if err != nil {
+		log.Error().Err(err).Str("sessionId", req.SessionID).Msg("Failed to read session encryption")
+		httpError(w, http.StatusInternalServerError, "failed to store cropped photo")
+		return
+	}
+
+	base := strings.TrimSuffix(filepath.Base(req.Key), filepath.Ext(req.Key))
+	croppedKey := fmt.Sprintf("%s/cropped/%s-%s.jpg", req.SessionID, base, suffix)
+	contentType := "image/jpeg"
+	if _, err := s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
+		Bucket:      &mediaBucket,
+		Key:         &croppedKey,
+		Body:        bytes.NewReader(cropped),
+		ContentType: &contentType,
+		Metadata:    map[string]string{"source-key": req.Key},
+		Tagging:     s3util.ProjectTagging(),
+	})); err != nil {
+		log.Error().Err(err).Str("key", croppedKey).Msg("Failed to store cropped photo")
+		httpError(w, http.StatusInternalServerError, "failed to store cropped photo")
+		return
+	}
+
+	resp := map[string]interface{}{
+		"key":    croppedKey,
+		"width":  rect.Dx(),
+		"height": rect.Dy(),
+	}
+	thumbKey := fmt.Sprintf("%s/thumbnails/cropped-%s-%s.jpg", req.SessionID, base, suffix)
+	thumbData, _, err := s3util.GenerateThumbnailFromBytes(cropped, contentType, 400)
+	if err == nil {
+		_, err = s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
+			Bucket:      &mediaBucket,
+			Key:         &thumbKey,
+			Body:        bytes.NewReader(thumbData),
+			ContentType: &contentType,
+			Tagging:     s3util.ProjectTagging(),
+		}))
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("key", thumbKey).Msg("Failed to create cropped photo thumbnail")
+	} else {
+		resp["thumbnailUrl"] = fmt.Sprintf("/api/media/thumbnail?key=%s", thumbKey)
+	}
+
+	log.Info().Str("key", req.Key).Str("croppedKey", croppedKey).Str("crop", rect.String()).Int("bytes", len(cropped)).Msg("Photo cropped")
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// validateCropKey checks that key is a photo in the session.
+func validateCropKey(sessionID, key string) error {
+	if err := validateS3Key(key); err != nil {
+		return fmt.Errorf("invalid key: %s", err.Error())
+	}
+	if !strings.HasPrefix(key, sessionID+"/") {
+		return fmt.Errorf("key does not belong to session")
+	}
+	if _, ok := media.SupportedImageExtensions[strings.ToLower(filepath.Ext(key))]; !ok {
+		return fmt.Errorf("%s is not a photo", filepath.Base(key))
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/fpang/ai-social-media-helper/internal/ai"
 	"github.com/fpang/ai-social-media-helper/internal/jobs"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 	"github.com/rs/zerolog/log"
@@ -14,7 +15,15 @@ import (
 // --- Description Endpoints (DDR-036, DDR-050: DynamoDB + async Worker Lambda) ---
 
 // POST /api/description/generate
-// Body: {"sessionId": "uuid", "keys": ["uuid/enhanced/file1.jpg", ...], "groupLabel": "...", "tripContext": "..."}
+// Body: {"sessionId": "uuid", "keys": ["uuid/enhanced/file1.jpg", ...], "groupLabel": "...", "tripContext": "...", "templateId": "tpl-...", "noCache": false, "groupId": "grp-..."}
+//
+// templateId is optional; the template's caption skeleton and hashtags guide
+// the caption (DDR-122). A signed-in caller's brand kit adds its hashtag bank
+// and sign-off (DDR-149). noCache asks Gemini for a new caption even when
+// one is cached for the same media and prompt (DDR-166). model, thinking,
+// temperature and topP override the deployment's caption settings (DDR-170).
+// groupId names a post group whose keys and name stand in for keys and
+// groupLabel when those are omitted (DDR-178).
 func handleDescriptionGenerate(w http.ResponseWriter, r *http.Request) {
 	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleDescriptionGenerate")
 
@@ -25,10 +34,14 @@ func handleDescriptionGenerate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		SessionID   string   `json:"sessionId"`
-		Keys        []string `json:"keys"`
-		GroupLabel  string   `json:"groupLabel"`
-		TripContext string   `json:"tripContext"`
+		SessionID      string   `json:"sessionId"`
+		Keys           []string `json:"keys"`
+		GroupLabel     string   `json:"groupLabel"`
+		TripContext    string   `json:"tripContext"`
+		TemplateID     string   `json:"templateId"`
+		NoCache        bool     `json:"noCache,omitempty"` // DDR-166
+		GroupID        string   `json:"groupId,omitempty"` // DDR-178
+		ai.ModelConfig          // DDR-170
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Debug().Err(err).Msg("Request body decoding failed")
@@ -45,6 +58,18 @@ func handleDescriptionGenerate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	log.Debug().Str("sessionId", req.SessionID).Msg("SessionId validation passed")
+	group, ok := requestPostGroup(w, r, req.SessionID, req.GroupID)
+	if !ok {
+		return
+	}
+	if group != nil {
+		if len(req.Keys) == 0 {
+			req.Keys = group.MediaKeys
+		}
+		if req.GroupLabel == "" {
+			req.GroupLabel = group.Name
+		}
+	}
 	if len(req.Keys) == 0 {
 		log.Warn().Str("param", "keys").Msg("Keys are required")
 		httpError(w, http.StatusBadRequest, "keys are required")
@@ -59,6 +84,29 @@ func handleDescriptionGenerate(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	log.Debug().Int("keyCount", len(req.Keys)).Msg("All keys validated successfully")
+	modelCfg, err := req.ModelConfig.Normalize()
+	if err != nil {
+		log.Warn().Err(err).Str("param", "model").Msg("Invalid model settings")
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tmpl, err := loadCaptionTemplate(r, req.TemplateID)
+	if err != nil {
+		log.Warn().Err(err).Str("templateId", req.TemplateID).Msg("Caption template not usable")
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var captionSkeleton string
+	var templateHashtags []string
+	if tmpl != nil {
+		captionSkeleton, templateHashtags = tmpl.CaptionSkeleton, tmpl.Hashtags
+	}
+	var brandHashtags []string
+	var signOff string
+	if kit := callerBrandKit(r); kit != nil {
+		brandHashtags, signOff = callerHashtags(r, kit.Hashtags), kit.SignOff // DDR-157
+	}
 
 	jobID := jobs.GenerateID("desc-")
 
@@ -70,6 +118,16 @@ func handleDescriptionGenerate(w http.ResponseWriter, r *http.Request) {
 			GroupLabel:  req.GroupLabel,
 			TripContext: req.TripContext,
 			MediaKeys:   req.Keys,
+
+			CaptionSkeleton:  captionSkeleton,
+			TemplateHashtags: templateHashtags,
+			BrandHashtags:    brandHashtags,
+			SignOff:          signOff,
+
+			Model:       modelCfg.Model,
+			Thinking:    modelCfg.Thinking,
+			Temperature: modelCfg.Temperature,
+			TopP:        modelCfg.TopP,
 		}
 		if err := sessionStore.PutDescriptionJob(context.Background(), req.SessionID, pendingJob); err != nil {
 			log.Error().Err(err).Str("jobId", jobID).Msg("Failed to persist pending description job")
@@ -77,6 +135,7 @@ func handleDescriptionGenerate(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	notePostGroupJob(r, req.SessionID, group, func(g *store.PostGroup) { g.DescriptionJobID = jobID })
 
 	// Dispatch to Description Lambda asynchronously (DDR-053).
 	payload := map[string]interface{}{
@@ -86,6 +145,21 @@ func handleDescriptionGenerate(w http.ResponseWriter, r *http.Request) {
 		"keys":        req.Keys,
 		"groupLabel":  req.GroupLabel,
 		"tripContext": req.TripContext,
+		"noCache":     req.NoCache,
+	}
+	if tmpl != nil {
+		payload["captionSkeleton"] = captionSkeleton
+		payload["templateHashtags"] = templateHashtags
+	}
+	if len(brandHashtags) > 0 || signOff != "" {
+		payload["brandHashtags"] = brandHashtags
+		payload["signOff"] = signOff
+	}
+	if modelCfg != (ai.ModelConfig{}) {
+		payload["model"] = modelCfg.Model
+		payload["thinking"] = modelCfg.Thinking
+		payload["temperature"] = modelCfg.Temperature
+		payload["topP"] = modelCfg.TopP
 	}
 	log.Info().
 		Str("jobId", jobID).
@@ -163,16 +237,37 @@ func handleDescriptionResults(w http.ResponseWriter, r *http.Request, jobID stri
 	resp := map[string]interface{}{
 		"id":            job.ID,
 		"status":        job.Status,
-		"feedbackRound": len(job.History),
+		"feedbackRound": job.Rounds(),
 	}
 	if job.Caption != "" {
 		resp["caption"] = job.Caption
 		resp["hashtags"] = job.Hashtags
 		resp["locationTag"] = job.LocationTag
 	}
+	if len(job.AltText) > 0 {
+		resp["altText"] = job.AltText // DDR-151
+	}
+	if job.Model != "" {
+		resp["model"] = job.Model // DDR-170
+	}
+	if job.Thinking != "" {
+		resp["thinking"] = job.Thinking
+	}
+	if job.Temperature != nil {
+		resp["temperature"] = *job.Temperature
+	}
+	if job.TopP != nil {
+		resp["topP"] = *job.TopP
+	}
+	if len(job.ModelsUsed) > 0 {
+		resp["modelsUsed"] = job.ModelsUsed // DDR-169
+	}
 	if job.Error != "" {
 		resp["error"] = job.Error
 	}
+	if job.Telemetry != nil {
+		resp["perJobTelemetry"] = job.Telemetry // DDR-118
+	}
 	respondJSON(w, http.StatusOK, resp)
 }
 
@@ -8,7 +8,11 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	lambdasvc "github.com/aws/aws-sdk-go-v2/service/lambda"
 	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/jobs"
+	"github.com/fpang/ai-social-media-helper/internal/store"
 )
 
 // invokeAsync sends an event to the specified Lambda function asynchronously (DDR-053).
@@ -20,15 +24,53 @@ func invokeAsync(ctx context.Context, functionArn string, event map[string]inter
 		return fmt.Errorf("lambda not configured: %s", functionArn)
 	}
 
+	if _, ok := event["priority"]; !ok {
+		eventType, _ := event["type"].(string)
+		event["priority"] = jobs.PriorityFor(eventType)
+	}
+
 	payload, err := json.Marshal(event)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to marshal event")
 		return fmt.Errorf("marshal event: %w", err)
 	}
 
+	recordDispatch(ctx, functionArn, event, payload)
+
+	if err := invokePayload(ctx, functionArn, payload); err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("type", fmt.Sprintf("%v", event["type"])).
+		Str("jobId", fmt.Sprintf("%v", event["jobId"])).
+		Str("priority", fmt.Sprintf("%v", event["priority"])).
+		Str("functionArn", functionArn).
+		Msg("Job dispatched asynchronously")
+
+	return nil
+}
+
+// invokePayload sends a pre-marshaled event to the specified Lambda function
+// asynchronously. Used directly by the retry endpoint to re-send a stored
+// dispatch payload without recording it again (DDR-089).
+//
+// When the target has an SQS job queue configured, the payload is enqueued
+// instead of invoking the Lambda directly (DDR-092). Interactive jobs use the
+// target's interactive queue when one is configured (DDR-096).
+func invokePayload(ctx context.Context, functionArn string, payload []byte) error {
+	if queueURL, ok := jobQueueFor(functionArn, jobs.PriorityOf(payload)); ok && sqsClient != nil {
+		return enqueuePayload(ctx, queueURL, payload)
+	}
+
+	if lambdaClient == nil || functionArn == "" {
+		log.Warn().Str("functionArn", functionArn).Msg("Lambda client not configured for async dispatch")
+		return fmt.Errorf("lambda not configured: %s", functionArn)
+	}
+
 	log.Debug().Int("payloadSize", len(payload)).Str("functionArn", functionArn).Msg("Invoking Lambda asynchronously")
 
-	_, err = lambdaClient.Invoke(ctx, &lambdasvc.InvokeInput{
+	_, err := lambdaClient.Invoke(ctx, &lambdasvc.InvokeInput{
 		FunctionName:   aws.String(functionArn),
 		InvocationType: lambdatypes.InvocationTypeEvent, // async — returns 202 immediately
 		Payload:        payload,
@@ -37,12 +79,61 @@ func invokeAsync(ctx context.Context, functionArn string, event map[string]inter
 		log.Error().Err(err).Str("functionArn", functionArn).Msg("Failed to invoke Lambda")
 		return fmt.Errorf("invoke lambda: %w", err)
 	}
+	return nil
+}
 
-	log.Debug().
-		Str("type", fmt.Sprintf("%v", event["type"])).
-		Str("jobId", fmt.Sprintf("%v", event["jobId"])).
-		Str("functionArn", functionArn).
-		Msg("Lambda invoked asynchronously")
+// jobQueueFor returns the SQS queue for a job of the given priority sent to
+// functionArn. Interactive jobs fall back to the batch queue when the target
+// has no interactive queue, so they are never dispatched less reliably than
+// batch jobs.
+func jobQueueFor(functionArn string, priority jobs.Priority) (string, bool) {
+	if priority == jobs.PriorityInteractive {
+		if url, ok := interactiveQueueURLs[functionArn]; ok {
+			return url, true
+		}
+	}
+	url, ok := jobQueueURLs[functionArn]
+	return url, ok
+}
+
+// enqueuePayload sends a pre-marshaled job event to a worker's SQS job queue
+// (DDR-092). The worker's event source mapping delivers it at least once, and
+// the queue's visibility timeout and redrive policy handle retries.
+func enqueuePayload(ctx context.Context, queueURL string, payload []byte) error {
+	log.Debug().Int("payloadSize", len(payload)).Str("queueUrl", queueURL).Msg("Enqueuing job")
 
+	_, err := sqsClient.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:    aws.String(queueURL),
+		MessageBody: aws.String(string(payload)),
+	})
+	if err != nil {
+		log.Error().Err(err).Str("queueUrl", queueURL).Msg("Failed to enqueue job")
+		return fmt.Errorf("send job message: %w", err)
+	}
 	return nil
 }
+
+// recordDispatch persists the event a job was dispatched with so that the retry
+// endpoint and DLQ redrive handler can re-send it (DDR-089). Best-effort — a
+// missing dispatch record only disables retry for that job.
+func recordDispatch(ctx context.Context, functionArn string, event map[string]interface{}, payload []byte) {
+	if sessionStore == nil {
+		return
+	}
+	sessionID, _ := event["sessionId"].(string)
+	jobID, _ := event["jobId"].(string)
+	eventType, _ := event["type"].(string)
+	if sessionID == "" || jobID == "" {
+		return
+	}
+
+	rec := &store.DispatchRecord{
+		JobID:     jobID,
+		Target:    functionArn,
+		EventType: eventType,
+		Payload:   string(payload),
+	}
+	if err := sessionStore.PutDispatchRecord(ctx, sessionID, rec); err != nil {
+		log.Warn().Err(err).Str("jobId", jobID).Msg("Failed to persist dispatch record — retry disabled for this job")
+	}
+}
@@ -3,11 +3,16 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/fpang/ai-social-media-helper/internal/jobs"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 	"github.com/rs/zerolog/log"
 )
@@ -15,7 +20,11 @@ import (
 // --- Download Endpoints (DDR-034, DDR-050: DynamoDB + async Worker Lambda) ---
 
 // POST /api/download/start
-// Body: {"sessionId": "uuid", "keys": ["uuid/enhanced/file1.jpg", ...], "groupLabel": "Tokyo Day 1"}
+// Body: {"sessionId": "uuid", "keys": ["uuid/enhanced/file1.jpg", ...], "groupLabel": "Tokyo Day 1", "prewarm": false}
+//
+// DDR-101: if the session already has a live job for the same keys and label
+// (typically one prewarmed when the post groups were confirmed), its ID is
+// returned with "reused": true instead of zipping the files again.
 func handleDownloadStart(w http.ResponseWriter, r *http.Request) {
 	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleDownloadStart")
 
@@ -29,6 +38,7 @@ func handleDownloadStart(w http.ResponseWriter, r *http.Request) {
 		SessionID  string   `json:"sessionId"`
 		Keys       []string `json:"keys"`
 		GroupLabel string   `json:"groupLabel"`
+		Prewarm    bool     `json:"prewarm"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Debug().Err(err).Msg("Request body decoding failed")
@@ -73,49 +83,106 @@ func handleDownloadStart(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Debug().Int("keyCount", len(req.Keys)).Msg("All keys validated successfully")
 
+	ctx := context.Background()
+	req.Keys = resolveOriginalKeys(ctx, req.SessionID, req.Keys) // DDR-131
+	// DDR-135: the metadata policy is part of the fingerprint, so changing it
+	// does not reuse a bundle built under the old one.
+	policy := sessionMetadataPolicy(ctx, req.SessionID)
+	fingerprint := store.DownloadFingerprint(req.Keys, req.GroupLabel, string(policy))
+	if sessionStore != nil {
+		existing, err := sessionStore.FindDownloadJob(ctx, req.SessionID, fingerprint)
+		if err != nil {
+			log.Warn().Err(err).Str("sessionId", req.SessionID).Msg("Failed to look up existing download job, starting a new one")
+		} else if existing != nil {
+			log.Info().Str("jobId", existing.ID).Str("status", existing.Status).Bool("prewarmed", existing.Prewarm).Bool("prewarm", req.Prewarm).Msg("Reusing download job with matching fingerprint")
+			if existing.Prewarm && !req.Prewarm {
+				metrics.New("AiSocialMedia").
+					Dimension("JobType", "download").
+					Count("DownloadPrewarmHit").
+					Property("status", existing.Status).
+					Flush()
+			}
+			respondJSON(w, http.StatusAccepted, map[string]interface{}{
+				"id":     existing.ID,
+				"reused": true,
+			})
+			return
+		}
+	}
+
 	jobID := jobs.GenerateID("dl-")
+	pending := &store.DownloadJob{
+		ID:          jobID,
+		GroupLabel:  req.GroupLabel,
+		Fingerprint: fingerprint,
+		Prewarm:     req.Prewarm,
+
+		MetadataPolicy: string(policy),
+	}
+	if err := dispatchDownloadJob(ctx, req.SessionID, pending, req.Keys); err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, map[string]string{
+		"id": jobID,
+	})
+}
 
+// dispatchDownloadJob records job as pending and dispatches it to the Download
+// Lambda (DDR-050, DDR-053). The dispatch-time fields of job (group label,
+// retryOf from DDR-097, fingerprint and prewarm from DDR-101, metadata policy
+// from DDR-135) travel in the payload so the worker preserves them. The
+// returned error is user-facing.
+func dispatchDownloadJob(ctx context.Context, sessionID string, job *store.DownloadJob, keys []string) error {
 	// Write pending job to DynamoDB (DDR-050).
+	job.Status = "pending"
 	if sessionStore != nil {
-		pendingJob := &store.DownloadJob{
-			ID:     jobID,
-			Status: "pending",
-		}
-		if err := sessionStore.PutDownloadJob(context.Background(), req.SessionID, pendingJob); err != nil {
-			log.Error().Err(err).Str("jobId", jobID).Msg("Failed to persist pending download job")
-			httpError(w, http.StatusInternalServerError, "failed to create job")
-			return
+		if err := sessionStore.PutDownloadJob(ctx, sessionID, job); err != nil {
+			log.Error().Err(err).Str("jobId", job.ID).Msg("Failed to persist pending download job")
+			return errors.New("failed to create job")
 		}
 	}
 
 	// Dispatch to Download Lambda asynchronously (DDR-053).
 	payload := map[string]interface{}{
 		"type":       "download",
-		"sessionId":  req.SessionID,
-		"jobId":      jobID,
-		"keys":       req.Keys,
-		"groupLabel": req.GroupLabel,
+		"sessionId":  sessionID,
+		"jobId":      job.ID,
+		"keys":       keys,
+		"groupLabel": job.GroupLabel,
+	}
+	if job.RetryOf != "" {
+		payload["retryOf"] = job.RetryOf
+	}
+	if job.Fingerprint != "" {
+		payload["fingerprint"] = job.Fingerprint
+	}
+	if job.Prewarm {
+		payload["prewarm"] = true
+	}
+	if job.MetadataPolicy != "" {
+		payload["metadataPolicy"] = job.MetadataPolicy
 	}
 	log.Info().
-		Str("jobId", jobID).
-		Str("sessionId", req.SessionID).
-		Int("keyCount", len(req.Keys)).
-		Str("groupLabel", req.GroupLabel).
+		Str("jobId", job.ID).
+		Str("sessionId", sessionID).
+		Int("keyCount", len(keys)).
+		Str("groupLabel", job.GroupLabel).
+		Str("retryOf", job.RetryOf).
+		Bool("prewarm", job.Prewarm).
 		Msg("Job dispatched to download-lambda")
-	if err := invokeAsync(context.Background(), downloadLambdaArn, payload); err != nil {
-		log.Error().Err(err).Str("jobId", jobID).Str("lambdaArn", downloadLambdaArn).Msg("Failed to invoke download-lambda")
+	if err := invokeAsync(ctx, downloadLambdaArn, payload); err != nil {
+		log.Error().Err(err).Str("jobId", job.ID).Str("lambdaArn", downloadLambdaArn).Msg("Failed to invoke download-lambda")
 		errDetail := fmt.Sprintf("failed to start processing: %v", err)
 		if sessionStore != nil {
-			errJob := &store.DownloadJob{ID: jobID, Status: "error", Error: errDetail}
-			sessionStore.PutDownloadJob(context.Background(), req.SessionID, errJob)
+			job.Status = "error"
+			job.Error = errDetail
+			sessionStore.PutDownloadJob(ctx, sessionID, job)
 		}
-		httpError(w, http.StatusInternalServerError, errDetail)
-		return
+		return errors.New(errDetail)
 	}
-
-	respondJSON(w, http.StatusAccepted, map[string]string{
-		"id": jobID,
-	})
+	return nil
 }
 
 func handleDownloadRoutes(w http.ResponseWriter, r *http.Request) {
@@ -128,6 +195,8 @@ func handleDownloadRoutes(w http.ResponseWriter, r *http.Request) {
 	switch action {
 	case "results":
 		handleDownloadResults(w, r, jobID)
+	case "retry-omitted":
+		handleDownloadRetryOmitted(w, r, jobID)
 	default:
 		httpError(w, http.StatusNotFound, "not found")
 	}
@@ -168,6 +237,8 @@ func handleDownloadResults(w http.ResponseWriter, r *http.Request, jobID string)
 	}
 	log.Debug().Str("jobId", jobID).Str("status", job.Status).Msg("Download job found in DynamoDB")
 
+	refreshDownloadJob(r.Context(), sessionID, job)
+
 	resp := map[string]interface{}{
 		"id":      job.ID,
 		"status":  job.Status,
@@ -176,5 +247,162 @@ func handleDownloadResults(w http.ResponseWriter, r *http.Request, jobID string)
 	if job.Error != "" {
 		resp["error"] = job.Error
 	}
+	if job.RetryOf != "" {
+		resp["retryOf"] = job.RetryOf
+	}
+	if job.Prewarm {
+		resp["prewarm"] = true
+	}
+	if len(job.RestoringKeys) > 0 {
+		resp["restoringKeys"] = job.RestoringKeys
+	}
+	if job.Telemetry != nil {
+		resp["perJobTelemetry"] = job.Telemetry // DDR-118
+	}
 	respondJSON(w, http.StatusOK, resp)
 }
+
+// refreshDownloadJob does the work a download poll drives: it resumes a job
+// whose archived files are restored (DDR-115) and re-signs bundle URLs so a
+// client resuming a download after the original URL expired gets a fresh one
+// for the same object (DDR-099). job is updated in place.
+func refreshDownloadJob(ctx context.Context, sessionID string, job *store.DownloadJob) {
+	if job.Status == "restoring" {
+		resumeRestoredDownload(ctx, sessionID, job)
+	}
+	if presigner == nil {
+		return
+	}
+	for i, b := range job.Bundles {
+		if b.Status != "complete" || b.ZipKey == "" {
+			continue
+		}
+		url, err := s3util.PresignBundleURL(ctx, presigner, mediaBucket, b.ZipKey, b.Name)
+		if err != nil {
+			log.Warn().Err(err).Str("zipKey", b.ZipKey).Msg("Failed to re-sign bundle URL — returning stored URL")
+			continue
+		}
+		job.Bundles[i].DownloadURL = url
+	}
+}
+
+// restoreCheckInterval is how often a restoring download job's archived files
+// are checked again, however often the client polls (DDR-115).
+const restoreCheckInterval = time.Minute
+
+// resumeRestoredDownload checks whether the archived files of a job parked by
+// the Download Lambda are readable yet, and dispatches the job again once they
+// all are (DDR-115). job is updated in place for the response.
+func resumeRestoredDownload(ctx context.Context, sessionID string, job *store.DownloadJob) {
+	if time.Since(time.Unix(job.RestoreCheckedAt, 0)) < restoreCheckInterval {
+		return
+	}
+
+	var pending []string
+	for _, key := range job.RestoringKeys {
+		head, err := s3Client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: &mediaBucket, Key: &key})
+		if err != nil {
+			// The worker reports unreadable files as omitted (DDR-097).
+			log.Warn().Err(err).Str("key", key).Msg("HeadObject failed for restoring file")
+			continue
+		}
+		if s3util.NeedsRestore(head) {
+			pending = append(pending, key)
+		}
+	}
+
+	if len(pending) > 0 {
+		job.RestoringKeys = pending
+		job.RestoreCheckedAt = time.Now().Unix()
+		if err := sessionStore.PutDownloadJob(ctx, sessionID, job); err != nil {
+			log.Warn().Err(err).Str("jobId", job.ID).Msg("Failed to record restore check")
+		}
+		log.Debug().Str("jobId", job.ID).Int("restoring", len(pending)).Msg("Archived files still restoring")
+		return
+	}
+
+	resumed := &store.DownloadJob{
+		ID:          job.ID,
+		GroupLabel:  job.GroupLabel,
+		RetryOf:     job.RetryOf,
+		Fingerprint: job.Fingerprint,
+		Prewarm:     job.Prewarm,
+
+		MetadataPolicy: job.MetadataPolicy,
+	}
+	if err := dispatchDownloadJob(ctx, sessionID, resumed, job.Keys); err != nil {
+		log.Error().Err(err).Str("jobId", job.ID).Msg("Failed to dispatch restored download job")
+		return
+	}
+	log.Info().Str("jobId", job.ID).Int("keyCount", len(job.Keys)).Msg("Archived files restored, download job dispatched again")
+	*job = *resumed
+}
+
+// POST /api/download/{id}/retry-omitted
+// Body: {"sessionId": "uuid"}
+//
+// Starts a new download job for the files the given job had to leave out of
+// its ZIPs (DDR-097). The original job and its bundles are left unchanged.
+func handleDownloadRetryOmitted(w http.ResponseWriter, r *http.Request, jobID string) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("jobId", jobID).Msg("Handler entry: handleDownloadRetryOmitted")
+
+	if r.Method != http.MethodPost {
+		log.Warn().Str("param", "method").Msg("Method not allowed")
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		SessionID string `json:"sessionId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn().Str("param", "body").Msg("Invalid request body")
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validateSessionID(req.SessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !ensureSessionOwner(w, r, req.SessionID) {
+		return
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+
+	ctx := context.Background()
+	job, err := sessionStore.GetDownloadJob(ctx, req.SessionID, jobID)
+	if err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to read download job from DynamoDB")
+		httpError(w, http.StatusInternalServerError, "failed to read job status")
+		return
+	}
+	if job == nil {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if job.Status != "complete" {
+		httpError(w, http.StatusConflict, fmt.Sprintf("job is %s, only complete jobs can retry omitted files", job.Status))
+		return
+	}
+	keys := job.OmittedKeys()
+	if len(keys) == 0 {
+		httpError(w, http.StatusConflict, "job has no omitted files")
+		return
+	}
+
+	retryJobID := jobs.GenerateID("dl-")
+	retryJob := &store.DownloadJob{ID: retryJobID, GroupLabel: job.GroupLabel, RetryOf: jobID, MetadataPolicy: job.MetadataPolicy}
+	if err := dispatchDownloadJob(ctx, req.SessionID, retryJob, keys); err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, map[string]interface{}{
+		"id":       retryJobID,
+		"retryOf":  jobID,
+		"keyCount": len(keys),
+	})
+}
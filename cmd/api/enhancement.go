@@ -3,13 +3,16 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sfn"
+	"github.com/fpang/ai-social-media-helper/internal/ai"
 	"github.com/fpang/ai-social-media-helper/internal/media"
 	"github.com/fpang/ai-social-media-helper/internal/jobs"
 	"github.com/fpang/ai-social-media-helper/internal/store"
@@ -18,8 +21,31 @@ import (
 
 // --- Enhancement Endpoints (DDR-031, DDR-050) ---
 
+// enhanceItemOptions is one item's settings in POST /api/enhance/start
+// (DDR-143). Skipped items are recorded in the job but not sent to the
+// pipeline. Items with a higher Priority render first; ties keep request
+// order. Preset is an ai.EnhancementPreset and applies to photos only.
+type enhanceItemOptions struct {
+	Key      string `json:"key"`
+	Skip     bool   `json:"skip,omitempty"`
+	Preset   string `json:"preset,omitempty"`
+	Priority int    `json:"priority,omitempty"`
+}
+
 // POST /api/enhance/start
-// Body: {"sessionId": "uuid", "keys": ["uuid/file1.jpg", ...]}
+// Body: {"sessionId": "uuid", "keys": ["uuid/file1.jpg", ...], "debugArtifacts": false, "watermark": false}
+//
+//	or: {"sessionId": "uuid", "items": [{"key": "uuid/file1.jpg", "preset": "light", "priority": 1}, {"key": "uuid/file2.jpg", "skip": true}]}
+//
+// debugArtifacts keeps model responses and Imagen masks under
+// {sessionId}/debug/{jobId}/ for bug reports (DDR-106). watermark stamps the
+// caller's watermark on each enhanced photo (DDR-133). items replaces keys
+// when per-item settings are needed (DDR-143). model, thinking, temperature
+// and topP apply to the photo analysis calls (DDR-170). subjectPreset tunes
+// the pipeline for the kind of photos — "portrait", "landscape", "food" or
+// "night" — and applies to every photo of the job (DDR-180). upscaleBelow
+// upscales the result of each photo whose long edge is under that many
+// pixels with Imagen (DDR-184); 0 leaves sizes alone.
 func handleEnhanceStart(w http.ResponseWriter, r *http.Request) {
 	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleEnhanceStart")
 
@@ -30,8 +56,14 @@ func handleEnhanceStart(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		SessionID string   `json:"sessionId"`
-		Keys      []string `json:"keys"`
+		SessionID      string               `json:"sessionId"`
+		Keys           []string             `json:"keys"`
+		Items          []enhanceItemOptions `json:"items,omitempty"` // DDR-143
+		DebugArtifacts bool                 `json:"debugArtifacts,omitempty"`
+		Watermark      bool                 `json:"watermark,omitempty"`
+		SubjectPreset  string               `json:"subjectPreset,omitempty"` // DDR-180
+		UpscaleBelow   int                  `json:"upscaleBelow,omitempty"`  // DDR-184
+		ai.ModelConfig                      // DDR-170
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Debug().Err(err).Msg("Request body decoding failed")
@@ -53,14 +85,38 @@ func handleEnhanceStart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	log.Debug().Str("sessionId", req.SessionID).Msg("SessionId validation passed")
-	if len(req.Keys) == 0 {
+	modelCfg, err := req.ModelConfig.Normalize()
+	if err != nil {
+		log.Warn().Err(err).Str("param", "model").Msg("Invalid model settings")
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	subject, err := ai.ParseSubjectPreset(req.SubjectPreset)
+	if err != nil {
+		log.Warn().Err(err).Str("param", "subjectPreset").Msg("Invalid subject preset")
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := ai.ValidateUpscaleBelow(req.UpscaleBelow); err != nil {
+		log.Warn().Err(err).Str("param", "upscaleBelow").Msg("Invalid upscale threshold")
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	items, err := resolveEnhanceItems(req.Keys, req.Items)
+	if err != nil {
+		log.Warn().Err(err).Str("param", "items").Msg("Invalid enhancement items")
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(items) == 0 {
 		log.Warn().Str("param", "keys").Msg("At least one key is required")
 		httpError(w, http.StatusBadRequest, "at least one key is required")
 		return
 	}
 
 	// Validate all keys belong to the session
-	for _, key := range req.Keys {
+	for _, item := range items {
+		key := item.Key
 		if err := validateS3Key(key); err != nil {
 			log.Debug().Err(err).Str("key", key).Msg("S3 key validation failed")
 			log.Warn().Str("param", "keys").Str("key", key).Msg("Invalid S3 key")
@@ -74,49 +130,79 @@ func handleEnhanceStart(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
-	log.Debug().Int("keyCount", len(req.Keys)).Msg("All keys validated successfully")
+	log.Debug().Int("keyCount", len(items)).Msg("All keys validated successfully")
 
-	// Separate photos and videos for the enhancement pipeline.
-	// Use initialized slices (not nil) so JSON marshal produces [] not null,
-	// which Step Functions Map states require for ItemsPath.
-	photoKeys := make([]string, 0)
-	videoKeys := make([]string, 0)
-	for _, key := range req.Keys {
-		ext := strings.ToLower(filepath.Ext(key))
-		if media.IsImage(ext) {
-			photoKeys = append(photoKeys, key)
-		} else if media.IsVideo(ext) {
-			videoKeys = append(videoKeys, key)
+	// Separate photos and videos for the enhancement pipeline, in render order.
+	// Skipped items are only recorded in the job (DDR-143).
+	var runKeys, skippedKeys []string
+	presets := make(map[string]string)
+	for _, item := range items {
+		if item.Skip {
+			skippedKeys = append(skippedKeys, item.Key)
+			continue
 		}
+		runKeys = append(runKeys, item.Key)
+		presets[item.Key] = item.Preset
 	}
-	log.Debug().Int("photoCount", len(photoKeys)).Int("videoCount", len(videoKeys)).Msg("Media separated into photos and videos")
+	photoKeys, videoKeys := splitEnhancementKeys(runKeys)
+	skippedPhotos, skippedVideos := splitEnhancementKeys(skippedKeys)
+	skippedKeys = append(skippedPhotos, skippedVideos...)
+	log.Debug().Int("photoCount", len(photoKeys)).Int("videoCount", len(videoKeys)).Int("skippedCount", len(skippedKeys)).Msg("Media separated into photos and videos")
 
 	if len(photoKeys) == 0 && len(videoKeys) == 0 {
+		if len(skippedKeys) > 0 {
+			log.Warn().Str("param", "items").Msg("Every item is skipped")
+			httpError(w, http.StatusBadRequest, "every item is skipped")
+			return
+		}
 		log.Warn().Str("param", "keys").Msg("No media files in the provided keys")
 		httpError(w, http.StatusBadRequest, "no media files in the provided keys")
 		return
 	}
 
+	if req.Watermark {
+		if err := requireWatermark(r.Context(), r); err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
 	jobID := jobs.GenerateID("enh-")
 
 	// Write pending job to DynamoDB (DDR-050).
 	if sessionStore != nil {
 		// Pre-populate Items so the enhance-lambda can update by index.
-		allKeys := append(photoKeys, videoKeys...)
-		items := make([]store.EnhancementItem, len(allKeys))
+		// Skipped items are complete from the start (DDR-143).
+		allKeys := append(append(photoKeys, videoKeys...), skippedKeys...)
+		jobItems := make([]store.EnhancementItem, len(allKeys))
 		for i, k := range allKeys {
-			items[i] = store.EnhancementItem{
+			jobItems[i] = store.EnhancementItem{
 				Key:         k,
 				OriginalKey: k,
 				Filename:    filepath.Base(k),
 				Phase:       "pending",
+				Preset:      presets[k],
+			}
+			if i >= len(photoKeys)+len(videoKeys) {
+				jobItems[i].Phase = ai.PhaseSkipped
+				jobItems[i].OriginalThumbKey = fmt.Sprintf("%s/thumbnails/%s.jpg", req.SessionID,
+					strings.TrimSuffix(filepath.Base(k), filepath.Ext(k)))
 			}
 		}
 		pendingJob := &store.EnhancementJob{
-			ID:         jobID,
-			Status:     "pending",
-			TotalCount: len(photoKeys) + len(videoKeys),
-			Items:      items,
+			ID:             jobID,
+			Status:         "pending",
+			TotalCount:     len(allKeys),
+			CompletedCount: len(skippedKeys),
+			Items:          jobItems,
+			DebugArtifacts: req.DebugArtifacts,
+			Watermark:      req.Watermark,
+			Model:          modelCfg.Model,
+			Thinking:       modelCfg.Thinking,
+			Temperature:    modelCfg.Temperature,
+			TopP:           modelCfg.TopP,
+			SubjectPreset:  string(subject),
+			UpscaleBelow:   req.UpscaleBelow,
 		}
 		if err := sessionStore.PutEnhancementJob(context.Background(), req.SessionID, pendingJob); err != nil {
 			log.Error().Err(err).Str("jobId", jobID).Msg("Failed to persist pending enhancement job")
@@ -136,25 +222,7 @@ func handleEnhanceStart(w http.ResponseWriter, r *http.Request) {
 		httpError(w, http.StatusServiceUnavailable, errDetail)
 		return
 	}
-	sfnInput, _ := json.Marshal(map[string]interface{}{
-		"sessionId": req.SessionID,
-		"jobId":     jobID,
-		"photos":    photoKeys,
-		"videos":    videoKeys,
-	})
-	log.Info().
-		Str("jobId", jobID).
-		Str("sessionId", req.SessionID).
-		Int("photos", len(photoKeys)).
-		Int("videos", len(videoKeys)).
-		Str("sfnArn", enhancementSfnArn).
-		Msg("Job dispatched")
-	_, err := sfnClient.StartExecution(context.Background(), &sfn.StartExecutionInput{
-		StateMachineArn: aws.String(enhancementSfnArn),
-		Input:           aws.String(string(sfnInput)),
-		Name:            aws.String(jobID),
-	})
-	if err != nil {
+	if err := startEnhancementExecution(context.Background(), req.SessionID, jobID, jobID, photoKeys, videoKeys, req.DebugArtifacts, subject, req.UpscaleBelow, modelCfg); err != nil {
 		log.Error().Err(err).Str("jobId", jobID).Str("sfnArn", enhancementSfnArn).Msg("Failed to start enhancement pipeline")
 		errDetail := fmt.Sprintf("failed to start processing: %v", err)
 		if sessionStore != nil {
@@ -182,11 +250,111 @@ func handleEnhanceRoutes(w http.ResponseWriter, r *http.Request) {
 		handleEnhanceResults(w, r, jobID)
 	case "feedback":
 		handleEnhanceFeedback(w, r, jobID)
+	case "feedback-batch":
+		handleEnhanceFeedbackBatch(w, r, jobID) // DDR-182
+	case "pause":
+		handleEnhancePause(w, r, jobID)
+	case "resume":
+		handleEnhanceResume(w, r, jobID)
+	case "versions":
+		handleEnhanceVersions(w, r, jobID) // DDR-181
+	case "rollback":
+		handleEnhanceRollback(w, r, jobID)
 	default:
 		httpError(w, http.StatusNotFound, "not found")
 	}
 }
 
+// resolveEnhanceItems returns the items of an enhance start request in render
+// order (DDR-143). A request sends either keys, which render with default
+// settings in the given order, or items. Presets are validated and only
+// allowed on photos; a key may appear once.
+func resolveEnhanceItems(keys []string, items []enhanceItemOptions) ([]enhanceItemOptions, error) {
+	if len(items) == 0 {
+		items = make([]enhanceItemOptions, len(keys))
+		for i, k := range keys {
+			items[i] = enhanceItemOptions{Key: k}
+		}
+		return items, nil
+	}
+	if len(keys) > 0 {
+		return nil, errors.New("send keys or items, not both")
+	}
+
+	seen := make(map[string]bool, len(items))
+	out := make([]enhanceItemOptions, len(items))
+	for i, item := range items {
+		if seen[item.Key] {
+			return nil, fmt.Errorf("key %s appears twice", item.Key)
+		}
+		seen[item.Key] = true
+		if item.Preset != "" {
+			if _, err := ai.ParseEnhancementPreset(item.Preset); err != nil {
+				return nil, err
+			}
+			if media.IsVideo(strings.ToLower(filepath.Ext(item.Key))) {
+				return nil, fmt.Errorf("preset is not supported for video %s", item.Key)
+			}
+		}
+		out[i] = item
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Priority > out[j].Priority })
+	return out, nil
+}
+
+// splitEnhancementKeys separates photo and video keys, skipping anything else.
+// The slices are initialized (not nil) so JSON marshal produces [] not null,
+// which Step Functions Map states require for ItemsPath.
+func splitEnhancementKeys(keys []string) (photos, videos []string) {
+	photos = make([]string, 0)
+	videos = make([]string, 0)
+	for _, key := range keys {
+		ext := strings.ToLower(filepath.Ext(key))
+		if media.IsImage(ext) {
+			photos = append(photos, key)
+		} else if media.IsVideo(ext) {
+			videos = append(videos, key)
+		}
+	}
+	return photos, videos
+}
+
+// startEnhancementExecution starts an EnhancementPipeline execution over the
+// given keys. execName must be unique per state machine; resumed runs use
+// "{jobId}-resume-{n}" (DDR-095). The model settings are always present,
+// null or empty for the defaults, because the state machine passes them on
+// to each photo (DDR-170). The subject preset and upscale threshold are
+// passed on the same way (DDR-180, DDR-184).
+func startEnhancementExecution(ctx context.Context, sessionID, jobID, execName string, photos, videos []string, debugArtifacts bool, subject ai.SubjectPreset, upscaleBelow int, modelCfg ai.ModelConfig) error {
+	sfnInput, _ := json.Marshal(map[string]interface{}{
+		"sessionId":      sessionID,
+		"jobId":          jobID,
+		"photos":         photos,
+		"videos":         videos,
+		"debugArtifacts": debugArtifacts,
+		"subjectPreset":  subject,
+		"upscaleBelow":   upscaleBelow,
+		"model":          modelCfg.Model,
+		"thinking":       modelCfg.Thinking,
+		"temperature":    modelCfg.Temperature,
+		"topP":           modelCfg.TopP,
+	})
+	log.Info().
+		Str("jobId", jobID).
+		Str("sessionId", sessionID).
+		Str("execution", execName).
+		Int("photos", len(photos)).
+		Int("videos", len(videos)).
+		Str("sfnArn", enhancementSfnArn).
+		Msg("Job dispatched")
+	_, err := sfnClient.StartExecution(ctx, &sfn.StartExecutionInput{
+		StateMachineArn: aws.String(enhancementSfnArn),
+		Input:           aws.String(string(sfnInput)),
+		Name:            aws.String(execName),
+	})
+	return err
+}
+
 // GET /api/enhance/{id}/results?sessionId=...
 func handleEnhanceResults(w http.ResponseWriter, r *http.Request, jobID string) {
 	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("jobId", jobID).Msg("Handler entry: handleEnhanceResults")
@@ -222,8 +390,49 @@ func handleEnhanceResults(w http.ResponseWriter, r *http.Request, jobID string)
 	}
 	log.Debug().Str("jobId", jobID).Str("status", job.Status).Msg("Enhancement job found in DynamoDB")
 
-	// Self-healing reconciliation: count items where Phase != "pending" and
-	// compare with CompletedCount. Fixes any counter drift from past races.
+	reconcileEnhancementJob(r.Context(), sessionID, job)
+
+	resp := map[string]interface{}{
+		"id":             job.ID,
+		"status":         job.Status,
+		"items":          job.Items,
+		"totalCount":     job.TotalCount,
+		"completedCount": job.CompletedCount,
+	}
+	if job.Error != "" {
+		resp["error"] = job.Error
+	}
+	if job.PausedAt != 0 {
+		resp["pausedAt"] = job.PausedAt
+	}
+	if job.Model != "" {
+		resp["model"] = job.Model // DDR-170
+	}
+	if job.Thinking != "" {
+		resp["thinking"] = job.Thinking
+	}
+	if job.Temperature != nil {
+		resp["temperature"] = *job.Temperature
+	}
+	if job.TopP != nil {
+		resp["topP"] = *job.TopP
+	}
+	if job.SubjectPreset != "" {
+		resp["subjectPreset"] = job.SubjectPreset // DDR-180
+	}
+	if job.UpscaleBelow != 0 {
+		resp["upscaleBelow"] = job.UpscaleBelow // DDR-184
+	}
+	if job.Telemetry != nil {
+		resp["perJobTelemetry"] = job.Telemetry // DDR-118
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// reconcileEnhancementJob is self-healing reconciliation: it counts items
+// where Phase != "pending" and compares with CompletedCount, fixing any
+// counter drift from past races. job is updated in place.
+func reconcileEnhancementJob(ctx context.Context, sessionID string, job *store.EnhancementJob) {
 	trueCompleted := 0
 	for _, item := range job.Items {
 		if item.Phase != "" && item.Phase != "pending" {
@@ -232,33 +441,21 @@ func handleEnhanceResults(w http.ResponseWriter, r *http.Request, jobID string)
 	}
 	if trueCompleted != job.CompletedCount {
 		log.Warn().
-			Str("jobId", jobID).Str("sessionId", sessionID).
+			Str("jobId", job.ID).Str("sessionId", sessionID).
 			Int("storedCount", job.CompletedCount).Int("trueCount", trueCompleted).
 			Msg("Enhancement completedCount mismatch — reconciling")
 		job.CompletedCount = trueCompleted
 	}
 	if trueCompleted >= job.TotalCount && job.Status != "complete" {
 		log.Warn().
-			Str("jobId", jobID).Str("sessionId", sessionID).
+			Str("jobId", job.ID).Str("sessionId", sessionID).
 			Int("trueCompleted", trueCompleted).Int("totalCount", job.TotalCount).
 			Msg("All items done but status not complete — reconciling")
 		job.Status = "complete"
-		if err := sessionStore.UpdateEnhancementStatus(context.Background(), sessionID, jobID, "complete"); err != nil {
+		if err := sessionStore.UpdateEnhancementStatus(ctx, sessionID, job.ID, "complete"); err != nil {
 			log.Warn().Err(err).Msg("Failed to reconcile enhancement status")
 		}
 	}
-
-	resp := map[string]interface{}{
-		"id":             job.ID,
-		"status":         job.Status,
-		"items":          job.Items,
-		"totalCount":     job.TotalCount,
-		"completedCount": job.CompletedCount,
-	}
-	if job.Error != "" {
-		resp["error"] = job.Error
-	}
-	respondJSON(w, http.StatusOK, resp)
 }
 
 // POST /api/enhance/{id}/feedback
@@ -314,3 +511,131 @@ func handleEnhanceFeedback(w http.ResponseWriter, r *http.Request, jobID string)
 		"status": "processing",
 	})
 }
+
+// --- Pause / resume (DDR-095) ---
+
+// POST /api/enhance/{id}/pause
+// Body: {"sessionId": "uuid"}
+//
+// Marks the job paused. Items already being enhanced finish; the rest are
+// skipped by the workers and stay pending until the job is resumed.
+func handleEnhancePause(w http.ResponseWriter, r *http.Request, jobID string) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("jobId", jobID).Msg("Handler entry: handleEnhancePause")
+
+	sessionID, ok := decodeEnhanceControlRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := sessionStore.PauseEnhancementJob(context.Background(), sessionID, jobID)
+	if errors.Is(err, store.ErrStatusConflict) {
+		httpError(w, http.StatusConflict, "only pending or processing jobs can be paused")
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to pause enhancement job")
+		httpError(w, http.StatusInternalServerError, "failed to pause job")
+		return
+	}
+
+	log.Info().Str("jobId", jobID).Str("sessionId", sessionID).Msg("Enhancement job paused")
+	respondJSON(w, http.StatusOK, map[string]string{
+		"id":     jobID,
+		"status": store.EnhancementStatusPaused,
+	})
+}
+
+// POST /api/enhance/{id}/resume
+// Body: {"sessionId": "uuid"}
+//
+// Starts a new pipeline execution over the items that have no result yet.
+// Workers resolve each item's index by key, so the partial key list is safe.
+func handleEnhanceResume(w http.ResponseWriter, r *http.Request, jobID string) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("jobId", jobID).Msg("Handler entry: handleEnhanceResume")
+
+	sessionID, ok := decodeEnhanceControlRequest(w, r)
+	if !ok {
+		return
+	}
+	if sfnClient == nil || enhancementSfnArn == "" {
+		httpError(w, http.StatusServiceUnavailable, "enhancement processing is not available (pipeline not configured)")
+		return
+	}
+
+	ctx := context.Background()
+	job, err := sessionStore.GetEnhancementJob(ctx, sessionID, jobID)
+	if err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to read enhancement job for resume")
+		httpError(w, http.StatusInternalServerError, "failed to read job")
+		return
+	}
+	if job == nil {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if job.Status != store.EnhancementStatusPaused {
+		httpError(w, http.StatusConflict, fmt.Sprintf("job is %s, only paused jobs can be resumed", job.Status))
+		return
+	}
+
+	resumeCount, err := sessionStore.ResumeEnhancementJob(ctx, sessionID, jobID)
+	if errors.Is(err, store.ErrStatusConflict) {
+		httpError(w, http.StatusConflict, "only paused jobs can be resumed")
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to resume enhancement job")
+		httpError(w, http.StatusInternalServerError, "failed to resume job")
+		return
+	}
+
+	photos, videos := splitEnhancementKeys(job.PendingKeys())
+	execName := fmt.Sprintf("%s-resume-%d", jobID, resumeCount)
+	modelCfg := ai.ModelConfig{Model: job.Model, Thinking: job.Thinking, Temperature: job.Temperature, TopP: job.TopP}
+	if err := startEnhancementExecution(ctx, sessionID, jobID, execName, photos, videos, job.DebugArtifacts, ai.SubjectPreset(job.SubjectPreset), job.UpscaleBelow, modelCfg); err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Str("execution", execName).Msg("Failed to start resumed enhancement pipeline")
+		// Put the job back so the user can try again.
+		if pauseErr := sessionStore.PauseEnhancementJob(ctx, sessionID, jobID); pauseErr != nil {
+			log.Warn().Err(pauseErr).Str("jobId", jobID).Msg("Failed to restore paused status")
+		}
+		httpError(w, http.StatusInternalServerError, fmt.Sprintf("failed to start processing: %v", err))
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, map[string]interface{}{
+		"id":           jobID,
+		"status":       store.EnhancementStatusProcessing,
+		"pendingCount": len(photos) + len(videos),
+	})
+}
+
+// decodeEnhanceControlRequest validates a pause/resume request and returns
+// its session ID. It writes the error response and returns false on failure.
+func decodeEnhanceControlRequest(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if r.Method != http.MethodPost {
+		log.Warn().Str("param", "method").Msg("Method not allowed")
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return "", false
+	}
+
+	var req struct {
+		SessionID string `json:"sessionId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn().Str("param", "body").Msg("Invalid request body")
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return "", false
+	}
+	if err := validateSessionID(req.SessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return "", false
+	}
+	if !ensureSessionOwner(w, r, req.SessionID) {
+		return "", false
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return "", false
+	}
+	return req.SessionID, true
+}
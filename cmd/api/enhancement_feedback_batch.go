@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/jobs"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// --- Batch enhancement feedback (DDR-182) ---
+
+// maxFeedbackBatchPhotos bounds one batch to an Instagram carousel; each
+// photo is its own Lambda invocation.
+const maxFeedbackBatchPhotos = 20
+
+// handleEnhanceFeedbackBatch serves both methods of
+// /api/enhance/{id}/feedback-batch.
+func handleEnhanceFeedbackBatch(w http.ResponseWriter, r *http.Request, jobID string) {
+	switch r.Method {
+	case http.MethodPost:
+		handleEnhanceFeedbackBatchStart(w, r, jobID)
+	case http.MethodGet:
+		handleEnhanceFeedbackBatchStatus(w, r, jobID)
+	default:
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// POST /api/enhance/{id}/feedback-batch
+// Body: {"sessionId": "uuid", "keys": ["uuid/a.jpg", "uuid/b.jpg"], "feedback": "warmer tones"}
+//
+// Applies one feedback instruction to several photos of the job. Each photo
+// is dispatched as its own enhancement-feedback round; the batch tracks them.
+func handleEnhanceFeedbackBatchStart(w http.ResponseWriter, r *http.Request, jobID string) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("jobId", jobID).Msg("Handler entry: handleEnhanceFeedbackBatchStart")
+
+	var req struct {
+		SessionID string   `json:"sessionId"`
+		Keys      []string `json:"keys"`
+		Feedback  string   `json:"feedback"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn().Str("param", "body").Msg("Invalid request body")
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validateSessionID(req.SessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.Feedback = strings.TrimSpace(req.Feedback)
+	if req.Feedback == "" {
+		httpError(w, http.StatusBadRequest, "feedback is required")
+		return
+	}
+	var keys []string
+	seen := make(map[string]bool)
+	for _, key := range req.Keys {
+		if key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 || len(keys) > maxFeedbackBatchPhotos {
+		httpError(w, http.StatusBadRequest, fmt.Sprintf("request 1 to %d photos", maxFeedbackBatchPhotos))
+		return
+	}
+	if !ensureSessionOwner(w, r, req.SessionID) {
+		return
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+
+	ctx := context.Background()
+	job, err := sessionStore.GetEnhancementJob(ctx, req.SessionID, jobID)
+	if err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to read enhancement job for batch feedback")
+		httpError(w, http.StatusInternalServerError, "failed to read job")
+		return
+	}
+	if job == nil {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	// An item can be named by its original or its enhanced key. Two rounds
+	// on one item would both write its next version, so each item gets one.
+	items := make(map[int]bool)
+	var itemKeys []string
+	for _, key := range keys {
+		idx := enhancedItemIndex(job, key)
+		if idx < 0 {
+			httpError(w, http.StatusBadRequest, fmt.Sprintf("%s has no enhanced result in this job", key))
+			return
+		}
+		if !items[idx] {
+			items[idx] = true
+			itemKeys = append(itemKeys, key)
+		}
+	}
+	keys = itemKeys
+
+	batch := &store.FeedbackBatch{
+		ID:        jobs.GenerateID("efb-"),
+		JobID:     jobID,
+		Feedback:  req.Feedback,
+		CreatedAt: time.Now().Unix(),
+	}
+	for _, key := range keys {
+		batch.Items = append(batch.Items, store.FeedbackBatchItem{Key: key, Status: store.FeedbackBatchProcessing})
+	}
+	if err := sessionStore.PutFeedbackBatch(ctx, req.SessionID, batch); err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to persist feedback batch")
+		httpError(w, http.StatusInternalServerError, "failed to start batch feedback")
+		return
+	}
+
+	// Dispatch one enhancement-feedback round per photo (DDR-053). A photo
+	// that cannot be dispatched is marked failed; the rest still run.
+	dispatched := 0
+	for i, key := range keys {
+		payload := map[string]interface{}{
+			"type":       "enhancement-feedback",
+			"sessionId":  req.SessionID,
+			"jobId":      jobID,
+			"key":        key,
+			"feedback":   req.Feedback,
+			"batchId":    batch.ID,
+			"batchIndex": i,
+		}
+		if err := invokeAsync(ctx, enhanceLambdaArn, payload); err != nil {
+			log.Error().Err(err).Str("batchId", batch.ID).Str("key", key).Msg("Failed to dispatch batch feedback round")
+			batch.Items[i] = store.FeedbackBatchItem{Key: key, Status: store.FeedbackBatchError, Error: "failed to start feedback processing"}
+			if err := sessionStore.UpdateFeedbackBatchItem(ctx, req.SessionID, batch.ID, i, batch.Items[i]); err != nil {
+				log.Warn().Err(err).Str("batchId", batch.ID).Msg("Failed to record undispatched batch item")
+			}
+			continue
+		}
+		dispatched++
+	}
+	if dispatched == 0 {
+		httpError(w, http.StatusInternalServerError, "failed to start feedback processing")
+		return
+	}
+
+	log.Info().Str("jobId", jobID).Str("batchId", batch.ID).Int("photos", dispatched).Msg("Batch feedback dispatched to enhance-lambda")
+	batch.Settle()
+	respondJSON(w, http.StatusAccepted, batch)
+}
+
+// GET /api/enhance/{id}/feedback-batch?sessionId=...&batchId=...
+func handleEnhanceFeedbackBatchStatus(w http.ResponseWriter, r *http.Request, jobID string) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("jobId", jobID).Msg("Handler entry: handleEnhanceFeedbackBatchStatus")
+
+	sessionID := r.URL.Query().Get("sessionId")
+	batchID := r.URL.Query().Get("batchId")
+	if err := validateSessionID(sessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !strings.HasPrefix(batchID, "efb-") {
+		httpError(w, http.StatusBadRequest, "batchId is required")
+		return
+	}
+	if !ensureSessionOwner(w, r, sessionID) {
+		return
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+
+	batch, err := sessionStore.GetFeedbackBatch(r.Context(), sessionID, batchID)
+	if err != nil {
+		log.Error().Err(err).Str("batchId", batchID).Msg("Failed to read feedback batch")
+		httpError(w, http.StatusInternalServerError, "failed to read batch")
+		return
+	}
+	if batch == nil || batch.JobID != jobID {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	respondJSON(w, http.StatusOK, batch)
+}
+
+// enhancedItemIndex returns the index of the item of job that key names,
+// by original or current enhanced key, if it has a result to give feedback
+// on; otherwise -1.
+func enhancedItemIndex(job *store.EnhancementJob, key string) int {
+	for i, item := range job.Items {
+		if item.Key == key || item.EnhancedKey == key {
+			if item.EnhancedKey == "" {
+				return -1
+			}
+			return i
+		}
+	}
+	return -1
+}
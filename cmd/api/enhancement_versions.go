@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// --- Enhancement versions (DDR-181) ---
+
+// enhancementVersionView is a version with a URL to preview it side by side
+// with the others.
+type enhancementVersionView struct {
+	store.EnhancementVersion
+	PreviewURL string `json:"previewUrl,omitempty"`
+}
+
+// GET /api/enhance/{id}/versions?sessionId=...&key=...
+// Lists every result an item has had, oldest first, with the version it
+// currently shows.
+func handleEnhanceVersions(w http.ResponseWriter, r *http.Request, jobID string) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("jobId", jobID).Msg("Handler entry: handleEnhanceVersions")
+
+	if r.Method != http.MethodGet {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	sessionID := r.URL.Query().Get("sessionId")
+	key := r.URL.Query().Get("key")
+	if err := validateSessionID(sessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if key == "" {
+		httpError(w, http.StatusBadRequest, "key is required")
+		return
+	}
+	if !ensureSessionOwner(w, r, sessionID) {
+		return
+	}
+
+	_, item, ok := loadEnhancementItem(w, r.Context(), sessionID, jobID, key)
+	if !ok {
+		return
+	}
+
+	versions := item.VersionList()
+	views := make([]enhancementVersionView, 0, len(versions))
+	for _, v := range versions {
+		view := enhancementVersionView{EnhancementVersion: v}
+		if presigned, err := presigner.PresignGetObject(r.Context(), &s3.GetObjectInput{
+			Bucket: &mediaBucket,
+			Key:    &v.EnhancedKey,
+		}, s3.WithPresignExpires(1*time.Hour)); err != nil {
+			log.Warn().Err(err).Str("key", v.EnhancedKey).Msg("Failed to presign enhancement version preview")
+		} else {
+			view.PreviewURL = presigned.URL
+		}
+		views = append(views, view)
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"key":            item.Key,
+		"currentVersion": item.Current(),
+		"versions":       views,
+	})
+}
+
+// POST /api/enhance/{id}/rollback
+// Body: {"sessionId": "uuid", "key": "uuid/file.jpg", "version": 2}
+//
+// Makes an earlier (or later) version the item's current result. No version
+// is deleted, and the next feedback round edits the version rolled back to.
+func handleEnhanceRollback(w http.ResponseWriter, r *http.Request, jobID string) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("jobId", jobID).Msg("Handler entry: handleEnhanceRollback")
+
+	if r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		SessionID string `json:"sessionId"`
+		Key       string `json:"key"`
+		Version   int    `json:"version"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn().Str("param", "body").Msg("Invalid request body")
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validateSessionID(req.SessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Key == "" || req.Version < 1 {
+		httpError(w, http.StatusBadRequest, "key and version are required")
+		return
+	}
+	if !ensureSessionOwner(w, r, req.SessionID) {
+		return
+	}
+
+	ctx := context.Background()
+	index, item, ok := loadEnhancementItem(w, ctx, req.SessionID, jobID, req.Key)
+	if !ok {
+		return
+	}
+	if err := item.RollBack(req.Version); err != nil {
+		httpError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err := sessionStore.UpdateEnhancementItemFields(ctx, req.SessionID, jobID, index, item); err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to roll back enhancement item")
+		httpError(w, http.StatusInternalServerError, "failed to roll back")
+		return
+	}
+
+	log.Info().Str("jobId", jobID).Str("key", item.Key).Int("version", req.Version).Msg("Enhancement item rolled back")
+	respondJSON(w, http.StatusOK, item)
+}
+
+// loadEnhancementItem returns the job item whose original or current
+// enhanced key is key, with its index. It writes the error response and
+// returns false on failure.
+func loadEnhancementItem(w http.ResponseWriter, ctx context.Context, sessionID, jobID, key string) (int, store.EnhancementItem, bool) {
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return 0, store.EnhancementItem{}, false
+	}
+	job, err := sessionStore.GetEnhancementJob(ctx, sessionID, jobID)
+	if err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to read enhancement job")
+		httpError(w, http.StatusInternalServerError, "failed to read job")
+		return 0, store.EnhancementItem{}, false
+	}
+	if job == nil {
+		httpError(w, http.StatusNotFound, "not found")
+		return 0, store.EnhancementItem{}, false
+	}
+	for i, item := range job.Items {
+		if item.Key == key || item.EnhancedKey == key {
+			return i, item, true
+		}
+	}
+	httpError(w, http.StatusNotFound, "item not found in job")
+	return 0, store.EnhancementItem{}, false
+}
@@ -206,6 +206,9 @@ func handleFBPrepResults(w http.ResponseWriter, r *http.Request, jobID string) {
 	default:
 		resp["stage"] = 1
 	}
+	if job.Telemetry != nil {
+		resp["perJobTelemetry"] = job.Telemetry // DDR-118
+	}
 	respondJSON(w, http.StatusOK, resp)
 }
 
@@ -6,8 +6,12 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/sfn"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 
+	"github.com/fpang/ai-social-media-helper/internal/facebook"
+	"github.com/fpang/ai-social-media-helper/internal/flags"
 	"github.com/fpang/ai-social-media-helper/internal/instagram"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 )
 
@@ -18,20 +22,44 @@ var (
 	mediaBucket        string
 	originVerifySecret string // DDR-028: shared secret for CloudFront origin verification
 
+	// Per-session encryption keys (DDR-164).
+	sessionKeys *s3util.SessionKeys
+
+	// S3 Inventory destination holding storage reconciliation reports
+	// (DDR-148); empty when inventory is not configured.
+	inventoryBucket string
+
+	// Brand kit logos (DDR-149). The media bucket expires objects after a
+	// day, so logos live in their own bucket; empty when not configured.
+	brandAssetsBucket string
+
 	// DynamoDB session store for persistent job state (DDR-050).
 	sessionStore *store.DynamoStore
 
 	// File processing store for per-file status during triage (DDR-061).
 	fileProcessStore *store.FileProcessingStore
 
+	// Scheduled posts waiting for the publish scheduler (DDR-144).
+	scheduledPostStore *store.ScheduledPostStore
+
 	// Lambda client for async Lambda invocations (DDR-050, DDR-053).
 	lambdaClient *lambda.Client
 
+	// SQS job queues fronting the async worker Lambdas (DDR-092).
+	// Keyed by worker function ARN; workers without a queue fall back to lambda:Invoke.
+	sqsClient    *sqs.Client
+	jobQueueURLs = map[string]string{}
+
+	// Interactive-priority job queues, keyed like jobQueueURLs (DDR-096).
+	// Feedback jobs go here so they do not wait behind a batch backlog.
+	interactiveQueueURLs = map[string]string{}
+
 	// Domain-specific Lambda ARNs for async dispatch (DDR-053).
 	descriptionLambdaArn string
 	downloadLambdaArn     string
 	enhanceLambdaArn      string
 	fbPrepLambdaArn      string
+	selectionLambdaArn   string // DDR-177: selection feedback
 
 	// Step Functions client for pipelines (DDR-050, DDR-052).
 	sfnClient         *sfn.Client
@@ -45,9 +73,28 @@ var (
 	// nil if Instagram credentials are not configured (publishing disabled).
 	igClient *instagram.Client
 
+	// Facebook Page client for location search (DDR-138), which needs a
+	// Facebook token rather than the Instagram-Login one.
+	// nil if Facebook credentials are not configured (search disabled).
+	fbClient *facebook.Client
+
 	// EventBridge client for RAG feedback events (override capture).
 	ebClient *eventbridge.Client
 
 	// CloudWatch Logs client for triage logs endpoint.
 	cwlClient *cloudwatchlogs.Client
+
+	// Stylized cover variants (DDR-102): off unless MOOD_VARIANTS_ENABLED=true;
+	// each user may generate at most moodVariantsDailyLimit images per UTC day.
+	moodVariantsEnabled    bool
+	moodVariantsDailyLimit = 6
+
+	// Cognito subs allowed to approve someone else's gated publish job
+	// (DDR-121), from PUBLISH_APPROVER_SUBS. Approval links work without it.
+	publishApprovers = map[string]bool{}
+
+	// Per-deployment feature flags (DDR-132), and the Cognito subs allowed
+	// to flip them, from ADMIN_SUBS.
+	featureFlags *flags.Set
+	adminSubs    = map[string]bool{}
 )
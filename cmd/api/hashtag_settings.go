@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/hashtags"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Hashtag strategy (DDR-157) ---
+
+// GET    /api/hashtag-settings — the caller's hashtag strategy (404 if none)
+// POST   /api/hashtag-settings — save the caller's hashtag strategy
+// DELETE /api/hashtag-settings — remove it
+// Body: {"maxCount": 15, "banned": ["like4like"], "alwaysInclude": ["janetravels"]}
+//
+// The description worker applies the strategy to every caption it generates
+// for the caller's sessions, before storing the job result. maxCount 0 means
+// the platform limit of 30.
+func handleHashtagSettings(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleHashtagSettings")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	owner, ok := signedInUser(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		settings, err := sessionStore.GetHashtagSettings(r.Context(), owner)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to read hashtag settings")
+			httpError(w, http.StatusInternalServerError, "failed to read hashtag settings")
+			return
+		}
+		if settings == nil {
+			httpError(w, http.StatusNotFound, "no hashtag settings are set")
+			return
+		}
+		respondJSON(w, http.StatusOK, settings)
+
+	case http.MethodDelete:
+		if err := sessionStore.DeleteHashtagSettings(r.Context(), owner); err != nil {
+			log.Error().Err(err).Msg("Failed to delete hashtag settings")
+			httpError(w, http.StatusInternalServerError, "failed to delete hashtag settings")
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]bool{"deleted": true})
+
+	default:
+		r.Body = http.MaxBytesReader(w, r.Body, 64<<10)
+		var req store.HashtagSettings
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httpError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		settings, err := hashtags.Normalize(req)
+		if err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		settings.UpdatedAt = time.Now().Unix()
+		if err := sessionStore.PutHashtagSettings(r.Context(), owner, settings); err != nil {
+			log.Error().Err(err).Msg("Failed to save hashtag settings")
+			httpError(w, http.StatusInternalServerError, "failed to save hashtag settings")
+			return
+		}
+		log.Info().Int("maxCount", settings.MaxCount).Int("banned", len(settings.Banned)).Int("alwaysInclude", len(settings.AlwaysInclude)).Msg("Hashtag settings saved")
+		respondJSON(w, http.StatusOK, settings)
+	}
+}
+
+// callerHashtags applies the signed-in caller's hashtag strategy to tags, as
+// the description worker does for generated captions, so banned tags from a
+// brand kit are not sent on. Without a readable strategy the platform
+// defaults apply.
+func callerHashtags(r *http.Request, tags []string) []string {
+	var settings *store.HashtagSettings
+	if owner := getUserSub(r); owner != "" && sessionStore != nil {
+		s, err := sessionStore.GetHashtagSettings(r.Context(), owner)
+		if err != nil {
+			log.Warn().Err(err).Msg("Hashtag settings not readable — applying platform defaults")
+		} else {
+			settings = s
+		}
+	}
+	return hashtags.Apply(tags, settings)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/fpang/ai-social-media-helper/internal/jobs"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Post-publish insights (DDR-146) ---
+
+// GET /api/posts/{instagramPostId}/insights — the insights time series the
+// insights poller has collected for a published post, oldest first.
+//
+// Only the owner of the session the post was published from, or an admin,
+// may read it; anyone else gets 404.
+func handlePostRoutes(w http.ResponseWriter, r *http.Request) {
+	mediaID, action, ok := jobs.ParseRoute(r.URL.Path, "/api/posts/", "")
+	if !ok || action != "insights" || !validMediaID(mediaID) {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("instagramPostId", mediaID).Msg("Handler entry: handlePostRoutes")
+
+	if r.Method != http.MethodGet {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	owner, ok := signedInUser(w, r)
+	if !ok {
+		return
+	}
+
+	post, err := sessionStore.GetTrackedPost(r.Context(), mediaID)
+	if err != nil {
+		log.Error().Err(err).Str("instagramPostId", mediaID).Msg("Failed to read tracked post")
+		httpError(w, http.StatusInternalServerError, "failed to read insights")
+		return
+	}
+	if post == nil || (post.OwnerSub != owner && !adminSubs[owner]) {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	samples, err := sessionStore.ListInsightsSamples(r.Context(), mediaID)
+	if err != nil {
+		log.Error().Err(err).Str("instagramPostId", mediaID).Msg("Failed to read insights samples")
+		httpError(w, http.StatusInternalServerError, "failed to read insights")
+		return
+	}
+
+	resp := map[string]interface{}{
+		"post":    post,
+		"samples": samples,
+	}
+	if len(samples) > 0 {
+		resp["latest"] = samples[len(samples)-1]
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// validMediaID accepts Instagram media IDs (digits) and mock post IDs
+// (DDR-139), which use letters, digits and dashes.
+func validMediaID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	return strings.Trim(id, "abcdefghijklmnopqrstuvwxyz0123456789-") == ""
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// --- Instagram location search (DDR-138) ---
+
+// maxLocationQueryLen bounds the search text passed to the Graph API.
+const maxLocationQueryLen = 100
+
+// GET /api/instagram/locations?q=...
+//
+// Returns the taggable locations matching q. The chosen location's id is sent
+// as locationId to POST /api/publish/start. The search runs with the Facebook
+// Page token: the Pages search does not accept Instagram-Login tokens.
+func handleInstagramLocations(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleInstagramLocations")
+
+	if r.Method != http.MethodGet {
+		log.Warn().Str("param", "method").Msg("Method not allowed")
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if fbClient == nil {
+		log.Debug().Msg("Facebook client not configured")
+		httpError(w, http.StatusServiceUnavailable, "location search is not configured — set FACEBOOK_PAGE_TOKEN and FACEBOOK_PAGE_ID")
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		log.Warn().Str("param", "q").Msg("Location query is required")
+		httpError(w, http.StatusBadRequest, "q is required")
+		return
+	}
+	if len(query) > maxLocationQueryLen {
+		log.Warn().Str("param", "q").Int("length", len(query)).Msg("Location query too long")
+		httpError(w, http.StatusBadRequest, "q is too long")
+		return
+	}
+
+	locations, err := fbClient.SearchLocations(r.Context(), query)
+	if err != nil {
+		log.Error().Err(err).Str("query", query).Msg("Instagram location search failed")
+		httpError(w, http.StatusInternalServerError, "location search failed")
+		return
+	}
+	log.Debug().Str("query", query).Int("count", len(locations)).Msg("Instagram locations found")
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"locations": locations,
+	})
+}
+
+// validateLocationID checks a location ID from the client. Graph API page
+// IDs are numeric.
+func validateLocationID(id string) bool {
+	if id == "" || len(id) > 32 {
+		return false
+	}
+	for _, c := range id {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
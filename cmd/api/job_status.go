@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// --- Generic Job Status (DDR-136) ---
+
+// jobEnvelope is the normalized response of GET /api/jobs/{id}. Payload is
+// the job's own record, the same fields its type-specific results endpoint
+// returns.
+type jobEnvelope struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"`
+	Status   string       `json:"status"`
+	Progress *jobProgress `json:"progress,omitempty"`
+	Error    string       `json:"error,omitempty"`
+	Payload  interface{}  `json:"payload"`
+}
+
+// jobProgress counts the units of work a job has finished. It is omitted for
+// jobs that run as a single step (selection, description).
+type jobProgress struct {
+	Completed int `json:"completed"`
+	Total     int `json:"total"`
+}
+
+// jobTypes maps job ID prefixes (from jobs.GenerateID) to the job type
+// reported in the envelope. Types are step names from store.StepOrder.
+var jobTypes = []struct{ prefix, typ string }{
+	{"triage-", "triage"},
+	{"sel-", "selection"},
+	{"enh-", "enhancement"},
+	{"dl-", "download"},
+	{"pub-", "publish"},
+	{"desc-", "description"},
+}
+
+// jobTypeOf returns the job type for a job ID, or "" if its prefix is not
+// served by the generic endpoint.
+func jobTypeOf(jobID string) string {
+	for _, t := range jobTypes {
+		if strings.HasPrefix(jobID, t.prefix) {
+			return t.typ
+		}
+	}
+	return ""
+}
+
+// GET /api/jobs/{id}?sessionId=...
+//
+// Returns any triage, selection, enhancement, download, publish or
+// description job in one envelope:
+// {"id", "type", "status", "progress": {"completed", "total"}, "error", "payload"}
+// The job type comes from the ID prefix, so clients can poll a job without
+// knowing which pipeline started it.
+func handleJobStatus(w http.ResponseWriter, r *http.Request, jobID string) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("jobId", jobID).Msg("Handler entry: handleJobStatus")
+
+	if r.Method != http.MethodGet {
+		log.Warn().Str("param", "method").Msg("Method not allowed")
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	sessionID := r.URL.Query().Get("sessionId")
+	if err := validateSessionID(sessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	typ := jobTypeOf(jobID)
+	if typ == "" {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !ensureSessionOwner(w, r, sessionID) {
+		return
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+
+	env, err := loadJobEnvelope(r.Context(), sessionID, jobID, typ)
+	if err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Str("type", typ).Msg("Failed to read job from DynamoDB")
+		httpError(w, http.StatusInternalServerError, "failed to read job status")
+		return
+	}
+	if env == nil {
+		log.Debug().Str("jobId", jobID).Str("sessionId", sessionID).Msg("Job not found in DynamoDB")
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	env.ID, env.Type = jobID, typ
+	respondJSON(w, http.StatusOK, env)
+}
+
+// loadJobEnvelope reads a job of the given type and fills in everything but
+// its ID and type. Returns nil, nil if the job does not exist. Download and
+// enhancement jobs get the same refresh their own results endpoints apply.
+func loadJobEnvelope(ctx context.Context, sessionID, jobID, typ string) (*jobEnvelope, error) {
+	switch typ {
+	case "triage":
+		job, err := sessionStore.GetTriageJob(ctx, sessionID, jobID)
+		if err != nil || job == nil {
+			return nil, err
+		}
+		env := &jobEnvelope{Status: job.Status, Error: job.Error, Payload: job}
+		switch {
+		case len(job.FileProgress) > 0: // DDR-174
+			env.Progress = &jobProgress{Completed: job.AnalyzedFiles(), Total: len(job.FileProgress)}
+		case job.ExpectedFileCount > 0:
+			env.Progress = &jobProgress{Completed: job.ProcessedCount, Total: job.ExpectedFileCount}
+		case job.TriageBatchTotal > 0:
+			env.Progress = &jobProgress{Completed: job.TriageBatch, Total: job.TriageBatchTotal}
+		}
+		return env, nil
+
+	case "selection":
+		job, err := sessionStore.GetSelectionJob(ctx, sessionID, jobID)
+		if err != nil || job == nil {
+			return nil, err
+		}
+		return &jobEnvelope{Status: job.Status, Error: job.Error, Payload: job}, nil
+
+	case "enhancement":
+		job, err := sessionStore.GetEnhancementJob(ctx, sessionID, jobID)
+		if err != nil || job == nil {
+			return nil, err
+		}
+		reconcileEnhancementJob(ctx, sessionID, job)
+		return &jobEnvelope{
+			Status:   job.Status,
+			Error:    job.Error,
+			Progress: &jobProgress{Completed: job.CompletedCount, Total: job.TotalCount},
+			Payload:  job,
+		}, nil
+
+	case "download":
+		job, err := sessionStore.GetDownloadJob(ctx, sessionID, jobID)
+		if err != nil || job == nil {
+			return nil, err
+		}
+		refreshDownloadJob(ctx, sessionID, job)
+		env := &jobEnvelope{Status: job.Status, Error: job.Error, Payload: job}
+		if len(job.Bundles) > 0 {
+			done := 0
+			for _, b := range job.Bundles {
+				if b.Status == "complete" {
+					done++
+				}
+			}
+			env.Progress = &jobProgress{Completed: done, Total: len(job.Bundles)}
+		}
+		return env, nil
+
+	case "publish":
+		job, err := sessionStore.GetPublishJob(ctx, sessionID, jobID)
+		if err != nil || job == nil {
+			return nil, err
+		}
+		return &jobEnvelope{
+			Status:   job.Status,
+			Error:    job.Error,
+			Progress: &jobProgress{Completed: job.CompletedItems, Total: job.TotalItems},
+			Payload:  job,
+		}, nil
+
+	case "description":
+		job, err := sessionStore.GetDescriptionJob(ctx, sessionID, jobID)
+		if err != nil || job == nil {
+			return nil, err
+		}
+		return &jobEnvelope{Status: job.Status, Error: job.Error, Payload: job}, nil
+	}
+	return nil, nil
+}
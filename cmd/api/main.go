@@ -17,11 +17,18 @@
 //	POST /api/upload-multipart/init     — create S3 multipart upload + presign part URLs (DDR-054)
 //	POST /api/upload-multipart/complete — complete S3 multipart upload with ETags (DDR-054)
 //	POST /api/upload-multipart/abort    — abort S3 multipart upload (DDR-054)
+//	POST /api/upload/advice        — originals vs downscaled upload recommendation (DDR-128)
+//	POST /api/upload/proxy         — register a downscaled proxy upload + presigned PUT (DDR-131)
+//	POST /api/upload/original      — presigned PUT for a proxy's original (DDR-131)
+//	POST /api/upload/original/complete — link an uploaded original to its proxy (DDR-131)
+//	GET  /api/upload/links         — list proxy/original links for a session (DDR-131)
 //	POST /api/triage/init           — create triage job (DDB only, no SF — DDR-067)
 //	POST /api/triage/finalize      — start SF after uploads complete (DDR-067)
 //	POST /api/triage/start         — start triage from uploaded S3 files
 //	GET  /api/triage/{id}/results  — poll triage results
 //	POST /api/triage/{id}/confirm  — delete confirmed files from S3
+//	POST /api/selection/{id}/rerun — re-rank a selection around locked and excluded picks (DDR-176)
+//	POST /api/selection/{id}/feedback — revise a selection from free-text feedback (DDR-177)
 //	POST /api/download/start       — start ZIP bundle creation for a post group (DDR-034)
 //	GET  /api/download/{id}/results — poll download bundle status and URLs (DDR-034)
 //	POST /api/description/generate — generate AI Instagram caption for a post group (DDR-036)
@@ -32,34 +39,83 @@
 //	POST /api/fb-prep/{id}/feedback — regenerate caption for a single item with feedback
 //	POST /api/publish/start         — start publishing a post group to Instagram (DDR-040)
 //	GET  /api/publish/{id}/status  — poll publishing progress (DDR-040)
+//	POST /api/publish/{id}/approve — approve a gated publish job (DDR-121)
+//	POST /api/publish/{id}/reject  — reject a gated publish job (DDR-121)
+//	GET  /api/publish/scheduled    — list the caller's scheduled posts (DDR-144)
+//	POST /api/publish/scheduled/{id}/cancel     — cancel a scheduled post (DDR-144)
+//	POST /api/publish/scheduled/{id}/reschedule — move a scheduled post (DDR-144)
+//	GET  /api/posts/{id}/insights  — insights time series of a published post (DDR-146)
+//	GET  /api/instagram/locations  — search taggable Instagram locations (DDR-138)
+//	POST /api/crop/start           — suggest subject-aware 1:1, 4:5 and 9:16 crops (DDR-130)
+//	GET  /api/crop/{id}/results    — poll crop suggestions (DDR-130)
 //	GET  /api/sessions/{sessionId}/file-status — per-file processing statuses for a session
+//	GET  /api/sessions/{sessionId}/metadata-policy — metadata kept in downloads and uploads (DDR-135)
+//	POST /api/sessions/{sessionId}/metadata-policy — set strip-gps, strip-all or preserve (DDR-135)
+//	GET  /api/sessions/{sessionId}/encryption — whether the session has its own KMS key (DDR-164)
+//	POST /api/sessions/{sessionId}/encryption — give the session its own KMS key before uploading (DDR-164)
+//	GET  /api/templates            — list the caller's post group templates (DDR-122)
+//	POST /api/templates            — save a post group template (DDR-122)
+//	DELETE /api/templates/{id}     — delete a post group template (DDR-122)
+//	GET  /api/groups               — list a session's post groups (DDR-178)
+//	POST /api/groups               — create a post group from selected keys (DDR-178)
+//	POST /api/groups/{id}/{action} — rename, reorder, split or merge a post group (DDR-178)
+//	POST /api/groups/{id}/suggest-order — ask Gemini for the carousel order (DDR-179)
+//	DELETE /api/groups/{id}        — delete a post group (DDR-178)
+//	GET  /api/watermark            — the caller's watermark (DDR-133)
+//	POST /api/watermark            — save the caller's watermark (DDR-133)
+//	DELETE /api/watermark          — remove the caller's watermark (DDR-133)
+//	GET  /api/provenance           — whether edited photos carry a provenance record (DDR-140)
+//	POST /api/provenance           — turn provenance records on or off (DDR-140)
+//	GET  /api/brand-kit            — the caller's brand kit (DDR-149)
+//	POST /api/brand-kit            — save the caller's brand kit (DDR-149)
+//	DELETE /api/brand-kit          — remove the caller's brand kit (DDR-149)
+//	GET  /api/hashtag-settings     — the caller's hashtag strategy (DDR-157)
+//	POST /api/hashtag-settings     — save the caller's hashtag strategy (DDR-157)
+//	DELETE /api/hashtag-settings   — remove the caller's hashtag strategy (DDR-157)
+//	GET  /api/settings/caption-style — the caller's caption persona (DDR-159)
+//	PUT  /api/settings/caption-style — save the caller's caption persona (DDR-159)
 //	POST /api/session/invalidate   — invalidate downstream state on back-navigation (DDR-037)
+//	GET  /api/jobs/{id}            — any job in a normalized envelope (DDR-136)
+//	POST /api/jobs/{id}/retry      — re-dispatch a failed async job (DDR-089)
+//	GET  /api/admin/flags          — current feature flag values (DDR-132)
+//	POST /api/admin/flags          — flip a feature flag at runtime (DDR-132)
+//	GET  /api/admin/usage          — Gemini concurrency, job queue depth and daily tokens per model (DDR-142)
+//	GET  /api/admin/storage-report — latest S3 inventory reconciliation report (DDR-148)
 //	GET  /api/media/thumbnail      — generate thumbnail from S3 object
 //	GET  /api/media/full           — presigned GET URL for full-resolution image
+//	GET  /api/media/preview        — frame strip for a video (DDR-124)
+//	POST /api/media/crop           — crop a photo server-side before publish (DDR-130)
+//	POST /api/media/adjust         — brightness, contrast, saturation, rotation and crop as a new enhancement version (DDR-183)
+//	POST /api/media/redact         — blur or remove bystanders' faces and licence plates in a copy (DDR-185)
+//	GET  /api/media/redact/{id}/results — poll a redaction (DDR-185)
 package main
 
 import (
 	"context"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
 	lambdasvc "github.com/aws/aws-sdk-go-v2/service/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/sfn"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/awslabs/aws-lambda-go-api-proxy/httpadapter"
 
 	"github.com/fpang/ai-social-media-helper/internal/ai"
 	"github.com/fpang/ai-social-media-helper/internal/bootstrap"
-	"github.com/fpang/ai-social-media-helper/internal/instagram"
+	"github.com/fpang/ai-social-media-helper/internal/flags"
 	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 	"github.com/rs/zerolog/log"
 )
@@ -78,6 +134,7 @@ func init() {
 
 	s3Client = s3.NewFromConfig(cfg)
 	presigner = s3.NewPresignClient(s3Client)
+	sessionKeys = s3util.NewSessionKeys(kms.NewFromConfig(cfg))
 	mediaBucket = os.Getenv("MEDIA_BUCKET_NAME")
 	if mediaBucket == "" {
 		log.Fatal().Msg("MEDIA_BUCKET_NAME environment variable is required")
@@ -93,6 +150,7 @@ func init() {
 	if dynamoTableName != "" {
 		ddbClient := dynamodb.NewFromConfig(cfg)
 		sessionStore = store.NewDynamoStore(ddbClient, dynamoTableName)
+		metrics.SetUsageStore(sessionStore) // DDR-142
 		log.Info().Str("table", dynamoTableName).Msg("DynamoDB session store initialized")
 	} else {
 		log.Warn().Msg("DYNAMO_TABLE_NAME not set — DynamoDB store disabled")
@@ -102,6 +160,13 @@ func init() {
 	fpTableName := os.Getenv("FILE_PROCESSING_TABLE_NAME")
 	if fpTableName != "" && sessionStore != nil {
 		fileProcessStore = store.NewFileProcessingStore(sessionStore.Client(), fpTableName)
+		sessionStore.SetFileProcessingStore(fileProcessStore)
+	}
+
+	// Initialize scheduled posts store for scheduled publishing (DDR-144).
+	scheduledTableName := os.Getenv("SCHEDULED_POSTS_TABLE_NAME")
+	if scheduledTableName != "" && sessionStore != nil {
+		scheduledPostStore = store.NewScheduledPostStore(sessionStore.Client(), scheduledTableName)
 	}
 
 	// Initialize Lambda client for async invocations (DDR-050, DDR-053).
@@ -110,10 +175,36 @@ func init() {
 	downloadLambdaArn = os.Getenv("DOWNLOAD_LAMBDA_ARN")
 	enhanceLambdaArn = os.Getenv("ENHANCE_LAMBDA_ARN")
 	fbPrepLambdaArn = os.Getenv("FB_PREP_LAMBDA_ARN")
+	selectionLambdaArn = os.Getenv("SELECTION_LAMBDA_ARN") // DDR-177: selection feedback
 	if descriptionLambdaArn == "" || downloadLambdaArn == "" || enhanceLambdaArn == "" {
 		log.Warn().Msg("One or more Lambda ARNs not set — async dispatch may be disabled (DDR-053)")
 	}
 
+	// SQS job queues for async dispatch (DDR-092). Each queue is optional so
+	// workers can be migrated off direct lambda:Invoke one at a time.
+	sqsClient = sqs.NewFromConfig(cfg)
+	for arn, env := range map[string]string{
+		descriptionLambdaArn: "DESCRIPTION_QUEUE_URL",
+		downloadLambdaArn:    "DOWNLOAD_QUEUE_URL",
+		enhanceLambdaArn:     "ENHANCE_QUEUE_URL",
+		fbPrepLambdaArn:      "FB_PREP_QUEUE_URL",
+	} {
+		if url := os.Getenv(env); arn != "" && url != "" {
+			jobQueueURLs[arn] = url
+		}
+	}
+	// Interactive queues for feedback jobs (DDR-096). Without one, feedback
+	// jobs share the worker's batch queue.
+	for arn, env := range map[string]string{
+		descriptionLambdaArn: "DESCRIPTION_INTERACTIVE_QUEUE_URL",
+		enhanceLambdaArn:     "ENHANCE_INTERACTIVE_QUEUE_URL",
+		fbPrepLambdaArn:      "FB_PREP_INTERACTIVE_QUEUE_URL",
+	} {
+		if url := os.Getenv(env); arn != "" && url != "" {
+			interactiveQueueURLs[arn] = url
+		}
+	}
+
 	// Initialize Step Functions client for pipelines (DDR-050, DDR-052).
 	sfnClient = sfn.NewFromConfig(cfg)
 	selectionSfnArn = os.Getenv("SELECTION_STATE_MACHINE_ARN")
@@ -130,22 +221,7 @@ func init() {
 
 	// Load Gemini API key from SSM Parameter Store if not set via env var.
 	ssmClient := ssm.NewFromConfig(cfg)
-	if os.Getenv("GEMINI_API_KEY") == "" {
-		paramName := os.Getenv("SSM_API_KEY_PARAM")
-		if paramName == "" {
-			paramName = "/ai-social-media/prod/gemini-api-key"
-		}
-		ssmStart := time.Now()
-		result, err := ssmClient.GetParameter(context.Background(), &ssm.GetParameterInput{
-			Name:           &paramName,
-			WithDecryption: aws.Bool(true),
-		})
-		if err != nil {
-			log.Fatal().Err(err).Str("param", paramName).Msg("Failed to read API key from SSM")
-		}
-		os.Setenv("GEMINI_API_KEY", *result.Parameter.Value)
-		log.Debug().Str("param", paramName).Dur("elapsed", time.Since(ssmStart)).Msg("Gemini API key loaded from SSM")
-	}
+	bootstrap.LoadGeminiKey(ssmClient) // DDR-172: refreshed after rotation
 	bootstrap.LoadGCPServiceAccountKey(ssmClient)
 	if err := ai.LoadGCPServiceAccount(); err != nil {
 		log.Fatal().Err(err).Msg("Failed to load GCP service account")
@@ -153,48 +229,12 @@ func init() {
 
 	// Load Instagram credentials from SSM Parameter Store (DDR-040).
 	// Non-fatal: if credentials are not configured, publishing is disabled.
-	igAccessToken := os.Getenv("INSTAGRAM_ACCESS_TOKEN")
-	igUserID := os.Getenv("INSTAGRAM_USER_ID")
-	if igAccessToken == "" || igUserID == "" {
-		tokenParam := os.Getenv("SSM_INSTAGRAM_TOKEN_PARAM")
-		if tokenParam == "" {
-			tokenParam = "/ai-social-media/prod/instagram-access-token"
-		}
-		userIDParam := os.Getenv("SSM_INSTAGRAM_USER_ID_PARAM")
-		if userIDParam == "" {
-			userIDParam = "/ai-social-media/prod/instagram-user-id"
-		}
-
-		ssmStart := time.Now()
-		tokenResult, err := ssmClient.GetParameter(context.Background(), &ssm.GetParameterInput{
-			Name:           &tokenParam,
-			WithDecryption: aws.Bool(true),
-		})
-		if err != nil {
-			log.Warn().Err(err).Str("param", tokenParam).Msg("Instagram access token not found in SSM — publishing disabled")
-		} else {
-			igAccessToken = *tokenResult.Parameter.Value
-			log.Debug().Str("param", tokenParam).Dur("elapsed", time.Since(ssmStart)).Msg("Instagram token loaded from SSM")
-		}
+	// INSTAGRAM_MODE=mock simulates Instagram instead (DDR-139).
+	igClient = bootstrap.LoadInstagramCreds(ssmClient)
 
-		ssmStart = time.Now()
-		userIDResult, err := ssmClient.GetParameter(context.Background(), &ssm.GetParameterInput{
-			Name:           &userIDParam,
-			WithDecryption: aws.Bool(false),
-		})
-		if err != nil {
-			log.Warn().Err(err).Str("param", userIDParam).Msg("Instagram user ID not found in SSM — publishing disabled")
-		} else {
-			igUserID = *userIDResult.Parameter.Value
-			log.Debug().Str("param", userIDParam).Dur("elapsed", time.Since(ssmStart)).Msg("Instagram user ID loaded from SSM")
-		}
-	}
-	if igAccessToken != "" && igUserID != "" {
-		igClient = instagram.NewClient(igAccessToken, igUserID)
-		log.Info().Str("userId", igUserID).Msg("Instagram client initialized")
-	} else {
-		log.Warn().Msg("Instagram credentials not configured — publishing disabled")
-	}
+	// Load the Facebook Page credentials for location search (DDR-138).
+	// Non-fatal: without them the search is disabled and posts go untagged.
+	fbClient = bootstrap.LoadFacebookCreds(ssmClient)
 
 	// EventBridge client for RAG override feedback events.
 	ebClient = eventbridge.NewFromConfig(cfg)
@@ -202,16 +242,43 @@ func init() {
 	// CloudWatch Logs client for triage logs endpoint.
 	cwlClient = cloudwatchlogs.NewFromConfig(cfg)
 
+	// Stylized cover variants (DDR-102): opt-in per deployment and budget-capped per user.
+	moodVariantsEnabled = os.Getenv("MOOD_VARIANTS_ENABLED") == "true"
+	if v, err := strconv.Atoi(os.Getenv("MOOD_VARIANTS_DAILY_LIMIT")); err == nil && v >= 0 {
+		moodVariantsDailyLimit = v
+	}
+
+	// Publish approval gate (DDR-121): second users who may sign off.
+	for _, sub := range strings.Split(os.Getenv("PUBLISH_APPROVER_SUBS"), ",") {
+		if sub = strings.TrimSpace(sub); sub != "" {
+			publishApprovers[sub] = true
+		}
+	}
+
+	// Feature flags (DDR-132): read by every Lambda, flipped through /api/admin/flags.
+	featureFlags = bootstrap.InitFlags(ssmClient)
+	for _, sub := range strings.Split(os.Getenv("ADMIN_SUBS"), ",") {
+		if sub = strings.TrimSpace(sub); sub != "" {
+			adminSubs[sub] = true
+		}
+	}
+	inventoryBucket = os.Getenv("INVENTORY_BUCKET_NAME")      // DDR-148: optional
+	brandAssetsBucket = os.Getenv("BRAND_ASSETS_BUCKET_NAME") // DDR-149: optional
+
 	// Emit consolidated cold-start log for troubleshooting (DDR-062: version identity).
 	logging.NewStartupLogger("media-lambda").
 		CommitHash(commitHash).
 		BuildTime(buildTime).
 		InitDuration(time.Since(initStart)).
 		S3Bucket("mediaBucket", mediaBucket).
+		S3Bucket("inventoryBucket", inventoryBucket).
+		S3Bucket("brandAssetsBucket", brandAssetsBucket).
 		DynamoTable("sessions", dynamoTableName).
+		DynamoTable("scheduledPosts", scheduledTableName).
 		SSMParam("geminiApiKey", logging.EnvOrDefault("SSM_API_KEY_PARAM", "/ai-social-media/prod/gemini-api-key")).
 		SSMParam("instagramToken", logging.EnvOrDefault("SSM_INSTAGRAM_TOKEN_PARAM", "/ai-social-media/prod/instagram-access-token")).
 		SSMParam("instagramUserId", logging.EnvOrDefault("SSM_INSTAGRAM_USER_ID_PARAM", "/ai-social-media/prod/instagram-user-id")).
+		SSMParam("featureFlags", logging.EnvOrDefault("SSM_FEATURE_FLAGS_PARAM", flags.DefaultSSMParam)).
 		StateMachine("selectionPipeline", selectionSfnArn).
 		StateMachine("enhancementPipeline", enhancementSfnArn).
 		StateMachine("triagePipeline", triageSfnArn).
@@ -221,9 +288,18 @@ func init() {
 		LambdaFunc("downloadLambda", downloadLambdaArn).
 		LambdaFunc("enhanceLambda", enhanceLambdaArn).
 		LambdaFunc("fbPrepLambda", fbPrepLambdaArn).
+		LambdaFunc("selectionLambda", selectionLambdaArn).
+		Config("jobQueues", strconv.Itoa(len(jobQueueURLs))).
+		Config("interactiveQueues", strconv.Itoa(len(interactiveQueueURLs))).
 		Feature("instagram", igClient != nil).
+		Feature("instagramMock", igClient != nil && igClient.IsMock()).
+		Feature("locationSearch", fbClient != nil).
 		Feature("originVerify", originVerifySecret != "").
 		Feature("dynamodb", sessionStore != nil).
+		Feature("moodVariants", moodVariantsEnabled).
+		Config("moodVariantsDailyLimit", strconv.Itoa(moodVariantsDailyLimit)).
+		Config("publishApprovers", strconv.Itoa(len(publishApprovers))).
+		Config("admins", strconv.Itoa(len(adminSubs))).
 		Log()
 }
 
@@ -235,6 +311,11 @@ func main() {
 	mux.HandleFunc("/api/upload-multipart/init", handleMultipartInit)         // DDR-054
 	mux.HandleFunc("/api/upload-multipart/complete", handleMultipartComplete) // DDR-054
 	mux.HandleFunc("/api/upload-multipart/abort", handleMultipartAbort)       // DDR-054
+	mux.HandleFunc("/api/upload/advice", handleUploadAdvice)                  // DDR-128
+	mux.HandleFunc("/api/upload/proxy", handleProxyUpload)                    // DDR-131
+	mux.HandleFunc("/api/upload/original", handleOriginalUpload)              // DDR-131
+	mux.HandleFunc("/api/upload/links", handleMediaLinks)                     // DDR-131
+	mux.HandleFunc("/api/upload/original/complete", handleOriginalUploadComplete)
 	mux.HandleFunc("/api/triage/init", handleTriageInit)
 	mux.HandleFunc("/api/triage/finalize", handleTriageFinalize) // DDR-067
 	mux.HandleFunc("/api/triage/update-files", handleTriageUpdateFiles)
@@ -250,14 +331,41 @@ func main() {
 	mux.HandleFunc("/api/description/", handleDescriptionRoutes)
 	mux.HandleFunc("/api/fb-prep/start", handleFBPrepStart)
 	mux.HandleFunc("/api/fb-prep/", handleFBPrepRoutes)
-	mux.HandleFunc("/api/publish/start", handlePublishStart)           // DDR-040
-	mux.HandleFunc("/api/publish/", handlePublishRoutes)               // DDR-040
+	mux.HandleFunc("/api/publish/start", handlePublishStart)             // DDR-040
+	mux.HandleFunc("/api/publish/", handlePublishRoutes)                 // DDR-040
+	mux.HandleFunc("/api/publish/scheduled", handleScheduledPostList)    // DDR-144
+	mux.HandleFunc("/api/publish/scheduled/", handleScheduledPostRoutes) // DDR-144
+	mux.HandleFunc("/api/posts/", handlePostRoutes)                      // DDR-146
+	mux.HandleFunc("/api/instagram/locations", handleInstagramLocations) // DDR-138
+	mux.HandleFunc("/api/mood-variants/start", handleMoodVariantStart)   // DDR-102
+	mux.HandleFunc("/api/mood-variants/", handleMoodVariantRoutes)       // DDR-102
+	mux.HandleFunc("/api/crop/start", handleCropStart)                   // DDR-130
+	mux.HandleFunc("/api/crop/", handleCropRoutes)                       // DDR-130
+	mux.HandleFunc("/api/sessions", handleSessionList)                   // DDR-098
 	mux.HandleFunc("/api/sessions/", handleSessionRoutes)
 	mux.HandleFunc("/api/session/invalidate", handleSessionInvalidate) // DDR-037
+	mux.HandleFunc("/api/templates", handleTemplates)                  // DDR-122
+	mux.HandleFunc("/api/templates/", handleTemplateRoutes)            // DDR-122
+	mux.HandleFunc("/api/groups", handlePostGroups)                    // DDR-178
+	mux.HandleFunc("/api/groups/", handlePostGroupRoutes)              // DDR-178
+	mux.HandleFunc("/api/watermark", handleWatermark)                  // DDR-133
+	mux.HandleFunc("/api/provenance", handleProvenance)                // DDR-140
+	mux.HandleFunc("/api/brand-kit", handleBrandKit)                   // DDR-149
+	mux.HandleFunc("/api/hashtag-settings", handleHashtagSettings)     // DDR-157
+	mux.HandleFunc("/api/settings/caption-style", handleCaptionStyle)  // DDR-159
 	mux.HandleFunc("/api/overrides/", handleOverrideRoutes)
+	mux.HandleFunc("/api/jobs/", handleJobRoutes)                         // DDR-089
+	mux.HandleFunc("/api/admin/flags", handleAdminFlags)                  // DDR-132
+	mux.HandleFunc("/api/admin/usage", handleAdminUsage)                  // DDR-142
+	mux.HandleFunc("/api/admin/storage-report", handleAdminStorageReport) // DDR-148
 	mux.HandleFunc("/api/media/thumbnail", handleThumbnail)
 	mux.HandleFunc("/api/media/full", handleFullImage)
 	mux.HandleFunc("/api/media/compressed", handleCompressedVideo)
+	mux.HandleFunc("/api/media/preview", handleVideoPreview)      // DDR-124
+	mux.HandleFunc("/api/media/crop", handleMediaCrop)            // DDR-130
+	mux.HandleFunc("/api/media/adjust", handleMediaAdjust)        // DDR-183
+	mux.HandleFunc("/api/media/redact", handleMediaRedact)        // DDR-185
+	mux.HandleFunc("/api/media/redact/", handleMediaRedactRoutes) // DDR-185
 
 	// Catch-all: log unmatched routes explicitly (DDR-062: distinguish mux-404 from handler-404).
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -268,18 +376,28 @@ func main() {
 	// Log registered routes at cold start for troubleshooting (DDR-062).
 	routes := []string{
 		"/api/health", "/api/upload-url",
-		"/api/upload-multipart/init", "/api/upload-multipart/complete", "/api/upload-multipart/abort",
+		"/api/upload-multipart/init", "/api/upload-multipart/complete", "/api/upload-multipart/abort", "/api/upload/advice",
+		"/api/upload/proxy", "/api/upload/original", "/api/upload/original/complete", "/api/upload/links",
 		"/api/triage/init", "/api/triage/finalize", "/api/triage/update-files", "/api/triage/start", "/api/triage/",
 		"/api/selection/start", "/api/selection/",
 		"/api/enhance/start", "/api/enhance/",
 		"/api/download/start", "/api/download/",
 		"/api/description/generate", "/api/description/",
 		"/api/fb-prep/start", "/api/fb-prep/",
-		"/api/publish/start", "/api/publish/",
+		"/api/publish/start", "/api/publish/", "/api/publish/scheduled", "/api/publish/scheduled/",
+		"/api/posts/", "/api/instagram/locations",
+		"/api/crop/start", "/api/crop/",
 		"/api/sessions/",
 		"/api/session/invalidate",
+		"/api/templates", "/api/templates/",
+		"/api/groups", "/api/groups/",
+		"/api/watermark", "/api/provenance", "/api/brand-kit", "/api/hashtag-settings",
+		"/api/settings/caption-style",
 		"/api/overrides/",
-		"/api/media/thumbnail", "/api/media/full", "/api/media/compressed",
+		"/api/jobs/",
+		"/api/admin/flags", "/api/admin/usage", "/api/admin/storage-report",
+		"/api/media/thumbnail", "/api/media/full", "/api/media/compressed", "/api/media/preview", "/api/media/crop", "/api/media/adjust",
+		"/api/media/redact", "/api/media/redact/",
 	}
 	log.Info().Strs("routes", routes).Int("count", len(routes)).Msg("HTTP routes registered")
 
@@ -288,7 +406,7 @@ func main() {
 	handler := withMetrics(withOriginVerify(withUserIdentity(mux)))
 
 	adapter := httpadapter.NewV2(handler)
-	lambda.Start(adapter.ProxyWithContext)
+	bootstrap.Start(adapter.ProxyWithContext)
 }
 
 // --- Health ---
@@ -300,5 +418,6 @@ func handleHealth(w http.ResponseWriter, r *http.Request) {
 		"commitHash":          commitHash,
 		"buildTime":           buildTime,
 		"instagramConfigured": igClient != nil,
+		"instagramMock":       igClient != nil && igClient.IsMock(),
 	})
 }
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+)
+
+// --- Local adjustments (DDR-183) ---
+
+// POST /api/media/adjust
+// Body: {"sessionId": "uuid", "jobId": "enh-...", "key": "uuid/file.jpg",
+//
+//	"adjustments": {"brightness": 0.1, "contrast": 0.2, "saturation": -0.1, "rotate": 90,
+//	                "crop": {"x": 0, "y": 120, "w": 1080, "h": 1350}}}
+//
+// Applies deterministic edits to a photo of an enhancement job without
+// calling Gemini or Imagen. The enhance Lambda saves the result as the
+// item's next version, so it can be compared and rolled back like a
+// feedback round (DDR-181).
+func handleMediaAdjust(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleMediaAdjust")
+
+	if r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		SessionID   string            `json:"sessionId"`
+		JobID       string            `json:"jobId"`
+		Key         string            `json:"key"`
+		Adjustments media.Adjustments `json:"adjustments"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn().Str("param", "body").Msg("Invalid request body")
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validateSessionID(req.SessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !strings.HasPrefix(req.JobID, "enh-") || req.Key == "" {
+		httpError(w, http.StatusBadRequest, "jobId and key are required")
+		return
+	}
+	if err := req.Adjustments.Validate(); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !ensureSessionOwner(w, r, req.SessionID) {
+		return
+	}
+
+	ctx := context.Background()
+	_, item, ok := loadEnhancementItem(w, ctx, req.SessionID, req.JobID, req.Key)
+	if !ok {
+		return
+	}
+	if item.EnhancedKey == "" {
+		httpError(w, http.StatusBadRequest, "photo has no enhanced result to adjust")
+		return
+	}
+
+	payload := map[string]interface{}{
+		"type":        "enhancement-adjust",
+		"sessionId":   req.SessionID,
+		"jobId":       req.JobID,
+		"key":         item.Key,
+		"adjustments": req.Adjustments,
+	}
+	if err := invokeAsync(ctx, enhanceLambdaArn, payload); err != nil {
+		log.Error().Err(err).Str("jobId", req.JobID).Str("lambdaArn", enhanceLambdaArn).Msg("Failed to invoke enhance-lambda for adjustments")
+		httpError(w, http.StatusInternalServerError, "failed to start adjustment")
+		return
+	}
+
+	log.Info().Str("jobId", req.JobID).Str("key", item.Key).Str("adjustments", req.Adjustments.String()).Msg("Adjustment dispatched to enhance-lambda")
+	respondJSON(w, http.StatusAccepted, map[string]interface{}{
+		"status":  "processing",
+		"key":     item.Key,
+		"version": item.NextVersion(),
+	})
+}
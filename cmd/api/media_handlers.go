@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -12,9 +13,14 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
 	"github.com/rs/zerolog/log"
 )
 
+// videoPreviewPx is the width of a video preview strip: three 400px frames
+// (DDR-124).
+const videoPreviewPx = 1200
+
 // --- Media Endpoints ---
 
 // GET /api/media/thumbnail?key=sessionId/filename.jpg
@@ -38,38 +44,26 @@ func handleThumbnail(w http.ResponseWriter, r *http.Request) {
 
 	// Check for pre-generated thumbnail (DDR-030): keys under /thumbnails/ are
 	// already optimized thumbnails — serve directly from S3 without regeneration.
-	// Thumbnails are JPEG format (DDR-027: CGO_ENABLED=0 precludes WebP encoding).
 	parts := strings.SplitN(key, "/", 2)
 	if len(parts) == 2 && strings.HasPrefix(parts[1], "thumbnails/") {
-		result, err := s3Client.GetObject(context.Background(), &s3.GetObjectInput{
-			Bucket: &mediaBucket,
-			Key:    &key,
-		})
-		if err != nil {
-			log.Warn().Err(err).Str("key", key).Msg("Pre-generated thumbnail not found")
+		if !streamThumbnail(w, key) {
+			log.Warn().Str("key", key).Msg("Pre-generated thumbnail not found")
 			httpError(w, http.StatusNotFound, "thumbnail not found")
-			return
-		}
-		defer result.Body.Close()
-
-		// Determine content type from file extension
-		thumbExt := strings.ToLower(filepath.Ext(key))
-		contentType := "image/jpeg" // Default to JPEG (DDR-027: no CGO for WebP)
-		switch thumbExt {
-		case ".webp":
-			contentType = "image/webp"
-		case ".png":
-			contentType = "image/png"
 		}
-
-		w.Header().Set("Content-Type", contentType)
-		w.Header().Set("Cache-Control", "public, max-age=3600")
-		io.Copy(w, result.Body)
 		return
 	}
 
 	ext := strings.ToLower(filepath.Ext(key))
 
+	// Original key: consult the thumbnail existence index (DDR-091) so files
+	// processed by MediaProcess are served from their pre-generated thumbnail
+	// instead of being downloaded and decoded again.
+	if len(parts) == 2 && !strings.Contains(parts[1], "/") {
+		if serveIndexedThumbnail(w, parts[0], parts[1]) {
+			return
+		}
+	}
+
 	// For images, download from S3, generate thumbnail, return bytes.
 	if mime, ok := media.SupportedImageExtensions[ext]; ok {
 		tmpPath, cleanup, err := downloadFromS3(context.Background(), key)
@@ -99,8 +93,14 @@ func handleThumbnail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// For videos, return a placeholder SVG (pre-generated thumbnails are preferred; DDR-030).
-	if _, ok := media.SupportedVideoExtensions[ext]; ok {
+	// For videos, extract a frame where ffmpeg is installed (DDR-123), else
+	// return a placeholder SVG (pre-generated thumbnails are preferred; DDR-030).
+	if mime, ok := media.SupportedVideoExtensions[ext]; ok {
+		if media.IsFFmpegAvailable() {
+			if serveVideoFrame(w, key, mime) {
+				return
+			}
+		}
 		w.Header().Set("Content-Type", "image/svg+xml")
 		w.Header().Set("Cache-Control", "public, max-age=3600")
 		fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="400" height="400" viewBox="0 0 400 400">
@@ -114,6 +114,172 @@ func handleThumbnail(w http.ResponseWriter, r *http.Request) {
 	httpError(w, http.StatusBadRequest, "unsupported file type")
 }
 
+// serveVideoFrame downloads a video and responds with its thumbnail frame.
+// Returns false without writing anything if the frame cannot be produced,
+// so the caller can fall back to the placeholder.
+func serveVideoFrame(w http.ResponseWriter, key, mime string) bool {
+	tmpPath, cleanup, err := downloadFromS3(context.Background(), key)
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("Failed to download video for thumbnail")
+		return false
+	}
+	defer cleanup()
+
+	info, err := os.Stat(tmpPath)
+	if err != nil {
+		return false
+	}
+	mf := &media.MediaFile{
+		Path:     tmpPath,
+		MIMEType: mime,
+		Size:     info.Size(),
+	}
+
+	thumbData, thumbMIME, err := media.GenerateThumbnail(mf, 400)
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("Failed to extract video thumbnail frame — serving placeholder")
+		return false
+	}
+	w.Header().Set("Content-Type", thumbMIME)
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write(thumbData)
+	return true
+}
+
+// serveIndexedThumbnail streams the indexed pre-generated thumbnail for an
+// uploaded file. Returns false when the caller should fall back to
+// regeneration: nothing indexed, or the indexed object is gone from S3.
+func serveIndexedThumbnail(w http.ResponseWriter, sessionID, filename string) bool {
+	thumbKey, ok := lookupThumbnail(context.Background(), sessionID, filename)
+	if !ok {
+		return false
+	}
+
+	if streamThumbnail(w, thumbKey) {
+		return true
+	}
+
+	// Indexed but missing (e.g. thumbnails/ cleaned up by back-navigation, DDR-037).
+	log.Warn().Str("sessionId", sessionID).Str("filename", filename).Str("thumbnailKey", thumbKey).Msg("Indexed thumbnail missing from S3 — regenerating")
+	forgetThumbnailIndex(sessionID)
+	return false
+}
+
+// streamThumbnail copies a thumbnail object from S3 to the response.
+// Returns false without writing anything if the object cannot be read.
+// Thumbnails are JPEG format (DDR-027: CGO_ENABLED=0 precludes WebP encoding).
+func streamThumbnail(w http.ResponseWriter, key string) bool {
+	result, err := s3Client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: &mediaBucket,
+		Key:    &key,
+	})
+	if err != nil {
+		log.Debug().Err(err).Str("key", key).Msg("Thumbnail GetObject failed")
+		return false
+	}
+	defer result.Body.Close()
+
+	// Determine content type from file extension
+	thumbExt := strings.ToLower(filepath.Ext(key))
+	contentType := "image/jpeg" // Default to JPEG (DDR-027: no CGO for WebP)
+	switch thumbExt {
+	case ".webp":
+		contentType = "image/webp"
+	case ".png":
+		contentType = "image/png"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	io.Copy(w, result.Body)
+	return true
+}
+
+// GET /api/media/preview?key=sessionId/filename.mp4
+// Returns the frame strip for a video (DDR-124): the one MediaProcess
+// stored under {sessionId}/previews/, else one rendered on the spot where
+// ffmpeg is installed. 404 when neither is available, so the UI keeps
+// showing the thumbnail.
+func handleVideoPreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	key := r.URL.Query().Get("key")
+	if key == "" {
+		httpError(w, http.StatusBadRequest, "key is required")
+		return
+	}
+	if err := validateS3Key(key); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	parts := strings.SplitN(key, "/", 2)
+	if len(parts) != 2 || strings.Contains(parts[1], "/") {
+		httpError(w, http.StatusBadRequest, "invalid key format")
+		return
+	}
+	mime, ok := media.SupportedVideoExtensions[strings.ToLower(filepath.Ext(key))]
+	if !ok {
+		httpError(w, http.StatusBadRequest, "previews are only available for videos")
+		return
+	}
+
+	previewKey := fmt.Sprintf("%s/previews/%s.jpg", parts[0], strings.TrimSuffix(parts[1], filepath.Ext(parts[1])))
+	if streamThumbnail(w, previewKey) {
+		return
+	}
+
+	if !media.IsFFmpegAvailable() {
+		httpError(w, http.StatusNotFound, "preview not found")
+		return
+	}
+	tmpPath, cleanup, err := downloadFromS3(context.Background(), key)
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("Failed to download video for preview")
+		httpError(w, http.StatusNotFound, "file not found")
+		return
+	}
+	defer cleanup()
+
+	info, err := os.Stat(tmpPath)
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, "preview generation failed")
+		return
+	}
+	previewData, previewMIME, err := media.GenerateVideoPreview(&media.MediaFile{
+		Path:     tmpPath,
+		MIMEType: mime,
+		Size:     info.Size(),
+	}, videoPreviewPx)
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("Failed to generate video preview")
+		httpError(w, http.StatusNotFound, "preview not available")
+		return
+	}
+
+	// Store it so the next request streams instead of re-rendering.
+	sessionID, _, _ := strings.Cut(key, "/")
+	enc, err := sessionEncryption(context.Background(), sessionID)
+	if err == nil {
+		_, err = s3Client.PutObject(context.Background(), enc.Put(&s3.PutObjectInput{
+			Bucket:      &mediaBucket,
+			Key:         &previewKey,
+			Body:        bytes.NewReader(previewData),
+			ContentType: &previewMIME,
+			Tagging:     s3util.ProjectTagging(),
+		}))
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("previewKey", previewKey).Msg("Failed to store video preview")
+	}
+
+	w.Header().Set("Content-Type", previewMIME)
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write(previewData)
+}
+
 // GET /api/media/compressed?key=sessionId/filename.mp4
 // Returns a presigned GET URL for the compressed WebM video.
 // Falls back to original video if compressed version doesn't exist.
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/jobs"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Privacy redaction (DDR-185) ---
+
+// POST /api/media/redact
+// Body: {"sessionId": "uuid", "key": "uuid/enhanced/photo.jpg", "mode": "blur"}
+//
+// Dispatches a redaction job to the Enhance Lambda, which hides bystanders'
+// faces and licence plates in a copy of the photo. mode is "blur" (default)
+// or "inpaint"; inpainting falls back to blurring without Imagen.
+func handleMediaRedact(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleMediaRedact")
+
+	if r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		SessionID string `json:"sessionId"`
+		Key       string `json:"key"`
+		Mode      string `json:"mode"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validateSessionID(req.SessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !ensureSessionOwner(w, r, req.SessionID) {
+		return
+	}
+	if err := validateCropKey(req.SessionID, req.Key); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	mode, err := ai.ParseRedactMode(req.Mode)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+
+	ctx := context.Background()
+	jobID := jobs.GenerateID("redact-")
+	job := &store.RedactJob{ID: jobID, Status: "pending", Key: req.Key, Mode: string(mode)}
+	if err := sessionStore.PutRedactJob(ctx, req.SessionID, job); err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to persist pending redact job")
+		httpError(w, http.StatusInternalServerError, "failed to create job")
+		return
+	}
+
+	payload := map[string]interface{}{
+		"type":      "media-redact",
+		"sessionId": req.SessionID,
+		"jobId":     jobID,
+		"key":       req.Key,
+		"mode":      string(mode),
+	}
+	if err := invokeAsync(ctx, enhanceLambdaArn, payload); err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Str("lambdaArn", enhanceLambdaArn).Msg("Failed to invoke enhance-lambda for redaction")
+		job.Status = "error"
+		job.Error = fmt.Sprintf("failed to start processing: %v", err)
+		sessionStore.PutRedactJob(ctx, req.SessionID, job)
+		httpError(w, http.StatusInternalServerError, job.Error)
+		return
+	}
+	log.Info().Str("jobId", jobID).Str("sessionId", req.SessionID).Str("mode", string(mode)).Msg("Job dispatched to enhance-lambda (redaction)")
+
+	respondJSON(w, http.StatusAccepted, map[string]string{"id": jobID})
+}
+
+func handleMediaRedactRoutes(w http.ResponseWriter, r *http.Request) {
+	jobID, action, ok := jobs.ParseRoute(r.URL.Path, "/api/media/redact/", "redact-")
+	if !ok {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	switch action {
+	case "results":
+		handleMediaRedactResults(w, r, jobID)
+	default:
+		httpError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// GET /api/media/redact/{id}/results?sessionId=...
+func handleMediaRedactResults(w http.ResponseWriter, r *http.Request, jobID string) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("jobId", jobID).Msg("Handler entry: handleMediaRedactResults")
+
+	if r.Method != http.MethodGet {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	sessionID := r.URL.Query().Get("sessionId")
+	if err := validateSessionID(sessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !ensureSessionOwner(w, r, sessionID) {
+		return
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+
+	job, err := sessionStore.GetRedactJob(r.Context(), sessionID, jobID)
+	if err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to read redact job")
+		httpError(w, http.StatusInternalServerError, "failed to read job status")
+		return
+	}
+	if job == nil {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if job.Regions == nil {
+		job.Regions = []store.RedactRegion{}
+	}
+	respondJSON(w, http.StatusOK, job)
+}
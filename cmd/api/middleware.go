@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/awslabs/aws-lambda-go-api-proxy/core"
+	"github.com/fpang/ai-social-media-helper/internal/logging"
 	"github.com/fpang/ai-social-media-helper/internal/metrics"
 	"github.com/rs/zerolog/log"
 )
@@ -89,6 +90,9 @@ func withMetrics(next http.Handler) http.Handler {
 		start := time.Now()
 		sr := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
 
+		// DDR-111: Outbound Gemini/Instagram calls log the API Gateway request ID.
+		r = r.WithContext(logging.WithRequestID(r.Context(), r.Header.Get("X-Amzn-Requestid")))
+
 		next.ServeHTTP(sr, r)
 
 		elapsed := time.Since(start)
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/jobs"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Stylized cover variants (DDR-102) ---
+
+// POST /api/mood-variants/start
+// Body: {"sessionId": "uuid", "key": "uuid/enhanced/cover.jpg", "styles": ["illustration", "poster"]}
+//
+// Reserves one unit of the caller's daily budget per style, then dispatches
+// the job to the Enhance Lambda, which releases the units of styles that fail.
+// Returns 404 when the feature flag is off and 429 when the budget would be
+// exceeded.
+func handleMoodVariantStart(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleMoodVariantStart")
+
+	if !moodVariantsEnabled {
+		httpError(w, http.StatusNotFound, "mood variants are not enabled")
+		return
+	}
+	if r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		SessionID string   `json:"sessionId"`
+		Key       string   `json:"key"`
+		Styles    []string `json:"styles"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validateSessionID(req.SessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !ensureSessionOwner(w, r, req.SessionID) {
+		return
+	}
+	if err := validateS3Key(req.Key); err != nil {
+		httpError(w, http.StatusBadRequest, fmt.Sprintf("invalid key: %s", err.Error()))
+		return
+	}
+	if !strings.HasPrefix(req.Key, req.SessionID+"/") {
+		httpError(w, http.StatusBadRequest, "key does not belong to session")
+		return
+	}
+	if _, ok := media.SupportedImageExtensions[strings.ToLower(filepath.Ext(req.Key))]; !ok {
+		httpError(w, http.StatusBadRequest, "cover must be an image")
+		return
+	}
+	styles, err := ai.ParseMoodStyles(req.Styles)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+
+	ctx := context.Background()
+	owner := getUserSub(r)
+	if owner == "" {
+		owner = req.SessionID
+	}
+	used, err := sessionStore.ReserveDailyBudget(ctx, owner, store.MoodVariantBudget, len(styles), moodVariantsDailyLimit)
+	if errors.Is(err, store.ErrBudgetExhausted) {
+		log.Info().Str("sessionId", req.SessionID).Int("requested", len(styles)).Int("limit", moodVariantsDailyLimit).Msg("Mood variant budget exhausted")
+		httpError(w, http.StatusTooManyRequests, fmt.Sprintf("daily limit of %d AI-generated images reached", moodVariantsDailyLimit))
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("sessionId", req.SessionID).Msg("Failed to reserve mood variant budget")
+		httpError(w, http.StatusInternalServerError, "failed to create job")
+		return
+	}
+
+	jobID := jobs.GenerateID("mood-")
+	job := &store.MoodVariantJob{ID: jobID, Status: "pending", SourceKey: req.Key}
+	if err := sessionStore.PutMoodVariantJob(ctx, req.SessionID, job); err != nil {
+		sessionStore.ReleaseDailyBudget(ctx, owner, store.MoodVariantBudget, len(styles))
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to persist pending mood variant job")
+		httpError(w, http.StatusInternalServerError, "failed to create job")
+		return
+	}
+
+	payload := map[string]interface{}{
+		"type":      "mood-variants",
+		"sessionId": req.SessionID,
+		"jobId":     jobID,
+		"sourceKey": req.Key,
+		"styles":    req.Styles,
+		"owner":     owner,
+	}
+	if err := invokeAsync(ctx, enhanceLambdaArn, payload); err != nil {
+		sessionStore.ReleaseDailyBudget(ctx, owner, store.MoodVariantBudget, len(styles))
+		log.Error().Err(err).Str("jobId", jobID).Str("lambdaArn", enhanceLambdaArn).Msg("Failed to invoke enhance-lambda for mood variants")
+		job.Status = "error"
+		job.Error = fmt.Sprintf("failed to start processing: %v", err)
+		sessionStore.PutMoodVariantJob(ctx, req.SessionID, job)
+		httpError(w, http.StatusInternalServerError, job.Error)
+		return
+	}
+	log.Info().Str("jobId", jobID).Str("sessionId", req.SessionID).Strs("styles", req.Styles).Int("budgetUsed", used).Msg("Job dispatched to enhance-lambda (mood variants)")
+
+	respondJSON(w, http.StatusAccepted, map[string]interface{}{
+		"id":        jobID,
+		"remaining": moodVariantsDailyLimit - used,
+	})
+}
+
+func handleMoodVariantRoutes(w http.ResponseWriter, r *http.Request) {
+	jobID, action, ok := jobs.ParseRoute(r.URL.Path, "/api/mood-variants/", "mood-")
+	if !ok {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	switch action {
+	case "results":
+		handleMoodVariantResults(w, r, jobID)
+	default:
+		httpError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// GET /api/mood-variants/{id}/results?sessionId=...
+// Results stay readable with the flag off so in-flight jobs can finish.
+func handleMoodVariantResults(w http.ResponseWriter, r *http.Request, jobID string) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("jobId", jobID).Msg("Handler entry: handleMoodVariantResults")
+
+	if r.Method != http.MethodGet {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	sessionID := r.URL.Query().Get("sessionId")
+	if err := validateSessionID(sessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !ensureSessionOwner(w, r, sessionID) {
+		return
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+
+	job, err := sessionStore.GetMoodVariantJob(r.Context(), sessionID, jobID)
+	if err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to read mood variant job")
+		httpError(w, http.StatusInternalServerError, "failed to read job status")
+		return
+	}
+	if job == nil {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	variants := make([]map[string]interface{}, 0, len(job.Variants))
+	for _, v := range job.Variants {
+		out := map[string]interface{}{
+			"style":       v.Style,
+			"aiGenerated": true,
+		}
+		if v.Key != "" {
+			out["key"] = v.Key
+		}
+		if v.ThumbKey != "" {
+			out["thumbnailUrl"] = fmt.Sprintf("/api/media/thumbnail?key=%s", v.ThumbKey)
+		}
+		if v.Error != "" {
+			out["error"] = v.Error
+		}
+		variants = append(variants, out)
+	}
+
+	resp := map[string]interface{}{
+		"id":        job.ID,
+		"status":    job.Status,
+		"sourceKey": job.SourceKey,
+		"variants":  variants,
+	}
+	if job.Error != "" {
+		resp["error"] = job.Error
+	}
+	if job.Telemetry != nil {
+		resp["perJobTelemetry"] = job.Telemetry // DDR-118
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
@@ -2,11 +2,16 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"path/filepath"
 	"strings"
-	"time"
 
+	"github.com/fpang/ai-social-media-helper/internal/decisions"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
 	"github.com/fpang/ai-social-media-helper/internal/rag"
+	"github.com/fpang/ai-social-media-helper/internal/store"
 	"github.com/rs/zerolog/log"
 )
 
@@ -80,32 +85,15 @@ func handleOverrideAction(w http.ResponseWriter, r *http.Request, sessionID stri
 		return
 	}
 
-	userID := getUserSub(r)
-	aiVerdict := "excluded"
-	if req.Action == "removed" {
-		aiVerdict = "selected"
-	}
-
-	feedback := rag.ContentFeedback{
-		EventType:   rag.EventOverrideAction,
-		SessionID:   sessionID,
-		Timestamp:   time.Now().UTC().Format(time.RFC3339),
-		UserID:      userID,
-		MediaKey:    req.MediaKey,
-		MediaType:   req.MediaType,
-		UserVerdict: req.Action,
-		AIVerdict:   aiVerdict,
-		Reason:      req.AIReason,
-		IsOverride:  true,
-		Metadata: map[string]string{
-			"filename": req.Filename,
-		},
-	}
-
-	if ebClient != nil {
-		if err := rag.EmitContentFeedback(r.Context(), ebClient, feedback); err != nil {
-			log.Warn().Err(err).Str("sessionId", sessionID).Str("mediaKey", req.MediaKey).Msg("Failed to emit override action to EventBridge (best effort)")
-		}
+	dw := decisions.NewWriter(decisions.EventBridge(ebClient), decisions.Job{
+		SessionID: sessionID, UserID: rag.UserID(getUserSub(r), sessionID),
+	})
+	dw.RecordOverride(decisions.Override{
+		MediaKey: req.MediaKey, Filename: req.Filename, MediaType: req.MediaType,
+		Action: req.Action, Reason: req.AIReason,
+	})
+	if err := dw.Flush(r.Context()); err != nil {
+		log.Warn().Err(err).Str("sessionId", sessionID).Str("mediaKey", req.MediaKey).Msg("Failed to record override action (best effort)")
 	}
 
 	respondJSON(w, http.StatusOK, map[string]bool{"ok": true})
@@ -139,51 +127,151 @@ func handleOverrideFinalize(w http.ResponseWriter, r *http.Request, sessionID st
 		return
 	}
 
-	userID := getUserSub(r)
+	dw := decisions.NewWriter(decisions.EventBridge(ebClient), decisions.Job{
+		SessionID: sessionID, UserID: rag.UserID(getUserSub(r), sessionID),
+	})
+	for _, item := range req.Added {
+		dw.RecordOverride(decisions.Override{
+			MediaKey: item.MediaKey, Filename: item.Filename, Action: "added_back",
+			Reason: item.AIReason, Finalized: true,
+		})
+	}
+	for _, item := range req.Removed {
+		dw.RecordOverride(decisions.Override{
+			MediaKey: item.MediaKey, Filename: item.Filename, Action: "removed",
+			Reason: item.AIReason, Finalized: true,
+		})
+	}
+	if err := dw.Flush(r.Context()); err != nil {
+		log.Warn().Err(err).Str("sessionId", sessionID).Msg("failed to flush override finalize batch")
+	}
+
+	respondJSON(w, http.StatusOK, map[string]bool{"ok": true})
+}
+
+// handleTriageOverride records the keep/discard verdicts the user reversed
+// during triage review (DDR-100). Each override becomes a triage.override
+// feedback event, which rag-profile-lambda writes to both triage_decisions
+// (replacing the AI verdict) and override_decisions.
+// POST /api/triage/{id}/override
+// Body: {"sessionId": "...", "kept": ["key", ...], "discarded": ["key", ...]}
+// kept lists AI discards the user kept; discarded lists AI keeps the user discarded.
+func handleTriageOverride(w http.ResponseWriter, r *http.Request, jobID string) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("jobId", jobID).Msg("Handler entry: handleTriageOverride")
+
+	if r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		SessionID string   `json:"sessionId"`
+		Kept      []string `json:"kept"`
+		Discarded []string `json:"discarded"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validateSessionID(req.SessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !ensureSessionOwner(w, r, req.SessionID) {
+		return
+	}
+
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
 	ctx := r.Context()
+	job, err := sessionStore.GetTriageJob(ctx, req.SessionID, jobID)
+	if err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to read triage job")
+		httpError(w, http.StatusInternalServerError, "failed to read job")
+		return
+	}
+	if job == nil {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if job.Status != "complete" {
+		httpError(w, http.StatusConflict, "triage job is not complete")
+		return
+	}
 
-	if ebClient != nil {
-		batcher := rag.NewBatchEmitter(ebClient)
-		for _, item := range req.Added {
-			batcher.Add(rag.ContentFeedback{
-				EventType:   rag.EventOverridesFinalized,
-				SessionID:   sessionID,
-				Timestamp:   time.Now().UTC().Format(time.RFC3339),
-				UserID:      userID,
-				MediaKey:    item.MediaKey,
-				UserVerdict: "added_back",
-				AIVerdict:   "excluded",
-				Reason:      item.AIReason,
-				IsOverride:  true,
-				Metadata: map[string]string{
-					"filename":  item.Filename,
-					"action":    "added_back",
-					"finalized": "true",
-				},
-			})
-		}
-		for _, item := range req.Removed {
-			batcher.Add(rag.ContentFeedback{
-				EventType:   rag.EventOverridesFinalized,
-				SessionID:   sessionID,
-				Timestamp:   time.Now().UTC().Format(time.RFC3339),
-				UserID:      userID,
-				MediaKey:    item.MediaKey,
-				UserVerdict: "removed",
-				AIVerdict:   "selected",
-				Reason:      item.AIReason,
-				IsOverride:  true,
-				Metadata: map[string]string{
-					"filename":  item.Filename,
-					"action":    "removed",
-					"finalized": "true",
-				},
-			})
-		}
-		if err := batcher.Flush(ctx); err != nil {
-			log.Warn().Err(err).Str("sessionId", sessionID).Msg("failed to flush override finalize batch")
-		}
+	overrides, err := job.Overrides(req.Kept, req.Discarded)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(overrides) == 0 {
+		respondJSON(w, http.StatusOK, map[string]int{"recorded": 0})
+		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]bool{"ok": true})
+	if job.OverridesRecordedAt != 0 {
+		httpError(w, http.StatusConflict, "overrides already recorded for this job")
+		return
+	}
+
+	dw := decisions.NewWriter(decisions.EventBridge(ebClient), decisions.Job{
+		SessionID: req.SessionID, JobID: jobID, UserID: rag.UserID(getUserSub(r), req.SessionID),
+	})
+	for _, o := range overrides {
+		dw.RecordTriage(decisions.Triage{
+			MediaKey: o.Item.Key, Filename: o.Item.Filename,
+			AIKeep: !o.UserKeep, UserKeep: o.UserKeep, Reason: o.Item.Reason,
+		})
+	}
+	if err := dw.Flush(ctx); err != nil {
+		// Not marked recorded, so the client can retry.
+		log.Error().Err(err).Str("sessionId", req.SessionID).Str("jobId", jobID).Msg("Failed to send triage overrides")
+		httpError(w, http.StatusInternalServerError, "failed to record overrides")
+		return
+	}
+
+	// Marked only once sent; a concurrent request that got here first wins.
+	err = sessionStore.MarkTriageOverridesRecorded(ctx, req.SessionID, jobID)
+	if errors.Is(err, store.ErrStatusConflict) {
+		httpError(w, http.StatusConflict, "overrides already recorded for this job")
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to mark triage overrides recorded")
+		httpError(w, http.StatusInternalServerError, "failed to record overrides")
+		return
+	}
+
+	emitVideoOverrideMetrics(job, overrides)
+
+	log.Info().Str("sessionId", req.SessionID).Str("jobId", jobID).Int("kept", len(req.Kept)).Int("discarded", len(req.Discarded)).Msg("Triage overrides recorded")
+	respondJSON(w, http.StatusOK, map[string]int{"recorded": len(overrides)})
+}
+
+// emitVideoOverrideMetrics counts the video verdicts the user reversed,
+// by the job's video mode, so keyframe triage can be compared with whole
+// clips on how often it gets videos wrong (DDR-186).
+func emitVideoOverrideMetrics(job *store.TriageJob, overrides []store.TriageOverride) {
+	if job.VideoStats == nil {
+		return
+	}
+	videos := 0
+	for _, o := range overrides {
+		if media.IsVideo(filepath.Ext(o.Item.Filename)) {
+			videos++
+		}
+	}
+	mode := "whole"
+	if job.Keyframes > 0 {
+		mode = "keyframes"
+	}
+	metrics.New("AiSocialMedia").
+		Dimension("JobType", "triage").
+		Dimension("VideoMode", mode).
+		Metric("VideoOverrides", float64(videos), metrics.UnitCount).
+		Metric("VideosTriaged", float64(job.VideoStats.Videos), metrics.UnitCount).
+		Property("jobId", job.ID).
+		Flush()
 }
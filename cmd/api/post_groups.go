@@ -0,0 +1,384 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/jobs"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Post groups (DDR-178) ---
+
+// maxPostGroupName is the longest post group name accepted.
+const maxPostGroupName = 80
+
+// postGroupRequest is the body of the post group endpoints; each uses the
+// fields it needs.
+type postGroupRequest struct {
+	SessionID string   `json:"sessionId"`
+	Name      string   `json:"name"`
+	Keys      []string `json:"keys"`
+	At        int      `json:"at"`       // split
+	SourceID  string   `json:"sourceId"` // merge
+
+	TripContext string `json:"tripContext"` // suggest-order (DDR-179)
+}
+
+// GET  /api/groups?sessionId=... — list the session's post groups in order
+// POST /api/groups — create a post group
+// Body: {"sessionId": "uuid", "name": "Day 1 — Lisbon", "keys": ["uuid/a.jpg", ...]}
+//
+// A key belongs to at most one group, and a group holds at most 20 items.
+// The name defaults to "Post N".
+func handlePostGroups(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handlePostGroups")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		groups, ok := loadPostGroups(w, r, r.URL.Query().Get("sessionId"))
+		if !ok {
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]interface{}{"groups": groups})
+		return
+	}
+
+	var req postGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn().Str("param", "body").Msg("Invalid request body")
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	groups, ok := loadPostGroups(w, r, req.SessionID)
+	if !ok {
+		return
+	}
+	name, err := postGroupName(req.Name, fmt.Sprintf("Post %d", len(groups)+1))
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !checkPostGroupKeys(w, groups, req.SessionID, req.Keys) {
+		return
+	}
+
+	group := &store.PostGroup{
+		ID:        jobs.GenerateID("grp-"),
+		Name:      name,
+		MediaKeys: req.Keys,
+		Position:  len(groups),
+		CreatedAt: time.Now().Unix(),
+	}
+	if !savePostGroups(w, r, req.SessionID, group) {
+		return
+	}
+	log.Info().Str("sessionId", req.SessionID).Str("groupId", group.ID).Int("items", len(group.MediaKeys)).Msg("Post group created")
+	respondJSON(w, http.StatusCreated, group)
+}
+
+// DELETE /api/groups/{id}?sessionId=...
+// POST   /api/groups/{id}/rename  Body: {"sessionId": "uuid", "name": "..."}
+// POST   /api/groups/{id}/reorder Body: {"sessionId": "uuid", "keys": [all of the group's keys, in the new order]}
+// POST   /api/groups/{id}/split   Body: {"sessionId": "uuid", "at": 3, "name": "optional"}
+// POST   /api/groups/{id}/merge   Body: {"sessionId": "uuid", "sourceId": "grp-..."}
+// POST   /api/groups/{id}/suggest-order Body: {"sessionId": "uuid", "tripContext": "optional"}
+//
+// rename and reorder respond with the group. split moves the items from
+// index at onward into a new group placed right after it; merge appends
+// group sourceId's items to the group and deletes sourceId. Both, and
+// delete, respond with the session's groups.
+func handlePostGroupRoutes(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodDelete {
+		handlePostGroupDelete(w, r)
+		return
+	}
+	groupID, action, ok := jobs.ParseRoute(r.URL.Path, "/api/groups/", "grp-")
+	if !ok {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("groupId", groupID).Msg("Handler entry: handlePostGroupRoutes")
+
+	switch action {
+	case "rename", "reorder", "split", "merge", "suggest-order":
+	default:
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req postGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn().Str("param", "body").Msg("Invalid request body")
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	groups, ok := loadPostGroups(w, r, req.SessionID)
+	if !ok {
+		return
+	}
+	group := findPostGroup(groups, groupID)
+	if group == nil {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	switch action {
+	case "rename":
+		name, err := postGroupName(req.Name, "")
+		if err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		group.Name = name
+		if !savePostGroups(w, r, req.SessionID, group) {
+			return
+		}
+		respondJSON(w, http.StatusOK, group)
+
+	case "reorder":
+		if err := store.ReorderPostGroup(group, req.Keys); err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if !savePostGroups(w, r, req.SessionID, group) {
+			return
+		}
+		respondJSON(w, http.StatusOK, group)
+
+	case "split":
+		name, err := postGroupName(req.Name, group.Name+" (2)")
+		if err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		tail := &store.PostGroup{ID: jobs.GenerateID("grp-"), Name: name, CreatedAt: time.Now().Unix()}
+		ordered, edit, err := store.SplitPostGroupEdit(groups, group, tail, req.At)
+		if err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if !applyPostGroupEdit(w, r, req.SessionID, edit) {
+			return
+		}
+		log.Info().Str("sessionId", req.SessionID).Str("groupId", group.ID).Str("newGroupId", tail.ID).Int("at", req.At).Msg("Post group split")
+		respondJSON(w, http.StatusOK, map[string]interface{}{"groups": ordered})
+
+	case "merge":
+		src := findPostGroup(groups, strings.TrimSpace(req.SourceID))
+		if src == nil {
+			httpError(w, http.StatusBadRequest, "sourceId is not a post group of this session")
+			return
+		}
+		remaining, edit, err := store.MergePostGroupEdit(groups, group, src)
+		if err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if !applyPostGroupEdit(w, r, req.SessionID, edit) {
+			return
+		}
+		log.Info().Str("sessionId", req.SessionID).Str("groupId", group.ID).Str("sourceId", src.ID).Msg("Post groups merged")
+		respondJSON(w, http.StatusOK, map[string]interface{}{"groups": remaining})
+
+	case "suggest-order":
+		startOrderSuggestion(w, r, req, group)
+	}
+}
+
+// startOrderSuggestion asks the Description Lambda to suggest the group's
+// carousel order (DDR-179). The group's orderSuggestion reads "processing"
+// until the suggestion is recorded; poll GET /api/groups. The suggestion is
+// applied with reorder, or at publish time with useSuggestedOrder.
+func startOrderSuggestion(w http.ResponseWriter, r *http.Request, req postGroupRequest, group *store.PostGroup) {
+	if len(group.MediaKeys) < 2 {
+		httpError(w, http.StatusBadRequest, "a post group needs at least two items to order")
+		return
+	}
+	if s := group.OrderSuggestion; s != nil && s.Status == "processing" {
+		httpError(w, http.StatusConflict, "an order suggestion is already in progress")
+		return
+	}
+
+	group.OrderSuggestion = &store.CarouselOrderSuggestion{Status: "processing", CreatedAt: time.Now().Unix()}
+	if !savePostGroups(w, r, req.SessionID, group) {
+		return
+	}
+	payload := map[string]interface{}{
+		"type":        "carousel-order",
+		"sessionId":   req.SessionID,
+		"groupId":     group.ID,
+		"keys":        group.MediaKeys,
+		"groupLabel":  group.Name,
+		"tripContext": strings.TrimSpace(req.TripContext),
+	}
+	if err := invokeAsync(r.Context(), descriptionLambdaArn, payload); err != nil {
+		log.Error().Err(err).Str("groupId", group.ID).Str("lambdaArn", descriptionLambdaArn).Msg("Failed to invoke description-lambda for carousel order")
+		group.OrderSuggestion = &store.CarouselOrderSuggestion{Status: "error", Error: "failed to start processing", CreatedAt: time.Now().Unix()}
+		sessionStore.PutPostGroup(r.Context(), req.SessionID, group)
+		httpError(w, http.StatusInternalServerError, "failed to start order suggestion")
+		return
+	}
+	log.Info().Str("sessionId", req.SessionID).Str("groupId", group.ID).Int("items", len(group.MediaKeys)).Msg("Carousel order suggestion dispatched to description-lambda")
+	respondJSON(w, http.StatusAccepted, group)
+}
+
+// DELETE /api/groups/{id}?sessionId=...
+func handlePostGroupDelete(w http.ResponseWriter, r *http.Request) {
+	groupID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/groups/"), "/")
+	if !strings.HasPrefix(groupID, "grp-") || strings.Contains(groupID, "/") {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("groupId", groupID).Msg("Handler entry: handlePostGroupDelete")
+
+	sessionID := r.URL.Query().Get("sessionId")
+	groups, ok := loadPostGroups(w, r, sessionID)
+	if !ok {
+		return
+	}
+	if findPostGroup(groups, groupID) == nil {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	remaining, edit := store.DeletePostGroupEdit(groups, groupID)
+	if !applyPostGroupEdit(w, r, sessionID, edit) {
+		return
+	}
+	log.Info().Str("sessionId", sessionID).Str("groupId", groupID).Msg("Post group deleted")
+	respondJSON(w, http.StatusOK, map[string]interface{}{"groups": remaining})
+}
+
+// loadPostGroups validates sessionID and its owner and returns the
+// session's groups in order, writing an error response on failure.
+func loadPostGroups(w http.ResponseWriter, r *http.Request, sessionID string) ([]*store.PostGroup, bool) {
+	if err := validateSessionID(sessionID); err != nil {
+		log.Warn().Str("param", "sessionId").Msg("SessionId validation failed")
+		httpError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	if !ensureSessionOwner(w, r, sessionID) {
+		return nil, false
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return nil, false
+	}
+	groups, err := sessionStore.GetPostGroups(r.Context(), sessionID)
+	if err != nil {
+		log.Error().Err(err).Str("sessionId", sessionID).Msg("Failed to read post groups")
+		httpError(w, http.StatusInternalServerError, "failed to read post groups")
+		return nil, false
+	}
+	return groups, true
+}
+
+// checkPostGroupKeys writes a 400 response unless keys are valid keys of
+// the session that can make up a new group.
+func checkPostGroupKeys(w http.ResponseWriter, groups []*store.PostGroup, sessionID string, keys []string) bool {
+	for _, key := range keys {
+		if err := validateS3Key(key); err != nil || !strings.HasPrefix(key, sessionID+"/") {
+			log.Warn().Str("param", "keys").Str("key", key).Msg("Invalid S3 key")
+			httpError(w, http.StatusBadRequest, fmt.Sprintf("invalid key: %s", key))
+			return false
+		}
+	}
+	if err := store.CheckPostGroupKeys(groups, "", keys); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+// postGroupName trims name, using def when it is empty. An empty result or
+// one longer than maxPostGroupName is an error.
+func postGroupName(name, def string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = def
+	}
+	if name == "" {
+		return "", fmt.Errorf("name is required")
+	}
+	if len([]rune(name)) > maxPostGroupName {
+		return "", fmt.Errorf("name must be at most %d characters", maxPostGroupName)
+	}
+	return name, nil
+}
+
+// savePostGroups writes groups, writing an error response on failure.
+func savePostGroups(w http.ResponseWriter, r *http.Request, sessionID string, groups ...*store.PostGroup) bool {
+	for _, g := range groups {
+		if err := sessionStore.PutPostGroup(r.Context(), sessionID, g); err != nil {
+			log.Error().Err(err).Str("groupId", g.ID).Msg("Failed to save post group")
+			httpError(w, http.StatusInternalServerError, "failed to save post group")
+			return false
+		}
+	}
+	return true
+}
+
+// applyPostGroupEdit applies an edit that spans several groups in one
+// transaction, writing an error response on failure.
+func applyPostGroupEdit(w http.ResponseWriter, r *http.Request, sessionID string, edit store.PostGroupEdit) bool {
+	if err := sessionStore.ApplyPostGroupEdit(r.Context(), sessionID, edit); err != nil {
+		log.Error().Err(err).Str("sessionId", sessionID).Msg("Failed to save post groups")
+		httpError(w, http.StatusInternalServerError, "failed to save post groups")
+		return false
+	}
+	return true
+}
+
+func findPostGroup(groups []*store.PostGroup, id string) *store.PostGroup {
+	for _, g := range groups {
+		if g.ID == id {
+			return g
+		}
+	}
+	return nil
+}
+
+// requestPostGroup returns the stored post group a job request names in
+// groupId, so the job can take its keys and name (DDR-178). IDs that are
+// not stored groups' (without the "grp-" prefix, such as the web app's
+// own) yield nil. An unknown "grp-" ID writes a 400 response.
+func requestPostGroup(w http.ResponseWriter, r *http.Request, sessionID, groupID string) (*store.PostGroup, bool) {
+	if !strings.HasPrefix(groupID, "grp-") || sessionStore == nil {
+		return nil, true
+	}
+	groups, ok := loadPostGroups(w, r, sessionID)
+	if !ok {
+		return nil, false
+	}
+	group := findPostGroup(groups, groupID)
+	if group == nil {
+		log.Warn().Str("param", "groupId").Str("groupId", groupID).Msg("Unknown post group")
+		httpError(w, http.StatusBadRequest, "groupId is not a post group of this session")
+		return nil, false
+	}
+	return group, true
+}
+
+// notePostGroupJob records a job started from group, if there is one. A
+// failure is logged; the job runs regardless.
+func notePostGroupJob(r *http.Request, sessionID string, group *store.PostGroup, note func(*store.PostGroup)) {
+	if group == nil {
+		return
+	}
+	note(group)
+	if err := sessionStore.PutPostGroup(r.Context(), sessionID, group); err != nil {
+		log.Warn().Err(err).Str("groupId", group.ID).Msg("Failed to record the job on its post group")
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/store"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Provenance records (DDR-140) ---
+
+// GET  /api/provenance — whether the caller's edited photos carry a provenance record
+// POST /api/provenance — turn it on or off
+// Body: {"enabled": false}
+//
+// The setting belongs to the user and is on until turned off. Enhancement
+// and publish read it when they write a photo.
+func handleProvenance(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleProvenance")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	owner, ok := signedInUser(w, r)
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		settings, err := sessionStore.GetProvenanceSettings(r.Context(), owner)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to read provenance setting")
+			httpError(w, http.StatusInternalServerError, "failed to read provenance setting")
+			return
+		}
+		respondJSON(w, http.StatusOK, settings)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<10)
+	var req struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Enabled == nil {
+		httpError(w, http.StatusBadRequest, "enabled is required")
+		return
+	}
+	settings := &store.ProvenanceSettings{Enabled: *req.Enabled, UpdatedAt: time.Now().Unix()}
+	if err := sessionStore.PutProvenanceSettings(r.Context(), owner, settings); err != nil {
+		log.Error().Err(err).Msg("Failed to save provenance setting")
+		httpError(w, http.StatusInternalServerError, "failed to save provenance setting")
+		return
+	}
+	log.Info().Bool("enabled", settings.Enabled).Msg("Provenance setting saved")
+	respondJSON(w, http.StatusOK, settings)
+}
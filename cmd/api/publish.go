@@ -2,14 +2,26 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"path"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sfn"
+	"github.com/fpang/ai-social-media-helper/internal/brand"
+	"github.com/fpang/ai-social-media-helper/internal/instagram"
 	"github.com/fpang/ai-social-media-helper/internal/jobs"
+	"github.com/fpang/ai-social-media-helper/internal/mastodon"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/sfnevents"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 	"github.com/rs/zerolog/log"
 )
@@ -17,7 +29,43 @@ import (
 // --- Publish Endpoints (DDR-040, DDR-050, DDR-052: DynamoDB + Step Functions) ---
 
 // POST /api/publish/start
-// Body: {"sessionId": "uuid", "groupId": "group-1", "keys": [...], "caption": "...", "hashtags": [...]}
+// Body: {"sessionId": "uuid", "groupId": "group-1", "keys": [...], "caption": "...", "hashtags": [...], "locationId": "", "userTags": [[{"username": "jane", "x": 0.5, "y": 0.4}], []], "collaborators": ["sam"], "requireApproval": false, "watermark": false, "dryRun": false, "platform": "instagram", "brandKit": false, "altText": ["..."], "descriptionJobId": "", "selectionJobId": "", "platforms": ["instagram", "mastodon"], "captions": {"mastodon": "..."}, "hashtagsInComment": false}
+//
+// With requireApproval the job stops before finalizing until someone signs off
+// (DDR-121); the response then carries the approvalToken for the sign-off link.
+// With watermark the caller's watermark is stamped on every photo (DDR-133).
+// A locationId from GET /api/instagram/locations tags the post (DDR-138).
+// userTags tag accounts on each item, in the order of keys; collaborators
+// are invited to co-author the post (DDR-141).
+// With dryRun the pipeline runs against a simulated Instagram and nothing is
+// posted (DDR-139).
+// With publishAt (RFC 3339) a signed-in caller schedules the post instead of
+// publishing now; the publish scheduler starts the pipeline at that time
+// (DDR-144). The job reports status "scheduled" until then.
+// platform "facebook" posts to the deployment's Facebook Page instead of
+// Instagram (DDR-147); Page posts take no userTags or collaborators, and
+// an album holds photos only.
+// With brandKit the caller's brand kit sign-off ends the caption, and its
+// hashtags are used when the request has none (DDR-149).
+// platform "mastodon" posts to the deployment's Mastodon-compatible server,
+// Pixelfed included (DDR-151): up to 4 photos or a single video, with no
+// userTags, collaborators or locationId.
+// altText describes each item for screen readers, in the order of keys, and
+// is sent to every platform (DDR-154). Without it, descriptionJobId takes
+// the alt-text that description job generated, and selectionJobId fills the
+// photos still missing one from that selection job.
+// platforms, in place of platform, cross-posts the same items to several
+// platforms in one job (DDR-153). captions holds per-platform caption
+// variants; platforms without one use caption. Each platform's settings go
+// to it alone, and the job reports each platform's outcome, publishing
+// where it can when another platform fails.
+// With hashtagsInComment the Instagram caption goes out without its
+// hashtags, which are posted as the first comment once the post is live
+// (DDR-163); other platforms keep them in the caption.
+// A groupId naming a stored post group ("grp-...") supplies the keys when
+// the request omits them (DDR-178). With useSuggestedOrder the publish
+// pipeline posts the items in that group's AI-suggested carousel order
+// (DDR-179); keys, userTags and altText are given in their usual order.
 func handlePublishStart(w http.ResponseWriter, r *http.Request) {
 	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handlePublishStart")
 
@@ -27,19 +75,33 @@ func handlePublishStart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if igClient == nil {
-		log.Debug().Msg("Instagram client not configured")
-		httpError(w, http.StatusServiceUnavailable, "Instagram publishing is not configured — set INSTAGRAM_ACCESS_TOKEN and INSTAGRAM_USER_ID")
-		return
-	}
-	log.Debug().Msg("Instagram client check passed")
-
 	var req struct {
 		SessionID string   `json:"sessionId"`
 		GroupID   string   `json:"groupId"`
 		Keys      []string `json:"keys"`
 		Caption   string   `json:"caption"`
 		Hashtags  []string `json:"hashtags"`
+
+		LocationID      string                `json:"locationId"` // DDR-138
+		UserTags        [][]instagram.UserTag `json:"userTags"`   // DDR-141
+		Collaborators   []string              `json:"collaborators"`
+		RequireApproval bool                  `json:"requireApproval"`
+		Watermark       bool                  `json:"watermark"`
+		DryRun          bool                  `json:"dryRun"`    // DDR-139
+		PublishAt       string                `json:"publishAt"` // DDR-144
+		Platform        string                `json:"platform"`  // DDR-147
+		BrandKit        bool                  `json:"brandKit"`  // DDR-149
+
+		AltText          []string `json:"altText"`          // DDR-154
+		DescriptionJobID string   `json:"descriptionJobId"` // DDR-154
+		SelectionJobID   string   `json:"selectionJobId"`   // DDR-154
+
+		Platforms []string          `json:"platforms"` // DDR-153
+		Captions  map[string]string `json:"captions"`  // DDR-153: by platform
+
+		HashtagsInComment bool `json:"hashtagsInComment"` // DDR-163
+
+		UseSuggestedOrder bool `json:"useSuggestedOrder"` // DDR-179
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Debug().Err(err).Msg("Request body decoding failed")
@@ -47,7 +109,61 @@ func handlePublishStart(w http.ResponseWriter, r *http.Request) {
 		httpError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
-	log.Debug().Str("sessionId", req.SessionID).Str("groupId", req.GroupID).Int("keyCount", len(req.Keys)).Msg("Request body decoded successfully")
+	log.Debug().Str("sessionId", req.SessionID).Str("groupId", req.GroupID).Int("keyCount", len(req.Keys)).Bool("dryRun", req.DryRun).Msg("Request body decoded successfully")
+
+	// A stored post group supplies the keys when the request omits them (DDR-178).
+	group, ok := requestPostGroup(w, r, req.SessionID, req.GroupID)
+	if !ok {
+		return
+	}
+	if group != nil && len(req.Keys) == 0 {
+		req.Keys = group.MediaKeys
+	}
+	var order []int
+	if req.UseSuggestedOrder {
+		if group == nil {
+			httpError(w, http.StatusBadRequest, "useSuggestedOrder needs the groupId of a stored post group")
+			return
+		}
+		suggested, err := group.SuggestedOrder(req.Keys)
+		if err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		order = suggested
+	}
+
+	platforms := req.Platforms
+	switch {
+	case len(platforms) > 0 && req.Platform != "":
+		httpError(w, http.StatusBadRequest, "set platform or platforms, not both")
+		return
+	case len(platforms) == 0 && req.Platform != "":
+		platforms = []string{req.Platform}
+	case len(platforms) == 0:
+		platforms = []string{sfnevents.PlatformInstagram}
+	}
+	if err := validatePublishPlatforms(platforms, req.Keys, req.UserTags, req.Collaborators, req.LocationID, req.AltText); err != nil {
+		log.Warn().Err(err).Str("param", "platform").Strs("platforms", platforms).Msg("Invalid publish platform")
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for platform := range req.Captions {
+		if !slices.Contains(platforms, platform) {
+			httpError(w, http.StatusBadRequest, fmt.Sprintf("captions has a variant for %q, which is not a target platform", platform))
+			return
+		}
+	}
+
+	// A dry run simulates Instagram, so it needs no credentials (DDR-139).
+	// Facebook and Mastodon credentials live with the publish Lambda only
+	// (DDR-147, DDR-151).
+	if slices.Contains(platforms, sfnevents.PlatformInstagram) && igClient == nil && !req.DryRun {
+		log.Debug().Msg("Instagram client not configured")
+		httpError(w, http.StatusServiceUnavailable, "Instagram publishing is not configured — set INSTAGRAM_ACCESS_TOKEN and INSTAGRAM_USER_ID")
+		return
+	}
+	log.Debug().Msg("Instagram client check passed")
 
 	if err := validateSessionID(req.SessionID); err != nil {
 		log.Debug().Err(err).Str("sessionId", req.SessionID).Msg("SessionId validation failed")
@@ -75,30 +191,143 @@ func handlePublishStart(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	log.Debug().Int("keyCount", len(req.Keys)).Msg("All keys validated successfully")
+	if req.LocationID != "" && !validateLocationID(req.LocationID) {
+		log.Warn().Str("param", "locationId").Str("locationId", req.LocationID).Msg("Invalid location ID")
+		httpError(w, http.StatusBadRequest, "invalid locationId")
+		return
+	}
+	if err := validatePublishTags(req.Keys, req.UserTags, req.Collaborators); err != nil {
+		log.Warn().Err(err).Str("param", "userTags").Msg("Invalid user tags or collaborators")
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var publishAt time.Time
+	var scheduleOwner string
+	if req.PublishAt != "" {
+		owner, ok := signedInUser(w, r)
+		if !ok {
+			return
+		}
+		if scheduledPostStore == nil {
+			httpError(w, http.StatusServiceUnavailable, "scheduled publishing is not configured")
+			return
+		}
+		if req.RequireApproval {
+			httpError(w, http.StatusBadRequest, "requireApproval cannot be combined with publishAt")
+			return
+		}
+		at, err := parsePublishAt(r.Context(), req.SessionID, req.PublishAt)
+		if err != nil {
+			log.Warn().Err(err).Str("param", "publishAt").Msg("Invalid publish time")
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		publishAt, scheduleOwner = at, owner
+	}
+	if len(req.AltText) == 0 && req.DescriptionJobID != "" {
+		altText, err := descriptionAltText(r.Context(), req.SessionID, req.DescriptionJobID, req.Keys)
+		if err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		req.AltText = altText
+	}
+	if req.SelectionJobID != "" {
+		altText, err := selectionAltText(r.Context(), req.SessionID, req.SelectionJobID, req.Keys, req.AltText)
+		if err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		req.AltText = altText
+	}
+	req.Keys = resolveOriginalKeys(r.Context(), req.SessionID, req.Keys) // DDR-131
+	if req.Watermark {
+		if err := requireWatermark(r.Context(), r); err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 
-	// Assemble full caption with hashtags
-	fullCaption := req.Caption
-	if len(req.Hashtags) > 0 {
-		hashtagStrs := make([]string, len(req.Hashtags))
-		for i, h := range req.Hashtags {
-			if strings.HasPrefix(h, "#") {
-				hashtagStrs[i] = h
-			} else {
-				hashtagStrs[i] = "#" + h
-			}
+	signOff := ""
+	if req.BrandKit {
+		kit := callerBrandKit(r)
+		if kit == nil {
+			httpError(w, http.StatusBadRequest, "brandKit requested but none is set; save one with POST /api/brand-kit")
+			return
+		}
+		signOff = kit.SignOff
+		if len(req.Hashtags) == 0 {
+			req.Hashtags = callerHashtags(r, kit.Hashtags) // DDR-157
+		}
+	}
+	if req.HashtagsInComment {
+		switch {
+		case !slices.Contains(platforms, sfnevents.PlatformInstagram):
+			httpError(w, http.StatusBadRequest, "hashtagsInComment needs instagram as a target platform")
+			return
+		case len(req.Hashtags) == 0:
+			httpError(w, http.StatusBadRequest, "hashtagsInComment needs hashtags")
+			return
 		}
-		fullCaption += "\n\n" + strings.Join(hashtagStrs, " ")
 	}
 
+	// Assemble each platform's full caption: its variant or the shared
+	// caption, the sign-off, then the hashtags (DDR-153), which Instagram
+	// may move to the first comment (DDR-163). The first platform's caption
+	// is the job's own.
+	targets := make([]sfnevents.PublishTarget, len(platforms))
+	for i, platform := range platforms {
+		caption, ok := req.Captions[platform]
+		if !ok {
+			caption = req.Caption
+		}
+		caption = brand.AppendSignOff(caption, signOff)
+		targets[i] = sfnevents.PublishTarget{Platform: platform, Caption: fullPublishCaption(caption, req.Hashtags)}
+		if req.HashtagsInComment && platform == sfnevents.PlatformInstagram {
+			targets[i].Caption, targets[i].FirstComment = caption, hashtagLine(req.Hashtags)
+		}
+	}
+	fullCaption := targets[0].Caption
+
 	jobID := jobs.GenerateID("pub-")
 
-	// Write pending job to DynamoDB (DDR-050).
+	// DDR-121: the approval record must exist before the pipeline can reach
+	// its gate. The token is returned once; only its hash is stored.
+	var approvalToken string
+	if req.RequireApproval {
+		if sessionStore == nil {
+			httpError(w, http.StatusServiceUnavailable, "publish approval requires the job store")
+			return
+		}
+		approvalToken = jobs.GenerateID("")
+		approval := &store.PublishApproval{
+			JobID:       jobID,
+			Status:      store.ApprovalPending,
+			RequestedBy: getUserSub(r),
+			RequestedAt: time.Now().Unix(),
+			TokenHash:   hashApprovalToken(approvalToken),
+			Caption:     fullCaption,
+			Keys:        req.Keys,
+		}
+		if err := sessionStore.PutPublishApproval(r.Context(), req.SessionID, approval); err != nil {
+			log.Error().Err(err).Str("jobId", jobID).Msg("Failed to persist publish approval")
+			httpError(w, http.StatusInternalServerError, "failed to create job")
+			return
+		}
+	}
+
+	// Write pending job to DynamoDB (DDR-050). A scheduled job stays
+	// "scheduled" until the scheduler dispatches it (DDR-144).
 	if sessionStore != nil {
+		status := "pending"
+		if scheduleOwner != "" {
+			status = store.ScheduleStatusScheduled
+		}
 		pendingJob := &store.PublishJob{
 			ID:         jobID,
 			GroupID:    req.GroupID,
-			Status:     "pending",
-			Phase:      "pending",
+			Status:     status,
+			Phase:      status,
 			TotalItems: len(req.Keys),
 		}
 		if err := sessionStore.PutPublishJob(context.Background(), req.SessionID, pendingJob); err != nil {
@@ -107,6 +336,7 @@ func handlePublishStart(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	notePostGroupJob(r, req.SessionID, group, func(g *store.PostGroup) { g.PublishJobID = jobID })
 
 	// Dispatch to Publish Pipeline Step Functions (DDR-052).
 	if sfnClient == nil || publishSfnArn == "" {
@@ -126,12 +356,55 @@ func handlePublishStart(w http.ResponseWriter, r *http.Request) {
 		"groupId":   req.GroupID,
 		"keys":      req.Keys,
 		"caption":   fullCaption,
+
+		"locationId":      req.LocationID,
+		"userTags":        req.UserTags,
+		"collaborators":   req.Collaborators,
+		"requireApproval": req.RequireApproval,
+		"watermark":       req.Watermark,
+		"dryRun":          req.DryRun,
+		"platform":        platforms[0],
+		"altText":         req.AltText,
+		"targets":         targets,
+		"order":           order,
 	})
+	if scheduleOwner != "" {
+		post := &store.ScheduledPost{
+			JobID:     jobID,
+			OwnerSub:  scheduleOwner,
+			SessionID: req.SessionID,
+			GroupID:   req.GroupID,
+			PublishAt: publishAt.Unix(),
+			Caption:   fullCaption,
+			KeyCount:  len(req.Keys),
+			DryRun:    req.DryRun,
+			Input:     string(sfnInput),
+		}
+		if err := scheduledPostStore.PutScheduledPost(r.Context(), post); err != nil {
+			log.Error().Err(err).Str("jobId", jobID).Msg("Failed to persist scheduled post")
+			errJob := &store.PublishJob{ID: jobID, GroupID: req.GroupID, Status: "error", Phase: "error", Error: "failed to schedule post"}
+			sessionStore.PutPublishJob(context.Background(), req.SessionID, errJob)
+			httpError(w, http.StatusInternalServerError, "failed to schedule post")
+			return
+		}
+		log.Info().
+			Str("jobId", jobID).
+			Str("sessionId", req.SessionID).
+			Str("groupId", req.GroupID).
+			Time("publishAt", publishAt).
+			Msg("Publish job scheduled")
+		respondJSON(w, http.StatusAccepted, map[string]interface{}{
+			"id":        jobID,
+			"publishAt": publishAt.Unix(),
+		})
+		return
+	}
 	log.Info().
 		Str("jobId", jobID).
 		Str("sessionId", req.SessionID).
 		Str("groupId", req.GroupID).
 		Int("keyCount", len(req.Keys)).
+		Strs("platforms", platforms).
 		Str("sfnArn", publishSfnArn).
 		Msg("Job dispatched to Publish Pipeline")
 	_, err := sfnClient.StartExecution(context.Background(), &sfn.StartExecutionInput{
@@ -150,9 +423,224 @@ func handlePublishStart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respondJSON(w, http.StatusAccepted, map[string]string{
+	resp := map[string]string{
 		"id": jobID,
-	})
+	}
+	if approvalToken != "" {
+		resp["approvalToken"] = approvalToken
+	}
+	respondJSON(w, http.StatusAccepted, resp)
+}
+
+// fullPublishCaption appends the hashtags to a caption, adding the # where
+// it is missing.
+func fullPublishCaption(caption string, hashtags []string) string {
+	if len(hashtags) == 0 {
+		return caption
+	}
+	return caption + "\n\n" + hashtagLine(hashtags)
+}
+
+// hashtagLine formats hashtags as one space-separated line, adding the
+// leading '#' where missing.
+func hashtagLine(hashtags []string) string {
+	hashtagStrs := make([]string, len(hashtags))
+	for i, h := range hashtags {
+		if strings.HasPrefix(h, "#") {
+			hashtagStrs[i] = h
+		} else {
+			hashtagStrs[i] = "#" + h
+		}
+	}
+	return strings.Join(hashtagStrs, " ")
+}
+
+// validatePublishPlatforms checks the request against what the chosen
+// platforms can post (DDR-147). When cross-posting (DDR-153), a setting
+// goes to the platforms that support it and the others ignore it; only a
+// setting no chosen platform supports is refused. User tags and
+// collaborators are Instagram's, and a location needs Instagram or
+// Facebook. Every platform takes alt-text (DDR-154), up to Mastodon's
+// limit. Facebook albums attach photos only, and a Mastodon status holds
+// up to 4 photos or one video.
+func validatePublishPlatforms(platforms []string, keys []string, userTags [][]instagram.UserTag, collaborators []string, locationID string, altText []string) error {
+	has := make(map[string]bool, len(platforms))
+	var names []string
+	for _, platform := range platforms {
+		switch platform {
+		case sfnevents.PlatformInstagram, sfnevents.PlatformFacebook, sfnevents.PlatformMastodon:
+		default:
+			return fmt.Errorf("platform must be %q, %q or %q", sfnevents.PlatformInstagram, sfnevents.PlatformFacebook, sfnevents.PlatformMastodon)
+		}
+		if has[platform] {
+			return fmt.Errorf("platform %q is listed more than once", platform)
+		}
+		has[platform] = true
+		names = append(names, publishPlatformNames[platform])
+	}
+	chosen := strings.Join(names, " or ")
+
+	if !has[sfnevents.PlatformInstagram] {
+		for _, tags := range userTags {
+			if len(tags) > 0 {
+				return fmt.Errorf("userTags are not supported on %s", chosen)
+			}
+		}
+		if len(collaborators) > 0 {
+			return fmt.Errorf("collaborators are not supported on %s", chosen)
+		}
+		if locationID != "" && !has[sfnevents.PlatformFacebook] {
+			return fmt.Errorf("locationId is not supported on %s", chosen)
+		}
+	}
+	if len(altText) > len(keys) {
+		return fmt.Errorf("altText has %d entries for %d keys", len(altText), len(keys))
+	}
+	for i, text := range altText {
+		if n := len([]rune(text)); n > mastodon.MaxAltTextLength {
+			return fmt.Errorf("item %d: altText is %d characters, at most %d are allowed", i+1, n, mastodon.MaxAltTextLength)
+		}
+	}
+
+	if has[sfnevents.PlatformFacebook] && len(keys) > 1 {
+		for i, key := range keys {
+			if media.IsVideo(path.Ext(key)) {
+				return fmt.Errorf("item %d: Facebook albums can only contain photos", i+1)
+			}
+		}
+	}
+	if !has[sfnevents.PlatformMastodon] {
+		return nil
+	}
+	if len(keys) > mastodon.MaxAttachments {
+		return fmt.Errorf("a Mastodon post holds at most %d items, got %d", mastodon.MaxAttachments, len(keys))
+	}
+	if len(keys) > 1 {
+		for i, key := range keys {
+			if media.IsVideo(path.Ext(key)) {
+				return fmt.Errorf("item %d: a Mastodon post with several items can only contain photos", i+1)
+			}
+		}
+	}
+	return nil
+}
+
+// publishPlatformNames names the publish platforms in request errors.
+var publishPlatformNames = map[string]string{
+	sfnevents.PlatformInstagram: "Instagram",
+	sfnevents.PlatformFacebook:  "Facebook",
+	sfnevents.PlatformMastodon:  "Mastodon",
+}
+
+// descriptionAltText returns the alt-text a description job generated for
+// keys (DDR-151), matched by key so the publish order may differ from the
+// description's. Keys the job did not describe get none.
+func descriptionAltText(ctx context.Context, sessionID, jobID string, keys []string) ([]string, error) {
+	if sessionStore == nil {
+		return nil, nil
+	}
+	job, err := sessionStore.GetDescriptionJob(ctx, sessionID, jobID)
+	if err != nil {
+		log.Warn().Err(err).Str("descriptionJobId", jobID).Msg("Failed to read description job for alt-text")
+		return nil, fmt.Errorf("description job %s could not be read", jobID)
+	}
+	if job == nil {
+		return nil, fmt.Errorf("description job %s not found", jobID)
+	}
+	byKey := make(map[string]string, len(job.AltText))
+	for i, text := range job.AltText {
+		if i < len(job.MediaKeys) {
+			byKey[job.MediaKeys[i]] = text
+		}
+	}
+	altText := make([]string, len(keys))
+	found := 0
+	for i, key := range keys {
+		if altText[i] = byKey[key]; altText[i] != "" {
+			found++
+		}
+	}
+	log.Debug().Str("descriptionJobId", jobID).Int("items", len(keys)).Int("withAltText", found).Msg("Alt-text taken from description job")
+	if found == 0 {
+		return nil, nil
+	}
+	return altText, nil
+}
+
+// selectionAltText fills the entries of altText, aligned with keys, that
+// are still empty with the alt-text a selection job gave its photos
+// (DDR-154). Items are matched by file name, so a photo's enhanced copy
+// takes the original's alt-text.
+func selectionAltText(ctx context.Context, sessionID, jobID string, keys, altText []string) ([]string, error) {
+	if sessionStore == nil {
+		return altText, nil
+	}
+	job, err := sessionStore.GetSelectionJob(ctx, sessionID, jobID)
+	if err != nil {
+		log.Warn().Err(err).Str("selectionJobId", jobID).Msg("Failed to read selection job for alt-text")
+		return nil, fmt.Errorf("selection job %s could not be read", jobID)
+	}
+	if job == nil {
+		return nil, fmt.Errorf("selection job %s not found", jobID)
+	}
+	byName := make(map[string]string, len(job.Selected))
+	for _, item := range job.Selected {
+		if item.AltText != "" {
+			byName[fileStem(item.Key)] = item.AltText
+		}
+	}
+	out := make([]string, len(keys))
+	copy(out, altText)
+	filled, found := 0, 0
+	for i, key := range keys {
+		if out[i] == "" {
+			if out[i] = byName[fileStem(key)]; out[i] != "" {
+				filled++
+			}
+		}
+		if out[i] != "" {
+			found++
+		}
+	}
+	log.Debug().Str("selectionJobId", jobID).Int("items", len(keys)).Int("filled", filled).Msg("Alt-text taken from selection job")
+	if found == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// fileStem is a key's file name without its extension.
+func fileStem(key string) string {
+	name := path.Base(key)
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// validatePublishTags checks the user tags and collaborators of a publish
+// request against Instagram's limits (DDR-141), normalizing usernames in
+// place. userTags is aligned with keys; Instagram cannot tag people on
+// videos inside a carousel.
+func validatePublishTags(keys []string, userTags [][]instagram.UserTag, collaborators []string) error {
+	if len(userTags) > len(keys) {
+		return fmt.Errorf("userTags has %d entries for %d keys", len(userTags), len(keys))
+	}
+	for i, tags := range userTags {
+		if len(tags) == 0 {
+			continue
+		}
+		if len(keys) > 1 && media.IsVideo(path.Ext(keys[i])) {
+			return fmt.Errorf("item %d: people cannot be tagged on videos in a carousel", i+1)
+		}
+		for j := range tags {
+			tags[j].Username = instagram.NormalizeUsername(tags[j].Username)
+		}
+		if err := instagram.ValidateUserTags(tags); err != nil {
+			return fmt.Errorf("item %d: %w", i+1, err)
+		}
+	}
+	for i := range collaborators {
+		collaborators[i] = instagram.NormalizeUsername(collaborators[i])
+	}
+	return instagram.ValidateCollaborators(collaborators)
 }
 
 func handlePublishRoutes(w http.ResponseWriter, r *http.Request) {
@@ -165,6 +653,10 @@ func handlePublishRoutes(w http.ResponseWriter, r *http.Request) {
 	switch action {
 	case "status":
 		handlePublishStatus(w, r, jobID)
+	case "approve":
+		handlePublishDecision(w, r, jobID, store.ApprovalApproved)
+	case "reject":
+		handlePublishDecision(w, r, jobID, store.ApprovalRejected)
 	default:
 		httpError(w, http.StatusNotFound, "not found")
 	}
@@ -217,8 +709,123 @@ func handlePublishStatus(w http.ResponseWriter, r *http.Request, jobID string) {
 	if job.InstagramPostID != "" {
 		resp["instagramPostId"] = job.InstagramPostID
 	}
+	if job.FacebookPostID != "" {
+		resp["facebookPostId"] = job.FacebookPostID // DDR-147
+	}
+	if job.MastodonPostID != "" {
+		resp["mastodonPostId"] = job.MastodonPostID // DDR-151
+	}
+	if len(job.Platforms) > 0 {
+		resp["platforms"] = job.Platforms // DDR-153
+	}
+	if job.FirstCommentID != "" {
+		resp["firstCommentId"] = job.FirstCommentID // DDR-163
+	}
+	if job.FirstCommentError != "" {
+		resp["firstCommentError"] = job.FirstCommentError
+	}
 	if job.Error != "" {
 		resp["error"] = job.Error
 	}
+	if len(job.ItemErrors) > 0 {
+		resp["itemErrors"] = job.ItemErrors // DDR-129
+	}
+	approval, err := sessionStore.GetPublishApproval(r.Context(), sessionID, jobID)
+	if err != nil {
+		log.Warn().Err(err).Str("jobId", jobID).Msg("Failed to read publish approval")
+	} else if approval != nil {
+		resp["approval"] = approval
+	}
+	if job.Telemetry != nil {
+		resp["perJobTelemetry"] = job.Telemetry // DDR-118
+	}
 	respondJSON(w, http.StatusOK, resp)
 }
+
+// POST /api/publish/{id}/approve and /api/publish/{id}/reject (DDR-121)
+// Body: {"sessionId": "uuid", "token": "..."}
+//
+// The decision is accepted from the holder of the approval link's token (the
+// requester on another device, or whoever they sent it to), or without a
+// token from a signed-in approver listed in PUBLISH_APPROVER_SUBS who is not
+// the requester. The requester may always reject, to withdraw the post.
+func handlePublishDecision(w http.ResponseWriter, r *http.Request, jobID, decision string) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("jobId", jobID).Str("decision", decision).Msg("Handler entry: handlePublishDecision")
+
+	if r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		SessionID string `json:"sessionId"`
+		Token     string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validateSessionID(req.SessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+
+	approval, err := sessionStore.GetPublishApproval(r.Context(), req.SessionID, jobID)
+	if err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to read publish approval")
+		httpError(w, http.StatusInternalServerError, "failed to read approval")
+		return
+	}
+	if approval == nil {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	sub := getUserSub(r)
+	via := ""
+	switch {
+	case req.Token != "" && subtle.ConstantTimeCompare([]byte(hashApprovalToken(req.Token)), []byte(approval.TokenHash)) == 1:
+		via = "link"
+	case sub != "" && sub != approval.RequestedBy && publishApprovers[sub]:
+		via = "approver"
+	case sub != "" && sub == approval.RequestedBy && decision == store.ApprovalRejected:
+		via = "requester"
+	default:
+		log.Warn().Str("jobId", jobID).Str("sub", sub).Bool("token", req.Token != "").Msg("Publish decision not authorized")
+		httpError(w, http.StatusForbidden, "not allowed to decide on this publish job")
+		return
+	}
+	if approval.Expired(time.Now()) {
+		httpError(w, http.StatusConflict, "the approval window has expired")
+		return
+	}
+
+	decidedBy := sub
+	if decidedBy == "" {
+		decidedBy = via
+	}
+	if err := sessionStore.DecidePublishApproval(r.Context(), req.SessionID, jobID, decision, decidedBy, via); err != nil {
+		if errors.Is(err, store.ErrStatusConflict) {
+			httpError(w, http.StatusConflict, "this publish job has already been decided")
+			return
+		}
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to record publish decision")
+		httpError(w, http.StatusInternalServerError, "failed to record decision")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{
+		"id":       jobID,
+		"approval": decision,
+	})
+}
+
+// hashApprovalToken returns the hex SHA-256 stored in place of an approval token.
+func hashApprovalToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/fpang/ai-social-media-helper/internal/jobs"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Job Retry Endpoints (DDR-089) ---
+
+func handleJobRoutes(w http.ResponseWriter, r *http.Request) {
+	// GET /api/jobs/{id} has no action segment (DDR-136).
+	if id := strings.TrimPrefix(r.URL.Path, "/api/jobs/"); id != "" && !strings.Contains(id, "/") {
+		handleJobStatus(w, r, id)
+		return
+	}
+
+	// Job IDs carry their own type prefix (triage-, dl-, desc-, ...), so no idPrefix is enforced.
+	jobID, action, ok := jobs.ParseRoute(r.URL.Path, "/api/jobs/", "")
+	if !ok || jobID == "" {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	switch action {
+	case "retry":
+		handleJobRetry(w, r, jobID)
+	default:
+		httpError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// POST /api/jobs/{id}/retry
+// Body: {"sessionId": "uuid"}
+//
+// Re-dispatches a failed async job with the payload recorded at original
+// dispatch time. Only jobs in "error" status with retries remaining are eligible.
+func handleJobRetry(w http.ResponseWriter, r *http.Request, jobID string) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("jobId", jobID).Msg("Handler entry: handleJobRetry")
+
+	if r.Method != http.MethodPost {
+		log.Warn().Str("param", "method").Msg("Method not allowed")
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		SessionID string `json:"sessionId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn().Str("param", "body").Msg("Invalid request body")
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validateSessionID(req.SessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !ensureSessionOwner(w, r, req.SessionID) {
+		return
+	}
+
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+
+	ctx := context.Background()
+	summary, err := sessionStore.GetJobSummary(ctx, req.SessionID, jobID)
+	if err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to read job for retry")
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if summary == nil {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if summary.Status != "error" {
+		httpError(w, http.StatusConflict, fmt.Sprintf("job is %s, only failed jobs can be retried", summary.Status))
+		return
+	}
+	if summary.RetryCount >= jobs.MaxRetries {
+		httpError(w, http.StatusConflict, fmt.Sprintf("retry limit reached (%d)", jobs.MaxRetries))
+		return
+	}
+
+	rec, err := sessionStore.GetDispatchRecord(ctx, req.SessionID, jobID)
+	if err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to read dispatch record")
+		httpError(w, http.StatusInternalServerError, "failed to read job")
+		return
+	}
+	if rec == nil {
+		// Step Functions pipelines are retried by their own endpoints (e.g. /api/triage/finalize).
+		httpError(w, http.StatusConflict, "job cannot be retried")
+		return
+	}
+
+	retryCount, err := sessionStore.IncrementJobRetryCount(ctx, req.SessionID, jobID, "pending", jobs.MaxRetries)
+	if errors.Is(err, store.ErrRetryLimit) {
+		httpError(w, http.StatusConflict, fmt.Sprintf("retry limit reached (%d)", jobs.MaxRetries))
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to update job for retry")
+		httpError(w, http.StatusInternalServerError, "failed to prepare retry")
+		return
+	}
+
+	log.Info().
+		Str("jobId", jobID).
+		Str("sessionId", req.SessionID).
+		Str("eventType", rec.EventType).
+		Int("retryCount", retryCount).
+		Msg("Job re-dispatched for retry")
+	if err := invokePayload(ctx, rec.Target, []byte(rec.Payload)); err != nil {
+		errDetail := fmt.Sprintf("failed to start retry: %v", err)
+		sessionStore.UpdateJobStatus(ctx, req.SessionID, jobID, "error", errDetail)
+		httpError(w, http.StatusInternalServerError, errDetail)
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, map[string]interface{}{
+		"id":         jobID,
+		"retryCount": retryCount,
+	})
+}
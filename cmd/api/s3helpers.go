@@ -4,13 +4,18 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
 )
 
 // --- S3 Helpers ---
@@ -91,3 +96,171 @@ func cleanupS3Prefix(sessionID, prefix string) {
 		log.Info().Str("prefix", fullPrefix).Int("deleted", deleted).Msg("S3 cleanup completed")
 	}
 }
+
+// copyS3Prefix copies every object under from to the same relative key under
+// to, skipping keys that already exist there (DDR-113). Copies are encrypted
+// with enc, the destination session's encryption (DDR-164). Returns the
+// number of objects copied.
+func copyS3Prefix(ctx context.Context, from, to string, enc s3util.Encryption) (int, error) {
+	existing := make(map[string]bool)
+	targets := s3.NewListObjectsV2Paginator(s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(mediaBucket),
+		Prefix: aws.String(to),
+	})
+	for targets.HasMorePages() {
+		page, err := targets.NextPage(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("list %s: %w", to, err)
+		}
+		for _, obj := range page.Contents {
+			existing[aws.ToString(obj.Key)] = true
+		}
+	}
+
+	paginator := s3.NewListObjectsV2Paginator(s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(mediaBucket),
+		Prefix: aws.String(from),
+	})
+	copied := 0
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return copied, fmt.Errorf("list %s: %w", from, err)
+		}
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+			dest := to + strings.TrimPrefix(key, from)
+			if existing[dest] {
+				continue
+			}
+			if err := copyS3Object(ctx, key, dest, aws.ToInt64(obj.Size), enc); err != nil {
+				return copied, fmt.Errorf("copy %s: %w", key, err)
+			}
+			copied++
+		}
+	}
+	return copied, nil
+}
+
+// maxCopyObjectSize is the largest object a single CopyObject can copy;
+// larger objects are copied part by part. copyPartSize keeps a 5TB object
+// within S3's 10,000-part limit.
+const (
+	maxCopyObjectSize = 5 << 30
+	copyPartSize      = 1 << 30
+)
+
+// copyS3Object copies key to dest within the media bucket, encrypting the
+// copy with enc. Objects over maxCopyObjectSize use a multipart copy, which
+// is aborted if any part fails.
+func copyS3Object(ctx context.Context, key, dest string, size int64, enc s3util.Encryption) error {
+	source := aws.String(mediaBucket + "/" + url.PathEscape(key))
+	if size <= maxCopyObjectSize {
+		_, err := s3Client.CopyObject(ctx, enc.Copy(&s3.CopyObjectInput{
+			Bucket:     aws.String(mediaBucket),
+			CopySource: source,
+			Key:        aws.String(dest),
+		}))
+		return err
+	}
+
+	created, err := s3Client.CreateMultipartUpload(ctx, enc.Multipart(&s3.CreateMultipartUploadInput{
+		Bucket:  aws.String(mediaBucket),
+		Key:     aws.String(dest),
+		Tagging: s3util.ProjectTagging(),
+	}))
+	if err != nil {
+		return fmt.Errorf("create multipart copy: %w", err)
+	}
+	abort := func() {
+		_, _ = s3Client.AbortMultipartUpload(context.Background(), &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(mediaBucket),
+			Key:      aws.String(dest),
+			UploadId: created.UploadId,
+		})
+	}
+
+	var parts []s3types.CompletedPart
+	for start, n := int64(0), int32(1); start < size; start, n = start+copyPartSize, n+1 {
+		end := min(start+copyPartSize, size) - 1
+		out, err := s3Client.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+			Bucket:          aws.String(mediaBucket),
+			CopySource:      source,
+			CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+			Key:             aws.String(dest),
+			PartNumber:      aws.Int32(n),
+			UploadId:        created.UploadId,
+		})
+		if err != nil {
+			abort()
+			return fmt.Errorf("copy part %d: %w", n, err)
+		}
+		parts = append(parts, s3types.CompletedPart{ETag: out.CopyPartResult.ETag, PartNumber: aws.Int32(n)})
+	}
+
+	_, err = s3Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(mediaBucket),
+		Key:             aws.String(dest),
+		UploadId:        created.UploadId,
+		MultipartUpload: &s3types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		abort()
+		return fmt.Errorf("complete multipart copy: %w", err)
+	}
+	return nil
+}
+
+// hasS3Objects reports whether any object exists under prefix in the media
+// bucket.
+func hasS3Objects(ctx context.Context, prefix string) (bool, error) {
+	out, err := s3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+		Bucket:  aws.String(mediaBucket),
+		Prefix:  aws.String(prefix),
+		MaxKeys: aws.Int32(1),
+	})
+	if err != nil {
+		return false, fmt.Errorf("list %s: %w", prefix, err)
+	}
+	return len(out.Contents) > 0, nil
+}
+
+// deleteS3Prefix deletes every object under prefix in the media bucket,
+// paging through the listing and deleting up to 1000 keys per request
+// (DDR-098). Returns the number of objects deleted; unlike cleanupS3Prefix it
+// reports failures so the caller can stop before deleting the session records.
+func deleteS3Prefix(ctx context.Context, prefix string) (int, error) {
+	paginator := s3.NewListObjectsV2Paginator(s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(mediaBucket),
+		Prefix: aws.String(prefix),
+	})
+
+	deleted := 0
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return deleted, fmt.Errorf("list %s: %w", prefix, err)
+		}
+		if len(page.Contents) == 0 {
+			continue
+		}
+
+		objects := make([]s3types.ObjectIdentifier, 0, len(page.Contents))
+		for _, obj := range page.Contents {
+			objects = append(objects, s3types.ObjectIdentifier{Key: obj.Key})
+		}
+		result, err := s3Client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(mediaBucket),
+			Delete: &s3types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("delete objects under %s: %w", prefix, err)
+		}
+		if len(result.Errors) > 0 {
+			first := result.Errors[0]
+			return deleted + len(objects) - len(result.Errors), fmt.Errorf("delete %s: %s", aws.ToString(first.Key), aws.ToString(first.Message))
+		}
+		deleted += len(objects)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/jobs"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Scheduled publishing (DDR-144) ---
+
+const (
+	// minScheduleLead keeps a publish time far enough ahead that the
+	// scheduler, which runs every minute, cannot have passed it already.
+	minScheduleLead = time.Minute
+	// mediaExpiryMargin is kept between a publish time and the end of the
+	// session, when its media and records expire (DDR-035, DDR-059).
+	mediaExpiryMargin = time.Hour
+)
+
+// parsePublishAt parses an RFC 3339 publish time and checks it falls after
+// minScheduleLead and before the session's media expire.
+func parsePublishAt(ctx context.Context, sessionID, value string) (time.Time, error) {
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("publishAt must be an RFC 3339 time")
+	}
+	if at.Before(time.Now().Add(minScheduleLead)) {
+		return time.Time{}, fmt.Errorf("publishAt must be at least %s in the future", minScheduleLead)
+	}
+	session, err := sessionStore.GetSession(ctx, sessionID)
+	if err != nil || session == nil {
+		return time.Time{}, fmt.Errorf("session not found")
+	}
+	latest := time.Unix(session.CreatedAt, 0).Add(store.SessionTTL - mediaExpiryMargin)
+	if at.After(latest) {
+		return time.Time{}, fmt.Errorf("publishAt must be before %s, when this session's media expire", latest.UTC().Format(time.RFC3339))
+	}
+	return at, nil
+}
+
+// scheduledPostsReady writes an error response unless the caller is signed
+// in and scheduled publishing is configured.
+func scheduledPostsReady(w http.ResponseWriter, r *http.Request) (string, bool) {
+	owner, ok := signedInUser(w, r)
+	if !ok {
+		return "", false
+	}
+	if scheduledPostStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "scheduled publishing is not configured")
+		return "", false
+	}
+	return owner, true
+}
+
+// GET /api/publish/scheduled — the caller's scheduled posts, soonest first,
+// including those dispatched or cancelled within store.ScheduledPostRetention.
+func handleScheduledPostList(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleScheduledPostList")
+
+	if r.Method != http.MethodGet {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	owner, ok := scheduledPostsReady(w, r)
+	if !ok {
+		return
+	}
+
+	posts, err := scheduledPostStore.ListScheduledPosts(r.Context(), owner)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list scheduled posts")
+		httpError(w, http.StatusInternalServerError, "failed to list scheduled posts")
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"posts": posts,
+	})
+}
+
+// POST /api/publish/scheduled/{id}/cancel
+// POST /api/publish/scheduled/{id}/reschedule — body: {"publishAt": "RFC 3339"}
+//
+// Only a post that is still scheduled can be changed; once the scheduler has
+// dispatched it the request fails with 409.
+func handleScheduledPostRoutes(w http.ResponseWriter, r *http.Request) {
+	jobID, action, ok := jobs.ParseRoute(r.URL.Path, "/api/publish/scheduled/", "pub-")
+	if !ok || (action != "cancel" && action != "reschedule") {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("jobId", jobID).Msg("Handler entry: handleScheduledPostRoutes")
+
+	if r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	owner, ok := scheduledPostsReady(w, r)
+	if !ok {
+		return
+	}
+
+	post, err := scheduledPostStore.GetScheduledPost(r.Context(), owner, jobID)
+	if err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to read scheduled post")
+		httpError(w, http.StatusInternalServerError, "failed to read scheduled post")
+		return
+	}
+	if post == nil {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	if action == "cancel" {
+		err = scheduledPostStore.CancelScheduledPost(r.Context(), owner, jobID)
+	} else {
+		var req struct {
+			PublishAt string `json:"publishAt"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httpError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		at, perr := parsePublishAt(r.Context(), post.SessionID, req.PublishAt)
+		if perr != nil {
+			httpError(w, http.StatusBadRequest, perr.Error())
+			return
+		}
+		err = scheduledPostStore.RescheduleScheduledPost(r.Context(), owner, jobID, at)
+		post.PublishAt = at.Unix()
+	}
+	if errors.Is(err, store.ErrStatusConflict) {
+		httpError(w, http.StatusConflict, "post is no longer scheduled")
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Str("action", action).Msg("Failed to update scheduled post")
+		httpError(w, http.StatusInternalServerError, "failed to update scheduled post")
+		return
+	}
+
+	if action == "cancel" {
+		post.Status = store.ScheduleStatusCancelled
+		cancelled := &store.PublishJob{ID: jobID, GroupID: post.GroupID, Status: "cancelled", Phase: "cancelled", TotalItems: post.KeyCount}
+		if err := sessionStore.PutPublishJob(r.Context(), post.SessionID, cancelled); err != nil {
+			log.Warn().Err(err).Str("jobId", jobID).Msg("Failed to mark publish job cancelled")
+		}
+	}
+	log.Info().Str("jobId", jobID).Str("action", action).Int64("publishAt", post.PublishAt).Msg("Scheduled post updated")
+	respondJSON(w, http.StatusOK, post)
+}
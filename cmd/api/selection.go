@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
@@ -18,7 +19,14 @@ import (
 // --- Selection Endpoints (DDR-030, DDR-050) ---
 
 // POST /api/selection/start
-// Body: {"sessionId": "uuid", "tripContext": "...", "model": "optional-model-name"}
+// Body: {"sessionId": "uuid", "tripContext": "...", "model": "optional-model-name", "thinking": "", "temperature": 0.4, "topP": 0.9, "debugArtifacts": false}
+//
+// debugArtifacts keeps prompts, raw model responses, and compressed videos
+// under {sessionId}/debug/{jobId}/ for bug reports (DDR-106).
+// thinking overrides the Gemini thinking setting for this job: a level
+// (minimal, low, medium, high), off, dynamic, or a token budget (DDR-155).
+// temperature (0-2) and topP (0-1) override the model's sampling, and
+// model must be on the server's allowlist (DDR-170).
 func handleSelectionStart(w http.ResponseWriter, r *http.Request) {
 	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleSelectionStart")
 
@@ -29,9 +37,10 @@ func handleSelectionStart(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		SessionID   string `json:"sessionId"`
-		TripContext string `json:"tripContext"`
-		Model       string `json:"model,omitempty"`
+		SessionID      string `json:"sessionId"`
+		TripContext    string `json:"tripContext"`
+		DebugArtifacts bool   `json:"debugArtifacts,omitempty"`
+		ai.ModelConfig        // DDR-155, DDR-170
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Debug().Err(err).Msg("Request body decoding failed")
@@ -54,9 +63,15 @@ func handleSelectionStart(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Debug().Str("sessionId", req.SessionID).Msg("SessionId validation passed")
 
+	modelCfg, err := req.ModelConfig.Normalize()
+	if err != nil {
+		log.Warn().Err(err).Str("param", "model").Msg("Invalid model settings")
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	model := ai.DefaultModelName
-	if req.Model != "" {
-		model = req.Model
+	if modelCfg.Model != "" {
+		model = modelCfg.Model
 	}
 
 	jobID := jobs.GenerateID("sel-")
@@ -74,55 +89,84 @@ func handleSelectionStart(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// List S3 objects to build mediaKeys for the Step Functions pipeline.
-	prefix := req.SessionID + "/"
+	mediaKeys, ok := listSelectionMediaKeys(w, req.SessionID)
+	if !ok {
+		return
+	}
+	sfnInput := map[string]interface{}{
+		"sessionId":      req.SessionID,
+		"jobId":          jobID,
+		"tripContext":    req.TripContext,
+		"model":          model,
+		"thinking":       modelCfg.Thinking,
+		"temperature":    modelCfg.Temperature, // DDR-170: null for the model's default
+		"topP":           modelCfg.TopP,
+		"mediaKeys":      mediaKeys,
+		"debugArtifacts": req.DebugArtifacts,
+	}
+	if !dispatchSelection(w, req.SessionID, jobID, sfnInput) {
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, map[string]string{
+		"id": jobID,
+	})
+}
+
+// listSelectionMediaKeys lists the session's media for the selection
+// pipeline. On failure it writes the error response and returns false.
+func listSelectionMediaKeys(w http.ResponseWriter, sessionID string) ([]string, bool) {
+	prefix := sessionID + "/"
 	listResult, err := s3Client.ListObjectsV2(context.Background(), &s3.ListObjectsV2Input{
 		Bucket: aws.String(mediaBucket),
 		Prefix: aws.String(prefix),
 	})
 	if err != nil {
-		log.Error().Err(err).Str("sessionId", req.SessionID).Msg("Failed to list S3 objects for selection")
+		log.Error().Err(err).Str("sessionId", sessionID).Msg("Failed to list S3 objects for selection")
 		httpError(w, http.StatusInternalServerError, "failed to list uploaded media")
-		return
+		return nil, false
 	}
 	var mediaKeys []string
 	for _, obj := range listResult.Contents {
+		// Originals of proxy uploads are for download and publish only (DDR-131).
+		if strings.HasPrefix(*obj.Key, prefix+originalsDir) {
+			continue
+		}
 		mediaKeys = append(mediaKeys, *obj.Key)
 	}
-	log.Debug().Int("keyCount", len(mediaKeys)).Str("sessionId", req.SessionID).Msg("S3 objects listed")
+	log.Debug().Int("keyCount", len(mediaKeys)).Str("sessionId", sessionID).Msg("S3 objects listed")
 	if len(mediaKeys) == 0 {
 		log.Warn().Str("param", "keys").Msg("No files found for session")
 		httpError(w, http.StatusBadRequest, "no files found for session — upload files first")
-		return
+		return nil, false
 	}
-	log.Info().Int("count", len(mediaKeys)).Str("sessionId", req.SessionID).Msg("Found S3 objects for selection pipeline")
+	log.Info().Int("count", len(mediaKeys)).Str("sessionId", sessionID).Msg("Found S3 objects for selection pipeline")
+	return mediaKeys, true
+}
 
-	// Start Step Functions execution (DDR-050).
+// dispatchSelection starts the selection pipeline for the pending job
+// jobID (DDR-050). On failure it marks the job as failed, writes the error
+// response and returns false.
+func dispatchSelection(w http.ResponseWriter, sessionID, jobID string, input map[string]interface{}) bool {
 	if sfnClient == nil || selectionSfnArn == "" {
 		log.Error().Str("jobId", jobID).Msg("Selection pipeline not configured — cannot process")
 		errDetail := "selection processing is not available (pipeline not configured)"
 		if sessionStore != nil {
 			errJob := &store.SelectionJob{ID: jobID, Status: "error", Error: errDetail}
-			sessionStore.PutSelectionJob(context.Background(), req.SessionID, errJob)
+			sessionStore.PutSelectionJob(context.Background(), sessionID, errJob)
 		}
 		httpError(w, http.StatusServiceUnavailable, errDetail)
-		return
+		return false
 	}
-	sfnInput, _ := json.Marshal(map[string]interface{}{
-		"sessionId":   req.SessionID,
-		"jobId":       jobID,
-		"tripContext": req.TripContext,
-		"model":       model,
-		"mediaKeys":   mediaKeys,
-	})
+	sfnInput, _ := json.Marshal(input)
 	log.Info().
 		Str("jobId", jobID).
-		Str("sessionId", req.SessionID).
-		Str("model", model).
-		Int("keyCount", len(mediaKeys)).
+		Str("sessionId", sessionID).
+		Interface("model", input["model"]).
+		Interface("thinking", input["thinking"]).
 		Str("sfnArn", selectionSfnArn).
 		Msg("Job dispatched")
-	_, err = sfnClient.StartExecution(context.Background(), &sfn.StartExecutionInput{
+	_, err := sfnClient.StartExecution(context.Background(), &sfn.StartExecutionInput{
 		StateMachineArn: aws.String(selectionSfnArn),
 		Input:           aws.String(string(sfnInput)),
 		Name:            aws.String(jobID),
@@ -132,15 +176,12 @@ func handleSelectionStart(w http.ResponseWriter, r *http.Request) {
 		errDetail := fmt.Sprintf("failed to start processing: %v", err)
 		if sessionStore != nil {
 			errJob := &store.SelectionJob{ID: jobID, Status: "error", Error: errDetail}
-			sessionStore.PutSelectionJob(context.Background(), req.SessionID, errJob)
+			sessionStore.PutSelectionJob(context.Background(), sessionID, errJob)
 		}
 		httpError(w, http.StatusInternalServerError, errDetail)
-		return
+		return false
 	}
-
-	respondJSON(w, http.StatusAccepted, map[string]string{
-		"id": jobID,
-	})
+	return true
 }
 
 func handleSelectionRoutes(w http.ResponseWriter, r *http.Request) {
@@ -153,12 +194,20 @@ func handleSelectionRoutes(w http.ResponseWriter, r *http.Request) {
 	switch action {
 	case "results":
 		handleSelectionResults(w, r, jobID)
+	case "rerun":
+		handleSelectionRerun(w, r, jobID)
+	case "feedback":
+		handleSelectionFeedback(w, r, jobID)
 	default:
 		httpError(w, http.StatusNotFound, "not found")
 	}
 }
 
-// GET /api/selection/{id}/results?sessionId=...
+// GET /api/selection/{id}/results?sessionId=...&sort=composition
+//
+// sort orders the selected items by one scoring criterion, highest first:
+// composition, storyValue, uniqueness, technicalQuality or overall (DDR-150).
+// Without it they keep the AI's rank order.
 func handleSelectionResults(w http.ResponseWriter, r *http.Request, jobID string) {
 	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("jobId", jobID).Msg("Handler entry: handleSelectionResults")
 
@@ -194,6 +243,14 @@ func handleSelectionResults(w http.ResponseWriter, r *http.Request, jobID string
 	}
 	log.Debug().Str("jobId", jobID).Str("status", job.Status).Msg("Selection job found in DynamoDB")
 
+	if criterion := r.URL.Query().Get("sort"); criterion != "" {
+		if err := store.SortSelectedBy(job.Selected, criterion); err != nil {
+			log.Warn().Str("param", "sort").Str("sort", criterion).Msg("Unknown sort criterion")
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
 	resp := map[string]interface{}{
 		"id":          job.ID,
 		"status":      job.Status,
@@ -201,8 +258,41 @@ func handleSelectionResults(w http.ResponseWriter, r *http.Request, jobID string
 		"excluded":    job.Excluded,
 		"sceneGroups": job.SceneGroups,
 	}
+	if len(job.Unprocessed) > 0 {
+		resp["unprocessed"] = job.Unprocessed // DDR-134
+	}
 	if job.Error != "" {
 		resp["error"] = job.Error
 	}
+	if job.Telemetry != nil {
+		resp["perJobTelemetry"] = job.Telemetry // DDR-118
+	}
+	if job.Model != "" {
+		resp["model"] = job.Model // DDR-155
+	}
+	if job.Thinking != "" {
+		resp["thinking"] = job.Thinking // DDR-155
+	}
+	if job.Temperature != nil {
+		resp["temperature"] = *job.Temperature // DDR-170
+	}
+	if job.TopP != nil {
+		resp["topP"] = *job.TopP // DDR-170
+	}
+	if len(job.ModelsUsed) > 0 {
+		resp["modelsUsed"] = job.ModelsUsed // DDR-169
+	}
+	if job.Changes != nil {
+		resp["changes"] = job.Changes // DDR-158
+	}
+	resp["version"] = job.Revision() // DDR-176
+	if job.RerunOf != "" {
+		resp["rerunOf"] = job.RerunOf
+		resp["lockedKeys"] = job.LockedKeys
+		resp["excludedKeys"] = job.ExcludedKeys
+	}
+	if len(job.FeedbackHistory) > 0 {
+		resp["feedbackHistory"] = job.FeedbackHistory // DDR-177
+	}
 	respondJSON(w, http.StatusOK, resp)
 }
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// --- Selection feedback (DDR-177) ---
+
+// POST /api/selection/{id}/feedback
+// Body: {"sessionId": "uuid", "feedback": "more food shots, fewer selfies"}
+//
+// Revises the completed selection job {id} in place from the user's
+// feedback. The job is "processing" until the selection Lambda writes the
+// revised results; poll GET /api/selection/{id}/results. Earlier rounds are
+// listed in the results' feedbackHistory.
+func handleSelectionFeedback(w http.ResponseWriter, r *http.Request, jobID string) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("jobId", jobID).Msg("Handler entry: handleSelectionFeedback")
+
+	if r.Method != http.MethodPost {
+		log.Warn().Str("param", "method").Msg("Method not allowed")
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		SessionID string `json:"sessionId"`
+		Feedback  string `json:"feedback"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug().Err(err).Msg("Request body decoding failed")
+		log.Warn().Str("param", "body").Msg("Invalid request body")
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validateSessionID(req.SessionID); err != nil {
+		log.Warn().Str("param", "sessionId").Msg("SessionId validation failed")
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.Feedback = strings.TrimSpace(req.Feedback)
+	if req.Feedback == "" {
+		log.Warn().Str("param", "feedback").Msg("Feedback is required")
+		httpError(w, http.StatusBadRequest, "feedback is required")
+		return
+	}
+	if !ensureSessionOwner(w, r, req.SessionID) {
+		return
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+
+	ctx := r.Context()
+	job, err := sessionStore.GetSelectionJob(ctx, req.SessionID, jobID)
+	if err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to read selection job")
+		httpError(w, http.StatusInternalServerError, "failed to read job")
+		return
+	}
+	if job == nil {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if job.Status != "complete" {
+		httpError(w, http.StatusConflict, "selection job is not complete")
+		return
+	}
+
+	// Mark as processing; the record keeps the results being revised.
+	job.Status = "processing"
+	if err := sessionStore.PutSelectionJob(ctx, req.SessionID, job); err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to mark selection job processing")
+		httpError(w, http.StatusInternalServerError, "failed to update job")
+		return
+	}
+
+	payload := map[string]interface{}{
+		"type":      "selection-feedback",
+		"sessionId": req.SessionID,
+		"jobId":     jobID,
+		"feedback":  req.Feedback,
+	}
+	if err := invokeAsync(ctx, selectionLambdaArn, payload); err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Str("lambdaArn", selectionLambdaArn).Msg("Failed to invoke selection-lambda for feedback")
+		job.Status = "complete"
+		sessionStore.PutSelectionJob(ctx, req.SessionID, job)
+		httpError(w, http.StatusInternalServerError, "failed to start feedback processing")
+		return
+	}
+	log.Info().
+		Str("jobId", jobID).
+		Str("sessionId", req.SessionID).
+		Int("feedbackLength", len(req.Feedback)).
+		Int("round", len(job.FeedbackHistory)+1).
+		Msg("Job dispatched to selection-lambda")
+
+	respondJSON(w, http.StatusAccepted, map[string]string{
+		"status": "processing",
+	})
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/jobs"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Selection re-run with locked picks (DDR-176) ---
+
+// POST /api/selection/{id}/rerun
+// Body: {"sessionId": "uuid", "lockedKeys": ["..."], "excludedKeys": ["..."], "tripContext": "optional", "model": "optional", ...}
+//
+// Starts a new selection job that revises the completed job {id}: the
+// media in lockedKeys are selected, those in excludedKeys are not, and
+// Gemini re-ranks the rest around them. Each key must be one of job {id}'s
+// media. tripContext and the model settings default to job {id}'s.
+// Responds with the new job's ID and version.
+func handleSelectionRerun(w http.ResponseWriter, r *http.Request, jobID string) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("jobId", jobID).Msg("Handler entry: handleSelectionRerun")
+
+	if r.Method != http.MethodPost {
+		log.Warn().Str("param", "method").Msg("Method not allowed")
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		SessionID      string   `json:"sessionId"`
+		LockedKeys     []string `json:"lockedKeys"`
+		ExcludedKeys   []string `json:"excludedKeys"`
+		TripContext    string   `json:"tripContext,omitempty"`
+		DebugArtifacts bool     `json:"debugArtifacts,omitempty"`
+		ai.ModelConfig          // DDR-155, DDR-170
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug().Err(err).Msg("Request body decoding failed")
+		log.Warn().Str("param", "body").Msg("Invalid request body")
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validateSessionID(req.SessionID); err != nil {
+		log.Warn().Str("param", "sessionId").Msg("SessionId validation failed")
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !ensureSessionOwner(w, r, req.SessionID) {
+		return
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+
+	ctx := r.Context()
+	prev, err := sessionStore.GetSelectionJob(ctx, req.SessionID, jobID)
+	if err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("Failed to read selection job")
+		httpError(w, http.StatusInternalServerError, "failed to read job")
+		return
+	}
+	if prev == nil {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if prev.Status != "complete" {
+		httpError(w, http.StatusConflict, "selection job is not complete")
+		return
+	}
+
+	locked, excluded, err := rerunConstraints(prev, req.LockedKeys, req.ExcludedKeys)
+	if err != nil {
+		log.Warn().Err(err).Str("jobId", jobID).Msg("Invalid re-run constraints")
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	modelCfg := req.ModelConfig
+	if modelCfg == (ai.ModelConfig{}) {
+		modelCfg = ai.ModelConfig{Model: prev.Model, Thinking: prev.Thinking, Temperature: prev.Temperature, TopP: prev.TopP}
+	}
+	modelCfg, err = modelCfg.Normalize()
+	if err != nil {
+		log.Warn().Err(err).Str("param", "model").Msg("Invalid model settings")
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	model := ai.DefaultModelName
+	if modelCfg.Model != "" {
+		model = modelCfg.Model
+	}
+	tripContext := req.TripContext
+	if tripContext == "" {
+		tripContext = prev.TripContext
+	}
+
+	newID := jobs.GenerateID("sel-")
+	pending := &store.SelectionJob{
+		ID:           newID,
+		Status:       "pending",
+		RerunOf:      jobID,
+		Version:      prev.Revision() + 1,
+		LockedKeys:   locked,
+		ExcludedKeys: excluded,
+	}
+	if err := sessionStore.PutSelectionJob(ctx, req.SessionID, pending); err != nil {
+		log.Error().Err(err).Str("jobId", newID).Msg("Failed to persist pending selection re-run")
+		httpError(w, http.StatusInternalServerError, "failed to create job")
+		return
+	}
+
+	mediaKeys, ok := listSelectionMediaKeys(w, req.SessionID)
+	if !ok {
+		return
+	}
+	sfnInput := map[string]interface{}{
+		"sessionId":      req.SessionID,
+		"jobId":          newID,
+		"tripContext":    tripContext,
+		"model":          model,
+		"thinking":       modelCfg.Thinking,
+		"temperature":    modelCfg.Temperature,
+		"topP":           modelCfg.TopP,
+		"mediaKeys":      mediaKeys,
+		"debugArtifacts": req.DebugArtifacts,
+	}
+	if !dispatchSelection(w, req.SessionID, newID, sfnInput) {
+		return
+	}
+	log.Info().
+		Str("jobId", newID).
+		Str("rerunOf", jobID).
+		Int("version", pending.Version).
+		Int("locked", len(locked)).
+		Int("excluded", len(excluded)).
+		Msg("Selection re-run started")
+
+	respondJSON(w, http.StatusAccepted, map[string]interface{}{
+		"id":      newID,
+		"version": pending.Version,
+	})
+}
+
+// rerunConstraints returns the locked and excluded keys without
+// duplicates. Every key must be one of job's media, and none may be both
+// locked and excluded.
+func rerunConstraints(job *store.SelectionJob, lockedKeys, excludedKeys []string) ([]string, []string, error) {
+	known := make(map[string]bool)
+	for _, it := range job.Selected {
+		known[it.Key] = true
+	}
+	for _, it := range job.Excluded {
+		known[it.Key] = true
+	}
+	for _, it := range job.Unprocessed {
+		known[it.Key] = true
+	}
+
+	seen := make(map[string]string) // key → "locked" or "excluded"
+	collect := func(keys []string, as string) ([]string, error) {
+		var out []string
+		for _, key := range keys {
+			if !known[key] {
+				return nil, fmt.Errorf("key not in selection: %s", key)
+			}
+			switch seen[key] {
+			case "":
+				seen[key] = as
+				out = append(out, key)
+			case as:
+			default:
+				return nil, fmt.Errorf("key both locked and excluded: %s", key)
+			}
+		}
+		return out, nil
+	}
+	locked, err := collect(lockedKeys, "locked")
+	if err != nil {
+		return nil, nil, err
+	}
+	excluded, err := collect(excludedKeys, "excluded")
+	if err != nil {
+		return nil, nil, err
+	}
+	return locked, excluded, nil
+}
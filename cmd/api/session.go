@@ -4,8 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
+	"github.com/fpang/ai-social-media-helper/internal/store"
 )
 
 // --- Session Invalidation (DDR-037, DDR-050: DynamoDB-backed) ---
@@ -60,6 +65,7 @@ func handleSessionInvalidate(w http.ResponseWriter, r *http.Request) {
 	if req.FromStep == "enhancement" || req.FromStep == "selection" || req.FromStep == "triage" {
 		go cleanupS3Prefix(req.SessionID, "enhanced/")
 		go cleanupS3Prefix(req.SessionID, "thumbnails/")
+		forgetThumbnailIndex(req.SessionID) // DDR-091: stale entries fall back to regeneration
 	}
 
 	log.Info().
@@ -72,3 +78,401 @@ func handleSessionInvalidate(w http.ResponseWriter, r *http.Request) {
 		"invalidated": deletedSKs,
 	})
 }
+
+// --- Session listing and management (DDR-098) ---
+
+// GET /api/sessions
+//
+// Lists the caller's sessions, newest first, with file counts and job
+// summaries. Sessions expire with the rest of their data (store.SessionTTL).
+func handleSessionList(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleSessionList")
+
+	if r.Method != http.MethodGet {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	userSub := getUserSub(r)
+	if userSub == "" {
+		httpError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+
+	sessions, err := sessionStore.ListSessions(r.Context(), userSub)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list sessions")
+		httpError(w, http.StatusInternalServerError, "failed to list sessions")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"sessions": sessions,
+	})
+}
+
+// GET /api/sessions/{id}
+// DELETE /api/sessions/{id}
+//
+// DELETE removes the session's S3 objects and DynamoDB items. S3 is deleted
+// first so a failure leaves the session listed and the delete can be retried.
+// An encrypted session's key is then scheduled for deletion (DDR-164), before
+// the records that name it are gone.
+func handleSessionDetail(w http.ResponseWriter, r *http.Request, sessionID string) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("sessionId", sessionID).Msg("Handler entry: handleSessionDetail")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if err := validateSessionID(sessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+
+	session, ok := describeOwnedSession(w, r, sessionID)
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		respondJSON(w, http.StatusOK, session)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	objects, err := deleteS3Prefix(ctx, sessionID+"/")
+	if err != nil {
+		log.Error().Err(err).Str("sessionId", sessionID).Int("deleted", objects).Msg("Failed to delete session objects")
+		httpError(w, http.StatusInternalServerError, "failed to delete session files")
+		return
+	}
+	if session.KMSKeyID != "" {
+		if err := sessionKeys.Retire(ctx, sessionID, session.KMSKeyID); err != nil {
+			log.Error().Err(err).Str("sessionId", sessionID).Msg("Failed to retire session key")
+			httpError(w, http.StatusInternalServerError, "failed to retire session key")
+			return
+		}
+	}
+	items, err := sessionStore.DeleteSession(ctx, sessionID)
+	if err != nil {
+		log.Error().Err(err).Str("sessionId", sessionID).Msg("Failed to delete session records")
+		httpError(w, http.StatusInternalServerError, "failed to delete session records")
+		return
+	}
+	forgetThumbnailIndex(sessionID) // DDR-091
+
+	log.Info().
+		Str("sessionId", sessionID).
+		Int("objects", objects).
+		Int("items", items).
+		Msg("Session deleted")
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"id":             sessionID,
+		"deletedObjects": objects,
+		"deletedItems":   items,
+	})
+}
+
+// describeOwnedSession loads a session summary and checks that the caller
+// owns it. Unlike ensureSessionOwner it never creates a session. Sessions
+// owned by someone else are reported as not found so IDs cannot be probed.
+func describeOwnedSession(w http.ResponseWriter, r *http.Request, sessionID string) (*store.SessionRecord, bool) {
+	session, err := sessionStore.DescribeSession(r.Context(), sessionID)
+	if err != nil {
+		log.Error().Err(err).Str("sessionId", sessionID).Msg("Failed to describe session")
+		httpError(w, http.StatusInternalServerError, "failed to read session")
+		return nil, false
+	}
+	if session == nil {
+		httpError(w, http.StatusNotFound, "not found")
+		return nil, false
+	}
+	// Legacy sessions without an owner stay accessible, as in VerifySessionOwner.
+	if userSub := getUserSub(r); userSub != "" && session.OwnerSub != "" && session.OwnerSub != userSub {
+		log.Warn().Str("sessionId", sessionID).Msg("Session ownership mismatch — reporting not found")
+		httpError(w, http.StatusNotFound, "not found")
+		return nil, false
+	}
+	return session, true
+}
+
+// --- Duplicate session merge (DDR-113) ---
+
+// apiGatewayTimeout is API Gateway's integration timeout. The merge has to
+// finish inside it for the caller to see the result.
+const apiGatewayTimeout = 29 * time.Second
+
+// POST /api/sessions/{id}/merge
+// Body: {"sourceSessionId": "uuid"}
+//
+// Moves the source session's files and job history into {id} and deletes the
+// source. Both sessions must belong to the caller and have no running jobs.
+// Files take on the target's encryption, so an encrypted source can only be
+// merged into an encrypted target; the source's key is retired (DDR-164).
+// S3 objects are copied first and the source prefix is deleted last, so a
+// failure part-way leaves the source intact and the merge can be retried.
+func handleSessionMerge(w http.ResponseWriter, r *http.Request, sessionID string) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("sessionId", sessionID).Msg("Handler entry: handleSessionMerge")
+
+	if r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req struct {
+		SourceSessionID string `json:"sourceSessionId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	for _, id := range []string{sessionID, req.SourceSessionID} {
+		if err := validateSessionID(id); err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if req.SourceSessionID == sessionID {
+		httpError(w, http.StatusBadRequest, "cannot merge a session into itself")
+		return
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+
+	sessions := make([]*store.SessionRecord, 2)
+	for i, id := range []string{sessionID, req.SourceSessionID} {
+		session, ok := describeOwnedSession(w, r, id)
+		if !ok {
+			return
+		}
+		for _, job := range session.Jobs {
+			if job.Status == "pending" || job.Status == "processing" || job.Status == "partial" {
+				httpError(w, http.StatusConflict, "a job is still running in session "+id)
+				return
+			}
+		}
+		sessions[i] = session
+	}
+	target, source := sessions[0], sessions[1]
+	if source.Encrypted && !target.Encrypted {
+		httpError(w, http.StatusConflict, "an encrypted session can only be merged into an encrypted session")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), apiGatewayTimeout)
+	defer cancel()
+
+	objects, err := copyS3Prefix(ctx, req.SourceSessionID+"/", sessionID+"/", s3util.Encryption{KMSKeyID: target.KMSKeyID})
+	if err != nil {
+		log.Error().Err(err).Str("sessionId", sessionID).Str("sourceSessionId", req.SourceSessionID).Int("copied", objects).Msg("Failed to copy session objects")
+		httpError(w, http.StatusInternalServerError, "failed to copy session files")
+		return
+	}
+	items, err := sessionStore.MergeSession(ctx, sessionID, req.SourceSessionID)
+	if err != nil {
+		log.Error().Err(err).Str("sessionId", sessionID).Str("sourceSessionId", req.SourceSessionID).Msg("Failed to merge session records")
+		httpError(w, http.StatusInternalServerError, "failed to merge session records")
+		return
+	}
+	if _, err := deleteS3Prefix(ctx, req.SourceSessionID+"/"); err != nil {
+		// Records are already merged; leftover objects expire with the bucket lifecycle (DDR-035).
+		log.Warn().Err(err).Str("sourceSessionId", req.SourceSessionID).Msg("Failed to delete merged session objects")
+	}
+	if source.KMSKeyID != "" {
+		if err := sessionKeys.Retire(ctx, req.SourceSessionID, source.KMSKeyID); err != nil {
+			log.Error().Err(err).Str("sourceSessionId", req.SourceSessionID).Msg("Failed to retire merged session key")
+		}
+	}
+	forgetThumbnailIndex(sessionID) // DDR-091
+	forgetThumbnailIndex(req.SourceSessionID)
+
+	log.Info().
+		Str("sessionId", sessionID).
+		Str("sourceSessionId", req.SourceSessionID).
+		Int("objects", objects).
+		Int("items", items).
+		Msg("Session merged")
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"id":            sessionID,
+		"mergedFrom":    req.SourceSessionID,
+		"copiedObjects": objects,
+		"mergedItems":   items,
+	})
+}
+
+// --- Metadata policy (DDR-135) ---
+
+// GET  /api/sessions/{id}/metadata-policy
+// POST /api/sessions/{id}/metadata-policy
+// Body: {"metadataPolicy": "strip-gps"|"strip-all"|"preserve"}
+//
+// The policy decides what EXIF/XMP metadata download bundles and Instagram
+// uploads keep. Sessions that never set one strip GPS.
+func handleSessionMetadataPolicy(w http.ResponseWriter, r *http.Request, sessionID string) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("sessionId", sessionID).Msg("Handler entry: handleSessionMetadataPolicy")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if err := validateSessionID(sessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+	if !ensureSessionOwner(w, r, sessionID) {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		respondJSON(w, http.StatusOK, map[string]string{
+			"metadataPolicy": string(sessionMetadataPolicy(r.Context(), sessionID)),
+		})
+		return
+	}
+
+	var req struct {
+		MetadataPolicy string `json:"metadataPolicy"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	policy, err := media.ParseMetadataPolicy(req.MetadataPolicy)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := sessionStore.UpdateSessionMetadataPolicy(r.Context(), sessionID, string(policy)); err != nil {
+		log.Error().Err(err).Str("sessionId", sessionID).Msg("Failed to update metadata policy")
+		httpError(w, http.StatusInternalServerError, "failed to update metadata policy")
+		return
+	}
+	log.Info().Str("sessionId", sessionID).Str("policy", string(policy)).Msg("Session metadata policy updated")
+	respondJSON(w, http.StatusOK, map[string]string{"metadataPolicy": string(policy)})
+}
+
+// sessionMetadataPolicy returns the session's metadata policy, falling back
+// to the default when it is unset or cannot be read.
+func sessionMetadataPolicy(ctx context.Context, sessionID string) media.MetadataPolicy {
+	if sessionStore == nil {
+		return media.DefaultMetadataPolicy
+	}
+	return media.SessionMetadataPolicy(ctx, sessionStore, sessionID)
+}
+
+// --- Session encryption (DDR-164) ---
+
+// GET  /api/sessions/{id}/encryption
+// POST /api/sessions/{id}/encryption
+// Body: {"encrypted": true}
+//
+// Gives the session its own KMS key. Everything written under the session
+// from then on is encrypted with it, and deleting the session schedules the
+// key for deletion, after which nothing left of the session can be read.
+// Encryption must be turned on before the first upload and cannot be turned
+// off again.
+func handleSessionEncryption(w http.ResponseWriter, r *http.Request, sessionID string) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("sessionId", sessionID).Msg("Handler entry: handleSessionEncryption")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if err := validateSessionID(sessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+	if !ensureSessionOwner(w, r, sessionID) {
+		return
+	}
+
+	session, err := sessionStore.GetSession(r.Context(), sessionID)
+	if err != nil || session == nil {
+		log.Error().Err(err).Str("sessionId", sessionID).Msg("Failed to read session")
+		httpError(w, http.StatusInternalServerError, "failed to read session")
+		return
+	}
+	if r.Method == http.MethodGet {
+		respondJSON(w, http.StatusOK, map[string]bool{"encrypted": session.KMSKeyID != ""})
+		return
+	}
+
+	var req struct {
+		Encrypted bool `json:"encrypted"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	switch {
+	case req.Encrypted == (session.KMSKeyID != ""):
+		respondJSON(w, http.StatusOK, map[string]bool{"encrypted": req.Encrypted})
+		return
+	case !req.Encrypted:
+		httpError(w, http.StatusBadRequest, "encryption cannot be turned off")
+		return
+	}
+
+	uploaded, err := hasS3Objects(r.Context(), sessionID+"/")
+	if err != nil {
+		log.Error().Err(err).Str("sessionId", sessionID).Msg("Failed to check for session files")
+		httpError(w, http.StatusInternalServerError, "failed to read session files")
+		return
+	}
+	if uploaded || len(session.UploadedKeys) > 0 {
+		httpError(w, http.StatusConflict, "encryption must be turned on before the first upload")
+		return
+	}
+
+	keyARN, err := sessionKeys.Create(r.Context(), sessionID)
+	if err != nil {
+		log.Error().Err(err).Str("sessionId", sessionID).Msg("Failed to create session key")
+		httpError(w, http.StatusInternalServerError, "failed to create session key")
+		return
+	}
+	if err := sessionStore.SetSessionKMSKey(r.Context(), sessionID, keyARN); err != nil {
+		log.Error().Err(err).Str("sessionId", sessionID).Msg("Failed to record session key")
+		if retireErr := sessionKeys.Retire(r.Context(), sessionID, keyARN); retireErr != nil {
+			log.Warn().Err(retireErr).Str("sessionId", sessionID).Msg("Failed to retire unrecorded session key")
+		}
+		httpError(w, http.StatusInternalServerError, "failed to record session key")
+		return
+	}
+	log.Info().Str("sessionId", sessionID).Msg("Session encryption turned on")
+	respondJSON(w, http.StatusOK, map[string]bool{"encrypted": true})
+}
+
+// sessionEncryption returns the encryption for new objects of the session,
+// read from its record rather than the cached alias lookup the workers use,
+// since the API is where encryption is turned on.
+func sessionEncryption(ctx context.Context, sessionID string) (s3util.Encryption, error) {
+	if sessionStore == nil {
+		return s3util.Encryption{}, nil
+	}
+	session, err := sessionStore.GetSession(ctx, sessionID)
+	if err != nil || session == nil {
+		return s3util.Encryption{}, err
+	}
+	return s3util.Encryption{KMSKeyID: session.KMSKeyID}, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/fpang/ai-social-media-helper/internal/reconcile"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Storage reconciliation (DDR-148) ---
+
+// GET /api/admin/storage-report — the latest report of the storage
+// reconciliation job: sessions with objects but no session record, sessions
+// with storage but no jobs, and originals missing their thumbnail.
+//
+// The report covers the whole bucket, so only subs listed in ADMIN_SUBS may
+// read it. 404 until the job has produced a report.
+func handleAdminStorageReport(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleAdminStorageReport")
+
+	if r.Method != http.MethodGet {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	sub := getUserSub(r)
+	if sub == "" || !adminSubs[sub] {
+		log.Warn().Str("sub", sub).Msg("Storage report access not authorized")
+		httpError(w, http.StatusForbidden, "admin access required")
+		return
+	}
+	if inventoryBucket == "" {
+		httpError(w, http.StatusServiceUnavailable, "storage reconciliation is not configured")
+		return
+	}
+
+	out, err := s3Client.GetObject(r.Context(), &s3.GetObjectInput{
+		Bucket: aws.String(inventoryBucket),
+		Key:    aws.String(reconcile.LatestReportKey),
+	})
+	var missing *s3types.NoSuchKey
+	if errors.As(err, &missing) {
+		httpError(w, http.StatusNotFound, "no storage report yet")
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("bucket", inventoryBucket).Msg("Failed to read storage report")
+		httpError(w, http.StatusInternalServerError, "failed to read storage report")
+		return
+	}
+	defer out.Body.Close()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, out.Body); err != nil {
+		log.Warn().Err(err).Msg("Failed to stream storage report")
+	}
+}
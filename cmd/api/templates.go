@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/jobs"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Post group templates (DDR-122) ---
+
+// Length limits for template fields. The caption limit matches Instagram's.
+const (
+	maxTemplateName     = 80
+	maxTemplateCaption  = 2200
+	maxTemplateHashtags = 30
+	maxTemplateFeedback = 500
+)
+
+// GET  /api/templates — list the caller's templates
+// POST /api/templates — save a template
+// Body: {"name": "Weekly dog photos", "groupLabel": "...", "captionSkeleton": "...", "hashtags": [...], "enhancementFeedback": "..."}
+//
+// Templates are per user and outlive sessions, so they need a signed-in caller.
+func handleTemplates(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleTemplates")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	owner, ok := signedInUser(w, r)
+	if !ok {
+		return
+	}
+
+	templates, err := sessionStore.ListPostTemplates(r.Context(), owner)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list post templates")
+		httpError(w, http.StatusInternalServerError, "failed to list templates")
+		return
+	}
+	if r.Method == http.MethodGet {
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"templates": templates,
+		})
+		return
+	}
+
+	var req store.PostTemplate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	t, err := normalizeTemplate(req)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(templates) >= store.MaxPostTemplates {
+		httpError(w, http.StatusConflict, fmt.Sprintf("at most %d templates can be saved; delete one first", store.MaxPostTemplates))
+		return
+	}
+
+	t.ID = jobs.GenerateID("tpl-")
+	t.CreatedAt = time.Now().Unix()
+	if err := sessionStore.PutPostTemplate(r.Context(), owner, t); err != nil {
+		log.Error().Err(err).Msg("Failed to save post template")
+		httpError(w, http.StatusInternalServerError, "failed to save template")
+		return
+	}
+
+	log.Info().Str("templateId", t.ID).Str("name", t.Name).Msg("Post template saved")
+	respondJSON(w, http.StatusCreated, t)
+}
+
+// GET    /api/templates/{id}
+// DELETE /api/templates/{id}
+func handleTemplateRoutes(w http.ResponseWriter, r *http.Request) {
+	templateID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/templates/"), "/")
+	if !strings.HasPrefix(templateID, "tpl-") || strings.Contains(templateID, "/") {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Str("templateId", templateID).Msg("Handler entry: handleTemplateRoutes")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	owner, ok := signedInUser(w, r)
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		if err := sessionStore.DeletePostTemplate(r.Context(), owner, templateID); err != nil {
+			log.Error().Err(err).Str("templateId", templateID).Msg("Failed to delete post template")
+			httpError(w, http.StatusInternalServerError, "failed to delete template")
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]string{"id": templateID})
+		return
+	}
+
+	t, err := sessionStore.GetPostTemplate(r.Context(), owner, templateID)
+	if err != nil {
+		log.Error().Err(err).Str("templateId", templateID).Msg("Failed to read post template")
+		httpError(w, http.StatusInternalServerError, "failed to read template")
+		return
+	}
+	if t == nil {
+		httpError(w, http.StatusNotFound, "not found")
+		return
+	}
+	respondJSON(w, http.StatusOK, t)
+}
+
+// signedInUser returns the signed-in caller's sub, writing an error response
+// if there is none or the store is not configured.
+func signedInUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	owner := getUserSub(r)
+	if owner == "" {
+		httpError(w, http.StatusUnauthorized, "authentication required")
+		return "", false
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return "", false
+	}
+	return owner, true
+}
+
+// loadCaptionTemplate resolves the templateId of a description request to
+// the caption skeleton and hashtags it carries. An empty ID yields a nil
+// template; an unknown one is an error so a stale UI does not silently
+// generate an off-format caption.
+func loadCaptionTemplate(r *http.Request, templateID string) (*store.PostTemplate, error) {
+	if templateID == "" {
+		return nil, nil
+	}
+	owner := getUserSub(r)
+	if owner == "" || sessionStore == nil {
+		return nil, fmt.Errorf("templates require a signed-in user")
+	}
+	t, err := sessionStore.GetPostTemplate(r.Context(), owner, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("template %s not found", templateID)
+	}
+	sessionStore.TouchPostTemplate(r.Context(), owner, templateID)
+	return t, nil
+}
+
+// normalizeTemplate trims and validates the user-editable template fields.
+// Hashtags are stored without the leading '#', as the description step
+// returns them.
+func normalizeTemplate(req store.PostTemplate) (*store.PostTemplate, error) {
+	t := &store.PostTemplate{
+		Name:                strings.TrimSpace(req.Name),
+		GroupLabel:          strings.TrimSpace(req.GroupLabel),
+		CaptionSkeleton:     strings.TrimSpace(req.CaptionSkeleton),
+		EnhancementFeedback: strings.TrimSpace(req.EnhancementFeedback),
+	}
+	seen := map[string]bool{}
+	for _, tag := range req.Hashtags {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		if strings.ContainsAny(tag, " \t\n#") {
+			return nil, fmt.Errorf("invalid hashtag %q", tag)
+		}
+		seen[strings.ToLower(tag)] = true
+		t.Hashtags = append(t.Hashtags, tag)
+	}
+
+	switch {
+	case t.Name == "":
+		return nil, fmt.Errorf("name is required")
+	case len(t.Name) > maxTemplateName:
+		return nil, fmt.Errorf("name must be at most %d characters", maxTemplateName)
+	case len(t.CaptionSkeleton) > maxTemplateCaption:
+		return nil, fmt.Errorf("caption skeleton must be at most %d characters", maxTemplateCaption)
+	case len(t.Hashtags) > maxTemplateHashtags:
+		return nil, fmt.Errorf("at most %d hashtags are allowed", maxTemplateHashtags)
+	case len(t.EnhancementFeedback) > maxTemplateFeedback:
+		return nil, fmt.Errorf("enhancement feedback must be at most %d characters", maxTemplateFeedback)
+	case t.GroupLabel == "" && t.CaptionSkeleton == "" && len(t.Hashtags) == 0 && t.EnhancementFeedback == "":
+		return nil, fmt.Errorf("a template needs a label, caption skeleton, hashtags or enhancement feedback")
+	}
+	return t, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// --- Thumbnail existence index (DDR-091) ---
+//
+// The MediaProcess Lambda records every pre-generated thumbnail in the
+// file-processing table. The thumbnail handler consults that index to decide
+// between streaming the pre-generated JPEG and regenerating from the original,
+// without a speculative S3 request. Index reads are cached per session so a
+// grid of thumbnails costs one DynamoDB query, not one per image.
+
+// thumbIndexTTL bounds how stale a cached session index can be. Short enough
+// that thumbnails finishing during upload processing are picked up quickly.
+const thumbIndexTTL = 30 * time.Second
+
+// thumbIndexMaxSessions caps the number of cached session indexes.
+const thumbIndexMaxSessions = 256
+
+type thumbIndexEntry struct {
+	thumbs    map[string]string // filename → thumbnail S3 key
+	fetchedAt time.Time
+}
+
+var (
+	thumbIndexMu    sync.Mutex
+	thumbIndexCache = make(map[string]*thumbIndexEntry)
+)
+
+// lookupThumbnail returns the pre-generated thumbnail key for an uploaded
+// file, or ok=false when none is indexed (or the index is unavailable).
+func lookupThumbnail(ctx context.Context, sessionID, filename string) (string, bool) {
+	if fileProcessStore == nil {
+		return "", false
+	}
+
+	thumbIndexMu.Lock()
+	entry, cached := thumbIndexCache[sessionID]
+	thumbIndexMu.Unlock()
+
+	if !cached || time.Since(entry.fetchedAt) > thumbIndexTTL {
+		thumbs, err := fileProcessStore.GetThumbnailIndex(ctx, sessionID)
+		if err != nil {
+			log.Warn().Err(err).Str("sessionId", sessionID).Msg("Failed to read thumbnail index — falling back to regeneration")
+			return "", false
+		}
+		entry = &thumbIndexEntry{thumbs: thumbs, fetchedAt: time.Now()}
+		storeThumbIndex(sessionID, entry)
+	}
+
+	key, ok := entry.thumbs[filename]
+	return key, ok
+}
+
+// forgetThumbnailIndex drops a cached session index, e.g. after an indexed
+// thumbnail turned out to be missing from S3 (deleted by session cleanup).
+func forgetThumbnailIndex(sessionID string) {
+	thumbIndexMu.Lock()
+	delete(thumbIndexCache, sessionID)
+	thumbIndexMu.Unlock()
+}
+
+func storeThumbIndex(sessionID string, entry *thumbIndexEntry) {
+	thumbIndexMu.Lock()
+	defer thumbIndexMu.Unlock()
+
+	if len(thumbIndexCache) >= thumbIndexMaxSessions {
+		for id, e := range thumbIndexCache {
+			if time.Since(e.fetchedAt) > thumbIndexTTL {
+				delete(thumbIndexCache, id)
+			}
+		}
+		// Still full of fresh entries: drop an arbitrary one.
+		for id := range thumbIndexCache {
+			if len(thumbIndexCache) < thumbIndexMaxSessions {
+				break
+			}
+			delete(thumbIndexCache, id)
+		}
+	}
+	thumbIndexCache[sessionID] = entry
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/sfn"
 	"github.com/fpang/ai-social-media-helper/internal/ai"
 	"github.com/fpang/ai-social-media-helper/internal/jobs"
+	"github.com/fpang/ai-social-media-helper/internal/media"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 	"github.com/rs/zerolog/log"
 )
@@ -22,8 +23,14 @@ import (
 // --- Triage Endpoints (DDR-050, DDR-052: DynamoDB + Step Functions) ---
 
 // POST /api/triage/init
-// Body: {"sessionId": "uuid", "expectedFileCount": 36, "model": "optional-model-name"}
+// Body: {"sessionId": "uuid", "expectedFileCount": 36, "model": "optional-model-name", "thinking": "", "temperature": 0.4, "topP": 0.9, "noCache": false, "keyframes": 8}
 // Returns: {"id": "triage-xxx", "sessionId": "uuid"}
+//
+// thinking overrides the Gemini thinking setting for this job (DDR-155),
+// temperature and topP its sampling (DDR-170), noCache asks Gemini
+// again even for files judged before (DDR-166), and keyframes (2-16)
+// judges every video from that many frames instead of the clip (DDR-186);
+// all are kept on the job until finalize starts the pipeline.
 func handleTriageInit(w http.ResponseWriter, r *http.Request) {
 	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleTriageInit")
 
@@ -35,7 +42,10 @@ func handleTriageInit(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		SessionID         string `json:"sessionId"`
 		ExpectedFileCount int    `json:"expectedFileCount"`
-		Model             string `json:"model,omitempty"`
+		NoCache           bool   `json:"noCache,omitempty"`        // DDR-166
+		Keyframes         int    `json:"keyframes,omitempty"`      // DDR-186
+		DebugArtifacts    bool   `json:"debugArtifacts,omitempty"` // DDR-106
+		ai.ModelConfig           // DDR-155, DDR-170
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		httpError(w, http.StatusBadRequest, "invalid request body")
@@ -54,6 +64,15 @@ func handleTriageInit(w http.ResponseWriter, r *http.Request) {
 		httpError(w, http.StatusBadRequest, "expectedFileCount must be > 0")
 		return
 	}
+	modelCfg, err := req.ModelConfig.Normalize()
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := media.ValidateKeyframes(req.Keyframes); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	// Risk 15: Verify or establish session ownership before any processing.
 	if !ensureSessionOwner(w, r, req.SessionID) {
@@ -61,8 +80,8 @@ func handleTriageInit(w http.ResponseWriter, r *http.Request) {
 	}
 
 	model := ai.DefaultModelName
-	if req.Model != "" {
-		model = req.Model
+	if modelCfg.Model != "" {
+		model = modelCfg.Model
 	}
 
 	jobID := jobs.GenerateID("triage-")
@@ -74,6 +93,12 @@ func handleTriageInit(w http.ResponseWriter, r *http.Request) {
 			ID:                jobID,
 			Status:            "pending",
 			Model:             model,
+			Thinking:          modelCfg.Thinking,
+			Temperature:       modelCfg.Temperature,
+			TopP:              modelCfg.TopP,
+			NoCache:           req.NoCache,
+			Keyframes:         req.Keyframes,
+			DebugArtifacts:    req.DebugArtifacts,
 			ExpectedFileCount: req.ExpectedFileCount,
 		}
 		if err := sessionStore.PutTriageJob(context.Background(), req.SessionID, pendingJob); err != nil {
@@ -179,7 +204,13 @@ func handleTriageFinalize(w http.ResponseWriter, r *http.Request) {
 		"sessionId":         req.SessionID,
 		"jobId":             req.JobID,
 		"model":             model,
+		"thinking":          job.Thinking,
+		"temperature":       job.Temperature, // DDR-170: null for the model's default
+		"topP":              job.TopP,
+		"noCache":           job.NoCache,
+		"keyframes":         job.Keyframes, // DDR-186: MediaProcess renders the sheets
 		"expectedFileCount": job.ExpectedFileCount,
+		"debugArtifacts":    job.DebugArtifacts,
 	})
 	_, err = sfnClient.StartExecution(context.Background(), &sfn.StartExecutionInput{
 		StateMachineArn: aws.String(triageSfnArn),
@@ -249,7 +280,16 @@ func handleTriageUpdateFiles(w http.ResponseWriter, r *http.Request) {
 }
 
 // POST /api/triage/start
-// Body: {"sessionId": "uuid", "model": "optional-model-name"}
+// Body: {"sessionId": "uuid", "model": "optional-model-name", "thinking": "", "temperature": 0.4, "topP": 0.9, "noCache": false, "debugArtifacts": false}
+//
+// debugArtifacts keeps prompts, raw model responses, and compressed videos
+// under {sessionId}/debug/{jobId}/ for bug reports (DDR-106).
+// thinking overrides the Gemini thinking setting for this job: a level
+// (minimal, low, medium, high), off, dynamic, or a token budget (DDR-155).
+// temperature (0-2) and topP (0-1) override the model's sampling, and
+// model must be on the server's allowlist (DDR-170).
+// noCache skips the Gemini response cache, so every file is judged afresh
+// (DDR-166).
 func handleTriageStart(w http.ResponseWriter, r *http.Request) {
 	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleTriageStart")
 
@@ -260,8 +300,10 @@ func handleTriageStart(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		SessionID string `json:"sessionId"`
-		Model     string `json:"model,omitempty"`
+		SessionID      string `json:"sessionId"`
+		NoCache        bool   `json:"noCache,omitempty"` // DDR-166
+		DebugArtifacts bool   `json:"debugArtifacts,omitempty"`
+		ai.ModelConfig        // DDR-155, DDR-170
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Debug().Err(err).Msg("Request body decoding failed")
@@ -289,9 +331,15 @@ func handleTriageStart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	modelCfg, err := req.ModelConfig.Normalize()
+	if err != nil {
+		log.Warn().Err(err).Str("param", "model").Msg("Invalid model settings")
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	model := ai.DefaultModelName
-	if req.Model != "" {
-		model = req.Model
+	if modelCfg.Model != "" {
+		model = modelCfg.Model
 	}
 
 	jobID := jobs.GenerateID("triage-")
@@ -321,18 +369,25 @@ func handleTriageStart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	sfnInput, _ := json.Marshal(map[string]interface{}{
-		"type":      "triage-prepare",
-		"sessionId": req.SessionID,
-		"jobId":     jobID,
-		"model":     model,
+		"type":           "triage-prepare",
+		"sessionId":      req.SessionID,
+		"jobId":          jobID,
+		"model":          model,
+		"thinking":       modelCfg.Thinking,
+		"temperature":    modelCfg.Temperature, // DDR-170: null for the model's default
+		"topP":           modelCfg.TopP,
+		"noCache":        req.NoCache,
+		"keyframes":      0, // DDR-186: keyframe mode needs MediaProcess, which this flow skips
+		"debugArtifacts": req.DebugArtifacts,
 	})
 	log.Info().
 		Str("jobId", jobID).
 		Str("sessionId", req.SessionID).
 		Str("model", model).
+		Str("thinking", modelCfg.Thinking).
 		Str("sfnArn", triageSfnArn).
 		Msg("Job dispatched to Triage Pipeline")
-	_, err := sfnClient.StartExecution(context.Background(), &sfn.StartExecutionInput{
+	_, err = sfnClient.StartExecution(context.Background(), &sfn.StartExecutionInput{
 		StateMachineArn: aws.String(triageSfnArn),
 		Input:           aws.String(string(sfnInput)),
 		Name:            aws.String(jobID),
@@ -367,6 +422,8 @@ func handleTriageRoutes(w http.ResponseWriter, r *http.Request) {
 		handleTriageResults(w, r, jobID)
 	case "confirm":
 		handleTriageConfirm(w, r, jobID)
+	case "override":
+		handleTriageOverride(w, r, jobID)
 	case "logs":
 		handleTriageLogs(w, r, jobID)
 	default:
@@ -424,6 +481,9 @@ func handleTriageResults(w http.ResponseWriter, r *http.Request, jobID string) {
 		"keep":    keepItems,
 		"discard": discardItems,
 	}
+	if job.Status == "partial" {
+		resp["partial"] = true // DDR-175: keep and discard grow until complete
+	}
 	if job.Phase != "" {
 		resp["phase"] = job.Phase
 	}
@@ -439,12 +499,43 @@ func handleTriageResults(w http.ResponseWriter, r *http.Request, jobID string) {
 	if job.TriageBatchTotal > 0 {
 		resp["triageBatchTotal"] = job.TriageBatchTotal
 	}
+	if len(job.FileProgress) > 0 && job.Status != "complete" {
+		resp["fileProgress"] = job.FileProgress // DDR-174
+		resp["analyzedFiles"] = job.AnalyzedFiles()
+	}
 	if job.Error != "" {
 		resp["error"] = job.Error
 	}
+	if len(job.DuplicateSessions) > 0 {
+		resp["duplicateSessions"] = job.DuplicateSessions // DDR-113
+	}
+	if job.Telemetry != nil {
+		resp["perJobTelemetry"] = job.Telemetry // DDR-118
+	}
+	if job.Model != "" {
+		resp["model"] = job.Model
+	}
+	if job.Thinking != "" {
+		resp["thinking"] = job.Thinking // DDR-155
+	}
+	if job.Temperature != nil {
+		resp["temperature"] = *job.Temperature // DDR-170
+	}
+	if job.TopP != nil {
+		resp["topP"] = *job.TopP // DDR-170
+	}
+	if len(job.ModelsUsed) > 0 {
+		resp["modelsUsed"] = job.ModelsUsed // DDR-169
+	}
+	if job.Keyframes > 0 {
+		resp["keyframes"] = job.Keyframes // DDR-186
+	}
+	if job.VideoStats != nil {
+		resp["videoStats"] = job.VideoStats // DDR-186
+	}
 
-	// DDR-061, DDR-063: Include per-file statuses during pending and processing phases
-	if (job.Status == "pending" || job.Status == "processing") && fileProcessStore != nil {
+	// DDR-061, DDR-063: Include per-file statuses until the job finishes
+	if (job.Status == "pending" || job.Status == "processing" || job.Status == "partial") && fileProcessStore != nil {
 		fileResults, err := fileProcessStore.GetFileResults(context.Background(), sessionID, jobID)
 		if err == nil && len(fileResults) > 0 {
 			fileStatuses := make([]map[string]interface{}, 0, len(fileResults))
@@ -461,6 +552,9 @@ func handleTriageResults(w http.ResponseWriter, r *http.Request, jobID string) {
 				if fr.Error != "" {
 					status["error"] = fr.Error
 				}
+				if fr.Progress > 0 {
+					status["progress"] = fr.Progress // DDR-161
+				}
 				fileStatuses = append(fileStatuses, status)
 			}
 			resp["fileStatuses"] = fileStatuses
@@ -510,6 +604,10 @@ func handleTriageConfirm(w http.ResponseWriter, r *http.Request, jobID string) {
 		httpError(w, http.StatusNotFound, "not found")
 		return
 	}
+	if job.Status == "partial" {
+		httpError(w, http.StatusConflict, "triage job is still analyzing")
+		return
+	}
 
 	// Build a set of valid discard keys
 	validKeys := make(map[string]bool)
@@ -642,10 +740,14 @@ func handleTriageLogs(w http.ResponseWriter, r *http.Request, _ string) {
 func handleSessionRoutes(w http.ResponseWriter, r *http.Request) {
 	rest := strings.TrimPrefix(r.URL.Path, "/api/sessions/")
 	parts := strings.SplitN(rest, "/", 2)
-	if len(parts) < 2 || parts[0] == "" {
+	if parts[0] == "" {
 		httpError(w, http.StatusNotFound, "not found")
 		return
 	}
+	if len(parts) == 1 || parts[1] == "" {
+		handleSessionDetail(w, r, parts[0]) // DDR-098
+		return
+	}
 
 	sessionID := parts[0]
 	action := parts[1]
@@ -653,6 +755,12 @@ func handleSessionRoutes(w http.ResponseWriter, r *http.Request) {
 	switch action {
 	case "file-status":
 		handleSessionFileStatus(w, r, sessionID)
+	case "merge":
+		handleSessionMerge(w, r, sessionID) // DDR-113
+	case "metadata-policy":
+		handleSessionMetadataPolicy(w, r, sessionID) // DDR-135
+	case "encryption":
+		handleSessionEncryption(w, r, sessionID) // DDR-164
 	default:
 		httpError(w, http.StatusNotFound, "not found")
 	}
@@ -692,6 +800,9 @@ func handleSessionFileStatus(w http.ResponseWriter, r *http.Request, sessionID s
 		if fr.Error != "" {
 			status["error"] = fr.Error
 		}
+		if fr.Progress > 0 {
+			status["progress"] = fr.Progress // DDR-161
+		}
 		fileStatuses = append(fileStatuses, status)
 	}
 
@@ -1,13 +1,10 @@
 package main
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 	"path/filepath"
-	"time"
 
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/rs/zerolog/log"
 )
 
@@ -22,6 +19,9 @@ import (
 //   - contentType must be in the allowed media type list
 //   - Content-Type is included in the presigned signature
 //   - Size limits are enforced at processing time (triage/selection start)
+//
+// For an encrypted session the URL is signed for SSE-KMS under the session's
+// key, and the response's headers must be sent with the PUT (DDR-164).
 func handleUploadURL(w http.ResponseWriter, r *http.Request) {
 	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleUploadURL")
 
@@ -75,19 +75,16 @@ func handleUploadURL(w http.ResponseWriter, r *http.Request) {
 
 	key := sessionID + "/" + filename
 
-	result, err := presigner.PresignPutObject(context.Background(), &s3.PutObjectInput{
-		Bucket:      &mediaBucket,
-		Key:         &key,
-		ContentType: &contentType,
-	}, s3.WithPresignExpires(15*time.Minute))
+	uploadURL, headers, err := presignPut(r.Context(), key, contentType)
 	if err != nil {
 		log.Error().Err(err).Str("key", key).Msg("Failed to generate presigned URL")
 		httpError(w, http.StatusInternalServerError, "failed to generate upload URL")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]string{
-		"uploadUrl": result.URL,
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"uploadUrl": uploadURL,
 		"key":       key,
+		"headers":   headers,
 	})
 }
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/uploadadvice"
+)
+
+// --- Upload Advisor (DDR-128) ---
+
+// POST /api/upload/advice
+//
+// Body: {"workflow":"triage","images":{"count":n,"bytes":n},
+// "videos":{"count":n,"bytes":n,"durationSeconds":n},"throughputBytesPerSec":n}
+//
+// Stateless: nothing is stored and no session is required, so the browser
+// can ask before it creates the session.
+func handleUploadAdvice(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleUploadAdvice")
+
+	if r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req uploadadvice.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if req.ThroughputBytesPerSec <= 0 || math.IsInf(req.ThroughputBytesPerSec, 0) || math.IsNaN(req.ThroughputBytesPerSec) {
+		httpError(w, http.StatusBadRequest, "throughputBytesPerSec must be positive")
+		return
+	}
+	if req.Images.Count < 0 || req.Images.Bytes < 0 || req.Videos.Count < 0 || req.Videos.Bytes < 0 || req.Videos.DurationSeconds < 0 {
+		httpError(w, http.StatusBadRequest, "counts, bytes and duration must not be negative")
+		return
+	}
+
+	resp, err := uploadadvice.Advise(req)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	log.Info().
+		Str("workflow", req.Workflow).
+		Int("images", req.Images.Count).
+		Int("videos", req.Videos.Count).
+		Float64("throughputBytesPerSec", req.ThroughputBytesPerSec).
+		Str("recommendation", resp.Recommendation).
+		Int("originalSeconds", resp.OriginalSeconds).
+		Int("recommendedSeconds", resp.RecommendedSeconds).
+		Msg("Upload advice computed")
+	respondJSON(w, http.StatusOK, resp)
+}
@@ -42,6 +42,9 @@ type multipartInitRequest struct {
 	ContentType string `json:"contentType"`
 	FileSize    int64  `json:"fileSize"`
 	ChunkSize   int64  `json:"chunkSize"`
+	// ProxyFilename marks the upload as the original of a registered proxy
+	// (DDR-131); it is stored under {sessionId}/originals/.
+	ProxyFilename string `json:"proxyFilename,omitempty"`
 }
 
 type partURL struct {
@@ -132,6 +135,13 @@ func handleMultipartInit(w http.ResponseWriter, r *http.Request) {
 	}
 
 	key := req.SessionID + "/" + req.Filename
+	if req.ProxyFilename != "" {
+		link, ok := loadMediaLink(w, r.Context(), req.SessionID, filepath.Base(req.ProxyFilename))
+		if !ok {
+			return
+		}
+		key = link.OriginalKey
+	}
 
 	log.Info().
 		Str("sessionId", req.SessionID).
@@ -142,13 +152,19 @@ func handleMultipartInit(w http.ResponseWriter, r *http.Request) {
 		Int64("numParts", numParts).
 		Msg("Creating multipart upload (DDR-054)")
 
-	// Create the multipart upload.
-	createResult, err := s3Client.CreateMultipartUpload(context.Background(), &s3.CreateMultipartUploadInput{
+	// Create the multipart upload. Parts inherit its encryption (DDR-164).
+	enc, err := sessionEncryption(r.Context(), req.SessionID)
+	if err != nil {
+		log.Error().Err(err).Str("sessionId", req.SessionID).Msg("Failed to read session encryption")
+		httpError(w, http.StatusInternalServerError, "failed to create multipart upload")
+		return
+	}
+	createResult, err := s3Client.CreateMultipartUpload(context.Background(), enc.Multipart(&s3.CreateMultipartUploadInput{
 		Bucket:      &mediaBucket,
 		Key:         &key,
 		ContentType: &req.ContentType,
 		Tagging:     s3util.ProjectTagging(),
-	})
+	}))
 	if err != nil {
 		log.Error().Err(err).Str("key", key).Msg("Failed to create multipart upload")
 		httpError(w, http.StatusInternalServerError, "failed to create multipart upload")
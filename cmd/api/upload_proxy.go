@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// --- Proxy uploads linked to originals (DDR-131) ---
+//
+// A phone on a slow link uploads a downscaled proxy first. The proxy lives at
+// the usual {sessionId}/{filename} key, so triage and selection run on it
+// unchanged. The original follows later, or never, under
+// {sessionId}/originals/, which the pipeline ignores. Downloads and publish
+// swap a proxy key for its original once the original has arrived.
+
+// originalsDir is the session subdirectory that holds originals of proxies.
+const originalsDir = "originals/"
+
+type proxyUploadRequest struct {
+	SessionID           string `json:"sessionId"`
+	Filename            string `json:"filename"`
+	ContentType         string `json:"contentType"`
+	OriginalFilename    string `json:"originalFilename"`
+	OriginalContentType string `json:"originalContentType"`
+	OriginalBytes       int64  `json:"originalBytes"`
+}
+
+// POST /api/upload/proxy
+// Body: {"sessionId": "uuid", "filename": "IMG_1.jpg", "contentType": "image/jpeg",
+//
+//	"originalFilename": "IMG_1.HEIC", "originalContentType": "image/heic", "originalBytes": 4194304}
+//
+// Records the proxy/original link and returns a presigned PUT URL for the
+// proxy. originalFilename defaults to filename.
+func handleProxyUpload(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleProxyUpload")
+
+	if r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req proxyUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if req.SessionID == "" || req.Filename == "" || req.ContentType == "" || req.OriginalContentType == "" {
+		httpError(w, http.StatusBadRequest, "sessionId, filename, contentType, and originalContentType are required")
+		return
+	}
+	if err := validateSessionID(req.SessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !ensureSessionOwner(w, r, req.SessionID) {
+		return
+	}
+
+	req.Filename = filepath.Base(req.Filename)
+	if req.OriginalFilename == "" {
+		req.OriginalFilename = req.Filename
+	}
+	req.OriginalFilename = filepath.Base(req.OriginalFilename)
+	for _, name := range []string{req.Filename, req.OriginalFilename} {
+		if err := validateFilename(name); err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	for _, ct := range []string{req.ContentType, req.OriginalContentType} {
+		if !allowedContentTypes[ct] {
+			httpError(w, http.StatusBadRequest, fmt.Sprintf("unsupported content type: %s", ct))
+			return
+		}
+	}
+	if isVideoContentType(req.ContentType) != isVideoContentType(req.OriginalContentType) {
+		httpError(w, http.StatusBadRequest, "proxy and original must both be photos or both be videos")
+		return
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+
+	ctx := context.Background()
+	link := &store.MediaLink{
+		Filename:            req.Filename,
+		ProxyKey:            req.SessionID + "/" + req.Filename,
+		OriginalKey:         req.SessionID + "/" + originalsDir + req.OriginalFilename,
+		OriginalContentType: req.OriginalContentType,
+		OriginalBytes:       req.OriginalBytes,
+		Status:              store.MediaLinkProxy,
+	}
+	if err := sessionStore.PutMediaLink(ctx, req.SessionID, link); err != nil {
+		log.Error().Err(err).Str("filename", req.Filename).Msg("Failed to persist media link")
+		httpError(w, http.StatusInternalServerError, "failed to record proxy upload")
+		return
+	}
+
+	uploadURL, headers, err := presignPut(ctx, link.ProxyKey, req.ContentType)
+	if err != nil {
+		log.Error().Err(err).Str("key", link.ProxyKey).Msg("Failed to generate presigned URL")
+		httpError(w, http.StatusInternalServerError, "failed to generate upload URL")
+		return
+	}
+	log.Info().Str("sessionId", req.SessionID).Str("proxyKey", link.ProxyKey).Str("originalKey", link.OriginalKey).
+		Int64("originalBytes", req.OriginalBytes).Msg("Proxy upload registered")
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"uploadUrl":   uploadURL,
+		"key":         link.ProxyKey,
+		"originalKey": link.OriginalKey,
+		"headers":     headers,
+	})
+}
+
+// POST /api/upload/original
+// Body: {"sessionId": "uuid", "filename": "IMG_1.jpg"}
+//
+// Returns a presigned PUT URL for the original of a registered proxy, named
+// by the proxy's filename. Large originals use /api/upload-multipart/init
+// with "proxyFilename" instead.
+func handleOriginalUpload(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleOriginalUpload")
+
+	_, link, ok := decodeMediaLinkRequest(w, r)
+	if !ok {
+		return
+	}
+	// A proxy discarded in triage has no use for its original.
+	if _, err := s3Client.HeadObject(r.Context(), &s3.HeadObjectInput{Bucket: &mediaBucket, Key: &link.ProxyKey}); err != nil {
+		log.Info().Err(err).Str("key", link.ProxyKey).Msg("Proxy no longer exists — original not needed")
+		httpError(w, http.StatusGone, fmt.Sprintf("%s was removed from the session", link.Filename))
+		return
+	}
+	uploadURL, headers, err := presignPut(r.Context(), link.OriginalKey, link.OriginalContentType)
+	if err != nil {
+		log.Error().Err(err).Str("key", link.OriginalKey).Msg("Failed to generate presigned URL")
+		httpError(w, http.StatusInternalServerError, "failed to generate upload URL")
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"uploadUrl":   uploadURL,
+		"key":         link.OriginalKey,
+		"contentType": link.OriginalContentType,
+		"headers":     headers,
+	})
+}
+
+// POST /api/upload/original/complete
+// Body: {"sessionId": "uuid", "filename": "IMG_1.jpg"}
+//
+// Marks a proxy's original as uploaded after checking it exists in S3. From
+// then on downloads and publish use the original.
+func handleOriginalUploadComplete(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleOriginalUploadComplete")
+
+	sessionID, link, ok := decodeMediaLinkRequest(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+
+	head, err := s3Client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: &mediaBucket, Key: &link.OriginalKey})
+	if err != nil {
+		log.Warn().Err(err).Str("key", link.OriginalKey).Msg("Original not found on completion")
+		httpError(w, http.StatusConflict, "original has not been uploaded")
+		return
+	}
+	// Presigned PUTs cannot carry tags, and MediaProcess skips subdirectories,
+	// so tag the original here (DDR-049).
+	if err := s3util.TagObject(ctx, s3Client, mediaBucket, link.OriginalKey); err != nil {
+		log.Warn().Err(err).Str("key", link.OriginalKey).Msg("Failed to tag original (non-fatal)")
+	}
+
+	link.Status = store.MediaLinkOriginal
+	if head.ContentLength != nil {
+		link.OriginalBytes = *head.ContentLength
+	}
+	if err := sessionStore.PutMediaLink(ctx, sessionID, link); err != nil {
+		log.Error().Err(err).Str("filename", link.Filename).Msg("Failed to persist media link")
+		httpError(w, http.StatusInternalServerError, "failed to record original upload")
+		return
+	}
+	log.Info().Str("sessionId", sessionID).Str("originalKey", link.OriginalKey).Int64("bytes", link.OriginalBytes).Msg("Original linked to proxy")
+	respondJSON(w, http.StatusOK, link)
+}
+
+// GET /api/upload/links?sessionId=...
+// Lists the session's proxy/original links and how many originals are
+// still missing.
+func handleMediaLinks(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleMediaLinks")
+
+	if r.Method != http.MethodGet {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	sessionID := r.URL.Query().Get("sessionId")
+	if err := validateSessionID(sessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !ensureSessionOwner(w, r, sessionID) {
+		return
+	}
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return
+	}
+
+	links, err := sessionStore.ListMediaLinks(r.Context(), sessionID)
+	if err != nil {
+		log.Error().Err(err).Str("sessionId", sessionID).Msg("Failed to list media links")
+		httpError(w, http.StatusInternalServerError, "failed to list media links")
+		return
+	}
+	pending := 0
+	for _, link := range links {
+		if link.Status != store.MediaLinkOriginal {
+			pending++
+		}
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"links":            links,
+		"pendingOriginals": pending,
+	})
+}
+
+// decodeMediaLinkRequest reads {"sessionId", "filename"} and loads the
+// proxy's link, writing an error response and returning false on failure.
+func decodeMediaLinkRequest(w http.ResponseWriter, r *http.Request) (string, *store.MediaLink, bool) {
+	if r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return "", nil, false
+	}
+	var req struct {
+		SessionID string `json:"sessionId"`
+		Filename  string `json:"filename"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid JSON body")
+		return "", nil, false
+	}
+	if err := validateSessionID(req.SessionID); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return "", nil, false
+	}
+	if !ensureSessionOwner(w, r, req.SessionID) {
+		return "", nil, false
+	}
+	if err := validateFilename(req.Filename); err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return "", nil, false
+	}
+	link, ok := loadMediaLink(w, r.Context(), req.SessionID, req.Filename)
+	return req.SessionID, link, ok
+}
+
+// loadMediaLink fetches the link for a proxy filename, writing 404 when the
+// file was not uploaded as a proxy.
+func loadMediaLink(w http.ResponseWriter, ctx context.Context, sessionID, filename string) (*store.MediaLink, bool) {
+	if sessionStore == nil {
+		httpError(w, http.StatusServiceUnavailable, "store not configured")
+		return nil, false
+	}
+	link, err := sessionStore.GetMediaLink(ctx, sessionID, filename)
+	if err != nil {
+		log.Error().Err(err).Str("filename", filename).Msg("Failed to read media link")
+		httpError(w, http.StatusInternalServerError, "failed to read media link")
+		return nil, false
+	}
+	if link == nil {
+		httpError(w, http.StatusNotFound, fmt.Sprintf("%s was not uploaded as a proxy", filename))
+		return nil, false
+	}
+	return link, true
+}
+
+// resolveOriginalKeys swaps proxy keys for their uploaded originals before a
+// download or publish. Best effort: if the links cannot be read, the proxies
+// are used.
+func resolveOriginalKeys(ctx context.Context, sessionID string, keys []string) []string {
+	if sessionStore == nil {
+		return keys
+	}
+	links, err := sessionStore.ListMediaLinks(ctx, sessionID)
+	if err != nil {
+		log.Warn().Err(err).Str("sessionId", sessionID).Msg("Failed to read media links — using keys as given")
+		return keys
+	}
+	if len(links) == 0 {
+		return keys
+	}
+	resolved, pending := store.ResolveOriginals(keys, links)
+	log.Info().Str("sessionId", sessionID).Int("keys", len(keys)).Int("pendingOriginals", pending).Msg("Proxy keys resolved to originals")
+	return resolved
+}
+
+// presignPut returns a presigned PUT URL for a session object and the
+// encryption headers the browser must send with it, nil for an unencrypted
+// session (DDR-164).
+func presignPut(ctx context.Context, key, contentType string) (string, map[string]string, error) {
+	sessionID, _, _ := strings.Cut(key, "/")
+	enc, err := sessionEncryption(ctx, sessionID)
+	if err != nil {
+		return "", nil, err
+	}
+	result, err := presigner.PresignPutObject(ctx, enc.Put(&s3.PutObjectInput{
+		Bucket:      &mediaBucket,
+		Key:         &key,
+		ContentType: &contentType,
+	}), s3.WithPresignExpires(15*time.Minute))
+	if err != nil {
+		return "", nil, err
+	}
+	return result.URL, enc.UploadHeaders(), nil
+}
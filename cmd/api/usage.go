@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/fpang/ai-social-media-helper/internal/jobs"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Gemini usage governance (DDR-142) ---
+
+// queueDepth is the backlog of one job queue. Queued jobs wait for a worker;
+// in-progress jobs have been received and are not yet deleted.
+type queueDepth struct {
+	Type       string        `json:"type"`
+	Priority   jobs.Priority `json:"priority"`
+	Queued     int64         `json:"queued"`
+	InProgress int64         `json:"inProgress"`
+}
+
+// GET /api/admin/usage[?day=YYYY-MM-DD] — current Gemini concurrency, job
+// queue depth by type, and the day's token consumption per model (UTC,
+// default today).
+//
+// The readings cover the whole deployment, so only subs listed in ADMIN_SUBS
+// may read them. A source that cannot be read is omitted rather than failing
+// the request.
+func handleAdminUsage(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleAdminUsage")
+
+	if r.Method != http.MethodGet {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	sub := getUserSub(r)
+	if sub == "" || !adminSubs[sub] {
+		log.Warn().Str("sub", sub).Msg("Usage metrics access not authorized")
+		httpError(w, http.StatusForbidden, "admin access required")
+		return
+	}
+
+	day := r.URL.Query().Get("day")
+	if day == "" {
+		day = time.Now().UTC().Format(time.DateOnly)
+	} else if _, err := time.Parse(time.DateOnly, day); err != nil {
+		httpError(w, http.StatusBadRequest, "day must be YYYY-MM-DD")
+		return
+	}
+
+	gemini := &store.GeminiInFlight{}
+	models := []store.ModelTokens{}
+	if sessionStore != nil {
+		if g, err := sessionStore.GetGeminiInFlight(r.Context()); err != nil {
+			log.Warn().Err(err).Msg("Failed to read in-flight Gemini requests")
+		} else {
+			gemini = g
+		}
+		if m, err := sessionStore.ListModelTokens(r.Context(), day); err != nil {
+			log.Warn().Err(err).Str("day", day).Msg("Failed to read model token usage")
+		} else {
+			models = m
+		}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"gemini": gemini,
+		"queues": readQueueDepths(r.Context()),
+		"tokens": map[string]interface{}{
+			"day":    day,
+			"models": models,
+		},
+	})
+}
+
+// readQueueDepths reads the approximate backlog of every configured job
+// queue, sorted by type and priority.
+func readQueueDepths(ctx context.Context) []queueDepth {
+	out := []queueDepth{}
+	if sqsClient == nil {
+		return out
+	}
+	for priority, urls := range map[jobs.Priority]map[string]string{
+		jobs.PriorityBatch:       jobQueueURLs,
+		jobs.PriorityInteractive: interactiveQueueURLs,
+	} {
+		for arn, url := range urls {
+			result, err := sqsClient.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+				QueueUrl: &url,
+				AttributeNames: []sqstypes.QueueAttributeName{
+					sqstypes.QueueAttributeNameApproximateNumberOfMessages,
+					sqstypes.QueueAttributeNameApproximateNumberOfMessagesNotVisible,
+				},
+			})
+			if err != nil {
+				log.Warn().Err(err).Str("queueUrl", url).Msg("Failed to read job queue depth")
+				continue
+			}
+			queued, _ := strconv.ParseInt(result.Attributes[string(sqstypes.QueueAttributeNameApproximateNumberOfMessages)], 10, 64)
+			inProgress, _ := strconv.ParseInt(result.Attributes[string(sqstypes.QueueAttributeNameApproximateNumberOfMessagesNotVisible)], 10, 64)
+			out = append(out, queueDepth{
+				Type:       jobTypeFor(arn),
+				Priority:   priority,
+				Queued:     queued,
+				InProgress: inProgress,
+			})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Type != out[j].Type {
+			return out[i].Type < out[j].Type
+		}
+		return out[i].Priority < out[j].Priority
+	})
+	return out
+}
+
+// jobTypeFor names the worker a job queue feeds.
+func jobTypeFor(functionArn string) string {
+	switch functionArn {
+	case descriptionLambdaArn:
+		return "description"
+	case downloadLambdaArn:
+		return "download"
+	case enhanceLambdaArn:
+		return "enhance"
+	case fbPrepLambdaArn:
+		return "fb-prep"
+	}
+	return functionArn
+}
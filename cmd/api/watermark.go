@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/brand"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Watermarks (DDR-133) ---
+
+// GET    /api/watermark — the caller's watermark (404 if none)
+// POST   /api/watermark — save the caller's watermark
+// DELETE /api/watermark — remove it
+// Body: {"text": "© Jane Doe", "logo": "<base64 PNG>", "position": "bottom-right", "opacity": 0.7, "scale": 0.2}
+//
+// The watermark belongs to the user, not a session. Enhancement and publish
+// apply it when started with "watermark": true; a brand kit logo and palette
+// take precedence over its logo and text color (DDR-149).
+func handleWatermark(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("Handler entry: handleWatermark")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	owner, ok := signedInUser(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		settings, err := sessionStore.GetWatermark(r.Context(), owner)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to read watermark")
+			httpError(w, http.StatusInternalServerError, "failed to read watermark")
+			return
+		}
+		if settings == nil {
+			httpError(w, http.StatusNotFound, "no watermark is set")
+			return
+		}
+		respondJSON(w, http.StatusOK, settings)
+
+	case http.MethodDelete:
+		if err := sessionStore.DeleteWatermark(r.Context(), owner); err != nil {
+			log.Error().Err(err).Msg("Failed to delete watermark")
+			httpError(w, http.StatusInternalServerError, "failed to delete watermark")
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]bool{"deleted": true})
+
+	default:
+		r.Body = http.MaxBytesReader(w, r.Body, 2*media.MaxOverlayLogoBytes)
+		var req store.WatermarkSettings
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httpError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if err := watermarkOverlay(&req).Validate(); err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		req.UpdatedAt = time.Now().Unix()
+		if err := sessionStore.PutWatermark(r.Context(), owner, &req); err != nil {
+			log.Error().Err(err).Msg("Failed to save watermark")
+			httpError(w, http.StatusInternalServerError, "failed to save watermark")
+			return
+		}
+		log.Info().Bool("logo", len(req.Logo) > 0).Str("position", req.Position).Msg("Watermark saved")
+		respondJSON(w, http.StatusOK, req)
+	}
+}
+
+// watermarkOverlay converts stored watermark settings to a media.Overlay.
+func watermarkOverlay(s *store.WatermarkSettings) media.Overlay {
+	return media.Overlay{
+		Text:     s.Text,
+		Logo:     s.Logo,
+		Position: s.Position,
+		Opacity:  s.Opacity,
+		Scale:    s.Scale,
+	}
+}
+
+// errNoWatermark is returned by requireWatermark when the caller asked for a
+// watermark without having set one.
+var errNoWatermark = errors.New("watermark requested but none is set; save one with POST /api/watermark or add a logo to your brand kit")
+
+// requireWatermark checks that the signed-in caller has a watermark, or a
+// brand kit logo to stamp instead (DDR-149), before a job that applies it is
+// started, so the job does not fail item by item.
+func requireWatermark(ctx context.Context, r *http.Request) error {
+	owner := getUserSub(r)
+	if owner == "" || sessionStore == nil {
+		return errNoWatermark
+	}
+	ok, err := brand.HasWatermark(ctx, sessionStore, owner)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errNoWatermark
+	}
+	return nil
+}
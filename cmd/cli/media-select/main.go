@@ -19,11 +19,14 @@ import (
 
 // CLI flags
 var (
-	directoryFlag string
-	maxDepthFlag  int
-	limitFlag     int
-	contextFlag   string
-	modelFlag     string
+	directoryFlag      string
+	maxDepthFlag       int
+	limitFlag          int
+	contextFlag        string
+	modelFlag          string
+	thinkingFlag       string
+	localeFlag         string
+	debugArtifactsFlag bool
 )
 
 // rootCmd is the main Cobra command for the CLI.
@@ -42,6 +45,9 @@ Examples:
   media-select -d ./vacation-photos -c "Birthday party at restaurant then karaoke"
   media-select -d ./photos --max-depth 2 --limit 50
   media-select -d ./media --model gemini-3.1-pro-preview
+  media-select -d ./media --thinking low
+  media-select -d ./media --debug-artifacts
+  media-select -d ./media --locale ja
   media-select  # Interactive mode - prompts for directory and context`,
 	Run: runMain,
 }
@@ -52,6 +58,9 @@ func init() {
 	rootCmd.Flags().IntVar(&limitFlag, "limit", 0, "Maximum media items to process (0 = unlimited)")
 	rootCmd.Flags().StringVarP(&contextFlag, "context", "c", "", "Trip/event description for media selection (e.g., 'Birthday party at restaurant then karaoke')")
 	rootCmd.Flags().StringVarP(&modelFlag, "model", "m", ai.DefaultModelName, "Gemini model to use (e.g., gemini-3-flash-preview, gemini-3.1-pro-preview)")
+	rootCmd.Flags().StringVar(&thinkingFlag, "thinking", "", "Gemini thinking setting: minimal, low, medium, high, off, dynamic, or a token budget (default: the model's, or GEMINI_THINKING)")
+	rootCmd.Flags().StringVar(&localeFlag, "locale", "", "Output language: en, zh, or ja (default: from LC_ALL, LC_MESSAGES, or LANG)")
+	rootCmd.Flags().BoolVar(&debugArtifactsFlag, "debug-artifacts", false, "Keep prompts, raw model responses, and compressed videos in .debug/ for bug reports")
 }
 
 func main() {
@@ -63,6 +72,7 @@ func main() {
 // runMain is the main execution logic called by Cobra.
 func runMain(cmd *cobra.Command, args []string) {
 	logging.Init()
+	cli.InitLocale(localeFlag)
 
 	// Determine and validate directory path
 	dirPath := directoryFlag
@@ -73,6 +83,9 @@ func runMain(cmd *cobra.Command, args []string) {
 
 	// Initialize Gemini client
 	ctx, client := cli.InitGeminiClient()
+	ctx = cli.WithDebugArtifacts(ctx, debugArtifactsFlag)
+	ctx, thinkingFlag = cli.WithThinking(ctx, thinkingFlag)
+	ctx = cli.WithCompressionProgress(ctx)
 
 	// Get trip context
 	tripContext := contextFlag
@@ -88,10 +101,8 @@ func runMain(cmd *cobra.Command, args []string) {
 // Returns empty string if the user enters nothing (context is optional but recommended).
 func promptForContext() string {
 	fmt.Println()
-	fmt.Println("Describe your trip/event (helps Gemini select the best photos):")
-	fmt.Println("Examples: 'Weekend trip to Kyoto - temples, food tour, night market'")
-	fmt.Println("          'Birthday party at restaurant then karaoke'")
-	fmt.Print("Context (optional): ")
+	fmt.Println(cli.T("select.context_prompt"))
+	fmt.Print(cli.T("select.context_input"))
 
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
@@ -113,6 +124,7 @@ func runDirectorySelection(ctx context.Context, client *genai.Client, dirPath st
 		Int("limit", limitFlag).
 		Bool("has_context", tripContext != "").
 		Str("model", modelFlag).
+		Str("thinking", thinkingFlag).
 		Msg("Starting quality-agnostic media selection")
 
 	// Configure scan options
@@ -145,24 +157,27 @@ func runDirectorySelection(ctx context.Context, client *genai.Client, dirPath st
 	// Display header
 	fmt.Println()
 	fmt.Println("============================================")
-	fmt.Println("📁 Media Selection")
+	fmt.Println(cli.T("select.title"))
 	fmt.Println("============================================")
-	fmt.Printf("Directory: %s\n", dirPath)
-	fmt.Printf("Images found: %d\n", imageCount)
-	fmt.Printf("Videos found: %d\n", videoCount)
-	fmt.Printf("Total media: %d\n", len(files))
+	fmt.Println(cli.T("scan.directory", dirPath))
+	fmt.Println(cli.T("scan.images", imageCount))
+	fmt.Println(cli.T("scan.videos", videoCount))
+	fmt.Println(cli.T("scan.total", len(files)))
 	if limitFlag > 0 && len(files) == limitFlag {
-		fmt.Printf("(limited to %d)\n", limitFlag)
+		fmt.Println(cli.T("scan.limited", limitFlag))
+	}
+	fmt.Println(cli.T("select.max", ai.DefaultMaxMedia))
+	fmt.Println(cli.T("scan.model", modelFlag))
+	if thinkingFlag != "" {
+		fmt.Println(cli.T("scan.thinking", thinkingFlag))
 	}
-	fmt.Printf("Max selection: %d\n", ai.DefaultMaxMedia)
-	fmt.Printf("Model: %s\n", modelFlag)
 	if tripContext != "" {
-		fmt.Printf("Context: %s\n", tripContext)
+		fmt.Println(cli.T("select.context", tripContext))
 	}
 	fmt.Println("--------------------------------------------")
 
 	// Display summary of found media
-	fmt.Println("📸 Media to analyze:")
+	fmt.Println(cli.T("select.media_list"))
 	for i, file := range files {
 		// Show relative path from base directory if recursive
 		displayPath := filepath.Base(file.Path)
@@ -170,7 +185,6 @@ func runDirectorySelection(ctx context.Context, client *genai.Client, dirPath st
 			displayPath = relPath
 		}
 
-		sizeMB := float64(file.Size) / (1024 * 1024)
 		ext := strings.ToLower(filepath.Ext(file.Path))
 
 		// Determine media type indicator
@@ -188,23 +202,23 @@ func runDirectorySelection(ctx context.Context, client *genai.Client, dirPath st
 		metaInfo := ""
 		if file.Metadata != nil {
 			if file.Metadata.HasGPSData() {
-				metaInfo += " 📍GPS"
+				metaInfo += " " + cli.T("tag.gps")
 			}
 			if file.Metadata.HasDateData() {
-				metaInfo += " 📅Date"
+				metaInfo += " " + cli.T("tag.date")
 			}
 		}
 
-		fmt.Printf("   %2d. %s (%.1f MB) %s%s%s\n", i+1, displayPath, sizeMB, typeIndicator, durationStr, metaInfo)
+		fmt.Printf("   %2d. %s (%s) %s%s%s\n", i+1, displayPath, cli.FormatMB(file.Size, 1), typeIndicator, durationStr, metaInfo)
 	}
 
 	fmt.Println("--------------------------------------------")
 
 	// Show processing steps based on content
 	if videoCount > 0 {
-		fmt.Println("⏳ Compressing videos...")
+		fmt.Println(cli.T("select.compressing"))
 	}
-	fmt.Println("⏳ Processing media and sending to Gemini...")
+	fmt.Println(cli.T("select.sending"))
 	fmt.Println()
 
 	// Ask Gemini to select media using quality-agnostic criteria
@@ -214,7 +228,7 @@ func runDirectorySelection(ctx context.Context, client *genai.Client, dirPath st
 		log.Fatal().Err(err).Msg("failed to get media selection from Gemini")
 	}
 
-	fmt.Println("✅ Media Selection Complete!")
+	fmt.Println(cli.T("select.complete"))
 	fmt.Println("============================================")
 	fmt.Println()
 	fmt.Println(response)
@@ -235,6 +249,7 @@ func runMediaAnalysis(ctx context.Context, client *genai.Client, mediaPath strin
 		mediaType = "image"
 		emoji = "📸"
 	}
+	mediaNoun := cli.T("media." + mediaType)
 
 	log.Info().Str("path", mediaPath).Str("type", mediaType).Msg("Starting media analysis")
 
@@ -247,12 +262,12 @@ func runMediaAnalysis(ctx context.Context, client *genai.Client, mediaPath strin
 	// Display header
 	fmt.Println()
 	fmt.Println("============================================")
-	fmt.Printf("%s Analyzing %s for Social Media Post\n", emoji, strings.Title(mediaType))
+	fmt.Println(cli.T("analysis.title", emoji, mediaNoun))
 	fmt.Println("============================================")
-	fmt.Printf("File: %s\n", filepath.Base(mediaPath))
-	fmt.Printf("Size: %.2f MB\n", float64(mediaFile.Size)/(1024*1024))
-	fmt.Printf("Type: %s\n", mediaFile.MIMEType)
-	fmt.Println("Upload: Files API")
+	fmt.Println(cli.T("analysis.file", filepath.Base(mediaPath)))
+	fmt.Println(cli.T("analysis.size", cli.FormatMB(mediaFile.Size, 2)))
+	fmt.Println(cli.T("analysis.type", mediaFile.MIMEType))
+	fmt.Println(cli.T("analysis.upload"))
 
 	// Display extracted metadata
 	if mediaFile.Metadata != nil {
@@ -260,11 +275,11 @@ func runMediaAnalysis(ctx context.Context, client *genai.Client, mediaPath strin
 		displayMetadata(mediaFile.Metadata)
 	} else {
 		fmt.Println("--------------------------------------------")
-		fmt.Println("⚠️  No metadata could be extracted")
+		fmt.Println(cli.T("analysis.no_metadata"))
 	}
 
 	fmt.Println("--------------------------------------------")
-	fmt.Printf("⏳ Uploading %s to Gemini Files API...\n", mediaType)
+	fmt.Println(cli.T("analysis.uploading", strings.ToLower(mediaNoun)))
 	fmt.Println()
 
 	// Build the appropriate prompt based on media type
@@ -276,7 +291,7 @@ func runMediaAnalysis(ctx context.Context, client *genai.Client, mediaPath strin
 		log.Fatal().Err(err).Msg("failed to analyze media")
 	}
 
-	fmt.Println("✅ Analysis Complete!")
+	fmt.Println(cli.T("analysis.complete"))
 	fmt.Println("============================================")
 	fmt.Println()
 	fmt.Println(response)
@@ -290,37 +305,35 @@ func displayMetadata(metadata media.MediaMetadata) {
 	case *media.VideoMetadata:
 		displayVideoMetadata(m)
 	default:
-		fmt.Println("📋 Metadata extracted (unknown type)")
+		fmt.Println(cli.T("meta.unknown"))
 	}
 }
 
 // displayImageMetadata prints image-specific metadata.
 func displayImageMetadata(m *media.ImageMetadata) {
-	fmt.Println("📍 EXIF Metadata Extracted:")
+	fmt.Println(cli.T("meta.image"))
 	if m.HasGPS {
-		fmt.Printf("   GPS: %.6f, %.6f\n", m.Latitude, m.Longitude)
-		fmt.Printf("   Map: https://www.google.com/maps?q=%.6f,%.6f\n", m.Latitude, m.Longitude)
+		displayGPS(m.Latitude, m.Longitude)
 	}
 	if m.HasDate {
-		fmt.Printf("   Date: %s\n", m.DateTaken.Format("Monday, January 2, 2006 at 3:04 PM"))
+		fmt.Println("   " + cli.T("meta.date", cli.FormatCaptureTime(m)))
 	}
 	if m.CameraMake != "" || m.CameraModel != "" {
-		fmt.Printf("   Camera: %s %s\n", m.CameraMake, m.CameraModel)
+		fmt.Println("   " + cli.T("meta.camera", m.CameraMake, m.CameraModel))
 	}
 }
 
 // displayVideoMetadata prints video-specific metadata.
 func displayVideoMetadata(m *media.VideoMetadata) {
-	fmt.Println("🎥 Video Metadata Extracted:")
+	fmt.Println(cli.T("meta.video"))
 	if m.HasGPS {
-		fmt.Printf("   GPS: %.6f, %.6f\n", m.Latitude, m.Longitude)
-		fmt.Printf("   Map: https://www.google.com/maps?q=%.6f,%.6f\n", m.Latitude, m.Longitude)
+		displayGPS(m.Latitude, m.Longitude)
 	}
 	if m.HasDate {
-		fmt.Printf("   Date: %s\n", m.CreateDate.Format("Monday, January 2, 2006 at 3:04 PM"))
+		fmt.Println("   " + cli.T("meta.date", cli.FormatCaptureTime(m)))
 	}
 	if m.Duration > 0 {
-		fmt.Printf("   Duration: %s\n", formatDuration(m.Duration))
+		fmt.Println("   " + cli.T("meta.duration", formatDuration(m.Duration)))
 	}
 	if m.Width > 0 && m.Height > 0 {
 		resolution := fmt.Sprintf("%dx%d", m.Width, m.Height)
@@ -329,19 +342,26 @@ func displayVideoMetadata(m *media.VideoMetadata) {
 		} else if m.Width >= 1920 {
 			resolution += " (Full HD)"
 		}
-		fmt.Printf("   Resolution: %s\n", resolution)
+		fmt.Println("   " + cli.T("meta.resolution", resolution))
 	}
 	if m.FrameRate > 0 {
-		fmt.Printf("   Frame Rate: %.2f fps\n", m.FrameRate)
+		fmt.Println("   " + cli.T("meta.frame_rate", cli.FormatDecimal(m.FrameRate, 2)))
 	}
 	if m.Codec != "" {
-		fmt.Printf("   Codec: %s\n", m.Codec)
+		fmt.Println("   " + cli.T("meta.codec", m.Codec))
 	}
 	if m.BitRate > 0 {
-		fmt.Printf("   Bit Rate: %.2f Mbps\n", float64(m.BitRate)/(1024*1024))
+		fmt.Println("   " + cli.T("meta.bit_rate", cli.FormatDecimal(float64(m.BitRate)/(1024*1024), 2)))
 	}
 }
 
+// displayGPS prints GPS coordinates and a map link. Coordinates are not
+// localized, so they can be pasted into a map.
+func displayGPS(lat, lon float64) {
+	fmt.Println("   " + cli.T("meta.gps", lat, lon))
+	fmt.Println("   " + cli.T("meta.map", fmt.Sprintf("https://www.google.com/maps?q=%.6f,%.6f", lat, lon)))
+}
+
 // formatDuration formats a time.Duration in a human-readable format.
 func formatDuration(d interface{}) string {
 	switch v := d.(type) {
@@ -20,11 +20,15 @@ import (
 
 // CLI flags
 var (
-	directoryFlag string
-	maxDepthFlag  int
-	limitFlag     int
-	modelFlag     string
-	dryRunFlag    bool
+	directoryFlag      string
+	maxDepthFlag       int
+	limitFlag          int
+	modelFlag          string
+	thinkingFlag       string
+	keyframesFlag      int
+	localeFlag         string
+	dryRunFlag         bool
+	debugArtifactsFlag bool
 )
 
 // rootCmd is the main Cobra command for the media-triage CLI.
@@ -44,6 +48,9 @@ Examples:
   media-triage -d ./vacation-photos --dry-run
   media-triage -d ./photos --max-depth 2 --limit 100
   media-triage -d ./media --model gemini-3.1-pro-preview
+  media-triage -d ./media --thinking low
+  media-triage -d ./media --dry-run --debug-artifacts
+  media-triage -d ./media --dry-run --locale zh
   media-triage  # Interactive mode - prompts for directory`,
 	Run: runMain,
 }
@@ -53,7 +60,11 @@ func init() {
 	rootCmd.Flags().IntVar(&maxDepthFlag, "max-depth", 0, "Maximum recursion depth (0 = unlimited)")
 	rootCmd.Flags().IntVar(&limitFlag, "limit", 0, "Maximum media items to process (0 = unlimited)")
 	rootCmd.Flags().StringVarP(&modelFlag, "model", "m", ai.DefaultModelName, "Gemini model to use (e.g., gemini-3-flash-preview, gemini-3.1-pro-preview)")
+	rootCmd.Flags().StringVar(&thinkingFlag, "thinking", "", "Gemini thinking setting: minimal, low, medium, high, off, dynamic, or a token budget (default: the model's, or GEMINI_THINKING)")
+	rootCmd.Flags().IntVar(&keyframesFlag, "keyframes", 0, "Judge each video from this many frames (2-16) instead of sending the clip (0 = off)")
+	rootCmd.Flags().StringVar(&localeFlag, "locale", "", "Output language: en, zh, or ja (default: from LC_ALL, LC_MESSAGES, or LANG)")
 	rootCmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Show triage report without prompting for deletion")
+	rootCmd.Flags().BoolVar(&debugArtifactsFlag, "debug-artifacts", false, "Keep prompts, raw model responses, and compressed videos in .debug/ for bug reports")
 }
 
 func main() {
@@ -65,6 +76,7 @@ func main() {
 // runMain is the main execution logic called by Cobra.
 func runMain(cmd *cobra.Command, args []string) {
 	logging.Init()
+	cli.InitLocale(localeFlag)
 
 	// Determine and validate directory path
 	dirPath := directoryFlag
@@ -75,6 +87,13 @@ func runMain(cmd *cobra.Command, args []string) {
 
 	// Initialize Gemini client
 	ctx, client := cli.InitGeminiClient()
+	ctx = cli.WithDebugArtifacts(ctx, debugArtifactsFlag)
+	ctx, thinkingFlag = cli.WithThinking(ctx, thinkingFlag)
+	ctx = cli.WithCompressionProgress(ctx)
+	if err := media.ValidateKeyframes(keyframesFlag); err != nil {
+		log.Fatal().Err(err).Msg("invalid keyframes setting")
+	}
+	ctx = ai.WithTriageKeyframes(ctx, keyframesFlag) // DDR-186
 
 	// Run triage
 	runTriage(ctx, client, dirPath)
@@ -87,6 +106,7 @@ func runTriage(ctx context.Context, client *genai.Client, dirPath string) {
 		Int("max_depth", maxDepthFlag).
 		Int("limit", limitFlag).
 		Str("model", modelFlag).
+		Str("thinking", thinkingFlag).
 		Msg("Starting media triage")
 
 	// Configure scan options
@@ -119,18 +139,24 @@ func runTriage(ctx context.Context, client *genai.Client, dirPath string) {
 	// Display header
 	fmt.Println()
 	fmt.Println("============================================")
-	fmt.Println("Media Triage")
+	fmt.Println(cli.T("triage.title"))
 	fmt.Println("============================================")
-	fmt.Printf("Directory: %s\n", dirPath)
-	fmt.Printf("Images found: %d\n", imageCount)
-	fmt.Printf("Videos found: %d\n", videoCount)
-	fmt.Printf("Total media: %d\n", len(files))
+	fmt.Println(cli.T("scan.directory", dirPath))
+	fmt.Println(cli.T("scan.images", imageCount))
+	fmt.Println(cli.T("scan.videos", videoCount))
+	fmt.Println(cli.T("scan.total", len(files)))
 	if limitFlag > 0 && len(files) == limitFlag {
-		fmt.Printf("(limited to %d)\n", limitFlag)
+		fmt.Println(cli.T("scan.limited", limitFlag))
+	}
+	fmt.Println(cli.T("scan.model", modelFlag))
+	if thinkingFlag != "" {
+		fmt.Println(cli.T("scan.thinking", thinkingFlag))
+	}
+	if keyframesFlag > 0 {
+		fmt.Println(cli.T("scan.keyframes", keyframesFlag))
 	}
-	fmt.Printf("Model: %s\n", modelFlag)
 	if dryRunFlag {
-		fmt.Println("Mode: DRY RUN (no deletion)")
+		fmt.Println(cli.T("triage.dry_run_mode"))
 	}
 	fmt.Println("--------------------------------------------")
 
@@ -143,13 +169,14 @@ func runTriage(ctx context.Context, client *genai.Client, dirPath string) {
 		ext := strings.ToLower(filepath.Ext(file.Path))
 		if media.IsVideo(ext) && file.Metadata != nil {
 			if vm, ok := file.Metadata.(*media.VideoMetadata); ok && vm.Duration > 0 && vm.Duration < 2*time.Second {
+				seconds := cli.FormatDecimal(vm.Duration.Seconds(), 1)
 				preFilteredResults = append(preFilteredResults, ai.TriageResult{
 					Filename: filepath.Base(file.Path),
 					Saveable: false,
-					Reason:   fmt.Sprintf("Video too short (%.1fs) - likely accidental recording", vm.Duration.Seconds()),
+					Reason:   cli.T("triage.too_short", seconds),
 				})
 				preFilteredPaths[file.Path] = true
-				fmt.Printf("   PRE-FILTER: %s (%.1fs) - too short, skipping AI analysis\n", filepath.Base(file.Path), vm.Duration.Seconds())
+				fmt.Println("   " + cli.T("triage.prefilter", filepath.Base(file.Path), seconds))
 				continue
 			}
 		}
@@ -157,7 +184,8 @@ func runTriage(ctx context.Context, client *genai.Client, dirPath string) {
 	}
 
 	if len(preFilteredResults) > 0 {
-		fmt.Printf("\nPre-filtered %d short video(s) without AI analysis.\n", len(preFilteredResults))
+		fmt.Println()
+		fmt.Println(cli.T("triage.prefiltered", len(preFilteredResults)))
 	}
 
 	// Batch send remaining media to Gemini for triage
@@ -178,9 +206,9 @@ func runTriage(ctx context.Context, client *genai.Client, dirPath string) {
 		_ = aiImageCount // used for display
 
 		if aiVideoCount > 0 {
-			fmt.Println("Compressing videos...")
+			fmt.Println(cli.T("triage.compressing"))
 		}
-		fmt.Printf("Sending %d media items to Gemini for triage...\n", len(filesToAnalyze))
+		fmt.Println(cli.T("triage.sending", len(filesToAnalyze)))
 		fmt.Println()
 
 		// Local mode: no sessionID, no S3 storage
@@ -233,15 +261,15 @@ func runTriage(ctx context.Context, client *genai.Client, dirPath string) {
 
 	// Display triage report
 	fmt.Println("============================================")
-	fmt.Println("Triage Report")
+	fmt.Println(cli.T("triage.report"))
 	fmt.Println("============================================")
 	fmt.Println()
 
 	// KEEP section
-	fmt.Printf("KEEP (%d items)\n", len(keepItems))
+	fmt.Println(cli.T("triage.keep", len(keepItems)))
 	fmt.Println("--------------------------------------------")
 	if len(keepItems) == 0 {
-		fmt.Println("   (none)")
+		fmt.Println("   " + cli.T("triage.none"))
 	} else {
 		for i, item := range keepItems {
 			displayPath := filepath.Base(item.path)
@@ -249,18 +277,18 @@ func runTriage(ctx context.Context, client *genai.Client, dirPath string) {
 				displayPath = relPath
 			}
 			fmt.Printf("   %2d. %s\n", i+1, displayPath)
-			fmt.Printf("       %s\n", item.result.Reason)
+			fmt.Printf("       %s\n", reasonLine(item.result))
 		}
 	}
 	fmt.Println()
 
 	// DISCARD section
-	fmt.Printf("DISCARD (%d items)\n", len(discardItems))
+	fmt.Println(cli.T("triage.discard", len(discardItems)))
 	fmt.Println("--------------------------------------------")
 	if len(discardItems) == 0 {
-		fmt.Println("   (none)")
+		fmt.Println("   " + cli.T("triage.none"))
 		fmt.Println()
-		fmt.Println("All media files are worth keeping!")
+		fmt.Println(cli.T("triage.all_keep"))
 		return
 	}
 
@@ -277,33 +305,33 @@ func runTriage(ctx context.Context, client *genai.Client, dirPath string) {
 		}
 
 		fmt.Printf("   %2d. %s\n", i+1, displayPath)
-		fmt.Printf("       %s\n", item.result.Reason)
+		fmt.Printf("       %s\n", reasonLine(item.result))
 	}
 	fmt.Println()
-	fmt.Printf("Total space to reclaim: %.1f MB\n", float64(totalDiscardSize)/(1024*1024))
+	fmt.Println(cli.T("triage.reclaimable", cli.FormatMB(totalDiscardSize, 1)))
 	fmt.Println("============================================")
 	fmt.Println()
 
 	// Dry run: stop here
 	if dryRunFlag {
-		fmt.Println("Dry run complete. No files were deleted.")
+		fmt.Println(cli.T("triage.dry_run_done"))
 		return
 	}
 
 	// Prompt for deletion confirmation
-	fmt.Printf("Delete %d file(s)? This cannot be undone. (y/N): ", len(discardItems))
+	fmt.Print(cli.T("triage.confirm", len(discardItems)))
 
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to read input, aborting deletion")
-		fmt.Println("Aborted. No files were deleted.")
+		fmt.Println(cli.T("triage.aborted"))
 		return
 	}
 
 	input = strings.TrimSpace(strings.ToLower(input))
 	if input != "y" && input != "yes" {
-		fmt.Println("Aborted. No files were deleted.")
+		fmt.Println(cli.T("triage.aborted"))
 		return
 	}
 
@@ -319,18 +347,28 @@ func runTriage(ctx context.Context, client *genai.Client, dirPath string) {
 
 		if err := os.Remove(item.path); err != nil {
 			log.Error().Err(err).Str("path", item.path).Msg("Failed to delete file")
-			fmt.Printf("   FAILED: %s - %v\n", displayPath, err)
+			fmt.Println("   " + cli.T("triage.delete_failed", displayPath, err))
 			deleteErrors++
 		} else {
-			fmt.Printf("   Deleted: %s\n", displayPath)
+			fmt.Println("   " + cli.T("triage.deleted_file", displayPath))
 			deletedCount++
 		}
 	}
 
 	fmt.Println()
-	fmt.Printf("Deleted %d file(s)", deletedCount)
+	reclaimed := cli.FormatMB(totalDiscardSize, 1)
 	if deleteErrors > 0 {
-		fmt.Printf(", %d error(s)", deleteErrors)
+		fmt.Println(cli.T("triage.deleted_w_errors", deletedCount, deleteErrors, reclaimed))
+	} else {
+		fmt.Println(cli.T("triage.deleted", deletedCount, reclaimed))
+	}
+}
+
+// reasonLine is a verdict's reason as printed in the report, noting a long
+// video judged from samples (DDR-162).
+func reasonLine(r ai.TriageResult) string {
+	if r.Sampled {
+		return r.Reason + " " + cli.T("triage.sampled")
 	}
-	fmt.Printf(", reclaimed %.1f MB\n", float64(totalDiscardSize)/(1024*1024))
+	return r.Reason
 }
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/rpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda/messages"
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/sfnsim"
+)
+
+// localInvokeTimeout is the deadline passed to local handlers — the maximum
+// Lambda timeout, since the simulator has no per-function configuration.
+const localInvokeTimeout = 15 * time.Minute
+
+// taskHandler answers Task invocations for one --lambda or --stub entry.
+type taskHandler interface {
+	invoke(ctx context.Context, target string, payload json.RawMessage) (json.RawMessage, error)
+	close()
+}
+
+type route struct {
+	name    string
+	handler taskHandler
+}
+
+// router implements sfnsim.Invoker by matching Task targets to handlers.
+type router struct {
+	routes []route
+	tmpDir string
+}
+
+func newRouter() *router {
+	return &router{}
+}
+
+// Invoke implements sfnsim.Invoker.
+func (r *router) Invoke(ctx context.Context, target string, payload json.RawMessage) (json.RawMessage, error) {
+	fn := functionName(target)
+	for _, rt := range r.routes {
+		if fn == rt.name || target == rt.name || strings.Contains(fn, rt.name) {
+			return rt.handler.invoke(ctx, target, payload)
+		}
+	}
+	return nil, &sfnsim.Error{
+		Name:  "States.TaskFailed",
+		Cause: fmt.Sprintf("no --lambda or --stub matches task target %q", target),
+	}
+}
+
+// Close stops every local Lambda process and removes built binaries.
+func (r *router) Close() {
+	for _, rt := range r.routes {
+		rt.handler.close()
+	}
+	if r.tmpDir != "" {
+		_ = os.RemoveAll(r.tmpDir)
+	}
+}
+
+// AddStub registers a canned response file for tasks matching name.
+func (r *router) AddStub(name, file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("read stub %s: %w", file, err)
+	}
+	if !json.Valid(data) {
+		return fmt.Errorf("stub %s is not valid JSON", file)
+	}
+
+	s := &stubHandler{}
+	var seq []json.RawMessage
+	if err := json.Unmarshal(data, &seq); err == nil && len(seq) > 0 {
+		s.responses = seq
+	} else {
+		s.responses = []json.RawMessage{data}
+	}
+	r.routes = append(r.routes, route{name: name, handler: s})
+	return nil
+}
+
+// AddLambda builds the Lambda package and starts it as a local RPC server.
+func (r *router) AddLambda(ctx context.Context, name, pkg string) error {
+	if r.tmpDir == "" {
+		dir, err := os.MkdirTemp("", "sfn-sim-")
+		if err != nil {
+			return fmt.Errorf("create build dir: %w", err)
+		}
+		r.tmpDir = dir
+	}
+
+	bin := filepath.Join(r.tmpDir, filepath.Base(pkg))
+	log.Info().Str("package", pkg).Str("task", name).Msg("Building Lambda")
+	build := exec.CommandContext(ctx, "go", "build", "-o", bin, pkg)
+	build.Stdout, build.Stderr = os.Stderr, os.Stderr
+	if err := build.Run(); err != nil {
+		return fmt.Errorf("build %s: %w", pkg, err)
+	}
+
+	l, err := startLocalLambda(ctx, name, bin)
+	if err != nil {
+		return err
+	}
+	r.routes = append(r.routes, route{name: name, handler: l})
+	return nil
+}
+
+// stubHandler returns canned responses, repeating the last one once the
+// sequence is exhausted. A response of the form {"errorType": ..., "errorMessage": ...}
+// is raised as a task error, matching what a failed Lambda returns.
+type stubHandler struct {
+	mu        sync.Mutex
+	responses []json.RawMessage
+	calls     int
+}
+
+func (s *stubHandler) invoke(ctx context.Context, target string, payload json.RawMessage) (json.RawMessage, error) {
+	s.mu.Lock()
+	resp := s.responses[min(s.calls, len(s.responses)-1)]
+	s.calls++
+	s.mu.Unlock()
+
+	var lambdaErr messages.InvokeResponse_Error
+	if err := json.Unmarshal(resp, &lambdaErr); err == nil && lambdaErr.Type != "" {
+		return nil, &sfnsim.Error{Name: lambdaErr.Type, Cause: lambdaErr.Message}
+	}
+	return resp, nil
+}
+
+func (s *stubHandler) close() {}
+
+// localLambda is a Lambda binary running in aws-lambda-go's RPC mode
+// (_LAMBDA_SERVER_PORT), which lambda.Start selects when that variable is set.
+type localLambda struct {
+	name   string
+	cmd    *exec.Cmd
+	client *rpc.Client
+	mu     sync.Mutex // the RPC server handles one invocation at a time, like a Lambda instance
+}
+
+func startLocalLambda(ctx context.Context, name, bin string) (*localLambda, error) {
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(bin)
+	cmd.Env = append(os.Environ(),
+		"_LAMBDA_SERVER_PORT="+port,
+		"AWS_LAMBDA_FUNCTION_NAME="+name,
+	)
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start %s: %w", bin, err)
+	}
+
+	// Cold start (init) runs before the RPC listener opens; wait for it.
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		client, err := rpc.Dial("tcp", "localhost:"+port)
+		if err == nil {
+			return &localLambda{name: name, cmd: cmd, client: client}, nil
+		}
+		if time.Now().After(deadline) || ctx.Err() != nil {
+			_ = cmd.Process.Kill()
+			return nil, fmt.Errorf("lambda %s did not start listening: %w", name, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func (l *localLambda) invoke(ctx context.Context, target string, payload json.RawMessage) (json.RawMessage, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	deadline := time.Now().Add(localInvokeTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	req := &messages.InvokeRequest{
+		Payload:            payload,
+		RequestId:          fmt.Sprintf("sfn-sim-%d", time.Now().UnixNano()),
+		InvokedFunctionArn: target,
+		Deadline: messages.InvokeRequest_Timestamp{
+			Seconds: deadline.Unix(),
+			Nanos:   int64(deadline.Nanosecond()),
+		},
+	}
+
+	var resp messages.InvokeResponse
+	call := l.client.Go("Function.Invoke", req, &resp, nil)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-call.Done:
+	}
+	if call.Error != nil {
+		return nil, fmt.Errorf("invoke %s: %w", l.name, call.Error)
+	}
+	if resp.Error != nil {
+		return nil, &sfnsim.Error{Name: resp.Error.Type, Cause: resp.Error.Message}
+	}
+	return resp.Payload, nil
+}
+
+func (l *localLambda) close() {
+	_ = l.client.Close()
+	_ = l.cmd.Process.Kill()
+	_ = l.cmd.Wait()
+}
+
+// functionName extracts the function name from a Lambda ARN
+// (arn:aws:lambda:region:acct:function:NAME[:qualifier]); other targets are
+// returned unchanged.
+func functionName(target string) string {
+	_, rest, ok := strings.Cut(target, ":function:")
+	if !ok {
+		return target
+	}
+	name, _, _ := strings.Cut(rest, ":")
+	return name
+}
+
+func freePort() (string, error) {
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return "", fmt.Errorf("find free port: %w", err)
+	}
+	defer lis.Close()
+	_, port, _ := net.SplitHostPort(lis.Addr().String())
+	return port, nil
+}
@@ -0,0 +1,226 @@
+// Package main provides sfn-sim, a local dry-run harness for the Step Functions
+// pipelines (DDR-093).
+//
+// sfn-sim loads a state machine definition (ASL JSON, as returned by
+// `aws stepfunctions describe-state-machine --query definition`) and executes
+// it with the sfnsim interpreter. Task states are dispatched to:
+//
+//   - real Lambda handlers (--lambda): the Lambda package is built and run as
+//     a local process in the go1.x RPC mode of aws-lambda-go, so the
+//     unmodified handler code runs against whatever AWS endpoints the current
+//     environment points at;
+//   - canned responses (--stub): a JSON file returned verbatim, or a JSON array
+//     whose elements are returned on successive calls (useful for polling
+//     steps such as triage-check-processing).
+//
+// Wait states and retry intervals are skipped unless --real-waits is set.
+//
+// With --check, the definition is not executed; instead every Task payload and
+// path is validated against the Lambda payload types in sfnevents (DDR-094).
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/sfnevents"
+	"github.com/fpang/ai-social-media-helper/internal/sfnsim"
+)
+
+// CLI flags
+var (
+	definitionFlag     string
+	inputFlag          string
+	lambdaFlags        []string
+	stubFlags          []string
+	realWaitsFlag      bool
+	maxTransitionsFlag int
+	verboseFlag        bool
+	checkFlag          bool
+)
+
+// rootCmd is the main Cobra command for the sfn-sim CLI.
+var rootCmd = &cobra.Command{
+	Use:   "sfn-sim",
+	Short: "Dry-run a Step Functions pipeline locally against the real Lambda handlers",
+	Long: `sfn-sim executes a state machine definition on this machine. Task states
+invoke the real Lambda handlers (built from this repo and run as local
+processes) or canned stub responses, so pipeline changes can be exercised
+without deploying.
+
+Tasks are matched to --lambda and --stub entries by function name: an entry
+NAME=... matches a Task whose FunctionName (or Resource) is NAME, ends with
+":function:NAME", or contains NAME. The first matching entry wins; stubs are
+checked before Lambdas.
+
+Examples:
+  sfn-sim -f triage.asl.json -i '{"sessionId":"abc","jobId":"triage-1"}' \
+      --lambda TriageProcessor=./cmd/lambda/media-triage
+  sfn-sim -f selection.asl.json -i @input.json \
+      --lambda Thumbnail=./cmd/lambda/pipeline/thumbnail-worker \
+      --stub Selection=testdata/selection-result.json
+  sfn-sim -f triage.asl.json -i @input.json \
+      --stub TriageProcessor=testdata/triage-responses.json -v
+  sfn-sim -f statemachines/fb-prep.asl.json --check`,
+	Args: cobra.NoArgs,
+	RunE: runMain,
+}
+
+func init() {
+	rootCmd.Flags().StringVarP(&definitionFlag, "definition", "f", "", "State machine definition (ASL JSON file)")
+	rootCmd.Flags().StringVarP(&inputFlag, "input", "i", "{}", "Execution input: JSON string or @file")
+	rootCmd.Flags().StringArrayVar(&lambdaFlags, "lambda", nil, "NAME=PACKAGE: run Tasks matching NAME with the Lambda built from PACKAGE (repeatable)")
+	rootCmd.Flags().StringArrayVar(&stubFlags, "stub", nil, "NAME=FILE: answer Tasks matching NAME with the JSON in FILE (repeatable)")
+	rootCmd.Flags().BoolVar(&realWaitsFlag, "real-waits", false, "Honor Wait states and Retry intervals instead of skipping them")
+	rootCmd.Flags().IntVar(&maxTransitionsFlag, "max-transitions", sfnsim.DefaultMaxTransitions, "Abort after this many state transitions")
+	rootCmd.Flags().BoolVarP(&verboseFlag, "verbose", "v", false, "Print each state's input and output")
+	rootCmd.Flags().BoolVar(&checkFlag, "check", false, "Validate the definition against the Lambda payload types instead of running it")
+	_ = rootCmd.MarkFlagRequired("definition")
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// runMain is the main execution logic called by Cobra.
+func runMain(cmd *cobra.Command, args []string) error {
+	logging.Init()
+	cmd.SilenceUsage = true
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	raw, err := os.ReadFile(definitionFlag)
+	if err != nil {
+		return fmt.Errorf("read definition: %w", err)
+	}
+	def, err := sfnsim.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("parse definition: %w", err)
+	}
+
+	if checkFlag {
+		return runCheck(def)
+	}
+
+	input, err := readInput(inputFlag)
+	if err != nil {
+		return err
+	}
+
+	router := newRouter()
+	defer router.Close()
+	for _, s := range stubFlags {
+		name, file, err := splitMapping(s)
+		if err != nil {
+			return fmt.Errorf("--stub: %w", err)
+		}
+		if err := router.AddStub(name, file); err != nil {
+			return err
+		}
+	}
+	for _, l := range lambdaFlags {
+		name, pkg, err := splitMapping(l)
+		if err != nil {
+			return fmt.Errorf("--lambda: %w", err)
+		}
+		if err := router.AddLambda(ctx, name, pkg); err != nil {
+			return err
+		}
+	}
+
+	sim := sfnsim.New(router)
+	sim.RealWaits = realWaitsFlag
+	sim.MaxTransitions = maxTransitionsFlag
+	sim.OnStep = printStep
+
+	out, err := sim.Run(ctx, stateMachineName(definitionFlag), def, input)
+	if err != nil {
+		fmt.Printf("\nExecution FAILED: %v\n", err)
+		return err
+	}
+
+	fmt.Printf("\nExecution SUCCEEDED\n%s\n", indent(out))
+	return nil
+}
+
+// runCheck reports contract violations between the definition and the Lambda
+// payload types.
+func runCheck(def *sfnsim.Definition) error {
+	violations := sfnsim.Check(def, sfnevents.Contracts)
+	for _, v := range violations {
+		fmt.Println(v)
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%d contract violation(s)", len(violations))
+	}
+	fmt.Println("No contract violations")
+	return nil
+}
+
+func printStep(s sfnsim.Step) {
+	status := "ok"
+	if s.Err != nil {
+		status = "FAILED: " + s.Err.Error()
+	}
+	fmt.Printf("%-9s %-50s %s\n", s.Type, s.Path, status)
+	if !verboseFlag {
+		return
+	}
+	in, _ := json.Marshal(s.Input)
+	fmt.Printf("          in:  %s\n", in)
+	if s.Err == nil {
+		out, _ := json.Marshal(s.Output)
+		fmt.Printf("          out: %s\n", out)
+	}
+}
+
+// readInput returns the execution input from a literal JSON string or @file.
+func readInput(v string) (json.RawMessage, error) {
+	data := []byte(v)
+	if strings.HasPrefix(v, "@") {
+		var err error
+		if data, err = os.ReadFile(v[1:]); err != nil {
+			return nil, fmt.Errorf("read input: %w", err)
+		}
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("input is not valid JSON")
+	}
+	return data, nil
+}
+
+func splitMapping(v string) (string, string, error) {
+	name, value, ok := strings.Cut(v, "=")
+	if !ok || name == "" || value == "" {
+		return "", "", fmt.Errorf("expected NAME=VALUE, got %q", v)
+	}
+	return name, value, nil
+}
+
+// stateMachineName derives a display name from the definition file name.
+func stateMachineName(path string) string {
+	base := path[strings.LastIndexAny(path, `/\`)+1:]
+	if i := strings.IndexByte(base, '.'); i > 0 {
+		base = base[:i]
+	}
+	return base
+}
+
+func indent(raw json.RawMessage) string {
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return string(raw)
+	}
+	b, _ := json.MarshalIndent(v, "", "  ")
+	return string(b)
+}
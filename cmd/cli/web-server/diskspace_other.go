@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package main
+
+import "errors"
+
+// diskSpace is only implemented on Linux and macOS; /api/status then omits disk.
+func diskSpace(path string) (total, free uint64, err error) {
+	return 0, 0, errors.New("disk space is not supported on this platform")
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/cli"
+	"github.com/ncruces/zenity"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Local export (DDR-160) ---
+
+// Export modes. Both leave the bytes where they are on disk: a hard link
+// adds a second name for the file, a move renames it.
+const (
+	exportLink = "link"
+	exportMove = "move"
+)
+
+// exportManifest is written next to the exported files so the export can be
+// traced back to its triage job and source files.
+type exportManifest struct {
+	JobID     string               `json:"jobId"`
+	Mode      string               `json:"mode"`
+	CreatedAt time.Time            `json:"createdAt"`
+	Files     []exportManifestFile `json:"files"`
+}
+
+type exportManifestFile struct {
+	Name   string `json:"name"`   // file name in the export folder
+	Source string `json:"source"` // original path
+	Size   int64  `json:"size"`
+	Reason string `json:"reason,omitempty"` // the AI's keep reason
+}
+
+// POST /api/triage/{id}/export
+// Body: {"mode": "link" | "move", "paths": ["/photos/a.jpg"], "destination": "/exports/trip"}
+//
+// Hard-links or moves the job's kept files into a folder instead of copying
+// them. paths defaults to every kept file; destination defaults to a folder
+// chosen in the native directory picker. A manifest.json describing the
+// export is written to the folder (an existing one gets a timestamped name).
+// Files on a different volume than the folder cannot be linked or moved
+// without copying and are reported as errors.
+func handleTriageExport(w http.ResponseWriter, r *http.Request, job *triageJob) {
+	if r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
+
+	var req struct {
+		Mode        string   `json:"mode"`
+		Paths       []string `json:"paths"`
+		Destination string   `json:"destination"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = exportLink
+	}
+	if req.Mode != exportLink && req.Mode != exportMove {
+		httpError(w, http.StatusBadRequest, "mode must be 'link' or 'move'")
+		return
+	}
+
+	kept := keptItems(job)
+	if len(req.Paths) == 0 {
+		for p := range kept {
+			req.Paths = append(req.Paths, p)
+		}
+	}
+	if len(req.Paths) == 0 {
+		httpError(w, http.StatusBadRequest, "no kept files to export")
+		return
+	}
+
+	dest := req.Destination
+	if dest == "" {
+		selected, err := zenity.SelectFile(
+			zenity.Directory(),
+			zenity.Title("Select export folder"),
+		)
+		if err != nil {
+			if errors.Is(err, zenity.ErrCanceled) {
+				respondJSON(w, http.StatusOK, map[string]interface{}{"canceled": true})
+				return
+			}
+			log.Error().Err(err).Msg("Directory picker failed")
+			httpError(w, http.StatusInternalServerError, "directory picker failed")
+			return
+		}
+		dest = selected
+	}
+	if containsPathTraversal(dest) || !filepath.IsAbs(dest) {
+		httpError(w, http.StatusBadRequest, "destination must be an absolute path")
+		return
+	}
+	if info, err := os.Stat(dest); err != nil || !info.IsDir() {
+		httpError(w, http.StatusBadRequest, "destination is not a directory")
+		return
+	}
+
+	manifest := exportManifest{JobID: job.id, Mode: req.Mode, CreatedAt: time.Now().UTC()}
+	errMsgs := make([]string, 0)
+	moved := map[string]string{}
+	for _, p := range req.Paths {
+		item, ok := kept[p]
+		if !ok {
+			errMsgs = append(errMsgs, fmt.Sprintf("path not in kept files: %s", p))
+			continue
+		}
+		info, err := os.Stat(p)
+		if err != nil {
+			errMsgs = append(errMsgs, fmt.Sprintf("cannot access %s: %v", item.Filename, err))
+			continue
+		}
+		var target string
+		if req.Mode == exportMove {
+			target, err = cli.ExportMove(p, dest, filepath.Base(p))
+		} else {
+			target, err = cli.ExportLink(p, dest, filepath.Base(p))
+		}
+		if err != nil {
+			errMsgs = append(errMsgs, fmt.Sprintf("cannot %s %s: %v", req.Mode, item.Filename, err))
+			continue
+		}
+		if req.Mode == exportMove {
+			moved[p] = target
+		}
+		manifest.Files = append(manifest.Files, exportManifestFile{
+			Name: filepath.Base(target), Source: p, Size: info.Size(), Reason: item.Reason,
+		})
+	}
+	updateMovedPaths(job, moved)
+
+	manifestPath := ""
+	if len(manifest.Files) > 0 {
+		var err error
+		if manifestPath, err = writeExportManifest(dest, manifest); err != nil {
+			log.Error().Err(err).Str("destination", dest).Msg("Failed to write export manifest")
+			errMsgs = append(errMsgs, fmt.Sprintf("failed to write manifest: %v", err))
+		}
+	}
+
+	log.Info().Str("job", job.id).Str("mode", req.Mode).Str("destination", dest).
+		Int("exported", len(manifest.Files)).Int("errors", len(errMsgs)).Msg("Kept files exported")
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"destination": dest,
+		"exported":    len(manifest.Files),
+		"manifest":    manifestPath,
+		"errors":      errMsgs,
+		"canceled":    false,
+	})
+}
+
+// keptItems returns the job's kept items by path.
+func keptItems(job *triageJob) map[string]triageResultItem {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	out := make(map[string]triageResultItem, len(job.keep))
+	for _, item := range job.keep {
+		out[item.Path] = item
+	}
+	return out
+}
+
+// updateMovedPaths points moved kept items at their new location, so
+// thumbnails and a later export still find them.
+func updateMovedPaths(job *triageJob, moved map[string]string) {
+	if len(moved) == 0 {
+		return
+	}
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	for i, item := range job.keep {
+		if target, ok := moved[item.Path]; ok {
+			job.keep[i].Path = target
+			job.keep[i].ThumbnailURL = "/api/media/thumbnail?path=" + url.QueryEscape(target)
+		}
+	}
+}
+
+// writeExportManifest writes the manifest as manifest.json, or with a
+// timestamped name when an earlier export left one in the folder. Neither
+// replaces an existing file.
+func writeExportManifest(dir string, m exportManifest) (string, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, "manifest.json"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		f, err = cli.CreateExportFile(dir, fmt.Sprintf("manifest-%s.json", m.CreatedAt.Format("20060102-150405")))
+	}
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
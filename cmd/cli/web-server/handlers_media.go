@@ -67,8 +67,24 @@ func handleThumbnail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// For videos, serve a placeholder SVG
-	if _, ok := media.SupportedVideoExtensions[ext]; ok {
+	// For videos, extract a frame when ffmpeg is installed (DDR-123), else
+	// serve a placeholder SVG
+	if mime, ok := media.SupportedVideoExtensions[ext]; ok {
+		if media.IsFFmpegAvailable() {
+			mf := &media.MediaFile{
+				Path:     absPath,
+				MIMEType: mime,
+				Size:     info.Size(),
+			}
+			thumbData, thumbMIME, err := media.GenerateThumbnail(mf, 400)
+			if err == nil {
+				w.Header().Set("Content-Type", thumbMIME)
+				w.Header().Set("Cache-Control", "public, max-age=3600")
+				w.Write(thumbData)
+				return
+			}
+			log.Warn().Err(err).Str("path", absPath).Msg("Failed to extract video thumbnail frame, serving placeholder")
+		}
 		w.Header().Set("Content-Type", "image/svg+xml")
 		w.Header().Set("Cache-Control", "public, max-age=3600")
 		fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="400" height="400" viewBox="0 0 400 400">
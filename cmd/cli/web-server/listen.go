@@ -0,0 +1,152 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// --- Listener, browser launch and localhost TLS (DDR-126) ---
+
+// portFallbackAttempts is how many ports after the default are tried before
+// asking the OS for any free one.
+const portFallbackAttempts = 10
+
+// listen binds the server port. When the port was not set explicitly and
+// is taken, it tries the next few ports and then an OS-assigned one, so a
+// second instance or another local server does not stop media-web from
+// starting. An explicit --port is honoured exactly.
+func listen(port int, explicit bool) (net.Listener, error) {
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err == nil || explicit {
+		return ln, err
+	}
+	firstErr := err
+
+	for p := port + 1; p <= port+portFallbackAttempts; p++ {
+		if ln, err = net.Listen("tcp", fmt.Sprintf(":%d", p)); err == nil {
+			log.Warn().Err(firstErr).Int("requested", port).Int("port", p).Msg("Port unavailable — using fallback")
+			return ln, nil
+		}
+	}
+	if ln, err = net.Listen("tcp", ":0"); err == nil {
+		log.Warn().Err(firstErr).Int("requested", port).Int("port", ln.Addr().(*net.TCPAddr).Port).Msg("Port unavailable — using OS-assigned port")
+		return ln, nil
+	}
+	return nil, fmt.Errorf("no free port from %d: %w", port, firstErr)
+}
+
+// openBrowser opens url in the user's default browser.
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait() // reap the launcher
+	return nil
+}
+
+// localhostCertValidity is the lifetime of a generated localhost certificate.
+const localhostCertValidity = 365 * 24 * time.Hour
+
+// localhostTLSConfig loads the self-signed localhost certificate from the
+// user config directory, generating one on first use or after it expires.
+// Returns the config and the certificate path, which the user can add to
+// their trust store to silence the browser warning.
+func localhostTLSConfig() (*tls.Config, string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, "", fmt.Errorf("locate config directory: %w", err)
+	}
+	dir := filepath.Join(configDir, "media-web")
+	certPath := filepath.Join(dir, "localhost-cert.pem")
+	keyPath := filepath.Join(dir, "localhost-key.pem")
+
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err == nil && cert.Leaf != nil && time.Now().Before(cert.Leaf.NotAfter) {
+		return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, certPath, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, "", fmt.Errorf("create %s: %w", dir, err)
+	}
+	if err := writeLocalhostCert(certPath, keyPath); err != nil {
+		return nil, "", err
+	}
+	log.Info().Str("cert", certPath).Msg("Generated self-signed localhost certificate")
+
+	cert, err = tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("load localhost certificate: %w", err)
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, certPath, nil
+}
+
+// writeLocalhostCert generates an ECDSA P-256 self-signed certificate valid
+// for localhost, the loopback addresses and this machine's hostname.
+func writeLocalhostCert(certPath, keyPath string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("generate key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return fmt.Errorf("generate serial: %w", err)
+	}
+
+	dnsNames := []string{"localhost"}
+	if host, err := os.Hostname(); err == nil && host != "" && host != "localhost" {
+		dnsNames = append(dnsNames, host)
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "media-web localhost"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(localhostCertValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              dnsNames,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return fmt.Errorf("create certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("marshal key: %w", err)
+	}
+
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", keyPath, err)
+	}
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+		return errors.Join(fmt.Errorf("write %s: %w", certPath, err), os.Remove(keyPath))
+	}
+	return nil
+}
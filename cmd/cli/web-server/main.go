@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,8 +14,11 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
 	"github.com/fpang/ai-social-media-helper/internal/auth"
 	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/httputil"
 	"github.com/fpang/ai-social-media-helper/internal/logging"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -25,8 +29,12 @@ var frontendFS embed.FS
 
 // CLI flags
 var (
-	portFlag  int
-	modelFlag string
+	portFlag            int
+	modelFlag           string
+	recordDecisionsFlag bool
+	userIDFlag          string
+	noOpenFlag          bool
+	tlsFlag             bool
 )
 
 var rootCmd = &cobra.Command{
@@ -39,13 +47,20 @@ and confirm actions through your browser.
 Examples:
   media-web
   media-web --port 9090
-  media-web --model gemini-3.1-pro-preview`,
+  media-web --no-open
+  media-web --tls
+  media-web --model gemini-3.1-pro-preview
+  media-web --record-decisions --user-id <cognito-sub>`,
 	Run: runMain,
 }
 
 func init() {
 	rootCmd.Flags().IntVar(&portFlag, "port", 8080, "Port to listen on")
 	rootCmd.Flags().StringVarP(&modelFlag, "model", "m", ai.DefaultModelName, "Gemini model to use")
+	rootCmd.Flags().BoolVar(&recordDecisionsFlag, "record-decisions", false, "Send confirmed triage decisions to the RAG pipeline (uses default AWS credentials)")
+	rootCmd.Flags().StringVar(&userIDFlag, "user-id", "", "User ID to attribute recorded decisions to (default: global profile only)")
+	rootCmd.Flags().BoolVar(&noOpenFlag, "no-open", false, "Do not open the UI in the default browser")
+	rootCmd.Flags().BoolVar(&tlsFlag, "tls", false, "Serve HTTPS with a self-signed localhost certificate (for access from another hostname)")
 }
 
 func main() {
@@ -80,17 +95,32 @@ func runMain(cmd *cobra.Command, args []string) {
 		log.Fatal().Err(err).Msg("Invalid API key")
 	}
 	log.Info().Msg("API key validated")
+	geminiKey.record(validationClient, nil)
+
+	if recordDecisionsFlag {
+		cfg, err := config.LoadDefaultConfig(ctx)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to load AWS config for --record-decisions")
+		}
+		ebClient = eventbridge.NewFromConfig(cfg)
+		log.Info().Str("userId", userIDFlag).Msg("Recording triage decisions for RAG")
+	}
 
 	mux := http.NewServeMux()
 
 	// API routes
 	mux.HandleFunc("/api/browse", handleBrowse)
 	mux.HandleFunc("/api/pick", handlePick)
+	mux.HandleFunc("/api/status", handleStatus) // DDR-125
 	mux.HandleFunc("/api/triage/start", handleTriageStart)
 	mux.HandleFunc("/api/triage/start/", handleTriageStart) // handle trailing slash
 	mux.HandleFunc("/api/triage/", handleTriageRoutes)
-	mux.HandleFunc("/api/media/thumbnail", handleThumbnail)
-	mux.HandleFunc("/api/media/full", handleFullImage)
+
+	// Thumbnail generation decodes full-size images; bound concurrent work so a
+	// grid of cache misses cannot exhaust memory (DDR-090).
+	mediaLimiter := httputil.NewLimiter(8, time.Second)
+	mux.HandleFunc("/api/media/thumbnail", mediaLimiter.Limit(4, handleThumbnail))
+	mux.HandleFunc("/api/media/full", mediaLimiter.Limit(1, handleFullImage))
 
 	// Catch-all for unregistered /api/ paths — return 404 JSON instead of the
 	// SPA's index.html, so API clients get a proper error response.
@@ -128,9 +158,14 @@ func runMain(cmd *cobra.Command, args []string) {
 	// Wrap with logging and CORS for local dev
 	handler := withLogging(withCORS(mux))
 
-	addr := fmt.Sprintf(":%d", portFlag)
+	// Fall back to a free port unless --port was given (DDR-126).
+	ln, err := listen(portFlag, cmd.Flags().Changed("port"))
+	if err != nil {
+		log.Fatal().Err(err).Int("port", portFlag).Msg("Failed to bind port")
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+
 	srv := &http.Server{
-		Addr:         addr,
 		Handler:      handler,
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 120 * time.Second,
@@ -148,10 +183,33 @@ func runMain(cmd *cobra.Command, args []string) {
 		srv.Shutdown(ctx)
 	}()
 
-	log.Info().Int("port", portFlag).Msg("Starting web server")
-	fmt.Printf("\n  Media Web UI: http://localhost:%d\n\n", portFlag)
+	scheme := "http"
+	if tlsFlag {
+		tlsConfig, certPath, err := localhostTLSConfig()
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to set up localhost TLS")
+		}
+		srv.TLSConfig = tlsConfig
+		scheme = "https"
+		fmt.Printf("\n  TLS certificate: %s (add it to your trust store to avoid browser warnings)", certPath)
+	}
 
-	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+	url := fmt.Sprintf("%s://localhost:%d", scheme, port)
+	log.Info().Int("port", port).Str("url", url).Msg("Starting web server")
+	fmt.Printf("\n  Media Web UI: %s\n\n", url)
+
+	if !noOpenFlag {
+		if err := openBrowser(url); err != nil {
+			log.Warn().Err(err).Msg("Could not open browser — open the URL above manually")
+		}
+	}
+
+	if tlsFlag {
+		err = srv.ServeTLS(ln, "", "")
+	} else {
+		err = srv.Serve(ln)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatal().Err(err).Msg("Server failed")
 	}
 }
@@ -176,7 +234,8 @@ func withCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Only allow localhost origins for Phase 1
 		origin := r.Header.Get("Origin")
-		if origin != "" && (strings.HasPrefix(origin, "http://localhost:") || strings.HasPrefix(origin, "http://127.0.0.1:")) {
+		if origin != "" && (strings.HasPrefix(origin, "http://localhost:") || strings.HasPrefix(origin, "http://127.0.0.1:") ||
+			strings.HasPrefix(origin, "https://localhost:") || strings.HasPrefix(origin, "https://127.0.0.1:")) {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
 			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/auth"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genai"
+)
+
+// --- Status (DDR-125) ---
+
+const (
+	// keyRecheckInterval bounds how often /api/status re-validates the
+	// Gemini key; each check is a billable generate call.
+	keyRecheckInterval = 10 * time.Minute
+	// keyCheckTimeout caps a re-validation so a slow network does not stall
+	// the status bar.
+	keyCheckTimeout = 10 * time.Second
+	// lowDiskBytes is the free space below which a preflight warning is shown.
+	lowDiskBytes = 1 << 30 // 1 GiB
+)
+
+// keyCheck caches the result of the last Gemini API key validation.
+type keyCheck struct {
+	mu        sync.Mutex
+	client    *genai.Client
+	checkedAt time.Time
+	err       error
+}
+
+var geminiKey keyCheck
+
+// record stores a validation result, e.g. the one made at startup.
+func (k *keyCheck) record(client *genai.Client, err error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.client = client
+	k.checkedAt = time.Now()
+	k.err = err
+}
+
+// status returns the cached validation result, re-validating first if it is
+// older than keyRecheckInterval. Concurrent callers wait for one check.
+func (k *keyCheck) status(ctx context.Context) (time.Time, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.client != nil && time.Since(k.checkedAt) > keyRecheckInterval {
+		ctx, cancel := context.WithTimeout(ctx, keyCheckTimeout)
+		defer cancel()
+		k.err = auth.ValidateAPIKey(ctx, k.client)
+		k.checkedAt = time.Now()
+		if k.err != nil {
+			log.Warn().Err(k.err).Msg("Gemini API key re-validation failed")
+		}
+	}
+	return k.checkedAt, k.err
+}
+
+type statusJob struct {
+	ID        string    `json:"id"`
+	Status    string    `json:"status"`
+	Files     int       `json:"files"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type statusDisk struct {
+	Path       string `json:"path"`
+	TotalBytes uint64 `json:"totalBytes"`
+	FreeBytes  uint64 `json:"freeBytes"`
+}
+
+// GET /api/status?path=/Users/me/Pictures
+//
+// Reports what the desktop UI needs for its status bar and for preflight
+// warnings before a triage run: build version, Gemini key validity, ffmpeg
+// availability, active triage jobs, and free space on the volume holding
+// path (the user's home directory when omitted).
+func handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	path := r.URL.Query().Get("path")
+	if path != "" && (containsPathTraversal(path) || !filepath.IsAbs(path)) {
+		httpError(w, http.StatusBadRequest, "path must be absolute")
+		return
+	}
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			httpError(w, http.StatusInternalServerError, "cannot determine home directory")
+			return
+		}
+		path = home
+	}
+
+	var warnings []string
+
+	checkedAt, keyErr := geminiKey.status(r.Context())
+	key := map[string]interface{}{
+		"valid":     keyErr == nil,
+		"checkedAt": checkedAt,
+	}
+	if keyErr != nil {
+		key["error"] = keyErr.Error()
+		warnings = append(warnings, "The Gemini API key failed validation; triage will not be able to run.")
+	}
+
+	ffmpeg := media.IsFFmpegAvailable()
+	ffprobe := media.IsFFprobeAvailable()
+	if !ffmpeg || !ffprobe {
+		warnings = append(warnings, "ffmpeg/ffprobe not found; videos will get no thumbnails, metadata or compression.")
+	}
+
+	var disk *statusDisk
+	total, free, err := diskSpace(filepath.Clean(path))
+	if err != nil {
+		log.Debug().Err(err).Str("path", path).Msg("Disk space unavailable")
+	} else {
+		disk = &statusDisk{Path: path, TotalBytes: total, FreeBytes: free}
+		if free < lowDiskBytes {
+			warnings = append(warnings, "Less than 1 GB free on the scanned volume.")
+		}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"version":   commitHash,
+		"buildTime": buildTime,
+		"model":     modelFlag,
+		"geminiKey": key,
+		"ffmpeg":    ffmpeg,
+		"ffprobe":   ffprobe,
+		"jobs":      activeJobs(),
+		"disk":      disk,
+		"warnings":  warnings,
+	})
+}
+
+// activeJobs lists the pending and processing triage jobs, oldest first.
+func activeJobs() []statusJob {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+
+	active := []statusJob{}
+	for _, j := range jobs {
+		j.mu.Lock()
+		if j.status == "pending" || j.status == "processing" {
+			active = append(active, statusJob{
+				ID:        j.id,
+				Status:    j.status,
+				Files:     len(j.paths),
+				CreatedAt: j.createdAt,
+			})
+		}
+		j.mu.Unlock()
+	}
+	sort.Slice(active, func(a, b int) bool {
+		return active[a].CreatedAt.Before(active[b].CreatedAt)
+	})
+	return active
+}
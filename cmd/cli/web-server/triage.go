@@ -1,16 +1,21 @@
 package main
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/fpang/ai-social-media-helper/internal/decisions"
+	"github.com/fpang/ai-social-media-helper/internal/rag"
 	"github.com/rs/zerolog/log"
 )
 
@@ -23,8 +28,11 @@ type triageJob struct {
 	keep      []triageResultItem
 	discard   []triageResultItem
 	errMsg    string
+	model     string
 	paths     []string  // original input paths
 	createdAt time.Time // for TTL-based eviction
+
+	decisionsRecorded bool // guards against duplicate confirms (DDR-107)
 }
 
 type triageResultItem struct {
@@ -34,6 +42,8 @@ type triageResultItem struct {
 	Saveable     bool   `json:"saveable"`
 	Reason       string `json:"reason"`
 	ThumbnailURL string `json:"thumbnailUrl"`
+	PreFiltered  bool   `json:"preFiltered,omitempty"`
+	Sampled      bool   `json:"sampled,omitempty"`
 }
 
 const (
@@ -70,7 +80,7 @@ func newJobID() string {
 	return "triage-" + hex.EncodeToString(b)
 }
 
-func newJob(paths []string) *triageJob {
+func newJob(paths []string, model string) *triageJob {
 	jobsMu.Lock()
 	defer jobsMu.Unlock()
 
@@ -84,6 +94,7 @@ func newJob(paths []string) *triageJob {
 		id:        id,
 		status:    "pending",
 		paths:     paths,
+		model:     model,
 		createdAt: time.Now(),
 	}
 	jobs[id] = j
@@ -167,7 +178,7 @@ func handleTriageStart(w http.ResponseWriter, r *http.Request) {
 		model = req.Model
 	}
 
-	job := newJob(req.Paths)
+	job := newJob(req.Paths, model)
 
 	go runTriageJob(job, model)
 
@@ -204,6 +215,8 @@ func handleTriageRoutes(w http.ResponseWriter, r *http.Request) {
 		handleTriageResults(w, r, job)
 	case "confirm":
 		handleTriageConfirm(w, r, job)
+	case "export":
+		handleTriageExport(w, r, job) // DDR-160
 	default:
 		httpError(w, http.StatusNotFound, "not found")
 	}
@@ -294,6 +307,8 @@ func handleTriageConfirm(w http.ResponseWriter, r *http.Request, job *triageJob)
 		log.Info().Str("path", p).Msg("Deleted file")
 	}
 
+	recordTriageDecisions(r.Context(), job, req.DeletePaths)
+
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"deleted":        deleted,
 		"skipped":        skipped,
@@ -301,3 +316,48 @@ func handleTriageConfirm(w http.ResponseWriter, r *http.Request, job *triageJob)
 		"reclaimedBytes": reclaimedSize,
 	})
 }
+
+// ebClient is set by --record-decisions; when nil no decisions are recorded.
+var ebClient *eventbridge.Client
+
+// recordTriageDecisions sends the confirmed keep/discard verdicts to the RAG
+// pipeline (DDR-107). Kept items were never offered for deletion, so they
+// record the AI's verdict; a discard the user did not delete is an override.
+// Only the first confirm of a job is recorded.
+func recordTriageDecisions(ctx context.Context, job *triageJob, deletePaths []string) {
+	if ebClient == nil {
+		return
+	}
+	job.mu.Lock()
+	if job.decisionsRecorded {
+		job.mu.Unlock()
+		return
+	}
+	job.decisionsRecorded = true
+	keep, discard := job.keep, job.discard
+	job.mu.Unlock()
+
+	deleted := make(map[string]bool, len(deletePaths))
+	for _, p := range deletePaths {
+		deleted[p] = true
+	}
+
+	dw := decisions.NewWriter(decisions.EventBridge(ebClient), decisions.Job{
+		SessionID: job.id, JobID: job.id, UserID: rag.UserID(userIDFlag, job.id), Model: job.model,
+	})
+	for _, item := range keep {
+		dw.RecordTriage(decisions.Triage{
+			MediaKey: filepath.Base(item.Path), Filename: item.Filename,
+			AIKeep: true, UserKeep: true, Reason: item.Reason,
+		})
+	}
+	for _, item := range discard {
+		dw.RecordTriage(decisions.Triage{
+			MediaKey: filepath.Base(item.Path), Filename: item.Filename,
+			AIKeep: false, UserKeep: !deleted[item.Path], Reason: item.Reason,
+		})
+	}
+	if err := dw.Flush(ctx); err != nil {
+		log.Warn().Err(err).Str("job", job.id).Msg("Failed to record triage decisions (best effort)")
+	}
+}
@@ -104,6 +104,8 @@ func runTriageJob(job *triageJob, model string) {
 		return
 	}
 
+	media.ResolveTimeZones(mediaForAI)
+
 	// Use the existing AskMediaTriage function from the chat package
 	// Local mode: no sessionID, no S3 storage
 	output, err := ai.AskMediaTriage(ctx, client, mediaForAI, model, "", nil, nil, nil, "", false, nil)
@@ -130,6 +132,8 @@ func runTriageJob(job *triageJob, model string) {
 			Saveable:     tr.Saveable,
 			Reason:       tr.Reason,
 			ThumbnailURL: "/api/media/thumbnail?path=" + url.QueryEscape(mf.Path),
+			PreFiltered:  tr.PreFiltered,
+			Sampled:      tr.Sampled,
 		}
 		if tr.Saveable {
 			job.keep = append(job.keep, item)
@@ -12,7 +12,8 @@
 //
 // On successful token exchange, the Lambda writes the long-lived token
 // and user ID to SSM, making them available to the API Lambda for
-// Instagram publishing (DDR-040).
+// Instagram publishing (DDR-040), and the token's expiry, which the
+// token-refresh Lambda uses to extend it before it lapses (DDR-173).
 package main
 
 import (
@@ -45,6 +46,7 @@ var (
 	// SSM parameter paths for writing tokens (read from environment).
 	tokenParam  string
 	userIDParam string
+	expiryParam string
 )
 
 var coldStart = true
@@ -128,6 +130,11 @@ func init() {
 	if userIDParam == "" {
 		userIDParam = "/ai-social-media/prod/instagram-user-id"
 	}
+	// Same variable as the token-refresh job, which reads this expiry (DDR-173).
+	expiryParam = os.Getenv("SSM_INSTAGRAM_TOKEN_EXPIRY_PARAM")
+	if expiryParam == "" {
+		expiryParam = "/ai-social-media/prod/instagram-token-expires-at"
+	}
 
 	// Emit consolidated cold-start log for troubleshooting.
 	logging.NewStartupLogger("oauth-lambda").
@@ -137,6 +144,7 @@ func init() {
 		SSMParam("redirectUri", logging.EnvOrDefault("SSM_REDIRECT_URI_PARAM", "/ai-social-media/prod/instagram-oauth-redirect-uri")).
 		SSMParam("tokenStore", tokenParam).
 		SSMParam("userIdStore", userIDParam).
+		SSMParam("tokenExpiryStore", expiryParam).
 		Config("redirectURI", redirectURI).
 		Log()
 }
@@ -296,13 +304,27 @@ func handleOAuthCallback(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Info().Str("param", userIDParam).Str("userId", shortResult.UserID).Msg("Instagram user ID stored in SSM")
 
+	// Step 5: Record the token's expiry for the token-refresh Lambda (DDR-173).
+	// Non-fatal: without it the next refresh run extends the token anyway.
+	expiresAt := time.Now().Add(time.Duration(longResult.ExpiresIn) * time.Second).UTC().Format(time.RFC3339)
+	if _, err := ssmClient.PutParameter(ctx, &ssm.PutParameterInput{
+		Name:      &expiryParam,
+		Value:     &expiresAt,
+		Type:      ssmtypes.ParameterTypeString,
+		Overwrite: aws.Bool(true),
+	}); err != nil {
+		log.Warn().Err(err).Str("param", expiryParam).Msg("Failed to store token expiry in SSM")
+	} else {
+		log.Info().Str("param", expiryParam).Str("expiresAt", expiresAt).Msg("Instagram token expiry stored in SSM")
+	}
+
 	// Success — render confirmation page.
 	days := longResult.ExpiresIn / 86400
 	log.Debug().Int("days", int(days)).Int64("expiresIn", longResult.ExpiresIn).Msg("Token expiry calculated")
 	respondHTML(w, http.StatusOK, "Instagram Connected",
 		fmt.Sprintf("Your Instagram account (user ID: %s) has been connected successfully.<br><br>"+
 			"Long-lived token stored — expires in %d days.<br><br>"+
-			"The API Lambda will use the new token on its next cold start.<br>"+
+			"It is refreshed automatically before it expires, and the other Lambdas pick it up within 15 minutes.<br>"+
 			"You can close this window.", shortResult.UserID, days))
 }
 
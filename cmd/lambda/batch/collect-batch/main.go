@@ -9,13 +9,12 @@ import (
 	"strings"
 	"time"
 
-	"github.com/aws/aws-lambda-go/lambda"
-
 	"github.com/fpang/ai-social-media-helper/internal/ai"
 	"github.com/fpang/ai-social-media-helper/internal/bootstrap"
 	"github.com/fpang/ai-social-media-helper/internal/fbprep"
 	"github.com/fpang/ai-social-media-helper/internal/logging"
 	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/fpang/ai-social-media-helper/internal/sfnevents"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 	"github.com/rs/zerolog/log"
 )
@@ -37,17 +36,17 @@ func init() {
 		Log()
 }
 
-// CollectOutput is the Lambda response.
-type CollectOutput struct {
-	SessionID string `json:"session_id"`
-	Status    string `json:"status"`
-}
+// CollectOutput is the Lambda response; the keys read from the input are
+// documented by sfnevents.CollectBatchEvent (DDR-094).
+type CollectOutput = sfnevents.CollectOutput
 
 func handler(ctx context.Context, event interface{}) (*CollectOutput, error) {
 	m, ok := event.(map[string]interface{})
 	if !ok {
 		return nil, fmt.Errorf("collect-batch: expected map input")
 	}
+	// DDR-118: collect per-job telemetry; PutFBPrepJob records it.
+	ctx, _ = metrics.WithCollector(ctx)
 	sessionID, _ := m["sessionId"].(string)
 	jobID, _ := m["jobId"].(string)
 	batchJobID, _ := m["batchJobId"].(string)
@@ -259,5 +258,5 @@ func handler(ctx context.Context, event interface{}) (*CollectOutput, error) {
 }
 
 func main() {
-	lambda.Start(handler)
+	bootstrap.Start(handler)
 }
@@ -6,7 +6,6 @@ import (
 	"os"
 	"time"
 
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
@@ -16,6 +15,7 @@ import (
 	"github.com/fpang/ai-social-media-helper/internal/httputil"
 	"github.com/fpang/ai-social-media-helper/internal/logging"
 	"github.com/fpang/ai-social-media-helper/internal/s3util"
+	"github.com/fpang/ai-social-media-helper/internal/sfnevents"
 )
 
 var (
@@ -39,13 +39,9 @@ func init() {
 	logging.NewStartupLogger("fb-prep-gcs-upload").InitDuration(time.Since(initStart)).Log()
 }
 
-// UploadOutput is the Lambda response for the GCS upload task.
-type UploadOutput struct {
-	GsURI            string `json:"gs_uri"`
-	BatchIndex       int    `json:"batch_index"`
-	ItemIndexInBatch int    `json:"item_index_in_batch"`
-	S3Key            string `json:"s3_key"`
-}
+// UploadOutput is the Lambda response for the GCS upload task; the input is
+// an sfnevents.VideoToUpload (DDR-094).
+type UploadOutput = sfnevents.UploadOutput
 
 func handler(ctx context.Context, input map[string]interface{}) (*UploadOutput, error) {
 	useKey, _ := input["use_key"].(string)
@@ -109,5 +105,5 @@ func getFloat(m map[string]interface{}, keys ...string) float64 {
 }
 
 func main() {
-	lambda.Start(handler)
+	bootstrap.Start(handler)
 }
@@ -6,10 +6,10 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/fpang/ai-social-media-helper/internal/ai"
 	"github.com/fpang/ai-social-media-helper/internal/bootstrap"
 	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/sfnevents"
 	"github.com/rs/zerolog/log"
 )
 
@@ -27,17 +27,10 @@ func init() {
 	logging.NewStartupLogger("gemini-batch-poll").InitDuration(time.Since(initStart)).Log()
 }
 
-// PollInput is the input for the batch poll Lambda.
-type PollInput struct {
-	BatchJobID string `json:"batch_job_id"`
-}
-
-// PollOutput is the output from the batch poll Lambda.
-type PollOutput struct {
-	State   string          `json:"state"`
-	Results json.RawMessage `json:"results,omitempty"`
-	Error   string          `json:"error,omitempty"`
-}
+type (
+	PollInput  = sfnevents.PollInput
+	PollOutput = sfnevents.PollOutput
+)
 
 func handler(ctx context.Context, input PollInput) (PollOutput, error) {
 	if input.BatchJobID == "" {
@@ -78,5 +71,5 @@ func handler(ctx context.Context, input PollInput) (PollOutput, error) {
 }
 
 func main() {
-	lambda.Start(handler)
+	bootstrap.Start(handler)
 }
@@ -7,7 +7,6 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"google.golang.org/genai"
 
@@ -16,6 +15,8 @@ import (
 	"github.com/fpang/ai-social-media-helper/internal/bootstrap"
 	"github.com/fpang/ai-social-media-helper/internal/fbprep"
 	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/fpang/ai-social-media-helper/internal/sfnevents"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 	"github.com/rs/zerolog/log"
 )
@@ -53,19 +54,17 @@ func init() {
 		Log()
 }
 
-// SubmitOutput is the Lambda response. JSON keys match Step Functions references.
-type SubmitOutput struct {
-	SessionID   string   `json:"session_id"`
-	Status      string   `json:"status"`
-	BatchJobID  string   `json:"batch_job_id,omitempty"`  // single batch
-	BatchJobIDs []string `json:"batch_job_ids,omitempty"` // multiple batches
-}
+// SubmitOutput is the Lambda response; the keys read from the input are
+// documented by sfnevents.SubmitBatchEvent (DDR-094).
+type SubmitOutput = sfnevents.SubmitOutput
 
 func handler(ctx context.Context, event interface{}) (*SubmitOutput, error) {
 	m, ok := event.(map[string]interface{})
 	if !ok {
 		return nil, fmt.Errorf("submit-batch: expected map input")
 	}
+	// DDR-118: collect per-job telemetry; PutFBPrepJob records it.
+	ctx, _ = metrics.WithCollector(ctx)
 
 	sessionID, _ := m["sessionId"].(string)
 	jobID, _ := m["jobId"].(string)
@@ -224,5 +223,5 @@ func handler(ctx context.Context, event interface{}) (*SubmitOutput, error) {
 }
 
 func main() {
-	lambda.Start(handler)
+	bootstrap.Start(handler)
 }
@@ -28,6 +28,9 @@ import (
 const maxPresignedURLBytes int64 = 10 * 1024 * 1024 // 10 MiB (DDR-060)
 
 func handler(ctx context.Context, event interface{}) (out *FBPrepOutput, retErr error) {
+	// DDR-118: collect per-job telemetry; PutFBPrepJob records it.
+	ctx, _ = metrics.WithCollector(ctx)
+
 	// Check for special event types before attempting batch normalization.
 	if m, ok := event.(map[string]interface{}); ok {
 		if t, _ := m["type"].(string); t == "fb-prep-feedback" {
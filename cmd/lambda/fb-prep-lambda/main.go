@@ -4,11 +4,11 @@ import (
 	"os"
 	"time"
 
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 
 	"github.com/fpang/ai-social-media-helper/internal/ai"
 	"github.com/fpang/ai-social-media-helper/internal/bootstrap"
+	"github.com/fpang/ai-social-media-helper/internal/jobs"
 	"github.com/fpang/ai-social-media-helper/internal/logging"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 )
@@ -47,5 +47,5 @@ func init() {
 }
 
 func main() {
-	lambda.Start(handler)
+	bootstrap.Start(jobs.WithQueue(handler)) // Step Functions, direct invoke, or SQS job queue (DDR-092)
 }
@@ -0,0 +1,146 @@
+// Package main provides a Lambda entry point that collects Instagram insights
+// for published posts (DDR-146).
+//
+// The publish Lambda starts tracking every post it publishes. This Lambda
+// runs hourly and, for each tracked post that is due — hourly for its first
+// two days, daily until store.InsightsTrackingWindow ends:
+//
+//  1. Fetches reach, impressions, likes, comments and saves from the Graph API
+//  2. Stores the reading as one point of the post's time series
+//  3. Records the poll (or its error) on the tracking record
+//
+// Posts from publish dry runs (DDR-139) are answered by the mock client.
+//
+// Trigger: EventBridge schedule rate(1 hour)
+// Container: Light (Dockerfile.light — no ffmpeg needed)
+// Memory: 256 MB
+// Timeout: 5 minutes
+package main
+
+import (
+	"context"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/bootstrap"
+	"github.com/fpang/ai-social-media-helper/internal/instagram"
+	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// maxPollsPerRun keeps one run well inside the Graph API's 200 calls per
+// account per hour. Posts left over are the least overdue and go next run.
+const maxPollsPerRun = 150
+
+var coldStart = true
+
+// AWS clients initialized at cold start.
+var (
+	sessionStore *store.DynamoStore
+	igClient     *instagram.Client
+	mockClient   = instagram.NewMockClient()
+)
+
+func init() {
+	initStart := time.Now()
+	logging.Init()
+
+	awsClients := bootstrap.InitAWS()
+	sessionStore = bootstrap.InitDynamo(awsClients.Config, "DYNAMO_TABLE_NAME")
+	igClient = bootstrap.LoadInstagramCreds(awsClients.SSM)
+
+	bootstrap.StartupLog("insights-poller-lambda", initStart).
+		DynamoTable("sessions", os.Getenv("DYNAMO_TABLE_NAME")).
+		SSMParam("instagramToken", logging.EnvOrDefault("SSM_INSTAGRAM_TOKEN_PARAM", "/ai-social-media/prod/instagram-access-token")).
+		SSMParam("instagramUserId", logging.EnvOrDefault("SSM_INSTAGRAM_USER_ID_PARAM", "/ai-social-media/prod/instagram-user-id")).
+		Feature("instagram", igClient != nil).
+		Log()
+}
+
+func main() {
+	bootstrap.Start(handler)
+}
+
+// handler samples every due post. The EventBridge event carries nothing the
+// poller needs.
+func handler(ctx context.Context) error {
+	if coldStart {
+		coldStart = false
+		log.Info().Str("function", "insights-poller-lambda").Msg("Cold start — first invocation")
+	}
+
+	posts, err := sessionStore.ListTrackedPosts(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	due := posts[:0]
+	for _, p := range posts {
+		if p.Due(now) {
+			due = append(due, p)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextPollAt().Before(due[j].NextPollAt()) })
+	if len(due) > maxPollsPerRun {
+		log.Warn().Int("due", len(due)).Int("limit", maxPollsPerRun).Msg("More posts due than one run may poll — deferring the rest")
+		due = due[:maxPollsPerRun]
+	}
+
+	sampled, failed := 0, 0
+	for _, post := range due {
+		if pollPost(ctx, post) {
+			sampled++
+		} else {
+			failed++
+		}
+	}
+	log.Info().Int("tracked", len(posts)).Int("sampled", sampled).Int("failed", failed).Msg("Insights poll finished")
+	metrics.New("AiSocialMedia").
+		Dimension("JobType", "insights-poller").
+		Metric("InsightsPostsTracked", float64(len(posts)), metrics.UnitCount).
+		Metric("InsightsSamples", float64(sampled), metrics.UnitCount).
+		Metric("InsightsErrors", float64(failed), metrics.UnitCount).
+		Flush()
+	return nil
+}
+
+// pollPost takes one insights sample and reports whether it succeeded.
+func pollPost(ctx context.Context, post store.TrackedPost) bool {
+	logger := log.With().Str("instagramPostId", post.MediaID).Str("jobId", post.JobID).Logger()
+
+	ig := igClient
+	if instagram.IsMockID(post.MediaID) {
+		ig = mockClient
+	}
+	if ig == nil {
+		logger.Warn().Msg("Instagram client not configured — skipping post")
+		return false
+	}
+
+	insights, err := ig.MediaInsights(ctx, post.MediaID)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to fetch post insights")
+		if err := sessionStore.PutInsightsSample(ctx, post.MediaID, nil, err.Error()); err != nil {
+			logger.Error().Err(err).Msg("Failed to record insights poll error")
+		}
+		return false
+	}
+
+	sample := &store.InsightsSample{
+		Reach:       insights.Reach,
+		Impressions: insights.Impressions,
+		Likes:       insights.Likes,
+		Comments:    insights.Comments,
+		Saves:       insights.Saves,
+	}
+	if err := sessionStore.PutInsightsSample(ctx, post.MediaID, sample, ""); err != nil {
+		logger.Error().Err(err).Msg("Failed to store insights sample")
+		return false
+	}
+	logger.Debug().Int64("reach", sample.Reach).Int64("likes", sample.Likes).Msg("Insights sample stored")
+	return true
+}
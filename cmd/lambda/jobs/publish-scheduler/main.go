@@ -0,0 +1,207 @@
+// Package main provides a Lambda entry point that dispatches scheduled posts (DDR-144).
+//
+// POST /api/publish/start with a publishAt time stores the Publish Pipeline
+// input in the scheduled-posts table instead of starting the pipeline. This
+// Lambda runs every minute and, for each post whose time has come:
+//
+//  1. Claims the post (scheduled → dispatched) so a cancel or a concurrent
+//     run cannot act on it
+//  2. Marks the session's publish job pending
+//  3. Starts the Publish Pipeline with the stored input, named by job ID so a
+//     repeated start is a no-op
+//
+// A failed start returns the post to the schedule for the next run, up to
+// maxDispatchAttempts; after that the post and its job are marked as failed.
+//
+// Trigger: EventBridge schedule rate(1 minute)
+// Container: Light (Dockerfile.light — no ffmpeg needed)
+// Memory: 256 MB
+// Timeout: 1 minute
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sfn"
+	sfntypes "github.com/aws/aws-sdk-go-v2/service/sfn/types"
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/bootstrap"
+	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/fpang/ai-social-media-helper/internal/sfnevents"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// maxDispatchAttempts is how many runs try to start a post's pipeline before
+// it is marked as failed.
+const maxDispatchAttempts = 3
+
+var coldStart = true
+
+// AWS clients initialized at cold start.
+var (
+	sessionStore       *store.DynamoStore
+	scheduledPostStore *store.ScheduledPostStore
+	sfnClient          *sfn.Client
+	publishSfnArn      string
+)
+
+func init() {
+	initStart := time.Now()
+	logging.Init()
+
+	awsClients := bootstrap.InitAWS()
+	sessionStore = bootstrap.InitDynamo(awsClients.Config, "DYNAMO_TABLE_NAME")
+	scheduledTable := os.Getenv("SCHEDULED_POSTS_TABLE_NAME")
+	if scheduledTable == "" {
+		log.Fatal().Msg("SCHEDULED_POSTS_TABLE_NAME environment variable is required")
+	}
+	scheduledPostStore = store.NewScheduledPostStore(sessionStore.Client(), scheduledTable)
+	sfnClient = sfn.NewFromConfig(awsClients.Config)
+	publishSfnArn = os.Getenv("PUBLISH_STATE_MACHINE_ARN")
+	if publishSfnArn == "" {
+		log.Fatal().Msg("PUBLISH_STATE_MACHINE_ARN environment variable is required")
+	}
+
+	bootstrap.StartupLog("publish-scheduler-lambda", initStart).
+		DynamoTable("sessions", os.Getenv("DYNAMO_TABLE_NAME")).
+		DynamoTable("scheduledPosts", scheduledTable).
+		StateMachine("publishPipeline", publishSfnArn).
+		Log()
+}
+
+func main() {
+	bootstrap.Start(handler)
+}
+
+// handler dispatches every due post. The EventBridge event carries nothing
+// the scheduler needs; the current time decides what is due.
+func handler(ctx context.Context) error {
+	if coldStart {
+		coldStart = false
+		log.Info().Str("function", "publish-scheduler-lambda").Msg("Cold start — first invocation")
+	}
+
+	due, err := scheduledPostStore.ListDueScheduledPosts(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	dispatched := 0
+	for _, post := range due {
+		if dispatchPost(ctx, post) {
+			dispatched++
+		}
+	}
+	if len(due) > 0 {
+		log.Info().Int("due", len(due)).Int("dispatched", dispatched).Msg("Scheduled posts dispatched")
+		metrics.New("AiSocialMedia").
+			Dimension("JobType", "publish-scheduler").
+			Metric("ScheduledPostsDue", float64(len(due)), metrics.UnitCount).
+			Metric("ScheduledPostsDispatched", float64(dispatched), metrics.UnitCount).
+			Flush()
+	}
+	return nil
+}
+
+// dispatchPost starts one due post's pipeline and reports whether it did.
+func dispatchPost(ctx context.Context, post store.ScheduledPost) bool {
+	logger := log.With().
+		Str("jobId", post.JobID).
+		Str("sessionId", post.SessionID).
+		Int64("publishAt", post.PublishAt).
+		Logger()
+
+	if err := scheduledPostStore.ClaimScheduledPost(ctx, post.OwnerSub, post.JobID); err != nil {
+		if errors.Is(err, store.ErrStatusConflict) {
+			logger.Info().Msg("Scheduled post cancelled or claimed elsewhere — skipping")
+		} else {
+			logger.Error().Err(err).Msg("Failed to claim scheduled post")
+		}
+		return false
+	}
+
+	pendingJob := &store.PublishJob{
+		ID:         post.JobID,
+		GroupID:    post.GroupID,
+		Status:     "pending",
+		Phase:      "pending",
+		TotalItems: post.KeyCount,
+	}
+	if err := sessionStore.PutPublishJob(ctx, post.SessionID, pendingJob); err != nil {
+		logger.Warn().Err(err).Msg("Failed to mark publish job pending")
+	}
+
+	_, err := sfnClient.StartExecution(ctx, &sfn.StartExecutionInput{
+		StateMachineArn: aws.String(publishSfnArn),
+		Input:           aws.String(pipelineInput(post.Input)),
+		Name:            aws.String(post.JobID),
+	})
+	var exists *sfntypes.ExecutionAlreadyExists
+	if err == nil || errors.As(err, &exists) {
+		logger.Info().Int64("lateBySec", time.Now().Unix()-post.PublishAt).Msg("Job dispatched to Publish Pipeline")
+		return true
+	}
+
+	errMsg := fmt.Sprintf("failed to start publishing: %v", err)
+	if post.Attempts+1 < maxDispatchAttempts {
+		logger.Warn().Err(err).Int("attempt", post.Attempts+1).Msg("Failed to start publish pipeline — retrying next run")
+		if err := scheduledPostStore.ReleaseScheduledPost(ctx, post.OwnerSub, post.JobID, errMsg); err != nil {
+			logger.Error().Err(err).Msg("Failed to return scheduled post to the schedule")
+		}
+		return false
+	}
+
+	logger.Error().Err(err).Int("attempts", post.Attempts+1).Msg("Failed to start publish pipeline — giving up")
+	if err := scheduledPostStore.FailScheduledPost(ctx, post.OwnerSub, post.JobID, errMsg); err != nil {
+		logger.Error().Err(err).Msg("Failed to mark scheduled post as failed")
+	}
+	errJob := &store.PublishJob{ID: post.JobID, GroupID: post.GroupID, Status: "error", Phase: "error", Error: errMsg}
+	if err := sessionStore.PutPublishJob(ctx, post.SessionID, errJob); err != nil {
+		logger.Warn().Err(err).Msg("Failed to mark publish job failed")
+	}
+	return false
+}
+
+// pipelineInput returns the stored pipeline input with a platform (DDR-147),
+// alt-text (DDR-151), targets (DDR-153) and a carousel order (DDR-179).
+// Posts scheduled before the Publish Pipeline read $.platform, $.altText,
+// $.targets or $.order have none, and the state machine fails on a missing
+// path.
+func pipelineInput(input string) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(input), &fields); err != nil {
+		return input
+	}
+	_, hasPlatform := fields["platform"]
+	_, hasAltText := fields["altText"]
+	_, hasTargets := fields["targets"]
+	_, hasOrder := fields["order"]
+	if hasPlatform && hasAltText && hasTargets && hasOrder {
+		return input
+	}
+	if !hasPlatform {
+		fields["platform"], _ = json.Marshal(sfnevents.PlatformInstagram)
+	}
+	if !hasAltText {
+		fields["altText"] = json.RawMessage("null")
+	}
+	if !hasTargets {
+		fields["targets"] = json.RawMessage("null")
+	}
+	if !hasOrder {
+		fields["order"] = json.RawMessage("null")
+	}
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return input
+	}
+	return string(out)
+}
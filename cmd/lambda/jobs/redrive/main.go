@@ -0,0 +1,208 @@
+// Package main provides a Lambda entry point for dead-letter redrive of async jobs (DDR-089).
+//
+// The description, download, enhance, and FB prep Lambdas are invoked by the
+// API Lambda with InvocationType=Event (DDR-053). When an invocation exhausts
+// Lambda's built-in async retries (crash, timeout, out-of-memory), the event
+// lands on the job dead-letter queue. This Lambda consumes that queue and,
+// for each message:
+//
+//  1. Resolves the job (sessionId, jobId) from the original event payload
+//  2. Re-invokes the original target if the job has retries remaining
+//  3. Otherwise marks the job as "error" so the frontend stops polling
+//
+// Trigger: SQS event source on the job DLQ (ReportBatchItemFailures enabled)
+// Container: Light (Dockerfile.light — no ffmpeg needed)
+// Memory: 256 MB
+// Timeout: 1 minute
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	lambdasvc "github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/bootstrap"
+	"github.com/fpang/ai-social-media-helper/internal/jobs"
+	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+var coldStart = true
+
+// AWS clients initialized at cold start.
+var (
+	sessionStore *store.DynamoStore
+	lambdaClient *lambdasvc.Client
+)
+
+func init() {
+	initStart := time.Now()
+	logging.Init()
+
+	awsClients := bootstrap.InitAWS()
+	sessionStore = bootstrap.InitDynamo(awsClients.Config, "DYNAMO_TABLE_NAME")
+	lambdaClient = lambdasvc.NewFromConfig(awsClients.Config)
+
+	bootstrap.StartupLog("job-redrive-lambda", initStart).
+		DynamoTable("sessions", os.Getenv("DYNAMO_TABLE_NAME")).
+		Log()
+}
+
+func main() {
+	bootstrap.Start(handler)
+}
+
+// jobEvent is the subset of every async job event needed to locate the job.
+type jobEvent struct {
+	Type      string `json:"type"`
+	SessionID string `json:"sessionId"`
+	JobID     string `json:"jobId"`
+}
+
+// destinationRecord is the envelope Lambda writes when an on-failure
+// destination (rather than a function DLQ) routes the event to the queue.
+type destinationRecord struct {
+	RequestContext struct {
+		FunctionArn string `json:"functionArn"`
+		Condition   string `json:"condition"`
+	} `json:"requestContext"`
+	RequestPayload json.RawMessage `json:"requestPayload"`
+}
+
+func handler(ctx context.Context, sqsEvent events.SQSEvent) (events.SQSEventResponse, error) {
+	if coldStart {
+		coldStart = false
+		log.Info().Str("function", "job-redrive-lambda").Msg("Cold start — first invocation")
+	}
+
+	var resp events.SQSEventResponse
+	for _, record := range sqsEvent.Records {
+		if err := redriveRecord(ctx, record); err != nil {
+			log.Error().Err(err).Str("messageId", record.MessageId).Msg("Failed to redrive DLQ record — leaving on queue")
+			resp.BatchItemFailures = append(resp.BatchItemFailures, events.SQSBatchItemFailure{
+				ItemIdentifier: record.MessageId,
+			})
+		}
+	}
+	return resp, nil
+}
+
+// redriveRecord re-dispatches one dead-lettered job event. Returns an error only
+// when the message should stay on the queue for another attempt; messages that
+// can never succeed (unparseable, job gone, retries exhausted) are consumed.
+func redriveRecord(ctx context.Context, record events.SQSMessage) error {
+	payload, target, reason := unwrapMessage(record)
+
+	var event jobEvent
+	if err := json.Unmarshal(payload, &event); err != nil || event.SessionID == "" || event.JobID == "" {
+		log.Warn().Str("messageId", record.MessageId).Msg("DLQ message is not a job event — dropping")
+		return nil
+	}
+
+	logger := log.With().
+		Str("sessionId", event.SessionID).
+		Str("jobId", event.JobID).
+		Str("type", event.Type).
+		Logger()
+
+	summary, err := sessionStore.GetJobSummary(ctx, event.SessionID, event.JobID)
+	if err != nil {
+		return fmt.Errorf("read job: %w", err)
+	}
+	if summary == nil {
+		// Session expired or was invalidated by back-navigation (DDR-037).
+		logger.Info().Msg("Job no longer exists — dropping DLQ message")
+		return nil
+	}
+	if summary.Status == "complete" {
+		logger.Info().Msg("Job already complete — dropping DLQ message")
+		return nil
+	}
+
+	if target == "" {
+		rec, err := sessionStore.GetDispatchRecord(ctx, event.SessionID, event.JobID)
+		if err != nil {
+			return fmt.Errorf("read dispatch record: %w", err)
+		}
+		if rec != nil {
+			target = rec.Target
+		}
+	}
+	if target == "" {
+		logger.Warn().Msg("No dispatch target for job — marking as failed")
+		return failJob(ctx, event, fmt.Sprintf("processing failed: %s", reason))
+	}
+
+	if summary.RetryCount >= jobs.MaxRetries {
+		logger.Warn().Int("retryCount", summary.RetryCount).Str("reason", reason).Msg("Retries exhausted — marking job as failed")
+		metrics.New("AiSocialMedia").
+			Dimension("JobType", event.Type).
+			Count("JobRetriesExhausted").
+			Property("jobId", event.JobID).
+			Flush()
+		return failJob(ctx, event, fmt.Sprintf("processing failed after %d retries: %s", summary.RetryCount, reason))
+	}
+
+	retryCount, err := sessionStore.IncrementJobRetryCount(ctx, event.SessionID, event.JobID, "pending", jobs.MaxRetries)
+	if errors.Is(err, store.ErrRetryLimit) {
+		logger.Warn().Str("reason", reason).Msg("Retries exhausted by a concurrent retry — marking job as failed")
+		return failJob(ctx, event, fmt.Sprintf("processing failed after %d retries: %s", jobs.MaxRetries, reason))
+	}
+	if err != nil {
+		return fmt.Errorf("increment retry count: %w", err)
+	}
+
+	if _, err := lambdaClient.Invoke(ctx, &lambdasvc.InvokeInput{
+		FunctionName:   aws.String(target),
+		InvocationType: lambdatypes.InvocationTypeEvent,
+		Payload:        payload,
+	}); err != nil {
+		return fmt.Errorf("re-invoke %s: %w", target, err)
+	}
+
+	logger.Info().Int("retryCount", retryCount).Str("reason", reason).Msg("Job redriven from DLQ")
+	metrics.New("AiSocialMedia").
+		Dimension("JobType", event.Type).
+		Count("JobRedriven").
+		Property("jobId", event.JobID).
+		Property("retryCount", retryCount).
+		Flush()
+	return nil
+}
+
+// unwrapMessage extracts the original event payload, the target function ARN
+// (only known for destination records), and a human-readable failure reason.
+// A plain function DLQ delivers the original event as the body with the error
+// in message attributes; an on-failure destination wraps it in an envelope.
+func unwrapMessage(record events.SQSMessage) (payload []byte, target, reason string) {
+	reason = "worker invocation failed"
+	if attr, ok := record.MessageAttributes["ErrorMessage"]; ok && attr.StringValue != nil {
+		reason = *attr.StringValue
+	}
+
+	var dest destinationRecord
+	if err := json.Unmarshal([]byte(record.Body), &dest); err == nil && len(dest.RequestPayload) > 0 {
+		if dest.RequestContext.Condition != "" {
+			reason = dest.RequestContext.Condition
+		}
+		return dest.RequestPayload, dest.RequestContext.FunctionArn, reason
+	}
+	return []byte(record.Body), "", reason
+}
+
+func failJob(ctx context.Context, event jobEvent, errMsg string) error {
+	if err := sessionStore.UpdateJobStatus(ctx, event.SessionID, event.JobID, "error", errMsg); err != nil {
+		return fmt.Errorf("mark job failed: %w", err)
+	}
+	return nil
+}
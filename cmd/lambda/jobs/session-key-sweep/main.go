@@ -0,0 +1,94 @@
+// Package main provides a Lambda entry point that retires the KMS keys of
+// sessions that no longer exist (DDR-164).
+//
+// An encrypted session's key is retired when the session is deleted or
+// merged, but most sessions end through the DynamoDB TTL or the bucket
+// lifecycle instead. This Lambda runs daily and:
+//
+//  1. Lists the KMS aliases alias/ai-social-media/session-{id}
+//  2. Looks up each session in DynamoDB
+//  3. For each session that is gone, removes the alias and schedules the key
+//     for deletion after the 7-day KMS waiting period
+//
+// A session that cannot be looked up keeps its key until the next run.
+//
+// Trigger: EventBridge schedule rate(1 day)
+// Container: Light (Dockerfile.light — no ffmpeg needed)
+// Memory: 128 MB
+// Timeout: 5 minutes
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/bootstrap"
+	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+var coldStart = true
+
+// AWS clients initialized at cold start.
+var (
+	sessionKeys  *s3util.SessionKeys
+	sessionStore *store.DynamoStore
+)
+
+func init() {
+	initStart := time.Now()
+	logging.Init()
+
+	awsClients := bootstrap.InitAWS()
+	sessionKeys = s3util.NewSessionKeys(kms.NewFromConfig(awsClients.Config))
+	sessionStore = bootstrap.InitDynamo(awsClients.Config, "DYNAMO_TABLE_NAME")
+
+	bootstrap.StartupLog("session-key-sweep-lambda", initStart).
+		DynamoTable("sessions", os.Getenv("DYNAMO_TABLE_NAME")).
+		Log()
+}
+
+func main() {
+	bootstrap.Start(handler)
+}
+
+// handler sweeps the session keys. The EventBridge event carries nothing
+// the sweep needs.
+func handler(ctx context.Context) (*s3util.SweepResult, error) {
+	if coldStart {
+		coldStart = false
+		log.Info().Str("function", "session-key-sweep-lambda").Msg("Cold start — first invocation")
+	}
+	start := time.Now()
+
+	result, err := sessionKeys.Sweep(ctx, sessionExists)
+	if err != nil {
+		return nil, fmt.Errorf("sweep session keys: %w", err)
+	}
+
+	log.Info().
+		Int("keys", result.Keys).
+		Int("retired", result.Retired).
+		Int("failed", result.Failed).
+		Dur("duration", time.Since(start)).
+		Msg("Session key sweep finished")
+	metrics.New("AiSocialMedia").
+		Dimension("JobType", "session-key-sweep").
+		Metric("SessionKeysRetired", float64(result.Retired), metrics.UnitCount).
+		Metric("SessionKeySweepFailures", float64(result.Failed), metrics.UnitCount).
+		Flush()
+	return &result, nil
+}
+
+// sessionExists reports whether the session table still holds the session.
+func sessionExists(ctx context.Context, sessionID string) (bool, error) {
+	session, err := sessionStore.GetSession(ctx, sessionID)
+	return session != nil, err
+}
@@ -0,0 +1,218 @@
+// Package main provides a Lambda entry point that reconciles the media
+// bucket against the session table (DDR-148).
+//
+// S3 Inventory delivers a daily CSV listing of the media bucket. This Lambda
+// runs once a day, after the inventory lands, and:
+//
+//  1. Reads the newest inventory manifest (or the one named in the event)
+//  2. Groups the listed objects by session prefix
+//  3. Looks up each session in DynamoDB
+//  4. Writes a report of unreferenced sessions, missing thumbnails and
+//     sessions with storage but no jobs to reconcile-reports/ in the
+//     inventory bucket, where GET /api/admin/storage-report reads it
+//
+// The report only describes drift; nothing is deleted.
+//
+// Trigger: EventBridge schedule cron(0 6 * * ? *), or invoked by an operator
+// with {"manifestKey": "..."}
+// Container: Light (Dockerfile.light — no ffmpeg needed)
+// Memory: 512 MB
+// Timeout: 10 minutes
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/bootstrap"
+	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/fpang/ai-social-media-helper/internal/reconcile"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+var coldStart = true
+
+// AWS clients initialized at cold start.
+var (
+	s3Client        *s3.Client
+	inventoryBucket string
+	inventoryPrefix string
+	sessionStore    *store.DynamoStore
+)
+
+func init() {
+	initStart := time.Now()
+	logging.Init()
+
+	awsClients := bootstrap.InitAWS()
+	s3s := bootstrap.InitS3(awsClients.Config, "INVENTORY_BUCKET_NAME")
+	s3Client = s3s.Client
+	inventoryBucket = s3s.Bucket
+	inventoryPrefix = logging.EnvOrDefault("INVENTORY_PREFIX", "inventory/")
+	sessionStore = bootstrap.InitDynamo(awsClients.Config, "DYNAMO_TABLE_NAME")
+
+	bootstrap.StartupLog("storage-reconcile-lambda", initStart).
+		S3Bucket("inventoryBucket", inventoryBucket).
+		DynamoTable("sessions", os.Getenv("DYNAMO_TABLE_NAME")).
+		Config("inventoryPrefix", inventoryPrefix).
+		Log()
+}
+
+func main() {
+	bootstrap.Start(handler)
+}
+
+// reconcileEvent names the inventory to reconcile; empty means the newest.
+type reconcileEvent struct {
+	ManifestKey string `json:"manifestKey"`
+}
+
+func handler(ctx context.Context, event reconcileEvent) (*reconcile.Report, error) {
+	if coldStart {
+		coldStart = false
+		log.Info().Str("function", "storage-reconcile-lambda").Msg("Cold start — first invocation")
+	}
+	start := time.Now()
+
+	manifestKey := event.ManifestKey
+	if manifestKey == "" {
+		var err error
+		if manifestKey, err = latestManifest(ctx); err != nil {
+			return nil, err
+		}
+	}
+	data, err := getObject(ctx, manifestKey)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest %s: %w", manifestKey, err)
+	}
+	manifest, err := reconcile.ParseManifest(data)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Str("manifest", manifestKey).Str("sourceBucket", manifest.SourceBucket).Int("files", len(manifest.Files)).Msg("Reconciling inventory")
+
+	builder := reconcile.NewBuilder(manifest.SourceBucket, manifest.CreatedAt())
+	for _, file := range manifest.Files {
+		if err := readInventoryFile(ctx, manifest, file.Key, builder.Add); err != nil {
+			return nil, err
+		}
+	}
+
+	report, err := builder.Build(ctx, lookupSession)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeReport(ctx, report, manifest.CreatedAt()); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int64("objects", report.Objects).
+		Int("sessions", report.Sessions).
+		Int("unreferenced", report.UnreferencedCount).
+		Int64("unreferencedBytes", report.UnreferencedBytes).
+		Int("noJobs", report.NoJobsCount).
+		Int("missingThumbnails", report.MissingThumbnailCount).
+		Dur("duration", time.Since(start)).
+		Msg("Storage reconciliation finished")
+	metrics.New("AiSocialMedia").
+		Dimension("JobType", "storage-reconcile").
+		Metric("ReconcileUnreferencedBytes", float64(report.UnreferencedBytes), metrics.UnitBytes).
+		Metric("ReconcileUnreferencedSessions", float64(report.UnreferencedCount), metrics.UnitCount).
+		Metric("ReconcileNoJobSessions", float64(report.NoJobsCount), metrics.UnitCount).
+		Metric("ReconcileMissingThumbnails", float64(report.MissingThumbnailCount), metrics.UnitCount).
+		Flush()
+	return report, nil
+}
+
+// lookupSession reports whether the session table still holds the session
+// and how many job records it has.
+func lookupSession(ctx context.Context, sessionID string) (reconcile.SessionState, error) {
+	rec, err := sessionStore.DescribeSession(ctx, sessionID)
+	if err != nil || rec == nil {
+		return reconcile.SessionState{}, err
+	}
+	return reconcile.SessionState{Exists: true, Jobs: len(rec.Jobs)}, nil
+}
+
+// latestManifest returns the key of the most recently delivered inventory
+// manifest under inventoryPrefix.
+func latestManifest(ctx context.Context) (string, error) {
+	var latest string
+	var latestAt time.Time
+	paginator := s3.NewListObjectsV2Paginator(s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(inventoryBucket),
+		Prefix: aws.String(inventoryPrefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return "", fmt.Errorf("list inventory manifests: %w", err)
+		}
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+			if strings.HasSuffix(key, "/manifest.json") && aws.ToTime(obj.LastModified).After(latestAt) {
+				latest, latestAt = key, aws.ToTime(obj.LastModified)
+			}
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no inventory manifest under s3://%s/%s", inventoryBucket, inventoryPrefix)
+	}
+	return latest, nil
+}
+
+// readInventoryFile streams one gzipped CSV data file into fn.
+func readInventoryFile(ctx context.Context, manifest *reconcile.Manifest, key string, fn func(reconcile.Object)) error {
+	out, err := s3Client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(inventoryBucket), Key: aws.String(key)})
+	if err != nil {
+		return fmt.Errorf("read inventory file %s: %w", key, err)
+	}
+	defer out.Body.Close()
+	if err := manifest.ReadObjects(out.Body, fn); err != nil {
+		return fmt.Errorf("inventory file %s: %w", key, err)
+	}
+	return nil
+}
+
+// writeReport stores the report under the inventory's date and as the
+// latest report.
+func writeReport(ctx context.Context, report *reconcile.Report, inventoryAt time.Time) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshal report: %w", err)
+	}
+	dated := reconcile.ReportPrefix + inventoryAt.UTC().Format(time.DateOnly) + ".json"
+	for _, key := range []string{dated, reconcile.LatestReportKey} {
+		if _, err := s3Client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:      aws.String(inventoryBucket),
+			Key:         aws.String(key),
+			Body:        bytes.NewReader(body),
+			ContentType: aws.String("application/json"),
+		}); err != nil {
+			return fmt.Errorf("write report %s: %w", key, err)
+		}
+	}
+	log.Debug().Str("key", dated).Int("bytes", len(body)).Msg("Reconciliation report written")
+	return nil
+}
+
+func getObject(ctx context.Context, key string) ([]byte, error) {
+	out, err := s3Client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(inventoryBucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+	return io.ReadAll(out.Body)
+}
@@ -0,0 +1,182 @@
+// Package main provides a Lambda entry point that keeps the Instagram
+// long-lived access token from expiring (DDR-173).
+//
+// Long-lived tokens last 60 days, and publishing starts failing with error
+// code 190 once the token lapses. This Lambda runs daily and:
+//
+//  1. Reads the token and its recorded expiry from SSM
+//  2. If the token expires within INSTAGRAM_TOKEN_REFRESH_DAYS, or its expiry
+//     is unknown, extends it via GET /refresh_access_token
+//  3. Writes the refreshed token back to SSM or Secrets Manager, and its new
+//     expiry to SSM, where the other Lambdas reread it (DDR-172)
+//  4. Emits the days the token has left, so an alarm can fire well before
+//     it lapses
+//
+// A token that has already expired cannot be refreshed; the run fails and
+// the account must be connected again via /oauth/authorize (DDR-048).
+//
+// Trigger: EventBridge schedule rate(1 day)
+// Container: Light (Dockerfile.light — no ffmpeg needed)
+// Memory: 128 MB
+// Timeout: 1 minute
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/bootstrap"
+	"github.com/fpang/ai-social-media-helper/internal/instagram"
+	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+)
+
+// defaultRefreshDays is how close to expiry a token is refreshed. Half the
+// token's 60 days leaves a month of daily retries if refreshing fails.
+const defaultRefreshDays = 30
+
+var coldStart = true
+
+// AWS clients initialized at cold start.
+var (
+	ssmClient     *ssm.Client
+	tokenSecret   *bootstrap.Secret
+	expiryParam   string
+	refreshWindow time.Duration
+)
+
+func init() {
+	initStart := time.Now()
+	logging.Init()
+
+	awsClients := bootstrap.InitAWS()
+	ssmClient = awsClients.SSM
+	tokenParam := logging.EnvOrDefault("SSM_INSTAGRAM_TOKEN_PARAM", "/ai-social-media/prod/instagram-access-token")
+	tokenSecret = bootstrap.NewSecret(ssmClient, tokenParam).
+		WithSecretsManager(secretsmanager.NewFromConfig(awsClients.Config))
+	expiryParam = logging.EnvOrDefault("SSM_INSTAGRAM_TOKEN_EXPIRY_PARAM", "/ai-social-media/prod/instagram-token-expires-at")
+
+	days := defaultRefreshDays
+	if env := os.Getenv("INSTAGRAM_TOKEN_REFRESH_DAYS"); env != "" {
+		if n, err := strconv.Atoi(env); err == nil && n > 0 {
+			days = n
+		} else {
+			log.Warn().Str("value", env).Msg("Invalid INSTAGRAM_TOKEN_REFRESH_DAYS, using the default")
+		}
+	}
+	refreshWindow = time.Duration(days) * 24 * time.Hour
+
+	bootstrap.StartupLog("token-refresh-lambda", initStart).
+		SSMParam("instagramToken", tokenParam).
+		SSMParam("instagramTokenExpiry", expiryParam).
+		Config("refreshDays", strconv.Itoa(days)).
+		Feature("mock", instagram.MockEnabled()).
+		Log()
+}
+
+func main() {
+	bootstrap.Start(handler)
+}
+
+// handler refreshes the token if it is due. The EventBridge event carries
+// nothing the refresher needs.
+func handler(ctx context.Context) error {
+	if coldStart {
+		coldStart = false
+		log.Info().Str("function", "token-refresh-lambda").Msg("Cold start — first invocation")
+	}
+	if instagram.MockEnabled() {
+		log.Info().Msg("Instagram mock mode — no token to refresh")
+		return nil
+	}
+
+	rec := metrics.New("AiSocialMedia").Dimension("JobType", "token-refresh")
+	defer rec.Flush()
+
+	now := time.Now()
+	expiresAt, known, err := readExpiry(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to read the token expiry — refreshing anyway")
+	}
+	if known {
+		rec.Metric("InstagramTokenDaysRemaining", daysUntil(expiresAt, now), metrics.UnitNone)
+		if !expiresAt.After(now) {
+			log.Error().Time("expiresAt", expiresAt).Msg("Instagram token has expired — connect the account again via /oauth/authorize")
+			rec.Count("InstagramTokenRefreshErrors")
+			return fmt.Errorf("instagram token expired at %s", expiresAt.Format(time.RFC3339))
+		}
+		if expiresAt.Sub(now) > refreshWindow {
+			log.Info().Time("expiresAt", expiresAt).Msg("Instagram token not due for refresh")
+			return nil
+		}
+	}
+
+	token, err := tokenSecret.Value(ctx)
+	if err != nil {
+		rec.Count("InstagramTokenRefreshErrors")
+		return err
+	}
+	result, err := instagram.RefreshLongLivedToken(ctx, token)
+	if err != nil {
+		rec.Count("InstagramTokenRefreshErrors")
+		return err
+	}
+	if err := tokenSecret.Store(ctx, result.AccessToken); err != nil {
+		rec.Count("InstagramTokenRefreshErrors")
+		return err
+	}
+
+	expiresAt = now.Add(time.Duration(result.ExpiresIn) * time.Second)
+	if err := writeExpiry(ctx, expiresAt); err != nil {
+		// The token is saved; without the expiry the next run refreshes again.
+		log.Warn().Err(err).Str("param", expiryParam).Msg("Failed to record the token expiry")
+	}
+	rec.Count("InstagramTokenRefreshes").
+		Metric("InstagramTokenDaysRemaining", daysUntil(expiresAt, now), metrics.UnitNone)
+	log.Info().Time("expiresAt", expiresAt).Msg("Instagram token refreshed")
+	return nil
+}
+
+// readExpiry returns the recorded token expiry. known is false when none has
+// been recorded, as for a token connected before expiry tracking.
+func readExpiry(ctx context.Context) (expiresAt time.Time, known bool, err error) {
+	out, err := ssmClient.GetParameter(ctx, &ssm.GetParameterInput{Name: aws.String(expiryParam)})
+	var notFound *ssmtypes.ParameterNotFound
+	if errors.As(err, &notFound) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	expiresAt, err = time.Parse(time.RFC3339, aws.ToString(out.Parameter.Value))
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("parse %s: %w", expiryParam, err)
+	}
+	return expiresAt, true, nil
+}
+
+// writeExpiry records when the token expires.
+func writeExpiry(ctx context.Context, expiresAt time.Time) error {
+	_, err := ssmClient.PutParameter(ctx, &ssm.PutParameterInput{
+		Name:      aws.String(expiryParam),
+		Value:     aws.String(expiresAt.UTC().Format(time.RFC3339)),
+		Type:      ssmtypes.ParameterTypeString,
+		Overwrite: aws.Bool(true),
+	})
+	return err
+}
+
+// daysUntil returns the days from now to t, negative once t has passed.
+func daysUntil(t, now time.Time) float64 {
+	return t.Sub(now).Hours() / 24
+}
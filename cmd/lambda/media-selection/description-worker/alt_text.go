@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genai"
+
+	"github.com/fpang/ai-social-media-helper/internal/ai"
+)
+
+// completeAltText fills the photos the caption call left without alt-text
+// (DDR-154), one Gemini call per photo. altText is aligned with keys, or nil
+// when the caption call returned none usable. Videos keep an empty entry;
+// a photo whose call fails does too. Returns nil when no item has alt-text.
+func completeAltText(ctx context.Context, genaiClient *genai.Client, keys []string, items []ai.DescriptionMediaItem, altText []string, hint string) []string {
+	byFilename := make(map[string]ai.DescriptionMediaItem, len(items))
+	for _, item := range items {
+		byFilename[item.Filename] = item
+	}
+	if altText == nil {
+		altText = make([]string, len(keys))
+	}
+
+	client := ai.NewGeminiImageClient(genaiClient)
+	generated, described := 0, 0
+	for i, key := range keys {
+		item, ok := byFilename[filepath.Base(key)]
+		if altText[i] == "" && ok && item.Type == "Photo" && len(item.ThumbnailData) > 0 {
+			text, err := ai.GenerateAltText(ctx, client, item.ThumbnailData, item.ThumbnailMIMEType, hint)
+			if err != nil {
+				log.Warn().Err(err).Str("key", key).Msg("Alt-text generation failed, publishing the photo without it")
+			} else {
+				altText[i] = text
+				generated++
+			}
+		}
+		if altText[i] != "" {
+			described++
+		}
+	}
+	if generated > 0 {
+		log.Info().Int("generated", generated).Int("items", len(keys)).Msg("Alt-text completed photo by photo")
+	}
+	if described == 0 {
+		return nil
+	}
+	return altText
+}
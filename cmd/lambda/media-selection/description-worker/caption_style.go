@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// loadCaptionStyle returns the session owner's caption persona (DDR-159),
+// or nil when there is no owner, no style, or it cannot be read; captions
+// then keep the default voice.
+func loadCaptionStyle(ctx context.Context, owner string) *store.CaptionStyle {
+	if owner == "" {
+		return nil
+	}
+	style, err := sessionStore.GetCaptionStyle(ctx, owner)
+	if err != nil {
+		log.Warn().Err(err).Msg("Caption style not readable — using the default voice")
+		return nil
+	}
+	return style
+}
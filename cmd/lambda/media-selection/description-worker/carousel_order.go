@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// --- Carousel ordering (DDR-179) ---
+
+// handleCarouselOrder asks Gemini for the order of a post group's items,
+// cover first, and records the suggestion on the group. event.Keys are the
+// group's keys when the suggestion was requested; the suggestion lists the
+// same keys, reordered.
+func handleCarouselOrder(ctx context.Context, event DescriptionEvent) error {
+	start := time.Now()
+	suggestion, err := suggestCarouselOrder(ctx, event)
+	if err != nil {
+		log.Error().Err(err).Str("groupId", event.GroupID).Msg("Carousel order suggestion failed")
+		suggestion = &store.CarouselOrderSuggestion{Status: "error", Error: err.Error()}
+	}
+	suggestion.CreatedAt = time.Now().Unix()
+	if err := saveOrderSuggestion(ctx, event.SessionID, event.GroupID, suggestion); err != nil {
+		return err
+	}
+	log.Info().Str("groupId", event.GroupID).Str("status", suggestion.Status).Dur("duration", time.Since(start)).Msg("Carousel order suggestion complete")
+	return nil
+}
+
+func suggestCarouselOrder(ctx context.Context, event DescriptionEvent) (*store.CarouselOrderSuggestion, error) {
+	genaiClient, err := ai.NewAIClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize AI client")
+	}
+	// Gemini numbers the items by their thumbnails, so every key needs one.
+	mediaItems, err := buildDescriptionMediaItems(ctx, event.Keys)
+	if err != nil || len(mediaItems) != len(event.Keys) {
+		return nil, fmt.Errorf("failed to prepare media")
+	}
+	order, err := ai.SuggestCarouselOrder(ctx, genaiClient, event.GroupLabel, event.TripContext, mediaItems)
+	if err != nil {
+		return nil, fmt.Errorf("carousel ordering failed")
+	}
+	keys := make([]string, len(order.Order))
+	for i, idx := range order.Order {
+		keys[i] = event.Keys[idx]
+	}
+	return &store.CarouselOrderSuggestion{
+		Status:        "complete",
+		Keys:          keys,
+		OpeningReason: order.OpeningReason,
+		Rationale:     order.Rationale,
+	}, nil
+}
+
+// saveOrderSuggestion records the suggestion on the group as it is now. Only
+// the suggestion is written, so edits made while Gemini was working are
+// kept. A deleted group is skipped.
+func saveOrderSuggestion(ctx context.Context, sessionID, groupID string, suggestion *store.CarouselOrderSuggestion) error {
+	err := sessionStore.SetPostGroupOrderSuggestion(ctx, sessionID, groupID, suggestion)
+	if errors.Is(err, store.ErrPostGroupNotFound) {
+		log.Warn().Str("groupId", groupID).Msg("Post group deleted before its order suggestion finished")
+		return nil
+	}
+	return err
+}
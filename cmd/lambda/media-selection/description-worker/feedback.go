@@ -37,9 +37,13 @@ func handleDescriptionFeedback(ctx context.Context, event DescriptionEvent) erro
 		})
 	}
 
-	// Build history from current job state.
+	// Build history from the stored rounds (DDR-152).
+	stored, err := sessionStore.GetDescriptionHistory(ctx, event.SessionID, event.JobID)
+	if err != nil {
+		log.Warn().Err(err).Str("job", event.JobID).Msg("Failed to load description history, continuing with the current caption only")
+	}
 	var history []ai.DescriptionConversationEntry
-	for _, h := range job.History {
+	for _, h := range stored {
 		history = append(history, ai.DescriptionConversationEntry{
 			UserFeedback:  h.UserFeedback,
 			ModelResponse: h.ModelResponse,
@@ -50,9 +54,20 @@ func handleDescriptionFeedback(ctx context.Context, event DescriptionEvent) erro
 		ModelResponse: job.RawResponse,
 	})
 
+	owner, err := sessionStore.SessionOwner(ctx, event.SessionID)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to resolve session owner, using the default voice and hashtag defaults")
+	}
+
+	// DDR-170: feedback rounds keep the settings the job started with.
+	ctx, modelCfg := ai.ApplyModelConfig(ctx, ai.ModelConfig{
+		Model: job.Model, Thinking: job.Thinking, Temperature: job.Temperature, TopP: job.TopP,
+	})
+	// DDR-169: note which models answered, in case a fallback took over.
+	ctx, models := ai.WithModelRecorder(ctx)
 	result, rawResponse, err := ai.RegenerateDescription(
 		ctx, genaiClient, job.GroupLabel, job.TripContext, mediaItems,
-		event.Feedback, history,
+		event.Feedback, history, captionTemplate(job.CaptionSkeleton, job.TemplateHashtags, job.BrandHashtags, job.SignOff, loadCaptionStyle(ctx, owner)),
 	)
 	if err != nil {
 		return jobs.SetJobError(ctx, event.SessionID, event.JobID, "caption regeneration failed", func(ctx context.Context, sessionID, jobID, errMsg string) error {
@@ -61,23 +76,53 @@ func handleDescriptionFeedback(ctx context.Context, event DescriptionEvent) erro
 		})
 	}
 
-	// Persist updated history.
-	var storeHistory []store.ConversationEntry
-	for _, h := range history {
-		storeHistory = append(storeHistory, store.ConversationEntry{
-			UserFeedback:  h.UserFeedback,
-			ModelResponse: h.ModelResponse,
-		})
+	result.Hashtags = applyHashtagStrategy(ctx, owner, result.Hashtags)
+
+	// Persist this round as its own item; the job record only counts rounds.
+	round := job.Rounds() + 1
+	entry := store.ConversationEntry{UserFeedback: event.Feedback, ModelResponse: job.RawResponse}
+	if err := sessionStore.AppendDescriptionRound(ctx, event.SessionID, event.JobID, round, entry, rawResponseRounds); err != nil {
+		log.Warn().Err(err).Str("job", event.JobID).Int("round", round).Msg("Failed to persist description round")
+	}
+
+	// A regenerated caption that dropped the alt-text keeps the previous one.
+	altText := result.AltTextFor(len(job.MediaKeys))
+	if altText == nil && len(job.AltText) == len(job.MediaKeys) {
+		altText = job.AltText
 	}
+	altText = completeAltText(ctx, genaiClient, job.MediaKeys, mediaItems, altText, job.TripContext)
 
 	sessionStore.PutDescriptionJob(ctx, event.SessionID, &store.DescriptionJob{
 		ID: event.JobID, Status: "complete", GroupLabel: job.GroupLabel,
 		TripContext: job.TripContext, MediaKeys: job.MediaKeys,
+		CaptionSkeleton: job.CaptionSkeleton, TemplateHashtags: job.TemplateHashtags,
+		BrandHashtags: job.BrandHashtags, SignOff: job.SignOff,
 		Caption: result.Caption, Hashtags: result.Hashtags,
 		LocationTag: result.LocationTag, RawResponse: rawResponse,
-		History: storeHistory,
+		AltText: altText, ModelsUsed: models.Models(),
+		Model: modelCfg.Model, Thinking: modelCfg.Thinking,
+		Temperature: modelCfg.Temperature, TopP: modelCfg.TopP,
+		History: job.History, FeedbackRounds: round,
 	})
 
-	log.Info().Str("job", event.JobID).Int("round", len(storeHistory)).Dur("duration", time.Since(jobStart)).Msg("Description regeneration complete")
+	log.Info().Str("job", event.JobID).Int("round", round).Dur("duration", time.Since(jobStart)).Msg("Description regeneration complete")
 	return nil
 }
+
+// captionTemplate rebuilds a post template's caption format (DDR-122) and
+// the brand kit guidance (DDR-149), adding the owner's caption style
+// (DDR-159), or nil when there is none of them. The style's sign-off is used
+// only when the brand kit has none.
+func captionTemplate(skeleton string, hashtags, brandHashtags []string, signOff string, style *store.CaptionStyle) *ai.CaptionTemplate {
+	var voice *ai.CaptionStyle
+	if style != nil {
+		if signOff == "" {
+			signOff = style.SignOff
+		}
+		voice = &ai.CaptionStyle{EmojiDensity: style.EmojiDensity, Language: style.Language, Formality: style.Formality}
+	}
+	if skeleton == "" && len(hashtags) == 0 && len(brandHashtags) == 0 && signOff == "" && voice == nil {
+		return nil
+	}
+	return &ai.CaptionTemplate{Skeleton: skeleton, Hashtags: hashtags, HashtagBank: brandHashtags, SignOff: signOff, Style: voice}
+}
@@ -4,15 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"os"
-	"path/filepath"
-	"strings"
 	"time"
 
 	lambdasvc "github.com/aws/aws-sdk-go-v2/service/lambda"
 	"github.com/rs/zerolog/log"
 
 	"github.com/fpang/ai-social-media-helper/internal/ai"
-	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/decisions"
+	"github.com/fpang/ai-social-media-helper/internal/flags"
 	"github.com/fpang/ai-social-media-helper/internal/jobs"
 	"github.com/fpang/ai-social-media-helper/internal/rag"
 	"github.com/fpang/ai-social-media-helper/internal/store"
@@ -27,9 +26,15 @@ func resolveEconomyMode(eventEconomy bool) bool {
 
 func handleDescription(ctx context.Context, event DescriptionEvent) (interface{}, error) {
 	jobStart := time.Now()
+	// DDR-170: the job's model settings, kept on the job for feedback rounds.
+	ctx, modelCfg := ai.ApplyModelConfig(ctx, event.ModelConfig)
 	sessionStore.PutDescriptionJob(ctx, event.SessionID, &store.DescriptionJob{
 		ID: event.JobID, Status: "processing", GroupLabel: event.GroupLabel,
 		TripContext: event.TripContext, MediaKeys: event.Keys,
+		CaptionSkeleton: event.CaptionSkeleton, TemplateHashtags: event.TemplateHashtags,
+		BrandHashtags: event.BrandHashtags, SignOff: event.SignOff,
+		Model: modelCfg.Model, Thinking: modelCfg.Thinking,
+		Temperature: modelCfg.Temperature, TopP: modelCfg.TopP,
 	})
 
 	genaiClient, err := ai.NewAIClient(ctx)
@@ -48,14 +53,21 @@ func handleDescription(ctx context.Context, event DescriptionEvent) (interface{}
 		})
 	}
 
+	// Decisions and RAG profiles are per user (DDR-105).
+	owner, ownerErr := sessionStore.SessionOwner(ctx, event.SessionID)
+	if ownerErr != nil {
+		log.Warn().Err(ownerErr).Msg("Failed to resolve session owner, recording decisions without a user")
+	}
+	ragUserID := rag.UserID(owner, event.SessionID)
+
 	// RAG retrieval — best effort
 	ragContext := ""
-	if ragQueryArn != "" {
+	if ragQueryArn != "" && featureFlags.Enabled(ctx, flags.RAGContext) {
 		sessionContext := event.GroupLabel
 		if event.TripContext != "" {
 			sessionContext = event.TripContext + "\n" + event.GroupLabel
 		}
-		ragCtx, ragErr := invokeRAGQuery(ctx, "caption", event.SessionID, sessionContext)
+		ragCtx, ragErr := invokeRAGQuery(ctx, "caption", ragUserID, sessionContext)
 		if ragErr != nil {
 			log.Warn().Err(ragErr).Msg("RAG query failed, proceeding without context")
 		} else {
@@ -67,10 +79,18 @@ func handleDescription(ctx context.Context, event DescriptionEvent) (interface{}
 	cacheMgr := ai.NewCacheManager(genaiClient)
 	defer cacheMgr.DeleteAll(ctx, event.SessionID)
 
+	// DDR-166: reuse a caption generated for the same media and prompt.
+	if !event.NoCache {
+		ctx = ai.WithResponseCache(ctx, sessionStore)
+	}
+	// DDR-169: note which models answered, in case a fallback took over.
+	ctx, models := ai.WithModelRecorder(ctx)
+
 	economyMode := resolveEconomyMode(event.EconomyMode)
 	output, err := ai.GenerateDescription(
 		ctx, genaiClient, event.GroupLabel, event.TripContext, mediaItems,
-		cacheMgr, event.SessionID, ragContext, economyMode,
+		cacheMgr, event.SessionID, ragContext,
+		captionTemplate(event.CaptionSkeleton, event.TemplateHashtags, event.BrandHashtags, event.SignOff, loadCaptionStyle(ctx, owner)), economyMode,
 	)
 	if err != nil {
 		return nil, jobs.SetJobError(ctx, event.SessionID, event.JobID, "caption generation failed", func(ctx context.Context, sessionID, jobID, errMsg string) error {
@@ -87,46 +107,36 @@ func handleDescription(ctx context.Context, event DescriptionEvent) (interface{}
 
 	result := output.Result
 	rawResponse := output.RawResponse
+	result.Hashtags = applyHashtagStrategy(ctx, owner, result.Hashtags)
+	altText := completeAltText(ctx, genaiClient, event.Keys, mediaItems, result.AltTextFor(len(event.Keys)), event.TripContext)
 
 	sessionStore.PutDescriptionJob(ctx, event.SessionID, &store.DescriptionJob{
 		ID: event.JobID, Status: "complete", GroupLabel: event.GroupLabel,
 		TripContext: event.TripContext, MediaKeys: event.Keys,
+		CaptionSkeleton: event.CaptionSkeleton, TemplateHashtags: event.TemplateHashtags,
+		BrandHashtags: event.BrandHashtags, SignOff: event.SignOff,
 		Caption: result.Caption, Hashtags: result.Hashtags,
 		LocationTag: result.LocationTag, RawResponse: rawResponse,
+		AltText: altText, ModelsUsed: models.Models(),
+		Model: modelCfg.Model, Thinking: modelCfg.Thinking,
+		Temperature: modelCfg.Temperature, TopP: modelCfg.TopP,
 	})
 
-	// Emit description decisions to EventBridge — best effort
-	if ebClient != nil && len(event.Keys) > 0 {
-		metadata := map[string]string{
-			"caption":      result.Caption,
-			"locationTag": result.LocationTag,
+	// Record the caption for RAG (DDR-107) — best effort.
+	if len(event.Keys) > 0 {
+		groupName := event.GroupLabel
+		if groupName == "" {
+			groupName = event.JobID
 		}
-		if len(result.Hashtags) > 0 {
-			metadata["hashtags"] = strings.Join(result.Hashtags, ",")
-		}
-		batcher := rag.NewBatchEmitter(ebClient)
-		for _, key := range event.Keys {
-			mediaType := "Photo"
-			if ext := strings.ToLower(filepath.Ext(key)); media.IsVideo(ext) {
-				mediaType = "Video"
-			}
-			batcher.Add(rag.ContentFeedback{
-				EventType:   rag.EventDescriptionFinalized,
-				SessionID:   event.SessionID,
-				JobID:       event.JobID,
-				Timestamp:   time.Now().UTC().Format(time.RFC3339),
-				UserID:      event.SessionID,
-				MediaKey:    key,
-				MediaType:   mediaType,
-				AIVerdict:   "captioned",
-				UserVerdict: "captioned",
-				Reason:      result.Caption,
-				Model:       "gemini",
-				Metadata:    metadata,
-			})
-		}
-		if err := batcher.Flush(ctx); err != nil {
-			log.Warn().Err(err).Msg("failed to flush description feedback batch")
+		dw := decisions.NewWriter(decisions.EventBridge(ebClient), decisions.Job{
+			SessionID: event.SessionID, JobID: event.JobID, UserID: ragUserID, Model: "gemini",
+		})
+		dw.RecordCaption(decisions.Caption{
+			GroupName: groupName, Text: result.Caption, Hashtags: result.Hashtags,
+			LocationTag: result.LocationTag, MediaKeys: event.Keys,
+		})
+		if err := dw.Flush(ctx); err != nil {
+			log.Warn().Err(err).Msg("failed to flush caption decision")
 		}
 	}
 
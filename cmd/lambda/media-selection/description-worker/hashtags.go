@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/hashtags"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// applyHashtagStrategy runs a generated caption's hashtags through the
+// session owner's hashtag strategy (DDR-157) before the job is stored.
+// Without an owner, or when the strategy cannot be read, the tags are only
+// deduplicated and capped at the platform limit.
+func applyHashtagStrategy(ctx context.Context, owner string, tags []string) []string {
+	var settings *store.HashtagSettings
+	if owner != "" {
+		s, err := sessionStore.GetHashtagSettings(ctx, owner)
+		if err != nil {
+			log.Warn().Err(err).Msg("Hashtag settings not readable — applying platform defaults")
+		} else {
+			settings = s
+		}
+	}
+	out := hashtags.Apply(tags, settings)
+	log.Debug().Int("generated", len(tags)).Int("kept", len(out)).Bool("strategy", settings != nil).Msg("Hashtag strategy applied")
+	return out
+}
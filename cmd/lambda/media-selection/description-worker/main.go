@@ -3,8 +3,10 @@
 // This Lambda handles AI-powered Instagram caption generation:
 //   - description: Generate a caption from media thumbnails
 //   - description-feedback: Regenerate a caption with user feedback
+//   - carousel-order: Suggest the order of a post group's items (DDR-179)
 //
-// Invoked asynchronously by the API Lambda via lambda:Invoke (Event type).
+// Invoked asynchronously by the API Lambda via its SQS job queue (DDR-092),
+// or via lambda:Invoke (Event type) when no queue is configured.
 //
 // Container: Light (Dockerfile.light — no ffmpeg needed)
 // Memory: 2 GB
@@ -15,9 +17,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
 	lambdasvc "github.com/aws/aws-sdk-go-v2/service/lambda"
@@ -27,7 +29,11 @@ import (
 
 	"github.com/fpang/ai-social-media-helper/internal/ai"
 	"github.com/fpang/ai-social-media-helper/internal/bootstrap"
+	"github.com/fpang/ai-social-media-helper/internal/flags"
+	"github.com/fpang/ai-social-media-helper/internal/geo"
+	"github.com/fpang/ai-social-media-helper/internal/jobs"
 	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 )
 
@@ -40,6 +46,10 @@ var (
 	ebClient     *eventbridge.Client
 	lambdaClient *lambdasvc.Client
 	ragQueryArn  string
+	featureFlags *flags.Set   // DDR-132
+	geocoder     geo.Geocoder // DDR-137: nil disables place names
+
+	rawResponseRounds int // DDR-152: feedback rounds that keep their raw response
 )
 
 func init() {
@@ -55,8 +65,14 @@ func init() {
 	bootstrap.LoadGCPServiceAccountKey(awsClients.SSM)
 	_ = ai.LoadGCPServiceAccount()
 
+	featureFlags = bootstrap.InitFlags(awsClients.SSM)
 	ebClient = eventbridge.NewFromConfig(awsClients.Config)
 	lambdaClient = lambdasvc.NewFromConfig(awsClients.Config)
+	if os.Getenv("GEOCODER") != "off" {
+		geocoder = geo.NewNominatim(os.Getenv("NOMINATIM_URL"),
+			logging.EnvOrDefault("GEOCODER_USER_AGENT", "ai-social-media-helper (github.com/fpang/ai-social-media-helper)"))
+	}
+	rawResponseRounds = store.RawResponseRoundsFromEnv()
 	ragQueryArn = os.Getenv("RAG_QUERY_LAMBDA_ARN")
 	if ragQueryArn == "" {
 		paramPath := os.Getenv("RAG_QUERY_LAMBDA_ARN_PARAM")
@@ -76,11 +92,14 @@ func init() {
 		S3Bucket("mediaBucket", mediaBucket).
 		DynamoTable("sessions", os.Getenv("DYNAMO_TABLE_NAME")).
 		SSMParam("geminiApiKey", logging.EnvOrDefault("SSM_API_KEY_PARAM", "/ai-social-media/prod/gemini-api-key")).
+		SSMParam("featureFlags", logging.EnvOrDefault("SSM_FEATURE_FLAGS_PARAM", flags.DefaultSSMParam)).
+		Feature("geocoder", geocoder != nil).
+		Config("rawResponseRounds", strconv.Itoa(rawResponseRounds)).
 		Log()
 }
 
 func main() {
-	lambda.Start(handler)
+	bootstrap.Start(jobs.WithQueue(handler)) // direct invoke or SQS job queue (DDR-092)
 }
 
 func handler(ctx context.Context, event DescriptionEvent) (interface{}, error) {
@@ -94,11 +113,16 @@ func handler(ctx context.Context, event DescriptionEvent) (interface{}, error) {
 		Str("jobId", event.JobID).
 		Msg("Description Lambda invoked")
 
+	// DDR-118: collect per-job telemetry; PutDescriptionJob records it.
+	ctx, _ = metrics.WithCollector(ctx)
+
 	switch event.Type {
 	case "description":
 		return handleDescription(ctx, event)
 	case "description-feedback":
 		return nil, handleDescriptionFeedback(ctx, event)
+	case "carousel-order":
+		return nil, handleCarouselOrder(ctx, event)
 	default:
 		return nil, fmt.Errorf("unknown event type: %s", event.Type)
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/geo"
 	"github.com/fpang/ai-social-media-helper/internal/media"
 	"github.com/fpang/ai-social-media-helper/internal/s3util"
 )
@@ -18,6 +19,15 @@ func buildDescriptionMediaItems(ctx context.Context, keys []string) ([]ai.Descri
 	log.Debug().Int("keyCount", len(keys)).Msg("Building description media items")
 	var items []ai.DescriptionMediaItem
 
+	// Photos of one group usually share a few spots; the resolver looks each
+	// up once, within one time budget for the group (DDR-137).
+	var resolver *geo.Resolver
+	if geocoder != nil {
+		resolver = geo.NewResolver(geocoder, sessionStore)
+	}
+	placeCtx, cancel := context.WithTimeout(ctx, placeLookupBudget)
+	defer cancel()
+
 	for _, key := range keys {
 		filename := filepath.Base(key)
 		ext := strings.ToLower(filepath.Ext(key))
@@ -66,6 +76,7 @@ func buildDescriptionMediaItems(ctx context.Context, keys []string) ([]ai.Descri
 				item.ThumbnailData = thumbData
 				item.ThumbnailMIMEType = "image/jpeg"
 			}
+			locatePhoto(ctx, placeCtx, resolver, key, &item)
 		} else if media.IsVideo(ext) {
 			item.Type = "Video"
 			parts := strings.SplitN(key, "/", 2)
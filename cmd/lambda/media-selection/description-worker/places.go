@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/flags"
+	"github.com/fpang/ai-social-media-helper/internal/geo"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
+)
+
+// --- Place names (DDR-137) ---
+
+// exifProbeBytes is how much of an original photo is read to find its EXIF
+// GPS block. JPEG, HEIC and TIFF-based RAW files keep it near the start.
+const exifProbeBytes = 256 << 10
+
+// placeLookupBudget bounds the time one job spends reverse-geocoding, since
+// Nominatim serves one request per second. Items not resolved in time keep
+// their raw coordinates.
+const placeLookupBudget = 30 * time.Second
+
+// locatePhoto fills in a photo's GPS coordinates from its original and, when
+// reverse geocoding is on, the place name for them. placeCtx carries the
+// job's lookup budget. Failures leave the item without a location; they
+// never skip it.
+func locatePhoto(ctx, placeCtx context.Context, resolver *geo.Resolver, key string, item *ai.DescriptionMediaItem) {
+	lat, lon, ok := originalGPS(ctx, key)
+	if !ok {
+		return
+	}
+	item.GPSLat, item.GPSLon, item.HasGPS = lat, lon, true
+
+	if resolver == nil || !featureFlags.Enabled(ctx, flags.ReverseGeocode) {
+		return
+	}
+	place, err := resolver.Resolve(placeCtx, lat, lon)
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("Reverse geocoding failed, using raw coordinates")
+		return
+	}
+	if place != nil {
+		item.Place = place.Label()
+	}
+}
+
+// originalGPS reads the EXIF GPS coordinates of the photo behind key. Keys of
+// enhanced copies ({sessionId}/enhanced/{name}) are mapped back to the
+// original, since enhancement re-encodes without EXIF. The original's
+// extension is looked up, as a RAW original's enhanced copy is .jpg (DDR-119).
+func originalGPS(ctx context.Context, key string) (lat, lon float64, ok bool) {
+	original, err := s3util.ResolveOriginalKey(ctx, s3Client, mediaBucket, key)
+	if err != nil {
+		log.Debug().Err(err).Str("key", key).Msg("Original not found for GPS")
+		return 0, 0, false
+	}
+	key = original
+
+	rng := fmt.Sprintf("bytes=0-%d", exifProbeBytes-1)
+	out, err := s3Client.GetObject(ctx, &s3.GetObjectInput{Bucket: &mediaBucket, Key: &key, Range: &rng})
+	if err != nil {
+		log.Debug().Err(err).Str("key", key).Msg("Original not readable for GPS")
+		return 0, 0, false
+	}
+	defer out.Body.Close()
+
+	tmp, err := os.CreateTemp("", "exif-*"+filepath.Ext(key))
+	if err != nil {
+		return 0, 0, false
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.Copy(tmp, out.Body)
+	tmp.Close()
+	if err != nil {
+		return 0, 0, false
+	}
+
+	meta, err := media.ExtractImageMetadata(tmp.Name())
+	if err != nil || !meta.HasGPSData() {
+		return 0, 0, false
+	}
+	lat, lon = meta.GetGPS()
+	return lat, lon, true
+}
@@ -1,5 +1,10 @@
 package main
 
+import (
+	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/jobs"
+)
+
 // DescriptionEvent is the input from the API Lambda.
 type DescriptionEvent struct {
 	Type        string   `json:"type"`
@@ -10,6 +15,22 @@ type DescriptionEvent struct {
 	GroupLabel  string   `json:"groupLabel,omitempty"`
 	TripContext string   `json:"tripContext,omitempty"`
 	Feedback    string   `json:"feedback,omitempty"`
+	NoCache     bool     `json:"noCache,omitempty"` // DDR-166: skip the Gemini response cache
+
+	// Caption format from a post template (DDR-122).
+	CaptionSkeleton  string   `json:"captionSkeleton,omitempty"`
+	TemplateHashtags []string `json:"templateHashtags,omitempty"`
+
+	// Brand kit guidance (DDR-149).
+	BrandHashtags []string `json:"brandHashtags,omitempty"`
+	SignOff       string   `json:"signOff,omitempty"`
+
+	// Post group to suggest a carousel order for (DDR-179).
+	GroupID string `json:"groupId,omitempty"`
+
+	Priority jobs.Priority `json:"priority,omitempty"` // DDR-096
+
+	ai.ModelConfig // DDR-170: model, thinking, temperature, topP
 }
 
 // DescriptionRunResult is returned when economy_mode is true.
@@ -6,7 +6,8 @@
 //
 // This is the leanest Lambda: no Gemini API, no Instagram, no chat package.
 //
-// Invoked asynchronously by the API Lambda via lambda:Invoke (Event type).
+// Invoked asynchronously by the API Lambda via its SQS job queue (DDR-092),
+// or via lambda:Invoke (Event type) when no queue is configured.
 //
 // Container: Light (Dockerfile.light — no ffmpeg needed)
 // Memory: 2 GB
@@ -15,7 +16,11 @@ package main
 
 import (
 	"archive/zip"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
@@ -24,14 +29,16 @@ import (
 	"strings"
 	"time"
 
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/klauspost/compress/zstd"
 	"github.com/rs/zerolog/log"
 
 	"github.com/fpang/ai-social-media-helper/internal/bootstrap"
+	"github.com/fpang/ai-social-media-helper/internal/jobs"
 	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
 	"github.com/fpang/ai-social-media-helper/internal/s3util"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 )
@@ -42,7 +49,9 @@ var (
 	s3Client     *s3.Client
 	presigner    *s3.PresignClient
 	mediaBucket  string
+	sessionKeys  *s3util.SessionKeys // DDR-164
 	sessionStore *store.DynamoStore
+	tiering      s3util.TieringPolicy
 )
 
 // zipMethodZstd is the ZIP compression method ID for Zstandard.
@@ -51,6 +60,10 @@ const zipMethodZstd uint16 = 93
 // maxVideoZipBytes is the maximum size of a single video ZIP bundle (375 MB).
 const maxVideoZipBytes int64 = 375 * 1024 * 1024
 
+// maxSinglePutBytes is the S3 PutObject size limit (5 GB). Bundles are always
+// uploaded in one part (DDR-099); only a single oversized video can get close.
+const maxSinglePutBytes int64 = 5 * 1024 * 1024 * 1024
+
 func init() {
 	initStart := time.Now()
 	logging.Init()
@@ -60,7 +73,9 @@ func init() {
 	s3Client = s3s.Client
 	presigner = s3s.Presigner
 	mediaBucket = s3s.Bucket
+	sessionKeys = s3s.Keys
 	sessionStore = bootstrap.InitDynamo(awsClients.Config, "DYNAMO_TABLE_NAME")
+	tiering = s3util.TieringPolicyFromEnv()
 
 	// Register Zstandard compressor for ZIP bundles (DDR-034).
 	zip.RegisterCompressor(zipMethodZstd, func(w io.Writer) (io.WriteCloser, error) {
@@ -70,11 +85,15 @@ func init() {
 	bootstrap.StartupLog("download-lambda", initStart).
 		S3Bucket("mediaBucket", mediaBucket).
 		DynamoTable("sessions", os.Getenv("DYNAMO_TABLE_NAME")).
+		Feature("storageTiering", tiering.Enabled()).
 		Log()
 }
 
 func main() {
-	lambda.Start(handler)
+	// Direct invoke or SQS job queue (DDR-092).
+	bootstrap.Start(jobs.WithQueue(func(ctx context.Context, event DownloadEvent) (interface{}, error) {
+		return nil, handler(ctx, event)
+	}))
 }
 
 // DownloadEvent is the input from the API Lambda.
@@ -84,6 +103,16 @@ type DownloadEvent struct {
 	JobID      string   `json:"jobId"`
 	Keys       []string `json:"keys"`
 	GroupLabel string   `json:"groupLabel,omitempty"`
+	RetryOf    string   `json:"retryOf,omitempty"` // DDR-097: job whose omitted files this job retries
+
+	// DDR-101: carried through so every job write keeps the reuse fingerprint.
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Prewarm     bool   `json:"prewarm,omitempty"`
+
+	// DDR-135: metadata policy for the bundled photos; empty means the default.
+	MetadataPolicy string `json:"metadataPolicy,omitempty"`
+
+	Priority jobs.Priority `json:"priority,omitempty"` // DDR-096
 }
 
 func handler(ctx context.Context, event DownloadEvent) error {
@@ -97,40 +126,72 @@ func handler(ctx context.Context, event DownloadEvent) error {
 		Int("keyCount", len(event.Keys)).
 		Msg("Download Lambda invoked")
 
+	// DDR-118: collect per-job telemetry; PutDownloadJob records it.
+	ctx, _ = metrics.WithCollector(ctx)
 	return handleDownload(ctx, event)
 }
 
 func handleDownload(ctx context.Context, event DownloadEvent) error {
 	jobStart := time.Now()
-	sessionStore.PutDownloadJob(ctx, event.SessionID, &store.DownloadJob{
-		ID: event.JobID, Status: "processing",
-	})
+	sessionStore.PutDownloadJob(ctx, event.SessionID, newDownloadJob(event, "processing"))
 
-	// Step 1: Query file sizes and separate images from videos.
-	var images, videos []dlFile
+	// Step 1: Query file sizes and separate images, RAW originals and videos.
+	// A file whose HeadObject fails is still planned (with unknown size) so
+	// that it is reported as omitted from its bundle rather than silently
+	// dropped (DDR-097). Archived originals are restored first (DDR-115).
+	var images, raws, videos []dlFile
+	var restoring []string
+	headFailures := 0
 
 	for _, key := range event.Keys {
+		var size int64
 		headResult, err := s3Client.HeadObject(ctx, &s3.HeadObjectInput{
 			Bucket: &mediaBucket, Key: &key,
 		})
 		if err != nil {
-			log.Warn().Err(err).Str("key", key).Msg("HeadObject failed, skipping")
-			continue
+			log.Warn().Err(err).Str("key", key).Msg("HeadObject failed, size unknown")
+			headFailures++
+		} else {
+			size = *headResult.ContentLength
+			if s3util.NeedsRestore(headResult) {
+				if err := s3util.RestoreObject(ctx, s3Client, mediaBucket, key, tiering); err != nil {
+					log.Warn().Err(err).Str("key", key).Msg("Failed to start restore of archived file, it will be omitted")
+				} else {
+					restoring = append(restoring, key)
+				}
+			}
 		}
-		size := *headResult.ContentLength
 		ext := strings.ToLower(filepath.Ext(key))
 		if isVideoExt(ext) {
 			videos = append(videos, dlFile{key: key, size: size})
+		} else if isRawExt(ext) {
+			raws = append(raws, dlFile{key: key, size: size})
 		} else {
 			images = append(images, dlFile{key: key, size: size})
 		}
 	}
 
-	if len(images) == 0 && len(videos) == 0 {
+	if headFailures == len(event.Keys) {
 		return setDownloadError(ctx, event, "No downloadable files found")
 	}
 
-	log.Debug().Int("images", len(images)).Int("videos", len(videos)).Str("jobId", event.JobID).Msg("Bundle planning")
+	// DDR-115: park the job until the restores finish. The API re-dispatches
+	// it from the results endpoint once every key is readable again.
+	if len(restoring) > 0 {
+		job := newDownloadJob(event, "restoring")
+		job.Keys = event.Keys
+		job.RestoringKeys = restoring
+		job.RestoreCheckedAt = time.Now().Unix()
+		// Without the record the API never re-dispatches the job, so fail
+		// the invocation and let Lambda retry it.
+		if err := sessionStore.PutDownloadJob(ctx, event.SessionID, job); err != nil {
+			return fmt.Errorf("record restoring download job: %w", err)
+		}
+		log.Info().Str("job", event.JobID).Int("restoring", len(restoring)).Str("restoreTier", string(tiering.RestoreTier)).Msg("Download waiting for archived files to be restored")
+		return nil
+	}
+
+	log.Debug().Int("images", len(images)).Int("raws", len(raws)).Int("videos", len(videos)).Str("jobId", event.JobID).Msg("Bundle planning")
 
 	// Step 2: Plan bundles.
 	var bundles []store.DownloadBundle
@@ -146,6 +207,20 @@ func handleDownload(ctx context.Context, event DownloadEvent) error {
 		})
 	}
 
+	// RAW originals (DDR-119) are several times larger than JPEGs, so they
+	// get their own size-split bundles instead of swelling the images ZIP.
+	rawGroups := dlGroupBySize(raws, maxVideoZipBytes)
+	for i, group := range rawGroups {
+		var totalSize int64
+		for _, r := range group {
+			totalSize += r.size
+		}
+		bundles = append(bundles, store.DownloadBundle{
+			Type: "raw", Name: sanitizeZipName(event.GroupLabel, "raw", i+1),
+			FileCount: len(group), TotalSize: totalSize, Status: "pending",
+		})
+	}
+
 	if len(videos) > 0 {
 		videoGroups := dlGroupBySize(videos, maxVideoZipBytes)
 		for i, group := range videoGroups {
@@ -161,33 +236,43 @@ func handleDownload(ctx context.Context, event DownloadEvent) error {
 	}
 
 	// Step 3: Create each ZIP bundle.
-	videoGroupIdx := 0
+	policy, err := media.ParseMetadataPolicy(event.MetadataPolicy)
+	if err != nil {
+		log.Warn().Err(err).Msg("Invalid metadata policy, using the default")
+		policy = media.DefaultMetadataPolicy
+	}
+	rawGroupIdx, videoGroupIdx := 0, 0
 	videoGroups := dlGroupBySize(videos, maxVideoZipBytes)
 
 	for i := range bundles {
 		bundles[i].Status = "processing"
 
 		var filesToZip []dlFile
-		if bundles[i].Type == "images" {
+		switch bundles[i].Type {
+		case "images":
 			filesToZip = images
-		} else {
+		case "raw":
+			filesToZip = rawGroups[rawGroupIdx]
+			rawGroupIdx++
+		default:
 			filesToZip = videoGroups[videoGroupIdx]
 			videoGroupIdx++
 		}
 
 		zipKey := fmt.Sprintf("%s/downloads/%s/%s", event.SessionID, event.JobID, bundles[i].Name)
-		zipSize, err := dlCreateZip(ctx, filesToZip, zipKey)
+		zipped, err := dlCreateZip(ctx, filesToZip, zipKey, policy)
+		bundles[i].OmittedFiles = zipped.omitted
+		bundles[i].FileCount = len(filesToZip) - len(zipped.omitted)
 		if err != nil {
 			bundles[i].Status = "error"
 			bundles[i].Error = err.Error()
 			continue
 		}
+		if len(zipped.omitted) > 0 {
+			log.Warn().Str("job", event.JobID).Str("bundle", bundles[i].Name).Strs("omitted", zipped.omitted).Msg("Bundle created with omitted files")
+		}
 
-		downloadResult, err := presigner.PresignGetObject(ctx, &s3.GetObjectInput{
-			Bucket:                     &mediaBucket,
-			Key:                        &zipKey,
-			ResponseContentDisposition: aws.String(fmt.Sprintf(`attachment; filename="%s"`, bundles[i].Name)),
-		}, s3.WithPresignExpires(1*time.Hour))
+		downloadURL, err := s3util.PresignBundleURL(ctx, presigner, mediaBucket, zipKey, bundles[i].Name)
 		if err != nil {
 			bundles[i].Status = "error"
 			bundles[i].Error = "failed to generate download URL"
@@ -195,14 +280,16 @@ func handleDownload(ctx context.Context, event DownloadEvent) error {
 		}
 
 		bundles[i].ZipKey = zipKey
-		bundles[i].ZipSize = zipSize
-		bundles[i].DownloadURL = downloadResult.URL
+		bundles[i].ZipSize = zipped.size
+		bundles[i].SHA256 = zipped.sha256
+		bundles[i].ETag = zipped.etag
+		bundles[i].DownloadURL = downloadURL
 		bundles[i].Status = "complete"
 	}
 
-	sessionStore.PutDownloadJob(ctx, event.SessionID, &store.DownloadJob{
-		ID: event.JobID, Status: "complete", Bundles: bundles,
-	})
+	job := newDownloadJob(event, "complete")
+	job.Bundles = bundles
+	sessionStore.PutDownloadJob(ctx, event.SessionID, job)
 
 	log.Info().Str("job", event.JobID).Int("bundles", len(bundles)).Dur("duration", time.Since(jobStart)).Msg("Download job complete")
 	return nil
@@ -210,14 +297,28 @@ func handleDownload(ctx context.Context, event DownloadEvent) error {
 
 func setDownloadError(ctx context.Context, event DownloadEvent, msg string) error {
 	log.Error().Str("job", event.JobID).Str("error", msg).Msg("Download job failed")
-	sessionStore.PutDownloadJob(ctx, event.SessionID, &store.DownloadJob{
-		ID: event.JobID, Status: "error", Error: msg,
-	})
+	job := newDownloadJob(event, "error")
+	job.Error = msg
+	sessionStore.PutDownloadJob(ctx, event.SessionID, job)
 	return nil
 }
 
+// newDownloadJob builds the job record for event. PutDownloadJob replaces the
+// whole item, so every write carries the fields set at dispatch time.
+func newDownloadJob(event DownloadEvent, status string) *store.DownloadJob {
+	return &store.DownloadJob{
+		ID:          event.JobID,
+		Status:      status,
+		GroupLabel:  event.GroupLabel,
+		RetryOf:     event.RetryOf,
+		Fingerprint: event.Fingerprint,
+		Prewarm:     event.Prewarm,
+
+		MetadataPolicy: event.MetadataPolicy,
+	}
+}
+
 // isVideoExt checks if a file extension is a video format.
-// Inlined to avoid importing filehandler (which pulls in genai via LoadMediaFile).
 func isVideoExt(ext string) bool {
 	switch ext {
 	case ".mp4", ".mov", ".avi", ".webm", ".mkv", ".m4v", ".3gp":
@@ -226,6 +327,16 @@ func isVideoExt(ext string) bool {
 	return false
 }
 
+// isRawExt checks if a file extension is a RAW camera format (DDR-119).
+// Mirrors media.SupportedRawExtensions.
+func isRawExt(ext string) bool {
+	switch ext {
+	case ".dng", ".cr2", ".cr3", ".nef", ".arw", ".raf", ".orf", ".rw2", ".srw":
+		return true
+	}
+	return false
+}
+
 // --- ZIP Helpers ---
 
 type dlFile struct {
@@ -270,15 +381,42 @@ func dlGroupBySize(files []dlFile, maxBytes int64) [][]dlFile {
 	return groups
 }
 
-func dlCreateZip(ctx context.Context, files []dlFile, zipKey string) (int64, error) {
+// dlZip describes an uploaded ZIP bundle.
+type dlZip struct {
+	size    int64
+	sha256  string // hex digest of the whole object (DDR-099)
+	etag    string // S3 ETag, used by clients as the If-Range validator
+	omitted []string
+}
+
+// dlCreateZip writes files into a ZIP and uploads it to zipKey. Files that
+// cannot be read from S3 are left out and returned as omitted (DDR-097); a
+// failure after a file's data has started streaming fails the whole bundle,
+// since the ZIP is corrupt at that point. If every file is omitted, no ZIP is
+// uploaded and an error is returned.
+//
+// The ZIP is uploaded with a single PutObject, never multipart, so the object
+// has a plain SHA-256 checksum and a stable ETag that download managers can
+// use to resume a ranged GET (DDR-099).
+//
+// Photos pass through media.SanitizeMetadata under policy (DDR-135). One whose
+// metadata cannot be parsed fails the bundle, naming the photo, rather than
+// being shipped with its location or dropped as if it could not be read.
+// Videos are copied unchanged.
+func dlCreateZip(ctx context.Context, files []dlFile, zipKey string, policy media.MetadataPolicy) (dlZip, error) {
+	var out dlZip
+
 	tmpFile, err := os.CreateTemp("", "download-*.zip")
 	if err != nil {
-		return 0, fmt.Errorf("create temp ZIP: %w", err)
+		return out, fmt.Errorf("create temp ZIP: %w", err)
 	}
 	tmpPath := tmpFile.Name()
 	defer os.Remove(tmpPath)
 
-	zipWriter := zip.NewWriter(tmpFile)
+	// zip.Writer writes sequentially, so the digest can be computed as the
+	// archive is written instead of re-reading it.
+	hasher := sha256.New()
+	zipWriter := zip.NewWriter(io.MultiWriter(tmpFile, hasher))
 
 	for _, file := range files {
 		filename := filepath.Base(file.key)
@@ -286,10 +424,29 @@ func dlCreateZip(ctx context.Context, files []dlFile, zipKey string) (int64, err
 			Bucket: &mediaBucket, Key: &file.key,
 		})
 		if err != nil {
-			log.Warn().Err(err).Str("key", file.key).Msg("Failed to download for ZIP, skipping")
+			log.Warn().Err(err).Str("key", file.key).Msg("Failed to download for ZIP, omitting")
+			out.omitted = append(out.omitted, file.key)
 			continue
 		}
 
+		var body io.Reader = getResult.Body
+		sanitized := false
+		if policy != media.MetadataPreserve && !isVideoExt(strings.ToLower(filepath.Ext(file.key))) {
+			data, err := io.ReadAll(getResult.Body)
+			getResult.Body.Close()
+			if err == nil {
+				metrics.RecordS3Download(ctx, int64(len(data)))
+				data, err = media.SanitizeMetadata(data, policy)
+			}
+			if err != nil {
+				// Shipping the photo would leak the metadata the policy
+				// removes, and leaving it out would look like a read failure.
+				log.Error().Err(err).Str("key", file.key).Str("policy", string(policy)).Msg("Failed to sanitize metadata, failing the bundle")
+				return out, fmt.Errorf("cannot apply the %s metadata policy to %s: %w", policy, filename, err)
+			}
+			body, sanitized = bytes.NewReader(data), true
+		}
+
 		header := &zip.FileHeader{
 			Name:   filename,
 			Method: zipMethodZstd,
@@ -299,44 +456,65 @@ func dlCreateZip(ctx context.Context, files []dlFile, zipKey string) (int64, err
 		writer, err := zipWriter.CreateHeader(header)
 		if err != nil {
 			getResult.Body.Close()
-			return 0, fmt.Errorf("create ZIP entry for %s: %w", filename, err)
-		}
-		if _, err := io.Copy(writer, getResult.Body); err != nil {
-			getResult.Body.Close()
-			return 0, fmt.Errorf("write to ZIP for %s: %w", filename, err)
+			return out, fmt.Errorf("create ZIP entry for %s: %w", filename, err)
 		}
+		n, err := io.Copy(writer, body)
 		getResult.Body.Close()
+		if err != nil {
+			return out, fmt.Errorf("write to ZIP for %s: %w", filename, err)
+		}
+		if !sanitized {
+			metrics.RecordS3Download(ctx, n)
+		}
+	}
+
+	if len(out.omitted) == len(files) {
+		zipWriter.Close()
+		tmpFile.Close()
+		return out, fmt.Errorf("none of the %d files could be read", len(files))
 	}
 
 	if err := zipWriter.Close(); err != nil {
 		tmpFile.Close()
-		return 0, fmt.Errorf("close ZIP writer: %w", err)
+		return out, fmt.Errorf("close ZIP writer: %w", err)
 	}
 	tmpFile.Close()
 
 	info, err := os.Stat(tmpPath)
 	if err != nil {
-		return 0, fmt.Errorf("stat ZIP file: %w", err)
+		return out, fmt.Errorf("stat ZIP file: %w", err)
+	}
+	if info.Size() > maxSinglePutBytes {
+		return out, fmt.Errorf("ZIP is %d bytes, over the %d-byte single-upload limit", info.Size(), maxSinglePutBytes)
 	}
-	zipSize := info.Size()
 
 	zipFile, err := os.Open(tmpPath)
 	if err != nil {
-		return 0, fmt.Errorf("open ZIP for upload: %w", err)
+		return out, fmt.Errorf("open ZIP for upload: %w", err)
 	}
 	defer zipFile.Close()
 
+	enc, err := sessionKeys.ForKey(ctx, zipKey)
+	if err != nil {
+		return out, fmt.Errorf("upload ZIP to S3: %w", err)
+	}
+	digest := hasher.Sum(nil)
 	contentType := "application/zip"
-	_, err = s3Client.PutObject(ctx, &s3.PutObjectInput{
+	putResult, err := s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
 		Bucket: &mediaBucket, Key: &zipKey,
 		Body: zipFile, ContentType: &contentType,
-		Tagging: s3util.ProjectTagging(),
-	})
+		ContentLength:  aws.Int64(info.Size()),
+		ChecksumSHA256: aws.String(base64.StdEncoding.EncodeToString(digest)),
+		Tagging:        s3util.ProjectTagging(),
+	}))
 	if err != nil {
-		return 0, fmt.Errorf("upload ZIP to S3: %w", err)
+		return out, fmt.Errorf("upload ZIP to S3: %w", err)
 	}
 
-	return zipSize, nil
+	out.size = info.Size()
+	out.sha256 = hex.EncodeToString(digest)
+	out.etag = aws.ToString(putResult.ETag)
+	return out, nil
 }
 
 func sanitizeZipName(groupLabel, bundleType string, index int) string {
@@ -357,5 +535,8 @@ func sanitizeZipName(groupLabel, bundleType string, index int) string {
 	if bundleType == "images" {
 		return fmt.Sprintf("%s-images.zip", name)
 	}
+	if bundleType == "raw" {
+		return fmt.Sprintf("%s-raw-%d.zip", name, index)
+	}
 	return fmt.Sprintf("%s-videos-%d.zip", name, index)
 }
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+)
+
+// adjustQuality is the JPEG quality of locally adjusted photos.
+const adjustQuality = 92
+
+// handleEnhancementAdjust applies local adjustments (brightness, contrast,
+// saturation, rotation, crop) to an enhanced photo and saves the result as
+// its next version (DDR-183). Invoked asynchronously by the API Lambda; no
+// model is called.
+func handleEnhancementAdjust(ctx context.Context, event AdjustEvent) error {
+	if err := applyEnhancementAdjust(ctx, event); err != nil {
+		log.Error().Err(err).Str("jobId", event.JobID).Str("key", event.Key).Msg("Enhancement adjustment failed")
+	}
+	return nil
+}
+
+func applyEnhancementAdjust(ctx context.Context, event AdjustEvent) error {
+	start := time.Now()
+	job, err := sessionStore.GetEnhancementJob(ctx, event.SessionID, event.JobID)
+	if err != nil {
+		return fmt.Errorf("read enhancement job: %w", err)
+	}
+	if job == nil {
+		return fmt.Errorf("enhancement job %s not found", event.JobID)
+	}
+
+	targetIdx, item, imageData, _, err := loadItemSource(ctx, job, event.Key)
+	if err != nil {
+		return err
+	}
+
+	adjusted, err := media.ApplyAdjustments(imageData, event.Adjustments, adjustQuality)
+	if err != nil {
+		return fmt.Errorf("apply adjustments: %w", err)
+	}
+
+	summary := event.Adjustments.String()
+	v, err := uploadVersion(ctx, job, item, adjusted, "image/jpeg",
+		media.EditGeminiEnhance, media.ProvenanceEdit{Description: "Adjusted: " + summary})
+	if err != nil {
+		return err
+	}
+	v.Adjustments = summary
+
+	// The feedback conversation is unchanged: the next feedback round starts
+	// from this version.
+	updatedItem := item
+	updatedItem.AddVersion(v)
+	if err := sessionStore.UpdateEnhancementItemFields(ctx, event.SessionID, event.JobID, targetIdx, updatedItem); err != nil {
+		return fmt.Errorf("save adjusted version: %w", err)
+	}
+	log.Info().Str("jobId", event.JobID).Str("key", v.EnhancedKey).Int("version", v.Version).Str("adjustments", summary).Dur("duration", time.Since(start)).Msg("Enhancement adjustment complete")
+	return nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// cropDetectWidth is the width of the copy sent to Gemini for subject
+// detection. Boxes come back normalized, so full resolution adds upload time
+// without improving them.
+const cropDetectWidth = 1024
+
+// handleCropSuggestions proposes a 1:1, 4:5 and 9:16 crop for each photo,
+// centred on the subject Gemini detects (DDR-130). Photos without a clear
+// subject, or whose detection fails, get centred crops. A photo that cannot
+// be read is recorded on its item; the job only errors if every photo failed.
+func handleCropSuggestions(ctx context.Context, event CropSuggestionEvent) error {
+	jobStart := time.Now()
+	job := &store.CropJob{ID: event.JobID, Status: "processing"}
+	sessionStore.PutCropJob(ctx, event.SessionID, job)
+
+	fail := func(msg string) error {
+		log.Error().Str("jobId", event.JobID).Str("error", msg).Msg("Crop suggestion job failed")
+		job.Status = "error"
+		job.Error = msg
+		sessionStore.PutCropJob(ctx, event.SessionID, job)
+		return nil
+	}
+
+	genaiClient, err := ai.NewAIClient(ctx)
+	if err != nil {
+		return fail(fmt.Sprintf("create Gemini client: %v", err))
+	}
+	imageClient := ai.NewGeminiImageClient(genaiClient)
+
+	suggested := 0
+	for _, key := range event.Keys {
+		item := suggestCrops(ctx, imageClient, event.JobID, key)
+		if item.Error == "" {
+			suggested++
+		}
+		job.Items = append(job.Items, item)
+		sessionStore.PutCropJob(ctx, event.SessionID, job)
+	}
+
+	metrics.New("AiSocialMedia").
+		Dimension("JobType", "crop-suggestions").
+		Metric("CropSuggestionsGenerated", float64(suggested), metrics.UnitCount).
+		Metric("JobDurationMs", float64(time.Since(jobStart).Milliseconds()), metrics.UnitMilliseconds).
+		Flush()
+
+	if suggested == 0 {
+		return fail("no photo could be analyzed")
+	}
+	job.Status = "complete"
+	sessionStore.PutCropJob(ctx, event.SessionID, job)
+	log.Info().Str("jobId", event.JobID).Int("photos", suggested).Dur("duration", time.Since(jobStart)).Msg("Crop suggestion job complete")
+	return nil
+}
+
+// suggestCrops detects the subject of one photo and computes a crop per
+// aspect ratio in its displayed coordinates.
+func suggestCrops(ctx context.Context, client *ai.GeminiImageClient, jobID, key string) store.CropSuggestion {
+	item := store.CropSuggestion{Key: key}
+
+	tmpPath, cleanup, err := s3util.DownloadToTempFile(ctx, s3Client, mediaBucket, key)
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("Failed to download photo for crop suggestions")
+		item.Error = "failed to download photo"
+		return item
+	}
+	defer cleanup()
+	data, err := os.ReadFile(tmpPath)
+	if err != nil {
+		item.Error = "failed to read photo"
+		return item
+	}
+	cfg, _, err := media.OrientedImageConfig(data)
+	if err != nil {
+		item.Error = "unsupported image format"
+		return item
+	}
+	item.Width, item.Height = cfg.Width, cfg.Height
+
+	var subject image.Rectangle
+	// FitJPEG applies EXIF orientation, so Gemini sees the photo as displayed.
+	preview, err := media.FitJPEG(data, media.JPEGFitOptions{MaxWidth: cropDetectWidth})
+	if err == nil {
+		var subjects []ai.CropSubject
+		subjects, err = ai.DetectCropSubjects(ctx, client, preview, "image/jpeg")
+		if err == nil && len(subjects) > 0 {
+			subject = ai.SubjectBounds(subjects, cfg.Width, cfg.Height)
+			item.Subject = &store.CropRect{
+				Label: subjects[0].Label,
+				X:     subject.Min.X, Y: subject.Min.Y, W: subject.Dx(), H: subject.Dy(),
+			}
+		}
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("jobId", jobID).Str("key", key).Msg("Subject detection failed — suggesting centred crops")
+	}
+
+	for _, aspect := range media.CropAspects {
+		r := media.CropRectForAspect(cfg.Width, cfg.Height, subject, aspect)
+		item.Crops = append(item.Crops, store.CropRect{
+			Aspect: aspect.Name,
+			X:      r.Min.X, Y: r.Min.Y, W: r.Dx(), H: r.Dy(),
+		})
+	}
+	return item
+}
@@ -7,74 +7,65 @@ import (
 	"image"
 	_ "image/jpeg"
 	_ "image/png"
-	"os"
-	"path/filepath"
-	"strings"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/rs/zerolog/log"
 
 	"github.com/fpang/ai-social-media-helper/internal/ai"
 	"github.com/fpang/ai-social-media-helper/internal/media"
-	"github.com/fpang/ai-social-media-helper/internal/s3util"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 )
 
 // handleEnhancementFeedback applies user feedback to an already-enhanced photo.
-// Invoked asynchronously by the API Lambda (not via Step Functions).
+// Invoked asynchronously by the API Lambda (not via Step Functions). A round
+// that belongs to a batch records its outcome on the batch (DDR-182).
 func handleEnhancementFeedback(ctx context.Context, event EnhanceEvent) error {
-	jobStart := time.Now()
-	job, err := sessionStore.GetEnhancementJob(ctx, event.SessionID, event.JobID)
-	if err != nil || job == nil {
-		log.Error().Err(err).Str("jobId", event.JobID).Msg("Enhancement job not found for feedback")
-		return nil
-	}
-
-	// Find the target item.
-	var targetIdx int = -1
-	for i, item := range job.Items {
-		if item.Key == event.Key || item.EnhancedKey == event.Key {
-			targetIdx = i
-			break
-		}
+	version, err := applyEnhancementFeedback(ctx, event)
+	if err != nil {
+		log.Error().Err(err).Str("jobId", event.JobID).Str("key", event.Key).Msg("Enhancement feedback failed")
 	}
-	if targetIdx == -1 {
-		log.Error().Str("key", event.Key).Str("jobId", event.JobID).Msg("Item not found in enhancement job")
+	if event.BatchID == "" {
 		return nil
 	}
-	item := job.Items[targetIdx]
-
-	genaiClient, err := ai.NewAIClient(ctx)
+	item := store.FeedbackBatchItem{Key: event.Key, Status: store.FeedbackBatchComplete, Version: version}
 	if err != nil {
-		log.Error().Err(err).Str("jobId", event.JobID).Msg("Failed to create Gemini client for feedback")
-		return nil
+		item.Status, item.Error = store.FeedbackBatchError, err.Error()
 	}
-	geminiImageClient := ai.NewGeminiImageClient(genaiClient)
-
-	enhancedKey := item.EnhancedKey
-	if enhancedKey == "" {
-		enhancedKey = item.Key
+	if err := sessionStore.UpdateFeedbackBatchItem(ctx, event.SessionID, event.BatchID, event.BatchIndex, item); err != nil {
+		log.Warn().Err(err).Str("batchId", event.BatchID).Msg("Failed to record feedback batch item")
 	}
+	return nil
+}
 
-	tmpPath, cleanup, err := s3util.DownloadToTempFile(ctx, s3Client, mediaBucket, enhancedKey)
+// applyEnhancementFeedback runs one feedback round and returns the version it
+// saved (DDR-181).
+func applyEnhancementFeedback(ctx context.Context, event EnhanceEvent) (int, error) {
+	jobStart := time.Now()
+	job, err := sessionStore.GetEnhancementJob(ctx, event.SessionID, event.JobID)
 	if err != nil {
-		log.Error().Err(err).Str("key", enhancedKey).Msg("Failed to download enhanced image for feedback")
-		return nil
+		return 0, fmt.Errorf("read enhancement job: %w", err)
+	}
+	if job == nil {
+		return 0, fmt.Errorf("enhancement job %s not found", event.JobID)
 	}
-	defer cleanup()
 
-	imageData, err := os.ReadFile(tmpPath)
+	targetIdx, item, imageData, mime, err := loadItemSource(ctx, job, event.Key)
 	if err != nil {
-		log.Error().Err(err).Str("key", enhancedKey).Msg("Failed to read downloaded file for feedback")
-		return nil
+		return 0, err
 	}
 
-	ext := strings.ToLower(filepath.Ext(enhancedKey))
-	mime := "image/jpeg"
-	if m, ok := media.SupportedImageExtensions[ext]; ok {
-		mime = m
+	// DDR-170: feedback uses the settings the job started with.
+	ctx, _ = ai.ApplyModelConfig(ctx, ai.ModelConfig{
+		Model: job.Model, Thinking: job.Thinking, Temperature: job.Temperature, TopP: job.TopP,
+	})
+	// DDR-180: an Imagen fallback keeps to the job's subject preset budget.
+	ctx = ai.WithSubjectPreset(ctx, ai.SubjectPreset(job.SubjectPreset))
+
+	genaiClient, err := ai.NewAIClient(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("create Gemini client: %w", err)
 	}
+	geminiImageClient := ai.NewGeminiImageClient(genaiClient)
 
 	imgConfig, _, err := image.DecodeConfig(bytes.NewReader(imageData))
 	imageWidth, imageHeight := 1024, 1024
@@ -83,13 +74,7 @@ func handleEnhancementFeedback(ctx context.Context, event EnhanceEvent) error {
 		imageHeight = imgConfig.Height
 	}
 
-	var imagenClient *ai.ImagenClient
-	vertexProject := os.Getenv("VERTEX_AI_PROJECT")
-	vertexRegion := os.Getenv("VERTEX_AI_REGION")
-	vertexToken := os.Getenv("VERTEX_AI_TOKEN")
-	if vertexProject != "" && vertexRegion != "" && vertexToken != "" {
-		imagenClient = ai.NewImagenClient(vertexProject, vertexRegion, vertexToken)
-	}
+	imagenClient := newImagenClient(ctx)
 
 	// Convert store feedback history to chat format.
 	var feedbackHistory []ai.FeedbackEntry
@@ -110,51 +95,37 @@ func handleEnhancementFeedback(ctx context.Context, event EnhanceEvent) error {
 	if err != nil {
 		log.Warn().Err(err).Msg("Feedback processing failed")
 	}
-
-	if len(resultData) > 0 {
-		feedbackKey := fmt.Sprintf("%s/enhanced/%s", event.SessionID, filepath.Base(item.Key))
-		contentType := resultMIME
-		_, uploadErr := s3Client.PutObject(ctx, &s3.PutObjectInput{
-			Bucket: &mediaBucket, Key: &feedbackKey,
-			Body: bytes.NewReader(resultData), ContentType: &contentType,
-			Tagging: s3util.ProjectTagging(),
-		})
-		if uploadErr != nil {
-			log.Error().Err(uploadErr).Str("key", feedbackKey).Msg("Failed to upload feedback result")
-			return nil
-		}
-
-		// Generate and upload thumbnail.
-		thumbKey := fmt.Sprintf("%s/thumbnails/enhanced-%s.jpg", event.SessionID,
-			strings.TrimSuffix(filepath.Base(item.Key), filepath.Ext(item.Key)))
-		thumbData, _, thumbErr := s3util.GenerateThumbnailFromBytes(resultData, resultMIME, thumbnailMaxDimension)
-		if thumbErr == nil {
-			thumbContentType := "image/jpeg"
-			s3Client.PutObject(ctx, &s3.PutObjectInput{
-				Bucket: &mediaBucket, Key: &thumbKey,
-				Body: bytes.NewReader(thumbData), ContentType: &thumbContentType,
-				Tagging: s3util.ProjectTagging(),
-			})
+	if len(resultData) == 0 {
+		if err == nil {
+			err = fmt.Errorf("feedback produced no image")
 		}
-
-		// Atomically update only this item (no counter change for feedback).
-		updatedItem := item
-		updatedItem.EnhancedKey = feedbackKey
-		updatedItem.EnhancedThumbKey = thumbKey
-		updatedItem.Phase = ai.PhaseFeedback
-		if feedbackEntry != nil {
-			updatedItem.FeedbackHistory = append(updatedItem.FeedbackHistory, store.FeedbackEntry{
-				UserFeedback:  feedbackEntry.UserFeedback,
-				ModelResponse: feedbackEntry.ModelResponse,
-				Method:        feedbackEntry.Method,
-				Success:       feedbackEntry.Success,
-			})
-		}
-		if err := sessionStore.UpdateEnhancementItemFields(ctx, event.SessionID, event.JobID, targetIdx, updatedItem); err != nil {
-			log.Warn().Err(err).Msg("Failed to update enhancement item with feedback")
-		}
-		log.Info().Str("jobId", event.JobID).Str("feedbackKey", feedbackKey).Dur("duration", time.Since(jobStart)).Msg("Enhancement feedback complete")
+		return 0, err
 	}
 
-	return nil
+	// DDR-181: each round is saved as a new version instead of replacing
+	// the current one.
+	v, err := uploadVersion(ctx, job, item, resultData, resultMIME,
+		media.EditGeminiEnhance, media.ProvenanceEdit{Description: "Revised with Gemini from user feedback", AI: true})
+	if err != nil {
+		return 0, err
+	}
+	v.Feedback = event.Feedback
+
+	// Atomically update only this item (no counter change for feedback).
+	updatedItem := item
+	updatedItem.AddVersion(v)
+	updatedItem.Phase = ai.PhaseFeedback
+	if feedbackEntry != nil {
+		updatedItem.FeedbackHistory = append(updatedItem.FeedbackHistory, store.FeedbackEntry{
+			UserFeedback:  feedbackEntry.UserFeedback,
+			ModelResponse: feedbackEntry.ModelResponse,
+			Method:        feedbackEntry.Method,
+			Success:       feedbackEntry.Success,
+		})
+	}
+	if err := sessionStore.UpdateEnhancementItemFields(ctx, event.SessionID, event.JobID, targetIdx, updatedItem); err != nil {
+		return 0, fmt.Errorf("save feedback result: %w", err)
+	}
+	log.Info().Str("jobId", event.JobID).Str("feedbackKey", v.EnhancedKey).Int("version", v.Version).Dur("duration", time.Since(jobStart)).Msg("Enhancement feedback complete")
+	return v.Version, nil
 }
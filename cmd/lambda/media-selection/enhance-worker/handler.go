@@ -16,6 +16,8 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/artifacts"
+	"github.com/fpang/ai-social-media-helper/internal/flags"
 	"github.com/fpang/ai-social-media-helper/internal/media"
 	"github.com/fpang/ai-social-media-helper/internal/s3util"
 	"github.com/fpang/ai-social-media-helper/internal/store"
@@ -45,6 +47,44 @@ func handleEnhance(ctx context.Context, event EnhanceEvent) (EnhanceResult, erro
 		}, fmt.Errorf("sessionId, jobId, and key are required")
 	}
 
+	// Checkpoint (DDR-095): skip items of a paused job, items an earlier run
+	// already finished and items the user skipped (DDR-143), and resolve the
+	// item index by key for resumed runs.
+	itemIndex, preset, skipPhase := checkpointItem(ctx, event.SessionID, event.JobID, event.Key, event.ItemIndex)
+	if skipPhase != "" {
+		logger.Info().Str("phase", skipPhase).Msg("Skipping item — job paused or item already processed")
+		return EnhanceResult{OriginalKey: event.Key, Phase: skipPhase}, nil
+	}
+	event.ItemIndex = itemIndex
+
+	// Everything the item writes is encrypted like the session (DDR-164).
+	enc, err := sessionKeys.ForSession(ctx, event.SessionID)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to resolve session encryption")
+		updateItemError(ctx, event, "encryption unavailable")
+		return EnhanceResult{
+			OriginalKey: event.Key,
+			Phase:       ai.PhaseError,
+			Error:       fmt.Sprintf("session encryption: %v", err),
+		}, err
+	}
+
+	// DDR-106: keep model responses and Imagen masks when requested. Items run
+	// in parallel, so each gets its own prefix.
+	if event.DebugArtifacts {
+		prefix := fmt.Sprintf("%s/item-%d", artifacts.SessionPrefix(event.SessionID, event.JobID), event.ItemIndex)
+		ctx = artifacts.WithRecorder(ctx, artifacts.NewS3Recorder(s3Client, bucket, prefix, enc))
+	}
+
+	// DDR-170: the job's model settings for the analysis and edit calls.
+	ctx, _ = ai.ApplyModelConfig(ctx, event.ModelConfig)
+	// DDR-180: the job's subject preset for the prompts and Imagen budget.
+	subject, err := ai.ParseSubjectPreset(event.SubjectPreset)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Invalid subject preset — using the general pipeline")
+	}
+	ctx = ai.WithSubjectPreset(ctx, subject)
+
 	// Download photo from S3.
 	tmpPath, cleanup, err := s3util.DownloadToTempFile(ctx, s3Client, bucket, event.Key)
 	if err != nil {
@@ -76,6 +116,20 @@ func handleEnhance(ctx context.Context, event EnhanceEvent) (EnhanceResult, erro
 	if m, ok := media.SupportedImageExtensions[ext]; ok {
 		mime = m
 	}
+	// DDR-119: Gemini cannot read RAW files; enhance the embedded preview.
+	if media.IsRaw(ext) {
+		imageData, err = media.RAWPreviewJPEG(tmpPath, 95)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to extract RAW preview")
+			updateItemError(ctx, event, "RAW preview unavailable")
+			return EnhanceResult{
+				OriginalKey: event.Key,
+				Phase:       ai.PhaseError,
+				Error:       fmt.Sprintf("RAW preview unavailable: %v", err),
+			}, err
+		}
+		mime = "image/jpeg"
+	}
 	logger.Debug().Str("mimeType", mime).Str("extension", ext).Msg("MIME type determined")
 
 	// Get image dimensions for mask generation.
@@ -102,17 +156,11 @@ func handleEnhance(ctx context.Context, event EnhanceEvent) (EnhanceResult, erro
 	geminiImageClient := ai.NewGeminiImageClient(genaiClient)
 
 	// Set up Imagen client (optional — only if Vertex AI is configured).
-	var imagenClient *ai.ImagenClient
-	vertexProject := os.Getenv("VERTEX_AI_PROJECT")
-	vertexRegion := os.Getenv("VERTEX_AI_REGION")
-	vertexToken := os.Getenv("VERTEX_AI_TOKEN")
-	if vertexProject != "" && vertexRegion != "" && vertexToken != "" {
-		imagenClient = ai.NewImagenClient(vertexProject, vertexRegion, vertexToken)
-	}
+	imagenClient := newImagenClient(ctx)
 	logger.Debug().Bool("imagenConfigured", imagenClient != nil).Msg("Imagen client status")
 
 	// Run the full enhancement pipeline.
-	state, err := ai.RunFullEnhancement(ctx, geminiImageClient, imagenClient, imageData, mime, imageWidth, imageHeight)
+	state, err := ai.RunFullEnhancement(ctx, geminiImageClient, imagenClient, imageData, mime, imageWidth, imageHeight, preset)
 	if err != nil {
 		logger.Warn().Err(err).Msg("Enhancement pipeline failed")
 		updateItemError(ctx, event, err.Error())
@@ -127,20 +175,66 @@ func handleEnhance(ctx context.Context, event EnhanceEvent) (EnhanceResult, erro
 		return result, err
 	}
 
+	// DDR-184: upscale the result of a low-resolution photo.
+	var upscale *store.UpscaleInfo
+	if configErr == nil {
+		upscale = upscaleResult(ctx, imagenClient, state, imageWidth, imageHeight, event.UpscaleBelow)
+	}
+
+	// DDR-185: the privacy preset blurs bystanders in the final pixels.
+	var redactions []store.RedactRegion
+	if preset == ai.PresetPrivacy {
+		var redactErr error
+		redactions, redactErr = redactResult(ctx, geminiImageClient, imagenClient, state, mime)
+		if redactErr != nil {
+			logger.Error().Err(redactErr).Msg("Privacy preset could not redact bystanders, not storing the result")
+			updateItemRedactionError(ctx, event, preset, redactErr)
+			return EnhanceResult{
+				OriginalKey: event.Key,
+				Phase:       ai.PhaseError,
+				Error:       redactErr.Error(),
+				Phase1Text:  state.Phase1Text,
+			}, redactErr
+		}
+	}
+
 	// Upload enhanced image to S3.
-	enhancedKey := fmt.Sprintf("%s/enhanced/%s", event.SessionID, filepath.Base(event.Key))
+	enhancedKey := enhancedKeyFor(event.SessionID, event.Key, 1)
 	contentType := state.CurrentMIME
 	if contentType == "" {
 		contentType = mime
 	}
-	logger.Debug().Str("enhancedKey", enhancedKey).Int("size", len(state.CurrentData)).Msg("Uploading enhanced image to S3")
-	_, uploadErr := s3Client.PutObject(ctx, &s3.PutObjectInput{
+	// DDR-133: stamp the owner's watermark when the job asked for one.
+	enhancedData, cleanKey := state.CurrentData, ""
+	edits := []media.ProvenanceEdit{media.EditGeminiEnhance}
+	if state.ImagenEdits > 0 {
+		edits = append(edits, media.ProvenanceEdit{Description: fmt.Sprintf("%d region edits with Imagen", state.ImagenEdits), AI: true})
+	}
+	if upscale != nil {
+		edits = append(edits, media.ProvenanceEdit{Description: fmt.Sprintf("Upscaled %dx with Imagen", upscale.Factor), AI: true})
+	}
+	if len(redactions) > 0 {
+		edits = append(edits, redactEdit(ai.RedactBlur, len(redactions)))
+	}
+	if overlay := jobWatermark(ctx, event.SessionID, event.JobID); overlay != nil {
+		stamped, key, err := watermarkEnhanced(ctx, *overlay, bucket, event.SessionID, event.Key, 1, enhancedData, contentType)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Watermark failed, storing the enhanced photo without it")
+		} else {
+			enhancedData, cleanKey, contentType = stamped, key, "image/jpeg"
+			edits = append(edits, media.EditWatermark)
+		}
+	}
+	// DDR-140: record the AI edits in the photo's metadata.
+	enhancedData = stampProvenance(ctx, event.SessionID, enhancedData, edits...)
+	logger.Debug().Str("enhancedKey", enhancedKey).Int("size", len(enhancedData)).Msg("Uploading enhanced image to S3")
+	_, uploadErr := s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
 		Bucket:      &bucket,
 		Key:         &enhancedKey,
-		Body:        bytes.NewReader(state.CurrentData),
+		Body:        bytes.NewReader(enhancedData),
 		ContentType: &contentType,
 		Tagging:     s3util.ProjectTagging(),
-	})
+	}))
 	if uploadErr != nil {
 		logger.Error().Err(uploadErr).Str("enhancedKey", enhancedKey).Msg("Failed to upload enhanced image")
 		updateItemError(ctx, event, "upload failed")
@@ -152,22 +246,21 @@ func handleEnhance(ctx context.Context, event EnhanceEvent) (EnhanceResult, erro
 	}
 
 	// Generate and upload thumbnail of enhanced version.
-	enhancedThumbKey := fmt.Sprintf("%s/thumbnails/enhanced-%s.jpg", event.SessionID,
-		strings.TrimSuffix(filepath.Base(event.Key), filepath.Ext(event.Key)))
-	thumbData, _, thumbErr := s3util.GenerateThumbnailFromBytes(state.CurrentData, contentType, thumbnailMaxDimension)
+	enhancedThumbKey := enhancedThumbKeyFor(event.SessionID, event.Key, 1)
+	thumbData, _, thumbErr := s3util.GenerateThumbnailFromBytes(enhancedData, contentType, thumbnailMaxDimension)
 	if thumbErr == nil {
 		thumbContentType := "image/jpeg"
-		s3Client.PutObject(ctx, &s3.PutObjectInput{
+		s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
 			Bucket:      &bucket,
 			Key:         &enhancedThumbKey,
 			Body:        bytes.NewReader(thumbData),
 			ContentType: &thumbContentType,
 			Tagging:     s3util.ProjectTagging(),
-		})
+		}))
 	}
 
 	// Update DynamoDB with the enhanced item results.
-	updateItemComplete(ctx, event, enhancedKey, enhancedThumbKey, state)
+	updateItemComplete(ctx, event, preset, enhancedKey, enhancedThumbKey, cleanKey, state, upscale, redactions, checkLegibility(event.Key, state.CurrentData))
 
 	logger.Info().
 		Str("enhancedKey", enhancedKey).
@@ -189,7 +282,7 @@ func handleEnhance(ctx context.Context, event EnhanceEvent) (EnhanceResult, erro
 // updateItemComplete atomically updates the enhancement item with success results
 // and increments CompletedCount. Sets job status to "complete" if all items are done.
 // Best-effort — errors are logged but don't affect the Lambda response.
-func updateItemComplete(ctx context.Context, event EnhanceEvent, enhancedKey, enhancedThumbKey string, state *ai.EnhancementState) {
+func updateItemComplete(ctx context.Context, event EnhanceEvent, preset ai.EnhancementPreset, enhancedKey, enhancedThumbKey, cleanKey string, state *ai.EnhancementState, upscale *store.UpscaleInfo, redactions []store.RedactRegion, legibility []store.LegibilityWarning) {
 	if event.ItemIndex < 0 {
 		log.Warn().Int("itemIndex", event.ItemIndex).Msg("Invalid item index for completion update")
 		return
@@ -202,11 +295,25 @@ func updateItemComplete(ctx context.Context, event EnhanceEvent, enhancedKey, en
 		Phase:            state.Phase,
 		EnhancedKey:      enhancedKey,
 		EnhancedThumbKey: enhancedThumbKey,
+		CleanKey:         cleanKey,
 		OriginalThumbKey: fmt.Sprintf("%s/thumbnails/%s.jpg", event.SessionID,
 			strings.TrimSuffix(filepath.Base(event.Key), filepath.Ext(event.Key))),
-		Phase1Text:  state.Phase1Text,
-		ImagenEdits: state.ImagenEdits,
+		Phase1Text:         state.Phase1Text,
+		ImagenEdits:        state.ImagenEdits,
+		LegibilityWarnings: legibility,
+		Preset:             string(preset),
+		Upscale:            upscale,
+		Redactions:         redactions,
 	}
+	item.AddVersion(store.EnhancementVersion{
+		Version:            1,
+		EnhancedKey:        enhancedKey,
+		EnhancedThumbKey:   enhancedThumbKey,
+		CleanKey:           cleanKey,
+		LegibilityWarnings: legibility,
+		Redactions:         redactions,
+		CreatedAt:          time.Now().Unix(),
+	})
 	if state.Analysis != nil {
 		item.Analysis = &store.AnalysisResult{
 			OverallAssessment:    state.Analysis.OverallAssessment,
@@ -241,3 +348,78 @@ func updateItemComplete(ctx context.Context, event EnhanceEvent, enhancedKey, en
 		}
 	}
 }
+
+// checkLegibility flags text in the enhanced image that is unlikely to stay
+// readable after Instagram resizes and recompresses it (DDR-104). Screenshots
+// are recognized from the original key, since enhanced output is often PNG.
+// Best-effort — an undecodable image yields no warnings.
+func checkLegibility(originalKey string, enhancedData []byte) []store.LegibilityWarning {
+	ext := strings.ToLower(filepath.Ext(originalKey))
+	screenshot := media.IsLikelyScreenshot(originalKey, strings.TrimPrefix(ext, "."))
+	report, err := media.CheckLegibility(enhancedData, screenshot)
+	if err != nil {
+		log.Warn().Err(err).Str("key", originalKey).Msg("Legibility check failed")
+		return nil
+	}
+
+	var warnings []store.LegibilityWarning
+	for _, w := range report.Warnings {
+		warnings = append(warnings, store.LegibilityWarning{Code: w.Code, Message: w.Message})
+	}
+	log.Debug().
+		Str("key", originalKey).
+		Bool("screenshot", report.Screenshot).
+		Float64("textCoverage", report.TextCoverage).
+		Float64("contrastRatio", report.ContrastRatio).
+		Int("textHeight", report.TextHeight).
+		Int("warnings", len(warnings)).
+		Msg("Legibility check complete")
+	return warnings
+}
+
+// newImagenClient returns an Imagen client when Vertex AI is configured and
+// the imagen feature flag is on (DDR-132), nil otherwise. Its credentials
+// come from the service account loaded at cold start (DDR-171).
+func newImagenClient(ctx context.Context) *ai.ImagenClient {
+	vertexProject := os.Getenv("VERTEX_AI_PROJECT")
+	vertexRegion := os.Getenv("VERTEX_AI_REGION")
+	if vertexProject == "" || vertexRegion == "" {
+		return nil
+	}
+	if !featureFlags.Enabled(ctx, flags.Imagen) {
+		log.Info().Msg("Imagen disabled by feature flag")
+		return nil
+	}
+	tokens, err := ai.VertexTokenSource()
+	if err != nil {
+		log.Warn().Err(err).Msg("Imagen unavailable without Vertex AI credentials")
+		return nil
+	}
+	return ai.NewImagenClient(vertexProject, vertexRegion, tokens)
+}
+
+// enhancedKeyFor returns the S3 key of a version of the enhanced copy of
+// key. A RAW original is enhanced from its JPEG preview, so its copy is named
+// .jpg (DDR-119). Version 1 is {sessionId}/enhanced/{name}; later versions
+// sit in a v{n} folder and keep the name, so mapping an enhanced key back to
+// its original by base name still works (DDR-181).
+func enhancedKeyFor(sessionID, key string, version int) string {
+	name := filepath.Base(key)
+	if ext := filepath.Ext(name); media.IsRaw(ext) {
+		name = strings.TrimSuffix(name, ext) + ".jpg"
+	}
+	if version > 1 {
+		return fmt.Sprintf("%s/enhanced/v%d/%s", sessionID, version, name)
+	}
+	return fmt.Sprintf("%s/enhanced/%s", sessionID, name)
+}
+
+// enhancedThumbKeyFor returns the S3 key of the thumbnail of a version of
+// the enhanced copy of key.
+func enhancedThumbKeyFor(sessionID, key string, version int) string {
+	base := strings.TrimSuffix(filepath.Base(key), filepath.Ext(key))
+	if version > 1 {
+		return fmt.Sprintf("%s/thumbnails/enhanced/v%d/%s.jpg", sessionID, version, base)
+	}
+	return fmt.Sprintf("%s/thumbnails/enhanced-%s.jpg", sessionID, base)
+}
@@ -1,8 +1,11 @@
 // Package main provides a Lambda entry point for per-photo AI enhancement (DDR-053).
 //
-// This Lambda handles both initial enhancement and feedback-driven re-enhancement:
+// This Lambda handles initial enhancement, feedback-driven re-enhancement,
+// stylized cover variants, and crop suggestions:
 //   - Step Functions invocation: EnhancementPipeline Map state (one per photo)
 //   - Async invocation: enhancement-feedback from the API Lambda
+//   - Async invocation: mood-variants from the API Lambda (DDR-102)
+//   - Async invocation: crop-suggestions from the API Lambda (DDR-130)
 //
 // Container: Light (Dockerfile.light — no ffmpeg needed for photo enhancement)
 // Memory: 2 GB
@@ -21,14 +24,17 @@ import (
 	"os"
 	"time"
 
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/rs/zerolog/log"
 
 	"github.com/fpang/ai-social-media-helper/internal/ai"
 	"github.com/fpang/ai-social-media-helper/internal/bootstrap"
+	"github.com/fpang/ai-social-media-helper/internal/flags"
+	"github.com/fpang/ai-social-media-helper/internal/jobs"
 	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 )
 
@@ -37,10 +43,13 @@ const thumbnailMaxDimension = 400
 
 // AWS clients and configuration initialized at cold start.
 var (
-	s3Client     *s3.Client
-	sessionStore *store.DynamoStore
-	mediaBucket  string
-	ebClient     *eventbridge.Client
+	s3Client          *s3.Client
+	sessionStore      *store.DynamoStore
+	mediaBucket       string
+	sessionKeys       *s3util.SessionKeys // DDR-164
+	brandAssetsBucket string              // DDR-149: optional
+	ebClient          *eventbridge.Client
+	featureFlags      *flags.Set // DDR-132
 )
 
 var coldStart = true
@@ -53,27 +62,37 @@ func init() {
 	s3s := bootstrap.InitS3(awsClients.Config, "MEDIA_BUCKET_NAME")
 	s3Client = s3s.Client
 	mediaBucket = s3s.Bucket
+	sessionKeys = s3s.Keys
+	brandAssetsBucket = os.Getenv("BRAND_ASSETS_BUCKET_NAME")
 	sessionStore = bootstrap.InitDynamo(awsClients.Config, "DYNAMO_TABLE_NAME")
 	bootstrap.LoadGeminiKey(awsClients.SSM)
 	bootstrap.LoadGCPServiceAccountKey(awsClients.SSM)
 	_ = ai.LoadGCPServiceAccount()
 
 	ebClient = eventbridge.NewFromConfig(awsClients.Config)
+	featureFlags = bootstrap.InitFlags(awsClients.SSM)
 
 	bootstrap.StartupLog("enhance-lambda", initStart).
 		S3Bucket("mediaBucket", mediaBucket).
+		S3Bucket("brandAssetsBucket", brandAssetsBucket).
 		DynamoTable("sessions", os.Getenv("DYNAMO_TABLE_NAME")).
 		SSMParam("geminiApiKey", logging.EnvOrDefault("SSM_API_KEY_PARAM", "/ai-social-media/prod/gemini-api-key")).
+		SSMParam("featureFlags", logging.EnvOrDefault("SSM_FEATURE_FLAGS_PARAM", flags.DefaultSSMParam)).
 		Log()
 }
 
-// rawHandler accepts raw JSON to route between enhancement and feedback handlers.
+// rawHandler accepts raw JSON to route between enhancement, feedback, mood
+// variant, and crop suggestion handlers.
 func rawHandler(ctx context.Context, raw json.RawMessage) (interface{}, error) {
 	if coldStart {
 		coldStart = false
 		log.Info().Str("function", "enhance-lambda").Msg("Cold start — first invocation")
 	}
 
+	// DDR-118: collect per-job telemetry; the enhancement and mood variant
+	// stores record it.
+	ctx, _ = metrics.WithCollector(ctx)
+
 	// Peek at the "type" field to route.
 	var peek struct {
 		Type string `json:"type"`
@@ -87,6 +106,34 @@ func rawHandler(ctx context.Context, raw json.RawMessage) (interface{}, error) {
 		}
 		return nil, handleEnhancementFeedback(ctx, event)
 	}
+	if peek.Type == "mood-variants" {
+		var event MoodVariantEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return nil, fmt.Errorf("unmarshal mood variant event: %w", err)
+		}
+		return nil, handleMoodVariants(ctx, event)
+	}
+	if peek.Type == "crop-suggestions" {
+		var event CropSuggestionEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return nil, fmt.Errorf("unmarshal crop suggestion event: %w", err)
+		}
+		return nil, handleCropSuggestions(ctx, event)
+	}
+	if peek.Type == "enhancement-adjust" {
+		var event AdjustEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return nil, fmt.Errorf("unmarshal adjust event: %w", err)
+		}
+		return nil, handleEnhancementAdjust(ctx, event)
+	}
+	if peek.Type == "media-redact" {
+		var event RedactEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return nil, fmt.Errorf("unmarshal redact event: %w", err)
+		}
+		return nil, handleMediaRedact(ctx, event)
+	}
 
 	// Default: Step Functions enhancement invocation.
 	var event EnhanceEvent
@@ -97,23 +144,39 @@ func rawHandler(ctx context.Context, raw json.RawMessage) (interface{}, error) {
 }
 
 func main() {
-	lambda.Start(rawHandler)
+	bootstrap.Start(jobs.WithQueue(rawHandler)) // Step Functions, direct invoke, or SQS job queue (DDR-092)
+}
+
+// checkpointItem consults the job record before processing an item (DDR-095)
+// and resolves the preset the item was started with (DDR-143).
+func checkpointItem(ctx context.Context, sessionID, jobID, key string, hint int) (int, ai.EnhancementPreset, string) {
+	index, item, skipPhase := store.CheckpointItem(ctx, sessionStore, sessionID, jobID, key, hint)
+	preset, err := ai.ParseEnhancementPreset(item.Preset)
+	if err != nil {
+		log.Warn().Err(err).Str("jobId", jobID).Str("key", key).Msg("Invalid stored preset — using full")
+		preset = ai.PresetFull
+	}
+	return index, preset, skipPhase
 }
 
 // updateItemError atomically updates the enhancement item with an error status
 // and increments CompletedCount. Sets job status to "complete" if all items are done.
 // Best-effort — errors are logged but don't affect the Lambda response.
 func updateItemError(ctx context.Context, event EnhanceEvent, errMsg string) {
-	if event.ItemIndex < 0 {
-		log.Warn().Int("itemIndex", event.ItemIndex).Msg("Invalid item index for error update")
-		return
-	}
-
-	errItem := store.EnhancementItem{
+	recordItemError(ctx, event, store.EnhancementItem{
 		Key:         event.Key,
 		OriginalKey: event.Key,
 		Phase:       ai.PhaseError,
 		Error:       errMsg,
+	})
+}
+
+// recordItemError saves a failed item and counts it as done, completing the
+// job when it was the last one.
+func recordItemError(ctx context.Context, event EnhanceEvent, errItem store.EnhancementItem) {
+	if event.ItemIndex < 0 {
+		log.Warn().Int("itemIndex", event.ItemIndex).Msg("Invalid item index for error update")
+		return
 	}
 
 	newCount, totalCount, err := sessionStore.UpdateEnhancementItemResult(ctx, event.SessionID, event.JobID, event.ItemIndex, errItem)
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// handleMoodVariants renders the requested stylized variants of a cover image,
+// stamps each with an AI-generated watermark, and stores them under
+// {sessionId}/ai-generated/ (DDR-102). A style that fails is recorded on its
+// variant and its budget unit is released; the job only errors if no variant
+// was produced.
+func handleMoodVariants(ctx context.Context, event MoodVariantEvent) error {
+	jobStart := time.Now()
+	job := &store.MoodVariantJob{ID: event.JobID, Status: "processing", SourceKey: event.SourceKey}
+	sessionStore.PutMoodVariantJob(ctx, event.SessionID, job)
+
+	generated := 0
+	refund := func() {
+		if failed := len(event.Styles) - generated; failed > 0 && event.Owner != "" {
+			sessionStore.ReleaseDailyBudget(ctx, event.Owner, store.MoodVariantBudget, failed)
+			log.Info().Str("jobId", event.JobID).Int("units", failed).Msg("Mood variant budget released for failed styles")
+		}
+	}
+	fail := func(msg string) error {
+		log.Error().Str("jobId", event.JobID).Str("error", msg).Msg("Mood variant job failed")
+		refund()
+		job.Status = "error"
+		job.Error = msg
+		sessionStore.PutMoodVariantJob(ctx, event.SessionID, job)
+		return nil
+	}
+
+	styles, err := ai.ParseMoodStyles(event.Styles)
+	if err != nil {
+		return fail(err.Error())
+	}
+
+	tmpPath, cleanup, err := s3util.DownloadToTempFile(ctx, s3Client, mediaBucket, event.SourceKey)
+	if err != nil {
+		return fail(fmt.Sprintf("download cover image: %v", err))
+	}
+	defer cleanup()
+	imageData, err := os.ReadFile(tmpPath)
+	if err != nil {
+		return fail(fmt.Sprintf("read cover image: %v", err))
+	}
+	mime := "image/jpeg"
+	if m, ok := media.SupportedImageExtensions[strings.ToLower(filepath.Ext(event.SourceKey))]; ok {
+		mime = m
+	}
+
+	genaiClient, err := ai.NewAIClient(ctx)
+	if err != nil {
+		return fail(fmt.Sprintf("create Gemini client: %v", err))
+	}
+	imageClient := ai.NewGeminiImageClient(genaiClient)
+
+	for _, style := range styles {
+		variant := generateMoodVariant(ctx, imageClient, event, imageData, mime, style)
+		if variant.Error == "" {
+			generated++
+		}
+		job.Variants = append(job.Variants, variant)
+		sessionStore.PutMoodVariantJob(ctx, event.SessionID, job)
+	}
+
+	metrics.New("AiSocialMedia").
+		Dimension("JobType", "mood-variants").
+		Metric("MoodVariantsGenerated", float64(generated), metrics.UnitCount).
+		Metric("JobDurationMs", float64(time.Since(jobStart).Milliseconds()), metrics.UnitMilliseconds).
+		Flush()
+
+	if generated == 0 {
+		return fail("no variant could be generated")
+	}
+	refund()
+	job.Status = "complete"
+	sessionStore.PutMoodVariantJob(ctx, event.SessionID, job)
+	log.Info().Str("jobId", event.JobID).Int("generated", generated).Dur("duration", time.Since(jobStart)).Msg("Mood variant job complete")
+	return nil
+}
+
+// generateMoodVariant renders, watermarks, and uploads a single style.
+func generateMoodVariant(ctx context.Context, client *ai.GeminiImageClient, event MoodVariantEvent, imageData []byte, mime string, style ai.MoodStyle) store.MoodVariant {
+	variant := store.MoodVariant{Style: style.ID}
+
+	result, err := ai.GenerateMoodVariant(ctx, client, imageData, mime, style)
+	if err != nil {
+		log.Warn().Err(err).Str("jobId", event.JobID).Str("style", style.ID).Msg("Mood variant generation failed")
+		variant.Error = err.Error()
+		return variant
+	}
+	marked, err := media.WatermarkAIGenerated(result.ImageData)
+	if err != nil {
+		log.Warn().Err(err).Str("jobId", event.JobID).Str("style", style.ID).Msg("Mood variant watermarking failed")
+		variant.Error = fmt.Sprintf("watermark: %v", err)
+		return variant
+	}
+	marked = stampProvenance(ctx, event.SessionID, marked, // DDR-140
+		media.ProvenanceEdit{Description: fmt.Sprintf("Restyled as %q with Gemini", style.ID), AI: true},
+		media.ProvenanceEdit{Description: "AI-generated label added"})
+
+	key := fmt.Sprintf("%s/ai-generated/%s-%s.jpg", event.SessionID, event.JobID, style.ID)
+	contentType := "image/jpeg"
+	enc, err := sessionKeys.ForSession(ctx, event.SessionID) // DDR-164
+	if err != nil {
+		log.Warn().Err(err).Str("jobId", event.JobID).Str("style", style.ID).Msg("Mood variant encryption unavailable")
+		variant.Error = fmt.Sprintf("encryption: %v", err)
+		return variant
+	}
+	_, err = s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
+		Bucket: &mediaBucket, Key: &key,
+		Body: bytes.NewReader(marked), ContentType: &contentType,
+		Metadata: map[string]string{
+			"ai-generated": "true",
+			"ai-style":     style.ID,
+			"source-key":   event.SourceKey,
+		},
+		Tagging: s3util.ProjectTagging(),
+	}))
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("Failed to upload mood variant")
+		variant.Error = "failed to store variant"
+		return variant
+	}
+	variant.Key = key
+
+	thumbKey := fmt.Sprintf("%s/thumbnails/ai-generated-%s-%s.jpg", event.SessionID, event.JobID, style.ID)
+	thumbData, _, err := s3util.GenerateThumbnailFromBytes(marked, contentType, thumbnailMaxDimension)
+	if err == nil {
+		_, err = s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
+			Bucket: &mediaBucket, Key: &thumbKey,
+			Body: bytes.NewReader(thumbData), ContentType: &contentType,
+			Tagging: s3util.ProjectTagging(),
+		}))
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("key", thumbKey).Msg("Failed to create mood variant thumbnail")
+	} else {
+		variant.ThumbKey = thumbKey
+	}
+	return variant
+}
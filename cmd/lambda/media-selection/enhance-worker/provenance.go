@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+)
+
+// --- Provenance records (DDR-140) ---
+
+// stampProvenance embeds a provenance record listing edits when the session
+// owner wants one. Failures are logged and return data unchanged.
+func stampProvenance(ctx context.Context, sessionID string, data []byte, edits ...media.ProvenanceEdit) []byte {
+	if !media.OwnerWantsProvenance(ctx, sessionStore, sessionID) {
+		return data
+	}
+	out, err := media.EmbedProvenance(data, media.Provenance{Edits: edits})
+	if err != nil {
+		log.Warn().Err(err).Str("sessionId", sessionID).Msg("Provenance embedding failed, storing the photo without it")
+		return data
+	}
+	return out
+}
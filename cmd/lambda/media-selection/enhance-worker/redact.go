@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// redactResult blurs bystanders in the pipeline's result in place for the
+// privacy preset (DDR-185). It runs after any upscale, so an upscaler never
+// sees a face it could sharpen back. A detection failure is returned rather
+// than logged: a privacy result must not be saved unredacted.
+func redactResult(ctx context.Context, geminiClient *ai.GeminiImageClient, imagenClient *ai.ImagenClient, state *ai.EnhancementState, mime string) ([]store.RedactRegion, error) {
+	contentType := state.CurrentMIME
+	if contentType == "" {
+		contentType = mime
+	}
+	result, err := ai.RedactBystanders(ctx, geminiClient, imagenClient, state.CurrentData, contentType, ai.RedactBlur)
+	if err != nil {
+		return nil, fmt.Errorf("bystander redaction failed: %w", err)
+	}
+	if len(result.Regions) == 0 {
+		return nil, nil
+	}
+	state.CurrentData, state.CurrentMIME = result.ImageData, result.MIMEType
+	log.Info().Int("regions", len(result.Regions)).Msg("Bystanders redacted")
+	return redactRegions(result.Regions), nil
+}
+
+// redactVersion blurs bystanders in a feedback or adjustment result of a
+// privacy item before it is saved as a new version, since Gemini or Imagen
+// may redraw a face the first round hid.
+func redactVersion(ctx context.Context, data []byte, contentType string) ([]byte, string, []store.RedactRegion, error) {
+	genaiClient, err := ai.NewAIClient(ctx)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("bystander redaction failed: create Gemini client: %w", err)
+	}
+	result, err := ai.RedactBystanders(ctx, ai.NewGeminiImageClient(genaiClient), nil, data, contentType, ai.RedactBlur)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("bystander redaction failed: %w", err)
+	}
+	if len(result.Regions) == 0 {
+		return data, contentType, nil, nil
+	}
+	return result.ImageData, result.MIMEType, redactRegions(result.Regions), nil
+}
+
+// updateItemRedactionError marks an item failed because its bystanders could
+// not be detected, so it is never shown as a redacted result.
+func updateItemRedactionError(ctx context.Context, event EnhanceEvent, preset ai.EnhancementPreset, redactErr error) {
+	recordItemError(ctx, event, store.EnhancementItem{
+		Key:            event.Key,
+		Filename:       filepath.Base(event.Key),
+		OriginalKey:    event.Key,
+		Phase:          ai.PhaseError,
+		Error:          redactErr.Error(),
+		Preset:         string(preset),
+		RedactionError: redactErr.Error(),
+	})
+}
+
+// redactEdit describes a redaction for the provenance record (DDR-140).
+func redactEdit(mode ai.RedactMode, regions int) media.ProvenanceEdit {
+	if mode == ai.RedactInpaint {
+		return media.ProvenanceEdit{Description: fmt.Sprintf("%d bystander faces or plates removed with Imagen", regions), AI: true}
+	}
+	return media.ProvenanceEdit{Description: fmt.Sprintf("%d bystander faces or plates blurred", regions)}
+}
+
+func redactRegions(regions []ai.RedactedRegion) []store.RedactRegion {
+	out := make([]store.RedactRegion, 0, len(regions))
+	for _, r := range regions {
+		out = append(out, store.RedactRegion{
+			Kind: r.Kind, Label: r.Label,
+			X: r.Rect.Min.X, Y: r.Rect.Min.Y, W: r.Rect.Dx(), H: r.Rect.Dy(),
+		})
+	}
+	return out
+}
+
+// handleMediaRedact hides the bystanders in one photo and stores the result
+// as a copy under {sessionId}/redacted/{jobId}-{name} (DDR-185). A photo with no
+// bystanders completes with no regions and no copy.
+func handleMediaRedact(ctx context.Context, event RedactEvent) error {
+	jobStart := time.Now()
+	job := &store.RedactJob{ID: event.JobID, Status: "processing", Key: event.Key, Mode: event.Mode}
+	sessionStore.PutRedactJob(ctx, event.SessionID, job)
+
+	fail := func(msg string) error {
+		log.Error().Str("jobId", event.JobID).Str("error", msg).Msg("Redact job failed")
+		job.Status = "error"
+		job.Error = msg
+		sessionStore.PutRedactJob(ctx, event.SessionID, job)
+		return nil
+	}
+
+	mode, err := ai.ParseRedactMode(event.Mode)
+	if err != nil {
+		return fail(err.Error())
+	}
+
+	tmpPath, cleanup, err := s3util.DownloadToTempFile(ctx, s3Client, mediaBucket, event.Key)
+	if err != nil {
+		return fail(fmt.Sprintf("download photo: %v", err))
+	}
+	defer cleanup()
+	imageData, err := os.ReadFile(tmpPath)
+	if err != nil {
+		return fail(fmt.Sprintf("read photo: %v", err))
+	}
+	mime := "image/jpeg"
+	if m, ok := media.SupportedImageExtensions[strings.ToLower(filepath.Ext(event.Key))]; ok {
+		mime = m
+	}
+
+	genaiClient, err := ai.NewAIClient(ctx)
+	if err != nil {
+		return fail(fmt.Sprintf("create Gemini client: %v", err))
+	}
+	var imagenClient *ai.ImagenClient
+	if mode == ai.RedactInpaint {
+		imagenClient = newImagenClient(ctx)
+	}
+	result, err := ai.RedactBystanders(ctx, ai.NewGeminiImageClient(genaiClient), imagenClient, imageData, mime, mode)
+	if err != nil {
+		return fail(err.Error())
+	}
+	job.Applied = string(result.Mode)
+	job.Regions = redactRegions(result.Regions)
+
+	if len(result.Regions) > 0 {
+		if err := uploadRedacted(ctx, event, job, result); err != nil {
+			return fail(err.Error())
+		}
+	}
+
+	job.Status = "complete"
+	sessionStore.PutRedactJob(ctx, event.SessionID, job)
+	log.Info().Str("jobId", event.JobID).Int("regions", len(job.Regions)).Str("mode", job.Applied).Dur("duration", time.Since(jobStart)).Msg("Redact job complete")
+	return nil
+}
+
+// uploadRedacted stores the redacted copy and its thumbnail, filling in the
+// job's keys.
+func uploadRedacted(ctx context.Context, event RedactEvent, job *store.RedactJob, result *ai.RedactResult) error {
+	base := strings.TrimSuffix(filepath.Base(event.Key), filepath.Ext(event.Key))
+	data := stampProvenance(ctx, event.SessionID, result.ImageData, redactEdit(result.Mode, len(result.Regions)))
+	contentType := result.MIMEType
+
+	enc, err := sessionKeys.ForSession(ctx, event.SessionID) // DDR-164
+	if err != nil {
+		return fmt.Errorf("encryption: %v", err)
+	}
+	// The job ID keeps a redaction of an original, of its enhanced copy or
+	// in another mode from replacing an earlier job's copy.
+	name := event.JobID + "-" + base
+	key := fmt.Sprintf("%s/redacted/%s.jpg", event.SessionID, name)
+	if contentType == "image/png" {
+		key = fmt.Sprintf("%s/redacted/%s.png", event.SessionID, name)
+	}
+	_, err = s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
+		Bucket: &mediaBucket, Key: &key,
+		Body: bytes.NewReader(data), ContentType: &contentType,
+		Metadata: map[string]string{"source-key": event.Key},
+		Tagging:  s3util.ProjectTagging(),
+	}))
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("Failed to upload redacted photo")
+		return fmt.Errorf("failed to store redacted photo")
+	}
+	job.RedactedKey = key
+
+	thumbKey := fmt.Sprintf("%s/thumbnails/redacted-%s.jpg", event.SessionID, name)
+	thumbContentType := "image/jpeg"
+	thumbData, _, err := s3util.GenerateThumbnailFromBytes(data, contentType, thumbnailMaxDimension)
+	if err == nil {
+		_, err = s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
+			Bucket: &mediaBucket, Key: &thumbKey,
+			Body: bytes.NewReader(thumbData), ContentType: &thumbContentType,
+			Tagging: s3util.ProjectTagging(),
+		}))
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("key", thumbKey).Msg("Failed to create redacted thumbnail")
+	} else {
+		job.RedactedThumbKey = thumbKey
+	}
+	return nil
+}
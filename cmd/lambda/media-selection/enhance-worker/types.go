@@ -1,25 +1,54 @@
 package main
 
-// EnhanceEvent is the input payload from Step Functions or async invocation.
-// For Step Functions (initial enhancement): type is empty, key + itemIndex are set.
-// For async feedback (DDR-053): type is "enhancement-feedback", key + feedback are set.
-type EnhanceEvent struct {
-	Type      string `json:"type,omitempty"`
+import (
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/sfnevents"
+)
+
+type (
+	EnhanceEvent  = sfnevents.EnhanceEvent
+	EnhanceResult = sfnevents.EnhanceResult
+)
+
+// MoodVariantEvent is the async payload from the API Lambda asking for
+// stylized variants of a cover image (DDR-102). It is not part of any state
+// machine, so it lives here rather than in sfnevents.
+type MoodVariantEvent struct {
+	Type      string   `json:"type"`
+	SessionID string   `json:"sessionId"`
+	JobID     string   `json:"jobId"`
+	SourceKey string   `json:"sourceKey"`
+	Styles    []string `json:"styles"`
+	// Owner holds the daily budget a unit per style was reserved from.
+	// Empty for jobs started before failed styles were refunded.
+	Owner string `json:"owner"`
+}
+
+// CropSuggestionEvent is the async payload from the API Lambda asking for
+// subject-aware crop suggestions for a set of photos (DDR-130).
+type CropSuggestionEvent struct {
+	Type      string   `json:"type"`
+	SessionID string   `json:"sessionId"`
+	JobID     string   `json:"jobId"`
+	Keys      []string `json:"keys"`
+}
+
+// AdjustEvent is the async payload from the API Lambda asking for local
+// adjustments to an enhanced photo, saved as a new version (DDR-183).
+type AdjustEvent struct {
+	Type        string            `json:"type"`
+	SessionID   string            `json:"sessionId"`
+	JobID       string            `json:"jobId"`
+	Key         string            `json:"key"`
+	Adjustments media.Adjustments `json:"adjustments"`
+}
+
+// RedactEvent is the async payload from the API Lambda asking for the
+// bystanders in one photo to be hidden (DDR-185).
+type RedactEvent struct {
+	Type      string `json:"type"`
 	SessionID string `json:"sessionId"`
 	JobID     string `json:"jobId"`
 	Key       string `json:"key"`
-	ItemIndex int    `json:"itemIndex"`
-	Bucket    string `json:"bucket,omitempty"`
-	Feedback  string `json:"feedback,omitempty"` // DDR-053: enhancement feedback text
-}
-
-// EnhanceResult is the output returned to Step Functions.
-type EnhanceResult struct {
-	OriginalKey      string `json:"originalKey"`
-	EnhancedKey      string `json:"enhancedKey"`
-	EnhancedThumbKey string `json:"enhancedThumbKey"`
-	Phase            string `json:"phase"`
-	Phase1Text       string `json:"phase1Text,omitempty"`
-	ImagenEdits      int    `json:"imagenEdits"`
-	Error            string `json:"error,omitempty"`
+	Mode      string `json:"mode"`
 }
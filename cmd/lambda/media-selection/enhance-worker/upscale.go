@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// upscaleResult enlarges the pipeline's result in place when the original
+// photo (width x height) has a long edge under the job's threshold
+// (DDR-184). Best-effort — without Imagen, or when the call fails, the
+// result keeps its size and nil is returned.
+func upscaleResult(ctx context.Context, imagenClient *ai.ImagenClient, state *ai.EnhancementState, width, height, below int) *store.UpscaleInfo {
+	if below == 0 || width <= 0 || height <= 0 || max(width, height) >= below {
+		return nil
+	}
+	if imagenClient == nil {
+		log.Info().Int("upscaleBelow", below).Msg("Imagen unavailable — skipping upscale")
+		return nil
+	}
+	up, err := imagenClient.UpscaleToLongEdge(ctx, state.CurrentData, below)
+	if err != nil {
+		log.Warn().Err(err).Msg("Upscale failed, keeping the enhanced photo's size")
+		return nil
+	}
+	if up == nil {
+		return nil
+	}
+	state.CurrentData, state.CurrentMIME = up.ImageData, up.MIMEType
+
+	// The enhanced photo's own size, not the original's: Gemini may have
+	// returned it at a different resolution.
+	log.Info().
+		Int("factor", up.Factor).
+		Int("beforeWidth", up.BeforeWidth).
+		Int("beforeHeight", up.BeforeHeight).
+		Int("afterWidth", up.AfterWidth).
+		Int("afterHeight", up.AfterHeight).
+		Msg("Enhanced photo upscaled")
+	return &store.UpscaleInfo{
+		Factor:       up.Factor,
+		BeforeWidth:  up.BeforeWidth,
+		BeforeHeight: up.BeforeHeight,
+		AfterWidth:   up.AfterWidth,
+		AfterHeight:  up.AfterHeight,
+	}
+}
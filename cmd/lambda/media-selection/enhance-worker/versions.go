@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/brand"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// loadItemSource finds the job item for key (original or enhanced key) and
+// downloads the image a follow-up edit should start from.
+func loadItemSource(ctx context.Context, job *store.EnhancementJob, key string) (int, store.EnhancementItem, []byte, string, error) {
+	targetIdx := -1
+	for i, item := range job.Items {
+		if item.Key == key || item.EnhancedKey == key {
+			targetIdx = i
+			break
+		}
+	}
+	if targetIdx == -1 {
+		return 0, store.EnhancementItem{}, nil, "", fmt.Errorf("item %s not found in enhancement job", key)
+	}
+	item := job.Items[targetIdx]
+
+	// DDR-133: edit the unwatermarked copy so the watermark is not reworked.
+	sourceKey := item.CleanKey
+	if sourceKey == "" {
+		sourceKey = item.EnhancedKey
+	}
+	if sourceKey == "" {
+		sourceKey = item.Key
+	}
+
+	tmpPath, cleanup, err := s3util.DownloadToTempFile(ctx, s3Client, mediaBucket, sourceKey)
+	if err != nil {
+		return 0, item, nil, "", fmt.Errorf("download %s: %w", sourceKey, err)
+	}
+	defer cleanup()
+
+	data, err := os.ReadFile(tmpPath)
+	if err != nil {
+		return 0, item, nil, "", fmt.Errorf("read %s: %w", sourceKey, err)
+	}
+
+	mime := "image/jpeg"
+	if m, ok := media.SupportedImageExtensions[strings.ToLower(filepath.Ext(sourceKey))]; ok {
+		mime = m
+	}
+	return targetIdx, item, data, mime, nil
+}
+
+// uploadVersion stores data as the item's next version (DDR-181): redacted
+// again for a privacy item (DDR-185), watermarked when the job asks for it,
+// stamped with provenance and given a thumbnail. The caller adds the
+// returned version to the item and saves it.
+func uploadVersion(ctx context.Context, job *store.EnhancementJob, item store.EnhancementItem, data []byte, contentType string, edits ...media.ProvenanceEdit) (store.EnhancementVersion, error) {
+	sessionID := job.SessionID
+	var redactions []store.RedactRegion
+	if ai.EnhancementPreset(item.Preset) == ai.PresetPrivacy {
+		var err error
+		data, contentType, redactions, err = redactVersion(ctx, data, contentType)
+		if err != nil {
+			return store.EnhancementVersion{}, err
+		}
+		if len(redactions) > 0 {
+			edits = append(edits, redactEdit(ai.RedactBlur, len(redactions)))
+		}
+	}
+	enc, err := sessionKeys.ForSession(ctx, sessionID) // DDR-164
+	if err != nil {
+		return store.EnhancementVersion{}, fmt.Errorf("resolve session encryption: %w", err)
+	}
+
+	version := item.NextVersion()
+	enhancedKey := enhancedKeyFor(sessionID, item.Key, version)
+	cleanKey := ""
+	if job.Watermark {
+		if overlay := brand.OwnerWatermark(ctx, sessionStore, s3Client, brandAssetsBucket, sessionID); overlay != nil {
+			stamped, key, err := watermarkEnhanced(ctx, *overlay, mediaBucket, sessionID, item.Key, version, data, contentType)
+			if err != nil {
+				log.Warn().Err(err).Msg("Watermark failed, storing the new version without it")
+			} else {
+				data, contentType, cleanKey = stamped, "image/jpeg", key
+				edits = append(edits, media.EditWatermark)
+			}
+		}
+	}
+	data = stampProvenance(ctx, sessionID, data, edits...) // DDR-140
+	if _, err := s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
+		Bucket: &mediaBucket, Key: &enhancedKey,
+		Body: bytes.NewReader(data), ContentType: &contentType,
+		Tagging: s3util.ProjectTagging(),
+	})); err != nil {
+		return store.EnhancementVersion{}, fmt.Errorf("upload %s: %w", enhancedKey, err)
+	}
+
+	// Generate and upload thumbnail.
+	thumbKey := enhancedThumbKeyFor(sessionID, item.Key, version)
+	thumbData, _, thumbErr := s3util.GenerateThumbnailFromBytes(data, contentType, thumbnailMaxDimension)
+	if thumbErr == nil {
+		thumbContentType := "image/jpeg"
+		s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
+			Bucket: &mediaBucket, Key: &thumbKey,
+			Body: bytes.NewReader(thumbData), ContentType: &thumbContentType,
+			Tagging: s3util.ProjectTagging(),
+		}))
+	}
+
+	return store.EnhancementVersion{
+		Version:            version,
+		EnhancedKey:        enhancedKey,
+		EnhancedThumbKey:   thumbKey,
+		CleanKey:           cleanKey,
+		LegibilityWarnings: checkLegibility(item.Key, data),
+		Redactions:         redactions,
+		CreatedAt:          time.Now().Unix(),
+	}, nil
+}
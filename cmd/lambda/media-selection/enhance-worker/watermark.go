@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/fpang/ai-social-media-helper/internal/brand"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
+)
+
+// --- Watermarks (DDR-133) ---
+
+// jobWatermark returns the overlay to stamp on a job's enhanced photos: the
+// session owner's watermark when the job was started with watermark on, nil
+// otherwise.
+func jobWatermark(ctx context.Context, sessionID, jobID string) *media.Overlay {
+	job, err := sessionStore.GetEnhancementJob(ctx, sessionID, jobID)
+	if err != nil || job == nil || !job.Watermark {
+		return nil
+	}
+	return brand.OwnerWatermark(ctx, sessionStore, s3Client, brandAssetsBucket, sessionID) // DDR-149
+}
+
+// watermarkEnhanced stamps overlay on an enhanced photo. The unwatermarked
+// photo is kept at cleanKeyFor(key, version) so feedback rounds edit the photo rather
+// than the watermark. Returns the JPEG to store as the enhanced copy and the
+// clean copy's key.
+func watermarkEnhanced(ctx context.Context, overlay media.Overlay, bucket, sessionID, key string, version int, data []byte, contentType string) ([]byte, string, error) {
+	stamped, err := media.ApplyOverlay(data, overlay, brand.WatermarkQuality)
+	if err != nil {
+		return nil, "", err
+	}
+	cleanKey := cleanKeyFor(sessionID, key, version)
+	enc, err := sessionKeys.ForSession(ctx, sessionID) // DDR-164
+	if err != nil {
+		return nil, "", fmt.Errorf("upload unwatermarked copy: %w", err)
+	}
+	if _, err := s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
+		Bucket:      &bucket,
+		Key:         &cleanKey,
+		Body:        bytes.NewReader(data),
+		ContentType: &contentType,
+		Tagging:     s3util.ProjectTagging(),
+	})); err != nil {
+		return nil, "", fmt.Errorf("upload unwatermarked copy: %w", err)
+	}
+	return stamped, cleanKey, nil
+}
+
+// cleanKeyFor returns the S3 key of the unwatermarked copy of a version of
+// the enhanced copy of key: a clean folder beside the enhanced copy.
+func cleanKeyFor(sessionID, key string, version int) string {
+	return brand.CleanKey(enhancedKeyFor(sessionID, key, version))
+}
@@ -1,13 +1,21 @@
 // Package main provides a Lambda entry point for the publish pipeline (DDR-053).
 //
-// This Lambda handles the 3 steps of the Publish Pipeline Step Function (DDR-052):
-//   - publish-create-containers: Create Instagram media containers
-//   - publish-check-video: Poll Instagram video container processing status
-//   - publish-finalize: Create carousel (if multi-item) and publish to Instagram
+// This Lambda handles the 5 steps of the Publish Pipeline Step Function (DDR-052):
+//   - publish-prepare: Validate items against Instagram's media requirements and convert fixable ones (DDR-129), after applying a suggested carousel order (DDR-179)
+//   - publish-create-containers: Create media containers
+//   - publish-check-video: Poll video container processing status
+//   - publish-check-approval: Poll the approval gate, when required (DDR-121)
+//   - publish-finalize: Create carousel (if multi-item) and publish, then post
+//     the hashtags as the first comment when the job asks for it (DDR-163)
 //
-// Container: Light (Dockerfile.light — no ffmpeg, no Gemini needed)
-// Memory: 256 MB
-// Timeout: 5 minutes
+// Jobs publish to Instagram, or to a Facebook Page when the event's platform
+// is "facebook" (DDR-147), or to a Mastodon-compatible server such as
+// Pixelfed when it is "mastodon" (DDR-151). A job with several targets
+// cross-posts to each of them in turn and reports partial success (DDR-153).
+//
+// Container: Heavy (Dockerfile.heavy — ffmpeg for video conversion, DDR-129)
+// Memory: 2 GB
+// Timeout: 15 minutes
 package main
 
 import (
@@ -17,26 +25,37 @@ import (
 	"strings"
 	"time"
 
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/rs/zerolog/log"
 
 	"github.com/fpang/ai-social-media-helper/internal/instagram"
 	"github.com/fpang/ai-social-media-helper/internal/bootstrap"
+	"github.com/fpang/ai-social-media-helper/internal/crosspost"
+	"github.com/fpang/ai-social-media-helper/internal/facebook"
 	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/mastodon"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
 	"github.com/fpang/ai-social-media-helper/internal/rag"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
+	"github.com/fpang/ai-social-media-helper/internal/sfnevents"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 )
 
 var coldStart = true
 
 var (
-	presigner    *s3.PresignClient
-	mediaBucket  string
-	sessionStore *store.DynamoStore
-	igClient     *instagram.Client
-	ebClient     *eventbridge.Client
+	s3Client          *s3.Client
+	presigner         *s3.PresignClient
+	mediaBucket       string
+	brandAssetsBucket string              // DDR-149: optional
+	sessionKeys       *s3util.SessionKeys // DDR-164
+	sessionStore      *store.DynamoStore
+	igClient          *instagram.Client
+	fbClient          *facebook.Client // DDR-147
+	mastodonClient    *mastodon.Client // DDR-151
+	ebClient          *eventbridge.Client
+	tiering           s3util.TieringPolicy
 )
 
 func init() {
@@ -45,60 +64,45 @@ func init() {
 
 	awsClients := bootstrap.InitAWS()
 	s3s := bootstrap.InitS3(awsClients.Config, "MEDIA_BUCKET_NAME")
+	s3Client = s3s.Client
 	presigner = s3s.Presigner
 	mediaBucket = s3s.Bucket
+	sessionKeys = s3s.Keys
+	brandAssetsBucket = os.Getenv("BRAND_ASSETS_BUCKET_NAME")
 	sessionStore = bootstrap.InitDynamo(awsClients.Config, "DYNAMO_TABLE_NAME")
 	igClient = bootstrap.LoadInstagramCreds(awsClients.SSM)
+	fbClient = bootstrap.LoadFacebookCreds(awsClients.SSM)
+	mastodonClient = bootstrap.LoadMastodonCreds(awsClients.SSM)
 	ebClient = eventbridge.NewFromConfig(awsClients.Config)
+	tiering = s3util.TieringPolicyFromEnv()
 
 	bootstrap.StartupLog("publish-lambda", initStart).
 		S3Bucket("mediaBucket", mediaBucket).
+		S3Bucket("brandAssetsBucket", brandAssetsBucket).
 		DynamoTable("sessions", os.Getenv("DYNAMO_TABLE_NAME")).
 		SSMParam("instagramToken", logging.EnvOrDefault("SSM_INSTAGRAM_TOKEN_PARAM", "/ai-social-media/prod/instagram-access-token")).
 		SSMParam("instagramUserId", logging.EnvOrDefault("SSM_INSTAGRAM_USER_ID_PARAM", "/ai-social-media/prod/instagram-user-id")).
 		Feature("instagram", igClient != nil).
+		Feature("instagramMock", igClient != nil && igClient.IsMock()).
+		Feature("facebook", fbClient != nil).
+		Feature("mastodon", mastodonClient != nil).
+		Feature("storageTiering", tiering.Enabled()).
 		Log()
 }
 
 func main() {
-	lambda.Start(handler)
+	bootstrap.Start(handler)
 }
 
 // --- Event and Result types ---
 
-type PublishEvent struct {
-	Type              string   `json:"type"`
-	SessionID         string   `json:"sessionId"`
-	JobID             string   `json:"jobId"`
-	GroupID           string   `json:"groupId,omitempty"`
-	Keys              []string `json:"keys,omitempty"`
-	Caption           string   `json:"caption,omitempty"`
-	ContainerIDs      []string `json:"containerIDs,omitempty"`
-	VideoContainerIDs []string `json:"videoContainerIDs,omitempty"`
-	IsCarousel        bool     `json:"isCarousel,omitempty"`
-}
-
-type PublishCreateContainersResult struct {
-	SessionID         string   `json:"sessionId"`
-	JobID             string   `json:"jobId"`
-	GroupID           string   `json:"groupId"`
-	Caption           string   `json:"caption"`
-	ContainerIDs      []string `json:"containerIDs"`
-	VideoContainerIDs []string `json:"videoContainerIDs"`
-	HasVideos         bool     `json:"hasVideos"`
-	IsCarousel        bool     `json:"isCarousel"`
-}
-
-type PublishCheckVideoResult struct {
-	SessionID         string   `json:"sessionId"`
-	JobID             string   `json:"jobId"`
-	GroupID           string   `json:"groupId"`
-	Caption           string   `json:"caption"`
-	ContainerIDs      []string `json:"containerIDs"`
-	VideoContainerIDs []string `json:"videoContainerIDs"`
-	AllFinished       bool     `json:"allFinished"`
-	IsCarousel        bool     `json:"isCarousel"`
-}
+type (
+	PublishEvent                  = sfnevents.PublishEvent
+	PublishPrepareResult          = sfnevents.PublishPrepareResult
+	PublishCreateContainersResult = sfnevents.PublishCreateContainersResult
+	PublishCheckVideoResult       = sfnevents.PublishCheckVideoResult
+	PublishCheckApprovalResult    = sfnevents.PublishCheckApprovalResult
+)
 
 func handler(ctx context.Context, event PublishEvent) (interface{}, error) {
 	if coldStart {
@@ -109,13 +113,21 @@ func handler(ctx context.Context, event PublishEvent) (interface{}, error) {
 		Str("type", event.Type).
 		Str("sessionId", event.SessionID).
 		Str("jobId", event.JobID).
+		Str("platform", event.Platform).
 		Msg("Publish Lambda invoked")
 
+	// DDR-118: collect per-job telemetry; PutPublishJob records it.
+	ctx, _ = metrics.WithCollector(ctx)
+
 	switch event.Type {
+	case "publish-prepare":
+		return handlePublishPrepare(ctx, event)
 	case "publish-create-containers":
 		return handlePublishCreateContainers(ctx, event)
 	case "publish-check-video":
 		return handlePublishCheckVideo(ctx, event)
+	case "publish-check-approval":
+		return handlePublishCheckApproval(ctx, event)
 	case "publish-finalize":
 		return nil, handlePublishFinalize(ctx, event)
 	default:
@@ -123,21 +135,69 @@ func handler(ctx context.Context, event PublishEvent) (interface{}, error) {
 	}
 }
 
-func handlePublishCreateContainers(ctx context.Context, event PublishEvent) (*PublishCreateContainersResult, error) {
-	if igClient == nil {
-		setPublishError(ctx, event, "Instagram client not configured")
-		return nil, fmt.Errorf("Instagram client not configured")
+// trackInsights starts collecting the post's insights (DDR-146). Simulated
+// posts are tracked too, so dry runs exercise the poller against the mock.
+// Best-effort: an untracked post only misses its insights.
+func trackInsights(ctx context.Context, event PublishEvent, instagramPostID string) {
+	owner, err := sessionStore.SessionOwner(ctx, event.SessionID)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to resolve session owner, tracking insights without a user")
+	}
+	post := &store.TrackedPost{
+		MediaID:   instagramPostID,
+		OwnerSub:  owner,
+		SessionID: event.SessionID,
+		JobID:     event.JobID,
 	}
+	if err := sessionStore.PutTrackedPost(ctx, post); err != nil {
+		log.Warn().Err(err).Str("instagramPostId", instagramPostID).Msg("Failed to start insights tracking")
+	}
+}
 
+// postFirstComment posts the hashtags a job moved out of its Instagram
+// caption as the post's first comment (DDR-163). The post is already live,
+// so a failure is returned for the job record instead of failing the job.
+func postFirstComment(ctx context.Context, event PublishEvent, postID, message string) (commentID, errMsg string) {
+	client := instagramFor(event)
+	if client == nil {
+		return "", "Instagram client not configured"
+	}
+	commentID, err := client.Comment(ctx, postID, message)
+	if err != nil {
+		log.Warn().Err(err).Str("postId", postID).Msg("Failed to post first comment")
+		return "", fmt.Sprintf("failed to post hashtags as the first comment: %v", err)
+	}
+	return commentID, ""
+}
+
+// postOptions returns the post-level settings of a job: its location tag
+// (DDR-138) and collaborator invites (DDR-141).
+func postOptions(event PublishEvent) instagram.PostOptions {
+	return instagram.PostOptions{LocationID: event.LocationID, Collaborators: event.Collaborators}
+}
+
+// dryRunClient simulates Instagram for dry-run jobs (DDR-139).
+var dryRunClient = instagram.NewMockClient()
+
+// instagramFor returns the client a job publishes with: the simulating client
+// for a dry run, otherwise the deployment's client (itself a mock under
+// INSTAGRAM_MODE=mock), which is nil when credentials are missing.
+func instagramFor(event PublishEvent) *instagram.Client {
+	if event.DryRun {
+		return dryRunClient
+	}
+	return igClient
+}
+
+func handlePublishCreateContainers(ctx context.Context, event PublishEvent) (*PublishCreateContainersResult, error) {
+	targets := crosspost.Targets(event)
+	total := len(event.Keys) * len(targets)
 	sessionStore.PutPublishJob(ctx, event.SessionID, &store.PublishJob{
 		ID: event.JobID, GroupID: event.GroupID, Status: "creating_containers",
-		Phase: "creating_containers", TotalItems: len(event.Keys),
+		Phase: "creating_containers", TotalItems: total, Platforms: crosspost.Statuses(targets, nil),
 	})
 
-	containerIDs := make([]string, 0, len(event.Keys))
-	videoContainerIDs := make([]string, 0)
-	isCarousel := len(event.Keys) > 1
-
+	mediaURLs := make([]string, len(event.Keys))
 	for i, key := range event.Keys {
 		presignResult, err := presigner.PresignGetObject(ctx, &s3.GetObjectInput{
 			Bucket: &mediaBucket, Key: &key,
@@ -146,148 +206,276 @@ func handlePublishCreateContainers(ctx context.Context, event PublishEvent) (*Pu
 			setPublishError(ctx, event, fmt.Sprintf("failed to generate presigned URL for %s", key))
 			return nil, fmt.Errorf("presign %s: %w", key, err)
 		}
+		mediaURLs[i] = presignResult.URL
+	}
 
-		mediaURL := presignResult.URL
-		isVideo := isVideoKey(key)
+	isCarousel := len(event.Keys) > 1
+	created := make([]string, 0, total)
+	for ti := range targets {
+		t := &targets[ti]
+		tev := crosspost.TargetEvent(event, *t)
+		pub := publisherFor(tev)
+		if pub == nil {
+			t.Error = platformName(tev) + " client not configured"
+			log.Error().Str("platform", platformName(tev)).Msg("Publisher not configured, skipping platform")
+			continue
+		}
 
-		var containerID string
-		if isCarousel {
-			if isVideo {
-				containerID, err = igClient.CreateVideoContainer(ctx, mediaURL, true)
-			} else {
-				containerID, err = igClient.CreateImageContainer(ctx, mediaURL, true)
+		t.ContainerIDs, t.VideoContainerIDs = make([]string, 0, len(event.Keys)), nil
+		for i, key := range event.Keys {
+			isVideo := isVideoKey(key)
+			containerID, err := pub.CreateItem(ctx, tev, i, mediaURLs[i], isVideo, isCarousel)
+			if err != nil {
+				log.Warn().Err(err).Str("platform", platformName(tev)).Int("item", i+1).Msg("Container creation failed, skipping platform")
+				t.Error = fmt.Sprintf("failed to create container for item %d: %v", i+1, err)
+				t.ContainerIDs, t.VideoContainerIDs = nil, nil
+				break
 			}
-		} else {
+
+			log.Debug().Str("containerId", containerID).Str("platform", platformName(tev)).Int("item", i+1).Str("key", key).Msg("Container created")
+			t.ContainerIDs = append(t.ContainerIDs, containerID)
 			if isVideo {
-				containerID, err = igClient.CreateSingleReelPost(ctx, mediaURL, event.Caption)
-			} else {
-				containerID, err = igClient.CreateSingleImagePost(ctx, mediaURL, event.Caption)
+				t.VideoContainerIDs = append(t.VideoContainerIDs, containerID)
 			}
+			created = append(created, containerID)
+
+			sessionStore.PutPublishJob(ctx, event.SessionID, &store.PublishJob{
+				ID: event.JobID, GroupID: event.GroupID, Status: "creating_containers",
+				Phase: "creating_containers", TotalItems: total,
+				CompletedItems: len(created), ContainerIDs: created,
+				Platforms: crosspost.Statuses(targets, nil),
+			})
 		}
-		if err != nil {
-			setPublishError(ctx, event, fmt.Sprintf("failed to create container for item %d: %v", i+1, err))
-			return nil, fmt.Errorf("create container %d: %w", i+1, err)
-		}
-
-		log.Debug().Str("containerId", containerID).Int("item", i+1).Str("key", key).Msg("Container created")
-		containerIDs = append(containerIDs, containerID)
-		if isVideo {
-			videoContainerIDs = append(videoContainerIDs, containerID)
-		}
-
-		sessionStore.PutPublishJob(ctx, event.SessionID, &store.PublishJob{
-			ID: event.JobID, GroupID: event.GroupID, Status: "creating_containers",
-			Phase: "creating_containers", TotalItems: len(event.Keys),
-			CompletedItems: i + 1, ContainerIDs: containerIDs,
-		})
+	}
+	if crosspost.Live(targets) == 0 {
+		failTargets(ctx, event, targets)
+		return nil, fmt.Errorf("create containers: %s", crosspost.Error(targets))
 	}
 
-	log.Info().Int("containers", len(containerIDs)).Int("videoContainers", len(videoContainerIDs)).Msg("All containers created")
+	containerIDs, videoContainerIDs := crosspost.Containers(targets)
+	log.Info().Int("containers", len(containerIDs)).Int("videoContainers", len(videoContainerIDs)).
+		Int("platforms", crosspost.Live(targets)).Msg("All containers created")
 
 	return &PublishCreateContainersResult{
 		SessionID:         event.SessionID,
 		JobID:             event.JobID,
 		GroupID:           event.GroupID,
+		Keys:              event.Keys,
 		Caption:           event.Caption,
+		LocationID:        event.LocationID,
+		Collaborators:     event.Collaborators,
 		ContainerIDs:      containerIDs,
 		VideoContainerIDs: videoContainerIDs,
 		HasVideos:         len(videoContainerIDs) > 0,
 		IsCarousel:        isCarousel,
+		RequireApproval:   event.RequireApproval,
+		DryRun:            event.DryRun,
+		Platform:          event.Platform,
+		Targets:           targets,
 	}, nil
 }
 
 func handlePublishCheckVideo(ctx context.Context, event PublishEvent) (*PublishCheckVideoResult, error) {
-	if igClient == nil {
-		return nil, fmt.Errorf("Instagram client not configured")
-	}
-
+	targets := crosspost.Targets(event)
+	containerIDs, _ := crosspost.Containers(targets)
 	sessionStore.PutPublishJob(ctx, event.SessionID, &store.PublishJob{
 		ID: event.JobID, GroupID: event.GroupID, Status: "processing_videos",
-		Phase: "processing_videos", TotalItems: len(event.ContainerIDs),
-		CompletedItems: len(event.ContainerIDs), ContainerIDs: event.ContainerIDs,
+		Phase: "processing_videos", TotalItems: len(containerIDs),
+		CompletedItems: len(containerIDs), ContainerIDs: containerIDs,
+		Platforms: crosspost.Statuses(targets, nil),
 	})
 
 	allFinished := true
-	for _, vid := range event.VideoContainerIDs {
-		status, err := igClient.ContainerStatus(ctx, vid)
-		if err != nil {
-			log.Warn().Err(err).Str("containerId", vid).Msg("Failed to check container status")
-			return nil, fmt.Errorf("check container %s: %w", vid, err)
+	for ti := range targets {
+		t := &targets[ti]
+		if t.Error != "" || len(t.VideoContainerIDs) == 0 {
+			continue
 		}
-		log.Debug().Str("containerId", vid).Str("status", status).Msg("Video container status")
-		if status == "ERROR" {
-			setPublishError(ctx, event, fmt.Sprintf("video processing failed for container %s", vid))
-			return nil, fmt.Errorf("video processing failed: container %s", vid)
+		tev := crosspost.TargetEvent(event, *t)
+		pub := publisherFor(tev)
+		if pub == nil {
+			return nil, fmt.Errorf("%s client not configured", platformName(tev))
 		}
-		if status != "FINISHED" {
-			allFinished = false
+		for _, vid := range t.VideoContainerIDs {
+			status, err := pub.ContainerStatus(ctx, vid)
+			if err != nil {
+				log.Warn().Err(err).Str("containerId", vid).Msg("Failed to check container status")
+				return nil, fmt.Errorf("check container %s: %w", vid, err)
+			}
+			log.Debug().Str("containerId", vid).Str("platform", platformName(tev)).Str("status", status).Msg("Video container status")
+			if status == "ERROR" {
+				log.Warn().Str("containerId", vid).Str("platform", platformName(tev)).Msg("Video processing failed, skipping platform")
+				t.Error = fmt.Sprintf("video processing failed for container %s", vid)
+				break
+			}
+			if status != "FINISHED" {
+				allFinished = false
+			}
 		}
 	}
+	if crosspost.Live(targets) == 0 {
+		failTargets(ctx, event, targets)
+		return nil, fmt.Errorf("video processing failed: %s", crosspost.Error(targets))
+	}
 
-	log.Info().Bool("allFinished", allFinished).Int("videoCount", len(event.VideoContainerIDs)).Msg("Video status check complete")
+	containerIDs, videoContainerIDs := crosspost.Containers(targets)
+	log.Info().Bool("allFinished", allFinished).Int("videoCount", len(videoContainerIDs)).Msg("Video status check complete")
 
 	return &PublishCheckVideoResult{
 		SessionID:         event.SessionID,
 		JobID:             event.JobID,
 		GroupID:           event.GroupID,
+		Keys:              event.Keys,
 		Caption:           event.Caption,
-		ContainerIDs:      event.ContainerIDs,
-		VideoContainerIDs: event.VideoContainerIDs,
+		LocationID:        event.LocationID,
+		Collaborators:     event.Collaborators,
+		ContainerIDs:      containerIDs,
+		VideoContainerIDs: videoContainerIDs,
 		AllFinished:       allFinished,
 		IsCarousel:        event.IsCarousel,
+		RequireApproval:   event.RequireApproval,
+		DryRun:            event.DryRun,
+		Platform:          event.Platform,
+		Targets:           targets,
 	}, nil
 }
 
-func handlePublishFinalize(ctx context.Context, event PublishEvent) error {
-	jobStart := time.Now()
-	if igClient == nil {
-		return setPublishError(ctx, event, "Instagram client not configured")
+// handlePublishCheckApproval reports the approval gate's state once the
+// containers are ready (DDR-121). While pending, the job shows
+// "awaiting_approval"; a rejection or an expired window ends the job here.
+func handlePublishCheckApproval(ctx context.Context, event PublishEvent) (*PublishCheckApprovalResult, error) {
+	targets := crosspost.Targets(event)
+	containerIDs, _ := crosspost.Containers(targets)
+	result := &PublishCheckApprovalResult{
+		SessionID:     event.SessionID,
+		JobID:         event.JobID,
+		GroupID:       event.GroupID,
+		Keys:          event.Keys,
+		Caption:       event.Caption,
+		LocationID:    event.LocationID,
+		Collaborators: event.Collaborators,
+		ContainerIDs:  containerIDs,
+		IsCarousel:    event.IsCarousel,
+		DryRun:        event.DryRun,
+		Platform:      event.Platform,
+		Targets:       targets,
+		Approval:      "closed",
 	}
 
-	var publishContainerID string
-	if event.IsCarousel {
+	approval, err := sessionStore.GetPublishApproval(ctx, event.SessionID, event.JobID)
+	if err != nil {
+		return nil, fmt.Errorf("read approval: %w", err)
+	}
+	if approval == nil {
+		setPublishError(ctx, event, "approval record not found")
+		return result, nil
+	}
+
+	switch {
+	case approval.Status == store.ApprovalApproved:
+		log.Info().Str("job", event.JobID).Str("decidedBy", approval.DecidedBy).Str("via", approval.Via).Msg("Publish approved")
+		result.Approval = "approved"
+	case approval.Status == store.ApprovalRejected:
+		log.Info().Str("job", event.JobID).Str("decidedBy", approval.DecidedBy).Str("via", approval.Via).Msg("Publish rejected")
+		sessionStore.PutPublishJob(ctx, event.SessionID, &store.PublishJob{
+			ID: event.JobID, GroupID: event.GroupID, Status: "rejected",
+			Phase: "rejected", TotalItems: len(containerIDs),
+			CompletedItems: len(containerIDs), ContainerIDs: containerIDs,
+			Platforms: crosspost.Statuses(targets, nil),
+			Error: "publishing was rejected by the approver",
+		})
+	case approval.Expired(time.Now()):
+		setPublishError(ctx, event, fmt.Sprintf("not approved within %s", store.PublishApprovalWindow))
+	default:
+		sessionStore.PutPublishJob(ctx, event.SessionID, &store.PublishJob{
+			ID: event.JobID, GroupID: event.GroupID, Status: "awaiting_approval",
+			Phase: "awaiting_approval", TotalItems: len(containerIDs),
+			CompletedItems: len(containerIDs), ContainerIDs: containerIDs,
+			Platforms: crosspost.Statuses(targets, nil),
+		})
+		result.Approval = "pending"
+		result.ApprovalWaitSeconds = int(approval.PollInterval(time.Now()).Seconds())
+	}
+	return result, nil
+}
+
+func handlePublishFinalize(ctx context.Context, event PublishEvent) error {
+	jobStart := time.Now()
+	targets := crosspost.Targets(event)
+	containerIDs, _ := crosspost.Containers(targets)
+	run := crosspost.NewRun(targets)
+	phase := func(status string) {
 		sessionStore.PutPublishJob(ctx, event.SessionID, &store.PublishJob{
-			ID: event.JobID, GroupID: event.GroupID, Status: "creating_carousel",
-			Phase: "creating_carousel", TotalItems: len(event.ContainerIDs),
-			CompletedItems: len(event.ContainerIDs), ContainerIDs: event.ContainerIDs,
+			ID: event.JobID, GroupID: event.GroupID, Status: status,
+			Phase: status, TotalItems: len(containerIDs),
+			CompletedItems: len(containerIDs), ContainerIDs: containerIDs,
+			Platforms: run.Statuses(),
 		})
+	}
 
-		var err error
-		publishContainerID, err = igClient.CreateCarouselContainer(ctx, event.ContainerIDs, event.Caption)
+	published := &store.PublishJob{
+		ID: event.JobID, GroupID: event.GroupID, Status: "published",
+		Phase: "published", TotalItems: len(containerIDs),
+		CompletedItems: len(containerIDs), ContainerIDs: containerIDs,
+	}
+	run.Publish(func(t sfnevents.PublishTarget) (string, bool, error) {
+		tev := crosspost.TargetEvent(event, t)
+		postID, mock, err := publishTarget(ctx, tev, phase)
 		if err != nil {
-			return setPublishError(ctx, event, fmt.Sprintf("failed to create carousel: %v", err))
-		}
-	} else {
-		if len(event.ContainerIDs) == 0 {
-			return setPublishError(ctx, event, "no container IDs provided")
+			log.Error().Err(err).Str("job", event.JobID).Str("platform", platformName(tev)).Msg("Publishing failed on platform")
+			return "", false, err
 		}
-		publishContainerID = event.ContainerIDs[0]
-	}
 
-	sessionStore.PutPublishJob(ctx, event.SessionID, &store.PublishJob{
-		ID: event.JobID, GroupID: event.GroupID, Status: "publishing",
-		Phase: "publishing", TotalItems: len(event.ContainerIDs),
-		CompletedItems: len(event.ContainerIDs), ContainerIDs: event.ContainerIDs,
+		switch crosspost.PlatformKey(t.Platform) {
+		case sfnevents.PlatformFacebook:
+			published.FacebookPostID = postID
+		case sfnevents.PlatformMastodon:
+			published.MastodonPostID = postID
+		default:
+			published.InstagramPostID = postID
+			trackInsights(ctx, event, postID)
+			if t.FirstComment != "" {
+				phase("commenting")
+				published.FirstCommentID, published.FirstCommentError = postFirstComment(ctx, tev, postID, t.FirstComment)
+			}
+		}
+		log.Info().Str("postId", postID).Str("platform", platformName(tev)).Bool("simulated", mock).Msg("Published to platform")
+		return postID, mock, nil
 	})
-
-	instagramPostID, err := igClient.Publish(ctx, publishContainerID)
-	if err != nil {
-		return setPublishError(ctx, event, fmt.Sprintf("publish failed: %v", err))
+	if len(run.Published) == 0 {
+		return failTargets(ctx, event, targets)
 	}
 
-	sessionStore.PutPublishJob(ctx, event.SessionID, &store.PublishJob{
-		ID: event.JobID, GroupID: event.GroupID, Status: "published",
-		Phase: "published", TotalItems: len(event.ContainerIDs),
-		CompletedItems: len(event.ContainerIDs), ContainerIDs: event.ContainerIDs,
-		InstagramPostID: instagramPostID,
-	})
+	// Some platforms may have failed while others published; the job
+	// reports both (DDR-153).
+	published.Status, published.Error = run.Status()
+	published.Phase = published.Status
+	published.Platforms = run.Statuses()
+	sessionStore.PutPublishJob(ctx, event.SessionID, published)
+
+	// A simulated post (DDR-139) teaches the RAG nothing and leaves the
+	// originals in use.
+	if run.Simulated {
+		log.Info().Strs("platforms", run.Published).Int("items", len(event.Keys)).Dur("duration", time.Since(jobStart)).Msg("Simulated publish finished")
+		return nil
+	}
 
 	// Emit publish.finalized to EventBridge — best effort
 	if ebClient != nil && len(event.Keys) > 0 {
+		owner, err := sessionStore.SessionOwner(ctx, event.SessionID)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to resolve session owner, recording decisions without a user")
+		}
+		ragUserID := rag.UserID(owner, event.SessionID)
 		metadata := map[string]string{
-			"platform":        "instagram",
-			"instagramPostId": instagramPostID,
-			"caption":         event.Caption,
+			"platform": run.Published[0],
+			"caption":  event.Caption,
+		}
+		for _, platform := range run.Published {
+			metadata[platform+"PostId"] = run.PostIDs[platform]
+		}
+		if len(run.Published) > 1 {
+			metadata["platforms"] = strings.Join(run.Published, ",")
 		}
 		batcher := rag.NewBatchEmitter(ebClient)
 		for _, key := range event.Keys {
@@ -296,7 +484,7 @@ func handlePublishFinalize(ctx context.Context, event PublishEvent) error {
 				SessionID:   event.SessionID,
 				JobID:       event.JobID,
 				Timestamp:   time.Now().UTC().Format(time.RFC3339),
-				UserID:      event.SessionID,
+				UserID:      ragUserID,
 				MediaKey:    key,
 				MediaType:   "Photo",
 				AIVerdict:   "published",
@@ -315,7 +503,12 @@ func handlePublishFinalize(ctx context.Context, event PublishEvent) error {
 		}
 	}
 
-	log.Info().Str("instagramPostId", instagramPostID).Int("items", len(event.ContainerIDs)).Dur("duration", time.Since(jobStart)).Msg("Published to Instagram")
+	// DDR-115: published originals rarely need hot access again.
+	if tiering.Enabled() {
+		archivePublishedOriginals(ctx, event.Keys)
+	}
+
+	log.Info().Strs("platforms", run.Published).Str("status", published.Status).Int("items", len(event.Keys)).Dur("duration", time.Since(jobStart)).Msg("Published")
 	return nil
 }
 
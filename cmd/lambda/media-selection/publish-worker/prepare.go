@@ -0,0 +1,389 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/brand"
+	"github.com/fpang/ai-social-media-helper/internal/instagram"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// --- Pre-publish validation and conversion (DDR-129) ---
+
+// handlePublishPrepare checks every item against Instagram's media
+// requirements before any container is created. Items that break a fixable
+// rule are converted — images re-encoded as JPEG under 8 MB and padded into
+// the feed aspect range, videos transcoded to H.264/AAC — and the converted
+// copy is published in place of the original. Items that cannot be fixed
+// (video duration) fail the job with a per-item error. With event.Watermark
+// the owner's watermark is stamped on every photo (DDR-133). Photos that pass
+// through unconverted have their metadata sanitized under the session's
+// policy (DDR-135); converted copies are re-encoded without any. Edited
+// photos carry a provenance record of their edits unless the owner turned
+// it off (DDR-140). A carousel order from the request (DDR-179) is applied
+// first, so every later step sees the items in their published order.
+func handlePublishPrepare(ctx context.Context, event PublishEvent) (*PublishPrepareResult, error) {
+	event = applyCarouselOrder(event)
+	result := &PublishPrepareResult{
+		SessionID:       event.SessionID,
+		JobID:           event.JobID,
+		GroupID:         event.GroupID,
+		Caption:         event.Caption,
+		LocationID:      event.LocationID,
+		UserTags:        event.UserTags,
+		Collaborators:   event.Collaborators,
+		RequireApproval: event.RequireApproval,
+		DryRun:          event.DryRun,
+		Platform:        event.Platform,
+		AltText:         event.AltText,
+		Targets:         event.Targets,
+	}
+
+	carousel := len(event.Keys) > 1
+	keys := make([]string, 0, len(event.Keys))
+	itemErrors := carouselTagErrors(event)
+	converted := 0
+	var overlay *media.Overlay
+	if event.Watermark {
+		overlay = brand.OwnerWatermark(ctx, sessionStore, s3Client, brandAssetsBucket, event.SessionID) // DDR-149
+	}
+	policy := media.SessionMetadataPolicy(ctx, sessionStore, event.SessionID) // DDR-135
+	provenance := media.OwnerWantsProvenance(ctx, sessionStore, event.SessionID)
+
+	keysToPrepare := event.Keys
+	if len(itemErrors) > 0 {
+		keysToPrepare = nil // the job fails below without converting anything
+	}
+	for i, key := range keysToPrepare {
+		sessionStore.PutPublishJob(ctx, event.SessionID, &store.PublishJob{
+			ID: event.JobID, GroupID: event.GroupID, Status: "preparing_media",
+			Phase: "preparing_media", TotalItems: len(event.Keys), CompletedItems: i,
+		})
+
+		// An enhanced copy stamped when it was made is not stamped twice.
+		itemOverlay := overlay
+		if overlay != nil && brand.Watermarked(ctx, s3Client, mediaBucket, key) {
+			itemOverlay = nil
+		}
+		publishKey, err := prepareItem(ctx, event, i, key, carousel, itemOverlay, policy, provenance)
+		if err != nil {
+			log.Warn().Err(err).Int("item", i+1).Str("key", key).Msg("Item cannot be published to Instagram")
+			itemErrors = append(itemErrors, store.PublishItemError{Index: i, Key: key, Error: err.Error()})
+			continue
+		}
+		if publishKey != key {
+			converted++
+		}
+		keys = append(keys, publishKey)
+	}
+
+	if len(itemErrors) > 0 {
+		msg := fmt.Sprintf("%d of %d items do not meet Instagram's requirements", len(itemErrors), len(event.Keys))
+		if len(itemErrors) == 1 {
+			msg = fmt.Sprintf("item %d does not meet Instagram's requirements: %s", itemErrors[0].Index+1, itemErrors[0].Error)
+		}
+		log.Error().Str("job", event.JobID).Int("failed", len(itemErrors)).Msg("Publish job failed media validation")
+		sessionStore.PutPublishJob(ctx, event.SessionID, &store.PublishJob{
+			ID: event.JobID, GroupID: event.GroupID, Status: "error",
+			Phase: "error", TotalItems: len(event.Keys), Error: msg, ItemErrors: itemErrors,
+		})
+		return result, nil
+	}
+
+	log.Info().Int("items", len(keys)).Int("converted", converted).Msg("Media ready for Instagram")
+	result.Keys = keys
+	result.Ready = true
+	return result, nil
+}
+
+// applyCarouselOrder reorders the event's keys, and the user tags and
+// alt-text aligned with them, into event.Order (DDR-179). The request may
+// tag or describe only the first items, so both lists are padded to the
+// keys first and every entry moves with its item. An order that is not a
+// permutation of the keys is ignored.
+func applyCarouselOrder(event PublishEvent) PublishEvent {
+	n := len(event.Keys)
+	if len(event.Order) == 0 {
+		return event
+	}
+	valid := len(event.Order) == n
+	seen := make([]bool, n)
+	for _, i := range event.Order {
+		if !valid || i < 0 || i >= n || seen[i] {
+			valid = false
+			break
+		}
+		seen[i] = true
+	}
+	if !valid {
+		log.Warn().Ints("order", event.Order).Int("items", n).Msg("Ignoring carousel order that does not match the items")
+		return event
+	}
+
+	event.Keys = permute(event.Keys, event.Order)
+	if len(event.UserTags) > 0 {
+		event.UserTags = permute(padTo(event.UserTags, n), event.Order)
+	}
+	if len(event.AltText) > 0 {
+		event.AltText = permute(padTo(event.AltText, n), event.Order)
+	}
+	log.Info().Ints("order", event.Order).Msg("Applied suggested carousel order")
+	event.Order = nil
+	return event
+}
+
+// permute returns items in order, where order[to] is the index an item
+// moves from.
+func permute[T any](items []T, order []int) []T {
+	out := make([]T, len(order))
+	for to, from := range order {
+		out[to] = items[from]
+	}
+	return out
+}
+
+// padTo extends items with zero values to n entries.
+func padTo[T any](items []T, n int) []T {
+	if len(items) >= n {
+		return items
+	}
+	return append(items, make([]T, n-len(items))...)
+}
+
+// carouselTagErrors re-checks, after reordering, that no video in a
+// carousel carries user tags, which Instagram rejects (DDR-141). The API
+// validates the request, but the check must hold for the published order.
+func carouselTagErrors(event PublishEvent) []store.PublishItemError {
+	if len(event.Keys) < 2 {
+		return nil
+	}
+	var errs []store.PublishItemError
+	for i, tags := range event.UserTags {
+		if i < len(event.Keys) && len(tags) > 0 && isVideoKey(event.Keys[i]) {
+			errs = append(errs, store.PublishItemError{Index: i, Key: event.Keys[i], Error: "people cannot be tagged on videos in a carousel"})
+		}
+	}
+	return errs
+}
+
+// prepareItem returns the key to publish for one item: the original when it
+// already meets Instagram's requirements, otherwise a converted copy under
+// {sessionId}/publish/{jobId}/. The error is the user-facing reason the item
+// cannot be published. Photos are copied when overlay is set, the metadata
+// policy changes them or a provenance record is embedded.
+func prepareItem(ctx context.Context, event PublishEvent, index int, key string, carousel bool, overlay *media.Overlay, policy media.MetadataPolicy, provenance bool) (string, error) {
+	localPath, cleanup, err := s3util.DownloadToTempFile(ctx, s3Client, mediaBucket, key)
+	if err != nil {
+		return "", fmt.Errorf("download failed: %w", err)
+	}
+	defer cleanup()
+
+	base := strings.TrimSuffix(path.Base(key), path.Ext(key))
+	prefix := fmt.Sprintf("%s/publish/%s/%02d-%s", event.SessionID, event.JobID, index+1, base)
+	if isVideoKey(key) {
+		return prepareVideo(ctx, key, localPath, prefix+".mp4", carousel)
+	}
+	return prepareImage(ctx, key, localPath, prefix+".jpg", overlay, policy, provenance)
+}
+
+func prepareImage(ctx context.Context, key, localPath, outKey string, overlay *media.Overlay, policy media.MetadataPolicy, provenance bool) (string, error) {
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return "", fmt.Errorf("read image: %w", err)
+	}
+	edits := sourceEdits(key)
+	if overlay != nil {
+		if data, err = media.ApplyOverlay(data, *overlay, brand.WatermarkQuality); err != nil {
+			return "", fmt.Errorf("watermark failed: %w", err)
+		}
+		edits = append(edits, media.EditWatermark)
+	}
+	cfg, format, err := media.OrientedImageConfig(data)
+	if err != nil {
+		return "", fmt.Errorf("unsupported image format: %w", err)
+	}
+
+	problems := instagram.CheckImage(instagram.ImageInfo{
+		Format: format, Width: cfg.Width, Height: cfg.Height, Bytes: int64(len(data)),
+	})
+	if len(problems) == 0 {
+		out := data
+		if overlay == nil {
+			if out, err = media.SanitizeMetadata(data, policy); err != nil {
+				return "", fmt.Errorf("cannot read image metadata: %w", err)
+			}
+		}
+		if provenance {
+			out = embedProvenance(key, out, edits)
+		}
+		if overlay == nil && bytes.Equal(out, data) {
+			return key, nil
+		}
+		if err := putPrepared(ctx, outKey, out, "image/jpeg"); err != nil {
+			return "", err
+		}
+		log.Info().Str("key", key).Str("publishKey", outKey).Bool("watermarked", overlay != nil).Str("metadataPolicy", string(policy)).Msg("Image copied for Instagram")
+		return outKey, nil
+	}
+	if !instagram.Fixable(problems) {
+		return "", fmt.Errorf("%s", instagram.Describe(problems))
+	}
+
+	out, err := media.FitJPEG(data, media.JPEGFitOptions{
+		MaxWidth:  instagram.MaxImageWidth,
+		MinAspect: instagram.MinFeedAspect,
+		MaxAspect: instagram.MaxFeedAspect,
+		MaxBytes:  instagram.MaxImageBytes,
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s; conversion failed: %w", instagram.Describe(problems), err)
+	}
+	if provenance {
+		edits = append(edits, media.ProvenanceEdit{Description: "Resized and re-encoded for Instagram: " + instagram.Describe(problems)})
+		out = embedProvenance(key, out, edits)
+	}
+	if err := putPrepared(ctx, outKey, out, "image/jpeg"); err != nil {
+		return "", err
+	}
+	log.Info().Str("key", key).Str("publishKey", outKey).Str("problems", instagram.Describe(problems)).
+		Int("inputBytes", len(data)).Int("outputBytes", len(out)).Msg("Image converted for Instagram")
+	return outKey, nil
+}
+
+func prepareVideo(ctx context.Context, key, localPath, outKey string, carousel bool) (string, error) {
+	meta, err := media.ExtractVideoMetadata(localPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot read video: %w", err)
+	}
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return "", fmt.Errorf("stat video: %w", err)
+	}
+	width, height := meta.DisplaySize()
+	problems := instagram.CheckVideo(instagram.VideoInfo{
+		Width: width, Height: height, Duration: meta.Duration, FrameRate: meta.FrameRate,
+		VideoCodec: meta.Codec, AudioCodec: meta.AudioCodec, AudioRate: meta.AudioRate,
+		Bytes: info.Size(),
+	}, carousel)
+	if len(problems) == 0 {
+		return key, nil
+	}
+	if !instagram.Fixable(problems) {
+		return "", fmt.Errorf("%s", instagram.Describe(problems))
+	}
+	if !media.IsFFmpegAvailable() {
+		return "", fmt.Errorf("%s; conversion needs ffmpeg", instagram.Describe(problems))
+	}
+
+	profile := instagramVideoProfile(problems, width, height, meta, carousel)
+	outPath, outSize, cleanup, err := media.Transcode(ctx, localPath, meta, profile)
+	if err != nil {
+		return "", fmt.Errorf("%s; conversion failed: %w", instagram.Describe(problems), err)
+	}
+	defer cleanup()
+
+	f, err := os.Open(outPath)
+	if err != nil {
+		return "", fmt.Errorf("open converted video: %w", err)
+	}
+	defer f.Close()
+	contentType := "video/mp4"
+	enc, err := sessionKeys.ForKey(ctx, outKey)
+	if err != nil {
+		return "", fmt.Errorf("upload converted video: %w", err)
+	}
+	if _, err := s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
+		Bucket: &mediaBucket, Key: &outKey, Body: f, ContentLength: &outSize,
+		ContentType: &contentType, Tagging: s3util.ProjectTagging(),
+	})); err != nil {
+		return "", fmt.Errorf("upload converted video: %w", err)
+	}
+	log.Info().Str("key", key).Str("publishKey", outKey).Str("problems", instagram.Describe(problems)).
+		Int64("inputBytes", info.Size()).Int64("outputBytes", outSize).Msg("Video converted for Instagram")
+	return outKey, nil
+}
+
+// instagramVideoProfile adapts the instagram-feed transcode profile to the
+// item: letterboxed into the nearest allowed aspect ratio when outside the
+// range, and bitrate-capped to fit the size limit when the video is long.
+func instagramVideoProfile(problems []instagram.Problem, width, height int, meta *media.VideoMetadata, carousel bool) media.TranscodeProfile {
+	profile := media.ProfileInstagramFeed
+	for _, p := range problems {
+		switch p.Code {
+		case instagram.ProblemAspectRatio:
+			minAspect, maxAspect := instagram.MinReelAspect, instagram.MaxReelAspect
+			if carousel {
+				minAspect, maxAspect = instagram.MinFeedAspect, instagram.MaxFeedAspect
+			}
+			aspect := instagram.ClampAspect(width, height, minAspect, maxAspect)
+			profile.FrameWidth = profile.MaxWidth
+			profile.FrameHeight = int(float64(profile.MaxWidth)/aspect) &^ 1
+		case instagram.ProblemVideoSize:
+			limit := int64(instagram.MaxReelBytes)
+			if carousel {
+				limit = instagram.MaxCarouselVideoBytes
+			}
+			if secs := meta.Duration.Seconds(); secs > 0 {
+				// 90% of the budget for video, leaving room for audio and the container.
+				kbps := int64(float64(limit)*8*0.9/secs/1000) - 128
+				profile.MaxBitrate = fmt.Sprintf("%dk", max(kbps, 500))
+			}
+		}
+	}
+	return profile
+}
+
+func putPrepared(ctx context.Context, key string, data []byte, contentType string) error {
+	enc, err := sessionKeys.ForKey(ctx, key)
+	if err != nil {
+		return fmt.Errorf("upload converted image: %w", err)
+	}
+	if _, err := s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
+		Bucket: &mediaBucket, Key: &key, Body: bytes.NewReader(data),
+		ContentType: &contentType, Tagging: s3util.ProjectTagging(),
+	})); err != nil {
+		return fmt.Errorf("upload converted image: %w", err)
+	}
+	return nil
+}
+
+// sourceEdits returns the edits that produced the photo at key, judged by
+// the folder it was written to. Originals have none.
+func sourceEdits(key string) []media.ProvenanceEdit {
+	switch {
+	case strings.Contains(key, "/enhanced/"):
+		return []media.ProvenanceEdit{media.EditGeminiEnhance}
+	case strings.Contains(key, "/ai-generated/"):
+		return []media.ProvenanceEdit{media.EditGeminiRestyle}
+	case strings.Contains(key, "/cropped/"):
+		return []media.ProvenanceEdit{media.EditCropped}
+	case strings.Contains(key, "/redacted/"): // DDR-185
+		return []media.ProvenanceEdit{media.EditRedacted}
+	}
+	return nil
+}
+
+// embedProvenance records edits in data, replacing any record written at
+// enhancement time. Photos this tool did not edit are left alone; a failure
+// is logged and publishes the photo without a record.
+func embedProvenance(key string, data []byte, edits []media.ProvenanceEdit) []byte {
+	if len(edits) == 0 {
+		return data
+	}
+	out, err := media.EmbedProvenance(data, media.Provenance{Edits: edits})
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("Provenance embedding failed, publishing without it")
+		return data
+	}
+	return out
+}
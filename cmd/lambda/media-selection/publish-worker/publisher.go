@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/crosspost"
+	"github.com/fpang/ai-social-media-helper/internal/facebook"
+	"github.com/fpang/ai-social-media-helper/internal/instagram"
+	"github.com/fpang/ai-social-media-helper/internal/mastodon"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/sfnevents"
+)
+
+// --- Publish platforms (DDR-147) ---
+
+// publisher is one platform's side of the publish phases. Every platform
+// creates a container per item, reports video processing in Instagram's
+// terms (IN_PROGRESS, FINISHED, ERROR), combines a multi-item post into one
+// container and publishes it, so the state machine and the job phases are
+// the same for all of them.
+type publisher interface {
+	// CreateItem creates the container for item index of the job. For a
+	// single-item post the container is the whole post.
+	CreateItem(ctx context.Context, event PublishEvent, index int, mediaURL string, video, carousel bool) (string, error)
+	ContainerStatus(ctx context.Context, containerID string) (string, error)
+	// CreateCarousel combines item containers into one post container.
+	CreateCarousel(ctx context.Context, event PublishEvent, containerIDs []string) (string, error)
+	// Publish makes a post container visible and returns the post ID.
+	Publish(ctx context.Context, containerID string) (string, error)
+	IsMock() bool
+}
+
+// Dry-run clients simulate Facebook and Mastodon for dry-run jobs.
+var (
+	fbDryRunClient       = facebook.NewMockClient()
+	mastodonDryRunClient = mastodon.NewMockClient() // DDR-151
+)
+
+// publisherFor returns the publisher for the job's platform, or nil when
+// that platform's credentials are missing.
+func publisherFor(event PublishEvent) publisher {
+	switch event.Platform {
+	case sfnevents.PlatformFacebook:
+		fb := fbClient
+		if event.DryRun {
+			fb = fbDryRunClient
+		}
+		if fb == nil {
+			return nil
+		}
+		return facebookPublisher{fb}
+	case sfnevents.PlatformMastodon:
+		md := mastodonClient
+		if event.DryRun {
+			md = mastodonDryRunClient
+		}
+		if md == nil {
+			return nil
+		}
+		return mastodonPublisher{c: md, event: event}
+	}
+	ig := instagramFor(event)
+	if ig == nil {
+		return nil
+	}
+	return instagramPublisher{ig}
+}
+
+// platformName names the job's platform in job errors and logs.
+func platformName(event PublishEvent) string {
+	return crosspost.PlatformLabel(event.Platform)
+}
+
+// itemAltText returns the alt-text of the job's item at index, or "" when it
+// has none (DDR-154).
+func itemAltText(event PublishEvent, index int) string {
+	if index < len(event.AltText) {
+		return event.AltText[index]
+	}
+	return ""
+}
+
+// instagramPublisher publishes through the Instagram content publishing API
+// (DDR-040).
+type instagramPublisher struct {
+	c *instagram.Client
+}
+
+func (p instagramPublisher) CreateItem(ctx context.Context, event PublishEvent, index int, mediaURL string, video, carousel bool) (string, error) {
+	var tags []instagram.UserTag
+	if index < len(event.UserTags) {
+		tags = event.UserTags[index]
+	}
+	switch {
+	case carousel && video:
+		return p.c.CreateVideoContainer(ctx, mediaURL, true)
+	case carousel:
+		return p.c.CreateImageContainer(ctx, mediaURL, true, tags, itemAltText(event, index))
+	case video:
+		return p.c.CreateSingleReelPost(ctx, mediaURL, event.Caption, tags, postOptions(event))
+	default:
+		return p.c.CreateSingleImagePost(ctx, mediaURL, event.Caption, tags, itemAltText(event, index), postOptions(event))
+	}
+}
+
+func (p instagramPublisher) ContainerStatus(ctx context.Context, containerID string) (string, error) {
+	return p.c.ContainerStatus(ctx, containerID)
+}
+
+func (p instagramPublisher) CreateCarousel(ctx context.Context, event PublishEvent, containerIDs []string) (string, error) {
+	return p.c.CreateCarouselContainer(ctx, containerIDs, event.Caption, postOptions(event))
+}
+
+func (p instagramPublisher) Publish(ctx context.Context, containerID string) (string, error) {
+	return p.c.Publish(ctx, containerID)
+}
+
+func (p instagramPublisher) IsMock() bool { return p.c.IsMock() }
+
+// facebookPublisher publishes to a Facebook Page. Photos are uploaded
+// unpublished and attached to an unpublished Page post; a video is its own
+// post. User tags and collaborators are Instagram-only and were refused
+// when the job started.
+type facebookPublisher struct {
+	c *facebook.Client
+}
+
+func (p facebookPublisher) CreateItem(ctx context.Context, event PublishEvent, index int, mediaURL string, video, carousel bool) (string, error) {
+	if video {
+		if carousel {
+			return "", fmt.Errorf("Facebook albums can only contain photos")
+		}
+		return p.c.CreateUnpublishedVideo(ctx, mediaURL, event.Caption)
+	}
+	photoID, err := p.c.CreateUnpublishedPhoto(ctx, mediaURL, itemAltText(event, index))
+	if err != nil || carousel {
+		return photoID, err
+	}
+	return p.c.CreatePost(ctx, []string{photoID}, event.Caption, fbPostOptions(event))
+}
+
+// ContainerStatus reports Page posts as finished; videos are mapped from
+// Facebook's processing status.
+func (p facebookPublisher) ContainerStatus(ctx context.Context, containerID string) (string, error) {
+	if facebook.IsPostID(containerID) {
+		return "FINISHED", nil
+	}
+	status, err := p.c.VideoStatus(ctx, containerID)
+	if err != nil {
+		return "", err
+	}
+	switch status {
+	case facebook.VideoReady:
+		return "FINISHED", nil
+	case facebook.VideoError:
+		return "ERROR", nil
+	default:
+		return "IN_PROGRESS", nil
+	}
+}
+
+func (p facebookPublisher) CreateCarousel(ctx context.Context, event PublishEvent, containerIDs []string) (string, error) {
+	return p.c.CreatePost(ctx, containerIDs, event.Caption, fbPostOptions(event))
+}
+
+func (p facebookPublisher) Publish(ctx context.Context, containerID string) (string, error) {
+	return p.c.Publish(ctx, containerID)
+}
+
+func (p facebookPublisher) IsMock() bool { return p.c.IsMock() }
+
+// fbPostOptions returns the Page post settings of a job. Instagram location
+// IDs are Facebook place IDs, so the location tag carries over (DDR-138).
+func fbPostOptions(event PublishEvent) facebook.PostOptions {
+	return facebook.PostOptions{PlaceID: event.LocationID}
+}
+
+// mastodonPublisher publishes to a Mastodon-compatible server (DDR-151).
+// Servers post a status the moment it is created, so item containers are
+// media attachment IDs, a multi-item post is the comma-joined list of them,
+// and Publish creates the status. The publisher keeps the job's event
+// because Publish needs its caption.
+type mastodonPublisher struct {
+	c     *mastodon.Client
+	event PublishEvent
+}
+
+// mastodonImageWait bounds how long an uploaded photo may stay in
+// processing before its upload fails. Videos are polled by the pipeline.
+const mastodonImageWait = 30 * time.Second
+
+// maxPhotoFetchBytes bounds a photo download. Prepared photos are far
+// smaller (DDR-129); the bound only guards memory.
+const maxPhotoFetchBytes = 64 << 20
+
+// mediaFetchClient downloads items through their presigned URLs.
+var mediaFetchClient = &http.Client{Timeout: 2 * time.Minute}
+
+// CreateItem uploads the item with its alt-text. Photos over the instance's
+// size or pixel limits are scaled down and re-encoded; a video over the size
+// limit fails the item.
+func (p mastodonPublisher) CreateItem(ctx context.Context, event PublishEvent, index int, mediaURL string, video, carousel bool) (string, error) {
+	u, err := url.Parse(mediaURL)
+	if err != nil {
+		return "", fmt.Errorf("parse media URL: %w", err)
+	}
+	filename := path.Base(u.Path)
+	contentType, err := media.GetMIMEType(path.Ext(filename))
+	if err != nil {
+		return "", err
+	}
+
+	limits := p.c.Limits(ctx)
+	maxBytes := int64(maxPhotoFetchBytes)
+	if video {
+		maxBytes = limits.VideoBytes
+	}
+	data, err := fetchMedia(ctx, mediaURL, maxBytes)
+	if err != nil {
+		return "", err
+	}
+	if video {
+		if int64(len(data)) > limits.VideoBytes {
+			return "", fmt.Errorf("video is larger than the instance's %d MB limit", limits.VideoBytes>>20)
+		}
+	} else {
+		var converted bool
+		if data, converted, err = fitMastodonImage(data, limits); err != nil {
+			return "", err
+		}
+		if converted {
+			filename, contentType = strings.TrimSuffix(filename, path.Ext(filename))+".jpg", "image/jpeg"
+		}
+	}
+
+	id, err := p.c.UploadMedia(ctx, data, filename, contentType, itemAltText(event, index))
+	if err != nil {
+		return "", err
+	}
+	if !video {
+		if err := p.c.WaitForMedia(ctx, id, mastodonImageWait); err != nil {
+			return "", err
+		}
+	}
+	return id, nil
+}
+
+func (p mastodonPublisher) ContainerStatus(ctx context.Context, containerID string) (string, error) {
+	status, err := p.c.MediaStatus(ctx, containerID)
+	if err != nil {
+		return "", err
+	}
+	if status == mastodon.MediaReady {
+		return "FINISHED", nil
+	}
+	return "IN_PROGRESS", nil
+}
+
+func (p mastodonPublisher) CreateCarousel(ctx context.Context, event PublishEvent, containerIDs []string) (string, error) {
+	if len(containerIDs) > mastodon.MaxAttachments {
+		return "", fmt.Errorf("Mastodon posts can carry at most %d items", mastodon.MaxAttachments)
+	}
+	return strings.Join(containerIDs, ","), nil
+}
+
+// Publish posts the status. The job ID is the idempotency key, so a retried
+// finalize step cannot post twice.
+func (p mastodonPublisher) Publish(ctx context.Context, containerID string) (string, error) {
+	limits := p.c.Limits(ctx)
+	if n := len([]rune(p.event.Caption)); n > limits.Characters {
+		return "", fmt.Errorf("caption is %d characters; the instance allows %d", n, limits.Characters)
+	}
+	return p.c.PostStatus(ctx, p.event.Caption, strings.Split(containerID, ","), p.event.JobID)
+}
+
+func (p mastodonPublisher) IsMock() bool { return p.c.IsMock() }
+
+// fetchMedia downloads an item through its presigned URL, refusing anything
+// larger than maxBytes.
+func fetchMedia(ctx context.Context, mediaURL string, maxBytes int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build media request: %w", err)
+	}
+	resp, err := mediaFetchClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download media: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download media: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("download media: %w", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("media is larger than %d MB", maxBytes>>20)
+	}
+	return data, nil
+}
+
+// fitMastodonImage returns data unchanged when it is within the instance's
+// limits, otherwise a JPEG scaled and re-encoded to fit them; converted
+// reports which.
+func fitMastodonImage(data []byte, limits mastodon.Limits) (out []byte, converted bool, err error) {
+	cfg, _, err := media.OrientedImageConfig(data)
+	if err != nil {
+		return nil, false, fmt.Errorf("unsupported image format: %w", err)
+	}
+	if limits.FitsImage(int64(len(data)), cfg.Width, cfg.Height) {
+		return data, false, nil
+	}
+	out, err = media.FitJPEG(data, media.JPEGFitOptions{
+		MaxWidth: limits.MaxImageWidth(cfg.Width, cfg.Height),
+		MaxBytes: int(limits.ImageBytes),
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("image exceeds the instance's limits; conversion failed: %w", err)
+	}
+	log.Info().Int("width", cfg.Width).Int("height", cfg.Height).Int("inputBytes", len(data)).Int("outputBytes", len(out)).
+		Int64("imageBytesLimit", limits.ImageBytes).Int64("imagePixelsLimit", limits.ImagePixels).Msg("Image resized for Mastodon")
+	return out, true, nil
+}
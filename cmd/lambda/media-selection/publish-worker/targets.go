@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/crosspost"
+	"github.com/fpang/ai-social-media-helper/internal/sfnevents"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// --- Cross-posting (DDR-153) ---
+//
+// Target resolution and per-platform outcomes live in internal/crosspost;
+// this file holds the steps that talk to the store and the publishers.
+
+// failTargets records a job whose every target has failed.
+func failTargets(ctx context.Context, event PublishEvent, targets []sfnevents.PublishTarget) error {
+	msg := crosspost.Error(targets)
+	log.Error().Str("job", event.JobID).Int("platforms", len(targets)).Str("error", msg).Msg("Publish job failed on every platform")
+	sessionStore.PutPublishJob(ctx, event.SessionID, &store.PublishJob{
+		ID: event.JobID, GroupID: event.GroupID, Status: "error",
+		Phase: "error", Error: msg, Platforms: crosspost.Statuses(targets, nil),
+	})
+	return nil
+}
+
+// publishTarget creates the post container of one target, combining its
+// items when the post has several, and publishes it. phase records the
+// job's progress; mock reports a simulated post (DDR-139).
+func publishTarget(ctx context.Context, event PublishEvent, phase func(string)) (postID string, mock bool, err error) {
+	pub := publisherFor(event)
+	if pub == nil {
+		return "", false, fmt.Errorf("%s client not configured", platformName(event))
+	}
+
+	var publishContainerID string
+	if event.IsCarousel {
+		phase("creating_carousel")
+		if publishContainerID, err = pub.CreateCarousel(ctx, event, event.ContainerIDs); err != nil {
+			return "", false, fmt.Errorf("failed to create carousel: %v", err)
+		}
+	} else {
+		if len(event.ContainerIDs) == 0 {
+			return "", false, fmt.Errorf("no container IDs provided")
+		}
+		publishContainerID = event.ContainerIDs[0]
+	}
+
+	phase("publishing")
+	if postID, err = pub.Publish(ctx, publishContainerID); err != nil {
+		return "", false, fmt.Errorf("publish failed: %v", err)
+	}
+	return postID, pub.IsMock(), nil
+}
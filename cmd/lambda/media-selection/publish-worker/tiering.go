@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
+)
+
+// archivePublishedOriginals moves the originals behind a just-published
+// group's keys — originals, enhanced copies or publish copies — to the
+// configured storage class (DDR-115). Best effort: an original that cannot
+// be found or moved stays in its current class.
+func archivePublishedOriginals(ctx context.Context, keys []string) {
+	seen := make(map[string]bool, len(keys))
+	moved := 0
+	for _, key := range keys {
+		original, err := s3util.ResolveOriginalKey(ctx, s3Client, mediaBucket, key)
+		if err != nil {
+			log.Warn().Err(err).Str("key", key).Msg("Failed to find the original of a published item")
+			continue
+		}
+		if seen[original] {
+			continue
+		}
+		seen[original] = true
+
+		ok, err := s3util.TransitionObject(ctx, s3Client, mediaBucket, original, tiering.StorageClass)
+		if err != nil {
+			log.Warn().Err(err).Str("key", original).Msg("Failed to move original to archive storage")
+			continue
+		}
+		if ok {
+			moved++
+		}
+	}
+	log.Info().
+		Int("originals", len(seen)).
+		Int("moved", moved).
+		Str("storageClass", string(tiering.StorageClass)).
+		Msg("Published originals moved to archive storage")
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/jobs"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// handleSelectionFeedback revises a completed selection job in place from
+// the user's feedback (DDR-177). Gemini refines its previous answer without
+// seeing the media again, so no files are downloaded.
+func handleSelectionFeedback(ctx context.Context, event SelectionEvent) error {
+	jobStart := time.Now()
+	logger := log.With().Str("sessionId", event.SessionID).Str("jobId", event.JobID).Logger()
+
+	job, err := sessionStore.GetSelectionJob(ctx, event.SessionID, event.JobID)
+	if err != nil || job == nil {
+		return jobs.SetJobError(ctx, event.SessionID, event.JobID, "job not found", func(ctx context.Context, sessionID, jobID, errMsg string) error {
+			sessionStore.PutSelectionJob(ctx, sessionID, &store.SelectionJob{ID: jobID, Status: "error", Error: errMsg})
+			return nil
+		})
+	}
+	// Failures keep the previous results on the job.
+	setError := func(ctx context.Context, sessionID, jobID, errMsg string) error {
+		job.Status, job.Error = "error", errMsg
+		sessionStore.PutSelectionJob(ctx, sessionID, job)
+		return nil
+	}
+
+	client, err := ai.NewAIClient(ctx)
+	if err != nil {
+		return jobs.SetJobError(ctx, event.SessionID, event.JobID, "failed to initialize AI client", setError)
+	}
+
+	files, keys, thumbs := feedbackMedia(job)
+	history := make([]string, len(job.FeedbackHistory))
+	for i, h := range job.FeedbackHistory {
+		history[i] = h.UserFeedback
+	}
+
+	// DDR-170: feedback rounds keep the settings the job started with.
+	ctx, _ = ai.ApplyModelConfig(ctx, ai.ModelConfig{
+		Model: job.Model, Thinking: job.Thinking, Temperature: job.Temperature, TopP: job.TopP,
+	})
+	// DDR-169: note which models answered, in case a fallback took over.
+	ctx, models := ai.WithModelRecorder(ctx)
+	// DDR-176: a re-run's locked and excluded picks still hold.
+	if job.RerunOf != "" {
+		ctx = ai.WithSelectionConstraints(ctx, selectionConstraints(files, keys, job.LockedKeys, job.ExcludedKeys))
+	}
+
+	result, err := ai.RefineSelection(ctx, client, files, job.TripContext, priorSelection(job), event.Feedback, history)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Selection refinement failed")
+		return jobs.SetJobError(ctx, event.SessionID, event.JobID, "selection refinement failed", setError)
+	}
+
+	// DDR-187: videos still selected keep the highlights already cut.
+	highlights := make(map[string][]store.VideoHighlight)
+	for _, it := range job.Selected {
+		highlights[it.Key] = it.Highlights
+	}
+
+	var selected []store.SelectedItem
+	for _, sel := range result.Selected {
+		if !validMedia(files, sel.Media) {
+			logger.Warn().Int("mediaIndex", sel.Media).Msg("Skipping refined result with unknown media index")
+			continue
+		}
+		key := keys[sel.Media-1]
+		selected = append(selected, store.SelectedItem{
+			Rank: sel.Rank, Media: sel.Media, Filename: sel.Filename, Key: key,
+			Type: sel.Type, Scene: sel.Scene, Justification: sel.Justification,
+			ComparisonNote: sel.ComparisonNote, ThumbnailURL: thumbs[sel.Media],
+			Scores: selectionScores(sel.Scores), AltText: sel.AltText,
+			Highlights: highlights[key],
+		})
+	}
+	var excluded []store.ExcludedItem
+	for _, exc := range result.Excluded {
+		if !validMedia(files, exc.Media) {
+			logger.Warn().Int("mediaIndex", exc.Media).Msg("Skipping refined result with unknown media index")
+			continue
+		}
+		key := keys[exc.Media-1]
+		excluded = append(excluded, store.ExcludedItem{
+			Media: exc.Media, Filename: exc.Filename, Key: key, Reason: exc.Reason,
+			Category: exc.Category, DuplicateOf: exc.DuplicateOf, ThumbnailURL: thumbs[exc.Media],
+		})
+	}
+	var groups []store.SceneGroup
+	for _, sg := range result.SceneGroups {
+		group := store.SceneGroup{Name: sg.Name, GPS: sg.GPS, TimeRange: sg.TimeRange}
+		for _, item := range sg.Items {
+			if !validMedia(files, item.Media) {
+				continue
+			}
+			group.Items = append(group.Items, store.SceneGroupItem{
+				Media: item.Media, Filename: item.Filename, Key: keys[item.Media-1],
+				Type: item.Type, Selected: item.Selected, Description: item.Description,
+				ThumbnailURL: thumbs[item.Media],
+			})
+		}
+		groups = append(groups, group)
+	}
+
+	job.Selected, job.Excluded, job.SceneGroups = selected, excluded, groups
+	// Safety net: media the refinement dropped are listed rather than lost.
+	for _, key := range job.AddUnjudged(keys, reasonNotEvaluated) {
+		logger.Warn().Str("key", key).Msg("Media item missing from refined selection")
+	}
+	job.FeedbackHistory = append(job.FeedbackHistory, store.SelectionFeedbackEntry{
+		UserFeedback: event.Feedback, CreatedAt: time.Now().Unix(),
+	})
+	job.Status, job.Error = "complete", ""
+	job.CompletedAt = time.Now().Unix()
+	job.ModelsUsed = models.Models()
+	if changes, err := sessionStore.SelectionChanges(ctx, event.SessionID, job); err != nil {
+		logger.Warn().Err(err).Msg("Failed to compare with the previous selection")
+	} else {
+		job.Changes = changes
+	}
+	if err := sessionStore.PutSelectionJob(ctx, event.SessionID, job); err != nil {
+		logger.Error().Err(err).Msg("Failed to write refined selection")
+		return err
+	}
+
+	logger.Info().
+		Int("round", len(job.FeedbackHistory)).
+		Int("selected", len(job.Selected)).
+		Int("excluded", len(job.Excluded)).
+		Dur("duration", time.Since(jobStart)).
+		Msg("Selection refinement complete")
+	return nil
+}
+
+// feedbackMedia rebuilds the numbered media of a completed job: files[i] and
+// keys[i] are media i+1, and thumbs maps a media number to its thumbnail
+// URL. Numbers the job never judged are left nil and empty.
+func feedbackMedia(job *store.SelectionJob) ([]*media.MediaFile, []string, map[int]string) {
+	thumbs := make(map[int]string)
+	byMedia := make(map[int]string)
+	add := func(n int, key, thumb string) {
+		if n < 1 || key == "" {
+			return
+		}
+		byMedia[n] = key
+		if thumb != "" {
+			thumbs[n] = thumb
+		}
+	}
+	for _, it := range job.Selected {
+		add(it.Media, it.Key, it.ThumbnailURL)
+	}
+	for _, it := range job.Excluded {
+		add(it.Media, it.Key, it.ThumbnailURL)
+	}
+	for _, sg := range job.SceneGroups {
+		for _, it := range sg.Items {
+			add(it.Media, it.Key, it.ThumbnailURL)
+		}
+	}
+
+	count := 0
+	for n := range byMedia {
+		count = max(count, n)
+	}
+	files := make([]*media.MediaFile, count)
+	keys := make([]string, count)
+	for n, key := range byMedia {
+		files[n-1] = &media.MediaFile{Path: key}
+		keys[n-1] = key
+	}
+	return files, keys, thumbs
+}
+
+// validMedia reports whether n numbers one of files.
+func validMedia(files []*media.MediaFile, n int) bool {
+	return n >= 1 && n <= len(files) && files[n-1] != nil
+}
+
+// priorSelection converts a job's stored results back into Gemini's
+// selection format.
+func priorSelection(job *store.SelectionJob) *ai.SelectionResult {
+	prior := &ai.SelectionResult{}
+	for _, it := range job.Selected {
+		var scores *ai.SelectionScores
+		if s := it.Scores; s != nil {
+			scores = &ai.SelectionScores{
+				Composition: s.Composition, StoryValue: s.StoryValue,
+				Uniqueness: s.Uniqueness, TechnicalQuality: s.TechnicalQuality,
+			}
+		}
+		prior.Selected = append(prior.Selected, ai.SelectedItem{
+			Rank: it.Rank, Media: it.Media, Filename: it.Filename, Type: it.Type,
+			Scene: it.Scene, Justification: it.Justification,
+			ComparisonNote: it.ComparisonNote, Scores: scores, AltText: it.AltText,
+		})
+	}
+	for _, it := range job.Excluded {
+		prior.Excluded = append(prior.Excluded, ai.ExcludedItem{
+			Media: it.Media, Filename: it.Filename, Reason: it.Reason,
+			Category: it.Category, DuplicateOf: it.DuplicateOf,
+		})
+	}
+	for _, sg := range job.SceneGroups {
+		group := ai.SceneGroup{Name: sg.Name, GPS: sg.GPS, TimeRange: sg.TimeRange}
+		for _, it := range sg.Items {
+			group.Items = append(group.Items, ai.SceneGroupItem{
+				Media: it.Media, Filename: it.Filename, Type: it.Type,
+				Selected: it.Selected, Description: it.Description,
+			})
+		}
+		prior.SceneGroups = append(prior.SceneGroups, group)
+	}
+	return prior
+}
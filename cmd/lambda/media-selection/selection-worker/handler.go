@@ -13,12 +13,24 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/artifacts"
+	"github.com/fpang/ai-social-media-helper/internal/decisions"
+	"github.com/fpang/ai-social-media-helper/internal/flags"
 	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
 	"github.com/fpang/ai-social-media-helper/internal/rag"
 	"github.com/fpang/ai-social-media-helper/internal/s3util"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 )
 
+// Reasons recorded for files selection could not consider (DDR-134).
+const (
+	reasonUnsupported  = "Unsupported file type"
+	reasonDownload     = "Could not be downloaded from storage"
+	reasonUnreadable   = "Could not be read as a photo or video"
+	reasonNotEvaluated = "Not evaluated by AI"
+)
+
 func resolveEconomyMode(eventEconomy bool) bool {
 	if v := os.Getenv("ECONOMY_MODE"); v == "true" {
 		return true
@@ -37,6 +49,23 @@ func handler(ctx context.Context, event SelectionEvent) (SelectionResult, error)
 		bucket = event.Bucket
 	}
 
+	// DDR-118: collect per-job telemetry; PutSelectionJob records it.
+	ctx, _ = metrics.WithCollector(ctx)
+
+	// DDR-177: the API invokes this Lambda directly for feedback rounds.
+	if event.Type == "selection-feedback" {
+		return SelectionResult{JobID: event.JobID}, handleSelectionFeedback(ctx, event)
+	}
+
+	// DDR-106: keep prompts, raw responses, and compressed videos when requested.
+	if event.DebugArtifacts {
+		if enc, err := sessionKeys.ForSession(ctx, event.SessionID); err != nil {
+			log.Warn().Err(err).Str("sessionId", event.SessionID).Msg("Session encryption unavailable — not keeping debug artifacts")
+		} else {
+			ctx = artifacts.WithRecorder(ctx, artifacts.NewS3Recorder(s3Client, bucket, artifacts.SessionPrefix(event.SessionID, event.JobID), enc))
+		}
+	}
+
 	logger := log.With().
 		Str("sessionId", event.SessionID).
 		Str("jobId", event.JobID).
@@ -65,15 +94,40 @@ func handler(ctx context.Context, event SelectionEvent) (SelectionResult, error)
 			fmt.Errorf("no media keys provided")
 	}
 
+	// DDR-155, DDR-170: the job's model settings; thinking falls back to
+	// the deployment's.
+	ctx, modelCfg := ai.ApplyModelConfig(ctx, event.ModelConfig)
 	model := ai.DefaultModelName
-	if event.Model != "" {
-		model = event.Model
+	if modelCfg.Model != "" {
+		model = modelCfg.Model
 	}
+	// DDR-169: note which models answered, in case a fallback took over.
+	ctx, models := ai.WithModelRecorder(ctx)
 
 	// Update job status to "processing" in DynamoDB.
 	selJob := &store.SelectionJob{
-		ID:     event.JobID,
-		Status: "processing",
+		ID:          event.JobID,
+		Status:      "processing",
+		Model:       model,
+		Thinking:    modelCfg.Thinking,
+		Temperature: modelCfg.Temperature,
+		TopP:        modelCfg.TopP,
+		TripContext: event.TripContext,
+	}
+	// DDR-176: a re-run's pending record holds the job it revises and the
+	// user's locked and excluded keys. Without it a re-run would silently
+	// drop what the user pinned, so an unreadable record fails the job.
+	pending, err := sessionStore.GetSelectionJob(ctx, event.SessionID, event.JobID)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to read the pending job: %v", err)
+		selJob.Status = "error"
+		selJob.Error = errMsg
+		sessionStore.PutSelectionJob(ctx, event.SessionID, selJob)
+		return SelectionResult{JobID: event.JobID, Error: errMsg}, err
+	}
+	if pending != nil {
+		selJob.RerunOf, selJob.Version = pending.RerunOf, pending.Version
+		selJob.LockedKeys, selJob.ExcludedKeys = pending.LockedKeys, pending.ExcludedKeys
 	}
 	logger.Debug().Str("status", "processing").Msg("Updating DynamoDB job status")
 	if err := sessionStore.PutSelectionJob(ctx, event.SessionID, selJob); err != nil {
@@ -96,18 +150,21 @@ func handler(ctx context.Context, event SelectionEvent) (SelectionResult, error)
 
 		if !media.IsSupported(ext) {
 			logger.Debug().Str("key", key).Msg("Skipping unsupported file type")
+			selJob.AddUnprocessed(key, reasonUnsupported)
 			continue
 		}
 
 		localPath := filepath.Join(tmpDir, filename)
 		if err := s3util.DownloadToFile(ctx, s3Client, bucket, key, localPath); err != nil {
 			logger.Warn().Err(err).Str("key", key).Msg("Failed to download file, skipping")
+			selJob.AddUnprocessed(key, reasonDownload)
 			continue
 		}
 
 		mf, err := media.LoadMediaFile(localPath)
 		if err != nil {
 			logger.Warn().Err(err).Str("key", key).Msg("Failed to load media file, skipping")
+			selJob.AddUnprocessed(key, reasonUnreadable)
 			continue
 		}
 
@@ -128,6 +185,15 @@ func handler(ctx context.Context, event SelectionEvent) (SelectionResult, error)
 		pathToKeyMap[localPath] = key
 	}
 
+	if len(selJob.Unprocessed) > 0 {
+		logger.Warn().Int("unprocessed", len(selJob.Unprocessed)).Msg("Some files will not be considered for selection")
+		// Record them now so a job that later fails or waits on a batch
+		// still shows which files were dropped.
+		if err := sessionStore.PutSelectionJob(ctx, event.SessionID, selJob); err != nil {
+			logger.Warn().Err(err).Msg("Failed to record unprocessed files")
+		}
+	}
+
 	if len(allMediaFiles) == 0 {
 		errMsg := "no supported media files found"
 		selJob.Status = "error"
@@ -140,7 +206,7 @@ func handler(ctx context.Context, event SelectionEvent) (SelectionResult, error)
 	logger.Info().Int("count", len(allMediaFiles)).Msg("Loaded media files, calling Gemini")
 
 	// Initialize Gemini client and run selection.
-	logger.Debug().Str("model", model).Msg("Calling Gemini API for media selection")
+	logger.Debug().Str("model", model).Str("thinking", modelCfg.Thinking).Msg("Calling Gemini API for media selection")
 	client, err := ai.NewAIClient(ctx)
 	if err != nil {
 		errMsg := fmt.Sprintf("failed to create Gemini client: %v", err)
@@ -157,26 +223,56 @@ func handler(ctx context.Context, event SelectionEvent) (SelectionResult, error)
 
 	// Create compressed video store callback
 	storeCompressed := func(ctx context.Context, sessionID, originalKey, compressedPath string) (string, error) {
-		return s3util.UploadCompressedVideo(ctx, s3Client, mediaBucket, sessionID, originalKey, compressedPath)
+		enc, err := sessionKeys.ForSession(ctx, sessionID) // DDR-164
+		if err != nil {
+			return "", err
+		}
+		return s3util.UploadCompressedVideo(ctx, s3Client, mediaBucket, sessionID, originalKey, compressedPath, enc)
+	}
+
+	// Decisions and RAG profiles are per user (DDR-105).
+	owner, ownerErr := sessionStore.SessionOwner(ctx, event.SessionID)
+	if ownerErr != nil {
+		logger.Warn().Err(ownerErr).Msg("Failed to resolve session owner, recording decisions without a user")
 	}
+	ragUserID := rag.UserID(owner, event.SessionID)
 
 	// RAG retrieval — best effort
 	ragContext := ""
-	if ragQueryArn != "" {
-		ragCtx, ragErr := invokeRAGQuery(ctx, "selection", event.SessionID, event.TripContext)
+	if ragQueryArn != "" && featureFlags.Enabled(ctx, flags.RAGContext) {
+		ragCtx, ragErr := invokeRAGQuery(ctx, "selection", ragUserID, event.TripContext)
 		if ragErr != nil {
 			logger.Warn().Err(ragErr).Msg("RAG query failed, proceeding without context")
 		} else {
 			ragContext = ragCtx
 		}
 	}
+	if similar := retrieveSimilarDecisions(ctx, ragUserID, event.SessionID, s3Keys); similar != "" {
+		ragContext = strings.TrimSpace(ragContext + "\n\n" + similar)
+	}
 
 	// DDR-065: Create CacheManager for context caching across selection → description.
 	cacheMgr := ai.NewCacheManager(client)
 	defer cacheMgr.DeleteAll(ctx, event.SessionID)
 
+	// DDR-117: Resolve capture time zones so scenes split at local midnight,
+	// then DDR-114: group media into scenes by capture time and GPS.
+	media.ResolveTimeZones(allMediaFiles)
+	scenes := media.GroupScenes(allMediaFiles)
+	logger.Info().Int("scenes", len(scenes)).Int("files", len(allMediaFiles)).Msg("Precomputed scene groups")
+
+	if selJob.RerunOf != "" {
+		ctx = ai.WithSelectionConstraints(ctx, selectionConstraints(allMediaFiles, s3Keys, selJob.LockedKeys, selJob.ExcludedKeys))
+		logger.Info().
+			Str("rerunOf", selJob.RerunOf).
+			Int("version", selJob.Version).
+			Int("locked", len(selJob.LockedKeys)).
+			Int("excluded", len(selJob.ExcludedKeys)).
+			Msg("Re-running selection with the user's constraints")
+	}
+
 	economyMode := resolveEconomyMode(event.EconomyMode)
-	output, err := ai.AskMediaSelectionJSON(ctx, client, allMediaFiles, event.TripContext, model, event.SessionID, storeCompressed, keyMapper, cacheMgr, ragContext, economyMode)
+	output, err := ai.AskMediaSelectionJSON(ctx, client, allMediaFiles, event.TripContext, model, event.SessionID, storeCompressed, keyMapper, cacheMgr, ragContext, scenes, economyMode)
 	if err != nil {
 		errMsg := fmt.Sprintf("selection failed: %v", err)
 		selJob.Status = "error"
@@ -213,6 +309,8 @@ func handler(ctx context.Context, event SelectionEvent) (SelectionResult, error)
 			Justification:  sel.Justification,
 			ComparisonNote: sel.ComparisonNote,
 			ThumbnailURL:   fmt.Sprintf("/api/media/thumbnail?key=%s", thumbKey),
+			Scores:         selectionScores(sel.Scores),
+			AltText:        sel.AltText,
 		})
 	}
 
@@ -236,6 +334,12 @@ func handler(ctx context.Context, event SelectionEvent) (SelectionResult, error)
 		})
 	}
 
+	// Safety net: files missing from the AI's results are listed rather
+	// than dropped (mirrors triage).
+	for _, key := range selJob.AddUnjudged(s3Keys, reasonNotEvaluated) {
+		logger.Warn().Str("key", key).Msg("Media item missing from AI selection results")
+	}
+
 	for _, sg := range selResult.SceneGroups {
 		group := store.SceneGroup{
 			Name:      sg.Name,
@@ -264,56 +368,45 @@ func handler(ctx context.Context, event SelectionEvent) (SelectionResult, error)
 		selJob.SceneGroups = append(selJob.SceneGroups, group)
 	}
 
-	// Emit selection decisions to EventBridge — best effort
-	if ebClient != nil {
-		batcher := rag.NewBatchEmitter(ebClient)
-		for _, sel := range selResult.Selected {
-			idx := sel.Media - 1
-			if idx >= 0 && idx < len(s3Keys) {
-				batcher.Add(rag.ContentFeedback{
-					EventType:   rag.EventSelectionFinalized,
-					SessionID:   event.SessionID,
-					JobID:       event.JobID,
-					Timestamp:   time.Now().UTC().Format(time.RFC3339),
-					UserID:      event.SessionID,
-					MediaKey:    s3Keys[idx],
-					MediaType:   sel.Type,
-					AIVerdict:   "selected",
-					UserVerdict: "selected",
-					Reason:      sel.Justification,
-					Model:       "gemini",
-				})
-			}
-		}
-		for _, exc := range selResult.Excluded {
-			idx := exc.Media - 1
-			if idx >= 0 && idx < len(s3Keys) {
-				feedback := rag.ContentFeedback{
-					EventType:   rag.EventSelectionFinalized,
-					SessionID:   event.SessionID,
-					JobID:       event.JobID,
-					Timestamp:   time.Now().UTC().Format(time.RFC3339),
-					UserID:      event.SessionID,
-					MediaKey:    s3Keys[idx],
-					MediaType:   "Photo",
-					AIVerdict:   "excluded",
-					UserVerdict: "excluded",
-					Reason:      exc.Reason,
-					Model:       "gemini",
-				}
-				if ext := strings.ToLower(filepath.Ext(exc.Filename)); media.IsVideo(ext) {
-					feedback.MediaType = "Video"
-				}
-				batcher.Add(feedback)
-			}
-		}
-		if err := batcher.Flush(ctx); err != nil {
-			logger.Warn().Err(err).Msg("failed to flush selection feedback batch")
-		}
+	// DDR-187: Reels clips from the selected videos — best effort.
+	addVideoHighlights(ctx, client, event.SessionID, event.TripContext, selJob.Selected, allMediaFiles, logger)
+
+	// Record selection decisions for RAG (DDR-107) — best effort.
+	dw := decisions.NewWriter(decisions.EventBridge(ebClient), decisions.Job{
+		SessionID: event.SessionID, JobID: event.JobID, UserID: ragUserID, Model: model,
+	})
+	for _, sel := range selJob.Selected {
+		dw.RecordSelection(decisions.Selection{
+			MediaKey: sel.Key, Filename: sel.Filename, MediaType: sel.Type,
+			Selected: true, Reason: sel.Justification, SceneGroup: sel.Scene,
+		})
+	}
+	for _, exc := range selJob.Excluded {
+		dw.RecordSelection(decisions.Selection{
+			MediaKey: exc.Key, Filename: exc.Filename,
+			Reason: exc.Reason, ExclusionCategory: exc.Category,
+		})
+	}
+	if err := dw.Flush(ctx); err != nil {
+		logger.Warn().Err(err).Msg("failed to flush selection decisions")
 	}
 
-	// Write completed results to DynamoDB.
+	// Write completed results to DynamoDB, with what changed since the
+	// session's previous selection (DDR-158).
 	selJob.Status = "complete"
+	selJob.CompletedAt = time.Now().Unix()
+	selJob.ModelsUsed = models.Models()
+	if changes, err := sessionStore.SelectionChanges(ctx, event.SessionID, selJob); err != nil {
+		logger.Warn().Err(err).Msg("Failed to compare with the previous selection")
+	} else if changes != nil {
+		selJob.Changes = changes
+		logger.Info().
+			Str("previousJobId", changes.PreviousJobID).
+			Int("added", len(changes.Added)).
+			Int("dropped", len(changes.Dropped)).
+			Int("moved", len(changes.Moved)).
+			Msg("Compared with the previous selection")
+	}
 	if err := sessionStore.PutSelectionJob(ctx, event.SessionID, selJob); err != nil {
 		errMsg := fmt.Sprintf("failed to write results to DynamoDB: %v", err)
 		logger.Error().Err(err).Msg(errMsg)
@@ -324,17 +417,33 @@ func handler(ctx context.Context, event SelectionEvent) (SelectionResult, error)
 		Int("selected", len(selJob.Selected)).
 		Int("excluded", len(selJob.Excluded)).
 		Int("scenes", len(selJob.SceneGroups)).
+		Int("unprocessed", len(selJob.Unprocessed)).
 		Dur("duration", time.Since(handlerStart)).
 		Msg("Selection complete, results written to DynamoDB")
 
 	return SelectionResult{
-		JobID:           event.JobID,
-		SelectedCount:   len(selJob.Selected),
-		ExcludedCount:   len(selJob.Excluded),
-		SceneGroupCount: len(selJob.SceneGroups),
+		JobID:            event.JobID,
+		SelectedCount:    len(selJob.Selected),
+		ExcludedCount:    len(selJob.Excluded),
+		SceneGroupCount:  len(selJob.SceneGroups),
+		UnprocessedCount: len(selJob.Unprocessed),
 	}, nil
 }
 
+// selectionScores copies the AI's per-criterion breakdown (DDR-150); nil
+// when the model gave none or an invalid one.
+func selectionScores(s *ai.SelectionScores) *store.SelectionScores {
+	if s == nil {
+		return nil
+	}
+	return &store.SelectionScores{
+		Composition:      s.Composition,
+		StoryValue:       s.StoryValue,
+		Uniqueness:       s.Uniqueness,
+		TechnicalQuality: s.TechnicalQuality,
+	}
+}
+
 func invokeRAGQuery(ctx context.Context, queryType, userID, sessionContext string) (string, error) {
 	if lambdaClient == nil || ragQueryArn == "" {
 		return "", nil
@@ -359,3 +468,25 @@ func invokeRAGQuery(ctx context.Context, queryType, userID, sessionContext strin
 	}
 	return resp.RAGContext, nil
 }
+
+// selectionConstraints maps the user's locked and excluded keys onto the
+// loaded files; keys[i] is files[i]'s S3 key. Keys of files that could not
+// be loaded are dropped (DDR-176).
+func selectionConstraints(files []*media.MediaFile, keys, locked, excluded []string) ai.SelectionConstraints {
+	byKey := make(map[string]*media.MediaFile, len(keys))
+	for i, key := range keys {
+		byKey[key] = files[i]
+	}
+	var c ai.SelectionConstraints
+	for _, key := range locked {
+		if f := byKey[key]; f != nil {
+			c.Locked = append(c.Locked, f)
+		}
+	}
+	for _, key := range excluded {
+		if f := byKey[key]; f != nil {
+			c.Excluded = append(c.Excluded, f)
+		}
+	}
+	return c
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/rs/zerolog"
+	"google.golang.org/genai"
+
+	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/flags"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// maxHighlightVideos caps the selected videos one job cuts highlights
+// from, bounding the time and Gemini calls it adds to selection.
+const maxHighlightVideos = 5
+
+// Highlights run before the selection job is saved, so they must leave the
+// Lambda time to save it. A video is started only with highlightVideoBudget
+// left, and the step is cut off highlightSaveReserve before the deadline.
+const (
+	highlightVideoBudget = 3 * time.Minute
+	highlightSaveReserve = 30 * time.Second
+)
+
+// addVideoHighlights cuts Reels highlights from the selected videos longer
+// than a highlight (DDR-187) and records them on the items. files[i] is
+// the local file of media number i+1. Best effort: a video whose
+// highlights cannot be suggested, cut or stored is left without them, and
+// the step stops early rather than run into the Lambda deadline.
+func addVideoHighlights(ctx context.Context, client *genai.Client, sessionID, tripContext string, selected []store.SelectedItem, files []*media.MediaFile, logger zerolog.Logger) {
+	if !featureFlags.Enabled(ctx, flags.VideoHighlights) {
+		return
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline.Add(-highlightSaveReserve))
+		defer cancel()
+	}
+	start := time.Now()
+	videos, clips := 0, 0
+	for i := range selected {
+		item := &selected[i]
+		if item.Media < 1 || item.Media > len(files) {
+			continue
+		}
+		file := files[item.Media-1]
+		meta, _ := file.Metadata.(*media.VideoMetadata)
+		if !media.IsVideo(filepath.Ext(file.Path)) || meta == nil || meta.Duration <= media.MaxHighlightDuration {
+			continue
+		}
+		if videos == maxHighlightVideos {
+			logger.Info().Int("max", maxHighlightVideos).Msg("Highlight limit reached, skipping the remaining videos")
+			break
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < highlightVideoBudget {
+			logger.Warn().Dur("remaining", time.Until(deadline)).Msg("Lambda deadline near, skipping the remaining highlights")
+			break
+		}
+		videos++
+
+		highlights, err := ai.SuggestVideoHighlights(ctx, client, file, tripContext)
+		if err != nil {
+			logger.Warn().Err(err).Str("key", item.Key).Msg("Failed to suggest video highlights")
+			continue
+		}
+		for n, h := range highlights {
+			hl, err := cutHighlight(ctx, sessionID, item.Key, file.Path, meta, h, n+1)
+			if err != nil {
+				logger.Warn().Err(err).Str("key", item.Key).Dur("start", h.Clip.Start).Msg("Failed to cut video highlight")
+				continue
+			}
+			item.Highlights = append(item.Highlights, *hl)
+			clips++
+		}
+	}
+	if videos == 0 {
+		return
+	}
+
+	logger.Info().Int("videos", videos).Int("clips", clips).Dur("duration", time.Since(start)).Msg("Video highlights cut")
+	metrics.New("AiSocialMedia").
+		Dimension("JobType", "selection").
+		Metric("HighlightVideos", float64(videos), metrics.UnitCount).
+		Metric("HighlightClips", float64(clips), metrics.UnitCount).
+		Metric("HighlightDurationMs", float64(time.Since(start).Milliseconds()), metrics.UnitMilliseconds).
+		Property("sessionId", sessionID).
+		Flush()
+}
+
+// cutHighlight trims one highlight from the local video, re-encoded for
+// Reels, and stores it at {sessionId}/highlights/{fileName}-{n}.mp4. The
+// file name keeps its extension so a.mov and a.mp4 do not collide.
+func cutHighlight(ctx context.Context, sessionID, key, localPath string, meta *media.VideoMetadata, h ai.VideoHighlight, n int) (*store.VideoHighlight, error) {
+	enc, err := sessionKeys.ForSession(ctx, sessionID) // DDR-164
+	if err != nil {
+		return nil, fmt.Errorf("session encryption: %w", err)
+	}
+	clipPath, size, cleanup, err := media.TrimVideo(ctx, localPath, meta, h.Clip, media.ProfileInstagramReel)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	f, err := os.Open(clipPath)
+	if err != nil {
+		return nil, fmt.Errorf("open clip: %w", err)
+	}
+	defer f.Close()
+
+	clipKey := fmt.Sprintf("%s/highlights/%s-%d.mp4", sessionID, filepath.Base(key), n)
+	contentType := "video/mp4"
+	_, err = s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
+		Bucket:      &mediaBucket,
+		Key:         &clipKey,
+		Body:        f,
+		ContentType: &contentType,
+		Tagging:     s3util.ProjectTagging(),
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("upload clip: %w", err)
+	}
+	return &store.VideoHighlight{
+		Key:     clipKey,
+		StartMs: h.Clip.Start.Milliseconds(),
+		EndMs:   h.Clip.End.Milliseconds(),
+		Reason:  h.Reason,
+		Size:    size,
+	}, nil
+}
@@ -5,6 +5,9 @@
 // thumbnails, sends them to Gemini for structured JSON selection analysis,
 // and writes the complete results to DynamoDB.
 //
+// The API also invokes it directly with a "selection-feedback" event to
+// revise a completed selection from the user's feedback (DDR-177).
+//
 // Container: Heavy (Dockerfile.heavy — includes ffmpeg for video compression)
 // Memory: 4 GB
 // Timeout: 15 minutes
@@ -18,18 +21,23 @@ import (
 	"os"
 	"time"
 
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
 	lambdasvc "github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/rdsdata"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
 
 	"github.com/fpang/ai-social-media-helper/internal/ai"
 	"github.com/fpang/ai-social-media-helper/internal/bootstrap"
+	"github.com/fpang/ai-social-media-helper/internal/flags"
 	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/fpang/ai-social-media-helper/internal/rag"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 	"github.com/rs/zerolog/log"
 )
@@ -38,11 +46,18 @@ import (
 var (
 	s3Client      *s3.Client
 	presignClient *s3.PresignClient
+	sessionKeys   *s3util.SessionKeys // DDR-164
 	sessionStore  store.SessionStore
 	mediaBucket   string
 	ebClient      *eventbridge.Client
 	lambdaClient  *lambdasvc.Client
 	ragQueryArn   string
+	featureFlags  *flags.Set // DDR-132
+
+	// Similar-photo retrieval (DDR-109); nil when Aurora is not configured.
+	ddbClient    *dynamodb.Client
+	stagingTable string
+	ragDataAPI   *rag.DataAPIClient
 )
 
 var coldStart = true
@@ -59,6 +74,7 @@ func init() {
 
 	s3Client = s3.NewFromConfig(cfg)
 	presignClient = s3.NewPresignClient(s3Client)
+	sessionKeys = s3util.NewSessionKeys(kms.NewFromConfig(cfg))
 	mediaBucket = os.Getenv("MEDIA_BUCKET_NAME")
 	if mediaBucket == "" {
 		log.Fatal().Msg("MEDIA_BUCKET_NAME environment variable is required")
@@ -69,28 +85,16 @@ func init() {
 	if tableName == "" {
 		tableName = "media-selection-sessions"
 	}
-	ddbClient := dynamodb.NewFromConfig(cfg)
-	sessionStore = store.NewDynamoStore(ddbClient, tableName)
+	ddbClient = dynamodb.NewFromConfig(cfg)
+	dynamoStore := store.NewDynamoStore(ddbClient, tableName)
+	sessionStore = dynamoStore
+	metrics.SetUsageStore(dynamoStore) // DDR-142
 
 	// Load Gemini API key and GCP SA from SSM Parameter Store if not set.
 	ssmClient := ssm.NewFromConfig(cfg)
-	if os.Getenv("GEMINI_API_KEY") == "" {
-		paramName := os.Getenv("SSM_API_KEY_PARAM")
-		if paramName == "" {
-			paramName = "/ai-social-media/prod/gemini-api-key"
-		}
-		ssmStart := time.Now()
-		result, err := ssmClient.GetParameter(context.Background(), &ssm.GetParameterInput{
-			Name:           &paramName,
-			WithDecryption: aws.Bool(true),
-		})
-		if err != nil {
-			log.Fatal().Err(err).Str("param", paramName).Msg("Failed to read API key from SSM")
-		}
-		os.Setenv("GEMINI_API_KEY", *result.Parameter.Value)
-		log.Debug().Str("param", paramName).Dur("elapsed", time.Since(ssmStart)).Msg("Gemini API key loaded from SSM")
-	}
+	bootstrap.LoadGeminiKey(ssmClient) // DDR-172: refreshed after rotation
 	bootstrap.LoadGCPServiceAccountKey(ssmClient)
+	featureFlags = bootstrap.InitFlags(ssmClient)
 	_ = ai.LoadGCPServiceAccount()
 
 	ebClient = eventbridge.NewFromConfig(cfg)
@@ -111,15 +115,25 @@ func init() {
 		}
 	}
 
+	stagingTable = os.Getenv("STAGING_TABLE_NAME")
+	clusterARN := os.Getenv("AURORA_CLUSTER_ARN")
+	secretARN := os.Getenv("AURORA_SECRET_ARN")
+	database := os.Getenv("AURORA_DATABASE_NAME")
+	if clusterARN != "" && secretARN != "" && database != "" {
+		ragDataAPI = rag.NewDataAPIClient(rdsdata.NewFromConfig(cfg), clusterARN, secretARN, database)
+	}
+
 	// Emit consolidated cold-start log for troubleshooting.
 	logging.NewStartupLogger("selection-lambda").
 		InitDuration(time.Since(initStart)).
 		S3Bucket("mediaBucket", mediaBucket).
 		DynamoTable("sessions", tableName).
+		DynamoTable("ragStaging", stagingTable).
 		SSMParam("geminiApiKey", logging.EnvOrDefault("SSM_API_KEY_PARAM", "/ai-social-media/prod/gemini-api-key")).
+		SSMParam("featureFlags", logging.EnvOrDefault("SSM_FEATURE_FLAGS_PARAM", flags.DefaultSSMParam)).
 		Log()
 }
 
 func main() {
-	lambda.Start(handler)
+	bootstrap.Start(handler)
 }
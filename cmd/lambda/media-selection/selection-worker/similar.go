@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/rag"
+)
+
+const (
+	// similarNeighbors is how many past decisions are shown per photo.
+	similarNeighbors = 3
+
+	// minNeighborSimilarity drops neighbors that only loosely resemble the
+	// photo; cosine similarity between unrelated photos is rarely above 0.7.
+	minNeighborSimilarity = 0.8
+
+	// similarTimeout bounds the whole retrieval so a slow or stopped Aurora
+	// cluster cannot hold up selection.
+	similarTimeout = 20 * time.Second
+)
+
+// retrieveSimilarDecisions finds the user's past keep/discard decisions on
+// photos that look like the ones in this session and formats them as
+// few-shot context for the selection prompt (DDR-109). Best effort: Aurora
+// only runs during the daily batch (DDR-068), so an unreachable cluster
+// returns "" after the first failed query.
+func retrieveSimilarDecisions(ctx context.Context, userID, sessionID string, mediaKeys []string) string {
+	if ragDataAPI == nil {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(ctx, similarTimeout)
+	defer cancel()
+	logger := log.With().Str("sessionId", sessionID).Logger()
+
+	embeddings, err := sessionEmbeddings(ctx, sessionID)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Similar-photo retrieval skipped: embeddings unavailable")
+		return ""
+	}
+	wanted := make(map[string]bool, len(mediaKeys))
+	for _, k := range mediaKeys {
+		wanted[k] = true
+	}
+
+	neighbors := make(map[string][]rag.NeighborDecision)
+	for _, e := range embeddings {
+		if !wanted[e.MediaKey] {
+			continue
+		}
+		ns, err := ragDataAPI.QueryNeighborDecisions(ctx, userID, sessionID, e.Embedding, similarNeighbors)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Similar-photo retrieval stopped: Aurora query failed")
+			break
+		}
+		for _, n := range ns {
+			if n.Similarity >= minNeighborSimilarity {
+				neighbors[e.MediaKey] = append(neighbors[e.MediaKey], n)
+			}
+		}
+	}
+	logger.Info().Int("embeddings", len(embeddings)).Int("withNeighbors", len(neighbors)).Msg("Similar-photo retrieval complete")
+	return rag.FormatNeighborContext(mediaKeys, neighbors)
+}
+
+// sessionEmbeddings reads the session's thumbnail embeddings from staging,
+// falling back to Aurora when the daily batch has already moved them.
+func sessionEmbeddings(ctx context.Context, sessionID string) ([]rag.MediaEmbedding, error) {
+	if stagingTable != "" {
+		staged, err := rag.ReadStagedEmbeddings(ctx, ddbClient, stagingTable, sessionID)
+		if err != nil {
+			return nil, err
+		}
+		if len(staged) > 0 {
+			return staged, nil
+		}
+	}
+	return ragDataAPI.SessionEmbeddings(ctx, sessionID)
+}
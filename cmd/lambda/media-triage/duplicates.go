@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// detectDuplicateSessions records the session's signature and returns the
+// owner's other sessions that probably hold the same trip (DDR-113). Errors
+// are logged and yield no duplicates; triage never fails because of them.
+func detectDuplicateSessions(ctx context.Context, owner, sessionID string, files []store.FileResult) []store.DuplicateSession {
+	sig := store.NewSessionSignature(files)
+	if err := sessionStore.PutSessionSignature(ctx, sessionID, sig); err != nil {
+		log.Warn().Err(err).Str("sessionId", sessionID).Msg("Failed to store session signature")
+	}
+
+	duplicates, err := sessionStore.FindDuplicateSessions(ctx, owner, sessionID, sig)
+	if err != nil {
+		log.Warn().Err(err).Str("sessionId", sessionID).Msg("Duplicate session check failed")
+		return nil
+	}
+	for _, d := range duplicates {
+		log.Info().
+			Str("sessionId", sessionID).
+			Str("duplicateOf", d.SessionID).
+			Strs("reasons", d.Reasons).
+			Msg("Probable duplicate session detected")
+	}
+	return duplicates
+}
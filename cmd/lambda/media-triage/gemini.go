@@ -15,11 +15,11 @@ import (
 func deleteOriginals(ctx context.Context, sessionID string, originalKeys []string) {
 	deleted := 0
 	for _, key := range originalKeys {
-		// Skip keys under thumbnails/, compressed/, or processed/ — those are generated artifacts.
+		// Skip keys under thumbnails/, previews/, compressed/, processed/, or debug/ — those are generated artifacts.
 		parts := strings.SplitN(key, "/", 2)
 		if len(parts) == 2 {
 			suffix := parts[1]
-			if strings.HasPrefix(suffix, "thumbnails/") || strings.HasPrefix(suffix, "compressed/") || strings.HasPrefix(suffix, "processed/") {
+			if strings.HasPrefix(suffix, "thumbnails/") || strings.HasPrefix(suffix, "previews/") || strings.HasPrefix(suffix, "compressed/") || strings.HasPrefix(suffix, "processed/") || strings.HasPrefix(suffix, "debug/") {
 				continue
 			}
 		}
@@ -13,6 +13,9 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/artifacts"
+	"github.com/fpang/ai-social-media-helper/internal/decisions"
+	"github.com/fpang/ai-social-media-helper/internal/flags"
 	"github.com/fpang/ai-social-media-helper/internal/media"
 	"github.com/fpang/ai-social-media-helper/internal/jobs"
 	"github.com/fpang/ai-social-media-helper/internal/metrics"
@@ -37,6 +40,18 @@ func resolveEconomyMode(eventEconomy bool) bool {
 func handleTriageRun(ctx context.Context, event TriageEvent) (interface{}, error) {
 	jobStart := time.Now()
 
+	// DDR-118: collect per-job telemetry; PutTriageJob records it.
+	ctx, _ = metrics.WithCollector(ctx)
+
+	// DDR-106: keep prompts, raw responses, and compressed videos when requested.
+	if event.DebugArtifacts {
+		if enc, err := sessionKeys.ForSession(ctx, event.SessionID); err != nil {
+			log.Warn().Err(err).Str("sessionId", event.SessionID).Msg("Session encryption unavailable — not keeping debug artifacts")
+		} else {
+			ctx = artifacts.WithRecorder(ctx, artifacts.NewS3Recorder(s3Client, mediaBucket, artifacts.SessionPrefix(event.SessionID, event.JobID), enc))
+		}
+	}
+
 	client, err := ai.NewAIClient(ctx)
 	if err != nil {
 		return nil, jobs.SetJobError(ctx, event.SessionID, event.JobID, fmt.Sprintf("Failed to create Gemini client: %v", err), func(ctx context.Context, sessionID, jobID, errMsg string) error {
@@ -81,6 +96,7 @@ func handleTriageRun(ctx context.Context, event TriageEvent) (interface{}, error
 	// Build MediaFile list from file results using presigned URLs
 	var allMediaFiles []*media.MediaFile
 	var s3Keys []string
+	var sources []store.FileResult // sources[i] is allMediaFiles[i]'s manifest entry
 	pathToKeyMap := make(map[string]string)
 
 	for _, fr := range validFiles {
@@ -117,10 +133,20 @@ func handleTriageRun(ctx context.Context, event TriageEvent) (interface{}, error
 			MIMEType:     mimeType,
 			Size:         fr.FileSize,
 			PresignedURL: url,
+			QualityIssue: fr.QualityIssue,
+			ContentHash:  fr.ContentHash,
+			Metadata:     media.MetadataFromManifest(fr.Metadata), // dates for DDR-117, animation for DDR-120
+		}
+		if h, err := media.ParseDHash(fr.DHash); err == nil {
+			mf.DHash = h
+		}
+		if fr.Sample != nil {
+			mf.Sample = loadVideoSample(ctx, fr.Sample)
 		}
 
 		allMediaFiles = append(allMediaFiles, mf)
 		s3Keys = append(s3Keys, fr.OriginalKey)
+		sources = append(sources, fr)
 		pathToKeyMap[fr.Filename] = fr.OriginalKey
 	}
 
@@ -131,10 +157,20 @@ func handleTriageRun(ctx context.Context, event TriageEvent) (interface{}, error
 		})
 	}
 
-	model := event.Model
+	// DDR-155, DDR-170: the job's model settings; thinking falls back to
+	// the deployment's.
+	ctx, modelCfg := ai.ApplyModelConfig(ctx, event.ModelConfig)
+	model := modelCfg.Model
 	if model == "" {
 		model = ai.DefaultModelName
 	}
+	thinking := modelCfg.Thinking
+	// DDR-166: files judged before keep their verdicts unless the job opts out.
+	if !event.NoCache {
+		ctx = ai.WithResponseCache(ctx, sessionStore)
+	}
+	// DDR-169: note which models answered, in case a fallback took over.
+	ctx, models := ai.WithModelRecorder(ctx)
 
 	keyMapper := func(localPath string) string {
 		return pathToKeyMap[localPath]
@@ -142,18 +178,33 @@ func handleTriageRun(ctx context.Context, event TriageEvent) (interface{}, error
 
 	// No storeCompressed callback needed — files are already processed (DDR-061)
 	storeCompressed := func(ctx context.Context, sessionID, originalKey, compressedPath string) (string, error) {
-		return s3util.UploadCompressedVideo(ctx, s3Client, mediaBucket, sessionID, originalKey, compressedPath)
+		enc, err := sessionKeys.ForSession(ctx, sessionID) // DDR-164
+		if err != nil {
+			return "", err
+		}
+		return s3util.UploadCompressedVideo(ctx, s3Client, mediaBucket, sessionID, originalKey, compressedPath, enc)
 	}
 
-	sessionStore.PutTriageJob(ctx, event.SessionID, &store.TriageJob{
+	// DDR-174: every file starts queued and moves on as its batch runs.
+	progress := newTriageProgress(ctx, event.SessionID, store.TriageJob{
 		ID: event.JobID, Status: "processing", Phase: "analyzing",
-		TotalFiles: len(allMediaFiles),
-	})
+		TotalFiles: len(allMediaFiles), Model: model, Thinking: thinking,
+		Temperature: modelCfg.Temperature, TopP: modelCfg.TopP, Keyframes: event.Keyframes,
+	}, allMediaFiles, sources)
+	progress.start()
+	ctx = ai.WithFileProgress(ctx, progress.files)
+
+	// Decisions and RAG profiles are per user (DDR-105).
+	owner, err := sessionStore.SessionOwner(ctx, event.SessionID)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to resolve session owner, recording decisions without a user")
+	}
+	ragUserID := rag.UserID(owner, event.SessionID)
 
 	// RAG retrieval — best effort
 	ragContext := ""
-	if ragQueryArn != "" {
-		ragCtx, err := invokeRAGQuery(ctx, "triage", event.SessionID, "")
+	if ragQueryArn != "" && featureFlags.Enabled(ctx, flags.RAGContext) {
+		ragCtx, err := invokeRAGQuery(ctx, "triage", ragUserID, "")
 		if err != nil {
 			log.Warn().Err(err).Msg("RAG query failed, proceeding without context")
 		} else {
@@ -161,20 +212,19 @@ func handleTriageRun(ctx context.Context, event TriageEvent) (interface{}, error
 		}
 	}
 
+	// DDR-113: flag probable duplicate sessions of the same trip — best effort.
+	duplicates := detectDuplicateSessions(ctx, owner, event.SessionID, validFiles)
+
+	// DDR-117: give files without a capture zone the zone of their neighbours.
+	media.ResolveTimeZones(allMediaFiles)
+
 	economyMode := resolveEconomyMode(event.EconomyMode)
-	log.Debug().Int("fileCount", len(allMediaFiles)).Str("model", model).Bool("economyMode", economyMode).Msg("Calling AskMediaTriage (DDR-061: presigned URLs from manifest)")
+	log.Debug().Int("fileCount", len(allMediaFiles)).Str("model", model).Str("thinking", thinking).Bool("economyMode", economyMode).Msg("Calling AskMediaTriage (DDR-061: presigned URLs from manifest)")
 	// DDR-065: Create CacheManager for context caching within triage batches (not used in economy mode).
 	cacheMgr := ai.NewCacheManager(client)
 	defer cacheMgr.DeleteAll(ctx, event.SessionID)
 
-	output, err := ai.AskMediaTriage(ctx, client, allMediaFiles, model, event.SessionID, storeCompressed, keyMapper, cacheMgr, ragContext, economyMode, func(batch, totalBatches int) {
-		sessionStore.PutTriageJob(ctx, event.SessionID, &store.TriageJob{
-			ID: event.JobID, Status: "processing", Phase: "analyzing",
-			TotalFiles:     len(allMediaFiles),
-			TriageBatch:    batch,
-			TriageBatchTotal: totalBatches,
-		})
-	})
+	output, err := ai.AskMediaTriage(ctx, client, allMediaFiles, model, event.SessionID, storeCompressed, keyMapper, cacheMgr, ragContext, economyMode, progress.batch)
 	if err != nil {
 		return nil, jobs.SetJobError(ctx, event.SessionID, event.JobID, fmt.Sprintf("Triage failed: %v", err), func(ctx context.Context, sessionID, jobID, errMsg string) error {
 			sessionStore.PutTriageJob(ctx, sessionID, &store.TriageJob{ID: jobID, Status: "error", Error: errMsg})
@@ -190,18 +240,6 @@ func handleTriageRun(ctx context.Context, event TriageEvent) (interface{}, error
 
 	triageResults := output.Results
 
-	// Build thumbnail URL and processed key maps from file results
-	thumbnailURLs := make(map[int]string)
-	processedKeys := make(map[int]string)
-	for i, fr := range validFiles {
-		if fr.ThumbnailKey != "" {
-			thumbnailURLs[i] = fmt.Sprintf("/api/media/thumbnail?key=%s", fr.ThumbnailKey)
-		}
-		if fr.ProcessedKey != "" {
-			processedKeys[i] = fr.ProcessedKey
-		}
-	}
-
 	// Map results to store items
 	var keep, discard []store.TriageItem
 	seen := make(map[int]bool)
@@ -211,22 +249,7 @@ func handleTriageRun(ctx context.Context, event TriageEvent) (interface{}, error
 			continue
 		}
 		seen[idx] = true
-		key := s3Keys[idx]
-
-		thumbURL := fmt.Sprintf("/api/media/thumbnail?key=%s", key)
-		if url, ok := thumbnailURLs[idx]; ok {
-			thumbURL = url
-		}
-
-		item := store.TriageItem{
-			Media:        tr.Media,
-			Filename:     tr.Filename,
-			Key:          key,
-			ProcessedKey: processedKeys[idx],
-			Saveable:     tr.Saveable,
-			Reason:       tr.Reason,
-			ThumbnailURL: thumbURL,
-		}
+		item := triageItem(sources[idx], tr)
 		if tr.Saveable {
 			keep = append(keep, item)
 		} else {
@@ -237,63 +260,44 @@ func handleTriageRun(ctx context.Context, event TriageEvent) (interface{}, error
 	// Safety net: missing items default to "keep"
 	for i, mf := range allMediaFiles {
 		if !seen[i] {
-			key := s3Keys[i]
 			log.Warn().Int("media", i+1).Str("filename", filepath.Base(mf.Path)).Msg("Media item missing from AI triage results — defaulting to keep")
-
-			thumbURL := fmt.Sprintf("/api/media/thumbnail?key=%s", key)
-			if url, ok := thumbnailURLs[i]; ok {
-				thumbURL = url
-			}
-
-			keep = append(keep, store.TriageItem{
-				Media:        i + 1,
-				Filename:     filepath.Base(mf.Path),
-				Key:          key,
-				ProcessedKey: processedKeys[i],
-				Saveable:     true,
-				Reason:       "Not evaluated by AI — kept by default",
-				ThumbnailURL: thumbURL,
-			})
+			keep = append(keep, triageItem(sources[i], ai.TriageResult{
+				Media:    i + 1,
+				Filename: filepath.Base(mf.Path),
+				Saveable: true,
+				Reason:   "Not evaluated by AI — kept by default",
+			}))
 		}
 	}
 
+	// DDR-186: how the videos were judged, to compare keyframes with whole clips.
+	videoStats := ai.SummarizeVideoTriage(allMediaFiles, triageResults)
+
 	sessionStore.PutTriageJob(ctx, event.SessionID, &store.TriageJob{
 		ID: event.JobID, Status: "complete", Keep: keep, Discard: discard,
+		DuplicateSessions: duplicates, Model: model, Thinking: thinking,
+		Temperature: modelCfg.Temperature, TopP: modelCfg.TopP,
+		ModelsUsed: models.Models(), Keyframes: event.Keyframes,
+		VideoStats: triageVideoStats(videoStats),
 	})
 
-	// Emit triage decisions to EventBridge — best effort
-	if ebClient != nil {
-		batcher := rag.NewBatchEmitter(ebClient)
-		for _, tr := range triageResults {
-			verdict := "discard"
-			if tr.Saveable {
-				verdict = "keep"
-			}
-			mediaType := "Photo"
-			if ext := strings.ToLower(filepath.Ext(tr.Filename)); media.IsVideo(ext) {
-				mediaType = "Video"
-			}
-			mediaKey := tr.Filename
-			if idx := tr.Media - 1; idx >= 0 && idx < len(s3Keys) {
-				mediaKey = s3Keys[idx]
-			}
-			batcher.Add(rag.ContentFeedback{
-				EventType:   rag.EventTriageFinalized,
-				SessionID:   event.SessionID,
-				JobID:       event.JobID,
-				Timestamp:   time.Now().UTC().Format(time.RFC3339),
-				UserID:      event.SessionID,
-				MediaKey:    mediaKey,
-				MediaType:   mediaType,
-				AIVerdict:   verdict,
-				UserVerdict: verdict,
-				Reason:      tr.Reason,
-				Model:       "gemini",
-			})
-		}
-		if err := batcher.Flush(ctx); err != nil {
-			log.Warn().Err(err).Msg("failed to flush triage feedback batch")
+	// Record triage decisions for RAG (DDR-107) — best effort. Overrides made
+	// during review are recorded later by the API.
+	dw := decisions.NewWriter(decisions.EventBridge(ebClient), decisions.Job{
+		SessionID: event.SessionID, JobID: event.JobID, UserID: ragUserID, Model: model,
+	})
+	for _, tr := range triageResults {
+		mediaKey := tr.Filename
+		if idx := tr.Media - 1; idx >= 0 && idx < len(s3Keys) {
+			mediaKey = s3Keys[idx]
 		}
+		dw.RecordTriage(decisions.Triage{
+			MediaKey: mediaKey, Filename: tr.Filename,
+			AIKeep: tr.Saveable, UserKeep: tr.Saveable, Reason: tr.Reason,
+		})
+	}
+	if err := dw.Flush(ctx); err != nil {
+		log.Warn().Err(err).Msg("failed to flush triage decisions")
 	}
 
 	log.Info().Int("keep", len(keep)).Int("discard", len(discard)).Dur("duration", time.Since(jobStart)).Msg("Triage complete (DDR-061)")
@@ -313,6 +317,7 @@ func handleTriageRun(ctx context.Context, event TriageEvent) (interface{}, error
 		Property("jobId", event.JobID).
 		Property("sessionId", event.SessionID).
 		Flush()
+	emitVideoTriageMetrics(event, videoStats)
 
 	return nil, nil
 }
@@ -341,3 +346,22 @@ func invokeRAGQuery(ctx context.Context, queryType, userID, sessionContext strin
 	}
 	return resp.RAGContext, nil
 }
+
+// triageItem is the stored verdict tr for the file read from fr.
+func triageItem(fr store.FileResult, tr ai.TriageResult) store.TriageItem {
+	thumbURL := fmt.Sprintf("/api/media/thumbnail?key=%s", fr.OriginalKey)
+	if fr.ThumbnailKey != "" {
+		thumbURL = fmt.Sprintf("/api/media/thumbnail?key=%s", fr.ThumbnailKey)
+	}
+	return store.TriageItem{
+		Media:        tr.Media,
+		Filename:     tr.Filename,
+		Key:          fr.OriginalKey,
+		ProcessedKey: fr.ProcessedKey,
+		Saveable:     tr.Saveable,
+		Reason:       tr.Reason,
+		ThumbnailURL: thumbURL,
+		PreFiltered:  tr.PreFiltered,
+		Sampled:      tr.Sampled,
+	}
+}
@@ -19,7 +19,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
 	lambdasvc "github.com/aws/aws-sdk-go-v2/service/lambda"
@@ -30,7 +29,9 @@ import (
 	"github.com/fpang/ai-social-media-helper/internal/ai"
 	"github.com/fpang/ai-social-media-helper/internal/media"
 	"github.com/fpang/ai-social-media-helper/internal/bootstrap"
+	"github.com/fpang/ai-social-media-helper/internal/flags"
 	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 )
 
@@ -41,11 +42,13 @@ var (
 	s3Client         *s3.Client
 	presignClient    *s3.PresignClient
 	mediaBucket      string
+	sessionKeys      *s3util.SessionKeys // DDR-164
 	sessionStore     *store.DynamoStore
 	fileProcessStore *store.FileProcessingStore
 	ebClient         *eventbridge.Client
 	lambdaClient     *lambdasvc.Client
 	ragQueryArn      string
+	featureFlags     *flags.Set // DDR-132
 )
 
 func init() {
@@ -57,6 +60,7 @@ func init() {
 	s3Client = s3s.Client
 	presignClient = s3s.Presigner
 	mediaBucket = s3s.Bucket
+	sessionKeys = s3s.Keys
 	sessionStore = bootstrap.InitDynamo(awsClients.Config, "DYNAMO_TABLE_NAME")
 	bootstrap.LoadGeminiKey(awsClients.SSM)
 	bootstrap.LoadGCPServiceAccountKey(awsClients.SSM)
@@ -67,6 +71,7 @@ func init() {
 		fileProcessStore = store.NewFileProcessingStore(sessionStore.Client(), fpTableName)
 	}
 
+	featureFlags = bootstrap.InitFlags(awsClients.SSM)
 	ebClient = eventbridge.NewFromConfig(awsClients.Config)
 	lambdaClient = lambdasvc.NewFromConfig(awsClients.Config)
 	ragQueryArn = os.Getenv("RAG_QUERY_LAMBDA_ARN")
@@ -88,11 +93,12 @@ func init() {
 		S3Bucket("mediaBucket", mediaBucket).
 		DynamoTable("sessions", os.Getenv("DYNAMO_TABLE_NAME")).
 		SSMParam("geminiApiKey", logging.EnvOrDefault("SSM_API_KEY_PARAM", "/ai-social-media/prod/gemini-api-key")).
+		SSMParam("featureFlags", logging.EnvOrDefault("SSM_FEATURE_FLAGS_PARAM", flags.DefaultSSMParam)).
 		Log()
 }
 
 func main() {
-	lambda.Start(handler)
+	bootstrap.Start(handler)
 }
 
 func handler(ctx context.Context, event TriageEvent) (interface{}, error) {
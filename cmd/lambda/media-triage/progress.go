@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// triageProgress is a triage job's record while it is analyzing. Batch
+// counts and file stages are reported from different goroutines; each
+// update writes the whole record under one lock, so neither overwrites the
+// other with stale fields (DDR-174). Verdicts known so far are written as
+// partial Keep and Discard lists, and the status becomes "partial" with the
+// first of them (DDR-175).
+type triageProgress struct {
+	mu        sync.Mutex
+	ctx       context.Context
+	sessionID string
+	job       store.TriageJob
+	index     map[*media.MediaFile]int // file → position in job.FileProgress
+	sources   []store.FileResult       // sources[i] is file i's manifest entry
+	judged    map[int]bool             // files whose verdict is in Keep or Discard
+}
+
+// newTriageProgress starts every file in files as queued. job holds the
+// fields written with every update; sources are the files' manifest
+// entries, in the same order.
+func newTriageProgress(ctx context.Context, sessionID string, job store.TriageJob, files []*media.MediaFile, sources []store.FileResult) *triageProgress {
+	p := &triageProgress{
+		ctx: ctx, sessionID: sessionID, job: job,
+		index: make(map[*media.MediaFile]int, len(files)), sources: sources, judged: make(map[int]bool),
+	}
+	p.job.FileProgress = make([]store.TriageFileProgress, len(files))
+	for i, f := range files {
+		p.job.FileProgress[i] = store.TriageFileProgress{Filename: filepath.Base(f.Path), Status: store.TriageFileQueued}
+		p.index[f] = i
+	}
+	return p
+}
+
+// start writes the record with every file queued.
+func (p *triageProgress) start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.write()
+}
+
+// files is the ai.FileProgressFunc. A file never moves back a stage, and
+// its first verdict is the one kept.
+func (p *triageProgress) files(files []*media.MediaFile, stage string, verdicts []ai.TriageResult) {
+	status := store.TriageFileDownloaded
+	if stage == ai.FileAnalyzed {
+		status = store.TriageFileAnalyzed
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, f := range files {
+		i, ok := p.index[f]
+		if !ok || p.job.FileProgress[i].Status == store.TriageFileAnalyzed {
+			continue
+		}
+		p.job.FileProgress[i].Status = status
+	}
+	for _, v := range verdicts {
+		i, ok := p.index[files[v.Media-1]]
+		if !ok || p.judged[i] {
+			continue
+		}
+		p.judged[i] = true
+		v.Media = i + 1
+		if item := triageItem(p.sources[i], v); v.Saveable {
+			p.job.Keep = append(p.job.Keep, item)
+		} else {
+			p.job.Discard = append(p.job.Discard, item)
+		}
+		p.job.Status = "partial"
+	}
+	p.write()
+}
+
+// batch is the ai.BatchProgressFunc.
+func (p *triageProgress) batch(done, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.job.TriageBatch, p.job.TriageBatchTotal = done, total
+	p.write()
+}
+
+// write saves the record. The caller holds p.mu.
+func (p *triageProgress) write() {
+	job := p.job
+	job.FileProgress = append([]store.TriageFileProgress(nil), p.job.FileProgress...)
+	if err := sessionStore.PutTriageJob(p.ctx, p.sessionID, &job); err != nil {
+		log.Warn().Err(err).Str("jobId", job.ID).Msg("Failed to save triage progress")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// loadVideoSample fetches the frame sheet MediaProcess rendered for a long
+// video (DDR-162), or for any video in keyframe mode (DDR-186). Returns nil when it cannot be read, in which case the
+// whole video is sent as before.
+func loadVideoSample(ctx context.Context, ref *store.VideoSample) *media.VideoSample {
+	out, err := s3Client.GetObject(ctx, &s3.GetObjectInput{Bucket: &mediaBucket, Key: &ref.Key})
+	if err != nil {
+		log.Warn().Err(err).Str("sampleKey", ref.Key).Msg("Failed to fetch video sample, sending the whole video")
+		return nil
+	}
+	defer out.Body.Close()
+	sheet, err := io.ReadAll(out.Body)
+	if err != nil {
+		log.Warn().Err(err).Str("sampleKey", ref.Key).Msg("Failed to read video sample, sending the whole video")
+		return nil
+	}
+
+	sample := &media.VideoSample{
+		Sheet:     sheet,
+		Duration:  time.Duration(ref.DurationMs) * time.Millisecond,
+		Keyframes: ref.Keyframes,
+	}
+	for _, ms := range ref.OffsetsMs {
+		sample.Offsets = append(sample.Offsets, time.Duration(ms)*time.Millisecond)
+	}
+	if ref.MeanVolumeDB != nil && ref.MaxVolumeDB != nil {
+		sample.Loudness = &media.AudioLoudness{MeanDB: *ref.MeanVolumeDB, MaxDB: *ref.MaxVolumeDB}
+	}
+	return sample
+}
+
+// triageVideoStats converts a job's video summary for the job record, nil
+// when the job had no videos.
+func triageVideoStats(st ai.VideoTriageStats) *store.TriageVideoStats {
+	if st.Videos == 0 {
+		return nil
+	}
+	return &store.TriageVideoStats{
+		Videos:      st.Videos,
+		Keyframed:   st.Keyframed,
+		Sampled:     st.Sampled,
+		Kept:        st.Kept,
+		SourceBytes: st.SourceBytes,
+		SheetBytes:  st.SheetBytes,
+	}
+}
+
+// emitVideoTriageMetrics records a job's videos by triage mode, so keyframe
+// jobs can be compared with whole-clip jobs on bytes sent and keep rate
+// (DDR-186). Gemini input tokens per job are on the job's telemetry.
+func emitVideoTriageMetrics(event TriageEvent, st ai.VideoTriageStats) {
+	if st.Videos == 0 {
+		return
+	}
+	metrics.New("AiSocialMedia").
+		Dimension("JobType", "triage").
+		Dimension("VideoMode", videoMode(event.Keyframes)).
+		Metric("VideosTriaged", float64(st.Videos), metrics.UnitCount).
+		Metric("VideosKept", float64(st.Kept), metrics.UnitCount).
+		Metric("VideoSourceBytes", float64(st.SourceBytes), metrics.UnitBytes).
+		Metric("VideoSheetBytes", float64(st.SheetBytes), metrics.UnitBytes).
+		Property("jobId", event.JobID).
+		Property("keyframes", event.Keyframes).
+		Flush()
+}
+
+// videoMode names a triage job's video handling for metric dimensions.
+func videoMode(keyframes int) string {
+	if keyframes > 0 {
+		return "keyframes"
+	}
+	return "whole"
+}
@@ -1,35 +1,10 @@
 package main
 
-// TriageEvent is the input from Step Functions.
-type TriageEvent struct {
-	Type              string   `json:"type"`
-	SessionID         string   `json:"sessionId"`
-	JobID             string   `json:"jobId"`
-	Model             string   `json:"model,omitempty"`
-	EconomyMode       bool     `json:"economy_mode,omitempty"`
-	ExpectedFileCount int      `json:"expectedFileCount,omitempty"`
-	VideoFileNames    []string `json:"videoFileNames,omitempty"`
-}
-
-// TriageRunResult is returned by triage-run when economy_mode is true.
-type TriageRunResult struct {
-	BatchJobID string `json:"batch_job_id,omitempty"`
-}
-
-// TriageInitResult is returned by the triage-init-session handler.
-type TriageInitResult struct {
-	SessionID string `json:"sessionId"`
-	JobID     string `json:"jobId"`
-	Model     string `json:"model"`
-}
-
-// TriageCheckProcessingResult is returned by the triage-check-processing handler.
-type TriageCheckProcessingResult struct {
-	SessionID      string `json:"sessionId"`
-	JobID          string `json:"jobId"`
-	Model          string `json:"model"`
-	AllProcessed   bool   `json:"allProcessed"`
-	ProcessedCount int    `json:"processedCount"`
-	ExpectedCount  int    `json:"expectedCount"`
-	ErrorCount     int    `json:"errorCount"`
-}
+import "github.com/fpang/ai-social-media-helper/internal/sfnevents"
+
+type (
+	TriageEvent                 = sfnevents.TriageEvent
+	TriageRunResult             = sfnevents.TriageRunResult
+	TriageInitResult            = sfnevents.TriageInitResult
+	TriageCheckProcessingResult = sfnevents.TriageCheckProcessingResult
+)
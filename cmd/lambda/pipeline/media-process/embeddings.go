@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/rag"
+)
+
+// stageEmbedding embeds a thumbnail and stages the vector for the daily RAG
+// batch (DDR-109). Best-effort: a missing embedding only means the file has
+// no look-alike examples in future selection prompts.
+func stageEmbedding(ctx context.Context, sessionID, key string, thumbData []byte) {
+	if genaiClient == nil || stagingTable == "" {
+		return
+	}
+	emb, err := rag.GenerateImageEmbedding(ctx, genaiClient, thumbData, "image/jpeg")
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("Failed to embed thumbnail")
+		return
+	}
+	err = rag.StageMediaEmbedding(ctx, sessionStore.Client(), stagingTable, rag.MediaEmbedding{
+		SessionID: sessionID,
+		MediaKey:  key,
+		Embedding: emb,
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("Failed to stage thumbnail embedding")
+		return
+	}
+	log.Debug().Str("key", key).Int("dims", len(emb)).Msg("Thumbnail embedding staged")
+}
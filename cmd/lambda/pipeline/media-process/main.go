@@ -6,7 +6,7 @@
 //  1. Validates the file extension and MIME type
 //  2. Extracts metadata (EXIF for images, ffprobe for videos)
 //  3. Converts if needed (resize large photos, compress videos)
-//  4. Generates a thumbnail
+//  4. Generates a thumbnail and stages its image embedding (DDR-109)
 //  5. Writes the result to the file-processing DynamoDB table
 //  6. Increments the processedCount on the session's TriageJob
 //
@@ -22,12 +22,14 @@ import (
 	"time"
 
 	"github.com/aws/aws-lambda-go/events"
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/rs/zerolog/log"
+	"google.golang.org/genai"
 
+	"github.com/fpang/ai-social-media-helper/internal/ai"
 	"github.com/fpang/ai-social-media-helper/internal/bootstrap"
 	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 )
 
@@ -39,14 +41,20 @@ const (
 	maxSmallPhotoPx    = 2000            // Skip resize if both dimensions ≤ this
 	targetResizePx     = 1920            // Instagram Stories/Reels & TikTok full-screen portrait height
 	thumbnailPx        = 400             // Thumbnail dimension
+	videoPreviewPx     = 1200            // Video preview strip width: three 400px frames (DDR-124)
 )
 
 // AWS clients initialized at cold start.
 var (
 	s3Client         *s3.Client
 	mediaBucket      string
+	sessionKeys      *s3util.SessionKeys
 	sessionStore     *store.DynamoStore
 	fileProcessStore *store.FileProcessingStore
+
+	// Thumbnail embeddings (DDR-109) are enabled when STAGING_TABLE_NAME is set.
+	stagingTable string
+	genaiClient  *genai.Client
 )
 
 func init() {
@@ -57,6 +65,7 @@ func init() {
 	s3s := bootstrap.InitS3(awsClients.Config, "MEDIA_BUCKET_NAME")
 	s3Client = s3s.Client
 	mediaBucket = s3s.Bucket
+	sessionKeys = s3s.Keys
 	sessionStore = bootstrap.InitDynamo(awsClients.Config, "DYNAMO_TABLE_NAME")
 
 	// Initialize file processing store (DDR-061)
@@ -67,15 +76,29 @@ func init() {
 	ddbClient := sessionStore.Client()
 	fileProcessStore = store.NewFileProcessingStore(ddbClient, fpTableName)
 
+	stagingTable = os.Getenv("STAGING_TABLE_NAME")
+	if stagingTable != "" {
+		bootstrap.LoadGeminiKey(awsClients.SSM)
+		bootstrap.LoadGCPServiceAccountKey(awsClients.SSM)
+		_ = ai.LoadGCPServiceAccount()
+		client, err := ai.NewAIClient(context.Background())
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to create AI client — thumbnail embeddings disabled")
+		} else {
+			genaiClient = client
+		}
+	}
+
 	bootstrap.StartupLog("media-process-lambda", initStart).
 		S3Bucket("mediaBucket", mediaBucket).
 		DynamoTable("sessions", os.Getenv("DYNAMO_TABLE_NAME")).
 		DynamoTable("fileProcessing", fpTableName).
+		DynamoTable("ragStaging", stagingTable).
 		Log()
 }
 
 func main() {
-	lambda.Start(handler)
+	bootstrap.Start(handler)
 }
 
 func handler(ctx context.Context, s3Event events.S3Event) error {
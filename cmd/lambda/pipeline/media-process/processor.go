@@ -16,6 +16,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/rs/zerolog/log"
 
+	"github.com/fpang/ai-social-media-helper/internal/ai"
 	"github.com/fpang/ai-social-media-helper/internal/media"
 	"github.com/fpang/ai-social-media-helper/internal/metrics"
 	"github.com/fpang/ai-social-media-helper/internal/s3util"
@@ -97,6 +98,12 @@ func processFile(ctx context.Context, key string) error {
 
 	log.Debug().Str("key", key).Int64("size", fileSize).Str("contentType", contentType).Str("mimeType", mimeType).Msg("File metadata retrieved")
 
+	// Everything derived from the file is encrypted like the session (DDR-164).
+	enc, err := sessionKeys.ForSession(ctx, sessionID)
+	if err != nil {
+		return writeErrorResult(ctx, sessionID, filename, key, fmt.Sprintf("Failed to resolve session encryption: %v", err))
+	}
+
 	// Download file to /tmp
 	tmpDir := filepath.Join(os.TempDir(), "media-process", sessionID)
 	os.MkdirAll(tmpDir, 0755)
@@ -134,46 +141,57 @@ func processFile(ctx context.Context, key string) error {
 		return writeErrorResult(ctx, sessionID, filename, key, fmt.Sprintf("Failed to load media file: %v", err))
 	}
 
-	// Extract metadata as string map for DDB storage
-	metadataMap := make(map[string]string)
-	if mf.Metadata != nil {
-		metadataMap["mediaType"] = mf.Metadata.GetMediaType()
-		if mf.Metadata.HasGPSData() {
-			lat, lon := mf.Metadata.GetGPS()
-			metadataMap["gpsLat"] = fmt.Sprintf("%.6f", lat)
-			metadataMap["gpsLon"] = fmt.Sprintf("%.6f", lon)
-		}
-		if mf.Metadata.HasDateData() {
-			metadataMap["date"] = mf.Metadata.GetDate().Format(time.RFC3339)
-		}
-	}
+	// Extract metadata as string map for DDB storage; triage reads it back.
+	metadataMap := media.ManifestFields(mf.Metadata)
 
 	// Determine processing strategy
 	var processedKey string
 	var thumbnailKey string
+	var dhash, qualityIssue string
+	var sample *store.VideoSample
 	converted := false
 
 	if isImage {
 		// Generate thumbnail (always)
 		thumbData, _, err := media.GenerateThumbnail(mf, thumbnailPx)
+		if err != nil && media.IsRaw(ext) {
+			// Nothing downstream can display or analyse a RAW without its preview (DDR-119).
+			return writeErrorResult(ctx, sessionID, filename, key, fmt.Sprintf("RAW file has no usable embedded preview: %v", err))
+		}
 		if err != nil {
 			log.Warn().Err(err).Str("key", key).Msg("Failed to generate thumbnail")
 		} else {
 			baseName := strings.TrimSuffix(filename, ext)
 			thumbnailKey = fmt.Sprintf("%s/thumbnails/%s.jpg", sessionID, baseName)
 			thumbContentType := "image/jpeg"
-			_, err = s3Client.PutObject(ctx, &s3.PutObjectInput{
+			_, err = s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
 				Bucket:      &mediaBucket,
 				Key:         &thumbnailKey,
 				Body:        bytes.NewReader(thumbData),
 				ContentType: &thumbContentType,
 				Tagging:     s3util.ProjectTagging(),
-			})
+			}))
 			if err != nil {
 				log.Warn().Err(err).Str("thumbnailKey", thumbnailKey).Msg("Failed to upload thumbnail")
 				thumbnailKey = ""
 			} else {
 				log.Debug().Str("thumbnailKey", thumbnailKey).Int("size", len(thumbData)).Msg("Thumbnail uploaded")
+				recordThumbnail(ctx, sessionID, filename, thumbnailKey)
+				stageEmbedding(ctx, sessionID, key, thumbData)
+			}
+
+			// Perceptual hash for near-duplicate clustering in triage (DDR-110).
+			if h, err := media.DHashBytes(thumbData); err != nil {
+				log.Warn().Err(err).Str("key", key).Msg("Failed to compute perceptual hash")
+			} else {
+				dhash = h.String()
+			}
+
+			// Local quality check so triage can skip clearly unsaveable photos (DDR-112).
+			if issue, err := media.CheckQualityBytes(thumbData); err != nil {
+				log.Warn().Err(err).Str("key", key).Msg("Failed to check image quality")
+			} else {
+				qualityIssue = issue
 			}
 		}
 
@@ -204,13 +222,13 @@ func processFile(ctx context.Context, key string) error {
 				outExt = ".webp"
 			}
 			processedKey = fmt.Sprintf("%s/processed/%s%s", sessionID, baseName, outExt)
-			_, err = s3Client.PutObject(ctx, &s3.PutObjectInput{
+			_, err = s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
 				Bucket:      &mediaBucket,
 				Key:         &processedKey,
 				Body:        bytes.NewReader(resizedData),
 				ContentType: &resizedMime,
 				Tagging:     s3util.ProjectTagging(),
-			})
+			}))
 			if err != nil {
 				log.Warn().Err(err).Str("processedKey", processedKey).Msg("Failed to upload resized image")
 				processedKey = key
@@ -241,18 +259,23 @@ func processFile(ctx context.Context, key string) error {
 			baseName := strings.TrimSuffix(filename, ext)
 			thumbnailKey = fmt.Sprintf("%s/thumbnails/%s.jpg", sessionID, baseName)
 			thumbContentType := "image/jpeg"
-			_, err = s3Client.PutObject(ctx, &s3.PutObjectInput{
+			_, err = s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
 				Bucket:      &mediaBucket,
 				Key:         &thumbnailKey,
 				Body:        bytes.NewReader(thumbData),
 				ContentType: &thumbContentType,
 				Tagging:     s3util.ProjectTagging(),
-			})
+			}))
 			if err != nil {
 				log.Warn().Err(err).Str("thumbnailKey", thumbnailKey).Msg("Failed to upload video thumbnail")
 				thumbnailKey = ""
+			} else {
+				recordThumbnail(ctx, sessionID, filename, thumbnailKey)
+				stageEmbedding(ctx, sessionID, key, thumbData)
 			}
 		}
+		uploadVideoPreview(ctx, mf, sessionID, filename, enc)
+		sample = uploadTriageSample(ctx, mf, localPath, sessionID, filename, enc, triageKeyframes(ctx, sessionID, jobID))
 
 		intermediateResult := &store.FileResult{
 			Filename:     filename,
@@ -263,6 +286,7 @@ func processFile(ctx context.Context, key string) error {
 			MimeType:     mimeType,
 			FileSize:     fileSize,
 			Metadata:     metadataMap,
+			Sample:       sample,
 		}
 		writeFileResult(ctx, sessionID, jobID, intermediateResult)
 
@@ -285,6 +309,7 @@ func processFile(ctx context.Context, key string) error {
 			}
 
 			compressCtx, compressCancel := context.WithTimeout(ctx, 12*time.Minute) // DDR-067: increased from 8 min
+			compressCtx = media.WithProgress(compressCtx, compressionProgress(ctx, sessionID, jobID, intermediateResult))
 			compressedPath, _, cleanup, err := media.CompressVideoForGemini(compressCtx, localPath, videoMeta)
 			compressCancel()
 			if err != nil {
@@ -301,13 +326,13 @@ func processFile(ctx context.Context, key string) error {
 					processedKey = key
 				} else {
 					compressedContentType := "video/webm"
-					_, err = s3Client.PutObject(ctx, &s3.PutObjectInput{
+					_, err = s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
 						Bucket:      &mediaBucket,
 						Key:         &processedKey,
 						Body:        compressedFile,
 						ContentType: &compressedContentType,
 						Tagging:     s3util.ProjectTagging(),
-					})
+					}))
 					compressedFile.Close()
 					if err != nil {
 						log.Warn().Err(err).Str("processedKey", processedKey).Msg("Failed to upload compressed video")
@@ -339,6 +364,10 @@ func processFile(ctx context.Context, key string) error {
 	if fp, fpErr := computeFingerprint(localPath, fileSize); fpErr == nil {
 		fingerprint = fp
 	}
+	contentHash, err := computeContentHash(localPath)
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("Failed to hash file content, triage will not be cached (non-fatal)")
+	}
 
 	// Write result to file-processing table
 	result := &store.FileResult{
@@ -352,7 +381,11 @@ func processFile(ctx context.Context, key string) error {
 		FileSize:     fileSize,
 		Converted:    converted,
 		Fingerprint:  fingerprint,
+		DHash:        dhash,
+		QualityIssue: qualityIssue,
 		Metadata:     metadataMap,
+		Sample:       sample,
+		ContentHash:  contentHash,
 	}
 
 	writeFileResult(ctx, sessionID, jobID, result)
@@ -425,6 +458,23 @@ func computeFingerprint(filePath string, fileSize int64) (string, error) {
 	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
+// computeContentHash returns the hex SHA-256 of the whole file, the key of
+// cached Gemini responses (DDR-166). Unlike the fingerprint it reads every
+// byte, so two files share a hash only if they are identical.
+func computeContentHash(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 // copyExistingResult copies an existing FileResult for a duplicate file (DDR-067).
 // Writes a new result pointing to the same processedKey/thumbnailKey and increments processedCount.
 func copyExistingResult(ctx context.Context, sessionID, jobID, originalFilename, newFilename, newKey string) error {
@@ -448,7 +498,11 @@ func copyExistingResult(ctx context.Context, sessionID, jobID, originalFilename,
 		FileSize:     original.FileSize,
 		Converted:    original.Converted,
 		Fingerprint:  original.Fingerprint,
+		DHash:        original.DHash,
+		QualityIssue: original.QualityIssue,
 		Metadata:     original.Metadata,
+		Sample:       original.Sample,
+		ContentHash:  original.ContentHash,
 	}
 
 	if err := fileProcessStore.PutFileResult(ctx, sessionID, jobID, result); err != nil {
@@ -472,3 +526,79 @@ func copyExistingResult(ctx context.Context, sessionID, jobID, originalFilename,
 
 	return nil
 }
+
+// uploadTriageSample renders the sample triage judges a long video by
+// (DDR-162) and stores its frame sheet at {sessionId}/samples/{baseName}.jpg.
+// When the triage job asked for keyframes (DDR-186), every video gets a
+// sheet of that many frames instead, whatever its length. Returns nil for a
+// video within the threshold, or when sampling or the upload fails, in
+// which case triage sends the whole video.
+func uploadTriageSample(ctx context.Context, mf *media.MediaFile, localPath, sessionID, filename string, enc s3util.Encryption, keyframes int) *store.VideoSample {
+	meta, ok := mf.Metadata.(*media.VideoMetadata)
+	if !ok || meta == nil || meta.Duration <= 0 {
+		return nil
+	}
+	var s *media.VideoSample
+	var err error
+	if keyframes > 0 {
+		s, err = media.SampleKeyframes(localPath, meta.Duration, media.DefaultThumbnailMaxDimension, keyframes)
+	} else if threshold := ai.TriageSampleThreshold(); threshold > 0 && meta.Duration > threshold {
+		s, err = media.SampleVideo(localPath, meta.Duration, media.DefaultThumbnailMaxDimension)
+	} else {
+		return nil
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("filename", filename).Msg("Failed to sample long video, triage will use the whole clip")
+		return nil
+	}
+
+	sampleKey := fmt.Sprintf("%s/samples/%s.jpg", sessionID, strings.TrimSuffix(filename, filepath.Ext(filename)))
+	contentType := "image/jpeg"
+	_, err = s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
+		Bucket:      &mediaBucket,
+		Key:         &sampleKey,
+		Body:        bytes.NewReader(s.Sheet),
+		ContentType: &contentType,
+		Tagging:     s3util.ProjectTagging(),
+	}))
+	if err != nil {
+		log.Warn().Err(err).Str("sampleKey", sampleKey).Msg("Failed to upload video sample")
+		return nil
+	}
+
+	out := &store.VideoSample{Key: sampleKey, DurationMs: meta.Duration.Milliseconds(), Keyframes: s.Keyframes}
+	for _, offset := range s.Offsets {
+		out.OffsetsMs = append(out.OffsetsMs, offset.Milliseconds())
+	}
+	if s.Loudness != nil {
+		out.MeanVolumeDB, out.MaxVolumeDB = &s.Loudness.MeanDB, &s.Loudness.MaxDB
+	}
+	log.Info().Str("sampleKey", sampleKey).Dur("duration", meta.Duration).Int("frames", len(s.Offsets)).Bool("keyframes", s.Keyframes).Msg("Video sampled for triage (DDR-162)")
+	return out
+}
+
+// uploadVideoPreview stores the frame strip the review UI shows for a video
+// at {sessionId}/previews/{baseName}.jpg (DDR-124). Best effort: without a
+// preview the UI keeps the single thumbnail.
+func uploadVideoPreview(ctx context.Context, mf *media.MediaFile, sessionID, filename string, enc s3util.Encryption) {
+	previewData, _, err := media.GenerateVideoPreview(mf, videoPreviewPx)
+	if err != nil {
+		log.Warn().Err(err).Str("filename", filename).Msg("Failed to generate video preview")
+		return
+	}
+
+	previewKey := fmt.Sprintf("%s/previews/%s.jpg", sessionID, strings.TrimSuffix(filename, filepath.Ext(filename)))
+	contentType := "image/jpeg"
+	_, err = s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
+		Bucket:      &mediaBucket,
+		Key:         &previewKey,
+		Body:        bytes.NewReader(previewData),
+		ContentType: &contentType,
+		Tagging:     s3util.ProjectTagging(),
+	}))
+	if err != nil {
+		log.Warn().Err(err).Str("previewKey", previewKey).Msg("Failed to upload video preview")
+		return
+	}
+	log.Debug().Str("previewKey", previewKey).Int("size", len(previewData)).Msg("Video preview uploaded")
+}
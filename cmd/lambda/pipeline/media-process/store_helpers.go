@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/rs/zerolog/log"
 
+	"github.com/fpang/ai-social-media-helper/internal/media"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 )
 
@@ -30,6 +32,31 @@ func findTriageJobID(ctx context.Context, sessionID string) (string, error) {
 	return "", fmt.Errorf("could not extract job ID from SK")
 }
 
+// triageKeyframes returns the frames per video the session's triage job
+// asked for (DDR-186), or 0 when keyframe mode is off or the job is unknown.
+func triageKeyframes(ctx context.Context, sessionID, jobID string) int {
+	if jobID == "" {
+		return 0
+	}
+	job, err := sessionStore.GetTriageJob(ctx, sessionID, jobID)
+	if err != nil || job == nil {
+		return 0
+	}
+	return job.Keyframes
+}
+
+// recordThumbnail adds an uploaded thumbnail to the thumbnail existence index
+// (DDR-091) so the API thumbnail handler can serve it without probing S3.
+// Best-effort: a missing entry only costs an on-the-fly regeneration.
+func recordThumbnail(ctx context.Context, sessionID, filename, thumbnailKey string) {
+	if fileProcessStore == nil || thumbnailKey == "" {
+		return
+	}
+	if err := fileProcessStore.PutThumbnailIndex(ctx, sessionID, filename, thumbnailKey); err != nil {
+		log.Warn().Err(err).Str("sessionId", sessionID).Str("filename", filename).Msg("Failed to record thumbnail index entry")
+	}
+}
+
 func writeErrorResult(ctx context.Context, sessionID, filename, originalKey, errMsg string) error {
 	log.Warn().Str("sessionId", sessionID).Str("filename", filename).Str("key", originalKey).Str("error", errMsg).Msg("File processing failed")
 
@@ -56,3 +83,24 @@ func writeErrorResult(ctx context.Context, sessionID, filename, originalKey, err
 
 	return nil
 }
+
+// progressInterval spaces compression progress writes, so a long video
+// costs a handful of DynamoDB writes rather than one per percent.
+const progressInterval = 5 * time.Second
+
+// compressionProgress returns a media.ProgressFunc that writes a video's
+// compression percent to its file result (DDR-161), at most once per
+// progressInterval. The upload screen polls the result, so long compressions
+// show movement. base is the "thumbnailed" result already written.
+func compressionProgress(ctx context.Context, sessionID, jobID string, base *store.FileResult) media.ProgressFunc {
+	var last time.Time
+	return func(_ string, percent int) {
+		if percent >= 100 || time.Since(last) < progressInterval {
+			return
+		}
+		last = time.Now()
+		result := *base
+		result.Progress = percent
+		writeFileResult(ctx, sessionID, jobID, &result)
+	}
+}
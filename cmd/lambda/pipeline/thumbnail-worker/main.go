@@ -5,12 +5,19 @@
 // generates a 400px JPEG thumbnail (images via pure Go, videos via ffmpeg),
 // and uploads the thumbnail to S3.
 //
+// Files uploaded through the MediaProcess Lambda usually have a thumbnail
+// already. The worker checks the thumbnail existence index (DDR-091) and
+// confirms the object is still in S3 before reusing it, so only missing
+// thumbnails are generated (DDR-145). Without FILE_PROCESSING_TABLE_NAME it
+// probes the expected thumbnail key directly.
+//
 // Container: Heavy (Dockerfile.heavy — includes ffmpeg for video frame extraction)
 // Memory: 512 MB
 // Timeout: 2 minutes
 //
 // See DDR-035: Multi-Lambda Deployment Architecture
 // See DDR-043: Step Functions Lambda Entrypoints
+// See DDR-145: Skip Existing Thumbnails in the Selection Pipeline
 package main
 
 import (
@@ -24,11 +31,16 @@ import (
 
 	"github.com/aws/aws-lambda-go/lambda"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 
-	"github.com/fpang/ai-social-media-helper/internal/media"
 	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
 	"github.com/fpang/ai-social-media-helper/internal/s3util"
+	"github.com/fpang/ai-social-media-helper/internal/sfnevents"
+	"github.com/fpang/ai-social-media-helper/internal/store"
 	"github.com/rs/zerolog/log"
 )
 
@@ -38,8 +50,10 @@ const thumbnailMaxDimension = 400
 
 // AWS clients initialized at cold start.
 var (
-	s3Client    *s3.Client
-	mediaBucket string
+	s3Client         *s3.Client
+	mediaBucket      string
+	sessionKeys      *s3util.SessionKeys        // DDR-164
+	fileProcessStore *store.FileProcessingStore // nil when FILE_PROCESSING_TABLE_NAME is unset
 )
 
 var coldStart = true
@@ -55,34 +69,28 @@ func init() {
 	log.Debug().Str("region", cfg.Region).Msg("AWS config loaded")
 
 	s3Client = s3.NewFromConfig(cfg)
+	sessionKeys = s3util.NewSessionKeys(kms.NewFromConfig(cfg))
 	mediaBucket = os.Getenv("MEDIA_BUCKET_NAME")
 	if mediaBucket == "" {
 		log.Fatal().Msg("MEDIA_BUCKET_NAME environment variable is required")
 	}
+	fpTableName := os.Getenv("FILE_PROCESSING_TABLE_NAME")
+	if fpTableName != "" {
+		fileProcessStore = store.NewFileProcessingStore(dynamodb.NewFromConfig(cfg), fpTableName)
+	}
 
 	// Emit consolidated cold-start log for troubleshooting.
 	logging.NewStartupLogger("thumbnail-lambda").
 		InitDuration(time.Since(initStart)).
 		S3Bucket("mediaBucket", mediaBucket).
+		DynamoTable("fileProcessing", fpTableName).
 		Log()
 }
 
-// ThumbnailEvent is the input payload from Step Functions.
-// The Map state iterates over media keys and sends one event per file.
-type ThumbnailEvent struct {
-	SessionID string `json:"sessionId"`
-	Key       string `json:"key"`
-	Bucket    string `json:"bucket,omitempty"` // Optional override; defaults to MEDIA_BUCKET_NAME.
-}
-
-// ThumbnailResult is the output returned to Step Functions.
-// The Map state collects all results for the next state (Selection Lambda).
-type ThumbnailResult struct {
-	ThumbnailKey string `json:"thumbnailKey"`
-	OriginalKey  string `json:"originalKey"`
-	Success      bool   `json:"success"`
-	Error        string `json:"error,omitempty"`
-}
+type (
+	ThumbnailEvent  = sfnevents.ThumbnailEvent
+	ThumbnailResult = sfnevents.ThumbnailResult
+)
 
 func handler(ctx context.Context, event ThumbnailEvent) (ThumbnailResult, error) {
 	handlerStart := time.Now()
@@ -124,6 +132,21 @@ func handler(ctx context.Context, event ThumbnailEvent) (ThumbnailResult, error)
 		}, fmt.Errorf("unsupported file type: %s", ext)
 	}
 
+	// Reuse the thumbnail MediaProcess already made, if any (DDR-145).
+	if thumbKey, ok := existingThumbnail(ctx, bucket, event.SessionID, filename); ok {
+		recordThumbnailCache(true)
+		logger.Info().
+			Str("thumbKey", thumbKey).
+			Dur("duration", time.Since(handlerStart)).
+			Msg("Thumbnail already exists — skipping generation")
+		return ThumbnailResult{
+			ThumbnailKey: thumbKey,
+			OriginalKey:  event.Key,
+			Success:      true,
+		}, nil
+	}
+	recordThumbnailCache(false)
+
 	// Download media file from S3 to /tmp.
 	tmpPath := filepath.Join(os.TempDir(), "thumb-"+filename)
 	if err := downloadToFile(ctx, bucket, event.Key, tmpPath); err != nil {
@@ -169,17 +192,19 @@ func handler(ctx context.Context, event ThumbnailEvent) (ThumbnailResult, error)
 	logger.Debug().Int("thumbnailSize", len(thumbData)).Msg("Thumbnail generated")
 
 	// Upload thumbnail to S3 at {sessionId}/thumbnails/{baseName}.jpg.
-	baseName := strings.TrimSuffix(filename, filepath.Ext(filename))
-	thumbKey := fmt.Sprintf("%s/thumbnails/%s.jpg", event.SessionID, baseName)
+	thumbKey := thumbnailKeyFor(event.SessionID, filename)
 	contentType := "image/jpeg"
 
-	_, err = s3Client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:      &bucket,
-		Key:         &thumbKey,
-		Body:        bytes.NewReader(thumbData),
-		ContentType: &contentType,
-		Tagging:     s3util.ProjectTagging(),
-	})
+	enc, err := sessionKeys.ForSession(ctx, event.SessionID)
+	if err == nil {
+		_, err = s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
+			Bucket:      &bucket,
+			Key:         &thumbKey,
+			Body:        bytes.NewReader(thumbData),
+			ContentType: &contentType,
+			Tagging:     s3util.ProjectTagging(),
+		}))
+	}
 	if err != nil {
 		logger.Error().Err(err).Str("thumbKey", thumbKey).Msg("Failed to upload thumbnail")
 		return ThumbnailResult{
@@ -189,6 +214,14 @@ func handler(ctx context.Context, event ThumbnailEvent) (ThumbnailResult, error)
 		}, err
 	}
 
+	// Index the new thumbnail so reruns and the API thumbnail handler find it
+	// (DDR-091). Best-effort, like the MediaProcess write.
+	if fileProcessStore != nil {
+		if err := fileProcessStore.PutThumbnailIndex(ctx, event.SessionID, filename, thumbKey); err != nil {
+			logger.Warn().Err(err).Msg("Failed to record thumbnail index entry")
+		}
+	}
+
 	logger.Info().
 		Str("thumbKey", thumbKey).
 		Int("thumbSize", len(thumbData)).
@@ -206,6 +239,52 @@ func main() {
 	lambda.Start(handler)
 }
 
+// --- Existing thumbnails (DDR-145) ---
+
+// thumbnailKeyFor returns the S3 key of a file's thumbnail,
+// {sessionId}/thumbnails/{baseName}.jpg — the key MediaProcess writes too.
+func thumbnailKeyFor(sessionID, filename string) string {
+	baseName := strings.TrimSuffix(filename, filepath.Ext(filename))
+	return fmt.Sprintf("%s/thumbnails/%s.jpg", sessionID, baseName)
+}
+
+// existingThumbnail returns the key of a thumbnail already in S3 for the
+// file. An index miss is trusted and costs no S3 request; an index hit is
+// confirmed with HeadObject because session invalidation deletes thumbnails
+// without clearing the index. Any lookup error counts as a miss.
+func existingThumbnail(ctx context.Context, bucket, sessionID, filename string) (string, bool) {
+	thumbKey := thumbnailKeyFor(sessionID, filename)
+	if fileProcessStore != nil {
+		indexed, err := fileProcessStore.GetThumbnailIndexEntry(ctx, sessionID, filename)
+		if err != nil {
+			log.Warn().Err(err).Str("sessionId", sessionID).Str("filename", filename).Msg("Failed to read thumbnail index — generating thumbnail")
+			return "", false
+		}
+		if indexed == "" {
+			return "", false
+		}
+		thumbKey = indexed
+	}
+	if _, err := s3Client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: &bucket, Key: &thumbKey}); err != nil {
+		log.Debug().Err(err).Str("thumbKey", thumbKey).Msg("Thumbnail not found in S3")
+		return "", false
+	}
+	return thumbKey, true
+}
+
+// recordThumbnailCache emits one ThumbnailCacheHit sample: 1 for a reused
+// thumbnail, 0 for a generated one. Its average is the cache hit rate.
+func recordThumbnailCache(hit bool) {
+	value := 0.0
+	if hit {
+		value = 1
+	}
+	metrics.New("AiSocialMedia").
+		Dimension("Operation", "selectionThumbnail").
+		Metric("ThumbnailCacheHit", value, metrics.UnitNone).
+		Flush()
+}
+
 // --- S3 Helpers ---
 
 // downloadToFile downloads an S3 object to a specific local path.
@@ -23,18 +23,19 @@ import (
 	"strings"
 	"time"
 
-	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
 
 	"github.com/fpang/ai-social-media-helper/internal/ai"
 	"github.com/fpang/ai-social-media-helper/internal/bootstrap"
+	"github.com/fpang/ai-social-media-helper/internal/flags"
 	"github.com/fpang/ai-social-media-helper/internal/logging"
 	"github.com/fpang/ai-social-media-helper/internal/media"
 	"github.com/fpang/ai-social-media-helper/internal/s3util"
+	"github.com/fpang/ai-social-media-helper/internal/sfnevents"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 	"github.com/rs/zerolog/log"
 )
@@ -42,8 +43,10 @@ import (
 // AWS clients and configuration initialized at cold start.
 var (
 	s3Client     *s3.Client
+	sessionKeys  *s3util.SessionKeys // DDR-164
 	sessionStore store.SessionStore
 	mediaBucket  string
+	featureFlags *flags.Set // DDR-132
 )
 
 var coldStart = true
@@ -59,6 +62,7 @@ func init() {
 	log.Debug().Str("region", cfg.Region).Msg("AWS config loaded")
 
 	s3Client = s3.NewFromConfig(cfg)
+	sessionKeys = s3util.NewSessionKeys(kms.NewFromConfig(cfg))
 	mediaBucket = os.Getenv("MEDIA_BUCKET_NAME")
 	if mediaBucket == "" {
 		log.Fatal().Msg("MEDIA_BUCKET_NAME environment variable is required")
@@ -74,23 +78,9 @@ func init() {
 
 	// Load Gemini API key and GCP SA from SSM Parameter Store if not set.
 	ssmClient := ssm.NewFromConfig(cfg)
-	if os.Getenv("GEMINI_API_KEY") == "" {
-		paramName := os.Getenv("SSM_API_KEY_PARAM")
-		if paramName == "" {
-			paramName = "/ai-social-media/prod/gemini-api-key"
-		}
-		ssmStart := time.Now()
-		result, err := ssmClient.GetParameter(context.Background(), &ssm.GetParameterInput{
-			Name:           &paramName,
-			WithDecryption: aws.Bool(true),
-		})
-		if err != nil {
-			log.Fatal().Err(err).Str("param", paramName).Msg("Failed to read API key from SSM")
-		}
-		os.Setenv("GEMINI_API_KEY", *result.Parameter.Value)
-		log.Debug().Str("param", paramName).Dur("elapsed", time.Since(ssmStart)).Msg("Gemini API key loaded from SSM")
-	}
+	bootstrap.LoadGeminiKey(ssmClient) // DDR-172: refreshed after rotation
 	bootstrap.LoadGCPServiceAccountKey(ssmClient)
+	featureFlags = bootstrap.InitFlags(ssmClient)
 	_ = ai.LoadGCPServiceAccount()
 
 	// Emit consolidated cold-start log for troubleshooting.
@@ -99,27 +89,14 @@ func init() {
 		S3Bucket("mediaBucket", mediaBucket).
 		DynamoTable("sessions", tableName).
 		SSMParam("geminiApiKey", logging.EnvOrDefault("SSM_API_KEY_PARAM", "/ai-social-media/prod/gemini-api-key")).
+		SSMParam("featureFlags", logging.EnvOrDefault("SSM_FEATURE_FLAGS_PARAM", flags.DefaultSSMParam)).
 		Log()
 }
 
-// VideoEvent is the input payload from Step Functions.
-// The Map state iterates over selected video keys and sends one event per video.
-type VideoEvent struct {
-	SessionID string `json:"sessionId"`
-	JobID     string `json:"jobId"`
-	Key       string `json:"key"`
-	ItemIndex int    `json:"itemIndex"`
-	Bucket    string `json:"bucket,omitempty"`
-}
-
-// VideoResult is the output returned to Step Functions.
-type VideoResult struct {
-	OriginalKey string `json:"originalKey"`
-	EnhancedKey string `json:"enhancedKey"`
-	Phase       string `json:"phase"`
-	Summary     string `json:"summary,omitempty"`
-	Error       string `json:"error,omitempty"`
-}
+type (
+	VideoEvent  = sfnevents.VideoEvent
+	VideoResult = sfnevents.VideoResult
+)
 
 func handler(ctx context.Context, event VideoEvent) (VideoResult, error) {
 	handlerStart := time.Now()
@@ -149,6 +126,15 @@ func handler(ctx context.Context, event VideoEvent) (VideoResult, error) {
 		}, fmt.Errorf("sessionId, jobId, and key are required")
 	}
 
+	// Checkpoint (DDR-095): skip items of a paused job and items an earlier run
+	// already finished, and resolve the item index by key for resumed runs.
+	itemIndex, _, skipPhase := store.CheckpointItem(ctx, sessionStore, event.SessionID, event.JobID, event.Key, event.ItemIndex) // DDR-095
+	if skipPhase != "" {
+		logger.Info().Str("phase", skipPhase).Msg("Skipping item — job paused or item already processed")
+		return VideoResult{OriginalKey: event.Key, Phase: skipPhase}, nil
+	}
+	event.ItemIndex = itemIndex
+
 	// Download video from S3 to /tmp.
 	tmpDir := filepath.Join(os.TempDir(), "video", event.SessionID)
 	os.MkdirAll(tmpDir, 0755)
@@ -186,11 +172,13 @@ func handler(ctx context.Context, event VideoEvent) (VideoResult, error) {
 		GeminiAPIKey:            os.Getenv("GEMINI_API_KEY"),
 		VertexAIProject:         os.Getenv("VERTEX_AI_PROJECT"),
 		VertexAIRegion:          os.Getenv("VERTEX_AI_REGION"),
-		VertexAIAccessToken:     os.Getenv("VERTEX_AI_TOKEN"),
 		SimilarityThreshold:     0.92,
 		MaxAnalysisIterations:   3,
 		TargetProfessionalScore: 8.5,
 	}
+	if !featureFlags.Enabled(ctx, flags.Imagen) {
+		config.VertexAIProject = "" // DDR-132: Imagen disabled by feature flag
+	}
 	logger.Debug().Bool("imagenConfigured", config.VertexAIProject != "").Float64("similarityThreshold", config.SimilarityThreshold).Msg("Video enhancement config")
 
 	// Run video enhancement pipeline.
@@ -234,13 +222,16 @@ func handler(ctx context.Context, event VideoEvent) (VideoResult, error) {
 		logger.Debug().Int64("enhancedFileSize", enhancedFileInfo.Size()).Msg("Enhanced video file size")
 	}
 
-	_, uploadErr := s3Client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:      &bucket,
-		Key:         &enhancedKey,
-		Body:        enhancedFile,
-		ContentType: &contentType,
-		Tagging:     s3util.ProjectTagging(),
-	})
+	enc, uploadErr := sessionKeys.ForSession(ctx, event.SessionID)
+	if uploadErr == nil {
+		_, uploadErr = s3Client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
+			Bucket:      &bucket,
+			Key:         &enhancedKey,
+			Body:        enhancedFile,
+			ContentType: &contentType,
+			Tagging:     s3util.ProjectTagging(),
+		}))
+	}
 	if uploadErr != nil {
 		logger.Error().Err(uploadErr).Str("enhancedKey", enhancedKey).Msg("Failed to upload enhanced video")
 		updateItemError(ctx, event, "upload failed")
@@ -271,7 +262,7 @@ func handler(ctx context.Context, event VideoEvent) (VideoResult, error) {
 }
 
 func main() {
-	lambda.Start(handler)
+	bootstrap.Start(handler)
 }
 
 // --- DynamoDB Helpers ---
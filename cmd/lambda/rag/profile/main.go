@@ -8,7 +8,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -37,8 +36,19 @@ var (
 	database       string
 	profilesTable  string
 	stagingTable   string
+
+	// forceFullRecompute makes every run rebuild stats from the full decision
+	// history instead of merging in decisions past the watermark (DDR-103).
+	forceFullRecompute bool
 )
 
+// profileEvent is the invocation payload. Scheduled runs send an EventBridge
+// event, which decodes to the zero value; a manual invoke can send
+// {"fullRecompute": true}.
+type profileEvent struct {
+	FullRecompute bool `json:"fullRecompute"`
+}
+
 type stagingItem struct {
 	PK           string `dynamodbav:"PK"`
 	SK           string `dynamodbav:"SK"`
@@ -49,7 +59,8 @@ type stagingItem struct {
 
 // handler runs the daily batch lifecycle (DDR-068):
 // staging ingest → Aurora embed+insert → profile build → cleanup → Aurora stop.
-func handler(ctx context.Context) error {
+// A forced full recompute runs the profile build even with nothing staged.
+func handler(ctx context.Context, event profileEvent) error {
 	if clusterARN == "" || secretARN == "" || database == "" || profilesTable == "" || stagingTable == "" {
 		log.Warn().Msg("Required env vars not configured")
 		return nil
@@ -61,10 +72,19 @@ func handler(ctx context.Context) error {
 		return err
 	}
 
-	if len(items) == 0 {
+	full := forceFullRecompute || event.FullRecompute
+	if len(items) == 0 && !full {
 		log.Info().Msg("No staging items to process, skipping batch")
 		return nil
 	}
+	for _, item := range items {
+		// A triage override rewrites a triage_decisions row that earlier runs
+		// already counted; only a full recompute can un-count the old verdict.
+		if item.EventType == rag.EventTriageOverride {
+			full = true
+			break
+		}
+	}
 
 	log.Info().Int("count", len(items)).Msg("Staging items found, starting batch")
 
@@ -86,7 +106,7 @@ func handler(ctx context.Context) error {
 	processed := ingestStagingItems(ctx, items, dataAPI)
 	log.Info().Int("processed", len(processed)).Int("total", len(items)).Msg("Staging items ingested to Aurora")
 
-	if err := buildAndWriteProfile(ctx, dataAPI); err != nil {
+	if err := buildAndWriteProfile(ctx, dataAPI, full); err != nil {
 		log.Error().Err(err).Msg("Profile build failed (continuing to cleanup)")
 	}
 
@@ -220,7 +240,12 @@ type preparedItem struct {
 
 func ingestStagingItems(ctx context.Context, items []stagingItem, dataAPI *rag.DataAPIClient) []stagingItem {
 	var prepared []preparedItem
+	var embeddingItems []stagingItem
 	for _, item := range items {
+		if item.EventType == rag.EventMediaEmbedding {
+			embeddingItems = append(embeddingItems, item)
+			continue
+		}
 		var fb rag.ContentFeedback
 		if err := json.Unmarshal([]byte(item.FeedbackJSON), &fb); err != nil {
 			log.Error().Err(err).Str("sk", item.SK).Msg("failed to parse feedback JSON")
@@ -239,22 +264,48 @@ func ingestStagingItems(ctx context.Context, items []stagingItem, dataAPI *rag.D
 		prepared = append(prepared, preparedItem{staging: item, feedback: fb, embedding: emb, createdAt: createdAt})
 	}
 
+	result := ingestMediaEmbeddings(ctx, embeddingItems, dataAPI)
 	if len(prepared) == 0 {
-		return nil
+		return result
 	}
 
 	if err := batchUpsertAll(ctx, dataAPI, prepared); err != nil {
 		log.Error().Err(err).Msg("batch upsert failed, falling back to per-item")
-		return fallbackPerItem(ctx, dataAPI, prepared)
+		return append(result, fallbackPerItem(ctx, dataAPI, prepared)...)
 	}
 
-	result := make([]stagingItem, len(prepared))
-	for i, p := range prepared {
-		result[i] = p.staging
+	for _, p := range prepared {
+		result = append(result, p.staging)
 	}
 	return result
 }
 
+// ingestMediaEmbeddings upserts thumbnail embeddings staged by the
+// media-process Lambda (DDR-109). They are already embedded, so no Gemini
+// call is needed. Returns the staging items that were written.
+func ingestMediaEmbeddings(ctx context.Context, items []stagingItem, dataAPI *rag.DataAPIClient) []stagingItem {
+	var embeddings []rag.MediaEmbedding
+	var parsed []stagingItem
+	for _, item := range items {
+		var e rag.MediaEmbedding
+		if err := json.Unmarshal([]byte(item.FeedbackJSON), &e); err != nil {
+			log.Error().Err(err).Str("sk", item.SK).Msg("failed to parse media embedding JSON")
+			continue
+		}
+		embeddings = append(embeddings, e)
+		parsed = append(parsed, item)
+	}
+	if len(embeddings) == 0 {
+		return nil
+	}
+	if err := dataAPI.BatchUpsertMediaEmbeddings(ctx, embeddings); err != nil {
+		log.Error().Err(err).Int("count", len(embeddings)).Msg("media embedding upsert failed, leaving items staged")
+		return nil
+	}
+	log.Info().Int("count", len(embeddings)).Msg("Media embeddings ingested to Aurora")
+	return parsed
+}
+
 func batchUpsertAll(ctx context.Context, dataAPI *rag.DataAPIClient, items []preparedItem) error {
 	var triages    []rag.TriageDecision
 	var selections []rag.SelectionDecision
@@ -289,6 +340,22 @@ func batchUpsertAll(ctx context.Context, dataAPI *rag.DataAPIClient, items []pre
 				SceneGroup: getMeta(fb.Metadata, "sceneGroup"), MediaMetadata: fb.Metadata,
 				Embedding: p.embedding, CreatedAt: p.createdAt,
 			})
+		case rag.EventTriageOverride:
+			// DDR-100: the user's verdict replaces the AI's in triage_decisions
+			// (same session/media key) and is logged as a finalized override.
+			triages = append(triages, rag.TriageDecision{
+				SessionID: fb.SessionID, UserID: fb.UserID, MediaKey: fb.MediaKey,
+				Filename: getMeta(fb.Metadata, "filename"), MediaType: fb.MediaType,
+				Saveable: strings.EqualFold(fb.UserVerdict, "keep"), Reason: fb.Reason, MediaMetadata: fb.Metadata,
+				Embedding: p.embedding, CreatedAt: p.createdAt,
+			})
+			overrides = append(overrides, rag.OverrideDecision{
+				SessionID: fb.SessionID, UserID: fb.UserID, MediaKey: fb.MediaKey,
+				Filename: getMeta(fb.Metadata, "filename"), MediaType: fb.MediaType,
+				Action: fb.UserVerdict, AIVerdict: fb.AIVerdict, AIReason: fb.Reason,
+				IsFinalized: true, MediaMetadata: fb.Metadata,
+				Embedding: p.embedding, CreatedAt: p.createdAt,
+			})
 		case rag.EventOverrideAction, rag.EventOverridesFinalized:
 			action := fb.UserVerdict
 			if action == "" {
@@ -418,6 +485,23 @@ func embedAndUpsert(ctx context.Context, dataAPI *rag.DataAPIClient, feedback ra
 			Embedding: embedding, CreatedAt: createdAt,
 		})
 
+	case rag.EventTriageOverride:
+		if err := dataAPI.UpsertTriageDecision(ctx, rag.TriageDecision{
+			SessionID: feedback.SessionID, UserID: feedback.UserID, MediaKey: feedback.MediaKey,
+			Filename: getMeta(feedback.Metadata, "filename"), MediaType: feedback.MediaType,
+			Saveable: strings.EqualFold(feedback.UserVerdict, "keep"), Reason: feedback.Reason, MediaMetadata: feedback.Metadata,
+			Embedding: embedding, CreatedAt: createdAt,
+		}); err != nil {
+			return err
+		}
+		return dataAPI.UpsertOverrideDecision(ctx, rag.OverrideDecision{
+			SessionID: feedback.SessionID, UserID: feedback.UserID, MediaKey: feedback.MediaKey,
+			Filename: getMeta(feedback.Metadata, "filename"), MediaType: feedback.MediaType,
+			Action: feedback.UserVerdict, AIVerdict: feedback.AIVerdict, AIReason: feedback.Reason,
+			IsFinalized: true, MediaMetadata: feedback.Metadata,
+			Embedding: embedding, CreatedAt: createdAt,
+		})
+
 	case rag.EventOverrideAction, rag.EventOverridesFinalized:
 		action := feedback.UserVerdict
 		if action == "" {
@@ -502,33 +586,165 @@ func parseStringSlice(m map[string]string, key string) []string {
 // Phase 4: Build preference profile (from DDR-066 profile Lambda logic)
 // ---------------------------------------------------------------------------
 
-func buildAndWriteProfile(ctx context.Context, _ *rag.DataAPIClient) error {
-	triageRows, err := executeQuery(ctx, `SELECT * FROM triage_decisions ORDER BY created_at DESC`)
+// buildAndWriteProfile folds decisions created since the stored watermarks
+// into the stored stats, or recomputes them from the full history when full is
+// set or the state cannot be merged into (DDR-103), then regenerates the
+// global profile and the profiles of users with new decisions (DDR-105).
+func buildAndWriteProfile(ctx context.Context, _ *rag.DataAPIClient, full bool) error {
+	state, err := readProfileState(ctx)
 	if err != nil {
-		return fmt.Errorf("query triage_decisions: %w", err)
+		return err
+	}
+	now := time.Now().UTC()
+	// Postgres timestamps have microsecond precision; truncate so the bound
+	// compares exactly when it comes back as the next run's watermark.
+	until := now.Add(-watermarkSettleLag).Truncate(time.Microsecond)
+	reason := needsFullRecompute(state, full, now)
+	if reason != "" {
+		state = &profileState{LastFullAt: now, Version: profileStateVersion}
+	}
+	since := func(table string) time.Time {
+		return state.Watermarks[table]
 	}
 
-	selectionRows, err := executeQuery(ctx, `SELECT * FROM selection_decisions ORDER BY created_at DESC`)
+	triageDecisions, selectionDecisions, overrideDecisions, err := windowDecisions(ctx, since, until, "")
 	if err != nil {
-		return fmt.Errorf("query selection_decisions: %w", err)
+		return err
 	}
 
-	overrideRows, err := executeQuery(ctx, `SELECT * FROM override_decisions WHERE is_finalized = true ORDER BY created_at DESC`)
+	// Caption examples are the newest few captions, not an aggregate, so they
+	// are always read directly.
+	captionRows, err := executeQuery(ctx, `SELECT * FROM caption_decisions ORDER BY created_at DESC LIMIT 10`)
 	if err != nil {
-		return fmt.Errorf("query override_decisions: %w", err)
+		return fmt.Errorf("query caption_decisions: %w", err)
+	}
+
+	stats := rag.MergeStats(state.Stats, triageDecisions, overrideDecisions, selectionDecisions)
+	if err := writeProfile(ctx, rag.GlobalProfilePK, stats, parseCaptionDecisions(captionRows)); err != nil {
+		return err
 	}
 
-	captionRows, err := executeQuery(ctx, `SELECT * FROM caption_decisions ORDER BY created_at DESC`)
+	// Per-user profiles (DDR-105). Only users with decisions in this window
+	// have changed, so only their profiles are regenerated; a failure leaves
+	// that user on their previous profile until their next decisions arrive.
+	users := rag.GroupByUser(triageDecisions, overrideDecisions, selectionDecisions)
+	userProfiles := 0
+	for userID, d := range users {
+		userStats, err := mergeUserStats(ctx, userID, d, since(rag.TableTriageDecisions), until, reason != "")
+		if err != nil {
+			log.Warn().Err(err).Str("userId", userID).Msg("User stats merge failed")
+			continue
+		}
+		if err := writeUserState(ctx, userID, &userState{Stats: userStats, Until: until, Version: profileStateVersion}); err != nil {
+			log.Warn().Err(err).Str("userId", userID).Msg("User stats write failed")
+			continue
+		}
+		if userStats.TotalDecisions < rag.MinUserProfileDecisions {
+			continue
+		}
+		if err := writeUserProfile(ctx, userID, userStats); err != nil {
+			log.Warn().Err(err).Str("userId", userID).Msg("User profile build failed")
+			continue
+		}
+		userProfiles++
+	}
+
+	log.Info().
+		Int("triageCount", len(triageDecisions)).
+		Int("selectionCount", len(selectionDecisions)).
+		Int("overrideCount", len(overrideDecisions)).
+		Int("usersChanged", len(users)).
+		Int("userProfiles", userProfiles).
+		Str("mode", recomputeMode(reason)).
+		Str("fullReason", reason).
+		Msg("Preference profiles computed and stored")
+
+	// Advance the watermarks only after the global profile is written, so a failed
+	// run re-reads the same window next time.
+	state.Stats = stats
+	state.Watermarks = map[string]time.Time{
+		rag.TableTriageDecisions:    until,
+		rag.TableSelectionDecisions: until,
+		rag.TableOverrideDecisions:  until,
+	}
+	return writeProfileState(ctx, state)
+}
+
+// windowDecisions reads the triage, selection and finalized override
+// decisions created in (since(table), until], only userID's when it is set.
+func windowDecisions(ctx context.Context, since func(table string) time.Time, until time.Time, userID string) ([]rag.TriageDecision, []rag.SelectionDecision, []rag.OverrideDecision, error) {
+	query := func(table, where string) ([]map[string]interface{}, error) {
+		var userParams []rdsdatatypes.SqlParameter
+		if userID != "" {
+			if where != "" {
+				where += " AND "
+			}
+			where += "user_id = :user_id"
+			userParams = append(userParams, rdsdatatypes.SqlParameter{Name: aws.String("user_id"), Value: &rdsdatatypes.FieldMemberStringValue{Value: userID}})
+		}
+		sql, params := windowQuery(table, where, since(table), until)
+		rows, err := executeQuery(ctx, sql, append(params, userParams...)...)
+		if err != nil {
+			return nil, fmt.Errorf("query %s: %w", table, err)
+		}
+		return rows, nil
+	}
+
+	triageRows, err := query(rag.TableTriageDecisions, "")
 	if err != nil {
-		return fmt.Errorf("query caption_decisions: %w", err)
+		return nil, nil, nil, err
 	}
+	selectionRows, err := query(rag.TableSelectionDecisions, "")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	overrideRows, err := query(rag.TableOverrideDecisions, "is_finalized = true")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return parseTriageDecisions(triageRows), parseSelectionDecisions(selectionRows), parseOverrideDecisions(overrideRows), nil
+}
+
+// mergeUserStats folds a user's decisions from this run's window into their
+// stored stats (DDR-105). Stats stored up to a point other than the window's
+// start — a new user, or a run that failed between writes — are rebuilt from
+// the user's full history instead, so no window is counted twice or skipped.
+// On a full recompute the window is the full history already.
+func mergeUserStats(ctx context.Context, userID string, d *rag.UserDecisions, since, until time.Time, full bool) (rag.DecisionStats, error) {
+	if full {
+		return rag.MergeStats(rag.DecisionStats{}, d.Triage, d.Overrides, d.Selection), nil
+	}
+	prev, err := readUserState(ctx, userID)
+	if err != nil {
+		return rag.DecisionStats{}, err
+	}
+	if prev != nil && prev.Until.Equal(since) {
+		return rag.MergeStats(prev.Stats, d.Triage, d.Overrides, d.Selection), nil
+	}
+
+	log.Info().Str("userId", userID).Msg("User stats out of step with the watermark — recomputing from full history")
+	fromStart := func(string) time.Time { return time.Time{} }
+	triage, selection, overrides, err := windowDecisions(ctx, fromStart, until, userID)
+	if err != nil {
+		return rag.DecisionStats{}, err
+	}
+	return rag.MergeStats(rag.DecisionStats{}, triage, overrides, selection), nil
+}
 
-	triageDecisions := parseTriageDecisions(triageRows)
-	selectionDecisions := parseSelectionDecisions(selectionRows)
-	overrideDecisions := parseOverrideDecisions(overrideRows)
-	captionDecisions := parseCaptionDecisions(captionRows)
+// writeUserProfile generates and stores one user's profile from their stats
+// and their own newest captions.
+func writeUserProfile(ctx context.Context, userID string, stats rag.DecisionStats) error {
+	captionRows, err := executeQuery(ctx, `SELECT * FROM caption_decisions WHERE user_id = :user_id ORDER BY created_at DESC LIMIT 10`,
+		rdsdatatypes.SqlParameter{Name: aws.String("user_id"), Value: &rdsdatatypes.FieldMemberStringValue{Value: userID}})
+	if err != nil {
+		return fmt.Errorf("query caption_decisions for user: %w", err)
+	}
+	return writeProfile(ctx, rag.ProfilePK(userID), stats, parseCaptionDecisions(captionRows))
+}
 
-	stats := rag.ComputeStats(triageDecisions, overrideDecisions, selectionDecisions)
+// writeProfile asks Gemini for a narrative profile from stats and stores it,
+// with caption examples, under pk.
+func writeProfile(ctx context.Context, pk string, stats rag.DecisionStats, captionDecisions []rag.CaptionDecision) error {
 	prompt := rag.FormatStatsForLLM(stats)
 
 	systemPrompt := "You are a preference profile writer. Write a concise bullet-point preference profile based on the user's media curation statistics. Be specific. Do not invent patterns not present in the data."
@@ -565,7 +781,7 @@ func buildAndWriteProfile(ctx context.Context, _ *rag.DataAPIClient) error {
 
 	computedAt := time.Now().UTC().Format(time.RFC3339)
 	item := map[string]types.AttributeValue{
-		"PK":                  &types.AttributeValueMemberS{Value: "PROFILE#preference"},
+		"PK":                  &types.AttributeValueMemberS{Value: pk},
 		"SK":                  &types.AttributeValueMemberS{Value: "latest"},
 		"profileText":         &types.AttributeValueMemberS{Value: profileText},
 		"captionExamplesText": &types.AttributeValueMemberS{Value: captionExamplesText},
@@ -581,17 +797,22 @@ func buildAndWriteProfile(ctx context.Context, _ *rag.DataAPIClient) error {
 		return fmt.Errorf("write profile to DynamoDB: %w", err)
 	}
 
-	log.Info().
+	log.Debug().
+		Str("pk", pk).
 		Str("computedAt", computedAt).
-		Int("triageCount", len(triageDecisions)).
-		Int("selectionCount", len(selectionDecisions)).
-		Int("overrideCount", len(overrideDecisions)).
+		Int("totalDecisions", stats.TotalDecisions).
 		Int("captionCount", len(captionDecisions)).
-		Msg("Preference profile computed and stored")
-
+		Msg("Preference profile stored")
 	return nil
 }
 
+func recomputeMode(fullReason string) string {
+	if fullReason != "" {
+		return "full"
+	}
+	return "incremental"
+}
+
 func buildCaptionExamples(decisions []rag.CaptionDecision, n int) string {
 	if n > len(decisions) {
 		n = len(decisions)
@@ -673,12 +894,13 @@ func deleteStagingItems(ctx context.Context, items []stagingItem) {
 // Aurora Data API helpers (for profile queries)
 // ---------------------------------------------------------------------------
 
-func executeQuery(ctx context.Context, sql string) ([]map[string]interface{}, error) {
+func executeQuery(ctx context.Context, sql string, params ...rdsdatatypes.SqlParameter) ([]map[string]interface{}, error) {
 	result, err := rdsDataClient.ExecuteStatement(ctx, &rdsdata.ExecuteStatementInput{
 		ResourceArn: aws.String(clusterARN),
 		SecretArn:   aws.String(secretARN),
 		Database:    aws.String(database),
 		Sql:         aws.String(sql),
+		Parameters:  params,
 	})
 	if err != nil {
 		return nil, err
@@ -880,10 +1102,11 @@ func main() {
 	database = os.Getenv("AURORA_DATABASE_NAME")
 	profilesTable = os.Getenv("RAG_PROFILES_TABLE_NAME")
 	stagingTable = os.Getenv("STAGING_TABLE_NAME")
+	forceFullRecompute = os.Getenv("RAG_FULL_RECOMPUTE") == "true"
 
 	if os.Getenv("SSM_API_KEY_PARAM") == "" {
 		os.Setenv("SSM_API_KEY_PARAM", "/ai-social-media/prod/gemini-api-key")
 	}
 
-	lambda.Start(handler)
+	bootstrap.Start(handler)
 }
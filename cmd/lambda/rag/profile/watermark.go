@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	rdsdatatypes "github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+	"github.com/fpang/ai-social-media-helper/internal/rag"
+)
+
+// ---------------------------------------------------------------------------
+// Incremental profile state (DDR-103)
+// ---------------------------------------------------------------------------
+
+// profileStateVersion is bumped whenever the state changes shape; a stored
+// state with a different version forces a full recompute. Version 2 added
+// per-user stats (DDR-105); version 3 moved them to an item per user.
+const profileStateVersion = 3
+
+// watermarkSettleLag holds back the newest decisions so that events still in
+// flight to the staging table when the batch started, which carry earlier
+// timestamps, are not skipped by the next run's watermark.
+const watermarkSettleLag = 15 * time.Minute
+
+// fullRecomputeInterval bounds drift from approximations in incremental
+// merging (see rag.MergeStats) by recomputing from scratch periodically.
+const fullRecomputeInterval = 7 * 24 * time.Hour
+
+// profileState is the last merged global stats plus, per decision table, the
+// created_at up to which decisions have been folded into them. It lives next
+// to the global profile in the profiles table (PK = PROFILE#preference,
+// SK = state).
+type profileState struct {
+	Watermarks map[string]time.Time
+	Stats      rag.DecisionStats
+	LastFullAt time.Time
+	Version    int
+}
+
+// userState is one user's last merged stats (DDR-105). It lives next to the
+// user's profile (PK = PROFILE#<userId>, SK = state), so its size does not
+// grow with the number of users. Until is the watermark the stats were merged
+// up to; stats that do not line up with the global watermarks are rebuilt
+// (see mergeUserStats).
+type userState struct {
+	Stats   rag.DecisionStats
+	Until   time.Time
+	Version int
+}
+
+func profileStateKey() map[string]types.AttributeValue {
+	return stateKey(rag.GlobalProfilePK)
+}
+
+func userStateKey(userID string) map[string]types.AttributeValue {
+	return stateKey(rag.ProfilePK(userID))
+}
+
+func stateKey(pk string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: pk},
+		"SK": &types.AttributeValueMemberS{Value: "state"},
+	}
+}
+
+// readProfileState returns the stored state, or nil if there is none or it
+// cannot be decoded (both of which mean a full recompute).
+func readProfileState(ctx context.Context) (*profileState, error) {
+	out, err := dynamoClient.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(profilesTable),
+		Key:       profileStateKey(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read profile state: %w", err)
+	}
+	if out.Item == nil {
+		return nil, nil
+	}
+
+	state := &profileState{Watermarks: make(map[string]time.Time)}
+	if v, ok := out.Item["version"].(*types.AttributeValueMemberN); ok {
+		state.Version, _ = strconv.Atoi(v.Value)
+	}
+	if v, ok := out.Item["lastFullAt"].(*types.AttributeValueMemberS); ok {
+		state.LastFullAt, _ = time.Parse(time.RFC3339Nano, v.Value)
+	}
+	if v, ok := out.Item["watermarks"].(*types.AttributeValueMemberM); ok {
+		for table, av := range v.Value {
+			s, ok := av.(*types.AttributeValueMemberS)
+			if !ok {
+				continue
+			}
+			if t, err := time.Parse(time.RFC3339Nano, s.Value); err == nil {
+				state.Watermarks[table] = t
+			}
+		}
+	}
+	v, ok := out.Item["stats"].(*types.AttributeValueMemberS)
+	if !ok {
+		return nil, nil
+	}
+	if err := json.Unmarshal([]byte(v.Value), &state.Stats); err != nil {
+		return nil, nil
+	}
+	return state, nil
+}
+
+func writeProfileState(ctx context.Context, state *profileState) error {
+	statsJSON, err := json.Marshal(state.Stats)
+	if err != nil {
+		return fmt.Errorf("marshal profile stats: %w", err)
+	}
+	watermarks := make(map[string]types.AttributeValue, len(state.Watermarks))
+	for table, t := range state.Watermarks {
+		watermarks[table] = &types.AttributeValueMemberS{Value: t.UTC().Format(time.RFC3339Nano)}
+	}
+
+	item := profileStateKey()
+	item["watermarks"] = &types.AttributeValueMemberM{Value: watermarks}
+	item["stats"] = &types.AttributeValueMemberS{Value: string(statsJSON)}
+	item["lastFullAt"] = &types.AttributeValueMemberS{Value: state.LastFullAt.UTC().Format(time.RFC3339Nano)}
+	item["version"] = &types.AttributeValueMemberN{Value: strconv.Itoa(state.Version)}
+
+	if _, err := dynamoClient.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(profilesTable),
+		Item:      item,
+	}); err != nil {
+		return fmt.Errorf("write profile state: %w", err)
+	}
+	return nil
+}
+
+// readUserState returns the user's stored state, or nil if there is none or
+// it cannot be used (both of which mean the user's stats are rebuilt).
+func readUserState(ctx context.Context, userID string) (*userState, error) {
+	out, err := dynamoClient.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(profilesTable),
+		Key:       userStateKey(userID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read user profile state: %w", err)
+	}
+	if out.Item == nil {
+		return nil, nil
+	}
+
+	state := &userState{}
+	if v, ok := out.Item["version"].(*types.AttributeValueMemberN); ok {
+		state.Version, _ = strconv.Atoi(v.Value)
+	}
+	if state.Version != profileStateVersion {
+		return nil, nil
+	}
+	v, ok := out.Item["until"].(*types.AttributeValueMemberS)
+	if !ok {
+		return nil, nil
+	}
+	if state.Until, err = time.Parse(time.RFC3339Nano, v.Value); err != nil {
+		return nil, nil
+	}
+	v, ok = out.Item["stats"].(*types.AttributeValueMemberS)
+	if !ok {
+		return nil, nil
+	}
+	if err := json.Unmarshal([]byte(v.Value), &state.Stats); err != nil {
+		return nil, nil
+	}
+	return state, nil
+}
+
+func writeUserState(ctx context.Context, userID string, state *userState) error {
+	statsJSON, err := json.Marshal(state.Stats)
+	if err != nil {
+		return fmt.Errorf("marshal user profile stats: %w", err)
+	}
+
+	item := userStateKey(userID)
+	item["stats"] = &types.AttributeValueMemberS{Value: string(statsJSON)}
+	item["until"] = &types.AttributeValueMemberS{Value: state.Until.UTC().Format(time.RFC3339Nano)}
+	item["version"] = &types.AttributeValueMemberN{Value: strconv.Itoa(state.Version)}
+
+	if _, err := dynamoClient.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(profilesTable),
+		Item:      item,
+	}); err != nil {
+		return fmt.Errorf("write user profile state: %w", err)
+	}
+	return nil
+}
+
+// needsFullRecompute reports why the stored state cannot be merged into, or
+// "" if an incremental run is safe.
+func needsFullRecompute(state *profileState, forced bool, now time.Time) string {
+	switch {
+	case forced:
+		return "forced"
+	case state == nil:
+		return "no state"
+	case state.Version != profileStateVersion:
+		return "state version changed"
+	case now.Sub(state.LastFullAt) > fullRecomputeInterval:
+		return "periodic"
+	}
+	return ""
+}
+
+// windowQuery selects the rows of table created in (since, until]. A zero
+// since selects everything up to until.
+func windowQuery(table, where string, since, until time.Time) (string, []rdsdatatypes.SqlParameter) {
+	sql := fmt.Sprintf(`SELECT * FROM %s WHERE created_at <= :until::timestamptz`, table)
+	params := []rdsdatatypes.SqlParameter{
+		{Name: aws.String("until"), Value: &rdsdatatypes.FieldMemberStringValue{Value: until.UTC().Format(time.RFC3339Nano)}},
+	}
+	if !since.IsZero() {
+		sql += ` AND created_at > :since::timestamptz`
+		params = append(params, rdsdatatypes.SqlParameter{
+			Name: aws.String("since"), Value: &rdsdatatypes.FieldMemberStringValue{Value: since.UTC().Format(time.RFC3339Nano)},
+		})
+	}
+	if where != "" {
+		sql += " AND " + where
+	}
+	return sql + " ORDER BY created_at DESC", params
+}
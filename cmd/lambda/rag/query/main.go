@@ -42,11 +42,12 @@ type profileItem struct {
 
 // handler returns the pre-computed preference profile from DynamoDB (DDR-068).
 // Aurora is no longer queried at request time; all data comes from the daily
-// batch-built profile stored in the rag-preference-profiles table.
+// batch-built profile stored in the rag-preference-profiles table. The
+// caller's own profile is preferred, falling back to the global one (DDR-105).
 func handler(ctx context.Context, event QueryEvent) (QueryResponse, error) {
-	profile, err := readProfile(ctx)
+	profile, err := readUserProfile(ctx, event.UserID)
 	if err != nil {
-		log.Warn().Err(err).Msg("readProfile failed")
+		log.Warn().Err(err).Msg("readUserProfile failed")
 		return QueryResponse{RAGContext: "", Source: "empty"}, nil
 	}
 
@@ -70,7 +71,20 @@ func handler(ctx context.Context, event QueryEvent) (QueryResponse, error) {
 	return QueryResponse{RAGContext: "", Source: "empty"}, nil
 }
 
-func readProfile(ctx context.Context) (*rag.PreferenceProfile, error) {
+// readUserProfile reads the profile stored for userID, or the global profile
+// if the user has none yet.
+func readUserProfile(ctx context.Context, userID string) (*rag.PreferenceProfile, error) {
+	if userID != "" {
+		profile, err := readProfile(ctx, rag.ProfilePK(userID))
+		if err != nil || profile != nil {
+			return profile, err
+		}
+		log.Debug().Str("userId", userID).Msg("No user profile, using global profile")
+	}
+	return readProfile(ctx, rag.GlobalProfilePK)
+}
+
+func readProfile(ctx context.Context, pk string) (*rag.PreferenceProfile, error) {
 	if profilesTable == "" || ddbClient == nil {
 		return nil, nil
 	}
@@ -78,7 +92,7 @@ func readProfile(ctx context.Context) (*rag.PreferenceProfile, error) {
 	result, err := ddbClient.GetItem(ctx, &dynamodb.GetItemInput{
 		TableName: aws.String(profilesTable),
 		Key: map[string]types.AttributeValue{
-			"PK": &types.AttributeValueMemberS{Value: "PROFILE#preference"},
+			"PK": &types.AttributeValueMemberS{Value: pk},
 			"SK": &types.AttributeValueMemberS{Value: "latest"},
 		},
 	})
@@ -0,0 +1,68 @@
+package ai
+
+// alt_text.go asks Gemini to describe a photo for screen readers, so every
+// published image carries alt-text. See DDR-154: Alt-Text on Every
+// Published Image.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// MaxAltTextLength caps generated alt-text, in characters. Screen readers
+// read it in one go, so it is kept far below the platforms' own limits.
+const MaxAltTextLength = 300
+
+const altTextSystemInstruction = `You write alt-text for photos posted to social media, for people using screen readers.
+Describe what is visible: the subject, the setting, notable details and any readable text.
+Write 1-2 plain sentences under 300 characters. Do not start with "Image of" or "Photo of".
+Do not use emojis or hashtags, and do not guess names, feelings or places that are not evident.
+Respond with the alt-text only, without quotes or labels.`
+
+// GenerateAltText returns concise, screen-reader-friendly alt-text for a
+// photo. hint, when set, is context the photo alone does not show, such as
+// the trip or place; the description uses it only where it fits.
+func GenerateAltText(ctx context.Context, client *GeminiImageClient, imageData []byte, imageMIMEType, hint string) (string, error) {
+	log.Debug().Int("image_bytes", len(imageData)).Msg("GenerateAltText: starting")
+
+	prompt := "Write the alt-text for this photo."
+	if hint = strings.TrimSpace(hint); hint != "" {
+		prompt += "\nContext: " + hint
+	}
+	text, err := client.AnalyzeImage(ctx, imageData, imageMIMEType, prompt, altTextSystemInstruction)
+	if err != nil {
+		return "", fmt.Errorf("generate alt-text: %w", err)
+	}
+	altText := CleanAltText(text)
+	if altText == "" {
+		return "", fmt.Errorf("generate alt-text: empty response")
+	}
+	log.Debug().Int("length", len(altText)).Msg("GenerateAltText: complete")
+	return altText, nil
+}
+
+// CleanAltText normalizes model-written alt-text: one line, no surrounding
+// quotes or "Alt-text:" label, and at most MaxAltTextLength characters,
+// cut at a word boundary.
+func CleanAltText(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	for _, label := range []string{"alt-text:", "alt text:"} {
+		if len(text) >= len(label) && strings.EqualFold(text[:len(label)], label) {
+			text = strings.TrimSpace(text[len(label):])
+		}
+	}
+	text = strings.Trim(text, `"'“”`)
+
+	runes := []rune(text)
+	if len(runes) <= MaxAltTextLength {
+		return text
+	}
+	cut := string(runes[:MaxAltTextLength-1])
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:") + "…"
+}
@@ -0,0 +1,28 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestCleanAltText(t *testing.T) {
+	for in, want := range map[string]string{
+		"  A red tram climbs\na steep street. ": "A red tram climbs a steep street.",
+		`"A cat asleep on a windowsill."`:       "A cat asleep on a windowsill.",
+		"Alt-text: Two hikers on a ridge.":      "Two hikers on a ridge.",
+		"":                                      "",
+	} {
+		if got := CleanAltText(in); got != want {
+			t.Errorf("CleanAltText(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	long := CleanAltText(strings.Repeat("lantern ", 100))
+	if n := utf8.RuneCountInString(long); n > MaxAltTextLength {
+		t.Errorf("long alt-text has %d characters, want at most %d", n, MaxAltTextLength)
+	}
+	if !strings.HasSuffix(long, "lantern…") {
+		t.Errorf("long alt-text not cut at a word boundary: %q", long[len(long)-20:])
+	}
+}
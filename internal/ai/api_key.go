@@ -0,0 +1,114 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Rotating Gemini API key (DDR-172) ---
+
+// KeySource supplies the Gemini API key. Value may return a cached key;
+// Refresh reads it again after Gemini rejected it. *bootstrap.Secret
+// implements it.
+type KeySource interface {
+	Value(ctx context.Context) (string, error)
+	Refresh(ctx context.Context) (string, error)
+}
+
+var apiKeys struct {
+	mu  sync.RWMutex
+	src KeySource
+}
+
+// SetAPIKeySource makes Gemini API requests carry the key from src instead
+// of the GEMINI_API_KEY the client was created with. nil restores that key.
+func SetAPIKeySource(src KeySource) {
+	apiKeys.mu.Lock()
+	defer apiKeys.mu.Unlock()
+	apiKeys.src = src
+}
+
+func apiKeySource() KeySource {
+	apiKeys.mu.RLock()
+	defer apiKeys.mu.RUnlock()
+	return apiKeys.src
+}
+
+// maxRejectionPeek bounds how much of a 400 response body
+// credentialRejected reads to look for the API key error reason.
+const maxRejectionPeek = 64 << 10
+
+// credentialRejected reports whether resp means the credential was not
+// accepted, so a rotated one may succeed. Gemini answers a deleted or
+// rotated API key with 400 INVALID_ARGUMENT and reason API_KEY_INVALID, so
+// the body of a 400 is peeked at and then restored for the caller.
+func credentialRejected(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return true
+	case http.StatusBadRequest:
+	default:
+		return false
+	}
+	peek, _ := io.ReadAll(io.LimitReader(resp.Body, maxRejectionPeek))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peek), resp.Body), resp.Body}
+	return bytes.Contains(peek, []byte("API_KEY_INVALID"))
+}
+
+// apiKeyTransport sets the current API key on Gemini API requests and, when
+// Gemini rejects it, reloads the key and sends the request once more.
+// Vertex AI requests carry no API key and pass straight through.
+type apiKeyTransport struct {
+	base http.RoundTripper
+}
+
+func (t *apiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	src := apiKeySource()
+	if src == nil || req.Header.Get("x-goog-api-key") == "" {
+		return t.base.RoundTrip(req)
+	}
+	ctx := req.Context()
+	key, err := src.Value(ctx)
+	if err != nil || key == "" {
+		return t.base.RoundTrip(req)
+	}
+	resp, err := t.base.RoundTrip(withAPIKey(req, key))
+	if err != nil || !credentialRejected(resp) || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return resp, err
+	}
+
+	fresh, rerr := src.Refresh(ctx)
+	if rerr != nil || fresh == "" || fresh == key {
+		return resp, err
+	}
+	log.Warn().Int("status", resp.StatusCode).Msg("Gemini rejected the API key, retrying with the reloaded key")
+	metrics.New("AiSocialMedia").Dimension("Service", "gemini").Count("CredentialRefreshes").Flush()
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	retry := withAPIKey(req, fresh)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	return t.base.RoundTrip(retry)
+}
+
+// withAPIKey returns a copy of req that carries key.
+func withAPIKey(req *http.Request, key string) *http.Request {
+	r := req.Clone(req.Context())
+	r.Header.Set("x-goog-api-key", key)
+	return r
+}
@@ -0,0 +1,111 @@
+package ai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// staticKeys returns key until refreshed, then next.
+type staticKeys struct {
+	key, next string
+	refreshed int
+}
+
+func (s *staticKeys) Value(context.Context) (string, error) { return s.key, nil }
+
+func (s *staticKeys) Refresh(context.Context) (string, error) {
+	s.refreshed++
+	s.key = s.next
+	return s.key, nil
+}
+
+// apiKeyInvalidBody is the error Gemini returns for a deleted or rotated key.
+const apiKeyInvalidBody = `{
+  "error": {
+    "code": 400,
+    "message": "API key not valid. Please pass a valid API key.",
+    "status": "INVALID_ARGUMENT",
+    "details": [
+      {
+        "@type": "type.googleapis.com/google.rpc.ErrorInfo",
+        "reason": "API_KEY_INVALID",
+        "domain": "googleapis.com",
+        "metadata": {"service": "generativelanguage.googleapis.com"}
+      }
+    ]
+  }
+}`
+
+func TestAPIKeyTransportRetriesRejectedKey(t *testing.T) {
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		seen = append(seen, r.Header.Get("x-goog-api-key")+":"+string(body))
+		if r.Header.Get("x-goog-api-key") != "rotated" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(apiKeyInvalidBody))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	keys := &staticKeys{key: "stale", next: "rotated"}
+	SetAPIKeySource(keys)
+	defer SetAPIKeySource(nil)
+
+	client := &http.Client{Transport: &apiKeyTransport{base: http.DefaultTransport}}
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("prompt"))
+	req.Header.Set("x-goog-api-key", "from-env")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || keys.refreshed != 1 {
+		t.Errorf("status = %d, refreshes = %d", resp.StatusCode, keys.refreshed)
+	}
+	if want := "stale:prompt rotated:prompt"; strings.Join(seen, " ") != want {
+		t.Errorf("requests = %v, want %s", seen, want)
+	}
+
+	// Vertex AI requests carry no API key and are left alone.
+	seen = nil
+	req, _ = http.NewRequest(http.MethodGet, srv.URL, nil)
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(seen) != 1 || seen[0] != ":" {
+		t.Errorf("keyless request sent as %v", seen)
+	}
+}
+
+func TestCredentialRejected(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   bool
+	}{
+		{http.StatusBadRequest, apiKeyInvalidBody, true},
+		{http.StatusForbidden, `{"error":{"status":"PERMISSION_DENIED"}}`, true},
+		{http.StatusUnauthorized, "", true},
+		{http.StatusBadRequest, `{"error":{"status":"INVALID_ARGUMENT","message":"Request contains an invalid argument."}}`, false},
+		{http.StatusTooManyRequests, apiKeyInvalidBody, false},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status, Body: io.NopCloser(strings.NewReader(tt.body))}
+		if got := credentialRejected(resp); got != tt.want {
+			t.Errorf("credentialRejected(%d, %.40q) = %v, want %v", tt.status, tt.body, got, tt.want)
+		}
+		// The caller still reads the whole error.
+		if body, _ := io.ReadAll(resp.Body); string(body) != tt.body {
+			t.Errorf("body after credentialRejected(%d) = %q, want %q", tt.status, body, tt.body)
+		}
+	}
+}
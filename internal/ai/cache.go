@@ -224,6 +224,7 @@ func (cm *CacheManager) GenerateWithCache(
 	if extraConfig != nil {
 		*config = *extraConfig
 	}
+	applySampling(ctx, config) // DDR-170
 
 	var contents []*genai.Content
 
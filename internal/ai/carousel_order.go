@@ -0,0 +1,176 @@
+package ai
+
+// carousel_order.go asks Gemini how to order the items of a post group, so
+// the carousel opens on its strongest image. See DDR-179: AI Carousel
+// Ordering.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genai"
+
+	"github.com/fpang/ai-social-media-helper/internal/artifacts"
+	"github.com/fpang/ai-social-media-helper/internal/jsonutil"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+)
+
+// CarouselOrder is a suggested order for a post group's items. Order holds
+// indices into the items passed to SuggestCarouselOrder, opening item first,
+// and lists every item exactly once.
+type CarouselOrder struct {
+	Order         []int
+	OpeningReason string
+	Rationale     string
+}
+
+type carouselOrderResponse struct {
+	Order         []int  `json:"order"`
+	OpeningReason string `json:"opening_reason"`
+	Rationale     string `json:"rationale"`
+}
+
+const carouselOrderSystemInstruction = `You order the photos and videos of an Instagram carousel post.
+The first item is the cover: it shows in the feed and decides whether people stop scrolling, so pick the most striking, clear and representative item.
+After the cover, order the rest so the post tells a story: vary subjects and framing, keep related shots together, avoid near-duplicates side by side, and end on a strong image.
+Return only JSON: {"order": [item numbers, cover first], "opening_reason": "why the cover leads", "rationale": "how the rest is ordered"}
+List every item number exactly once. Keep each explanation to 1-2 sentences.`
+
+// SuggestCarouselOrder asks Gemini for the order of a post group's items,
+// cover first. items carry the thumbnails and metadata the description step
+// uses. groupLabel and tripContext, when set, say what the post is about.
+// Numbers Gemini leaves out or repeats are repaired, so the order always
+// covers every item.
+func SuggestCarouselOrder(ctx context.Context, client *genai.Client, groupLabel, tripContext string, items []DescriptionMediaItem) (*CarouselOrder, error) {
+	log.Debug().Int("media_count", len(items)).Msg("SuggestCarouselOrder: starting")
+	if len(items) == 0 {
+		return nil, fmt.Errorf("suggest carousel order: no media items")
+	}
+
+	config := &genai.GenerateContentConfig{
+		SystemInstruction: &genai.Content{
+			Parts: []*genai.Part{{Text: carouselOrderSystemInstruction}},
+		},
+		ResponseMIMEType: "application/json",
+		ThinkingConfig:   thinkingConfig(ctx), // DDR-170
+	}
+	applySampling(ctx, config)
+
+	var parts []*genai.Part
+	for _, item := range items {
+		if len(item.ThumbnailData) == 0 {
+			return nil, fmt.Errorf("suggest carousel order: %s has no thumbnail", item.Filename)
+		}
+		parts = append(parts, &genai.Part{
+			InlineData: &genai.Blob{MIMEType: item.ThumbnailMIMEType, Data: item.ThumbnailData},
+		})
+	}
+	prompt := carouselOrderPrompt(groupLabel, tripContext, items)
+	parts = append(parts, &genai.Part{Text: prompt})
+
+	modelName := modelFor(ctx, GetModelName())
+	artifacts.SaveText(ctx, "carousel-order-prompt.txt", prompt)
+	callStart := time.Now()
+	resp, err := client.Models.GenerateContent(ctx, modelName, []*genai.Content{{Role: "user", Parts: parts}}, config)
+	duration := time.Since(callStart)
+	metrics.RecordGeminiCall(ctx, duration)
+
+	m := metrics.New("AiSocialMedia").
+		Dimension("Operation", "carouselOrder").
+		Metric("GeminiApiLatencyMs", float64(duration.Milliseconds()), metrics.UnitMilliseconds).
+		Count("GeminiApiCalls")
+	if err != nil {
+		m.Count("GeminiApiErrors")
+	}
+	if resp != nil && resp.UsageMetadata != nil {
+		m.Metric("GeminiInputTokens", float64(resp.UsageMetadata.PromptTokenCount), metrics.UnitCount)
+		metrics.RecordGeminiTokens(ctx, int64(resp.UsageMetadata.PromptTokenCount)) // DDR-186
+		m.Metric("GeminiOutputTokens", float64(resp.UsageMetadata.CandidatesTokenCount), metrics.UnitCount)
+	}
+	m.Flush()
+
+	if err != nil {
+		log.Error().Err(err).Dur("duration", duration).Msg("Failed to get carousel order from Gemini")
+		return nil, fmt.Errorf("suggest carousel order: %w", err)
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("suggest carousel order: empty response")
+	}
+
+	text := resp.Text()
+	artifacts.SaveText(ctx, "carousel-order-response.txt", text)
+	order, err := parseCarouselOrderResponse(text, len(items))
+	if err != nil {
+		log.Warn().Err(err).Str("response", truncateString(text, 500)).Msg("Failed to parse carousel order response")
+		return nil, err
+	}
+	log.Info().Int("items", len(items)).Int("opening", order.Order[0]).Dur("duration", duration).Msg("SuggestCarouselOrder: complete")
+	return order, nil
+}
+
+// carouselOrderPrompt numbers the items in the order their thumbnails are
+// attached, with what the description step knows about each.
+func carouselOrderPrompt(groupLabel, tripContext string, items []DescriptionMediaItem) string {
+	var sb strings.Builder
+	sb.WriteString("## Carousel Ordering\n\n")
+	if groupLabel != "" {
+		sb.WriteString(fmt.Sprintf("Post: %s\n", groupLabel))
+	}
+	if tripContext != "" {
+		sb.WriteString(fmt.Sprintf("Trip context: %s\n", tripContext))
+	}
+	sb.WriteString("\nThe attached images are these items, in order (videos are shown by a frame):\n\n")
+	for i, item := range items {
+		sb.WriteString(fmt.Sprintf("- Item %d: %s (%s)", i+1, item.Filename, item.Type))
+		if item.Scene != "" {
+			sb.WriteString(fmt.Sprintf(", scene: %s", item.Scene))
+		}
+		if item.HasDate {
+			sb.WriteString(fmt.Sprintf(", taken %s", item.Date))
+		}
+		if item.Place != "" {
+			sb.WriteString(fmt.Sprintf(", at %s", item.Place))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(fmt.Sprintf("\nOrder all %d items for the carousel, cover first. Follow the response format in the system instruction exactly.", len(items)))
+	return sb.String()
+}
+
+// parseCarouselOrderResponse converts Gemini's 1-based item numbers to
+// indices. Numbers out of range or repeated are dropped, and items Gemini
+// left out are appended in their original order.
+func parseCarouselOrderResponse(response string, n int) (*CarouselOrder, error) {
+	parsed, err := jsonutil.ParseJSON[carouselOrderResponse](response)
+	if err != nil {
+		return nil, fmt.Errorf("carousel order response: %w", err)
+	}
+	seen := make([]bool, n)
+	order := make([]int, 0, n)
+	for _, num := range parsed.Order {
+		if num < 1 || num > n || seen[num-1] {
+			continue
+		}
+		seen[num-1] = true
+		order = append(order, num-1)
+	}
+	if len(order) == 0 {
+		return nil, fmt.Errorf("carousel order response: no valid item numbers")
+	}
+	if len(order) < n {
+		log.Warn().Int("listed", len(order)).Int("items", n).Msg("Carousel order left items out — appending them")
+		for i := range seen {
+			if !seen[i] {
+				order = append(order, i)
+			}
+		}
+	}
+	return &CarouselOrder{
+		Order:         order,
+		OpeningReason: strings.TrimSpace(parsed.OpeningReason),
+		Rationale:     strings.TrimSpace(parsed.Rationale),
+	}, nil
+}
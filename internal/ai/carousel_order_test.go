@@ -0,0 +1,55 @@
+package ai
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCarouselOrderResponse(t *testing.T) {
+	got, err := parseCarouselOrderResponse("```json\n{\"order\": [3, 1, 2], \"opening_reason\": \" Sunset over the bay \", \"rationale\": \"Arrival first.\"}\n```", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Order, []int{2, 0, 1}) {
+		t.Errorf("order = %v", got.Order)
+	}
+	if got.OpeningReason != "Sunset over the bay" || got.Rationale != "Arrival first." {
+		t.Errorf("reasons = %q, %q", got.OpeningReason, got.Rationale)
+	}
+}
+
+func TestParseCarouselOrderResponseRepairsOrder(t *testing.T) {
+	got, err := parseCarouselOrderResponse(`{"order": [4, 0, 4, 9, 2]}`, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Order, []int{3, 1, 0, 2}) {
+		t.Errorf("order = %v", got.Order)
+	}
+
+	if _, err := parseCarouselOrderResponse(`{"order": [7]}`, 3); err == nil {
+		t.Error("order with no valid numbers accepted")
+	}
+	if _, err := parseCarouselOrderResponse("no json here", 3); err == nil {
+		t.Error("non-JSON response accepted")
+	}
+}
+
+func TestCarouselOrderPrompt(t *testing.T) {
+	prompt := carouselOrderPrompt("Day 1 — Lisbon", "Portugal trip", []DescriptionMediaItem{
+		{Filename: "tram.jpg", Type: "Photo", Place: "Alfama, Lisbon"},
+		{Filename: "fado.mp4", Type: "Video", Date: "2026-05-02", HasDate: true},
+	})
+	for _, want := range []string{
+		"Post: Day 1 — Lisbon",
+		"Trip context: Portugal trip",
+		"- Item 1: tram.jpg (Photo), at Alfama, Lisbon",
+		"- Item 2: fado.mp4 (Video), taken 2026-05-02",
+		"Order all 2 items",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+}
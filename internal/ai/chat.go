@@ -3,12 +3,14 @@ package ai
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/fpang/ai-social-media-helper/internal/assets"
+	"github.com/fpang/ai-social-media-helper/internal/logging"
 	"github.com/fpang/ai-social-media-helper/internal/media"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/genai"
@@ -38,9 +40,24 @@ func NewGeminiClient(ctx context.Context, apiKey string) (*genai.Client, error)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
 	}
+	traceOutbound(client)
 	return client, nil
 }
 
+// traceOutbound wraps the client's HTTP transport so every Gemini call logs
+// boundary entries with the caller's request ID (DDR-111), is counted in
+// the usage gauges (DDR-142), falls back to other models when its own is
+// overloaded (DDR-169), and carries the current API key (DDR-172).
+func traceOutbound(client *genai.Client) {
+	if hc := client.ClientConfig().HTTPClient; hc != nil {
+		base := hc.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		hc.Transport = logging.OutboundTransport("gemini", &fallbackTransport{base: &usageTransport{base: &apiKeyTransport{base: base}}})
+	}
+}
+
 // NewAIClient creates a Gemini client with automatic backend selection.
 // Priority: Vertex AI (if VERTEX_AI_PROJECT set) > Gemini API (if GEMINI_API_KEY set).
 func NewAIClient(ctx context.Context) (*genai.Client, error) {
@@ -59,6 +76,7 @@ func NewAIClient(ctx context.Context) (*genai.Client, error) {
 			Backend:  genai.BackendVertexAI,
 		})
 		if err == nil {
+			traceOutbound(client)
 			log.Info().Msg("Using Vertex AI backend")
 			return client, nil
 		}
@@ -78,6 +96,7 @@ func NewAIClient(ctx context.Context) (*genai.Client, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Gemini API client: %w", err)
 	}
+	traceOutbound(client)
 	log.Info().Msg("Using Gemini API backend (fallback)")
 	return client, nil
 }
@@ -85,6 +104,8 @@ func NewAIClient(ctx context.Context) (*genai.Client, error) {
 // LoadGCPServiceAccount writes the GCP service account JSON (from GCP_SERVICE_ACCOUNT_JSON env var)
 // to a temp file and sets GOOGLE_APPLICATION_CREDENTIALS for ADC.
 // No-op if the env var is not set (allows CLI usage without service account).
+// The JSON may be a service account key or a workload identity federation
+// config; ADC accepts either (DDR-171).
 func LoadGCPServiceAccount() error {
 	saJSON := os.Getenv("GCP_SERVICE_ACCOUNT_JSON")
 	if saJSON == "" {
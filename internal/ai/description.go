@@ -32,6 +32,53 @@ type DescriptionResult struct {
 	Caption     string   `json:"caption"`
 	Hashtags    []string `json:"hashtags"`
 	LocationTag string   `json:"locationTag"`
+	// AltText describes each post item for screen readers, in item order
+	// (DDR-151). Platforms with per-item alt-text publish it.
+	AltText []string `json:"altText,omitempty"`
+}
+
+// AltTextFor returns the alt-text aligned with a post of n items, or nil
+// when the model did not return exactly one entry per item; misaligned
+// alt-text would describe the wrong photo.
+func (r *DescriptionResult) AltTextFor(n int) []string {
+	if len(r.AltText) != n {
+		if len(r.AltText) > 0 {
+			log.Warn().Int("altText", len(r.AltText)).Int("items", n).Msg("Alt-text count does not match the items — dropping it")
+		}
+		return nil
+	}
+	out := make([]string, n)
+	for i, text := range r.AltText {
+		out[i] = CleanAltText(text)
+	}
+	return out
+}
+
+// CaptionTemplate is the recurring caption format of a post template
+// (DDR-122). The skeleton is an example or outline the caption should follow;
+// the hashtags must appear in the result.
+//
+// HashtagBank and SignOff come from the user's brand kit (DDR-149): the
+// caption picks the bank's hashtags that fit and ends with the sign-off.
+// Style is the user's caption persona (DDR-159).
+type CaptionTemplate struct {
+	Skeleton string
+	Hashtags []string
+
+	HashtagBank []string
+	SignOff     string
+
+	Style *CaptionStyle
+}
+
+// CaptionStyle is the voice every caption of a user follows (DDR-159). It
+// overrides the default tone and emoji guidance of the system instruction.
+// EmojiDensity is "none", "light" or "heavy"; Formality is "casual",
+// "neutral" or "formal"; empty fields keep the defaults.
+type CaptionStyle struct {
+	EmojiDensity string
+	Language     string
+	Formality    string
 }
 
 // DescriptionMediaItem represents a media item to include in the description prompt.
@@ -46,18 +93,27 @@ type DescriptionMediaItem struct {
 	ThumbnailData     []byte
 	ThumbnailMIMEType string
 
-	// For videos: Gemini Files API reference (uploaded separately)
+	// For videos: Gemini Files API reference (uploaded separately). The URI
+	// changes with every upload, so ContentHash, the hex SHA-256 of the
+	// video, keys the response cache instead; without it the caption is
+	// not cached (DDR-166).
 	VideoFileURI  string
 	VideoMIMEType string
+	ContentHash   string
 
 	// Metadata
 	GPSLat  float64
 	GPSLon  float64
 	HasGPS  bool
+	Place   string // reverse-geocoded place name for the GPS coordinates (DDR-137)
 	Date    string // formatted date string
 	HasDate bool
 }
 
+// omittedModelResponse stands in for a raw response the store no longer
+// keeps, so the conversation still alternates between user and model.
+const omittedModelResponse = "(Earlier caption omitted.)"
+
 // DescriptionConversationEntry records one round of description feedback.
 type DescriptionConversationEntry struct {
 	UserFeedback  string `json:"userFeedback"`
@@ -79,7 +135,9 @@ type DescriptionOutput struct {
 // mediaItems contains the thumbnail data and metadata for each item in the group.
 // cacheMgr is an optional CacheManager for context caching (DDR-065). Pass nil to disable.
 // sessionID is required when cacheMgr is provided.
+// tmpl is an optional caption format to follow (DDR-122); pass nil for none.
 // When economyMode is true, submits to Gemini Batch API and returns DescriptionOutput{BatchJobID}.
+// A caption cached for the same media and prompt is returned without a call (DDR-166).
 func GenerateDescription(
 	ctx context.Context,
 	client *genai.Client,
@@ -89,6 +147,7 @@ func GenerateDescription(
 	cacheMgr *CacheManager,
 	sessionID string,
 	ragContext string,
+	tmpl *CaptionTemplate,
 	economyMode bool,
 ) (*DescriptionOutput, error) {
 	log.Debug().
@@ -98,14 +157,16 @@ func GenerateDescription(
 		Msg("Starting description generation")
 
 	// Build the user prompt
-	prompt := BuildDescriptionPrompt(groupLabel, tripContext, mediaItems, ragContext)
+	prompt := BuildDescriptionPrompt(groupLabel, tripContext, mediaItems, ragContext, tmpl)
 
 	// Configure model with description system instruction
 	config := &genai.GenerateContentConfig{
 		SystemInstruction: &genai.Content{
 			Parts: []*genai.Part{{Text: assets.DescriptionSystemPrompt}},
 		},
+		ThinkingConfig: thinkingConfig(ctx), // DDR-170
 	}
+	applySampling(ctx, config)
 
 	// Build parts: media first, then text prompt
 	var parts []*genai.Part
@@ -145,7 +206,26 @@ func GenerateDescription(
 		Bool("cache_enabled", cacheMgr != nil).
 		Msg("Sending media to Gemini for caption generation...")
 
-	modelName := GetModelName()
+	modelName := modelFor(ctx, GetModelName()) // DDR-170
+
+	// DDR-166: A caption for the same media and prompt is served from the
+	// response cache, in economy mode too.
+	cache := responseCacheFrom(ctx)
+	var cacheKey string
+	if cache != nil {
+		cacheKey = descriptionCacheKey(ctx, modelName, prompt, mediaItems)
+	}
+	if cacheKey != "" {
+		var raw string
+		if getCachedResponse(ctx, cache, cacheKey, &raw) {
+			if result, err := parseDescriptionResponse(raw); err == nil {
+				recordResponseCache("description", 1, 0)
+				log.Info().Msg("Caption served from the response cache")
+				return &DescriptionOutput{Result: result, RawResponse: raw}, nil
+			}
+		}
+		recordResponseCache("description", 0, 1)
+	}
 
 	if economyMode {
 		contents := []*genai.Content{{Role: "user", Parts: parts}}
@@ -178,7 +258,7 @@ func GenerateDescription(
 		resp, err = cacheMgr.GenerateWithCache(ctx, CacheConfig{
 			SessionID: sessionID,
 			Operation: "description",
-		}, modelName, config.SystemInstruction, cacheContents, userParts, nil)
+		}, modelName, config.SystemInstruction, cacheContents, userParts, config)
 	} else {
 		log.Debug().
 			Str("model", modelName).
@@ -199,6 +279,7 @@ func GenerateDescription(
 	}
 
 	duration := time.Since(callStart)
+	metrics.RecordGeminiCall(ctx, duration)
 	if err != nil {
 		log.Error().Err(err).Dur("duration", duration).Msg("Failed to generate description from Gemini")
 		return nil, fmt.Errorf("failed to generate content: %w", err)
@@ -239,6 +320,7 @@ func GenerateDescription(
 
 	// DDR-088: Emit token metrics for cost analysis.
 	if resp != nil && resp.UsageMetadata != nil {
+		metrics.RecordGeminiTokens(ctx, int64(resp.UsageMetadata.PromptTokenCount)) // DDR-186
 		metrics.New("AiSocialMedia").
 			Dimension("Operation", "description").
 			Metric("GeminiInputTokens", float64(resp.UsageMetadata.PromptTokenCount), metrics.UnitCount).
@@ -246,6 +328,9 @@ func GenerateDescription(
 			Flush()
 	}
 
+	if cacheKey != "" {
+		putCachedResponse(ctx, cache, cacheKey, responseText)
+	}
 	return &DescriptionOutput{Result: result, RawResponse: responseText}, nil
 }
 
@@ -260,6 +345,7 @@ func RegenerateDescription(
 	mediaItems []DescriptionMediaItem,
 	feedback string,
 	history []DescriptionConversationEntry,
+	tmpl *CaptionTemplate,
 ) (*DescriptionResult, string, error) {
 	log.Debug().
 		Str("group_label", truncateString(groupLabel, 100)).
@@ -273,7 +359,9 @@ func RegenerateDescription(
 		SystemInstruction: &genai.Content{
 			Parts: []*genai.Part{{Text: assets.DescriptionSystemPrompt}},
 		},
+		ThinkingConfig: thinkingConfig(ctx), // DDR-170
 	}
+	applySampling(ctx, config)
 
 	// Build the initial user message with media
 	var initialParts []*genai.Part
@@ -306,7 +394,7 @@ func RegenerateDescription(
 	}
 
 	// Add the original prompt
-	prompt := BuildDescriptionPrompt(groupLabel, tripContext, mediaItems, "")
+	prompt := BuildDescriptionPrompt(groupLabel, tripContext, mediaItems, "", tmpl)
 	initialParts = append(initialParts, &genai.Part{Text: prompt})
 
 	// Build multi-turn conversation
@@ -317,10 +405,14 @@ func RegenerateDescription(
 
 	// Add conversation history
 	for _, entry := range history {
-		// Model's previous response
+		// Model's previous response; older rounds may no longer keep it (DDR-152)
+		text := entry.ModelResponse
+		if text == "" {
+			text = omittedModelResponse
+		}
 		contents = append(contents, &genai.Content{
 			Role:  "model",
-			Parts: []*genai.Part{{Text: entry.ModelResponse}},
+			Parts: []*genai.Part{{Text: text}},
 		})
 		// User's feedback
 		contents = append(contents, &genai.Content{
@@ -359,7 +451,7 @@ func RegenerateDescription(
 		Msg("Sending multi-turn feedback to Gemini...")
 
 	// Generate content with conversation history
-	modelName := GetModelName()
+	modelName := modelFor(ctx, GetModelName()) // DDR-170
 	callStart := time.Now()
 	log.Debug().
 		Str("model", modelName).
@@ -367,6 +459,7 @@ func RegenerateDescription(
 		Msg("Starting Gemini API call for description regeneration")
 	resp, err := client.Models.GenerateContent(ctx, modelName, contents, config)
 	duration := time.Since(callStart)
+	metrics.RecordGeminiCall(ctx, duration)
 	if err != nil {
 		log.Error().Err(err).Dur("duration", duration).Msg("Failed to regenerate description from Gemini")
 		return nil, "", fmt.Errorf("failed to generate content: %w", err)
@@ -395,6 +488,7 @@ func RegenerateDescription(
 
 	// DDR-088: Emit token metrics for cost analysis.
 	if resp.UsageMetadata != nil {
+		metrics.RecordGeminiTokens(ctx, int64(resp.UsageMetadata.PromptTokenCount)) // DDR-186
 		metrics.New("AiSocialMedia").
 			Dimension("Operation", "description").
 			Metric("GeminiInputTokens", float64(resp.UsageMetadata.PromptTokenCount), metrics.UnitCount).
@@ -409,7 +503,7 @@ func RegenerateDescription(
 
 // BuildDescriptionPrompt creates the user prompt for caption generation.
 // Combines the group label, trip context, and media metadata into a structured prompt.
-func BuildDescriptionPrompt(groupLabel string, tripContext string, mediaItems []DescriptionMediaItem, ragContext string) string {
+func BuildDescriptionPrompt(groupLabel string, tripContext string, mediaItems []DescriptionMediaItem, ragContext string, tmpl *CaptionTemplate) string {
 	log.Trace().
 		Int("media_count", len(mediaItems)).
 		Msg("Building description prompt")
@@ -458,6 +552,9 @@ func BuildDescriptionPrompt(groupLabel string, tripContext string, mediaItems []
 		if item.Scene != "" {
 			sb.WriteString(fmt.Sprintf("- Scene: %s\n", item.Scene))
 		}
+		if item.Place != "" {
+			sb.WriteString(fmt.Sprintf("- Location: %s\n", item.Place))
+		}
 		if item.HasGPS {
 			sb.WriteString(fmt.Sprintf("- GPS: %.6f, %.6f\n", item.GPSLat, item.GPSLon))
 		}
@@ -467,12 +564,19 @@ func BuildDescriptionPrompt(groupLabel string, tripContext string, mediaItems []
 		sb.WriteString("\n")
 	}
 
+	writeCaptionTemplate(&sb, tmpl)
+
 	sb.WriteString("### Instructions\n\n")
 	sb.WriteString("1. Look at ALL the provided media to understand the visual story\n")
 	sb.WriteString("2. Use the group description as your primary guide for the caption's theme and tone\n")
 	sb.WriteString("3. Reference specific visual details you see in the photos/videos\n")
-	sb.WriteString("4. Use GPS coordinates to identify the location for the location tag\n")
-	sb.WriteString("5. Respond with ONLY the JSON object as specified in the system instruction\n")
+	sb.WriteString("4. Use the item locations for the location tag and any place names in the caption; fall back to GPS coordinates only for items without a location\n")
+	if tmpl != nil {
+		sb.WriteString("5. Follow the caption format and brand guidance above\n")
+		sb.WriteString("6. Respond with ONLY the JSON object as specified in the system instruction\n")
+	} else {
+		sb.WriteString("5. Respond with ONLY the JSON object as specified in the system instruction\n")
+	}
 
 	prompt := sb.String()
 	if ragContext != "" {
@@ -481,6 +585,90 @@ func BuildDescriptionPrompt(groupLabel string, tripContext string, mediaItems []
 	return prompt
 }
 
+// writeCaptionTemplate adds the brand guidance and the post template's
+// caption format (DDR-122). The skeleton sets structure and recurring lines
+// only; the content still comes from this post's media.
+func writeCaptionTemplate(sb *strings.Builder, tmpl *CaptionTemplate) {
+	if tmpl == nil {
+		return
+	}
+	writeCaptionStyle(sb, tmpl.Style)
+	writeBrandGuidance(sb, tmpl)
+	if tmpl.Skeleton == "" && len(tmpl.Hashtags) == 0 {
+		return
+	}
+	sb.WriteString("### Recurring Caption Format\n\n")
+	sb.WriteString("This post is part of a recurring series. Match the structure, length, tone and any fixed lines (sign-offs, series names, emoji markers) of this caption skeleton, but describe what is in THIS post's media:\n\n")
+	if tmpl.Skeleton != "" {
+		sb.WriteString("```\n")
+		sb.WriteString(tmpl.Skeleton)
+		sb.WriteString("\n```\n\n")
+	}
+	if len(tmpl.Hashtags) > 0 {
+		sb.WriteString("Always include these hashtags (add others only if they fit): ")
+		for i, tag := range tmpl.Hashtags {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString("#" + tag)
+		}
+		sb.WriteString("\n\n")
+	}
+}
+
+// writeCaptionStyle adds the user's caption persona (DDR-159).
+func writeCaptionStyle(sb *strings.Builder, style *CaptionStyle) {
+	if style == nil || (style.EmojiDensity == "" && style.Language == "" && style.Formality == "") {
+		return
+	}
+	sb.WriteString("### Caption Voice\n\n")
+	sb.WriteString("The account's own voice. It takes precedence over the tone and emoji guidance in the system instruction:\n\n")
+	if style.Language != "" {
+		sb.WriteString(fmt.Sprintf("- Write the caption in %s. Hashtags may stay in the language they are usually searched in.\n", style.Language))
+	}
+	switch style.EmojiDensity {
+	case "none":
+		sb.WriteString("- Use no emojis at all.\n")
+	case "light":
+		sb.WriteString("- Use at most 1-2 emojis in the whole caption.\n")
+	case "heavy":
+		sb.WriteString("- Use emojis generously, 8 or more across the caption.\n")
+	}
+	switch style.Formality {
+	case "casual":
+		sb.WriteString("- Keep it casual and chatty, like texting a friend.\n")
+	case "neutral":
+		sb.WriteString("- Keep it friendly but neutral, with little slang.\n")
+	case "formal":
+		sb.WriteString("- Keep it polished and formal, with no slang.\n")
+	}
+	sb.WriteString("\n")
+}
+
+// writeBrandGuidance adds the user's brand kit (DDR-149). Unlike a template's
+// hashtags, the bank is a pool to choose from, not a required list.
+func writeBrandGuidance(sb *strings.Builder, tmpl *CaptionTemplate) {
+	if len(tmpl.HashtagBank) == 0 && tmpl.SignOff == "" {
+		return
+	}
+	sb.WriteString("### Brand Guidance\n\n")
+	if len(tmpl.HashtagBank) > 0 {
+		sb.WriteString("The account's own hashtags. Include the ones that fit this post: ")
+		for i, tag := range tmpl.HashtagBank {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString("#" + tag)
+		}
+		sb.WriteString("\n\n")
+	}
+	if tmpl.SignOff != "" {
+		sb.WriteString("End the caption with this sign-off, exactly as written:\n\n")
+		sb.WriteString(tmpl.SignOff)
+		sb.WriteString("\n\n")
+	}
+}
+
 // --- Response parsing ---
 
 // parseDescriptionResponse extracts and parses the JSON caption from Gemini's response.
@@ -500,6 +688,7 @@ func parseDescriptionResponse(response string) (*DescriptionResult, error) {
 		Int("caption_length", len(result.Caption)).
 		Int("hashtag_count", len(result.Hashtags)).
 		Str("location_tag", result.LocationTag).
+		Int("alt_text_count", len(result.AltText)).
 		Msg("Description response parsed successfully")
 	return &result, nil
 }
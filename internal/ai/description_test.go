@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDescriptionResultAltTextFor(t *testing.T) {
+	r := &DescriptionResult{AltText: []string{" A tram climbs a hill. ", "Two coffees on a table."}}
+	got := r.AltTextFor(2)
+	if strings.Join(got, "|") != "A tram climbs a hill.|Two coffees on a table." {
+		t.Errorf("AltTextFor(2) = %q", got)
+	}
+	if got := r.AltTextFor(3); got != nil {
+		t.Errorf("AltTextFor(3) = %q, want nil for a count mismatch", got)
+	}
+	if got := (&DescriptionResult{}).AltTextFor(0); len(got) != 0 {
+		t.Errorf("AltTextFor(0) = %q", got)
+	}
+}
+
+func TestWriteCaptionStyle(t *testing.T) {
+	var sb strings.Builder
+	writeCaptionTemplate(&sb, &CaptionTemplate{Style: &CaptionStyle{EmojiDensity: "none", Language: "Japanese", Formality: "formal"}})
+	got := sb.String()
+	for _, want := range []string{"### Caption Voice", "in Japanese", "no emojis", "formal"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("caption voice missing %q:\n%s", want, got)
+		}
+	}
+
+	sb.Reset()
+	writeCaptionTemplate(&sb, &CaptionTemplate{Style: &CaptionStyle{}})
+	if sb.Len() != 0 {
+		t.Errorf("empty style wrote %q", sb.String())
+	}
+}
+
+func TestWriteCaptionTemplateFormat(t *testing.T) {
+	var sb strings.Builder
+	writeCaptionTemplate(&sb, &CaptionTemplate{Skeleton: "Week 12 of Biscuit 🐶\n...\nSee you next Friday!", Hashtags: []string{"dogsofinstagram", "biscuitfriday"}})
+	got := sb.String()
+	for _, want := range []string{"### Recurring Caption Format", "See you next Friday!", "#dogsofinstagram, #biscuitfriday"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("caption format missing %q:\n%s", want, got)
+		}
+	}
+
+	sb.Reset()
+	writeCaptionTemplate(&sb, nil)
+	writeCaptionTemplate(&sb, &CaptionTemplate{})
+	if sb.Len() != 0 {
+		t.Errorf("empty template wrote %q", sb.String())
+	}
+}
+
+func TestBuildDescriptionPromptTemplate(t *testing.T) {
+	items := []DescriptionMediaItem{{Type: "Photo", ThumbnailData: []byte("thumb")}}
+
+	plain := BuildDescriptionPrompt("Friday walk", "", items, "", nil)
+	if strings.Contains(plain, "Recurring Caption Format") || strings.Contains(plain, "6. ") {
+		t.Errorf("prompt without a template mentions one:\n%s", plain)
+	}
+
+	templated := BuildDescriptionPrompt("Friday walk", "", items, "", &CaptionTemplate{Hashtags: []string{"biscuitfriday"}})
+	for _, want := range []string{"#biscuitfriday", "5. Follow the caption format", "6. Respond with ONLY the JSON object"} {
+		if !strings.Contains(templated, want) {
+			t.Errorf("templated prompt missing %q:\n%s", want, templated)
+		}
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/fpang/ai-social-media-helper/internal/artifacts"
 	"github.com/fpang/ai-social-media-helper/internal/assets"
 	"github.com/fpang/ai-social-media-helper/internal/jsonutil"
 	"github.com/rs/zerolog/log"
@@ -27,9 +28,11 @@ const (
 	PhaseFeedback = "feedback" // User feedback loop
 	PhaseComplete = "complete" // Enhancement finished
 	PhaseError    = "error"    // Enhancement failed
+	PhaseSkipped  = "skipped"  // Left unchanged at the user's request (DDR-143)
 )
 
 // MaxImagenIterations is the maximum number of Imagen 3 iterations per photo.
+// Subject presets may allow fewer (DDR-180).
 const MaxImagenIterations = 3
 
 // ProfessionalScoreThreshold is the score above which no further edits are needed.
@@ -89,20 +92,7 @@ func RunPhaseOne(ctx context.Context, geminiClient *GeminiImageClient, imageData
 		Str("mime", imageMIME).
 		Msg("Phase 1: Starting Gemini 3 Pro Image global enhancement")
 
-	instruction := `Enhance this photo to professional quality for Instagram posting.
-
-Apply all necessary improvements:
-- Fix exposure, lighting, and white balance
-- Correct color balance and boost vibrancy naturally
-- Improve contrast and clarity
-- Reduce noise while preserving detail
-- Sharpen key subjects
-- For portraits: enhance skin naturally, brighten eyes
-- For landscapes: enhance sky and natural colors
-- For food: boost warmth and make colors appetizing
-
-Make it look like a professionally shot and edited photo.
-Describe what changes you made.`
+	instruction := phaseOneInstruction(subjectProfileFor(ctx)) // DDR-180
 
 	startTime := time.Now()
 	result, err := geminiClient.EditImage(ctx, imageData, imageMIME, instruction, assets.EnhancementSystemPrompt)
@@ -110,6 +100,7 @@ Describe what changes you made.`
 	if err != nil {
 		return nil, "", "", fmt.Errorf("phase 1 failed: %w", err)
 	}
+	artifacts.SaveText(ctx, "enhance-phase1-response.txt", result.Text)
 
 	log.Debug().
 		Int("response_bytes", len(result.ImageData)).
@@ -132,7 +123,7 @@ func RunPhaseTwo(ctx context.Context, geminiClient *GeminiImageClient, imageData
 		Int("image_bytes", len(imageData)).
 		Msg("Phase 2: Analyzing enhanced image for remaining improvements")
 
-	analysisPrompt := "Analyze this photo that has been enhanced once. Identify what further improvements would bring it to professional publication quality. Follow the response format in the system instruction exactly."
+	analysisPrompt := phaseTwoPrompt(subjectProfileFor(ctx)) // DDR-180
 
 	startTime := time.Now()
 	responseText, err := geminiClient.AnalyzeImage(ctx, imageData, imageMIME, analysisPrompt, assets.EnhancementAnalysisPrompt)
@@ -140,6 +131,7 @@ func RunPhaseTwo(ctx context.Context, geminiClient *GeminiImageClient, imageData
 	if err != nil {
 		return nil, fmt.Errorf("phase 2 analysis failed: %w", err)
 	}
+	artifacts.SaveText(ctx, "enhance-phase2-response.txt", responseText)
 
 	// Parse the JSON response
 	analysis, err := parseAnalysisResponse(responseText)
@@ -220,11 +212,12 @@ func RunPhaseThree(ctx context.Context, imagenClient *ImagenClient, imageData []
 
 	currentImage := imageData
 	editsApplied := 0
+	budget := subjectProfileFor(ctx).imagenBudget // DDR-180
 
 	for i, edit := range imagenEdits {
-		if i >= MaxImagenIterations {
+		if i >= budget {
 			log.Warn().
-				Int("max", MaxImagenIterations).
+				Int("max", budget).
 				Int("remaining", len(imagenEdits)-i).
 				Msg("Phase 3: Max Imagen iterations reached, stopping")
 			break
@@ -244,6 +237,8 @@ func RunPhaseThree(ctx context.Context, imagenClient *ImagenClient, imageData []
 			log.Warn().Err(err).Str("region", edit.Region).Msg("Failed to generate mask, skipping edit")
 			continue
 		}
+		artifacts.Save(ctx, fmt.Sprintf("masks/edit-%d.jpg", i+1), maskData)
+		artifacts.SaveText(ctx, fmt.Sprintf("masks/edit-%d.txt", i+1), fmt.Sprintf("type: %s\nregion: %s\n\n%s", edit.Type, edit.Region, edit.EditInstruction))
 
 		// Determine edit mode
 		editMode := "inpainting-remove"
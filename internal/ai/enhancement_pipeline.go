@@ -13,11 +13,13 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-// RunFullEnhancement executes the complete three-phase enhancement pipeline for one photo.
+// RunFullEnhancement executes the enhancement pipeline for one photo, running
+// the phases preset selects (DDR-143).
 // Returns the final enhanced image data, MIME type, and the enhancement state.
-func RunFullEnhancement(ctx context.Context, geminiClient *GeminiImageClient, imagenClient *ImagenClient, imageData []byte, imageMIME string, imageWidth, imageHeight int) (*EnhancementState, error) {
+func RunFullEnhancement(ctx context.Context, geminiClient *GeminiImageClient, imagenClient *ImagenClient, imageData []byte, imageMIME string, imageWidth, imageHeight int, preset EnhancementPreset) (*EnhancementState, error) {
 	pipelineStart := time.Now()
 	log.Info().
+		Str("preset", string(preset)).
 		Int("image_bytes", len(imageData)).
 		Str("mime", imageMIME).
 		Int("width", imageWidth).
@@ -44,6 +46,12 @@ func RunFullEnhancement(ctx context.Context, geminiClient *GeminiImageClient, im
 		Dur("phase_duration", time.Since(phase1Start)).
 		Msg("Phase 1 completed")
 
+	if preset == PresetLight {
+		log.Info().Msg("Light preset: skipping analysis and further edits")
+		state.Phase = PhaseComplete
+		return state, nil
+	}
+
 	// Phase 2: Analysis
 	state.Phase = PhaseTwo
 	phase2Start := time.Now()
@@ -93,6 +101,12 @@ func RunFullEnhancement(ctx context.Context, geminiClient *GeminiImageClient, im
 		}
 	}
 
+	if preset == PresetGlobal {
+		log.Info().Msg("Global preset: skipping Imagen surgical edits")
+		state.Phase = PhaseComplete
+		return state, nil
+	}
+
 	// Phase 3: Imagen 3 surgical edits
 	state.Phase = PhaseThree
 	phase3Start := time.Now()
@@ -0,0 +1,33 @@
+package ai
+
+// enhancement_preset.go defines how much of the enhancement pipeline runs on a
+// photo. See DDR-143: Per-Photo Enhancement Options.
+
+import "fmt"
+
+// EnhancementPreset selects the pipeline phases run on one photo.
+type EnhancementPreset string
+
+const (
+	// PresetFull runs all three phases. It is the default.
+	PresetFull EnhancementPreset = "full"
+	// PresetGlobal runs the global Gemini passes without Imagen region edits,
+	// for photos whose details should not be retouched.
+	PresetGlobal EnhancementPreset = "global"
+	// PresetLight runs Phase 1 only: one global enhancement pass.
+	PresetLight EnhancementPreset = "light"
+	// PresetPrivacy runs all three phases, then blurs bystanders' faces and
+	// licence plates (DDR-185).
+	PresetPrivacy EnhancementPreset = "privacy"
+)
+
+// ParseEnhancementPreset resolves a requested preset; "" means PresetFull.
+func ParseEnhancementPreset(s string) (EnhancementPreset, error) {
+	switch p := EnhancementPreset(s); p {
+	case "":
+		return PresetFull, nil
+	case PresetFull, PresetGlobal, PresetLight, PresetPrivacy:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown enhancement preset %q", s)
+}
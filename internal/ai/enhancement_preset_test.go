@@ -0,0 +1,21 @@
+package ai
+
+import "testing"
+
+func TestParseEnhancementPreset(t *testing.T) {
+	for in, want := range map[string]EnhancementPreset{
+		"":        PresetFull,
+		"full":    PresetFull,
+		"global":  PresetGlobal,
+		"light":   PresetLight,
+		"privacy": PresetPrivacy,
+	} {
+		got, err := ParseEnhancementPreset(in)
+		if err != nil || got != want {
+			t.Errorf("ParseEnhancementPreset(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseEnhancementPreset("Light"); err == nil {
+		t.Error("ParseEnhancementPreset accepted an unknown preset")
+	}
+}
@@ -0,0 +1,160 @@
+package ai
+
+// enhancement_subject.go tailors the enhancement pipeline to the kind of
+// photo a job holds. See DDR-180: Subject Enhancement Presets.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SubjectPreset names the kind of photos an enhancement job holds. It sets
+// the Phase 1 instruction, what Phase 2 analysis looks for and how many
+// Imagen edits Phase 3 may make. It is independent of the per-item
+// EnhancementPreset, which chooses the phases that run (DDR-143).
+type SubjectPreset string
+
+const (
+	// SubjectGeneral is the default: one instruction covering every subject.
+	SubjectGeneral   SubjectPreset = ""
+	SubjectPortrait  SubjectPreset = "portrait"
+	SubjectLandscape SubjectPreset = "landscape"
+	SubjectFood      SubjectPreset = "food"
+	SubjectNight     SubjectPreset = "night"
+)
+
+// subjectProfile is the pipeline tuning of one SubjectPreset.
+type subjectProfile struct {
+	// phaseOne lists the Phase 1 improvements, one per line.
+	phaseOne []string
+	// analysisTargets are what Phase 2 checks before general quality.
+	analysisTargets []string
+	// imagenBudget caps the Imagen edits Phase 3 applies.
+	imagenBudget int
+}
+
+var subjectProfiles = map[SubjectPreset]subjectProfile{
+	SubjectGeneral: {
+		phaseOne: []string{
+			"Fix exposure, lighting, and white balance",
+			"Correct color balance and boost vibrancy naturally",
+			"Improve contrast and clarity",
+			"Reduce noise while preserving detail",
+			"Sharpen key subjects",
+			"For portraits: enhance skin naturally, brighten eyes",
+			"For landscapes: enhance sky and natural colors",
+			"For food: boost warmth and make colors appetizing",
+		},
+		imagenBudget: MaxImagenIterations,
+	},
+	// Faces are easily distorted by mask edits, so Imagen gets one.
+	SubjectPortrait: {
+		phaseOne: []string{
+			"Expose for the face; lift shadows on the face without flattening it",
+			"Keep skin tones natural and even; soften blemishes, never smooth away texture",
+			"Brighten eyes and sharpen them and the hair slightly",
+			"Keep the background calmer than the subject; do not change its shape",
+			"Do not change facial features, body shape or expression",
+		},
+		analysisTargets: []string{
+			"skin tone accuracy and color casts on the face",
+			"sharpness of the eyes",
+			"distracting objects or bright spots behind the subject",
+		},
+		imagenBudget: 1,
+	},
+	SubjectLandscape: {
+		phaseOne: []string{
+			"Balance the sky and the land; recover highlights in the sky",
+			"Deepen blues and greens naturally without neon saturation",
+			"Add clarity and depth to foreground texture and distant detail",
+			"Straighten a tilted horizon",
+			"Reduce haze where it hides detail",
+		},
+		analysisTargets: []string{
+			"horizon level and composition",
+			"blown-out sky or crushed shadows",
+			"litter, power lines or people distracting from the scene",
+		},
+		imagenBudget: MaxImagenIterations,
+	},
+	SubjectFood: {
+		phaseOne: []string{
+			"Warm the white balance so the food looks fresh and appetizing",
+			"Make colors rich but true to the dish; do not change what it is",
+			"Add gentle contrast and texture to the food",
+			"Brighten the plate and table without losing highlights on sauces",
+		},
+		analysisTargets: []string{
+			"color cast from restaurant lighting",
+			"crumbs, smudges or spills on the plate or table",
+			"clutter at the edges of the frame",
+		},
+		imagenBudget: 2,
+	},
+	// Mask edits on dark, noisy areas leave visible seams, so Imagen gets one.
+	SubjectNight: {
+		phaseOne: []string{
+			"Reduce noise in the shadows while keeping detail in lit areas",
+			"Keep the night dark; lift shadows only where the subject is",
+			"Control glare and recover clipped highlights around light sources",
+			"Keep the colors of artificial light natural, neither orange nor green",
+			"Sharpen lit subjects slightly",
+		},
+		analysisTargets: []string{
+			"noise and banding in dark areas",
+			"halos and clipped highlights around lights",
+			"white balance of mixed light sources",
+		},
+		imagenBudget: 1,
+	},
+}
+
+// ParseSubjectPreset resolves a requested subject preset; "" means
+// SubjectGeneral.
+func ParseSubjectPreset(s string) (SubjectPreset, error) {
+	p := SubjectPreset(s)
+	if _, ok := subjectProfiles[p]; !ok {
+		return "", fmt.Errorf("unknown enhancement preset %q (use portrait, landscape, food or night)", s)
+	}
+	return p, nil
+}
+
+type subjectPresetKey struct{}
+
+// WithSubjectPreset returns a context whose enhancement pipeline runs are
+// tuned for p.
+func WithSubjectPreset(ctx context.Context, p SubjectPreset) context.Context {
+	return context.WithValue(ctx, subjectPresetKey{}, p)
+}
+
+func subjectProfileFor(ctx context.Context) subjectProfile {
+	p, _ := ctx.Value(subjectPresetKey{}).(SubjectPreset)
+	if profile, ok := subjectProfiles[p]; ok {
+		return profile
+	}
+	return subjectProfiles[SubjectGeneral]
+}
+
+// phaseOneInstruction is the Phase 1 edit instruction for a profile.
+func phaseOneInstruction(profile subjectProfile) string {
+	var sb strings.Builder
+	sb.WriteString("Enhance this photo to professional quality for Instagram posting.\n\n")
+	sb.WriteString("Apply all necessary improvements:\n")
+	for _, line := range profile.phaseOne {
+		sb.WriteString("- " + line + "\n")
+	}
+	sb.WriteString("\nMake it look like a professionally shot and edited photo.\n")
+	sb.WriteString("Describe what changes you made.")
+	return sb.String()
+}
+
+// phaseTwoPrompt is the Phase 2 analysis prompt for a profile.
+func phaseTwoPrompt(profile subjectProfile) string {
+	prompt := "Analyze this photo that has been enhanced once. Identify what further improvements would bring it to professional publication quality."
+	if len(profile.analysisTargets) > 0 {
+		prompt += " Check these first: " + strings.Join(profile.analysisTargets, "; ") + "."
+	}
+	return prompt + " Follow the response format in the system instruction exactly."
+}
@@ -0,0 +1,41 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseSubjectPreset(t *testing.T) {
+	for _, in := range []string{"", "portrait", "landscape", "food", "night"} {
+		if got, err := ParseSubjectPreset(in); err != nil || string(got) != in {
+			t.Errorf("ParseSubjectPreset(%q) = %q, %v", in, got, err)
+		}
+	}
+	for _, in := range []string{"Portrait", "light", "macro"} {
+		if _, err := ParseSubjectPreset(in); err == nil {
+			t.Errorf("ParseSubjectPreset accepted %q", in)
+		}
+	}
+}
+
+func TestSubjectProfileFor(t *testing.T) {
+	general := subjectProfileFor(context.Background())
+	if general.imagenBudget != MaxImagenIterations || len(general.analysisTargets) != 0 {
+		t.Errorf("default profile = %+v", general)
+	}
+	if got := phaseTwoPrompt(general); strings.Contains(got, "Check these first") {
+		t.Errorf("default analysis prompt has targets: %s", got)
+	}
+
+	night := subjectProfileFor(WithSubjectPreset(context.Background(), SubjectNight))
+	if night.imagenBudget != 1 {
+		t.Errorf("night Imagen budget = %d", night.imagenBudget)
+	}
+	if got := phaseOneInstruction(night); !strings.Contains(got, "- Reduce noise in the shadows") || strings.Contains(got, "For food") {
+		t.Errorf("night Phase 1 instruction:\n%s", got)
+	}
+	if got := phaseTwoPrompt(night); !strings.Contains(got, "Check these first: noise and banding in dark areas;") {
+		t.Errorf("night analysis prompt: %s", got)
+	}
+}
@@ -92,6 +92,7 @@ func (c *GeminiImageClient) EditImageMultiTurn(ctx context.Context, imageData []
 	config := &genai.GenerateContentConfig{
 		ResponseModalities: []string{"TEXT", "IMAGE"},
 	}
+	applySampling(ctx, config) // DDR-170
 
 	// Add system instruction if provided
 	if systemInstruction != "" {
@@ -174,12 +175,14 @@ func (c *GeminiImageClient) EditImageMultiTurn(ctx context.Context, imageData []
 	return result, nil
 }
 
-// AnalyzeImage sends an image to Gemini 3.1 Pro for text-only analysis.
+// AnalyzeImage sends an image to Gemini 3.1 Pro, or the job's model
+// (DDR-170), for text-only analysis.
 // Used in Phase 2 to determine what further enhancements are needed.
 func (c *GeminiImageClient) AnalyzeImage(ctx context.Context, imageData []byte, imageMIMEType string, analysisPrompt string, systemInstruction string) (string, error) {
 	startTime := time.Now()
+	model := modelFor(ctx, ModelGemini31ProPreview)
 	log.Debug().
-		Str("model", ModelGemini31ProPreview).
+		Str("model", model).
 		Int("image_bytes", len(imageData)).
 		Int("prompt_length", len(analysisPrompt)).
 		Msg("AnalyzeImage: Starting Gemini API call")
@@ -187,7 +190,9 @@ func (c *GeminiImageClient) AnalyzeImage(ctx context.Context, imageData []byte,
 	// Build generation config — text only for analysis
 	config := &genai.GenerateContentConfig{
 		ResponseModalities: []string{"TEXT"},
+		ThinkingConfig:     thinkingConfig(ctx), // DDR-170
 	}
+	applySampling(ctx, config)
 
 	if systemInstruction != "" {
 		config.SystemInstruction = &genai.Content{
@@ -209,8 +214,8 @@ func (c *GeminiImageClient) AnalyzeImage(ctx context.Context, imageData []byte,
 		},
 	}
 
-	// Use Pro (text) model for analysis, not the image model
-	resp, err := c.client.Models.GenerateContent(ctx, ModelGemini31ProPreview, contents, config)
+	// Use a text model for analysis, not the image model
+	resp, err := c.client.Models.GenerateContent(ctx, model, contents, config)
 	if err != nil {
 		return "", fmt.Errorf("Gemini image analysis failed: %w", err)
 	}
@@ -18,28 +18,39 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/fpang/ai-social-media-helper/internal/logging"
 	"github.com/rs/zerolog/log"
+	"golang.org/x/oauth2"
 )
 
+// imagenEditModel is the Imagen model used for mask-based edits.
+const imagenEditModel = "imagen-3.0-capability-001"
+
 // ImagenClient calls the Imagen 3 model via Vertex AI REST API for mask-based editing.
 type ImagenClient struct {
-	projectID   string
-	region      string
-	accessToken string // GCP OAuth2 access token
-	httpClient  *http.Client
+	projectID  string
+	region     string
+	tokens     oauth2.TokenSource // GCP OAuth2 access tokens
+	httpClient *http.Client       // adds a token to each request
 }
 
-// NewImagenClient creates a new client for Imagen 3 editing.
-// accessToken is a GCP OAuth2 access token (not the Gemini API key).
-func NewImagenClient(projectID, region, accessToken string) *ImagenClient {
-	return &ImagenClient{
-		projectID:   projectID,
-		region:      region,
-		accessToken: accessToken,
-		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
+// NewImagenClient creates a new client for Imagen 3 editing. tokens supplies
+// GCP OAuth2 access tokens (not the Gemini API key); see VertexTokenSource
+// (DDR-171).
+func NewImagenClient(projectID, region string, tokens oauth2.TokenSource) *ImagenClient {
+	c := &ImagenClient{
+		projectID: projectID,
+		region:    region,
+		tokens:    tokens,
+	}
+	c.httpClient = &http.Client{
+		Timeout: 60 * time.Second,
+		Transport: &oauth2.Transport{
+			Source: oauth2.ReuseTokenSource(nil, tokens),
+			Base:   logging.OutboundTransport("imagen", nil),
 		},
 	}
+	return c
 }
 
 // --- Vertex AI Imagen 3 request/response types ---
@@ -68,6 +79,20 @@ type imagenMask struct {
 type imagenParameters struct {
 	SampleCount int    `json:"sampleCount"`
 	EditMode    string `json:"editMode,omitempty"` // "inpainting-insert", "inpainting-remove", "outpainting"
+
+	// DDR-184: super-resolution.
+	Mode          string               `json:"mode,omitempty"` // "upscale"
+	UpscaleConfig *imagenUpscaleConfig `json:"upscaleConfig,omitempty"`
+	OutputOptions *imagenOutputOptions `json:"outputOptions,omitempty"`
+}
+
+type imagenUpscaleConfig struct {
+	UpscaleFactor string `json:"upscaleFactor"` // "x2", "x4"
+}
+
+type imagenOutputOptions struct {
+	MimeType           string `json:"mimeType,omitempty"`
+	CompressionQuality int    `json:"compressionQuality,omitempty"`
 }
 
 type imagenResponse struct {
@@ -130,14 +155,28 @@ func (c *ImagenClient) EditWithMask(ctx context.Context, imageData []byte, maskD
 		},
 	}
 
+	result, err := c.predict(ctx, imagenEditModel, req)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug().
+		Int("output_bytes", len(result.ImageData)).
+		Dur("duration", time.Since(startTime)).
+		Msg("EditWithMask: Imagen API call completed successfully")
+	return result, nil
+}
+
+// predict sends one request to an Imagen model's :predict endpoint and
+// returns its first image.
+func (c *ImagenClient) predict(ctx context.Context, model string, req imagenRequest) (*ImagenEditResult, error) {
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	url := fmt.Sprintf(
-		"https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/google/models/imagen-3.0-capability-001:predict",
-		c.region, c.projectID, c.region,
+		"https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/google/models/%s:predict",
+		c.region, c.projectID, c.region, model,
 	)
 
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
@@ -145,8 +184,8 @@ func (c *ImagenClient) EditWithMask(ctx context.Context, imageData []byte, maskD
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+c.accessToken)
 
+	startTime := time.Now()
 	resp, err := c.httpClient.Do(httpReq)
 	httpDuration := time.Since(startTime)
 	if err != nil {
@@ -162,13 +201,13 @@ func (c *ImagenClient) EditWithMask(ctx context.Context, imageData []byte, maskD
 	log.Debug().
 		Int("status_code", resp.StatusCode).
 		Dur("duration", httpDuration).
-		Msg("EditWithMask: HTTP call completed")
+		Msg("Imagen predict: HTTP call completed")
 
 	if resp.StatusCode != http.StatusOK {
 		log.Error().
 			Int("status", resp.StatusCode).
 			Str("body", truncateString(string(respBody), 500)).
-			Msg("Imagen 3 API returned error")
+			Msg("Imagen API returned error")
 		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, truncateString(string(respBody), 200))
 	}
 
@@ -182,7 +221,7 @@ func (c *ImagenClient) EditWithMask(ctx context.Context, imageData []byte, maskD
 	}
 
 	if len(imagenResp.Predictions) == 0 {
-		return nil, fmt.Errorf("no predictions returned from Imagen 3")
+		return nil, fmt.Errorf("no predictions returned from Imagen")
 	}
 
 	decoded, err := base64.StdEncoding.DecodeString(imagenResp.Predictions[0].BytesBase64Encoded)
@@ -190,12 +229,6 @@ func (c *ImagenClient) EditWithMask(ctx context.Context, imageData []byte, maskD
 		return nil, fmt.Errorf("failed to decode response image: %w", err)
 	}
 
-	totalDuration := time.Since(startTime)
-	log.Debug().
-		Int("output_bytes", len(decoded)).
-		Dur("duration", totalDuration).
-		Msg("EditWithMask: Imagen API call completed successfully")
-
 	return &ImagenEditResult{
 		ImageData: decoded,
 		MIMEType:  imagenResp.Predictions[0].MimeType,
@@ -204,7 +237,7 @@ func (c *ImagenClient) EditWithMask(ctx context.Context, imageData []byte, maskD
 
 // IsConfigured returns true if the Imagen client has the required Vertex AI configuration.
 func (c *ImagenClient) IsConfigured() bool {
-	return c.projectID != "" && c.region != "" && c.accessToken != ""
+	return c.projectID != "" && c.region != "" && c.tokens != nil
 }
 
 // --- Mask Generation Helpers ---
@@ -0,0 +1,177 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/artifacts"
+	"github.com/fpang/ai-social-media-helper/internal/jsonutil"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genai"
+)
+
+// --- Structured output validation and repair (DDR-165) ---
+//
+// Gemini occasionally returns JSON that does not parse, usually cut off or
+// with a stray trailing comma. Instead of failing the whole job, such a
+// response is sent back to the model once, with the problem, asking for
+// the corrected JSON. A response that parses is never sent back: items with
+// a media number out of range or seen before are dropped and logged, and
+// small gaps are filled with defaults, as before validation existed.
+
+// responseSchema describes the JSON a prompt asks for.
+type responseSchema[T any] struct {
+	// Operation names the call in logs, metrics and artifacts.
+	Operation string
+	// Shape is a compact description of the JSON, quoted in the repair
+	// prompt.
+	Shape string
+	// Normalize drops unusable items from a parsed response and fills
+	// defaults for optional fields it left out.
+	Normalize func(*T)
+}
+
+// jsonRepairer asks the model to correct an unparseable response and
+// returns its answer.
+type jsonRepairer func(ctx context.Context, prompt string) (string, error)
+
+// geminiJSONRepairer sends repair prompts to modelName as text-only
+// requests in JSON mode. Returns nil, disabling repair, without a client.
+// Like the other Gemini calls it records latency and token metrics and the
+// job's telemetry (DDR-118), and answers repeated repairs of the same
+// response from the context's response cache (DDR-166).
+func geminiJSONRepairer(client *genai.Client, modelName string) jsonRepairer {
+	if client == nil {
+		return nil
+	}
+	return func(ctx context.Context, prompt string) (string, error) {
+		cache := responseCacheFrom(ctx)
+		cacheKey := responseCacheKeyOf("json-repair", responseCacheVersion, modelName, prompt)
+		if cache != nil {
+			var cached string
+			if getCachedResponse(ctx, cache, cacheKey, &cached) {
+				recordResponseCache("jsonRepair", 1, 0)
+				return cached, nil
+			}
+			recordResponseCache("jsonRepair", 0, 1)
+		}
+
+		contents := []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: prompt}}}}
+		callStart := time.Now()
+		resp, err := client.Models.GenerateContent(ctx, modelName, contents, &genai.GenerateContentConfig{
+			ResponseMIMEType: "application/json",
+			MaxOutputTokens:  65536,
+		})
+		duration := time.Since(callStart)
+		metrics.RecordGeminiCall(ctx, duration)
+
+		m := metrics.New("AiSocialMedia").
+			Dimension("Operation", "jsonRepair").
+			Metric("GeminiApiLatencyMs", float64(duration.Milliseconds()), metrics.UnitMilliseconds).
+			Count("GeminiApiCalls")
+		if err != nil {
+			m.Count("GeminiApiErrors")
+		}
+		if resp != nil && resp.UsageMetadata != nil {
+			m.Metric("GeminiInputTokens", float64(resp.UsageMetadata.PromptTokenCount), metrics.UnitCount)
+			metrics.RecordGeminiTokens(ctx, int64(resp.UsageMetadata.PromptTokenCount)) // DDR-186
+			m.Metric("GeminiOutputTokens", float64(resp.UsageMetadata.CandidatesTokenCount), metrics.UnitCount)
+		}
+		m.Flush()
+
+		if err != nil {
+			return "", fmt.Errorf("repair request: %w", err)
+		}
+		text := resp.Text()
+		if cache != nil {
+			putCachedResponse(ctx, cache, cacheKey, text)
+		}
+		return text, nil
+	}
+}
+
+// parseValidated parses response as T and normalizes it with schema. A
+// response that does not parse is repaired once through repair, if not
+// nil. The error returned when repair fails is the one about the original
+// response.
+func parseValidated[T any](ctx context.Context, response string, schema responseSchema[T], repair jsonRepairer) (T, error) {
+	m := metrics.New("AiSocialMedia").
+		Dimension("Operation", schema.Operation).
+		Count("GeminiJsonResponses")
+	defer m.Flush()
+
+	result, err := decodeValidated(response, schema)
+	if err == nil {
+		return result, nil
+	}
+	m.Count("GeminiJsonInvalid")
+	if repair == nil {
+		return result, err
+	}
+
+	log.Warn().Err(err).Str("operation", schema.Operation).Msg("Gemini returned unparseable JSON, asking it to repair the response")
+	m.Count("GeminiJsonRepairAttempts")
+	repaired, repairErr := repair(ctx, buildJSONRepairPrompt(response, schema.Shape, err))
+	if repairErr == nil {
+		artifacts.SaveText(ctx, schema.Operation+"-response-repaired.txt", repaired)
+		result, repairErr = decodeValidated(repaired, schema)
+	}
+	if repairErr != nil {
+		m.Count("GeminiJsonRepairFailures")
+		log.Error().Err(repairErr).Str("operation", schema.Operation).Msg("Gemini JSON repair failed")
+		var zero T
+		return zero, fmt.Errorf("%w (repair failed: %v)", err, repairErr)
+	}
+	m.Count("GeminiJsonRepaired")
+	log.Info().Str("operation", schema.Operation).Msg("Gemini JSON response repaired")
+	return result, nil
+}
+
+func decodeValidated[T any](response string, schema responseSchema[T]) (T, error) {
+	result, err := jsonutil.ParseJSON[T](response)
+	if err != nil {
+		return result, err
+	}
+	if schema.Normalize != nil {
+		schema.Normalize(&result)
+	}
+	return result, nil
+}
+
+// buildJSONRepairPrompt asks for the corrected JSON only. The model cannot
+// see the media again, so it is told to fix the form and never invent
+// items or verdicts.
+func buildJSONRepairPrompt(response, shape string, problem error) string {
+	var sb strings.Builder
+	sb.WriteString("Your previous response could not be used.\n\n")
+	sb.WriteString("Problem: ")
+	sb.WriteString(problem.Error())
+	sb.WriteString("\n\nThe response must be JSON of this shape:\n")
+	sb.WriteString(shape)
+	sb.WriteString("\n\nReturn only the corrected JSON, with no markdown fences or other text. ")
+	sb.WriteString("Keep every item and value from the previous response that fits the shape. ")
+	sb.WriteString("Do not invent items or change verdicts. ")
+	sb.WriteString("If the response was cut off, end it after the last complete item.\n\n")
+	sb.WriteString("Previous response:\n")
+	sb.WriteString(response)
+	return sb.String()
+}
+
+// keepMedia reports whether an item of the operation's response, described
+// by what, has a media number in 1..count not seen before, recording it as
+// seen. Items that do not are logged and should be dropped.
+func keepMedia(operation, what string, media, count int, seen map[int]bool) bool {
+	switch {
+	case media < 1 || media > count:
+		log.Warn().Str("operation", operation).Str("item", what).Int("media", media).Int("count", count).Msg("Dropping response item with a media number out of range")
+		return false
+	case seen[media]:
+		log.Warn().Str("operation", operation).Str("item", what).Int("media", media).Msg("Dropping response item for a media number already answered")
+		return false
+	}
+	seen[media] = true
+	return true
+}
@@ -0,0 +1,154 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestParseTriageResponseDefaults(t *testing.T) {
+	results, err := parseTriageResponse(context.Background(), "```json\n"+`[{"media": 1, "saveable": true}, {"media": 2, "saveable": false, "reason": "blurry"}]`+"\n```", 2, nil)
+	if err != nil {
+		t.Fatalf("parseTriageResponse: %v", err)
+	}
+	if results[0].Reason != "No reason given" || results[1].Reason != "blurry" {
+		t.Errorf("reasons = %q, %q", results[0].Reason, results[1].Reason)
+	}
+}
+
+func TestParseTriageResponseInvalidWithoutRepair(t *testing.T) {
+	response := `[{"media": 1, "saveable": true, "reason": "ok"}, {"media": 2, "sav`
+	if _, err := parseTriageResponse(context.Background(), response, 2, nil); err == nil {
+		t.Error("truncated response parsed without error")
+	}
+}
+
+// TestParseTriageResponseDropsInvalidItems checks that a response that
+// parses is used without repair, dropping results out of range or for a
+// media number already answered.
+func TestParseTriageResponseDropsInvalidItems(t *testing.T) {
+	response := `[{"media": 1, "saveable": true, "reason": "ok"}, {"media": 3, "saveable": true, "reason": "no such item"},
+		{"media": 1, "saveable": false, "reason": "dark"}, {"media": 2, "saveable": false, "reason": "blurry"}]`
+	repair := func(ctx context.Context, p string) (string, error) {
+		t.Error("repair called for a response that parses")
+		return "", errors.New("unexpected")
+	}
+	results, err := parseTriageResponse(context.Background(), response, 2, repair)
+	if err != nil {
+		t.Fatalf("parseTriageResponse: %v", err)
+	}
+	if len(results) != 2 || results[0].Media != 1 || results[0].Reason != "ok" || results[1].Media != 2 {
+		t.Errorf("results = %+v", results)
+	}
+}
+
+func TestParseTriageResponseRepair(t *testing.T) {
+	broken := `[{"media": 1, "saveable": true, "reason": "sharp"},]`
+	var prompt string
+	repair := func(ctx context.Context, p string) (string, error) {
+		prompt = p
+		return `[{"media": 1, "saveable": true, "reason": "sharp"}]`, nil
+	}
+
+	results, err := parseTriageResponse(context.Background(), broken, 1, repair)
+	if err != nil {
+		t.Fatalf("parseTriageResponse: %v", err)
+	}
+	if len(results) != 1 || !results[0].Saveable {
+		t.Errorf("results = %+v", results)
+	}
+	for _, want := range []string{"invalid JSON", triageResponseShape, broken} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("repair prompt lacks %q", want)
+		}
+	}
+}
+
+func TestParseTriageResponseRepairFails(t *testing.T) {
+	repair := func(ctx context.Context, p string) (string, error) {
+		return "", errors.New("quota exceeded")
+	}
+	_, err := parseTriageResponse(context.Background(), `[{"media": 1, "saveable": tr`, 1, repair)
+	if err == nil || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestParseSelectionResponseDefaults(t *testing.T) {
+	response := `{
+		"selected": [{"media": 2, "filename": "b.jpg"}, {"rank": 5, "media": 1, "filename": "a.jpg"}],
+		"excluded": [{"media": 3, "filename": "c.jpg"}],
+		"sceneGroups": [{"name": "Beach", "items": [{"media": 1}, {"media": 9}]}]
+	}`
+	result, err := parseSelectionResponse(context.Background(), response, 3, nil)
+	if err != nil {
+		t.Fatalf("parseSelectionResponse: %v", err)
+	}
+	if result.Selected[0].Rank != 1 || result.Selected[1].Rank != 5 {
+		t.Errorf("ranks = %d, %d", result.Selected[0].Rank, result.Selected[1].Rank)
+	}
+	if result.Excluded[0].Reason != "No reason given" {
+		t.Errorf("excluded reason = %q", result.Excluded[0].Reason)
+	}
+	if items := result.SceneGroups[0].Items; len(items) != 1 || items[0].Media != 1 {
+		t.Errorf("scene items = %+v", items)
+	}
+}
+
+func TestParseSelectionResponseSelectedAndExcluded(t *testing.T) {
+	response := `{"selected": [{"rank": 1, "media": 1}, {"rank": 2, "media": 7}], "excluded": [{"media": 1, "reason": "dup"}, {"media": 2, "reason": "dark"}]}`
+	result, err := parseSelectionResponse(context.Background(), response, 2, nil)
+	if err != nil {
+		t.Fatalf("parseSelectionResponse: %v", err)
+	}
+	if len(result.Selected) != 1 || result.Selected[0].Media != 1 {
+		t.Errorf("selected = %+v", result.Selected)
+	}
+	if len(result.Excluded) != 1 || result.Excluded[0].Media != 2 {
+		t.Errorf("excluded = %+v", result.Excluded)
+	}
+}
+
+// TestGeminiJSONRepairerCached checks that repairing the same response
+// twice calls Gemini once when the context has a response cache.
+func TestGeminiJSONRepairerCached(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		io.WriteString(w, `{"candidates": [{"content": {"role": "model", "parts": [{"text": "[{\"media\": 1, \"saveable\": true, \"reason\": \"sharp\"}]"}]}}],
+			"usageMetadata": {"promptTokenCount": 50, "candidatesTokenCount": 12}}`)
+	}))
+	defer srv.Close()
+
+	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+		APIKey:      "test",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: srv.URL},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := &fakeResponseCache{entries: map[string][]byte{}}
+	ctx := WithResponseCache(context.Background(), cache)
+	repair := geminiJSONRepairer(client, "gemini-test")
+
+	broken := `[{"media": 1, "saveable": true, "reason": "sharp"},]`
+	for i := 0; i < 2; i++ {
+		results, err := parseTriageResponse(ctx, broken, 1, repair)
+		if err != nil {
+			t.Fatalf("attempt %d: %v", i+1, err)
+		}
+		if len(results) != 1 || !results[0].Saveable {
+			t.Errorf("attempt %d: results = %+v", i+1, results)
+		}
+	}
+	if calls != 1 || cache.puts != 1 {
+		t.Errorf("Gemini calls = %d, cache writes = %d; want 1 and 1", calls, cache.puts)
+	}
+}
@@ -0,0 +1,117 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genai"
+)
+
+// --- Per-job model configuration (DDR-170) ---
+
+// ModelConfig is a job's Gemini generation settings. The zero value uses
+// the deployment's defaults. It is embedded in API requests and pipeline
+// events, so its fields sit next to the job's other fields in JSON.
+type ModelConfig struct {
+	Model       string   `json:"model,omitempty"`
+	Thinking    string   `json:"thinking,omitempty"` // DDR-155
+	Temperature *float32 `json:"temperature,omitempty"`
+	TopP        *float32 `json:"topP,omitempty"`
+}
+
+// AllowedModels returns the models a job may ask for, resolved from:
+// 1. GEMINI_ALLOWED_MODELS environment variable (comma-separated)
+// 2. Default: the text models listed in model.go
+func AllowedModels() []string {
+	var models []string
+	for _, m := range strings.Split(os.Getenv("GEMINI_ALLOWED_MODELS"), ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			models = append(models, m)
+		}
+	}
+	if len(models) > 0 {
+		return models
+	}
+	return []string{
+		ModelGemini31ProPreview,
+		ModelGemini3FlashPreview,
+		ModelGemini25Pro,
+		ModelGemini25Flash,
+		ModelGemini25FlashLite,
+	}
+}
+
+// Normalize checks c against the model allowlist and the sampling ranges,
+// and returns it with Thinking in canonical form.
+func (c ModelConfig) Normalize() (ModelConfig, error) {
+	if err := c.validate(); err != nil {
+		return c, err
+	}
+	thinking, err := NormalizeThinking(c.Thinking)
+	if err != nil {
+		return c, err
+	}
+	c.Thinking = thinking
+	return c, nil
+}
+
+// validate checks everything but Thinking.
+func (c ModelConfig) validate() error {
+	if c.Model != "" && !slices.Contains(AllowedModels(), c.Model) {
+		return fmt.Errorf("model %q is not allowed (allowed: %s)", c.Model, strings.Join(AllowedModels(), ", "))
+	}
+	if t := c.Temperature; t != nil && (*t < 0 || *t > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2, got %g", *t)
+	}
+	if p := c.TopP; p != nil && (*p <= 0 || *p > 1) {
+		return fmt.Errorf("topP must be above 0 and at most 1, got %g", *p)
+	}
+	return nil
+}
+
+type modelConfigKey struct{}
+
+// ApplyModelConfig applies a job's settings to the calls made with the
+// returned context, taking the deployment's GEMINI_THINKING when the job
+// sets none (see ApplyThinking), and returns the settings used. The API
+// validates settings before a job starts; here an invalid model or
+// sampling setting is logged and dropped, so the job runs on the defaults.
+func ApplyModelConfig(ctx context.Context, c ModelConfig) (context.Context, ModelConfig) {
+	if err := c.validate(); err != nil {
+		log.Warn().Err(err).Msg("Ignoring invalid model settings, using the defaults")
+		c.Model, c.Temperature, c.TopP = "", nil, nil
+	}
+	ctx, c.Thinking = ApplyThinking(ctx, c.Thinking)
+	if c.Model != "" || c.Temperature != nil || c.TopP != nil {
+		ctx = context.WithValue(ctx, modelConfigKey{}, c)
+	}
+	return ctx, c
+}
+
+func modelConfigFrom(ctx context.Context) ModelConfig {
+	c, _ := ctx.Value(modelConfigKey{}).(ModelConfig)
+	return c
+}
+
+// modelFor returns the context's model, or def when the job set none.
+func modelFor(ctx context.Context, def string) string {
+	if m := modelConfigFrom(ctx).Model; m != "" {
+		return m
+	}
+	return def
+}
+
+// applySampling sets the context's temperature and topP on config.
+func applySampling(ctx context.Context, config *genai.GenerateContentConfig) {
+	c := modelConfigFrom(ctx)
+	if c.Temperature != nil {
+		config.Temperature = genai.Ptr(*c.Temperature)
+	}
+	if c.TopP != nil {
+		config.TopP = genai.Ptr(*c.TopP)
+	}
+}
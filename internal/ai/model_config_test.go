@@ -0,0 +1,58 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestModelConfigNormalize(t *testing.T) {
+	c, err := ModelConfig{Model: ModelGemini25Flash, Thinking: " LOW ", Temperature: genai.Ptr[float32](0.4)}.Normalize()
+	if err != nil || c.Thinking != "low" || *c.Temperature != 0.4 {
+		t.Errorf("Normalize = %+v, %v", c, err)
+	}
+	for name, bad := range map[string]ModelConfig{
+		"model":       {Model: "gemini-unknown"},
+		"thinking":    {Thinking: "loud"},
+		"temperature": {Temperature: genai.Ptr[float32](2.5)},
+		"topP zero":   {TopP: genai.Ptr[float32](0)},
+		"topP high":   {TopP: genai.Ptr[float32](1.1)},
+	} {
+		if _, err := bad.Normalize(); err == nil {
+			t.Errorf("%s: Normalize should fail", name)
+		}
+	}
+
+	t.Setenv("GEMINI_ALLOWED_MODELS", "tuned-model, "+ModelGemini25Pro)
+	if _, err := (ModelConfig{Model: "tuned-model"}).Normalize(); err != nil {
+		t.Errorf("allowlisted model rejected: %v", err)
+	}
+	if _, err := (ModelConfig{Model: ModelGemini25Flash}).Normalize(); err == nil {
+		t.Error("model outside GEMINI_ALLOWED_MODELS accepted")
+	}
+}
+
+func TestApplyModelConfig(t *testing.T) {
+	t.Setenv("GEMINI_THINKING", "")
+	ctx, c := ApplyModelConfig(context.Background(), ModelConfig{})
+	if c != (ModelConfig{}) || modelFor(ctx, "default") != "default" || generationCacheKey(ctx) != "" {
+		t.Errorf("defaults: config %+v, model %q, cache key %q", c, modelFor(ctx, "default"), generationCacheKey(ctx))
+	}
+
+	ctx, c = ApplyModelConfig(context.Background(), ModelConfig{Model: ModelGemini25Pro, Thinking: "high", TopP: genai.Ptr[float32](0.9)})
+	if modelFor(ctx, "default") != ModelGemini25Pro || c.Thinking != "high" || thinkingConfig(ctx) == nil {
+		t.Errorf("override: config %+v, model %q", c, modelFor(ctx, "default"))
+	}
+	config := &genai.GenerateContentConfig{Temperature: genai.Ptr[float32](0.1)}
+	applySampling(ctx, config)
+	if *config.Temperature != 0.1 || config.TopP == nil || *config.TopP != 0.9 {
+		t.Errorf("applySampling: temperature %v, topP %v", config.Temperature, config.TopP)
+	}
+
+	// Settings the API would have rejected run on the defaults.
+	ctx, c = ApplyModelConfig(context.Background(), ModelConfig{Model: "gemini-unknown", Temperature: genai.Ptr[float32](0.5), Thinking: "low"})
+	if c.Model != "" || c.Temperature != nil || c.Thinking != "low" || modelFor(ctx, "default") != "default" {
+		t.Errorf("invalid settings kept: %+v", c)
+	}
+}
@@ -0,0 +1,277 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Model fallback chain (DDR-169) ---
+//
+// A preview model under load answers 429 or 503 for minutes at a time, and
+// a job that fails on it has to be restarted by hand. The fallback
+// transport retries an overloaded generation request on the same model,
+// then moves it along a configured chain of other models, and records the
+// model that answered on the request's context so jobs can store it.
+
+// DefaultModelAttempts is how many times a generation request is sent to
+// one model before moving on to the next.
+const DefaultModelAttempts = 2
+
+// overloadBackoff is the first wait before retrying an overloaded model;
+// it doubles with each further attempt.
+const overloadBackoff = 2 * time.Second
+
+// errLatencyBudget reports an attempt cut off by the latency budget.
+var errLatencyBudget = errors.New("gemini latency budget exceeded")
+
+// FallbackModels returns the models a generation request moves to, in
+// order, when primary stays overloaded, resolved from:
+// 1. GEMINI_FALLBACK_MODELS environment variable (comma-separated)
+// 2. Default: none
+//
+// primary and duplicates are left out.
+func FallbackModels(primary string) []string {
+	var models []string
+	for _, m := range strings.Split(os.Getenv("GEMINI_FALLBACK_MODELS"), ",") {
+		m = strings.TrimSpace(m)
+		if m != "" && m != primary && !slices.Contains(models, m) {
+			models = append(models, m)
+		}
+	}
+	return models
+}
+
+// ModelAttempts returns how many times a generation request is sent to
+// each model in the chain, resolved from:
+// 1. GEMINI_MODEL_ATTEMPTS environment variable
+// 2. Default: 2
+func ModelAttempts() int {
+	return positiveIntEnv("GEMINI_MODEL_ATTEMPTS", DefaultModelAttempts)
+}
+
+// LatencyBudget returns how long a model may take to start answering
+// before the request moves to the next model, resolved from:
+// 1. GEMINI_LATENCY_BUDGET environment variable (e.g., "90s")
+// 2. Default: 0, no budget
+//
+// The last model in the chain is never cut off.
+func LatencyBudget() time.Duration {
+	env := os.Getenv("GEMINI_LATENCY_BUDGET")
+	if env == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(env)
+	if err != nil || d < 0 {
+		log.Warn().Str("value", env).Msg("Invalid GEMINI_LATENCY_BUDGET, using no budget")
+		return 0
+	}
+	return d
+}
+
+// ModelRecorder collects the models that answered the generation requests
+// made with a context, in the order they first answered.
+type ModelRecorder struct {
+	mu     sync.Mutex
+	models []string
+	parent *ModelRecorder // also records the models, when set
+}
+
+type modelRecorderKey struct{}
+
+// WithModelRecorder returns a context whose generation requests are
+// recorded in the returned recorder.
+func WithModelRecorder(ctx context.Context) (context.Context, *ModelRecorder) {
+	r := &ModelRecorder{}
+	return context.WithValue(ctx, modelRecorderKey{}, r), r
+}
+
+// withCallModelRecorder returns a context whose generation requests are
+// recorded both in the returned recorder and in the one ctx already
+// carries, for code that needs the model of one call.
+func withCallModelRecorder(ctx context.Context) (context.Context, *ModelRecorder) {
+	parent, _ := ctx.Value(modelRecorderKey{}).(*ModelRecorder)
+	r := &ModelRecorder{parent: parent}
+	return context.WithValue(ctx, modelRecorderKey{}, r), r
+}
+
+// Models returns the models recorded so far, or nil when there are none.
+func (r *ModelRecorder) Models() []string {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.models)
+}
+
+func recordModelUsed(ctx context.Context, model string) {
+	r, _ := ctx.Value(modelRecorderKey{}).(*ModelRecorder)
+	for ; r != nil; r = r.parent {
+		r.mu.Lock()
+		if !slices.Contains(r.models, model) {
+			r.models = append(r.models, model)
+		}
+		r.mu.Unlock()
+	}
+}
+
+// overloaded reports whether status means the model is busy, not that the
+// request is wrong.
+func overloaded(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable
+}
+
+// fallbackTransport sends generation requests along the model chain.
+// Other requests, such as file uploads, pass straight through.
+type fallbackTransport struct {
+	base    http.RoundTripper
+	backoff time.Duration // zero: overloadBackoff
+}
+
+func (t *fallbackTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	loc := modelPathPattern.FindStringSubmatchIndex(req.URL.Path)
+	if loc == nil || req.Body == nil || req.Body == http.NoBody {
+		return t.base.RoundTrip(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	primary := req.URL.Path[loc[2]:loc[3]]
+	chain := []string{primary}
+	// A context cache (DDR-065) belongs to the model that created it, so a
+	// request that uses one stays on its model.
+	if !bytes.Contains(body, []byte(`"cachedContent"`)) {
+		chain = append(chain, FallbackModels(primary)...)
+	}
+	attempts := ModelAttempts()
+	budget := LatencyBudget()
+	backoff := t.backoff
+	if backoff <= 0 {
+		backoff = overloadBackoff
+	}
+
+	for i, model := range chain {
+		last := i == len(chain)-1
+		limit := budget
+		if last {
+			limit = 0
+		}
+		wait := backoff
+		for attempt := 1; attempt <= attempts; attempt++ {
+			resp, err := t.send(req, loc, model, body, limit)
+			if errors.Is(err, errLatencyBudget) {
+				log.Warn().
+					Str("model", model).
+					Dur("budget", limit).
+					Msg("Gemini model exceeded its latency budget, falling back")
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if !overloaded(resp.StatusCode) || (last && attempt == attempts) {
+				if resp.StatusCode == http.StatusOK {
+					recordModelUsed(req.Context(), model)
+					if model != primary {
+						metrics.New("AiSocialMedia").
+							Dimension("Model", model).
+							Count("GeminiModelFallbacks").
+							Flush()
+					}
+				}
+				return resp, nil
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			log.Warn().
+				Str("model", model).
+				Int("status", resp.StatusCode).
+				Int("attempt", attempt).
+				Int("max_attempts", attempts).
+				Msg("Gemini model overloaded")
+			if attempt == attempts {
+				break
+			}
+			select {
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			case <-time.After(wait):
+			}
+			wait *= 2
+		}
+	}
+	// Unreachable: the last model has no latency budget and returns its
+	// final response whatever the status.
+	return nil, errors.New("no Gemini model answered")
+}
+
+// send makes one attempt on model. With a budget, the attempt is cancelled
+// if the response has not started within it; otherwise the cancellation
+// is tied to the response body.
+func (t *fallbackTransport) send(req *http.Request, loc []int, model string, body []byte, budget time.Duration) (*http.Response, error) {
+	ctx := req.Context()
+	var (
+		cancel   context.CancelFunc
+		timer    *time.Timer
+		timedOut atomic.Bool
+	)
+	if budget > 0 {
+		ctx, cancel = context.WithCancel(ctx)
+		timer = time.AfterFunc(budget, func() {
+			timedOut.Store(true)
+			cancel()
+		})
+	}
+
+	r := req.Clone(ctx)
+	r.URL.Path = req.URL.Path[:loc[2]] + model + req.URL.Path[loc[3]:]
+	r.URL.RawPath = ""
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	r.ContentLength = int64(len(body))
+
+	resp, err := t.base.RoundTrip(r)
+	if budget == 0 {
+		return resp, err
+	}
+	timer.Stop()
+	if timedOut.Load() {
+		if err == nil {
+			resp.Body.Close()
+		}
+		return nil, errLatencyBudget
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases an attempt's context when its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,128 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fallbackServer answers each model with the statuses in script, in turn,
+// and records the model and body of every request it receives.
+type fallbackServer struct {
+	mu     sync.Mutex
+	script map[string][]int
+	delay  map[string]time.Duration
+	calls  []string
+	bodies []string
+}
+
+func (s *fallbackServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m := modelPathPattern.FindStringSubmatch(r.URL.Path)
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	s.calls = append(s.calls, m[1])
+	s.bodies = append(s.bodies, string(body))
+	status := http.StatusOK
+	if q := s.script[m[1]]; len(q) > 0 {
+		status, s.script[m[1]] = q[0], q[1:]
+	}
+	delay := s.delay[m[1]]
+	s.mu.Unlock()
+
+	select {
+	case <-time.After(delay):
+	case <-r.Context().Done():
+		return
+	}
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"model": %q}`, m[1])
+}
+
+func postGenerate(t *testing.T, ctx context.Context, url, body string) (int, string) {
+	t.Helper()
+	client := &http.Client{Transport: &fallbackTransport{base: http.DefaultTransport, backoff: time.Millisecond}}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, url+"/v1beta/models/gemini-3.1-pro-preview:generateContent", strings.NewReader(body))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data)
+}
+
+func TestFallbackTransportMovesToNextModel(t *testing.T) {
+	t.Setenv("GEMINI_FALLBACK_MODELS", "gemini-3.1-pro-preview, gemini-3-flash-preview")
+	fake := &fallbackServer{script: map[string][]int{"gemini-3.1-pro-preview": {429, 503}}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	ctx, models := WithModelRecorder(context.Background())
+	status, body := postGenerate(t, ctx, srv.URL, `{"contents": []}`)
+	if status != http.StatusOK || !strings.Contains(body, "gemini-3-flash-preview") {
+		t.Errorf("got %d %s, want 200 from the fallback", status, body)
+	}
+	if got := strings.Join(fake.calls, ","); got != "gemini-3.1-pro-preview,gemini-3.1-pro-preview,gemini-3-flash-preview" {
+		t.Errorf("calls = %s", got)
+	}
+	for i, b := range fake.bodies {
+		if b != `{"contents": []}` {
+			t.Errorf("attempt %d sent body %q", i+1, b)
+		}
+	}
+	if got := models.Models(); len(got) != 1 || got[0] != "gemini-3-flash-preview" {
+		t.Errorf("models used = %v", got)
+	}
+}
+
+func TestFallbackTransportKeepsCachedContentOnItsModel(t *testing.T) {
+	t.Setenv("GEMINI_FALLBACK_MODELS", "gemini-3-flash-preview")
+	fake := &fallbackServer{script: map[string][]int{"gemini-3.1-pro-preview": {503, 503}}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	ctx, models := WithModelRecorder(context.Background())
+	status, _ := postGenerate(t, ctx, srv.URL, `{"cachedContent": "cachedContents/abc"}`)
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want the last 503", status)
+	}
+	if len(fake.calls) != DefaultModelAttempts {
+		t.Errorf("calls = %v, want %d attempts on the primary only", fake.calls, DefaultModelAttempts)
+	}
+	if got := models.Models(); got != nil {
+		t.Errorf("models used = %v, want none", got)
+	}
+}
+
+func TestFallbackTransportLatencyBudget(t *testing.T) {
+	t.Setenv("GEMINI_FALLBACK_MODELS", "gemini-3-flash-preview")
+	t.Setenv("GEMINI_LATENCY_BUDGET", "50ms")
+	fake := &fallbackServer{delay: map[string]time.Duration{"gemini-3.1-pro-preview": time.Second}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	status, body := postGenerate(t, context.Background(), srv.URL, `{}`)
+	if status != http.StatusOK || !strings.Contains(body, "gemini-3-flash-preview") {
+		t.Errorf("got %d %s, want 200 from the fallback", status, body)
+	}
+	if got := strings.Join(fake.calls, ","); got != "gemini-3.1-pro-preview,gemini-3-flash-preview" {
+		t.Errorf("calls = %s, want one slow attempt then the fallback", got)
+	}
+}
+
+func TestFallbackModels(t *testing.T) {
+	t.Setenv("GEMINI_FALLBACK_MODELS", " gemini-2.5-pro,,gemini-3-flash-preview,gemini-2.5-pro ")
+	if got := strings.Join(FallbackModels("gemini-3-flash-preview"), ","); got != "gemini-2.5-pro" {
+		t.Errorf("FallbackModels = %s, want gemini-2.5-pro", got)
+	}
+	t.Setenv("GEMINI_LATENCY_BUDGET", "soon")
+	if got := LatencyBudget(); got != 0 {
+		t.Errorf("LatencyBudget = %v, want 0 for an invalid value", got)
+	}
+}
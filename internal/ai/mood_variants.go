@@ -0,0 +1,77 @@
+package ai
+
+// mood_variants.go generates stylized "mood" renditions of a post's cover
+// image for an optional intro slide. See DDR-102: Stylized Cover Variants.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// MaxMoodVariants is the most variants a single request may generate.
+const MaxMoodVariants = 2
+
+// MoodStyle is a stylized rendition the user can request for a cover image.
+type MoodStyle struct {
+	ID          string
+	Instruction string
+}
+
+// MoodStyles lists the supported styles, keyed by ID.
+var MoodStyles = map[string]MoodStyle{
+	"illustration": {
+		ID: "illustration",
+		Instruction: `Redraw this photo as a hand-painted travel illustration: soft gouache textures, simplified shapes, and a warm, limited palette.
+Keep the composition, landmarks, and people recognizable. Do not add text, logos, or signatures.`,
+	},
+	"poster": {
+		ID: "poster",
+		Instruction: `Turn this photo into a vintage travel poster: bold flat colors, strong outlines, and a slightly grainy screen-printed look.
+Keep the composition and main subject. Leave clear space near the top for a title, but do not add any text.`,
+	},
+}
+
+// moodSystemInstruction tells the model the output is a stylized artwork, not
+// a photo edit, so it may depart from photographic realism.
+const moodSystemInstruction = `You create stylized artwork from a user's own travel photo for use as an intro slide in a social media post.
+The result must be clearly an illustration, never a photorealistic edit. Return exactly one image.`
+
+// ParseMoodStyles resolves requested style IDs, rejecting unknown or repeated
+// IDs and requests for more than MaxMoodVariants.
+func ParseMoodStyles(ids []string) ([]MoodStyle, error) {
+	if len(ids) == 0 || len(ids) > MaxMoodVariants {
+		return nil, fmt.Errorf("request 1 to %d styles", MaxMoodVariants)
+	}
+	seen := make(map[string]bool, len(ids))
+	styles := make([]MoodStyle, 0, len(ids))
+	for _, id := range ids {
+		style, ok := MoodStyles[id]
+		if !ok {
+			return nil, fmt.Errorf("unknown style %q", id)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("style %q requested twice", id)
+		}
+		seen[id] = true
+		styles = append(styles, style)
+	}
+	return styles, nil
+}
+
+// GenerateMoodVariant renders imageData in the given style. The result is not
+// watermarked; callers must pass it through media.WatermarkAIGenerated before
+// storing it.
+func GenerateMoodVariant(ctx context.Context, client *GeminiImageClient, imageData []byte, imageMIMEType string, style MoodStyle) (*GeminiImageResult, error) {
+	log.Debug().Str("style", style.ID).Int("image_bytes", len(imageData)).Msg("GenerateMoodVariant: starting")
+
+	result, err := client.EditImage(ctx, imageData, imageMIMEType, style.Instruction, moodSystemInstruction)
+	if err != nil {
+		return nil, fmt.Errorf("generate %s variant: %w", style.ID, err)
+	}
+	if len(result.ImageData) == 0 {
+		return nil, fmt.Errorf("generate %s variant: model returned no image", style.ID)
+	}
+	return result, nil
+}
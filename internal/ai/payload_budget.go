@@ -0,0 +1,212 @@
+package ai
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genai"
+)
+
+// --- Request payload budgeting (DDR-168) ---
+//
+// A request that exceeds the model's input token limit or the API's inline
+// request size fails only after every video has been uploaded and the call
+// made. Estimating the payload up front lets triage split batches and shrink
+// inline images before sending, instead of failing mid-call. The estimates
+// are deliberately rough and lean high: they follow Gemini's published
+// per-image and per-second token rates, not an exact count.
+
+const (
+	// DefaultMaxInputTokens is the input token limit of the Gemini 2.5 and 3
+	// models.
+	DefaultMaxInputTokens = 1_048_576
+
+	// maxInlineRequestBytes is the API's limit on the size of a request
+	// with inline data.
+	maxInlineRequestBytes = 20 * 1024 * 1024
+
+	// payloadBudgetShare is the part of each limit a request may plan to
+	// use, leaving headroom for the prompt text and estimation error.
+	payloadBudgetShare = 0.8
+
+	// imageTokens is what Gemini counts for one image (or frame sheet).
+	imageTokens = 258
+	// videoTokensPerSecond covers one frame per second plus the audio track.
+	videoTokensPerSecond = 300
+	// mediaTextTokens covers the per-item metadata lines of a prompt.
+	mediaTextTokens = 100
+
+	// estimatedImageBytes is the inline size assumed for an image whose
+	// thumbnail has not been made yet.
+	estimatedImageBytes = 512 * 1024
+	// estimatedVideoBitrate turns a video's file size into a duration when
+	// its metadata has none. It is low for phone video, so the estimate of
+	// the duration, and the tokens, errs high.
+	estimatedVideoBitrate = 8_000_000 // bits per second
+	// defaultVideoDuration is assumed when neither duration nor size is known.
+	defaultVideoDuration = time.Minute
+	// minShrinkBytes is the size below which an image is not shrunk further.
+	minShrinkBytes = 32 * 1024
+)
+
+// payloadEstimate is the approximate cost of media in a request.
+type payloadEstimate struct {
+	Tokens      int
+	InlineBytes int64
+}
+
+func (e payloadEstimate) add(o payloadEstimate) payloadEstimate {
+	return payloadEstimate{Tokens: e.Tokens + o.Tokens, InlineBytes: e.InlineBytes + o.InlineBytes}
+}
+
+// fits reports whether e is within budget b.
+func (e payloadEstimate) fits(b payloadEstimate) bool {
+	return e.Tokens <= b.Tokens && e.InlineBytes <= b.InlineBytes
+}
+
+// MaxInputTokens returns the model input token limit budgets are planned
+// against, resolved from:
+// 1. GEMINI_MAX_INPUT_TOKENS environment variable
+// 2. Default: 1,048,576
+func MaxInputTokens() int {
+	return positiveIntEnv("GEMINI_MAX_INPUT_TOKENS", DefaultMaxInputTokens)
+}
+
+// defaultPayloadBudget is the media payload a single request may plan for.
+func defaultPayloadBudget() payloadEstimate {
+	return payloadEstimate{
+		Tokens:      int(float64(MaxInputTokens()) * payloadBudgetShare),
+		InlineBytes: int64(maxInlineRequestBytes * payloadBudgetShare),
+	}
+}
+
+// estimateTriagePayload estimates what one file adds to a triage request:
+// a frame sheet for a long video that will be sampled (DDR-162), an inline
+// thumbnail for an image, and a video by the second otherwise.
+func estimateTriagePayload(file *media.MediaFile) payloadEstimate {
+	est := payloadEstimate{Tokens: mediaTextTokens}
+	ext := strings.ToLower(filepath.Ext(file.Path))
+	switch {
+	case file.Sample != nil:
+		est.Tokens += imageTokens
+		est.InlineBytes += int64(len(file.Sample.Sheet))
+	case media.IsVideo(ext):
+		d := estimateVideoDuration(file)
+		if t := TriageSampleThreshold(); t > 0 && file.PresignedURL == "" && d > t {
+			// Local long videos are sampled when the batch is built.
+			est.Tokens += imageTokens
+			est.InlineBytes += estimatedImageBytes
+			break
+		}
+		est.Tokens += int(d.Seconds()+1) * videoTokensPerSecond
+	default:
+		est.Tokens += imageTokens
+		size := int64(estimatedImageBytes)
+		if file.Size > 0 {
+			size = min(file.Size, size)
+		}
+		est.InlineBytes += size
+	}
+	return est
+}
+
+// estimateVideoDuration returns a video's duration from its metadata, or
+// else from its size at estimatedVideoBitrate.
+func estimateVideoDuration(file *media.MediaFile) time.Duration {
+	if meta, ok := file.Metadata.(*media.VideoMetadata); ok && meta != nil && meta.Duration > 0 {
+		return meta.Duration
+	}
+	if file.Size > 0 {
+		return time.Duration(float64(file.Size*8) / estimatedVideoBitrate * float64(time.Second))
+	}
+	return defaultVideoDuration
+}
+
+// splitByBudget cuts files into consecutive batches of at most maxCount
+// files whose estimated payload fits budget. A file too large for the
+// budget on its own gets a batch to itself. Returns each batch's start
+// index; batch i covers files[starts[i]:starts[i+1]].
+func splitByBudget(files []*media.MediaFile, maxCount int, budget payloadEstimate, estimate func(*media.MediaFile) payloadEstimate) []int {
+	if len(files) == 0 {
+		return nil
+	}
+	starts := []int{0}
+	var used payloadEstimate
+	for i, f := range files {
+		est := estimate(f)
+		count := i - starts[len(starts)-1]
+		if count > 0 && (count >= maxCount || !used.add(est).fits(budget)) {
+			starts = append(starts, i)
+			used = payloadEstimate{}
+		}
+		if !est.fits(budget) {
+			log.Warn().
+				Str("file", filepath.Base(f.Path)).
+				Int("estimated_tokens", est.Tokens).
+				Int64("estimated_inline_bytes", est.InlineBytes).
+				Msg("File alone exceeds the request budget, sending it in a batch of its own")
+		}
+		used = used.add(est)
+	}
+	return starts
+}
+
+// inlineBytes sums the inline data of parts.
+func inlineBytes(parts []*genai.Part) int64 {
+	var n int64
+	for _, p := range parts {
+		if p.InlineData != nil {
+			n += int64(len(p.InlineData.Data))
+		}
+	}
+	return n
+}
+
+// fitInlineParts shrinks inline images, largest first, until parts carry
+// at most maxBytes of inline data. Each step re-encodes the largest image
+// as a JPEG of at most half its size; images that cannot be decoded, or
+// are already small, are left alone. An error means the parts could not be
+// brought within maxBytes.
+func fitInlineParts(parts []*genai.Part, maxBytes int64) error {
+	total := inlineBytes(parts)
+	if total <= maxBytes {
+		return nil
+	}
+	start := total
+	shrunk := 0
+	done := map[*genai.Part]bool{} // images that cannot shrink further
+	for total > maxBytes {
+		var largest *genai.Part
+		for _, p := range parts {
+			if p.InlineData == nil || done[p] || len(p.InlineData.Data) < minShrinkBytes || !strings.HasPrefix(p.InlineData.MIMEType, "image/") {
+				continue
+			}
+			if largest == nil || len(p.InlineData.Data) > len(largest.InlineData.Data) {
+				largest = p
+			}
+		}
+		if largest == nil {
+			return fmt.Errorf("inline media is %d bytes after shrinking images, over the %d-byte request limit", total, maxBytes)
+		}
+		before := len(largest.InlineData.Data)
+		data, err := media.FitJPEG(largest.InlineData.Data, media.JPEGFitOptions{MaxBytes: before / 2})
+		if err != nil {
+			log.Debug().Err(err).Msg("Could not shrink inline image")
+			done[largest] = true
+			continue
+		}
+		largest.InlineData = &genai.Blob{MIMEType: "image/jpeg", Data: data}
+		total -= int64(before - len(data))
+		shrunk++
+	}
+	log.Info().
+		Int64("inline_bytes_before", start).
+		Int64("inline_bytes_after", total).
+		Int("images_shrunk", shrunk).
+		Msg("Shrank inline images to fit the request size limit")
+	return nil
+}
@@ -0,0 +1,125 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"google.golang.org/genai"
+)
+
+// noisyJPEG encodes random pixels, which compress poorly, so the result is
+// large enough to need shrinking.
+func noisyJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	rng := rand.New(rand.NewSource(1))
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(rng.Intn(256)), uint8(rng.Intn(256)), uint8(rng.Intn(256)), 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestEstimateTriagePayload(t *testing.T) {
+	t.Setenv("TRIAGE_SAMPLE_THRESHOLD", "3m")
+
+	photo := estimateTriagePayload(&media.MediaFile{Path: "a.jpg", Size: 100_000})
+	if photo.Tokens != mediaTextTokens+imageTokens || photo.InlineBytes != 100_000 {
+		t.Errorf("photo = %+v", photo)
+	}
+
+	clip := estimateTriagePayload(&media.MediaFile{Path: "b.mp4", PresignedURL: "https://s3/b.mp4", Metadata: &media.VideoMetadata{Duration: time.Minute}})
+	if want := mediaTextTokens + 61*videoTokensPerSecond; clip.Tokens != want || clip.InlineBytes != 0 {
+		t.Errorf("clip = %+v, want %d tokens", clip, want)
+	}
+
+	sampled := estimateTriagePayload(&media.MediaFile{Path: "c.mp4", Sample: &media.VideoSample{Sheet: make([]byte, 1000)}})
+	if sampled.Tokens != mediaTextTokens+imageTokens || sampled.InlineBytes != 1000 {
+		t.Errorf("sampled = %+v", sampled)
+	}
+
+	// A video without metadata is priced from its size: 60 MB at 8 Mbit/s is a minute.
+	if got := estimateVideoDuration(&media.MediaFile{Path: "d.mp4", Size: 60_000_000}); got != time.Minute {
+		t.Errorf("estimated duration = %v, want 1m", got)
+	}
+}
+
+func TestSplitByBudget(t *testing.T) {
+	files := numberedFiles(7)
+	cost := map[string]int{"f03.jpg": 50, "f05.jpg": 200}
+	estimate := func(f *media.MediaFile) payloadEstimate {
+		if c, ok := cost[f.Path]; ok {
+			return payloadEstimate{Tokens: c}
+		}
+		return payloadEstimate{Tokens: 10}
+	}
+
+	// f01+f02 reach the count limit of 2; f03+f04 just fit 60 tokens; f05
+	// exceeds the budget alone, so f06 cannot join it.
+	starts := splitByBudget(files, 2, payloadEstimate{Tokens: 60}, estimate)
+	if got := fmt.Sprint(starts); got != "[0 2 4 5]" {
+		t.Errorf("starts = %s, want [0 2 4 5]", got)
+	}
+}
+
+func TestRunTriageBatchesSplitsByBudget(t *testing.T) {
+	files := numberedFiles(4)
+	var sizes []int
+	plan := triageBatchPlan{
+		Size:        10,
+		Concurrency: 1,
+		Budget:      payloadEstimate{Tokens: 25},
+		Estimate:    func(*media.MediaFile) payloadEstimate { return payloadEstimate{Tokens: 10} },
+	}
+	results, err := runTriageBatches(context.Background(), files, plan, func(ctx context.Context, batch int, fs []*media.MediaFile) ([]TriageResult, error) {
+		sizes = append(sizes, len(fs))
+		var rs []TriageResult
+		for i := range fs {
+			rs = append(rs, TriageResult{Media: i + 1, Filename: fs[i].Path})
+		}
+		return rs, nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("runTriageBatches: %v", err)
+	}
+	if fmt.Sprint(sizes) != "[2 2]" {
+		t.Errorf("batch sizes = %v, want [2 2]", sizes)
+	}
+	if len(results) != 4 || results[3].Media != 4 || results[3].Filename != "f04.jpg" {
+		t.Errorf("results = %+v", results)
+	}
+}
+
+func TestFitInlineParts(t *testing.T) {
+	img := noisyJPEG(t, 256, 256)
+	parts := []*genai.Part{
+		{InlineData: &genai.Blob{MIMEType: "image/jpeg", Data: img}},
+		{InlineData: &genai.Blob{MIMEType: "image/jpeg", Data: img}},
+		{Text: "prompt"},
+	}
+	limit := int64(len(img))
+	if err := fitInlineParts(parts, limit); err != nil {
+		t.Fatalf("fitInlineParts: %v", err)
+	}
+	if got := inlineBytes(parts); got > limit {
+		t.Errorf("inline bytes = %d, want at most %d", got, limit)
+	}
+
+	audio := []*genai.Part{{InlineData: &genai.Blob{MIMEType: "audio/mpeg", Data: make([]byte, 100)}}}
+	if err := fitInlineParts(audio, 50); err == nil {
+		t.Error("fitInlineParts shrank non-image data")
+	}
+}
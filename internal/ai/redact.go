@@ -0,0 +1,226 @@
+package ai
+
+// redact.go hides people who are not the subject of a photo: Gemini finds
+// bystanders' faces and licence plates, then each region is blurred locally
+// or removed with Imagen. See DDR-185: Privacy Redaction of Bystanders.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/fpang/ai-social-media-helper/internal/jsonutil"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/rs/zerolog/log"
+)
+
+// RedactMode chooses how bystander regions are hidden.
+type RedactMode string
+
+const (
+	// RedactBlur blurs each region in place. It is the default.
+	RedactBlur RedactMode = "blur"
+	// RedactInpaint asks Imagen to replace each region with background.
+	// Without Imagen, or when the edit fails, regions are blurred instead.
+	RedactInpaint RedactMode = "inpaint"
+)
+
+// ParseRedactMode resolves a requested mode; "" means RedactBlur.
+func ParseRedactMode(s string) (RedactMode, error) {
+	switch m := RedactMode(s); m {
+	case "":
+		return RedactBlur, nil
+	case RedactBlur, RedactInpaint:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown redaction mode %q", s)
+}
+
+// redactQuality is the JPEG quality of redacted photos.
+const redactQuality = 92
+
+// redactPadding grows each detected box by this fraction of its size on
+// every side, so hair, ears and plate frames are covered too.
+const redactPadding = 0.15
+
+// maxRedactRegions caps the regions one photo may report; a crowd is better
+// left to the user than blurred into a smear.
+const maxRedactRegions = 20
+
+// BystanderRegion is a face or licence plate Gemini found that does not
+// belong to the photo's main subjects. Box uses Gemini's native detection
+// format: [ymin, xmin, ymax, xmax] normalized to 0–1000.
+type BystanderRegion struct {
+	Kind  string `json:"kind"` // "face" or "plate"
+	Label string `json:"label"`
+	Box   [4]int `json:"box_2d"`
+}
+
+type bystanderResponse struct {
+	Regions []BystanderRegion `json:"regions"`
+}
+
+const bystanderSystemInstruction = `You protect the privacy of people who appear in travel photos by accident.
+First decide who the photo is about: the people posing, the people the camera follows, or none when it shows a place.
+Then find every face of a person who is NOT one of those main subjects (passers-by, people in the background, strangers in a crowd), and every readable vehicle licence plate.
+Return only JSON: {"regions": [{"kind": "face" or "plate", "label": "short description", "box_2d": [ymin, xmin, ymax, xmax]}]}
+Coordinates are normalized to 0-1000. Never list the main subjects' faces.
+If there are no bystanders or plates, return {"regions": []}.`
+
+const bystanderPrompt = "Find bystanders' faces and licence plates to blur in this photo. Follow the response format in the system instruction exactly."
+
+const inpaintBystanderPrompt = "Fill the masked areas with background that matches the surroundings. Do not add people, faces, text or licence plates."
+
+// DetectBystanders returns the faces and plates in a photo that should be
+// hidden. An empty result means there is nothing to redact.
+func DetectBystanders(ctx context.Context, client *GeminiImageClient, imageData []byte, imageMIMEType string) ([]BystanderRegion, error) {
+	log.Debug().Int("image_bytes", len(imageData)).Msg("DetectBystanders: starting")
+
+	text, err := client.AnalyzeImage(ctx, imageData, imageMIMEType, bystanderPrompt, bystanderSystemInstruction)
+	if err != nil {
+		return nil, fmt.Errorf("detect bystanders: %w", err)
+	}
+	regions, err := parseBystanderResponse(text)
+	if err != nil {
+		log.Warn().Err(err).Str("response", truncateString(text, 500)).Msg("Failed to parse bystander response")
+		return nil, err
+	}
+	log.Debug().Int("regions", len(regions)).Msg("DetectBystanders: complete")
+	return regions, nil
+}
+
+// parseBystanderResponse extracts regions from Gemini's response, dropping
+// unknown kinds and boxes that are empty or outside the 0–1000 range.
+func parseBystanderResponse(response string) ([]BystanderRegion, error) {
+	result, err := jsonutil.ParseJSON[bystanderResponse](response)
+	if err != nil {
+		return nil, fmt.Errorf("bystander response: %w", err)
+	}
+	regions := make([]BystanderRegion, 0, len(result.Regions))
+	for _, r := range result.Regions {
+		ymin, xmin, ymax, xmax := r.Box[0], r.Box[1], r.Box[2], r.Box[3]
+		if r.Kind != "face" && r.Kind != "plate" {
+			continue
+		}
+		if ymin < 0 || xmin < 0 || ymax > 1000 || xmax > 1000 || ymin >= ymax || xmin >= xmax {
+			continue
+		}
+		regions = append(regions, r)
+		if len(regions) == maxRedactRegions {
+			break
+		}
+	}
+	return regions, nil
+}
+
+// Rect scales the region's box to a width x height image, padded by
+// redactPadding and clipped to the image.
+func (r BystanderRegion) Rect(width, height int) image.Rectangle {
+	x0, y0 := r.Box[1]*width/1000, r.Box[0]*height/1000
+	x1, y1 := r.Box[3]*width/1000, r.Box[2]*height/1000
+	padX := int(float64(x1-x0) * redactPadding)
+	padY := int(float64(y1-y0) * redactPadding)
+	return image.Rect(x0-padX, y0-padY, x1+padX, y1+padY).Intersect(image.Rect(0, 0, width, height))
+}
+
+// RedactedRegion is a region that was hidden, in the redacted photo's pixels.
+type RedactedRegion struct {
+	Kind  string
+	Label string
+	Rect  image.Rectangle
+}
+
+// RedactResult is the outcome of RedactBystanders. When Regions is empty
+// nothing was found and ImageData is the input unchanged.
+type RedactResult struct {
+	ImageData []byte
+	MIMEType  string
+	Mode      RedactMode // the mode actually applied
+	Regions   []RedactedRegion
+}
+
+// RedactBystanders finds bystanders' faces and licence plates in a photo and
+// hides them with the given mode. imagenClient may be nil; inpainting then
+// falls back to blurring.
+func RedactBystanders(ctx context.Context, geminiClient *GeminiImageClient, imagenClient *ImagenClient, imageData []byte, imageMIMEType string, mode RedactMode) (*RedactResult, error) {
+	// Work on the photo as displayed, so Gemini's boxes, the mask and
+	// Imagen's input share one pixel layout.
+	oriented, err := media.OrientedJPEG(imageData, 95)
+	if err != nil {
+		return nil, err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(oriented))
+	if err != nil {
+		return nil, fmt.Errorf("decode image config: %w", err)
+	}
+
+	found, err := DetectBystanders(ctx, geminiClient, oriented, "image/jpeg")
+	if err != nil {
+		return nil, err
+	}
+	result := &RedactResult{ImageData: imageData, MIMEType: imageMIMEType, Mode: mode}
+	if len(found) == 0 {
+		return result, nil
+	}
+
+	rects := make([]image.Rectangle, 0, len(found))
+	for _, r := range found {
+		rect := r.Rect(cfg.Width, cfg.Height)
+		if rect.Empty() {
+			continue
+		}
+		rects = append(rects, rect)
+		result.Regions = append(result.Regions, RedactedRegion{Kind: r.Kind, Label: r.Label, Rect: rect})
+	}
+	if len(rects) == 0 {
+		return result, nil
+	}
+
+	if mode == RedactInpaint {
+		if imagenClient == nil {
+			log.Info().Msg("Imagen unavailable — blurring bystanders instead of removing them")
+		} else if inpainted, err := inpaintRegions(ctx, imagenClient, oriented, cfg.Width, cfg.Height, rects); err != nil {
+			log.Warn().Err(err).Msg("Imagen removal failed — blurring bystanders instead")
+		} else {
+			result.ImageData, result.MIMEType = inpainted.ImageData, inpainted.MIMEType
+			return result, nil
+		}
+	}
+
+	blurred, err := media.BlurRegions(oriented, rects, redactQuality)
+	if err != nil {
+		return nil, err
+	}
+	result.ImageData, result.MIMEType, result.Mode = blurred, "image/jpeg", RedactBlur
+	return result, nil
+}
+
+// inpaintRegions removes every rect in one Imagen edit.
+func inpaintRegions(ctx context.Context, imagenClient *ImagenClient, imageData []byte, width, height int, rects []image.Rectangle) (*ImagenEditResult, error) {
+	mask, err := GenerateBoxMask(width, height, rects)
+	if err != nil {
+		return nil, err
+	}
+	result, err := imagenClient.EditWithMask(ctx, imageData, mask, inpaintBystanderPrompt, "inpainting-remove")
+	if err != nil {
+		return nil, err
+	}
+	if result.MIMEType == "" {
+		result.MIMEType = "image/png"
+	}
+	return result, nil
+}
+
+// GenerateBoxMask creates a mask image where the given rectangles are white
+// (edit) and everything else is black (keep).
+func GenerateBoxMask(width, height int, rects []image.Rectangle) ([]byte, error) {
+	mask := image.NewRGBA(image.Rect(0, 0, width, height))
+	fillRegion(mask, 0, 0, width, height, color.Black)
+	for _, r := range rects {
+		r = r.Intersect(mask.Bounds())
+		fillRegion(mask, r.Min.X, r.Min.Y, r.Max.X, r.Max.Y, color.White)
+	}
+	return encodeMaskJPEG(mask)
+}
@@ -0,0 +1,67 @@
+package ai
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+func TestParseBystanderResponse(t *testing.T) {
+	response := "```json\n" + `{"regions": [
+		{"kind": "face", "label": "man in red", "box_2d": [100, 200, 300, 300]},
+		{"kind": "tree", "label": "not a region kind", "box_2d": [0, 0, 500, 500]},
+		{"kind": "face", "label": "inverted", "box_2d": [500, 500, 400, 600]},
+		{"kind": "plate", "label": "car plate", "box_2d": [800, 400, 850, 500]}
+	]}` + "\n```"
+
+	regions, err := parseBystanderResponse(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(regions) != 2 || regions[0].Label != "man in red" || regions[1].Kind != "plate" {
+		t.Fatalf("unexpected regions: %+v", regions)
+	}
+
+	// 15% padding on each side, clipped to the image.
+	if got, want := regions[0].Rect(1000, 1000), image.Rect(185, 70, 315, 330); got != want {
+		t.Errorf("Rect() = %v, want %v", got, want)
+	}
+	edge := BystanderRegion{Kind: "face", Box: [4]int{0, 0, 100, 100}}
+	if got, want := edge.Rect(1000, 1000), image.Rect(0, 0, 115, 115); got != want {
+		t.Errorf("Rect() at the edge = %v, want %v", got, want)
+	}
+}
+
+func TestParseRedactMode(t *testing.T) {
+	for in, want := range map[string]RedactMode{"": RedactBlur, "blur": RedactBlur, "inpaint": RedactInpaint} {
+		if got, err := ParseRedactMode(in); err != nil || got != want {
+			t.Errorf("ParseRedactMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseRedactMode("erase"); err == nil {
+		t.Error("ParseRedactMode accepted an unknown mode")
+	}
+}
+
+func TestGenerateBoxMask(t *testing.T) {
+	data, err := GenerateBoxMask(100, 50, []image.Rectangle{image.Rect(10, 10, 30, 30), image.Rect(90, 40, 200, 200)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 100 || b.Dy() != 50 {
+		t.Fatalf("mask size = %v, want 100x50", b)
+	}
+	gray := func(x, y int) uint8 { return color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y }
+	if gray(20, 20) < 200 || gray(95, 45) < 200 {
+		t.Error("masked regions are not white")
+	}
+	if gray(60, 20) > 55 {
+		t.Error("unmasked area is not black")
+	}
+}
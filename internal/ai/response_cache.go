@@ -0,0 +1,245 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/fpang/ai-social-media-helper/internal/assets"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genai"
+)
+
+// --- Content-addressed response cache (DDR-166) ---
+//
+// Re-running triage or caption generation on files Gemini has already seen
+// costs the full tokens again. Results are cached under a key derived from
+// the SHA-256 of the content sent and everything else that shapes the
+// answer: the prompt version, the model, the thinking and sampling settings
+// and the context text. Any change to one of them is a miss, never a stale hit.
+
+// responseCacheVersion is part of every cache key. Bump it when a change to
+// the prompt builders or result parsing should invalidate cached results;
+// edits to the system prompt files change the keys on their own.
+const responseCacheVersion = "2"
+
+// ResponseCache stores Gemini results by content key. It is implemented by
+// store.DynamoStore.
+type ResponseCache interface {
+	GetCachedResponse(ctx context.Context, key string) (data []byte, found bool, err error)
+	PutCachedResponse(ctx context.Context, key string, data []byte) error
+}
+
+type responseCacheKey struct{}
+
+// WithResponseCache makes triage and description calls made with the
+// returned context look up and store their results in cache. A nil cache
+// returns ctx unchanged; jobs that bypass the cache simply do not call it.
+func WithResponseCache(ctx context.Context, cache ResponseCache) context.Context {
+	if cache == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, responseCacheKey{}, cache)
+}
+
+func responseCacheFrom(ctx context.Context) ResponseCache {
+	cache, _ := ctx.Value(responseCacheKey{}).(ResponseCache)
+	return cache
+}
+
+// responseCacheKeyOf hashes the parts of a cache key. Each part is length
+// prefixed so no two different lists of parts hash alike.
+func responseCacheKeyOf(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		_ = binary.Write(h, binary.BigEndian, uint64(len(p)))
+		h.Write([]byte(p))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// promptVersion identifies a system prompt by its hash.
+func promptVersion(systemPrompt string) string {
+	sum := sha256.Sum256([]byte(systemPrompt))
+	return responseCacheVersion + ":" + hex.EncodeToString(sum[:8])
+}
+
+// generationCacheKey renders the context's thinking configuration and
+// sampling settings (DDR-170); empty for the model's defaults.
+func generationCacheKey(ctx context.Context) string {
+	thinking := thinkingConfig(ctx)
+	mc := modelConfigFrom(ctx)
+	if thinking == nil && mc.Temperature == nil && mc.TopP == nil {
+		return ""
+	}
+	b, _ := json.Marshal(struct {
+		Thinking    *genai.ThinkingConfig `json:"thinking,omitempty"`
+		Temperature *float32              `json:"temperature,omitempty"`
+		TopP        *float32              `json:"topP,omitempty"`
+	}{thinking, mc.Temperature, mc.TopP})
+	return string(b)
+}
+
+// getCachedResponse decodes the cached value of key into v. Cache errors
+// are logged and treated as misses: the cache saves money, it must never
+// fail a job.
+func getCachedResponse(ctx context.Context, cache ResponseCache, key string, v any) bool {
+	data, found, err := cache.GetCachedResponse(ctx, key)
+	if err != nil {
+		log.Warn().Err(err).Msg("Gemini response cache lookup failed, calling Gemini")
+		return false
+	}
+	if !found {
+		return false
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		log.Warn().Err(err).Msg("Ignoring undecodable cached Gemini response")
+		return false
+	}
+	return true
+}
+
+func putCachedResponse(ctx context.Context, cache ResponseCache, key string, v any) {
+	data, err := json.Marshal(v)
+	if err == nil {
+		err = cache.PutCachedResponse(ctx, key, data)
+	}
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to cache Gemini response (non-fatal)")
+	}
+}
+
+func recordResponseCache(operation string, hits, misses int) {
+	metrics.New("AiSocialMedia").
+		Dimension("Operation", operation).
+		Metric("GeminiResponseCacheHits", float64(hits), metrics.UnitCount).
+		Metric("GeminiResponseCacheMisses", float64(misses), metrics.UnitCount).
+		Flush()
+}
+
+// cachedTriageVerdict is the cached part of a TriageResult. The media
+// number and filename belong to the job, not the content. Model is the
+// model that answered, which a fallback (DDR-169) makes differ from the
+// requested model in the key; a hit reports it as used.
+type cachedTriageVerdict struct {
+	Saveable bool   `json:"saveable"`
+	Reason   string `json:"reason"`
+	Sampled  bool   `json:"sampled,omitempty"`
+	Model    string `json:"model,omitempty"`
+}
+
+// triageCacheKey keys one file's verdict. Whether a long video is sent as
+// a sample changes what Gemini sees, so it is part of the key.
+func triageCacheKey(ctx context.Context, file *media.MediaFile, modelName, ragContext string) string {
+	parts := []string{"triage", promptVersion(assets.TriageSystemPrompt), modelName,
+		generationCacheKey(ctx), ragContext, file.ContentHash, strconv.FormatBool(file.Sample != nil)}
+	if file.Sample != nil && file.Sample.Keyframes {
+		// DDR-186: a verdict from N keyframes is not one from the whole clip.
+		parts = append(parts, "keyframes:"+strconv.Itoa(len(file.Sample.Offsets)))
+	}
+	return responseCacheKeyOf(parts...)
+}
+
+// askMediaTriageCached answers what it can from the context's response
+// cache and passes the remaining files to ask, caching the new verdicts.
+// Media numbers in and out are relative to files. Files without a
+// ContentHash are always asked. Without a cache it is just ask(files).
+func askMediaTriageCached(ctx context.Context, files []*media.MediaFile, modelName, ragContext string, ask func([]*media.MediaFile) ([]TriageResult, error)) ([]TriageResult, error) {
+	cache := responseCacheFrom(ctx)
+	if cache == nil {
+		return ask(files)
+	}
+
+	keys := make([]string, len(files))
+	var results []TriageResult
+	var missing []int // indexes into files
+	for i, f := range files {
+		if f.ContentHash != "" {
+			keys[i] = triageCacheKey(ctx, f, modelName, ragContext)
+			var v cachedTriageVerdict
+			if getCachedResponse(ctx, cache, keys[i], &v) {
+				if v.Model != "" {
+					recordModelUsed(ctx, v.Model)
+				}
+				results = append(results, TriageResult{
+					Media:    i + 1,
+					Filename: filepath.Base(f.Path),
+					Saveable: v.Saveable,
+					Reason:   v.Reason,
+					Sampled:  v.Sampled,
+					model:    v.Model,
+				})
+				continue
+			}
+		}
+		missing = append(missing, i)
+	}
+	if len(results) > 0 {
+		hits := make([]*media.MediaFile, len(results))
+		verdicts := make([]TriageResult, len(results))
+		for j, r := range results {
+			hits[j] = files[r.Media-1]
+			verdicts[j] = r
+			verdicts[j].Media = j + 1
+		}
+		reportFileProgress(ctx, hits, FileAnalyzed, verdicts) // DDR-174
+	}
+	recordResponseCache("triage", len(results), len(missing))
+	log.Info().
+		Int("cached", len(results)).
+		Int("uncached", len(missing)).
+		Msg("Triage response cache checked")
+
+	if len(missing) > 0 {
+		askFiles := make([]*media.MediaFile, len(missing))
+		for j, i := range missing {
+			askFiles[j] = files[i]
+		}
+		fresh, err := ask(askFiles)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range fresh {
+			if r.Media < 1 || r.Media > len(missing) {
+				continue
+			}
+			i := missing[r.Media-1]
+			r.Media = i + 1
+			if keys[i] != "" {
+				putCachedResponse(ctx, cache, keys[i], cachedTriageVerdict{Saveable: r.Saveable, Reason: r.Reason, Sampled: r.Sampled, Model: r.model})
+			}
+			results = append(results, r)
+		}
+	}
+
+	sort.Slice(results, func(a, b int) bool { return results[a].Media < results[b].Media })
+	return results, nil
+}
+
+// descriptionCacheKey keys a caption by the media bytes sent, the model
+// settings and the full user prompt, which carries the group label, trip
+// context, metadata, RAG context and caption template. A video sent by
+// Files API URI is keyed by its content hash; without one the key is "",
+// and the caption is not cached.
+func descriptionCacheKey(ctx context.Context, modelName, prompt string, items []DescriptionMediaItem) string {
+	parts := []string{"description", promptVersion(assets.DescriptionSystemPrompt), modelName, generationCacheKey(ctx), prompt}
+	for _, item := range items {
+		video := ""
+		if item.VideoFileURI != "" {
+			if item.ContentHash == "" {
+				return ""
+			}
+			video = item.ContentHash
+		}
+		sum := sha256.Sum256(item.ThumbnailData)
+		parts = append(parts, item.Type, hex.EncodeToString(sum[:]), video)
+	}
+	return responseCacheKeyOf(parts...)
+}
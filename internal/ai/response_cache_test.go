@@ -0,0 +1,188 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"google.golang.org/genai"
+)
+
+// fakeResponseCache holds entries in memory and counts writes.
+type fakeResponseCache struct {
+	entries map[string][]byte
+	puts    int
+	getErr  error
+}
+
+func (f *fakeResponseCache) GetCachedResponse(ctx context.Context, key string) ([]byte, bool, error) {
+	if f.getErr != nil {
+		return nil, false, f.getErr
+	}
+	data, ok := f.entries[key]
+	return data, ok, nil
+}
+
+func (f *fakeResponseCache) PutCachedResponse(ctx context.Context, key string, data []byte) error {
+	f.entries[key] = data
+	f.puts++
+	return nil
+}
+
+func TestAskMediaTriageCached(t *testing.T) {
+	cache := &fakeResponseCache{entries: map[string][]byte{}}
+	ctx := WithResponseCache(context.Background(), cache)
+	files := []*media.MediaFile{
+		{Path: "a.jpg", ContentHash: "aaa"},
+		{Path: "b.jpg"}, // no hash: never cached
+		{Path: "c.jpg", ContentHash: "ccc"},
+	}
+
+	var asked [][]string
+	ask := func(fs []*media.MediaFile) ([]TriageResult, error) {
+		var names []string
+		var results []TriageResult
+		for i, f := range fs {
+			names = append(names, f.Path)
+			results = append(results, TriageResult{Media: i + 1, Filename: f.Path, Saveable: f.Path != "c.jpg", Reason: "judged " + f.Path})
+		}
+		asked = append(asked, names)
+		return results, nil
+	}
+
+	first, err := askMediaTriageCached(ctx, files, "model", "", ask)
+	if err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if cache.puts != 2 {
+		t.Errorf("puts = %d, want 2", cache.puts)
+	}
+
+	second, err := askMediaTriageCached(ctx, files, "model", "", ask)
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if len(asked) != 2 || len(asked[1]) != 1 || asked[1][0] != "b.jpg" {
+		t.Fatalf("asked = %v, want only b.jpg on the second run", asked)
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("result %d = %+v, want %+v", i, second[i], first[i])
+		}
+	}
+	if second[2].Media != 3 || second[2].Saveable || second[2].Reason != "judged c.jpg" {
+		t.Errorf("cached c.jpg = %+v", second[2])
+	}
+
+	// A different model is a different key.
+	if _, err := askMediaTriageCached(ctx, files, "other-model", "", ask); err != nil {
+		t.Fatalf("other model: %v", err)
+	}
+	if got := len(asked[2]); got != 3 {
+		t.Errorf("other model asked %d files, want 3", got)
+	}
+}
+
+func TestAskMediaTriageCachedReportsModel(t *testing.T) {
+	cache := &fakeResponseCache{entries: map[string][]byte{}}
+	files := []*media.MediaFile{{Path: "a.jpg", ContentHash: "aaa"}}
+	ask := func(fs []*media.MediaFile) ([]TriageResult, error) {
+		// A fallback model answered for the requested one (DDR-169).
+		return []TriageResult{{Media: 1, Saveable: true, model: "fallback-model"}}, nil
+	}
+	if _, err := askMediaTriageCached(WithResponseCache(context.Background(), cache), files, "model", "", ask); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, models := WithModelRecorder(WithResponseCache(context.Background(), cache))
+	results, err := askMediaTriageCached(ctx, files, "model", "", func([]*media.MediaFile) ([]TriageResult, error) {
+		t.Fatal("cached verdict asked again")
+		return nil, nil
+	})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("results = %+v, err = %v", results, err)
+	}
+	if got := models.Models(); len(got) != 1 || got[0] != "fallback-model" {
+		t.Errorf("models used on a cache hit = %v, want [fallback-model]", got)
+	}
+}
+
+func TestCallModelRecorder(t *testing.T) {
+	ctx, job := WithModelRecorder(context.Background())
+	callCtx, call := withCallModelRecorder(ctx)
+	recordModelUsed(callCtx, "fallback-model")
+	recordModelUsed(ctx, "model")
+	if got := call.Models(); len(got) != 1 || got[0] != "fallback-model" {
+		t.Errorf("call models = %v", got)
+	}
+	if got := job.Models(); len(got) != 2 {
+		t.Errorf("job models = %v, want both", got)
+	}
+}
+
+func TestAskMediaTriageCachedLookupError(t *testing.T) {
+	cache := &fakeResponseCache{entries: map[string][]byte{}, getErr: errors.New("throttled")}
+	ctx := WithResponseCache(context.Background(), cache)
+	files := []*media.MediaFile{{Path: "a.jpg", ContentHash: "aaa"}}
+
+	calls := 0
+	results, err := askMediaTriageCached(ctx, files, "model", "", func(fs []*media.MediaFile) ([]TriageResult, error) {
+		calls++
+		return []TriageResult{{Media: 1, Saveable: true}}, nil
+	})
+	if err != nil || calls != 1 || len(results) != 1 {
+		t.Errorf("results = %+v, err = %v, calls = %d", results, err, calls)
+	}
+}
+
+func TestTriageCacheKey(t *testing.T) {
+	ctx := context.Background()
+	photo := &media.MediaFile{Path: "a.jpg", ContentHash: "aaa"}
+	renamed := &media.MediaFile{Path: "renamed.jpg", ContentHash: "aaa"}
+	sampled := &media.MediaFile{Path: "a.jpg", ContentHash: "aaa", Sample: &media.VideoSample{}}
+	keyframed := &media.MediaFile{Path: "a.jpg", ContentHash: "aaa", Sample: &media.VideoSample{Keyframes: true, Offsets: make([]time.Duration, 4)}}
+
+	key := triageCacheKey(ctx, photo, "model", "")
+	if triageCacheKey(ctx, renamed, "model", "") != key {
+		t.Error("renaming a file changed its key")
+	}
+	thinking, _ := WithThinking(ctx, ThinkingLow)
+	warm, _ := ApplyModelConfig(ctx, ModelConfig{Temperature: genai.Ptr[float32](1.5)})
+	for name, other := range map[string]string{
+		"rag context": triageCacheKey(ctx, photo, "model", "likes sunsets"),
+		"sample":      triageCacheKey(ctx, sampled, "model", ""),
+		"keyframes":   triageCacheKey(ctx, keyframed, "model", ""),
+		"thinking":    triageCacheKey(thinking, photo, "model", ""),
+		"temperature": triageCacheKey(warm, photo, "model", ""),
+	} {
+		if other == key {
+			t.Errorf("%s did not change the key", name)
+		}
+	}
+	if triageCacheKey(ctx, keyframed, "model", "") == triageCacheKey(ctx, sampled, "model", "") {
+		t.Error("a keyframe sheet shares its key with a long-video sample")
+	}
+}
+
+func TestDescriptionCacheKey(t *testing.T) {
+	ctx := context.Background()
+	video := func(uri, hash string) []DescriptionMediaItem {
+		return []DescriptionMediaItem{{Type: "Video", ThumbnailData: []byte("thumb"), VideoFileURI: uri, ContentHash: hash}}
+	}
+
+	key := descriptionCacheKey(ctx, "model", "prompt", video("files/1", "aaa"))
+	if key == "" || descriptionCacheKey(ctx, "model", "prompt", video("files/2", "aaa")) != key {
+		t.Error("uploading the same video again changed its key")
+	}
+	if descriptionCacheKey(ctx, "model", "prompt", video("files/1", "bbb")) == key {
+		t.Error("a different video shares the key")
+	}
+	if got := descriptionCacheKey(ctx, "model", "prompt", video("files/1", "")); got != "" {
+		t.Errorf("video without a content hash keyed as %q, want no key", got)
+	}
+	if descriptionCacheKey(ctx, "model", "prompt", video("", "")) == "" {
+		t.Error("a video described by its thumbnail alone was not keyed")
+	}
+}
@@ -4,10 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/fpang/ai-social-media-helper/internal/assets"
-	"github.com/fpang/ai-social-media-helper/internal/jsonutil"
 	"github.com/fpang/ai-social-media-helper/internal/metrics"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/genai"
@@ -38,6 +38,34 @@ type SelectedItem struct {
 	Scene          string `json:"scene"`
 	Justification  string `json:"justification"`
 	ComparisonNote string `json:"comparisonNote,omitempty"`
+
+	Scores  *SelectionScores `json:"scores,omitempty"`  // DDR-150
+	AltText string           `json:"altText,omitempty"` // DDR-154: photos only
+}
+
+// SelectionScores rates a selected item against the named selection
+// criteria, each from MinCriterionScore to MaxCriterionScore (DDR-150).
+type SelectionScores struct {
+	Composition      int `json:"composition"`
+	StoryValue       int `json:"storyValue"`
+	Uniqueness       int `json:"uniqueness"`
+	TechnicalQuality int `json:"technicalQuality"`
+}
+
+// Bounds of a criterion score.
+const (
+	MinCriterionScore = 1
+	MaxCriterionScore = 10
+)
+
+// valid reports whether every criterion was scored within bounds.
+func (s *SelectionScores) valid() bool {
+	for _, v := range []int{s.Composition, s.StoryValue, s.Uniqueness, s.TechnicalQuality} {
+		if v < MinCriterionScore || v > MaxCriterionScore {
+			return false
+		}
+	}
+	return true
 }
 
 // ExcludedItem represents a media item not chosen by the AI, with a reason.
@@ -45,7 +73,7 @@ type ExcludedItem struct {
 	Media       int    `json:"media"`
 	Filename    string `json:"filename"`
 	Reason      string `json:"reason"`
-	Category    string `json:"category"` // "near-duplicate", "quality-issue", "content-mismatch", "redundant-scene"
+	Category    string `json:"category"` // "near-duplicate", "quality-issue", "content-mismatch", "redundant-scene", "user-excluded" (DDR-176)
 	DuplicateOf string `json:"duplicateOf,omitempty"`
 }
 
@@ -72,12 +100,65 @@ type SceneGroupItem struct {
 // See DDR-017: Francis Reference Photo for Person Identification.
 var SelectionSystemInstruction = assets.SelectionSystemPrompt
 
-// parseSelectionResponse extracts and parses the JSON object from Gemini's response.
-func parseSelectionResponse(response string) (*SelectionResult, error) {
+// selectionResponseShape describes the selection JSON for repair prompts.
+const selectionResponseShape = `{"selected": [{"rank": <int>, "media": <1-based media number>, "filename": "<file name>", "type": "Photo"|"Video", "scene": "<scene>", "justification": "<text>", "comparisonNote": "<optional>", "scores": {"composition": <1-10>, "storyValue": <1-10>, "uniqueness": <1-10>, "technicalQuality": <1-10>}, "altText": "<photos only>"}, ...],
+ "excluded": [{"media": <1-based media number>, "filename": "<file name>", "reason": "<text>", "category": "near-duplicate"|"quality-issue"|"content-mismatch"|"redundant-scene", "duplicateOf": "<optional>"}, ...],
+ "sceneGroups": [{"name": "<scene>", "gps": "<optional>", "timeRange": "<optional>", "items": [{"media": <int>, "filename": "<file name>", "type": "Photo"|"Video", "selected": <true|false>, "description": "<text>"}, ...]}, ...]}`
+
+// selectionSchema normalizes a selection response for fileCount media items
+// (DDR-165). Selected and excluded items with a media number out of range,
+// or already selected or excluded, are dropped, as are scene group items
+// out of range. A missing rank is taken from the item's position and a
+// missing exclusion reason is filled in.
+func selectionSchema(fileCount int) responseSchema[SelectionResult] {
+	return responseSchema[SelectionResult]{
+		Operation: "selection",
+		Shape:     selectionResponseShape,
+		Normalize: func(result *SelectionResult) {
+			seen := make(map[int]bool, len(result.Selected)+len(result.Excluded))
+			selected := result.Selected[:0]
+			for i, it := range result.Selected {
+				if !keepMedia("selection", fmt.Sprintf("selected item %d", i+1), it.Media, fileCount, seen) {
+					continue
+				}
+				if it.Rank == 0 {
+					it.Rank = i + 1
+				}
+				selected = append(selected, it)
+			}
+			result.Selected = selected
+			excluded := result.Excluded[:0]
+			for i, it := range result.Excluded {
+				if !keepMedia("selection", fmt.Sprintf("excluded item %d", i+1), it.Media, fileCount, seen) {
+					continue
+				}
+				if strings.TrimSpace(it.Reason) == "" {
+					it.Reason = "No reason given"
+				}
+				excluded = append(excluded, it)
+			}
+			result.Excluded = excluded
+			for g := range result.SceneGroups {
+				items := result.SceneGroups[g].Items[:0]
+				for _, it := range result.SceneGroups[g].Items {
+					if it.Media >= 1 && it.Media <= fileCount {
+						items = append(items, it)
+					}
+				}
+				result.SceneGroups[g].Items = items
+			}
+		},
+	}
+}
+
+// parseSelectionResponse extracts, normalizes and parses the JSON object from
+// Gemini's response for fileCount media items, repairing it through repair
+// when it does not parse.
+func parseSelectionResponse(ctx context.Context, response string, fileCount int, repair jsonRepairer) (*SelectionResult, error) {
 	log.Debug().
 		Int("response_length", len(response)).
 		Msg("Parsing selection response JSON")
-	result, err := jsonutil.ParseJSON[SelectionResult](response)
+	result, err := parseValidated(ctx, response, selectionSchema(fileCount), repair)
 	if err != nil {
 		log.Error().Err(err).Str("response", response).Msg("Failed to parse selection response")
 		return nil, fmt.Errorf("selection response: %w", err)
@@ -85,6 +166,15 @@ func parseSelectionResponse(response string) (*SelectionResult, error) {
 	if len(result.Selected) == 0 && len(result.Excluded) == 0 {
 		return nil, fmt.Errorf("empty selection results (no items selected or excluded)")
 	}
+	// A partial or out-of-range breakdown would sort misleadingly; the
+	// justification still explains the pick.
+	for i := range result.Selected {
+		if sc := result.Selected[i].Scores; sc != nil && !sc.valid() {
+			log.Warn().Int("media", result.Selected[i].Media).Interface("scores", sc).Msg("Dropping invalid selection scores")
+			result.Selected[i].Scores = nil
+		}
+		result.Selected[i].AltText = CleanAltText(result.Selected[i].AltText)
+	}
 	log.Debug().
 		Int("selected_count", len(result.Selected)).
 		Int("excluded_count", len(result.Excluded)).
@@ -0,0 +1,174 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+)
+
+// --- Selection re-run constraints (DDR-176) ---
+
+// CategoryUserExcluded is the exclusion category of media the user
+// excluded from a re-run.
+const CategoryUserExcluded = "user-excluded"
+
+// Reasons recorded for media placed by a constraint rather than by Gemini.
+const (
+	lockedJustification = "Locked by the user"
+	userExcludedReason  = "Excluded by the user"
+)
+
+// SelectionConstraints are the picks a user fixed on a previous selection.
+// Locked files must be selected and Excluded files must not be; Gemini
+// ranks the rest around them. Files are the caller's own MediaFile
+// pointers.
+type SelectionConstraints struct {
+	Locked   []*media.MediaFile
+	Excluded []*media.MediaFile
+}
+
+type selectionConstraintsKey struct{}
+
+// WithSelectionConstraints returns a context whose AskMediaSelectionJSON
+// tells Gemini about c in the prompt and enforces c on the result.
+func WithSelectionConstraints(ctx context.Context, c SelectionConstraints) context.Context {
+	return context.WithValue(ctx, selectionConstraintsKey{}, c)
+}
+
+func selectionConstraints(ctx context.Context) SelectionConstraints {
+	c, _ := ctx.Value(selectionConstraintsKey{}).(SelectionConstraints)
+	return c
+}
+
+// mediaNumbers returns the 1-based numbers of want within files, in order.
+// Files not in files are skipped.
+func mediaNumbers(files, want []*media.MediaFile) []int {
+	wanted := make(map[*media.MediaFile]bool, len(want))
+	for _, f := range want {
+		wanted[f] = true
+	}
+	var nums []int
+	for i, f := range files {
+		if wanted[f] {
+			nums = append(nums, i+1)
+		}
+	}
+	return nums
+}
+
+// writeSelectionConstraints writes the user's fixed picks. Writes nothing
+// without constraints.
+func writeSelectionConstraints(sb *strings.Builder, files []*media.MediaFile, c SelectionConstraints) {
+	locked, excluded := mediaNumbers(files, c.Locked), mediaNumbers(files, c.Excluded)
+	if len(locked) == 0 && len(excluded) == 0 {
+		return
+	}
+	list := func(nums []int) string {
+		s := make([]string, len(nums))
+		for i, n := range nums {
+			s[i] = fmt.Sprintf("%d", n)
+		}
+		return strings.Join(s, ", ")
+	}
+
+	sb.WriteString("### User Constraints\n\n")
+	sb.WriteString("The user reviewed a previous selection of these media and fixed some choices. Follow them exactly and re-rank everything else around them.\n\n")
+	if len(locked) > 0 {
+		sb.WriteString(fmt.Sprintf("- **Locked**: Media %s. Include each in \"selected\", ranked where it best fits the story, and mark it selected in its scene group. Judge the remaining media as alternatives to these picks, so near-duplicates of a locked item are excluded.\n", list(locked)))
+	}
+	if len(excluded) > 0 {
+		sb.WriteString(fmt.Sprintf("- **Excluded**: Media %s. Put each in \"excluded\" with category %q and reason %q. Do not select a near-duplicate just to replace it.\n", list(excluded), CategoryUserExcluded, userExcludedReason))
+	}
+	sb.WriteString("\n")
+}
+
+// applySelectionConstraints makes result honour c for files when Gemini
+// did not: a locked file it excluded or left out is selected after its
+// ranked picks, and an excluded file is listed with CategoryUserExcluded
+// whatever Gemini said. Ranks are renumbered from 1.
+func applySelectionConstraints(result *SelectionResult, files []*media.MediaFile, c SelectionConstraints) {
+	lockedNums, excludedNums := mediaNumbers(files, c.Locked), mediaNumbers(files, c.Excluded)
+	if result == nil || len(lockedNums)+len(excludedNums) == 0 {
+		return
+	}
+	locked := make(map[int]bool, len(lockedNums))
+	for _, n := range lockedNums {
+		locked[n] = true
+	}
+	excluded := make(map[int]bool, len(excludedNums))
+	for _, n := range excludedNums {
+		excluded[n] = true
+	}
+
+	moved := 0
+	selected := make(map[int]bool, len(result.Selected))
+	kept := result.Selected[:0]
+	for _, it := range result.Selected {
+		if excluded[it.Media] {
+			moved++
+			continue
+		}
+		selected[it.Media] = true
+		kept = append(kept, it)
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Rank < kept[j].Rank })
+	for _, n := range lockedNums {
+		if !selected[n] {
+			moved++
+			kept = append(kept, SelectedItem{
+				Media:         n,
+				Filename:      filepath.Base(files[n-1].Path),
+				Type:          mediaTypeLabel(files[n-1].Path),
+				Justification: lockedJustification,
+			})
+		}
+	}
+	for i := range kept {
+		kept[i].Rank = i + 1
+	}
+	result.Selected = kept
+
+	others := result.Excluded[:0]
+	for _, it := range result.Excluded {
+		if !locked[it.Media] && !excluded[it.Media] {
+			others = append(others, it)
+		}
+	}
+	for _, n := range excludedNums {
+		others = append(others, ExcludedItem{
+			Media:    n,
+			Filename: filepath.Base(files[n-1].Path),
+			Reason:   userExcludedReason,
+			Category: CategoryUserExcluded,
+		})
+	}
+	result.Excluded = others
+
+	for g := range result.SceneGroups {
+		for i := range result.SceneGroups[g].Items {
+			it := &result.SceneGroups[g].Items[i]
+			if locked[it.Media] {
+				it.Selected = true
+			} else if excluded[it.Media] {
+				it.Selected = false
+			}
+		}
+	}
+	if moved > 0 {
+		log.Info().Int("moved", moved).Msg("Selection adjusted to the user's locked and excluded media")
+	}
+}
+
+// mediaTypeLabel is the "Photo" or "Video" type Gemini reports for path.
+func mediaTypeLabel(path string) string {
+	if media.IsVideo(strings.ToLower(filepath.Ext(path))) {
+		return "Video"
+	}
+	return "Photo"
+}
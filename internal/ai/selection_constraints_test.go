@@ -0,0 +1,61 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+)
+
+func TestApplySelectionConstraints(t *testing.T) {
+	files := []*media.MediaFile{{Path: "a.jpg"}, {Path: "b.jpg"}, {Path: "c.mp4"}, {Path: "d.jpg"}}
+	result := &SelectionResult{
+		Selected: []SelectedItem{{Rank: 1, Media: 2}, {Rank: 2, Media: 4}},
+		Excluded: []ExcludedItem{{Media: 1, Category: "near-duplicate"}, {Media: 3, Category: "quality-issue"}},
+		SceneGroups: []SceneGroup{{Items: []SceneGroupItem{
+			{Media: 1}, {Media: 2, Selected: true}, {Media: 3}, {Media: 4, Selected: true},
+		}}},
+	}
+	// Lock c.mp4, which Gemini excluded; exclude b.jpg, which it selected.
+	applySelectionConstraints(result, files, SelectionConstraints{
+		Locked:   []*media.MediaFile{files[2]},
+		Excluded: []*media.MediaFile{files[1]},
+	})
+
+	if len(result.Selected) != 2 {
+		t.Fatalf("selected = %+v", result.Selected)
+	}
+	if s := result.Selected[0]; s.Media != 4 || s.Rank != 1 {
+		t.Errorf("selected[0] = %+v, want d.jpg at rank 1", s)
+	}
+	if s := result.Selected[1]; s.Media != 3 || s.Rank != 2 || s.Type != "Video" || s.Justification != lockedJustification {
+		t.Errorf("selected[1] = %+v, want the locked c.mp4 at rank 2", s)
+	}
+	if len(result.Excluded) != 2 || result.Excluded[0].Media != 1 {
+		t.Fatalf("excluded = %+v", result.Excluded)
+	}
+	if e := result.Excluded[1]; e.Media != 2 || e.Category != CategoryUserExcluded || e.Filename != "b.jpg" {
+		t.Errorf("excluded[1] = %+v, want b.jpg excluded by the user", e)
+	}
+	for _, it := range result.SceneGroups[0].Items {
+		if want := it.Media == 3 || it.Media == 4; it.Selected != want {
+			t.Errorf("scene item %d selected = %v, want %v", it.Media, it.Selected, want)
+		}
+	}
+}
+
+func TestSelectionPromptConstraints(t *testing.T) {
+	files := []*media.MediaFile{{Path: "a.jpg"}, {Path: "b.jpg"}, {Path: "c.jpg"}}
+	prompt := buildMediaSelectionJSONPrompt(files, "", "", nil, SelectionConstraints{
+		Locked:   []*media.MediaFile{files[2], files[0]},
+		Excluded: []*media.MediaFile{files[1], {Path: "elsewhere.jpg"}},
+	})
+	for _, want := range []string{"### User Constraints", "**Locked**: Media 1, 3.", "**Excluded**: Media 2."} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt lacks %q", want)
+		}
+	}
+	if strings.Contains(BuildMediaSelectionJSONPrompt(files, "", "", nil), "User Constraints") {
+		t.Error("prompt without constraints has a constraints section")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fpang/ai-social-media-helper/internal/artifacts"
 	"github.com/fpang/ai-social-media-helper/internal/assets"
 	"github.com/fpang/ai-social-media-helper/internal/media"
 	"github.com/fpang/ai-social-media-helper/internal/metrics"
@@ -69,10 +70,13 @@ func AskMediaSelection(ctx context.Context, client *genai.Client, files []*media
 			Parts: []*genai.Part{{Text: MediaSelectionSystemInstruction}},
 		},
 		MediaResolution: genai.MediaResolutionHigh,
+		ThinkingConfig:  thinkingConfig(ctx), // DDR-155
 	}
+	applySampling(ctx, config) // DDR-170
 
 	// Add the text prompt at the end
 	parts = append(parts, &genai.Part{Text: prompt})
+	artifacts.SaveText(ctx, "selection-prompt.txt", prompt)
 
 	log.Info().
 		Int("num_images", imageCount).
@@ -88,6 +92,7 @@ func AskMediaSelection(ctx context.Context, client *genai.Client, files []*media
 		Msg("Starting Gemini API call for media selection")
 	resp, err := client.Models.GenerateContent(ctx, modelName, contents, config)
 	geminiElapsed := time.Since(geminiStart)
+	metrics.RecordGeminiCall(ctx, geminiElapsed)
 
 	// Emit Gemini API metrics
 	m := metrics.New("AiSocialMedia").
@@ -99,6 +104,7 @@ func AskMediaSelection(ctx context.Context, client *genai.Client, files []*media
 	}
 	if resp != nil && resp.UsageMetadata != nil {
 		m.Metric("GeminiInputTokens", float64(resp.UsageMetadata.PromptTokenCount), metrics.UnitCount)
+		metrics.RecordGeminiTokens(ctx, int64(resp.UsageMetadata.PromptTokenCount)) // DDR-186
 		m.Metric("GeminiOutputTokens", float64(resp.UsageMetadata.CandidatesTokenCount), metrics.UnitCount)
 	}
 	m.Flush()
@@ -120,6 +126,7 @@ func AskMediaSelection(ctx context.Context, client *genai.Client, files []*media
 
 	// Extract text from response
 	response := resp.Text()
+	artifacts.SaveText(ctx, "selection-response.txt", response)
 	log.Info().
 		Int("response_length", len(response)).
 		Msg("Media selection complete")
@@ -141,7 +148,10 @@ type SelectionOutput struct {
 // storeCompressed is an optional callback to store compressed videos in S3.
 // keyMapper maps local file paths to S3 keys (optional, for cloud mode).
 // cacheMgr is an optional CacheManager for context caching (DDR-065). Pass nil to disable.
-func AskMediaSelectionJSON(ctx context.Context, client *genai.Client, files []*media.MediaFile, tripContext string, modelName string, sessionID string, storeCompressed CompressedVideoStore, keyMapper KeyMapper, cacheMgr *CacheManager, ragContext string, economyMode bool) (*SelectionOutput, error) {
+// scenes are precomputed time/GPS scene groups (DDR-114). Pass nil to disable.
+// A context from WithSelectionConstraints fixes the user's locked and
+// excluded picks (DDR-176); economy mode only puts them in the prompt.
+func AskMediaSelectionJSON(ctx context.Context, client *genai.Client, files []*media.MediaFile, tripContext string, modelName string, sessionID string, storeCompressed CompressedVideoStore, keyMapper KeyMapper, cacheMgr *CacheManager, ragContext string, scenes []media.Scene, economyMode bool) (*SelectionOutput, error) {
 	// Count media types for logging
 	var imageCount, videoCount int
 	for _, file := range files {
@@ -169,8 +179,9 @@ func AskMediaSelectionJSON(ctx context.Context, client *genai.Client, files []*m
 		return nil, err
 	}
 
-	// Build the prompt
-	prompt := BuildMediaSelectionJSONPrompt(files, tripContext, ragContext)
+	// Build the prompt, with the user's fixed picks on a re-run (DDR-176)
+	constraints := selectionConstraints(ctx)
+	prompt := buildMediaSelectionJSONPrompt(files, tripContext, ragContext, scenes, constraints)
 
 	systemInstruction := &genai.Content{
 		Parts: []*genai.Part{{Text: MediaSelectionJSONInstruction}},
@@ -181,13 +192,17 @@ func AskMediaSelectionJSON(ctx context.Context, client *genai.Client, files []*m
 		Int("num_videos", len(uploadedFiles)).
 		Msg("Sending media to Gemini for JSON selection...")
 
+	artifacts.SaveText(ctx, "selection-prompt.txt", prompt)
+
 	if economyMode {
 		parts = append(parts, &genai.Part{Text: prompt})
 		contents := []*genai.Content{{Role: "user", Parts: parts}}
 		config := &genai.GenerateContentConfig{
 			SystemInstruction: systemInstruction,
 			MediaResolution:   genai.MediaResolutionHigh,
+			ThinkingConfig:    thinkingConfig(ctx), // DDR-155
 		}
+		applySampling(ctx, config) // DDR-170
 		req := &genai.InlinedRequest{Contents: contents, Config: config}
 		jobName, err := SubmitGeminiBatch(ctx, client, modelName, []*genai.InlinedRequest{req})
 		if err != nil {
@@ -214,12 +229,15 @@ func AskMediaSelectionJSON(ctx context.Context, client *genai.Client, files []*m
 			Operation: "selection",
 		}, modelName, systemInstruction, cacheContents, userParts, &genai.GenerateContentConfig{
 			MediaResolution: genai.MediaResolutionHigh,
+			ThinkingConfig:  thinkingConfig(ctx), // DDR-155
 		})
 	} else {
 		config := &genai.GenerateContentConfig{
 			SystemInstruction: systemInstruction,
 			MediaResolution:   genai.MediaResolutionHigh,
+			ThinkingConfig:    thinkingConfig(ctx), // DDR-155
 		}
+		applySampling(ctx, config) // DDR-170
 		parts = append(parts, &genai.Part{Text: prompt})
 		contents := []*genai.Content{{Role: "user", Parts: parts}}
 
@@ -232,6 +250,7 @@ func AskMediaSelectionJSON(ctx context.Context, client *genai.Client, files []*m
 	}
 
 	geminiElapsed := time.Since(geminiStart)
+	metrics.RecordGeminiCall(ctx, geminiElapsed)
 
 	// Emit Gemini API metrics (DDR-065: cache hit/miss tracking)
 	m := metrics.New("AiSocialMedia").
@@ -250,6 +269,7 @@ func AskMediaSelectionJSON(ctx context.Context, client *genai.Client, files []*m
 	}
 	if resp != nil && resp.UsageMetadata != nil {
 		m.Metric("GeminiInputTokens", float64(resp.UsageMetadata.PromptTokenCount), metrics.UnitCount)
+		metrics.RecordGeminiTokens(ctx, int64(resp.UsageMetadata.PromptTokenCount)) // DDR-186
 		m.Metric("GeminiOutputTokens", float64(resp.UsageMetadata.CandidatesTokenCount), metrics.UnitCount)
 		if resp.UsageMetadata.CachedContentTokenCount > 0 {
 			m.Metric("GeminiCachedTokens", float64(resp.UsageMetadata.CachedContentTokenCount), metrics.UnitCount)
@@ -269,16 +289,18 @@ func AskMediaSelectionJSON(ctx context.Context, client *genai.Client, files []*m
 
 	// Extract text from response
 	responseText := resp.Text()
+	artifacts.SaveText(ctx, "selection-response.txt", responseText)
 	log.Debug().
 		Int("response_length", len(responseText)).
 		Dur("duration", geminiElapsed).
 		Msg("Gemini API response received for JSON media selection")
 
-	// Parse JSON response
-	selectionResult, err := parseSelectionResponse(responseText)
+	// Parse JSON response, repairing it if needed (DDR-165)
+	selectionResult, err := parseSelectionResponse(ctx, responseText, len(files), geminiJSONRepairer(client, modelName))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse selection response: %w", err)
 	}
+	applySelectionConstraints(selectionResult, files, constraints)
 
 	log.Info().
 		Int("selected", len(selectionResult.Selected)).
@@ -387,6 +409,7 @@ func buildMediaParts(ctx context.Context, client *genai.Client, files []*media.M
 					continue
 				}
 				cleanupFuncs = append(cleanupFuncs, cleanup)
+				artifacts.SaveFile(ctx, "compressed/"+filepath.Base(file.Path)+".webm", compressedPath)
 
 				log.Info().
 					Str("file", filepath.Base(file.Path)).
@@ -431,5 +454,10 @@ func buildMediaParts(ctx context.Context, client *genai.Client, files []*media.M
 		}
 	}
 
+	// DDR-168: shrink inline thumbnails rather than exceed the request size limit.
+	if err := fitInlineParts(parts, maxInlineRequestBytes); err != nil {
+		return nil, cleanupAll, nil, nil, err
+	}
+
 	return parts, cleanupAll, uploadedFiles, compressedKeys, nil
 }
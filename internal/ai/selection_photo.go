@@ -33,7 +33,9 @@ func AskPhotoSelection(ctx context.Context, client *genai.Client, files []*media
 			Parts: []*genai.Part{{Text: SelectionSystemInstruction}},
 		},
 		MediaResolution: genai.MediaResolutionHigh,
+		ThinkingConfig:  thinkingConfig(ctx), // DDR-155
 	}
+	applySampling(ctx, config) // DDR-170
 
 	// Build parts: reference photo first, then thumbnails, then prompt
 	var parts []*genai.Part
@@ -91,6 +93,7 @@ func AskPhotoSelection(ctx context.Context, client *genai.Client, files []*media
 		Msg("Starting Gemini API call for photo selection")
 	resp, err := client.Models.GenerateContent(ctx, modelName, contents, config)
 	geminiElapsed := time.Since(geminiStart)
+	metrics.RecordGeminiCall(ctx, geminiElapsed)
 	log.Debug().
 		Int("response_length", len(resp.Text())).
 		Dur("duration", geminiElapsed).
@@ -106,6 +109,7 @@ func AskPhotoSelection(ctx context.Context, client *genai.Client, files []*media
 	}
 	if resp != nil && resp.UsageMetadata != nil {
 		m.Metric("GeminiInputTokens", float64(resp.UsageMetadata.PromptTokenCount), metrics.UnitCount)
+		metrics.RecordGeminiTokens(ctx, int64(resp.UsageMetadata.PromptTokenCount)) // DDR-186
 		m.Metric("GeminiOutputTokens", float64(resp.UsageMetadata.CandidatesTokenCount), metrics.UnitCount)
 	}
 	m.Flush()
@@ -170,8 +170,7 @@ func BuildPhotoSelectionPrompt(files []*media.MediaFile, maxPhotos int, tripCont
 				sb.WriteString(fmt.Sprintf("- GPS: %.6f, %.6f\n", lat, lon))
 			}
 			if file.Metadata.HasDateData() {
-				date := file.Metadata.GetDate()
-				sb.WriteString(fmt.Sprintf("- Date: %s\n", date.Format("Monday, January 2, 2006 at 3:04 PM")))
+				sb.WriteString(fmt.Sprintf("- Date: %s\n", media.FormatCaptureTime(file.Metadata)))
 			}
 			// Add camera info for images
 			if imgMeta, ok := file.Metadata.(*media.ImageMetadata); ok {
@@ -240,6 +239,14 @@ func BuildPhotoSelectionPrompt(files []*media.MediaFile, maxPhotos int, tripCont
 // writeMediaMetadata writes per-item metadata to the string builder.
 // Shared by BuildMediaSelectionPrompt and BuildMediaSelectionJSONPrompt.
 func writeMediaMetadata(sb *strings.Builder, files []*media.MediaFile) {
+	writeSceneMediaMetadata(sb, files, nil)
+}
+
+// writeSceneMediaMetadata is writeMediaMetadata for items already grouped into
+// scenes (DDR-114). sceneOf maps a file index to its 1-based scene number; for
+// those items the scene and capture time replace the GPS and full date lines,
+// which the scene summary already carries.
+func writeSceneMediaMetadata(sb *strings.Builder, files []*media.MediaFile, sceneOf map[int]int) {
 	for i, file := range files {
 		ext := strings.ToLower(filepath.Ext(file.Path))
 		mediaType := "Photo"
@@ -250,13 +257,17 @@ func writeMediaMetadata(sb *strings.Builder, files []*media.MediaFile) {
 		sb.WriteString(fmt.Sprintf("**Media %d: %s** [%s]\n", i+1, filepath.Base(file.Path), mediaType))
 
 		if file.Metadata != nil {
-			if file.Metadata.HasGPSData() {
-				lat, lon := file.Metadata.GetGPS()
-				sb.WriteString(fmt.Sprintf("- GPS: %.6f, %.6f\n", lat, lon))
-			}
-			if file.Metadata.HasDateData() {
-				date := file.Metadata.GetDate()
-				sb.WriteString(fmt.Sprintf("- Date: %s\n", date.Format("Monday, January 2, 2006 at 3:04 PM")))
+			if scene, ok := sceneOf[i]; ok {
+				sb.WriteString(fmt.Sprintf("- Scene: %d\n", scene))
+				sb.WriteString(fmt.Sprintf("- Time: %s\n", file.Metadata.GetDate().Format("3:04 PM")))
+			} else {
+				if file.Metadata.HasGPSData() {
+					lat, lon := file.Metadata.GetGPS()
+					sb.WriteString(fmt.Sprintf("- GPS: %.6f, %.6f\n", lat, lon))
+				}
+				if file.Metadata.HasDateData() {
+					sb.WriteString(fmt.Sprintf("- Date: %s\n", media.FormatCaptureTime(file.Metadata)))
+				}
 			}
 
 			switch m := file.Metadata.(type) {
@@ -11,7 +11,15 @@ import (
 // BuildMediaSelectionJSONPrompt creates a prompt for structured JSON media selection.
 // Unlike BuildMediaSelectionPrompt, this produces a prompt for the JSON output mode
 // without an item limit — the AI selects all worthy items. See DDR-030.
-func BuildMediaSelectionJSONPrompt(files []*media.MediaFile, tripContext string, ragContext string) string {
+// scenes are the precomputed time/GPS groups from media.GroupScenes (DDR-114);
+// pass nil to leave scene grouping entirely to the model.
+func BuildMediaSelectionJSONPrompt(files []*media.MediaFile, tripContext string, ragContext string, scenes []media.Scene) string {
+	return buildMediaSelectionJSONPrompt(files, tripContext, ragContext, scenes, SelectionConstraints{})
+}
+
+// buildMediaSelectionJSONPrompt is BuildMediaSelectionJSONPrompt with the
+// user's fixed picks from a re-run (DDR-176).
+func buildMediaSelectionJSONPrompt(files []*media.MediaFile, tripContext string, ragContext string, scenes []media.Scene, constraints SelectionConstraints) string {
 	var sb strings.Builder
 
 	// Count media types
@@ -38,10 +46,14 @@ func BuildMediaSelectionJSONPrompt(files []*media.MediaFile, tripContext string,
 		sb.WriteString("No context provided. Infer the event type from media and metadata.\n\n")
 	}
 
+	sceneOf := writeSceneHints(&sb, scenes)
+
 	sb.WriteString("### Media Metadata\n\n")
 	sb.WriteString("Below is the metadata for each media item. Media files are provided in the same order.\n\n")
 
-	writeMediaMetadata(&sb, files)
+	writeSceneMediaMetadata(&sb, files, sceneOf)
+
+	writeSelectionConstraints(&sb, files, constraints)
 
 	sb.WriteString("### Output\n\n")
 	sb.WriteString("Respond with ONLY the JSON object as specified in the system instruction. No other text.\n")
@@ -52,3 +64,34 @@ func BuildMediaSelectionJSONPrompt(files []*media.MediaFile, tripContext string,
 	}
 	return prompt
 }
+
+// writeSceneHints writes the precomputed scene summary and returns the 1-based
+// scene number of each grouped file index. Writes nothing for no scenes.
+func writeSceneHints(sb *strings.Builder, scenes []media.Scene) map[int]int {
+	if len(scenes) == 0 {
+		return nil
+	}
+
+	sb.WriteString("### Precomputed Scenes\n\n")
+	sb.WriteString(fmt.Sprintf("Media were grouped into %d scenes from EXIF data: a new scene starts after a capture-time gap of more than %.0f hours or a move of more than %.0f km. ",
+		len(scenes), media.SceneTimeGap.Hours(), media.SceneDistanceKm))
+	sb.WriteString("Start from these groups for sceneGroups. Split a scene only when the visuals clearly show different settings, and merge scenes only when they clearly show the same one. Media not listed have no capture date; place them by their content.\n\n")
+
+	sceneOf := make(map[int]int)
+	for n, s := range scenes {
+		nums := make([]string, len(s.Members))
+		for j, idx := range s.Members {
+			nums[j] = fmt.Sprintf("%d", idx+1)
+			sceneOf[idx] = n + 1
+		}
+		sb.WriteString(fmt.Sprintf("- **Scene %d**: Media %s — %s to %s",
+			n+1, strings.Join(nums, ", "),
+			s.Start.Format("Mon Jan 2, 3:04 PM"), s.End.Format("Mon Jan 2, 3:04 PM")))
+		if s.HasGPS {
+			sb.WriteString(fmt.Sprintf(" — GPS %.5f, %.5f", s.Lat, s.Lon))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	return sceneOf
+}
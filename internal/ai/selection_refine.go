@@ -0,0 +1,150 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genai"
+
+	"github.com/fpang/ai-social-media-helper/internal/artifacts"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+)
+
+// --- Selection feedback (DDR-177) ---
+
+// RefineSelection revises a completed selection from the user's
+// natural-language feedback ("more food shots, fewer selfies"). Gemini
+// works from its previous answer — the descriptions, justifications and
+// exclusion reasons in prior — so the media are not sent again. files[i]
+// is the media numbered i+1 in prior; only its path is used, and a nil
+// entry is a number prior does not cover. history lists earlier feedback
+// on the same selection, oldest first. Locked and excluded picks from
+// WithSelectionConstraints are kept, as on a re-run (DDR-176).
+func RefineSelection(
+	ctx context.Context,
+	client *genai.Client,
+	files []*media.MediaFile,
+	tripContext string,
+	prior *SelectionResult,
+	feedback string,
+	history []string,
+) (*SelectionResult, error) {
+	log.Debug().
+		Int("feedback_length", len(feedback)).
+		Int("history_length", len(history)).
+		Int("media_count", len(files)).
+		Msg("Starting selection refinement with feedback")
+
+	constraints := selectionConstraints(ctx)
+	contents, err := selectionRefineContents(files, tripContext, prior, feedback, history, constraints)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &genai.GenerateContentConfig{
+		SystemInstruction: &genai.Content{
+			Parts: []*genai.Part{{Text: MediaSelectionJSONInstruction}},
+		},
+		ThinkingConfig: thinkingConfig(ctx), // DDR-170
+	}
+	applySampling(ctx, config)
+
+	modelName := modelFor(ctx, GetModelName())
+	artifacts.SaveText(ctx, "selection-feedback-prompt.txt", contents[len(contents)-1].Parts[0].Text)
+	callStart := time.Now()
+	resp, err := client.Models.GenerateContent(ctx, modelName, contents, config)
+	duration := time.Since(callStart)
+	metrics.RecordGeminiCall(ctx, duration)
+
+	m := metrics.New("AiSocialMedia").
+		Dimension("Operation", "selectionFeedback").
+		Metric("GeminiApiLatencyMs", float64(duration.Milliseconds()), metrics.UnitMilliseconds).
+		Count("GeminiApiCalls")
+	if err != nil {
+		m.Count("GeminiApiErrors")
+	}
+	if resp != nil && resp.UsageMetadata != nil {
+		m.Metric("GeminiInputTokens", float64(resp.UsageMetadata.PromptTokenCount), metrics.UnitCount)
+		metrics.RecordGeminiTokens(ctx, int64(resp.UsageMetadata.PromptTokenCount)) // DDR-186
+		m.Metric("GeminiOutputTokens", float64(resp.UsageMetadata.CandidatesTokenCount), metrics.UnitCount)
+	}
+	m.Flush()
+
+	if err != nil {
+		log.Error().Err(err).Dur("duration", duration).Msg("Failed to refine selection from Gemini")
+		return nil, fmt.Errorf("failed to generate content: %w", err)
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("received empty response from Gemini API")
+	}
+
+	responseText := resp.Text()
+	artifacts.SaveText(ctx, "selection-feedback-response.txt", responseText)
+	log.Debug().
+		Int("response_length", len(responseText)).
+		Dur("duration", duration).
+		Msg("Gemini API response received for selection refinement")
+
+	result, err := parseSelectionResponse(ctx, responseText, len(files), geminiJSONRepairer(client, modelName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse refined selection: %w", err)
+	}
+	applySelectionConstraints(result, files, constraints)
+
+	log.Info().
+		Int("selected", len(result.Selected)).
+		Int("excluded", len(result.Excluded)).
+		Dur("duration", duration).
+		Msg("Selection refinement complete")
+	return result, nil
+}
+
+// selectionRefineContents builds the refinement conversation: the media
+// and trip context, the previous selection as Gemini's answer, and the
+// feedback.
+func selectionRefineContents(files []*media.MediaFile, tripContext string, prior *SelectionResult, feedback string, history []string, c SelectionConstraints) ([]*genai.Content, error) {
+	if prior == nil {
+		return nil, fmt.Errorf("refinement requires a previous selection")
+	}
+	answer, err := json.Marshal(prior)
+	if err != nil {
+		return nil, fmt.Errorf("marshal previous selection: %w", err)
+	}
+
+	var intro strings.Builder
+	intro.WriteString("## Media Selection\n\n")
+	if tripContext != "" {
+		intro.WriteString(fmt.Sprintf("Trip context: %s\n\n", tripContext))
+	}
+	intro.WriteString("You reviewed these media and answered with the selection JSON that follows:\n\n")
+	for i, f := range files {
+		if f != nil {
+			intro.WriteString(fmt.Sprintf("- Media %d: %s (%s)\n", i+1, filepath.Base(f.Path), mediaTypeLabel(f.Path)))
+		}
+	}
+
+	var ask strings.Builder
+	if len(history) > 0 {
+		ask.WriteString("Earlier feedback, already reflected in your previous answer:\n")
+		for _, h := range history {
+			ask.WriteString(fmt.Sprintf("- %s\n", h))
+		}
+		ask.WriteString("\n")
+	}
+	ask.WriteString(fmt.Sprintf("Please revise the selection based on this feedback: %s\n\n", feedback))
+	ask.WriteString("Judge the media by your descriptions, justifications and exclusion reasons above. Keep every media number in exactly one of \"selected\" or \"excluded\", re-rank the selected media from 1, and update the scene groups' \"selected\" flags to match.\n\n")
+	writeSelectionConstraints(&ask, files, c)
+	ask.WriteString("Respond with ONLY the updated JSON object in the same format.")
+
+	return []*genai.Content{
+		{Role: "user", Parts: []*genai.Part{{Text: intro.String()}}},
+		{Role: "model", Parts: []*genai.Part{{Text: string(answer)}}},
+		{Role: "user", Parts: []*genai.Part{{Text: ask.String()}}},
+	}, nil
+}
@@ -0,0 +1,46 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+)
+
+func TestSelectionRefineContents(t *testing.T) {
+	files := []*media.MediaFile{{Path: "a.jpg"}, nil, {Path: "c.mp4"}}
+	prior := &SelectionResult{
+		Selected: []SelectedItem{{Rank: 1, Media: 1, Filename: "a.jpg", Justification: "Sunset over the bay"}},
+		Excluded: []ExcludedItem{{Media: 3, Filename: "c.mp4", Reason: "Shaky", Category: "quality-issue"}},
+	}
+	contents, err := selectionRefineContents(files, "Lisbon", prior, "more food shots", []string{"fewer selfies"},
+		SelectionConstraints{Locked: []*media.MediaFile{files[2]}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contents) != 3 || contents[0].Role != "user" || contents[1].Role != "model" || contents[2].Role != "user" {
+		t.Fatalf("contents = %+v, want user, model, user", contents)
+	}
+	intro := contents[0].Parts[0].Text
+	for _, want := range []string{"Trip context: Lisbon", "- Media 1: a.jpg (Photo)", "- Media 3: c.mp4 (Video)"} {
+		if !strings.Contains(intro, want) {
+			t.Errorf("intro lacks %q", want)
+		}
+	}
+	if strings.Contains(intro, "Media 2") {
+		t.Error("intro lists a media number the selection does not cover")
+	}
+	if answer := contents[1].Parts[0].Text; !strings.Contains(answer, "Sunset over the bay") {
+		t.Errorf("model turn = %q, want the previous selection", answer)
+	}
+	ask := contents[2].Parts[0].Text
+	for _, want := range []string{"- fewer selfies", "this feedback: more food shots", "**Locked**: Media 3."} {
+		if !strings.Contains(ask, want) {
+			t.Errorf("feedback prompt lacks %q", want)
+		}
+	}
+
+	if _, err := selectionRefineContents(files, "", nil, "more food", nil, SelectionConstraints{}); err == nil {
+		t.Error("refining without a previous selection succeeded")
+	}
+}
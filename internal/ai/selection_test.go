@@ -0,0 +1,32 @@
+package ai
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseSelectionResponseDropsInvalidScores(t *testing.T) {
+	response := `{"selected": [
+		{"rank": 1, "media": 1, "scores": {"composition": 8, "storyValue": 9, "uniqueness": 6, "technicalQuality": 7}},
+		{"rank": 2, "media": 2, "scores": {"composition": 11, "storyValue": 9, "uniqueness": 6, "technicalQuality": 7}},
+		{"rank": 3, "media": 3, "scores": {"composition": 8, "storyValue": 9}},
+		{"rank": 4, "media": 4}
+	]}`
+	result, err := parseSelectionResponse(context.Background(), response, 4, nil)
+	if err != nil {
+		t.Fatalf("parseSelectionResponse: %v", err)
+	}
+	if len(result.Selected) != 4 {
+		t.Fatalf("selected = %+v, want all four items kept", result.Selected)
+	}
+	if got := result.Selected[0].Scores; got == nil || *got != (SelectionScores{Composition: 8, StoryValue: 9, Uniqueness: 6, TechnicalQuality: 7}) {
+		t.Errorf("valid scores = %+v", got)
+	}
+	// An out-of-range score and a partial breakdown are dropped; the item
+	// stays selected.
+	for _, i := range []int{1, 2, 3} {
+		if got := result.Selected[i].Scores; got != nil {
+			t.Errorf("media %d scores = %+v, want nil", result.Selected[i].Media, got)
+		}
+	}
+}
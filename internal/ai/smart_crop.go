@@ -0,0 +1,83 @@
+package ai
+
+// smart_crop.go asks Gemini where the subject of a photo is, so crop
+// suggestions can keep it in frame. See DDR-130: Subject-Aware Crop Suggestions.
+
+import (
+	"context"
+	"fmt"
+	"image"
+
+	"github.com/fpang/ai-social-media-helper/internal/jsonutil"
+	"github.com/rs/zerolog/log"
+)
+
+// CropSubject is a region Gemini identified as part of the photo's subject.
+// Box uses Gemini's native detection format: [ymin, xmin, ymax, xmax]
+// normalized to 0–1000.
+type CropSubject struct {
+	Label string `json:"label"`
+	Box   [4]int `json:"box_2d"`
+}
+
+type cropSubjectResponse struct {
+	Subjects []CropSubject `json:"subjects"`
+}
+
+const cropSubjectSystemInstruction = `You help crop travel photos for social media.
+Identify the subject a viewer should see when the photo is cropped: people, faces, a landmark, an animal, or the main object.
+Return only JSON: {"subjects": [{"label": "short name", "box_2d": [ymin, xmin, ymax, xmax]}]}
+Coordinates are normalized to 0-1000. List at most 5 subjects, most important first.
+If the photo has no clear subject (an open landscape or texture), return {"subjects": []}.`
+
+const cropSubjectPrompt = "Detect the subject of this photo for cropping. Follow the response format in the system instruction exactly."
+
+// DetectCropSubjects returns the subject regions of a photo. An empty result
+// means the photo has no clear subject and crops should be centred.
+func DetectCropSubjects(ctx context.Context, client *GeminiImageClient, imageData []byte, imageMIMEType string) ([]CropSubject, error) {
+	log.Debug().Int("image_bytes", len(imageData)).Msg("DetectCropSubjects: starting")
+
+	text, err := client.AnalyzeImage(ctx, imageData, imageMIMEType, cropSubjectPrompt, cropSubjectSystemInstruction)
+	if err != nil {
+		return nil, fmt.Errorf("detect crop subjects: %w", err)
+	}
+	subjects, err := parseCropSubjectResponse(text)
+	if err != nil {
+		log.Warn().Err(err).Str("response", truncateString(text, 500)).Msg("Failed to parse crop subject response")
+		return nil, err
+	}
+	log.Debug().Int("subjects", len(subjects)).Msg("DetectCropSubjects: complete")
+	return subjects, nil
+}
+
+// parseCropSubjectResponse extracts subjects from Gemini's response, dropping
+// boxes that are empty or outside the 0–1000 range.
+func parseCropSubjectResponse(response string) ([]CropSubject, error) {
+	result, err := jsonutil.ParseJSON[cropSubjectResponse](response)
+	if err != nil {
+		return nil, fmt.Errorf("crop subject response: %w", err)
+	}
+	subjects := make([]CropSubject, 0, len(result.Subjects))
+	for _, s := range result.Subjects {
+		ymin, xmin, ymax, xmax := s.Box[0], s.Box[1], s.Box[2], s.Box[3]
+		if ymin < 0 || xmin < 0 || ymax > 1000 || xmax > 1000 || ymin >= ymax || xmin >= xmax {
+			continue
+		}
+		subjects = append(subjects, s)
+	}
+	return subjects, nil
+}
+
+// SubjectBounds scales the union of the subjects' boxes to a width x height
+// image. Returns an empty rectangle when there are no subjects.
+func SubjectBounds(subjects []CropSubject, width, height int) image.Rectangle {
+	var bounds image.Rectangle
+	for _, s := range subjects {
+		r := image.Rect(
+			s.Box[1]*width/1000, s.Box[0]*height/1000,
+			s.Box[3]*width/1000, s.Box[2]*height/1000,
+		)
+		bounds = bounds.Union(r)
+	}
+	return bounds
+}
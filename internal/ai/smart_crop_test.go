@@ -0,0 +1,32 @@
+package ai
+
+import (
+	"image"
+	"testing"
+)
+
+func TestParseCropSubjectResponse(t *testing.T) {
+	response := "```json\n" + `{"subjects": [
+		{"label": "woman", "box_2d": [100, 200, 900, 500]},
+		{"label": "inverted", "box_2d": [500, 500, 400, 600]},
+		{"label": "out of range", "box_2d": [0, 0, 1200, 1000]},
+		{"label": "tower", "box_2d": [50, 600, 700, 800]}
+	]}` + "\n```"
+
+	subjects, err := parseCropSubjectResponse(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subjects) != 2 || subjects[0].Label != "woman" || subjects[1].Label != "tower" {
+		t.Fatalf("unexpected subjects: %+v", subjects)
+	}
+
+	got := SubjectBounds(subjects, 4000, 3000)
+	want := image.Rect(800, 150, 3200, 2700)
+	if got != want {
+		t.Errorf("SubjectBounds() = %v, want %v", got, want)
+	}
+	if b := SubjectBounds(nil, 4000, 3000); !b.Empty() {
+		t.Errorf("SubjectBounds(nil) = %v, want empty", b)
+	}
+}
@@ -0,0 +1,138 @@
+package ai
+
+// thinking.go resolves the Gemini thinking setting of triage and selection
+// calls, and of caption and enhancement analysis calls through
+// ApplyModelConfig. See DDR-155: Per-Job Gemini Thinking Settings, and
+// DDR-170: Per-Job Model Settings.
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genai"
+)
+
+// Thinking settings. A level suits Gemini 3 models; "off", "dynamic" or a
+// token budget suits Gemini 2.5 models. Which levels a model accepts is up
+// to the model: Gemini 3 Pro, for one, takes only "low" and "high".
+//
+//	""         the model's default
+//	"minimal", "low", "medium", "high"
+//	"off"      no thinking (budget 0)
+//	"dynamic"  the model sizes its own budget (budget -1)
+//	"1".."32768" a thinking budget in tokens
+const (
+	ThinkingMinimal = "minimal"
+	ThinkingLow     = "low"
+	ThinkingMedium  = "medium"
+	ThinkingHigh    = "high"
+	ThinkingOff     = "off"
+	ThinkingDynamic = "dynamic"
+
+	// MaxThinkingBudget is the largest token budget any Gemini model takes.
+	MaxThinkingBudget = 32768
+)
+
+var thinkingLevels = map[string]genai.ThinkingLevel{
+	ThinkingMinimal: genai.ThinkingLevelMinimal,
+	ThinkingLow:     genai.ThinkingLevelLow,
+	ThinkingMedium:  genai.ThinkingLevelMedium,
+	ThinkingHigh:    genai.ThinkingLevelHigh,
+}
+
+// GetThinking returns the deployment's default thinking setting from the
+// GEMINI_THINKING environment variable; empty leaves it to the model.
+func GetThinking() string {
+	return os.Getenv("GEMINI_THINKING")
+}
+
+// NormalizeThinking validates a thinking setting and returns its canonical
+// form, the one recorded on jobs: lower case, budgets without leading zeros.
+func NormalizeThinking(setting string) (string, error) {
+	setting = strings.ToLower(strings.TrimSpace(setting))
+	if setting == "" || setting == ThinkingOff || setting == ThinkingDynamic {
+		return setting, nil
+	}
+	if _, ok := thinkingLevels[setting]; ok {
+		return setting, nil
+	}
+	budget, err := strconv.Atoi(setting)
+	if err != nil {
+		return "", fmt.Errorf("thinking must be minimal, low, medium, high, off, dynamic or a token budget, got %q", setting)
+	}
+	switch {
+	case budget == 0:
+		return ThinkingOff, nil
+	case budget == -1:
+		return ThinkingDynamic, nil
+	case budget < 1 || budget > MaxThinkingBudget:
+		return "", fmt.Errorf("thinking budget must be between 1 and %d tokens, got %d", MaxThinkingBudget, budget)
+	}
+	return strconv.Itoa(budget), nil
+}
+
+// ParseThinking returns the Gemini thinking configuration of a setting, or
+// nil for the model's default.
+func ParseThinking(setting string) (*genai.ThinkingConfig, error) {
+	setting, err := NormalizeThinking(setting)
+	if err != nil || setting == "" {
+		return nil, err
+	}
+	if level, ok := thinkingLevels[setting]; ok {
+		return &genai.ThinkingConfig{ThinkingLevel: level}, nil
+	}
+	var budget int
+	switch setting {
+	case ThinkingOff:
+		budget = 0
+	case ThinkingDynamic:
+		budget = -1
+	default:
+		budget, _ = strconv.Atoi(setting)
+	}
+	return &genai.ThinkingConfig{ThinkingBudget: genai.Ptr(int32(budget))}, nil
+}
+
+type thinkingKey struct{}
+
+// WithThinking applies a thinking setting to the triage and selection calls
+// made with the returned context. An empty setting keeps the model's default.
+func WithThinking(ctx context.Context, setting string) (context.Context, error) {
+	cfg, err := ParseThinking(setting)
+	if err != nil || cfg == nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, thinkingKey{}, cfg), nil
+}
+
+// ApplyThinking applies a job's thinking setting to ctx, or the
+// deployment's GEMINI_THINKING when the job has none, and returns the
+// setting used in canonical form. An invalid setting is logged and the
+// model's default used instead.
+func ApplyThinking(ctx context.Context, setting string) (context.Context, string) {
+	if setting == "" {
+		setting = GetThinking()
+	}
+	setting, err := NormalizeThinking(setting)
+	if err != nil {
+		log.Warn().Err(err).Msg("Ignoring invalid thinking setting, using the model's default")
+		return ctx, ""
+	}
+	ctx, _ = WithThinking(ctx, setting) // a normalized setting always parses
+	return ctx, setting
+}
+
+// thinkingConfig returns a copy of the context's thinking configuration, or
+// nil when none was set.
+func thinkingConfig(ctx context.Context) *genai.ThinkingConfig {
+	cfg, ok := ctx.Value(thinkingKey{}).(*genai.ThinkingConfig)
+	if !ok {
+		return nil
+	}
+	c := *cfg
+	return &c
+}
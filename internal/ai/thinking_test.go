@@ -0,0 +1,84 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestNormalizeThinking(t *testing.T) {
+	for in, want := range map[string]string{
+		"":        "",
+		" LOW ":   "low",
+		"minimal": "minimal",
+		"0":       "off",
+		"-1":      "dynamic",
+		"Dynamic": "dynamic",
+		"02048":   "2048",
+	} {
+		got, err := NormalizeThinking(in)
+		if err != nil || got != want {
+			t.Errorf("NormalizeThinking(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"max", "-2", "40000", "1.5"} {
+		if _, err := NormalizeThinking(in); err == nil {
+			t.Errorf("NormalizeThinking(%q) should fail", in)
+		}
+	}
+}
+
+func TestParseThinking(t *testing.T) {
+	cfg, err := ParseThinking("high")
+	if err != nil || cfg.ThinkingLevel != genai.ThinkingLevelHigh || cfg.ThinkingBudget != nil {
+		t.Errorf("ParseThinking(high) = %+v, %v", cfg, err)
+	}
+	cfg, err = ParseThinking("off")
+	if err != nil || cfg.ThinkingBudget == nil || *cfg.ThinkingBudget != 0 || cfg.ThinkingLevel != "" {
+		t.Errorf("ParseThinking(off) = %+v, %v", cfg, err)
+	}
+	cfg, err = ParseThinking("1024")
+	if err != nil || cfg.ThinkingBudget == nil || *cfg.ThinkingBudget != 1024 {
+		t.Errorf("ParseThinking(1024) = %+v, %v", cfg, err)
+	}
+	if cfg, err := ParseThinking(""); cfg != nil || err != nil {
+		t.Errorf("ParseThinking(\"\") = %+v, %v; want the model default", cfg, err)
+	}
+}
+
+func TestWithThinking(t *testing.T) {
+	if thinkingConfig(context.Background()) != nil {
+		t.Error("a plain context should carry no thinking configuration")
+	}
+	ctx, err := WithThinking(context.Background(), "low")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := thinkingConfig(ctx)
+	if cfg == nil || cfg.ThinkingLevel != genai.ThinkingLevelLow {
+		t.Fatalf("thinkingConfig = %+v", cfg)
+	}
+	cfg.ThinkingLevel = genai.ThinkingLevelHigh
+	if thinkingConfig(ctx).ThinkingLevel != genai.ThinkingLevelLow {
+		t.Error("thinkingConfig should return a copy")
+	}
+	if _, err := WithThinking(context.Background(), "loud"); err == nil {
+		t.Error("WithThinking should reject an unknown setting")
+	}
+}
+
+func TestApplyThinking(t *testing.T) {
+	t.Setenv("GEMINI_THINKING", "2048")
+	ctx, setting := ApplyThinking(context.Background(), "")
+	if setting != "2048" || thinkingConfig(ctx) == nil {
+		t.Errorf("deployment default: setting %q, config %+v", setting, thinkingConfig(ctx))
+	}
+	if _, setting := ApplyThinking(context.Background(), "HIGH"); setting != "high" {
+		t.Errorf("job override: setting %q, want high", setting)
+	}
+	ctx, setting = ApplyThinking(context.Background(), "loud")
+	if setting != "" || thinkingConfig(ctx) != nil {
+		t.Errorf("invalid setting should fall back to the model default, got %q", setting)
+	}
+}
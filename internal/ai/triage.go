@@ -12,9 +12,9 @@ import (
 
 	"time"
 
+	"github.com/fpang/ai-social-media-helper/internal/artifacts"
 	"github.com/fpang/ai-social-media-helper/internal/assets"
 	"github.com/fpang/ai-social-media-helper/internal/media"
-	"github.com/fpang/ai-social-media-helper/internal/jsonutil"
 	"github.com/fpang/ai-social-media-helper/internal/metrics"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/genai"
@@ -26,21 +26,30 @@ type TriageResult struct {
 	Filename string `json:"filename"`
 	Saveable bool   `json:"saveable"`
 	Reason   string `json:"reason"`
+	// PreFiltered is set when the local quality check discarded the file
+	// without asking Gemini (DDR-112).
+	PreFiltered bool `json:"preFiltered,omitempty"`
+	// Sampled is set when Gemini judged a long video from sampled frames
+	// and an audio summary instead of the whole clip (DDR-162).
+	Sampled bool `json:"sampled,omitempty"`
+	// model is the model that answered, kept with a cached verdict (DDR-166).
+	model string
 }
 
 // BuildMediaTriagePrompt creates a prompt asking Gemini to evaluate each media item
 // for saveability. Media metadata is included so Gemini can reference items by number.
-func BuildMediaTriagePrompt(files []*media.MediaFile, ragContext string) string {
+// samples holds the long videos sent as frame sheets (DDR-162) and may be nil.
+func BuildMediaTriagePrompt(files []*media.MediaFile, ragContext string, samples triageSamples) string {
 	var sb strings.Builder
 
 	// Count media types
 	var imageCount, videoCount int
 	for _, file := range files {
 		ext := strings.ToLower(filepath.Ext(file.Path))
-		if media.IsImage(ext) {
-			imageCount++
-		} else if media.IsVideo(ext) {
+		if media.IsVideo(ext) || isAnimatedImage(file) {
 			videoCount++
+		} else if media.IsImage(ext) {
+			imageCount++
 		}
 	}
 
@@ -60,7 +69,7 @@ func BuildMediaTriagePrompt(files []*media.MediaFile, ragContext string) string
 	for i, file := range files {
 		ext := strings.ToLower(filepath.Ext(file.Path))
 		mediaType := "Photo"
-		if media.IsVideo(ext) {
+		if media.IsVideo(ext) || isAnimatedImage(file) {
 			mediaType = "Video"
 		}
 
@@ -68,8 +77,7 @@ func BuildMediaTriagePrompt(files []*media.MediaFile, ragContext string) string
 
 		if file.Metadata != nil {
 			if file.Metadata.HasDateData() {
-				date := file.Metadata.GetDate()
-				sb.WriteString(fmt.Sprintf("- Date: %s\n", date.Format("Monday, January 2, 2006 at 3:04 PM")))
+				sb.WriteString(fmt.Sprintf("- Date: %s\n", media.FormatCaptureTime(file.Metadata)))
 			}
 
 			// Add type-specific metadata
@@ -78,6 +86,7 @@ func BuildMediaTriagePrompt(files []*media.MediaFile, ragContext string) string
 				if m.CameraMake != "" || m.CameraModel != "" {
 					sb.WriteString(fmt.Sprintf("- Camera: %s %s\n", m.CameraMake, m.CameraModel))
 				}
+				writeAnimationLine(&sb, m)
 			case *media.VideoMetadata:
 				if m.Duration > 0 {
 					sb.WriteString(fmt.Sprintf("- Duration: %s\n", formatVideoDuration(m.Duration)))
@@ -89,6 +98,9 @@ func BuildMediaTriagePrompt(files []*media.MediaFile, ragContext string) string
 		} else {
 			sb.WriteString("- No metadata available\n")
 		}
+		if s := samples[file]; s != nil {
+			writeSampleLines(&sb, s)
+		}
 		sb.WriteString("\n")
 	}
 
@@ -103,10 +115,22 @@ func BuildMediaTriagePrompt(files []*media.MediaFile, ragContext string) string
 	return prompt
 }
 
-// triageBatchSize is the maximum number of media items to send in a single
-// Gemini API call. Large batches cause the model to silently drop items from
-// its response — batching into smaller groups ensures every item gets a verdict.
-const triageBatchSize = 20
+// isAnimatedImage reports whether file is an animated GIF/WebP, which triage
+// presents to Gemini as a short video (DDR-120).
+func isAnimatedImage(file *media.MediaFile) bool {
+	m, ok := file.Metadata.(*media.ImageMetadata)
+	return ok && m.IsAnimated()
+}
+
+// writeAnimationLine describes an animated image's length and explains the
+// frame sheet Gemini receives in place of the animation.
+func writeAnimationLine(sb *strings.Builder, m *media.ImageMetadata) {
+	if !m.IsAnimated() {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("- Duration: %.1fs (animated image, %d frames; shown as a sheet of frames in playback order, left to right then top to bottom)\n",
+		m.AnimationDuration.Seconds(), m.FrameCount))
+}
 
 // maxPresignedURLBytes is the maximum file size that can be referenced via an
 // S3 presigned URL in the Gemini FileData.FileURI field. The Gemini API returns
@@ -124,9 +148,11 @@ type TriageOutput struct {
 }
 
 // AskMediaTriage sends media files to Gemini for triage evaluation.
-// When len(files) > triageBatchSize the work is split into smaller batches
-// so the model reliably covers every item; results are merged and Media
-// indices adjusted to the caller's original file positions.
+// When len(files) > TriageBatchSize() the work is split into smaller
+// batches so the model reliably covers every item. Batches run in parallel,
+// paced by TriageConcurrency() and TriageRequestsPerMinute(); results are
+// merged and Media indices adjusted to the caller's original file positions
+// (DDR-167).
 //
 // When economyMode is true, submits to Gemini Batch API and returns
 // TriageOutput{BatchJobID: jobName}. Results is nil. The caller should
@@ -140,71 +166,59 @@ type TriageOutput struct {
 // Returns a slice of TriageResult with one verdict per media item (or BatchJobID when economyMode).
 // See DDR-021: Media Triage Command with Batch AI Evaluation.
 // progressFn is called after each batch completes when batching; pass nil to disable.
+//
+// Near-duplicate photos are clustered by perceptual hash first and only one
+// per cluster is sent; the others inherit its verdict (DDR-110). Photos that
+// fail the local quality check are discarded without a Gemini call and
+// marked PreFiltered (DDR-112). Economy mode sends every file because its
+// results are mapped back by the batch poller.
+//
+// Files already judged with the same content, prompt, model, thinking
+// setting and RAG context are answered from the context's response cache,
+// if any, without a Gemini call (DDR-166).
+//
+// A context from WithFileProgress is told as each file is sent and judged
+// (DDR-174).
 func AskMediaTriage(ctx context.Context, client *genai.Client, files []*media.MediaFile, modelName string, sessionID string, storeCompressed CompressedVideoStore, keyMapper KeyMapper, cacheMgr *CacheManager, ragContext string, economyMode bool, progressFn BatchProgressFunc) (*TriageOutput, error) {
+	sampleKeyframes(ctx, files) // DDR-186
 	if economyMode {
 		return askMediaTriageEconomy(ctx, client, files, modelName, sessionID, storeCompressed, keyMapper, ragContext)
 	}
-	if len(files) <= triageBatchSize {
-		results, err := askMediaTriageSingle(ctx, client, files, modelName, sessionID, storeCompressed, keyMapper, cacheMgr, ragContext)
-		if err != nil {
-			return nil, err
-		}
-		return &TriageOutput{Results: results}, nil
-	}
-
-	totalBatches := (len(files) + triageBatchSize - 1) / triageBatchSize
-	log.Info().
-		Int("total_files", len(files)).
-		Int("batch_size", triageBatchSize).
-		Int("total_batches", totalBatches).
-		Msg("Batching media triage — too many files for a single request")
-
-	var allResults []TriageResult
-
-	for batchStart := 0; batchStart < len(files); batchStart += triageBatchSize {
-		batchEnd := batchStart + triageBatchSize
-		if batchEnd > len(files) {
-			batchEnd = len(files)
-		}
-		batch := files[batchStart:batchEnd]
-		batchNum := (batchStart / triageBatchSize) + 1
 
+	clusters := clusterTriageFiles(files)
+	sendFiles := clusters.representatives()
+	if skipped := len(files) - len(sendFiles); skipped > 0 {
 		log.Info().
-			Int("batch", batchNum).
-			Int("total_batches", totalBatches).
-			Int("batch_size", len(batch)).
-			Int("offset", batchStart).
-			Msg("Processing triage batch")
-
-		batchResults, err := askMediaTriageSingle(ctx, client, batch, modelName, sessionID, storeCompressed, keyMapper, cacheMgr, ragContext)
-		if err != nil {
-			log.Error().Err(err).Int("batch", batchNum).Msg("Batch triage failed")
-			return nil, fmt.Errorf("batch %d/%d triage failed: %w", batchNum, totalBatches, err)
-		}
-
-		if progressFn != nil {
-			progressFn(batchNum, totalBatches)
-		}
+			Int("total_files", len(files)).
+			Int("pre_filtered", clusters.preFiltered()).
+			Int("near_duplicates", skipped-clusters.preFiltered()).
+			Msg("Skipping Gemini for pre-filtered photos and near-duplicates")
+	}
 
-		// Adjust Media indices from batch-local (1-based) to global (1-based).
-		for i := range batchResults {
-			batchResults[i].Media += batchStart
-		}
-		allResults = append(allResults, batchResults...)
+	// DDR-174: pre-filtered photos are done; near-duplicates follow their
+	// representative.
+	preFiltered, verdicts := clusters.preFilteredFiles()
+	reportFileProgress(ctx, preFiltered, FileAnalyzed, verdicts)
+	ctx = withFileProgressMapped(ctx, clusters.withMembers)
 
-		log.Info().
-			Int("batch", batchNum).
-			Int("batch_results", len(batchResults)).
-			Int("total_so_far", len(allResults)).
-			Msg("Batch triage complete")
+	if len(sendFiles) == 0 {
+		return &TriageOutput{Results: clusters.expand(nil)}, nil
 	}
+	results, err := askMediaTriageCached(ctx, sendFiles, modelName, ragContext, func(files []*media.MediaFile) ([]TriageResult, error) {
+		return askMediaTriageBatched(ctx, client, files, modelName, sessionID, storeCompressed, keyMapper, cacheMgr, ragContext, progressFn)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &TriageOutput{Results: clusters.expand(results)}, nil
+}
 
-	log.Info().
-		Int("total_results", len(allResults)).
-		Int("total_files", len(files)).
-		Msg("All triage batches complete")
-
-	return &TriageOutput{Results: allResults}, nil
+// askMediaTriageBatched splits files into batches and returns the merged
+// results with Media indices relative to files (DDR-167).
+func askMediaTriageBatched(ctx context.Context, client *genai.Client, files []*media.MediaFile, modelName string, sessionID string, storeCompressed CompressedVideoStore, keyMapper KeyMapper, cacheMgr *CacheManager, ragContext string, progressFn BatchProgressFunc) ([]TriageResult, error) {
+	return runTriageBatches(ctx, files, defaultTriageBatchPlan(), func(ctx context.Context, batch int, files []*media.MediaFile) ([]TriageResult, error) {
+		return askMediaTriageSingle(ctx, client, files, modelName, sessionID, storeCompressed, keyMapper, cacheMgr, batch, ragContext)
+	}, progressFn)
 }
 
 // askMediaTriageEconomy builds the same prompt/parts as askMediaTriageSingle,
@@ -212,15 +226,16 @@ func AskMediaTriage(ctx context.Context, client *genai.Client, files []*media.Me
 func askMediaTriageEconomy(ctx context.Context, client *genai.Client, files []*media.MediaFile, modelName string, sessionID string, storeCompressed CompressedVideoStore, keyMapper KeyMapper, ragContext string) (*TriageOutput, error) {
 	var allRequests []*genai.InlinedRequest
 
-	for batchStart := 0; batchStart < len(files); batchStart += triageBatchSize {
-		batchEnd := batchStart + triageBatchSize
-		if batchEnd > len(files) {
-			batchEnd = len(files)
+	starts := splitByBudget(files, TriageBatchSize(), defaultPayloadBudget(), estimateTriagePayload) // DDR-168
+	for b, batchStart := range starts {
+		batchEnd := len(files)
+		if b+1 < len(starts) {
+			batchEnd = starts[b+1]
 		}
 		batch := files[batchStart:batchEnd]
 		req, err := buildTriageBatchRequest(ctx, client, batch, modelName, sessionID, storeCompressed, keyMapper, ragContext)
 		if err != nil {
-			return nil, fmt.Errorf("batch %d: %w", batchStart/triageBatchSize+1, err)
+			return nil, fmt.Errorf("batch %d: %w", b+1, err)
 		}
 		allRequests = append(allRequests, req)
 	}
@@ -248,18 +263,27 @@ func buildTriageBatchRequest(ctx context.Context, client *genai.Client, files []
 		}
 	}()
 
-	prompt := BuildMediaTriagePrompt(files, ragContext)
+	samples := sampleLongVideos(files)
+	prompt := BuildMediaTriagePrompt(files, ragContext, samples)
 	config := &genai.GenerateContentConfig{
 		SystemInstruction: &genai.Content{
 			Parts: []*genai.Part{{Text: assets.TriageSystemPrompt}},
 		},
 		MaxOutputTokens:  65536,
 		MediaResolution: genai.MediaResolutionLow,
+		ThinkingConfig:  thinkingConfig(ctx), // DDR-155
 	}
+	applySampling(ctx, config) // DDR-170
 
 	var parts []*genai.Part
 	for _, file := range files {
 		ext := strings.ToLower(filepath.Ext(file.Path))
+		if s := samples[file]; s != nil {
+			parts = append(parts, &genai.Part{
+				InlineData: &genai.Blob{MIMEType: "image/jpeg", Data: s.Sheet},
+			})
+			continue
+		}
 		if media.IsImage(ext) {
 			if file.PresignedURL != "" {
 				imgData, err := downloadToBytes(ctx, file.PresignedURL)
@@ -316,6 +340,7 @@ func buildTriageBatchRequest(ctx context.Context, client *genai.Client, files []
 					continue
 				}
 				cleanupFuncs = append(cleanupFuncs, cleanup)
+				artifacts.SaveFile(ctx, "compressed/"+filepath.Base(file.Path)+".webm", compressedPath)
 				originalKey := file.Path
 				if keyMapper != nil {
 					if s3Key := keyMapper(file.Path); s3Key != "" {
@@ -341,7 +366,12 @@ func buildTriageBatchRequest(ctx context.Context, client *genai.Client, files []
 	if len(parts) == 0 {
 		return nil, fmt.Errorf("no media files could be processed for triage (all %d files skipped)", len(files))
 	}
+	// DDR-168: shrink inline images rather than exceed the request size limit.
+	if err := fitInlineParts(parts, maxInlineRequestBytes); err != nil {
+		return nil, err
+	}
 
+	artifacts.SaveText(ctx, "triage-batch-prompt.txt", prompt)
 	parts = append(parts, &genai.Part{Text: prompt})
 	contents := []*genai.Content{{Role: "user", Parts: parts}}
 
@@ -352,17 +382,18 @@ func buildTriageBatchRequest(ctx context.Context, client *genai.Client, files []
 }
 
 // askMediaTriageSingle sends a single batch of media files to Gemini for
-// triage evaluation. Callers should prefer AskMediaTriage which handles
-// batching automatically.
-func askMediaTriageSingle(ctx context.Context, client *genai.Client, files []*media.MediaFile, modelName string, sessionID string, storeCompressed CompressedVideoStore, keyMapper KeyMapper, cacheMgr *CacheManager, ragContext string) ([]TriageResult, error) {
+// triage evaluation. batch is its 1-based number, or 0 for the single-call
+// path, and names its context cache and debug artifacts. Callers should prefer AskMediaTriage which
+// handles batching automatically.
+func askMediaTriageSingle(ctx context.Context, client *genai.Client, files []*media.MediaFile, modelName string, sessionID string, storeCompressed CompressedVideoStore, keyMapper KeyMapper, cacheMgr *CacheManager, batch int, ragContext string) ([]TriageResult, error) {
 	// Count media types for logging
 	var imageCount, videoCount int
 	for _, file := range files {
 		ext := strings.ToLower(filepath.Ext(file.Path))
-		if media.IsImage(ext) {
-			imageCount++
-		} else if media.IsVideo(ext) {
+		if media.IsVideo(ext) || isAnimatedImage(file) {
 			videoCount++
+		} else if media.IsImage(ext) {
+			imageCount++
 		}
 	}
 
@@ -391,8 +422,9 @@ func askMediaTriageSingle(ctx context.Context, client *genai.Client, files []*me
 		}
 	}()
 
-	// Build the prompt with metadata
-	prompt := BuildMediaTriagePrompt(files, ragContext)
+	// Build the prompt with metadata; long videos are sampled (DDR-162)
+	samples := sampleLongVideos(files)
+	prompt := BuildMediaTriagePrompt(files, ragContext, samples)
 
 	// Configure model with triage system instruction
 	// MaxOutputTokens must be set high enough for large batches — each media item
@@ -403,7 +435,9 @@ func askMediaTriageSingle(ctx context.Context, client *genai.Client, files []*me
 		},
 		MaxOutputTokens:  65536,
 		MediaResolution: genai.MediaResolutionLow,
+		ThinkingConfig:  thinkingConfig(ctx), // DDR-155
 	}
+	applySampling(ctx, config) // DDR-170
 
 	// Build parts: media files then prompt (no reference photo for triage)
 	var parts []*genai.Part
@@ -414,6 +448,18 @@ func askMediaTriageSingle(ctx context.Context, client *genai.Client, files []*me
 	for i, file := range files {
 		ext := strings.ToLower(filepath.Ext(file.Path))
 
+		if s := samples[file]; s != nil {
+			log.Debug().
+				Int("index", i+1).
+				Str("file", filepath.Base(file.Path)).
+				Int("sheet_bytes", len(s.Sheet)).
+				Msg("Sending sample sheet in place of long video")
+			parts = append(parts, &genai.Part{
+				InlineData: &genai.Blob{MIMEType: "image/jpeg", Data: s.Sheet},
+			})
+			continue
+		}
+
 		if media.IsImage(ext) {
 			if file.PresignedURL != "" {
 				// Cloud mode: download thumbnail from presigned URL and pass as
@@ -530,6 +576,7 @@ func askMediaTriageSingle(ctx context.Context, client *genai.Client, files []*me
 					continue
 				}
 				cleanupFuncs = append(cleanupFuncs, cleanup)
+				artifacts.SaveFile(ctx, "compressed/"+filepath.Base(file.Path)+".webm", compressedPath)
 
 				log.Info().
 					Str("file", filepath.Base(file.Path)).
@@ -589,6 +636,10 @@ func askMediaTriageSingle(ctx context.Context, client *genai.Client, files []*me
 	if len(parts) == 0 {
 		return nil, fmt.Errorf("no media files could be processed for triage (all %d files skipped)", len(files))
 	}
+	// DDR-168: shrink inline images rather than exceed the request size limit.
+	if err := fitInlineParts(parts, maxInlineRequestBytes); err != nil {
+		return nil, err
+	}
 
 	log.Info().
 		Int("media_parts", len(parts)).
@@ -600,10 +651,13 @@ func askMediaTriageSingle(ctx context.Context, client *genai.Client, files []*me
 
 	systemInstruction := config.SystemInstruction
 
+	artifacts.SaveText(ctx, triageArtifact(batch, "prompt"), prompt)
+
 	var streamedText string
 	geminiStart := time.Now()
 	var resp *genai.GenerateContentResponse
 	var err error
+	genCtx, answered := withCallModelRecorder(ctx) // DDR-169: which model answered this batch
 
 	if cacheMgr != nil && sessionID != "" {
 		// DDR-065: Use context caching for triage system instruction + media.
@@ -616,12 +670,13 @@ func askMediaTriageSingle(ctx context.Context, client *genai.Client, files []*me
 			Int("media_parts", len(mediaParts)).
 			Msg("Starting cached Gemini API call for media triage")
 
-		resp, err = cacheMgr.GenerateWithCache(ctx, CacheConfig{
+		resp, err = cacheMgr.GenerateWithCache(genCtx, CacheConfig{
 			SessionID: sessionID,
-			Operation: "triage",
+			Operation: triageCacheOperation(batch),
 		}, modelName, systemInstruction, cacheContents, userParts, &genai.GenerateContentConfig{
 			MaxOutputTokens: config.MaxOutputTokens,
 			MediaResolution: genai.MediaResolutionLow,
+			ThinkingConfig:  config.ThinkingConfig,
 		})
 	} else {
 		parts = append(parts, &genai.Part{Text: prompt})
@@ -634,7 +689,7 @@ func askMediaTriageSingle(ctx context.Context, client *genai.Client, files []*me
 			Msg("Starting streaming Gemini API call for media triage")
 
 		var accumulated strings.Builder
-		for streamResp, streamErr := range client.Models.GenerateContentStream(ctx, modelName, contents, config) {
+		for streamResp, streamErr := range client.Models.GenerateContentStream(genCtx, modelName, contents, config) {
 			if streamErr != nil {
 				err = streamErr
 				break
@@ -646,6 +701,7 @@ func askMediaTriageSingle(ctx context.Context, client *genai.Client, files []*me
 	}
 
 	geminiElapsed := time.Since(geminiStart)
+	metrics.RecordGeminiCall(ctx, geminiElapsed)
 
 	// Emit Gemini API metrics
 	m := metrics.New("AiSocialMedia").
@@ -657,6 +713,7 @@ func askMediaTriageSingle(ctx context.Context, client *genai.Client, files []*me
 	}
 	if resp != nil && resp.UsageMetadata != nil {
 		m.Metric("GeminiInputTokens", float64(resp.UsageMetadata.PromptTokenCount), metrics.UnitCount)
+		metrics.RecordGeminiTokens(ctx, int64(resp.UsageMetadata.PromptTokenCount)) // DDR-186
 		m.Metric("GeminiOutputTokens", float64(resp.UsageMetadata.CandidatesTokenCount), metrics.UnitCount)
 	}
 	m.Flush()
@@ -670,6 +727,7 @@ func askMediaTriageSingle(ctx context.Context, client *genai.Client, files []*me
 	if responseText == "" && resp != nil {
 		responseText = resp.Text()
 	}
+	artifacts.SaveText(ctx, triageArtifact(batch, "response"), responseText)
 
 	if responseText == "" {
 		log.Warn().Dur("duration", geminiElapsed).Msg("Received empty response from Gemini")
@@ -681,11 +739,17 @@ func askMediaTriageSingle(ctx context.Context, client *genai.Client, files []*me
 		Dur("duration", geminiElapsed).
 		Msg("Gemini API response received for media triage")
 
-	// Parse JSON response
-	results, err := parseTriageResponse(responseText)
+	// Parse JSON response, repairing it if needed (DDR-165)
+	results, err := parseTriageResponse(ctx, responseText, len(files), geminiJSONRepairer(client, modelName))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse triage response: %w", err)
 	}
+	markSampled(results, files, samples)
+	if models := answered.Models(); len(models) > 0 {
+		for i := range results {
+			results[i].model = models[0]
+		}
+	}
 
 	log.Info().
 		Int("total_results", len(results)).
@@ -694,12 +758,41 @@ func askMediaTriageSingle(ctx context.Context, client *genai.Client, files []*me
 	return results, nil
 }
 
-// parseTriageResponse extracts and parses the JSON array from Gemini's response.
-func parseTriageResponse(response string) ([]TriageResult, error) {
+// triageResponseShape describes the triage JSON for repair prompts.
+const triageResponseShape = `[{"media": <1-based media number>, "filename": "<file name>", "saveable": <true|false>, "reason": "<one sentence>"}, ...]`
+
+// triageSchema normalizes a triage response for fileCount media items
+// (DDR-165). Results with a media number out of range, or one already
+// answered, are dropped; a missing reason is filled in.
+func triageSchema(fileCount int) responseSchema[[]TriageResult] {
+	return responseSchema[[]TriageResult]{
+		Operation: "triage",
+		Shape:     triageResponseShape,
+		Normalize: func(results *[]TriageResult) {
+			seen := make(map[int]bool, len(*results))
+			kept := (*results)[:0]
+			for i, r := range *results {
+				if !keepMedia("triage", fmt.Sprintf("result %d", i+1), r.Media, fileCount, seen) {
+					continue
+				}
+				if strings.TrimSpace(r.Reason) == "" {
+					r.Reason = "No reason given"
+				}
+				kept = append(kept, r)
+			}
+			*results = kept
+		},
+	}
+}
+
+// parseTriageResponse extracts, normalizes and parses the JSON array from
+// Gemini's response for fileCount media items, repairing it through repair
+// when it does not parse.
+func parseTriageResponse(ctx context.Context, response string, fileCount int, repair jsonRepairer) ([]TriageResult, error) {
 	log.Debug().
 		Int("response_length", len(response)).
 		Msg("Parsing triage response JSON")
-	results, err := jsonutil.ParseJSON[[]TriageResult](response)
+	results, err := parseValidated(ctx, response, triageSchema(fileCount), repair)
 	if err != nil {
 		log.Error().Err(err).Str("response", response).Msg("Failed to parse triage response")
 		return nil, fmt.Errorf("triage response: %w", err)
@@ -735,7 +828,9 @@ func AskMediaTriageMCP(
 		},
 		MaxOutputTokens:  65536,
 		MediaResolution: genai.MediaResolutionLow,
+		ThinkingConfig:  thinkingConfig(ctx), // DDR-155
 	}
+	applySampling(ctx, config) // DDR-170
 
 	contents := []*genai.Content{{
 		Role:  "user",
@@ -752,7 +847,7 @@ func AskMediaTriageMCP(
 		return nil, fmt.Errorf("empty response from MCP triage")
 	}
 
-	return parseTriageResponse(responseText)
+	return parseTriageResponse(ctx, responseText, len(files), geminiJSONRepairer(client, modelName))
 }
 
 // BuildMediaTriageMetadataPrompt creates a metadata-only prompt for MCP triage.
@@ -764,10 +859,10 @@ func BuildMediaTriageMetadataPrompt(files []*media.MediaFile) string {
 	var imageCount, videoCount int
 	for _, file := range files {
 		ext := strings.ToLower(filepath.Ext(file.Path))
-		if media.IsImage(ext) {
-			imageCount++
-		} else if media.IsVideo(ext) {
+		if media.IsVideo(ext) || isAnimatedImage(file) {
 			videoCount++
+		} else if media.IsImage(ext) {
+			imageCount++
 		}
 	}
 
@@ -780,7 +875,7 @@ func BuildMediaTriageMetadataPrompt(files []*media.MediaFile) string {
 	for i, file := range files {
 		ext := strings.ToLower(filepath.Ext(file.Path))
 		mediaType := "Photo"
-		if media.IsVideo(ext) {
+		if media.IsVideo(ext) || isAnimatedImage(file) {
 			mediaType = "Video"
 		}
 
@@ -789,8 +884,7 @@ func BuildMediaTriageMetadataPrompt(files []*media.MediaFile) string {
 
 		if file.Metadata != nil {
 			if file.Metadata.HasDateData() {
-				sb.WriteString(fmt.Sprintf("- Date: %s\n",
-					file.Metadata.GetDate().Format("Monday, January 2, 2006 at 3:04 PM")))
+				sb.WriteString(fmt.Sprintf("- Date: %s\n", media.FormatCaptureTime(file.Metadata)))
 			}
 
 			switch m := file.Metadata.(type) {
@@ -798,6 +892,7 @@ func BuildMediaTriageMetadataPrompt(files []*media.MediaFile) string {
 				if m.CameraMake != "" || m.CameraModel != "" {
 					sb.WriteString(fmt.Sprintf("- Camera: %s %s\n", m.CameraMake, m.CameraModel))
 				}
+				writeAnimationLine(&sb, m)
 			case *media.VideoMetadata:
 				if m.Duration > 0 {
 					sb.WriteString(fmt.Sprintf("- Duration: %s\n", formatVideoDuration(m.Duration)))
@@ -0,0 +1,265 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/time/rate"
+)
+
+// --- Parallel triage batches (DDR-167) ---
+
+// DefaultTriageBatchSize is the largest number of media items sent in one
+// Gemini call. Large batches cause the model to silently drop items from
+// its response; smaller ones ensure every item gets a verdict.
+const DefaultTriageBatchSize = 20
+
+// DefaultTriageConcurrency is how many triage batches are in flight at once.
+const DefaultTriageConcurrency = 4
+
+// DefaultTriageRequestsPerMinute caps how often a triage batch may start.
+const DefaultTriageRequestsPerMinute = 30
+
+// TriageBatchSize returns the number of media items per triage call,
+// resolved from:
+// 1. TRIAGE_BATCH_SIZE environment variable
+// 2. Default: 20
+func TriageBatchSize() int {
+	return positiveIntEnv("TRIAGE_BATCH_SIZE", DefaultTriageBatchSize)
+}
+
+// TriageConcurrency returns the number of triage batches sent at once,
+// resolved from:
+// 1. TRIAGE_CONCURRENCY environment variable; "1" sends them one by one
+// 2. Default: 4
+func TriageConcurrency() int {
+	return positiveIntEnv("TRIAGE_CONCURRENCY", DefaultTriageConcurrency)
+}
+
+// TriageRequestsPerMinute returns how many triage batches may start per
+// minute across a job, resolved from:
+// 1. TRIAGE_REQUESTS_PER_MINUTE environment variable
+// 2. Default: 30
+func TriageRequestsPerMinute() int {
+	return positiveIntEnv("TRIAGE_REQUESTS_PER_MINUTE", DefaultTriageRequestsPerMinute)
+}
+
+func positiveIntEnv(name string, def int) int {
+	env := os.Getenv(name)
+	if env == "" {
+		return def
+	}
+	n, err := strconv.Atoi(env)
+	if err != nil || n < 1 {
+		log.Warn().Str("name", name).Str("value", env).Msg("Invalid setting, using the default")
+		return def
+	}
+	return n
+}
+
+// triageBatchPlan sets how a large triage job is split and paced.
+type triageBatchPlan struct {
+	Size        int
+	Concurrency int
+	Limiter     *rate.Limiter // nil: no pacing
+	// Budget caps each batch's estimated payload (DDR-168); Estimate
+	// prices one file. A nil Estimate splits by Size alone.
+	Budget   payloadEstimate
+	Estimate func(*media.MediaFile) payloadEstimate
+}
+
+// defaultTriageBatchPlan reads the plan from the environment. The limiter
+// allows a burst of one batch per worker, so the first round starts at once.
+func defaultTriageBatchPlan() triageBatchPlan {
+	concurrency := TriageConcurrency()
+	perMinute := TriageRequestsPerMinute()
+	return triageBatchPlan{
+		Size:        TriageBatchSize(),
+		Concurrency: concurrency,
+		Limiter:     rate.NewLimiter(rate.Every(time.Minute/time.Duration(perMinute)), concurrency),
+		Budget:      defaultPayloadBudget(),
+		Estimate:    estimateTriagePayload,
+	}
+}
+
+// triageBatchFunc asks Gemini about one batch. batch is 1-based, or 0 when
+// the files fit in a single call. Media numbers in its results are
+// relative to files.
+type triageBatchFunc func(ctx context.Context, batch int, files []*media.MediaFile) ([]TriageResult, error)
+
+// runTriageBatches splits files into batches of at most plan.Size files
+// whose estimated payload fits plan.Budget, and runs up to plan.Concurrency
+// of them at once, starting each when plan.Limiter allows.
+// Results are merged in file order with Media numbers relative to files;
+// a result numbering a file outside its batch is dropped. The first failed
+// batch cancels the others and fails the job. progressFn, if not nil, is
+// called once per finished batch with the count finished so far, never
+// concurrently. Each batch's files are reported to the context's
+// WithFileProgress function as it starts and finishes, with its verdicts
+// (DDR-174, DDR-175).
+func runTriageBatches(ctx context.Context, files []*media.MediaFile, plan triageBatchPlan, ask triageBatchFunc, progressFn BatchProgressFunc) ([]TriageResult, error) {
+	if plan.Size < 1 {
+		plan.Size = DefaultTriageBatchSize
+	}
+	if plan.Concurrency < 1 {
+		plan.Concurrency = 1
+	}
+	var starts []int
+	if plan.Estimate != nil {
+		starts = splitByBudget(files, plan.Size, plan.Budget, plan.Estimate)
+	} else {
+		for start := 0; start < len(files); start += plan.Size {
+			starts = append(starts, start)
+		}
+	}
+	if len(starts) <= 1 {
+		reportFileProgress(ctx, files, FileDownloaded, nil)
+		results, err := ask(ctx, 0, files)
+		if err == nil {
+			reportFileProgress(ctx, files, FileAnalyzed, results)
+		}
+		return results, err
+	}
+
+	totalBatches := len(starts)
+	log.Info().
+		Int("total_files", len(files)).
+		Int("batch_size", plan.Size).
+		Int("total_batches", totalBatches).
+		Int("concurrency", plan.Concurrency).
+		Msg("Batching media triage — too many files for a single request")
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batchResults := make([][]TriageResult, totalBatches)
+	sem := make(chan struct{}, plan.Concurrency)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex // guards finished, firstErr and progressFn calls
+		finished int
+		firstErr error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+		mu.Unlock()
+	}
+
+	for b := 0; b < totalBatches; b++ {
+		start := starts[b]
+		end := len(files)
+		if b+1 < totalBatches {
+			end = starts[b+1]
+		}
+
+		sem <- struct{}{}
+		if plan.Limiter != nil {
+			if err := plan.Limiter.Wait(ctx); err != nil {
+				<-sem
+				fail(err)
+				break
+			}
+		}
+		if ctx.Err() != nil {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+		go func(b, start int, batch []*media.MediaFile) {
+			defer func() { <-sem; wg.Done() }()
+			log.Info().
+				Int("batch", b+1).
+				Int("total_batches", totalBatches).
+				Int("batch_size", len(batch)).
+				Int("offset", start).
+				Msg("Processing triage batch")
+
+			reportFileProgress(ctx, batch, FileDownloaded, nil)
+			results, err := ask(ctx, b+1, batch)
+			if err != nil {
+				log.Error().Err(err).Int("batch", b+1).Msg("Batch triage failed")
+				fail(fmt.Errorf("batch %d/%d triage failed: %w", b+1, totalBatches, err))
+				return
+			}
+
+			// DDR-175: report the batch's verdicts before they are renumbered.
+			reportFileProgress(ctx, batch, FileAnalyzed, results)
+
+			// Adjust Media numbers from batch-local (1-based) to global (1-based).
+			kept := results[:0]
+			for _, r := range results {
+				if r.Media < 1 || r.Media > len(batch) {
+					log.Warn().Int("batch", b+1).Int("media", r.Media).Msg("Dropping triage result outside its batch")
+					continue
+				}
+				r.Media += start
+				kept = append(kept, r)
+			}
+			batchResults[b] = kept
+
+			mu.Lock()
+			finished++
+			done := finished
+			if progressFn != nil && firstErr == nil {
+				progressFn(done, totalBatches)
+			}
+			mu.Unlock()
+			log.Info().
+				Int("batch", b+1).
+				Int("batch_results", len(kept)).
+				Int("finished_batches", done).
+				Msg("Batch triage complete")
+		}(b, start, files[start:end])
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var allResults []TriageResult
+	for _, results := range batchResults {
+		allResults = append(allResults, results...)
+	}
+	sort.SliceStable(allResults, func(a, b int) bool { return allResults[a].Media < allResults[b].Media })
+
+	log.Info().
+		Int("total_results", len(allResults)).
+		Int("total_files", len(files)).
+		Msg("All triage batches complete")
+	return allResults, nil
+}
+
+// triageCacheOperation names the Gemini context cache of a batch (DDR-065).
+// Each batch sends different media, so each needs its own cache.
+func triageCacheOperation(batch int) string {
+	if batch == 0 {
+		return "triage"
+	}
+	return "triage-" + strconv.Itoa(batch)
+}
+
+// triageArtifact names a batch's debug artifact (DDR-106). Batches run in
+// parallel, so the 1-based batch number pairs each prompt with its response;
+// the single-call path (batch 0) keeps the unnumbered name.
+func triageArtifact(batch int, kind string) string {
+	if batch == 0 {
+		return "triage-" + kind + ".txt"
+	}
+	return fmt.Sprintf("triage-batch%d-%s.txt", batch, kind)
+}
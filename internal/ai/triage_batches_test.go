@@ -0,0 +1,157 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+)
+
+func numberedFiles(n int) []*media.MediaFile {
+	files := make([]*media.MediaFile, n)
+	for i := range files {
+		files[i] = &media.MediaFile{Path: fmt.Sprintf("f%02d.jpg", i+1)}
+	}
+	return files
+}
+
+func TestRunTriageBatchesMergesInFileOrder(t *testing.T) {
+	files := numberedFiles(8)
+	var (
+		mu             sync.Mutex
+		inFlight, peak int
+		progress       []int
+		batchNumbers   []int
+	)
+	ask := func(ctx context.Context, batch int, fs []*media.MediaFile) ([]TriageResult, error) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		batchNumbers = append(batchNumbers, batch)
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		// Answer in reverse order, with one stray number the batch does not have.
+		var results []TriageResult
+		for i := len(fs); i >= 1; i-- {
+			results = append(results, TriageResult{Media: i, Filename: fs[i-1].Path, Saveable: true})
+		}
+		return append(results, TriageResult{Media: len(fs) + 1}), nil
+	}
+
+	results, err := runTriageBatches(context.Background(), files, triageBatchPlan{Size: 3, Concurrency: 2}, ask, func(done, total int) {
+		if total != 3 {
+			t.Errorf("total = %d, want 3", total)
+		}
+		progress = append(progress, done)
+	})
+	if err != nil {
+		t.Fatalf("runTriageBatches: %v", err)
+	}
+	if len(results) != len(files) {
+		t.Fatalf("got %d results, want %d", len(results), len(files))
+	}
+	for i, r := range results {
+		if r.Media != i+1 || r.Filename != files[i].Path {
+			t.Errorf("result %d = media %d %s, want media %d %s", i, r.Media, r.Filename, i+1, files[i].Path)
+		}
+	}
+	if peak > 2 {
+		t.Errorf("%d batches in flight, want at most 2", peak)
+	}
+	if fmt.Sprint(progress) != "[1 2 3]" {
+		t.Errorf("progress = %v", progress)
+	}
+	for _, b := range batchNumbers {
+		if b < 1 || b > 3 {
+			t.Errorf("batch number %d, want 1..3", b)
+		}
+	}
+}
+
+func TestRunTriageBatchesSingleCall(t *testing.T) {
+	var batches []int
+	_, err := runTriageBatches(context.Background(), numberedFiles(3), triageBatchPlan{Size: 3}, func(ctx context.Context, batch int, fs []*media.MediaFile) ([]TriageResult, error) {
+		batches = append(batches, batch)
+		return nil, nil
+	}, nil)
+	if err != nil || fmt.Sprint(batches) != "[0]" {
+		t.Errorf("batches = %v, err = %v; want one call numbered 0", batches, err)
+	}
+	if triageCacheOperation(0) != "triage" || triageCacheOperation(2) != "triage-2" {
+		t.Errorf("cache operations = %q, %q", triageCacheOperation(0), triageCacheOperation(2))
+	}
+	if triageArtifact(0, "prompt") != "triage-prompt.txt" || triageArtifact(2, "response") != "triage-batch2-response.txt" {
+		t.Errorf("artifacts = %q, %q", triageArtifact(0, "prompt"), triageArtifact(2, "response"))
+	}
+}
+
+func TestRunTriageBatchesFailure(t *testing.T) {
+	ask := func(ctx context.Context, batch int, fs []*media.MediaFile) ([]TriageResult, error) {
+		if batch == 2 {
+			return nil, errors.New("quota exceeded")
+		}
+		return nil, nil
+	}
+	_, err := runTriageBatches(context.Background(), numberedFiles(9), triageBatchPlan{Size: 3, Concurrency: 1}, ask, nil)
+	if err == nil || !strings.Contains(err.Error(), "batch 2/3") || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestTriageBatchSettings(t *testing.T) {
+	t.Setenv("TRIAGE_BATCH_SIZE", "50")
+	t.Setenv("TRIAGE_CONCURRENCY", "zero")
+	t.Setenv("TRIAGE_REQUESTS_PER_MINUTE", "-1")
+	if got := TriageBatchSize(); got != 50 {
+		t.Errorf("TriageBatchSize = %d, want 50", got)
+	}
+	if got := TriageConcurrency(); got != DefaultTriageConcurrency {
+		t.Errorf("TriageConcurrency = %d, want the default", got)
+	}
+	if got := TriageRequestsPerMinute(); got != DefaultTriageRequestsPerMinute {
+		t.Errorf("TriageRequestsPerMinute = %d, want the default", got)
+	}
+}
+
+func TestRunTriageBatchesReportsFileProgress(t *testing.T) {
+	files := numberedFiles(5)
+	stages := make(map[string][]string)
+	verdicts := make(map[string]string)
+	ctx := WithFileProgress(context.Background(), func(fs []*media.MediaFile, stage string, rs []TriageResult) {
+		for _, f := range fs {
+			stages[f.Path] = append(stages[f.Path], stage)
+		}
+		for _, r := range rs {
+			verdicts[fs[r.Media-1].Path] = r.Reason
+		}
+	})
+	ask := func(ctx context.Context, batch int, fs []*media.MediaFile) ([]TriageResult, error) {
+		// Judge only each batch's first file; 9 is outside every batch.
+		return []TriageResult{{Media: 1, Reason: "first of " + fs[0].Path}, {Media: 9}}, nil
+	}
+	if _, err := runTriageBatches(ctx, files, triageBatchPlan{Size: 2, Concurrency: 2}, ask, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if got := fmt.Sprint(stages[f.Path]); got != "[downloaded analyzed]" {
+			t.Errorf("%s stages = %s", f.Path, got)
+		}
+	}
+	if len(verdicts) != 3 {
+		t.Errorf("verdicts = %v, want one per batch", verdicts)
+	}
+	for _, i := range []int{0, 2, 4} {
+		if want := "first of " + files[i].Path; verdicts[files[i].Path] != want {
+			t.Errorf("%s verdict = %q, want %q", files[i].Path, verdicts[files[i].Path], want)
+		}
+	}
+}
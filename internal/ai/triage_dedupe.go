@@ -0,0 +1,183 @@
+package ai
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+)
+
+// triageClusters records which files were sent to Gemini on behalf of
+// their near-duplicates (DDR-110) and which were discarded locally by the
+// quality pre-filter (DDR-112).
+type triageClusters struct {
+	files  []*media.MediaFile // every file, in the caller's order
+	rep    []int              // rep[i] is the index of file i's representative
+	sent   []int              // indices of the representatives, in order
+	issues []string           // issues[i] is file i's quality issue, if pre-filtered
+}
+
+// triageThumbnailPx matches the media-process thumbnails, so local hashes and
+// quality checks see the same pixels as the cloud pipeline.
+const triageThumbnailPx = 400
+
+// clusterTriageFiles pre-filters clearly unsaveable photos and groups
+// near-duplicates so only one per cluster is sent to Gemini. A file's hash
+// and quality issue come from MediaFile or, for local images, are computed
+// from disk. Videos and files without a hash are always sent.
+func clusterTriageFiles(files []*media.MediaFile) *triageClusters {
+	hashes := make([]media.DHash, len(files))
+	issues := make([]string, len(files))
+	for i, f := range files {
+		if !media.IsImage(strings.ToLower(filepath.Ext(f.Path))) {
+			continue
+		}
+		hashes[i], issues[i] = f.DHash, f.QualityIssue
+		if hashes[i] == 0 && f.PresignedURL == "" {
+			thumb, _, err := media.GenerateThumbnail(f, triageThumbnailPx)
+			if err != nil {
+				log.Debug().Err(err).Str("file", f.Path).Msg("Could not analyze image, sending it individually")
+				continue
+			}
+			if h, err := media.DHashBytes(thumb); err == nil {
+				hashes[i] = h
+			}
+			if issue, err := media.CheckQualityBytes(thumb); err == nil {
+				issues[i] = issue
+			}
+		}
+		if issues[i] != "" {
+			hashes[i] = 0 // a pre-filtered photo must not represent a cluster
+		}
+	}
+
+	c := &triageClusters{files: files, rep: media.GroupNearDuplicates(hashes, media.NearDuplicateMaxDistance), issues: issues}
+	for i, r := range c.rep {
+		if r == i && issues[i] == "" {
+			c.sent = append(c.sent, i)
+		}
+	}
+	return c
+}
+
+// preFiltered returns the number of files discarded without a Gemini call.
+func (c *triageClusters) preFiltered() int {
+	n := 0
+	for _, issue := range c.issues {
+		if issue != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// representatives returns the files to send to Gemini.
+func (c *triageClusters) representatives() []*media.MediaFile {
+	out := make([]*media.MediaFile, len(c.sent))
+	for i, idx := range c.sent {
+		out[i] = c.files[idx]
+	}
+	return out
+}
+
+// expand maps results for the representatives back onto every file. Media
+// indices are rewritten to the caller's 1-based positions, each
+// near-duplicate inherits its representative's verdict, and pre-filtered
+// files are discarded with their quality issue as the reason.
+func (c *triageClusters) expand(results []TriageResult) []TriageResult {
+	byRep := make(map[int]TriageResult, len(results))
+	for _, r := range results {
+		if r.Media < 1 || r.Media > len(c.sent) {
+			continue
+		}
+		byRep[c.sent[r.Media-1]] = r
+	}
+
+	out := make([]TriageResult, 0, len(c.files))
+	for i := range c.files {
+		if c.issues[i] != "" {
+			out = append(out, c.preFilteredResult(i))
+			continue
+		}
+		if r, ok := byRep[c.rep[i]]; ok {
+			out = append(out, c.memberResult(i, r))
+		}
+	}
+	return out
+}
+
+// preFilteredResult is the verdict for pre-filtered file i.
+func (c *triageClusters) preFilteredResult(i int) TriageResult {
+	return TriageResult{
+		Media:       i + 1,
+		Filename:    filepath.Base(c.files[i].Path),
+		Saveable:    false,
+		Reason:      "Pre-filtered: " + c.issues[i],
+		PreFiltered: true,
+	}
+}
+
+// memberResult is file i's verdict given its representative's verdict r,
+// numbered with the caller's 1-based position.
+func (c *triageClusters) memberResult(i int, r TriageResult) TriageResult {
+	if c.rep[i] == i {
+		r.Media = i + 1
+		return r
+	}
+	repName := filepath.Base(c.files[c.rep[i]].Path)
+	return TriageResult{
+		Media:    i + 1,
+		Filename: filepath.Base(c.files[i].Path),
+		Saveable: r.Saveable,
+		Reason:   fmt.Sprintf("Near-duplicate of %s: %s", repName, r.Reason),
+	}
+}
+
+// preFilteredFiles returns the files discarded without a Gemini call and
+// their verdicts, numbered from 1 within the returned files.
+func (c *triageClusters) preFilteredFiles() ([]*media.MediaFile, []TriageResult) {
+	var files []*media.MediaFile
+	var verdicts []TriageResult
+	for i, issue := range c.issues {
+		if issue != "" {
+			files = append(files, c.files[i])
+			r := c.preFilteredResult(i)
+			r.Media = len(files)
+			verdicts = append(verdicts, r)
+		}
+	}
+	return files, verdicts
+}
+
+// withMembers returns reps, which must be representatives, together with
+// the near-duplicates that inherit their verdicts, in the caller's order.
+// verdicts number reps from 1 and are mapped onto the returned files the
+// same way.
+func (c *triageClusters) withMembers(reps []*media.MediaFile, verdicts []TriageResult) ([]*media.MediaFile, []TriageResult) {
+	byRep := make(map[*media.MediaFile]TriageResult, len(verdicts))
+	for _, r := range verdicts {
+		byRep[reps[r.Media-1]] = r
+	}
+	wanted := make(map[*media.MediaFile]bool, len(reps))
+	for _, f := range reps {
+		wanted[f] = true
+	}
+	var files []*media.MediaFile
+	var out []TriageResult
+	for i, f := range c.files {
+		rep := c.files[c.rep[i]]
+		if c.issues[i] != "" || !wanted[rep] {
+			continue
+		}
+		files = append(files, f)
+		if r, ok := byRep[rep]; ok {
+			r = c.memberResult(i, r)
+			r.Media = len(files)
+			out = append(out, r)
+		}
+	}
+	return files, out
+}
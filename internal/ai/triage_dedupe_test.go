@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+)
+
+func TestTriageClustersPropagateVerdicts(t *testing.T) {
+	files := []*media.MediaFile{
+		{Path: "a.jpg", DHash: 0xf0f0, PresignedURL: "https://s3/a"},
+		{Path: "b.jpg", DHash: 0xf0f1, PresignedURL: "https://s3/b"}, // near-duplicate of a
+		{Path: "c.mp4", PresignedURL: "https://s3/c"},
+		{Path: "d.jpg", DHash: 0x0f0f, PresignedURL: "https://s3/d"},
+	}
+	c := clusterTriageFiles(files)
+
+	sent := c.representatives()
+	if len(sent) != 3 || sent[0].Path != "a.jpg" || sent[1].Path != "c.mp4" || sent[2].Path != "d.jpg" {
+		t.Fatalf("unexpected representatives: %v", sent)
+	}
+
+	// Gemini numbers media by position in the request.
+	got := c.expand([]TriageResult{
+		{Media: 1, Filename: "a.jpg", Saveable: true, Reason: "sharp"},
+		{Media: 2, Filename: "c.mp4", Saveable: true, Reason: "clear audio"},
+		{Media: 3, Filename: "d.jpg", Saveable: false, Reason: "pocket shot"},
+	})
+	if len(got) != 4 {
+		t.Fatalf("expected a result per file, got %+v", got)
+	}
+	dup := got[1]
+	if dup.Media != 2 || dup.Filename != "b.jpg" || !dup.Saveable || !strings.Contains(dup.Reason, "a.jpg") {
+		t.Errorf("near-duplicate result = %+v", dup)
+	}
+	if got[2].Media != 3 || got[3].Media != 4 || got[3].Saveable {
+		t.Errorf("indices not mapped back to the caller's files: %+v", got)
+	}
+}
+
+func TestTriageClustersPreFilter(t *testing.T) {
+	files := []*media.MediaFile{
+		{Path: "dark.jpg", DHash: 0xf0f0, QualityIssue: "Nearly black frame", PresignedURL: "https://s3/dark"},
+		{Path: "ok.jpg", DHash: 0xf0f1, PresignedURL: "https://s3/ok"}, // would be a near-duplicate of dark.jpg
+	}
+	c := clusterTriageFiles(files)
+
+	sent := c.representatives()
+	if len(sent) != 1 || sent[0].Path != "ok.jpg" {
+		t.Fatalf("pre-filtered photo must not be sent or represent a cluster: %v", sent)
+	}
+
+	got := c.expand([]TriageResult{{Media: 1, Filename: "ok.jpg", Saveable: true, Reason: "sharp"}})
+	if len(got) != 2 {
+		t.Fatalf("expected a result per file, got %+v", got)
+	}
+	if pf := got[0]; pf.Media != 1 || pf.Saveable || !pf.PreFiltered || pf.Reason != "Pre-filtered: Nearly black frame" {
+		t.Errorf("pre-filtered result = %+v", pf)
+	}
+	if got[1].Media != 2 || !got[1].Saveable || got[1].PreFiltered {
+		t.Errorf("kept result = %+v", got[1])
+	}
+}
+
+func TestTriageClustersWithMembers(t *testing.T) {
+	files := []*media.MediaFile{
+		{Path: "a.jpg", DHash: 0xf0f0, PresignedURL: "https://s3/a"},
+		{Path: "b.jpg", DHash: 0xf0f1, PresignedURL: "https://s3/b"}, // near-duplicate of a
+		{Path: "dark.jpg", QualityIssue: "Nearly black frame", PresignedURL: "https://s3/dark"},
+		{Path: "d.jpg", DHash: 0x0f0f, PresignedURL: "https://s3/d"},
+	}
+	c := clusterTriageFiles(files)
+
+	got, verdicts := c.withMembers([]*media.MediaFile{files[0]}, []TriageResult{{Media: 1, Filename: "a.jpg", Saveable: true, Reason: "Sharp"}})
+	if len(got) != 2 || got[0].Path != "a.jpg" || got[1].Path != "b.jpg" {
+		t.Errorf("withMembers(a) = %v", got)
+	}
+	if len(verdicts) != 2 || verdicts[1].Media != 2 || verdicts[1].Filename != "b.jpg" || verdicts[1].Reason != "Near-duplicate of a.jpg: Sharp" {
+		t.Errorf("withMembers(a) verdicts = %+v", verdicts)
+	}
+	pf, pv := c.preFilteredFiles()
+	if len(pf) != 1 || pf[0].Path != "dark.jpg" {
+		t.Errorf("preFilteredFiles = %v", pf)
+	}
+	if len(pv) != 1 || pv[0].Media != 1 || !pv[0].PreFiltered {
+		t.Errorf("preFilteredFiles verdicts = %+v", pv)
+	}
+}
@@ -0,0 +1,69 @@
+package ai
+
+import (
+	"context"
+	"sync"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+)
+
+// --- Per-file triage progress (DDR-174) ---
+
+// File stages reported to a FileProgressFunc.
+const (
+	FileDownloaded = "downloaded" // its batch is being fetched and sent to Gemini
+	FileAnalyzed   = "analyzed"   // its verdict is known
+)
+
+// FileProgressFunc is told when triage files reach stage. files are the
+// caller's own MediaFile pointers. For FileAnalyzed, verdicts holds the
+// files' verdicts, each Media numbering its file's 1-based position in
+// files (DDR-175); a file Gemini skipped has none. Calls never overlap.
+type FileProgressFunc func(files []*media.MediaFile, stage string, verdicts []TriageResult)
+
+type fileProgressKey struct{}
+
+type fileProgressHook struct {
+	mu *sync.Mutex
+	fn FileProgressFunc
+}
+
+// WithFileProgress returns a context whose triage reports each file's stage
+// to fn. Pre-filtered photos and cached verdicts are analyzed at once;
+// other files are downloaded when their batch starts and analyzed when it
+// finishes, together with their near-duplicates. Economy mode reports
+// nothing, as its verdicts arrive later.
+func WithFileProgress(ctx context.Context, fn FileProgressFunc) context.Context {
+	return context.WithValue(ctx, fileProgressKey{}, &fileProgressHook{mu: &sync.Mutex{}, fn: fn})
+}
+
+// withFileProgressMapped returns a context whose reports pass through
+// mapFiles first, under the same lock.
+func withFileProgressMapped(ctx context.Context, mapFiles func([]*media.MediaFile, []TriageResult) ([]*media.MediaFile, []TriageResult)) context.Context {
+	p, _ := ctx.Value(fileProgressKey{}).(*fileProgressHook)
+	if p == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, fileProgressKey{}, &fileProgressHook{mu: p.mu, fn: func(files []*media.MediaFile, stage string, verdicts []TriageResult) {
+		files, verdicts = mapFiles(files, verdicts)
+		p.fn(files, stage, verdicts)
+	}})
+}
+
+// reportFileProgress reports files at stage. verdicts, for FileAnalyzed,
+// number files from 1; those outside files are dropped.
+func reportFileProgress(ctx context.Context, files []*media.MediaFile, stage string, verdicts []TriageResult) {
+	p, _ := ctx.Value(fileProgressKey{}).(*fileProgressHook)
+	if p == nil || len(files) == 0 {
+		return
+	}
+	var valid []TriageResult
+	for _, r := range verdicts {
+		if r.Media >= 1 && r.Media <= len(files) {
+			valid = append(valid, r)
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fn(files, stage, valid)
+}
@@ -0,0 +1,200 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultTriageSampleThreshold is the video length above which triage
+// judges a sample of the video instead of the whole clip (DDR-162).
+const DefaultTriageSampleThreshold = 3 * time.Minute
+
+// TriageSampleThreshold returns the video length above which triage
+// samples, resolved from:
+// 1. TRIAGE_SAMPLE_THRESHOLD environment variable, a Go duration such as
+// "5m"; "0" turns sampling off
+// 2. Default: 3 minutes
+func TriageSampleThreshold() time.Duration {
+	env := os.Getenv("TRIAGE_SAMPLE_THRESHOLD")
+	if env == "" {
+		return DefaultTriageSampleThreshold
+	}
+	d, err := time.ParseDuration(env)
+	if err != nil || d < 0 {
+		log.Warn().Str("value", env).Msg("Invalid TRIAGE_SAMPLE_THRESHOLD, using the default")
+		return DefaultTriageSampleThreshold
+	}
+	return d
+}
+
+// triageSamples maps a long video to the sample Gemini sees in its place.
+type triageSamples map[*media.MediaFile]*media.VideoSample
+
+// sampleLongVideos returns the samples to send in place of long videos:
+// the sample a file already carries (rendered by MediaProcess in cloud
+// mode), or, for a local video whose known duration is over the threshold,
+// a sample rendered now. A local video that cannot be sampled is left out
+// and sent whole as before.
+func sampleLongVideos(files []*media.MediaFile) triageSamples {
+	threshold := TriageSampleThreshold()
+	samples := triageSamples{}
+	for _, file := range files {
+		if file.Sample != nil {
+			samples[file] = file.Sample
+			continue
+		}
+		meta, ok := file.Metadata.(*media.VideoMetadata)
+		if threshold == 0 || file.PresignedURL != "" || !ok || meta == nil || meta.Duration <= threshold {
+			continue
+		}
+		s, err := media.SampleVideo(file.Path, meta.Duration, media.DefaultThumbnailMaxDimension)
+		if err != nil {
+			log.Warn().Err(err).Str("file", filepath.Base(file.Path)).Msg("Failed to sample long video, sending it whole")
+			continue
+		}
+		log.Info().
+			Str("file", filepath.Base(file.Path)).
+			Dur("duration", meta.Duration).
+			Dur("threshold", threshold).
+			Int("frames", len(s.Offsets)).
+			Msg("Long video will be triaged via sampling")
+		samples[file] = s
+	}
+	return samples
+}
+
+type keyframesKey struct{}
+
+// WithTriageKeyframes returns a context whose triage judges every video
+// from a sheet of n frames and its audio summary instead of the clip
+// (DDR-186); 0 leaves keyframe mode off. Cloud files carry sheets MediaProcess
+// rendered for the job; local videos are sampled when triage starts.
+func WithTriageKeyframes(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, keyframesKey{}, n)
+}
+
+// triageKeyframes returns the context's keyframe count, 0 when off.
+func triageKeyframes(ctx context.Context) int {
+	n, _ := ctx.Value(keyframesKey{}).(int)
+	return n
+}
+
+// sampleKeyframes renders keyframe sheets for the local videos in files
+// that have none, when the context asks for keyframe mode. A video that
+// cannot be sampled keeps whatever it had and is sent as before.
+func sampleKeyframes(ctx context.Context, files []*media.MediaFile) {
+	n := triageKeyframes(ctx)
+	if n == 0 {
+		return
+	}
+	for _, file := range files {
+		meta, ok := file.Metadata.(*media.VideoMetadata)
+		if (file.Sample != nil && file.Sample.Keyframes) || file.PresignedURL != "" || !ok || meta == nil || meta.Duration <= 0 {
+			continue
+		}
+		s, err := media.SampleKeyframes(file.Path, meta.Duration, media.DefaultThumbnailMaxDimension, n)
+		if err != nil {
+			log.Warn().Err(err).Str("file", filepath.Base(file.Path)).Msg("Failed to render keyframes, sending the video as before")
+			continue
+		}
+		file.Sample = s
+	}
+}
+
+// writeSampleLines explains the frame sheet and audio summary Gemini
+// receives in place of a long video, or of any video in keyframe mode.
+func writeSampleLines(sb *strings.Builder, s *media.VideoSample) {
+	stamps := make([]string, len(s.Offsets))
+	for i, offset := range s.Offsets {
+		stamps[i] = formatVideoDuration(offset)
+	}
+	if s.Keyframes {
+		sb.WriteString(fmt.Sprintf("- Evaluated from keyframes: a %s video shown as a sheet of %d frames taken at %s, left to right then top to bottom\n",
+			formatVideoDuration(s.Duration), len(s.Offsets), strings.Join(stamps, ", ")))
+	} else {
+		sb.WriteString(fmt.Sprintf("- Evaluated via sampling: too long to send whole, shown as a sheet of %d frames taken at %s, left to right then top to bottom\n",
+			len(s.Offsets), strings.Join(stamps, ", ")))
+	}
+	if s.Loudness == nil {
+		sb.WriteString("- Audio: none or not measured\n")
+		return
+	}
+	sb.WriteString(fmt.Sprintf("- Audio: mean %.1f dB, peak %.1f dB (%s)\n",
+		s.Loudness.MeanDB, s.Loudness.MaxDB, loudnessLabel(s.Loudness)))
+}
+
+// loudnessLabel puts a volumedetect mean into words, so a muffled pocket
+// recording reads as such without the model weighing decibels.
+func loudnessLabel(l *media.AudioLoudness) string {
+	switch {
+	case l.MeanDB <= -50:
+		return "near silent"
+	case l.MeanDB <= -35:
+		return "quiet or muffled"
+	default:
+		return "clearly audible"
+	}
+}
+
+// markSampled flags the results of sampled videos. Media numbers are
+// 1-based positions in files.
+func markSampled(results []TriageResult, files []*media.MediaFile, samples triageSamples) {
+	if len(samples) == 0 {
+		return
+	}
+	for i, r := range results {
+		if r.Media >= 1 && r.Media <= len(files) && samples[files[r.Media-1]] != nil {
+			results[i].Sampled = true
+		}
+	}
+}
+
+// VideoTriageStats summarises how a triage job judged its videos, so
+// keyframe mode can be compared with whole clips on cost and outcome
+// (DDR-186).
+type VideoTriageStats struct {
+	Videos      int   // videos in the job
+	Keyframed   int   // judged from keyframe sheets
+	Sampled     int   // judged from long-video samples (DDR-162)
+	Kept        int   // judged saveable
+	SourceBytes int64 // size of the videos as uploaded
+	SheetBytes  int64 // size of the frame sheets sent in their place
+}
+
+// SummarizeVideoTriage counts the videos among files and their verdicts.
+// Media numbers in results are 1-based positions in files.
+func SummarizeVideoTriage(files []*media.MediaFile, results []TriageResult) VideoTriageStats {
+	kept := make(map[int]bool, len(results))
+	for _, r := range results {
+		if r.Saveable {
+			kept[r.Media-1] = true
+		}
+	}
+	var st VideoTriageStats
+	for i, file := range files {
+		if !media.IsVideo(filepath.Ext(file.Path)) {
+			continue
+		}
+		st.Videos++
+		st.SourceBytes += file.Size
+		if kept[i] {
+			st.Kept++
+		}
+		if s := file.Sample; s != nil {
+			st.SheetBytes += int64(len(s.Sheet))
+			if s.Keyframes {
+				st.Keyframed++
+			} else {
+				st.Sampled++
+			}
+		}
+	}
+	return st
+}
@@ -0,0 +1,97 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+)
+
+func TestTriageSampleThreshold(t *testing.T) {
+	for env, want := range map[string]time.Duration{
+		"":      DefaultTriageSampleThreshold,
+		"5m":    5 * time.Minute,
+		"0":     0,
+		"-1m":   DefaultTriageSampleThreshold,
+		"three": DefaultTriageSampleThreshold,
+	} {
+		t.Setenv("TRIAGE_SAMPLE_THRESHOLD", env)
+		if got := TriageSampleThreshold(); got != want {
+			t.Errorf("TriageSampleThreshold with %q = %s, want %s", env, got, want)
+		}
+	}
+}
+
+func TestTriagePromptDescribesSample(t *testing.T) {
+	long := &media.MediaFile{
+		Path: "pocket.mp4",
+		Sample: &media.VideoSample{
+			Sheet:    []byte{0xff},
+			Offsets:  []time.Duration{75 * time.Second, 225 * time.Second},
+			Loudness: &media.AudioLoudness{MeanDB: -52.4, MaxDB: -30},
+		},
+	}
+	short := &media.MediaFile{Path: "clip.mp4"}
+	files := []*media.MediaFile{short, long}
+	samples := sampleLongVideos(files)
+
+	prompt := BuildMediaTriagePrompt(files, "", samples)
+	for _, want := range []string{
+		"sheet of 2 frames taken at 1:15, 3:45",
+		"- Audio: mean -52.4 dB, peak -30.0 dB (near silent)",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt lacks %q:\n%s", want, prompt)
+		}
+	}
+	if strings.Count(prompt, "Evaluated via sampling") != 1 {
+		t.Errorf("only the long video should be described as sampled:\n%s", prompt)
+	}
+
+	results := []TriageResult{{Media: 1}, {Media: 2}, {Media: 9}}
+	markSampled(results, files, samples)
+	if results[0].Sampled || !results[1].Sampled || results[2].Sampled {
+		t.Errorf("markSampled = %+v", results)
+	}
+}
+
+func TestTriagePromptDescribesKeyframes(t *testing.T) {
+	clip := &media.MediaFile{
+		Path: "clip.mp4",
+		Sample: &media.VideoSample{
+			Sheet:     []byte{0xff},
+			Offsets:   []time.Duration{10 * time.Second, 30 * time.Second, 50 * time.Second},
+			Duration:  time.Minute,
+			Keyframes: true,
+		},
+	}
+	files := []*media.MediaFile{clip}
+	prompt := BuildMediaTriagePrompt(files, "", sampleLongVideos(files))
+	if want := "Evaluated from keyframes: a 1:00 video shown as a sheet of 3 frames taken at 0:10, 0:30, 0:50"; !strings.Contains(prompt, want) {
+		t.Errorf("prompt lacks %q:\n%s", want, prompt)
+	}
+	if strings.Contains(prompt, "too long to send whole") {
+		t.Errorf("keyframed video described as a long-video sample:\n%s", prompt)
+	}
+}
+
+func TestSummarizeVideoTriage(t *testing.T) {
+	files := []*media.MediaFile{
+		{Path: "a.mp4", Size: 1000, Sample: &media.VideoSample{Sheet: make([]byte, 10), Keyframes: true}},
+		{Path: "b.MOV", Size: 2000, Sample: &media.VideoSample{Sheet: make([]byte, 20)}},
+		{Path: "c.mp4", Size: 500},
+		{Path: "d.jpg", Size: 300},
+	}
+	results := []TriageResult{
+		{Media: 1, Saveable: true},
+		{Media: 2},
+		{Media: 3, Saveable: true},
+		{Media: 4, Saveable: true},
+	}
+	got := SummarizeVideoTriage(files, results)
+	want := VideoTriageStats{Videos: 3, Keyframed: 1, Sampled: 1, Kept: 2, SourceBytes: 3500, SheetBytes: 30}
+	if got != want {
+		t.Errorf("SummarizeVideoTriage = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,148 @@
+package ai
+
+// upscale.go adds an optional super-resolution step for low-resolution
+// photos, using Imagen's upscale mode through the same Vertex AI client as
+// the Phase 3 edits. See DDR-184: Upscaling Low-Resolution Photos.
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// imagenUpscaleModel is the Imagen model used for super-resolution.
+const imagenUpscaleModel = "imagen-4.0-upscale-preview"
+
+// Upscale threshold bounds, on the long edge in pixels (DDR-184). A job's
+// threshold of 0 turns upscaling off.
+const (
+	MinUpscaleBelow = 512
+	MaxUpscaleBelow = 4096
+)
+
+// maxUpscaleOutputPixels is the largest image Imagen's upscale mode returns.
+const maxUpscaleOutputPixels = 17_000_000
+
+// upscaleQuality is the JPEG quality Imagen encodes upscaled photos at.
+const upscaleQuality = 92
+
+// ValidateUpscaleBelow checks a job's upscale threshold: 0 (off) or a long
+// edge between MinUpscaleBelow and MaxUpscaleBelow.
+func ValidateUpscaleBelow(px int) error {
+	if px != 0 && (px < MinUpscaleBelow || px > MaxUpscaleBelow) {
+		return fmt.Errorf("upscaleBelow must be 0 or between %d and %d pixels", MinUpscaleBelow, MaxUpscaleBelow)
+	}
+	return nil
+}
+
+// UpscaleFactor returns the factor (2 or 4) that brings a width x height
+// image's long edge to at least below pixels, or 0 when the image is already
+// that large or below is 0. When reaching the threshold would exceed
+// Imagen's output limit, it returns the largest factor within the limit.
+func UpscaleFactor(width, height, below int) int {
+	if below <= 0 || width <= 0 || height <= 0 || max(width, height) >= below {
+		return 0
+	}
+	factor := 0
+	for _, f := range []int{2, 4} {
+		if width*f*height*f > maxUpscaleOutputPixels {
+			break
+		}
+		factor = f
+		if max(width, height)*f >= below {
+			break
+		}
+	}
+	return factor
+}
+
+// Upscale enlarges an image by factor (2 or 4) with Imagen's upscale mode
+// and returns it as a JPEG.
+func (c *ImagenClient) Upscale(ctx context.Context, imageData []byte, factor int) (*ImagenEditResult, error) {
+	if factor != 2 && factor != 4 {
+		return nil, fmt.Errorf("unsupported upscale factor %d", factor)
+	}
+	log.Debug().
+		Int("factor", factor).
+		Int("image_bytes", len(imageData)).
+		Msg("Upscale: Starting Imagen API call")
+
+	startTime := time.Now()
+	req := imagenRequest{
+		Instances: []imagenInstance{
+			{
+				Image: imagenData{
+					BytesBase64Encoded: base64.StdEncoding.EncodeToString(imageData),
+				},
+			},
+		},
+		Parameters: imagenParameters{
+			SampleCount:   1,
+			Mode:          "upscale",
+			UpscaleConfig: &imagenUpscaleConfig{UpscaleFactor: fmt.Sprintf("x%d", factor)},
+			OutputOptions: &imagenOutputOptions{MimeType: "image/jpeg", CompressionQuality: upscaleQuality},
+		},
+	}
+
+	result, err := c.predict(ctx, imagenUpscaleModel, req)
+	if err != nil {
+		return nil, err
+	}
+	if result.MIMEType == "" {
+		result.MIMEType = "image/jpeg"
+	}
+	log.Debug().
+		Int("output_bytes", len(result.ImageData)).
+		Dur("duration", time.Since(startTime)).
+		Msg("Upscale: Imagen API call completed successfully")
+	return result, nil
+}
+
+// UpscaledImage is an image enlarged by UpscaleToLongEdge, with its size
+// before and after.
+type UpscaledImage struct {
+	*ImagenEditResult
+	Factor       int
+	BeforeWidth  int
+	BeforeHeight int
+	AfterWidth   int
+	AfterHeight  int
+}
+
+// UpscaleToLongEdge enlarges imageData until its long edge reaches below
+// pixels, within Imagen's output limit. The factor comes from imageData's
+// own size, which can differ from the original photo's: the enhancement
+// models return images at a resolution of their choosing. It returns nil
+// when imageData is already large enough.
+func (c *ImagenClient) UpscaleToLongEdge(ctx context.Context, imageData []byte, below int) (*UpscaledImage, error) {
+	before, _, err := image.DecodeConfig(bytes.NewReader(imageData))
+	if err != nil {
+		return nil, fmt.Errorf("read image size: %w", err)
+	}
+	factor := UpscaleFactor(before.Width, before.Height, below)
+	if factor == 0 {
+		return nil, nil
+	}
+
+	result, err := c.Upscale(ctx, imageData, factor)
+	if err != nil {
+		return nil, err
+	}
+	after, _, err := image.DecodeConfig(bytes.NewReader(result.ImageData))
+	if err != nil {
+		return nil, fmt.Errorf("read upscaled image size: %w", err)
+	}
+	return &UpscaledImage{
+		ImagenEditResult: result,
+		Factor:           factor,
+		BeforeWidth:      before.Width,
+		BeforeHeight:     before.Height,
+		AfterWidth:       after.Width,
+		AfterHeight:      after.Height,
+	}, nil
+}
@@ -0,0 +1,123 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/jpeg"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUpscaleFactor(t *testing.T) {
+	tests := []struct {
+		name                 string
+		width, height, below int
+		want                 int
+	}{
+		{"off", 800, 600, 0, 0},
+		{"already large enough", 2048, 1536, 2048, 0},
+		{"2x reaches the threshold", 1200, 900, 2048, 2},
+		{"needs 4x", 640, 480, 2048, 4},
+		{"4x would exceed the output limit", 1500, 1500, 4096, 2},
+		{"2x would exceed the output limit", 3000, 2900, 4096, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UpscaleFactor(tt.width, tt.height, tt.below); got != tt.want {
+				t.Errorf("UpscaleFactor(%d, %d, %d) = %d, want %d", tt.width, tt.height, tt.below, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateUpscaleBelow(t *testing.T) {
+	for _, px := range []int{0, MinUpscaleBelow, 2048, MaxUpscaleBelow} {
+		if err := ValidateUpscaleBelow(px); err != nil {
+			t.Errorf("ValidateUpscaleBelow(%d) = %v", px, err)
+		}
+	}
+	for _, px := range []int{-1, 100, MaxUpscaleBelow + 1} {
+		if err := ValidateUpscaleBelow(px); err == nil {
+			t.Errorf("ValidateUpscaleBelow(%d) accepted", px)
+		}
+	}
+}
+
+// fakeUpscaler answers Imagen upscale requests with a blank JPEG the
+// requested factor larger than the request's image.
+type fakeUpscaler struct{ t *testing.T }
+
+func (f fakeUpscaler) RoundTrip(r *http.Request) (*http.Response, error) {
+	var req imagenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		f.t.Fatalf("decode request: %v", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(req.Instances[0].Image.BytesBase64Encoded)
+	if err != nil {
+		f.t.Fatalf("decode request image: %v", err)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		f.t.Fatalf("read request image size: %v", err)
+	}
+	factor, err := strconv.Atoi(strings.TrimPrefix(req.Parameters.UpscaleConfig.UpscaleFactor, "x"))
+	if err != nil {
+		f.t.Fatalf("upscale factor %q: %v", req.Parameters.UpscaleConfig.UpscaleFactor, err)
+	}
+	body, _ := json.Marshal(imagenResponse{Predictions: []imagenPrediction{{
+		BytesBase64Encoded: base64.StdEncoding.EncodeToString(blankJPEG(f.t, cfg.Width*factor, cfg.Height*factor)),
+		MimeType:           "image/jpeg",
+	}}})
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body)), Header: http.Header{}}, nil
+}
+
+func blankJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatalf("encode JPEG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// The original photo is 1600x1200, but the enhancement came back at
+// 800x600: the factor and the before size must come from the enhanced
+// image.
+func TestUpscaleToLongEdgeUsesEnhancedSize(t *testing.T) {
+	c := &ImagenClient{projectID: "p", region: "r", httpClient: &http.Client{Transport: fakeUpscaler{t}}}
+
+	up, err := c.UpscaleToLongEdge(context.Background(), blankJPEG(t, 800, 600), 2048)
+	if err != nil {
+		t.Fatalf("UpscaleToLongEdge: %v", err)
+	}
+	if up == nil {
+		t.Fatal("UpscaleToLongEdge = nil, want an upscaled image")
+	}
+	if up.Factor != 4 {
+		t.Errorf("Factor = %d, want 4", up.Factor)
+	}
+	if up.BeforeWidth != 800 || up.BeforeHeight != 600 {
+		t.Errorf("before = %dx%d, want 800x600", up.BeforeWidth, up.BeforeHeight)
+	}
+	if up.AfterWidth != 3200 || up.AfterHeight != 2400 {
+		t.Errorf("after = %dx%d, want 3200x2400", up.AfterWidth, up.AfterHeight)
+	}
+	if up.MIMEType != "image/jpeg" {
+		t.Errorf("MIMEType = %q, want image/jpeg", up.MIMEType)
+	}
+}
+
+func TestUpscaleToLongEdgeLargeEnough(t *testing.T) {
+	c := &ImagenClient{projectID: "p", region: "r", httpClient: &http.Client{Transport: fakeUpscaler{t}}}
+
+	up, err := c.UpscaleToLongEdge(context.Background(), blankJPEG(t, 2048, 1536), 2048)
+	if err != nil || up != nil {
+		t.Errorf("UpscaleToLongEdge = %+v, %v; want nil, nil", up, err)
+	}
+}
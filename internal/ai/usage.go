@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+)
+
+// --- Gemini usage gauges (DDR-142) ---
+
+// usageTailBytes is how much of a response is kept for reading its usage
+// metadata, which Gemini writes after the candidates.
+const usageTailBytes = 8 << 10
+
+var (
+	// modelPathPattern extracts the model from a generation request path, on
+	// both the Gemini API (/v1beta/models/{model}:generateContent) and
+	// Vertex AI (.../publishers/google/models/{model}:generateContent).
+	modelPathPattern = regexp.MustCompile(`/models/([^/:]+):(?:generateContent|streamGenerateContent)$`)
+
+	promptTokensPattern     = regexp.MustCompile(`"promptTokenCount":\s*(\d+)`)
+	candidatesTokensPattern = regexp.MustCompile(`"candidatesTokenCount":\s*(\d+)`)
+	thoughtsTokensPattern   = regexp.MustCompile(`"thoughtsTokenCount":\s*(\d+)`)
+)
+
+// usageTransport counts every Gemini request as in flight until its
+// response body is closed, and records the tokens a generation request
+// consumed per model. Thinking tokens are billed as output, so they are
+// counted with it.
+type usageTransport struct {
+	base http.RoundTripper
+}
+
+func (t *usageTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	done := metrics.StartGeminiRequest()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		done()
+		return nil, err
+	}
+
+	var model string
+	if m := modelPathPattern.FindStringSubmatch(req.URL.Path); m != nil && resp.StatusCode == http.StatusOK {
+		model = m[1]
+	}
+	resp.Body = &usageBody{ReadCloser: resp.Body, done: func(tail []byte) {
+		done()
+		if model != "" {
+			input := lastCount(promptTokensPattern, tail)
+			output := lastCount(candidatesTokensPattern, tail) + lastCount(thoughtsTokensPattern, tail)
+			metrics.RecordModelTokens(model, input, output)
+		}
+	}}
+	return resp, nil
+}
+
+// usageBody keeps the last usageTailBytes read and calls done with them on
+// Close.
+type usageBody struct {
+	io.ReadCloser
+	tail []byte
+	done func(tail []byte)
+}
+
+func (b *usageBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.tail = append(b.tail, p[:n]...)
+	if len(b.tail) > usageTailBytes {
+		b.tail = append(b.tail[:0], b.tail[len(b.tail)-usageTailBytes:]...)
+	}
+	return n, err
+}
+
+func (b *usageBody) Close() error {
+	err := b.ReadCloser.Close()
+	if b.done != nil {
+		b.done(b.tail)
+		b.done = nil
+	}
+	return err
+}
+
+// lastCount returns the last count pattern matches in data. A streamed
+// response repeats usage metadata in every chunk, with the totals last.
+func lastCount(pattern *regexp.Regexp, data []byte) int64 {
+	matches := pattern.FindAllSubmatch(data, -1)
+	if len(matches) == 0 {
+		return 0
+	}
+	n, _ := strconv.ParseInt(string(matches[len(matches)-1][1]), 10, 64)
+	return n
+}
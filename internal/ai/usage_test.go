@@ -0,0 +1,74 @@
+package ai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+)
+
+type fakeUsageStore struct {
+	mu       sync.Mutex
+	inFlight []int64
+	tokens   map[string][2]int64
+}
+
+func (s *fakeUsageStore) PutGeminiInFlight(_ context.Context, _ string, n int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight = append(s.inFlight, n)
+	return nil
+}
+
+func (s *fakeUsageStore) AddModelTokens(_ context.Context, _, model string, _, input, output int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.tokens[model]
+	s.tokens[model] = [2]int64{t[0] + input, t[1] + output}
+	return nil
+}
+
+func TestUsageTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A streamed response repeats usage metadata; the last chunk holds the totals.
+		io.WriteString(w, `data: {"usageMetadata": {"promptTokenCount": 120, "candidatesTokenCount": 5}}`+"\n")
+		io.WriteString(w, `data: {"usageMetadata": {"promptTokenCount": 120, "candidatesTokenCount": 40, "thoughtsTokenCount": 10}}`+"\n")
+	}))
+	defer srv.Close()
+
+	fake := &fakeUsageStore{tokens: map[string][2]int64{}}
+	metrics.SetUsageStore(fake)
+	defer metrics.SetUsageStore(nil)
+
+	client := &http.Client{Transport: &usageTransport{base: http.DefaultTransport}}
+	for _, path := range []string{
+		"/v1beta/models/gemini-2.5-flash:streamGenerateContent",
+		"/v1beta/models/gemini-2.5-flash:countTokens",
+	} {
+		resp, err := client.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if metrics.GeminiInFlight() != 1 {
+			t.Errorf("%s: in flight = %d before the body was closed, want 1", path, metrics.GeminiInFlight())
+		}
+		io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body.Close()
+	}
+
+	if n := metrics.GeminiInFlight(); n != 0 {
+		t.Errorf("in flight = %d after all bodies closed, want 0", n)
+	}
+	metrics.FlushUsage(context.Background())
+	if n := len(fake.inFlight); n == 0 || fake.inFlight[n-1] != 0 {
+		t.Errorf("published in-flight counts %v, want the last to be 0", fake.inFlight)
+	}
+	if got, want := fake.tokens["gemini-2.5-flash"], [2]int64{120, 50}; got != want || len(fake.tokens) != 1 {
+		t.Errorf("recorded tokens %v, want only gemini-2.5-flash %v", fake.tokens, want)
+	}
+}
@@ -0,0 +1,52 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// --- Vertex AI credentials for REST clients (DDR-171) ---
+//
+// The genai SDK finds its own credentials. Clients that call Vertex AI over
+// plain HTTP, such as Imagen, get theirs here, so their tokens are refreshed
+// before they expire instead of being fixed at deploy time.
+
+// cloudPlatformScope is the OAuth2 scope Vertex AI requires.
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+var vertexTokens = sync.OnceValues(newVertexTokenSource)
+
+// VertexTokenSource returns access tokens for Vertex AI REST calls,
+// resolved from:
+//  1. VERTEX_AI_TOKEN environment variable: a fixed access token for local
+//     testing; it expires after about an hour and is never refreshed
+//  2. Application Default Credentials: the file named by
+//     GOOGLE_APPLICATION_CREDENTIALS (see LoadGCPServiceAccount), which holds
+//     a service account key or a workload identity federation config, or
+//     the metadata server
+//
+// The source is created once per process and refreshes tokens as needed.
+func VertexTokenSource() (oauth2.TokenSource, error) {
+	return vertexTokens()
+}
+
+func newVertexTokenSource() (oauth2.TokenSource, error) {
+	if token := os.Getenv("VERTEX_AI_TOKEN"); token != "" {
+		log.Warn().Msg("Using VERTEX_AI_TOKEN for Vertex AI; it is not refreshed and stops working when it expires")
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token, TokenType: "Bearer"}), nil
+	}
+	// Tokens are refreshed long after the call that created the source has
+	// returned, so the source must not depend on a request's context.
+	creds, err := google.FindDefaultCredentials(context.Background(), cloudPlatformScope)
+	if err != nil {
+		return nil, fmt.Errorf("no Vertex AI credentials: %w", err)
+	}
+	log.Info().Str("project", creds.ProjectID).Msg("Vertex AI credentials loaded for REST clients")
+	return creds.TokenSource, nil
+}
@@ -0,0 +1,90 @@
+package ai
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestVertexTokenSourceStaticToken(t *testing.T) {
+	t.Setenv("VERTEX_AI_TOKEN", "fixed")
+	ts, err := newVertexTokenSource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok, err := ts.Token(); err != nil || tok.AccessToken != "fixed" {
+		t.Errorf("Token = %+v, %v", tok, err)
+	}
+}
+
+func TestVertexTokenSourceServiceAccount(t *testing.T) {
+	var exchanges int
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		exchanges++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"minted","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sa, _ := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "test-project",
+		"private_key_id": "k1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		"client_email":   "imagen@test-project.iam.gserviceaccount.com",
+		"token_uri":      tokenServer.URL,
+	})
+	path := filepath.Join(t.TempDir(), "sa.json")
+	if err := os.WriteFile(path, sa, 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VERTEX_AI_TOKEN", "")
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", path)
+
+	ts, err := newVertexTokenSource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if tok, err := ts.Token(); err != nil || tok.AccessToken != "minted" {
+			t.Fatalf("Token = %+v, %v", tok, err)
+		}
+	}
+	if exchanges != 1 {
+		t.Errorf("token exchanges = %d, want 1 (a valid token is reused)", exchanges)
+	}
+}
+
+func TestImagenClientSendsToken(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+	}))
+	defer srv.Close()
+
+	c := NewImagenClient("p", "r", oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "abc"}))
+	if !c.IsConfigured() {
+		t.Error("client with credentials should be configured")
+	}
+	resp, err := c.httpClient.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if auth != "Bearer abc" {
+		t.Errorf("Authorization = %q", auth)
+	}
+}
@@ -29,9 +29,6 @@ type VideoEnhancementConfig struct {
 	// VertexAIRegion is the GCP region for Imagen 3 (optional).
 	VertexAIRegion string
 
-	// VertexAIAccessToken is the GCP OAuth2 access token for Imagen 3 (optional).
-	VertexAIAccessToken string
-
 	// SimilarityThreshold is the histogram correlation threshold for frame grouping.
 	// Default: 0.92
 	SimilarityThreshold float64
@@ -155,9 +152,14 @@ func EnhanceVideo(ctx context.Context, videoPath string, outputPath string, meta
 	geminiClient := NewGeminiImageClient(genaiClient)
 
 	var imagenClient *ImagenClient
-	if config.VertexAIProject != "" && config.VertexAIAccessToken != "" {
-		imagenClient = NewImagenClient(config.VertexAIProject, config.VertexAIRegion, config.VertexAIAccessToken)
-		log.Info().Msg("Imagen 3 client configured for surgical edits")
+	if config.VertexAIProject != "" {
+		// DDR-171: refreshing credentials rather than a fixed token.
+		if tokens, err := VertexTokenSource(); err != nil {
+			log.Warn().Err(err).Msg("Imagen 3 not configured — skipping mask-based edits")
+		} else {
+			imagenClient = NewImagenClient(config.VertexAIProject, config.VertexAIRegion, tokens)
+			log.Info().Msg("Imagen 3 client configured for surgical edits")
+		}
 	} else {
 		log.Info().Msg("Imagen 3 not configured — skipping mask-based edits")
 	}
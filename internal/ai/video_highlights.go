@@ -0,0 +1,210 @@
+package ai
+
+// video_highlights.go asks Gemini for the best 15–30 second moments of a
+// video, which the selection worker cuts into Reels clips. See DDR-187:
+// Video Highlight Clips for Reels.
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genai"
+
+	"github.com/fpang/ai-social-media-helper/internal/artifacts"
+	"github.com/fpang/ai-social-media-helper/internal/jsonutil"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+)
+
+// MaxVideoHighlights is the most highlights kept for one video.
+const MaxVideoHighlights = 2
+
+// VideoHighlight is a segment of a video suggested as a Reel, with why it
+// stands out.
+type VideoHighlight struct {
+	Clip   media.VideoClip
+	Reason string
+}
+
+type videoHighlightsResponse struct {
+	Highlights []struct {
+		StartSeconds float64 `json:"start_seconds"`
+		EndSeconds   float64 `json:"end_seconds"`
+		Reason       string  `json:"reason"`
+	} `json:"highlights"`
+}
+
+const videoHighlightsSystemInstruction = `You pick the best moments of a travel video to post as Instagram Reels.
+A highlight is one continuous segment of 15 to 30 seconds that works on its own: it has a clear subject, something happens, and it starts and ends cleanly rather than mid-action or mid-sentence.
+Prefer moments with motion, reaction, or a reveal over static or shaky footage, and skip segments that are blurry, dark, or pointed at the ground.
+Return only JSON: {"highlights": [{"start_seconds": number, "end_seconds": number, "reason": "why this moment works"}]}
+Give 1 or 2 highlights, best first, that do not overlap. Keep each reason to one sentence.`
+
+// SuggestVideoHighlights asks Gemini for the best 15–30 second segments of
+// a video, best first. The video is sent by its presigned URL when it has
+// one (DDR-060), otherwise compressed and uploaded like selection videos.
+// tripContext, when set, says what the trip is about. Segments are clamped
+// to the video and to the highlight bounds, so every result can be trimmed.
+func SuggestVideoHighlights(ctx context.Context, client *genai.Client, file *media.MediaFile, tripContext string) ([]VideoHighlight, error) {
+	meta, _ := file.Metadata.(*media.VideoMetadata)
+	if meta == nil || meta.Duration < media.MinHighlightDuration {
+		return nil, fmt.Errorf("suggest video highlights: %s is shorter than %s or has no duration", filepath.Base(file.Path), media.MinHighlightDuration)
+	}
+	log.Debug().Str("file", filepath.Base(file.Path)).Dur("duration", meta.Duration).Msg("SuggestVideoHighlights: starting")
+
+	videoPart, cleanup, err := videoHighlightPart(ctx, client, file, meta)
+	if err != nil {
+		return nil, fmt.Errorf("suggest video highlights: %w", err)
+	}
+	defer cleanup()
+
+	config := &genai.GenerateContentConfig{
+		SystemInstruction: &genai.Content{
+			Parts: []*genai.Part{{Text: videoHighlightsSystemInstruction}},
+		},
+		ResponseMIMEType: "application/json",
+		ThinkingConfig:   thinkingConfig(ctx), // DDR-170
+	}
+	applySampling(ctx, config)
+
+	prompt := videoHighlightsPrompt(filepath.Base(file.Path), tripContext, meta.Duration)
+	parts := []*genai.Part{videoPart, {Text: prompt}}
+
+	modelName := modelFor(ctx, GetModelName())
+	artifacts.SaveText(ctx, "video-highlights-prompt.txt", prompt)
+	callStart := time.Now()
+	resp, err := client.Models.GenerateContent(ctx, modelName, []*genai.Content{{Role: "user", Parts: parts}}, config)
+	duration := time.Since(callStart)
+	metrics.RecordGeminiCall(ctx, duration)
+
+	m := metrics.New("AiSocialMedia").
+		Dimension("Operation", "videoHighlights").
+		Metric("GeminiApiLatencyMs", float64(duration.Milliseconds()), metrics.UnitMilliseconds).
+		Count("GeminiApiCalls")
+	if err != nil {
+		m.Count("GeminiApiErrors")
+	}
+	if resp != nil && resp.UsageMetadata != nil {
+		m.Metric("GeminiInputTokens", float64(resp.UsageMetadata.PromptTokenCount), metrics.UnitCount)
+		metrics.RecordGeminiTokens(ctx, int64(resp.UsageMetadata.PromptTokenCount)) // DDR-186
+		m.Metric("GeminiOutputTokens", float64(resp.UsageMetadata.CandidatesTokenCount), metrics.UnitCount)
+	}
+	m.Flush()
+
+	if err != nil {
+		log.Error().Err(err).Dur("duration", duration).Msg("Failed to get video highlights from Gemini")
+		return nil, fmt.Errorf("suggest video highlights: %w", err)
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("suggest video highlights: empty response")
+	}
+
+	text := resp.Text()
+	artifacts.SaveText(ctx, "video-highlights-response.txt", text)
+	highlights, err := parseVideoHighlightsResponse(text, meta.Duration)
+	if err != nil {
+		log.Warn().Err(err).Str("response", truncateString(text, 500)).Msg("Failed to parse video highlights response")
+		return nil, err
+	}
+	log.Info().Str("file", filepath.Base(file.Path)).Int("highlights", len(highlights)).Dur("duration", duration).Msg("SuggestVideoHighlights: complete")
+	return highlights, nil
+}
+
+// videoHighlightPart references the video for Gemini: its presigned URL
+// when set, otherwise a compressed copy uploaded to the Files API, which
+// the returned cleanup removes.
+func videoHighlightPart(ctx context.Context, client *genai.Client, file *media.MediaFile, meta *media.VideoMetadata) (*genai.Part, func(), error) {
+	if file.PresignedURL != "" {
+		return &genai.Part{FileData: &genai.FileData{MIMEType: file.MIMEType, FileURI: file.PresignedURL}}, func() {}, nil
+	}
+	compressedPath, _, cleanupCompressed, err := media.CompressVideoForGemini(ctx, file.Path, meta)
+	if err != nil {
+		return nil, nil, fmt.Errorf("compress video: %w", err)
+	}
+	uploaded, err := uploadVideoFile(ctx, client, compressedPath)
+	cleanupCompressed()
+	if err != nil {
+		return nil, nil, fmt.Errorf("upload video: %w", err)
+	}
+	cleanup := func() {
+		if _, err := client.Files.Delete(ctx, uploaded.Name, nil); err != nil {
+			log.Warn().Err(err).Str("file", uploaded.Name).Msg("Failed to delete uploaded Gemini file")
+		}
+	}
+	return &genai.Part{FileData: &genai.FileData{MIMEType: uploaded.MIMEType, FileURI: uploaded.URI}}, cleanup, nil
+}
+
+// videoHighlightsPrompt describes the attached video.
+func videoHighlightsPrompt(filename, tripContext string, duration time.Duration) string {
+	var sb strings.Builder
+	sb.WriteString("## Video Highlights\n\n")
+	if tripContext != "" {
+		sb.WriteString(fmt.Sprintf("Trip context: %s\n", tripContext))
+	}
+	sb.WriteString(fmt.Sprintf("The attached video is %s, %s long (%.0f seconds).\n", filename, formatVideoDuration(duration), duration.Seconds()))
+	sb.WriteString("\nPick its best Reels highlights. Timestamps are seconds from the start of the video. Follow the response format in the system instruction exactly.")
+	return sb.String()
+}
+
+// parseVideoHighlightsResponse converts Gemini's segments to clips within
+// the video. A segment shorter than media.MinHighlightDuration is widened
+// around its middle and a longer one than media.MaxHighlightDuration cut
+// from its start; segments outside the video, or overlapping one already
+// kept, are dropped. At most MaxVideoHighlights are returned.
+func parseVideoHighlightsResponse(response string, duration time.Duration) ([]VideoHighlight, error) {
+	parsed, err := jsonutil.ParseJSON[videoHighlightsResponse](response)
+	if err != nil {
+		return nil, fmt.Errorf("video highlights response: %w", err)
+	}
+	var out []VideoHighlight
+	for _, h := range parsed.Highlights {
+		if len(out) == MaxVideoHighlights {
+			break
+		}
+		clip, ok := fitHighlight(highlightOffset(h.StartSeconds), highlightOffset(h.EndSeconds), duration)
+		if !ok || slices.ContainsFunc(out, func(o VideoHighlight) bool {
+			return clip.Start < o.Clip.End && o.Clip.Start < clip.End
+		}) {
+			continue
+		}
+		out = append(out, VideoHighlight{Clip: clip, Reason: strings.TrimSpace(h.Reason)})
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("video highlights response: no usable segments")
+	}
+	return out, nil
+}
+
+// fitHighlight clamps a suggested segment to the video and the highlight
+// bounds; ok is false when nothing of it lies within the video.
+func fitHighlight(start, end, duration time.Duration) (media.VideoClip, bool) {
+	start, end = max(start, 0), min(end, duration)
+	if end <= start {
+		return media.VideoClip{}, false
+	}
+	if length := end - start; length < media.MinHighlightDuration {
+		pad := (media.MinHighlightDuration - length) / 2
+		start, end = start-pad, end+pad
+		if start < 0 {
+			start, end = 0, media.MinHighlightDuration
+		}
+		if end > duration {
+			start, end = max(duration-media.MinHighlightDuration, 0), duration
+		}
+	}
+	if end-start > media.MaxHighlightDuration {
+		end = start + media.MaxHighlightDuration
+	}
+	return media.VideoClip{Start: start, End: end}, true
+}
+
+// highlightOffset converts Gemini's seconds to a duration, to the millisecond.
+func highlightOffset(s float64) time.Duration {
+	return time.Duration(math.Round(s*1000)) * time.Millisecond
+}
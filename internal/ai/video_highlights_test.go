@@ -0,0 +1,69 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+)
+
+func TestParseVideoHighlightsResponse(t *testing.T) {
+	got, err := parseVideoHighlightsResponse("```json\n"+`{"highlights": [
+		{"start_seconds": 42.5, "end_seconds": 64, "reason": " The whale breaches "},
+		{"start_seconds": 50, "end_seconds": 70, "reason": "overlaps the first"},
+		{"start_seconds": 100, "end_seconds": 150, "reason": "too long"},
+		{"start_seconds": 5, "end_seconds": 10, "reason": "a third one"}
+	]}`+"\n```", 2*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []VideoHighlight{
+		{Clip: media.VideoClip{Start: 42500 * time.Millisecond, End: 64 * time.Second}, Reason: "The whale breaches"},
+		{Clip: media.VideoClip{Start: 100 * time.Second, End: 120 * time.Second}, Reason: "too long"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("highlights = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("highlight %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if _, err := parseVideoHighlightsResponse(`{"highlights": [{"start_seconds": 200, "end_seconds": 220}]}`, time.Minute); err == nil {
+		t.Error("segment past the end of the video accepted")
+	}
+	if _, err := parseVideoHighlightsResponse("no json here", time.Minute); err == nil {
+		t.Error("non-JSON response accepted")
+	}
+}
+
+func TestFitHighlight(t *testing.T) {
+	s := time.Second
+	for _, tc := range []struct {
+		start, end, duration time.Duration
+		want                 media.VideoClip
+	}{
+		{20 * s, 40 * s, time.Minute, media.VideoClip{Start: 20 * s, End: 40 * s}},
+		{20 * s, 25 * s, time.Minute, media.VideoClip{Start: 15 * s, End: 30 * s}}, // widened around its middle
+		{0, 4 * s, time.Minute, media.VideoClip{Start: 0, End: 15 * s}},
+		{55 * s, 59 * s, time.Minute, media.VideoClip{Start: 45 * s, End: 60 * s}},
+		{10 * s, 55 * s, time.Minute, media.VideoClip{Start: 10 * s, End: 40 * s}}, // cut to 30s
+		{-5 * s, 20 * s, time.Minute, media.VideoClip{Start: 0, End: 20 * s}},
+	} {
+		got, ok := fitHighlight(tc.start, tc.end, tc.duration)
+		if !ok || got != tc.want {
+			t.Errorf("fitHighlight(%s, %s) = %+v, %v; want %+v", tc.start, tc.end, got, ok, tc.want)
+		}
+	}
+}
+
+func TestVideoHighlightsPrompt(t *testing.T) {
+	prompt := videoHighlightsPrompt("reef.mp4", "Great Barrier Reef", 95*time.Second)
+	for _, want := range []string{"Trip context: Great Barrier Reef", "reef.mp4, 1:35 long (95 seconds)"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt lacks %q:\n%s", want, prompt)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Package artifacts keeps the intermediate artifacts of an AI pipeline run —
+// prompts, raw model responses, compressed videos, and edit masks — so that a
+// bug report can include exactly what the model saw and said (DDR-106).
+//
+// Recording is opt-in per run: callers attach a Recorder to the context with
+// WithRecorder, and the pipeline code calls Save/SaveFile unconditionally.
+// Without a recorder every call is a no-op. Recording is best effort: errors
+// are logged and never fail the pipeline.
+package artifacts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Recorder stores named artifacts for one run. Names may contain "/" to group
+// related artifacts (e.g. "masks/edit-1.jpg").
+type Recorder interface {
+	Save(ctx context.Context, name string, data []byte) error
+	SaveFile(ctx context.Context, name, path string) error
+}
+
+type recorderKey struct{}
+
+// sequenced numbers artifacts in the order they were recorded so that repeated
+// calls (one prompt per triage batch, say) do not overwrite each other and the
+// run reads top to bottom.
+type sequenced struct {
+	Recorder
+	n atomic.Int64
+}
+
+func (s *sequenced) name(name string) string {
+	dir, base := path.Split(name)
+	return fmt.Sprintf("%s%03d-%s", dir, s.n.Add(1), base)
+}
+
+// WithRecorder returns a context that records artifacts to r. A nil r returns
+// ctx unchanged.
+func WithRecorder(ctx context.Context, r Recorder) context.Context {
+	if r == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, recorderKey{}, &sequenced{Recorder: r})
+}
+
+// Enabled reports whether ctx carries a recorder. Use it to skip work that is
+// only needed for recording, such as encoding a mask that is not otherwise kept.
+func Enabled(ctx context.Context) bool {
+	_, ok := ctx.Value(recorderKey{}).(*sequenced)
+	return ok
+}
+
+// Save records data under name if ctx carries a recorder.
+func Save(ctx context.Context, name string, data []byte) {
+	s, ok := ctx.Value(recorderKey{}).(*sequenced)
+	if !ok {
+		return
+	}
+	key := s.name(name)
+	if err := s.Save(ctx, key, data); err != nil {
+		log.Warn().Err(err).Str("artifact", key).Msg("Failed to record debug artifact")
+		return
+	}
+	log.Debug().Str("artifact", key).Int("bytes", len(data)).Msg("Debug artifact recorded")
+}
+
+// SaveText records text under name if ctx carries a recorder.
+func SaveText(ctx context.Context, name, text string) {
+	Save(ctx, name, []byte(text))
+}
+
+// SaveJSON records v, indented, under name if ctx carries a recorder.
+func SaveJSON(ctx context.Context, name string, v any) {
+	if !Enabled(ctx) {
+		return
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		log.Warn().Err(err).Str("artifact", name).Msg("Failed to encode debug artifact")
+		return
+	}
+	Save(ctx, name, data)
+}
+
+// SaveFile records the contents of the local file at path under name if ctx
+// carries a recorder. Use it for files the pipeline is about to delete, such
+// as compressed videos.
+func SaveFile(ctx context.Context, name, path string) {
+	s, ok := ctx.Value(recorderKey{}).(*sequenced)
+	if !ok {
+		return
+	}
+	key := s.name(name)
+	if err := s.SaveFile(ctx, key, path); err != nil {
+		log.Warn().Err(err).Str("artifact", key).Str("path", path).Msg("Failed to record debug artifact")
+		return
+	}
+	log.Debug().Str("artifact", key).Str("path", path).Msg("Debug artifact recorded")
+}
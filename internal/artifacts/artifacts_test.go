@@ -0,0 +1,93 @@
+package artifacts
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveWithoutRecorderIsNoop(t *testing.T) {
+	ctx := context.Background()
+	if Enabled(ctx) {
+		t.Fatal("Enabled on a bare context")
+	}
+	Save(ctx, "prompt.txt", []byte("x"))
+	SaveFile(ctx, "video.webm", "/does/not/exist")
+	if WithRecorder(ctx, nil) != ctx {
+		t.Error("WithRecorder(nil) should return ctx unchanged")
+	}
+}
+
+func TestDirRecorderNumbersArtifacts(t *testing.T) {
+	root := t.TempDir()
+	rec, err := NewDirRecorder(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithRecorder(context.Background(), rec)
+	if !Enabled(ctx) {
+		t.Fatal("Enabled = false with a recorder")
+	}
+
+	src := filepath.Join(t.TempDir(), "compressed.webm")
+	if err := os.WriteFile(src, []byte("video"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	SaveText(ctx, "triage-prompt.txt", "first")
+	SaveText(ctx, "triage-prompt.txt", "second")
+	SaveFile(ctx, "compressed/clip.mov.webm", src)
+	SaveJSON(ctx, "result.json", map[string]int{"n": 1})
+
+	want := map[string]string{
+		"001-triage-prompt.txt":        "first",
+		"002-triage-prompt.txt":        "second",
+		"compressed/003-clip.mov.webm": "video",
+		"004-result.json":              "{\n  \"n\": 1\n}",
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(rec.Dir(), filepath.FromSlash(name)))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("%s = %q, want %q", name, got, content)
+		}
+	}
+}
+
+func TestDirRecorderRejectsEscapingNames(t *testing.T) {
+	rec, err := NewDirRecorder(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Save(context.Background(), "../outside.txt", []byte("x")); err == nil {
+		t.Error("Save outside the run directory succeeded")
+	}
+}
+
+func TestPruneDir(t *testing.T) {
+	root := t.TempDir()
+	old := filepath.Join(root, "old")
+	fresh := filepath.Join(root, "fresh")
+	for _, dir := range []string{old, fresh} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stale := time.Now().Add(-2 * DefaultMaxAge)
+	if err := os.Chtimes(old, stale, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	PruneDir(root, DefaultMaxAge)
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("old run was not pruned")
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Error("fresh run was pruned")
+	}
+	PruneDir(filepath.Join(root, "missing"), DefaultMaxAge)
+}
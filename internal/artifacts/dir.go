@@ -0,0 +1,101 @@
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultDir is where the CLIs keep debug artifacts, relative to the working
+// directory.
+const DefaultDir = ".debug"
+
+// DefaultMaxAge is how long local runs are kept before PruneDir removes them,
+// mirroring the expiry the media bucket applies to cloud runs.
+const DefaultMaxAge = 7 * 24 * time.Hour
+
+// DirRecorder writes artifacts to a local directory, one per run.
+type DirRecorder struct {
+	dir string
+}
+
+// NewDirRecorder creates a run directory named after the current time under
+// root and returns a recorder that writes into it.
+func NewDirRecorder(root string) (*DirRecorder, error) {
+	dir := filepath.Join(root, time.Now().Format("20060102-150405"))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create debug dir: %w", err)
+	}
+	return &DirRecorder{dir: dir}, nil
+}
+
+// Dir returns the run directory.
+func (d *DirRecorder) Dir() string { return d.dir }
+
+func (d *DirRecorder) Save(_ context.Context, name string, data []byte) error {
+	path, err := d.path(name)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (d *DirRecorder) SaveFile(_ context.Context, name, src string) error {
+	path, err := d.path(name)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", src, err)
+	}
+	defer in.Close()
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("copy %s: %w", src, err)
+	}
+	return out.Close()
+}
+
+func (d *DirRecorder) path(name string) (string, error) {
+	path := filepath.Join(d.dir, filepath.FromSlash(name))
+	if rel, err := filepath.Rel(d.dir, path); err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("artifact name %q escapes debug dir", name)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("create debug dir: %w", err)
+	}
+	return path, nil
+}
+
+// PruneDir removes run directories under root older than maxAge, the local
+// counterpart of the bucket lifecycle rule. Missing root is not an error.
+func PruneDir(root string, maxAge time.Duration) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-maxAge)
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(root, e.Name())); err != nil {
+			log.Warn().Err(err).Str("dir", e.Name()).Msg("Failed to prune debug artifacts")
+		}
+	}
+}
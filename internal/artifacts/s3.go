@@ -0,0 +1,65 @@
+package artifacts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
+)
+
+// S3Recorder writes artifacts under a key prefix in the media bucket. Objects
+// there expire with the rest of the session under the bucket's 1-day
+// lifecycle rule (DDR-059), so nothing needs to clean them up.
+type S3Recorder struct {
+	client *s3.Client
+	bucket string
+	prefix string
+	enc    s3util.Encryption
+}
+
+// SessionPrefix is the key prefix for one job's artifacts:
+// {sessionId}/debug/{jobId}. Listings of session uploads skip subdirectories,
+// so nothing under it is mistaken for user media.
+func SessionPrefix(sessionID, jobID string) string {
+	return fmt.Sprintf("%s/debug/%s", sessionID, jobID)
+}
+
+// NewS3Recorder returns a recorder that writes to bucket under prefix,
+// encrypted with enc, the session's encryption (DDR-164).
+func NewS3Recorder(client *s3.Client, bucket, prefix string, enc s3util.Encryption) *S3Recorder {
+	return &S3Recorder{client: client, bucket: bucket, prefix: prefix, enc: enc}
+}
+
+func (r *S3Recorder) Save(ctx context.Context, name string, data []byte) error {
+	key := r.prefix + "/" + name
+	if _, err := r.client.PutObject(ctx, r.enc.Put(&s3.PutObjectInput{
+		Bucket:  &r.bucket,
+		Key:     &key,
+		Body:    bytes.NewReader(data),
+		Tagging: s3util.ProjectTagging(),
+	})); err != nil {
+		return fmt.Errorf("S3 PutObject %s: %w", key, err)
+	}
+	return nil
+}
+
+func (r *S3Recorder) SaveFile(ctx context.Context, name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", path, err)
+	}
+	defer f.Close()
+	key := r.prefix + "/" + name
+	if _, err := r.client.PutObject(ctx, r.enc.Put(&s3.PutObjectInput{
+		Bucket:  &r.bucket,
+		Key:     &key,
+		Body:    f,
+		Tagging: s3util.ProjectTagging(),
+	})); err != nil {
+		return fmt.Errorf("S3 PutObject %s: %w", key, err)
+	}
+	return nil
+}
@@ -13,12 +13,19 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/rs/zerolog/log"
 
+	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/facebook"
+	"github.com/fpang/ai-social-media-helper/internal/flags"
 	"github.com/fpang/ai-social-media-helper/internal/instagram"
 	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/mastodon"
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/fpang/ai-social-media-helper/internal/s3util"
 	"github.com/fpang/ai-social-media-helper/internal/store"
 )
 
@@ -28,11 +35,13 @@ type AWSClients struct {
 	SSM    *ssm.Client
 }
 
-// S3Clients holds S3 client, presigner, and bucket name.
+// S3Clients holds S3 client, presigner, bucket name, and the per-session
+// encryption keys objects in the bucket are written with (DDR-164).
 type S3Clients struct {
 	Client    *s3.Client
 	Presigner *s3.PresignClient
 	Bucket    string
+	Keys      *s3util.SessionKeys
 }
 
 // InitAWS loads the default AWS config and returns it along with common clients.
@@ -60,6 +69,7 @@ func InitS3(cfg aws.Config, bucketEnvVar string) S3Clients {
 		Client:    client,
 		Presigner: s3.NewPresignClient(client),
 		Bucket:    bucket,
+		Keys:      s3util.NewSessionKeys(kms.NewFromConfig(cfg)),
 	}
 }
 
@@ -70,8 +80,7 @@ func InitDynamo(cfg aws.Config, tableEnvVar string) *store.DynamoStore {
 	if tableName == "" {
 		log.Fatal().Str("envVar", tableEnvVar).Msg("DynamoDB table environment variable is required")
 	}
-	ddbClient := dynamodb.NewFromConfig(cfg)
-	return store.NewDynamoStore(ddbClient, tableName)
+	return newDynamoStore(cfg, tableName)
 }
 
 // InitDynamoOptional creates a DynamoDB session store if the env var is set.
@@ -82,15 +91,29 @@ func InitDynamoOptional(cfg aws.Config, tableEnvVar string) *store.DynamoStore {
 		log.Warn().Str("envVar", tableEnvVar).Msg("DynamoDB table not set — store disabled")
 		return nil
 	}
-	ddbClient := dynamodb.NewFromConfig(cfg)
-	return store.NewDynamoStore(ddbClient, tableName)
+	return newDynamoStore(cfg, tableName)
+}
+
+// newDynamoStore creates the store and makes it the destination of the
+// process's Gemini usage gauges (DDR-142).
+func newDynamoStore(cfg aws.Config, tableName string) *store.DynamoStore {
+	s := store.NewDynamoStore(dynamodb.NewFromConfig(cfg), tableName)
+	metrics.SetUsageStore(s)
+	return s
 }
 
 // LoadParameters fetches multiple SSM parameters in a single GetParameters call.
+// A "secretsmanager:{id}" name reads a Secrets Manager secret (DDR-172).
 func LoadParameters(ssmClient *ssm.Client, names []string) map[string]string {
 	ssmStart := time.Now()
+	ssmNames := make([]string, len(names))
+	requested := make(map[string]string, len(names))
+	for i, name := range names {
+		ssmNames[i] = ssmParameterName(name)
+		requested[ssmNames[i]] = name
+	}
 	result, err := ssmClient.GetParameters(context.Background(), &ssm.GetParametersInput{
-		Names:          names,
+		Names:          ssmNames,
 		WithDecryption: aws.Bool(true),
 	})
 	if err != nil {
@@ -98,7 +121,11 @@ func LoadParameters(ssmClient *ssm.Client, names []string) map[string]string {
 	}
 	params := make(map[string]string, len(result.Parameters))
 	for _, p := range result.Parameters {
-		params[*p.Name] = *p.Value
+		name, ok := requested[*p.Name]
+		if !ok {
+			name = *p.Name
+		}
+		params[name] = *p.Value
 	}
 	if len(result.InvalidParameters) > 0 {
 		log.Warn().Strs("params", result.InvalidParameters).Msg("SSM parameters not found")
@@ -112,7 +139,8 @@ func LoadParameters(ssmClient *ssm.Client, names []string) map[string]string {
 }
 
 // LoadGeminiKey fetches the Gemini API key from SSM Parameter Store if not
-// already set via GEMINI_API_KEY env var. Fatals on error.
+// already set via GEMINI_API_KEY env var. Fatals on error. The key is read
+// again after SecretTTL, or at once when Gemini rejects it (DDR-172).
 func LoadGeminiKey(ssmClient *ssm.Client) {
 	if os.Getenv("GEMINI_API_KEY") != "" {
 		return
@@ -124,6 +152,7 @@ func LoadGeminiKey(ssmClient *ssm.Client) {
 	params := LoadParameters(ssmClient, []string{paramName})
 	if val, ok := params[paramName]; ok {
 		os.Setenv("GEMINI_API_KEY", val)
+		ai.SetAPIKeySource(NewSecret(ssmClient, paramName).seed(val))
 	} else {
 		log.Fatal().Str("param", paramName).Msg("Failed to read API key from SSM")
 	}
@@ -132,6 +161,9 @@ func LoadGeminiKey(ssmClient *ssm.Client) {
 // LoadGCPServiceAccountKey fetches the GCP service account JSON from SSM Parameter
 // Store if not already set via GCP_SERVICE_ACCOUNT_JSON env var. Non-fatal: logs a
 // warning if missing (Vertex AI will be unavailable; Gemini API fallback used).
+// The JSON may also be a workload identity federation config, and a
+// SSM_GCP_SA_PARAM of "/aws/reference/secretsmanager/{secret}" reads it from
+// Secrets Manager instead (DDR-171).
 func LoadGCPServiceAccountKey(ssmClient *ssm.Client) {
 	if os.Getenv("GCP_SERVICE_ACCOUNT_JSON") != "" {
 		return
@@ -148,12 +180,30 @@ func LoadGCPServiceAccountKey(ssmClient *ssm.Client) {
 	}
 }
 
+// InitFlags returns the deployment's feature flags (DDR-132), read from the SSM
+// parameter named by SSM_FEATURE_FLAGS_PARAM. Values are loaded lazily on the
+// first evaluation, so this adds no cold-start latency.
+func InitFlags(ssmClient *ssm.Client) *flags.Set {
+	paramName := os.Getenv("SSM_FEATURE_FLAGS_PARAM")
+	if paramName == "" {
+		paramName = flags.DefaultSSMParam
+	}
+	return flags.New(flags.NewSSMSource(ssmClient, paramName), flags.DefaultTTL)
+}
+
 // LoadInstagramCreds fetches Instagram access token and user ID from SSM
 // Parameter Store. Returns an Instagram client if both are available, nil otherwise.
-// Non-fatal: logs a warning if credentials are missing.
+// Non-fatal: logs a warning if credentials are missing. With
+// INSTAGRAM_MODE=mock it returns a simulating client instead (DDR-139).
+// A token from SSM is read again after SecretTTL, or at once when Instagram
+// rejects it (DDR-172).
 func LoadInstagramCreds(ssmClient *ssm.Client) *instagram.Client {
+	if instagram.MockEnabled() {
+		return newMockInstagram()
+	}
 	igAccessToken := os.Getenv("INSTAGRAM_ACCESS_TOKEN")
 	igUserID := os.Getenv("INSTAGRAM_USER_ID")
+	var tokenSecret *Secret
 
 	if igAccessToken == "" || igUserID == "" {
 		tokenParam := os.Getenv("SSM_INSTAGRAM_TOKEN_PARAM")
@@ -168,6 +218,7 @@ func LoadInstagramCreds(ssmClient *ssm.Client) *instagram.Client {
 		params := LoadParameters(ssmClient, []string{tokenParam, userIDParam})
 		if v, ok := params[tokenParam]; ok {
 			igAccessToken = v
+			tokenSecret = NewSecret(ssmClient, tokenParam).seed(v)
 		}
 		if v, ok := params[userIDParam]; ok {
 			igUserID = v
@@ -176,6 +227,9 @@ func LoadInstagramCreds(ssmClient *ssm.Client) *instagram.Client {
 
 	if igAccessToken != "" && igUserID != "" {
 		client := instagram.NewClient(igAccessToken, igUserID)
+		if tokenSecret != nil {
+			client.WithTokenSource(tokenSecret)
+		}
 		log.Info().Str("userId", igUserID).Msg("Instagram client initialized")
 		return client
 	}
@@ -183,11 +237,80 @@ func LoadInstagramCreds(ssmClient *ssm.Client) *instagram.Client {
 	return nil
 }
 
+// LoadFacebookCreds fetches the Facebook Page access token and Page ID from
+// SSM Parameter Store (DDR-147). Returns a Facebook client if both are
+// available, nil otherwise. Non-fatal: logs a warning if credentials are
+// missing. With FACEBOOK_MODE=mock it returns a simulating client instead.
+func LoadFacebookCreds(ssmClient *ssm.Client) *facebook.Client {
+	if facebook.MockEnabled() {
+		log.Warn().Msg("Facebook mock mode (FACEBOOK_MODE=mock) — nothing will be posted")
+		return facebook.NewMockClient()
+	}
+	pageToken := os.Getenv("FACEBOOK_PAGE_TOKEN")
+	pageID := os.Getenv("FACEBOOK_PAGE_ID")
+
+	if pageToken == "" || pageID == "" {
+		tokenParam := logging.EnvOrDefault("SSM_FACEBOOK_PAGE_TOKEN_PARAM", "/ai-social-media/prod/facebook-page-token")
+		pageIDParam := logging.EnvOrDefault("SSM_FACEBOOK_PAGE_ID_PARAM", "/ai-social-media/prod/facebook-page-id")
+
+		params := LoadParameters(ssmClient, []string{tokenParam, pageIDParam})
+		if v, ok := params[tokenParam]; ok {
+			pageToken = v
+		}
+		if v, ok := params[pageIDParam]; ok {
+			pageID = v
+		}
+	}
+
+	if pageToken != "" && pageID != "" {
+		log.Info().Str("pageId", pageID).Msg("Facebook client initialized")
+		return facebook.NewClient(pageToken, pageID)
+	}
+	log.Warn().Msg("Facebook credentials not configured — Facebook publishing disabled")
+	return nil
+}
+
+// LoadMastodonCreds fetches the Mastodon-compatible instance URL and access
+// token from SSM Parameter Store (DDR-151). Returns a client if both are
+// available, nil otherwise. Non-fatal: logs a warning if credentials are
+// missing. With MASTODON_MODE=mock it returns a simulating client instead.
+func LoadMastodonCreds(ssmClient *ssm.Client) *mastodon.Client {
+	if mastodon.MockEnabled() {
+		log.Warn().Msg("Mastodon mock mode (MASTODON_MODE=mock) — nothing will be posted")
+		return mastodon.NewMockClient()
+	}
+	instanceURL := os.Getenv("MASTODON_INSTANCE_URL")
+	accessToken := os.Getenv("MASTODON_ACCESS_TOKEN")
+
+	if instanceURL == "" || accessToken == "" {
+		instanceParam := logging.EnvOrDefault("SSM_MASTODON_INSTANCE_URL_PARAM", "/ai-social-media/prod/mastodon-instance-url")
+		tokenParam := logging.EnvOrDefault("SSM_MASTODON_TOKEN_PARAM", "/ai-social-media/prod/mastodon-access-token")
+
+		params := LoadParameters(ssmClient, []string{instanceParam, tokenParam})
+		if v, ok := params[instanceParam]; ok {
+			instanceURL = v
+		}
+		if v, ok := params[tokenParam]; ok {
+			accessToken = v
+		}
+	}
+
+	if instanceURL != "" && accessToken != "" {
+		client := mastodon.NewClient(instanceURL, accessToken)
+		log.Info().Str("instance", client.InstanceURL()).Msg("Mastodon client initialized")
+		return client
+	}
+	log.Warn().Msg("Mastodon credentials not configured — Mastodon publishing disabled")
+	return nil
+}
+
 // LoadAllParams fetches Gemini + Instagram credentials in a single SSM call.
 // Use instead of separate LoadGeminiKey + LoadInstagramCreds for minimal cold-start latency.
+// Both are refreshed as those functions refresh them (DDR-172).
 func LoadAllParams(ssmClient *ssm.Client) *instagram.Client {
+	mockIG := instagram.MockEnabled()
 	needGemini := os.Getenv("GEMINI_API_KEY") == ""
-	needIG := os.Getenv("INSTAGRAM_ACCESS_TOKEN") == "" || os.Getenv("INSTAGRAM_USER_ID") == ""
+	needIG := !mockIG && (os.Getenv("INSTAGRAM_ACCESS_TOKEN") == "" || os.Getenv("INSTAGRAM_USER_ID") == "")
 
 	geminiParam := os.Getenv("SSM_API_KEY_PARAM")
 	if geminiParam == "" {
@@ -217,16 +340,22 @@ func LoadAllParams(ssmClient *ssm.Client) *instagram.Client {
 	if needGemini {
 		if val, ok := params[geminiParam]; ok {
 			os.Setenv("GEMINI_API_KEY", val)
+			ai.SetAPIKeySource(NewSecret(ssmClient, geminiParam).seed(val))
 		} else {
 			log.Fatal().Str("param", geminiParam).Msg("Failed to read API key from SSM")
 		}
 	}
+	if mockIG {
+		return newMockInstagram()
+	}
 
 	igAccessToken := os.Getenv("INSTAGRAM_ACCESS_TOKEN")
 	igUserID := os.Getenv("INSTAGRAM_USER_ID")
+	var tokenSecret *Secret
 	if needIG {
 		if v, ok := params[tokenParam]; ok {
 			igAccessToken = v
+			tokenSecret = NewSecret(ssmClient, tokenParam).seed(v)
 		}
 		if v, ok := params[userIDParam]; ok {
 			igUserID = v
@@ -235,6 +364,9 @@ func LoadAllParams(ssmClient *ssm.Client) *instagram.Client {
 
 	if igAccessToken != "" && igUserID != "" {
 		client := instagram.NewClient(igAccessToken, igUserID)
+		if tokenSecret != nil {
+			client.WithTokenSource(tokenSecret)
+		}
 		log.Info().Str("userId", igUserID).Msg("Instagram client initialized")
 		return client
 	}
@@ -242,6 +374,12 @@ func LoadAllParams(ssmClient *ssm.Client) *instagram.Client {
 	return nil
 }
 
+// newMockInstagram returns the simulating Instagram client (DDR-139).
+func newMockInstagram() *instagram.Client {
+	log.Warn().Msg("Instagram mock mode (INSTAGRAM_MODE=mock) — nothing will be posted")
+	return instagram.NewMockClient()
+}
+
 // StartupLog is a convenience wrapper for the startup logger.
 func StartupLog(name string, initStart time.Time) *logging.StartupLogger {
 	return logging.NewStartupLogger(name).InitDuration(time.Since(initStart))
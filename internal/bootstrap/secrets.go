@@ -0,0 +1,216 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Refreshing secrets (DDR-172) ---
+//
+// A value read once at cold start outlives its rotation in a warm Lambda.
+// A Secret rereads its value after a TTL, and clients reload it at once
+// when the upstream service rejects it (see ai.SetAPIKeySource and
+// instagram.Client.WithTokenSource).
+
+// DefaultSecretTTL is how long a secret's value is used before it is read
+// again.
+const DefaultSecretTTL = 15 * time.Minute
+
+// minSecretRefresh is the least time between two forced reloads of one
+// secret, so a credential that is wrong everywhere does not flood SSM.
+const minSecretRefresh = 30 * time.Second
+
+// secretsManagerPrefix is the SSM path that reads a Secrets Manager secret
+// through Parameter Store.
+const secretsManagerPrefix = "/aws/reference/secretsmanager/"
+
+// SecretTTL returns how long secret values are cached, resolved from:
+// 1. SECRETS_TTL environment variable (e.g., "5m")
+// 2. Default: 15m
+func SecretTTL() time.Duration {
+	env := os.Getenv("SECRETS_TTL")
+	if env == "" {
+		return DefaultSecretTTL
+	}
+	d, err := time.ParseDuration(env)
+	if err != nil || d <= 0 {
+		log.Warn().Str("value", env).Msg("Invalid SECRETS_TTL, using the default")
+		return DefaultSecretTTL
+	}
+	return d
+}
+
+// parameterStore is the SSM calls a Secret needs.
+type parameterStore interface {
+	GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+	PutParameter(ctx context.Context, params *ssm.PutParameterInput, optFns ...func(*ssm.Options)) (*ssm.PutParameterOutput, error)
+}
+
+// secretWriter is the Secrets Manager call Store needs. Parameter Store
+// references are read-only, so writes go to Secrets Manager itself.
+type secretWriter interface {
+	PutSecretValue(ctx context.Context, params *secretsmanager.PutSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.PutSecretValueOutput, error)
+}
+
+// Secret is one secret value, read from SSM Parameter Store or Secrets
+// Manager and cached for SecretTTL. It is safe for concurrent use.
+type Secret struct {
+	name string
+	ssm  parameterStore
+	sm   secretWriter // set by WithSecretsManager; only Store uses it
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu       sync.Mutex
+	value    string
+	loadedAt time.Time
+	forcedAt time.Time // last Refresh that read the value again
+}
+
+// NewSecret returns the secret called name. An SSM parameter path is read
+// from Parameter Store; "secretsmanager:{id}" or
+// "/aws/reference/secretsmanager/{id}" is read from Secrets Manager through
+// Parameter Store. The value is not read until first used.
+func NewSecret(ssmClient *ssm.Client, name string) *Secret {
+	return newSecret(ssmClient, name, SecretTTL())
+}
+
+func newSecret(api parameterStore, name string, ttl time.Duration) *Secret {
+	return &Secret{name: name, ssm: api, ttl: ttl, now: time.Now}
+}
+
+// Name returns the name the secret was created with.
+func (s *Secret) Name() string {
+	return s.name
+}
+
+// WithSecretsManager lets Store write a Secrets Manager secret. It returns s.
+func (s *Secret) WithSecretsManager(client *secretsmanager.Client) *Secret {
+	s.sm = client
+	return s
+}
+
+// seed sets a value already read, such as one from a batched cold-start
+// fetch, so the first use does not read it again.
+func (s *Secret) seed(value string) *Secret {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.value, s.loadedAt = value, s.now()
+	return s
+}
+
+// Value returns the secret's value, reading it again once the TTL has
+// passed. If that read fails, the previous value is kept and used.
+func (s *Secret) Value(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.value != "" && s.now().Sub(s.loadedAt) < s.ttl {
+		return s.value, nil
+	}
+	if err := s.load(ctx); err != nil {
+		if s.value != "" {
+			log.Warn().Err(err).Str("secret", s.name).Msg("Failed to reread secret, keeping the cached value")
+			return s.value, nil
+		}
+		return "", err
+	}
+	return s.value, nil
+}
+
+// Refresh reads the secret again at once, after the upstream service
+// rejected its value, and returns the new value. Within 30 s of the last
+// read or forced read it returns the cached value instead.
+func (s *Secret) Refresh(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if s.value != "" && (now.Sub(s.loadedAt) < minSecretRefresh || now.Sub(s.forcedAt) < minSecretRefresh) {
+		return s.value, nil
+	}
+	s.forcedAt = now
+	if err := s.load(ctx); err != nil {
+		return s.value, err
+	}
+	return s.value, nil
+}
+
+// Store writes value as the secret's new value, as an SSM SecureString or a
+// new Secrets Manager version, and uses it from now on (DDR-173). Other
+// Lambdas pick it up within their SecretTTL.
+func (s *Secret) Store(ctx context.Context, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id, ok := secretsManagerID(s.name); ok {
+		if s.sm == nil {
+			return fmt.Errorf("store secret %s: no Secrets Manager client", s.name)
+		}
+		if _, err := s.sm.PutSecretValue(ctx, &secretsmanager.PutSecretValueInput{
+			SecretId:     aws.String(id),
+			SecretString: aws.String(value),
+		}); err != nil {
+			return fmt.Errorf("store secret %s: %w", s.name, err)
+		}
+	} else if _, err := s.ssm.PutParameter(ctx, &ssm.PutParameterInput{
+		Name:      aws.String(s.name),
+		Value:     aws.String(value),
+		Type:      ssmtypes.ParameterTypeSecureString,
+		Overwrite: aws.Bool(true),
+	}); err != nil {
+		return fmt.Errorf("store secret %s: %w", s.name, err)
+	}
+	changed := value != s.value
+	s.value, s.loadedAt = value, s.now()
+	log.Info().Str("secret", s.name).Bool("changed", changed).Msg("Secret stored")
+	return nil
+}
+
+// load reads the value. The caller holds s.mu.
+func (s *Secret) load(ctx context.Context) error {
+	start := time.Now()
+	out, err := s.ssm.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           aws.String(ssmParameterName(s.name)),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return fmt.Errorf("read secret %s: %w", s.name, err)
+	}
+	if out.Parameter == nil || aws.ToString(out.Parameter.Value) == "" {
+		return fmt.Errorf("secret %s is empty", s.name)
+	}
+	value := aws.ToString(out.Parameter.Value)
+	changed := s.value != "" && value != s.value
+	s.value, s.loadedAt = value, s.now()
+	ev := log.Debug()
+	if changed {
+		ev = log.Info()
+	}
+	ev.Str("secret", s.name).Bool("changed", changed).Dur("elapsed", time.Since(start)).Msg("Secret loaded")
+	return nil
+}
+
+// ssmParameterName maps a secret name to the SSM parameter that reads it.
+func ssmParameterName(name string) string {
+	if id, ok := strings.CutPrefix(name, "secretsmanager:"); ok {
+		return secretsManagerPrefix + id
+	}
+	return name
+}
+
+// secretsManagerID returns the Secrets Manager secret a name refers to, if
+// it refers to one.
+func secretsManagerID(name string) (string, bool) {
+	if id, ok := strings.CutPrefix(name, "secretsmanager:"); ok {
+		return id, true
+	}
+	return strings.CutPrefix(name, secretsManagerPrefix)
+}
@@ -0,0 +1,133 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// fakeParameters serves one value per name and records the names read.
+type fakeParameters struct {
+	values map[string]string
+	reads  []string
+	err    error
+}
+
+func (f *fakeParameters) GetParameter(_ context.Context, in *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	name := aws.ToString(in.Name)
+	f.reads = append(f.reads, name)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &ssm.GetParameterOutput{Parameter: &types.Parameter{Name: in.Name, Value: aws.String(f.values[name])}}, nil
+}
+
+func (f *fakeParameters) PutParameter(_ context.Context, in *ssm.PutParameterInput, _ ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+	if in.Type != types.ParameterTypeSecureString || !aws.ToBool(in.Overwrite) {
+		return nil, errors.New("want an overwritten SecureString")
+	}
+	f.values[aws.ToString(in.Name)] = aws.ToString(in.Value)
+	return &ssm.PutParameterOutput{}, nil
+}
+
+// fakeSecrets records the Secrets Manager values written.
+type fakeSecrets map[string]string
+
+func (f fakeSecrets) PutSecretValue(_ context.Context, in *secretsmanager.PutSecretValueInput, _ ...func(*secretsmanager.Options)) (*secretsmanager.PutSecretValueOutput, error) {
+	f[aws.ToString(in.SecretId)] = aws.ToString(in.SecretString)
+	return &secretsmanager.PutSecretValueOutput{}, nil
+}
+
+func TestSecretCachesForTTL(t *testing.T) {
+	ctx := context.Background()
+	params := &fakeParameters{values: map[string]string{"/key": "v1"}}
+	s := newSecret(params, "/key", time.Minute)
+	now := time.Unix(1000, 0)
+	s.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if v, err := s.Value(ctx); err != nil || v != "v1" {
+			t.Fatalf("Value = %q, %v", v, err)
+		}
+	}
+	if len(params.reads) != 1 {
+		t.Errorf("reads = %d, want 1 within the TTL", len(params.reads))
+	}
+
+	params.values["/key"] = "v2"
+	now = now.Add(2 * time.Minute)
+	if v, _ := s.Value(ctx); v != "v2" {
+		t.Errorf("after the TTL Value = %q, want v2", v)
+	}
+
+	// A failed reread keeps the cached value.
+	params.err = errors.New("throttled")
+	now = now.Add(2 * time.Minute)
+	if v, err := s.Value(ctx); err != nil || v != "v2" {
+		t.Errorf("failed reread: Value = %q, %v", v, err)
+	}
+}
+
+func TestSecretRefresh(t *testing.T) {
+	ctx := context.Background()
+	params := &fakeParameters{values: map[string]string{"/token": "old"}}
+	s := newSecret(params, "/token", time.Hour)
+	now := time.Unix(1000, 0)
+	s.now = func() time.Time { return now }
+	s.seed("old")
+
+	// Just loaded: a refresh is throttled.
+	if v, _ := s.Refresh(ctx); v != "old" || len(params.reads) != 0 {
+		t.Errorf("throttled refresh = %q after %d reads", v, len(params.reads))
+	}
+	params.values["/token"] = "new"
+	now = now.Add(time.Minute)
+	if v, err := s.Refresh(ctx); err != nil || v != "new" {
+		t.Errorf("Refresh = %q, %v", v, err)
+	}
+	if v, _ := s.Value(ctx); v != "new" || len(params.reads) != 1 {
+		t.Errorf("Value after refresh = %q after %d reads", v, len(params.reads))
+	}
+}
+
+func TestSecretsManagerName(t *testing.T) {
+	params := &fakeParameters{values: map[string]string{"/aws/reference/secretsmanager/prod/gemini": "k"}}
+	s := newSecret(params, "secretsmanager:prod/gemini", time.Hour)
+	if v, err := s.Value(context.Background()); err != nil || v != "k" {
+		t.Errorf("Value = %q, %v (read %v)", v, err, params.reads)
+	}
+	if got := ssmParameterName("/ai-social-media/prod/gemini-api-key"); got != "/ai-social-media/prod/gemini-api-key" {
+		t.Errorf("SSM path changed to %q", got)
+	}
+}
+
+func TestSecretStore(t *testing.T) {
+	ctx := context.Background()
+	params := &fakeParameters{values: map[string]string{"/token": "old"}}
+	s := newSecret(params, "/token", time.Hour)
+	if err := s.Store(ctx, "new"); err != nil {
+		t.Fatal(err)
+	}
+	if params.values["/token"] != "new" {
+		t.Errorf("SSM value = %q, want new", params.values["/token"])
+	}
+	if v, _ := s.Value(ctx); v != "new" || len(params.reads) != 0 {
+		t.Errorf("Value after Store = %q after %d reads", v, len(params.reads))
+	}
+
+	sm := newSecret(params, "secretsmanager:prod/instagram", time.Hour)
+	if err := sm.Store(ctx, "x"); err == nil {
+		t.Error("Store without a Secrets Manager client should fail")
+	}
+	secrets := fakeSecrets{}
+	sm.sm = secrets
+	if err := sm.Store(ctx, "x"); err != nil || secrets["prod/instagram"] != "x" {
+		t.Errorf("Store = %v, secrets %v", err, secrets)
+	}
+}
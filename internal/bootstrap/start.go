@@ -0,0 +1,32 @@
+package bootstrap
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/lambda"
+
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+)
+
+// Start runs handler as the Lambda's entry point, like lambda.Start, and
+// writes the buffered Gemini usage readings (DDR-142) before each invocation
+// returns. Lambda freezes the instance once the handler returns, so a write
+// left to the background writer could be lost or land only on the next
+// invocation.
+func Start(handler interface{}) {
+	lambda.Start(flushUsage(lambda.NewHandler(handler)))
+}
+
+// usageFlushHandler flushes the usage readings after each invocation of next.
+type usageFlushHandler struct {
+	next lambda.Handler
+}
+
+func flushUsage(next lambda.Handler) lambda.Handler {
+	return usageFlushHandler{next: next}
+}
+
+func (h usageFlushHandler) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	defer metrics.FlushUsage(ctx)
+	return h.next.Invoke(ctx, payload)
+}
@@ -0,0 +1,46 @@
+package bootstrap
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambda"
+
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+)
+
+// recordingUsageStore keeps the token readings written to it.
+type recordingUsageStore struct {
+	mu     sync.Mutex
+	tokens map[string]int64
+}
+
+func (s *recordingUsageStore) PutGeminiInFlight(context.Context, string, int64) error { return nil }
+
+func (s *recordingUsageStore) AddModelTokens(_ context.Context, _, model string, _, input, _ int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[model] += input
+	return nil
+}
+
+func TestStartFlushesUsageBeforeReturning(t *testing.T) {
+	s := &recordingUsageStore{tokens: map[string]int64{}}
+	metrics.SetUsageStore(s)
+	defer metrics.SetUsageStore(nil)
+
+	h := flushUsage(lambda.NewHandler(func(ctx context.Context, event map[string]string) (string, error) {
+		metrics.RecordModelTokens("gemini-2.5-flash", 120, 30)
+		return "ok", nil
+	}))
+	if _, err := h.Invoke(context.Background(), []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if got := s.tokens["gemini-2.5-flash"]; got != 120 {
+		t.Errorf("input tokens stored when the invocation returned = %d, want 120", got)
+	}
+}
@@ -0,0 +1,146 @@
+// Package brand applies a user's brand kit (DDR-149): the logo and palette
+// shape watermark overlays, and the hashtag bank and sign-off shape captions,
+// so every session's output looks and reads the same.
+package brand
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+const (
+	// MaxPaletteColors caps the colors in a kit.
+	MaxPaletteColors = 6
+	// MaxHashtags caps the hashtag bank.
+	MaxHashtags = 30
+	// MaxSignOffLength caps the caption sign-off.
+	MaxSignOffLength = 200
+	// MaxLogoBytes caps the logo PNG; it is applied as a watermark logo.
+	MaxLogoBytes = media.MaxOverlayLogoBytes
+)
+
+// Normalize trims and validates the user-editable fields of a kit. Colors
+// are stored as lowercase "#rrggbb"; hashtags without the leading '#', as
+// the description step returns them. LogoKey and UpdatedAt are copied as is.
+func Normalize(k store.BrandKit) (*store.BrandKit, error) {
+	out := &store.BrandKit{
+		LogoKey:   k.LogoKey,
+		SignOff:   strings.TrimSpace(k.SignOff),
+		UpdatedAt: k.UpdatedAt,
+	}
+	for _, c := range k.Palette {
+		rgba, err := ParseColor(c)
+		if err != nil {
+			return nil, err
+		}
+		out.Palette = append(out.Palette, fmt.Sprintf("#%02x%02x%02x", rgba.R, rgba.G, rgba.B))
+	}
+	seen := map[string]bool{}
+	for _, tag := range k.Hashtags {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		if strings.ContainsAny(tag, " \t\n#") {
+			return nil, fmt.Errorf("invalid hashtag %q", tag)
+		}
+		seen[strings.ToLower(tag)] = true
+		out.Hashtags = append(out.Hashtags, tag)
+	}
+
+	switch {
+	case len(out.Palette) > MaxPaletteColors:
+		return nil, fmt.Errorf("at most %d palette colors are allowed", MaxPaletteColors)
+	case len(out.Hashtags) > MaxHashtags:
+		return nil, fmt.Errorf("at most %d hashtags are allowed", MaxHashtags)
+	case len(out.SignOff) > MaxSignOffLength:
+		return nil, fmt.Errorf("sign-off must be at most %d characters", MaxSignOffLength)
+	}
+	return out, nil
+}
+
+// ParseColor parses a "#rrggbb" or "rrggbb" color.
+func ParseColor(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color %q, use #rrggbb", s)
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, nil
+}
+
+// Overlay builds the watermark overlay from the user's watermark settings
+// (DDR-133) and brand kit, either of which may be nil. The kit's logo
+// replaces the watermark's own logo or text, and its first palette color
+// fills text marks; position, opacity and scale come from the watermark
+// settings. Returns nil when there is nothing to stamp.
+func Overlay(w *store.WatermarkSettings, k *store.BrandKit, logo []byte) *media.Overlay {
+	var o media.Overlay
+	if w != nil {
+		o = media.Overlay{
+			Text:     w.Text,
+			Logo:     w.Logo,
+			Position: w.Position,
+			Opacity:  w.Opacity,
+			Scale:    w.Scale,
+		}
+	}
+	if len(logo) > 0 {
+		o.Logo = logo
+	}
+	if k != nil && len(k.Palette) > 0 {
+		if c, err := ParseColor(k.Palette[0]); err == nil {
+			o.TextColor = c
+		}
+	}
+	if o.Text == "" && len(o.Logo) == 0 {
+		return nil
+	}
+	return &o
+}
+
+// LoadLogo reads the kit's logo from the brand assets bucket. It returns nil
+// when the kit has no logo or the bucket is not configured.
+func LoadLogo(ctx context.Context, client *s3.Client, bucket string, k *store.BrandKit) ([]byte, error) {
+	if k == nil || k.LogoKey == "" || bucket == "" {
+		return nil, nil
+	}
+	out, err := client.GetObject(ctx, &s3.GetObjectInput{Bucket: &bucket, Key: &k.LogoKey})
+	if err != nil {
+		return nil, fmt.Errorf("get brand logo %s: %w", k.LogoKey, err)
+	}
+	defer out.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(out.Body, MaxLogoBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("read brand logo %s: %w", k.LogoKey, err)
+	}
+	return data, nil
+}
+
+// AppendSignOff ends caption with signOff unless it already contains it.
+func AppendSignOff(caption, signOff string) string {
+	signOff = strings.TrimSpace(signOff)
+	if signOff == "" || strings.Contains(caption, signOff) {
+		return caption
+	}
+	if strings.TrimSpace(caption) == "" {
+		return signOff
+	}
+	return strings.TrimRight(caption, " \n") + "\n\n" + signOff
+}
+
+// LogoKey returns the brand assets bucket key for a logo owner uploaded at
+// the given Unix time. A new key per upload keeps cached copies from going
+// stale.
+func LogoKey(owner string, uploadedAt int64) string {
+	return fmt.Sprintf("logos/%s/%d.png", owner, uploadedAt)
+}
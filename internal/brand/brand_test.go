@@ -0,0 +1,134 @@
+package brand
+
+import (
+	"context"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+func TestNormalize(t *testing.T) {
+	k, err := Normalize(store.BrandKit{
+		Palette:  []string{"#FF8800", "112233"},
+		Hashtags: []string{"#Travel", "travel", " food ", ""},
+		SignOff:  "  — Jane  ",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(k.Palette, ",") != "#ff8800,#112233" {
+		t.Errorf("palette = %v", k.Palette)
+	}
+	if strings.Join(k.Hashtags, ",") != "Travel,food" {
+		t.Errorf("hashtags = %v", k.Hashtags)
+	}
+	if k.SignOff != "— Jane" {
+		t.Errorf("signOff = %q", k.SignOff)
+	}
+
+	for _, bad := range []store.BrandKit{
+		{Palette: []string{"red"}},
+		{Palette: []string{"#12345"}},
+		{Hashtags: []string{"two words"}},
+		{SignOff: strings.Repeat("x", MaxSignOffLength+1)},
+		{Palette: []string{"#000000", "#000001", "#000002", "#000003", "#000004", "#000005", "#000006"}},
+	} {
+		if _, err := Normalize(bad); err == nil {
+			t.Errorf("Normalize(%+v) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	if o := Overlay(nil, &store.BrandKit{Palette: []string{"#ff0000"}}, nil); o != nil {
+		t.Errorf("overlay without text or logo = %+v, want nil", o)
+	}
+
+	w := &store.WatermarkSettings{Text: "© Jane", Position: "top-left", Opacity: 0.5}
+	o := Overlay(w, &store.BrandKit{Palette: []string{"#ff0000"}}, nil)
+	if o == nil || o.Text != "© Jane" || o.Position != "top-left" || o.TextColor != (color.RGBA{R: 0xff, A: 0xff}) {
+		t.Errorf("text overlay = %+v", o)
+	}
+
+	logo := []byte("png")
+	o = Overlay(w, &store.BrandKit{LogoKey: "logos/u/1.png"}, logo)
+	if o == nil || string(o.Logo) != "png" || o.Position != "top-left" {
+		t.Errorf("brand logo overlay = %+v", o)
+	}
+	if o = Overlay(nil, &store.BrandKit{LogoKey: "logos/u/1.png"}, logo); o == nil || string(o.Logo) != "png" {
+		t.Errorf("brand logo without watermark = %+v", o)
+	}
+}
+
+func TestAppendSignOff(t *testing.T) {
+	for _, tc := range []struct{ caption, signOff, want string }{
+		{"Sunset in Lisbon", "— Jane", "Sunset in Lisbon\n\n— Jane"},
+		{"Sunset in Lisbon\n\n— Jane", "— Jane", "Sunset in Lisbon\n\n— Jane"},
+		{"Sunset in Lisbon", "", "Sunset in Lisbon"},
+		{"", "— Jane", "— Jane"},
+	} {
+		if got := AppendSignOff(tc.caption, tc.signOff); got != tc.want {
+			t.Errorf("AppendSignOff(%q, %q) = %q, want %q", tc.caption, tc.signOff, got, tc.want)
+		}
+	}
+}
+
+func TestCleanKey(t *testing.T) {
+	tests := map[string]string{
+		"s/enhanced/a.jpg":    "s/enhanced/clean/a.jpg",
+		"s/enhanced/v2/a.jpg": "s/enhanced/v2/clean/a.jpg",
+	}
+	for in, want := range tests {
+		if got := CleanKey(in); got != want {
+			t.Errorf("CleanKey(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestWatermarkedSkipsUneditedKeys(t *testing.T) {
+	// Only enhanced copies can carry a watermark from enhancement; other
+	// keys are answered without reading S3.
+	for _, key := range []string{"s/a.jpg", "s/cropped/a.jpg", "s/enhanced/clean/a.jpg"} {
+		if Watermarked(context.Background(), nil, "bucket", key) {
+			t.Errorf("Watermarked(%q) = true", key)
+		}
+	}
+}
+
+type fakeWatermarkStore struct {
+	settings *store.WatermarkSettings
+	kit      *store.BrandKit
+}
+
+func (f fakeWatermarkStore) SessionOwner(context.Context, string) (string, error) {
+	return "owner", nil
+}
+
+func (f fakeWatermarkStore) GetWatermark(context.Context, string) (*store.WatermarkSettings, error) {
+	return f.settings, nil
+}
+
+func (f fakeWatermarkStore) GetBrandKit(context.Context, string) (*store.BrandKit, error) {
+	return f.kit, nil
+}
+
+func TestHasWatermark(t *testing.T) {
+	tests := []struct {
+		name string
+		st   fakeWatermarkStore
+		want bool
+	}{
+		{"watermark", fakeWatermarkStore{settings: &store.WatermarkSettings{Text: "© Jane"}}, true},
+		{"brand logo only", fakeWatermarkStore{kit: &store.BrandKit{LogoKey: "logos/o/1.png"}}, true},
+		{"kit without logo", fakeWatermarkStore{kit: &store.BrandKit{Hashtags: []string{"travel"}}}, false},
+		{"nothing", fakeWatermarkStore{}, false},
+	}
+	for _, tt := range tests {
+		got, err := HasWatermark(context.Background(), tt.st, "owner")
+		if err != nil || got != tt.want {
+			t.Errorf("%s: HasWatermark = %v, %v; want %v", tt.name, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package brand
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// MaxLanguageLength caps the caption language of a caption style.
+const MaxLanguageLength = 40
+
+// NormalizeCaptionStyle trims and validates a caption style (DDR-159). The
+// emoji density and formality are lowercased and must be one of the store's
+// values or empty. UpdatedAt is copied as is.
+func NormalizeCaptionStyle(c store.CaptionStyle) (*store.CaptionStyle, error) {
+	out := &store.CaptionStyle{
+		EmojiDensity: strings.ToLower(strings.TrimSpace(c.EmojiDensity)),
+		Language:     strings.TrimSpace(c.Language),
+		Formality:    strings.ToLower(strings.TrimSpace(c.Formality)),
+		SignOff:      strings.TrimSpace(c.SignOff),
+		UpdatedAt:    c.UpdatedAt,
+	}
+	switch out.EmojiDensity {
+	case "", store.EmojiNone, store.EmojiLight, store.EmojiHeavy:
+	default:
+		return nil, fmt.Errorf("emojiDensity must be %q, %q or %q", store.EmojiNone, store.EmojiLight, store.EmojiHeavy)
+	}
+	switch out.Formality {
+	case "", store.FormalityCasual, store.FormalityNeutral, store.FormalityFormal:
+	default:
+		return nil, fmt.Errorf("formality must be %q, %q or %q", store.FormalityCasual, store.FormalityNeutral, store.FormalityFormal)
+	}
+	switch {
+	case len(out.Language) > MaxLanguageLength || strings.ContainsAny(out.Language, "\n\r"):
+		return nil, fmt.Errorf("language must be a single line of at most %d characters", MaxLanguageLength)
+	case len(out.SignOff) > MaxSignOffLength:
+		return nil, fmt.Errorf("sign-off must be at most %d characters", MaxSignOffLength)
+	}
+	return out, nil
+}
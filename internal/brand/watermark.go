@@ -0,0 +1,96 @@
+package brand
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// WatermarkQuality is the JPEG quality of watermarked photos (DDR-133).
+const WatermarkQuality = 92
+
+// WatermarkStore reads the records a session owner's watermark is built from.
+type WatermarkStore interface {
+	SessionOwner(ctx context.Context, sessionID string) (string, error)
+	GetWatermark(ctx context.Context, owner string) (*store.WatermarkSettings, error)
+	GetBrandKit(ctx context.Context, owner string) (*store.BrandKit, error)
+}
+
+// OwnerWatermark returns the session owner's watermark with their brand kit
+// applied, or nil when the owner has neither a watermark nor a brand logo,
+// or they cannot be read. Misses are logged; the caller goes on without a
+// watermark.
+func OwnerWatermark(ctx context.Context, st WatermarkStore, client *s3.Client, bucket, sessionID string) *media.Overlay {
+	owner, err := st.SessionOwner(ctx, sessionID)
+	if err != nil || owner == "" {
+		log.Warn().Err(err).Str("sessionId", sessionID).Msg("Session owner unknown — skipping watermark")
+		return nil
+	}
+	settings, err := st.GetWatermark(ctx, owner)
+	if err != nil {
+		log.Warn().Err(err).Str("sessionId", sessionID).Msg("Watermark not readable — skipping it")
+		return nil
+	}
+	kit, err := st.GetBrandKit(ctx, owner)
+	if err != nil {
+		log.Warn().Err(err).Str("sessionId", sessionID).Msg("Brand kit not readable — watermarking without it")
+	}
+	logo, err := LoadLogo(ctx, client, bucket, kit)
+	if err != nil {
+		log.Warn().Err(err).Str("sessionId", sessionID).Msg("Brand logo not readable — watermarking without it")
+	}
+	overlay := Overlay(settings, kit, logo)
+	if overlay == nil {
+		log.Warn().Str("sessionId", sessionID).Msg("No watermark or brand logo set — skipping watermark")
+	}
+	return overlay
+}
+
+// HasWatermark reports whether owner has something to stamp: a watermark,
+// or a brand kit logo to stamp instead.
+func HasWatermark(ctx context.Context, st WatermarkStore, owner string) (bool, error) {
+	settings, err := st.GetWatermark(ctx, owner)
+	if err != nil {
+		return false, err
+	}
+	if settings != nil {
+		return true, nil
+	}
+	kit, err := st.GetBrandKit(ctx, owner)
+	if err != nil {
+		return false, err
+	}
+	return kit != nil && kit.LogoKey != "", nil
+}
+
+// CleanKey returns the key of the unwatermarked copy kept beside a
+// watermarked enhanced photo: a clean folder next to it.
+func CleanKey(enhancedKey string) string {
+	return path.Join(path.Dir(enhancedKey), "clean", path.Base(enhancedKey))
+}
+
+// Watermarked reports whether the photo at key already carries the owner's
+// watermark: an enhanced copy stamped when it was made keeps its clean copy
+// at CleanKey. An unreadable clean copy is treated as absent.
+func Watermarked(ctx context.Context, client *s3.Client, bucket, key string) bool {
+	if !strings.Contains(key, "/enhanced/") || strings.Contains(key, "/clean/") {
+		return false
+	}
+	cleanKey := CleanKey(key)
+	if _, err := client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: &bucket, Key: &cleanKey}); err != nil {
+		var notFound *s3types.NotFound
+		if !errors.As(err, &notFound) {
+			log.Warn().Err(err).Str("key", cleanKey).Msg("Clean copy not readable — treating photo as unwatermarked")
+		}
+		return false
+	}
+	return true
+}
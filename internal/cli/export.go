@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExportLink hard-links src into dir as name, or as name-N.ext for the first
+// N that is free, and returns the path it used. The link itself claims the
+// name, so an existing file is never replaced, even one created while the
+// export runs.
+func ExportLink(src, dir, name string) (string, error) {
+	return exportTo(dir, name, func(target string) error {
+		return os.Link(src, target)
+	})
+}
+
+// ExportMove moves src into dir as name, or as name-N.ext for the first N
+// that is free, and returns the path it used. The name is claimed with an
+// exclusively created placeholder that the move then replaces, so the move
+// only ever replaces a file it created itself.
+func ExportMove(src, dir, name string) (string, error) {
+	return exportTo(dir, name, func(target string) error {
+		f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			return err
+		}
+		f.Close()
+		if err := os.Rename(src, target); err != nil {
+			os.Remove(target)
+			return err
+		}
+		return nil
+	})
+}
+
+// CreateExportFile creates dir/name, or dir/name-N.ext for the first N that
+// is free, for writing. It never opens an existing file.
+func CreateExportFile(dir, name string) (*os.File, error) {
+	var f *os.File
+	_, err := exportTo(dir, name, func(target string) error {
+		var err error
+		f, err = os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		return err
+	})
+	return f, err
+}
+
+// exportTo calls claim with dir/name, then dir/name-1.ext, dir/name-2.ext and
+// so on while claim reports that the path exists.
+func exportTo(dir, name string, claim func(target string) error) (string, error) {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	target := filepath.Join(dir, name)
+	for n := 1; ; n++ {
+		err := claim(target)
+		if err == nil {
+			return target, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", err
+		}
+		target = filepath.Join(dir, fmt.Sprintf("%s-%d%s", stem, n, ext))
+	}
+}
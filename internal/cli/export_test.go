@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestExportNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []string
+		file     string
+		want     string
+	}{
+		{name: "free name", file: "a.jpg", want: "a.jpg"},
+		{name: "taken name", existing: []string{"a.jpg"}, file: "a.jpg", want: "a-1.jpg"},
+		{name: "first free suffix", existing: []string{"a.jpg", "a-1.jpg", "a-3.jpg"}, file: "a.jpg", want: "a-2.jpg"},
+		{name: "no extension", existing: []string{"notes"}, file: "notes", want: "notes-1"},
+		{name: "taken by a directory", existing: []string{"a.jpg/"}, file: "a.jpg", want: "a-1.jpg"},
+	}
+	exports := map[string]func(src, dir, name string) (string, error){
+		"link": ExportLink,
+		"move": ExportMove,
+	}
+	for mode, export := range exports {
+		for _, tt := range tests {
+			t.Run(mode+"/"+tt.name, func(t *testing.T) {
+				src, dest := t.TempDir(), t.TempDir()
+				for _, name := range tt.existing {
+					path := filepath.Join(dest, name)
+					if strings.HasSuffix(name, "/") {
+						if err := os.Mkdir(path, 0o755); err != nil {
+							t.Fatal(err)
+						}
+						continue
+					}
+					writeTestFile(t, path, "existing")
+				}
+				from := filepath.Join(src, tt.file)
+				writeTestFile(t, from, "exported")
+
+				got, err := export(from, dest, tt.file)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := filepath.Join(dest, tt.want); got != want {
+					t.Errorf("exported to %s, want %s", got, want)
+				}
+				if readTestFile(t, got) != "exported" {
+					t.Error("export target does not hold the source file")
+				}
+				for _, name := range tt.existing {
+					if info, err := os.Stat(filepath.Join(dest, name)); err == nil && !info.IsDir() && readTestFile(t, filepath.Join(dest, name)) != "existing" {
+						t.Errorf("existing %s was replaced", name)
+					}
+				}
+				_, err = os.Stat(from)
+				if kept := err == nil; kept != (mode == "link") {
+					t.Errorf("source kept = %v after %s", kept, mode)
+				}
+			})
+		}
+	}
+}
+
+func TestExportLinkSharesTheFile(t *testing.T) {
+	src, dest := filepath.Join(t.TempDir(), "a.jpg"), t.TempDir()
+	writeTestFile(t, src, "photo")
+	got, err := ExportLink(src, dest, "a.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := os.Stat(src)
+	b, _ := os.Stat(got)
+	if !os.SameFile(a, b) {
+		t.Error("export is a copy, not a link")
+	}
+}
+
+func TestExportMoveFailureLeavesNoPlaceholder(t *testing.T) {
+	dest := t.TempDir()
+	if _, err := ExportMove(filepath.Join(t.TempDir(), "missing.jpg"), dest, "missing.jpg"); err == nil {
+		t.Fatal("moving a missing file succeeded")
+	}
+	if entries, _ := os.ReadDir(dest); len(entries) != 0 {
+		t.Errorf("destination keeps %d entries after a failed move", len(entries))
+	}
+}
+
+func TestConcurrentExportsNeverShareAName(t *testing.T) {
+	src, dest := t.TempDir(), t.TempDir()
+	const n = 20
+	var wg sync.WaitGroup
+	targets := make([]string, n)
+	errs := make([]error, n)
+	for i := range n {
+		from := filepath.Join(src, string(rune('a'+i))+".jpg")
+		writeTestFile(t, from, from)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i%2 == 0 {
+				targets[i], errs[i] = ExportLink(from, dest, "same.jpg")
+			} else {
+				targets[i], errs[i] = ExportMove(from, dest, "same.jpg")
+			}
+		}()
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for i, target := range targets {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+		if seen[target] {
+			t.Errorf("two exports wrote %s", target)
+		}
+		seen[target] = true
+		if want := filepath.Join(src, string(rune('a'+i))+".jpg"); readTestFile(t, target) != want {
+			t.Errorf("%s holds %q, want %q", target, readTestFile(t, target), want)
+		}
+	}
+}
+
+func TestCreateExportFile(t *testing.T) {
+	dest := t.TempDir()
+	writeTestFile(t, filepath.Join(dest, "manifest.json"), "earlier")
+	f, err := CreateExportFile(dest, "manifest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if want := filepath.Join(dest, "manifest-1.json"); f.Name() != want {
+		t.Errorf("created %s, want %s", f.Name(), want)
+	}
+	if readTestFile(t, filepath.Join(dest, "manifest.json")) != "earlier" {
+		t.Error("existing manifest was replaced")
+	}
+}
@@ -2,10 +2,12 @@ package cli
 
 import (
 	"context"
+	"fmt"
 	"os"
 
 	"github.com/fpang/ai-social-media-helper/internal/auth"
 	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/artifacts"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/genai"
 )
@@ -42,3 +44,36 @@ func InitGeminiClient() (context.Context, *genai.Client) {
 
 	return ctx, client
 }
+
+// WithDebugArtifacts returns ctx recording prompts, raw model responses, and
+// compressed videos to a new run directory under .debug/ when enabled, after
+// pruning runs older than a week (DDR-106). Exits fatally if the directory
+// cannot be created, since the user asked for the artifacts.
+func WithDebugArtifacts(ctx context.Context, enabled bool) context.Context {
+	if !enabled {
+		return ctx
+	}
+	artifacts.PruneDir(artifacts.DefaultDir, artifacts.DefaultMaxAge)
+	rec, err := artifacts.NewDirRecorder(artifacts.DefaultDir)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to create debug artifacts directory")
+	}
+	fmt.Println(T("debug.artifacts", rec.Dir()))
+	return artifacts.WithRecorder(ctx, rec)
+}
+
+// WithThinking returns ctx applying the --thinking setting to triage and
+// selection calls, or GEMINI_THINKING when the flag is empty (DDR-155), and
+// the setting used. Exits fatally on an invalid setting, since the user
+// asked for it.
+func WithThinking(ctx context.Context, setting string) (context.Context, string) {
+	if setting == "" {
+		setting = ai.GetThinking()
+	}
+	setting, err := ai.NormalizeThinking(setting)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid thinking setting")
+	}
+	ctx, _ = ai.WithThinking(ctx, setting)
+	return ctx, setting
+}
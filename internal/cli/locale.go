@@ -0,0 +1,166 @@
+package cli
+
+// locale.go is the CLIs' small i18n layer: message catalogs, capture date
+// formats and decimal formats per locale. See DDR-156: Localized CLI Output.
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/rs/zerolog/log"
+)
+
+// Locale is a CLI output language.
+type Locale string
+
+// Supported locales. Chinese is Simplified Chinese.
+const (
+	LocaleEnglish  Locale = "en"
+	LocaleChinese  Locale = "zh"
+	LocaleJapanese Locale = "ja"
+)
+
+// numberFormat holds a locale's decimal and digit grouping separators.
+type numberFormat struct {
+	decimal string
+	group   string
+}
+
+var numberFormats = map[Locale]numberFormat{
+	LocaleEnglish:  {decimal: ".", group: ","},
+	LocaleChinese:  {decimal: ".", group: ","},
+	LocaleJapanese: {decimal: ".", group: ","},
+}
+
+var (
+	zhWeekdays = [...]string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"}
+	jaWeekdays = [...]string{"日", "月", "火", "水", "木", "金", "土"}
+)
+
+// current is the locale of T and the formatters, set once at startup.
+var current = LocaleEnglish
+
+// ParseLocale returns the supported locale of a language tag or POSIX
+// locale name, such as "ja", "zh-TW" or "en_US.UTF-8". Only the language
+// is used.
+func ParseLocale(s string) (Locale, error) {
+	lang := strings.ToLower(strings.TrimSpace(s))
+	if i := strings.IndexAny(lang, "-_.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	l := Locale(lang)
+	if _, ok := catalogs[l]; !ok {
+		return "", fmt.Errorf("unsupported locale %q: use en, zh or ja", s)
+	}
+	return l, nil
+}
+
+// DetectLocale returns the locale of the first of LC_ALL, LC_MESSAGES and
+// LANG that is set, or English when it is unsupported (such as "C").
+func DetectLocale() Locale {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(env); v != "" {
+			if l, err := ParseLocale(v); err == nil {
+				return l
+			}
+			return LocaleEnglish
+		}
+	}
+	return LocaleEnglish
+}
+
+// SetLocale sets the locale of T and the formatters.
+func SetLocale(l Locale) {
+	current = l
+}
+
+// InitLocale sets the output locale from the --locale flag, or from the
+// environment when the flag is empty, and returns it. Exits fatally on an
+// unsupported flag, since the user asked for it.
+func InitLocale(flag string) Locale {
+	l := DetectLocale()
+	if flag != "" {
+		var err error
+		if l, err = ParseLocale(flag); err != nil {
+			log.Fatal().Err(err).Msg("invalid locale")
+		}
+	}
+	SetLocale(l)
+	log.Debug().Str("locale", string(l)).Msg("Output locale set")
+	return l
+}
+
+// T returns the message with the given key in the current locale, formatted
+// with args. A message missing from the locale's catalog falls back to
+// English, and an unknown key to the key itself.
+func T(key string, args ...any) string {
+	msg, ok := catalogs[current][key]
+	if !ok {
+		if msg, ok = catalogs[LocaleEnglish][key]; !ok {
+			msg = key
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// FormatDate formats a capture time in the current locale, with its
+// weekday, e.g. "Monday, January 2, 2006 at 3:04 PM" or
+// "2006年1月2日(月) 15:04".
+func FormatDate(t time.Time) string {
+	switch current {
+	case LocaleChinese:
+		return fmt.Sprintf("%d年%d月%d日 %s %02d:%02d", t.Year(), t.Month(), t.Day(), zhWeekdays[t.Weekday()], t.Hour(), t.Minute())
+	case LocaleJapanese:
+		return fmt.Sprintf("%d年%d月%d日(%s) %02d:%02d", t.Year(), t.Month(), t.Day(), jaWeekdays[t.Weekday()], t.Hour(), t.Minute())
+	}
+	return t.Format("Monday, January 2, 2006 at 3:04 PM")
+}
+
+// FormatCaptureTime formats a file's capture time in the current locale,
+// with the UTC offset when the zone is known (DDR-117).
+func FormatCaptureTime(md media.MediaMetadata) string {
+	t := md.GetDate()
+	s := FormatDate(t)
+	if media.HasCaptureZone(md) {
+		s += t.Format(" (UTC-07:00)")
+	}
+	return s
+}
+
+// FormatDecimal formats v with prec decimal places and grouped thousands,
+// using the current locale's separators, e.g. "1,234.5".
+func FormatDecimal(v float64, prec int) string {
+	nf := numberFormats[current]
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, frac, hasFrac := strings.Cut(s, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, d := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(nf.group)
+		}
+		b.WriteRune(d)
+	}
+	if hasFrac {
+		b.WriteString(nf.decimal)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+// FormatMB formats a size in bytes as megabytes with prec decimal places.
+func FormatMB(bytes int64, prec int) string {
+	return FormatDecimal(float64(bytes)/(1024*1024), prec) + " MB"
+}
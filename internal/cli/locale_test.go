@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+)
+
+var formatVerb = regexp.MustCompile(`%[-+# 0]*\d*(\.\d+)?[a-zA-Z%]`)
+
+func TestCatalogsMatchEnglish(t *testing.T) {
+	en := catalogs[LocaleEnglish]
+	for l, catalog := range catalogs {
+		for key, msg := range en {
+			got, ok := catalog[key]
+			if !ok {
+				t.Errorf("%s: missing %q", l, key)
+				continue
+			}
+			if want, have := formatVerb.FindAllString(msg, -1), formatVerb.FindAllString(got, -1); !slices.Equal(want, have) {
+				t.Errorf("%s: %q has verbs %v, want %v", l, key, have, want)
+			}
+		}
+		for key := range catalog {
+			if _, ok := en[key]; !ok {
+				t.Errorf("%s: %q is not in the English catalog", l, key)
+			}
+		}
+	}
+}
+
+func TestParseLocale(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Locale
+		wantErr bool
+	}{
+		{"en", LocaleEnglish, false},
+		{"en_US.UTF-8", LocaleEnglish, false},
+		{"zh-TW", LocaleChinese, false},
+		{"zh_CN.UTF-8", LocaleChinese, false},
+		{" JA ", LocaleJapanese, false},
+		{"ja_JP@calendar=japanese", LocaleJapanese, false},
+		{"C", "", true},
+		{"fr_FR", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLocale(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLocale(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestDetectLocale(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "ja_JP.UTF-8")
+	t.Setenv("LANG", "zh_CN.UTF-8")
+	if got := DetectLocale(); got != LocaleJapanese {
+		t.Errorf("DetectLocale() = %q, want ja", got)
+	}
+	t.Setenv("LC_ALL", "C")
+	if got := DetectLocale(); got != LocaleEnglish {
+		t.Errorf("DetectLocale() with LC_ALL=C = %q, want en", got)
+	}
+}
+
+func TestT(t *testing.T) {
+	defer SetLocale(LocaleEnglish)
+
+	SetLocale(LocaleJapanese)
+	if got, want := T("triage.keep", 3), "残す（3 件）"; got != want {
+		t.Errorf("T(triage.keep) = %q, want %q", got, want)
+	}
+	if got := T("no.such.key"); got != "no.such.key" {
+		t.Errorf("T(unknown) = %q, want the key", got)
+	}
+	SetLocale(LocaleEnglish)
+	if got, want := T("triage.keep", 3), "KEEP (3 items)"; got != want {
+		t.Errorf("T(triage.keep) = %q, want %q", got, want)
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	defer SetLocale(LocaleEnglish)
+	ts := time.Date(2026, 7, 4, 21, 5, 0, 0, time.UTC)
+
+	tests := map[Locale]string{
+		LocaleEnglish:  "Saturday, July 4, 2026 at 9:05 PM",
+		LocaleChinese:  "2026年7月4日 星期六 21:05",
+		LocaleJapanese: "2026年7月4日(土) 21:05",
+	}
+	for l, want := range tests {
+		SetLocale(l)
+		if got := FormatDate(ts); got != want {
+			t.Errorf("%s: FormatDate = %q, want %q", l, got, want)
+		}
+	}
+}
+
+func TestFormatDecimal(t *testing.T) {
+	tests := []struct {
+		v    float64
+		prec int
+		want string
+	}{
+		{0, 1, "0.0"},
+		{999.94, 1, "999.9"},
+		{1234.56, 1, "1,234.6"},
+		{1234567, 0, "1,234,567"},
+		{-12345.5, 2, "-12,345.50"},
+	}
+	for _, tt := range tests {
+		if got := FormatDecimal(tt.v, tt.prec); got != tt.want {
+			t.Errorf("FormatDecimal(%v, %d) = %q, want %q", tt.v, tt.prec, got, tt.want)
+		}
+	}
+	if got := FormatMB(3*1024*1024/2, 1); got != "1.5 MB" {
+		t.Errorf("FormatMB = %q, want 1.5 MB", got)
+	}
+}
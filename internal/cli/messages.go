@@ -0,0 +1,234 @@
+package cli
+
+// Message catalogs of the CLIs (DDR-156). Keys are grouped by where the
+// message appears; every catalog has the keys of the English one, with the
+// same format verbs in the same order.
+var catalogs = map[Locale]map[string]string{
+	LocaleEnglish: {
+		// Shared prompts and setup
+		"prompt.directory":  "Directory [%s]: ",
+		"debug.artifacts":   "Debug artifacts: %s",
+		"compress.progress": "⏳ Compressing %s %s %d%%",
+
+		// Scan summary
+		"scan.directory": "Directory: %s",
+		"scan.images":    "Images found: %d",
+		"scan.videos":    "Videos found: %d",
+		"scan.total":     "Total media: %d",
+		"scan.limited":   "(limited to %d)",
+		"scan.model":     "Model: %s",
+		"scan.thinking":  "Thinking: %s",
+		"scan.keyframes": "Videos judged from %d keyframes each",
+		"tag.gps":        "📍GPS",
+		"tag.date":       "📅Date",
+
+		// media-select
+		"select.title":          "📁 Media Selection",
+		"select.max":            "Max selection: %d",
+		"select.context":        "Context: %s",
+		"select.context_prompt": "Describe your trip/event (helps Gemini select the best photos):\nExamples: 'Weekend trip to Kyoto - temples, food tour, night market'\n          'Birthday party at restaurant then karaoke'",
+		"select.context_input":  "Context (optional): ",
+		"select.media_list":     "📸 Media to analyze:",
+		"select.compressing":    "⏳ Compressing videos...",
+		"select.sending":        "⏳ Processing media and sending to Gemini...",
+		"select.complete":       "✅ Media Selection Complete!",
+
+		// Single-file analysis
+		"media.image":          "Image",
+		"media.video":          "Video",
+		"media.media":          "Media",
+		"analysis.title":       "%s Analyzing %s for Social Media Post",
+		"analysis.file":        "File: %s",
+		"analysis.size":        "Size: %s",
+		"analysis.type":        "Type: %s",
+		"analysis.upload":      "Upload: Files API",
+		"analysis.no_metadata": "⚠️  No metadata could be extracted",
+		"analysis.uploading":   "⏳ Uploading %s to Gemini Files API...",
+		"analysis.complete":    "✅ Analysis Complete!",
+
+		// Metadata display
+		"meta.unknown":    "📋 Metadata extracted (unknown type)",
+		"meta.image":      "📍 EXIF Metadata Extracted:",
+		"meta.video":      "🎥 Video Metadata Extracted:",
+		"meta.gps":        "GPS: %.6f, %.6f",
+		"meta.map":        "Map: %s",
+		"meta.date":       "Date: %s",
+		"meta.camera":     "Camera: %s %s",
+		"meta.duration":   "Duration: %s",
+		"meta.resolution": "Resolution: %s",
+		"meta.frame_rate": "Frame Rate: %s fps",
+		"meta.codec":      "Codec: %s",
+		"meta.bit_rate":   "Bit Rate: %s Mbps",
+
+		// media-triage
+		"triage.title":            "Media Triage",
+		"triage.dry_run_mode":     "Mode: DRY RUN (no deletion)",
+		"triage.too_short":        "Video too short (%ss) - likely accidental recording",
+		"triage.sampled":          "(evaluated via sampling)",
+		"triage.prefilter":        "PRE-FILTER: %s (%ss) - too short, skipping AI analysis",
+		"triage.prefiltered":      "Pre-filtered %d short video(s) without AI analysis.",
+		"triage.compressing":      "Compressing videos...",
+		"triage.sending":          "Sending %d media items to Gemini for triage...",
+		"triage.report":           "Triage Report",
+		"triage.keep":             "KEEP (%d items)",
+		"triage.discard":          "DISCARD (%d items)",
+		"triage.none":             "(none)",
+		"triage.all_keep":         "All media files are worth keeping!",
+		"triage.reclaimable":      "Total space to reclaim: %s",
+		"triage.dry_run_done":     "Dry run complete. No files were deleted.",
+		"triage.confirm":          "Delete %d file(s)? This cannot be undone. (y/N): ",
+		"triage.aborted":          "Aborted. No files were deleted.",
+		"triage.delete_failed":    "FAILED: %s - %v",
+		"triage.deleted_file":     "Deleted: %s",
+		"triage.deleted":          "Deleted %d file(s), reclaimed %s",
+		"triage.deleted_w_errors": "Deleted %d file(s), %d error(s), reclaimed %s",
+	},
+
+	LocaleChinese: {
+		"prompt.directory":  "目录 [%s]：",
+		"debug.artifacts":   "调试文件：%s",
+		"compress.progress": "⏳ 正在压缩 %s %s %d%%",
+
+		"scan.directory": "目录：%s",
+		"scan.images":    "找到图片：%d",
+		"scan.videos":    "找到视频：%d",
+		"scan.total":     "媒体总数：%d",
+		"scan.limited":   "（上限 %d）",
+		"scan.model":     "模型：%s",
+		"scan.thinking":  "思考设置：%s",
+		"scan.keyframes": "视频按每段 %d 个关键帧评估",
+		"tag.gps":        "📍GPS",
+		"tag.date":       "📅日期",
+
+		"select.title":          "📁 媒体精选",
+		"select.max":            "最多选择：%d",
+		"select.context":        "背景：%s",
+		"select.context_prompt": "描述这次旅行或活动（帮助 Gemini 选出最好的照片）：\n示例：'京都周末游 - 寺庙、美食之旅、夜市'\n      '餐厅生日聚会，然后去唱卡拉OK'",
+		"select.context_input":  "背景（可选）：",
+		"select.media_list":     "📸 待分析的媒体：",
+		"select.compressing":    "⏳ 正在压缩视频...",
+		"select.sending":        "⏳ 正在处理媒体并发送给 Gemini...",
+		"select.complete":       "✅ 媒体精选完成！",
+
+		"media.image":          "图片",
+		"media.video":          "视频",
+		"media.media":          "媒体",
+		"analysis.title":       "%s 正在分析%s，用于社交媒体帖子",
+		"analysis.file":        "文件：%s",
+		"analysis.size":        "大小：%s",
+		"analysis.type":        "类型：%s",
+		"analysis.upload":      "上传方式：Files API",
+		"analysis.no_metadata": "⚠️  未能提取元数据",
+		"analysis.uploading":   "⏳ 正在将%s上传到 Gemini Files API...",
+		"analysis.complete":    "✅ 分析完成！",
+
+		"meta.unknown":    "📋 已提取元数据（未知类型）",
+		"meta.image":      "📍 已提取 EXIF 元数据：",
+		"meta.video":      "🎥 已提取视频元数据：",
+		"meta.gps":        "GPS：%.6f, %.6f",
+		"meta.map":        "地图：%s",
+		"meta.date":       "日期：%s",
+		"meta.camera":     "相机：%s %s",
+		"meta.duration":   "时长：%s",
+		"meta.resolution": "分辨率：%s",
+		"meta.frame_rate": "帧率：%s fps",
+		"meta.codec":      "编码：%s",
+		"meta.bit_rate":   "码率：%s Mbps",
+
+		"triage.title":            "媒体筛查",
+		"triage.dry_run_mode":     "模式：演练（不删除）",
+		"triage.too_short":        "视频过短（%s 秒）- 可能是误录",
+		"triage.sampled":          "（基于抽样评估）",
+		"triage.prefilter":        "预筛：%s（%s 秒）- 过短，跳过 AI 分析",
+		"triage.prefiltered":      "已预筛 %d 个短视频，未经 AI 分析。",
+		"triage.compressing":      "正在压缩视频...",
+		"triage.sending":          "正在将 %d 个媒体发送给 Gemini 进行筛查...",
+		"triage.report":           "筛查报告",
+		"triage.keep":             "保留（%d 项）",
+		"triage.discard":          "丢弃（%d 项）",
+		"triage.none":             "（无）",
+		"triage.all_keep":         "所有媒体文件都值得保留！",
+		"triage.reclaimable":      "可释放空间：%s",
+		"triage.dry_run_done":     "演练完成，未删除任何文件。",
+		"triage.confirm":          "删除 %d 个文件？此操作无法撤销。(y/N)：",
+		"triage.aborted":          "已取消，未删除任何文件。",
+		"triage.delete_failed":    "失败：%s - %v",
+		"triage.deleted_file":     "已删除：%s",
+		"triage.deleted":          "已删除 %d 个文件，释放 %s",
+		"triage.deleted_w_errors": "已删除 %d 个文件，%d 个错误，释放 %s",
+	},
+
+	LocaleJapanese: {
+		"prompt.directory":  "ディレクトリ [%s]: ",
+		"debug.artifacts":   "デバッグファイル: %s",
+		"compress.progress": "⏳ %s を圧縮中 %s %d%%",
+
+		"scan.directory": "ディレクトリ: %s",
+		"scan.images":    "画像: %d 件",
+		"scan.videos":    "動画: %d 件",
+		"scan.total":     "メディア合計: %d 件",
+		"scan.limited":   "（上限 %d 件）",
+		"scan.model":     "モデル: %s",
+		"scan.thinking":  "思考設定: %s",
+		"scan.keyframes": "動画は各 %d 枚のキーフレームで評価",
+		"tag.gps":        "📍GPS",
+		"tag.date":       "📅日付",
+
+		"select.title":          "📁 メディア選定",
+		"select.max":            "最大選定数: %d",
+		"select.context":        "コンテキスト: %s",
+		"select.context_prompt": "旅行やイベントの内容を入力してください（Gemini が最適な写真を選ぶ助けになります）:\n例: '京都の週末旅行 - 寺社、食べ歩き、夜市'\n    'レストランで誕生日会、そのあとカラオケ'",
+		"select.context_input":  "コンテキスト（任意）: ",
+		"select.media_list":     "📸 分析するメディア:",
+		"select.compressing":    "⏳ 動画を圧縮しています...",
+		"select.sending":        "⏳ メディアを処理して Gemini に送信しています...",
+		"select.complete":       "✅ メディア選定が完了しました！",
+
+		"media.image":          "画像",
+		"media.video":          "動画",
+		"media.media":          "メディア",
+		"analysis.title":       "%s SNS 投稿用に%sを分析しています",
+		"analysis.file":        "ファイル: %s",
+		"analysis.size":        "サイズ: %s",
+		"analysis.type":        "種類: %s",
+		"analysis.upload":      "アップロード: Files API",
+		"analysis.no_metadata": "⚠️  メタデータを抽出できませんでした",
+		"analysis.uploading":   "⏳ %sを Gemini Files API にアップロードしています...",
+		"analysis.complete":    "✅ 分析が完了しました！",
+
+		"meta.unknown":    "📋 メタデータを抽出しました（不明な種類）",
+		"meta.image":      "📍 EXIF メタデータ:",
+		"meta.video":      "🎥 動画メタデータ:",
+		"meta.gps":        "GPS: %.6f, %.6f",
+		"meta.map":        "地図: %s",
+		"meta.date":       "撮影日時: %s",
+		"meta.camera":     "カメラ: %s %s",
+		"meta.duration":   "再生時間: %s",
+		"meta.resolution": "解像度: %s",
+		"meta.frame_rate": "フレームレート: %s fps",
+		"meta.codec":      "コーデック: %s",
+		"meta.bit_rate":   "ビットレート: %s Mbps",
+
+		"triage.title":            "メディア仕分け",
+		"triage.dry_run_mode":     "モード: ドライラン（削除しません）",
+		"triage.too_short":        "動画が短すぎます（%s 秒）- 誤って撮影された可能性があります",
+		"triage.sampled":          "（サンプリングで評価）",
+		"triage.prefilter":        "事前除外: %s（%s 秒）- 短すぎるため AI 分析を省略",
+		"triage.prefiltered":      "短い動画 %d 件を AI 分析なしで除外しました。",
+		"triage.compressing":      "動画を圧縮しています...",
+		"triage.sending":          "%d 件のメディアを Gemini に送信して仕分けしています...",
+		"triage.report":           "仕分けレポート",
+		"triage.keep":             "残す（%d 件）",
+		"triage.discard":          "削除候補（%d 件）",
+		"triage.none":             "（なし）",
+		"triage.all_keep":         "すべてのメディアを残す価値があります！",
+		"triage.reclaimable":      "解放できる容量: %s",
+		"triage.dry_run_done":     "ドライランが完了しました。ファイルは削除されていません。",
+		"triage.confirm":          "%d 件のファイルを削除しますか？元に戻せません。(y/N): ",
+		"triage.aborted":          "中止しました。ファイルは削除されていません。",
+		"triage.delete_failed":    "失敗: %s - %v",
+		"triage.deleted_file":     "削除しました: %s",
+		"triage.deleted":          "%d 件のファイルを削除し、%s を解放しました",
+		"triage.deleted_w_errors": "%d 件のファイルを削除し（エラー %d 件）、%s を解放しました",
+	},
+}
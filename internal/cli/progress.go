@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+)
+
+// progressBarWidth is the number of cells in a compression progress bar.
+const progressBarWidth = 20
+
+// WithCompressionProgress returns ctx drawing a progress bar for each video
+// compressed with it (DDR-161). The bar redraws in place, so it is only
+// drawn when stdout is a terminal; otherwise ctx is returned unchanged.
+func WithCompressionProgress(ctx context.Context) context.Context {
+	if info, err := os.Stdout.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return ctx
+	}
+	return media.WithProgress(ctx, func(inputPath string, percent int) {
+		fmt.Printf("\r%s", T("compress.progress", filepath.Base(inputPath), progressBar(percent), percent))
+		if percent >= 100 {
+			fmt.Println()
+		}
+	})
+}
+
+// progressBar renders percent as a bar of progressBarWidth cells.
+func progressBar(percent int) string {
+	filled := min(max(percent, 0), 100) * progressBarWidth / 100
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled) + "]"
+}
@@ -0,0 +1,16 @@
+package cli
+
+import "testing"
+
+func TestProgressBar(t *testing.T) {
+	for percent, want := range map[int]string{
+		0:   "[░░░░░░░░░░░░░░░░░░░░]",
+		42:  "[████████░░░░░░░░░░░░]",
+		100: "[████████████████████]",
+		120: "[████████████████████]",
+	} {
+		if got := progressBar(percent); got != want {
+			t.Errorf("progressBar(%d) = %s, want %s", percent, got, want)
+		}
+	}
+}
@@ -17,7 +17,7 @@ func PromptForDirectory() string {
 		cwd = "."
 	}
 
-	fmt.Printf("Directory [%s]: ", cwd)
+	fmt.Print(T("prompt.directory", cwd))
 
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
@@ -0,0 +1,195 @@
+// Package crosspost resolves the platforms a publish job posts to and
+// tracks each platform's outcome (DDR-153).
+//
+// A job posts the same items to one or more platforms. Each publish step
+// works through the job's targets in turn; a target that fails records its
+// error and is skipped from then on, so the others still publish. The job
+// only fails when every target has.
+package crosspost
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fpang/ai-social-media-helper/internal/sfnevents"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// knownPlatforms are the platforms a target may name. An empty platform is
+// Instagram (DDR-147).
+var knownPlatforms = map[string]bool{
+	"":                          true,
+	sfnevents.PlatformInstagram: true,
+	sfnevents.PlatformFacebook:  true,
+	sfnevents.PlatformMastodon:  true,
+}
+
+// Targets returns a copy of the job's targets. Jobs started before
+// cross-posting carry their single platform in the event's own fields.
+// A target naming an unknown platform is returned failed, so no step
+// publishes it to a default platform.
+func Targets(event sfnevents.PublishEvent) []sfnevents.PublishTarget {
+	var targets []sfnevents.PublishTarget
+	if len(event.Targets) > 0 {
+		targets = append(targets, event.Targets...)
+	} else {
+		targets = []sfnevents.PublishTarget{{
+			Platform:          event.Platform,
+			Caption:           event.Caption,
+			ContainerIDs:      event.ContainerIDs,
+			VideoContainerIDs: event.VideoContainerIDs,
+		}}
+	}
+	for i := range targets {
+		if t := &targets[i]; t.Error == "" && !knownPlatforms[t.Platform] {
+			t.Error = fmt.Sprintf("unsupported platform %q", t.Platform)
+		}
+	}
+	return targets
+}
+
+// TargetEvent returns the event as one target's publisher sees it: with the
+// target's platform, caption variant and containers.
+func TargetEvent(event sfnevents.PublishEvent, t sfnevents.PublishTarget) sfnevents.PublishEvent {
+	event.Platform, event.Caption = t.Platform, t.Caption
+	event.ContainerIDs, event.VideoContainerIDs = t.ContainerIDs, t.VideoContainerIDs
+	event.Targets = nil
+	return event
+}
+
+// PlatformKey names a target's platform in job records; an empty platform
+// is Instagram (DDR-147).
+func PlatformKey(platform string) string {
+	if platform == "" {
+		return sfnevents.PlatformInstagram
+	}
+	return platform
+}
+
+// PlatformLabel names a platform for people.
+func PlatformLabel(platform string) string {
+	switch platform {
+	case sfnevents.PlatformFacebook:
+		return "Facebook"
+	case sfnevents.PlatformMastodon:
+		return "Mastodon"
+	case "", sfnevents.PlatformInstagram:
+		return "Instagram"
+	}
+	return platform
+}
+
+// Live counts the targets that have not failed.
+func Live(targets []sfnevents.PublishTarget) int {
+	n := 0
+	for _, t := range targets {
+		if t.Error == "" {
+			n++
+		}
+	}
+	return n
+}
+
+// Containers returns the containers of every live target, and the video
+// containers among them.
+func Containers(targets []sfnevents.PublishTarget) (containerIDs, videoContainerIDs []string) {
+	containerIDs, videoContainerIDs = []string{}, []string{}
+	for _, t := range targets {
+		if t.Error == "" {
+			containerIDs = append(containerIDs, t.ContainerIDs...)
+			videoContainerIDs = append(videoContainerIDs, t.VideoContainerIDs...)
+		}
+	}
+	return containerIDs, videoContainerIDs
+}
+
+// Statuses reports the targets as the job's per-platform outcomes. postIDs
+// holds the posts published so far, by platform key.
+func Statuses(targets []sfnevents.PublishTarget, postIDs map[string]string) []store.PlatformPublish {
+	out := make([]store.PlatformPublish, len(targets))
+	for i, t := range targets {
+		p := store.PlatformPublish{Platform: PlatformKey(t.Platform), Status: "pending"}
+		switch postID, ok := postIDs[p.Platform]; {
+		case t.Error != "":
+			p.Status, p.Error = "error", t.Error
+		case ok:
+			p.Status, p.PostID = "published", postID
+		}
+		out[i] = p
+	}
+	return out
+}
+
+// Error describes the failed targets for the job's error: the failure
+// itself for a single-platform job, otherwise one entry per failed
+// platform.
+func Error(targets []sfnevents.PublishTarget) string {
+	if len(targets) == 1 {
+		return targets[0].Error
+	}
+	var parts []string
+	for _, t := range targets {
+		if t.Error != "" {
+			parts = append(parts, PlatformLabel(t.Platform)+": "+t.Error)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// PublishFunc publishes one target and reports its post ID and whether the
+// post was simulated (DDR-139).
+type PublishFunc func(t sfnevents.PublishTarget) (postID string, mock bool, err error)
+
+// Run is the publish-finalize pass over a job's targets.
+type Run struct {
+	Targets []sfnevents.PublishTarget
+	// PostIDs holds the posts published so far, by platform key.
+	PostIDs map[string]string
+	// Published lists the platform keys that published, in target order.
+	Published []string
+	// Simulated is true while every published post was simulated.
+	Simulated bool
+}
+
+// NewRun starts a publish pass over targets, which it updates in place.
+func NewRun(targets []sfnevents.PublishTarget) *Run {
+	return &Run{Targets: targets, PostIDs: make(map[string]string, len(targets)), Simulated: true}
+}
+
+// Publish publishes every live target in turn. A target whose publish
+// fails records the error and the pass moves on to the next one.
+func (r *Run) Publish(publish PublishFunc) {
+	for i := range r.Targets {
+		t := &r.Targets[i]
+		if t.Error != "" {
+			continue
+		}
+		postID, mock, err := publish(*t)
+		if err != nil {
+			t.Error = err.Error()
+			continue
+		}
+		platform := PlatformKey(t.Platform)
+		r.PostIDs[platform] = postID
+		r.Published = append(r.Published, platform)
+		r.Simulated = r.Simulated && mock
+	}
+}
+
+// Statuses reports the pass's per-platform outcomes so far.
+func (r *Run) Statuses() []store.PlatformPublish {
+	return Statuses(r.Targets, r.PostIDs)
+}
+
+// Status returns the job's status once the pass is done: "published" when
+// every target published, store.PublishPartiallyPublished with the failed
+// targets' errors when only some did, and "error" when none did.
+func (r *Run) Status() (status, errMsg string) {
+	switch {
+	case len(r.Published) == 0:
+		return "error", Error(r.Targets)
+	case Live(r.Targets) < len(r.Targets):
+		return store.PublishPartiallyPublished, Error(r.Targets)
+	}
+	return "published", ""
+}
@@ -0,0 +1,209 @@
+package crosspost
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fpang/ai-social-media-helper/internal/sfnevents"
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+func TestTargets(t *testing.T) {
+	tests := []struct {
+		name  string
+		event sfnevents.PublishEvent
+		want  []sfnevents.PublishTarget
+	}{
+		{
+			name:  "legacy single-platform event",
+			event: sfnevents.PublishEvent{Platform: "facebook", Caption: "hi", ContainerIDs: []string{"c1"}},
+			want:  []sfnevents.PublishTarget{{Platform: "facebook", Caption: "hi", ContainerIDs: []string{"c1"}}},
+		},
+		{
+			name:  "legacy event without a platform is Instagram",
+			event: sfnevents.PublishEvent{Caption: "hi"},
+			want:  []sfnevents.PublishTarget{{Caption: "hi"}},
+		},
+		{
+			name: "cross-post targets win over the event's fields",
+			event: sfnevents.PublishEvent{Platform: "facebook", Caption: "ignored", Targets: []sfnevents.PublishTarget{
+				{Platform: "instagram", Caption: "ig"},
+				{Platform: "mastodon", Caption: "md"},
+			}},
+			want: []sfnevents.PublishTarget{{Platform: "instagram", Caption: "ig"}, {Platform: "mastodon", Caption: "md"}},
+		},
+		{
+			name: "failed target keeps its error",
+			event: sfnevents.PublishEvent{Targets: []sfnevents.PublishTarget{
+				{Platform: "instagram"},
+				{Platform: "facebook", Error: "token expired"},
+			}},
+			want: []sfnevents.PublishTarget{{Platform: "instagram"}, {Platform: "facebook", Error: "token expired"}},
+		},
+		{
+			name: "unknown target fails instead of defaulting to Instagram",
+			event: sfnevents.PublishEvent{Targets: []sfnevents.PublishTarget{
+				{Platform: "instagram"},
+				{Platform: "myspace"},
+			}},
+			want: []sfnevents.PublishTarget{{Platform: "instagram"}, {Platform: "myspace", Error: `unsupported platform "myspace"`}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Targets(tt.event)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Targets() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetsCopiesTheEventsTargets(t *testing.T) {
+	event := sfnevents.PublishEvent{Targets: []sfnevents.PublishTarget{{Platform: "instagram"}}}
+	Targets(event)[0].Error = "failed"
+	if event.Targets[0].Error != "" {
+		t.Error("marking a resolved target failed changed the event")
+	}
+}
+
+func TestRunPublish(t *testing.T) {
+	tests := []struct {
+		name       string
+		targets    []sfnevents.PublishTarget
+		fail       map[string]bool // platforms whose publish fails
+		mock       bool
+		wantStatus string
+		wantError  string
+		wantPub    []string
+		wantCalls  []string
+		wantStates []store.PlatformPublish
+	}{
+		{
+			name:       "all targets succeed",
+			targets:    []sfnevents.PublishTarget{{Platform: "instagram"}, {Platform: "facebook"}, {Platform: "mastodon"}},
+			wantStatus: "published",
+			wantPub:    []string{"instagram", "facebook", "mastodon"},
+			wantCalls:  []string{"instagram", "facebook", "mastodon"},
+			wantStates: []store.PlatformPublish{
+				{Platform: "instagram", Status: "published", PostID: "post-instagram"},
+				{Platform: "facebook", Status: "published", PostID: "post-facebook"},
+				{Platform: "mastodon", Status: "published", PostID: "post-mastodon"},
+			},
+		},
+		{
+			name:       "legacy target without a platform publishes to Instagram",
+			targets:    []sfnevents.PublishTarget{{}},
+			mock:       true,
+			wantStatus: "published",
+			wantPub:    []string{"instagram"},
+			wantCalls:  []string{""},
+			wantStates: []store.PlatformPublish{{Platform: "instagram", Status: "published", PostID: "post-"}},
+		},
+		{
+			name:       "some targets fail",
+			targets:    []sfnevents.PublishTarget{{Platform: "instagram"}, {Platform: "facebook"}, {Platform: "mastodon"}},
+			fail:       map[string]bool{"facebook": true},
+			wantStatus: store.PublishPartiallyPublished,
+			wantError:  "Facebook: facebook is down",
+			wantPub:    []string{"instagram", "mastodon"},
+			wantCalls:  []string{"instagram", "facebook", "mastodon"},
+			wantStates: []store.PlatformPublish{
+				{Platform: "instagram", Status: "published", PostID: "post-instagram"},
+				{Platform: "facebook", Status: "error", Error: "facebook is down"},
+				{Platform: "mastodon", Status: "published", PostID: "post-mastodon"},
+			},
+		},
+		{
+			name:       "target failed in an earlier step is skipped",
+			targets:    []sfnevents.PublishTarget{{Platform: "instagram", Error: "video processing failed"}, {Platform: "facebook"}},
+			wantStatus: store.PublishPartiallyPublished,
+			wantError:  "Instagram: video processing failed",
+			wantPub:    []string{"facebook"},
+			wantCalls:  []string{"facebook"},
+			wantStates: []store.PlatformPublish{
+				{Platform: "instagram", Status: "error", Error: "video processing failed"},
+				{Platform: "facebook", Status: "published", PostID: "post-facebook"},
+			},
+		},
+		{
+			name:       "unknown target is never published",
+			targets:    Targets(sfnevents.PublishEvent{Targets: []sfnevents.PublishTarget{{Platform: "instagram"}, {Platform: "myspace"}}}),
+			wantStatus: store.PublishPartiallyPublished,
+			wantError:  `myspace: unsupported platform "myspace"`,
+			wantPub:    []string{"instagram"},
+			wantCalls:  []string{"instagram"},
+			wantStates: []store.PlatformPublish{
+				{Platform: "instagram", Status: "published", PostID: "post-instagram"},
+				{Platform: "myspace", Status: "error", Error: `unsupported platform "myspace"`},
+			},
+		},
+		{
+			name:       "every target fails",
+			targets:    []sfnevents.PublishTarget{{Platform: "instagram"}, {Platform: "facebook"}},
+			fail:       map[string]bool{"instagram": true, "facebook": true},
+			wantStatus: "error",
+			wantError:  "Instagram: instagram is down; Facebook: facebook is down",
+			wantCalls:  []string{"instagram", "facebook"},
+			wantStates: []store.PlatformPublish{
+				{Platform: "instagram", Status: "error", Error: "instagram is down"},
+				{Platform: "facebook", Status: "error", Error: "facebook is down"},
+			},
+		},
+		{
+			name:       "single-platform failure reports the error itself",
+			targets:    []sfnevents.PublishTarget{{Platform: "mastodon"}},
+			fail:       map[string]bool{"mastodon": true},
+			wantStatus: "error",
+			wantError:  "mastodon is down",
+			wantCalls:  []string{"mastodon"},
+			wantStates: []store.PlatformPublish{{Platform: "mastodon", Status: "error", Error: "mastodon is down"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			run := NewRun(tt.targets)
+			run.Publish(func(target sfnevents.PublishTarget) (string, bool, error) {
+				calls = append(calls, target.Platform)
+				if tt.fail[target.Platform] {
+					return "", false, errors.New(target.Platform + " is down")
+				}
+				return "post-" + target.Platform, tt.mock, nil
+			})
+
+			status, errMsg := run.Status()
+			if status != tt.wantStatus || errMsg != tt.wantError {
+				t.Errorf("Status() = %q, %q; want %q, %q", status, errMsg, tt.wantStatus, tt.wantError)
+			}
+			if !reflect.DeepEqual(run.Published, tt.wantPub) {
+				t.Errorf("Published = %v, want %v", run.Published, tt.wantPub)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("published to %v, want %v", calls, tt.wantCalls)
+			}
+			if got := run.Statuses(); !reflect.DeepEqual(got, tt.wantStates) {
+				t.Errorf("Statuses() = %+v, want %+v", got, tt.wantStates)
+			}
+			if wantSimulated := tt.mock || len(tt.wantPub) == 0; run.Simulated != wantSimulated {
+				t.Errorf("Simulated = %v, want %v", run.Simulated, wantSimulated)
+			}
+		})
+	}
+}
+
+func TestContainersSkipFailedTargets(t *testing.T) {
+	targets := []sfnevents.PublishTarget{
+		{Platform: "instagram", ContainerIDs: []string{"ig-1", "ig-2"}, VideoContainerIDs: []string{"ig-2"}},
+		{Platform: "facebook", ContainerIDs: []string{"fb-1"}, Error: "token expired"},
+		{Platform: "mastodon", ContainerIDs: []string{"md-1"}},
+	}
+	ids, videos := Containers(targets)
+	if !reflect.DeepEqual(ids, []string{"ig-1", "ig-2", "md-1"}) || !reflect.DeepEqual(videos, []string{"ig-2"}) {
+		t.Errorf("Containers() = %v, %v", ids, videos)
+	}
+	if Live(targets) != 2 {
+		t.Errorf("Live() = %d, want 2", Live(targets))
+	}
+}
@@ -0,0 +1,157 @@
+// Package decisions captures triage, selection, override, and caption
+// decisions from every pipeline for the RAG decision corpus (DDR-107).
+//
+// A Writer is created per job, records typed decisions, and sends them as
+// ContentFeedback events in batches on background goroutines. Callers must
+// call Flush before the job ends (before a Lambda handler returns or an HTTP
+// response is written), since a frozen Lambda never finishes in-flight sends.
+// Capture is best effort: failures are logged and never fail the job.
+package decisions
+
+import (
+	"context"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/fpang/ai-social-media-helper/internal/media"
+	"github.com/fpang/ai-social-media-helper/internal/rag"
+	"github.com/rs/zerolog/log"
+)
+
+// batchSize matches the EventBridge PutEvents limit, so each background send
+// is a single API call.
+const batchSize = 10
+
+// sendTimeout bounds a background send, which outlives the caller's context.
+const sendTimeout = 10 * time.Second
+
+// Sink delivers a batch of decisions to the RAG pipeline.
+type Sink interface {
+	Send(ctx context.Context, events []rag.ContentFeedback) error
+}
+
+type eventBridgeSink struct {
+	client *eventbridge.Client
+}
+
+// EventBridge returns a Sink that publishes to the default event bus, from
+// which rag-ingest-lambda stages decisions for Aurora. A nil client returns a
+// nil Sink, which makes a Writer drop everything it records.
+func EventBridge(client *eventbridge.Client) Sink {
+	if client == nil {
+		return nil
+	}
+	return eventBridgeSink{client: client}
+}
+
+func (s eventBridgeSink) Send(ctx context.Context, events []rag.ContentFeedback) error {
+	batcher := rag.NewBatchEmitter(s.client)
+	for _, e := range events {
+		batcher.Add(e)
+	}
+	return batcher.Flush(ctx)
+}
+
+// Job identifies the job decisions belong to. UserID should come from
+// rag.UserID so that decisions without a known user count only toward the
+// global profile (DDR-105).
+type Job struct {
+	SessionID string
+	JobID     string
+	UserID    string
+	Model     string
+}
+
+// Writer batches one job's decisions. It is safe for concurrent use.
+type Writer struct {
+	sink Sink
+	job  Job
+
+	mu       sync.Mutex
+	pending  []rag.ContentFeedback
+	firstErr error
+	inflight sync.WaitGroup
+}
+
+// NewWriter returns a Writer for job. With a nil sink every method is a no-op.
+func NewWriter(sink Sink, job Job) *Writer {
+	return &Writer{sink: sink, job: job}
+}
+
+// Flush sends everything still queued and waits for background sends. It
+// returns the first send error since the previous Flush.
+func (w *Writer) Flush(ctx context.Context) error {
+	if w.sink == nil {
+		return nil
+	}
+	w.mu.Lock()
+	batch := w.pending
+	w.pending = nil
+	w.mu.Unlock()
+
+	if len(batch) > 0 {
+		w.send(ctx, batch)
+	}
+	w.inflight.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := w.firstErr
+	w.firstErr = nil
+	return err
+}
+
+func (w *Writer) record(e rag.ContentFeedback) {
+	if w.sink == nil {
+		return
+	}
+	e.SessionID = w.job.SessionID
+	e.JobID = w.job.JobID
+	e.UserID = w.job.UserID
+	e.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	if e.Model == "" {
+		e.Model = w.job.Model
+	}
+
+	w.mu.Lock()
+	w.pending = append(w.pending, e)
+	if len(w.pending) < batchSize {
+		w.mu.Unlock()
+		return
+	}
+	batch := w.pending
+	w.pending = nil
+	w.inflight.Add(1)
+	w.mu.Unlock()
+
+	go func() {
+		defer w.inflight.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		defer cancel()
+		w.send(ctx, batch)
+	}()
+}
+
+func (w *Writer) send(ctx context.Context, batch []rag.ContentFeedback) {
+	err := w.sink.Send(ctx, batch)
+	if err == nil {
+		return
+	}
+	log.Warn().Err(err).Str("sessionId", w.job.SessionID).Str("jobId", w.job.JobID).Int("decisions", len(batch)).Msg("Failed to send decisions (best effort)")
+	w.mu.Lock()
+	if w.firstErr == nil {
+		w.firstErr = err
+	}
+	w.mu.Unlock()
+}
+
+// mediaType returns "Video" or "Photo" from a key's or filename's extension.
+func mediaType(name string) string {
+	if media.IsVideo(strings.ToLower(path.Ext(name))) {
+		return "Video"
+	}
+	return "Photo"
+}
@@ -0,0 +1,112 @@
+package decisions
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/fpang/ai-social-media-helper/internal/rag"
+)
+
+type fakeSink struct {
+	mu      sync.Mutex
+	batches [][]rag.ContentFeedback
+	err     error
+}
+
+func (s *fakeSink) Send(_ context.Context, events []rag.ContentFeedback) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, events)
+	return s.err
+}
+
+func (s *fakeSink) events() []rag.ContentFeedback {
+	var all []rag.ContentFeedback
+	for _, b := range s.batches {
+		all = append(all, b...)
+	}
+	return all
+}
+
+func TestWriterBatchesAndFlushes(t *testing.T) {
+	sink := &fakeSink{}
+	w := NewWriter(sink, Job{SessionID: "s1", JobID: "j1", UserID: "u1", Model: "gemini"})
+	for i := 0; i < 23; i++ {
+		w.RecordSelection(Selection{MediaKey: "s1/a.jpg", Selected: true})
+	}
+	if err := w.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if len(sink.batches) != 3 {
+		t.Fatalf("got %d batches, want 3", len(sink.batches))
+	}
+	for _, b := range sink.batches {
+		if len(b) > batchSize {
+			t.Errorf("batch of %d exceeds %d", len(b), batchSize)
+		}
+	}
+	events := sink.events()
+	if len(events) != 23 {
+		t.Fatalf("got %d events, want 23", len(events))
+	}
+	e := events[0]
+	if e.SessionID != "s1" || e.JobID != "j1" || e.UserID != "u1" || e.Model != "gemini" || e.Timestamp == "" {
+		t.Errorf("job fields not stamped: %+v", e)
+	}
+}
+
+func TestRecordTriageOverride(t *testing.T) {
+	sink := &fakeSink{}
+	w := NewWriter(sink, Job{SessionID: "s1"})
+	w.RecordTriage(Triage{MediaKey: "s1/a.jpg", AIKeep: true, UserKeep: true})
+	w.RecordTriage(Triage{MediaKey: "s1/b.mov", AIKeep: false, UserKeep: true})
+	w.Flush(context.Background())
+
+	events := sink.events()
+	if events[0].EventType != rag.EventTriageFinalized || events[0].IsOverride {
+		t.Errorf("agreeing verdicts recorded as %s (override %v)", events[0].EventType, events[0].IsOverride)
+	}
+	got := events[1]
+	if got.EventType != rag.EventTriageOverride || !got.IsOverride || got.AIVerdict != "discard" || got.UserVerdict != "keep" || got.MediaType != "Video" {
+		t.Errorf("reversed verdict recorded as %+v", got)
+	}
+}
+
+func TestRecordCaptionCarriesText(t *testing.T) {
+	sink := &fakeSink{}
+	w := NewWriter(sink, Job{SessionID: "s1"})
+	w.RecordCaption(Caption{GroupName: "Day 1", Text: "Sunset in Kyoto", Hashtags: []string{"#kyoto", "#travel"}, MediaKeys: []string{"s1/a.jpg", "s1/b.jpg"}})
+	w.Flush(context.Background())
+
+	events := sink.events()
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want one per post group", len(events))
+	}
+	e := events[0]
+	if e.AIVerdict != "Sunset in Kyoto" || e.Metadata["postGroupName"] != "Day 1" || e.Metadata["mediaKeys"] != "s1/a.jpg,s1/b.jpg" || e.Metadata["hashtags"] != "#kyoto,#travel" {
+		t.Errorf("caption recorded as %+v", e)
+	}
+}
+
+func TestFlushReportsSendError(t *testing.T) {
+	sink := &fakeSink{err: errors.New("boom")}
+	w := NewWriter(sink, Job{})
+	w.RecordOverride(Override{MediaKey: "a.jpg", Action: "removed"})
+	if err := w.Flush(context.Background()); err == nil {
+		t.Fatal("Flush did not report the send error")
+	}
+	if err := w.Flush(context.Background()); err != nil {
+		t.Errorf("second Flush = %v, want nil", err)
+	}
+}
+
+func TestNilSinkIsNoop(t *testing.T) {
+	w := NewWriter(EventBridge(nil), Job{})
+	w.RecordTriage(Triage{MediaKey: "a.jpg"})
+	if err := w.Flush(context.Background()); err != nil {
+		t.Errorf("Flush = %v", err)
+	}
+}
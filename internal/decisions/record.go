@@ -0,0 +1,152 @@
+package decisions
+
+import (
+	"strings"
+
+	"github.com/fpang/ai-social-media-helper/internal/rag"
+)
+
+// Triage is the keep/discard outcome for one media item. When the user's
+// verdict differs from the AI's it is recorded as a triage override, which
+// rag-profile-lambda writes to both triage_decisions and override_decisions.
+type Triage struct {
+	MediaKey string
+	Filename string
+	AIKeep   bool
+	UserKeep bool
+	Reason   string // the AI's reason
+}
+
+// RecordTriage queues a triage decision.
+func (w *Writer) RecordTriage(d Triage) {
+	e := rag.ContentFeedback{
+		EventType:   rag.EventTriageFinalized,
+		MediaKey:    d.MediaKey,
+		MediaType:   mediaType(d.MediaKey),
+		AIVerdict:   keepVerdict(d.AIKeep),
+		UserVerdict: keepVerdict(d.UserKeep),
+		Reason:      d.Reason,
+		Metadata:    map[string]string{"filename": d.Filename},
+	}
+	if d.AIKeep != d.UserKeep {
+		e.EventType = rag.EventTriageOverride
+		e.IsOverride = true
+	}
+	w.record(e)
+}
+
+func keepVerdict(keep bool) string {
+	if keep {
+		return "keep"
+	}
+	return "discard"
+}
+
+// Selection is the AI's selected/excluded outcome for one media item.
+type Selection struct {
+	MediaKey          string
+	Filename          string
+	MediaType         string // "Photo" or "Video"; derived from the key when empty
+	Selected          bool
+	Reason            string // justification or exclusion reason
+	SceneGroup        string
+	ExclusionCategory string
+}
+
+// RecordSelection queues a selection decision.
+func (w *Writer) RecordSelection(d Selection) {
+	verdict := "excluded"
+	if d.Selected {
+		verdict = "selected"
+	}
+	if d.MediaType == "" {
+		d.MediaType = mediaType(d.MediaKey)
+	}
+	metadata := map[string]string{"filename": d.Filename}
+	if d.SceneGroup != "" {
+		metadata["sceneGroup"] = d.SceneGroup
+	}
+	if !d.Selected {
+		metadata["exclusionCategory"] = d.ExclusionCategory
+		metadata["exclusionReason"] = d.Reason
+	}
+	w.record(rag.ContentFeedback{
+		EventType:   rag.EventSelectionFinalized,
+		MediaKey:    d.MediaKey,
+		MediaType:   d.MediaType,
+		AIVerdict:   verdict,
+		UserVerdict: verdict,
+		Reason:      d.Reason,
+		Metadata:    metadata,
+	})
+}
+
+// Override is a user's change to the AI's selection: Action is "added_back"
+// for an item the AI excluded or "removed" for one it selected. Finalized
+// marks the delta submitted when the user proceeds, as opposed to a single
+// toggle during review.
+type Override struct {
+	MediaKey  string
+	Filename  string
+	MediaType string // derived from the key when empty
+	Action    string
+	Reason    string // the AI's reason
+	Finalized bool
+}
+
+// RecordOverride queues a selection override.
+func (w *Writer) RecordOverride(d Override) {
+	aiVerdict := "excluded"
+	if d.Action == "removed" {
+		aiVerdict = "selected"
+	}
+	if d.MediaType == "" {
+		d.MediaType = mediaType(d.MediaKey)
+	}
+	e := rag.ContentFeedback{
+		EventType:   rag.EventOverrideAction,
+		MediaKey:    d.MediaKey,
+		MediaType:   d.MediaType,
+		AIVerdict:   aiVerdict,
+		UserVerdict: d.Action,
+		Reason:      d.Reason,
+		IsOverride:  true,
+		Metadata:    map[string]string{"filename": d.Filename},
+	}
+	if d.Finalized {
+		e.EventType = rag.EventOverridesFinalized
+		e.Metadata["action"] = d.Action
+		e.Metadata["finalized"] = "true"
+	}
+	w.record(e)
+}
+
+// Caption is a generated caption for one post group.
+type Caption struct {
+	GroupName   string
+	Text        string
+	Hashtags    []string
+	LocationTag string
+	MediaKeys   []string
+}
+
+// RecordCaption queues a caption decision. One event is recorded per post
+// group, carrying all of its media keys, to match caption_decisions'
+// (session_id, post_group_name) key.
+func (w *Writer) RecordCaption(d Caption) {
+	var mediaKey string
+	if len(d.MediaKeys) > 0 {
+		mediaKey = d.MediaKeys[0]
+	}
+	w.record(rag.ContentFeedback{
+		EventType: rag.EventDescriptionFinalized,
+		MediaKey:  mediaKey,
+		AIVerdict: d.Text,
+		Metadata: map[string]string{
+			"postGroupName": d.GroupName,
+			"hashtags":      strings.Join(d.Hashtags, ","),
+			"locationTag":   d.LocationTag,
+			"mediaKeys":     strings.Join(d.MediaKeys, ","),
+		},
+	})
+}
@@ -0,0 +1,395 @@
+// Package facebook provides a client for publishing to a Facebook Page via
+// the Graph API: photo, video and multi-photo (album) posts.
+//
+// The client requires a Page access token and the Page ID, both typically
+// loaded from SSM Parameter Store at Lambda cold start.
+//
+// Publishing follows the same phases as Instagram (DDR-040), so the publish
+// pipeline drives both platforms through one state machine (DDR-147):
+//  1. Create containers: photos are uploaded unpublished; a single photo is
+//     wrapped in an unpublished Page post at once. Videos are uploaded
+//     unpublished with their description.
+//  2. For videos: poll processing status until the video is ready
+//  3. For albums: create an unpublished Page post attaching every photo
+//  4. Publish the Page post or video
+//
+// With FACEBOOK_MODE=mock, or per job with a publish dry run,
+// NewMockClient simulates the Graph API instead.
+package facebook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// defaultBaseURL is the Facebook Graph API base URL.
+	defaultBaseURL = "https://graph.facebook.com/v22.0"
+
+	// defaultTimeout is the HTTP client timeout for API calls.
+	defaultTimeout = 30 * time.Second
+
+	// maxAlbumItems caps the photos attached to one Page post.
+	maxAlbumItems = 20
+
+	// maxLocationResults caps the locations returned by SearchLocations.
+	maxLocationResults = 10
+)
+
+// Video processing states reported by VideoStatus.
+const (
+	VideoProcessing = "processing"
+	VideoReady      = "ready"
+	VideoError      = "error"
+)
+
+// Client provides methods for publishing to a Facebook Page.
+type Client struct {
+	httpClient *http.Client
+	pageToken  string
+	pageID     string
+	baseURL    string
+	mock       bool // answered by mockTransport
+}
+
+// NewClient creates a Facebook Page client.
+// pageToken and pageID are loaded from SSM Parameter Store at Lambda cold start.
+func NewClient(pageToken, pageID string) *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Timeout:   defaultTimeout,
+			Transport: logging.OutboundTransport("facebook", nil),
+		},
+		pageToken: pageToken,
+		pageID:    pageID,
+		baseURL:   defaultBaseURL,
+	}
+}
+
+// PostOptions are the post-level settings of a Page post.
+type PostOptions struct {
+	// PlaceID tags the post with a place. Instagram location IDs are
+	// Facebook place Page IDs, so the same ID works on both platforms.
+	PlaceID string
+}
+
+// --- API response types ---
+
+// apiResponse is the generic Graph API response: creates return an id (and,
+// for photos, the post_id); updates return success.
+type apiResponse struct {
+	ID      string  `json:"id,omitempty"`
+	PostID  string  `json:"post_id,omitempty"`
+	Success bool    `json:"success,omitempty"`
+	Error   *apiErr `json:"error,omitempty"`
+}
+
+type apiErr struct {
+	Message   string `json:"message"`
+	Type      string `json:"type"`
+	Code      int    `json:"code"`
+	FBTraceID string `json:"fbtrace_id,omitempty"`
+}
+
+// videoStatusResponse is the response from GET /{video-id}?fields=status.
+type videoStatusResponse struct {
+	ID     string `json:"id"`
+	Status struct {
+		VideoStatus string `json:"video_status"` // ready, processing, error, ...
+	} `json:"status"`
+	Error *apiErr `json:"error,omitempty"`
+}
+
+// locationSearchResponse is the response from GET /pages/search.
+type locationSearchResponse struct {
+	Data []struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		Location *struct {
+			Street    string  `json:"street"`
+			City      string  `json:"city"`
+			Country   string  `json:"country"`
+			Latitude  float64 `json:"latitude"`
+			Longitude float64 `json:"longitude"`
+		} `json:"location"`
+	} `json:"data"`
+	Error *apiErr `json:"error,omitempty"`
+}
+
+// Location is a Facebook Page with a physical address. Its ID is the
+// location_id Instagram accepts when creating a post container, and the
+// place of a Page post (PostOptions.PlaceID).
+type Location struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Street    string  `json:"street,omitempty"`
+	City      string  `json:"city,omitempty"`
+	Country   string  `json:"country,omitempty"`
+	Latitude  float64 `json:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
+}
+
+// --- Container creation ---
+
+// CreateUnpublishedPhoto uploads a photo to the Page without posting it and
+// returns its ID, to be attached to a post by CreatePost.
+// imageURL must be a publicly accessible URL (e.g., presigned S3 GET URL).
+// altText, when set, replaces Facebook's automatic alt-text (DDR-154).
+func (c *Client) CreateUnpublishedPhoto(ctx context.Context, imageURL, altText string) (string, error) {
+	params := url.Values{
+		"url":          {imageURL},
+		"published":    {"false"},
+		"access_token": {c.pageToken},
+	}
+	if altText = strings.TrimSpace(altText); altText != "" {
+		params.Set("alt_text_custom", altText)
+	}
+	resp, err := c.postForm(ctx, fmt.Sprintf("/%s/photos", c.pageID), params)
+	if err != nil {
+		return "", fmt.Errorf("create unpublished photo: %w", err)
+	}
+	if resp.ID == "" {
+		return "", fmt.Errorf("create unpublished photo: no ID returned")
+	}
+	log.Info().Str("photoId", resp.ID).Msg("Unpublished Facebook photo created")
+	return resp.ID, nil
+}
+
+// CreateUnpublishedVideo uploads a video to the Page without posting it.
+// description is the post text. Facebook processes the video before it can
+// be published; poll VideoStatus until it reports VideoReady.
+func (c *Client) CreateUnpublishedVideo(ctx context.Context, videoURL, description string) (string, error) {
+	params := url.Values{
+		"file_url":     {videoURL},
+		"description":  {description},
+		"published":    {"false"},
+		"access_token": {c.pageToken},
+	}
+	resp, err := c.postForm(ctx, fmt.Sprintf("/%s/videos", c.pageID), params)
+	if err != nil {
+		return "", fmt.Errorf("create unpublished video: %w", err)
+	}
+	if resp.ID == "" {
+		return "", fmt.Errorf("create unpublished video: no ID returned")
+	}
+	log.Info().Str("videoId", resp.ID).Msg("Unpublished Facebook video created")
+	return resp.ID, nil
+}
+
+// CreatePost creates an unpublished Page post with message and the given
+// unpublished photos attached, and returns the post ID ({pageId}_{postId}).
+func (c *Client) CreatePost(ctx context.Context, photoIDs []string, message string, opts PostOptions) (string, error) {
+	if len(photoIDs) == 0 {
+		return "", fmt.Errorf("post requires at least 1 photo")
+	}
+	if len(photoIDs) > maxAlbumItems {
+		return "", fmt.Errorf("post supports at most %d photos, got %d", maxAlbumItems, len(photoIDs))
+	}
+
+	params := url.Values{
+		"message":      {message},
+		"published":    {"false"},
+		"access_token": {c.pageToken},
+	}
+	for i, id := range photoIDs {
+		media, _ := json.Marshal(map[string]string{"media_fbid": id})
+		params.Set(fmt.Sprintf("attached_media[%d]", i), string(media))
+	}
+	if opts.PlaceID != "" {
+		params.Set("place", opts.PlaceID)
+	}
+
+	resp, err := c.postForm(ctx, fmt.Sprintf("/%s/feed", c.pageID), params)
+	if err != nil {
+		return "", fmt.Errorf("create page post: %w", err)
+	}
+	if resp.ID == "" {
+		return "", fmt.Errorf("create page post: no ID returned")
+	}
+	return resp.ID, nil
+}
+
+// --- Publishing ---
+
+// IsPostID reports whether id names a Page post ({pageId}_{postId}) rather
+// than a video.
+func IsPostID(id string) bool {
+	return strings.Contains(id, "_")
+}
+
+// Publish makes an unpublished Page post or video visible and returns its
+// ID, which stays the same once published.
+func (c *Client) Publish(ctx context.Context, id string) (string, error) {
+	log.Debug().Str("containerId", id).Msg("Publishing Facebook container")
+	params := url.Values{"access_token": {c.pageToken}}
+	if IsPostID(id) {
+		params.Set("is_published", "true")
+	} else {
+		params.Set("published", "true")
+	}
+
+	resp, err := c.postForm(ctx, "/"+id, params)
+	if err != nil {
+		return "", fmt.Errorf("publish %s: %w", id, err)
+	}
+	if !resp.Success {
+		return "", fmt.Errorf("publish %s: not confirmed by the API", id)
+	}
+	log.Info().Str("postId", id).Msg("Facebook post published successfully")
+	return id, nil
+}
+
+// --- Status polling ---
+
+// VideoStatus returns the processing status of an uploaded video:
+// VideoProcessing, VideoReady or VideoError.
+func (c *Client) VideoStatus(ctx context.Context, videoID string) (string, error) {
+	endpoint := fmt.Sprintf("/%s?fields=status&access_token=%s", videoID, url.QueryEscape(c.pageToken))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("build request: %w", err)
+	}
+
+	httpResp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("video status request: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return "", fmt.Errorf("read response: %w", err)
+	}
+
+	var status videoStatusResponse
+	if err := json.Unmarshal(body, &status); err != nil {
+		return "", fmt.Errorf("parse response: %w", err)
+	}
+	if status.Error != nil {
+		return "", fmt.Errorf("API error: %s (code %d)", status.Error.Message, status.Error.Code)
+	}
+
+	switch status.Status.VideoStatus {
+	case "ready":
+		return VideoReady, nil
+	case "error", "expired":
+		return VideoError, nil
+	default:
+		return VideoProcessing, nil
+	}
+}
+
+// --- Location search ---
+
+// SearchLocations returns up to 10 taggable locations matching query, using
+// the Graph API Pages search (DDR-138). The search needs a Facebook token;
+// the Page token is one, while the Instagram-Login token is not accepted on
+// graph.facebook.com. Pages without a physical location cannot be tagged
+// and are left out.
+func (c *Client) SearchLocations(ctx context.Context, query string) ([]Location, error) {
+	params := url.Values{
+		"q":            {query},
+		"fields":       {"id,name,location{street,city,country,latitude,longitude}"},
+		"limit":        {fmt.Sprint(maxLocationResults)},
+		"access_token": {c.pageToken},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/pages/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+
+	httpResp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("location search request: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	var resp locationSearchResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("parse response: %w (body: %s)", err, truncate(string(body), 200))
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("API error: %s (code %d)", resp.Error.Message, resp.Error.Code)
+	}
+
+	locations := make([]Location, 0, len(resp.Data))
+	for _, page := range resp.Data {
+		if page.Location == nil {
+			continue
+		}
+		locations = append(locations, Location{
+			ID:        page.ID,
+			Name:      page.Name,
+			Street:    page.Location.Street,
+			City:      page.Location.City,
+			Country:   page.Location.Country,
+			Latitude:  page.Location.Latitude,
+			Longitude: page.Location.Longitude,
+		})
+	}
+	return locations, nil
+}
+
+// --- Internal helpers ---
+
+// postForm sends a POST request with form-encoded parameters to the Graph API.
+func (c *Client) postForm(ctx context.Context, endpoint string, params url.Values) (*apiResponse, error) {
+	startTime := time.Now()
+
+	log.Debug().Str("method", http.MethodPost).Str("path", endpoint).Msg("Facebook API request")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+endpoint,
+		strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	httpResp, err := c.httpClient.Do(req)
+	duration := time.Since(startTime)
+	if err != nil {
+		log.Debug().Int("statusCode", 0).Dur("duration", duration).Err(err).Msg("Facebook API response")
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	log.Debug().Int("statusCode", httpResp.StatusCode).Dur("duration", duration).Msg("Facebook API response")
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	var resp apiResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("parse response: %w (body: %s)", err, truncate(string(body), 200))
+	}
+	if resp.Error != nil {
+		log.Error().Str("errorMessage", resp.Error.Message).Str("errorType", resp.Error.Type).Int("errorCode", resp.Error.Code).Msg("Facebook API error")
+		return nil, fmt.Errorf("Facebook API error: %s (type: %s, code: %d)",
+			resp.Error.Message, resp.Error.Type, resp.Error.Code)
+	}
+	return &resp, nil
+}
+
+// truncate returns the first n characters of s, appending "..." if truncated.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,237 @@
+package facebook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestClient creates a Client pointing at a test HTTP server.
+func newTestClient(server *httptest.Server) *Client {
+	return &Client{
+		httpClient: server.Client(),
+		pageToken:  "test-token",
+		pageID:     "777",
+		baseURL:    server.URL,
+	}
+}
+
+// newInstantMock returns a mock client with no simulated latency and a
+// clock the test controls.
+func newInstantMock(now *time.Time) *Client {
+	c := NewMockClient()
+	tr := newMockTransport()
+	tr.createDelay, tr.publishDelay = 0, 0
+	tr.Now = func() time.Time { return *now }
+	c.httpClient = &http.Client{Transport: tr}
+	return c
+}
+
+func TestCreateUnpublishedPhoto(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/777/photos" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		r.ParseForm()
+		if r.Form.Get("url") != "https://example.com/photo.jpg" {
+			t.Errorf("unexpected url: %s", r.Form.Get("url"))
+		}
+		if r.Form.Get("published") != "false" {
+			t.Errorf("expected published=false")
+		}
+		if got := r.Form.Get("alt_text_custom"); got != "A tram on a hill" {
+			t.Errorf("alt_text_custom = %q", got)
+		}
+		json.NewEncoder(w).Encode(apiResponse{ID: "photo-001"})
+	}))
+	defer server.Close()
+
+	id, err := newTestClient(server).CreateUnpublishedPhoto(context.Background(), "https://example.com/photo.jpg", " A tram on a hill ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != "photo-001" {
+		t.Errorf("expected photo-001, got %s", id)
+	}
+}
+
+func TestCreatePost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/777/feed" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		r.ParseForm()
+		if got := r.Form.Get("attached_media[1]"); got != `{"media_fbid":"p2"}` {
+			t.Errorf("attached_media[1] = %s", got)
+		}
+		if r.Form.Get("message") != "Hello" || r.Form.Get("place") != "place-1" || r.Form.Get("published") != "false" {
+			t.Errorf("unexpected form: %v", r.Form)
+		}
+		json.NewEncoder(w).Encode(apiResponse{ID: "777_888"})
+	}))
+	defer server.Close()
+
+	id, err := newTestClient(server).CreatePost(context.Background(), []string{"p1", "p2"}, "Hello", PostOptions{PlaceID: "place-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != "777_888" {
+		t.Errorf("expected 777_888, got %s", id)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	tests := []struct {
+		id    string
+		param string
+	}{
+		{"777_888", "is_published"},
+		{"999", "published"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/"+tt.id {
+					t.Errorf("unexpected path: %s", r.URL.Path)
+				}
+				r.ParseForm()
+				if r.Form.Get(tt.param) != "true" {
+					t.Errorf("expected %s=true, got form %v", tt.param, r.Form)
+				}
+				json.NewEncoder(w).Encode(apiResponse{Success: true})
+			}))
+			defer server.Close()
+
+			id, err := newTestClient(server).Publish(context.Background(), tt.id)
+			if err != nil || id != tt.id {
+				t.Errorf("Publish = %q, %v", id, err)
+			}
+		})
+	}
+}
+
+func TestVideoStatus(t *testing.T) {
+	for raw, want := range map[string]string{"processing": VideoProcessing, "ready": VideoReady, "error": VideoError} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("fields") != "status" {
+				t.Errorf("unexpected query: %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"id":"999","status":{"video_status":"` + raw + `"}}`))
+		}))
+		got, err := newTestClient(server).VideoStatus(context.Background(), "999")
+		server.Close()
+		if err != nil || got != want {
+			t.Errorf("VideoStatus(%s) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":{"message":"Invalid token","type":"OAuthException","code":190}}`))
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(server).CreateUnpublishedPhoto(context.Background(), "https://example.com/photo.jpg", "")
+	if err == nil || !strings.Contains(err.Error(), "Invalid token") {
+		t.Errorf("expected API error, got %v", err)
+	}
+}
+
+func TestMockAlbumAndVideoFlow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_800_000_000, 0)
+	c := newInstantMock(&now)
+
+	p1, err := c.CreateUnpublishedPhoto(ctx, "https://example.com/a.jpg", "")
+	if err != nil || !IsMockID(p1) {
+		t.Fatalf("CreateUnpublishedPhoto = %q, %v", p1, err)
+	}
+	p2, _ := c.CreateUnpublishedPhoto(ctx, "https://example.com/b.jpg", "")
+	post, err := c.CreatePost(ctx, []string{p1, p2}, "caption", PostOptions{})
+	if err != nil || !IsPostID(post) || !IsMockID(post) {
+		t.Fatalf("CreatePost = %q, %v", post, err)
+	}
+	if id, err := c.Publish(ctx, post); err != nil || id != post {
+		t.Errorf("Publish(post) = %q, %v", id, err)
+	}
+
+	vid, err := c.CreateUnpublishedVideo(ctx, "https://example.com/c.mp4", "caption")
+	if err != nil || IsPostID(vid) {
+		t.Fatalf("CreateUnpublishedVideo = %q, %v", vid, err)
+	}
+	if status, _ := c.VideoStatus(ctx, vid); status != VideoProcessing {
+		t.Errorf("new video status = %q, want %q", status, VideoProcessing)
+	}
+	if _, err := c.Publish(ctx, vid); err == nil {
+		t.Error("publishing a processing video should fail")
+	}
+	now = now.Add(mockVideoProcessing)
+	if status, _ := c.VideoStatus(ctx, vid); status != VideoReady {
+		t.Errorf("video status after processing = %q, want %q", status, VideoReady)
+	}
+	if _, err := c.Publish(ctx, vid); err != nil {
+		t.Errorf("Publish(video) = %v", err)
+	}
+}
+
+func TestSearchLocations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/pages/search" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.Query().Get("q") != "central park" {
+			t.Errorf("unexpected q: %s", r.URL.Query().Get("q"))
+		}
+		if r.URL.Query().Get("access_token") != "test-token" {
+			t.Errorf("expected the Page token, got %q", r.URL.Query().Get("access_token"))
+		}
+		w.Write([]byte(`{"data":[
+			{"id":"110843418940484","name":"Central Park","location":{"city":"New York","country":"United States","latitude":40.78,"longitude":-73.96}},
+			{"id":"999","name":"Central Park Fan Club"}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+	locations, err := client.SearchLocations(context.Background(), "central park")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(locations) != 1 {
+		t.Fatalf("expected 1 location (pages without a location dropped), got %d", len(locations))
+	}
+	if got := locations[0]; got.ID != "110843418940484" || got.City != "New York" || got.Latitude != 40.78 {
+		t.Errorf("unexpected location: %+v", got)
+	}
+}
+
+func TestSearchLocationsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":{"message":"(#10) Requires Page Public Metadata Access","type":"OAuthException","code":10}}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+	_, err := client.SearchLocations(context.Background(), "central park")
+	if err == nil || !strings.Contains(err.Error(), "Page Public Metadata Access") {
+		t.Errorf("expected API error, got: %v", err)
+	}
+}
+
+func TestMockSearchLocations(t *testing.T) {
+	now := time.Now()
+	c := newInstantMock(&now)
+	first, err := c.SearchLocations(context.Background(), "Central Park")
+	if err != nil || len(first) != 1 || first[0].Name != "Central Park" {
+		t.Fatalf("SearchLocations = %+v, %v", first, err)
+	}
+	again, _ := c.SearchLocations(context.Background(), "central park")
+	if again[0].ID != first[0].ID {
+		t.Errorf("IDs differ across searches: %s vs %s", first[0].ID, again[0].ID)
+	}
+}
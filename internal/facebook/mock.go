@@ -0,0 +1,198 @@
+package facebook
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/mockapi"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Mock mode (DDR-147, following DDR-139) ---
+//
+// A mock client answers the Graph API calls this package makes without
+// reaching Facebook. IDs start with "mock-"; post IDs keep the real
+// {pageId}_{postId} shape ("mock-page_post-..."), so IsPostID holds for
+// them too. Videos report processing until mockVideoProcessing has passed
+// since their upload; the upload time is encoded in the ID, so any Lambda
+// invocation can answer a status poll.
+
+const (
+	// ModeEnv selects the client mode: "mock" simulates Facebook for the
+	// whole deployment; anything else publishes for real.
+	ModeEnv = "FACEBOOK_MODE"
+
+	// Simulated Graph API latencies, close to what the live API shows.
+	mockCreateDelay     = 800 * time.Millisecond
+	mockPublishDelay    = time.Second
+	mockVideoProcessing = 30 * time.Second
+)
+
+// MockEnabled reports whether FACEBOOK_MODE=mock is set.
+func MockEnabled() bool {
+	return strings.EqualFold(os.Getenv(ModeEnv), "mock")
+}
+
+// NewMockClient returns a Client that simulates uploads, video processing
+// and publishing with realistic timing and fake IDs.
+func NewMockClient() *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Timeout:   defaultTimeout,
+			Transport: logging.OutboundTransport("facebook-mock", newMockTransport()),
+		},
+		pageToken: "mock-token",
+		pageID:    "mock-page",
+		baseURL:   defaultBaseURL,
+		mock:      true,
+	}
+}
+
+// IsMock reports whether c simulates Facebook instead of calling it.
+func (c *Client) IsMock() bool {
+	return c.mock
+}
+
+// IsMockID reports whether id was handed out by a mock client.
+func IsMockID(id string) bool {
+	return mockapi.IsID(id)
+}
+
+// mockTransport is an http.RoundTripper that plays the Graph API.
+type mockTransport struct {
+	*mockapi.Clock
+	createDelay     time.Duration
+	publishDelay    time.Duration
+	videoProcessing time.Duration
+}
+
+func newMockTransport() *mockTransport {
+	return &mockTransport{
+		Clock:           mockapi.NewClock(),
+		createDelay:     mockCreateDelay,
+		publishDelay:    mockPublishDelay,
+		videoProcessing: mockVideoProcessing,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (m *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := req.URL.Path
+	switch {
+	case req.Method == http.MethodPost && strings.HasSuffix(path, "/photos"):
+		return m.create(req, "photo")
+	case req.Method == http.MethodPost && strings.HasSuffix(path, "/videos"):
+		return m.create(req, "video")
+	case req.Method == http.MethodPost && strings.HasSuffix(path, "/feed"):
+		return m.createPost(req)
+	case req.Method == http.MethodPost:
+		return m.publish(req)
+	case req.Method == http.MethodGet && strings.HasSuffix(path, "/pages/search"):
+		return m.searchLocations(req)
+	case req.Method == http.MethodGet:
+		return m.videoStatus(req)
+	}
+	return mockError(req, fmt.Sprintf("mock: unsupported request %s %s", req.Method, path))
+}
+
+func (m *mockTransport) create(req *http.Request, kind string) (*http.Response, error) {
+	if err := mockapi.Sleep(req.Context(), m.createDelay); err != nil {
+		return nil, err
+	}
+	id := m.NewID(kind)
+	log.Info().Str("containerId", id).Str("kind", kind).Msg("Mock Facebook upload created")
+	return mockapi.JSON(req, http.StatusOK, apiResponse{ID: id})
+}
+
+func (m *mockTransport) createPost(req *http.Request) (*http.Response, error) {
+	form, err := mockapi.ReadForm(req)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; ; i++ {
+		raw := form.Get(fmt.Sprintf("attached_media[%d]", i))
+		if raw == "" {
+			break
+		}
+		var media struct {
+			ID string `json:"media_fbid"`
+		}
+		if err := json.Unmarshal([]byte(raw), &media); err != nil || !IsMockID(media.ID) {
+			return mockError(req, fmt.Sprintf("mock: unknown attached photo %q", raw))
+		}
+	}
+	if err := mockapi.Sleep(req.Context(), m.createDelay); err != nil {
+		return nil, err
+	}
+	id := m.NewID("page_post")
+	log.Info().Str("containerId", id).Bool("placed", form.Get("place") != "").Msg("Mock Facebook post created")
+	return mockapi.JSON(req, http.StatusOK, apiResponse{ID: id})
+}
+
+func (m *mockTransport) publish(req *http.Request) (*http.Response, error) {
+	id := lastSegment(req.URL.Path)
+	kind, created, ok := mockapi.ParseID(id)
+	if !ok {
+		return mockError(req, fmt.Sprintf("mock: unknown container %q", id))
+	}
+	if kind == "video" && m.Since(created) < m.videoProcessing {
+		return mockError(req, fmt.Sprintf("mock: video %s is still processing", id))
+	}
+	if err := mockapi.Sleep(req.Context(), m.publishDelay); err != nil {
+		return nil, err
+	}
+	log.Info().Str("postId", id).Msg("Mock Facebook post published")
+	return mockapi.JSON(req, http.StatusOK, apiResponse{Success: true})
+}
+
+func (m *mockTransport) videoStatus(req *http.Request) (*http.Response, error) {
+	id := lastSegment(req.URL.Path)
+	kind, created, ok := mockapi.ParseID(id)
+	if !ok || kind != "video" {
+		return mockError(req, fmt.Sprintf("mock: unknown video %q", id))
+	}
+	var resp videoStatusResponse
+	resp.ID = id
+	resp.Status.VideoStatus = "ready"
+	if m.Since(created) < m.videoProcessing {
+		resp.Status.VideoStatus = "processing"
+	}
+	return mockapi.JSON(req, http.StatusOK, resp)
+}
+
+// searchLocations returns one location named after the query, with an ID
+// derived from it so repeated searches agree.
+func (m *mockTransport) searchLocations(req *http.Request) (*http.Response, error) {
+	query := req.URL.Query().Get("q")
+	h := fnv.New64a()
+	h.Write([]byte(strings.ToLower(query)))
+	body := map[string]any{
+		"data": []map[string]any{{
+			"id":       strconv.FormatUint(1e14+h.Sum64()%1e14, 10),
+			"name":     query,
+			"location": map[string]any{"city": "Mock City", "country": "Mockland"},
+		}},
+	}
+	return mockapi.JSON(req, http.StatusOK, body)
+}
+
+// --- Internal helpers ---
+
+func lastSegment(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// mockError answers like a Graph API error, so callers see the same error
+// path as with the live API.
+func mockError(req *http.Request, msg string) (*http.Response, error) {
+	return mockapi.JSON(req, http.StatusOK, map[string]any{
+		"error": apiErr{Message: msg, Type: "MockException", Code: 100},
+	})
+}
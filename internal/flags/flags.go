@@ -0,0 +1,152 @@
+// Package flags provides per-deployment feature flags (DDR-132).
+//
+// All flags of a deployment live in one source (an SSM parameter in Lambda)
+// as a JSON object of flag name to bool. Each process caches the values for a
+// short TTL, so a flag flipped through the admin endpoint reaches every Lambda
+// within one TTL and without a redeploy.
+package flags
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Flag names a feature that can be switched at runtime.
+type Flag string
+
+const (
+	// RAGContext injects the user's RAG profile into triage, selection and
+	// description prompts (DDR-105).
+	RAGContext Flag = "rag-context"
+	// Imagen lets photo and video enhancement use Imagen for localized edits.
+	Imagen Flag = "imagen"
+	// ReverseGeocode turns photo GPS coordinates into place names for
+	// captions and location tags (DDR-137).
+	ReverseGeocode Flag = "reverse-geocode"
+	// VideoHighlights cuts Reels highlight clips from selected videos
+	// (DDR-187).
+	VideoHighlights Flag = "video-highlights"
+)
+
+// defaults holds every known flag and its value when the source does not set it.
+var defaults = map[Flag]bool{
+	RAGContext:      true,
+	Imagen:          true,
+	ReverseGeocode:  true,
+	VideoHighlights: true,
+}
+
+// DefaultTTL is how long evaluated flags are cached per process.
+const DefaultTTL = time.Minute
+
+// Known returns every known flag, sorted by name.
+func Known() []Flag {
+	known := make([]Flag, 0, len(defaults))
+	for f := range defaults {
+		known = append(known, f)
+	}
+	sort.Slice(known, func(i, j int) bool { return known[i] < known[j] })
+	return known
+}
+
+// Lookup returns the flag with the given name, if it is known.
+func Lookup(name string) (Flag, bool) {
+	f := Flag(name)
+	_, ok := defaults[f]
+	return f, ok
+}
+
+// Default returns the value of f when its source does not set it.
+func Default(f Flag) bool {
+	return defaults[f]
+}
+
+// Source loads and saves the raw flag values of a deployment.
+type Source interface {
+	Load(ctx context.Context) (map[string]bool, error)
+	Save(ctx context.Context, values map[string]bool) error
+}
+
+// Set evaluates flags against a Source with a per-process cache.
+// A nil *Set is valid and reports every flag at its default.
+type Set struct {
+	source Source
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	values   map[string]bool
+	loadedAt time.Time
+}
+
+// New returns a Set that reloads from source at most once per ttl.
+func New(source Source, ttl time.Duration) *Set {
+	return &Set{source: source, ttl: ttl, now: time.Now}
+}
+
+// Enabled reports whether f is on. When the source cannot be read the last
+// loaded values are kept, or the defaults if nothing was ever loaded.
+func (s *Set) Enabled(ctx context.Context, f Flag) bool {
+	if s == nil {
+		return defaults[f]
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refresh(ctx)
+	if v, ok := s.values[string(f)]; ok {
+		return v
+	}
+	return defaults[f]
+}
+
+// All returns the current value of every known flag.
+func (s *Set) All(ctx context.Context) map[Flag]bool {
+	all := make(map[Flag]bool, len(defaults))
+	for _, f := range Known() {
+		all[f] = s.Enabled(ctx, f)
+	}
+	return all
+}
+
+// Set writes a flag value through to the source and updates the cache.
+// Values of other flags are re-read first so concurrent flips are not lost
+// unless they race within the same read-modify-write.
+func (s *Set) Set(ctx context.Context, f Flag, enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values, err := s.source.Load(ctx)
+	if err != nil {
+		return err
+	}
+	if values == nil {
+		values = make(map[string]bool, 1)
+	}
+	values[string(f)] = enabled
+	if err := s.source.Save(ctx, values); err != nil {
+		return err
+	}
+	s.values = values
+	s.loadedAt = s.now()
+	return nil
+}
+
+// refresh reloads the values when the cache has expired. Must hold s.mu.
+// A failed load also restarts the TTL so an unavailable source is not
+// retried on every evaluation.
+func (s *Set) refresh(ctx context.Context) {
+	if !s.loadedAt.IsZero() && s.now().Sub(s.loadedAt) < s.ttl {
+		return
+	}
+	s.loadedAt = s.now()
+	values, err := s.source.Load(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load feature flags, keeping previous values")
+		return
+	}
+	s.values = values
+}
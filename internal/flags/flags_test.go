@@ -0,0 +1,99 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeSource struct {
+	values map[string]bool
+	err    error
+	loads  int
+}
+
+func (f *fakeSource) Load(context.Context) (map[string]bool, error) {
+	f.loads++
+	if f.err != nil {
+		return nil, f.err
+	}
+	values := make(map[string]bool, len(f.values))
+	for k, v := range f.values {
+		values[k] = v
+	}
+	return values, nil
+}
+
+func (f *fakeSource) Save(_ context.Context, values map[string]bool) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.values = values
+	return nil
+}
+
+func TestSetEnabled(t *testing.T) {
+	ctx := context.Background()
+	src := &fakeSource{values: map[string]bool{string(Imagen): false}}
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	s := New(src, time.Minute)
+	s.now = func() time.Time { return now }
+
+	if s.Enabled(ctx, Imagen) {
+		t.Error("imagen should be off")
+	}
+	if !s.Enabled(ctx, RAGContext) {
+		t.Error("rag-context should fall back to its default")
+	}
+	if src.loads != 1 {
+		t.Errorf("loads = %d, want 1 within the TTL", src.loads)
+	}
+
+	src.values[string(Imagen)] = true
+	if s.Enabled(ctx, Imagen) {
+		t.Error("cached value should be used within the TTL")
+	}
+	now = now.Add(time.Minute)
+	if !s.Enabled(ctx, Imagen) {
+		t.Error("value should be reloaded after the TTL")
+	}
+
+	src.err = errors.New("throttled")
+	now = now.Add(time.Minute)
+	if !s.Enabled(ctx, Imagen) {
+		t.Error("a failed reload should keep the previous value")
+	}
+	s.Enabled(ctx, Imagen)
+	if src.loads != 3 {
+		t.Errorf("loads = %d, want 3: a failed reload should wait for the TTL", src.loads)
+	}
+}
+
+func TestSetSet(t *testing.T) {
+	ctx := context.Background()
+	src := &fakeSource{values: map[string]bool{string(RAGContext): false}}
+	s := New(src, time.Minute)
+
+	if err := s.Set(ctx, Imagen, false); err != nil {
+		t.Fatal(err)
+	}
+	if src.values[string(Imagen)] || src.values[string(RAGContext)] || len(src.values) != 2 {
+		t.Errorf("saved %v, want both flags off", src.values)
+	}
+	if got := s.All(ctx); got[Imagen] || got[RAGContext] {
+		t.Errorf("All() = %v, want both flags off", got)
+	}
+}
+
+func TestNilSet(t *testing.T) {
+	var s *Set
+	for _, f := range Known() {
+		if s.Enabled(context.Background(), f) != Default(f) {
+			t.Errorf("nil Set should report the default for %s", f)
+		}
+	}
+	if _, ok := Lookup("no-such-flag"); ok {
+		t.Error("unknown flag should not be found")
+	}
+}
@@ -0,0 +1,65 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// DefaultSSMParam is the parameter holding the flags when SSM_FEATURE_FLAGS_PARAM is unset.
+const DefaultSSMParam = "/ai-social-media/prod/feature-flags"
+
+// SSMSource stores flags as a JSON object in a single SSM String parameter.
+type SSMSource struct {
+	client *ssm.Client
+	name   string
+}
+
+// NewSSMSource returns a Source backed by the named SSM parameter.
+func NewSSMSource(client *ssm.Client, name string) *SSMSource {
+	return &SSMSource{client: client, name: name}
+}
+
+// Load reads the parameter. A missing parameter means no flag is set.
+func (s *SSMSource) Load(ctx context.Context) (map[string]bool, error) {
+	result, err := s.client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name: aws.String(s.name),
+	})
+	if err != nil {
+		var notFound *ssmtypes.ParameterNotFound
+		if errors.As(err, &notFound) {
+			return map[string]bool{}, nil
+		}
+		return nil, fmt.Errorf("get feature flags %s: %w", s.name, err)
+	}
+	values := map[string]bool{}
+	if result.Parameter != nil && result.Parameter.Value != nil {
+		if err := json.Unmarshal([]byte(*result.Parameter.Value), &values); err != nil {
+			return nil, fmt.Errorf("parse feature flags %s: %w", s.name, err)
+		}
+	}
+	return values, nil
+}
+
+// Save overwrites the parameter with the given values.
+func (s *SSMSource) Save(ctx context.Context, values map[string]bool) error {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("marshal feature flags: %w", err)
+	}
+	_, err = s.client.PutParameter(ctx, &ssm.PutParameterInput{
+		Name:      aws.String(s.name),
+		Value:     aws.String(string(data)),
+		Type:      ssmtypes.ParameterTypeString,
+		Overwrite: aws.Bool(true),
+	})
+	if err != nil {
+		return fmt.Errorf("put feature flags %s: %w", s.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// Package geo reverse-geocodes GPS coordinates into place names (DDR-137).
+//
+// Captions and location tags read better with "Shibuya, Tokyo, Japan" than
+// with raw coordinates, and Gemini guesses places from coordinates poorly.
+// A Resolver looks coordinates up through a Geocoder and keeps the answers in
+// a Cache (DynamoDB in Lambda) so repeat visits to the same spot, across
+// photos and sessions, cost no further lookups.
+package geo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Place is a reverse-geocoded location. Any field may be empty.
+type Place struct {
+	// Name is the point of interest or neighbourhood, e.g. "Central Park".
+	Name     string `json:"name,omitempty" dynamodbav:"name,omitempty"`
+	Locality string `json:"locality,omitempty" dynamodbav:"locality,omitempty"` // city, town or village
+	Region   string `json:"region,omitempty" dynamodbav:"region,omitempty"`     // state or province
+	Country  string `json:"country,omitempty" dynamodbav:"country,omitempty"`
+}
+
+// Label formats the place for a caption or location tag, most specific part
+// first: "Central Park, New York, United States". The region is used only
+// when there is no locality. Returns "" for an empty place.
+func (p Place) Label() string {
+	parts := make([]string, 0, 3)
+	add := func(s string) {
+		if s == "" {
+			return
+		}
+		for _, seen := range parts {
+			if strings.EqualFold(seen, s) {
+				return
+			}
+		}
+		parts = append(parts, s)
+	}
+	add(p.Name)
+	if p.Locality != "" {
+		add(p.Locality)
+	} else {
+		add(p.Region)
+	}
+	add(p.Country)
+	return strings.Join(parts, ", ")
+}
+
+// Geocoder looks up the place at a coordinate. It returns nil, nil when
+// nothing is there (open sea, for example).
+type Geocoder interface {
+	Reverse(ctx context.Context, lat, lon float64) (*Place, error)
+}
+
+// Cache stores places by CacheKey. Get reports found=false for keys never
+// stored; a stored empty Place records a coordinate with nothing there.
+type Cache interface {
+	GetPlace(ctx context.Context, key string) (place *Place, found bool, err error)
+	PutPlace(ctx context.Context, key string, place *Place) error
+}
+
+// CacheKey rounds a coordinate to three decimals (about 110 m) so photos
+// taken around the same spot share one lookup.
+func CacheKey(lat, lon float64) string {
+	return fmt.Sprintf("%.3f,%.3f", lat, lon)
+}
+
+// Resolver reverse-geocodes through a Geocoder with a Cache in front of it.
+// It also remembers answers for its own lifetime, so one job asking for the
+// same spot repeatedly reads the cache once. Safe for concurrent use.
+type Resolver struct {
+	geocoder Geocoder
+	cache    Cache
+
+	mu   sync.Mutex
+	seen map[string]*Place
+}
+
+// NewResolver returns a Resolver. cache may be nil to disable caching.
+func NewResolver(geocoder Geocoder, cache Cache) *Resolver {
+	return &Resolver{geocoder: geocoder, cache: cache, seen: make(map[string]*Place)}
+}
+
+// Resolve returns the place at a coordinate, or nil when nothing is there.
+// Cache failures are logged and otherwise ignored; geocoder failures are
+// returned and not cached.
+func (r *Resolver) Resolve(ctx context.Context, lat, lon float64) (*Place, error) {
+	key := CacheKey(lat, lon)
+
+	r.mu.Lock()
+	place, ok := r.seen[key]
+	r.mu.Unlock()
+	if ok {
+		return place, nil
+	}
+
+	if r.cache != nil {
+		cached, found, err := r.cache.GetPlace(ctx, key)
+		if err != nil {
+			log.Warn().Err(err).Str("key", key).Msg("Geocode cache read failed")
+		} else if found {
+			log.Debug().Str("key", key).Msg("Geocode cache hit")
+			return r.remember(key, cached), nil
+		}
+	}
+
+	place, err := r.geocoder.Reverse(ctx, lat, lon)
+	if err != nil {
+		return nil, fmt.Errorf("reverse geocode %s: %w", key, err)
+	}
+	if r.cache != nil {
+		stored := place
+		if stored == nil {
+			stored = &Place{}
+		}
+		if err := r.cache.PutPlace(ctx, key, stored); err != nil {
+			log.Warn().Err(err).Str("key", key).Msg("Geocode cache write failed")
+		}
+	}
+	log.Debug().Str("key", key).Str("place", labelOf(place)).Msg("Coordinate reverse-geocoded")
+	return r.remember(key, place), nil
+}
+
+// remember records a lookup result, mapping empty places to nil.
+func (r *Resolver) remember(key string, place *Place) *Place {
+	if place != nil && *place == (Place{}) {
+		place = nil
+	}
+	r.mu.Lock()
+	r.seen[key] = place
+	r.mu.Unlock()
+	return place
+}
+
+func labelOf(p *Place) string {
+	if p == nil {
+		return ""
+	}
+	return p.Label()
+}
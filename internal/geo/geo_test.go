@@ -0,0 +1,131 @@
+package geo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPlaceLabel(t *testing.T) {
+	tests := []struct {
+		place Place
+		want  string
+	}{
+		{Place{Name: "Central Park", Locality: "New York", Region: "New York", Country: "United States"}, "Central Park, New York, United States"},
+		{Place{Name: "Tokyo", Locality: "Tokyo", Country: "Japan"}, "Tokyo, Japan"},
+		{Place{Region: "Hokkaido", Country: "Japan"}, "Hokkaido, Japan"},
+		{Place{}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.place.Label(); got != tt.want {
+			t.Errorf("Label(%+v) = %q, want %q", tt.place, got, tt.want)
+		}
+	}
+}
+
+type fakeGeocoder struct {
+	calls int
+	place *Place
+	err   error
+}
+
+func (f *fakeGeocoder) Reverse(ctx context.Context, lat, lon float64) (*Place, error) {
+	f.calls++
+	return f.place, f.err
+}
+
+type mapCache map[string]*Place
+
+func (c mapCache) GetPlace(ctx context.Context, key string) (*Place, bool, error) {
+	p, ok := c[key]
+	return p, ok, nil
+}
+
+func (c mapCache) PutPlace(ctx context.Context, key string, p *Place) error {
+	c[key] = p
+	return nil
+}
+
+func TestResolverCaches(t *testing.T) {
+	ctx := context.Background()
+	g := &fakeGeocoder{place: &Place{Name: "Shibuya Crossing", Locality: "Tokyo", Country: "Japan"}}
+	cache := mapCache{}
+
+	p, err := NewResolver(g, cache).Resolve(ctx, 35.65950, 139.70050)
+	if err != nil || p == nil || p.Name != "Shibuya Crossing" {
+		t.Fatalf("Resolve = %+v, %v", p, err)
+	}
+	if _, ok := cache["35.660,139.701"]; !ok {
+		t.Errorf("place not cached under the rounded key: %v", cache)
+	}
+
+	// A new resolver (another Lambda invocation) reads the shared cache.
+	if _, err := NewResolver(g, cache).Resolve(ctx, 35.65951, 139.70060); err != nil || g.calls != 1 {
+		t.Errorf("geocoder called %d times, want 1 (err %v)", g.calls, err)
+	}
+}
+
+func TestResolverEmptyPlace(t *testing.T) {
+	ctx := context.Background()
+	g := &fakeGeocoder{}
+	cache := mapCache{}
+	r := NewResolver(g, cache)
+
+	for i := 0; i < 2; i++ {
+		if p, err := r.Resolve(ctx, 0.5, -30); err != nil || p != nil {
+			t.Fatalf("Resolve = %+v, %v, want nil", p, err)
+		}
+	}
+	if g.calls != 1 || cache["0.500,-30.000"] == nil {
+		t.Errorf("empty result not remembered: %d calls, cache %v", g.calls, cache)
+	}
+}
+
+func TestResolverDoesNotCacheErrors(t *testing.T) {
+	g := &fakeGeocoder{err: errors.New("rate limited")}
+	cache := mapCache{}
+	if _, err := NewResolver(g, cache).Resolve(context.Background(), 1, 1); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(cache) != 0 {
+		t.Errorf("error was cached: %v", cache)
+	}
+}
+
+func TestNominatimReverse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/reverse" || r.URL.Query().Get("lat") != "40.782900" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		if r.Header.Get("User-Agent") != "test-agent" {
+			t.Errorf("User-Agent = %q", r.Header.Get("User-Agent"))
+		}
+		if r.URL.Query().Get("lat") == "40.782900" {
+			w.Write([]byte(`{"name":"Central Park","address":{"park":"Central Park","city":"New York","state":"New York","country":"United States"}}`))
+		}
+	}))
+	defer server.Close()
+
+	p, err := NewNominatim(server.URL, "test-agent").Reverse(context.Background(), 40.7829, -73.9654)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Place{Name: "Central Park", Locality: "New York", Region: "New York", Country: "United States"}
+	if p == nil || *p != want {
+		t.Errorf("Reverse = %+v, want %+v", p, want)
+	}
+}
+
+func TestNominatimNothingThere(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":"Unable to geocode"}`))
+	}))
+	defer server.Close()
+
+	p, err := NewNominatim(server.URL, "test-agent").Reverse(context.Background(), 0.5, -30)
+	if err != nil || p != nil {
+		t.Errorf("Reverse = %+v, %v, want nil, nil", p, err)
+	}
+}
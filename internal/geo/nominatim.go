@@ -0,0 +1,122 @@
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultNominatimURL is the public OpenStreetMap Nominatim service. Its
+// usage policy allows one request per second and requires an identifying
+// User-Agent; both are enforced by Nominatim.
+const DefaultNominatimURL = "https://nominatim.openstreetmap.org"
+
+// nominatimInterval is the minimum gap between requests to one Nominatim.
+const nominatimInterval = time.Second
+
+// Nominatim is a Geocoder backed by an OpenStreetMap Nominatim server.
+type Nominatim struct {
+	baseURL   string
+	userAgent string
+	client    *http.Client
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// NewNominatim returns a Nominatim geocoder. baseURL "" means
+// DefaultNominatimURL; userAgent identifies the application to the server.
+func NewNominatim(baseURL, userAgent string) *Nominatim {
+	if baseURL == "" {
+		baseURL = DefaultNominatimURL
+	}
+	return &Nominatim{
+		baseURL:   baseURL,
+		userAgent: userAgent,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// nominatimResponse is the subset of a jsonv2 reverse response we read.
+type nominatimResponse struct {
+	Name    string            `json:"name"`
+	Address map[string]string `json:"address"`
+	Error   string            `json:"error"`
+}
+
+// Reverse implements Geocoder.
+func (n *Nominatim) Reverse(ctx context.Context, lat, lon float64) (*Place, error) {
+	if err := n.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	q := url.Values{
+		"format":          {"jsonv2"},
+		"lat":             {strconv.FormatFloat(lat, 'f', 6, 64)},
+		"lon":             {strconv.FormatFloat(lon, 'f', 6, 64)},
+		"zoom":            {"16"}, // street level: names parks and landmarks, not buildings
+		"addressdetails":  {"1"},
+		"accept-language": {"en"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, n.baseURL+"/reverse?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", n.userAgent)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("nominatim request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("nominatim returned HTTP %d", resp.StatusCode)
+	}
+
+	var body nominatimResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode nominatim response: %w", err)
+	}
+	if body.Error != "" {
+		// "Unable to geocode": nothing mapped at this point.
+		return nil, nil
+	}
+	return &Place{
+		Name:     body.Name,
+		Locality: firstOf(body.Address, "city", "town", "village", "municipality", "suburb"),
+		Region:   firstOf(body.Address, "state", "province", "region", "county"),
+		Country:  body.Address["country"],
+	}, nil
+}
+
+// wait blocks until nominatimInterval has passed since the last request.
+func (n *Nominatim) wait(ctx context.Context) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if d := nominatimInterval - time.Since(n.last); d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	n.last = time.Now()
+	return nil
+}
+
+// firstOf returns the first non-empty value of keys in m.
+func firstOf(m map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if v := m[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,142 @@
+// Package hashtags applies a user's hashtag strategy (DDR-157) to the
+// hashtags of a generated caption: banned tags are dropped, the user's own
+// brand tags are always kept, duplicates are removed regardless of case,
+// and the total is capped.
+package hashtags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+const (
+	// PlatformMax is the most hashtags Instagram accepts on a post, and the
+	// cap when a strategy sets none.
+	PlatformMax = 30
+	// MaxBanned caps the banned list.
+	MaxBanned = 200
+)
+
+// Normalize trims and validates the user-editable fields of a strategy.
+// Tags are stored without the leading '#' and deduplicated regardless of
+// case. UpdatedAt is copied as is.
+func Normalize(h store.HashtagSettings) (*store.HashtagSettings, error) {
+	out := &store.HashtagSettings{MaxCount: h.MaxCount, UpdatedAt: h.UpdatedAt}
+	var err error
+	if out.Banned, err = normalizeTags(h.Banned); err != nil {
+		return nil, err
+	}
+	if out.AlwaysInclude, err = normalizeTags(h.AlwaysInclude); err != nil {
+		return nil, err
+	}
+
+	banned := keySet(out.Banned)
+	for _, tag := range out.AlwaysInclude {
+		if banned[key(tag)] {
+			return nil, fmt.Errorf("hashtag %q is both banned and always included", tag)
+		}
+	}
+	switch {
+	case out.MaxCount < 0 || out.MaxCount > PlatformMax:
+		return nil, fmt.Errorf("maxCount must be between 1 and %d, or 0 for the platform limit", PlatformMax)
+	case len(out.Banned) > MaxBanned:
+		return nil, fmt.Errorf("at most %d banned hashtags are allowed", MaxBanned)
+	case len(out.AlwaysInclude) > limit(out):
+		return nil, fmt.Errorf("at most %d hashtags can always be included", limit(out))
+	}
+	return out, nil
+}
+
+// Apply returns the hashtags of a caption under strategy s, which may be
+// nil. Tags lose any leading '#'; empty, banned and repeated tags are
+// dropped, keeping the first spelling of a repeat. The always-included tags
+// keep their place, in the user's spelling, when the caption has them and
+// are appended otherwise; the caption's other tags fill the remaining room
+// in order.
+func Apply(tags []string, s *store.HashtagSettings) []string {
+	max := PlatformMax
+	var banned map[string]bool
+	var alwaysInclude []string
+	if s != nil {
+		max = limit(s)
+		banned = keySet(s.Banned)
+		alwaysInclude = s.AlwaysInclude
+	}
+	always := make(map[string]string, len(alwaysInclude))
+	for _, tag := range alwaysInclude {
+		always[key(tag)] = tag
+	}
+
+	room := max - len(alwaysInclude)
+	seen := map[string]bool{}
+	var out []string
+	for _, tag := range tags {
+		tag = clean(tag)
+		k := key(tag)
+		if tag == "" || seen[k] || banned[k] || strings.ContainsAny(tag, " \t\n") {
+			continue
+		}
+		if own, ok := always[k]; ok {
+			tag = own
+		} else if room > 0 {
+			room--
+		} else {
+			continue
+		}
+		seen[k] = true
+		out = append(out, tag)
+	}
+	for _, tag := range alwaysInclude {
+		if !seen[key(tag)] {
+			seen[key(tag)] = true
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+// limit returns the hashtag cap of a strategy.
+func limit(s *store.HashtagSettings) int {
+	if s.MaxCount <= 0 || s.MaxCount > PlatformMax {
+		return PlatformMax
+	}
+	return s.MaxCount
+}
+
+// normalizeTags cleans and deduplicates user-entered tags, rejecting tags
+// that cannot be a single hashtag.
+func normalizeTags(tags []string) ([]string, error) {
+	var out []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = clean(tag)
+		if tag == "" || seen[key(tag)] {
+			continue
+		}
+		if strings.ContainsAny(tag, " \t\n#") {
+			return nil, fmt.Errorf("invalid hashtag %q", tag)
+		}
+		seen[key(tag)] = true
+		out = append(out, tag)
+	}
+	return out, nil
+}
+
+func clean(tag string) string {
+	return strings.TrimPrefix(strings.TrimSpace(tag), "#")
+}
+
+// key compares tags regardless of case.
+func key(tag string) string {
+	return strings.ToLower(tag)
+}
+
+func keySet(tags []string) map[string]bool {
+	set := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		set[key(tag)] = true
+	}
+	return set
+}
@@ -0,0 +1,74 @@
+package hashtags
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+func TestApply(t *testing.T) {
+	s := &store.HashtagSettings{
+		MaxCount:      4,
+		Banned:        []string{"like4like", "FollowForFollow"},
+		AlwaysInclude: []string{"JaneTravels"},
+	}
+	tests := []struct {
+		name string
+		tags []string
+		s    *store.HashtagSettings
+		want string
+	}{
+		{"no strategy dedupes", []string{"#Kyoto", "kyoto", " temples ", ""}, nil, "Kyoto,temples"},
+		{"banned dropped regardless of case", []string{"kyoto", "#LIKE4LIKE", "followforfollow", "food"}, s, "kyoto,food,JaneTravels"},
+		{"brand tag appended", []string{"kyoto", "food"}, s, "kyoto,food,JaneTravels"},
+		{"brand tag keeps its place and spelling", []string{"janetravels", "kyoto"}, s, "JaneTravels,kyoto"},
+		{"cap leaves room for brand tags", []string{"a", "b", "c", "d", "e"}, s, "a,b,c,JaneTravels"},
+		{"tags with spaces dropped", []string{"two words", "kyoto"}, s, "kyoto,JaneTravels"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(Apply(tt.tags, tt.s), ","); got != tt.want {
+			t.Errorf("%s: Apply = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestApplyPlatformLimit(t *testing.T) {
+	var tags []string
+	for i := range 40 {
+		tags = append(tags, fmt.Sprintf("tag%d", i))
+	}
+	if got := Apply(tags, nil); len(got) != PlatformMax {
+		t.Errorf("Apply kept %d tags, want %d", len(got), PlatformMax)
+	}
+	if got := Apply(tags, &store.HashtagSettings{AlwaysInclude: []string{"mine"}}); len(got) != PlatformMax || got[len(got)-1] != "mine" {
+		t.Errorf("Apply with a brand tag = %d tags ending %q", len(got), got[len(got)-1])
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	h, err := Normalize(store.HashtagSettings{
+		MaxCount:      10,
+		Banned:        []string{"#Spam", "spam", " ", "follow4follow"},
+		AlwaysInclude: []string{" #JaneTravels "},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(h.Banned, ",") != "Spam,follow4follow" || strings.Join(h.AlwaysInclude, ",") != "JaneTravels" {
+		t.Errorf("Normalize = %+v", h)
+	}
+
+	for _, bad := range []store.HashtagSettings{
+		{MaxCount: -1},
+		{MaxCount: PlatformMax + 1},
+		{Banned: []string{"two words"}},
+		{Banned: []string{"travel"}, AlwaysInclude: []string{"Travel"}},
+		{MaxCount: 1, AlwaysInclude: []string{"a", "b"}},
+	} {
+		if _, err := Normalize(bad); err == nil {
+			t.Errorf("Normalize(%+v) succeeded, want an error", bad)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package httputil
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// Limiter bounds concurrent expensive request work (S3 download + image decode)
+// with a weighted budget (DDR-090). Handlers reserve units proportional to the
+// memory they are about to use; when the budget is exhausted the request is
+// rejected with 503 + Retry-After instead of queueing, so a burst of thumbnail
+// misses degrades into client retries rather than an out-of-memory crash.
+type Limiter struct {
+	sem        *semaphore.Weighted
+	capacity   int64
+	retryAfter time.Duration
+}
+
+// NewLimiter creates a Limiter with the given total weight budget.
+// retryAfter is advertised to rejected clients via the Retry-After header.
+func NewLimiter(capacity int64, retryAfter time.Duration) *Limiter {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Limiter{
+		sem:        semaphore.NewWeighted(capacity),
+		capacity:   capacity,
+		retryAfter: retryAfter,
+	}
+}
+
+// TryAcquire reserves weight units without blocking. Returns false when the
+// budget is saturated. Weights above the capacity are clamped so a single
+// oversized request can still run when the limiter is otherwise idle.
+func (l *Limiter) TryAcquire(weight int64) bool {
+	return l.sem.TryAcquire(l.clamp(weight))
+}
+
+// Release returns weight units previously reserved with TryAcquire.
+func (l *Limiter) Release(weight int64) {
+	l.sem.Release(l.clamp(weight))
+}
+
+// Reject writes a 503 JSON error with a Retry-After header.
+func (l *Limiter) Reject(w http.ResponseWriter) {
+	secs := int(l.retryAfter.Round(time.Second) / time.Second)
+	if secs < 1 {
+		secs = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(secs))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(map[string]string{"error": "server busy, retry shortly"})
+}
+
+// Limit wraps a handler so every request reserves a fixed weight for its
+// duration. Saturated requests are rejected via Reject.
+func (l *Limiter) Limit(weight int64, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !l.TryAcquire(weight) {
+			l.Reject(w)
+			return
+		}
+		defer l.Release(weight)
+		next(w, r)
+	}
+}
+
+func (l *Limiter) clamp(weight int64) int64 {
+	if weight > l.capacity {
+		return l.capacity
+	}
+	if weight < 1 {
+		return 1
+	}
+	return weight
+}
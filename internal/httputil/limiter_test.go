@@ -0,0 +1,130 @@
+package httputil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLimiterTryAcquireAndRelease(t *testing.T) {
+	l := NewLimiter(4, time.Second)
+
+	if !l.TryAcquire(3) {
+		t.Fatal("TryAcquire(3) on an idle budget of 4 = false, want true")
+	}
+	if l.TryAcquire(2) {
+		t.Fatal("TryAcquire(2) with 1 unit free = true, want false")
+	}
+	if !l.TryAcquire(1) {
+		t.Fatal("TryAcquire(1) with 1 unit free = false, want true")
+	}
+	l.Release(3)
+	if !l.TryAcquire(2) {
+		t.Error("TryAcquire(2) after Release(3) = false, want true")
+	}
+}
+
+func TestLimiterClampsWeight(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int64
+		weight   int64
+		want     int64
+	}{
+		{"within budget", 8, 4, 4},
+		{"oversized clamps to capacity", 8, 20, 8},
+		{"zero counts as one", 8, 0, 1},
+		{"negative counts as one", 8, -3, 1},
+		{"capacity below one is raised", 0, 5, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLimiter(tt.capacity, time.Second)
+			if got := l.clamp(tt.weight); got != tt.want {
+				t.Errorf("clamp(%d) = %d, want %d", tt.weight, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimiterOversizedRequestRunsWhenIdle(t *testing.T) {
+	l := NewLimiter(2, time.Second)
+	if !l.TryAcquire(10) {
+		t.Fatal("TryAcquire(10) on an idle budget of 2 = false, want true")
+	}
+	if l.TryAcquire(1) {
+		t.Error("TryAcquire(1) while an oversized request holds the budget = true, want false")
+	}
+	l.Release(10)
+	if !l.TryAcquire(2) {
+		t.Error("TryAcquire(2) after releasing the oversized request = false, want true")
+	}
+}
+
+func TestLimiterReject(t *testing.T) {
+	tests := []struct {
+		retryAfter time.Duration
+		want       string
+	}{
+		{3 * time.Second, "3"},
+		{1500 * time.Millisecond, "2"},
+		{100 * time.Millisecond, "1"},
+		{0, "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.retryAfter.String(), func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			NewLimiter(1, tt.retryAfter).Reject(rec)
+
+			if rec.Code != http.StatusServiceUnavailable {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.want {
+				t.Errorf("Retry-After = %q, want %q", got, tt.want)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+			if !strings.Contains(rec.Body.String(), "server busy") {
+				t.Errorf("body = %q, want a server busy error", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestLimiterLimit(t *testing.T) {
+	l := NewLimiter(4, time.Second)
+	entered := make(chan struct{})
+	unblock := make(chan struct{})
+	slow := l.Limit(4, func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-unblock
+	})
+	fast := l.Limit(1, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		slow(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+		close(done)
+	}()
+	<-entered
+
+	rec := httptest.NewRecorder()
+	fast(rec, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status while saturated = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	close(unblock)
+	<-done
+
+	rec = httptest.NewRecorder()
+	fast(rec, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("status after release = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+}
@@ -11,6 +11,9 @@
 //  3. Publish the container
 //  4. For videos: poll container status until processing completes before publishing
 //
+// With INSTAGRAM_MODE=mock, or per job with a publish dry run, NewMockClient
+// simulates the Graph API instead (DDR-139).
+//
 // See DDR-040: Instagram Publishing Client for the full design.
 package instagram
 
@@ -24,6 +27,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fpang/ai-social-media-helper/internal/logging"
 	"github.com/rs/zerolog/log"
 )
 
@@ -49,6 +53,7 @@ type Client struct {
 	accessToken string
 	userID      string
 	baseURL     string
+	mock        bool // DDR-139: answered by mockTransport
 }
 
 // NewClient creates an Instagram API client.
@@ -56,7 +61,8 @@ type Client struct {
 func NewClient(accessToken, userID string) *Client {
 	return &Client{
 		httpClient: &http.Client{
-			Timeout: defaultTimeout,
+			Timeout:   defaultTimeout,
+			Transport: logging.OutboundTransport("instagram", nil),
 		},
 		accessToken: accessToken,
 		userID:      userID,
@@ -92,8 +98,10 @@ type containerStatusResponse struct {
 // CreateImageContainer creates an image media container.
 // imageURL must be a publicly accessible URL (e.g., presigned S3 GET URL).
 // If isCarousel is true, the container is created as a carousel child item.
-func (c *Client) CreateImageContainer(ctx context.Context, imageURL string, isCarousel bool) (string, error) {
-	log.Debug().Bool("isCarousel", isCarousel).Msg("Creating image container")
+// tags, when set, tag accounts on the photo (DDR-141). altText, when set,
+// describes the photo for screen readers (DDR-154).
+func (c *Client) CreateImageContainer(ctx context.Context, imageURL string, isCarousel bool, tags []UserTag, altText string) (string, error) {
+	log.Debug().Bool("isCarousel", isCarousel).Int("userTags", len(tags)).Bool("altText", altText != "").Msg("Creating image container")
 	params := url.Values{
 		"image_url":    {imageURL},
 		"access_token": {c.accessToken},
@@ -101,6 +109,8 @@ func (c *Client) CreateImageContainer(ctx context.Context, imageURL string, isCa
 	if isCarousel {
 		params.Set("is_carousel_item", "true")
 	}
+	setUserTags(params, tags, true)
+	setAltText(params, altText)
 
 	resp, err := c.postForm(ctx, fmt.Sprintf("/%s/media", c.userID), params)
 	if err != nil {
@@ -134,8 +144,9 @@ func (c *Client) CreateVideoContainer(ctx context.Context, videoURL string, isCa
 }
 
 // CreateCarouselContainer creates a carousel container from child container IDs.
-// caption is the full post caption text (including hashtags).
-func (c *Client) CreateCarouselContainer(ctx context.Context, children []string, caption string) (string, error) {
+// caption is the full post caption text (including hashtags). opts adds a
+// location tag and collaborator invites.
+func (c *Client) CreateCarouselContainer(ctx context.Context, children []string, caption string, opts PostOptions) (string, error) {
 	if len(children) < 2 {
 		return "", fmt.Errorf("carousel requires at least 2 items, got %d", len(children))
 	}
@@ -149,6 +160,7 @@ func (c *Client) CreateCarouselContainer(ctx context.Context, children []string,
 		"caption":      {caption},
 		"access_token": {c.accessToken},
 	}
+	setPostOptions(params, opts)
 
 	resp, err := c.postForm(ctx, fmt.Sprintf("/%s/media", c.userID), params)
 	if err != nil {
@@ -157,13 +169,18 @@ func (c *Client) CreateCarouselContainer(ctx context.Context, children []string,
 	return resp.ID, nil
 }
 
-// CreateSingleImagePost creates a single-image post container with caption.
-func (c *Client) CreateSingleImagePost(ctx context.Context, imageURL, caption string) (string, error) {
+// CreateSingleImagePost creates a single-image post container with caption,
+// the accounts tagged on the photo, its alt-text, and the location and
+// collaborators in opts.
+func (c *Client) CreateSingleImagePost(ctx context.Context, imageURL, caption string, tags []UserTag, altText string, opts PostOptions) (string, error) {
 	params := url.Values{
 		"image_url":    {imageURL},
 		"caption":      {caption},
 		"access_token": {c.accessToken},
 	}
+	setUserTags(params, tags, true)
+	setAltText(params, altText)
+	setPostOptions(params, opts)
 
 	resp, err := c.postForm(ctx, fmt.Sprintf("/%s/media", c.userID), params)
 	if err != nil {
@@ -172,14 +189,26 @@ func (c *Client) CreateSingleImagePost(ctx context.Context, imageURL, caption st
 	return resp.ID, nil
 }
 
-// CreateSingleReelPost creates a single reel (video) post container with caption.
-func (c *Client) CreateSingleReelPost(ctx context.Context, videoURL, caption string) (string, error) {
+// setAltText sends a photo's alt-text (DDR-154). Instagram accepts it on
+// images only.
+func setAltText(params url.Values, altText string) {
+	if altText = strings.TrimSpace(altText); altText != "" {
+		params.Set("alt_text", altText)
+	}
+}
+
+// CreateSingleReelPost creates a single reel (video) post container with
+// caption, the accounts tagged in it, and the location and collaborators in
+// opts. Reel tags carry no position.
+func (c *Client) CreateSingleReelPost(ctx context.Context, videoURL, caption string, tags []UserTag, opts PostOptions) (string, error) {
 	params := url.Values{
 		"video_url":    {videoURL},
 		"media_type":   {"REELS"},
 		"caption":      {caption},
 		"access_token": {c.accessToken},
 	}
+	setUserTags(params, tags, false)
+	setPostOptions(params, opts)
 
 	resp, err := c.postForm(ctx, fmt.Sprintf("/%s/media", c.userID), params)
 	if err != nil {
@@ -207,6 +236,26 @@ func (c *Client) Publish(ctx context.Context, containerID string) (string, error
 	return resp.ID, nil
 }
 
+// --- Comments ---
+
+// Comment posts message as a comment on a published post and returns the
+// comment's ID. Publishing uses it to put a post's hashtags in its first
+// comment (DDR-163).
+func (c *Client) Comment(ctx context.Context, mediaID, message string) (string, error) {
+	log.Debug().Str("mediaId", mediaID).Int("length", len(message)).Msg("Commenting on post")
+	params := url.Values{
+		"message":      {message},
+		"access_token": {c.accessToken},
+	}
+
+	resp, err := c.postForm(ctx, fmt.Sprintf("/%s/comments", mediaID), params)
+	if err != nil {
+		return "", fmt.Errorf("comment on %s: %w", mediaID, err)
+	}
+	log.Info().Str("mediaId", mediaID).Str("commentId", resp.ID).Msg("Comment posted")
+	return resp.ID, nil
+}
+
 // --- Status polling ---
 
 // ContainerStatus returns the processing status of a media container.
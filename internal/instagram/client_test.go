@@ -36,13 +36,16 @@ func TestCreateImageContainer(t *testing.T) {
 		if r.Form.Get("is_carousel_item") != "true" {
 			t.Errorf("expected is_carousel_item=true")
 		}
+		if got := r.Form.Get("alt_text"); got != "A tram on a hill" {
+			t.Errorf("alt_text = %q", got)
+		}
 
 		json.NewEncoder(w).Encode(apiResponse{ID: "container-img-001"})
 	}))
 	defer server.Close()
 
 	client := newTestClient(server)
-	id, err := client.CreateImageContainer(context.Background(), "https://example.com/photo.jpg", true)
+	id, err := client.CreateImageContainer(context.Background(), "https://example.com/photo.jpg", true, nil, " A tram on a hill ")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -121,7 +124,7 @@ func TestCreateCarouselContainer(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server)
-	id, err := client.CreateCarouselContainer(context.Background(), []string{"c1", "c2", "c3"}, "Hello world")
+	id, err := client.CreateCarouselContainer(context.Background(), []string{"c1", "c2", "c3"}, "Hello world", PostOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -132,7 +135,7 @@ func TestCreateCarouselContainer(t *testing.T) {
 
 func TestCreateCarouselContainerTooFewItems(t *testing.T) {
 	client := &Client{userID: "12345", accessToken: "tok"}
-	_, err := client.CreateCarouselContainer(context.Background(), []string{"c1"}, "caption")
+	_, err := client.CreateCarouselContainer(context.Background(), []string{"c1"}, "caption", PostOptions{})
 	if err == nil || !strings.Contains(err.Error(), "at least 2") {
 		t.Errorf("expected error about minimum items, got: %v", err)
 	}
@@ -162,6 +165,30 @@ func TestPublish(t *testing.T) {
 	}
 }
 
+func TestComment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/post-001/comments") {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		r.ParseForm()
+		if r.Form.Get("message") != "#travel #kyoto" {
+			t.Errorf("unexpected message: %s", r.Form.Get("message"))
+		}
+
+		json.NewEncoder(w).Encode(apiResponse{ID: "comment-001"})
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+	id, err := client.Comment(context.Background(), "post-001", "#travel #kyoto")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != "comment-001" {
+		t.Errorf("expected comment-001, got %s", id)
+	}
+}
+
 func TestContainerStatus(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -231,7 +258,7 @@ func TestAPIError(t *testing.T) {
 	defer server.Close()
 
 	client := newTestClient(server)
-	_, err := client.CreateImageContainer(context.Background(), "https://example.com/photo.jpg", false)
+	_, err := client.CreateImageContainer(context.Background(), "https://example.com/photo.jpg", false, nil, "")
 	if err == nil {
 		t.Fatal("expected error for invalid token")
 	}
@@ -252,13 +279,19 @@ func TestCreateSingleImagePost(t *testing.T) {
 		if r.Form.Get("is_carousel_item") != "" {
 			t.Errorf("single post should not have is_carousel_item")
 		}
+		if _, ok := r.Form["location_id"]; ok {
+			t.Errorf("untagged post should not have location_id")
+		}
+		if _, ok := r.Form["alt_text"]; ok {
+			t.Errorf("post without alt-text should not send alt_text")
+		}
 
 		json.NewEncoder(w).Encode(apiResponse{ID: "single-001"})
 	}))
 	defer server.Close()
 
 	client := newTestClient(server)
-	id, err := client.CreateSingleImagePost(context.Background(), "https://example.com/photo.jpg", "Great photo!")
+	id, err := client.CreateSingleImagePost(context.Background(), "https://example.com/photo.jpg", "Great photo!", nil, "", PostOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -267,6 +300,100 @@ func TestCreateSingleImagePost(t *testing.T) {
 	}
 }
 
+func TestCreateSingleReelPostWithLocation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("location_id") != "110843418940484" {
+			t.Errorf("unexpected location_id: %q", r.Form.Get("location_id"))
+		}
+		json.NewEncoder(w).Encode(apiResponse{ID: "reel-001"})
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+	if _, err := client.CreateSingleReelPost(context.Background(), "https://example.com/v.mp4", "Reel", nil, PostOptions{LocationID: "110843418940484"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCreateSingleImagePostWithTagsAndCollaborators(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if got := r.Form.Get("user_tags"); got != `[{"username":"jane.doe","x":0.25,"y":0.5}]` {
+			t.Errorf("unexpected user_tags: %s", got)
+		}
+		if got := r.Form.Get("collaborators"); got != `["sam_k","lee"]` {
+			t.Errorf("unexpected collaborators: %s", got)
+		}
+		json.NewEncoder(w).Encode(apiResponse{ID: "single-002"})
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+	tags := []UserTag{{Username: "jane.doe", X: 0.25, Y: 0.5}}
+	if _, err := client.CreateSingleImagePost(context.Background(), "https://example.com/photo.jpg", "Friends", tags, "", PostOptions{Collaborators: []string{"sam_k", "lee"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCreateSingleReelPostTagsHaveNoPosition(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if got := r.Form.Get("user_tags"); got != `[{"username":"jane.doe"}]` {
+			t.Errorf("unexpected user_tags: %s", got)
+		}
+		json.NewEncoder(w).Encode(apiResponse{ID: "reel-002"})
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+	tags := []UserTag{{Username: "jane.doe", X: 0.25, Y: 0.5}}
+	if _, err := client.CreateSingleReelPost(context.Background(), "https://example.com/v.mp4", "Reel", tags, PostOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMediaInsights(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/17895695668004550/insights" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.URL.Query().Get("metric"); got != "reach,views,likes,comments,saved" {
+			t.Errorf("unexpected metric: %s", got)
+		}
+		w.Write([]byte(`{"data":[
+			{"name":"reach","period":"lifetime","values":[{"value":1200}]},
+			{"name":"views","period":"lifetime","values":[{"value":1800}]},
+			{"name":"likes","period":"lifetime","values":[{"value":95}]},
+			{"name":"comments","period":"lifetime","total_value":{"value":7}},
+			{"name":"saved","period":"lifetime","values":[{"value":12}]}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+	got, err := client.MediaInsights(context.Background(), "17895695668004550")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Insights{Reach: 1200, Impressions: 1800, Likes: 95, Comments: 7, Saves: 12}
+	if *got != want {
+		t.Errorf("MediaInsights = %+v, want %+v", *got, want)
+	}
+}
+
+func TestMediaInsightsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":{"message":"(#10) Not enough viewers for the media to show insights","type":"OAuthException","code":10}}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+	if _, err := client.MediaInsights(context.Background(), "1789"); err == nil || !strings.Contains(err.Error(), "Not enough viewers") {
+		t.Errorf("expected API error, got: %v", err)
+	}
+}
+
 func TestTruncate(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -0,0 +1,99 @@
+package instagram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// --- Post insights (DDR-146) ---
+
+// insightMetrics are the media insights MediaInsights requests. Instagram
+// replaced impressions with views in Graph API v22; impressions are no
+// longer reported for new media, so views fill Insights.Impressions.
+var insightMetrics = []string{"reach", "views", "likes", "comments", "saved"}
+
+// Insights are the lifetime engagement counts of a published post.
+type Insights struct {
+	Reach       int64 `json:"reach"`
+	Impressions int64 `json:"impressions"`
+	Likes       int64 `json:"likes"`
+	Comments    int64 `json:"comments"`
+	Saves       int64 `json:"saves"`
+}
+
+// insightsResponse is the response from GET /{media_id}/insights.
+type insightsResponse struct {
+	Data []struct {
+		Name   string `json:"name"`
+		Values []struct {
+			Value int64 `json:"value"`
+		} `json:"values"`
+		TotalValue *struct {
+			Value int64 `json:"value"`
+		} `json:"total_value"`
+	} `json:"data"`
+	Error *apiErr `json:"error,omitempty"`
+}
+
+// MediaInsights returns the lifetime insights of a published post, by the
+// media ID Publish returned. Instagram only reports insights for posts on a
+// business or creator account.
+func (c *Client) MediaInsights(ctx context.Context, mediaID string) (*Insights, error) {
+	params := url.Values{
+		"metric":       {strings.Join(insightMetrics, ",")},
+		"access_token": {c.accessToken},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/%s/insights?%s", c.baseURL, url.PathEscape(mediaID), params.Encode()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+
+	httpResp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("insights request: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	var resp insightsResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("parse response: %w (body: %s)", err, truncate(string(body), 200))
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("API error: %s (code %d)", resp.Error.Message, resp.Error.Code)
+	}
+
+	insights := &Insights{}
+	for _, metric := range resp.Data {
+		var value int64
+		switch {
+		case metric.TotalValue != nil:
+			value = metric.TotalValue.Value
+		case len(metric.Values) > 0:
+			value = metric.Values[0].Value
+		}
+		switch metric.Name {
+		case "reach":
+			insights.Reach = value
+		case "views", "impressions":
+			insights.Impressions = value
+		case "likes":
+			insights.Likes = value
+		case "comments":
+			insights.Comments = value
+		case "saved":
+			insights.Saves = value
+		}
+	}
+	return insights, nil
+}
@@ -0,0 +1,224 @@
+package instagram
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/mockapi"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Mock mode (DDR-139) ---
+//
+// A mock client answers the Graph API calls this package makes without
+// reaching Instagram, so the whole publish flow can be exercised without
+// posting to a real account. Container and post IDs start with "mock-".
+// Video containers report IN_PROGRESS until mockVideoProcessing has passed
+// since their creation; the creation time is encoded in the ID, so any
+// Lambda invocation can answer a status poll.
+
+const (
+	// ModeEnv selects the client mode: "mock" simulates Instagram for the
+	// whole deployment; anything else publishes for real.
+	ModeEnv = "INSTAGRAM_MODE"
+
+	// Simulated Graph API latencies, close to what the live API shows.
+	mockCreateDelay     = 800 * time.Millisecond
+	mockPublishDelay    = 2 * time.Second
+	mockVideoProcessing = 30 * time.Second
+)
+
+// MockEnabled reports whether INSTAGRAM_MODE=mock is set.
+func MockEnabled() bool {
+	return strings.EqualFold(os.Getenv(ModeEnv), "mock")
+}
+
+// NewMockClient returns a Client that simulates container creation, video
+// processing and publishing with realistic timing and fake IDs.
+func NewMockClient() *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Timeout:   defaultTimeout,
+			Transport: logging.OutboundTransport("instagram-mock", newMockTransport()),
+		},
+		accessToken: "mock-token",
+		userID:      "mock-user",
+		baseURL:     defaultBaseURL,
+		mock:        true,
+	}
+}
+
+// IsMock reports whether c simulates Instagram instead of calling it.
+func (c *Client) IsMock() bool {
+	return c.mock
+}
+
+// IsMockID reports whether id was handed out by a mock client.
+func IsMockID(id string) bool {
+	return mockapi.IsID(id)
+}
+
+// mockTransport is an http.RoundTripper that plays the Graph API.
+type mockTransport struct {
+	*mockapi.Clock
+	createDelay     time.Duration
+	publishDelay    time.Duration
+	videoProcessing time.Duration
+}
+
+func newMockTransport() *mockTransport {
+	return &mockTransport{
+		Clock:           mockapi.NewClock(),
+		createDelay:     mockCreateDelay,
+		publishDelay:    mockPublishDelay,
+		videoProcessing: mockVideoProcessing,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (m *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := req.URL.Path
+	switch {
+	case req.Method == http.MethodPost && strings.HasSuffix(path, "/media_publish"):
+		return m.publish(req)
+	case req.Method == http.MethodPost && strings.HasSuffix(path, "/comments"):
+		return m.comment(req)
+	case req.Method == http.MethodPost && strings.HasSuffix(path, "/media"):
+		return m.createContainer(req)
+	case req.Method == http.MethodGet && strings.HasSuffix(path, "/insights"):
+		return m.insights(req)
+	case req.Method == http.MethodGet:
+		return m.containerStatus(req)
+	}
+	return mockError(req, fmt.Sprintf("mock: unsupported request %s %s", req.Method, path))
+}
+
+func (m *mockTransport) createContainer(req *http.Request) (*http.Response, error) {
+	form, err := mockapi.ReadForm(req)
+	if err != nil {
+		return nil, err
+	}
+	kind := "image"
+	switch form.Get("media_type") {
+	case "REELS", "VIDEO":
+		kind = "video"
+	case "CAROUSEL":
+		kind = "carousel"
+		for _, child := range strings.Split(form.Get("children"), ",") {
+			if !IsMockID(child) {
+				return mockError(req, fmt.Sprintf("mock: unknown carousel child %q", child))
+			}
+		}
+	}
+	if err := mockapi.Sleep(req.Context(), m.createDelay); err != nil {
+		return nil, err
+	}
+	id := m.NewID(kind)
+	log.Info().Str("containerId", id).Str("kind", kind).Bool("located", form.Get("location_id") != "").Bool("tagged", form.Get("user_tags") != "").Bool("collaborators", form.Get("collaborators") != "").Msg("Mock Instagram container created")
+	return mockapi.JSON(req, http.StatusOK, apiResponse{ID: id})
+}
+
+func (m *mockTransport) publish(req *http.Request) (*http.Response, error) {
+	form, err := mockapi.ReadForm(req)
+	if err != nil {
+		return nil, err
+	}
+	creationID := form.Get("creation_id")
+	if !IsMockID(creationID) {
+		return mockError(req, fmt.Sprintf("mock: unknown container %q", creationID))
+	}
+	if status := m.status(creationID); status != "FINISHED" {
+		return mockError(req, fmt.Sprintf("mock: container %s is %s", creationID, status))
+	}
+	if err := mockapi.Sleep(req.Context(), m.publishDelay); err != nil {
+		return nil, err
+	}
+	id := m.NewID("post")
+	log.Info().Str("containerId", creationID).Str("postId", id).Msg("Mock Instagram post published")
+	return mockapi.JSON(req, http.StatusOK, apiResponse{ID: id})
+}
+
+// comment answers a comment on a mock post (DDR-163).
+func (m *mockTransport) comment(req *http.Request) (*http.Response, error) {
+	form, err := mockapi.ReadForm(req)
+	if err != nil {
+		return nil, err
+	}
+	id := strings.TrimSuffix(req.URL.Path, "/comments")
+	id = id[strings.LastIndex(id, "/")+1:]
+	if kind, _, ok := mockapi.ParseID(id); !ok || kind != "post" {
+		return mockError(req, fmt.Sprintf("mock: unknown post %q", id))
+	}
+	if form.Get("message") == "" {
+		return mockError(req, "mock: comment message is empty")
+	}
+	commentID := m.NewID("comment")
+	log.Info().Str("postId", id).Str("commentId", commentID).Msg("Mock Instagram comment posted")
+	return mockapi.JSON(req, http.StatusOK, apiResponse{ID: commentID})
+}
+
+func (m *mockTransport) containerStatus(req *http.Request) (*http.Response, error) {
+	id := strings.TrimPrefix(req.URL.Path, "/")
+	if i := strings.LastIndex(id, "/"); i >= 0 {
+		id = id[i+1:]
+	}
+	if !IsMockID(id) {
+		return mockError(req, fmt.Sprintf("mock: unknown container %q", id))
+	}
+	return mockapi.JSON(req, http.StatusOK, containerStatusResponse{ID: id, StatusCode: m.status(id)})
+}
+
+// insights reports engagement for a mock post (DDR-146) that grows over its
+// first days and levels off, scaled by a per-post factor derived from the ID.
+func (m *mockTransport) insights(req *http.Request) (*http.Response, error) {
+	id := strings.TrimSuffix(req.URL.Path, "/insights")
+	id = id[strings.LastIndex(id, "/")+1:]
+	kind, created, ok := mockapi.ParseID(id)
+	if !ok || kind != "post" {
+		return mockError(req, fmt.Sprintf("mock: unknown post %q", id))
+	}
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	scale := int64(h.Sum64()%50) + 50
+	hours := int64(m.Since(created)/time.Hour) + 1
+	reach := scale * 20 * hours / (hours + 12)
+
+	metric := func(name string, value int64) map[string]any {
+		return map[string]any{"name": name, "period": "lifetime", "values": []map[string]any{{"value": value}}}
+	}
+	return mockapi.JSON(req, http.StatusOK, map[string]any{
+		"data": []map[string]any{
+			metric("reach", reach),
+			metric("views", reach*3/2),
+			metric("likes", reach/10),
+			metric("comments", reach/80),
+			metric("saved", reach/40),
+		},
+	})
+}
+
+// status is the processing state of a mock container: videos finish
+// videoProcessing after creation, everything else at once.
+func (m *mockTransport) status(id string) string {
+	kind, created, ok := mockapi.ParseID(id)
+	if !ok {
+		return "ERROR"
+	}
+	if kind == "video" && m.Since(created) < m.videoProcessing {
+		return "IN_PROGRESS"
+	}
+	return "FINISHED"
+}
+
+// mockError answers like a Graph API error, so callers see the same error
+// path as with the live API.
+func mockError(req *http.Request, msg string) (*http.Response, error) {
+	return mockapi.JSON(req, http.StatusOK, map[string]any{
+		"error": apiErr{Message: msg, Type: "MockException", Code: 100},
+	})
+}
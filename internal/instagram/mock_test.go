@@ -0,0 +1,105 @@
+package instagram
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newInstantMock returns a mock client with no simulated latency and a
+// clock the test controls.
+func newInstantMock(now *time.Time) *Client {
+	c := NewMockClient()
+	tr := newMockTransport()
+	tr.createDelay, tr.publishDelay = 0, 0
+	tr.Now = func() time.Time { return *now }
+	c.httpClient = &http.Client{Transport: tr}
+	return c
+}
+
+func TestMockCarouselFlow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_800_000_000, 0)
+	c := newInstantMock(&now)
+
+	img, err := c.CreateImageContainer(ctx, "https://example.com/a.jpg", true, nil, "")
+	if err != nil || !IsMockID(img) {
+		t.Fatalf("CreateImageContainer = %q, %v", img, err)
+	}
+	vid, err := c.CreateVideoContainer(ctx, "https://example.com/b.mp4", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status, _ := c.ContainerStatus(ctx, vid); status != "IN_PROGRESS" {
+		t.Errorf("new video status = %q, want IN_PROGRESS", status)
+	}
+	now = now.Add(mockVideoProcessing)
+	if status, _ := c.ContainerStatus(ctx, vid); status != "FINISHED" {
+		t.Errorf("video status after processing = %q, want FINISHED", status)
+	}
+
+	carousel, err := c.CreateCarouselContainer(ctx, []string{img, vid}, "caption", PostOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	post, err := c.Publish(ctx, carousel)
+	if err != nil || !strings.HasPrefix(post, "mock-post-") {
+		t.Errorf("Publish = %q, %v", post, err)
+	}
+	comment, err := c.Comment(ctx, post, "#travel #kyoto")
+	if err != nil || !strings.HasPrefix(comment, "mock-comment-") {
+		t.Errorf("Comment = %q, %v", comment, err)
+	}
+}
+
+func TestMockPublishBeforeVideoFinished(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_800_000_000, 0)
+	c := newInstantMock(&now)
+
+	reel, err := c.CreateSingleReelPost(ctx, "https://example.com/b.mp4", "caption", nil, PostOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Publish(ctx, reel); err == nil || !strings.Contains(err.Error(), "IN_PROGRESS") {
+		t.Errorf("expected a not-ready error, got %v", err)
+	}
+}
+
+func TestMockRejectsRealIDs(t *testing.T) {
+	now := time.Now()
+	c := newInstantMock(&now)
+	if _, err := c.Publish(context.Background(), "17889455560051444"); err == nil {
+		t.Error("expected an error publishing a non-mock container")
+	}
+	if _, err := c.Comment(context.Background(), "17889455560051444", "#travel"); err == nil {
+		t.Error("expected an error commenting on a non-mock post")
+	}
+}
+
+func TestMockInsightsGrow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_800_000_000, 0)
+	c := newInstantMock(&now)
+
+	container, _ := c.CreateSingleImagePost(ctx, "https://example.com/a.jpg", "caption", nil, "", PostOptions{})
+	post, err := c.Publish(ctx, container)
+	if err != nil {
+		t.Fatal(err)
+	}
+	early, err := c.MediaInsights(ctx, post)
+	if err != nil || early.Reach == 0 {
+		t.Fatalf("MediaInsights = %+v, %v", early, err)
+	}
+	now = now.Add(48 * time.Hour)
+	later, _ := c.MediaInsights(ctx, post)
+	if later.Reach <= early.Reach || later.Likes < early.Likes {
+		t.Errorf("insights did not grow: %+v then %+v", early, later)
+	}
+	if _, err := c.MediaInsights(ctx, container); err == nil {
+		t.Error("expected an error for insights on a container")
+	}
+}
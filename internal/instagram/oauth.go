@@ -5,6 +5,8 @@
 //  2. Short-lived token → long-lived token (60 days) via GET to graph.instagram.com
 //
 // The short-lived token response also includes the Instagram user ID.
+// A long-lived token at least a day old is extended for another 60 days via
+// GET to graph.instagram.com/refresh_access_token (DDR-173).
 // See: https://developers.facebook.com/docs/instagram-platform/instagram-api-with-instagram-login/business-login
 
 package instagram
@@ -20,9 +22,18 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fpang/ai-social-media-helper/internal/logging"
 	"github.com/rs/zerolog/log"
 )
 
+// refreshTokenURL is the long-lived token refresh endpoint. Tests point it
+// at a local server.
+var refreshTokenURL = "https://graph.instagram.com/refresh_access_token"
+
+// oauthHTTPClient is used for the token exchange calls. Like the Graph API
+// client, it logs each call with the caller's request ID (DDR-111).
+var oauthHTTPClient = &http.Client{Transport: logging.OutboundTransport("instagram", nil)}
+
 // ExchangeCodeResult holds the response from exchanging an authorization code
 // for a short-lived access token.
 type ExchangeCodeResult struct {
@@ -89,7 +100,7 @@ func ExchangeCode(ctx context.Context, code, appID, appSecret, redirectURI strin
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := oauthHTTPClient.Do(req)
 	duration := time.Since(startTime)
 	if err != nil {
 		log.Debug().Int("statusCode", 0).Dur("duration", duration).Err(err).Msg("OAuth HTTP response")
@@ -154,7 +165,7 @@ func ExchangeLongLivedToken(ctx context.Context, shortToken, appSecret string) (
 		return nil, fmt.Errorf("build request: %w", err)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := oauthHTTPClient.Do(req)
 	duration := time.Since(startTime)
 	if err != nil {
 		log.Debug().Int("statusCode", 0).Dur("duration", duration).Err(err).Msg("OAuth HTTP response")
@@ -192,3 +203,65 @@ func ExchangeLongLivedToken(ctx context.Context, shortToken, appSecret string) (
 		ExpiresIn:   result.ExpiresIn,
 	}, nil
 }
+
+// RefreshLongLivedToken extends a long-lived Instagram token for another 60
+// days (DDR-173). The token must be at least 24 hours old and not yet
+// expired; the returned token may be the same string with a new expiry.
+//
+// Endpoint: GET https://graph.instagram.com/refresh_access_token
+//
+//	?grant_type=ig_refresh_token
+//	&access_token={long_lived_token}
+func RefreshLongLivedToken(ctx context.Context, token string) (*LongLivedTokenResult, error) {
+	log.Debug().Msg("Refreshing long-lived token")
+	startTime := time.Now()
+	u := refreshTokenURL + "?grant_type=ig_refresh_token&access_token=" + url.QueryEscape(token)
+
+	log.Debug().Str("method", http.MethodGet).Str("endpoint", "/refresh_access_token").Msg("OAuth HTTP request")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+
+	resp, err := oauthHTTPClient.Do(req)
+	duration := time.Since(startTime)
+	if err != nil {
+		log.Debug().Int("statusCode", 0).Dur("duration", duration).Err(err).Msg("OAuth HTTP response")
+		return nil, fmt.Errorf("token refresh request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	log.Debug().Int("statusCode", resp.StatusCode).Dur("duration", duration).Msg("OAuth HTTP response")
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp apiResponse
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error != nil {
+			log.Error().Str("errorMessage", errResp.Error.Message).Int("errorCode", errResp.Error.Code).Msg("OAuth token refresh failed")
+			return nil, fmt.Errorf("token refresh failed: %s (code: %d)", errResp.Error.Message, errResp.Error.Code)
+		}
+		log.Error().Int("statusCode", resp.StatusCode).Msg("OAuth token refresh failed")
+		return nil, fmt.Errorf("token refresh failed (status %d): %s",
+			resp.StatusCode, truncate(string(body), 300))
+	}
+
+	var result longTokenResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+
+	if result.AccessToken == "" {
+		return nil, fmt.Errorf("no access token in response: %s", truncate(string(body), 300))
+	}
+
+	log.Info().Int64("expiresInDays", result.ExpiresIn/86400).Bool("changed", result.AccessToken != token).Msg("Long-lived token refreshed")
+
+	return &LongLivedTokenResult{
+		AccessToken: result.AccessToken,
+		ExpiresIn:   result.ExpiresIn,
+	}, nil
+}
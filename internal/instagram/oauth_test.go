@@ -0,0 +1,47 @@
+package instagram
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRefreshLongLivedToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("grant_type") != "ig_refresh_token" {
+			t.Errorf("grant_type = %q", r.URL.Query().Get("grant_type"))
+		}
+		if r.URL.Query().Get("access_token") != "old-token" {
+			t.Errorf("access_token = %q", r.URL.Query().Get("access_token"))
+		}
+		w.Write([]byte(`{"access_token":"new-token","token_type":"bearer","expires_in":5184000}`))
+	}))
+	defer server.Close()
+	defer func(u string) { refreshTokenURL = u }(refreshTokenURL)
+	refreshTokenURL = server.URL
+
+	got, err := RefreshLongLivedToken(context.Background(), "old-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.AccessToken != "new-token" || got.ExpiresIn != 5184000 {
+		t.Errorf("result = %+v", got)
+	}
+}
+
+func TestRefreshLongLivedTokenError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"message":"Error validating access token","type":"OAuthException","code":190}}`))
+	}))
+	defer server.Close()
+	defer func(u string) { refreshTokenURL = u }(refreshTokenURL)
+	refreshTokenURL = server.URL
+
+	_, err := RefreshLongLivedToken(context.Background(), "expired")
+	if err == nil || !strings.Contains(err.Error(), "code: 190") {
+		t.Errorf("err = %v, want the Graph API error", err)
+	}
+}
@@ -0,0 +1,184 @@
+package instagram
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// --- Media specification (DDR-129) ---
+//
+// Instagram validates media only after a container has been created, and a
+// rejected item fails the whole carousel minutes into the publish. These
+// limits mirror the Graph API content publishing requirements so items can
+// be checked — and, where possible, converted — before any container exists.
+
+const (
+	// MaxImageBytes is the largest JPEG Instagram accepts.
+	MaxImageBytes = 8 * 1024 * 1024
+	// MaxImageWidth is the widest image Instagram stores; wider images are
+	// downscaled by Instagram, so converting beyond it only wastes bytes.
+	MaxImageWidth = 1440
+
+	// Feed aspect ratio limits (width / height) for images and carousel
+	// videos: 4:5 portrait to 1.91:1 landscape.
+	MinFeedAspect = 4.0 / 5.0
+	MaxFeedAspect = 1.91
+
+	// Reel limits.
+	MinReelAspect   = 0.01
+	MaxReelAspect   = 10.0
+	MaxReelWidth    = 1920
+	MaxReelBytes    = 300 * 1024 * 1024
+	MinVideoLength  = 3 * time.Second
+	MaxReelLength   = 15 * time.Minute
+	MaxVideoFPS     = 60
+	MaxAudioRate    = 48000
+	MaxCarouselClip = 60 * time.Second
+	// MaxCarouselVideoBytes is the size limit for a video carousel item.
+	MaxCarouselVideoBytes = 100 * 1024 * 1024
+)
+
+// Problem codes. A Problem is Fixable when re-encoding the item resolves it;
+// duration limits are not, since trimming a user's video is an editorial
+// decision.
+const (
+	ProblemImageFormat = "image_format"
+	ProblemImageSize   = "image_size"
+	ProblemAspectRatio = "aspect_ratio"
+	ProblemVideoCodec  = "video_codec"
+	ProblemAudioCodec  = "audio_codec"
+	ProblemResolution  = "resolution"
+	ProblemFrameRate   = "frame_rate"
+	ProblemVideoSize   = "video_size"
+	ProblemDuration    = "duration"
+)
+
+// Problem is one way an item breaks Instagram's publishing requirements.
+type Problem struct {
+	Code    string
+	Message string
+	Fixable bool
+}
+
+// ImageInfo describes an image to be published. Format is the decoded
+// format name ("jpeg", "png", "heif", ...).
+type ImageInfo struct {
+	Format string
+	Width  int
+	Height int
+	Bytes  int64
+}
+
+// VideoInfo describes a video to be published, as reported by ffprobe.
+type VideoInfo struct {
+	Width      int
+	Height     int
+	Duration   time.Duration
+	FrameRate  float64
+	VideoCodec string
+	AudioCodec string // empty when the video has no audio stream
+	AudioRate  int
+	Bytes      int64
+}
+
+// CheckImage returns the problems that would make Instagram reject the
+// image. Images share the feed aspect ratio limits in and out of carousels.
+func CheckImage(info ImageInfo) []Problem {
+	var problems []Problem
+	if f := strings.ToLower(info.Format); f != "jpeg" && f != "jpg" {
+		problems = append(problems, Problem{ProblemImageFormat, fmt.Sprintf("%s images must be converted to JPEG", info.Format), true})
+	}
+	if info.Bytes > MaxImageBytes {
+		problems = append(problems, Problem{ProblemImageSize, fmt.Sprintf("%.1f MB exceeds the 8 MB image limit", float64(info.Bytes)/(1024*1024)), true})
+	}
+	if p, ok := checkAspect(info.Width, info.Height, MinFeedAspect, MaxFeedAspect); !ok {
+		problems = append(problems, p)
+	}
+	return problems
+}
+
+// CheckVideo returns the problems that would make Instagram reject the
+// video, either as a single reel or as a carousel item.
+func CheckVideo(info VideoInfo, carousel bool) []Problem {
+	var problems []Problem
+
+	if c := strings.ToLower(info.VideoCodec); c != "h264" && c != "hevc" {
+		problems = append(problems, Problem{ProblemVideoCodec, fmt.Sprintf("video codec %s must be H.264 or HEVC", displayCodec(info.VideoCodec)), true})
+	}
+	if info.AudioCodec != "" && (strings.ToLower(info.AudioCodec) != "aac" || info.AudioRate > MaxAudioRate) {
+		problems = append(problems, Problem{ProblemAudioCodec, fmt.Sprintf("audio %s must be AAC at up to 48 kHz", displayCodec(info.AudioCodec)), true})
+	}
+	if info.FrameRate > MaxVideoFPS {
+		problems = append(problems, Problem{ProblemFrameRate, fmt.Sprintf("%.0f FPS exceeds the 60 FPS limit", info.FrameRate), true})
+	}
+	if info.Width > MaxReelWidth {
+		problems = append(problems, Problem{ProblemResolution, fmt.Sprintf("width %dpx exceeds the 1920px limit", info.Width), true})
+	}
+
+	minAspect, maxAspect := MinReelAspect, MaxReelAspect
+	maxBytes, maxLength := int64(MaxReelBytes), MaxReelLength
+	if carousel {
+		minAspect, maxAspect = MinFeedAspect, MaxFeedAspect
+		maxBytes, maxLength = MaxCarouselVideoBytes, MaxCarouselClip
+	}
+	if p, ok := checkAspect(info.Width, info.Height, minAspect, maxAspect); !ok {
+		problems = append(problems, p)
+	}
+	if info.Bytes > maxBytes {
+		problems = append(problems, Problem{ProblemVideoSize, fmt.Sprintf("%d MB exceeds the %d MB video limit", info.Bytes/(1024*1024), maxBytes/(1024*1024)), true})
+	}
+	if info.Duration < MinVideoLength || info.Duration > maxLength {
+		problems = append(problems, Problem{ProblemDuration, fmt.Sprintf("duration %s is outside %s–%s", info.Duration.Round(time.Second), MinVideoLength, maxLength), false})
+	}
+	return problems
+}
+
+// Fixable reports whether every problem can be resolved by conversion.
+func Fixable(problems []Problem) bool {
+	for _, p := range problems {
+		if !p.Fixable {
+			return false
+		}
+	}
+	return true
+}
+
+// Describe joins problem messages into one human-readable sentence.
+func Describe(problems []Problem) string {
+	msgs := make([]string, len(problems))
+	for i, p := range problems {
+		msgs[i] = p.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ClampAspect returns the aspect ratio nearest to width/height within
+// [minAspect, maxAspect].
+func ClampAspect(width, height int, minAspect, maxAspect float64) float64 {
+	if height == 0 {
+		return maxAspect
+	}
+	return min(max(float64(width)/float64(height), minAspect), maxAspect)
+}
+
+func checkAspect(width, height int, minAspect, maxAspect float64) (Problem, bool) {
+	if width <= 0 || height <= 0 {
+		return Problem{ProblemAspectRatio, "dimensions are unknown", false}, false
+	}
+	aspect := float64(width) / float64(height)
+	// Instagram rounds to a few decimals; allow for encoder-rounded sizes
+	// such as 1080x1352.
+	const tolerance = 0.005
+	if aspect < minAspect-tolerance || aspect > maxAspect+tolerance {
+		return Problem{ProblemAspectRatio, fmt.Sprintf("aspect ratio %.2f:1 is outside %.2f:1–%.2f:1", aspect, minAspect, maxAspect), true}, false
+	}
+	return Problem{}, true
+}
+
+func displayCodec(codec string) string {
+	if codec == "" {
+		return "unknown"
+	}
+	return codec
+}
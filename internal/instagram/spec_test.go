@@ -0,0 +1,105 @@
+package instagram
+
+import (
+	"testing"
+	"time"
+)
+
+func problemCodes(problems []Problem) map[string]bool {
+	codes := make(map[string]bool, len(problems))
+	for _, p := range problems {
+		codes[p.Code] = true
+	}
+	return codes
+}
+
+func TestCheckImage(t *testing.T) {
+	tests := []struct {
+		name string
+		info ImageInfo
+		want []string
+	}{
+		{"valid square JPEG", ImageInfo{"jpeg", 1080, 1080, 2 << 20}, nil},
+		{"4:5 portrait is allowed", ImageInfo{"jpeg", 1080, 1350, 2 << 20}, nil},
+		{"PNG must be converted", ImageInfo{"png", 1080, 1080, 2 << 20}, []string{ProblemImageFormat}},
+		{"over 8 MB", ImageInfo{"jpeg", 4000, 3000, 12 << 20}, []string{ProblemImageSize}},
+		{"9:16 portrait", ImageInfo{"jpeg", 1080, 1920, 1 << 20}, []string{ProblemAspectRatio}},
+		{"panorama", ImageInfo{"heif", 8000, 2000, 9 << 20}, []string{ProblemImageFormat, ProblemImageSize, ProblemAspectRatio}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := CheckImage(tt.info)
+			codes := problemCodes(problems)
+			if len(codes) != len(tt.want) {
+				t.Fatalf("problems = %v, want codes %v", problems, tt.want)
+			}
+			for _, c := range tt.want {
+				if !codes[c] {
+					t.Errorf("missing %s in %v", c, problems)
+				}
+			}
+			if !Fixable(problems) {
+				t.Errorf("image problems should all be fixable: %v", problems)
+			}
+		})
+	}
+}
+
+func TestCheckVideo(t *testing.T) {
+	reel := VideoInfo{
+		Width: 1080, Height: 1920, Duration: 30 * time.Second, FrameRate: 30,
+		VideoCodec: "h264", AudioCodec: "aac", AudioRate: 44100, Bytes: 50 << 20,
+	}
+	if p := CheckVideo(reel, false); len(p) != 0 {
+		t.Errorf("valid reel reported %v", p)
+	}
+
+	// 9:16 is fine for a reel but outside the carousel range.
+	if codes := problemCodes(CheckVideo(reel, true)); !codes[ProblemAspectRatio] || len(codes) != 1 {
+		t.Errorf("carousel 9:16 = %v, want only aspect_ratio", codes)
+	}
+
+	hevcNoAudio := reel
+	hevcNoAudio.VideoCodec, hevcNoAudio.AudioCodec = "hevc", ""
+	if p := CheckVideo(hevcNoAudio, false); len(p) != 0 {
+		t.Errorf("HEVC without audio reported %v", p)
+	}
+
+	prores := reel
+	prores.VideoCodec, prores.AudioCodec, prores.FrameRate, prores.Width, prores.Height = "prores", "pcm_s16le", 120, 3840, 2160
+	codes := problemCodes(CheckVideo(prores, false))
+	for _, c := range []string{ProblemVideoCodec, ProblemAudioCodec, ProblemFrameRate, ProblemResolution} {
+		if !codes[c] {
+			t.Errorf("ProRes missing %s: %v", c, codes)
+		}
+	}
+
+	long := reel
+	long.Duration = 2 * time.Minute
+	p := CheckVideo(long, true)
+	if codes := problemCodes(p); !codes[ProblemDuration] {
+		t.Errorf("2 min carousel clip = %v, want duration", codes)
+	}
+	if Fixable(p) {
+		t.Error("duration problems must not be fixable")
+	}
+	if p := CheckVideo(long, false); len(p) != 0 {
+		t.Errorf("2 min reel reported %v", p)
+	}
+}
+
+func TestClampAspect(t *testing.T) {
+	tests := []struct {
+		w, h int
+		want float64
+	}{
+		{1080, 1920, MinFeedAspect},
+		{4000, 1000, MaxFeedAspect},
+		{1080, 1080, 1},
+	}
+	for _, tt := range tests {
+		if got := ClampAspect(tt.w, tt.h, MinFeedAspect, MaxFeedAspect); got != tt.want {
+			t.Errorf("ClampAspect(%d, %d) = %v, want %v", tt.w, tt.h, got, tt.want)
+		}
+	}
+}
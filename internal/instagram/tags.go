@@ -0,0 +1,123 @@
+package instagram
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// --- User tags and collaborators (DDR-141) ---
+
+const (
+	// MaxUserTags is the most accounts Instagram lets one photo tag.
+	MaxUserTags = 20
+	// MaxCollaborators is the most accounts one post can invite as
+	// collaborators.
+	MaxCollaborators = 3
+)
+
+// usernamePattern matches an Instagram username: up to 30 letters, digits,
+// periods and underscores.
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9._]{1,30}$`)
+
+// UserTag tags an account on a photo. X and Y place the tag as fractions of
+// the image width and height from the top-left corner; reels ignore them.
+type UserTag struct {
+	Username string  `json:"username"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+}
+
+// PostOptions are the post-level settings of a single post or a carousel.
+type PostOptions struct {
+	// LocationID tags the post with a location from SearchLocations (DDR-138).
+	LocationID string
+	// Collaborators are usernames invited to co-author the post.
+	Collaborators []string
+}
+
+// NormalizeUsername trims whitespace and a leading "@" from a username.
+func NormalizeUsername(username string) string {
+	return strings.TrimPrefix(strings.TrimSpace(username), "@")
+}
+
+// ValidateUserTags checks the tags of one photo against Instagram's limits.
+// Usernames must already be normalized.
+func ValidateUserTags(tags []UserTag) error {
+	if len(tags) > MaxUserTags {
+		return fmt.Errorf("at most %d people can be tagged in one photo, got %d", MaxUserTags, len(tags))
+	}
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		if err := validateUsername(t.Username); err != nil {
+			return err
+		}
+		if t.X < 0 || t.X > 1 || t.Y < 0 || t.Y > 1 {
+			return fmt.Errorf("tag position for @%s must be between 0 and 1", t.Username)
+		}
+		if seen[strings.ToLower(t.Username)] {
+			return fmt.Errorf("@%s is tagged more than once", t.Username)
+		}
+		seen[strings.ToLower(t.Username)] = true
+	}
+	return nil
+}
+
+// ValidateCollaborators checks a post's collaborator invites. Usernames must
+// already be normalized.
+func ValidateCollaborators(usernames []string) error {
+	if len(usernames) > MaxCollaborators {
+		return fmt.Errorf("at most %d collaborators can be invited, got %d", MaxCollaborators, len(usernames))
+	}
+	seen := make(map[string]bool, len(usernames))
+	for _, u := range usernames {
+		if err := validateUsername(u); err != nil {
+			return err
+		}
+		if seen[strings.ToLower(u)] {
+			return fmt.Errorf("@%s is invited more than once", u)
+		}
+		seen[strings.ToLower(u)] = true
+	}
+	return nil
+}
+
+func validateUsername(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return fmt.Errorf("invalid Instagram username %q", username)
+	}
+	return nil
+}
+
+// setUserTags adds the user_tags parameter when tags are set. Reels take
+// usernames only, so positioned is false for them.
+func setUserTags(params url.Values, tags []UserTag, positioned bool) {
+	if len(tags) == 0 {
+		return
+	}
+	var data []byte
+	if positioned {
+		data, _ = json.Marshal(tags)
+	} else {
+		names := make([]map[string]string, len(tags))
+		for i, t := range tags {
+			names[i] = map[string]string{"username": t.Username}
+		}
+		data, _ = json.Marshal(names)
+	}
+	params.Set("user_tags", string(data))
+}
+
+// setPostOptions adds the location_id and collaborators parameters that
+// are set in opts.
+func setPostOptions(params url.Values, opts PostOptions) {
+	if opts.LocationID != "" {
+		params.Set("location_id", opts.LocationID)
+	}
+	if len(opts.Collaborators) > 0 {
+		data, _ := json.Marshal(opts.Collaborators)
+		params.Set("collaborators", string(data))
+	}
+}
@@ -0,0 +1,52 @@
+package instagram
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidateUserTags(t *testing.T) {
+	tooMany := make([]UserTag, MaxUserTags+1)
+	for i := range tooMany {
+		tooMany[i] = UserTag{Username: fmt.Sprintf("user%d", i), X: 0.5, Y: 0.5}
+	}
+	tests := []struct {
+		name    string
+		tags    []UserTag
+		wantErr string
+	}{
+		{"none", nil, ""},
+		{"valid", []UserTag{{Username: "jane.doe", X: 0, Y: 1}, {Username: "sam_k", X: 0.3, Y: 0.7}}, ""},
+		{"bad username", []UserTag{{Username: "jane doe", X: 0.5, Y: 0.5}}, "invalid Instagram username"},
+		{"off the photo", []UserTag{{Username: "jane", X: 1.2, Y: 0.5}}, "between 0 and 1"},
+		{"duplicate", []UserTag{{Username: "Jane", X: 0.1, Y: 0.1}, {Username: "jane", X: 0.9, Y: 0.9}}, "more than once"},
+		{"too many", tooMany, "at most 20"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUserTags(tt.tags)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCollaborators(t *testing.T) {
+	if err := ValidateCollaborators([]string{"jane.doe", "sam_k"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidateCollaborators([]string{"a", "b", "c", "d"}); err == nil {
+		t.Error("expected an error for four collaborators")
+	}
+	if err := ValidateCollaborators([]string{"@jane"}); err == nil {
+		t.Error("expected an error for an unnormalized username")
+	}
+	if got := NormalizeUsername("  @jane.doe "); got != "jane.doe" {
+		t.Errorf("NormalizeUsername = %q", got)
+	}
+}
@@ -0,0 +1,127 @@
+package instagram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Rotating access token (DDR-172) ---
+
+// TokenSource supplies the access token. Value may return a cached token;
+// Refresh reads it again after Instagram rejected it. *bootstrap.Secret
+// implements it.
+type TokenSource interface {
+	Value(ctx context.Context) (string, error)
+	Refresh(ctx context.Context) (string, error)
+}
+
+// errCodeInvalidToken is the Graph API error code for an expired or
+// revoked access token (OAuthException).
+const errCodeInvalidToken = 190
+
+// WithTokenSource makes c send the token from src instead of the one it was
+// created with, and reload it once when Instagram rejects it. It returns c.
+func (c *Client) WithTokenSource(src TokenSource) *Client {
+	c.httpClient.Transport = &tokenTransport{base: c.httpClient.Transport, src: src}
+	return c
+}
+
+// tokenTransport replaces the access_token parameter of each request with
+// the source's current token. A request rejected with 401, 403 or error
+// code 190 is sent once more with a reloaded token, if it changed.
+type tokenTransport struct {
+	base http.RoundTripper
+	src  TokenSource
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	token, err := t.src.Value(ctx)
+	if err != nil || token == "" {
+		return t.base.RoundTrip(req)
+	}
+	r, err := withAccessToken(req, token)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(r)
+	if err != nil || !tokenRejected(resp) {
+		return resp, err
+	}
+
+	fresh, rerr := t.src.Refresh(ctx)
+	if rerr != nil || fresh == "" || fresh == token {
+		return resp, err
+	}
+	log.Warn().Int("status", resp.StatusCode).Msg("Instagram rejected the access token, retrying with the reloaded token")
+	metrics.New("AiSocialMedia").Dimension("Service", "instagram").Count("CredentialRefreshes").Flush()
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	retry, err := withAccessToken(req, fresh)
+	if err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(retry)
+}
+
+// withAccessToken returns a copy of req whose access_token parameter, in
+// the query or a form body, is token.
+func withAccessToken(req *http.Request, token string) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if q := r.URL.Query(); q.Has("access_token") {
+		q.Set("access_token", token)
+		r.URL.RawQuery = q.Encode()
+	}
+	if req.GetBody == nil || !strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		return r, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return nil, err
+	}
+	form, err := url.ParseQuery(string(raw))
+	if err != nil || !form.Has("access_token") {
+		r.Body = io.NopCloser(bytes.NewReader(raw))
+		return r, nil
+	}
+	form.Set("access_token", token)
+	encoded := []byte(form.Encode())
+	r.Body = io.NopCloser(bytes.NewReader(encoded))
+	r.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(encoded)), nil }
+	r.ContentLength = int64(len(encoded))
+	return r, nil
+}
+
+// tokenRejected reports whether resp rejects the access token. A 400 is
+// read to look for error code 190 and its body restored.
+func tokenRejected(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return true
+	case http.StatusBadRequest:
+	default:
+		return false
+	}
+	raw, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(raw))
+	if err != nil {
+		return false
+	}
+	var body apiResponse
+	return json.Unmarshal(raw, &body) == nil && body.Error != nil && body.Error.Code == errCodeInvalidToken
+}
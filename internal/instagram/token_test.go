@@ -0,0 +1,81 @@
+package instagram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// rotatingTokens returns old until refreshed, then new.
+type rotatingTokens struct {
+	old, new  string
+	refreshed int
+}
+
+func (s *rotatingTokens) Value(context.Context) (string, error) {
+	if s.refreshed > 0 {
+		return s.new, nil
+	}
+	return s.old, nil
+}
+
+func (s *rotatingTokens) Refresh(context.Context) (string, error) {
+	s.refreshed++
+	return s.new, nil
+}
+
+func TestTokenSourceRetriesRejectedToken(t *testing.T) {
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		token := r.Form.Get("access_token")
+		seen = append(seen, token)
+		if token != "rotated" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(apiResponse{Error: &apiErr{Message: "Error validating access token", Type: "OAuthException", Code: errCodeInvalidToken}})
+			return
+		}
+		json.NewEncoder(w).Encode(apiResponse{ID: "container-1"})
+	}))
+	defer server.Close()
+
+	tokens := &rotatingTokens{old: "stale", new: "rotated"}
+	client := newTestClient(server).WithTokenSource(tokens)
+	id, err := client.CreateImageContainer(context.Background(), "https://example.com/a.jpg", false, nil, "")
+	if err != nil || id != "container-1" {
+		t.Fatalf("CreateImageContainer = %q, %v", id, err)
+	}
+	if len(seen) != 2 || seen[0] != "stale" || seen[1] != "rotated" || tokens.refreshed != 1 {
+		t.Errorf("tokens sent = %v, refreshes = %d", seen, tokens.refreshed)
+	}
+
+	// GET requests carry the token in the query.
+	seen = nil
+	if _, err := client.ContainerStatus(context.Background(), "container-1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != 1 || seen[0] != "rotated" {
+		t.Errorf("status poll sent %v, want the current token", seen)
+	}
+}
+
+func TestTokenSourceKeepsOtherErrors(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(apiResponse{Error: &apiErr{Message: "Invalid image URL", Code: 9004}})
+	}))
+	defer server.Close()
+
+	tokens := &rotatingTokens{old: "a", new: "b"}
+	client := newTestClient(server).WithTokenSource(tokens)
+	if _, err := client.CreateImageContainer(context.Background(), "https://example.com/a.jpg", false, nil, ""); err == nil {
+		t.Fatal("expected the API error")
+	}
+	if calls != 1 || tokens.refreshed != 0 {
+		t.Errorf("calls = %d, refreshes = %d; a non-token error should not be retried", calls, tokens.refreshed)
+	}
+}
@@ -0,0 +1,51 @@
+package jobs
+
+import "encoding/json"
+
+// Priority is the dispatch class of an async job (DDR-096). Interactive jobs
+// are small follow-ups a user is actively waiting on; batch jobs are large
+// and can absorb queueing delay.
+type Priority string
+
+const (
+	PriorityInteractive Priority = "interactive"
+	PriorityBatch       Priority = "batch"
+)
+
+// interactiveTypes lists the event types dispatched at interactive priority.
+// Everything else (description, download, fb-prep runs) is batch.
+var interactiveTypes = map[string]bool{
+	"description-feedback": true,
+	"enhancement-feedback": true,
+	"fb-prep-feedback":     true,
+	"selection-feedback":   true, // DDR-177
+	"carousel-order":       true, // DDR-179
+	"enhancement-adjust":   true, // DDR-183
+	"media-redact":         true, // DDR-185
+}
+
+// PriorityFor returns the default priority for a job event type.
+func PriorityFor(eventType string) Priority {
+	if interactiveTypes[eventType] {
+		return PriorityInteractive
+	}
+	return PriorityBatch
+}
+
+// PriorityOf returns the priority of a marshaled job event. Events without a
+// priority field (dispatch records written before DDR-096, Step Functions
+// tasks) fall back to the default for their type.
+func PriorityOf(payload []byte) Priority {
+	var peek struct {
+		Type     string   `json:"type"`
+		Priority Priority `json:"priority"`
+	}
+	if err := json.Unmarshal(payload, &peek); err != nil {
+		return PriorityBatch
+	}
+	switch peek.Priority {
+	case PriorityInteractive, PriorityBatch:
+		return peek.Priority
+	}
+	return PriorityFor(peek.Type)
+}
@@ -0,0 +1,121 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+)
+
+// QueueHandler is the Lambda entry point produced by WithQueue.
+type QueueHandler func(ctx context.Context, raw json.RawMessage) (interface{}, error)
+
+// WithQueue adapts a worker's typed event handler so the same Lambda can be
+// triggered both directly (lambda:Invoke, Step Functions) and by an SQS event
+// source mapping on its job queue (DDR-092).
+//
+// For SQS batches each message body is decoded as one job event and handled
+// in order. Failed messages are reported via BatchItemFailures so only they
+// return to the queue after the visibility timeout; the rest are deleted.
+// Direct invocations pass through unchanged, including the handler's result.
+//
+// Within a batch, interactive messages are handled before batch ones, and
+// each message's queue latency is emitted per priority (DDR-096).
+func WithQueue[E any, R any](next func(context.Context, E) (R, error)) QueueHandler {
+	return func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+		if !isSQSEvent(raw) {
+			var event E
+			if err := json.Unmarshal(raw, &event); err != nil {
+				return nil, fmt.Errorf("unmarshal event: %w", err)
+			}
+			return next(ctx, event)
+		}
+
+		var sqsEvent events.SQSEvent
+		if err := json.Unmarshal(raw, &sqsEvent); err != nil {
+			return nil, fmt.Errorf("unmarshal SQS event: %w", err)
+		}
+
+		records := sqsEvent.Records
+		priorities := make(map[string]Priority, len(records))
+		for _, record := range records {
+			priorities[record.MessageId] = PriorityOf([]byte(record.Body))
+		}
+		sort.SliceStable(records, func(i, j int) bool {
+			return priorities[records[i].MessageId] == PriorityInteractive &&
+				priorities[records[j].MessageId] != PriorityInteractive
+		})
+
+		var resp events.SQSEventResponse
+		for _, record := range records {
+			recordQueueLatency(record, priorities[record.MessageId])
+
+			var event E
+			if err := json.Unmarshal([]byte(record.Body), &event); err != nil {
+				// A malformed body will never succeed; let the redrive policy move it to the DLQ.
+				log.Error().Err(err).Str("messageId", record.MessageId).Msg("Invalid job queue message body")
+				resp.BatchItemFailures = append(resp.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: record.MessageId})
+				continue
+			}
+
+			log.Debug().
+				Str("messageId", record.MessageId).
+				Str("priority", string(priorities[record.MessageId])).
+				Str("receiveCount", record.Attributes["ApproximateReceiveCount"]).
+				Msg("Job queue message received")
+
+			if _, err := next(ctx, event); err != nil {
+				log.Error().Err(err).Str("messageId", record.MessageId).Msg("Job failed — returning message to queue")
+				resp.BatchItemFailures = append(resp.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: record.MessageId})
+			}
+		}
+		return resp, nil
+	}
+}
+
+// recordQueueLatency emits the time a message spent on its queue, from the
+// SQS SentTimestamp attribute to now. Redelivered messages include their
+// earlier attempts, so only first receives are measured.
+func recordQueueLatency(record events.SQSMessage, priority Priority) {
+	if record.Attributes["ApproximateReceiveCount"] != "1" {
+		return
+	}
+	sentMs, err := strconv.ParseInt(record.Attributes["SentTimestamp"], 10, 64)
+	if err != nil {
+		return
+	}
+	latency := time.Since(time.UnixMilli(sentMs))
+	if latency < 0 {
+		latency = 0
+	}
+	metrics.New("AiSocialMedia").
+		Dimension("Priority", string(priority)).
+		Metric("JobQueueLatencyMs", float64(latency.Milliseconds()), metrics.UnitMilliseconds).
+		Property("messageId", record.MessageId).
+		Flush()
+}
+
+// isSQSEvent reports whether a raw Lambda payload is an SQS event source batch.
+func isSQSEvent(raw json.RawMessage) bool {
+	var peek struct {
+		Records []struct {
+			EventSource string `json:"eventSource"`
+		} `json:"Records"`
+	}
+	if err := json.Unmarshal(raw, &peek); err != nil || len(peek.Records) == 0 {
+		return false
+	}
+	for _, r := range peek.Records {
+		if r.EventSource != "aws:sqs" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type testEvent struct {
+	Type  string `json:"type"`
+	JobID string `json:"jobId"`
+}
+
+func TestWithQueueDirectInvocation(t *testing.T) {
+	var got testEvent
+	h := WithQueue(func(ctx context.Context, e testEvent) (string, error) {
+		got = e
+		return "done", nil
+	})
+
+	out, err := h(context.Background(), json.RawMessage(`{"type":"description","jobId":"desc-1"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.JobID != "desc-1" || got.Type != "description" {
+		t.Errorf("unexpected event: %+v", got)
+	}
+	if out != "done" {
+		t.Errorf("expected handler result to pass through, got %v", out)
+	}
+}
+
+func TestWithQueueSQSBatch(t *testing.T) {
+	var handled []string
+	h := WithQueue(func(ctx context.Context, e testEvent) (interface{}, error) {
+		handled = append(handled, e.JobID)
+		if e.JobID == "dl-bad" {
+			return nil, errors.New("boom")
+		}
+		return nil, nil
+	})
+
+	raw := json.RawMessage(`{"Records":[
+		{"messageId":"m1","eventSource":"aws:sqs","body":"{\"type\":\"download\",\"jobId\":\"dl-ok\"}"},
+		{"messageId":"m2","eventSource":"aws:sqs","body":"{\"type\":\"download\",\"jobId\":\"dl-bad\"}"},
+		{"messageId":"m3","eventSource":"aws:sqs","body":"not json"}
+	]}`)
+
+	out, err := h(context.Background(), raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(handled) != 2 {
+		t.Errorf("expected 2 handled messages, got %v", handled)
+	}
+
+	resp, ok := out.(events.SQSEventResponse)
+	if !ok {
+		t.Fatalf("expected SQSEventResponse, got %T", out)
+	}
+	if len(resp.BatchItemFailures) != 2 {
+		t.Fatalf("expected 2 batch item failures, got %d", len(resp.BatchItemFailures))
+	}
+	if resp.BatchItemFailures[0].ItemIdentifier != "m2" || resp.BatchItemFailures[1].ItemIdentifier != "m3" {
+		t.Errorf("unexpected failures: %+v", resp.BatchItemFailures)
+	}
+}
+
+func TestIsSQSEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want bool
+	}{
+		{"sqs batch", `{"Records":[{"eventSource":"aws:sqs"}]}`, true},
+		{"s3 event", `{"Records":[{"eventSource":"aws:s3"}]}`, false},
+		{"empty records", `{"Records":[]}`, false},
+		{"job event", `{"type":"description"}`, false},
+		{"not an object", `[1,2]`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSQSEvent(json.RawMessage(tt.raw)); got != tt.want {
+				t.Errorf("isSQSEvent(%s) = %v, want %v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithQueueInteractiveFirst(t *testing.T) {
+	var handled []string
+	h := WithQueue(func(ctx context.Context, e testEvent) (interface{}, error) {
+		handled = append(handled, e.JobID)
+		return nil, nil
+	})
+
+	raw := json.RawMessage(`{"Records":[
+		{"messageId":"m1","eventSource":"aws:sqs","body":"{\"type\":\"download\",\"jobId\":\"dl-1\"}"},
+		{"messageId":"m2","eventSource":"aws:sqs","body":"{\"type\":\"enhancement-feedback\",\"jobId\":\"enh-1\"}"},
+		{"messageId":"m3","eventSource":"aws:sqs","body":"{\"type\":\"description\",\"jobId\":\"desc-1\"}"},
+		{"messageId":"m4","eventSource":"aws:sqs","body":"{\"type\":\"description\",\"jobId\":\"desc-2\",\"priority\":\"interactive\"}"}
+	]}`)
+	if _, err := h(context.Background(), raw); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"enh-1", "desc-2", "dl-1", "desc-1"}
+	if len(handled) != len(want) {
+		t.Fatalf("handled %v, want %v", handled, want)
+	}
+	for i := range want {
+		if handled[i] != want[i] {
+			t.Fatalf("handled %v, want %v", handled, want)
+		}
+	}
+}
+
+func TestPriorityOf(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Priority
+	}{
+		{`{"type":"description-feedback"}`, PriorityInteractive},
+		{`{"type":"download"}`, PriorityBatch},
+		{`{"type":"download","priority":"interactive"}`, PriorityInteractive},
+		{`{"type":"enhancement-feedback","priority":"batch"}`, PriorityBatch},
+		{`{"type":"fb-prep-feedback","priority":"urgent"}`, PriorityInteractive},
+		{`not json`, PriorityBatch},
+	}
+	for _, tt := range tests {
+		if got := PriorityOf([]byte(tt.raw)); got != tt.want {
+			t.Errorf("PriorityOf(%s) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+package jobs
+
+// MaxRetries is the number of times a failed job may be re-dispatched (DDR-089).
+// The budget is shared by manual retries (POST /api/jobs/{id}/retry) and
+// automatic DLQ redrives, since both increment the same retryCount attribute.
+const MaxRetries = 3
@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/rs/zerolog/log"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the caller's request ID, e.g. the
+// API Gateway request ID of the HTTP request being served.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID set by WithRequestID, falling back to the
+// Lambda invocation's request ID. Returns "" outside both.
+func RequestID(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return id
+	}
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		return lc.AwsRequestID
+	}
+	return ""
+}
+
+// upstreamTraceHeaders are response headers in which upstream services
+// return their own trace ID, logged so a slow call can be raised with them.
+var upstreamTraceHeaders = []string{"X-Fb-Trace-Id", "X-Goog-Request-Id"}
+
+// OutboundTransport wraps base (http.DefaultTransport if nil) so every
+// request to an upstream service logs a start entry and an end entry with
+// the caller's request ID, the upstream status, and the duration. The end
+// entry is written when the response body is closed, so streamed responses
+// report their full duration. Query strings are never logged, since the
+// Graph API carries the access token there.
+func OutboundTransport(service string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &outboundTransport{service: service, base: base}
+}
+
+type outboundTransport struct {
+	service string
+	base    http.RoundTripper
+}
+
+func (t *outboundTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	logger := log.With().
+		Str("requestId", RequestID(req.Context())).
+		Str("upstream", t.service).
+		Str("method", req.Method).
+		Str("host", req.URL.Host).
+		Str("path", req.URL.Path).
+		Logger()
+	logger.Debug().Msg("Outbound call started")
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		logger.Warn().Err(err).Dur("duration", time.Since(start)).Msg("Outbound call failed")
+		return nil, err
+	}
+
+	ev := logger.Info().Int("status", resp.StatusCode).Dur("headerLatency", time.Since(start))
+	for _, h := range upstreamTraceHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			ev = ev.Str("upstreamTraceId", v)
+			break
+		}
+	}
+	resp.Body = &outboundBody{ReadCloser: resp.Body, done: func() {
+		ev.Dur("duration", time.Since(start)).Msg("Outbound call finished")
+	}}
+	return resp, nil
+}
+
+// outboundBody runs done once, when the body is first closed.
+type outboundBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *outboundBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
@@ -29,7 +29,7 @@ type StartupLogger struct {
 }
 
 // NewStartupLogger creates a StartupLogger for the given Lambda name
-// (e.g. "media-lambda", "worker-lambda").
+// (e.g. "media-lambda", "selection-lambda").
 func NewStartupLogger(name string) *StartupLogger {
 	return &StartupLogger{
 		name:          name,
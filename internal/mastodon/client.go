@@ -0,0 +1,394 @@
+// Package mastodon provides a client for publishing to a Mastodon-compatible
+// (ActivityPub) server: Mastodon itself, Pixelfed, and other servers that
+// implement the Mastodon client API for media attachments and statuses.
+//
+// The client requires the instance URL and an access token with the
+// write:media and write:statuses scopes, both typically loaded from SSM
+// Parameter Store at Lambda cold start.
+//
+// Publishing maps onto the same phases as Instagram (DDR-040), so the publish
+// pipeline drives it through the same state machine (DDR-147, DDR-151):
+//  1. Upload each item as a media attachment with its alt-text
+//  2. For videos: poll processing status until the attachment is ready
+//  3. Post one status attaching every item
+//
+// Servers publish a status as soon as it is created, so nothing is posted
+// until the last phase. With MASTODON_MODE=mock, or per job with a publish
+// dry run, NewMockClient simulates the server instead.
+package mastodon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// defaultTimeout is the HTTP client timeout for API calls. Uploads carry
+	// the media itself, so it is longer than for the Graph API clients.
+	defaultTimeout = 2 * time.Minute
+
+	// MaxAttachments is the number of media attachments one status can
+	// carry on Mastodon and Pixelfed.
+	MaxAttachments = 4
+
+	// MaxAltTextLength caps an attachment's description (alt-text), in
+	// characters, as Mastodon does.
+	MaxAltTextLength = 1500
+
+	// visibilityPublic posts to the public timelines.
+	visibilityPublic = "public"
+)
+
+// Media processing states reported by MediaStatus.
+const (
+	MediaProcessing = "processing"
+	MediaReady      = "ready"
+)
+
+// Limits are the media and status limits of an instance.
+type Limits struct {
+	ImageBytes  int64 // largest image upload
+	ImagePixels int64 // largest image width × height
+	VideoBytes  int64 // largest video upload
+	Characters  int   // longest status text
+	Attachments int   // most attachments per status
+}
+
+// DefaultLimits are Mastodon's stock limits, used when an instance does not
+// report its own.
+var DefaultLimits = Limits{
+	ImageBytes:  16 << 20,
+	ImagePixels: 4096 * 4096,
+	VideoBytes:  99 << 20,
+	Characters:  500,
+	Attachments: MaxAttachments,
+}
+
+// FitsImage reports whether an image of the given size and dimensions can be
+// uploaded as is.
+func (l Limits) FitsImage(size int64, width, height int) bool {
+	return size <= l.ImageBytes && int64(width)*int64(height) <= l.ImagePixels
+}
+
+// MaxImageWidth returns the largest width an image of the given dimensions
+// can be scaled to, keeping its aspect ratio, within ImagePixels.
+func (l Limits) MaxImageWidth(width, height int) int {
+	if width <= 0 || height <= 0 || int64(width)*int64(height) <= l.ImagePixels {
+		return width
+	}
+	return int(math.Sqrt(float64(l.ImagePixels) * float64(width) / float64(height)))
+}
+
+// Client provides methods for publishing to a Mastodon-compatible instance.
+type Client struct {
+	httpClient  *http.Client
+	instanceURL string
+	accessToken string
+	mock        bool // answered by mockTransport
+
+	limitsOnce sync.Once
+	limits     Limits
+}
+
+// NewClient creates a client for the instance at instanceURL
+// ("https://mastodon.social" or just "mastodon.social").
+func NewClient(instanceURL, accessToken string) *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Timeout:   defaultTimeout,
+			Transport: logging.OutboundTransport("mastodon", nil),
+		},
+		instanceURL: normalizeInstanceURL(instanceURL),
+		accessToken: accessToken,
+	}
+}
+
+// InstanceURL returns the base URL of the instance the client posts to.
+func (c *Client) InstanceURL() string {
+	return c.instanceURL
+}
+
+// --- API response types ---
+
+// attachmentResponse is the MediaAttachment entity. URL stays empty while
+// the server is still processing the upload.
+type attachmentResponse struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// statusResponse is the Status entity.
+type statusResponse struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// instanceResponse holds the configuration part of the Instance entity.
+type instanceResponse struct {
+	Configuration struct {
+		Statuses struct {
+			MaxCharacters       int `json:"max_characters"`
+			MaxMediaAttachments int `json:"max_media_attachments"`
+		} `json:"statuses"`
+		MediaAttachments struct {
+			ImageSizeLimit   int64 `json:"image_size_limit"`
+			ImageMatrixLimit int64 `json:"image_matrix_limit"`
+			VideoSizeLimit   int64 `json:"video_size_limit"`
+		} `json:"media_attachments"`
+	} `json:"configuration"`
+}
+
+// apiErr is the error body Mastodon-compatible servers return.
+type apiErr struct {
+	Error string `json:"error"`
+}
+
+// --- Instance limits ---
+
+// Limits returns the instance's media and status limits. They are read once
+// per client, from /api/v2/instance or, on servers without it, from
+// /api/v1/instance; anything the instance does not report, or a failed
+// read, falls back to DefaultLimits.
+func (c *Client) Limits(ctx context.Context) Limits {
+	c.limitsOnce.Do(func() {
+		c.limits = DefaultLimits
+		var inst instanceResponse
+		err := c.getJSON(ctx, "/api/v2/instance", &inst)
+		if err != nil {
+			err = c.getJSON(ctx, "/api/v1/instance", &inst)
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("instance", c.instanceURL).Msg("Instance limits not readable — using Mastodon defaults")
+			return
+		}
+		cfg := inst.Configuration
+		if v := cfg.MediaAttachments.ImageSizeLimit; v > 0 {
+			c.limits.ImageBytes = v
+		}
+		if v := cfg.MediaAttachments.ImageMatrixLimit; v > 0 {
+			c.limits.ImagePixels = v
+		}
+		if v := cfg.MediaAttachments.VideoSizeLimit; v > 0 {
+			c.limits.VideoBytes = v
+		}
+		if v := cfg.Statuses.MaxCharacters; v > 0 {
+			c.limits.Characters = v
+		}
+		if v := cfg.Statuses.MaxMediaAttachments; v > 0 {
+			c.limits.Attachments = v
+		}
+		log.Debug().Interface("limits", c.limits).Str("instance", c.instanceURL).Msg("Instance limits loaded")
+	})
+	return c.limits
+}
+
+// --- Media attachments ---
+
+// UploadMedia uploads data as a media attachment with description as its
+// alt-text, truncated to MaxAltTextLength, and returns the attachment ID.
+// Large images and videos are processed after the upload returns; poll
+// MediaStatus or call WaitForMedia before attaching them.
+func (c *Client) UploadMedia(ctx context.Context, data []byte, filename, contentType, description string) (string, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, path.Base(filename)))
+	header.Set("Content-Type", contentType)
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return "", fmt.Errorf("build upload: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", fmt.Errorf("build upload: %w", err)
+	}
+	if description = TruncateAltText(description); description != "" {
+		if err := mw.WriteField("description", description); err != nil {
+			return "", fmt.Errorf("build upload: %w", err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return "", fmt.Errorf("build upload: %w", err)
+	}
+
+	var att attachmentResponse
+	if _, err := c.do(ctx, http.MethodPost, "/api/v2/media", mw.FormDataContentType(), &body, nil, &att); err != nil {
+		return "", fmt.Errorf("upload media: %w", err)
+	}
+	if att.ID == "" {
+		return "", fmt.Errorf("upload media: no ID returned")
+	}
+	log.Info().Str("mediaId", att.ID).Str("type", att.Type).Int("bytes", len(data)).Bool("altText", description != "").Msg("Mastodon media uploaded")
+	return att.ID, nil
+}
+
+// MediaStatus returns MediaProcessing while the server is still processing
+// an upload and MediaReady once it can be attached. A failed processing
+// is reported by the server as an error.
+func (c *Client) MediaStatus(ctx context.Context, mediaID string) (string, error) {
+	var att attachmentResponse
+	status, err := c.do(ctx, http.MethodGet, "/api/v1/media/"+url.PathEscape(mediaID), "", nil, nil, &att)
+	if err != nil {
+		return "", fmt.Errorf("media status %s: %w", mediaID, err)
+	}
+	if status == http.StatusPartialContent || att.URL == "" {
+		return MediaProcessing, nil
+	}
+	return MediaReady, nil
+}
+
+// WaitForMedia polls MediaStatus until the attachment is ready or timeout
+// passes. Images are usually ready within seconds, so it is used for them
+// instead of the pipeline's video polling.
+func (c *Client) WaitForMedia(ctx context.Context, mediaID string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		status, err := c.MediaStatus(ctx, mediaID)
+		if err != nil {
+			return err
+		}
+		if status == MediaReady {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("media %s still processing after %s", mediaID, timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// --- Statuses ---
+
+// PostStatus posts a public status with text and the given attachments and
+// returns its ID. idempotencyKey makes a retried call return the status the
+// first call created instead of posting twice.
+func (c *Client) PostStatus(ctx context.Context, text string, mediaIDs []string, idempotencyKey string) (string, error) {
+	if len(mediaIDs) == 0 {
+		return "", fmt.Errorf("status requires at least 1 attachment")
+	}
+	if len(mediaIDs) > MaxAttachments {
+		return "", fmt.Errorf("status supports at most %d attachments, got %d", MaxAttachments, len(mediaIDs))
+	}
+
+	params := url.Values{
+		"status":      {text},
+		"visibility":  {visibilityPublic},
+		"media_ids[]": mediaIDs,
+	}
+	headers := http.Header{}
+	if idempotencyKey != "" {
+		headers.Set("Idempotency-Key", idempotencyKey)
+	}
+	var st statusResponse
+	if _, err := c.do(ctx, http.MethodPost, "/api/v1/statuses", "application/x-www-form-urlencoded",
+		strings.NewReader(params.Encode()), headers, &st); err != nil {
+		return "", fmt.Errorf("post status: %w", err)
+	}
+	if st.ID == "" {
+		return "", fmt.Errorf("post status: no ID returned")
+	}
+	log.Info().Str("statusId", st.ID).Str("url", st.URL).Int("attachments", len(mediaIDs)).Msg("Mastodon status posted successfully")
+	return st.ID, nil
+}
+
+// TruncateAltText trims s and cuts it to MaxAltTextLength characters.
+func TruncateAltText(s string) string {
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) <= MaxAltTextLength {
+		return s
+	}
+	return string([]rune(s)[:MaxAltTextLength])
+}
+
+// --- Internal helpers ---
+
+// getJSON sends a GET request and decodes a successful response into out.
+func (c *Client) getJSON(ctx context.Context, endpoint string, out any) error {
+	_, err := c.do(ctx, http.MethodGet, endpoint, "", nil, nil, out)
+	return err
+}
+
+// do sends an authenticated request and decodes a 2xx response into out.
+// It returns the HTTP status code, which tells a processed attachment (200)
+// from one still processing (206).
+func (c *Client) do(ctx context.Context, method, endpoint, contentType string, body io.Reader, headers http.Header, out any) (int, error) {
+	startTime := time.Now()
+
+	log.Debug().Str("method", method).Str("path", endpoint).Msg("Mastodon API request")
+	req, err := http.NewRequestWithContext(ctx, method, c.instanceURL+endpoint, body)
+	if err != nil {
+		return 0, fmt.Errorf("build request: %w", err)
+	}
+	for k, v := range headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	httpResp, err := c.httpClient.Do(req)
+	duration := time.Since(startTime)
+	if err != nil {
+		log.Debug().Int("statusCode", 0).Dur("duration", duration).Err(err).Msg("Mastodon API response")
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	log.Debug().Int("statusCode", httpResp.StatusCode).Dur("duration", duration).Msg("Mastodon API response")
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return httpResp.StatusCode, fmt.Errorf("read response: %w", err)
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		var apiError apiErr
+		if json.Unmarshal(respBody, &apiError) == nil && apiError.Error != "" {
+			log.Error().Str("errorMessage", apiError.Error).Int("statusCode", httpResp.StatusCode).Msg("Mastodon API error")
+			return httpResp.StatusCode, fmt.Errorf("Mastodon API error: %s (status %d)", apiError.Error, httpResp.StatusCode)
+		}
+		return httpResp.StatusCode, fmt.Errorf("Mastodon API error: status %d (body: %s)", httpResp.StatusCode, truncate(string(respBody), 200))
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return httpResp.StatusCode, fmt.Errorf("parse response: %w (body: %s)", err, truncate(string(respBody), 200))
+	}
+	return httpResp.StatusCode, nil
+}
+
+// normalizeInstanceURL adds https:// to a bare host name and drops trailing
+// slashes.
+func normalizeInstanceURL(u string) string {
+	u = strings.TrimRight(strings.TrimSpace(u), "/")
+	if u != "" && !strings.Contains(u, "://") {
+		u = "https://" + u
+	}
+	return u
+}
+
+// truncate returns the first n characters of s, appending "..." if truncated.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,217 @@
+package mastodon
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestClient creates a Client pointing at a test HTTP server.
+func newTestClient(server *httptest.Server) *Client {
+	return &Client{
+		httpClient:  server.Client(),
+		instanceURL: server.URL,
+		accessToken: "test-token",
+	}
+}
+
+// newInstantMock returns a mock client with no simulated latency and a
+// clock the test controls.
+func newInstantMock(now *time.Time) *Client {
+	c := NewMockClient()
+	tr := newMockTransport()
+	tr.uploadDelay, tr.postDelay = 0, 0
+	tr.Now = func() time.Time { return *now }
+	c.httpClient = &http.Client{Transport: tr}
+	return c
+}
+
+func TestUploadMedia(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v2/media" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("no file part: %v", err)
+		}
+		data, _ := io.ReadAll(file)
+		if string(data) != "jpeg-bytes" || header.Filename != "photo.jpg" || header.Header.Get("Content-Type") != "image/jpeg" {
+			t.Errorf("unexpected file part: %q %q %q", data, header.Filename, header.Header.Get("Content-Type"))
+		}
+		if got := r.FormValue("description"); got != "A red tram on a hill" {
+			t.Errorf("description = %q", got)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(attachmentResponse{ID: "1001", Type: "image"})
+	}))
+	defer server.Close()
+
+	id, err := newTestClient(server).UploadMedia(context.Background(), []byte("jpeg-bytes"), "uploads/photo.jpg", "image/jpeg", "  A red tram on a hill ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != "1001" {
+		t.Errorf("expected 1001, got %s", id)
+	}
+}
+
+func TestMediaStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/media/1":
+			w.WriteHeader(http.StatusPartialContent)
+			json.NewEncoder(w).Encode(attachmentResponse{ID: "1", Type: "video"})
+		case "/api/v1/media/2":
+			json.NewEncoder(w).Encode(attachmentResponse{ID: "2", Type: "video", URL: "https://files.example/2.mp4"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(apiErr{Error: "Record not found"})
+		}
+	}))
+	defer server.Close()
+	c := newTestClient(server)
+
+	for id, want := range map[string]string{"1": MediaProcessing, "2": MediaReady} {
+		got, err := c.MediaStatus(context.Background(), id)
+		if err != nil || got != want {
+			t.Errorf("MediaStatus(%s) = %q, %v; want %q", id, got, err, want)
+		}
+	}
+	if _, err := c.MediaStatus(context.Background(), "3"); err == nil || !strings.Contains(err.Error(), "Record not found") {
+		t.Errorf("expected API error, got %v", err)
+	}
+}
+
+func TestPostStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/statuses" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Idempotency-Key"); got != "pub-1" {
+			t.Errorf("Idempotency-Key = %q", got)
+		}
+		r.ParseForm()
+		if got := strings.Join(r.Form["media_ids[]"], ","); got != "1,2" {
+			t.Errorf("media_ids[] = %s", got)
+		}
+		if r.Form.Get("status") != "Hello #travel" || r.Form.Get("visibility") != "public" {
+			t.Errorf("unexpected form: %v", r.Form)
+		}
+		json.NewEncoder(w).Encode(statusResponse{ID: "110", URL: "https://example.social/@me/110"})
+	}))
+	defer server.Close()
+
+	id, err := newTestClient(server).PostStatus(context.Background(), "Hello #travel", []string{"1", "2"}, "pub-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != "110" {
+		t.Errorf("expected 110, got %s", id)
+	}
+
+	if _, err := newTestClient(server).PostStatus(context.Background(), "x", []string{"1", "2", "3", "4", "5"}, ""); err == nil {
+		t.Error("expected an error for 5 attachments")
+	}
+}
+
+func TestLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/instance" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"configuration":{"statuses":{"max_characters":2000},"media_attachments":{"image_size_limit":15360000}}}`))
+	}))
+	defer server.Close()
+
+	got := newTestClient(server).Limits(context.Background())
+	want := DefaultLimits
+	want.ImageBytes, want.Characters = 15360000, 2000
+	if got != want {
+		t.Errorf("Limits = %+v, want %+v", got, want)
+	}
+}
+
+func TestLimitsImageFit(t *testing.T) {
+	l := Limits{ImageBytes: 1000, ImagePixels: 1000 * 1000}
+	if !l.FitsImage(1000, 1000, 1000) || l.FitsImage(1001, 10, 10) || l.FitsImage(10, 2000, 1000) {
+		t.Error("FitsImage misjudged the limits")
+	}
+	if w := l.MaxImageWidth(800, 600); w != 800 {
+		t.Errorf("MaxImageWidth within limits = %d, want 800", w)
+	}
+	w := l.MaxImageWidth(4000, 2000)
+	if h := w / 2; int64(w)*int64(h) > l.ImagePixels || w < 1400 {
+		t.Errorf("MaxImageWidth(4000, 2000) = %d", w)
+	}
+}
+
+func TestTruncateAltText(t *testing.T) {
+	long := strings.Repeat("é", MaxAltTextLength+10)
+	if got := TruncateAltText(long); len([]rune(got)) != MaxAltTextLength {
+		t.Errorf("truncated to %d characters", len([]rune(got)))
+	}
+	if got := TruncateAltText("  a cat  "); got != "a cat" {
+		t.Errorf("TruncateAltText = %q", got)
+	}
+}
+
+func TestNormalizeInstanceURL(t *testing.T) {
+	for in, want := range map[string]string{
+		"mastodon.social":          "https://mastodon.social",
+		"https://pixelfed.social/": "https://pixelfed.social",
+		"http://localhost:3000":    "http://localhost:3000",
+	} {
+		if got := normalizeInstanceURL(in); got != want {
+			t.Errorf("normalizeInstanceURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMockPublishFlow(t *testing.T) {
+	now := time.Now()
+	c := newInstantMock(&now)
+	ctx := context.Background()
+
+	if l := c.Limits(ctx); l != DefaultLimits {
+		t.Errorf("mock limits = %+v", l)
+	}
+	photo, err := c.UploadMedia(ctx, []byte("jpeg"), "a.jpg", "image/jpeg", "alt")
+	if err != nil || !IsMockID(photo) {
+		t.Fatalf("upload photo = %q, %v", photo, err)
+	}
+	if err := c.WaitForMedia(ctx, photo, time.Second); err != nil {
+		t.Errorf("photo not ready: %v", err)
+	}
+	video, err := c.UploadMedia(ctx, []byte("mp4"), "b.mp4", "video/mp4", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := c.MediaStatus(ctx, video); status != MediaProcessing {
+		t.Errorf("fresh video status = %s, want processing", status)
+	}
+	if _, err := c.PostStatus(ctx, "Hi", []string{video}, "job"); err == nil {
+		t.Error("posting an unprocessed video should fail")
+	}
+
+	now = now.Add(mockVideoProcessing)
+	if status, _ := c.MediaStatus(ctx, video); status != MediaReady {
+		t.Errorf("processed video status = %s, want ready", status)
+	}
+	id, err := c.PostStatus(ctx, "Hi", []string{video}, "job")
+	if err != nil || !IsMockID(id) {
+		t.Errorf("post = %q, %v", id, err)
+	}
+	if !c.IsMock() {
+		t.Error("mock client should report IsMock")
+	}
+}
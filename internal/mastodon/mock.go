@@ -0,0 +1,181 @@
+package mastodon
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/logging"
+	"github.com/fpang/ai-social-media-helper/internal/mockapi"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Mock mode (DDR-151, following DDR-139) ---
+//
+// A mock client answers the API calls this package makes without reaching
+// an instance. IDs start with "mock-". Videos report processing until
+// mockVideoProcessing has passed since their upload; the upload time is
+// encoded in the ID, so any Lambda invocation can answer a status poll.
+// The mock reports DefaultLimits as its instance limits.
+
+const (
+	// ModeEnv selects the client mode: "mock" simulates the instance for the
+	// whole deployment; anything else publishes for real.
+	ModeEnv = "MASTODON_MODE"
+
+	// mockInstanceURL is the instance a mock client claims to post to.
+	mockInstanceURL = "https://mastodon.mock"
+
+	// Simulated API latencies, close to what a busy instance shows.
+	mockUploadDelay     = 800 * time.Millisecond
+	mockPostDelay       = 500 * time.Millisecond
+	mockVideoProcessing = 20 * time.Second
+)
+
+// MockEnabled reports whether MASTODON_MODE=mock is set.
+func MockEnabled() bool {
+	return strings.EqualFold(os.Getenv(ModeEnv), "mock")
+}
+
+// NewMockClient returns a Client that simulates uploads, media processing
+// and posting with realistic timing and fake IDs.
+func NewMockClient() *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Timeout:   defaultTimeout,
+			Transport: logging.OutboundTransport("mastodon-mock", newMockTransport()),
+		},
+		instanceURL: mockInstanceURL,
+		accessToken: "mock-token",
+		mock:        true,
+	}
+}
+
+// IsMock reports whether c simulates an instance instead of calling it.
+func (c *Client) IsMock() bool {
+	return c.mock
+}
+
+// IsMockID reports whether id was handed out by a mock client.
+func IsMockID(id string) bool {
+	return mockapi.IsID(id)
+}
+
+// mockTransport is an http.RoundTripper that plays a Mastodon instance.
+type mockTransport struct {
+	*mockapi.Clock
+	uploadDelay     time.Duration
+	postDelay       time.Duration
+	videoProcessing time.Duration
+}
+
+func newMockTransport() *mockTransport {
+	return &mockTransport{
+		Clock:           mockapi.NewClock(),
+		uploadDelay:     mockUploadDelay,
+		postDelay:       mockPostDelay,
+		videoProcessing: mockVideoProcessing,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (m *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := req.URL.Path
+	switch {
+	case req.Method == http.MethodGet && strings.HasSuffix(path, "/instance"):
+		return m.instance(req)
+	case req.Method == http.MethodPost && path == "/api/v2/media":
+		return m.upload(req)
+	case req.Method == http.MethodGet && strings.HasPrefix(path, "/api/v1/media/"):
+		return m.mediaStatus(req)
+	case req.Method == http.MethodPost && path == "/api/v1/statuses":
+		return m.postStatus(req)
+	}
+	return mockError(req, http.StatusNotFound, fmt.Sprintf("mock: unsupported request %s %s", req.Method, path))
+}
+
+func (m *mockTransport) instance(req *http.Request) (*http.Response, error) {
+	var inst instanceResponse
+	inst.Configuration.Statuses.MaxCharacters = DefaultLimits.Characters
+	inst.Configuration.Statuses.MaxMediaAttachments = DefaultLimits.Attachments
+	inst.Configuration.MediaAttachments.ImageSizeLimit = DefaultLimits.ImageBytes
+	inst.Configuration.MediaAttachments.ImageMatrixLimit = DefaultLimits.ImagePixels
+	inst.Configuration.MediaAttachments.VideoSizeLimit = DefaultLimits.VideoBytes
+	return mockapi.JSON(req, http.StatusOK, inst)
+}
+
+func (m *mockTransport) upload(req *http.Request) (*http.Response, error) {
+	if err := req.ParseMultipartForm(32 << 20); err != nil {
+		return mockError(req, http.StatusUnprocessableEntity, "mock: invalid upload: "+err.Error())
+	}
+	file, header, err := req.FormFile("file")
+	if err != nil {
+		return mockError(req, http.StatusUnprocessableEntity, "mock: upload has no file")
+	}
+	file.Close()
+	kind := "image"
+	if strings.HasPrefix(header.Header.Get("Content-Type"), "video/") {
+		kind = "video"
+	}
+	if err := mockapi.Sleep(req.Context(), m.uploadDelay); err != nil {
+		return nil, err
+	}
+	id := m.NewID(kind)
+	log.Info().Str("mediaId", id).Str("kind", kind).Int64("bytes", header.Size).
+		Bool("altText", req.FormValue("description") != "").Msg("Mock Mastodon media uploaded")
+	if kind == "video" {
+		return mockapi.JSON(req, http.StatusAccepted, attachmentResponse{ID: id, Type: kind})
+	}
+	return mockapi.JSON(req, http.StatusOK, attachmentResponse{ID: id, Type: kind, URL: mockInstanceURL + "/media/" + id})
+}
+
+func (m *mockTransport) mediaStatus(req *http.Request) (*http.Response, error) {
+	id := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+	kind, created, ok := mockapi.ParseID(id)
+	if !ok || (kind != "image" && kind != "video") {
+		return mockError(req, http.StatusNotFound, "Record not found")
+	}
+	if kind == "video" && m.Since(created) < m.videoProcessing {
+		return mockapi.JSON(req, http.StatusPartialContent, attachmentResponse{ID: id, Type: kind})
+	}
+	return mockapi.JSON(req, http.StatusOK, attachmentResponse{ID: id, Type: kind, URL: mockInstanceURL + "/media/" + id})
+}
+
+func (m *mockTransport) postStatus(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return mockError(req, http.StatusUnprocessableEntity, "mock: invalid form")
+	}
+	for _, id := range form["media_ids[]"] {
+		kind, created, ok := mockapi.ParseID(id)
+		if !ok {
+			return mockError(req, http.StatusUnprocessableEntity, fmt.Sprintf("mock: unknown media %q", id))
+		}
+		if kind == "video" && m.Since(created) < m.videoProcessing {
+			return mockError(req, http.StatusUnprocessableEntity, "Cannot attach files that have not finished processing. Try again in a moment!")
+		}
+	}
+	if n := len([]rune(form.Get("status"))); n > DefaultLimits.Characters {
+		return mockError(req, http.StatusUnprocessableEntity, fmt.Sprintf("Validation failed: Text character limit of %d exceeded", DefaultLimits.Characters))
+	}
+	if err := mockapi.Sleep(req.Context(), m.postDelay); err != nil {
+		return nil, err
+	}
+	id := m.NewID("status")
+	log.Info().Str("statusId", id).Int("attachments", len(form["media_ids[]"])).Msg("Mock Mastodon status posted")
+	return mockapi.JSON(req, http.StatusOK, statusResponse{ID: id, URL: mockInstanceURL + "/@mock/" + id})
+}
+
+// mockError answers like an instance error, so callers see the same error
+// path as with a live server.
+func mockError(req *http.Request, status int, msg string) (*http.Response, error) {
+	return mockapi.JSON(req, status, apiErr{Error: msg})
+}
@@ -0,0 +1,160 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"math"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/image/draw"
+)
+
+// --- Local adjustments (DDR-183) ---
+
+// Adjustments are deterministic edits to a photo, applied without Gemini or
+// Imagen. The zero value changes nothing. They apply in a fixed order:
+// rotate, crop, then tone (brightness, contrast, saturation).
+type Adjustments struct {
+	// Brightness shifts every channel by up to the full range: -1 is black,
+	// 1 is white.
+	Brightness float64 `json:"brightness,omitempty"`
+	// Contrast scales distance from mid-grey: -1 is flat grey, 1 doubles it.
+	Contrast float64 `json:"contrast,omitempty"`
+	// Saturation scales distance from grey: -1 is black and white, 1
+	// doubles it.
+	Saturation float64 `json:"saturation,omitempty"`
+	// Rotate turns the photo clockwise by 90, 180 or 270 degrees.
+	Rotate int `json:"rotate,omitempty"`
+	// Crop is a rectangle in displayed pixels of the rotated photo.
+	Crop *AdjustCrop `json:"crop,omitempty"`
+}
+
+// AdjustCrop is a crop rectangle in displayed pixels.
+type AdjustCrop struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// Validate checks that each adjustment is in range. Whether the crop fits
+// the photo is only known when it is applied.
+func (a Adjustments) Validate() error {
+	for _, v := range []struct {
+		name  string
+		value float64
+	}{{"brightness", a.Brightness}, {"contrast", a.Contrast}, {"saturation", a.Saturation}} {
+		if math.IsNaN(v.value) || v.value < -1 || v.value > 1 {
+			return fmt.Errorf("%s must be between -1 and 1", v.name)
+		}
+	}
+	switch a.Rotate {
+	case 0, 90, 180, 270:
+	default:
+		return fmt.Errorf("rotate must be 0, 90, 180 or 270")
+	}
+	if a.Crop != nil && (a.Crop.X < 0 || a.Crop.Y < 0 || a.Crop.W <= 0 || a.Crop.H <= 0) {
+		return fmt.Errorf("crop must have a non-negative origin and a positive width and height")
+	}
+	if a.IsZero() {
+		return fmt.Errorf("no adjustments given")
+	}
+	return nil
+}
+
+// IsZero reports whether a leaves the photo unchanged.
+func (a Adjustments) IsZero() bool {
+	return a.Brightness == 0 && a.Contrast == 0 && a.Saturation == 0 && a.Rotate == 0 && a.Crop == nil
+}
+
+// String summarizes the adjustments in the order they apply, e.g.
+// "rotate 90°, crop 1080x1350 at 0,120, brightness +0.20".
+func (a Adjustments) String() string {
+	var parts []string
+	if a.Rotate != 0 {
+		parts = append(parts, fmt.Sprintf("rotate %d°", a.Rotate))
+	}
+	if c := a.Crop; c != nil {
+		parts = append(parts, fmt.Sprintf("crop %dx%d at %d,%d", c.W, c.H, c.X, c.Y))
+	}
+	if a.Brightness != 0 {
+		parts = append(parts, fmt.Sprintf("brightness %+.2f", a.Brightness))
+	}
+	if a.Contrast != 0 {
+		parts = append(parts, fmt.Sprintf("contrast %+.2f", a.Contrast))
+	}
+	if a.Saturation != 0 {
+		parts = append(parts, fmt.Sprintf("saturation %+.2f", a.Saturation))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ApplyAdjustments decodes image data (JPEG EXIF orientation applied),
+// applies a and re-encodes the result as a JPEG at the given quality.
+func ApplyAdjustments(data []byte, a Adjustments, quality int) ([]byte, error) {
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	if format == "jpeg" {
+		img = orientJPEG(img, data)
+	}
+	img = rotateCCW(img, (360-a.Rotate)/90)
+
+	b := img.Bounds()
+	rect := image.Rect(0, 0, b.Dx(), b.Dy())
+	if c := a.Crop; c != nil {
+		rect = image.Rect(c.X, c.Y, c.X+c.W, c.Y+c.H)
+		if !rect.In(image.Rect(0, 0, b.Dx(), b.Dy())) {
+			return nil, fmt.Errorf("crop %v is outside the %dx%d image", rect, b.Dx(), b.Dy())
+		}
+	}
+	out := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(out, out.Bounds(), img, b.Min.Add(rect.Min), draw.Src)
+	adjustTone(out, a)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, out, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("encode image: %w", err)
+	}
+	log.Debug().
+		Str("format", format).
+		Str("adjustments", a.String()).
+		Int("width", rect.Dx()).
+		Int("height", rect.Dy()).
+		Int("output_size", buf.Len()).
+		Msg("Image adjusted")
+	return buf.Bytes(), nil
+}
+
+// adjustTone applies brightness and contrast through a lookup table, then
+// saturation against each pixel's Rec. 601 luma.
+func adjustTone(img *image.RGBA, a Adjustments) {
+	if a.Brightness == 0 && a.Contrast == 0 && a.Saturation == 0 {
+		return
+	}
+	var lut [256]float64
+	for i := range lut {
+		v := float64(i) + a.Brightness*255
+		lut[i] = (v-128)*(1+a.Contrast) + 128
+	}
+	sat := 1 + a.Saturation
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		r, g, b := lut[img.Pix[i]], lut[img.Pix[i+1]], lut[img.Pix[i+2]]
+		if sat != 1 {
+			l := 0.299*r + 0.587*g + 0.114*b
+			r, g, b = l+(r-l)*sat, l+(g-l)*sat, l+(b-l)*sat
+		}
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2] = clampByte(r), clampByte(g), clampByte(b)
+	}
+}
+
+func clampByte(v float64) uint8 {
+	return uint8(math.Round(min(max(v, 0), 255)))
+}
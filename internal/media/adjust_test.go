@@ -0,0 +1,92 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestApplyAdjustmentsGeometry(t *testing.T) {
+	data := testJPEG(t, 400, 300)
+
+	tests := []struct {
+		name string
+		adj  Adjustments
+		w, h int
+	}{
+		{"rotate 90", Adjustments{Rotate: 90}, 300, 400},
+		{"rotate 180", Adjustments{Rotate: 180}, 400, 300},
+		{"crop", Adjustments{Crop: &AdjustCrop{X: 50, Y: 0, W: 300, H: 300}}, 300, 300},
+		{"crop applies after rotation", Adjustments{Rotate: 270, Crop: &AdjustCrop{X: 0, Y: 100, W: 300, H: 240}}, 300, 240},
+		{"tone keeps size", Adjustments{Brightness: 0.1}, 400, 300},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := ApplyAdjustments(data, tt.adj, 90)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg, format, err := image.DecodeConfig(bytes.NewReader(out))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format != "jpeg" || cfg.Width != tt.w || cfg.Height != tt.h {
+				t.Errorf("got %s %dx%d, want jpeg %dx%d", format, cfg.Width, cfg.Height, tt.w, tt.h)
+			}
+		})
+	}
+
+	if _, err := ApplyAdjustments(data, Adjustments{Rotate: 90, Crop: &AdjustCrop{W: 400, H: 300}}, 90); err == nil {
+		t.Error("expected error for crop outside the rotated image")
+	}
+}
+
+func TestAdjustmentsValidate(t *testing.T) {
+	bad := []Adjustments{
+		{},
+		{Brightness: 1.5},
+		{Contrast: -2},
+		{Rotate: 45},
+		{Crop: &AdjustCrop{W: 0, H: 10}},
+	}
+	for _, a := range bad {
+		if err := a.Validate(); err == nil {
+			t.Errorf("Validate(%+v) accepted", a)
+		}
+	}
+	if err := (Adjustments{Saturation: -1, Rotate: 270}).Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestAdjustTone(t *testing.T) {
+	tests := []struct {
+		name string
+		adj  Adjustments
+		in   color.RGBA
+		want color.RGBA
+	}{
+		{"brightness", Adjustments{Brightness: 0.2}, color.RGBA{100, 50, 0, 255}, color.RGBA{151, 101, 51, 255}},
+		{"contrast", Adjustments{Contrast: 1}, color.RGBA{100, 150, 250, 255}, color.RGBA{72, 172, 255, 255}},
+		{"flat contrast", Adjustments{Contrast: -1}, color.RGBA{10, 200, 90, 255}, color.RGBA{128, 128, 128, 255}},
+		{"black and white", Adjustments{Saturation: -1}, color.RGBA{200, 100, 50, 255}, color.RGBA{124, 124, 124, 255}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+			img.SetRGBA(0, 0, tt.in)
+			adjustTone(img, tt.adj)
+			if got := img.RGBAAt(0, 0); got != tt.want {
+				t.Errorf("adjustTone() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdjustmentsString(t *testing.T) {
+	a := Adjustments{Brightness: 0.2, Rotate: 90, Crop: &AdjustCrop{X: 0, Y: 120, W: 1080, H: 1350}}
+	if got, want := a.String(), "rotate 90°, crop 1080x1350 at 0,120, brightness +0.20"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
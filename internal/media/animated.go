@@ -0,0 +1,349 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// Animated GIF and WebP (DDR-120). A single frame misrepresents an animation
+// — the first frame of a GIF is often blank or a title card — so animations
+// are shown to the UI and Gemini as a sheet of evenly spaced frames, report
+// their frame count and duration in metadata, and are triaged as short videos.
+// Stills in either format keep the plain image path.
+
+// animationSheetFrames is the number of frames sampled into a frame sheet,
+// tiled two per row.
+const animationSheetFrames = 4
+
+// minGIFFrameDelay is the delay browsers substitute for GIF frame delays of
+// 10ms or less; durations are reported as the animation actually plays.
+const minGIFFrameDelay = 100 * time.Millisecond
+
+// ErrNotAnimated is returned by DecodeAnimationFrames for single-frame files.
+var ErrNotAnimated = errors.New("animation: file has a single frame")
+
+// AnimationInfo describes an animated image.
+type AnimationInfo struct {
+	FrameCount int
+	Duration   time.Duration
+	Width      int
+	Height     int
+}
+
+// IsAnimatable returns true if the extension is an image format that can
+// carry an animation.
+func IsAnimatable(ext string) bool {
+	ext = strings.ToLower(ext)
+	return ext == ".gif" || ext == ".webp"
+}
+
+// ProbeAnimation reports the frame count, duration and canvas size of a GIF
+// or WebP file. A still image reports FrameCount 1.
+func ProbeAnimation(filePath string) (AnimationInfo, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return AnimationInfo{}, fmt.Errorf("read animation: %w", err)
+	}
+	if strings.ToLower(filepath.Ext(filePath)) == ".webp" {
+		anim, err := parseWebPAnimation(data)
+		if err != nil {
+			return AnimationInfo{}, err
+		}
+		return anim.info(), nil
+	}
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return AnimationInfo{}, fmt.Errorf("decode GIF: %w", err)
+	}
+	return gifInfo(g), nil
+}
+
+// DecodeAnimationFrames returns up to n evenly spaced frames of an animated
+// GIF or WebP, each fully composited onto the canvas as it appears during
+// playback.
+func DecodeAnimationFrames(filePath string, n int) ([]image.Image, AnimationInfo, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, AnimationInfo{}, fmt.Errorf("read animation: %w", err)
+	}
+	if strings.ToLower(filepath.Ext(filePath)) == ".webp" {
+		anim, err := parseWebPAnimation(data)
+		if err != nil {
+			return nil, AnimationInfo{}, err
+		}
+		info := anim.info()
+		if info.FrameCount < 2 {
+			return nil, info, ErrNotAnimated
+		}
+		frames, err := anim.composite(sampleFrames(info.FrameCount, n))
+		return frames, info, err
+	}
+
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, AnimationInfo{}, fmt.Errorf("decode GIF: %w", err)
+	}
+	info := gifInfo(g)
+	if info.FrameCount < 2 {
+		return nil, info, ErrNotAnimated
+	}
+	return compositeGIF(g, sampleFrames(info.FrameCount, n)), info, nil
+}
+
+// AnimationFrameSheet renders evenly spaced frames of an animation as one
+// JPEG, two frames per row in playback order, scaled to fit maxDimension.
+// Transparent areas are flattened onto white.
+func AnimationFrameSheet(filePath string, maxDimension, quality int) ([]byte, error) {
+	frames, info, err := DecodeAnimationFrames(filePath, animationSheetFrames)
+	if err != nil {
+		return nil, err
+	}
+
+	cols := min(len(frames), 2)
+	rows := (len(frames) + cols - 1) / cols
+	sheet := image.NewRGBA(image.Rect(0, 0, cols*info.Width, rows*info.Height))
+	draw.Draw(sheet, sheet.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	for i, frame := range frames {
+		at := image.Pt(i%cols*info.Width, i/cols*info.Height)
+		draw.Draw(sheet, frame.Bounds().Add(at), frame, frame.Bounds().Min, draw.Over)
+	}
+
+	log.Debug().
+		Str("path", filePath).
+		Int("frames", info.FrameCount).
+		Dur("duration", info.Duration).
+		Int("sampled", len(frames)).
+		Msg("Rendered animation frame sheet")
+	return encodeJPEGThumbnail(sheet, maxDimension, quality)
+}
+
+// isAnimatedFile reports whether a media file is an animation, using its
+// metadata when loaded and probing the file otherwise.
+func isAnimatedFile(mediaFile *MediaFile) bool {
+	if m, ok := mediaFile.Metadata.(*ImageMetadata); ok {
+		return m.IsAnimated()
+	}
+	info, err := ProbeAnimation(mediaFile.Path)
+	return err == nil && info.FrameCount > 1
+}
+
+// sampleFrames picks n frame indices spread evenly across count frames,
+// centred in each span so the first and last frames (often identical in a
+// loop) are not both chosen.
+func sampleFrames(count, n int) []int {
+	n = min(n, count)
+	out := make([]int, n)
+	for k := range out {
+		out[k] = (2*k + 1) * count / (2 * n)
+	}
+	return out
+}
+
+func gifInfo(g *gif.GIF) AnimationInfo {
+	info := AnimationInfo{FrameCount: len(g.Image), Width: g.Config.Width, Height: g.Config.Height}
+	for _, d := range g.Delay {
+		delay := time.Duration(d) * 10 * time.Millisecond
+		if delay <= 10*time.Millisecond {
+			delay = minGIFFrameDelay
+		}
+		info.Duration += delay
+	}
+	if (info.Width == 0 || info.Height == 0) && len(g.Image) > 0 {
+		b := g.Image[0].Bounds()
+		info.Width, info.Height = b.Max.X, b.Max.Y
+	}
+	return info
+}
+
+// compositeGIF plays the GIF onto a canvas, applying each frame's disposal
+// method, and snapshots the canvas at the wanted (ascending) frame indices.
+func compositeGIF(g *gif.GIF, wanted []int) []image.Image {
+	info := gifInfo(g)
+	canvas := image.NewRGBA(image.Rect(0, 0, info.Width, info.Height))
+	var out []image.Image
+	next := 0
+	for i, frame := range g.Image {
+		var previous *image.RGBA
+		disposal := byte(0)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		if disposal == gif.DisposalPrevious {
+			previous = cloneRGBA(canvas)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		for next < len(wanted) && wanted[next] == i {
+			out = append(out, cloneRGBA(canvas))
+			next++
+		}
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	return out
+}
+
+func cloneRGBA(img *image.RGBA) *image.RGBA {
+	c := image.NewRGBA(img.Bounds())
+	copy(c.Pix, img.Pix)
+	return c
+}
+
+// webpAnimation is the frame layout of a WebP file, read from its RIFF
+// chunks. A still WebP has no frames.
+type webpAnimation struct {
+	width, height int
+	frames        []webpFrame
+}
+
+// webpFrame is one ANMF chunk: a sub-rectangle of the canvas and the
+// bitstream chunks (optional ALPH, then VP8 or VP8L) that fill it.
+type webpFrame struct {
+	rect     image.Rectangle
+	duration time.Duration
+	dispose  bool // dispose to background after display
+	noBlend  bool // replace the rectangle instead of alpha-blending
+	data     []byte
+}
+
+func (a *webpAnimation) info() AnimationInfo {
+	info := AnimationInfo{FrameCount: max(len(a.frames), 1), Width: a.width, Height: a.height}
+	for _, f := range a.frames {
+		info.Duration += f.duration
+	}
+	return info
+}
+
+// composite plays the animation onto a canvas and snapshots it at the
+// wanted (ascending) frame indices. Frames are decoded with x/image/webp by
+// wrapping each frame's bitstream in a standalone WebP container.
+func (a *webpAnimation) composite(wanted []int) ([]image.Image, error) {
+	canvas := image.NewRGBA(image.Rect(0, 0, a.width, a.height))
+	var out []image.Image
+	next := 0
+	for i, f := range a.frames {
+		if next == len(wanted) {
+			break
+		}
+		img, err := webp.Decode(bytes.NewReader(f.standalone()))
+		if err != nil {
+			return nil, fmt.Errorf("decode WebP frame %d: %w", i, err)
+		}
+		op := draw.Over
+		if f.noBlend {
+			op = draw.Src
+		}
+		draw.Draw(canvas, f.rect, img, img.Bounds().Min, op)
+		for next < len(wanted) && wanted[next] == i {
+			out = append(out, cloneRGBA(canvas))
+			next++
+		}
+		if f.dispose {
+			draw.Draw(canvas, f.rect, image.Transparent, image.Point{}, draw.Src)
+		}
+	}
+	return out, nil
+}
+
+// standalone wraps the frame's bitstream chunks in a RIFF WEBP container,
+// adding a VP8X header when an ALPH chunk needs one.
+func (f webpFrame) standalone() []byte {
+	var body []byte
+	if bytes.HasPrefix(f.data, []byte("ALPH")) {
+		vp8x := make([]byte, 10)
+		vp8x[0] = 1 << 4 // alpha
+		putUint24LE(vp8x[4:], uint32(f.rect.Dx()-1))
+		putUint24LE(vp8x[7:], uint32(f.rect.Dy()-1))
+		body = appendRIFFChunk(body, "VP8X", vp8x)
+	}
+	body = append(body, f.data...)
+
+	out := []byte("RIFF")
+	out = binary.LittleEndian.AppendUint32(out, uint32(4+len(body)))
+	out = append(out, "WEBP"...)
+	return append(out, body...)
+}
+
+// parseWebPAnimation reads the canvas size and ANMF frames of a WebP file.
+func parseWebPAnimation(data []byte) (*webpAnimation, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, fmt.Errorf("not a WebP file")
+	}
+	anim := &webpAnimation{}
+	for pos := 12; pos+8 <= len(data); {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		body := data[pos+8:]
+		if size > len(body) {
+			return nil, fmt.Errorf("truncated WebP chunk %q", id)
+		}
+		body = body[:size]
+		pos += 8 + size + size&1
+
+		switch id {
+		case "VP8X":
+			if size < 10 {
+				return nil, fmt.Errorf("short VP8X chunk")
+			}
+			anim.width = int(uint24LE(body[4:])) + 1
+			anim.height = int(uint24LE(body[7:])) + 1
+		case "ANMF":
+			if size < 16 {
+				return nil, fmt.Errorf("short ANMF chunk")
+			}
+			x, y := 2*int(uint24LE(body[0:])), 2*int(uint24LE(body[3:]))
+			w, h := int(uint24LE(body[6:]))+1, int(uint24LE(body[9:]))+1
+			anim.frames = append(anim.frames, webpFrame{
+				rect:     image.Rect(x, y, x+w, y+h),
+				duration: time.Duration(uint24LE(body[12:])) * time.Millisecond,
+				dispose:  body[15]&0x01 != 0,
+				noBlend:  body[15]&0x02 != 0,
+				data:     body[16:],
+			})
+		}
+	}
+	if anim.width == 0 {
+		cfg, err := webp.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("decode WebP config: %w", err)
+		}
+		anim.width, anim.height = cfg.Width, cfg.Height
+	}
+	return anim, nil
+}
+
+func appendRIFFChunk(b []byte, id string, body []byte) []byte {
+	b = append(b, id...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(body)))
+	b = append(b, body...)
+	if len(body)&1 == 1 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func uint24LE(b []byte) uint32 {
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+}
+
+func putUint24LE(b []byte, v uint32) {
+	b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+}
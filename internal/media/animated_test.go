@@ -0,0 +1,145 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestGIF writes a GIF whose frame i is a solid palette colour i.
+func writeTestGIF(t *testing.T, frames int, delay int) string {
+	t.Helper()
+	palette := color.Palette{color.Black, color.White, color.RGBA{255, 0, 0, 255}, color.RGBA{0, 255, 0, 255}}
+	g := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		img := image.NewPaletted(image.Rect(0, 0, 40, 30), palette)
+		for p := range img.Pix {
+			img.Pix[p] = uint8(i % len(palette))
+		}
+		g.Image = append(g.Image, img)
+		g.Delay = append(g.Delay, delay)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "anim.gif")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAnimatedGIF(t *testing.T) {
+	path := writeTestGIF(t, 8, 25)
+
+	info, err := ProbeAnimation(path)
+	if err != nil {
+		t.Fatalf("ProbeAnimation: %v", err)
+	}
+	if info.FrameCount != 8 || info.Duration != 2*time.Second {
+		t.Errorf("ProbeAnimation = %d frames, %v; want 8 frames, 2s", info.FrameCount, info.Duration)
+	}
+
+	md, err := ExtractImageMetadata(path)
+	if err != nil {
+		t.Fatalf("ExtractImageMetadata: %v", err)
+	}
+	if !md.IsAnimated() || md.FrameCount != 8 || md.AnimationDuration != 2*time.Second {
+		t.Errorf("metadata = %d frames, %v; want 8 frames, 2s", md.FrameCount, md.AnimationDuration)
+	}
+
+	sheet, err := AnimationFrameSheet(path, 1024, 80)
+	if err != nil {
+		t.Fatalf("AnimationFrameSheet: %v", err)
+	}
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(sheet))
+	if err != nil {
+		t.Fatalf("frame sheet is not a JPEG: %v", err)
+	}
+	if cfg.Width != 80 || cfg.Height != 60 {
+		t.Errorf("frame sheet is %dx%d, want 80x60 (2x2 frames)", cfg.Width, cfg.Height)
+	}
+}
+
+func TestStillGIFIsNotAnimated(t *testing.T) {
+	path := writeTestGIF(t, 1, 0)
+	if _, _, err := DecodeAnimationFrames(path, 4); err != ErrNotAnimated {
+		t.Errorf("DecodeAnimationFrames err = %v, want ErrNotAnimated", err)
+	}
+	mf := &MediaFile{Path: path}
+	if isAnimatedFile(mf) {
+		t.Error("single-frame GIF reported as animated")
+	}
+}
+
+func TestGIFDelayFloor(t *testing.T) {
+	info, err := ProbeAnimation(writeTestGIF(t, 3, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Duration != 3*minGIFFrameDelay {
+		t.Errorf("Duration = %v, want %v", info.Duration, 3*minGIFFrameDelay)
+	}
+}
+
+func TestSampleFrames(t *testing.T) {
+	tests := []struct {
+		count, n int
+		want     []int
+	}{
+		{8, 4, []int{1, 3, 5, 7}},
+		{10, 4, []int{1, 3, 6, 8}},
+		{3, 4, []int{0, 1, 2}},
+	}
+	for _, tt := range tests {
+		got := sampleFrames(tt.count, tt.n)
+		if len(got) != len(tt.want) {
+			t.Errorf("sampleFrames(%d, %d) = %v, want %v", tt.count, tt.n, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("sampleFrames(%d, %d) = %v, want %v", tt.count, tt.n, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestParseWebPAnimation(t *testing.T) {
+	le24 := func(v int) []byte { return []byte{byte(v), byte(v >> 8), byte(v >> 16)} }
+	vp8x := append([]byte{1 << 1, 0, 0, 0}, append(le24(99), le24(49)...)...)
+	anmf := func(x, y, w, h, ms int, flags byte) []byte {
+		b := bytes.Join([][]byte{le24(x / 2), le24(y / 2), le24(w - 1), le24(h - 1), le24(ms), {flags}}, nil)
+		return append(b, "VP8L"...)
+	}
+
+	var body []byte
+	body = appendRIFFChunk(body, "VP8X", vp8x)
+	body = appendRIFFChunk(body, "ANIM", make([]byte, 6))
+	body = appendRIFFChunk(body, "ANMF", anmf(0, 0, 100, 50, 120, 0))
+	body = appendRIFFChunk(body, "ANMF", anmf(10, 20, 30, 20, 80, 0x03))
+	data := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(4+len(body)))...)
+	data = append(append(data, "WEBP"...), body...)
+
+	anim, err := parseWebPAnimation(data)
+	if err != nil {
+		t.Fatalf("parseWebPAnimation: %v", err)
+	}
+	info := anim.info()
+	if info.FrameCount != 2 || info.Duration != 200*time.Millisecond || info.Width != 100 || info.Height != 50 {
+		t.Errorf("info = %+v, want 2 frames, 200ms, 100x50", info)
+	}
+	f := anim.frames[1]
+	if f.rect != image.Rect(10, 20, 40, 40) || !f.dispose || !f.noBlend {
+		t.Errorf("frame 1 = %v dispose=%v noBlend=%v", f.rect, f.dispose, f.noBlend)
+	}
+}
@@ -0,0 +1,97 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/image/draw"
+)
+
+// CropAspect is a target aspect ratio for a suggested crop (DDR-130).
+type CropAspect struct {
+	Name   string // "1:1", "4:5", "9:16"
+	Width  int
+	Height int
+}
+
+// CropAspects lists the crops suggested for each photo: square and 4:5 for
+// the feed, 9:16 for stories and reel covers.
+var CropAspects = []CropAspect{
+	{Name: "1:1", Width: 1, Height: 1},
+	{Name: "4:5", Width: 4, Height: 5},
+	{Name: "9:16", Width: 9, Height: 16},
+}
+
+// LookupCropAspect returns the CropAspect with the given name.
+func LookupCropAspect(name string) (CropAspect, bool) {
+	for _, a := range CropAspects {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return CropAspect{}, false
+}
+
+// CropRectForAspect returns the largest rectangle of the given aspect that
+// fits a width x height image, centred on subject and shifted as little as
+// needed to stay inside the image. An empty subject centres the crop.
+// Coordinates are in displayed pixels (EXIF orientation applied).
+func CropRectForAspect(width, height int, subject image.Rectangle, aspect CropAspect) image.Rectangle {
+	if width <= 0 || height <= 0 || aspect.Width <= 0 || aspect.Height <= 0 {
+		return image.Rectangle{}
+	}
+	cropW, cropH := width, height
+	if width*aspect.Height > height*aspect.Width {
+		cropW = height * aspect.Width / aspect.Height
+	} else {
+		cropH = width * aspect.Height / aspect.Width
+	}
+
+	bounds := image.Rect(0, 0, width, height)
+	subject = subject.Intersect(bounds)
+	centre := image.Pt(width/2, height/2)
+	if !subject.Empty() {
+		centre = image.Pt((subject.Min.X+subject.Max.X)/2, (subject.Min.Y+subject.Max.Y)/2)
+	}
+	x := min(max(centre.X-cropW/2, 0), width-cropW)
+	y := min(max(centre.Y-cropH/2, 0), height-cropH)
+	return image.Rect(x, y, x+cropW, y+cropH)
+}
+
+// CropJPEG decodes image data (JPEG EXIF orientation applied), cuts out rect
+// and re-encodes it as a JPEG at the given quality. rect must lie within the
+// displayed image.
+func CropJPEG(data []byte, rect image.Rectangle, quality int) ([]byte, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	if format == "jpeg" {
+		img = orientJPEG(img, data)
+	}
+	b := img.Bounds()
+	rect = rect.Add(b.Min)
+	if rect.Empty() || !rect.In(b) {
+		return nil, fmt.Errorf("crop %v is outside the %dx%d image", rect.Sub(b.Min), b.Dx(), b.Dy())
+	}
+
+	cropped := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(cropped, cropped.Bounds(), img, rect.Min, draw.Src)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, cropped, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("encode image: %w", err)
+	}
+	log.Debug().
+		Str("format", format).
+		Int("orig_width", b.Dx()).
+		Int("orig_height", b.Dy()).
+		Int("crop_width", rect.Dx()).
+		Int("crop_height", rect.Dy()).
+		Int("output_size", buf.Len()).
+		Msg("Image cropped")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,69 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+func TestCropRectForAspect(t *testing.T) {
+	square, _ := LookupCropAspect("1:1")
+	portrait, _ := LookupCropAspect("4:5")
+	story, _ := LookupCropAspect("9:16")
+
+	tests := []struct {
+		name    string
+		w, h    int
+		subject image.Rectangle
+		aspect  CropAspect
+		want    image.Rectangle
+	}{
+		{"square from landscape, no subject", 4000, 3000, image.Rectangle{}, square, image.Rect(500, 0, 3500, 3000)},
+		{"square follows subject", 4000, 3000, image.Rect(200, 1000, 800, 2000), square, image.Rect(0, 0, 3000, 3000)},
+		{"square follows subject on the right", 4000, 3000, image.Rect(3000, 500, 3600, 900), square, image.Rect(1000, 0, 4000, 3000)},
+		{"4:5 from landscape", 4000, 3000, image.Rect(1800, 0, 2200, 3000), portrait, image.Rect(800, 0, 3200, 3000)},
+		{"9:16 from portrait clamps to the right edge", 3000, 4000, image.Rect(2400, 1000, 2900, 1800), story, image.Rect(750, 0, 3000, 4000)},
+		{"4:5 from tall portrait crops height", 900, 1600, image.Rect(0, 0, 900, 200), portrait, image.Rect(0, 0, 900, 1125)},
+		{"subject outside image is ignored", 1000, 1000, image.Rect(2000, 2000, 2100, 2100), portrait, image.Rect(100, 0, 900, 1000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CropRectForAspect(tt.w, tt.h, tt.subject, tt.aspect)
+			if got != tt.want {
+				t.Errorf("CropRectForAspect() = %v, want %v", got, tt.want)
+			}
+			if !got.In(image.Rect(0, 0, tt.w, tt.h)) {
+				t.Errorf("crop %v is outside the image", got)
+			}
+		})
+	}
+}
+
+func TestLookupCropAspect(t *testing.T) {
+	if _, ok := LookupCropAspect("16:9"); ok {
+		t.Error("16:9 should not be a crop aspect")
+	}
+	if a, ok := LookupCropAspect("9:16"); !ok || a.Width != 9 || a.Height != 16 {
+		t.Errorf("LookupCropAspect(9:16) = %+v, %v", a, ok)
+	}
+}
+
+func TestCropJPEG(t *testing.T) {
+	data := testJPEG(t, 400, 300)
+
+	out, err := CropJPEG(data, image.Rect(50, 0, 350, 300), 90)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "jpeg" || cfg.Width != 300 || cfg.Height != 300 {
+		t.Errorf("got %s %dx%d, want jpeg 300x300", format, cfg.Width, cfg.Height)
+	}
+
+	if _, err := CropJPEG(data, image.Rect(200, 0, 500, 300), 90); err == nil {
+		t.Error("expected error for crop outside the image")
+	}
+}
@@ -0,0 +1,111 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif" // register decoders for DHashBytes
+	_ "image/jpeg"
+	_ "image/png"
+	"math/bits"
+	"strconv"
+
+	"golang.org/x/image/draw"
+)
+
+// DHash is a 64-bit difference hash of an image. Resizing, recompression,
+// and small exposure changes barely move it, so burst shots of the same
+// scene land within a few bits of each other. The zero value means "no hash".
+//
+// See DDR-110: Near-Duplicate Detection Before Triage.
+type DHash uint64
+
+// NearDuplicateMaxDistance is the largest Hamming distance between two
+// hashes that still counts as a near-duplicate. Burst frames typically
+// differ by 0–4 bits; different compositions of the same subject by 10+.
+const NearDuplicateMaxDistance = 5
+
+// ComputeDHash hashes img: it shrinks the image to 9x8 grayscale and sets
+// one bit per pixel that is brighter than its right-hand neighbour.
+func ComputeDHash(img image.Image) DHash {
+	small := image.NewGray(image.Rect(0, 0, 9, 8))
+	draw.ApproxBiLinear.Scale(small, small.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	var h uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			h <<= 1
+			if small.GrayAt(x, y).Y > small.GrayAt(x+1, y).Y {
+				h |= 1
+			}
+		}
+	}
+	return DHash(h)
+}
+
+// DHashBytes decodes a JPEG, PNG, or GIF (e.g. a thumbnail) and hashes it.
+func DHashBytes(data []byte) (DHash, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("decode image for dhash: %w", err)
+	}
+	return ComputeDHash(img), nil
+}
+
+// DHashFile hashes an image file on disk via a small thumbnail, so HEIC is
+// supported wherever GenerateThumbnail is.
+func DHashFile(mediaFile *MediaFile) (DHash, error) {
+	data, _, err := GenerateThumbnail(mediaFile, 256)
+	if err != nil {
+		return 0, err
+	}
+	return DHashBytes(data)
+}
+
+// String returns the hash as 16 hex digits, the form stored in DynamoDB.
+func (h DHash) String() string {
+	return fmt.Sprintf("%016x", uint64(h))
+}
+
+// ParseDHash parses the output of DHash.String. An empty string yields 0.
+func ParseDHash(s string) (DHash, error) {
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse dhash %q: %w", s, err)
+	}
+	return DHash(v), nil
+}
+
+// Distance returns the number of differing bits between h and other.
+func (h DHash) Distance(other DHash) int {
+	return bits.OnesCount64(uint64(h ^ other))
+}
+
+// GroupNearDuplicates clusters hashes whose distance is at most maxDistance
+// and returns, for each index, the index of its cluster's representative.
+// The representative is the first member in input order, and every member
+// is within maxDistance of it, so clusters cannot drift through a long
+// chain of small changes. Zero hashes are never grouped.
+func GroupNearDuplicates(hashes []DHash, maxDistance int) []int {
+	rep := make([]int, len(hashes))
+	var reps []int
+	for i, h := range hashes {
+		rep[i] = i
+		if h == 0 {
+			continue
+		}
+		for _, r := range reps {
+			if h.Distance(hashes[r]) <= maxDistance {
+				rep[i] = r
+				break
+			}
+		}
+		if rep[i] == i {
+			reps = append(reps, i)
+		}
+	}
+	return rep
+}
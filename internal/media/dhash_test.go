@@ -0,0 +1,74 @@
+package media
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// gradient returns a w×h image whose brightness rises left to right, or
+// falls when reversed, with a dark band across the upper half.
+func gradient(w, h int, reversed bool, offset uint8) image.Image {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			col := x
+			if reversed {
+				col = w - 1 - x
+			}
+			v := uint8(col*200/w) + offset
+			if y >= h/4 && y < h/2 && x > w/3 && x < w/2 {
+				v = 10
+			}
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	return img
+}
+
+func TestComputeDHashNearDuplicates(t *testing.T) {
+	base := ComputeDHash(gradient(400, 300, false, 0))
+	resized := ComputeDHash(gradient(200, 150, false, 0))
+	brighter := ComputeDHash(gradient(400, 300, false, 20))
+	different := ComputeDHash(gradient(400, 300, true, 0))
+
+	if d := base.Distance(resized); d > NearDuplicateMaxDistance {
+		t.Errorf("resized copy distance = %d, want <= %d", d, NearDuplicateMaxDistance)
+	}
+	if d := base.Distance(brighter); d > NearDuplicateMaxDistance {
+		t.Errorf("brighter copy distance = %d, want <= %d", d, NearDuplicateMaxDistance)
+	}
+	if d := base.Distance(different); d <= NearDuplicateMaxDistance {
+		t.Errorf("different composition distance = %d, want > %d", d, NearDuplicateMaxDistance)
+	}
+}
+
+func TestParseDHashRoundTrip(t *testing.T) {
+	h := DHash(0x0123456789abcdef)
+	got, err := ParseDHash(h.String())
+	if err != nil || got != h {
+		t.Errorf("ParseDHash(%q) = %v, %v", h.String(), got, err)
+	}
+	if got, err := ParseDHash(""); err != nil || got != 0 {
+		t.Errorf("ParseDHash(\"\") = %v, %v, want 0", got, err)
+	}
+}
+
+func TestGroupNearDuplicates(t *testing.T) {
+	hashes := []DHash{
+		0b1111_0000, // 0: representative
+		0b1111_0001, // 1: 1 bit from 0
+		0,           // 2: unknown, never grouped
+		0b1111_0011, // 3: 2 bits from 0
+		0xffff_0000, // 4: far from 0
+		0b1111_1111, // 5: 4 bits from 0; 2 from 3, but 3 is not a representative
+		0,           // 6: unknown
+	}
+	got := GroupNearDuplicates(hashes, 2)
+	want := []int{0, 0, 2, 0, 4, 5, 6}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("GroupNearDuplicates = %v, want %v", got, want)
+		}
+	}
+}
@@ -142,6 +142,9 @@ func ScanDirectoryWithOptions(dirPath string, opts ScanOptions) ([]*MediaFile, e
 		return mediaFiles[i].Path < mediaFiles[j].Path
 	})
 
+	// Fill in capture time zones the file's own metadata could not (DDR-117)
+	ResolveTimeZones(mediaFiles)
+
 	logEvent := log.Info().
 		Int("total_images", len(mediaFiles)).
 		Str("directory", dirPath)
@@ -271,6 +274,9 @@ func ScanDirectoryMediaWithOptions(dirPath string, opts ScanOptions) ([]*MediaFi
 		return mediaFiles[i].Path < mediaFiles[j].Path
 	})
 
+	// Fill in capture time zones the file's own metadata could not (DDR-117)
+	ResolveTimeZones(mediaFiles)
+
 	logEvent := log.Info().
 		Int("total_media", len(mediaFiles)).
 		Int("images", imageCount).
@@ -0,0 +1,512 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// Native HEIF decoding (DDR-116). HEIF is a container: each picture is an
+// "item" whose bytes are located by the iloc box and whose codec is named by
+// its infe item type. iPhone photos code the primary picture as a grid of
+// HEVC tiles, which needs an HEVC decoder (ffmpeg); many HEIF files also
+// carry JPEG-coded pictures — a JPEG primary, a JPEG thumbnail item, or a
+// JPEG preview inside the Exif item — which the standard library decodes.
+// DecodeHEIF returns the largest of those, so thumbnails and Gemini uploads
+// work without external tools whenever the file has one, and has ffmpeg
+// decode the HEVC primary when the file has none.
+
+// ErrHEIFNoJPEG is returned when every picture in the file is coded with a
+// codec that has no pure-Go decoder (HEVC, AV1) and ffmpeg could not decode
+// it either.
+var ErrHEIFNoJPEG = errors.New("heif: no JPEG-coded picture in file")
+
+func init() {
+	for _, brand := range []string{"heic", "heix", "mif1", "msf1"} {
+		image.RegisterFormat("heif", "????ftyp"+brand, decodeHEIFReader, decodeHEIFConfigReader)
+	}
+}
+
+// DecodeHEIF decodes the largest JPEG-coded picture in a HEIF/HEIC file,
+// rotated by the primary item's irot property. A file with no JPEG-coded
+// picture, such as an iPhone HEIC of HEVC tiles, is decoded with ffmpeg.
+func DecodeHEIF(filePath string) (image.Image, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("read HEIF file: %w", err)
+	}
+	img, err := decodeHEIF(data)
+	if !errors.Is(err, ErrHEIFNoJPEG) {
+		return img, err
+	}
+	img, ffErr := decodeHEIFFFmpeg(filePath)
+	if ffErr != nil {
+		return nil, fmt.Errorf("%w; %v", err, ffErr)
+	}
+	return img, nil
+}
+
+// decodeHEIFFFmpeg decodes the primary picture with ffmpeg, which joins the
+// HEVC tile grid and applies irot, and reads it back as PNG.
+func decodeHEIFFFmpeg(filePath string) (image.Image, error) {
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg not found: %w", err)
+	}
+	// ffmpeg -v error -i input.heic -frames:v 1 -f image2pipe -c:v png pipe:1
+	cmd := exec.Command(ffmpegPath,
+		"-v", "error",
+		"-i", filePath,
+		"-frames:v", "1",
+		"-f", "image2pipe",
+		"-c:v", "png",
+		"pipe:1",
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg HEIF decode: %w: %s", err, stderr.String())
+	}
+	img, err := png.Decode(bytes.NewReader(out))
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg HEIF decode: %w", err)
+	}
+	return img, nil
+}
+
+func decodeHEIFReader(r io.Reader) (image.Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return decodeHEIF(data)
+}
+
+func decodeHEIFConfigReader(r io.Reader) (image.Config, error) {
+	img, err := decodeHEIFReader(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{ColorModel: img.ColorModel(), Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}, nil
+}
+
+func decodeHEIF(data []byte) (image.Image, error) {
+	f, err := parseHEIF(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var best image.Image
+	bestRotation := 0
+	consider := func(jpegData []byte, rotation int) {
+		img, err := jpeg.Decode(bytes.NewReader(jpegData))
+		if err != nil {
+			return
+		}
+		if best == nil || img.Bounds().Dx()*img.Bounds().Dy() > best.Bounds().Dx()*best.Bounds().Dy() {
+			best, bestRotation = img, rotation
+		}
+	}
+
+	for id, it := range f.items {
+		thumbOf, isThumb := f.thumbOf[id]
+		switch {
+		case it.typ == "jpeg" && (id == f.primary || isThumb && thumbOf == f.primary):
+			if b, err := f.itemData(id); err == nil {
+				consider(b, f.rotation[id])
+			}
+		case it.typ == "Exif":
+			// The Exif preview is stored unrotated, like the primary's pixels.
+			if b, err := f.itemData(id); err == nil {
+				if preview := exifJPEGPreview(b); preview != nil {
+					consider(preview, f.rotation[f.primary])
+				}
+			}
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("%w (primary is %q)", ErrHEIFNoJPEG, f.items[f.primary].typ)
+	}
+	return rotateCCW(best, bestRotation), nil
+}
+
+// --- Container parsing ---
+
+type heifExtent struct{ offset, length uint64 }
+
+type heifItem struct {
+	typ     string
+	method  uint8 // iloc construction method: 0 = file offset, 1 = idat offset
+	base    uint64
+	extents []heifExtent
+}
+
+type heifFile struct {
+	data     []byte
+	primary  uint32
+	items    map[uint32]*heifItem
+	thumbOf  map[uint32]uint32 // thumbnail item → item it is a thumbnail of
+	rotation map[uint32]int    // item → irot angle in 90° steps, counter-clockwise
+	idat     []byte
+}
+
+// heifBox is one ISO BMFF box: its type and its payload.
+type heifBox struct {
+	typ  string
+	body []byte
+}
+
+// readBoxes splits b into consecutive boxes.
+func readBoxes(b []byte) ([]heifBox, error) {
+	var boxes []heifBox
+	for len(b) > 0 {
+		if len(b) < 8 {
+			return nil, errors.New("heif: truncated box header")
+		}
+		size := uint64(binary.BigEndian.Uint32(b))
+		typ := string(b[4:8])
+		hdr := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(b))
+		case 1:
+			if len(b) < 16 {
+				return nil, errors.New("heif: truncated box header")
+			}
+			size = binary.BigEndian.Uint64(b[8:])
+			hdr = 16
+		}
+		if size < hdr || size > uint64(len(b)) {
+			return nil, fmt.Errorf("heif: bad size %d for box %q", size, typ)
+		}
+		boxes = append(boxes, heifBox{typ: typ, body: b[hdr:size]})
+		b = b[size:]
+	}
+	return boxes, nil
+}
+
+// heifReader reads big-endian fields from a box body, recording the first
+// out-of-range read in err.
+type heifReader struct {
+	b   []byte
+	err error
+}
+
+func (r *heifReader) next(n int) []byte {
+	if r.err != nil || n > len(r.b) {
+		r.err = errors.New("heif: truncated box")
+		return make([]byte, n)
+	}
+	out := r.b[:n]
+	r.b = r.b[n:]
+	return out
+}
+
+func (r *heifReader) u8() uint8   { return r.next(1)[0] }
+func (r *heifReader) u16() uint16 { return binary.BigEndian.Uint16(r.next(2)) }
+func (r *heifReader) u32() uint32 { return binary.BigEndian.Uint32(r.next(4)) }
+
+// uint reads an unsigned integer of n bytes (0, 4 or 8, as used by iloc).
+func (r *heifReader) uint(n int) uint64 {
+	var v uint64
+	for _, c := range r.next(n) {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+// id reads an item ID, 16-bit when wide is false.
+func (r *heifReader) id(wide bool) uint32 {
+	if wide {
+		return r.u32()
+	}
+	return uint32(r.u16())
+}
+
+// fullBox reads the version and flags of a FullBox.
+func (r *heifReader) fullBox() (version uint8, flags uint32) {
+	v := r.u32()
+	return uint8(v >> 24), v & 0xffffff
+}
+
+func parseHEIF(data []byte) (*heifFile, error) {
+	top, err := readBoxes(data)
+	if err != nil {
+		return nil, err
+	}
+	var meta []byte
+	for _, b := range top {
+		if b.typ == "meta" {
+			meta = b.body
+			break
+		}
+	}
+	if len(meta) < 4 {
+		return nil, errors.New("heif: no meta box")
+	}
+	children, err := readBoxes(meta[4:])
+	if err != nil {
+		return nil, err
+	}
+
+	f := &heifFile{
+		data:     data,
+		items:    make(map[uint32]*heifItem),
+		thumbOf:  make(map[uint32]uint32),
+		rotation: make(map[uint32]int),
+	}
+	item := func(id uint32) *heifItem {
+		if f.items[id] == nil {
+			f.items[id] = &heifItem{}
+		}
+		return f.items[id]
+	}
+
+	for _, b := range children {
+		r := &heifReader{b: b.body}
+		switch b.typ {
+		case "pitm":
+			v, _ := r.fullBox()
+			f.primary = r.id(v > 0)
+		case "iinf":
+			v, _ := r.fullBox()
+			r.id(v > 0) // entry count; the infe boxes follow
+			entries, err := readBoxes(r.b)
+			if err != nil {
+				return nil, err
+			}
+			for _, e := range entries {
+				if e.typ != "infe" {
+					continue
+				}
+				er := &heifReader{b: e.body}
+				ev, _ := er.fullBox()
+				if ev < 2 {
+					continue // pre-HEIF infe versions have no item type
+				}
+				id := er.id(ev > 2)
+				er.u16() // protection index
+				typ := string(er.next(4))
+				if er.err == nil {
+					item(id).typ = typ
+				}
+			}
+		case "iloc":
+			if err := f.parseIloc(r, item); err != nil {
+				return nil, err
+			}
+		case "iref":
+			v, _ := r.fullBox()
+			refs, err := readBoxes(r.b)
+			if err != nil {
+				return nil, err
+			}
+			for _, ref := range refs {
+				if ref.typ != "thmb" {
+					continue
+				}
+				rr := &heifReader{b: ref.body}
+				from := rr.id(v > 0)
+				if n := rr.u16(); n > 0 {
+					f.thumbOf[from] = rr.id(v > 0)
+				}
+			}
+		case "iprp":
+			f.parseRotations(b.body)
+		case "idat":
+			f.idat = b.body
+		}
+		if r.err != nil {
+			return nil, fmt.Errorf("heif: parse %s: %w", b.typ, r.err)
+		}
+	}
+	if f.items[f.primary] == nil {
+		return nil, errors.New("heif: primary item not found")
+	}
+	return f, nil
+}
+
+func (f *heifFile) parseIloc(r *heifReader, item func(uint32) *heifItem) error {
+	v, _ := r.fullBox()
+	sizes := r.u16()
+	offsetSize := int(sizes >> 12)
+	lengthSize := int(sizes >> 8 & 0xf)
+	baseSize := int(sizes >> 4 & 0xf)
+	indexSize := 0
+	if v == 1 || v == 2 {
+		indexSize = int(sizes & 0xf)
+	}
+	count := r.id(v == 2)
+	for i := uint32(0); i < count && r.err == nil; i++ {
+		it := item(r.id(v == 2))
+		if v == 1 || v == 2 {
+			it.method = uint8(r.u16() & 0xf)
+		}
+		r.u16() // data reference index
+		it.base = r.uint(baseSize)
+		extents := r.u16()
+		it.extents = it.extents[:0]
+		for e := uint16(0); e < extents && r.err == nil; e++ {
+			r.uint(indexSize)
+			it.extents = append(it.extents, heifExtent{offset: r.uint(offsetSize), length: r.uint(lengthSize)})
+		}
+	}
+	return r.err
+}
+
+// parseRotations records each item's irot property from iprp's ipco
+// (the property list) and ipma (item → property associations).
+func (f *heifFile) parseRotations(iprp []byte) {
+	boxes, err := readBoxes(iprp)
+	if err != nil {
+		return
+	}
+	var props []heifBox
+	for _, b := range boxes {
+		if b.typ == "ipco" {
+			props, _ = readBoxes(b.body)
+		}
+	}
+	for _, b := range boxes {
+		if b.typ != "ipma" {
+			continue
+		}
+		r := &heifReader{b: b.body}
+		v, flags := r.fullBox()
+		count := r.u32()
+		for i := uint32(0); i < count && r.err == nil; i++ {
+			id := r.id(v > 0)
+			n := int(r.u8())
+			for j := 0; j < n && r.err == nil; j++ {
+				var idx int
+				if flags&1 != 0 {
+					idx = int(r.u16() & 0x7fff)
+				} else {
+					idx = int(r.u8() & 0x7f)
+				}
+				if idx > 0 && idx <= len(props) && props[idx-1].typ == "irot" && len(props[idx-1].body) > 0 {
+					f.rotation[id] = int(props[idx-1].body[0] & 3)
+				}
+			}
+		}
+	}
+}
+
+// itemData concatenates an item's extents.
+func (f *heifFile) itemData(id uint32) ([]byte, error) {
+	it := f.items[id]
+	src := f.data
+	switch it.method {
+	case 0:
+	case 1:
+		src = f.idat
+	default:
+		return nil, fmt.Errorf("heif: unsupported construction method %d", it.method)
+	}
+
+	var out []byte
+	for _, e := range it.extents {
+		start := it.base + e.offset
+		end := start + e.length
+		if e.length == 0 {
+			end = uint64(len(src)) // length 0 means "to the end of the source"
+		}
+		if start > end || end > uint64(len(src)) {
+			return nil, fmt.Errorf("heif: item %d extent out of range", id)
+		}
+		out = append(out, src[start:end]...)
+	}
+	return out, nil
+}
+
+// exifJPEGPreview returns the JPEG thumbnail in IFD1 of a HEIF Exif item, or
+// nil if there is none. The item starts with a 4-byte offset to the TIFF header.
+func exifJPEGPreview(item []byte) []byte {
+	if len(item) < 4 {
+		return nil
+	}
+	skip := uint64(binary.BigEndian.Uint32(item)) + 4
+	if skip >= uint64(len(item)) {
+		return nil
+	}
+	tiff := item[skip:]
+	if len(tiff) < 8 {
+		return nil
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil
+	}
+
+	// ifdEntries returns the tag → value map of the IFD at off, and the
+	// offset of the next IFD.
+	ifdEntries := func(off uint32) (map[uint16]uint32, uint32) {
+		if uint64(off)+2 > uint64(len(tiff)) {
+			return nil, 0
+		}
+		n := int(order.Uint16(tiff[off:]))
+		end := uint64(off) + 2 + uint64(n)*12
+		if end+4 > uint64(len(tiff)) {
+			return nil, 0
+		}
+		tags := make(map[uint16]uint32, n)
+		for i := 0; i < n; i++ {
+			e := tiff[uint64(off)+2+uint64(i)*12:]
+			tags[order.Uint16(e)] = order.Uint32(e[8:])
+		}
+		return tags, order.Uint32(tiff[end:])
+	}
+
+	_, ifd1 := ifdEntries(order.Uint32(tiff[4:]))
+	if ifd1 == 0 {
+		return nil
+	}
+	tags, _ := ifdEntries(ifd1)
+	off, n := uint64(tags[0x0201]), uint64(tags[0x0202]) // JPEGInterchangeFormat, …Length
+	if n == 0 || off+n > uint64(len(tiff)) {
+		return nil
+	}
+	return tiff[off : off+n]
+}
+
+// rotateCCW rotates img counter-clockwise by steps × 90°.
+func rotateCCW(img image.Image, steps int) image.Image {
+	steps &= 3
+	if steps == 0 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if steps%2 == 1 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := img.At(b.Min.X+x, b.Min.Y+y)
+			switch steps {
+			case 1:
+				dst.Set(y, w-1-x, c)
+			case 2:
+				dst.Set(w-1-x, h-1-y, c)
+			case 3:
+				dst.Set(h-1-y, x, c)
+			}
+		}
+	}
+	return dst
+}
@@ -0,0 +1,163 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testBox(typ string, parts ...[]byte) []byte {
+	body := bytes.Join(parts, nil)
+	b := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	return append(append(b, typ...), body...)
+}
+
+func be16(v uint16) []byte { return binary.BigEndian.AppendUint16(nil, v) }
+func be32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+
+func testJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// testExifItem builds an Exif item whose IFD1 holds preview.
+func testExifItem(preview []byte) []byte {
+	tiff := []byte("MM\x00\x2a")
+	tiff = append(tiff, be32(8)...)
+	tiff = append(tiff, be16(0)...)  // IFD0: no entries
+	tiff = append(tiff, be32(14)...) // next IFD: IFD1
+	tiff = append(tiff, be16(2)...)
+	tiff = append(tiff, bytes.Join([][]byte{be16(0x0201), be16(4), be32(1), be32(44)}, nil)...)
+	tiff = append(tiff, bytes.Join([][]byte{be16(0x0202), be16(4), be32(1), be32(uint32(len(preview)))}, nil)...)
+	tiff = append(tiff, be32(0)...)
+	return append(append(be32(0), tiff...), preview...)
+}
+
+type testItem struct {
+	typ  string
+	data []byte
+}
+
+// testHEIF builds a HEIF file whose item i+1 is items[i]; item 1 is primary
+// and is rotated rot × 90° counter-clockwise.
+func testHEIF(items []testItem, rot byte) []byte {
+	ftyp := testBox("ftyp", []byte("heic"), be32(0), []byte("mif1heic"))
+	build := func(mdatStart uint32) []byte {
+		iinf := [][]byte{be32(0), be16(uint16(len(items)))}
+		iloc := [][]byte{be32(0), be16(0x4400), be16(uint16(len(items)))}
+		offset := mdatStart
+		for i, it := range items {
+			id := be16(uint16(i + 1))
+			iinf = append(iinf, testBox("infe", be32(2<<24), id, be16(0), []byte(it.typ), []byte{0}))
+			iloc = append(iloc, id, be16(0), be16(1), be32(offset), be32(uint32(len(it.data))))
+			offset += uint32(len(it.data))
+		}
+		iprp := testBox("iprp",
+			testBox("ipco", testBox("irot", []byte{rot})),
+			testBox("ipma", be32(0), be32(1), be16(1), []byte{1, 0x81}))
+		return testBox("meta", be32(0),
+			testBox("pitm", be32(0), be16(1)),
+			testBox("iinf", iinf...),
+			testBox("iloc", iloc...),
+			iprp)
+	}
+	meta := build(0)
+	meta = build(uint32(len(ftyp) + len(meta) + 8))
+
+	var mdat [][]byte
+	for _, it := range items {
+		mdat = append(mdat, it.data)
+	}
+	return bytes.Join([][]byte{ftyp, meta, testBox("mdat", mdat...)}, nil)
+}
+
+func TestDecodeHEIF(t *testing.T) {
+	hevc := []byte("not really hevc")
+	tests := []struct {
+		name    string
+		file    []byte
+		wantW   int
+		wantH   int
+		wantErr error
+	}{
+		{"jpeg primary rotated", testHEIF([]testItem{{"jpeg", testJPEG(t, 64, 32)}}, 1), 32, 64, nil},
+		{"hevc primary with exif preview", testHEIF([]testItem{{"hvc1", hevc}, {"Exif", testExifItem(testJPEG(t, 48, 40))}}, 0), 48, 40, nil},
+		{"hevc only", testHEIF([]testItem{{"hvc1", hevc}}, 0), 0, 0, ErrHEIFNoJPEG},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := decodeHEIF(tt.file)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b := img.Bounds(); b.Dx() != tt.wantW || b.Dy() != tt.wantH {
+				t.Errorf("size = %dx%d, want %dx%d", b.Dx(), b.Dy(), tt.wantW, tt.wantH)
+			}
+		})
+	}
+
+	t.Run("registered with image.Decode", func(t *testing.T) {
+		_, format, err := image.Decode(bytes.NewReader(testHEIF([]testItem{{"jpeg", testJPEG(t, 16, 16)}}, 0)))
+		if err != nil || format != "heif" {
+			t.Errorf("image.Decode format = %q, err = %v", format, err)
+		}
+	})
+}
+
+// TestDecodeHEIFHEVC checks that a HEIC with only HEVC pictures, as iPhones
+// write, is handed to ffmpeg. A stub ffmpeg on PATH prints a PNG.
+func TestDecodeHEIFHEVC(t *testing.T) {
+	dir := t.TempDir()
+	heic := filepath.Join(dir, "IMG_0001.HEIC")
+	if err := os.WriteFile(heic, testHEIF([]testItem{{"hvc1", []byte("hevc tiles")}}, 0), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("without ffmpeg", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+		if _, err := DecodeHEIF(heic); !errors.Is(err, ErrHEIFNoJPEG) {
+			t.Errorf("err = %v, want %v", err, ErrHEIFNoJPEG)
+		}
+	})
+
+	t.Run("with ffmpeg", func(t *testing.T) {
+		bin := t.TempDir()
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 40, 30))); err != nil {
+			t.Fatal(err)
+		}
+		pngPath := filepath.Join(bin, "out.png")
+		if err := os.WriteFile(pngPath, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		script := "#!/bin/sh\ncat '" + pngPath + "'\n"
+		if err := os.WriteFile(filepath.Join(bin, "ffmpeg"), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		img, err := DecodeHEIF(heic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := img.Bounds(); b.Dx() != 40 || b.Dy() != 30 {
+			t.Errorf("size = %dx%d, want 40x30", b.Dx(), b.Dy())
+		}
+	})
+}
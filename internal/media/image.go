@@ -3,6 +3,7 @@ package media
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -27,14 +28,21 @@ type ImageMetadata struct {
 	Longitude float64
 	HasGPS    bool
 
-	// Timestamp (with timezone if available in OffsetTimeOriginal)
+	// Timestamp, local to where the photo was taken (see ZoneSource)
 	DateTaken time.Time
 	HasDate   bool
+	// ZoneSource records how DateTaken's zone was determined (DDR-117);
+	// empty means unknown, and DateTaken is the wall clock labelled UTC.
+	ZoneSource string
 
 	// Camera info
 	CameraMake  string
 	CameraModel string
 
+	// Animation, for animated GIF/WebP (DDR-120); FrameCount is 0 for stills
+	FrameCount        int
+	AnimationDuration time.Duration
+
 	// Raw fields for debugging
 	RawFields map[string]string
 }
@@ -67,6 +75,11 @@ func (m *ImageMetadata) GetDate() time.Time {
 	return m.DateTaken
 }
 
+// IsAnimated returns true for animated GIF/WebP files.
+func (m *ImageMetadata) IsAnimated() bool {
+	return m.FrameCount > 1
+}
+
 // ExtractImageMetadata extracts EXIF metadata from an image file using the imagemeta library.
 //
 // This is a pure Go implementation that supports JPEG, HEIC, HEIF, TIFF, and other formats.
@@ -95,13 +108,25 @@ func ExtractImageMetadata(filePath string) (*ImageMetadata, error) {
 	// Decode metadata using imagemeta
 	// This auto-detects format (JPEG, HEIC, TIFF) from file headers
 	exifData, err := imagemeta.Decode(file)
+	if err != nil && IsRaw(filepath.Ext(filePath)) {
+		// RAW formats imagemeta does not recognise (DDR-119)
+		exifData, err = readRawExif(filePath)
+	}
 	if err != nil {
+		// GIF and WebP rarely carry EXIF, but an animation's frame count and
+		// duration are still worth reporting (DDR-120).
+		metadata := &ImageMetadata{RawFields: make(map[string]string)}
+		addAnimationInfo(metadata, filePath)
+		if metadata.IsAnimated() {
+			return metadata, nil
+		}
 		return nil, fmt.Errorf("failed to decode EXIF metadata: %w", err)
 	}
 
 	metadata := &ImageMetadata{
 		RawFields: make(map[string]string),
 	}
+	addAnimationInfo(metadata, filePath)
 
 	// Extract GPS coordinates
 	// GPS is stored as Rational values (e.g., 40° 44' 55" = [40/1, 44/1, 550404/10000])
@@ -130,6 +155,9 @@ func ExtractImageMetadata(filePath string) (*ImageMetadata, error) {
 		metadata.HasDate = true
 		metadata.RawFields["ModifyDate"] = exifData.ModifyDate().String()
 	}
+	if metadata.HasDate {
+		resolveImageZone(metadata, gps.Date())
+	}
 
 	// Extract camera info
 	metadata.CameraMake = strings.TrimSpace(exifData.Make)
@@ -150,6 +178,25 @@ func ExtractImageMetadata(filePath string) (*ImageMetadata, error) {
 	return metadata, nil
 }
 
+// addAnimationInfo records the frame count and duration of an animated GIF
+// or WebP on m. Other files and stills are left unchanged.
+func addAnimationInfo(m *ImageMetadata, filePath string) {
+	if !IsAnimatable(filepath.Ext(filePath)) {
+		return
+	}
+	info, err := ProbeAnimation(filePath)
+	if err != nil {
+		log.Debug().Err(err).Str("path", filePath).Msg("Could not probe animation")
+		return
+	}
+	if info.FrameCount > 1 {
+		m.FrameCount = info.FrameCount
+		m.AnimationDuration = info.Duration
+		m.RawFields["FrameCount"] = fmt.Sprintf("%d", info.FrameCount)
+		m.RawFields["AnimationDuration"] = info.Duration.String()
+	}
+}
+
 // FormatMetadataContext formats the image metadata as a text block for inclusion in prompts.
 func (m *ImageMetadata) FormatMetadataContext() string {
 	var sb strings.Builder
@@ -168,7 +215,7 @@ func (m *ImageMetadata) FormatMetadataContext() string {
 	if m.HasDate {
 		sb.WriteString("**Date/Time Taken:**\n")
 		sb.WriteString(fmt.Sprintf("- Date: %s\n", m.DateTaken.Format("Monday, January 2, 2006")))
-		sb.WriteString(fmt.Sprintf("- Time: %s\n", m.DateTaken.Format("3:04 PM")))
+		sb.WriteString(fmt.Sprintf("- Time: %s\n", formatClock(m)))
 		sb.WriteString(fmt.Sprintf("- Day of Week: %s\n\n", m.DateTaken.Weekday().String()))
 	} else {
 		sb.WriteString("**Date/Time Taken:** Not available in image metadata\n\n")
@@ -178,5 +225,9 @@ func (m *ImageMetadata) FormatMetadataContext() string {
 		sb.WriteString(fmt.Sprintf("**Camera:** %s %s\n\n", m.CameraMake, m.CameraModel))
 	}
 
+	if m.IsAnimated() {
+		sb.WriteString(fmt.Sprintf("**Animation:** %d frames, %.1fs\n\n", m.FrameCount, m.AnimationDuration.Seconds()))
+	}
+
 	return sb.String()
 }
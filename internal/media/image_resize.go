@@ -23,8 +23,11 @@ import (
 //
 // Returns the resized image bytes, MIME type, and error.
 // Returns nil, "", nil when no resize is needed (image already small enough,
-// format not supported for resize, or ffmpeg unavailable for HEIC).
+// format not supported for resize, or HEIC that needs ffmpeg when it is unavailable).
 // The caller checks for nil bytes and falls back to the original file.
+// RAW files always return the resized embedded preview or an error, since
+// Gemini cannot read the original (DDR-119). Animated GIF/WebP return a frame
+// sheet (DDR-120).
 func ResizeImageForGemini(mediaFile *MediaFile, maxDimension int, quality int) ([]byte, string, error) {
 	ext := strings.ToLower(filepath.Ext(mediaFile.Path))
 
@@ -39,17 +42,48 @@ func ResizeImageForGemini(mediaFile *MediaFile, maxDimension int, quality int) (
 		if IsFFmpegAvailable() {
 			return resizeWithFFmpegWebP(mediaFile.Path, ext, maxDimension, quality)
 		}
-		log.Debug().Str("path", mediaFile.Path).Msg("ffmpeg not available, skipping HEIC resize")
-		return nil, "", nil
+		return resizeHEIFNative(mediaFile.Path, maxDimension, quality)
 
 	case ".gif", ".webp":
+		if isAnimatedFile(mediaFile) {
+			// Gemini sees a frame sheet instead of only the first frame (DDR-120)
+			data, err := AnimationFrameSheet(mediaFile.Path, maxDimension, quality)
+			if err != nil {
+				return nil, "", err
+			}
+			return data, "image/jpeg", nil
+		}
 		return nil, "", nil
 
 	default:
+		if IsRaw(ext) {
+			return resizeRAWPreview(mediaFile.Path, maxDimension, quality)
+		}
 		return nil, "", nil
 	}
 }
 
+// resizeRAWPreview downscales a RAW file's embedded JPEG preview to JPEG.
+// Previews are rarely larger than the Gemini target, so there is nothing to
+// gain from ffmpeg's WebP encoding.
+func resizeRAWPreview(filePath string, maxDimension, jpegQuality int) ([]byte, string, error) {
+	img, err := DecodeRAWPreview(filePath)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := encodeJPEGThumbnail(img, maxDimension, jpegQuality)
+	if err != nil {
+		return nil, "", err
+	}
+	log.Debug().
+		Str("path", filePath).
+		Int("preview_width", img.Bounds().Dx()).
+		Int("preview_height", img.Bounds().Dy()).
+		Int("output_size", len(data)).
+		Msg("RAW preview converted to JPEG for Gemini")
+	return data, "image/jpeg", nil
+}
+
 // resizeWithFFmpegWebP uses ffmpeg to resize and convert any supported image
 // to WebP. Handles JPEG, PNG, and HEIC/HEIF input uniformly.
 // WebP is ~30-40% smaller than JPEG at equivalent quality and encodes in ~300ms.
@@ -123,6 +157,34 @@ func imageExceedsDimension(filePath, _ string, maxDimension int) (bool, error) {
 	return cfg.Width > maxDimension || cfg.Height > maxDimension, nil
 }
 
+// resizeHEIFNative is the pure-Go fallback for HEIC/HEIF (DDR-116). It uses a
+// JPEG-coded picture from the container only when it is at least
+// maxDimension on its longer side; a smaller preview would lose detail
+// compared with uploading the original, which Gemini accepts as-is.
+func resizeHEIFNative(filePath string, maxDimension, jpegQuality int) ([]byte, string, error) {
+	img, err := DecodeHEIF(filePath)
+	if err != nil {
+		log.Debug().Err(err).Str("path", filePath).Msg("ffmpeg not available and no native HEIC decode, skipping HEIC resize")
+		return nil, "", nil
+	}
+	if maxSide(img) < maxDimension {
+		log.Debug().Str("path", filePath).Int("preview_size", maxSide(img)).Msg("HEIC JPEG preview too small, skipping HEIC resize")
+		return nil, "", nil
+	}
+
+	data, err := encodeJPEGThumbnail(img, maxDimension, jpegQuality)
+	if err != nil {
+		return nil, "", err
+	}
+	log.Debug().
+		Str("path", filePath).
+		Int("orig_width", img.Bounds().Dx()).
+		Int("orig_height", img.Bounds().Dy()).
+		Int("output_size", len(data)).
+		Msg("HEIC converted to JPEG for Gemini (native HEIF)")
+	return data, "image/jpeg", nil
+}
+
 // resizeJPEGPNG is the pure-Go fallback when ffmpeg is unavailable.
 // Outputs JPEG since WebP encoding requires CGO (DDR-027).
 func resizeJPEGPNG(filePath, ext string, maxDimension, jpegQuality int) ([]byte, string, error) {
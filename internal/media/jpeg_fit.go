@@ -0,0 +1,143 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+
+	"github.com/evanoberholster/imagemeta"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/image/draw"
+)
+
+// JPEGFitOptions constrains FitJPEG's output. Zero fields are unconstrained.
+type JPEGFitOptions struct {
+	// MaxWidth scales the image down to at most this width.
+	MaxWidth int
+	// MinAspect and MaxAspect bound width/height; images outside the range
+	// are padded, never cropped, so nothing the user framed is lost.
+	MinAspect float64
+	MaxAspect float64
+	// MaxBytes is the largest acceptable encoded size.
+	MaxBytes int
+	// Background fills the padding. The zero value is opaque white.
+	Background color.Color
+}
+
+// fitJPEGQualities are tried in order before FitJPEG gives up resolution.
+var fitJPEGQualities = []int{92, 85, 78, 70, 60}
+
+// FitJPEG re-encodes image data (any decodable format, JPEG EXIF orientation
+// applied) as a JPEG within opts: padded into the aspect range, scaled to
+// MaxWidth, then encoded at decreasing quality and, if that is not enough,
+// decreasing size until it fits MaxBytes. Used to make photos publishable
+// on platforms with strict upload rules (DDR-129).
+func FitJPEG(data []byte, opts JPEGFitOptions) ([]byte, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	if format == "jpeg" {
+		img = orientJPEG(img, data)
+	}
+	srcBounds := img.Bounds()
+
+	bg := opts.Background
+	if bg == nil {
+		bg = color.White
+	}
+	img = padToAspect(img, opts.MinAspect, opts.MaxAspect, bg)
+
+	width := img.Bounds().Dx()
+	if opts.MaxWidth > 0 {
+		width = min(width, opts.MaxWidth)
+	}
+	for attempt := 0; attempt < 4; attempt++ {
+		scaled := scaleToWidth(img, width)
+		for _, quality := range fitJPEGQualities {
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: quality}); err != nil {
+				return nil, fmt.Errorf("encode image: %w", err)
+			}
+			if opts.MaxBytes == 0 || buf.Len() <= opts.MaxBytes {
+				log.Debug().
+					Str("format", format).
+					Int("orig_width", srcBounds.Dx()).
+					Int("orig_height", srcBounds.Dy()).
+					Int("new_width", scaled.Bounds().Dx()).
+					Int("new_height", scaled.Bounds().Dy()).
+					Int("quality", quality).
+					Int("output_size", buf.Len()).
+					Msg("Image fitted to JPEG constraints")
+				return buf.Bytes(), nil
+			}
+		}
+		width = width * 3 / 4
+	}
+	return nil, fmt.Errorf("cannot encode %dx%d image under %d bytes", srcBounds.Dx(), srcBounds.Dy(), opts.MaxBytes)
+}
+
+// OrientedImageConfig returns an image's format and its dimensions as
+// displayed, i.e. swapped when a JPEG's EXIF orientation rotates it by 90°.
+func OrientedImageConfig(data []byte) (image.Config, string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return image.Config{}, "", fmt.Errorf("decode image config: %w", err)
+	}
+	if format == "jpeg" {
+		if exifData, err := imagemeta.Decode(bytes.NewReader(data)); err == nil {
+			switch exifData.Orientation {
+			case meta.OrientationRotate90, meta.OrientationRotate270:
+				cfg.Width, cfg.Height = cfg.Height, cfg.Width
+			}
+		}
+	}
+	return cfg, format, nil
+}
+
+// orientJPEG applies the EXIF orientation of a JPEG, which image.Decode
+// ignores. Mirrored orientations are left as-is, as in DecodeRAWPreview.
+func orientJPEG(img image.Image, data []byte) image.Image {
+	exifData, err := imagemeta.Decode(bytes.NewReader(data))
+	if err != nil {
+		return img
+	}
+	switch exifData.Orientation {
+	case meta.OrientationRotate180:
+		return rotateCCW(img, 2)
+	case meta.OrientationRotate90:
+		return rotateCCW(img, 3)
+	case meta.OrientationRotate270:
+		return rotateCCW(img, 1)
+	}
+	return img
+}
+
+// padToAspect centres img on a bg canvas whose aspect ratio is the nearest
+// one within [minAspect, maxAspect]. The canvas is always drawn, so
+// transparent PNG pixels come out as bg rather than JPEG black.
+func padToAspect(img image.Image, minAspect, maxAspect float64, bg color.Color) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return img
+	}
+	aspect := float64(w) / float64(h)
+	canvasW, canvasH := w, h
+	switch {
+	case minAspect > 0 && aspect < minAspect:
+		canvasW = int(math.Ceil(float64(h) * minAspect))
+	case maxAspect > 0 && aspect > maxAspect:
+		canvasH = int(math.Ceil(float64(w) / maxAspect))
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, canvasW, canvasH))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	offset := image.Pt((canvasW-w)/2, (canvasH-h)/2)
+	draw.Draw(canvas, image.Rectangle{Min: offset, Max: offset.Add(b.Size())}, img, b.Min, draw.Over)
+	return canvas
+}
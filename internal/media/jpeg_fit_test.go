@@ -0,0 +1,87 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand"
+	"testing"
+)
+
+func TestFitJPEG_PadsToAspectRange(t *testing.T) {
+	tests := []struct {
+		name         string
+		w, h         int
+		wantW, wantH int
+	}{
+		{"tall portrait padded to 4:5", 900, 1600, 1280, 1600},
+		{"panorama padded to 1.91:1", 1910, 500, 1910, 1000},
+		{"in range unchanged", 1000, 1000, 1000, 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := FitJPEG(testJPEG(t, tt.w, tt.h), JPEGFitOptions{MinAspect: 0.8, MaxAspect: 1.91})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg, format, err := image.DecodeConfig(bytes.NewReader(out))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format != "jpeg" || cfg.Width != tt.wantW || cfg.Height != tt.wantH {
+				t.Errorf("got %s %dx%d, want jpeg %dx%d", format, cfg.Width, cfg.Height, tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestFitJPEG_ConvertsPNGAndLimitsWidth(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 2000, 1500)) // fully transparent
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := FitJPEG(buf.Bytes(), JPEGFitOptions{MaxWidth: 1440})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, format, err := image.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "jpeg" || img.Bounds().Dx() != 1440 || img.Bounds().Dy() != 1080 {
+		t.Errorf("got %s %v, want jpeg 1440x1080", format, img.Bounds())
+	}
+	// Transparent pixels become the white background, not black.
+	if r, _, _, _ := img.At(10, 10).RGBA(); r < 0xf000 {
+		t.Errorf("transparent pixel decoded as %v, want white", img.At(10, 10))
+	}
+}
+
+func TestFitJPEG_MeetsByteLimit(t *testing.T) {
+	// Noise does not compress, so only lower quality and size get it under.
+	src := image.NewRGBA(image.Rect(0, 0, 800, 800))
+	rng := rand.New(rand.NewSource(1))
+	for i := range src.Pix {
+		src.Pix[i] = byte(rng.Intn(256))
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+
+	const limit = 100 * 1024
+	out, err := FitJPEG(buf.Bytes(), JPEGFitOptions{MaxBytes: limit, Background: color.Black})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) > limit {
+		t.Errorf("output %d bytes exceeds limit %d", len(out), limit)
+	}
+
+	if _, err := FitJPEG(buf.Bytes(), JPEGFitOptions{MaxBytes: 100}); err == nil {
+		t.Error("expected an error for an unreachable byte limit")
+	}
+}
@@ -0,0 +1,282 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+// Legibility checks (DDR-104) estimate whether text in an image survives
+// Instagram's resize to 1080px and its aggressive JPEG recompression. They
+// are heuristic: text is found by edge structure, not OCR.
+
+const (
+	// instagramWidth and instagramJPEGQuality approximate what Instagram
+	// serves in the feed.
+	instagramWidth       = 1080
+	instagramJPEGQuality = 70
+
+	legibilityBlockSize = 16
+	// textEdgeThreshold is the luma step (0-1) that counts as a glyph edge.
+	textEdgeThreshold = 0.1
+	// minTextBlockRun is how many adjacent text-like blocks form a text line;
+	// isolated busy blocks are usually foliage or gravel, not text.
+	minTextBlockRun = 3
+	// minPhotoTextCoverage is the fraction of text blocks a photo needs before
+	// it is checked; screenshots are checked whenever any text is found.
+	minPhotoTextCoverage = 0.01
+
+	// minContrastRatio is the WCAG AA ratio for large text.
+	minContrastRatio = 3.0
+	// minTextHeightPx is the smallest legible line height at 1080px: the feed
+	// shows the image about 390pt wide, so 24px renders at roughly 9pt.
+	minTextHeightPx = 24
+)
+
+// Legibility warning codes.
+const (
+	LegibilityLowContrast = "low-contrast"
+	LegibilitySmallText   = "small-text"
+)
+
+// LegibilityWarning is one problem found by CheckLegibility.
+type LegibilityWarning struct {
+	Code    string
+	Message string
+}
+
+// LegibilityReport is the result of CheckLegibility. ContrastRatio and
+// TextHeight are medians over detected text and are zero when no text was
+// checked.
+type LegibilityReport struct {
+	Screenshot    bool
+	TextCoverage  float64
+	ContrastRatio float64
+	TextHeight    int
+	Warnings      []LegibilityWarning
+}
+
+// IsLikelyScreenshot reports whether an image is a screenshot or graphic
+// rather than a camera photo, from its filename and decoded format. PNG is
+// rare from cameras and common for screenshots and designed graphics.
+func IsLikelyScreenshot(filename, format string) bool {
+	name := strings.ToLower(filepath.Base(filename))
+	for _, marker := range []string{"screenshot", "screen shot", "screen_shot", "screen-shot", "simulator screen"} {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return format == "png"
+}
+
+// CheckLegibility finds text-like regions in an image and reports text that
+// is low contrast or too small once the image is resized and recompressed the
+// way Instagram does. Photos with only incidental text are skipped (no
+// warnings) to avoid flagging busy textures.
+func CheckLegibility(data []byte, screenshot bool) (*LegibilityReport, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	report := &LegibilityReport{Screenshot: screenshot}
+
+	scaled := scaleToWidth(src, instagramWidth)
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: instagramJPEGQuality}); err != nil {
+		return nil, fmt.Errorf("encode preview: %w", err)
+	}
+	compressed, err := jpeg.Decode(&buf)
+	if err != nil {
+		return nil, fmt.Errorf("decode preview: %w", err)
+	}
+
+	// Text is located on the clean resize so compression noise does not
+	// create or hide it, then measured on what viewers actually see.
+	before := newLumaPlane(scaled)
+	after := newLumaPlane(compressed)
+	mask, textBlocks, totalBlocks := before.textBlocks()
+	if textBlocks == 0 {
+		return report, nil
+	}
+	report.TextCoverage = float64(textBlocks) / float64(totalBlocks)
+	if !screenshot && report.TextCoverage < minPhotoTextCoverage {
+		return report, nil
+	}
+
+	report.ContrastRatio = after.medianContrast(mask)
+	if report.ContrastRatio < minContrastRatio {
+		report.Warnings = append(report.Warnings, LegibilityWarning{
+			Code:    LegibilityLowContrast,
+			Message: fmt.Sprintf("Text contrast is about %.1f:1 after Instagram compression; aim for at least %.0f:1.", report.ContrastRatio, minContrastRatio),
+		})
+	}
+	report.TextHeight = before.medianLineHeight(mask)
+	if report.TextHeight > 0 && report.TextHeight < minTextHeightPx {
+		report.Warnings = append(report.Warnings, LegibilityWarning{
+			Code:    LegibilitySmallText,
+			Message: fmt.Sprintf("Text is about %dpx tall at Instagram's %dpx width and will be hard to read on a phone.", report.TextHeight, instagramWidth),
+		})
+	}
+	return report, nil
+}
+
+// scaleToWidth downscales img to width, or copies it unchanged if narrower.
+func scaleToWidth(img image.Image, width int) *image.RGBA {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > width {
+		h = max(h*width/w, 1)
+		w = width
+	}
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(out, out.Bounds(), img, b, draw.Src, nil)
+	return out
+}
+
+// lumaPlane holds gamma-encoded Rec. 601 luma in [0,1].
+type lumaPlane struct {
+	w, h int
+	pix  []float64
+}
+
+func newLumaPlane(img image.Image) *lumaPlane {
+	b := img.Bounds()
+	p := &lumaPlane{w: b.Dx(), h: b.Dy(), pix: make([]float64, b.Dx()*b.Dy())}
+	for y := 0; y < p.h; y++ {
+		for x := 0; x < p.w; x++ {
+			r, g, bl, _ := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			p.pix[y*p.w+x] = (0.299*float64(r) + 0.587*float64(g) + 0.114*float64(bl)) / 0xffff
+		}
+	}
+	return p
+}
+
+func (p *lumaPlane) at(x, y int) float64 { return p.pix[y*p.w+x] }
+
+func (p *lumaPlane) isEdge(x, y int) bool {
+	v := p.at(x, y)
+	return (x+1 < p.w && math.Abs(p.at(x+1, y)-v) > textEdgeThreshold) ||
+		(y+1 < p.h && math.Abs(p.at(x, y+1)-v) > textEdgeThreshold)
+}
+
+// textBlocks marks blocks whose edge density looks like glyphs and that sit
+// in a horizontal run of at least minTextBlockRun such blocks. The mask is
+// indexed [blockRow][blockCol].
+func (p *lumaPlane) textBlocks() ([][]bool, int, int) {
+	cols, rows := p.w/legibilityBlockSize, p.h/legibilityBlockSize
+	mask := make([][]bool, rows)
+	count := 0
+	for by := 0; by < rows; by++ {
+		busy := make([]bool, cols)
+		for bx := 0; bx < cols; bx++ {
+			edges := 0
+			for y := by * legibilityBlockSize; y < (by+1)*legibilityBlockSize; y++ {
+				for x := bx * legibilityBlockSize; x < (bx+1)*legibilityBlockSize; x++ {
+					if p.isEdge(x, y) {
+						edges++
+					}
+				}
+			}
+			density := float64(edges) / (legibilityBlockSize * legibilityBlockSize)
+			busy[bx] = density >= 0.08 && density <= 0.6
+		}
+
+		mask[by] = make([]bool, cols)
+		for start := 0; start < cols; {
+			end := start
+			for end < cols && busy[end] {
+				end++
+			}
+			if end-start >= minTextBlockRun {
+				for bx := start; bx < end; bx++ {
+					mask[by][bx] = true
+				}
+				count += end - start
+			}
+			start = end + 1
+		}
+	}
+	return mask, count, max(cols*rows, 1)
+}
+
+// medianContrast is the median WCAG contrast ratio between the dark and
+// light ends (5th and 95th percentile) of each text block.
+func (p *lumaPlane) medianContrast(mask [][]bool) float64 {
+	var ratios []float64
+	vals := make([]float64, 0, legibilityBlockSize*legibilityBlockSize)
+	for by, row := range mask {
+		for bx, text := range row {
+			if !text {
+				continue
+			}
+			vals = vals[:0]
+			for y := by * legibilityBlockSize; y < (by+1)*legibilityBlockSize; y++ {
+				for x := bx * legibilityBlockSize; x < (bx+1)*legibilityBlockSize; x++ {
+					vals = append(vals, p.at(x, y))
+				}
+			}
+			sort.Float64s(vals)
+			dark := relativeLuminance(vals[len(vals)*5/100])
+			light := relativeLuminance(vals[len(vals)*95/100])
+			ratios = append(ratios, (light+0.05)/(dark+0.05))
+		}
+	}
+	return medianFloat(ratios)
+}
+
+// medianLineHeight estimates text size as the median height of runs of
+// consecutive pixel rows that contain glyph edges inside text blocks.
+func (p *lumaPlane) medianLineHeight(mask [][]bool) int {
+	var runs []float64
+	run := 0
+	for y := 0; y < len(mask)*legibilityBlockSize; y++ {
+		row := mask[y/legibilityBlockSize]
+		span, edges := 0, 0
+		for bx, text := range row {
+			if !text {
+				continue
+			}
+			span += legibilityBlockSize
+			for x := bx * legibilityBlockSize; x < (bx+1)*legibilityBlockSize; x++ {
+				if p.isEdge(x, y) {
+					edges++
+				}
+			}
+		}
+		if span > 0 && edges*50 >= span {
+			run++
+			continue
+		}
+		if run > 1 {
+			runs = append(runs, float64(run))
+		}
+		run = 0
+	}
+	if run > 1 {
+		runs = append(runs, float64(run))
+	}
+	return int(medianFloat(runs))
+}
+
+// relativeLuminance linearizes a gamma-encoded value per WCAG 2.x.
+func relativeLuminance(v float64) float64 {
+	if v <= 0.03928 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func medianFloat(vals []float64) float64 {
+	if len(vals) == 0 {
+		return 0
+	}
+	sort.Float64s(vals)
+	return vals[len(vals)/2]
+}
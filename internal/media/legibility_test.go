@@ -0,0 +1,117 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"testing"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// textImage renders lines of text in fg on bg, scaled up by scale, and
+// returns it PNG-encoded.
+func textImage(t *testing.T, width, height, scale int, fg, bg color.Color) []byte {
+	t.Helper()
+	face := basicfont.Face7x13
+	lineHeight := face.Metrics().Height.Ceil() + 4
+	small := image.NewRGBA(image.Rect(0, 0, width/scale, height/scale))
+	draw.Draw(small, small.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	d := &font.Drawer{Dst: small, Src: image.NewUniform(fg), Face: face}
+	for y := lineHeight; y < small.Bounds().Dy()-4; y += lineHeight {
+		d.Dot = fixed.P(4, y)
+		d.DrawString("The quick brown fox jumps over the lazy dog 0123456789 THE QUICK BROWN FOX")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.NearestNeighbor.Scale(img, img.Bounds(), small, small.Bounds(), draw.Src, nil)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func warningCodes(r *LegibilityReport) map[string]bool {
+	codes := make(map[string]bool)
+	for _, w := range r.Warnings {
+		codes[w.Code] = true
+	}
+	return codes
+}
+
+func TestCheckLegibility(t *testing.T) {
+	black := color.RGBA{A: 0xff}
+	white := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	lightGray := color.RGBA{R: 0xc8, G: 0xc8, B: 0xc8, A: 0xff}
+
+	tests := []struct {
+		name  string
+		data  []byte
+		codes []string
+	}{
+		{"large dark text", textImage(t, 1080, 1080, 3, black, white), nil},
+		{"light gray text", textImage(t, 1080, 1080, 3, lightGray, white), []string{LegibilityLowContrast}},
+		{"small text after resize", textImage(t, 3240, 3240, 2, black, white), []string{LegibilitySmallText}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := CheckLegibility(tt.data, true)
+			if err != nil {
+				t.Fatalf("CheckLegibility: %v", err)
+			}
+			t.Logf("coverage=%.3f contrast=%.2f height=%d", report.TextCoverage, report.ContrastRatio, report.TextHeight)
+			if report.TextCoverage == 0 {
+				t.Fatal("no text detected")
+			}
+			got := warningCodes(report)
+			if len(got) != len(tt.codes) {
+				t.Errorf("warnings = %+v, want codes %v", report.Warnings, tt.codes)
+			}
+			for _, code := range tt.codes {
+				if !got[code] {
+					t.Errorf("missing %s warning: %+v", code, report.Warnings)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckLegibilityIgnoresPlainPhoto(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1600, 1200))
+	for y := 0; y < 1200; y++ {
+		for x := 0; x < 1600; x++ {
+			v := uint8(128 + 100*math.Sin(float64(x)/200)*math.Cos(float64(y)/150))
+			img.Set(x, y, color.RGBA{R: v, G: v / 2, B: 255 - v, A: 0xff})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := CheckLegibility(buf.Bytes(), false)
+	if err != nil {
+		t.Fatalf("CheckLegibility: %v", err)
+	}
+	if report.TextCoverage != 0 || len(report.Warnings) != 0 {
+		t.Errorf("plain photo reported text: %+v", report)
+	}
+}
+
+func TestIsLikelyScreenshot(t *testing.T) {
+	if !IsLikelyScreenshot("sess/Screenshot 2026-10-01 at 10.00.00.jpg", "jpeg") {
+		t.Error("screenshot filename not detected")
+	}
+	if !IsLikelyScreenshot("sess/graphic.png", "png") {
+		t.Error("PNG not treated as screenshot")
+	}
+	if IsLikelyScreenshot("sess/IMG_1234.HEIC", "heic") {
+		t.Error("camera photo treated as screenshot")
+	}
+}
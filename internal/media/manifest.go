@@ -0,0 +1,82 @@
+package media
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Cloud triage never downloads the media it judges; it reads what
+// media-process extracted from the file-processing manifest (DDR-061).
+// ManifestFields and MetadataFromManifest are the two ends of that hand-off.
+
+// ManifestFields returns the metadata media-process stores with a file's
+// result.
+func ManifestFields(m MediaMetadata) map[string]string {
+	fields := make(map[string]string)
+	if m == nil {
+		return fields
+	}
+	fields["mediaType"] = m.GetMediaType()
+	if m.HasGPSData() {
+		lat, lon := m.GetGPS()
+		fields["gpsLat"] = fmt.Sprintf("%.6f", lat)
+		fields["gpsLon"] = fmt.Sprintf("%.6f", lon)
+	}
+	if m.HasDateData() {
+		fields["date"] = m.GetDate().Format(time.RFC3339)
+		if src := zoneSource(m); src != "" {
+			fields["zoneSource"] = src
+		}
+	}
+	if im, ok := m.(*ImageMetadata); ok && im.IsAnimated() {
+		fields["frameCount"] = strconv.Itoa(im.FrameCount)
+		fields["animationMs"] = strconv.FormatInt(im.AnimationDuration.Milliseconds(), 10)
+	}
+	return fields
+}
+
+// MetadataFromManifest rebuilds from a file result's stored fields the
+// metadata triage reads: the capture date and how its zone was found
+// (DDR-117), GPS, and the frame count and duration of an animated GIF or
+// WebP (DDR-120). It returns nil when the fields hold no date or animation.
+func MetadataFromManifest(fields map[string]string) MediaMetadata {
+	date, dateErr := time.Parse(time.RFC3339, fields["date"])
+	hasDate := dateErr == nil
+	lat, latErr := strconv.ParseFloat(fields["gpsLat"], 64)
+	lon, lonErr := strconv.ParseFloat(fields["gpsLon"], 64)
+	hasGPS := latErr == nil && lonErr == nil
+
+	switch fields["mediaType"] {
+	case "image":
+		frames, _ := strconv.Atoi(fields["frameCount"])
+		if !hasDate && frames < 2 {
+			return nil
+		}
+		ms, _ := strconv.ParseInt(fields["animationMs"], 10, 64)
+		return &ImageMetadata{
+			Latitude:          lat,
+			Longitude:         lon,
+			HasGPS:            hasGPS,
+			DateTaken:         date,
+			HasDate:           hasDate,
+			ZoneSource:        fields["zoneSource"],
+			FrameCount:        frames,
+			AnimationDuration: time.Duration(ms) * time.Millisecond,
+			RawFields:         fields,
+		}
+	case "video":
+		if !hasDate {
+			return nil
+		}
+		return &VideoMetadata{
+			Latitude:   lat,
+			Longitude:  lon,
+			HasGPS:     hasGPS,
+			CreateDate: date,
+			HasDate:    true,
+			ZoneSource: fields["zoneSource"],
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package media
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManifestFieldsCarryAnimation(t *testing.T) {
+	anim := &ImageMetadata{FrameCount: 8, AnimationDuration: 2 * time.Second}
+	got, ok := MetadataFromManifest(ManifestFields(anim)).(*ImageMetadata)
+	if !ok || got.FrameCount != 8 || got.AnimationDuration != 2*time.Second {
+		t.Fatalf("MetadataFromManifest = %+v, want 8 frames, 2s", got)
+	}
+
+	still := &ImageMetadata{HasDate: false}
+	if m := MetadataFromManifest(ManifestFields(still)); m != nil {
+		t.Errorf("MetadataFromManifest(undated still) = %+v, want nil", m)
+	}
+	if m := MetadataFromManifest(nil); m != nil {
+		t.Errorf("MetadataFromManifest(nil) = %+v, want nil", m)
+	}
+}
+
+func TestManifestFieldsLetTriageResolveZones(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*3600)
+	zoned := &ImageMetadata{HasDate: true, DateTaken: time.Date(2026, 6, 1, 12, 0, 0, 0, tokyo), ZoneSource: ZoneFromOffset}
+	unzoned := &VideoMetadata{HasDate: true, CreateDate: time.Date(2026, 6, 1, 4, 30, 0, 0, time.UTC)}
+
+	files := []*MediaFile{
+		{Path: "a.jpg", Metadata: MetadataFromManifest(ManifestFields(zoned))},
+		{Path: "b.mp4", Metadata: MetadataFromManifest(ManifestFields(unzoned))},
+	}
+	if n := ResolveTimeZones(files); n != 1 {
+		t.Fatalf("ResolveTimeZones = %d, want 1", n)
+	}
+	got := files[1].Metadata.(*VideoMetadata)
+	if _, offset := got.CreateDate.Zone(); offset != 9*3600 || got.ZoneSource != ZoneFromSession {
+		t.Errorf("video date = %v (%s), want +09:00 from session", got.CreateDate, got.ZoneSource)
+	}
+}
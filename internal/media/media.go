@@ -70,7 +70,11 @@ type MediaFile struct {
 	MIMEType     string
 	Size         int64
 	Metadata     MediaMetadata
-	PresignedURL string // S3 presigned GET URL; when set, Gemini fetches directly (DDR-060)
+	PresignedURL string       // S3 presigned GET URL; when set, Gemini fetches directly (DDR-060)
+	DHash        DHash        // precomputed perceptual hash; zero if unknown (DDR-110)
+	QualityIssue string       // precomputed CheckQuality reason; empty if none or unknown (DDR-112)
+	Sample       *VideoSample // precomputed long-video sample; triage sends it in place of the video (DDR-162)
+	ContentHash  string       // hex SHA-256 of the original bytes; empty if unknown, which disables response caching (DDR-166)
 }
 
 // LoadMediaFile loads a media file from disk and returns a MediaFile struct.
@@ -150,6 +154,14 @@ func GetMIMEType(ext string) (string, error) {
 		return mimeType, nil
 	}
 
+	if mimeType, ok := SupportedRawExtensions[ext]; ok {
+		log.Trace().
+			Str("extension", ext).
+			Str("mime_type", mimeType).
+			Msg("Resolved MIME type")
+		return mimeType, nil
+	}
+
 	if mimeType, ok := SupportedVideoExtensions[ext]; ok {
 		log.Trace().
 			Str("extension", ext).
@@ -161,10 +173,11 @@ func GetMIMEType(ext string) (string, error) {
 	return "", fmt.Errorf("unsupported file extension: %s", ext)
 }
 
-// IsImage returns true if the file extension corresponds to an image.
+// IsImage returns true if the file extension corresponds to an image,
+// including RAW camera formats (see IsRaw).
 func IsImage(ext string) bool {
 	_, ok := SupportedImageExtensions[strings.ToLower(ext)]
-	return ok
+	return ok || IsRaw(ext)
 }
 
 // IsVideo returns true if the file extension corresponds to a video.
@@ -0,0 +1,330 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// MetadataPolicy says what embedded metadata survives when a photo leaves
+// the system in a download bundle or an Instagram upload (DDR-135).
+type MetadataPolicy string
+
+const (
+	// MetadataStripGPS removes the EXIF GPS block and the XMP packet, which
+	// can repeat the location. Camera, exposure and date tags are kept.
+	MetadataStripGPS MetadataPolicy = "strip-gps"
+	// MetadataStripAll removes EXIF, XMP, IPTC and comments from JPEG and
+	// PNG. Orientation is kept so the photo still displays upright.
+	MetadataStripAll MetadataPolicy = "strip-all"
+	// MetadataPreserve leaves files untouched.
+	MetadataPreserve MetadataPolicy = "preserve"
+
+	// DefaultMetadataPolicy applies to sessions that never chose one.
+	DefaultMetadataPolicy = MetadataStripGPS
+)
+
+// ParseMetadataPolicy validates a policy name; "" means the default.
+func ParseMetadataPolicy(s string) (MetadataPolicy, error) {
+	switch p := MetadataPolicy(s); p {
+	case "":
+		return DefaultMetadataPolicy, nil
+	case MetadataStripGPS, MetadataStripAll, MetadataPreserve:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown metadata policy %q (want %s, %s or %s)", s, MetadataStripGPS, MetadataStripAll, MetadataPreserve)
+}
+
+// SessionReader reads a session record.
+type SessionReader interface {
+	GetSession(ctx context.Context, sessionID string) (*store.Session, error)
+}
+
+// SessionMetadataPolicy returns the session's metadata policy, or the
+// default when it is unset, invalid or cannot be read.
+func SessionMetadataPolicy(ctx context.Context, sessions SessionReader, sessionID string) MetadataPolicy {
+	session, err := sessions.GetSession(ctx, sessionID)
+	if err != nil || session == nil {
+		log.Warn().Err(err).Str("sessionId", sessionID).Msg("Session not readable — using the default metadata policy")
+		return DefaultMetadataPolicy
+	}
+	policy, err := ParseMetadataPolicy(session.MetadataPolicy)
+	if err != nil {
+		log.Warn().Err(err).Str("sessionId", sessionID).Msg("Stored metadata policy is invalid — using the default")
+		return DefaultMetadataPolicy
+	}
+	return policy
+}
+
+// SanitizeMetadata applies policy to an image file without re-encoding its
+// pixels. Provenance packets written by EmbedProvenance are kept under every
+// policy (DDR-140). JPEG and PNG are fully supported. HEIF and TIFF-based RAW files
+// (DNG, CR2, NEF, ARW) have their GPS block blanked in place under either
+// strip policy, since removing their other metadata would mean rewriting the
+// container. Other formats are returned unchanged. The input is never
+// modified.
+func SanitizeMetadata(data []byte, policy MetadataPolicy) ([]byte, error) {
+	if policy == MetadataPreserve || len(data) < 8 {
+		return data, nil
+	}
+	out := bytes.Clone(data)
+	switch {
+	case bytes.HasPrefix(out, []byte{0xFF, 0xD8}):
+		return sanitizeJPEG(out, policy)
+	case bytes.HasPrefix(out, pngSignature):
+		return sanitizePNG(out, policy)
+	case bytes.Equal(out[4:8], []byte("ftyp")):
+		return sanitizeHEIF(out)
+	case bytes.HasPrefix(out, []byte("II*\x00")), bytes.HasPrefix(out, []byte("MM\x00*")):
+		blankGPS(out)
+		return out, nil
+	}
+	return data, nil
+}
+
+// --- JPEG ---
+
+var (
+	exifHeader    = []byte("Exif\x00\x00")
+	xmpHeader     = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	xmpExtHeader  = []byte("http://ns.adobe.com/xmp/extension/\x00")
+	xmpKeyword    = []byte("XML:com.adobe.xmp\x00") // PNG iTXt
+	pngSignature  = []byte("\x89PNG\r\n\x1a\n")
+	pngTextChunks = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+)
+
+func sanitizeJPEG(data []byte, policy MetadataPolicy) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+
+	i := 2
+	for {
+		if i+4 > len(data) || data[i] != 0xFF {
+			return nil, fmt.Errorf("sanitize JPEG: malformed segment at offset %d", i)
+		}
+		marker := data[i+1]
+		if marker == 0xDA { // start of scan: the rest is entropy-coded data
+			break
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) {
+			return nil, fmt.Errorf("sanitize JPEG: segment at offset %d overruns file", i)
+		}
+		seg, payload := data[i:end], data[i+4:end]
+
+		keep := true
+		switch {
+		case marker == 0xE1 && bytes.HasPrefix(payload, exifHeader):
+			if policy == MetadataStripAll {
+				if o := exifOrientation(payload[len(exifHeader):]); o != 1 {
+					out = append(out, orientationSegment(o)...)
+				}
+				keep = false
+			} else {
+				blankGPS(payload[len(exifHeader):])
+			}
+		case marker == 0xE1 && (bytes.HasPrefix(payload, xmpHeader) || bytes.HasPrefix(payload, xmpExtHeader)):
+			// Provenance records are kept; one merged into another tool's
+			// packet is kept without it (DDR-140).
+			keep = false
+			if packet, ok := bytes.CutPrefix(payload, xmpHeader); ok && isProvenancePacket(packet) {
+				record, err := xmpSegment(provenanceOnly(packet))
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, record...)
+			}
+		case marker == 0xED, marker == 0xFE: // IPTC/Photoshop, comment
+			keep = policy != MetadataStripAll
+		}
+		if keep {
+			out = append(out, seg...)
+		}
+		i = end
+	}
+	return append(out, data[i:]...), nil
+}
+
+// orientationSegment builds an APP1 Exif segment holding only the
+// Orientation tag.
+func orientationSegment(orientation uint16) []byte {
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8, 0, 1}
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3 /* SHORT */)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0) // value padding, next IFD = none
+
+	seg := []byte{0xFF, 0xE1}
+	seg = binary.BigEndian.AppendUint16(seg, uint16(2+len(exifHeader)+len(tiff)))
+	seg = append(seg, exifHeader...)
+	return append(seg, tiff...)
+}
+
+// --- PNG ---
+
+func sanitizePNG(data []byte, policy MetadataPolicy) ([]byte, error) {
+	out := append(make([]byte, 0, len(data)), pngSignature...)
+	for i := len(pngSignature); i < len(data); {
+		if i+12 > len(data) {
+			return nil, fmt.Errorf("sanitize PNG: truncated chunk at offset %d", i)
+		}
+		n := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + n
+		if end > len(data) {
+			return nil, fmt.Errorf("sanitize PNG: chunk at offset %d overruns file", i)
+		}
+		typ, body := string(data[i+4:i+8]), data[i+8:i+8+n]
+
+		keep := true
+		switch {
+		case typ == "iTXt" && isProvenancePacket(body): // DDR-140
+			if packet := itxtXMP(body); packet != nil {
+				out = append(out, xmpChunk(provenanceOnly(packet))...)
+				keep = false
+			}
+		case policy == MetadataStripAll:
+			keep = !pngTextChunks[typ]
+		case typ == "eXIf":
+			if blankGPS(body) {
+				binary.BigEndian.PutUint32(data[i+8+n:], crc32.ChecksumIEEE(data[i+4:i+8+n]))
+			}
+		case typ == "iTXt" && bytes.HasPrefix(body, xmpKeyword):
+			keep = false
+		}
+		if keep {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return out, nil
+}
+
+// --- HEIF ---
+
+// sanitizeHEIF blanks the GPS block of the Exif item in place. Items split
+// across several extents are left alone.
+func sanitizeHEIF(data []byte) ([]byte, error) {
+	f, err := parseHEIF(data)
+	if err != nil {
+		return nil, fmt.Errorf("sanitize HEIF: %w", err)
+	}
+	for id, it := range f.items {
+		if it.typ != "Exif" || len(it.extents) != 1 {
+			continue
+		}
+		item, err := f.itemData(id)
+		if err != nil || len(item) < 4 {
+			continue
+		}
+		// itemData copies; locate the same bytes in the file to edit them.
+		src := f.data
+		if it.method == 1 {
+			src = f.idat
+		}
+		start := it.base + it.extents[0].offset
+		if start+uint64(len(item)) > uint64(len(src)) {
+			continue
+		}
+		item = src[start : start+uint64(len(item))]
+		skip := uint64(binary.BigEndian.Uint32(item)) + 4
+		if skip < uint64(len(item)) {
+			blankGPS(item[skip:])
+		}
+	}
+	return data, nil
+}
+
+// --- TIFF / EXIF ---
+
+// tiffTypeSizes is the byte size of one value of each TIFF field type.
+var tiffTypeSizes = map[uint16]uint64{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+// tiffOrder returns the byte order of a TIFF header, or nil.
+func tiffOrder(tiff []byte) binary.ByteOrder {
+	if len(tiff) < 8 {
+		return nil
+	}
+	switch string(tiff[:2]) {
+	case "II":
+		return binary.LittleEndian
+	case "MM":
+		return binary.BigEndian
+	}
+	return nil
+}
+
+// ifd0Tag returns the value field of a tag in IFD0 and its offset in tiff.
+func ifd0Tag(tiff []byte, order binary.ByteOrder, tag uint16) (value uint32, at uint64, ok bool) {
+	off := uint64(order.Uint32(tiff[4:]))
+	if off+2 > uint64(len(tiff)) {
+		return 0, 0, false
+	}
+	n := uint64(order.Uint16(tiff[off:]))
+	for i := uint64(0); i < n; i++ {
+		e := off + 2 + i*12
+		if e+12 > uint64(len(tiff)) {
+			return 0, 0, false
+		}
+		if order.Uint16(tiff[e:]) == tag {
+			return order.Uint32(tiff[e+8:]), e, true
+		}
+	}
+	return 0, 0, false
+}
+
+// blankGPS zeroes the GPS IFD of an EXIF TIFF structure in place — its
+// entries and the values they point to — and leaves it as an empty IFD, so
+// every other offset in the file stays valid. It reports whether a GPS IFD
+// was found.
+func blankGPS(tiff []byte) bool {
+	order := tiffOrder(tiff)
+	if order == nil {
+		return false
+	}
+	gps, _, ok := ifd0Tag(tiff, order, 0x8825) // GPSInfo
+	if !ok || uint64(gps)+2 > uint64(len(tiff)) {
+		return false
+	}
+	off := uint64(gps)
+	n := uint64(order.Uint16(tiff[off:]))
+	end := off + 2 + n*12 + 4
+	if end > uint64(len(tiff)) {
+		return false
+	}
+	for i := uint64(0); i < n; i++ {
+		e := off + 2 + i*12
+		size := tiffTypeSizes[order.Uint16(tiff[e+2:])] * uint64(order.Uint32(tiff[e+4:]))
+		if size > 4 {
+			if v := uint64(order.Uint32(tiff[e+8:])); v+size <= uint64(len(tiff)) {
+				clear(tiff[v : v+size])
+			}
+		}
+	}
+	clear(tiff[off:end])
+	log.Debug().Uint64("entries", n).Msg("EXIF GPS block blanked")
+	return true
+}
+
+// exifOrientation returns the Orientation tag of an EXIF TIFF structure, or
+// 1 (upright) when it has none.
+func exifOrientation(tiff []byte) uint16 {
+	order := tiffOrder(tiff)
+	if order == nil {
+		return 1
+	}
+	_, at, ok := ifd0Tag(tiff, order, 0x0112)
+	if !ok {
+		return 1
+	}
+	if o := order.Uint16(tiff[at+8:]); o >= 1 && o <= 8 {
+		return o
+	}
+	return 1
+}
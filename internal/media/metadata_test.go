@@ -0,0 +1,226 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta"
+
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// testExifGPS builds a big-endian EXIF TIFF structure with an Orientation
+// of 6 and a GPS IFD holding latitude 40° 44' 55" N.
+func testExifGPS() []byte {
+	tiff := []byte("MM\x00\x2a")
+	tiff = append(tiff, be32(8)...)
+	tiff = append(tiff, be16(2)...) // IFD0 at 8
+	tiff = append(tiff, be16(0x0112)...)
+	tiff = append(tiff, be16(3)...)
+	tiff = append(tiff, be32(1)...)
+	tiff = append(tiff, be16(6)...)
+	tiff = append(tiff, be16(0)...)
+	tiff = append(tiff, be16(0x8825)...)
+	tiff = append(tiff, be16(4)...)
+	tiff = append(tiff, be32(1)...)
+	tiff = append(tiff, be32(38)...) // GPS IFD
+	tiff = append(tiff, be32(0)...)  // no IFD1
+	tiff = append(tiff, be16(2)...)  // GPS IFD at 38
+	tiff = append(tiff, be16(1)...)  // GPSLatitudeRef "N"
+	tiff = append(tiff, be16(2)...)
+	tiff = append(tiff, be32(2)...)
+	tiff = append(tiff, 'N', 0, 0, 0)
+	tiff = append(tiff, be16(2)...) // GPSLatitude, 3 rationals at 68
+	tiff = append(tiff, be16(5)...)
+	tiff = append(tiff, be32(3)...)
+	tiff = append(tiff, be32(68)...)
+	tiff = append(tiff, be32(0)...)
+	for _, v := range []uint32{40, 1, 44, 1, 55, 1} {
+		tiff = append(tiff, be32(v)...)
+	}
+	return tiff
+}
+
+// testJPEGWithMetadata inserts an EXIF segment with GPS, an XMP packet and a
+// comment after the SOI marker of a plain JPEG.
+func testJPEGWithMetadata(t *testing.T) []byte {
+	t.Helper()
+	segment := func(marker byte, payload []byte) []byte {
+		return append(append([]byte{0xFF, marker}, be16(uint16(len(payload)+2))...), payload...)
+	}
+	plain := testJPEG(t, 16, 8)
+	out := append([]byte(nil), plain[:2]...)
+	out = append(out, segment(0xE1, append([]byte("Exif\x00\x00"), testExifGPS()...))...)
+	out = append(out, segment(0xE1, append(append([]byte(nil), xmpHeader...), "<x:xmpmeta exif:GPSLatitude=\"40,44.9N\"/>"...))...)
+	out = append(out, segment(0xFE, []byte("shot at home"))...)
+	return append(out, plain[2:]...)
+}
+
+func TestSanitizeMetadataJPEG(t *testing.T) {
+	data := testJPEGWithMetadata(t)
+	before, err := imagemeta.Decode(bytes.NewReader(data))
+	if err != nil || before.GPS.Latitude() == 0 {
+		t.Fatalf("test JPEG has no GPS to strip (err %v)", err)
+	}
+
+	tests := []struct {
+		policy      MetadataPolicy
+		wantComment bool
+	}{
+		{MetadataStripGPS, true},
+		{MetadataStripAll, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			out, err := SanitizeMetadata(data, tt.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := image.Decode(bytes.NewReader(out)); err != nil {
+				t.Fatalf("sanitized JPEG does not decode: %v", err)
+			}
+			if bytes.Contains(out, []byte("GPSLatitude")) {
+				t.Error("XMP location survived")
+			}
+			if got := bytes.Contains(out, []byte("shot at home")); got != tt.wantComment {
+				t.Errorf("comment kept = %v, want %v", got, tt.wantComment)
+			}
+			after, err := imagemeta.Decode(bytes.NewReader(out))
+			if err != nil {
+				t.Fatalf("decode EXIF: %v", err)
+			}
+			if after.GPS.Latitude() != 0 {
+				t.Errorf("latitude = %v, want 0", after.GPS.Latitude())
+			}
+			if after.Orientation != 6 {
+				t.Errorf("orientation = %v, want 6", after.Orientation)
+			}
+		})
+	}
+
+	if out, _ := SanitizeMetadata(data, MetadataPreserve); !bytes.Equal(out, data) {
+		t.Error("preserve changed the file")
+	}
+	if !bytes.Equal(data, testJPEGWithMetadata(t)) {
+		t.Error("SanitizeMetadata modified its input")
+	}
+}
+
+func TestSanitizeMetadataPNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	plain := buf.Bytes()
+	exif := testExifGPS()
+	chunk := append(be32(uint32(len(exif))), "eXIf"...)
+	chunk = append(chunk, exif...)
+	chunk = append(chunk, 0, 0, 0, 0) // placeholder CRC; strip-gps rewrites it
+	// Insert after IHDR (8-byte signature + 25-byte chunk).
+	data := append(append(append([]byte(nil), plain[:33]...), chunk...), plain[33:]...)
+
+	gps, err := SanitizeMetadata(data, MetadataStripGPS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gps) != len(data) || bytes.Contains(gps, gpsLatitude) {
+		t.Error("strip-gps left the GPS values in the eXIf chunk")
+	}
+	if _, err := png.Decode(bytes.NewReader(gps)); err != nil {
+		t.Errorf("strip-gps output does not decode: %v", err)
+	}
+
+	all, err := SanitizeMetadata(data, MetadataStripAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(all, plain) {
+		t.Error("strip-all kept the eXIf chunk")
+	}
+}
+
+func TestParseMetadataPolicy(t *testing.T) {
+	if p, err := ParseMetadataPolicy(""); err != nil || p != DefaultMetadataPolicy {
+		t.Errorf(`ParseMetadataPolicy("") = %q, %v`, p, err)
+	}
+	if _, err := ParseMetadataPolicy("strip-everything"); err == nil {
+		t.Error("unknown policy accepted")
+	}
+}
+
+// gpsLatitude is the GPSLatitude value testExifGPS stores: 40/1 44/1.
+var gpsLatitude = []byte{0, 0, 0, 40, 0, 0, 0, 1, 0, 0, 0, 44}
+
+func TestSanitizeMetadataHEIF(t *testing.T) {
+	exif := append(be32(0), testExifGPS()...)
+	data := testHEIF([]testItem{{"hvc1", []byte("hevc tiles")}, {"Exif", exif}}, 0)
+
+	for _, policy := range []MetadataPolicy{MetadataStripGPS, MetadataStripAll} {
+		out, err := SanitizeMetadata(data, policy)
+		if err != nil {
+			t.Fatalf("%s: %v", policy, err)
+		}
+		if len(out) != len(data) || bytes.Contains(out, gpsLatitude) {
+			t.Errorf("%s left the GPS values in the Exif item", policy)
+		}
+		if !bytes.Contains(out, []byte("hevc tiles")) {
+			t.Errorf("%s changed the picture data", policy)
+		}
+	}
+	if !bytes.Contains(data, gpsLatitude) {
+		t.Error("SanitizeMetadata modified its input")
+	}
+
+	if _, err := SanitizeMetadata(testBox("ftyp", []byte("heic"), be32(0)), MetadataStripGPS); err == nil {
+		t.Error("HEIF without a meta box sanitized without error")
+	}
+}
+
+func TestSanitizeMetadataTIFF(t *testing.T) {
+	data := testExifGPS() // a TIFF-based RAW file starts with the same header
+	out, err := SanitizeMetadata(data, MetadataStripGPS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != len(data) || bytes.Contains(out, gpsLatitude) || bytes.Contains(out, []byte{'N', 0, 0, 0}) {
+		t.Error("strip-gps left the GPS values in the TIFF")
+	}
+	if got := exifOrientation(out); got != 6 {
+		t.Errorf("orientation = %d, want 6", got)
+	}
+	if !bytes.Contains(data, gpsLatitude) {
+		t.Error("SanitizeMetadata modified its input")
+	}
+}
+
+type fakeSessionReader struct {
+	session *store.Session
+	err     error
+}
+
+func (f fakeSessionReader) GetSession(context.Context, string) (*store.Session, error) {
+	return f.session, f.err
+}
+
+func TestSessionMetadataPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		sessions fakeSessionReader
+		want     MetadataPolicy
+	}{
+		{"chosen", fakeSessionReader{session: &store.Session{MetadataPolicy: "strip-all"}}, MetadataStripAll},
+		{"unset", fakeSessionReader{session: &store.Session{}}, DefaultMetadataPolicy},
+		{"invalid", fakeSessionReader{session: &store.Session{MetadataPolicy: "bogus"}}, DefaultMetadataPolicy},
+		{"missing", fakeSessionReader{}, DefaultMetadataPolicy},
+		{"unreadable", fakeSessionReader{err: errors.New("throttled")}, DefaultMetadataPolicy},
+	}
+	for _, tt := range tests {
+		if got := SessionMetadataPolicy(context.Background(), tt.sessions, "s"); got != tt.want {
+			t.Errorf("%s: SessionMetadataPolicy = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/image/draw"
+)
+
+// Overlay positions for a user watermark (DDR-133).
+const (
+	OverlayBottomRight = "bottom-right"
+	OverlayBottomLeft  = "bottom-left"
+	OverlayTopRight    = "top-right"
+	OverlayTopLeft     = "top-left"
+	OverlayCenter      = "center"
+)
+
+const (
+	// DefaultOverlayOpacity applies when Overlay.Opacity is zero.
+	DefaultOverlayOpacity = 0.7
+	// DefaultOverlayScale applies when Overlay.Scale is zero: the mark is a
+	// fifth of the image's shorter side wide.
+	DefaultOverlayScale = 0.2
+	// MaxOverlayLogoBytes caps the PNG logo so it fits in a DynamoDB item.
+	MaxOverlayLogoBytes = 256 * 1024
+	// MaxOverlayTextLength caps a text watermark.
+	MaxOverlayTextLength = 60
+
+	// overlayMarginRatio is the gap between the mark and the image edge,
+	// relative to the shorter side.
+	overlayMarginRatio = 0.03
+)
+
+// Overlay is a photographer's watermark: a PNG logo or a line of text
+// stamped in a corner or the centre of a photo.
+type Overlay struct {
+	// Text is drawn with a dark outline. Ignored when Logo is set.
+	Text string
+	// TextColor fills the text; nil means white (DDR-149).
+	TextColor color.Color
+	// Logo is a PNG; its alpha channel is kept.
+	Logo []byte
+	// Position is one of the Overlay* constants; empty means bottom-right.
+	Position string
+	// Opacity is 0–1; zero means DefaultOverlayOpacity.
+	Opacity float64
+	// Scale is the mark's width relative to the image's shorter side, 0–1;
+	// zero means DefaultOverlayScale.
+	Scale float64
+}
+
+// Validate reports whether the overlay can be applied.
+func (o Overlay) Validate() error {
+	if o.Text == "" && len(o.Logo) == 0 {
+		return errors.New("watermark needs text or a logo")
+	}
+	if len(o.Text) > MaxOverlayTextLength {
+		return fmt.Errorf("watermark text is longer than %d characters", MaxOverlayTextLength)
+	}
+	if len(o.Logo) > MaxOverlayLogoBytes {
+		return fmt.Errorf("watermark logo is larger than %d KB", MaxOverlayLogoBytes/1024)
+	}
+	if len(o.Logo) > 0 {
+		if _, err := png.DecodeConfig(bytes.NewReader(o.Logo)); err != nil {
+			return fmt.Errorf("watermark logo is not a PNG: %w", err)
+		}
+	}
+	switch o.Position {
+	case "", OverlayBottomRight, OverlayBottomLeft, OverlayTopRight, OverlayTopLeft, OverlayCenter:
+	default:
+		return fmt.Errorf("unknown watermark position %q", o.Position)
+	}
+	if o.Opacity < 0 || o.Opacity > 1 {
+		return errors.New("watermark opacity must be between 0 and 1")
+	}
+	if o.Scale < 0 || o.Scale > 1 {
+		return errors.New("watermark scale must be between 0 and 1")
+	}
+	return nil
+}
+
+// ApplyOverlay stamps the overlay on image data (JPEG EXIF orientation
+// applied) and returns the result as a JPEG at the given quality.
+func ApplyOverlay(data []byte, o Overlay, quality int) ([]byte, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	if format == "jpeg" {
+		src = orientJPEG(src, data)
+	}
+	b := src.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(out, out.Bounds(), src, b.Min, draw.Src)
+
+	var mark image.Image
+	interp := draw.Interpolator(draw.CatmullRom)
+	if len(o.Logo) > 0 {
+		if mark, err = png.Decode(bytes.NewReader(o.Logo)); err != nil {
+			return nil, fmt.Errorf("decode watermark logo: %w", err)
+		}
+	} else {
+		// Keep the bitmap font's hard edges when scaling it up.
+		fill := o.TextColor
+		if fill == nil {
+			fill = color.White
+		}
+		mark = renderLabel(o.Text, true, fill)
+		interp = draw.NearestNeighbor
+	}
+
+	rect := overlayRect(out.Bounds().Size(), mark.Bounds().Size(), o)
+	scaled := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	interp.Scale(scaled, scaled.Bounds(), mark, mark.Bounds(), draw.Src, nil)
+
+	opacity := o.Opacity
+	if opacity == 0 {
+		opacity = DefaultOverlayOpacity
+	}
+	alpha := image.NewUniform(color.Alpha{A: uint8(opacity * 0xff)})
+	draw.DrawMask(out, rect, scaled, image.Point{}, alpha, image.Point{}, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, out, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("encode image: %w", err)
+	}
+	log.Debug().
+		Str("position", o.Position).
+		Bool("logo", len(o.Logo) > 0).
+		Int("width", b.Dx()).
+		Int("height", b.Dy()).
+		Int("output_size", buf.Len()).
+		Msg("Watermark applied")
+	return buf.Bytes(), nil
+}
+
+// overlayRect places a mark of the given native size on an image: scaled to
+// the overlay's width (never wider than the image minus margins), with its
+// aspect ratio kept, at the overlay's position.
+func overlayRect(img, mark image.Point, o Overlay) image.Rectangle {
+	scale := o.Scale
+	if scale == 0 {
+		scale = DefaultOverlayScale
+	}
+	short := min(img.X, img.Y)
+	margin := int(float64(short) * overlayMarginRatio)
+
+	w := max(int(float64(short)*scale), 1)
+	w = min(w, max(img.X-2*margin, 1))
+	h := max(w*mark.Y/max(mark.X, 1), 1)
+	if h > img.Y-2*margin {
+		h = max(img.Y-2*margin, 1)
+		w = max(h*mark.X/max(mark.Y, 1), 1)
+	}
+
+	var x, y int
+	switch o.Position {
+	case OverlayTopLeft:
+		x, y = margin, margin
+	case OverlayTopRight:
+		x, y = img.X-margin-w, margin
+	case OverlayBottomLeft:
+		x, y = margin, img.Y-margin-h
+	case OverlayCenter:
+		x, y = (img.X-w)/2, (img.Y-h)/2
+	default:
+		x, y = img.X-margin-w, img.Y-margin-h
+	}
+	return image.Rect(x, y, x+w, y+h)
+}
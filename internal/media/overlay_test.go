@@ -0,0 +1,103 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestOverlayRect(t *testing.T) {
+	tests := []struct {
+		name string
+		img  image.Point
+		mark image.Point
+		o    Overlay
+		want image.Rectangle
+	}{
+		{"default bottom-right", image.Pt(4000, 3000), image.Pt(200, 100), Overlay{}, image.Rect(3310, 2610, 3910, 2910)},
+		{"top-left at scale", image.Pt(1000, 1000), image.Pt(100, 50), Overlay{Position: OverlayTopLeft, Scale: 0.5}, image.Rect(30, 30, 530, 280)},
+		{"center", image.Pt(1000, 500), image.Pt(10, 10), Overlay{Position: OverlayCenter}, image.Rect(450, 200, 550, 300)},
+		{"tall mark limited by height", image.Pt(1000, 100), image.Pt(10, 100), Overlay{Position: OverlayTopRight, Scale: 1}, image.Rect(988, 3, 997, 97)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := overlayRect(tt.img, tt.mark, tt.o)
+			if got != tt.want {
+				t.Errorf("overlayRect() = %v, want %v", got, tt.want)
+			}
+			if !got.In(image.Rect(0, 0, tt.img.X, tt.img.Y)) {
+				t.Errorf("mark %v is outside the image", got)
+			}
+		})
+	}
+}
+
+func TestApplyOverlay(t *testing.T) {
+	data := testJPEG(t, 400, 300)
+
+	logo := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 20; x++ {
+			logo.Set(x, y, color.White)
+		}
+	}
+	var logoPNG bytes.Buffer
+	if err := png.Encode(&logoPNG, logo); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, o := range []Overlay{
+		{Logo: logoPNG.Bytes(), Opacity: 1},
+		{Text: "© Jane Doe", Position: OverlayTopLeft},
+	} {
+		out, err := ApplyOverlay(data, o, 90)
+		if err != nil {
+			t.Fatalf("ApplyOverlay(%+v): %v", o.Position, err)
+		}
+		img, format, err := image.Decode(bytes.NewReader(out))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if format != "jpeg" || img.Bounds().Dx() != 400 || img.Bounds().Dy() != 300 {
+			t.Fatalf("got %s %v, want jpeg 400x300", format, img.Bounds())
+		}
+		rect := overlayRect(image.Pt(400, 300), image.Pt(20, 10), o)
+		if o.Text != "" {
+			rect = overlayRect(image.Pt(400, 300), renderLabel(o.Text, true, color.White).Bounds().Size(), o)
+		}
+		var bright bool
+		for y := rect.Min.Y; y < rect.Max.Y && !bright; y++ {
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				if r, _, _, _ := img.At(x, y).RGBA(); r>>8 > 0xa0 {
+					bright = true
+					break
+				}
+			}
+		}
+		if !bright {
+			t.Errorf("no watermark pixels inside %v", rect)
+		}
+		if r, _, _, _ := img.At(200, 150).RGBA(); o.Position != OverlayCenter && r>>8 > 0x10 {
+			t.Errorf("centre pixel changed: %#x", r>>8)
+		}
+	}
+}
+
+func TestOverlayValidate(t *testing.T) {
+	bad := []Overlay{
+		{},
+		{Text: "x", Position: "middle"},
+		{Text: "x", Opacity: 1.5},
+		{Logo: []byte("not a png")},
+	}
+	for _, o := range bad {
+		if err := o.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", o)
+		}
+	}
+	if err := (Overlay{Text: "© Jane"}).Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
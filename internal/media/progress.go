@@ -0,0 +1,91 @@
+package media
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- Transcode progress (DDR-161) ---
+
+// ProgressFunc receives the percent complete (0-100) of a running video
+// transcode of inputPath. It is called from the goroutine reading ffmpeg's
+// output, once per whole percent, and with 100 when ffmpeg reports the end.
+type ProgressFunc func(inputPath string, percent int)
+
+type progressKey struct{}
+
+// WithProgress returns ctx reporting the progress of the transcodes run
+// with it, such as CompressVideoForGemini, to fn. Progress is reported only
+// for videos whose duration is known.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+func progressFrom(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
+
+// progressArgs makes ffmpeg write machine-readable progress to stdout and
+// drop its interactive stats line from stderr. They go before the input.
+var progressArgs = []string{"-progress", "pipe:1", "-nostats"}
+
+// runWithProgress runs cmd, which must have been built with progressArgs,
+// reporting its progress against total. It returns ffmpeg's stderr as
+// CombinedOutput would for error messages.
+func runWithProgress(cmd *exec.Cmd, total time.Duration, report func(percent int)) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	parseFFmpegProgress(stdout, total, report)
+	err = cmd.Wait()
+	return stderr.Bytes(), err
+}
+
+// parseFFmpegProgress reads ffmpeg -progress output, blocks of key=value
+// lines each ending in "progress=continue" or "progress=end", and reports
+// out_time as a percent of total. The percent stays below 100 until ffmpeg
+// reports the end, and never goes backwards.
+func parseFFmpegProgress(r io.Reader, total time.Duration, report func(percent int)) {
+	last := -1
+	emit := func(p int) {
+		if p > last {
+			last = p
+			report(p)
+		}
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "out_time_us":
+			us, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || us < 0 || total <= 0 {
+				continue
+			}
+			emit(min(int(time.Duration(us)*time.Microsecond*100/total), 99))
+		case "progress":
+			if value == "end" {
+				emit(100)
+			}
+		}
+	}
+	// Drain the rest so ffmpeg never blocks on a full pipe.
+	io.Copy(io.Discard, r)
+}
@@ -0,0 +1,26 @@
+package media
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFFmpegProgress(t *testing.T) {
+	out := strings.Join([]string{
+		"frame=10", "out_time_us=N/A", "progress=continue",
+		"out_time_us=2500000", "out_time=00:00:02.500000", "progress=continue",
+		"out_time_us=2600000", "progress=continue",
+		"out_time_us=1000000", "progress=continue", // never goes backwards
+		"out_time_us=7500000", "progress=continue",
+		"out_time_us=10400000", "progress=continue", // past the probed duration
+		"out_time_us=10400000", "progress=end",
+	}, "\n")
+
+	var got []int
+	parseFFmpegProgress(strings.NewReader(out), 10*time.Second, func(p int) { got = append(got, p) })
+	if want := []int{25, 26, 75, 99, 100}; !slices.Equal(got, want) {
+		t.Errorf("progress = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,376 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"hash/crc32"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+// ProvenanceTool names this application in provenance records (DDR-140).
+const ProvenanceTool = "AI Social Media Helper"
+
+// IPTC digital source type for media edited with generative AI. Platforms
+// read it from XMP to label posts as AI-modified.
+const digitalSourceAIComposite = "http://cv.iptc.org/newscodes/digitalsourcetype/compositeWithTrainedAlgorithmicMedia"
+
+// provenanceMarker identifies a packet written by EmbedProvenance, which
+// SanitizeMetadata keeps: it holds no location or camera data.
+var provenanceMarker = []byte(`xmp:CreatorTool="` + ProvenanceTool)
+
+// Provenance records how this application produced an image: which edits
+// were made and which of them used generative AI.
+type Provenance struct {
+	// Version is the build that made the edits; "" means this binary's.
+	Version string
+	// When is the time of the last edit; zero means now.
+	When  time.Time
+	Edits []ProvenanceEdit
+}
+
+// ProvenanceEdit is one step in an image's edit history.
+type ProvenanceEdit struct {
+	Description string // e.g. "Enhanced with Gemini image editing"
+	AI          bool   // generative AI changed pixels
+}
+
+// Edits recorded by both the enhancement and the publish steps, which must
+// read the same in a photo's history.
+var (
+	EditGeminiEnhance = ProvenanceEdit{Description: "Enhanced with Gemini image editing", AI: true}
+	EditGeminiRestyle = ProvenanceEdit{Description: "Restyled with Gemini", AI: true}
+	EditCropped       = ProvenanceEdit{Description: "Cropped"}
+	EditRedacted      = ProvenanceEdit{Description: "Bystander faces or plates hidden"}
+	EditWatermark     = ProvenanceEdit{Description: "Watermark added"}
+)
+
+// ProvenanceSettingsReader reads a session owner's provenance choice.
+type ProvenanceSettingsReader interface {
+	SessionOwner(ctx context.Context, sessionID string) (string, error)
+	GetProvenanceSettings(ctx context.Context, owner string) (*store.ProvenanceSettings, error)
+}
+
+// OwnerWantsProvenance reports whether the session owner has provenance
+// records on. An unknown owner or an unreadable setting gets the default, on.
+func OwnerWantsProvenance(ctx context.Context, st ProvenanceSettingsReader, sessionID string) bool {
+	owner, err := st.SessionOwner(ctx, sessionID)
+	if err != nil || owner == "" {
+		log.Warn().Err(err).Str("sessionId", sessionID).Msg("Session owner unknown — embedding provenance by default")
+		return true
+	}
+	settings, err := st.GetProvenanceSettings(ctx, owner)
+	if err != nil {
+		log.Warn().Err(err).Str("sessionId", sessionID).Msg("Provenance setting not readable — embedding provenance by default")
+		return true
+	}
+	return settings.Enabled
+}
+
+// UsedAI reports whether any edit used generative AI.
+func (p Provenance) UsedAI() bool {
+	for _, e := range p.Edits {
+		if e.AI {
+			return true
+		}
+	}
+	return false
+}
+
+// EmbedProvenance writes p into a JPEG or PNG as XMP. The record names the
+// tool and version (xmp:CreatorTool), lists each edit in xmpMM:History, and
+// sets the IPTC DigitalSourceType when an edit used generative AI. XMP the
+// file already carries (kept under MetadataPreserve) is merged with the
+// record; an earlier record is replaced. Pixels are not re-encoded. Other
+// formats are returned unchanged; the input is never modified.
+func EmbedProvenance(data []byte, p Provenance) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		return embedXMPJPEG(data, mergeXMP(jpegXMP(data), p))
+	case bytes.HasPrefix(data, pngSignature):
+		return embedXMPPNG(data, mergeXMP(pngXMP(data), p))
+	}
+	return data, nil
+}
+
+// provenanceProperty matches the properties a provenance record sets, in
+// attribute or element form, so a merged packet holds each of them once.
+var provenanceProperty = regexp.MustCompile(`(?s)\s(?:xmp:CreatorTool|xmp:ModifyDate|Iptc4xmpExt:DigitalSourceType)\s*=\s*(?:"[^"]*"|'[^']*')` +
+	`|<(?:xmp:CreatorTool|xmp:ModifyDate|Iptc4xmpExt:DigitalSourceType|xmpMM:History)\b[^>]*/>` +
+	`|<xmp:CreatorTool\b[^>]*>.*?</xmp:CreatorTool>` +
+	`|<xmp:ModifyDate\b[^>]*>.*?</xmp:ModifyDate>` +
+	`|<Iptc4xmpExt:DigitalSourceType\b[^>]*>.*?</Iptc4xmpExt:DigitalSourceType>` +
+	`|<xmpMM:History\b[^>]*>.*?</xmpMM:History>`)
+
+// mergeXMP returns the packet to embed for p given the file's existing XMP
+// packet. A packet from another tool keeps its descriptions, less the
+// properties the record sets, and gains the record as another
+// rdf:Description; an earlier record in it is replaced. No packet, or one
+// without an rdf:RDF element to merge into, gets the record alone.
+func mergeXMP(existing []byte, p Provenance) []byte {
+	if start, end, ok := recordSpan(existing); ok {
+		existing = append(bytes.Clone(existing[:start]), existing[end:]...)
+	}
+	end := bytes.LastIndex(existing, []byte("</rdf:RDF>"))
+	if end < 0 || !bytes.Contains(existing[:end], []byte("<rdf:Description")) {
+		return provenanceXMP(p)
+	}
+	var b bytes.Buffer
+	b.Write(provenanceProperty.ReplaceAll(existing[:end], nil))
+	b.WriteString(provenanceDescription(p))
+	b.Write(existing[end:])
+	return b.Bytes()
+}
+
+// recordSpan locates the rdf:Description written by provenanceDescription
+// in packet, including its trailing newline.
+func recordSpan(packet []byte) (start, end int, ok bool) {
+	m := bytes.Index(packet, provenanceMarker)
+	if m < 0 {
+		return 0, 0, false
+	}
+	start = bytes.LastIndex(packet[:m], []byte("<rdf:Description"))
+	n := bytes.Index(packet[m:], []byte("</rdf:Description>\n"))
+	if start < 0 || n < 0 {
+		return 0, 0, false
+	}
+	return start, m + n + len("</rdf:Description>\n"), true
+}
+
+// provenanceOnly returns the record in a merged packet as a packet of its
+// own, so SanitizeMetadata keeps the record without the other tool's
+// properties. A packet holding only the record is returned as is.
+func provenanceOnly(packet []byte) []byte {
+	start, end, ok := recordSpan(packet)
+	if !ok || bytes.Count(packet, []byte("<rdf:Description")) == 1 {
+		return packet
+	}
+	return wrapXMP(string(packet[start:end]))
+}
+
+// isProvenancePacket reports whether an XMP payload was written by
+// EmbedProvenance.
+func isProvenancePacket(payload []byte) bool {
+	return bytes.Contains(payload, provenanceMarker)
+}
+
+// provenanceXMP renders p as a complete XMP packet.
+func provenanceXMP(p Provenance) []byte {
+	return wrapXMP(provenanceDescription(p))
+}
+
+// wrapXMP renders a complete XMP packet around one rdf:Description.
+func wrapXMP(description string) []byte {
+	var b strings.Builder
+	b.WriteString("<?xpacket begin=\"\ufeff\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n")
+	b.WriteString(`<x:xmpmeta xmlns:x="adobe:ns:meta/">` + "\n")
+	b.WriteString(`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">` + "\n")
+	b.WriteString(description)
+	b.WriteString("</rdf:RDF>\n</x:xmpmeta>\n")
+	b.WriteString(`<?xpacket end="r"?>`)
+	return []byte(b.String())
+}
+
+// provenanceDescription renders p as an rdf:Description element.
+func provenanceDescription(p Provenance) string {
+	version := p.Version
+	if version == "" {
+		version = buildRevision()
+	}
+	when := p.When
+	if when.IsZero() {
+		when = time.Now()
+	}
+	stamp := when.UTC().Format(time.RFC3339)
+	agent := fmt.Sprintf("%s (%s)", ProvenanceTool, version)
+
+	var b strings.Builder
+	b.WriteString(`<rdf:Description rdf:about=""` + "\n")
+	b.WriteString(` xmlns:xmp="http://ns.adobe.com/xap/1.0/"` + "\n")
+	b.WriteString(` xmlns:xmpMM="http://ns.adobe.com/xap/1.0/mm/"` + "\n")
+	b.WriteString(` xmlns:stEvt="http://ns.adobe.com/xap/1.0/sType/ResourceEvent#"` + "\n")
+	b.WriteString(` xmlns:Iptc4xmpExt="http://iptc.org/std/Iptc4xmpExt/2008-02-29/"` + "\n")
+	fmt.Fprintf(&b, ` xmp:CreatorTool="%s"`+"\n", xmlEscape(agent))
+	fmt.Fprintf(&b, ` xmp:ModifyDate="%s"`, stamp)
+	if p.UsedAI() {
+		fmt.Fprintf(&b, "\n"+` Iptc4xmpExt:DigitalSourceType="%s"`, digitalSourceAIComposite)
+	}
+	b.WriteString(">\n<xmpMM:History>\n<rdf:Seq>\n")
+	for _, e := range p.Edits {
+		params := e.Description
+		if e.AI {
+			params += " (generative AI)"
+		}
+		b.WriteString(`<rdf:li rdf:parseType="Resource">`)
+		b.WriteString("<stEvt:action>edited</stEvt:action>")
+		fmt.Fprintf(&b, "<stEvt:parameters>%s</stEvt:parameters>", xmlEscape(params))
+		fmt.Fprintf(&b, "<stEvt:softwareAgent>%s</stEvt:softwareAgent>", xmlEscape(agent))
+		fmt.Fprintf(&b, "<stEvt:when>%s</stEvt:when>", stamp)
+		b.WriteString("</rdf:li>\n")
+	}
+	b.WriteString("</rdf:Seq>\n</xmpMM:History>\n</rdf:Description>\n")
+	return b.String()
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// buildRevision returns the short VCS revision this binary was built from,
+// or "dev" when the build carries none.
+func buildRevision() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" && len(s.Value) >= 7 {
+				return s.Value[:7]
+			}
+		}
+	}
+	return "dev"
+}
+
+// jpegXMP returns the payload of a JPEG's main XMP segment, or nil.
+func jpegXMP(data []byte) []byte {
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF && data[i+1] != 0xDA; {
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) {
+			return nil
+		}
+		if payload := data[i+4 : end]; data[i+1] == 0xE1 && bytes.HasPrefix(payload, xmpHeader) {
+			return payload[len(xmpHeader):]
+		}
+		i = end
+	}
+	return nil
+}
+
+// pngXMP returns the packet of a PNG's XMP iTXt chunk, or nil.
+func pngXMP(data []byte) []byte {
+	for i := len(pngSignature); i+12 <= len(data); {
+		n := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + n
+		if end > len(data) {
+			return nil
+		}
+		if string(data[i+4:i+8]) == "iTXt" {
+			if packet := itxtXMP(data[i+8 : i+8+n]); packet != nil {
+				return packet
+			}
+		}
+		i = end
+	}
+	return nil
+}
+
+// itxtXMP returns the packet held by an uncompressed XMP iTXt chunk body,
+// or nil for any other chunk.
+func itxtXMP(body []byte) []byte {
+	rest, ok := bytes.CutPrefix(body, xmpKeyword)
+	// Compression flag and method, then the language tag and translated
+	// keyword, each NUL-terminated.
+	if !ok || len(rest) < 2 || rest[0] != 0 {
+		return nil
+	}
+	parts := bytes.SplitN(rest[2:], []byte{0}, 3)
+	if len(parts) < 3 {
+		return nil
+	}
+	return parts[2]
+}
+
+// embedXMPJPEG drops the file's main XMP segment and inserts packet after
+// the leading APP0/APP1 Exif segments, where readers expect it. Extended
+// XMP segments stay: a merged packet still refers to them.
+func embedXMPJPEG(data, packet []byte) ([]byte, error) {
+	seg, err := xmpSegment(packet)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(data)+len(seg))
+	out = append(out, data[:2]...)
+	inserted := false
+	i := 2
+	for {
+		if i+4 > len(data) || data[i] != 0xFF {
+			return nil, fmt.Errorf("embed provenance: malformed JPEG segment at offset %d", i)
+		}
+		marker := data[i+1]
+		if marker == 0xDA {
+			break
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) {
+			return nil, fmt.Errorf("embed provenance: JPEG segment at offset %d overruns file", i)
+		}
+		payload := data[i+4 : end]
+		leading := marker == 0xE0 || (marker == 0xE1 && bytes.HasPrefix(payload, exifHeader))
+		if !leading && !inserted {
+			out = append(out, seg...)
+			inserted = true
+		}
+		if !(marker == 0xE1 && bytes.HasPrefix(payload, xmpHeader)) {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	if !inserted {
+		out = append(out, seg...)
+	}
+	return append(out, data[i:]...), nil
+}
+
+// xmpSegment builds the APP1 segment holding an XMP packet.
+func xmpSegment(packet []byte) ([]byte, error) {
+	if len(xmpHeader)+len(packet)+2 > 0xFFFF {
+		return nil, fmt.Errorf("embed provenance: XMP packet too large")
+	}
+	seg := []byte{0xFF, 0xE1}
+	seg = binary.BigEndian.AppendUint16(seg, uint16(2+len(xmpHeader)+len(packet)))
+	return append(append(seg, xmpHeader...), packet...), nil
+}
+
+// xmpChunk builds the uncompressed iTXt chunk holding an XMP packet.
+func xmpChunk(packet []byte) []byte {
+	body := append(append(bytes.Clone(xmpKeyword), 0, 0, 0, 0), packet...)
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(body)))
+	chunk = append(append(chunk, "iTXt"...), body...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// embedXMPPNG drops the file's XMP iTXt chunk and inserts packet after IHDR.
+func embedXMPPNG(data, packet []byte) ([]byte, error) {
+	chunk := xmpChunk(packet)
+
+	out := append(make([]byte, 0, len(data)+len(chunk)), pngSignature...)
+	for i := len(pngSignature); i < len(data); {
+		if i+12 > len(data) {
+			return nil, fmt.Errorf("embed provenance: truncated PNG chunk at offset %d", i)
+		}
+		n := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + n
+		if end > len(data) {
+			return nil, fmt.Errorf("embed provenance: PNG chunk at offset %d overruns file", i)
+		}
+		typ, chunkBody := string(data[i+4:i+8]), data[i+8:i+8+n]
+		if !(typ == "iTXt" && bytes.HasPrefix(chunkBody, xmpKeyword)) {
+			out = append(out, data[i:end]...)
+		}
+		if typ == "IHDR" {
+			out = append(out, chunk...)
+		}
+		i = end
+	}
+	return out, nil
+}
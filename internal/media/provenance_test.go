@@ -0,0 +1,188 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evanoberholster/imagemeta"
+
+	"github.com/fpang/ai-social-media-helper/internal/store"
+)
+
+var testProvenance = Provenance{
+	Version: "abc1234",
+	When:    time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC),
+	Edits: []ProvenanceEdit{
+		{Description: "Enhanced with Gemini image editing", AI: true},
+		{Description: "Watermark <logo> added"},
+	},
+}
+
+func TestEmbedProvenanceJPEG(t *testing.T) {
+	data := testJPEGWithMetadata(t)
+	out, err := EmbedProvenance(data, testProvenance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := image.Decode(bytes.NewReader(out)); err != nil {
+		t.Fatalf("JPEG with provenance does not decode: %v", err)
+	}
+	for _, want := range []string{
+		`xmp:CreatorTool="AI Social Media Helper (abc1234)"`,
+		digitalSourceAIComposite,
+		"Enhanced with Gemini image editing (generative AI)",
+		"Watermark &lt;logo&gt; added</stEvt:parameters>",
+		"<stEvt:when>2026-10-15T12:00:00Z</stEvt:when>",
+	} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("packet lacks %q", want)
+		}
+	}
+	if bytes.Contains(out, []byte("GPSLatitude")) {
+		t.Error("the camera's XMP packet was not replaced")
+	}
+	if meta, err := imagemeta.Decode(bytes.NewReader(out)); err != nil || meta.Orientation != 6 {
+		t.Errorf("EXIF lost (orientation %v, err %v)", meta.Orientation, err)
+	}
+	if !bytes.Equal(data, testJPEGWithMetadata(t)) {
+		t.Error("EmbedProvenance modified its input")
+	}
+
+	// Embedding again replaces the packet rather than adding one.
+	again, _ := EmbedProvenance(out, testProvenance)
+	if n := bytes.Count(again, []byte("xmp:CreatorTool")); n != 1 {
+		t.Errorf("%d provenance packets after re-embedding, want 1", n)
+	}
+}
+
+func TestEmbedProvenanceWithoutAI(t *testing.T) {
+	out, err := EmbedProvenance(testJPEG(t, 16, 8), Provenance{Edits: []ProvenanceEdit{{Description: "Cropped to 4:5"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out, []byte("DigitalSourceType")) {
+		t.Error("a record without AI edits declared a digital source type")
+	}
+	if !bytes.Contains(out, []byte("Cropped to 4:5</stEvt:parameters>")) {
+		t.Error("edit missing from the history")
+	}
+}
+
+func TestSanitizeKeepsProvenance(t *testing.T) {
+	jpg, err := EmbedProvenance(testJPEGWithMetadata(t), testProvenance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 4)))
+	pngData, err := EmbedProvenance(buf.Bytes(), testProvenance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := png.Decode(bytes.NewReader(pngData)); err != nil {
+		t.Fatalf("PNG with provenance does not decode: %v", err)
+	}
+
+	for _, policy := range []MetadataPolicy{MetadataStripGPS, MetadataStripAll} {
+		for name, data := range map[string][]byte{"jpeg": jpg, "png": pngData} {
+			out, err := SanitizeMetadata(data, policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(out), digitalSourceAIComposite) {
+				t.Errorf("%s/%s: provenance stripped", policy, name)
+			}
+		}
+	}
+}
+
+// lightroomXMP is another tool's packet, as kept under MetadataPreserve.
+const lightroomXMP = `<x:xmpmeta xmlns:x="adobe:ns:meta/">
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+<rdf:Description rdf:about="" xmlns:xmp="http://ns.adobe.com/xap/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/"
+ xmp:CreatorTool="Adobe Lightroom" xmp:Rating="5">
+<dc:subject><rdf:Bag><rdf:li>sunset</rdf:li></rdf:Bag></dc:subject>
+</rdf:Description>
+</rdf:RDF>
+</x:xmpmeta>`
+
+func TestEmbedProvenanceMergesXMP(t *testing.T) {
+	plain := testJPEG(t, 16, 8)
+	seg := append(append([]byte{0xFF, 0xE1}, be16(uint16(2+len(xmpHeader)+len(lightroomXMP)))...), xmpHeader...)
+	data := append(append(append(bytes.Clone(plain[:2]), seg...), lightroomXMP...), plain[2:]...)
+
+	out, err := EmbedProvenance(data, testProvenance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet := string(jpegXMP(out))
+	for _, want := range []string{`xmp:Rating="5"`, "<rdf:li>sunset</rdf:li>", `xmp:CreatorTool="AI Social Media Helper (abc1234)"`} {
+		if !strings.Contains(packet, want) {
+			t.Errorf("merged packet lacks %q", want)
+		}
+	}
+	if strings.Contains(packet, "Lightroom") {
+		t.Error("the other tool's CreatorTool was kept beside the record's")
+	}
+
+	// Embedding again replaces the record and keeps the rest.
+	again, err := EmbedProvenance(out, Provenance{Version: "def5678", Edits: []ProvenanceEdit{EditWatermark}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet = string(jpegXMP(again))
+	if n := strings.Count(packet, "xmp:CreatorTool"); n != 1 || !strings.Contains(packet, "def5678") || !strings.Contains(packet, "sunset") {
+		t.Errorf("re-embedded packet has %d records or lost the merged properties:\n%s", n, packet)
+	}
+
+	// Stripping keeps the record without the other tool's properties.
+	stripped, err := SanitizeMetadata(again, MetadataStripGPS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet = string(jpegXMP(stripped))
+	if !strings.Contains(packet, "def5678") || strings.Contains(packet, "sunset") {
+		t.Errorf("sanitized packet:\n%s", packet)
+	}
+	if _, _, err := image.Decode(bytes.NewReader(stripped)); err != nil {
+		t.Fatalf("sanitized JPEG does not decode: %v", err)
+	}
+}
+
+type fakeProvenanceSettings struct {
+	owner    string
+	settings *store.ProvenanceSettings
+	err      error
+}
+
+func (f fakeProvenanceSettings) SessionOwner(context.Context, string) (string, error) {
+	return f.owner, nil
+}
+
+func (f fakeProvenanceSettings) GetProvenanceSettings(context.Context, string) (*store.ProvenanceSettings, error) {
+	return f.settings, f.err
+}
+
+func TestOwnerWantsProvenance(t *testing.T) {
+	tests := []struct {
+		name string
+		st   fakeProvenanceSettings
+		want bool
+	}{
+		{"on", fakeProvenanceSettings{owner: "u", settings: &store.ProvenanceSettings{Enabled: true}}, true},
+		{"off", fakeProvenanceSettings{owner: "u", settings: &store.ProvenanceSettings{}}, false},
+		{"unknown owner", fakeProvenanceSettings{}, true},
+		{"unreadable", fakeProvenanceSettings{owner: "u", err: errors.New("throttled")}, true},
+	}
+	for _, tt := range tests {
+		if got := OwnerWantsProvenance(context.Background(), tt.st, "s"); got != tt.want {
+			t.Errorf("%s: OwnerWantsProvenance = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"sort"
+)
+
+// Quality checks (DDR-112) catch photos that are unsaveable on any reading —
+// black pocket shots, blown-out frames, heavy motion blur — so triage can
+// discard them without a Gemini call. Thresholds are deliberately loose:
+// anything borderline is left for the model to judge.
+
+const (
+	// qualityCheckWidth is the width images are scaled to before checking, so
+	// thresholds mean the same thing for thumbnails and full-size originals.
+	// It matches the 400px thumbnails written by media-process.
+	qualityCheckWidth = 400
+
+	// nearBlackMax and nearWhiteMin bound the 99th / 1st percentile luma of
+	// a frame that is essentially one flat black or white field.
+	nearBlackMax = 0.05
+	nearWhiteMin = 0.97
+	// underexposedMax and overexposedMin bound the same percentiles for a
+	// frame with no mid-tones at all.
+	underexposedMax = 0.15
+	overexposedMin  = 0.85
+
+	// minBlurVariance is the Laplacian variance (luma on a 0-255 scale) below
+	// which a frame counts as severely blurred. In-focus photos at 400px
+	// score in the hundreds; slightly soft ones in the 30-100 range.
+	minBlurVariance = 15
+	// minBlurCheckRange is the 5th-95th percentile luma spread a frame needs
+	// before it is checked for blur; flat scenes like clear sky have no
+	// edges to measure.
+	minBlurCheckRange = 0.15
+)
+
+// CheckQuality returns why img is clearly unsaveable, or "" if it passes.
+func CheckQuality(img image.Image) string {
+	p := newLumaPlane(scaleToWidth(img, qualityCheckWidth))
+	if len(p.pix) == 0 {
+		return ""
+	}
+
+	sorted := append([]float64(nil), p.pix...)
+	sort.Float64s(sorted)
+	pct := func(q int) float64 { return sorted[(len(sorted)-1)*q/100] }
+
+	switch {
+	case pct(99) < nearBlackMax:
+		return "Nearly black frame — likely taken by accident"
+	case pct(1) > nearWhiteMin:
+		return "Nearly white frame — completely blown out"
+	case pct(99) < underexposedMax:
+		return "Severely underexposed — no part of the frame reaches mid-tones"
+	case pct(1) > overexposedMin:
+		return "Severely overexposed — no shadows or mid-tones left"
+	}
+
+	if pct(95)-pct(5) >= minBlurCheckRange {
+		if v := p.laplacianVariance(); v < minBlurVariance {
+			return fmt.Sprintf("Severely blurred (sharpness %.0f, minimum %d)", v, minBlurVariance)
+		}
+	}
+	return ""
+}
+
+// CheckQualityBytes decodes an image (e.g. a thumbnail) and runs CheckQuality.
+func CheckQualityBytes(data []byte) (string, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("decode image for quality check: %w", err)
+	}
+	return CheckQuality(img), nil
+}
+
+// laplacianVariance is the variance of the 4-neighbour Laplacian over the
+// interior pixels, with luma scaled to 0-255. Sharp edges give large
+// responses; blur flattens them toward zero.
+func (p *lumaPlane) laplacianVariance() float64 {
+	if p.w < 3 || p.h < 3 {
+		return 0
+	}
+	var sum, sumSq float64
+	n := 0
+	for y := 1; y < p.h-1; y++ {
+		for x := 1; x < p.w-1; x++ {
+			l := 255 * (p.at(x-1, y) + p.at(x+1, y) + p.at(x, y-1) + p.at(x, y+1) - 4*p.at(x, y))
+			sum += l
+			sumSq += l * l
+			n++
+		}
+	}
+	mean := sum / float64(n)
+	return sumSq/float64(n) - mean*mean
+}
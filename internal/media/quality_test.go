@@ -0,0 +1,52 @@
+package media
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+// fillGray returns a w×h image with luma f(x, y).
+func fillGray(w, h int, f func(x, y int) uint8) image.Image {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetGray(x, y, color.Gray{Y: f(x, y)})
+		}
+	}
+	return img
+}
+
+func checkerboard(lo, hi uint8) func(x, y int) uint8 {
+	return func(x, y int) uint8 {
+		if (x/16+y/16)%2 == 0 {
+			return lo
+		}
+		return hi
+	}
+}
+
+func TestCheckQuality(t *testing.T) {
+	tests := []struct {
+		name string
+		img  image.Image
+		want string // prefix of the reason; "" means the image passes
+	}{
+		{"sharp", fillGray(800, 600, checkerboard(30, 220)), ""},
+		{"flat mid-gray", fillGray(800, 600, func(x, y int) uint8 { return 128 }), ""},
+		{"black", fillGray(800, 600, func(x, y int) uint8 { return 4 }), "Nearly black"},
+		{"white", fillGray(800, 600, func(x, y int) uint8 { return 252 }), "Nearly white"},
+		{"underexposed", fillGray(800, 600, checkerboard(0, 30)), "Severely underexposed"},
+		{"overexposed", fillGray(800, 600, checkerboard(225, 255)), "Severely overexposed"},
+		{"blurred", fillGray(800, 600, func(x, y int) uint8 { return uint8(x * 255 / 800) }), "Severely blurred"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckQuality(tt.img)
+			if tt.want == "" && got != "" || !strings.HasPrefix(got, tt.want) {
+				t.Errorf("CheckQuality() = %q, want prefix %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package media
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"os"
+	"strings"
+
+	"github.com/evanoberholster/imagemeta"
+	"github.com/evanoberholster/imagemeta/exif2"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/tiff"
+	"github.com/rs/zerolog/log"
+)
+
+// RAW camera files (DDR-119). Sensor data needs a demosaicing decoder per
+// camera, which has no pure-Go implementation, but every RAW format embeds a
+// full- or near-full-size JPEG preview rendered by the camera. The preview
+// stands in for the RAW everywhere pixels are needed — thumbnails, Gemini
+// analysis, enhancement — while downloads always bundle the RAW original.
+
+// SupportedRawExtensions defines the RAW camera extensions accepted for
+// upload, matching the API's content-type allowlist. They count as images
+// (IsImage) but are kept out of SupportedImageExtensions, whose callers
+// send the file's bytes to Gemini or the browser as-is.
+var SupportedRawExtensions = map[string]string{
+	".dng": "image/x-adobe-dng",
+	".cr2": "image/x-canon-cr2",
+	".cr3": "image/x-canon-cr3",
+	".nef": "image/x-nikon-nef",
+	".arw": "image/x-sony-arw",
+	".raf": "image/x-fuji-raf",
+	".orf": "image/x-olympus-orf",
+	".rw2": "image/x-panasonic-rw2",
+	".srw": "image/x-samsung-srw",
+}
+
+// ErrRAWNoPreview is returned by DecodeRAWPreview when the file contains no
+// JPEG preview the standard library can decode.
+var ErrRAWNoPreview = errors.New("raw: no decodable JPEG preview in file")
+
+// IsRaw returns true if the file extension is a RAW camera format.
+func IsRaw(ext string) bool {
+	_, ok := SupportedRawExtensions[strings.ToLower(ext)]
+	return ok
+}
+
+// DecodeRAWPreview decodes the largest embedded JPEG preview of a RAW file,
+// rotated by the RAW's EXIF orientation (previews are stored as the sensor
+// saw them).
+func DecodeRAWPreview(filePath string) (image.Image, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("read RAW file: %w", err)
+	}
+	preview := largestJPEG(data)
+	if preview == nil {
+		return nil, ErrRAWNoPreview
+	}
+	img, err := jpeg.Decode(bytes.NewReader(preview))
+	if err != nil {
+		return nil, fmt.Errorf("decode RAW preview: %w", err)
+	}
+
+	if exifData, err := decodeRawExif(data, preview); err == nil {
+		switch exifData.Orientation {
+		case meta.OrientationRotate180:
+			img = rotateCCW(img, 2)
+		case meta.OrientationRotate90:
+			img = rotateCCW(img, 3)
+		case meta.OrientationRotate270:
+			img = rotateCCW(img, 1)
+		}
+	}
+
+	log.Debug().
+		Str("path", filePath).
+		Int("width", img.Bounds().Dx()).
+		Int("height", img.Bounds().Dy()).
+		Msg("Decoded RAW preview")
+	return img, nil
+}
+
+// RAWPreviewJPEG returns a RAW file's oriented preview as a full-size JPEG,
+// for callers that send the picture itself to Gemini (enhancement).
+func RAWPreviewJPEG(filePath string, quality int) ([]byte, error) {
+	img, err := DecodeRAWPreview(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return encodeJPEGThumbnail(img, maxSide(img), quality)
+}
+
+// readRawExif reads EXIF metadata from a RAW file; see decodeRawExif.
+func readRawExif(filePath string) (exif2.Exif, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return exif2.Exif{}, fmt.Errorf("read RAW file: %w", err)
+	}
+	return decodeRawExif(data, nil)
+}
+
+// decodeRawExif reads EXIF metadata from a RAW container. imagemeta handles
+// DNG, CR2, CR3 and RW2 directly; the other TIFF-based formats (NEF, ARW,
+// ORF, SRW) are read as plain TIFF, and RAF, which is not TIFF-based, from
+// the EXIF of its embedded preview. preview may be nil, in which case it is
+// located when needed.
+func decodeRawExif(data, preview []byte) (exif2.Exif, error) {
+	exifData, err := imagemeta.Decode(bytes.NewReader(data))
+	if err == nil {
+		return exifData, nil
+	}
+
+	br := bufio.NewReader(bytes.NewReader(data))
+	if header, tiffErr := tiff.ScanTiffHeader(br, imagetype.ImageTiff); tiffErr == nil {
+		ir := exif2.NewIfdReader(exif2.Logger)
+		defer ir.Close()
+		if tiffErr = ir.DecodeTiff(br, header); tiffErr == nil {
+			return ir.Exif, nil
+		}
+	}
+
+	if preview == nil {
+		preview = largestJPEG(data)
+	}
+	if preview != nil {
+		if exifData, previewErr := imagemeta.Decode(bytes.NewReader(preview)); previewErr == nil {
+			return exifData, nil
+		}
+	}
+	return exif2.Exif{}, fmt.Errorf("no readable EXIF in RAW file: %w", err)
+}
+
+// largestJPEG returns the embedded baseline or progressive JPEG with the most
+// pixels, or nil. Lossless JPEG streams (sensor data in DNG, CR2 and NEF)
+// are skipped because image/jpeg cannot decode them.
+func largestJPEG(data []byte) []byte {
+	var best []byte
+	bestPixels := 0
+	for _, candidate := range findJPEGs(data) {
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(candidate))
+		if err != nil {
+			continue
+		}
+		if px := cfg.Width * cfg.Height; px > bestPixels {
+			best, bestPixels = candidate, px
+		}
+	}
+	return best
+}
+
+// findJPEGs returns every complete top-level JPEG stream in data. Each stream
+// is walked marker by marker, so JPEGs nested inside another's APP segments
+// (e.g. an EXIF thumbnail in the preview) are not returned separately.
+func findJPEGs(data []byte) [][]byte {
+	soi := []byte{0xFF, 0xD8, 0xFF}
+	var out [][]byte
+	for pos := 0; ; {
+		i := bytes.Index(data[pos:], soi)
+		if i < 0 {
+			return out
+		}
+		start := pos + i
+		if end, ok := jpegEnd(data, start); ok {
+			out = append(out, data[start:end])
+			pos = end
+		} else {
+			pos = start + 2
+		}
+	}
+}
+
+// jpegEnd returns the offset just past the EOI marker of the JPEG stream
+// starting at start.
+func jpegEnd(data []byte, start int) (int, bool) {
+	pos := start + 2
+	for pos+1 < len(data) {
+		if data[pos] != 0xFF {
+			return 0, false
+		}
+		marker := data[pos+1]
+		pos += 2
+		switch {
+		case marker == 0xFF: // fill byte
+			pos--
+			continue
+		case marker == 0xD9: // EOI
+			return pos, true
+		case marker >= 0xD0 && marker <= 0xD7, marker == 0x01: // no length
+			continue
+		}
+
+		if pos+2 > len(data) {
+			return 0, false
+		}
+		length := int(binary.BigEndian.Uint16(data[pos:]))
+		if length < 2 {
+			return 0, false
+		}
+		pos += length
+		if marker != 0xDA {
+			continue
+		}
+
+		// Entropy-coded data after SOS runs until the next marker other than
+		// a stuffed 0xFF00 or a restart marker.
+		for ; pos+1 < len(data); pos++ {
+			if data[pos] != 0xFF {
+				continue
+			}
+			next := data[pos+1]
+			if next == 0x00 || (next >= 0xD0 && next <= 0xD7) {
+				pos++
+				continue
+			}
+			if next != 0xFF {
+				break
+			}
+		}
+	}
+	return 0, false
+}
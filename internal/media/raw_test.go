@@ -0,0 +1,77 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLargestJPEGPicksBiggestPreview(t *testing.T) {
+	small := testJPEG(t, 16, 12)
+	large := testJPEG(t, 64, 48)
+	data := bytes.Join([][]byte{
+		[]byte("II\x2a\x00\x08\x00\x00\x00"), // TIFF header
+		small,
+		{0xFF, 0xD8, 0xFF, 0x00}, // truncated stream
+		large,
+		[]byte("trailing sensor data"),
+	}, nil)
+
+	got := largestJPEG(data)
+	if !bytes.Equal(got, large) {
+		t.Fatalf("largestJPEG returned %d bytes, want the %d-byte large preview", len(got), len(large))
+	}
+	if n := len(findJPEGs(data)); n != 2 {
+		t.Errorf("findJPEGs found %d streams, want 2", n)
+	}
+}
+
+func TestDecodeRAWPreview(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "IMG_0001.NEF")
+	data := append([]byte("MM\x00\x2a\x00\x00\x00\x08"), testJPEG(t, 40, 30)...)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := DecodeRAWPreview(path)
+	if err != nil {
+		t.Fatalf("DecodeRAWPreview: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 40 || b.Dy() != 30 {
+		t.Errorf("preview is %dx%d, want 40x30", b.Dx(), b.Dy())
+	}
+
+	out, err := RAWPreviewJPEG(path, 90)
+	if err != nil {
+		t.Fatalf("RAWPreviewJPEG: %v", err)
+	}
+	if _, err := jpeg.DecodeConfig(bytes.NewReader(out)); err != nil {
+		t.Errorf("RAWPreviewJPEG output is not a JPEG: %v", err)
+	}
+
+	empty := filepath.Join(dir, "empty.dng")
+	if err := os.WriteFile(empty, []byte("II\x2a\x00\x08\x00\x00\x00"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecodeRAWPreview(empty); !errors.Is(err, ErrRAWNoPreview) {
+		t.Errorf("DecodeRAWPreview without preview: err = %v, want ErrRAWNoPreview", err)
+	}
+}
+
+func TestRawExtensions(t *testing.T) {
+	for _, ext := range []string{".dng", ".CR3", ".nef", ".ARW"} {
+		if !IsRaw(ext) || !IsImage(ext) {
+			t.Errorf("%s: IsRaw/IsImage = false, want true", ext)
+		}
+	}
+	if IsRaw(".jpg") {
+		t.Error("IsRaw(.jpg) = true, want false")
+	}
+	if _, ok := SupportedImageExtensions[".nef"]; ok {
+		t.Error(".nef must not be in SupportedImageExtensions")
+	}
+}
@@ -0,0 +1,87 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/image/draw"
+)
+
+// --- Privacy redaction (DDR-185) ---
+
+// redactBlurCells is how many cells a blurred region keeps along its long
+// edge: few enough that a face or plate cannot be read back.
+const redactBlurCells = 8
+
+// OrientedJPEG decodes image data, applies a JPEG's EXIF orientation and
+// re-encodes it as a JPEG, so pixel coordinates match the photo as displayed.
+func OrientedJPEG(data []byte, quality int) ([]byte, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	if format == "jpeg" {
+		img = orientJPEG(img, data)
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// BlurRegions decodes image data (JPEG EXIF orientation applied), blurs each
+// rectangle beyond recognition and re-encodes the result as a JPEG at the
+// given quality. Rectangles are in displayed pixels and are clipped to the
+// image.
+func BlurRegions(data []byte, rects []image.Rectangle, quality int) ([]byte, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	if format == "jpeg" {
+		img = orientJPEG(img, data)
+	}
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(out, out.Bounds(), img, b.Min, draw.Src)
+
+	blurred := 0
+	for _, r := range rects {
+		r = r.Intersect(out.Bounds())
+		if r.Empty() {
+			continue
+		}
+		blurRect(out, r)
+		blurred++
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, out, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("encode image: %w", err)
+	}
+	log.Debug().
+		Str("format", format).
+		Int("regions", blurred).
+		Int("output_size", buf.Len()).
+		Msg("Image regions blurred")
+	return buf.Bytes(), nil
+}
+
+// blurRect averages r down to a few cells and scales it back up smoothly,
+// which leaves colours but no detail.
+func blurRect(img *image.RGBA, r image.Rectangle) {
+	w, h := r.Dx(), r.Dy()
+	cw, ch := redactBlurCells, redactBlurCells
+	if w > h {
+		ch = max(1, redactBlurCells*h/w)
+	} else {
+		cw = max(1, redactBlurCells*w/h)
+	}
+	small := image.NewRGBA(image.Rect(0, 0, cw, ch))
+	draw.CatmullRom.Scale(small, small.Bounds(), img, r, draw.Src, nil)
+	draw.BiLinear.Scale(img, r, small, small.Bounds(), draw.Src, nil)
+}
@@ -0,0 +1,58 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+func TestBlurRegions(t *testing.T) {
+	// Vertical black and white stripes, 8 pixels wide.
+	src := image.NewGray(image.Rect(0, 0, 200, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 200; x++ {
+			if x/8%2 == 0 {
+				src.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, src, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := BlurRegions(buf.Bytes(), []image.Rectangle{image.Rect(0, 0, 100, 100), image.Rect(500, 500, 600, 600)}, 95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 200 || b.Dy() != 100 {
+		t.Fatalf("size = %v, want 200x100", b)
+	}
+
+	gray := func(x, y int) uint8 { return color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y }
+	for x := 10; x < 90; x++ {
+		if v := gray(x, 50); v < 40 || v > 215 {
+			t.Fatalf("blurred pixel (%d, 50) = %d, stripes still visible", x, v)
+		}
+	}
+	if gray(164, 50) < 200 || gray(172, 50) > 55 {
+		t.Errorf("stripes outside the region changed: %d, %d", gray(164, 50), gray(172, 50))
+	}
+}
+
+func TestOrientedJPEGKeepsPlainJPEGSize(t *testing.T) {
+	out, err := OrientedJPEG(testJPEG(t, 30, 20), 90)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(out))
+	if err != nil || cfg.Width != 30 || cfg.Height != 20 {
+		t.Errorf("OrientedJPEG size = %dx%d (%v), want 30x20", cfg.Width, cfg.Height, err)
+	}
+}
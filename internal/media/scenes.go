@@ -0,0 +1,98 @@
+package media
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Scene grouping (DDR-114) clusters media by capture time and GPS before
+// selection, so Gemini starts from deterministic groups instead of inferring
+// scenes from raw timestamps and coordinates.
+
+const (
+	// SceneTimeGap is the capture-time gap that starts a new scene.
+	SceneTimeGap = 2 * time.Hour
+	// SceneDistanceKm is the distance from a scene's centre that starts a new
+	// scene, when both have GPS.
+	SceneDistanceKm = 1.0
+
+	earthRadiusKm = 6371.0
+)
+
+// Scene is a run of media captured close together in time and place.
+// Members are indices into the slice passed to GroupScenes, in capture
+// order. Lat and Lon are the mean of the members' GPS coordinates and are
+// only meaningful when HasGPS is set.
+type Scene struct {
+	Members  []int
+	Start    time.Time
+	End      time.Time
+	Lat, Lon float64
+	HasGPS   bool
+
+	gpsCount int
+}
+
+// GroupScenes sorts media by capture time and starts a new scene whenever
+// the gap to the previous item exceeds SceneTimeGap or the item is more than
+// SceneDistanceKm from the current scene's centre. Items without a capture
+// date are not assigned to any scene. The result is ordered by start time.
+func GroupScenes(files []*MediaFile) []Scene {
+	var dated []int
+	for i, f := range files {
+		if f.Metadata != nil && f.Metadata.HasDateData() && !f.Metadata.GetDate().IsZero() {
+			dated = append(dated, i)
+		}
+	}
+	sort.SliceStable(dated, func(a, b int) bool {
+		return files[dated[a]].Metadata.GetDate().Before(files[dated[b]].Metadata.GetDate())
+	})
+
+	var scenes []Scene
+	for _, i := range dated {
+		md := files[i].Metadata
+		t := md.GetDate()
+		if n := len(scenes); n > 0 {
+			cur := &scenes[n-1]
+			if t.Sub(cur.End) <= SceneTimeGap && !cur.farFrom(md) {
+				cur.add(i, md)
+				continue
+			}
+		}
+		scenes = append(scenes, Scene{Start: t})
+		scenes[len(scenes)-1].add(i, md)
+	}
+	return scenes
+}
+
+func (s *Scene) add(i int, md MediaMetadata) {
+	s.Members = append(s.Members, i)
+	s.End = md.GetDate()
+	if !md.HasGPSData() {
+		return
+	}
+	lat, lon := md.GetGPS()
+	s.gpsCount++
+	s.Lat += (lat - s.Lat) / float64(s.gpsCount)
+	s.Lon += (lon - s.Lon) / float64(s.gpsCount)
+	s.HasGPS = true
+}
+
+func (s *Scene) farFrom(md MediaMetadata) bool {
+	if !s.HasGPS || !md.HasGPSData() {
+		return false
+	}
+	lat, lon := md.GetGPS()
+	return DistanceKm(s.Lat, s.Lon, lat, lon) > SceneDistanceKm
+}
+
+// DistanceKm returns the great-circle distance between two coordinates.
+func DistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,52 @@
+package media
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGroupScenes(t *testing.T) {
+	base := time.Date(2026, 7, 1, 10, 0, 0, 0, time.UTC)
+	at := func(name string, offset time.Duration, lat, lon float64) *MediaFile {
+		return &MediaFile{Path: name, Metadata: &ImageMetadata{
+			DateTaken: base.Add(offset), HasDate: true,
+			Latitude: lat, Longitude: lon, HasGPS: lat != 0,
+		}}
+	}
+	files := []*MediaFile{
+		at("museum-2.jpg", 40*time.Minute, 35.6586, 139.7454),
+		at("museum-1.jpg", 0, 35.6585, 139.7455),
+		at("dinner.jpg", 5*time.Hour, 35.6590, 139.7450),     // same place, 4h later
+		at("park.jpg", 50*time.Minute, 35.7148, 139.7967),    // 7 km away, 10 min later
+		{Path: "screenshot.png", Metadata: &ImageMetadata{}}, // no date
+		at("park-no-gps.jpg", 70*time.Minute, 0, 0),          // joins park by time
+	}
+
+	scenes := GroupScenes(files)
+	want := [][]int{{1, 0}, {3, 5}, {2}}
+	if len(scenes) != len(want) {
+		t.Fatalf("got %d scenes, want %d: %+v", len(scenes), len(want), scenes)
+	}
+	for i, s := range scenes {
+		if len(s.Members) != len(want[i]) {
+			t.Errorf("scene %d members = %v, want %v", i, s.Members, want[i])
+			continue
+		}
+		for j := range s.Members {
+			if s.Members[j] != want[i][j] {
+				t.Errorf("scene %d members = %v, want %v", i, s.Members, want[i])
+				break
+			}
+		}
+	}
+	if !scenes[0].HasGPS || scenes[0].End != base.Add(40*time.Minute) {
+		t.Errorf("scene 0 = %+v", scenes[0])
+	}
+}
+
+func TestDistanceKm(t *testing.T) {
+	// Tokyo Tower to Tokyo Skytree is about 8.2 km.
+	if d := DistanceKm(35.6586, 139.7454, 35.7101, 139.8107); d < 8 || d > 8.5 {
+		t.Errorf("DistanceKm = %.2f, want ~8.2", d)
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"golang.org/x/image/draw"
@@ -23,9 +25,13 @@ const DefaultThumbnailMaxDimension = 1024
 //
 // Strategy:
 //   - JPEG/PNG: Resize using pure Go (golang.org/x/image/draw) and encode as JPEG
-//   - HEIC/HEIF: Use ffmpeg to convert to JPEG thumbnail (cross-platform, DDR-027)
-//   - GIF/WebP: Return original file (typically small)
-//   - Video (MP4/MOV/AVI/WebM/MKV): Extract frame at 1s using ffmpeg (DDR-030)
+//   - HEIC/HEIF: Decode a JPEG-coded picture from the container in pure Go
+//     (DDR-116), else use ffmpeg to convert to JPEG thumbnail (DDR-027)
+//   - RAW (DNG/CR2/CR3/NEF/ARW/...): Resize the embedded JPEG preview (DDR-119)
+//   - Animated GIF/WebP: Sheet of evenly spaced frames as JPEG (DDR-120)
+//   - Still GIF/WebP: Return original file (typically small)
+//   - Video (MP4/MOV/AVI/WebM/MKV): Extract frame at 10% of the duration using
+//     ffmpeg, else the first frame (DDR-123)
 //
 // All thumbnails are encoded as JPEG to avoid CGO dependencies (DDR-027).
 // WebP encoding was considered but requires chai2010/webp which needs CGO_ENABLED=1,
@@ -51,10 +57,16 @@ func GenerateThumbnail(mediaFile *MediaFile, maxDimension int) ([]byte, string,
 
 	case ".heic", ".heif":
 		data, mimeType, err = generateThumbnailHEIC(mediaFile.Path, maxDimension)
-		method = "ffmpeg-heic"
+		method = "heic"
 
 	case ".gif", ".webp":
-		// Return original file for small formats (GIF animations lose frames when re-encoded)
+		if isAnimatedFile(mediaFile) {
+			data, err = AnimationFrameSheet(mediaFile.Path, maxDimension, 80)
+			mimeType = "image/jpeg"
+			method = "animation-sheet"
+			break
+		}
+		// Return original file for small still formats
 		data, err = os.ReadFile(mediaFile.Path)
 		if err != nil {
 			return nil, "", fmt.Errorf("failed to read file: %w", err)
@@ -63,11 +75,15 @@ func GenerateThumbnail(mediaFile *MediaFile, maxDimension int) ([]byte, string,
 		method = "original"
 
 	case ".mp4", ".mov", ".avi", ".webm", ".mkv":
-		data, mimeType, err = GenerateVideoThumbnail(mediaFile.Path, maxDimension)
+		data, mimeType, err = GenerateVideoThumbnail(mediaFile.Path, videoDuration(mediaFile), maxDimension)
 		method = "ffmpeg-video"
 
 	default:
-		return nil, "", fmt.Errorf("unsupported format for thumbnail: %s", ext)
+		if !IsRaw(ext) {
+			return nil, "", fmt.Errorf("unsupported format for thumbnail: %s", ext)
+		}
+		data, mimeType, err = generateThumbnailRAW(mediaFile.Path, maxDimension)
+		method = "raw-preview"
 	}
 
 	if err != nil {
@@ -83,13 +99,30 @@ func GenerateThumbnail(mediaFile *MediaFile, maxDimension int) ([]byte, string,
 	return data, mimeType, nil
 }
 
-// GenerateVideoThumbnail extracts a frame from a video at the 1-second mark
+// videoThumbnailFraction is how far into a video its thumbnail frame is
+// taken. Far enough to skip fade-ins and black leaders, early enough to
+// stay on the opening subject (DDR-123).
+const videoThumbnailFraction = 10
+
+// VideoThumbnailOffset returns the seek position for a video's thumbnail
+// frame: 10% of its duration, or zero (the first frame) when the duration
+// is unknown.
+func VideoThumbnailOffset(duration time.Duration) time.Duration {
+	if duration <= 0 {
+		return 0
+	}
+	return duration / videoThumbnailFraction
+}
+
+// GenerateVideoThumbnail extracts the frame at VideoThumbnailOffset(duration)
 // and returns it as a JPEG thumbnail. Uses ffmpeg for extraction.
-// Falls back to a frame at 0s if the video is shorter than 1 second.
-// See DDR-030: Cloud Selection Backend Architecture.
-func GenerateVideoThumbnail(videoPath string, maxDimension int) ([]byte, string, error) {
+// Falls back to the first frame if the seek fails or yields no frame, e.g.
+// when the container reports a longer duration than it holds (DDR-123).
+func GenerateVideoThumbnail(videoPath string, duration time.Duration, maxDimension int) ([]byte, string, error) {
+	offset := VideoThumbnailOffset(duration)
 	log.Debug().
 		Str("path", videoPath).
+		Dur("offset", offset).
 		Int("max_dimension", maxDimension).
 		Msg("Generating video thumbnail")
 
@@ -106,43 +139,13 @@ func GenerateVideoThumbnail(videoPath string, maxDimension int) ([]byte, string,
 	tmpFile.Close()
 	defer os.Remove(tmpPath)
 
-	// ffmpeg -i input.mp4 -ss 1 -vframes 1 -vf "scale='min(1024,iw)':-2" -y output.jpg
-	// -ss 1: seek to 1 second (avoids black/blank first frames)
-	// -vframes 1: extract single frame
-	// scale filter: downscale only if larger, preserve aspect ratio, ensure even height
-	vf := fmt.Sprintf("scale='min(%d,iw)':-2", maxDimension)
-	cmd := exec.Command(ffmpegPath,
-		"-i", videoPath,
-		"-ss", "1",
-		"-vframes", "1",
-		"-vf", vf,
-		"-f", "image2",
-		"-y", tmpPath,
-	)
-
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		// Retry at 0s in case the video is shorter than 1 second
-		cmd2 := exec.Command(ffmpegPath,
-			"-i", videoPath,
-			"-vframes", "1",
-			"-vf", vf,
-			"-f", "image2",
-			"-y", tmpPath,
-		)
-		output2, err2 := cmd2.CombinedOutput()
-		if err2 != nil {
-			return nil, "", fmt.Errorf("ffmpeg frame extraction failed: %w: %s / %s", err2, string(output), string(output2))
-		}
+	data, err := extractVideoFrame(ffmpegPath, videoPath, tmpPath, offset, maxDimension)
+	if err != nil && offset > 0 {
+		log.Debug().Err(err).Str("path", videoPath).Msg("Seeked frame extraction failed, retrying at first frame")
+		data, err = extractVideoFrame(ffmpegPath, videoPath, tmpPath, 0, maxDimension)
 	}
-
-	data, err := os.ReadFile(tmpPath)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to read video thumbnail: %w", err)
-	}
-
-	if len(data) == 0 {
-		return nil, "", fmt.Errorf("ffmpeg produced empty thumbnail for %s", filepath.Base(videoPath))
+		return nil, "", err
 	}
 
 	log.Debug().
@@ -153,6 +156,59 @@ func GenerateVideoThumbnail(videoPath string, maxDimension int) ([]byte, string,
 	return data, "image/jpeg", nil
 }
 
+// extractVideoFrame writes the frame at offset to outPath as a JPEG and
+// returns its bytes.
+//
+// ffmpeg -ss 4.2 -i input.mp4 -frames:v 1 -vf "scale='min(1024,iw)':-2" -y output.jpg
+// -ss before -i: input seeking, which jumps to the nearest keyframe and
+// decodes forward, so long videos are not decoded from the start
+// scale filter: downscale only if larger, preserve aspect ratio, ensure even height
+func extractVideoFrame(ffmpegPath, videoPath, outPath string, offset time.Duration, maxDimension int) ([]byte, error) {
+	os.Remove(outPath)
+
+	var args []string
+	if offset > 0 {
+		args = append(args, "-ss", strconv.FormatFloat(offset.Seconds(), 'f', 3, 64))
+	}
+	args = append(args,
+		"-i", videoPath,
+		"-frames:v", "1",
+		"-vf", fmt.Sprintf("scale='min(%d,iw)':-2", maxDimension),
+		"-f", "image2",
+		"-y", outPath,
+	)
+
+	output, err := exec.Command(ffmpegPath, args...).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg frame extraction at %s failed: %w: %s", offset, err, string(output))
+	}
+
+	// Seeking past the last frame exits 0 without writing a file.
+	data, err := os.ReadFile(outPath)
+	if err != nil || len(data) == 0 {
+		return nil, fmt.Errorf("ffmpeg produced empty thumbnail for %s at %s", filepath.Base(videoPath), offset)
+	}
+	return data, nil
+}
+
+// videoDuration returns the duration of a video MediaFile from its
+// metadata, probing with ffprobe when the caller built the MediaFile
+// without it. Returns zero when unknown.
+func videoDuration(mediaFile *MediaFile) time.Duration {
+	if meta, ok := mediaFile.Metadata.(*VideoMetadata); ok && meta != nil && meta.Duration > 0 {
+		return meta.Duration
+	}
+	if !IsFFprobeAvailable() {
+		return 0
+	}
+	meta, err := ExtractVideoMetadata(mediaFile.Path)
+	if err != nil {
+		log.Debug().Err(err).Str("path", mediaFile.Path).Msg("Could not probe video duration, using first frame")
+		return 0
+	}
+	return meta.Duration
+}
+
 // generateThumbnailPureGo resizes JPEG/PNG images using pure Go.
 func generateThumbnailPureGo(filePath, ext string, maxDimension int) ([]byte, string, error) {
 	log.Debug().
@@ -226,22 +282,39 @@ func generateThumbnailPureGo(filePath, ext string, maxDimension int) ([]byte, st
 	return buf.Bytes(), "image/jpeg", nil
 }
 
-// generateThumbnailHEIC uses ffmpeg to convert HEIC/HEIF to a JPEG thumbnail.
-// This replaces the macOS-only sips tool (DDR-027) and works cross-platform:
-// locally (if ffmpeg is installed) and in Lambda (ffmpeg bundled in container image).
-// Falls back to returning the original HEIC file if ffmpeg is unavailable.
+// generateThumbnailHEIC converts HEIC/HEIF to a JPEG thumbnail.
+// A JPEG-coded picture in the container is used when it is large enough, or
+// when ffmpeg is unavailable (DDR-116). Otherwise ffmpeg decodes the HEVC
+// primary image; it replaces the macOS-only sips tool (DDR-027) and works
+// locally (if installed) and in Lambda (bundled in the heavy container image).
+// Falls back to returning the original HEIC file if neither works.
 func generateThumbnailHEIC(filePath string, maxDimension int) ([]byte, string, error) {
 	log.Debug().
 		Str("path", filePath).
 		Int("max_dimension", maxDimension).
 		Msg("Generating HEIC thumbnail")
 
-	// Check if ffmpeg is available
+	img, nativeErr := DecodeHEIF(filePath)
 	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if nativeErr == nil && (err != nil || maxSide(img) >= maxDimension) {
+		data, err := encodeJPEGThumbnail(img, maxDimension, 80)
+		if err != nil {
+			return nil, "", err
+		}
+		log.Debug().
+			Str("file", filepath.Base(filePath)).
+			Int("source_width", img.Bounds().Dx()).
+			Int("source_height", img.Bounds().Dy()).
+			Int("thumb_size", len(data)).
+			Msg("Thumbnail generated (native HEIF)")
+		return data, "image/jpeg", nil
+	}
+
 	if err != nil {
 		log.Warn().
+			Err(nativeErr).
 			Str("file", filePath).
-			Msg("ffmpeg not found, falling back to original HEIC file for thumbnail")
+			Msg("ffmpeg not found and no JPEG picture in HEIC, falling back to original HEIC file for thumbnail")
 
 		data, err := os.ReadFile(filePath)
 		if err != nil {
@@ -298,6 +371,40 @@ func generateThumbnailHEIC(filePath string, maxDimension int) ([]byte, string, e
 	return data, "image/jpeg", nil
 }
 
+// generateThumbnailRAW resizes a RAW file's embedded JPEG preview. Unlike
+// HEIC there is no fallback: the RAW bytes are useless to a browser.
+func generateThumbnailRAW(filePath string, maxDimension int) ([]byte, string, error) {
+	img, err := DecodeRAWPreview(filePath)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := encodeJPEGThumbnail(img, maxDimension, 80)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, "image/jpeg", nil
+}
+
+// encodeJPEGThumbnail scales img to fit within maxDimension (never up) and
+// encodes it as JPEG.
+func encodeJPEGThumbnail(img image.Image, maxDimension, quality int) ([]byte, error) {
+	bounds := img.Bounds()
+	newWidth, newHeight := calculateThumbnailDimensions(bounds.Dx(), bounds.Dy(), maxDimension)
+	resized := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+	draw.CatmullRom.Scale(resized, resized.Bounds(), img, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// maxSide returns the larger of img's width and height.
+func maxSide(img image.Image) int {
+	return max(img.Bounds().Dx(), img.Bounds().Dy())
+}
+
 // calculateThumbnailDimensions calculates new dimensions maintaining aspect ratio.
 func calculateThumbnailDimensions(width, height, maxDimension int) (int, int) {
 	if width <= maxDimension && height <= maxDimension {
@@ -0,0 +1,261 @@
+package media
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+	_ "time/tzdata" // Lambda base images are not guaranteed to ship zoneinfo
+)
+
+// Capture time zones (DDR-117). EXIF DateTimeOriginal is a wall-clock time
+// with no zone, and QuickTime creation_time is a UTC instant. Mixing the two
+// and formatting both in the Lambda's UTC puts a late-evening photo on the
+// next day and orders videos hours away from the photos around them. Every
+// capture time is therefore resolved to the zone it was taken in, so that
+// GetDate returns the correct instant in local time.
+
+// Zone sources, from most to least reliable, recorded in ZoneSource.
+const (
+	ZoneFromOffset      = "offset"       // EXIF OffsetTimeOriginal or QuickTime creationdate offset
+	ZoneFromGPSTime     = "gps-time"     // wall clock compared with the UTC GPS timestamp
+	ZoneFromGPSLocation = "gps-location" // nearest reference zone to the GPS coordinates
+	ZoneFromSession     = "session"      // borrowed from the nearest file in time with a zone
+)
+
+// maxZoneRefDistanceKm is how far GPS coordinates may be from the nearest
+// reference zone before ZoneForLocation falls back to a longitude offset.
+const maxZoneRefDistanceKm = 800
+
+// zoneRef is a reference point for one IANA zone. Points are major cities;
+// a zone spanning a large area has several.
+type zoneRef struct {
+	lat, lon float64
+	name     string
+}
+
+var zoneRefs = []zoneRef{
+	// North America
+	{34.05, -118.24, "America/Los_Angeles"}, {37.77, -122.42, "America/Los_Angeles"}, {47.61, -122.33, "America/Los_Angeles"},
+	{49.28, -123.12, "America/Vancouver"}, {39.74, -104.99, "America/Denver"}, {33.45, -112.07, "America/Phoenix"},
+	{53.55, -113.49, "America/Edmonton"}, {51.05, -114.07, "America/Edmonton"}, {50.45, -104.61, "America/Regina"},
+	{41.88, -87.63, "America/Chicago"}, {29.76, -95.37, "America/Chicago"}, {32.78, -96.80, "America/Chicago"},
+	{44.98, -93.27, "America/Chicago"}, {29.95, -90.07, "America/Chicago"}, {49.90, -97.14, "America/Winnipeg"},
+	{40.71, -74.01, "America/New_York"}, {38.90, -77.04, "America/New_York"}, {42.36, -71.06, "America/New_York"},
+	{33.75, -84.39, "America/New_York"}, {25.76, -80.19, "America/New_York"}, {28.54, -81.38, "America/New_York"},
+	{42.33, -83.05, "America/Detroit"}, {43.65, -79.38, "America/Toronto"}, {45.50, -73.57, "America/Toronto"},
+	{44.65, -63.57, "America/Halifax"}, {47.56, -52.71, "America/St_Johns"}, {61.22, -149.90, "America/Anchorage"},
+	{21.31, -157.86, "Pacific/Honolulu"}, {19.43, -99.13, "America/Mexico_City"}, {20.67, -103.35, "America/Mexico_City"},
+	{21.16, -86.85, "America/Cancun"}, {32.51, -117.04, "America/Tijuana"}, {23.11, -82.37, "America/Havana"},
+	{18.47, -66.11, "America/Puerto_Rico"}, {9.93, -84.08, "America/Costa_Rica"}, {8.98, -79.52, "America/Panama"},
+	// South America
+	{4.71, -74.07, "America/Bogota"}, {10.48, -66.90, "America/Caracas"}, {-2.17, -79.92, "America/Guayaquil"},
+	{-12.05, -77.04, "America/Lima"}, {-13.53, -71.97, "America/Lima"}, {-33.45, -70.67, "America/Santiago"},
+	{-34.60, -58.38, "America/Argentina/Buenos_Aires"}, {-23.55, -46.63, "America/Sao_Paulo"},
+	{-22.91, -43.17, "America/Sao_Paulo"}, {-3.12, -60.02, "America/Manaus"},
+	// Europe
+	{51.51, -0.13, "Europe/London"}, {55.95, -3.19, "Europe/London"}, {53.35, -6.26, "Europe/Dublin"},
+	{64.15, -21.94, "Atlantic/Reykjavik"}, {38.72, -9.14, "Europe/Lisbon"}, {41.15, -8.61, "Europe/Lisbon"},
+	{28.12, -15.43, "Atlantic/Canary"}, {40.42, -3.70, "Europe/Madrid"}, {41.39, 2.17, "Europe/Madrid"},
+	{37.39, -5.98, "Europe/Madrid"}, {48.86, 2.35, "Europe/Paris"}, {43.30, 5.37, "Europe/Paris"},
+	{50.85, 4.35, "Europe/Brussels"}, {52.37, 4.90, "Europe/Amsterdam"}, {52.52, 13.40, "Europe/Berlin"},
+	{48.14, 11.58, "Europe/Berlin"}, {47.38, 8.54, "Europe/Zurich"}, {41.90, 12.50, "Europe/Rome"},
+	{45.46, 9.19, "Europe/Rome"}, {40.85, 14.27, "Europe/Rome"}, {48.21, 16.37, "Europe/Vienna"},
+	{50.08, 14.44, "Europe/Prague"}, {55.68, 12.57, "Europe/Copenhagen"}, {59.91, 10.75, "Europe/Oslo"},
+	{59.33, 18.07, "Europe/Stockholm"}, {60.17, 24.94, "Europe/Helsinki"}, {52.23, 21.01, "Europe/Warsaw"},
+	{47.50, 19.04, "Europe/Budapest"}, {45.81, 15.98, "Europe/Zagreb"}, {37.98, 23.73, "Europe/Athens"},
+	{44.43, 26.10, "Europe/Bucharest"}, {41.01, 28.98, "Europe/Istanbul"}, {50.45, 30.52, "Europe/Kyiv"},
+	{55.76, 37.62, "Europe/Moscow"},
+	// Africa and the Middle East
+	{33.57, -7.59, "Africa/Casablanca"}, {30.04, 31.24, "Africa/Cairo"}, {6.52, 3.38, "Africa/Lagos"},
+	{-1.29, 36.82, "Africa/Nairobi"}, {-26.20, 28.05, "Africa/Johannesburg"}, {-33.92, 18.42, "Africa/Johannesburg"},
+	{31.77, 35.21, "Asia/Jerusalem"}, {25.20, 55.27, "Asia/Dubai"}, {25.29, 51.53, "Asia/Qatar"},
+	{24.71, 46.68, "Asia/Riyadh"}, {35.69, 51.39, "Asia/Tehran"},
+	// Asia
+	{24.86, 67.01, "Asia/Karachi"}, {28.61, 77.21, "Asia/Kolkata"}, {19.08, 72.88, "Asia/Kolkata"},
+	{12.97, 77.59, "Asia/Kolkata"}, {6.93, 79.86, "Asia/Colombo"}, {27.72, 85.32, "Asia/Kathmandu"},
+	{23.81, 90.41, "Asia/Dhaka"}, {16.87, 96.20, "Asia/Yangon"}, {13.76, 100.50, "Asia/Bangkok"},
+	{18.79, 98.98, "Asia/Bangkok"}, {10.82, 106.63, "Asia/Ho_Chi_Minh"}, {21.03, 105.85, "Asia/Ho_Chi_Minh"},
+	{3.14, 101.69, "Asia/Kuala_Lumpur"}, {1.35, 103.82, "Asia/Singapore"}, {-6.21, 106.85, "Asia/Jakarta"},
+	{-8.65, 115.22, "Asia/Makassar"}, {14.60, 120.98, "Asia/Manila"}, {22.32, 114.17, "Asia/Hong_Kong"},
+	{31.23, 121.47, "Asia/Shanghai"}, {39.90, 116.41, "Asia/Shanghai"}, {30.57, 104.07, "Asia/Shanghai"},
+	{25.03, 121.57, "Asia/Taipei"}, {37.57, 126.98, "Asia/Seoul"}, {35.18, 129.08, "Asia/Seoul"},
+	{35.68, 139.69, "Asia/Tokyo"}, {34.69, 135.50, "Asia/Tokyo"}, {43.06, 141.35, "Asia/Tokyo"},
+	{26.21, 127.68, "Asia/Tokyo"}, {47.89, 106.91, "Asia/Ulaanbaatar"}, {43.24, 76.89, "Asia/Almaty"},
+	{41.30, 69.24, "Asia/Tashkent"}, {43.12, 131.89, "Asia/Vladivostok"},
+	// Oceania
+	{-31.95, 115.86, "Australia/Perth"}, {-12.46, 130.84, "Australia/Darwin"}, {-34.93, 138.60, "Australia/Adelaide"},
+	{-27.47, 153.03, "Australia/Brisbane"}, {-16.92, 145.77, "Australia/Brisbane"}, {-33.87, 151.21, "Australia/Sydney"},
+	{-37.81, 144.96, "Australia/Melbourne"}, {-42.88, 147.33, "Australia/Hobart"}, {-36.85, 174.76, "Pacific/Auckland"},
+	{-45.03, 168.66, "Pacific/Auckland"}, {-18.14, 178.44, "Pacific/Fiji"}, {-17.53, -149.57, "Pacific/Tahiti"},
+	{13.44, 144.79, "Pacific/Guam"},
+}
+
+// ZoneForLocation returns the time zone for GPS coordinates: the IANA zone of
+// the nearest reference city within maxZoneRefDistanceKm, or else a fixed
+// offset of one hour per 15° of longitude. Both are approximations near
+// zone borders.
+func ZoneForLocation(lat, lon float64) *time.Location {
+	best, bestDist := "", math.Inf(1)
+	for _, z := range zoneRefs {
+		if d := DistanceKm(lat, lon, z.lat, z.lon); d < bestDist {
+			best, bestDist = z.name, d
+		}
+	}
+	if bestDist <= maxZoneRefDistanceKm {
+		if loc, err := time.LoadLocation(best); err == nil {
+			return loc
+		}
+	}
+	hours := int(math.Round(lon / 15))
+	return time.FixedZone(fmt.Sprintf("UTC%+d", hours), hours*3600)
+}
+
+// inZone reinterprets a wall-clock time (parsed as UTC) as local time in loc.
+func inZone(wall time.Time, loc *time.Location) time.Time {
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), loc)
+}
+
+// zoneFromGPSTime derives the UTC offset of a wall-clock time from the GPS
+// timestamp (always UTC) recorded with it. The offset is rounded to 15
+// minutes, since the GPS fix may be a little older than the shutter. Returns
+// false when the difference is not a plausible UTC offset.
+func zoneFromGPSTime(wall, gpsUTC time.Time) (*time.Location, bool) {
+	if gpsUTC.IsZero() {
+		return nil, false
+	}
+	diff := inZone(wall, time.UTC).Sub(gpsUTC)
+	offset := diff.Round(15 * time.Minute)
+	if offset < -12*time.Hour || offset > 14*time.Hour || (diff-offset).Abs() > 5*time.Minute {
+		return nil, false
+	}
+	secs := int(offset.Seconds())
+	return time.FixedZone(fmt.Sprintf("UTC%+03d:%02d", secs/3600, (secs%3600/60+60)%60), secs), true
+}
+
+// ResolveTimeZones gives files whose capture zone is unknown the zone of the
+// file nearest in time that has one, on the assumption that one upload is
+// one trip. Photos keep their wall clock; videos keep their UTC instant.
+// Returns the number of files updated.
+func ResolveTimeZones(files []*MediaFile) int {
+	type known struct {
+		t   time.Time
+		loc *time.Location
+	}
+	var refs []known
+	for _, f := range files {
+		if f.Metadata == nil || !f.Metadata.HasDateData() {
+			continue
+		}
+		if src := zoneSource(f.Metadata); src != "" && src != ZoneFromSession {
+			refs = append(refs, known{f.Metadata.GetDate(), f.Metadata.GetDate().Location()})
+		}
+	}
+	if len(refs) == 0 {
+		return 0
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].t.Before(refs[j].t) })
+
+	nearest := func(t time.Time) *time.Location {
+		i := sort.Search(len(refs), func(i int) bool { return !refs[i].t.Before(t) })
+		switch {
+		case i == 0:
+			return refs[0].loc
+		case i == len(refs):
+			return refs[len(refs)-1].loc
+		case t.Sub(refs[i-1].t) <= refs[i].t.Sub(t):
+			return refs[i-1].loc
+		default:
+			return refs[i].loc
+		}
+	}
+
+	updated := 0
+	for _, f := range files {
+		switch m := f.Metadata.(type) {
+		case *ImageMetadata:
+			if m.HasDate && m.ZoneSource == "" {
+				// The wall clock is local already; estimate the instant in UTC
+				// to find the neighbour, then keep the wall clock in its zone.
+				m.DateTaken = inZone(m.DateTaken, nearest(m.DateTaken))
+				m.ZoneSource = ZoneFromSession
+				updated++
+			}
+		case *VideoMetadata:
+			if m.HasDate && m.ZoneSource == "" {
+				m.CreateDate = m.CreateDate.In(nearest(m.CreateDate))
+				m.ZoneSource = ZoneFromSession
+				updated++
+			}
+		}
+	}
+	return updated
+}
+
+// zoneSource returns how md's capture zone was determined, or "" if unknown.
+func zoneSource(md MediaMetadata) string {
+	switch m := md.(type) {
+	case *ImageMetadata:
+		return m.ZoneSource
+	case *VideoMetadata:
+		return m.ZoneSource
+	}
+	return ""
+}
+
+// HasCaptureZone reports whether md's capture zone is known, so that its
+// date can be shown with a UTC offset.
+func HasCaptureZone(md MediaMetadata) bool {
+	return zoneSource(md) != ""
+}
+
+// FormatCaptureTime formats a capture time for prompts in its local zone,
+// e.g. "Monday, January 2, 2006 at 3:04 PM". The UTC offset is appended
+// when the zone is known, so the model does not re-interpret the time.
+func FormatCaptureTime(md MediaMetadata) string {
+	t := md.GetDate()
+	s := t.Format("Monday, January 2, 2006 at 3:04 PM")
+	if HasCaptureZone(md) {
+		s += t.Format(" (UTC-07:00)")
+	}
+	return s
+}
+
+// resolveImageZone places a photo's EXIF wall clock in the zone it was taken
+// in, trying the EXIF offset, then the GPS timestamp, then the GPS location.
+// The zone stays unknown when none apply; ResolveTimeZones may fill it later.
+func resolveImageZone(m *ImageMetadata, gpsUTC time.Time) {
+	if m.DateTaken.Location() != time.UTC {
+		// imagemeta applies OffsetTimeOriginal when present.
+		m.ZoneSource = ZoneFromOffset
+		return
+	}
+	if loc, ok := zoneFromGPSTime(m.DateTaken, gpsUTC); ok {
+		m.DateTaken = inZone(m.DateTaken, loc)
+		m.ZoneSource = ZoneFromGPSTime
+		return
+	}
+	if m.HasGPS {
+		m.DateTaken = inZone(m.DateTaken, ZoneForLocation(m.Latitude, m.Longitude))
+		m.ZoneSource = ZoneFromGPSLocation
+	}
+}
+
+// resolveVideoZone converts a video's UTC creation time to the zone of its
+// GPS location, unless the zone is already known from the container.
+func resolveVideoZone(m *VideoMetadata) {
+	if m.ZoneSource != "" || !m.HasDate || !m.HasGPS {
+		return
+	}
+	m.CreateDate = m.CreateDate.In(ZoneForLocation(m.Latitude, m.Longitude))
+	m.ZoneSource = ZoneFromGPSLocation
+}
+
+// formatClock formats the time of day for FormatMetadataContext, with the
+// UTC offset when the zone is known.
+func formatClock(md MediaMetadata) string {
+	if zoneSource(md) == "" {
+		return md.GetDate().Format("3:04 PM")
+	}
+	return md.GetDate().Format("3:04 PM (UTC-07:00)")
+}
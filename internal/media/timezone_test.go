@@ -0,0 +1,99 @@
+package media
+
+import (
+	"testing"
+	"time"
+)
+
+func TestZoneForLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lon float64
+		want     string
+	}{
+		{"Tokyo", 35.66, 139.70, "Asia/Tokyo"},
+		{"Seattle suburb", 47.67, -122.12, "America/Los_Angeles"},
+		{"Lisbon", 38.71, -9.13, "Europe/Lisbon"},
+		{"mid-Pacific", 0, -150, "UTC-10"},
+	}
+	for _, tt := range tests {
+		if got := ZoneForLocation(tt.lat, tt.lon).String(); got != tt.want {
+			t.Errorf("%s: ZoneForLocation(%v, %v) = %s, want %s", tt.name, tt.lat, tt.lon, got, tt.want)
+		}
+	}
+}
+
+func TestResolveImageZone(t *testing.T) {
+	wall := time.Date(2026, 7, 4, 23, 30, 0, 0, time.UTC) // 11:30 PM local, no offset tag
+
+	// GPS timestamp 06:29 UTC the next day → UTC-07:00.
+	m := &ImageMetadata{DateTaken: wall, HasDate: true}
+	resolveImageZone(m, time.Date(2026, 7, 5, 6, 29, 0, 0, time.UTC))
+	if m.ZoneSource != ZoneFromGPSTime {
+		t.Fatalf("ZoneSource = %q, want %q", m.ZoneSource, ZoneFromGPSTime)
+	}
+	if _, off := m.DateTaken.Zone(); off != -7*3600 {
+		t.Errorf("offset = %d, want %d", off, -7*3600)
+	}
+	if m.DateTaken.Day() != 4 || m.DateTaken.Hour() != 23 {
+		t.Errorf("wall clock changed: %v", m.DateTaken)
+	}
+
+	// No GPS timestamp: fall back to the GPS location.
+	m = &ImageMetadata{DateTaken: wall, HasDate: true, Latitude: 35.66, Longitude: 139.70, HasGPS: true}
+	resolveImageZone(m, time.Time{})
+	if m.ZoneSource != ZoneFromGPSLocation || m.DateTaken.Location().String() != "Asia/Tokyo" {
+		t.Errorf("got %q in %s, want gps-location in Asia/Tokyo", m.ZoneSource, m.DateTaken.Location())
+	}
+
+	// Neither: zone stays unknown.
+	m = &ImageMetadata{DateTaken: wall, HasDate: true}
+	resolveImageZone(m, time.Time{})
+	if m.ZoneSource != "" || m.DateTaken != wall {
+		t.Errorf("got %q %v, want unchanged", m.ZoneSource, m.DateTaken)
+	}
+}
+
+func TestResolveTimeZones(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	files := []*MediaFile{
+		{Path: "a.jpg", Metadata: &ImageMetadata{
+			DateTaken: time.Date(2026, 7, 4, 20, 0, 0, 0, tokyo), HasDate: true, ZoneSource: ZoneFromOffset,
+		}},
+		// Wall clock only: 11:50 PM local must stay on July 4.
+		{Path: "b.jpg", Metadata: &ImageMetadata{
+			DateTaken: time.Date(2026, 7, 4, 23, 50, 0, 0, time.UTC), HasDate: true,
+		}},
+		// UTC instant: 15:10 UTC is 00:10 on July 5 in Tokyo.
+		{Path: "c.mov", Metadata: &VideoMetadata{
+			CreateDate: time.Date(2026, 7, 4, 15, 10, 0, 0, time.UTC), HasDate: true,
+		}},
+		{Path: "d.png", Metadata: &ImageMetadata{}},
+	}
+
+	if n := ResolveTimeZones(files); n != 2 {
+		t.Fatalf("updated %d files, want 2", n)
+	}
+	b := files[1].Metadata.(*ImageMetadata)
+	if b.DateTaken.Location() != tokyo || b.DateTaken.Day() != 4 || b.DateTaken.Hour() != 23 {
+		t.Errorf("b.jpg = %v, want 23:50 July 4 in Tokyo", b.DateTaken)
+	}
+	c := files[2].Metadata.(*VideoMetadata)
+	if c.CreateDate.Location() != tokyo || c.CreateDate.Day() != 5 || c.CreateDate.Hour() != 0 {
+		t.Errorf("c.mov = %v, want 00:10 July 5 in Tokyo", c.CreateDate)
+	}
+	if c.ZoneSource != ZoneFromSession {
+		t.Errorf("c.mov ZoneSource = %q, want %q", c.ZoneSource, ZoneFromSession)
+	}
+}
+
+func TestFormatCaptureTime(t *testing.T) {
+	m := &ImageMetadata{DateTaken: time.Date(2026, 7, 4, 21, 5, 0, 0, time.FixedZone("", -7*3600)), HasDate: true}
+	if got, want := FormatCaptureTime(m), "Saturday, July 4, 2026 at 9:05 PM"; got != want {
+		t.Errorf("unknown zone: got %q, want %q", got, want)
+	}
+	m.ZoneSource = ZoneFromOffset
+	if got, want := FormatCaptureTime(m), "Saturday, July 4, 2026 at 9:05 PM (UTC-07:00)"; got != want {
+		t.Errorf("known zone: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,403 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+	"github.com/rs/zerolog/log"
+)
+
+// Video transcode profiles (DDR-127). Each call site picks the output it
+// needs by name instead of carrying its own ffmpeg command line. Profiles
+// use software encoders only, so the same input produces the same output on
+// a laptop, in a Lambda container and in CI.
+
+// TranscodeProfile describes one ffmpeg output. Zero values keep the source
+// attribute, and no attribute is ever upscaled.
+type TranscodeProfile struct {
+	Name string
+	// Container is the output file extension; ffmpeg picks the muxer from it.
+	Container string
+
+	VideoCodec string // ffmpeg software encoder, e.g. "libsvtav1", "libx264"
+	// Preset is the encoder preset. Empty with libsvtav1 selects the preset
+	// from the video's duration (DDR-067).
+	Preset string
+	CRF    int
+	// MaxBitrate caps the video bitrate (VBV, buffer twice the rate) on top
+	// of CRF, e.g. "8M" for platform upload limits. Empty leaves CRF alone.
+	MaxBitrate string
+	// MaxWidth scales the width down to at most this, keeping aspect ratio.
+	MaxWidth int
+	// FrameWidth and FrameHeight, when set, produce a fixed canvas: the
+	// source is scaled to fit (down only) and letterboxed. Overrides MaxWidth.
+	FrameWidth  int
+	FrameHeight int
+	MaxFPS      float64
+	PixelFormat string
+
+	// AudioCodec is the ffmpeg audio encoder; empty drops audio.
+	AudioCodec    string
+	AudioBitrate  string
+	AudioChannels int
+	// AudioRate fixes the output sample rate. Zero keeps the source rate,
+	// rounded up to a supported rate for Opus.
+	AudioRate int
+
+	// ExtraArgs are appended before the output path, e.g. encoder profile
+	// or container flags.
+	ExtraArgs []string
+}
+
+// Named transcode profiles. Look them up by name with GetTranscodeProfile.
+var (
+	// ProfileGeminiAnalysis is the low-resolution AV1/Opus WebM sent to
+	// Gemini for triage and selection (DDR-018).
+	ProfileGeminiAnalysis = TranscodeProfile{
+		Name:          "gemini-analysis",
+		Container:     "webm",
+		VideoCodec:    "libsvtav1",
+		CRF:           VideoCRF,
+		MaxWidth:      MaxResolution,
+		MaxFPS:        MaxFrameRate,
+		PixelFormat:   "yuv420p",
+		AudioCodec:    "libopus",
+		AudioBitrate:  AudioBitrate,
+		AudioChannels: 1,
+	}
+
+	// ProfileGeminiCaptions is the silent 1 FPS variant for caption and
+	// description workloads, which only need the visuals.
+	ProfileGeminiCaptions = TranscodeProfile{
+		Name:        "gemini-captions",
+		Container:   "webm",
+		VideoCodec:  "libsvtav1",
+		CRF:         40,
+		MaxWidth:    768,
+		MaxFPS:      1,
+		PixelFormat: "yuv420p",
+	}
+
+	// ProfileInstagramReel matches Instagram's Reels upload spec: a
+	// 1080x1920 H.264 High MP4 at up to 30 FPS with AAC stereo audio and
+	// the index at the front of the file.
+	ProfileInstagramReel = TranscodeProfile{
+		Name:          "instagram-reel",
+		Container:     "mp4",
+		VideoCodec:    "libx264",
+		Preset:        "medium",
+		CRF:           21,
+		MaxBitrate:    "8M",
+		FrameWidth:    1080,
+		FrameHeight:   1920,
+		MaxFPS:        30,
+		PixelFormat:   "yuv420p",
+		AudioCodec:    "aac",
+		AudioBitrate:  "128k",
+		AudioChannels: 2,
+		AudioRate:     48000,
+		ExtraArgs:     []string{"-profile:v", "high", "-movflags", "+faststart"},
+	}
+
+	// ProfileInstagramFeed is the same H.264/AAC encode as the reel profile
+	// but keeps the source aspect ratio, for carousel videos and for reels
+	// that only need a codec change (DDR-129). Set FrameWidth and
+	// FrameHeight on a copy to letterbox into an allowed aspect ratio.
+	ProfileInstagramFeed = TranscodeProfile{
+		Name:          "instagram-feed",
+		Container:     "mp4",
+		VideoCodec:    "libx264",
+		Preset:        "medium",
+		CRF:           21,
+		MaxBitrate:    "8M",
+		MaxWidth:      1080,
+		MaxFPS:        30,
+		PixelFormat:   "yuv420p",
+		AudioCodec:    "aac",
+		AudioBitrate:  "128k",
+		AudioChannels: 2,
+		AudioRate:     48000,
+		ExtraArgs:     []string{"-profile:v", "high", "-movflags", "+faststart"},
+	}
+
+	// ProfileArchive keeps source resolution and frame rate in a compact
+	// HEVC MP4 for long-term storage.
+	ProfileArchive = TranscodeProfile{
+		Name:          "archive",
+		Container:     "mp4",
+		VideoCodec:    "libx265",
+		Preset:        "medium",
+		CRF:           22,
+		PixelFormat:   "yuv420p",
+		AudioCodec:    "aac",
+		AudioBitrate:  "192k",
+		AudioChannels: 2,
+		ExtraArgs:     []string{"-tag:v", "hvc1", "-movflags", "+faststart"},
+	}
+)
+
+var transcodeProfiles = map[string]TranscodeProfile{
+	ProfileGeminiAnalysis.Name: ProfileGeminiAnalysis,
+	ProfileGeminiCaptions.Name: ProfileGeminiCaptions,
+	ProfileInstagramReel.Name:  ProfileInstagramReel,
+	ProfileInstagramFeed.Name:  ProfileInstagramFeed,
+	ProfileArchive.Name:        ProfileArchive,
+}
+
+// GetTranscodeProfile returns the named profile.
+func GetTranscodeProfile(name string) (TranscodeProfile, bool) {
+	p, ok := transcodeProfiles[name]
+	return p, ok
+}
+
+// Transcode encodes a video with the given profile into a temporary file.
+// Metadata should come from the ORIGINAL file, since transcoding may strip
+// vendor-specific metadata; it is used to avoid upscaling and to pick the
+// adaptive preset.
+//
+// The cleanup function MUST be called to remove the temporary file.
+func Transcode(ctx context.Context, inputPath string, meta *VideoMetadata, profile TranscodeProfile) (
+	outputPath string,
+	outputSize int64,
+	cleanup func(),
+	err error,
+) {
+	return transcode(ctx, inputPath, meta, profile, nil)
+}
+
+// transcode runs Transcode, over only clip of the input when it is set.
+func transcode(ctx context.Context, inputPath string, meta *VideoMetadata, profile TranscodeProfile, clip *VideoClip) (
+	outputPath string,
+	outputSize int64,
+	cleanup func(),
+	err error,
+) {
+	var inputSize int64
+	if inputInfo, err := os.Stat(inputPath); err == nil {
+		inputSize = inputInfo.Size()
+	}
+
+	log.Info().
+		Str("input_path", inputPath).
+		Int64("input_size_bytes", inputSize).
+		Str("profile", profile.Name).
+		Str("codec", profile.VideoCodec).
+		Int("crf", profile.CRF).
+		Msg("Starting video transcode")
+
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return "", 0, nil, fmt.Errorf("ffmpeg not found in PATH: %w", err)
+	}
+
+	tempFile, err := os.CreateTemp("", fmt.Sprintf("transcode-%s-*.%s", profile.Name, profile.Container))
+	if err != nil {
+		return "", 0, nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	outputPath = tempFile.Name()
+	tempFile.Close()
+
+	cleanup = func() {
+		if err := os.Remove(outputPath); err != nil && !os.IsNotExist(err) {
+			log.Warn().Err(err).Str("path", outputPath).Msg("Failed to remove transcoded temp file")
+		} else {
+			log.Debug().Str("path", outputPath).Msg("Transcoded temp file removed")
+		}
+	}
+
+	args := BuildTranscodeArgs(profile, inputPath, outputPath, meta)
+	var duration time.Duration
+	if meta != nil && meta.Duration > 0 {
+		duration = meta.Duration
+	}
+	if clip != nil {
+		args = BuildTrimArgs(profile, inputPath, outputPath, meta, *clip)
+		duration = clip.Duration()
+	}
+	log.Debug().Strs("args", args).Str("profile", profile.Name).Msg("Running FFmpeg transcode")
+
+	ffmpegStart := time.Now()
+	var output []byte
+	if report := progressFrom(ctx); report != nil && duration > 0 {
+		// Live progress from ffmpeg -progress (DDR-161).
+		cmd := exec.CommandContext(ctx, ffmpegPath, append(slices.Clone(progressArgs), args...)...)
+		output, err = runWithProgress(cmd, duration, func(percent int) { report(inputPath, percent) })
+	} else {
+		output, err = exec.CommandContext(ctx, ffmpegPath, args...).CombinedOutput()
+	}
+	ffmpegElapsed := time.Since(ffmpegStart)
+	metrics.RecordFFmpeg(ctx, ffmpegElapsed)
+	if err != nil {
+		cleanup()
+		log.Warn().
+			Err(err).
+			Str("input_path", inputPath).
+			Str("profile", profile.Name).
+			Str("ffmpeg_output", string(output)).
+			Dur("duration", ffmpegElapsed).
+			Msg("FFmpeg transcode failed")
+		metrics.New("AiSocialMedia").
+			Metric("VideoCompressionMs", float64(ffmpegElapsed.Milliseconds()), metrics.UnitMilliseconds).
+			Count("VideoCompressionErrors").
+			Property("profile", profile.Name).
+			Flush()
+		return "", 0, nil, fmt.Errorf("ffmpeg compression failed: %w\nOutput: %s", err, string(output))
+	}
+
+	info, err := os.Stat(outputPath)
+	if err != nil {
+		cleanup()
+		return "", 0, nil, fmt.Errorf("failed to stat compressed file: %w", err)
+	}
+	outputSize = info.Size()
+
+	compressionRatio := float64(0)
+	if outputSize > 0 {
+		compressionRatio = float64(inputSize) / float64(outputSize)
+	}
+
+	metrics.New("AiSocialMedia").
+		Metric("VideoCompressionMs", float64(ffmpegElapsed.Milliseconds()), metrics.UnitMilliseconds).
+		Metric("MediaFileSizeBytes", float64(inputSize), metrics.UnitBytes).
+		Metric("VideoCompressionRatio", compressionRatio, metrics.UnitNone).
+		Count("VideoCompressions").
+		Property("profile", profile.Name).
+		Flush()
+
+	log.Info().
+		Str("input_path", inputPath).
+		Str("output_path", outputPath).
+		Str("profile", profile.Name).
+		Int64("input_size_bytes", inputSize).
+		Int64("output_size_bytes", outputSize).
+		Dur("duration", duration).
+		Dur("compression_time", ffmpegElapsed).
+		Float64("compression_ratio", compressionRatio).
+		Msg("Video transcode complete")
+
+	return outputPath, outputSize, cleanup, nil
+}
+
+// BuildTranscodeArgs constructs the ffmpeg arguments for a profile. meta may
+// be nil; frame rate and audio rate then fall back to the profile's caps.
+func BuildTranscodeArgs(profile TranscodeProfile, inputPath, outputPath string, meta *VideoMetadata) []string {
+	args := []string{"-i", inputPath}
+
+	// Video codec and rate control
+	args = append(args, "-c:v", profile.VideoCodec)
+	switch {
+	case profile.Preset != "":
+		args = append(args, "-preset", profile.Preset)
+	case profile.VideoCodec == "libsvtav1":
+		preset := DefaultVideoPreset
+		if meta != nil && meta.Duration > 0 {
+			preset = SelectPreset(meta.Duration)
+		}
+		args = append(args, "-preset", strconv.Itoa(preset))
+	}
+	args = append(args, "-crf", strconv.Itoa(profile.CRF))
+	if profile.MaxBitrate != "" {
+		args = append(args, "-maxrate", profile.MaxBitrate, "-bufsize", doubleBitrate(profile.MaxBitrate))
+	}
+
+	// Frame rate: min(MaxFPS, source) — never upscale
+	if profile.MaxFPS > 0 {
+		targetFPS := profile.MaxFPS
+		if meta != nil && meta.FrameRate > 0 {
+			targetFPS = minFloat(profile.MaxFPS, meta.FrameRate)
+		}
+		args = append(args, "-r", fmt.Sprintf("%.2f", targetFPS))
+	}
+
+	if vf := transcodeVideoFilter(profile); vf != "" {
+		args = append(args, "-vf", vf)
+	}
+
+	// Audio: video required, audio optional (handles videos without audio)
+	if profile.AudioCodec == "" {
+		args = append(args, "-an")
+	} else {
+		args = append(args, "-map", "0:v:0", "-map", "0:a?")
+		args = append(args, "-c:a", profile.AudioCodec)
+		if profile.AudioBitrate != "" {
+			args = append(args, "-b:a", profile.AudioBitrate)
+		}
+		if profile.AudioCodec == "libopus" {
+			args = append(args, "-vbr", "on")
+		}
+		if profile.AudioChannels > 0 {
+			args = append(args, "-ac", strconv.Itoa(profile.AudioChannels))
+		}
+		if rate := transcodeAudioRate(profile, meta); rate > 0 {
+			args = append(args, "-ar", strconv.Itoa(rate))
+		}
+	}
+
+	args = append(args, profile.ExtraArgs...)
+	args = append(args, "-y", outputPath)
+	return args
+}
+
+// transcodeVideoFilter builds the -vf chain: downscale-only scaling that
+// keeps even dimensions, letterboxing for fixed canvases, then the pixel
+// format.
+func transcodeVideoFilter(profile TranscodeProfile) string {
+	var vf string
+	switch {
+	case profile.FrameWidth > 0 && profile.FrameHeight > 0:
+		w, h := profile.FrameWidth, profile.FrameHeight
+		vf = fmt.Sprintf("scale='min(%d,iw)':'min(%d,ih)':force_original_aspect_ratio=decrease:force_divisible_by=2,pad=%d:%d:(ow-iw)/2:(oh-ih)/2,setsar=1", w, h, w, h)
+	case profile.MaxWidth > 0:
+		// scale='min(768,iw)':-2 keeps aspect ratio and ensures even dimensions
+		vf = fmt.Sprintf("scale='min(%d,iw)':-2", profile.MaxWidth)
+	}
+	if profile.PixelFormat != "" {
+		if vf != "" {
+			vf += ","
+		}
+		vf += "format=" + profile.PixelFormat
+	}
+	return vf
+}
+
+// transcodeAudioRate returns the -ar value for a profile, or 0 to keep the
+// source rate. libopus only supports 48, 24, 16, 12 and 8 kHz, so Opus
+// output rounds the source up and defaults to 48 kHz.
+func transcodeAudioRate(profile TranscodeProfile, meta *VideoMetadata) int {
+	if profile.AudioRate > 0 {
+		return profile.AudioRate
+	}
+	if profile.AudioCodec != "libopus" {
+		return 0
+	}
+	if meta != nil && meta.AudioRate > 0 {
+		return roundUpToOpusSampleRate(meta.AudioRate)
+	}
+	return 48000
+}
+
+// doubleBitrate returns twice an ffmpeg bitrate such as "8M" or "800k", for
+// the VBV buffer size. Unparseable values are returned unchanged.
+func doubleBitrate(rate string) string {
+	if rate == "" {
+		return rate
+	}
+	unit := rate[len(rate)-1:]
+	num := rate
+	if unit == "k" || unit == "K" || unit == "M" || unit == "m" {
+		num = rate[:len(rate)-1]
+	} else {
+		unit = ""
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return rate
+	}
+	return strconv.FormatFloat(n*2, 'f', -1, 64) + unit
+}
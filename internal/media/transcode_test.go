@@ -0,0 +1,92 @@
+package media
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildTranscodeArgs_GeminiAnalysisMatchesLegacyCommand(t *testing.T) {
+	meta := &VideoMetadata{Duration: 30 * time.Minute, FrameRate: 29.97, AudioRate: 44100}
+	got := BuildTranscodeArgs(ProfileGeminiAnalysis, "in.mov", "out.webm", meta)
+	want := []string{
+		"-i", "in.mov",
+		"-c:v", "libsvtav1", "-preset", "6", "-crf", "35",
+		"-r", "5.00",
+		"-vf", "scale='min(768,iw)':-2,format=yuv420p",
+		"-map", "0:v:0", "-map", "0:a?",
+		"-c:a", "libopus", "-b:a", "24k", "-vbr", "on", "-ac", "1", "-ar", "48000",
+		"-y", "out.webm",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("args =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestBuildTranscodeArgs_GeminiCaptionsDropsAudio(t *testing.T) {
+	args := BuildTranscodeArgs(ProfileGeminiCaptions, "in.mp4", "out.webm", nil)
+	assertContains(t, args, "-crf", "40")
+	assertContains(t, args, "-r", "1.00")
+	if !slices.Contains(args, "-an") || slices.Contains(args, "-c:a") {
+		t.Errorf("expected audio to be dropped, got %v", args)
+	}
+}
+
+func TestBuildTranscodeArgs_InstagramReel(t *testing.T) {
+	meta := &VideoMetadata{FrameRate: 24, AudioRate: 44100}
+	args := BuildTranscodeArgs(ProfileInstagramReel, "in.mov", "out.mp4", meta)
+
+	assertContains(t, args, "-c:v", "libx264")
+	assertContains(t, args, "-preset", "medium")
+	assertContains(t, args, "-maxrate", "8M")
+	assertContains(t, args, "-bufsize", "16M")
+	assertContains(t, args, "-r", "24.00") // source below the 30 FPS cap is kept
+	assertContains(t, args, "-c:a", "aac")
+	assertContains(t, args, "-ar", "48000")
+	assertContains(t, args, "-movflags", "+faststart")
+
+	vf := args[slices.Index(args, "-vf")+1]
+	for _, part := range []string{"force_original_aspect_ratio=decrease", "pad=1080:1920", "format=yuv420p"} {
+		if !strings.Contains(vf, part) {
+			t.Errorf("-vf %q missing %q", vf, part)
+		}
+	}
+	if args[len(args)-1] != "out.mp4" {
+		t.Errorf("output path must be last, got %v", args)
+	}
+}
+
+func TestBuildTranscodeArgs_ArchiveKeepsSource(t *testing.T) {
+	meta := &VideoMetadata{FrameRate: 60, AudioRate: 44100}
+	args := BuildTranscodeArgs(ProfileArchive, "in.mov", "out.mp4", meta)
+
+	assertContains(t, args, "-c:v", "libx265")
+	assertContains(t, args, "-vf", "format=yuv420p")
+	for _, flag := range []string{"-r", "-ar", "-maxrate"} {
+		if slices.Contains(args, flag) {
+			t.Errorf("archive should keep the source, found %s in %v", flag, args)
+		}
+	}
+}
+
+func TestGetTranscodeProfile(t *testing.T) {
+	for _, name := range []string{"gemini-analysis", "gemini-captions", "instagram-reel", "instagram-feed", "archive"} {
+		p, ok := GetTranscodeProfile(name)
+		if !ok || p.Name != name {
+			t.Errorf("GetTranscodeProfile(%q) = %q, %v", name, p.Name, ok)
+		}
+	}
+	if _, ok := GetTranscodeProfile("nope"); ok {
+		t.Error("GetTranscodeProfile(nope) should fail")
+	}
+}
+
+func TestDoubleBitrate(t *testing.T) {
+	tests := map[string]string{"8M": "16M", "800k": "1600k", "2.5M": "5M", "5000000": "10000000", "fast": "fast"}
+	for in, want := range tests {
+		if got := doubleBitrate(in); got != want {
+			t.Errorf("doubleBitrate(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -31,14 +31,18 @@ type VideoMetadata struct {
 	Longitude float64
 	HasGPS    bool
 
-	// Timestamp
+	// Timestamp, in the zone the video was recorded in when known
 	CreateDate time.Time
 	HasDate    bool
+	// ZoneSource records how CreateDate's zone was determined (DDR-117);
+	// empty means unknown, and CreateDate is in UTC.
+	ZoneSource string
 
 	// Video properties (from stream metadata)
 	Duration   time.Duration
 	Width      int
 	Height     int
+	Rotation   int // display rotation in degrees, e.g. -90 for portrait phone video; see DisplaySize
 	FrameRate  float64
 	Codec      string
 	BitRate    int64
@@ -63,6 +67,16 @@ func (m *VideoMetadata) GetMediaType() string {
 	return "video"
 }
 
+// DisplaySize returns the width and height as the video is shown, with
+// Width and Height swapped for a ±90° rotation. ffmpeg applies the rotation
+// when transcoding, so output dimensions match DisplaySize.
+func (m *VideoMetadata) DisplaySize() (width, height int) {
+	if r := (m.Rotation%180 + 180) % 180; r == 90 {
+		return m.Height, m.Width
+	}
+	return m.Width, m.Height
+}
+
 // HasGPSData returns true if GPS coordinates are available.
 func (m *VideoMetadata) HasGPSData() bool {
 	return m.HasGPS
@@ -174,7 +188,7 @@ func (m *VideoMetadata) FormatMetadataContext() string {
 	if m.HasDate {
 		sb.WriteString("**Date/Time Created:**\n")
 		sb.WriteString(fmt.Sprintf("- Date: %s\n", m.CreateDate.Format("Monday, January 2, 2006")))
-		sb.WriteString(fmt.Sprintf("- Time: %s\n", m.CreateDate.Format("3:04 PM")))
+		sb.WriteString(fmt.Sprintf("- Time: %s\n", formatClock(m)))
 		sb.WriteString(fmt.Sprintf("- Day of Week: %s\n\n", m.CreateDate.Weekday().String()))
 	} else {
 		sb.WriteString("**Date/Time Created:** Not available in video metadata\n\n")
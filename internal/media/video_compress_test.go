@@ -48,7 +48,7 @@ func TestSelectPreset(t *testing.T) {
 }
 
 func TestBuildFFmpegArgs_NoMetadata(t *testing.T) {
-	args := buildFFmpegArgs("input.mp4", "output.webm", nil, DefaultVideoPreset)
+	args := BuildTranscodeArgs(ProfileGeminiAnalysis, "input.mp4", "output.webm", nil)
 
 	// Verify essential arguments are present
 	assertContains(t, args, "-c:v", "libsvtav1")
@@ -71,7 +71,7 @@ func TestBuildFFmpegArgs_WithHighFPSSource(t *testing.T) {
 		AudioRate: 48000,
 	}
 
-	args := buildFFmpegArgs("input.mp4", "output.webm", metadata, DefaultVideoPreset)
+	args := BuildTranscodeArgs(ProfileGeminiAnalysis, "input.mp4", "output.webm", metadata)
 
 	// Verify frame rate is capped at MaxFrameRate (5), not 60
 	assertContains(t, args, "-r", "5.00")
@@ -89,7 +89,7 @@ func TestBuildFFmpegArgs_WithLowFPSSource(t *testing.T) {
 		AudioRate: 22050,
 	}
 
-	args := buildFFmpegArgs("input.mp4", "output.webm", metadata, DefaultVideoPreset)
+	args := BuildTranscodeArgs(ProfileGeminiAnalysis, "input.mp4", "output.webm", metadata)
 
 	// Verify frame rate preserves source (3 FPS), not upscaled to 5
 	assertContains(t, args, "-r", "3.00")
@@ -99,7 +99,7 @@ func TestBuildFFmpegArgs_WithLowFPSSource(t *testing.T) {
 }
 
 func TestBuildFFmpegArgs_VideoFilterPresent(t *testing.T) {
-	args := buildFFmpegArgs("input.mp4", "output.webm", nil, DefaultVideoPreset)
+	args := BuildTranscodeArgs(ProfileGeminiAnalysis, "input.mp4", "output.webm", nil)
 
 	// Verify video filter includes scale and format
 	found := false
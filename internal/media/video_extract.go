@@ -45,6 +45,10 @@ type ffprobeStream struct {
 	Channels      int               `json:"channels"`
 	ColorSpace    string            `json:"color_space"`
 	Tags          map[string]string `json:"tags"`
+	SideDataList  []struct {
+		SideDataType string  `json:"side_data_type"`
+		Rotation     float64 `json:"rotation"`
+	} `json:"side_data_list"`
 }
 
 // ExtractVideoMetadata extracts metadata from a video file using ffprobe.
@@ -110,10 +114,17 @@ func ExtractVideoMetadata(filePath string) (*VideoMetadata, error) {
 
 		switch strings.ToLower(key) {
 		case "creation_time":
-			if t, err := time.Parse(time.RFC3339, value); err == nil {
+			if t, err := time.Parse(time.RFC3339, value); err == nil && metadata.ZoneSource == "" {
 				metadata.CreateDate = t
 				metadata.HasDate = true
 			}
+		case "com.apple.quicktime.creationdate":
+			// iPhone records local time with its offset, e.g. "2024-07-04T21:15:03-0700".
+			if t, err := time.Parse("2006-01-02T15:04:05-0700", value); err == nil {
+				metadata.CreateDate = t
+				metadata.HasDate = true
+				metadata.ZoneSource = ZoneFromOffset
+			}
 		case "location", "location-eng", "com.apple.quicktime.location.iso6709":
 			// Parse ISO 6709 format: "+38.0048-084.4848/" or "+37.7749-122.4194+000.000/"
 			// Apple uses ©xyz atom which ffprobe exports as location or com.apple.quicktime.location.ISO6709
@@ -147,6 +158,7 @@ func ExtractVideoMetadata(filePath string) (*VideoMetadata, error) {
 			if metadata.Width == 0 {
 				metadata.Width = stream.Width
 				metadata.Height = stream.Height
+				metadata.Rotation = streamRotation(stream)
 			}
 			if metadata.Codec == "" {
 				metadata.Codec = stream.CodecName
@@ -176,6 +188,8 @@ func ExtractVideoMetadata(filePath string) (*VideoMetadata, error) {
 		}
 	}
 
+	resolveVideoZone(metadata)
+
 	log.Debug().
 		Bool("has_gps", metadata.HasGPS).
 		Float64("latitude", metadata.Latitude).
@@ -192,3 +206,17 @@ func ExtractVideoMetadata(filePath string) (*VideoMetadata, error) {
 
 	return metadata, nil
 }
+
+// streamRotation returns the display rotation of a video stream in degrees,
+// from the display matrix side data (ffmpeg 5+) or the legacy rotate tag.
+func streamRotation(stream ffprobeStream) int {
+	for _, sd := range stream.SideDataList {
+		if sd.SideDataType == "Display Matrix" {
+			return int(sd.Rotation)
+		}
+	}
+	if r, err := strconv.Atoi(stream.Tags["rotate"]); err == nil {
+		return r
+	}
+	return 0
+}
@@ -16,6 +16,8 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
 )
 
 // Frame extraction constants for the video enhancement pipeline (DDR-032).
@@ -147,7 +149,9 @@ func ExtractFrames(ctx context.Context, videoPath string, metadata *VideoMetadat
 		Msg("Executing ffmpeg command for frame extraction")
 
 	cmd := exec.CommandContext(ctx, ffmpegPath, args...)
+	ffmpegStart := time.Now()
 	output, err := cmd.CombinedOutput()
+	metrics.RecordFFmpeg(ctx, time.Since(ffmpegStart))
 	if err != nil {
 		cleanup()
 		return nil, fmt.Errorf("frame extraction failed: %w\nOutput: %s", err, string(output))
@@ -235,7 +239,9 @@ func ReassembleVideo(ctx context.Context, frameDir string, originalVideoPath str
 		Msg("Executing ffmpeg command for video reassembly")
 
 	cmd := exec.CommandContext(ctx, ffmpegPath, args...)
+	ffmpegStart := time.Now()
 	output, err := cmd.CombinedOutput()
+	metrics.RecordFFmpeg(ctx, time.Since(ffmpegStart))
 	if err != nil {
 		return fmt.Errorf("video reassembly failed: %w\nOutput: %s", err, string(output))
 	}
@@ -17,6 +17,8 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
 )
 
 // lutSize is the number of entries per channel in the 3D LUT.
@@ -259,7 +261,9 @@ func ApplyLUTToFrames(ctx context.Context, framePaths []string, lutContent strin
 			Str("lut_path", lutPath).
 			Msg("Applying LUT to frame via ffmpeg")
 
+		ffmpegStart := time.Now()
 		output, err := cmd.CombinedOutput()
+		metrics.RecordFFmpeg(ctx, time.Since(ffmpegStart))
 		if err != nil {
 			log.Warn().
 				Err(err).
@@ -0,0 +1,94 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/image/draw"
+)
+
+// Video previews (DDR-124). One thumbnail frame is not enough to judge
+// whether a clip is worth keeping, so review screens can show a strip of
+// frames from across the video instead. The strip is a JPEG rather than an
+// animated WebP: encoding WebP needs CGO (DDR-027) and a still strip shows
+// the whole clip at a glance without waiting for playback.
+
+// videoPreviewFrames is the number of frames in a video preview strip.
+const videoPreviewFrames = 3
+
+// GenerateVideoPreview renders frames from early, middle and late in a video
+// side by side as one JPEG, scaled to fit maxDimension. Frames that cannot
+// be extracted are left out; it fails only if none can be. Requires ffmpeg
+// and a known duration.
+func GenerateVideoPreview(mediaFile *MediaFile, maxDimension int) ([]byte, string, error) {
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, "", fmt.Errorf("ffmpeg not found: video preview generation requires ffmpeg")
+	}
+	duration := videoDuration(mediaFile)
+	if duration <= 0 {
+		return nil, "", fmt.Errorf("video preview needs the duration of %s", mediaFile.Path)
+	}
+
+	tmpFile, err := os.CreateTemp("", "vpreview-*.jpg")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	tmpFile.Close()
+	defer os.Remove(tmpPath)
+
+	var frames []image.Image
+	for _, offset := range videoPreviewOffsets(duration, videoPreviewFrames) {
+		data, err := extractVideoFrame(ffmpegPath, mediaFile.Path, tmpPath, offset, maxDimension)
+		if err != nil {
+			log.Debug().Err(err).Str("path", mediaFile.Path).Dur("offset", offset).Msg("Skipping video preview frame")
+			continue
+		}
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			log.Debug().Err(err).Str("path", mediaFile.Path).Dur("offset", offset).Msg("Skipping undecodable video preview frame")
+			continue
+		}
+		frames = append(frames, img)
+	}
+	if len(frames) == 0 {
+		return nil, "", fmt.Errorf("no frames could be extracted from %s", mediaFile.Path)
+	}
+
+	frameW, frameH := frames[0].Bounds().Dx(), frames[0].Bounds().Dy()
+	strip := image.NewRGBA(image.Rect(0, 0, len(frames)*frameW, frameH))
+	for i, frame := range frames {
+		at := image.Rect(i*frameW, 0, (i+1)*frameW, frameH)
+		draw.CatmullRom.Scale(strip, at, frame, frame.Bounds(), draw.Src, nil)
+	}
+
+	out, err := encodeJPEGThumbnail(strip, maxDimension, 80)
+	if err != nil {
+		return nil, "", err
+	}
+	log.Debug().
+		Str("path", mediaFile.Path).
+		Dur("duration", duration).
+		Int("frames", len(frames)).
+		Int("output_size", len(out)).
+		Msg("Rendered video preview strip")
+	return out, "image/jpeg", nil
+}
+
+// videoPreviewOffsets returns n seek positions spread evenly across
+// duration, centred in each span so the strip avoids the very first and
+// last frames, which are often black.
+func videoPreviewOffsets(duration time.Duration, n int) []time.Duration {
+	out := make([]time.Duration, n)
+	for k := range out {
+		out[k] = duration * time.Duration(2*k+1) / time.Duration(2*n)
+	}
+	return out
+}
@@ -0,0 +1,207 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/image/draw"
+)
+
+// Long-video sampling (DDR-162). An accidental pocket recording can run for
+// twenty minutes; uploading it whole to judge whether it is worth keeping
+// costs far more than the verdict is worth. Triage instead sees a sheet of
+// frames taken at even intervals plus a summary of how loud the audio is.
+
+const (
+	// SampleFrames is the number of frames on a video sample sheet.
+	SampleFrames = 8
+	// sampleSheetColumns lays the frames out four across, two rows deep.
+	sampleSheetColumns = 4
+
+	// MinKeyframes and MaxKeyframes bound the frames a triage job may ask
+	// for per video in keyframe mode (DDR-186). Sixteen fill a 4x4 sheet of
+	// 256px frames.
+	MinKeyframes = 2
+	MaxKeyframes = 16
+)
+
+// ValidateKeyframes checks a requested keyframe count; 0 means keyframe
+// mode is off.
+func ValidateKeyframes(n int) error {
+	if n != 0 && (n < MinKeyframes || n > MaxKeyframes) {
+		return fmt.Errorf("keyframes must be 0 (off) or %d-%d", MinKeyframes, MaxKeyframes)
+	}
+	return nil
+}
+
+// VideoSample stands in for a long video during triage.
+type VideoSample struct {
+	// Sheet is a JPEG grid of frames in playback order, left to right then
+	// top to bottom.
+	Sheet []byte
+	// Offsets are the positions of the frames on the sheet.
+	Offsets []time.Duration
+	// Loudness summarises the audio track; nil when the video has none or
+	// it could not be measured.
+	Loudness *AudioLoudness
+	// Duration is the length of the video the frames were taken from.
+	Duration time.Duration
+	// Keyframes is set when the job asked for every video to be judged
+	// from frames, whatever its length (DDR-186).
+	Keyframes bool
+}
+
+// AudioLoudness is the output of ffmpeg's volumedetect filter.
+type AudioLoudness struct {
+	MeanDB float64 // mean volume in dBFS
+	MaxDB  float64 // peak volume in dBFS
+}
+
+// SampleVideo renders SampleFrames frames spread evenly across a video as
+// one JPEG sheet scaled to fit maxDimension, and measures its audio.
+// Frames that cannot be extracted are left out; it fails only if none can
+// be. A failed loudness measurement is logged and leaves Loudness nil.
+func SampleVideo(videoPath string, duration time.Duration, maxDimension int) (*VideoSample, error) {
+	return sampleVideo(videoPath, duration, maxDimension, SampleFrames)
+}
+
+// SampleKeyframes renders a sheet of n frames like SampleVideo, for triage
+// in keyframe mode (DDR-186). n must pass ValidateKeyframes.
+func SampleKeyframes(videoPath string, duration time.Duration, maxDimension, n int) (*VideoSample, error) {
+	if err := ValidateKeyframes(n); err != nil || n == 0 {
+		return nil, fmt.Errorf("invalid keyframe count %d", n)
+	}
+	s, err := sampleVideo(videoPath, duration, maxDimension, n)
+	if err != nil {
+		return nil, err
+	}
+	s.Keyframes = true
+	return s, nil
+}
+
+func sampleVideo(videoPath string, duration time.Duration, maxDimension, n int) (*VideoSample, error) {
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg not found: video sampling requires ffmpeg")
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("video sampling needs the duration of %s", videoPath)
+	}
+
+	tmpFile, err := os.CreateTemp("", "vsample-*.jpg")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	tmpFile.Close()
+	defer os.Remove(tmpPath)
+
+	sample := &VideoSample{Duration: duration}
+	var frames []image.Image
+	frameDim := maxDimension / sampleSheetColumns
+	for _, offset := range videoPreviewOffsets(duration, n) {
+		data, err := extractVideoFrame(ffmpegPath, videoPath, tmpPath, offset, frameDim)
+		if err != nil {
+			log.Debug().Err(err).Str("path", videoPath).Dur("offset", offset).Msg("Skipping video sample frame")
+			continue
+		}
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			log.Debug().Err(err).Str("path", videoPath).Dur("offset", offset).Msg("Skipping undecodable video sample frame")
+			continue
+		}
+		frames = append(frames, img)
+		sample.Offsets = append(sample.Offsets, offset)
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("no frames could be extracted from %s", videoPath)
+	}
+
+	frameW, frameH := frames[0].Bounds().Dx(), frames[0].Bounds().Dy()
+	cols := min(len(frames), sampleSheetColumns)
+	rows := (len(frames) + cols - 1) / cols
+	sheet := image.NewRGBA(image.Rect(0, 0, cols*frameW, rows*frameH))
+	draw.Draw(sheet, sheet.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	for i, frame := range frames {
+		at := image.Rect(i%cols*frameW, i/cols*frameH, (i%cols+1)*frameW, (i/cols+1)*frameH)
+		draw.CatmullRom.Scale(sheet, at, frame, frame.Bounds(), draw.Src, nil)
+	}
+	if sample.Sheet, err = encodeJPEGThumbnail(sheet, maxDimension, 80); err != nil {
+		return nil, err
+	}
+
+	sample.Loudness, err = measureLoudness(ffmpegPath, videoPath)
+	if err != nil {
+		log.Debug().Err(err).Str("path", videoPath).Msg("Could not measure video loudness, sampling frames only")
+	}
+
+	log.Debug().
+		Str("path", videoPath).
+		Dur("duration", duration).
+		Int("frames", len(frames)).
+		Bool("has_loudness", sample.Loudness != nil).
+		Int("output_size", len(sample.Sheet)).
+		Msg("Rendered video sample sheet")
+	return sample, nil
+}
+
+// measureLoudness runs volumedetect over the audio track only, which
+// decodes far faster than the video. Returns nil, nil for a video without
+// audio.
+//
+// ffmpeg -nostats -i input.mp4 -vn -sn -dn -af volumedetect -f null -
+func measureLoudness(ffmpegPath, videoPath string) (*AudioLoudness, error) {
+	output, err := exec.Command(ffmpegPath,
+		"-nostats",
+		"-i", videoPath,
+		"-vn", "-sn", "-dn",
+		"-af", "volumedetect",
+		"-f", "null", "-",
+	).CombinedOutput()
+	if l, ok := parseVolumeDetect(string(output)); ok {
+		return l, nil
+	}
+	if strings.Contains(string(output), "does not contain any stream") {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg volumedetect failed: %w", err)
+	}
+	return nil, fmt.Errorf("ffmpeg volumedetect reported no volume")
+}
+
+var volumeDetectRe = regexp.MustCompile(`(mean|max)_volume:\s*(-?[0-9.]+|-inf) dB`)
+
+// parseVolumeDetect reads the mean_volume and max_volume lines volumedetect
+// logs. A silent track reports -inf, kept as the -91 dB floor of 16-bit
+// audio so it stays printable.
+func parseVolumeDetect(output string) (*AudioLoudness, bool) {
+	var l AudioLoudness
+	var seenMean, seenMax bool
+	for _, m := range volumeDetectRe.FindAllStringSubmatch(output, -1) {
+		v := -91.0
+		if m[2] != "-inf" {
+			parsed, err := strconv.ParseFloat(m[2], 64)
+			if err != nil {
+				continue
+			}
+			v = parsed
+		}
+		if m[1] == "mean" {
+			l.MeanDB, seenMean = v, true
+		} else {
+			l.MaxDB, seenMax = v, true
+		}
+	}
+	return &l, seenMean && seenMax
+}
@@ -0,0 +1,36 @@
+package media
+
+import "testing"
+
+func TestParseVolumeDetect(t *testing.T) {
+	output := `[Parsed_volumedetect_0 @ 0x600] n_samples: 52920000
+[Parsed_volumedetect_0 @ 0x600] mean_volume: -47.3 dB
+[Parsed_volumedetect_0 @ 0x600] max_volume: -21.0 dB
+[Parsed_volumedetect_0 @ 0x600] histogram_21db: 3`
+	l, ok := parseVolumeDetect(output)
+	if !ok || l.MeanDB != -47.3 || l.MaxDB != -21.0 {
+		t.Errorf("parseVolumeDetect = %+v, %v", l, ok)
+	}
+
+	l, ok = parseVolumeDetect("mean_volume: -inf dB\nmax_volume: -inf dB\n")
+	if !ok || l.MeanDB != -91 || l.MaxDB != -91 {
+		t.Errorf("parseVolumeDetect(silent) = %+v, %v", l, ok)
+	}
+
+	if _, ok := parseVolumeDetect("Output file #0 does not contain any stream"); ok {
+		t.Error("parseVolumeDetect succeeded without volume lines")
+	}
+}
+
+func TestValidateKeyframes(t *testing.T) {
+	for _, n := range []int{0, MinKeyframes, 8, MaxKeyframes} {
+		if err := ValidateKeyframes(n); err != nil {
+			t.Errorf("ValidateKeyframes(%d) = %v", n, err)
+		}
+	}
+	for _, n := range []int{-1, 1, MaxKeyframes + 1} {
+		if ValidateKeyframes(n) == nil {
+			t.Errorf("ValidateKeyframes(%d) accepted an invalid count", n)
+		}
+	}
+}
@@ -392,6 +392,37 @@ func TestCheckFFprobeAvailable(t *testing.T) {
 	}
 }
 
+func TestVideoThumbnailOffset(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		want     time.Duration
+	}{
+		{0, 0},
+		{-time.Second, 0},
+		{500 * time.Millisecond, 50 * time.Millisecond},
+		{42 * time.Second, 4200 * time.Millisecond},
+		{10 * time.Minute, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := VideoThumbnailOffset(tt.duration); got != tt.want {
+			t.Errorf("VideoThumbnailOffset(%v) = %v, want %v", tt.duration, got, tt.want)
+		}
+	}
+}
+
+func TestVideoPreviewOffsets(t *testing.T) {
+	got := videoPreviewOffsets(60*time.Second, 3)
+	want := []time.Duration{10 * time.Second, 30 * time.Second, 50 * time.Second}
+	if len(got) != len(want) {
+		t.Fatalf("videoPreviewOffsets returned %d offsets, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("offset[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
 // Helper function for float comparison
 func floatEquals(a, b, tolerance float64) bool {
 	diff := a - b
@@ -400,3 +431,18 @@ func floatEquals(a, b, tolerance float64) bool {
 	}
 	return diff < tolerance
 }
+
+func TestVideoMetadataDisplaySize(t *testing.T) {
+	for _, rotation := range []int{90, -90, 270} {
+		m := &VideoMetadata{Width: 1920, Height: 1080, Rotation: rotation}
+		if w, h := m.DisplaySize(); w != 1080 || h != 1920 {
+			t.Errorf("rotation %d: DisplaySize = %dx%d, want 1080x1920", rotation, w, h)
+		}
+	}
+	for _, rotation := range []int{0, 180, -180} {
+		m := &VideoMetadata{Width: 1920, Height: 1080, Rotation: rotation}
+		if w, h := m.DisplaySize(); w != 1920 || h != 1080 {
+			t.Errorf("rotation %d: DisplaySize = %dx%d, want 1920x1080", rotation, w, h)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Highlight clip bounds for Reels (DDR-187): long enough to hold a moment,
+// short enough to loop.
+const (
+	MinHighlightDuration = 15 * time.Second
+	MaxHighlightDuration = 30 * time.Second
+)
+
+// VideoClip is the segment of a video from Start to End.
+type VideoClip struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// Duration returns the length of the clip.
+func (c VideoClip) Duration() time.Duration {
+	return c.End - c.Start
+}
+
+// Validate checks that the clip is a non-empty segment within a video of
+// the given duration; a zero duration skips the upper bound.
+func (c VideoClip) Validate(duration time.Duration) error {
+	if c.Start < 0 || c.End <= c.Start {
+		return fmt.Errorf("invalid clip %s-%s", c.Start, c.End)
+	}
+	if duration > 0 && c.End > duration {
+		return fmt.Errorf("clip ends at %s, after the video's %s", c.End, duration)
+	}
+	return nil
+}
+
+// TrimVideo cuts clip out of a video and re-encodes it with profile into a
+// temporary file, e.g. ProfileInstagramReel for a highlight (DDR-187).
+// Re-encoding makes the cut frame-accurate, where a stream copy could only
+// start on a keyframe. Metadata should come from the original file, as for
+// Transcode.
+//
+// The cleanup function MUST be called to remove the temporary file.
+func TrimVideo(ctx context.Context, inputPath string, meta *VideoMetadata, clip VideoClip, profile TranscodeProfile) (
+	outputPath string,
+	outputSize int64,
+	cleanup func(),
+	err error,
+) {
+	var duration time.Duration
+	if meta != nil {
+		duration = meta.Duration
+	}
+	if err := clip.Validate(duration); err != nil {
+		return "", 0, nil, err
+	}
+	return transcode(ctx, inputPath, meta, profile, &clip)
+}
+
+// BuildTrimArgs constructs the ffmpeg arguments for re-encoding clip of a
+// video with profile. Seeking before the input is fast, and exact because
+// the output is re-encoded.
+func BuildTrimArgs(profile TranscodeProfile, inputPath, outputPath string, meta *VideoMetadata, clip VideoClip) []string {
+	return slices.Concat(
+		[]string{
+			"-ss", strconv.FormatFloat(clip.Start.Seconds(), 'f', 3, 64),
+			"-t", strconv.FormatFloat(clip.Duration().Seconds(), 'f', 3, 64),
+		},
+		BuildTranscodeArgs(profile, inputPath, outputPath, meta),
+	)
+}
@@ -0,0 +1,47 @@
+package media
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBuildTrimArgs(t *testing.T) {
+	meta := &VideoMetadata{Duration: 2 * time.Minute, FrameRate: 30, AudioRate: 48000}
+	clip := VideoClip{Start: 42500 * time.Millisecond, End: 64 * time.Second}
+	args := BuildTrimArgs(ProfileInstagramReel, "in.mov", "out.mp4", meta, clip)
+
+	if want := []string{"-ss", "42.500", "-t", "21.500", "-i", "in.mov"}; !slices.Equal(args[:6], want) {
+		t.Errorf("args start %v, want %v", args[:6], want)
+	}
+	assertContains(t, args, "-c:v", "libx264")
+	assertContains(t, args, "-movflags", "+faststart")
+	if args[len(args)-1] != "out.mp4" {
+		t.Errorf("output path must be last, got %v", args)
+	}
+}
+
+func TestVideoClipValidate(t *testing.T) {
+	for _, tc := range []struct {
+		clip VideoClip
+		ok   bool
+	}{
+		{VideoClip{Start: 10 * time.Second, End: 30 * time.Second}, true},
+		{VideoClip{Start: 0, End: time.Minute}, true},
+		{VideoClip{Start: 30 * time.Second, End: 30 * time.Second}, false},
+		{VideoClip{Start: -time.Second, End: 10 * time.Second}, false},
+		{VideoClip{Start: 50 * time.Second, End: 70 * time.Second}, false},
+	} {
+		if err := tc.clip.Validate(time.Minute); (err == nil) != tc.ok {
+			t.Errorf("Validate(%+v) = %v, want ok=%v", tc.clip, err, tc.ok)
+		}
+	}
+}
+
+func TestTrimVideoRejectsInvalidClip(t *testing.T) {
+	meta := &VideoMetadata{Duration: 20 * time.Second}
+	if _, _, _, err := TrimVideo(context.Background(), "in.mov", meta, VideoClip{Start: 15 * time.Second, End: 40 * time.Second}, ProfileInstagramReel); err == nil {
+		t.Error("clip past the end of the video accepted")
+	}
+}
@@ -0,0 +1,90 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// AIGeneratedLabel is the text stamped on generated images (DDR-102).
+const AIGeneratedLabel = "AI-GENERATED"
+
+// watermarkBandRatio is the height of the label band relative to the image.
+const watermarkBandRatio = 0.06
+
+// WatermarkAIGenerated stamps a full-width, semi-opaque band across the bottom
+// of an image with AIGeneratedLabel and returns the result as JPEG. The band
+// is sized relative to the image so the label stays legible at any resolution
+// and on the cropped square previews used by Instagram.
+func WatermarkAIGenerated(data []byte) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	b := src.Bounds()
+	out := image.NewRGBA(b)
+	draw.Draw(out, b, src, b.Min, draw.Src)
+
+	bandHeight := max(int(float64(b.Dy())*watermarkBandRatio), 16)
+	band := image.Rect(b.Min.X, b.Max.Y-bandHeight, b.Max.X, b.Max.Y)
+	draw.Draw(out, band, image.NewUniform(color.RGBA{A: 0xb0}), image.Point{}, draw.Over)
+
+	// Render the label at the bitmap font's native size, then scale it to
+	// the band so it is not a 13px smudge on a 4000px photo.
+	label := renderLabel(AIGeneratedLabel, false, color.White)
+	textWidth, textHeight := label.Bounds().Dx(), label.Bounds().Dy()
+
+	scale := float64(bandHeight) * 0.7 / float64(textHeight)
+	w, h := int(float64(textWidth)*scale), int(float64(textHeight)*scale)
+	if maxW := b.Dx() - bandHeight; w > maxW && maxW > 0 {
+		h = h * maxW / w
+		w = maxW
+	}
+	x := b.Max.X - w - bandHeight/2
+	y := band.Min.Y + (bandHeight-h)/2
+	draw.NearestNeighbor.Scale(out, image.Rect(x, y, x+w, y+h), label, label.Bounds(), draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, out, &jpeg.Options{Quality: 90}); err != nil {
+		return nil, fmt.Errorf("encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderLabel draws text in fill with the 7x13 bitmap font at its native
+// size on a transparent canvas. With outline, each glyph gets a 1px dark
+// border so the text stays legible on light backgrounds.
+func renderLabel(text string, outline bool, fill color.Color) *image.RGBA {
+	face := basicfont.Face7x13
+	pad := 0
+	if outline {
+		pad = 1
+	}
+	width := font.MeasureString(face, text).Ceil() + 2*pad
+	height := face.Metrics().Height.Ceil() + 2*pad
+	label := image.NewRGBA(image.Rect(0, 0, width, height))
+	drawText := func(src color.Color, dx, dy int) {
+		d := &font.Drawer{
+			Dst:  label,
+			Src:  image.NewUniform(src),
+			Face: face,
+			Dot:  fixed.P(pad+dx, pad+dy+face.Metrics().Ascent.Ceil()),
+		}
+		d.DrawString(text)
+	}
+	if outline {
+		for _, off := range [][2]int{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}} {
+			drawText(color.RGBA{A: 0xc0}, off[0], off[1])
+		}
+	}
+	drawText(fill, 0, 0)
+	return label
+}
@@ -0,0 +1,60 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestWatermarkAIGenerated(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 800, 600))
+	for y := 0; y < 600; y++ {
+		for x := 0; x < 800; x++ {
+			src.Set(x, y, color.RGBA{R: 0x20, G: 0x80, B: 0xe0, A: 0xff})
+		}
+	}
+	var in bytes.Buffer
+	if err := png.Encode(&in, src); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := WatermarkAIGenerated(in.Bytes())
+	if err != nil {
+		t.Fatalf("WatermarkAIGenerated: %v", err)
+	}
+	img, format, err := image.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if format != "jpeg" || img.Bounds() != src.Bounds() {
+		t.Fatalf("got %s %v, want jpeg %v", format, img.Bounds(), src.Bounds())
+	}
+
+	// The top of the image is untouched; the band at the bottom is darkened
+	// and contains white label pixels.
+	if r, _, _, _ := img.At(10, 10).RGBA(); r>>8 > 0x30 {
+		t.Errorf("top pixel red = %#x, want unchanged", r>>8)
+	}
+	bandTop := 600 - int(600*watermarkBandRatio)
+	var dark, white bool
+	for y := bandTop; y < 600; y++ {
+		for x := 0; x < 800; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			if b>>8 < 0x60 {
+				dark = true
+			}
+			if r>>8 > 0xd0 && g>>8 > 0xd0 && b>>8 > 0xd0 {
+				white = true
+			}
+		}
+	}
+	if !dark || !white {
+		t.Errorf("watermark band dark=%v white=%v, want both", dark, white)
+	}
+
+	if _, err := WatermarkAIGenerated([]byte("not an image")); err == nil {
+		t.Error("expected error for invalid image data")
+	}
+}
@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Per-job telemetry (DDR-118). A Collector stored in the handler's context
+// accumulates the expensive operations a job performs; the store adds them
+// to the job record's totals on every write, so the cost and latency of a
+// job are visible in its results without searching CloudWatch. A job that
+// runs over several invocations keeps the usage of all of them.
+
+// JobTelemetry is the snapshot written onto a job record as perJobTelemetry.
+type JobTelemetry struct {
+	GeminiCalls     int64   `json:"geminiCalls" dynamodbav:"geminiCalls"`
+	GeminiLatencyMs int64   `json:"geminiLatencyMs" dynamodbav:"geminiLatencyMs"`
+	S3Downloads     int64   `json:"s3Downloads" dynamodbav:"s3Downloads"`
+	BytesDownloaded int64   `json:"bytesDownloaded" dynamodbav:"bytesDownloaded"`
+	FFmpegSeconds   float64 `json:"ffmpegSeconds" dynamodbav:"ffmpegSeconds"`
+
+	// GeminiInputTokens is the prompt size Gemini reported across the
+	// job's calls, so triage modes can be compared on cost (DDR-186).
+	GeminiInputTokens int64 `json:"geminiInputTokens,omitempty" dynamodbav:"geminiInputTokens,omitempty"`
+}
+
+// Collector accumulates JobTelemetry. It is safe for concurrent use, since
+// triage and selection run Gemini calls and downloads in parallel.
+type Collector struct {
+	geminiCalls     atomic.Int64
+	geminiLatencyMs atomic.Int64
+	s3Downloads     atomic.Int64
+	bytesDownloaded atomic.Int64
+	ffmpegMs        atomic.Int64
+	inputTokens     atomic.Int64
+
+	// mu guards the per-record state the store keeps between writes.
+	mu        sync.Mutex
+	baselines map[string]*JobTelemetry
+	reported  map[string]*JobTelemetry
+}
+
+type collectorKey struct{}
+
+// WithCollector returns a context carrying a new Collector, and the Collector.
+func WithCollector(ctx context.Context) (context.Context, *Collector) {
+	c := &Collector{baselines: map[string]*JobTelemetry{}, reported: map[string]*JobTelemetry{}}
+	return context.WithValue(ctx, collectorKey{}, c), c
+}
+
+// CollectorFrom returns the Collector carried by ctx, or nil.
+func CollectorFrom(ctx context.Context) *Collector {
+	c, _ := ctx.Value(collectorKey{}).(*Collector)
+	return c
+}
+
+// RecordGeminiCall records one Gemini API call and its latency. Like the
+// other Record functions, it does nothing when ctx carries no Collector.
+func RecordGeminiCall(ctx context.Context, latency time.Duration) {
+	if c := CollectorFrom(ctx); c != nil {
+		c.geminiCalls.Add(1)
+		c.geminiLatencyMs.Add(latency.Milliseconds())
+	}
+}
+
+// RecordGeminiTokens records the input tokens of one Gemini response.
+func RecordGeminiTokens(ctx context.Context, input int64) {
+	if c := CollectorFrom(ctx); c != nil {
+		c.inputTokens.Add(input)
+	}
+}
+
+// RecordS3Download records one S3 object download of the given size.
+func RecordS3Download(ctx context.Context, bytes int64) {
+	if c := CollectorFrom(ctx); c != nil {
+		c.s3Downloads.Add(1)
+		c.bytesDownloaded.Add(bytes)
+	}
+}
+
+// RecordFFmpeg records time spent running ffmpeg.
+func RecordFFmpeg(ctx context.Context, elapsed time.Duration) {
+	if c := CollectorFrom(ctx); c != nil {
+		c.ffmpegMs.Add(elapsed.Milliseconds())
+	}
+}
+
+// Snapshot returns the totals so far.
+func (c *Collector) Snapshot() *JobTelemetry {
+	return &JobTelemetry{
+		GeminiCalls:     c.geminiCalls.Load(),
+		GeminiLatencyMs: c.geminiLatencyMs.Load(),
+		S3Downloads:     c.s3Downloads.Load(),
+		BytesDownloaded: c.bytesDownloaded.Load(),
+		FFmpegSeconds:   float64(c.ffmpegMs.Load()) / 1000,
+
+		GeminiInputTokens: c.inputTokens.Load(),
+	}
+}
+
+// Baseline returns the totals a record held before this invocation first
+// wrote it. load reads them from the record and is called only the first
+// time key is seen.
+func (c *Collector) Baseline(key string, load func() *JobTelemetry) *JobTelemetry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	base, ok := c.baselines[key]
+	if !ok {
+		base = load()
+		c.baselines[key] = base
+	}
+	return base
+}
+
+// Unreported returns the usage not yet added to the record under key, and
+// counts it as added. It serves records that concurrent invocations add to
+// atomically rather than overwrite.
+func (c *Collector) Unreported(key string) *JobTelemetry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.Snapshot()
+	delta := now.Plus(c.reported[key].negated())
+	c.reported[key] = now
+	return delta
+}
+
+// Plus returns the sum of t and o. Either may be nil.
+func (t *JobTelemetry) Plus(o *JobTelemetry) *JobTelemetry {
+	var sum JobTelemetry
+	for _, x := range []*JobTelemetry{t, o} {
+		if x == nil {
+			continue
+		}
+		sum.GeminiCalls += x.GeminiCalls
+		sum.GeminiLatencyMs += x.GeminiLatencyMs
+		sum.S3Downloads += x.S3Downloads
+		sum.BytesDownloaded += x.BytesDownloaded
+		sum.FFmpegSeconds += x.FFmpegSeconds
+		sum.GeminiInputTokens += x.GeminiInputTokens
+	}
+	return &sum
+}
+
+func (t *JobTelemetry) negated() *JobTelemetry {
+	if t == nil {
+		return nil
+	}
+	return &JobTelemetry{
+		GeminiCalls:       -t.GeminiCalls,
+		GeminiLatencyMs:   -t.GeminiLatencyMs,
+		S3Downloads:       -t.S3Downloads,
+		BytesDownloaded:   -t.BytesDownloaded,
+		FFmpegSeconds:     -t.FFmpegSeconds,
+		GeminiInputTokens: -t.GeminiInputTokens,
+	}
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCollector_Accumulates(t *testing.T) {
+	ctx, c := WithCollector(context.Background())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			RecordGeminiCall(ctx, 150*time.Millisecond)
+			RecordS3Download(ctx, 1024)
+		}()
+	}
+	wg.Wait()
+	RecordFFmpeg(ctx, 2500*time.Millisecond)
+	RecordGeminiTokens(ctx, 1200)
+	RecordGeminiTokens(ctx, 300)
+
+	got := *c.Snapshot()
+	want := JobTelemetry{GeminiCalls: 10, GeminiLatencyMs: 1500, S3Downloads: 10, BytesDownloaded: 10240, FFmpegSeconds: 2.5, GeminiInputTokens: 1500}
+	if got != want {
+		t.Errorf("Snapshot() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRecord_WithoutCollector(t *testing.T) {
+	ctx := context.Background()
+	if CollectorFrom(ctx) != nil {
+		t.Fatal("expected no collector on a bare context")
+	}
+	// Must not panic.
+	RecordGeminiCall(ctx, time.Second)
+	RecordS3Download(ctx, 1)
+	RecordFFmpeg(ctx, time.Second)
+	RecordGeminiTokens(ctx, 1)
+}
+
+func TestCollector_BaselineLoadsOnce(t *testing.T) {
+	_, c := WithCollector(context.Background())
+	loads := 0
+	load := func() *JobTelemetry {
+		loads++
+		return &JobTelemetry{GeminiCalls: 3}
+	}
+	c.Baseline("a", load)
+	if got := c.Baseline("a", load); got.GeminiCalls != 3 || loads != 1 {
+		t.Errorf("Baseline = %+v after %d loads, want 3 calls after 1 load", got, loads)
+	}
+	c.Baseline("b", load)
+	if loads != 2 {
+		t.Errorf("%d loads for two keys, want 2", loads)
+	}
+
+	// The stored totals plus this invocation's are what the record holds.
+	ctx, c := WithCollector(context.Background())
+	RecordGeminiCall(ctx, time.Second)
+	if got := c.Baseline("a", load).Plus(c.Snapshot()); got.GeminiCalls != 4 || got.GeminiLatencyMs != 1000 {
+		t.Errorf("merged = %+v, want 4 calls and 1000ms", got)
+	}
+}
+
+func TestCollector_Unreported(t *testing.T) {
+	ctx, c := WithCollector(context.Background())
+	RecordGeminiCall(ctx, time.Second)
+	if got := *c.Unreported("item-1"); got != (JobTelemetry{GeminiCalls: 1, GeminiLatencyMs: 1000}) {
+		t.Errorf("first Unreported = %+v", got)
+	}
+	if got := *c.Unreported("item-1"); got != (JobTelemetry{}) {
+		t.Errorf("Unreported with nothing new = %+v, want zero", got)
+	}
+	RecordS3Download(ctx, 10)
+	if got := *c.Unreported("item-1"); got != (JobTelemetry{S3Downloads: 1, BytesDownloaded: 10}) {
+		t.Errorf("Unreported after a download = %+v", got)
+	}
+	// Each record is reported separately.
+	if got := c.Unreported("item-2"); got.GeminiCalls != 1 || got.S3Downloads != 1 {
+		t.Errorf("Unreported for a new key = %+v, want all usage so far", got)
+	}
+}
+
+func TestJobTelemetry_PlusNil(t *testing.T) {
+	a := &JobTelemetry{GeminiCalls: 1, FFmpegSeconds: 1.5}
+	if got := *a.Plus(nil); got != *a {
+		t.Errorf("a.Plus(nil) = %+v", got)
+	}
+	var none *JobTelemetry
+	if got := *none.Plus(a); got != *a {
+		t.Errorf("nil.Plus(a) = %+v", got)
+	}
+}
@@ -0,0 +1,180 @@
+package metrics
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// --- Gemini usage gauges (DDR-142) ---
+//
+// Each process counts its in-flight Gemini requests and the tokens each model
+// consumed. Every change is emitted as an EMF metric for alarms and, when a
+// UsageStore is configured, written to it so the API can report the current
+// values across all Lambdas. Store writes are buffered and made by one
+// background writer, so Gemini requests never wait on DynamoDB.
+
+// InFlightTTL is how long an instance's in-flight reading counts after its
+// last update. It matches the 15-minute Lambda timeout: an instance that has
+// not reported for longer is gone, whatever its last reading said.
+const InFlightTTL = 15 * time.Minute
+
+// UsageStore persists usage readings across processes.
+type UsageStore interface {
+	// PutGeminiInFlight records the in-flight request count of one process.
+	PutGeminiInFlight(ctx context.Context, instance string, inFlight int64) error
+	// AddModelTokens adds the request and token counts of one or more
+	// requests to the model's total for day (YYYY-MM-DD, UTC).
+	AddModelTokens(ctx context.Context, day, model string, requests, input, output int64) error
+}
+
+// storeTimeout bounds each best-effort write to the UsageStore.
+const storeTimeout = 2 * time.Second
+
+var (
+	usageStore UsageStore
+	instanceID = newInstanceID()
+
+	geminiInFlight atomic.Int64
+
+	// pendingMu guards usageStore and the readings waiting for the writer:
+	// whether the in-flight count changed, and the request and token counts
+	// per day and model.
+	pendingMu       sync.Mutex
+	pendingInFlight bool
+	pendingTokens   = map[tokenKey]tokenCounts{}
+
+	// flushMu orders store writes so the last in-flight count stored is the
+	// latest one.
+	flushMu     sync.Mutex
+	wakeWriter  = make(chan struct{}, 1)
+	startWriter sync.Once
+)
+
+type tokenKey struct{ day, model string }
+
+type tokenCounts struct{ requests, input, output int64 }
+
+// SetUsageStore configures where usage readings are persisted. Call it once
+// at cold start, before any Gemini request.
+func SetUsageStore(s UsageStore) {
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+	usageStore = s
+}
+
+// GeminiInFlight returns the number of Gemini requests this process has in flight.
+func GeminiInFlight() int64 {
+	return geminiInFlight.Load()
+}
+
+// StartGeminiRequest counts a Gemini request as in flight. Call the returned
+// function when the response has been read.
+func StartGeminiRequest() (done func()) {
+	n := geminiInFlight.Add(1)
+	New("AiSocialMedia").Metric("GeminiInFlight", float64(n), UnitCount).Flush()
+	publishInFlight()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			geminiInFlight.Add(-1)
+			publishInFlight()
+		})
+	}
+}
+
+// RecordModelTokens adds one Gemini response's token counts to the model's
+// daily total.
+func RecordModelTokens(model string, input, output int64) {
+	if model == "" || input+output == 0 {
+		return
+	}
+	New("AiSocialMedia").
+		Dimension("Model", model).
+		Metric("GeminiModelInputTokens", float64(input), UnitCount).
+		Metric("GeminiModelOutputTokens", float64(output), UnitCount).
+		Flush()
+	key := tokenKey{time.Now().UTC().Format(time.DateOnly), model}
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+	if usageStore == nil {
+		return
+	}
+	t := pendingTokens[key]
+	pendingTokens[key] = tokenCounts{t.requests + 1, t.input + input, t.output + output}
+	wake()
+}
+
+// publishInFlight queues a write of this process's in-flight count.
+func publishInFlight() {
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+	if usageStore == nil {
+		return
+	}
+	pendingInFlight = true
+	wake()
+}
+
+// wake signals the background writer, starting it on first use. Signals
+// sent while a write is running coalesce into one more pass.
+func wake() {
+	startWriter.Do(func() {
+		go func() {
+			for range wakeWriter {
+				FlushUsage(context.Background())
+			}
+		}()
+	})
+	select {
+	case wakeWriter <- struct{}{}:
+	default:
+	}
+}
+
+// FlushUsage writes the buffered usage readings to the UsageStore and
+// returns once they are stored. The background writer calls it after every
+// change; call it directly to make sure nothing is pending.
+func FlushUsage(ctx context.Context) {
+	flushMu.Lock()
+	defer flushMu.Unlock()
+
+	pendingMu.Lock()
+	s, inFlight, tokens := usageStore, pendingInFlight, pendingTokens
+	pendingInFlight, pendingTokens = false, map[tokenKey]tokenCounts{}
+	pendingMu.Unlock()
+
+	if s == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), storeTimeout)
+	defer cancel()
+	if inFlight {
+		if err := s.PutGeminiInFlight(ctx, instanceID, geminiInFlight.Load()); err != nil {
+			log.Debug().Err(err).Msg("Failed to record in-flight Gemini requests")
+		}
+	}
+	for k, t := range tokens {
+		if err := s.AddModelTokens(ctx, k.day, k.model, t.requests, t.input, t.output); err != nil {
+			log.Warn().Err(err).Str("model", k.model).Msg("Failed to record model token usage")
+		}
+	}
+}
+
+// newInstanceID names this process: the Lambda function plus a random suffix,
+// since a function runs many instances at once.
+func newInstanceID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	name := os.Getenv("AWS_LAMBDA_FUNCTION_NAME")
+	if name == "" {
+		name = "local"
+	}
+	return name + "#" + hex.EncodeToString(b)
+}
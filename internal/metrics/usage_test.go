@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingUsageStore holds every write until release is closed.
+type blockingUsageStore struct {
+	release chan struct{}
+
+	mu       sync.Mutex
+	inFlight []int64
+	tokens   map[string][3]int64
+}
+
+func (s *blockingUsageStore) PutGeminiInFlight(_ context.Context, _ string, n int64) error {
+	<-s.release
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight = append(s.inFlight, n)
+	return nil
+}
+
+func (s *blockingUsageStore) AddModelTokens(_ context.Context, _, model string, requests, input, output int64) error {
+	<-s.release
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.tokens[model]
+	s.tokens[model] = [3]int64{t[0] + requests, t[1] + input, t[2] + output}
+	return nil
+}
+
+func TestUsageWritesDoNotBlockRequests(t *testing.T) {
+	s := &blockingUsageStore{release: make(chan struct{}), tokens: map[string][3]int64{}}
+	SetUsageStore(s)
+	defer SetUsageStore(nil)
+
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for range 5 {
+			done := StartGeminiRequest()
+			RecordModelTokens("gemini-2.5-flash", 100, 10)
+			done()
+		}
+	}()
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Gemini requests waited on the usage store")
+	}
+
+	close(s.release)
+	FlushUsage(context.Background())
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n := len(s.inFlight); n == 0 || s.inFlight[n-1] != 0 {
+		t.Errorf("in-flight writes %v, want the last to be 0", s.inFlight)
+	}
+	if got, want := s.tokens["gemini-2.5-flash"], [3]int64{5, 500, 50}; got != want {
+		t.Errorf("requests and tokens = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,102 @@
+// Package mockapi holds what the platform clients' mock modes (DDR-139)
+// share: fake IDs that record when they were handed out, a clock tests can
+// set, and canned HTTP responses. Each client keeps its own routes, payloads
+// and error shape.
+package mockapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// IDPrefix marks every ID a mock hands out.
+const IDPrefix = "mock-"
+
+// IsID reports whether id was handed out by a mock.
+func IsID(id string) bool {
+	return strings.HasPrefix(id, IDPrefix)
+}
+
+// Clock hands out mock IDs of the form mock-{kind}-{unix millis}-{seq}.
+// The creation time in the ID lets any Lambda invocation tell how old a
+// mock object is, e.g. whether a video has finished processing.
+type Clock struct {
+	// Now is the clock's time; tests replace it.
+	Now func() time.Time
+
+	seq atomic.Int64
+}
+
+// NewClock returns a Clock on the wall clock.
+func NewClock() *Clock {
+	return &Clock{Now: time.Now}
+}
+
+// NewID returns a new ID of the given kind. kind must not contain "-".
+func (c *Clock) NewID(kind string) string {
+	return fmt.Sprintf("%s%s-%d-%d", IDPrefix, kind, c.Now().UnixMilli(), c.seq.Add(1))
+}
+
+// Since returns how long ago created was by the clock.
+func (c *Clock) Since(created time.Time) time.Duration {
+	return c.Now().Sub(created)
+}
+
+// ParseID splits an ID made by NewID.
+func ParseID(id string) (kind string, created time.Time, ok bool) {
+	parts := strings.Split(strings.TrimPrefix(id, IDPrefix), "-")
+	if !IsID(id) || len(parts) != 3 {
+		return "", time.Time{}, false
+	}
+	ms, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return parts[0], time.UnixMilli(ms), true
+}
+
+// Sleep waits d to simulate an API's latency, or until ctx is done.
+func Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// ReadForm reads a URL-encoded request body.
+func ReadForm(req *http.Request) (url.Values, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	return url.ParseQuery(string(body))
+}
+
+// JSON answers req with v as a JSON body.
+func JSON(req *http.Request, status int, v any) (*http.Response, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(string(body))),
+		Request:    req,
+	}, nil
+}
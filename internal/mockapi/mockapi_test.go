@@ -0,0 +1,43 @@
+package mockapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClockIDs(t *testing.T) {
+	now := time.UnixMilli(1_800_000_000_123)
+	c := NewClock()
+	c.Now = func() time.Time { return now }
+
+	first, second := c.NewID("video"), c.NewID("video")
+	if first == second {
+		t.Errorf("two IDs are both %s", first)
+	}
+	if !IsID(first) {
+		t.Errorf("IsID(%q) = false", first)
+	}
+	kind, created, ok := ParseID(first)
+	if !ok || kind != "video" || !created.Equal(now) {
+		t.Errorf("ParseID(%q) = %q, %v, %v", first, kind, created, ok)
+	}
+	now = now.Add(30 * time.Second)
+	if got := c.Since(created); got != 30*time.Second {
+		t.Errorf("Since = %v, want 30s", got)
+	}
+}
+
+func TestParseIDRejectsOtherIDs(t *testing.T) {
+	for _, id := range []string{
+		"",
+		"17841400000000000",
+		"video-1800000000123-1",       // no prefix
+		"mock-video-1800000000123",    // no sequence
+		"mock-video-soon-1",           // no time
+		"mock-page-post-1800000000-1", // kind with a dash
+	} {
+		if kind, _, ok := ParseID(id); ok {
+			t.Errorf("ParseID(%q) accepted as %q", id, kind)
+		}
+	}
+}
@@ -38,7 +38,7 @@ func TableForEventType(eventType string) string {
 		return TableTriageDecisions
 	case EventSelectionFinalized, EventOverridesFinalized:
 		return TableSelectionDecisions
-	case EventOverrideAction:
+	case EventOverrideAction, EventTriageOverride:
 		return TableOverrideDecisions
 	case EventDescriptionFinalized:
 		return TableCaptionDecisions
@@ -482,17 +482,27 @@ func (c *DataAPIClient) QuerySimilar(ctx context.Context, table string, embeddin
 		{Name: aws.String("emb"), Value: &rdsdatatypes.FieldMemberStringValue{Value: embStr}},
 		{Name: aws.String("topk"), Value: &rdsdatatypes.FieldMemberLongValue{Value: int64(topK)}},
 	}
-	result, err := c.client.ExecuteStatement(ctx, &rdsdata.ExecuteStatementInput{
-		ResourceArn: aws.String(c.clusterARN),
-		SecretArn:   aws.String(c.secretARN),
-		Database:    aws.String(c.database),
-		Sql:         aws.String(sql),
-		Parameters:  params,
-	})
+	rows, err := c.query(ctx, sql, params)
 	if err != nil {
 		log.Error().Err(err).Str("table", table).Msg("QuerySimilar failed")
 		return nil, fmt.Errorf("QuerySimilar: %w", err)
 	}
+	return rows, nil
+}
+
+// query runs a SELECT and returns each record as a column-name map.
+func (c *DataAPIClient) query(ctx context.Context, sql string, params []rdsdatatypes.SqlParameter) ([]map[string]interface{}, error) {
+	result, err := c.client.ExecuteStatement(ctx, &rdsdata.ExecuteStatementInput{
+		ResourceArn:           aws.String(c.clusterARN),
+		SecretArn:             aws.String(c.secretARN),
+		Database:              aws.String(c.database),
+		Sql:                   aws.String(sql),
+		Parameters:            params,
+		IncludeResultMetadata: true,
+	})
+	if err != nil {
+		return nil, err
+	}
 	rows := make([]map[string]interface{}, 0, len(result.Records))
 	for _, rec := range result.Records {
 		row := make(map[string]interface{})
@@ -22,6 +22,27 @@ func GenerateEmbedding(ctx context.Context, client *genai.Client, text string) (
 	return result.Embeddings[0].Values, nil
 }
 
+// imageEmbeddingModel embeds images into the same 1024-dimension space as
+// the decision tables. gemini-embedding-001 accepts text only (DDR-109).
+const imageEmbeddingModel = "gemini-embedding-2-preview"
+
+// GenerateImageEmbedding embeds an image, typically a 400px thumbnail.
+func GenerateImageEmbedding(ctx context.Context, client *genai.Client, data []byte, mimeType string) ([]float32, error) {
+	dim := int32(1024)
+	result, err := client.Models.EmbedContent(ctx, imageEmbeddingModel,
+		[]*genai.Content{genai.NewContentFromBytes(data, mimeType, genai.RoleUser)},
+		&genai.EmbedContentConfig{OutputDimensionality: &dim},
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("Gemini image EmbedContent failed")
+		return nil, fmt.Errorf("EmbedContent: %w", err)
+	}
+	if len(result.Embeddings) == 0 {
+		return nil, fmt.Errorf("EmbedContent: no embedding returned")
+	}
+	return result.Embeddings[0].Values, nil
+}
+
 func BuildEmbeddingInput(event ContentFeedback) string {
 	outcome := event.UserVerdict
 	if outcome == "" {
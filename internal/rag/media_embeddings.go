@@ -0,0 +1,254 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	rdsdatatypes "github.com/aws/aws-sdk-go-v2/service/rdsdata/types"
+)
+
+// EventMediaEmbedding marks a staging item holding a thumbnail embedding
+// rather than a ContentFeedback event (DDR-109).
+const EventMediaEmbedding = "media.embedding"
+
+const (
+	// stagingPK is the partition shared by every staging item (DDR-068).
+	stagingPK = "STAGING"
+
+	// embeddingSKPrefix groups a session's staged embeddings so they can be
+	// read back by session before the daily batch moves them to Aurora.
+	embeddingSKPrefix = "EMBED#"
+
+	stagingTTL = 14 * 24 * time.Hour
+)
+
+// MediaEmbedding is the image embedding of one uploaded file's thumbnail.
+// MediaKey is the original S3 key ({sessionId}/{filename}), the same key the
+// triage and selection decisions are recorded under.
+type MediaEmbedding struct {
+	SessionID string    `json:"sessionId"`
+	MediaKey  string    `json:"mediaKey"`
+	Embedding []float32 `json:"embedding"`
+	CreatedAt string    `json:"createdAt"`
+}
+
+// NeighborDecision is a past keep/discard decision on a photo that looks
+// like one in the current session.
+type NeighborDecision struct {
+	MediaKey   string
+	Kept       bool
+	Category   string
+	Reason     string
+	Similarity float64
+}
+
+// StageMediaEmbedding writes e to the RAG staging table. The daily profile
+// batch upserts it into media_embeddings, so Aurora stays stopped during
+// uploads (DDR-068).
+func StageMediaEmbedding(ctx context.Context, client *dynamodb.Client, table string, e MediaEmbedding) error {
+	if e.CreatedAt == "" {
+		e.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal MediaEmbedding: %w", err)
+	}
+	_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(table),
+		Item: map[string]types.AttributeValue{
+			"PK":           &types.AttributeValueMemberS{Value: stagingPK},
+			"SK":           &types.AttributeValueMemberS{Value: embeddingSKPrefix + e.SessionID + "#" + e.MediaKey},
+			"feedbackJSON": &types.AttributeValueMemberS{Value: string(body)},
+			"eventType":    &types.AttributeValueMemberS{Value: EventMediaEmbedding},
+			"sessionId":    &types.AttributeValueMemberS{Value: e.SessionID},
+			"expiresAt":    &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Add(stagingTTL).Unix(), 10)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("stage embedding for %s: %w", e.MediaKey, err)
+	}
+	return nil
+}
+
+// ReadStagedEmbeddings returns the embeddings staged for sessionID that the
+// daily batch has not yet moved to Aurora.
+func ReadStagedEmbeddings(ctx context.Context, client *dynamodb.Client, table, sessionID string) ([]MediaEmbedding, error) {
+	var out []MediaEmbedding
+	var lastKey map[string]types.AttributeValue
+	for {
+		resp, err := client.Query(ctx, &dynamodb.QueryInput{
+			TableName:              aws.String(table),
+			KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :prefix)"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk":     &types.AttributeValueMemberS{Value: stagingPK},
+				":prefix": &types.AttributeValueMemberS{Value: embeddingSKPrefix + sessionID + "#"},
+			},
+			ExclusiveStartKey: lastKey,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("query staged embeddings: %w", err)
+		}
+		for _, item := range resp.Items {
+			attr, ok := item["feedbackJSON"].(*types.AttributeValueMemberS)
+			if !ok {
+				continue
+			}
+			var e MediaEmbedding
+			if err := json.Unmarshal([]byte(attr.Value), &e); err != nil {
+				continue
+			}
+			out = append(out, e)
+		}
+		lastKey = resp.LastEvaluatedKey
+		if lastKey == nil {
+			return out, nil
+		}
+	}
+}
+
+// BatchUpsertMediaEmbeddings writes embeddings to media_embeddings, replacing
+// the vector of a file that was uploaded again.
+func (c *DataAPIClient) BatchUpsertMediaEmbeddings(ctx context.Context, embeddings []MediaEmbedding) error {
+	if len(embeddings) == 0 {
+		return nil
+	}
+	sql := `INSERT INTO media_embeddings (session_id, media_key, embedding, created_at)
+		VALUES (:session_id, :media_key, :embedding::vector, COALESCE(:created_at::timestamptz, NOW()))
+		ON CONFLICT (session_id, media_key) DO UPDATE SET
+			embedding = EXCLUDED.embedding, created_at = EXCLUDED.created_at`
+	paramSets := make([][]rdsdatatypes.SqlParameter, 0, len(embeddings))
+	for _, e := range embeddings {
+		paramSets = append(paramSets, []rdsdatatypes.SqlParameter{
+			{Name: aws.String("session_id"), Value: &rdsdatatypes.FieldMemberStringValue{Value: e.SessionID}},
+			{Name: aws.String("media_key"), Value: &rdsdatatypes.FieldMemberStringValue{Value: e.MediaKey}},
+			{Name: aws.String("embedding"), Value: &rdsdatatypes.FieldMemberStringValue{Value: formatVector(e.Embedding)}},
+			{Name: aws.String("created_at"), Value: &rdsdatatypes.FieldMemberStringValue{Value: e.CreatedAt}},
+		})
+	}
+	if err := c.batchExec(ctx, sql, paramSets); err != nil {
+		return fmt.Errorf("BatchUpsertMediaEmbeddings: %w", err)
+	}
+	return nil
+}
+
+// SessionEmbeddings returns the embeddings already in Aurora for sessionID,
+// for sessions whose staged embeddings were ingested before selection ran.
+func (c *DataAPIClient) SessionEmbeddings(ctx context.Context, sessionID string) ([]MediaEmbedding, error) {
+	rows, err := c.query(ctx, `SELECT media_key, embedding::text AS embedding FROM media_embeddings WHERE session_id = :session_id`,
+		[]rdsdatatypes.SqlParameter{
+			{Name: aws.String("session_id"), Value: &rdsdatatypes.FieldMemberStringValue{Value: sessionID}},
+		})
+	if err != nil {
+		return nil, fmt.Errorf("SessionEmbeddings: %w", err)
+	}
+	out := make([]MediaEmbedding, 0, len(rows))
+	for _, row := range rows {
+		key, _ := row["media_key"].(string)
+		text, _ := row["embedding"].(string)
+		emb, err := parseVector(text)
+		if err != nil {
+			return nil, fmt.Errorf("SessionEmbeddings: %s: %w", key, err)
+		}
+		out = append(out, MediaEmbedding{SessionID: sessionID, MediaKey: key, Embedding: emb})
+	}
+	return out, nil
+}
+
+// QueryNeighborDecisions returns the user's past triage and selection
+// decisions on the photos nearest to embedding, excluding the current
+// session. A selection verdict takes precedence over the triage verdict for
+// the same photo.
+func (c *DataAPIClient) QueryNeighborDecisions(ctx context.Context, userID, sessionID string, embedding []float32, topK int) ([]NeighborDecision, error) {
+	sql := `SELECT e.media_key,
+			COALESCE(s.selected, t.saveable) AS kept,
+			COALESCE(s.exclusion_category, '') AS category,
+			COALESCE(NULLIF(s.exclusion_reason, ''), t.reason, '') AS reason,
+			1 - (e.embedding <=> :emb::vector) AS similarity
+		FROM media_embeddings e
+		LEFT JOIN selection_decisions s ON s.session_id = e.session_id AND s.media_key = e.media_key AND s.user_id = :user_id
+		LEFT JOIN triage_decisions t ON t.session_id = e.session_id AND t.media_key = e.media_key AND t.user_id = :user_id
+		WHERE e.session_id <> :session_id AND (s.id IS NOT NULL OR t.id IS NOT NULL)
+		ORDER BY e.embedding <=> :emb::vector
+		LIMIT :topk`
+	rows, err := c.query(ctx, sql, []rdsdatatypes.SqlParameter{
+		{Name: aws.String("emb"), Value: &rdsdatatypes.FieldMemberStringValue{Value: formatVector(embedding)}},
+		{Name: aws.String("user_id"), Value: &rdsdatatypes.FieldMemberStringValue{Value: userID}},
+		{Name: aws.String("session_id"), Value: &rdsdatatypes.FieldMemberStringValue{Value: sessionID}},
+		{Name: aws.String("topk"), Value: &rdsdatatypes.FieldMemberLongValue{Value: int64(topK)}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("QueryNeighborDecisions: %w", err)
+	}
+	out := make([]NeighborDecision, 0, len(rows))
+	for _, row := range rows {
+		n := NeighborDecision{}
+		n.MediaKey, _ = row["media_key"].(string)
+		n.Kept, _ = row["kept"].(bool)
+		n.Category, _ = row["category"].(string)
+		n.Reason, _ = row["reason"].(string)
+		n.Similarity, _ = row["similarity"].(float64)
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// parseVector parses pgvector's text form, e.g. "[0.1,-0.2]".
+func parseVector(s string) ([]float32, error) {
+	s = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(s), "["), "]")
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	out := make([]float32, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 32)
+		if err != nil {
+			return nil, fmt.Errorf("parse vector: %w", err)
+		}
+		out[i] = float32(v)
+	}
+	return out, nil
+}
+
+// FormatNeighborContext renders past decisions on look-alike photos as
+// few-shot examples for the selection prompt. neighbors is keyed by the
+// current session's media key; keys are listed in the order given.
+func FormatNeighborContext(keys []string, neighbors map[string][]NeighborDecision) string {
+	var b strings.Builder
+	for _, key := range keys {
+		ns := neighbors[key]
+		if len(ns) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "- %s looks like:\n", path.Base(key))
+		for _, n := range ns {
+			verdict := "kept"
+			if !n.Kept {
+				verdict = "discarded"
+				if n.Category != "" {
+					verdict += " (" + n.Category + ")"
+				}
+			}
+			fmt.Fprintf(&b, "  - a past photo the user %s, %.0f%% similar", verdict, n.Similarity*100)
+			if n.Reason != "" {
+				fmt.Fprintf(&b, ": %s", n.Reason)
+			}
+			b.WriteByte('\n')
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return "## Similar Photos From Past Sessions\n\n" +
+		"These photos resemble ones the user has already kept or discarded. " +
+		"Treat the past decisions as examples of the user's taste, not as rules.\n\n" +
+		b.String()
+}
@@ -0,0 +1,44 @@
+package rag
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseVectorRoundTrip(t *testing.T) {
+	want := []float32{0.25, -1, 0.125}
+	got, err := parseVector(formatVector(want))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseVector(formatVector(%v)) = %v", want, got)
+	}
+}
+
+func TestFormatNeighborContext(t *testing.T) {
+	neighbors := map[string][]NeighborDecision{
+		"s2/b.jpg": {
+			{MediaKey: "s1/x.jpg", Kept: false, Category: "near-duplicate", Reason: "weaker of two sunset shots", Similarity: 0.93},
+			{MediaKey: "s1/y.jpg", Kept: true, Similarity: 0.88},
+		},
+	}
+	got := FormatNeighborContext([]string{"s2/a.jpg", "s2/b.jpg"}, neighbors)
+
+	for _, want := range []string{
+		"- b.jpg looks like:",
+		"discarded (near-duplicate), 93% similar: weaker of two sunset shots",
+		"kept, 88% similar\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("context missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "a.jpg") {
+		t.Errorf("photo without neighbors listed:\n%s", got)
+	}
+	if FormatNeighborContext([]string{"s2/a.jpg"}, nil) != "" {
+		t.Error("expected empty context when no photo has neighbors")
+	}
+}
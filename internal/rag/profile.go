@@ -7,25 +7,60 @@ import (
 	"strings"
 )
 
+// DecisionStats summarizes a user's curation decisions. The count fields are
+// mergeable, so a run can fold in only decisions newer than its watermark
+// (DDR-103); the rates are derived from them on every merge.
 type DecisionStats struct {
-	TotalSessions       int
-	TotalDecisions      int
-	KeepRate            float64
-	OverrideRate        float64
-	KeepReasonCounts    map[string]int
-	DiscardReasonCounts map[string]int
-	OverridePatterns    []string
-	RecentOverrides     []OverrideDecision
-	MediaTypeBreakdown  map[string]map[string]int
+	TotalSessions          int
+	TotalDecisions         int
+	KeptCount              int
+	FinalizedOverrideCount int
+	KeepRate               float64
+	OverrideRate           float64
+	KeepReasonCounts       map[string]int
+	DiscardReasonCounts    map[string]int
+	OverridePatterns       []string
+	RecentOverrides        []OverrideDecision
+	MediaTypeBreakdown     map[string]map[string]int
 }
 
+// recentOverrideLimit is how many of the newest overrides DecisionStats keeps.
+const recentOverrideLimit = 10
+
+// overridePatternLimit is how many of the newest override patterns
+// DecisionStats keeps. They are stored with the profile and sent in the
+// profile prompt, so they must not grow with the user's history.
+const overridePatternLimit = 50
+
+// ComputeStats computes stats from scratch over the full decision history.
 func ComputeStats(triageDecisions []TriageDecision, overrideDecisions []OverrideDecision, selectionDecisions []SelectionDecision) DecisionStats {
+	return MergeStats(DecisionStats{}, triageDecisions, overrideDecisions, selectionDecisions)
+}
+
+// MergeStats folds decisions created since prev was computed into a copy of
+// prev. Merging disjoint batches in any order gives the same counts as
+// ComputeStats over their union, with one approximation: a session is counted
+// once per batch it appears in, which is exact as long as a session's triage
+// decisions are finalized together.
+func MergeStats(prev DecisionStats, triageDecisions []TriageDecision, overrideDecisions []OverrideDecision, selectionDecisions []SelectionDecision) DecisionStats {
 	stats := DecisionStats{
-		KeepReasonCounts:    make(map[string]int),
-		DiscardReasonCounts: make(map[string]int),
-		OverridePatterns:    []string{},
-		RecentOverrides:     []OverrideDecision{},
-		MediaTypeBreakdown:  make(map[string]map[string]int),
+		TotalSessions:          prev.TotalSessions,
+		TotalDecisions:         prev.TotalDecisions,
+		KeptCount:              prev.KeptCount,
+		FinalizedOverrideCount: prev.FinalizedOverrideCount,
+		KeepReasonCounts:       make(map[string]int, len(prev.KeepReasonCounts)),
+		DiscardReasonCounts:    make(map[string]int, len(prev.DiscardReasonCounts)),
+		OverridePatterns:       append([]string{}, prev.OverridePatterns...),
+		MediaTypeBreakdown:     make(map[string]map[string]int, len(prev.MediaTypeBreakdown)),
+	}
+	for reason, n := range prev.KeepReasonCounts {
+		stats.KeepReasonCounts[reason] = n
+	}
+	for reason, n := range prev.DiscardReasonCounts {
+		stats.DiscardReasonCounts[reason] = n
+	}
+	for mt, counts := range prev.MediaTypeBreakdown {
+		stats.MediaTypeBreakdown[mt] = map[string]int{"kept": counts["kept"], "discarded": counts["discarded"]}
 	}
 
 	sessionSet := make(map[string]bool)
@@ -41,6 +76,7 @@ func ComputeStats(triageDecisions []TriageDecision, overrideDecisions []Override
 			stats.MediaTypeBreakdown[mt] = map[string]int{"kept": 0, "discarded": 0}
 		}
 		if d.Saveable {
+			stats.KeptCount++
 			stats.MediaTypeBreakdown[mt]["kept"]++
 			if d.Reason != "" {
 				stats.KeepReasonCounts[d.Reason]++
@@ -52,37 +88,32 @@ func ComputeStats(triageDecisions []TriageDecision, overrideDecisions []Override
 			}
 		}
 	}
-	stats.TotalSessions = len(sessionSet)
+	stats.TotalSessions += len(sessionSet)
 
-	if stats.TotalDecisions > 0 {
-		kept := 0
-		for _, d := range triageDecisions {
-			if d.Saveable {
-				kept++
-			}
-		}
-		stats.KeepRate = float64(kept) / float64(stats.TotalDecisions)
-	}
-
-	overrideCount := 0
 	for _, d := range overrideDecisions {
 		if d.IsFinalized {
-			overrideCount++
+			stats.FinalizedOverrideCount++
 		}
 		if d.AIVerdict != "" && d.Action != "" {
 			stats.OverridePatterns = append(stats.OverridePatterns, fmt.Sprintf("%s -> %s: %s", d.AIVerdict, d.Action, d.AIReason))
 		}
 	}
+	if n := len(stats.OverridePatterns); n > overridePatternLimit {
+		stats.OverridePatterns = stats.OverridePatterns[n-overridePatternLimit:]
+	}
+
 	if stats.TotalDecisions > 0 {
-		stats.OverrideRate = float64(overrideCount) / float64(stats.TotalDecisions)
+		stats.KeepRate = float64(stats.KeptCount) / float64(stats.TotalDecisions)
+		stats.OverrideRate = float64(stats.FinalizedOverrideCount) / float64(stats.TotalDecisions)
 	}
 
-	sorted := make([]OverrideDecision, len(overrideDecisions))
-	copy(sorted, overrideDecisions)
-	sort.Slice(sorted, func(i, j int) bool {
+	sorted := make([]OverrideDecision, 0, len(prev.RecentOverrides)+len(overrideDecisions))
+	sorted = append(sorted, prev.RecentOverrides...)
+	sorted = append(sorted, overrideDecisions...)
+	sort.SliceStable(sorted, func(i, j int) bool {
 		return sorted[i].CreatedAt > sorted[j].CreatedAt
 	})
-	n := 10
+	n := recentOverrideLimit
 	if len(sorted) < n {
 		n = len(sorted)
 	}
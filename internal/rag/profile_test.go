@@ -0,0 +1,81 @@
+package rag
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestMergeStatsMatchesFullCompute(t *testing.T) {
+	triage := []TriageDecision{
+		{SessionID: "s1", MediaType: "image", Saveable: true, Reason: "sharp"},
+		{SessionID: "s1", MediaType: "image", Saveable: false, Reason: "blurry"},
+		{SessionID: "s1", MediaType: "video", Saveable: true},
+		{SessionID: "s2", MediaType: "image", Saveable: false, Reason: "blurry"},
+		{SessionID: "s2", Saveable: true, Reason: "sharp"},
+	}
+	overrides := []OverrideDecision{
+		{SessionID: "s1", Action: "keep", AIVerdict: "discard", AIReason: "blurry", IsFinalized: true, CreatedAt: "2026-10-01 10:00:00"},
+		{SessionID: "s1", Action: "remove", AIVerdict: "keep", CreatedAt: "2026-10-01 11:00:00"},
+		{SessionID: "s2", Action: "keep", AIVerdict: "discard", IsFinalized: true, CreatedAt: "2026-10-02 09:00:00"},
+	}
+
+	full := ComputeStats(triage, overrides, nil)
+	merged := MergeStats(ComputeStats(triage[:3], overrides[:2], nil), triage[3:], overrides[2:], nil)
+
+	if !reflect.DeepEqual(full, merged) {
+		t.Errorf("MergeStats over two batches = %+v, want %+v", merged, full)
+	}
+	if full.TotalSessions != 2 || full.TotalDecisions != 5 || full.KeptCount != 3 || full.FinalizedOverrideCount != 2 {
+		t.Errorf("unexpected counts: %+v", full)
+	}
+	if full.RecentOverrides[0].SessionID != "s2" {
+		t.Errorf("RecentOverrides not newest first: %+v", full.RecentOverrides)
+	}
+}
+
+func TestMergeStatsDoesNotMutatePrevious(t *testing.T) {
+	prev := ComputeStats([]TriageDecision{{SessionID: "s1", MediaType: "image", Saveable: true, Reason: "sharp"}}, nil, nil)
+	MergeStats(prev, []TriageDecision{{SessionID: "s2", MediaType: "image", Saveable: true, Reason: "sharp"}}, nil, nil)
+
+	if prev.KeepReasonCounts["sharp"] != 1 || prev.MediaTypeBreakdown["image"]["kept"] != 1 {
+		t.Errorf("previous stats mutated: %+v", prev)
+	}
+}
+
+func TestMergeStatsCapsOverridePatterns(t *testing.T) {
+	var overrides []OverrideDecision
+	for i := 0; i < overridePatternLimit+5; i++ {
+		overrides = append(overrides, OverrideDecision{Action: "keep", AIVerdict: "discard", AIReason: fmt.Sprint(i)})
+	}
+	stats := MergeStats(ComputeStats(nil, overrides[:overridePatternLimit], nil), nil, overrides[overridePatternLimit:], nil)
+	if n := len(stats.OverridePatterns); n != overridePatternLimit {
+		t.Fatalf("%d override patterns kept, want %d", n, overridePatternLimit)
+	}
+	if first, last := stats.OverridePatterns[0], stats.OverridePatterns[overridePatternLimit-1]; first != "discard -> keep: 5" || last != fmt.Sprintf("discard -> keep: %d", overridePatternLimit+4) {
+		t.Errorf("kept patterns %q .. %q, want the newest", first, last)
+	}
+}
+
+func TestGroupByUser(t *testing.T) {
+	groups := GroupByUser(
+		[]TriageDecision{
+			{SessionID: "s1", UserID: "u1"},
+			{SessionID: "s2", UserID: "u2"},
+			{SessionID: "s3", UserID: "s3"}, // legacy: user ID is the session ID
+			{SessionID: "s4"},
+		},
+		[]OverrideDecision{{SessionID: "s1", UserID: "u1"}},
+		[]SelectionDecision{{SessionID: "s2", UserID: "u2"}},
+	)
+
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2: %+v", len(groups), groups)
+	}
+	if g := groups["u1"]; len(g.Triage) != 1 || len(g.Overrides) != 1 || len(g.Selection) != 0 {
+		t.Errorf("u1 = %+v", g)
+	}
+	if g := groups["u2"]; len(g.Triage) != 1 || len(g.Overrides) != 0 || len(g.Selection) != 1 {
+		t.Errorf("u2 = %+v", g)
+	}
+}
@@ -106,3 +106,17 @@ CREATE INDEX IF NOT EXISTS publish_decisions_embedding_idx
     ON publish_decisions USING hnsw (embedding vector_cosine_ops);
 CREATE INDEX IF NOT EXISTS publish_decisions_user_idx
     ON publish_decisions (user_id, created_at DESC);
+
+-- Thumbnail embeddings for similar-photo retrieval (DDR-109). Joined to
+-- triage_decisions and selection_decisions on (session_id, media_key).
+CREATE TABLE IF NOT EXISTS media_embeddings (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    session_id      TEXT NOT NULL,
+    media_key       TEXT NOT NULL,
+    embedding       vector(1024) NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (session_id, media_key)
+);
+
+CREATE INDEX IF NOT EXISTS media_embeddings_embedding_idx
+    ON media_embeddings USING hnsw (embedding vector_cosine_ops);
@@ -2,6 +2,7 @@ package rag
 
 const (
 	EventTriageFinalized      = "triage.finalized"
+	EventTriageOverride       = "triage.override"
 	EventSelectionFinalized   = "selection.finalized"
 	EventOverrideAction       = "selection.override.action"
 	EventOverridesFinalized   = "selection.overrides.finalized"
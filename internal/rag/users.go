@@ -0,0 +1,72 @@
+package rag
+
+// GlobalProfilePK is the partition key of the profile built from every
+// user's decisions. It is the fallback for callers without a profile of their
+// own (DDR-105).
+const GlobalProfilePK = "PROFILE#preference"
+
+// MinUserProfileDecisions is how many triage decisions a user needs before a
+// profile of their own is generated; until then the global profile is used.
+const MinUserProfileDecisions = 20
+
+// ProfilePK returns the profile partition key for userID, or GlobalProfilePK
+// when the user is unknown.
+func ProfilePK(userID string) string {
+	if userID == "" {
+		return GlobalProfilePK
+	}
+	return "PROFILE#" + userID
+}
+
+// UserID returns the user ID to record on a session's decisions: the session
+// owner's Cognito sub, or the session ID when there is no owner (auth
+// disabled). Decisions whose user ID equals their session ID only count
+// toward the global profile.
+func UserID(ownerSub, sessionID string) string {
+	if ownerSub != "" {
+		return ownerSub
+	}
+	return sessionID
+}
+
+// UserDecisions is one user's share of a batch of decisions.
+type UserDecisions struct {
+	Triage    []TriageDecision
+	Overrides []OverrideDecision
+	Selection []SelectionDecision
+}
+
+// GroupByUser splits decisions by user ID. Rows without a known user — an
+// empty user ID, or one equal to the session ID as recorded before per-user
+// profiles or without auth — are left out.
+func GroupByUser(triageDecisions []TriageDecision, overrideDecisions []OverrideDecision, selectionDecisions []SelectionDecision) map[string]*UserDecisions {
+	groups := make(map[string]*UserDecisions)
+	group := func(userID, sessionID string) *UserDecisions {
+		if userID == "" || userID == sessionID {
+			return nil
+		}
+		g := groups[userID]
+		if g == nil {
+			g = &UserDecisions{}
+			groups[userID] = g
+		}
+		return g
+	}
+
+	for _, d := range triageDecisions {
+		if g := group(d.UserID, d.SessionID); g != nil {
+			g.Triage = append(g.Triage, d)
+		}
+	}
+	for _, d := range overrideDecisions {
+		if g := group(d.UserID, d.SessionID); g != nil {
+			g.Overrides = append(g.Overrides, d)
+		}
+	}
+	for _, d := range selectionDecisions {
+		if g := group(d.UserID, d.SessionID); g != nil {
+			g.Selection = append(g.Selection, d)
+		}
+	}
+	return groups
+}
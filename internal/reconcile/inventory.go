@@ -0,0 +1,140 @@
+// Package reconcile cross-references an S3 Inventory report of the media
+// bucket against the session table and reports storage drift (DDR-148):
+// objects under sessions DynamoDB no longer knows, originals without a
+// thumbnail, and sessions that hold storage but never ran a job.
+package reconcile
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Manifest is an S3 Inventory manifest.json. Only CSV inventories are
+// supported; their data files carry no header row, so FileSchema names the
+// columns.
+type Manifest struct {
+	SourceBucket      string         `json:"sourceBucket"`
+	DestinationBucket string         `json:"destinationBucket"`
+	CreationTimestamp string         `json:"creationTimestamp"` // Unix milliseconds
+	FileFormat        string         `json:"fileFormat"`
+	FileSchema        string         `json:"fileSchema"`
+	Files             []ManifestFile `json:"files"`
+}
+
+// ManifestFile is one gzipped CSV data file of an inventory.
+type ManifestFile struct {
+	Key  string `json:"key"`
+	Size int64  `json:"size"`
+}
+
+// Object is one current object listed by an inventory.
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// ParseManifest decodes a manifest and checks that it can be read.
+func ParseManifest(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse inventory manifest: %w", err)
+	}
+	if !strings.EqualFold(m.FileFormat, "CSV") {
+		return nil, fmt.Errorf("inventory format %q is not supported, configure CSV", m.FileFormat)
+	}
+	if _, err := m.columns(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// CreatedAt returns when S3 took the inventory.
+func (m *Manifest) CreatedAt() time.Time {
+	ms, err := strconv.ParseInt(m.CreationTimestamp, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+// inventoryColumns are the positions of the fields the report reads; -1
+// when the inventory does not include the field.
+type inventoryColumns struct {
+	key, size, lastModified, isLatest, isDeleteMarker int
+}
+
+func (m *Manifest) columns() (inventoryColumns, error) {
+	cols := inventoryColumns{-1, -1, -1, -1, -1}
+	for i, name := range strings.Split(m.FileSchema, ",") {
+		switch strings.TrimSpace(name) {
+		case "Key":
+			cols.key = i
+		case "Size":
+			cols.size = i
+		case "LastModifiedDate":
+			cols.lastModified = i
+		case "IsLatest":
+			cols.isLatest = i
+		case "IsDeleteMarker":
+			cols.isDeleteMarker = i
+		}
+	}
+	if cols.key < 0 || cols.size < 0 {
+		return cols, fmt.Errorf("inventory schema %q lacks Key or Size", m.FileSchema)
+	}
+	return cols, nil
+}
+
+// ReadObjects calls fn for every current object in one gzipped CSV data
+// file. Noncurrent versions and delete markers are skipped. Keys are
+// URL-encoded in the inventory and decoded here.
+func (m *Manifest) ReadObjects(r io.Reader, fn func(Object)) error {
+	cols, err := m.columns()
+	if err != nil {
+		return err
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("open inventory file: %w", err)
+	}
+	defer gz.Close()
+
+	cr := csv.NewReader(gz)
+	cr.FieldsPerRecord = -1
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read inventory row: %w", err)
+		}
+		if field(rec, cols.isLatest) == "false" || field(rec, cols.isDeleteMarker) == "true" {
+			continue
+		}
+		key, err := url.QueryUnescape(field(rec, cols.key))
+		if err != nil {
+			return fmt.Errorf("decode inventory key %q: %w", field(rec, cols.key), err)
+		}
+		size, _ := strconv.ParseInt(field(rec, cols.size), 10, 64)
+		modified, _ := time.Parse(time.RFC3339, field(rec, cols.lastModified))
+		fn(Object{Key: key, Size: size, LastModified: modified})
+	}
+}
+
+// field returns column i of rec, or "" when the inventory lacks it.
+func field(rec []string, i int) string {
+	if i < 0 || i >= len(rec) {
+		return ""
+	}
+	return rec[i]
+}
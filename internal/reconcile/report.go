@@ -0,0 +1,214 @@
+package reconcile
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+)
+
+const (
+	// MaxEntries caps each list in a report; the counts and byte totals
+	// still cover everything found.
+	MaxEntries = 1000
+
+	// SettleTime skips objects this recent when checking thumbnails and jobs,
+	// so uploads still being processed are not reported as drift.
+	SettleTime = time.Hour
+
+	// Reports are written to the inventory bucket, one per inventory date
+	// plus a copy of the newest.
+	ReportPrefix    = "reconcile-reports/"
+	LatestReportKey = ReportPrefix + "latest.json"
+)
+
+// sessionPrefix matches the session ID that starts every session key.
+var sessionPrefix = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// SessionState is what the session table knows about a session.
+type SessionState struct {
+	Exists bool
+	Jobs   int
+}
+
+// LookupFunc reads a session's state from the session table.
+type LookupFunc func(ctx context.Context, sessionID string) (SessionState, error)
+
+// SessionStorage is the storage one session holds.
+type SessionStorage struct {
+	SessionID    string `json:"sessionId"`
+	Objects      int64  `json:"objects"`
+	Bytes        int64  `json:"bytes"`
+	LastModified int64  `json:"lastModified"` // Unix seconds, newest object
+}
+
+// MissingThumbnail is an uploaded original whose thumbnail is not in S3.
+type MissingThumbnail struct {
+	SessionID string `json:"sessionId"`
+	Key       string `json:"key"`
+	Thumbnail string `json:"thumbnail"`
+}
+
+// Report is the outcome of one reconciliation. Lists are sorted largest or
+// oldest first and capped at MaxEntries.
+type Report struct {
+	GeneratedAt int64  `json:"generatedAt"` // Unix seconds
+	InventoryAt int64  `json:"inventoryAt"` // Unix seconds
+	Bucket      string `json:"bucket"`
+	Objects     int64  `json:"objects"`
+	Bytes       int64  `json:"bytes"`
+	Sessions    int    `json:"sessions"`   // session prefixes in the bucket
+	OtherBytes  int64  `json:"otherBytes"` // outside session prefixes (batch input, masks, ...)
+
+	// Unreferenced lists sessions with objects but no session record.
+	Unreferenced      []SessionStorage `json:"unreferenced"`
+	UnreferencedCount int              `json:"unreferencedCount"`
+	UnreferencedBytes int64            `json:"unreferencedBytes"`
+
+	// NoJobs lists sessions with a record and storage but no job records.
+	NoJobs      []SessionStorage `json:"noJobs"`
+	NoJobsCount int              `json:"noJobsCount"`
+	NoJobsBytes int64            `json:"noJobsBytes"`
+
+	// MissingThumbnails lists originals of live sessions without a thumbnail.
+	MissingThumbnails     []MissingThumbnail `json:"missingThumbnails"`
+	MissingThumbnailCount int                `json:"missingThumbnailCount"`
+}
+
+// sessionObjects accumulates the inventory rows of one session.
+type sessionObjects struct {
+	storage    SessionStorage
+	originals  map[string]time.Time // key → last modified
+	thumbnails map[string]bool
+}
+
+// Builder accumulates inventory objects into a report.
+type Builder struct {
+	bucket      string
+	inventoryAt time.Time
+	objects     int64
+	bytes       int64
+	otherBytes  int64
+	sessions    map[string]*sessionObjects
+}
+
+// NewBuilder starts a report for the inventory of bucket taken at inventoryAt.
+func NewBuilder(bucket string, inventoryAt time.Time) *Builder {
+	return &Builder{bucket: bucket, inventoryAt: inventoryAt, sessions: map[string]*sessionObjects{}}
+}
+
+// Add records one inventory object.
+func (b *Builder) Add(obj Object) {
+	b.objects++
+	b.bytes += obj.Size
+
+	sessionID, rest, ok := strings.Cut(obj.Key, "/")
+	if !ok || !sessionPrefix.MatchString(sessionID) {
+		b.otherBytes += obj.Size
+		return
+	}
+	s := b.sessions[sessionID]
+	if s == nil {
+		s = &sessionObjects{
+			storage:    SessionStorage{SessionID: sessionID},
+			originals:  map[string]time.Time{},
+			thumbnails: map[string]bool{},
+		}
+		b.sessions[sessionID] = s
+	}
+	s.storage.Objects++
+	s.storage.Bytes += obj.Size
+	if m := obj.LastModified.Unix(); m > s.storage.LastModified {
+		s.storage.LastModified = m
+	}
+
+	switch {
+	case strings.HasPrefix(rest, "thumbnails/"):
+		s.thumbnails[obj.Key] = true
+	case !strings.Contains(rest, "/") && media.IsSupported(path.Ext(rest)):
+		// Originals are stored directly under the session prefix.
+		s.originals[obj.Key] = obj.LastModified
+	}
+}
+
+// Build looks up every session seen and returns the report.
+func (b *Builder) Build(ctx context.Context, lookup LookupFunc) (*Report, error) {
+	r := &Report{
+		GeneratedAt:       time.Now().Unix(),
+		InventoryAt:       b.inventoryAt.Unix(),
+		Bucket:            b.bucket,
+		Objects:           b.objects,
+		Bytes:             b.bytes,
+		Sessions:          len(b.sessions),
+		OtherBytes:        b.otherBytes,
+		Unreferenced:      []SessionStorage{},
+		NoJobs:            []SessionStorage{},
+		MissingThumbnails: []MissingThumbnail{},
+	}
+	settled := b.inventoryAt.Add(-SettleTime)
+
+	ids := make([]string, 0, len(b.sessions))
+	for id := range b.sessions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		s := b.sessions[id]
+		state, err := lookup(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !state.Exists {
+			r.Unreferenced = append(r.Unreferenced, s.storage)
+			r.UnreferencedBytes += s.storage.Bytes
+			continue
+		}
+		if state.Jobs == 0 && time.Unix(s.storage.LastModified, 0).Before(settled) {
+			r.NoJobs = append(r.NoJobs, s.storage)
+			r.NoJobsBytes += s.storage.Bytes
+		}
+		for key, modified := range s.originals {
+			if modified.After(settled) {
+				continue
+			}
+			thumb := ThumbnailKey(key)
+			if !s.thumbnails[thumb] {
+				r.MissingThumbnails = append(r.MissingThumbnails, MissingThumbnail{SessionID: id, Key: key, Thumbnail: thumb})
+			}
+		}
+	}
+
+	r.UnreferencedCount, r.NoJobsCount, r.MissingThumbnailCount = len(r.Unreferenced), len(r.NoJobs), len(r.MissingThumbnails)
+	r.Unreferenced = largestFirst(r.Unreferenced)
+	r.NoJobs = largestFirst(r.NoJobs)
+	sort.Slice(r.MissingThumbnails, func(i, j int) bool { return r.MissingThumbnails[i].Key < r.MissingThumbnails[j].Key })
+	if len(r.MissingThumbnails) > MaxEntries {
+		r.MissingThumbnails = r.MissingThumbnails[:MaxEntries]
+	}
+	return r, nil
+}
+
+// ThumbnailKey returns the thumbnail key of an uploaded original,
+// {sessionId}/thumbnails/{baseName}.jpg.
+func ThumbnailKey(key string) string {
+	sessionID, name, _ := strings.Cut(key, "/")
+	return sessionID + "/thumbnails/" + strings.TrimSuffix(name, path.Ext(name)) + ".jpg"
+}
+
+func largestFirst(list []SessionStorage) []SessionStorage {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Bytes != list[j].Bytes {
+			return list[i].Bytes > list[j].Bytes
+		}
+		return list[i].SessionID < list[j].SessionID
+	})
+	if len(list) > MaxEntries {
+		list = list[:MaxEntries]
+	}
+	return list
+}
@@ -0,0 +1,101 @@
+package reconcile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"testing"
+	"time"
+)
+
+const (
+	liveSession     = "11111111-1111-1111-1111-111111111111"
+	goneSession     = "22222222-2222-2222-2222-222222222222"
+	idleSession     = "33333333-3333-3333-3333-333333333333"
+	freshSession    = "44444444-4444-4444-4444-444444444444"
+	inventorySchema = "Bucket, Key, Size, LastModifiedDate, IsLatest, IsDeleteMarker"
+)
+
+func TestReadObjects(t *testing.T) {
+	m, err := ParseManifest([]byte(`{"sourceBucket":"media","creationTimestamp":"1800000000000","fileFormat":"CSV","fileSchema":"` + inventorySchema + `","files":[]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.CreatedAt(); !got.Equal(time.Unix(1_800_000_000, 0)) {
+		t.Errorf("CreatedAt = %v", got)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(`"media","sid/My%20Photo.jpg","1024","2027-01-15T08:00:00.000Z","true","false"
+"media","sid/old.jpg","10","2027-01-14T08:00:00.000Z","false","false"
+"media","sid/deleted.jpg","0","2027-01-14T08:00:00.000Z","true","true"
+`))
+	gz.Close()
+
+	var objs []Object
+	if err := m.ReadObjects(&buf, func(o Object) { objs = append(objs, o) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 1 {
+		t.Fatalf("got %d objects, want 1: %+v", len(objs), objs)
+	}
+	if objs[0].Key != "sid/My Photo.jpg" || objs[0].Size != 1024 || objs[0].LastModified.IsZero() {
+		t.Errorf("object = %+v", objs[0])
+	}
+}
+
+func TestParseManifestRejectsParquet(t *testing.T) {
+	if _, err := ParseManifest([]byte(`{"fileFormat":"Parquet","fileSchema":"message s3.inventory {}"}`)); err == nil {
+		t.Error("expected an error for a Parquet inventory")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	inventoryAt := time.Unix(1_800_000_000, 0)
+	old := inventoryAt.Add(-24 * time.Hour)
+	recent := inventoryAt.Add(-10 * time.Minute)
+
+	b := NewBuilder("media", inventoryAt)
+	for _, o := range []Object{
+		{Key: liveSession + "/a.jpg", Size: 100, LastModified: old},
+		{Key: liveSession + "/thumbnails/a.jpg", Size: 10, LastModified: old},
+		{Key: liveSession + "/b.mov", Size: 500, LastModified: old},
+		{Key: liveSession + "/c.jpg", Size: 100, LastModified: recent},
+		{Key: liveSession + "/enhanced/a.jpg", Size: 100, LastModified: old},
+		{Key: goneSession + "/x.jpg", Size: 700, LastModified: old},
+		{Key: goneSession + "/thumbnails/x.jpg", Size: 7, LastModified: old},
+		{Key: idleSession + "/y.jpg", Size: 300, LastModified: old},
+		{Key: idleSession + "/thumbnails/y.jpg", Size: 3, LastModified: old},
+		{Key: freshSession + "/z.jpg", Size: 50, LastModified: recent},
+		{Key: "batch-input/job.jsonl", Size: 40, LastModified: old},
+	} {
+		b.Add(o)
+	}
+
+	states := map[string]SessionState{
+		liveSession:  {Exists: true, Jobs: 2},
+		idleSession:  {Exists: true},
+		freshSession: {Exists: true},
+	}
+	r, err := b.Build(context.Background(), func(_ context.Context, id string) (SessionState, error) {
+		return states[id], nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Objects != 11 || r.Sessions != 4 || r.OtherBytes != 40 {
+		t.Errorf("totals = %d objects, %d sessions, %d other bytes", r.Objects, r.Sessions, r.OtherBytes)
+	}
+	if r.UnreferencedCount != 1 || r.Unreferenced[0].SessionID != goneSession || r.UnreferencedBytes != 707 {
+		t.Errorf("unreferenced = %+v (%d bytes)", r.Unreferenced, r.UnreferencedBytes)
+	}
+	if r.NoJobsCount != 1 || r.NoJobs[0].SessionID != idleSession {
+		t.Errorf("noJobs = %+v; want only the settled idle session", r.NoJobs)
+	}
+	if r.MissingThumbnailCount != 1 || r.MissingThumbnails[0].Key != liveSession+"/b.mov" ||
+		r.MissingThumbnails[0].Thumbnail != liveSession+"/thumbnails/b.jpg" {
+		t.Errorf("missingThumbnails = %+v; want only the settled video", r.MissingThumbnails)
+	}
+}
@@ -13,6 +13,8 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
 )
 
 // DownloadToFile downloads an S3 object to a specific local path.
@@ -33,6 +35,7 @@ func DownloadToFile(ctx context.Context, client *s3.Client, bucket, key, localPa
 	}
 	defer f.Close()
 
+	var written int64
 	buf := make([]byte, 32*1024)
 	for {
 		n, readErr := result.Body.Read(buf)
@@ -40,6 +43,7 @@ func DownloadToFile(ctx context.Context, client *s3.Client, bucket, key, localPa
 			if _, writeErr := f.Write(buf[:n]); writeErr != nil {
 				return fmt.Errorf("write: %w", writeErr)
 			}
+			written += int64(n)
 		}
 		if readErr != nil {
 			if readErr.Error() == "EOF" {
@@ -48,6 +52,7 @@ func DownloadToFile(ctx context.Context, client *s3.Client, bucket, key, localPa
 			return fmt.Errorf("download: %w", readErr)
 		}
 	}
+	metrics.RecordS3Download(ctx, written)
 	return nil
 }
 
@@ -71,6 +76,7 @@ func DownloadToTempFile(ctx context.Context, client *s3.Client, bucket, key stri
 	}
 	defer result.Body.Close()
 
+	var written int64
 	buf := make([]byte, 32*1024)
 	for {
 		n, readErr := result.Body.Read(buf)
@@ -80,6 +86,7 @@ func DownloadToTempFile(ctx context.Context, client *s3.Client, bucket, key stri
 				os.Remove(tmpFile.Name())
 				return "", nil, fmt.Errorf("write: %w", writeErr)
 			}
+			written += int64(n)
 		}
 		if readErr != nil {
 			if readErr.Error() == "EOF" {
@@ -91,6 +98,7 @@ func DownloadToTempFile(ctx context.Context, client *s3.Client, bucket, key stri
 		}
 	}
 	tmpFile.Close()
+	metrics.RecordS3Download(ctx, written)
 
 	cleanup := func() { os.Remove(tmpFile.Name()) }
 	return tmpFile.Name(), cleanup, nil
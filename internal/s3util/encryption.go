@@ -0,0 +1,289 @@
+package s3util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Per-session encryption keys (DDR-164) ---
+//
+// An encrypted session gets its own KMS key, created when the user turns
+// encryption on and scheduled for deletion when the session is deleted, or
+// by the daily sweep once the session has expired.
+// Every object written under the session prefix is encrypted with SSE-KMS
+// under that key, so once the key is gone nothing left of the session, in
+// the bucket or in a backup of it, can be read.
+//
+// The key is found through the alias alias/ai-social-media/session-{id}, so
+// a Lambda needs only the session ID to encrypt what it writes. Reads need
+// nothing: S3 decrypts with the key recorded on the object, provided the
+// caller may use it.
+
+// SessionKeyDeletionWindow is the waiting period, in days, before KMS
+// deletes a retired session key. Seven is the minimum KMS allows.
+const SessionKeyDeletionWindow = 7
+
+// sessionKeyAliasPrefix starts the alias of every session key.
+const sessionKeyAliasPrefix = "alias/ai-social-media/session-"
+
+// SessionKeyAlias returns the KMS alias of a session's key.
+func SessionKeyAlias(sessionID string) string {
+	return sessionKeyAliasPrefix + sessionID
+}
+
+// kmsAPI is the subset of the KMS client SessionKeys uses.
+type kmsAPI interface {
+	CreateKey(ctx context.Context, in *kms.CreateKeyInput, optFns ...func(*kms.Options)) (*kms.CreateKeyOutput, error)
+	CreateAlias(ctx context.Context, in *kms.CreateAliasInput, optFns ...func(*kms.Options)) (*kms.CreateAliasOutput, error)
+	DescribeKey(ctx context.Context, in *kms.DescribeKeyInput, optFns ...func(*kms.Options)) (*kms.DescribeKeyOutput, error)
+	DeleteAlias(ctx context.Context, in *kms.DeleteAliasInput, optFns ...func(*kms.Options)) (*kms.DeleteAliasOutput, error)
+	ScheduleKeyDeletion(ctx context.Context, in *kms.ScheduleKeyDeletionInput, optFns ...func(*kms.Options)) (*kms.ScheduleKeyDeletionOutput, error)
+	ListAliases(ctx context.Context, in *kms.ListAliasesInput, optFns ...func(*kms.Options)) (*kms.ListAliasesOutput, error)
+}
+
+// SessionKeys creates, finds and retires session keys. Lookups are cached
+// for the life of the process, including the answer that a session has no
+// key: encryption can only be turned on before the first upload, so no
+// worker sees a session before its key exists. A nil *SessionKeys treats
+// every session as unencrypted.
+type SessionKeys struct {
+	client kmsAPI
+
+	mu    sync.Mutex
+	cache map[string]string // session ID → key ARN, "" for none
+}
+
+// NewSessionKeys returns a SessionKeys backed by client.
+func NewSessionKeys(client *kms.Client) *SessionKeys {
+	return newSessionKeys(client)
+}
+
+func newSessionKeys(client kmsAPI) *SessionKeys {
+	return &SessionKeys{client: client, cache: map[string]string{}}
+}
+
+// Create makes a new key for the session and points the session's alias at
+// it, returning the key ARN. A key whose alias cannot be created is
+// scheduled for deletion again.
+func (k *SessionKeys) Create(ctx context.Context, sessionID string) (string, error) {
+	out, err := k.client.CreateKey(ctx, &kms.CreateKeyInput{
+		Description: aws.String("ai-social-media-helper session " + sessionID),
+		Tags: []kmstypes.Tag{
+			{TagKey: aws.String("Project"), TagValue: aws.String("ai-social-media-helper")},
+			{TagKey: aws.String("SessionId"), TagValue: aws.String(sessionID)},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("KMS CreateKey: %w", err)
+	}
+	arn := aws.ToString(out.KeyMetadata.Arn)
+
+	if _, err := k.client.CreateAlias(ctx, &kms.CreateAliasInput{
+		AliasName:   aws.String(SessionKeyAlias(sessionID)),
+		TargetKeyId: aws.String(arn),
+	}); err != nil {
+		if _, delErr := k.client.ScheduleKeyDeletion(ctx, &kms.ScheduleKeyDeletionInput{
+			KeyId:               aws.String(arn),
+			PendingWindowInDays: aws.Int32(SessionKeyDeletionWindow),
+		}); delErr != nil {
+			log.Warn().Err(delErr).Str("keyArn", arn).Msg("Failed to schedule deletion of orphaned session key")
+		}
+		return "", fmt.Errorf("KMS CreateAlias: %w", err)
+	}
+
+	k.remember(sessionID, arn)
+	log.Info().Str("sessionId", sessionID).Str("keyArn", arn).Msg("Session key created")
+	return arn, nil
+}
+
+// ForSession returns the encryption for new objects of the session: SSE-KMS
+// under its key, or the zero Encryption (the bucket default) when the
+// session has none. It fails rather than guess when the key cannot be
+// looked up or is no longer enabled, so an encrypted session never gains
+// an object under the bucket default.
+func (k *SessionKeys) ForSession(ctx context.Context, sessionID string) (Encryption, error) {
+	if k == nil {
+		return Encryption{}, nil
+	}
+	k.mu.Lock()
+	arn, ok := k.cache[sessionID]
+	k.mu.Unlock()
+	if ok {
+		return Encryption{KMSKeyID: arn}, nil
+	}
+
+	out, err := k.client.DescribeKey(ctx, &kms.DescribeKeyInput{KeyId: aws.String(SessionKeyAlias(sessionID))})
+	var notFound *kmstypes.NotFoundException
+	switch {
+	case errors.As(err, &notFound):
+		k.remember(sessionID, "")
+		return Encryption{}, nil
+	case err != nil:
+		return Encryption{}, fmt.Errorf("KMS DescribeKey for session %s: %w", sessionID, err)
+	case out.KeyMetadata.KeyState != kmstypes.KeyStateEnabled:
+		return Encryption{}, fmt.Errorf("session %s key is %s", sessionID, out.KeyMetadata.KeyState)
+	}
+	arn = aws.ToString(out.KeyMetadata.Arn)
+	k.remember(sessionID, arn)
+	return Encryption{KMSKeyID: arn}, nil
+}
+
+// ForKey is ForSession for the session whose prefix holds key.
+func (k *SessionKeys) ForKey(ctx context.Context, key string) (Encryption, error) {
+	sessionID, _, _ := strings.Cut(key, "/")
+	return k.ForSession(ctx, sessionID)
+}
+
+// Retire removes the session's alias and schedules its key for deletion
+// after SessionKeyDeletionWindow days. Retiring a key that is already gone
+// or pending deletion is not an error.
+func (k *SessionKeys) Retire(ctx context.Context, sessionID, keyARN string) error {
+	k.mu.Lock()
+	delete(k.cache, sessionID)
+	k.mu.Unlock()
+
+	var notFound *kmstypes.NotFoundException
+	if _, err := k.client.DeleteAlias(ctx, &kms.DeleteAliasInput{
+		AliasName: aws.String(SessionKeyAlias(sessionID)),
+	}); err != nil && !errors.As(err, &notFound) {
+		return fmt.Errorf("KMS DeleteAlias: %w", err)
+	}
+
+	var invalidState *kmstypes.KMSInvalidStateException
+	_, err := k.client.ScheduleKeyDeletion(ctx, &kms.ScheduleKeyDeletionInput{
+		KeyId:               aws.String(keyARN),
+		PendingWindowInDays: aws.Int32(SessionKeyDeletionWindow),
+	})
+	if err != nil && !errors.As(err, &notFound) && !errors.As(err, &invalidState) {
+		return fmt.Errorf("KMS ScheduleKeyDeletion: %w", err)
+	}
+
+	log.Info().Str("sessionId", sessionID).Str("keyArn", keyARN).Int("windowDays", SessionKeyDeletionWindow).Msg("Session key scheduled for deletion")
+	return nil
+}
+
+// SweepResult counts what Sweep looked at and did.
+type SweepResult struct {
+	Keys    int `json:"keys"`
+	Retired int `json:"retired"`
+	Failed  int `json:"failed"`
+}
+
+// Sweep retires the key of every session that exists reports gone. Most
+// sessions end through the DynamoDB TTL or the bucket lifecycle rather
+// than a delete, and nothing else would retire their keys. A session whose
+// existence cannot be checked keeps its key until the next sweep; a key
+// that fails to retire is counted and the sweep moves on.
+func (k *SessionKeys) Sweep(ctx context.Context, exists func(ctx context.Context, sessionID string) (bool, error)) (SweepResult, error) {
+	var result SweepResult
+	var marker *string
+	for {
+		page, err := k.client.ListAliases(ctx, &kms.ListAliasesInput{Marker: marker})
+		if err != nil {
+			return result, fmt.Errorf("KMS ListAliases: %w", err)
+		}
+		for _, alias := range page.Aliases {
+			sessionID, ok := strings.CutPrefix(aws.ToString(alias.AliasName), sessionKeyAliasPrefix)
+			if !ok || alias.TargetKeyId == nil {
+				continue
+			}
+			result.Keys++
+			found, err := exists(ctx, sessionID)
+			if err != nil {
+				log.Warn().Err(err).Str("sessionId", sessionID).Msg("Failed to look up session, keeping its key")
+				result.Failed++
+				continue
+			}
+			if found {
+				continue
+			}
+			if err := k.Retire(ctx, sessionID, aws.ToString(alias.TargetKeyId)); err != nil {
+				log.Warn().Err(err).Str("sessionId", sessionID).Msg("Failed to retire key of ended session")
+				result.Failed++
+				continue
+			}
+			result.Retired++
+		}
+		if !page.Truncated {
+			return result, nil
+		}
+		marker = page.NextMarker
+	}
+}
+
+func (k *SessionKeys) remember(sessionID, arn string) {
+	k.mu.Lock()
+	k.cache[sessionID] = arn
+	k.mu.Unlock()
+}
+
+// Encryption is the server-side encryption to request for a new object.
+// The zero value requests nothing, leaving the bucket default in place.
+type Encryption struct {
+	KMSKeyID string
+}
+
+// Encrypted reports whether objects are encrypted under a session key.
+func (e Encryption) Encrypted() bool {
+	return e.KMSKeyID != ""
+}
+
+// Put sets the encryption on a PutObject request and returns it.
+func (e Encryption) Put(in *s3.PutObjectInput) *s3.PutObjectInput {
+	if e.Encrypted() {
+		in.ServerSideEncryption = s3types.ServerSideEncryptionAwsKms
+		in.SSEKMSKeyId = aws.String(e.KMSKeyID)
+	}
+	return in
+}
+
+// Copy sets the encryption of the copy on a CopyObject request and returns
+// it. Without it S3 would encrypt the copy with the bucket default.
+func (e Encryption) Copy(in *s3.CopyObjectInput) *s3.CopyObjectInput {
+	if e.Encrypted() {
+		in.ServerSideEncryption = s3types.ServerSideEncryptionAwsKms
+		in.SSEKMSKeyId = aws.String(e.KMSKeyID)
+	}
+	return in
+}
+
+// Multipart sets the encryption on a CreateMultipartUpload request and
+// returns it. The parts need nothing further.
+func (e Encryption) Multipart(in *s3.CreateMultipartUploadInput) *s3.CreateMultipartUploadInput {
+	if e.Encrypted() {
+		in.ServerSideEncryption = s3types.ServerSideEncryptionAwsKms
+		in.SSEKMSKeyId = aws.String(e.KMSKeyID)
+	}
+	return in
+}
+
+// UploadHeaders returns the headers a browser must send with a presigned
+// PUT signed after Put, or nil when there are none.
+func (e Encryption) UploadHeaders() map[string]string {
+	if !e.Encrypted() {
+		return nil
+	}
+	return map[string]string{
+		"x-amz-server-side-encryption":                string(s3types.ServerSideEncryptionAwsKms),
+		"x-amz-server-side-encryption-aws-kms-key-id": e.KMSKeyID,
+	}
+}
+
+// ObjectEncryption returns the session-key encryption an existing object
+// carries, so a rewrite of it keeps the same key.
+func ObjectEncryption(head *s3.HeadObjectOutput) Encryption {
+	if head.ServerSideEncryption != s3types.ServerSideEncryptionAwsKms {
+		return Encryption{}
+	}
+	return Encryption{KMSKeyID: aws.ToString(head.SSEKMSKeyId)}
+}
@@ -0,0 +1,241 @@
+package s3util
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// fakeKMS holds aliases in memory and counts DescribeKey calls.
+type fakeKMS struct {
+	aliases   map[string]string // alias → key ARN
+	states    map[string]kmstypes.KeyState
+	describes int
+	deleted   []string
+	aliasErr  error
+}
+
+func newFakeKMS() *fakeKMS {
+	return &fakeKMS{aliases: map[string]string{}, states: map[string]kmstypes.KeyState{}}
+}
+
+func (f *fakeKMS) CreateKey(ctx context.Context, in *kms.CreateKeyInput, _ ...func(*kms.Options)) (*kms.CreateKeyOutput, error) {
+	arn := "arn:aws:kms:us-east-1:123456789012:key/" + aws.ToString(in.Description)
+	f.states[arn] = kmstypes.KeyStateEnabled
+	return &kms.CreateKeyOutput{KeyMetadata: &kmstypes.KeyMetadata{Arn: aws.String(arn)}}, nil
+}
+
+func (f *fakeKMS) CreateAlias(ctx context.Context, in *kms.CreateAliasInput, _ ...func(*kms.Options)) (*kms.CreateAliasOutput, error) {
+	if f.aliasErr != nil {
+		return nil, f.aliasErr
+	}
+	f.aliases[aws.ToString(in.AliasName)] = aws.ToString(in.TargetKeyId)
+	return &kms.CreateAliasOutput{}, nil
+}
+
+func (f *fakeKMS) DescribeKey(ctx context.Context, in *kms.DescribeKeyInput, _ ...func(*kms.Options)) (*kms.DescribeKeyOutput, error) {
+	f.describes++
+	arn, ok := f.aliases[aws.ToString(in.KeyId)]
+	if !ok {
+		return nil, &kmstypes.NotFoundException{Message: aws.String("alias not found")}
+	}
+	return &kms.DescribeKeyOutput{KeyMetadata: &kmstypes.KeyMetadata{Arn: aws.String(arn), KeyState: f.states[arn]}}, nil
+}
+
+func (f *fakeKMS) DeleteAlias(ctx context.Context, in *kms.DeleteAliasInput, _ ...func(*kms.Options)) (*kms.DeleteAliasOutput, error) {
+	if _, ok := f.aliases[aws.ToString(in.AliasName)]; !ok {
+		return nil, &kmstypes.NotFoundException{Message: aws.String("alias not found")}
+	}
+	delete(f.aliases, aws.ToString(in.AliasName))
+	return &kms.DeleteAliasOutput{}, nil
+}
+
+func (f *fakeKMS) ScheduleKeyDeletion(ctx context.Context, in *kms.ScheduleKeyDeletionInput, _ ...func(*kms.Options)) (*kms.ScheduleKeyDeletionOutput, error) {
+	arn := aws.ToString(in.KeyId)
+	if f.states[arn] == kmstypes.KeyStatePendingDeletion {
+		return nil, &kmstypes.KMSInvalidStateException{Message: aws.String("pending deletion")}
+	}
+	f.states[arn] = kmstypes.KeyStatePendingDeletion
+	f.deleted = append(f.deleted, arn)
+	return &kms.ScheduleKeyDeletionOutput{}, nil
+}
+
+// ListAliases returns one alias per page, marked by the next alias name, so
+// Sweep must follow the marker while it deletes aliases.
+func (f *fakeKMS) ListAliases(ctx context.Context, in *kms.ListAliasesInput, _ ...func(*kms.Options)) (*kms.ListAliasesOutput, error) {
+	names := make([]string, 0, len(f.aliases))
+	for name := range f.aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	i := sort.SearchStrings(names, aws.ToString(in.Marker))
+	if i >= len(names) {
+		return &kms.ListAliasesOutput{}, nil
+	}
+	out := &kms.ListAliasesOutput{Aliases: []kmstypes.AliasListEntry{{
+		AliasName:   aws.String(names[i]),
+		TargetKeyId: aws.String(f.aliases[names[i]]),
+	}}}
+	if i+1 < len(names) {
+		out.Truncated = true
+		out.NextMarker = aws.String(names[i+1])
+	}
+	return out, nil
+}
+
+func TestSessionKeysLifecycle(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeKMS()
+	keys := newSessionKeys(fake)
+
+	arn, err := keys.Create(ctx, "sess-1")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// A fresh process finds the key through the alias, once.
+	worker := newSessionKeys(fake)
+	for i := 0; i < 2; i++ {
+		enc, err := worker.ForKey(ctx, "sess-1/photo.jpg")
+		if err != nil || enc.KMSKeyID != arn {
+			t.Fatalf("ForKey = %+v, %v; want key %s", enc, err, arn)
+		}
+	}
+	if fake.describes != 1 {
+		t.Errorf("DescribeKey called %d times, want 1", fake.describes)
+	}
+
+	if err := keys.Retire(ctx, "sess-1", arn); err != nil {
+		t.Fatalf("Retire: %v", err)
+	}
+	if err := keys.Retire(ctx, "sess-1", arn); err != nil {
+		t.Errorf("second Retire: %v", err)
+	}
+	if len(fake.deleted) != 1 || fake.deleted[0] != arn {
+		t.Errorf("deleted = %v", fake.deleted)
+	}
+	if enc, err := keys.ForSession(ctx, "sess-1"); err != nil || enc.Encrypted() {
+		t.Errorf("ForSession after Retire = %+v, %v; want unencrypted", enc, err)
+	}
+}
+
+func TestSessionKeysUnencryptedSession(t *testing.T) {
+	fake := newFakeKMS()
+	keys := newSessionKeys(fake)
+	for i := 0; i < 2; i++ {
+		enc, err := keys.ForSession(context.Background(), "sess-2")
+		if err != nil || enc.Encrypted() {
+			t.Fatalf("ForSession = %+v, %v; want unencrypted", enc, err)
+		}
+	}
+	if fake.describes != 1 {
+		t.Errorf("DescribeKey called %d times, want 1", fake.describes)
+	}
+
+	var nilKeys *SessionKeys
+	if enc, err := nilKeys.ForSession(context.Background(), "sess-2"); err != nil || enc.Encrypted() {
+		t.Errorf("nil ForSession = %+v, %v", enc, err)
+	}
+}
+
+func TestSessionKeysDisabledKey(t *testing.T) {
+	fake := newFakeKMS()
+	arn, err := newSessionKeys(fake).Create(context.Background(), "sess-3")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	fake.states[arn] = kmstypes.KeyStateDisabled
+
+	if _, err := newSessionKeys(fake).ForSession(context.Background(), "sess-3"); err == nil {
+		t.Error("ForSession with a disabled key succeeded, want an error")
+	}
+}
+
+func TestSessionKeysAliasFailure(t *testing.T) {
+	fake := newFakeKMS()
+	fake.aliasErr = errors.New("limit exceeded")
+	if _, err := newSessionKeys(fake).Create(context.Background(), "sess-4"); err == nil {
+		t.Fatal("Create succeeded, want an error")
+	}
+	if len(fake.deleted) != 1 {
+		t.Errorf("orphaned key not scheduled for deletion: %v", fake.deleted)
+	}
+}
+
+func TestSessionKeysSweep(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeKMS()
+	keys := newSessionKeys(fake)
+	arns := map[string]string{}
+	for _, id := range []string{"live", "expired", "unknown"} {
+		arn, err := keys.Create(ctx, id)
+		if err != nil {
+			t.Fatalf("Create %s: %v", id, err)
+		}
+		arns[id] = arn
+	}
+	fake.aliases["alias/aws/s3"] = "arn:aws:kms:us-east-1:123456789012:key/aws-managed"
+
+	var checked []string
+	result, err := keys.Sweep(ctx, func(ctx context.Context, sessionID string) (bool, error) {
+		checked = append(checked, sessionID)
+		switch sessionID {
+		case "live":
+			return true, nil
+		case "unknown":
+			return false, errors.New("throttled")
+		}
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if want := (SweepResult{Keys: 3, Retired: 1, Failed: 1}); result != want {
+		t.Errorf("Sweep = %+v, want %+v", result, want)
+	}
+	if len(checked) != 3 {
+		t.Errorf("checked %v, want the three session aliases only", checked)
+	}
+	if len(fake.deleted) != 1 || fake.deleted[0] != arns["expired"] {
+		t.Errorf("deleted = %v, want only the expired session's key", fake.deleted)
+	}
+	if _, ok := fake.aliases[SessionKeyAlias("expired")]; ok {
+		t.Error("expired session's alias still exists")
+	}
+	if _, ok := fake.aliases[SessionKeyAlias("unknown")]; !ok {
+		t.Error("key of a session that could not be looked up was retired")
+	}
+}
+
+func TestEncryption(t *testing.T) {
+	var none Encryption
+	put := none.Put(&s3.PutObjectInput{})
+	if put.ServerSideEncryption != "" || put.SSEKMSKeyId != nil || none.UploadHeaders() != nil {
+		t.Errorf("zero Encryption set %+v, headers %v", put, none.UploadHeaders())
+	}
+
+	enc := Encryption{KMSKeyID: "arn:key"}
+	put = enc.Put(&s3.PutObjectInput{})
+	if put.ServerSideEncryption != s3types.ServerSideEncryptionAwsKms || aws.ToString(put.SSEKMSKeyId) != "arn:key" {
+		t.Errorf("Put = %s %v", put.ServerSideEncryption, put.SSEKMSKeyId)
+	}
+	h := enc.UploadHeaders()
+	if h["x-amz-server-side-encryption"] != "aws:kms" || h["x-amz-server-side-encryption-aws-kms-key-id"] != "arn:key" {
+		t.Errorf("UploadHeaders = %v", h)
+	}
+
+	head := &s3.HeadObjectOutput{ServerSideEncryption: s3types.ServerSideEncryptionAwsKms, SSEKMSKeyId: aws.String("arn:key")}
+	if got := ObjectEncryption(head); got != enc {
+		t.Errorf("ObjectEncryption = %+v", got)
+	}
+	if got := ObjectEncryption(&s3.HeadObjectOutput{ServerSideEncryption: s3types.ServerSideEncryptionAes256}); got.Encrypted() {
+		t.Errorf("ObjectEncryption of SSE-S3 object = %+v", got)
+	}
+}
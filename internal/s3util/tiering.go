@@ -0,0 +1,206 @@
+package s3util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/rs/zerolog/log"
+)
+
+// Storage tiering (DDR-115) moves a published group's originals to a colder
+// storage class, and restores archived objects before they are read again.
+
+// maxCopyObjectBytes is the largest object a single CopyObject can rewrite (5 GB).
+const maxCopyObjectBytes int64 = 5 * 1024 * 1024 * 1024
+
+// TieringPolicy configures storage tiering for session originals.
+// Build it with TieringPolicyFromEnv.
+type TieringPolicy struct {
+	// StorageClass is the class originals move to after publish, e.g.
+	// STANDARD_IA or GLACIER. Empty disables tiering.
+	StorageClass s3types.StorageClass
+	// RestoreTier is the retrieval tier used to restore archived objects.
+	RestoreTier s3types.Tier
+	// RestoreDays is how long a restored copy stays readable.
+	RestoreDays int32
+}
+
+// TieringPolicyFromEnv reads ORIGINALS_STORAGE_CLASS, ORIGINALS_RESTORE_TIER
+// (default Standard) and ORIGINALS_RESTORE_DAYS (default 1). An unset or
+// unknown storage class disables tiering, and so does GLACIER or
+// DEEP_ARCHIVE: a later group of the same session may publish or enhance an
+// original, and only downloads restore before reading. The restore settings
+// still apply to objects archived while those classes were allowed.
+func TieringPolicyFromEnv() TieringPolicy {
+	p := TieringPolicy{RestoreTier: s3types.TierStandard, RestoreDays: 1}
+	switch tier := s3types.Tier(os.Getenv("ORIGINALS_RESTORE_TIER")); tier {
+	case s3types.TierBulk, s3types.TierExpedited:
+		p.RestoreTier = tier
+	}
+	if v, err := strconv.Atoi(os.Getenv("ORIGINALS_RESTORE_DAYS")); err == nil && v > 0 {
+		p.RestoreDays = int32(v)
+	}
+
+	class := s3types.StorageClass(strings.ToUpper(os.Getenv("ORIGINALS_STORAGE_CLASS")))
+	switch class {
+	case "", s3types.StorageClassStandard:
+	case s3types.StorageClassStandardIa, s3types.StorageClassOnezoneIa, s3types.StorageClassIntelligentTiering,
+		s3types.StorageClassGlacierIr:
+		p.StorageClass = class
+	case s3types.StorageClassGlacier, s3types.StorageClassDeepArchive:
+		log.Warn().Str("storageClass", string(class)).Msg("ORIGINALS_STORAGE_CLASS needs a restore before reads, storage tiering disabled")
+	default:
+		log.Warn().Str("storageClass", string(class)).Msg("Unknown ORIGINALS_STORAGE_CLASS, storage tiering disabled")
+	}
+	return p
+}
+
+// Enabled reports whether originals should be moved to a colder class.
+func (p TieringPolicy) Enabled() bool {
+	return p.StorageClass != ""
+}
+
+// objectLister is the part of the S3 client ResolveOriginalKey uses.
+type objectLister interface {
+	ListObjectsV2(ctx context.Context, in *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+}
+
+// OriginalKey returns the key the uploaded original of a session media key
+// has when it shares the derived copy's file name, e.g. "sid/enhanced/a.jpg"
+// → "sid/a.jpg". Originals are stored directly under the session prefix.
+// A publish copy, "sid/publish/{jobId}/NN-a.jpg", loses its "NN-" position
+// prefix. The extension can differ from the original's (a RAW original's
+// enhanced copy is .jpg, DDR-119); ResolveOriginalKey looks the real one up.
+func OriginalKey(key string) string {
+	sessionID, rest, _ := strings.Cut(key, "/")
+	name := path.Base(key)
+	if strings.HasPrefix(rest, "publish/") {
+		if pos, tail, ok := strings.Cut(name, "-"); ok && len(pos) == 2 && isDigits(pos) {
+			name = tail
+		}
+	}
+	return sessionID + "/" + name
+}
+
+// ResolveOriginalKey returns the key of the uploaded original behind a
+// session media key. Keys already directly under the session prefix are
+// originals. For derived copies it lists the originals with the same base
+// name, preferring the one with the copy's extension, so an enhanced
+// "sid/enhanced/a.jpg" of "sid/a.dng" resolves to the .dng.
+func ResolveOriginalKey(ctx context.Context, client objectLister, bucket, key string) (string, error) {
+	if strings.Count(key, "/") == 1 {
+		return key, nil
+	}
+	candidate := OriginalKey(key)
+	stem := strings.TrimSuffix(candidate, path.Ext(candidate))
+	out, err := client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+		Bucket:    &bucket,
+		Prefix:    aws.String(stem + "."),
+		Delimiter: aws.String("/"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("ListObjectsV2: %w", err)
+	}
+	found := ""
+	for _, obj := range out.Contents {
+		k := aws.ToString(obj.Key)
+		if strings.TrimSuffix(k, path.Ext(k)) != stem {
+			continue
+		}
+		if k == candidate {
+			return k, nil
+		}
+		if found == "" {
+			found = k
+		}
+	}
+	if found == "" {
+		return "", fmt.Errorf("no original found for %s", key)
+	}
+	return found, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// TransitionObject rewrites an object in place with the given storage class.
+// It returns false without error when the object is already in that class or
+// is too large for a single CopyObject; such objects keep their class.
+func TransitionObject(ctx context.Context, client *s3.Client, bucket, key string, class s3types.StorageClass) (bool, error) {
+	head, err := client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: &bucket, Key: &key})
+	if err != nil {
+		return false, fmt.Errorf("HeadObject: %w", err)
+	}
+	if head.StorageClass == class {
+		return false, nil
+	}
+	if aws.ToInt64(head.ContentLength) > maxCopyObjectBytes {
+		log.Warn().Str("key", key).Int64("size", aws.ToInt64(head.ContentLength)).Msg("Object too large to re-tier with CopyObject, leaving in place")
+		return false, nil
+	}
+
+	// The copy keeps the object's session key (DDR-164).
+	_, err = client.CopyObject(ctx, ObjectEncryption(head).Copy(&s3.CopyObjectInput{
+		Bucket:            &bucket,
+		Key:               &key,
+		CopySource:        aws.String(bucket + "/" + key),
+		StorageClass:      class,
+		MetadataDirective: s3types.MetadataDirectiveCopy,
+		TaggingDirective:  s3types.TaggingDirectiveCopy,
+	}))
+	if err != nil {
+		return false, fmt.Errorf("CopyObject: %w", err)
+	}
+	return true, nil
+}
+
+// NeedsRestore reports whether the object described by head is archived and
+// has no readable restored copy yet. Objects in GLACIER_IR and the IA
+// classes are readable directly and never need a restore.
+func NeedsRestore(head *s3.HeadObjectOutput) bool {
+	switch head.StorageClass {
+	case s3types.StorageClassGlacier, s3types.StorageClassDeepArchive:
+	default:
+		return false
+	}
+	// Restore is absent before a restore is requested, `ongoing-request="true"`
+	// while it runs and `ongoing-request="false", expiry-date=...` once done.
+	restore := aws.ToString(head.Restore)
+	return !strings.Contains(restore, `ongoing-request="false"`)
+}
+
+// RestoreObject starts restoring an archived object for policy.RestoreDays.
+// A restore that is already in progress is not an error.
+func RestoreObject(ctx context.Context, client *s3.Client, bucket, key string, policy TieringPolicy) error {
+	_, err := client.RestoreObject(ctx, &s3.RestoreObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+		RestoreRequest: &s3types.RestoreRequest{
+			Days:                 aws.Int32(policy.RestoreDays),
+			GlacierJobParameters: &s3types.GlacierJobParameters{Tier: policy.RestoreTier},
+		},
+	})
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "RestoreAlreadyInProgress" {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("RestoreObject: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package s3util
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// fakeLister lists keys from memory by prefix, like a flat bucket with a
+// "/" delimiter.
+type fakeLister struct {
+	keys  []string
+	lists int
+}
+
+func (f *fakeLister) ListObjectsV2(ctx context.Context, in *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	f.lists++
+	prefix := aws.ToString(in.Prefix)
+	out := &s3.ListObjectsV2Output{}
+	for _, k := range f.keys {
+		if strings.HasPrefix(k, prefix) && !strings.Contains(k[len(prefix):], "/") {
+			out.Contents = append(out.Contents, s3types.Object{Key: aws.String(k)})
+		}
+	}
+	return out, nil
+}
+
+func TestTieringPolicyFromEnv(t *testing.T) {
+	tests := []struct {
+		class string
+		want  s3types.StorageClass
+	}{
+		{"", ""},
+		{"STANDARD", ""},
+		{"standard_ia", s3types.StorageClassStandardIa},
+		{"GLACIER_IR", s3types.StorageClassGlacierIr},
+		{"INTELLIGENT_TIERING", s3types.StorageClassIntelligentTiering},
+		{"GLACIER", ""},
+		{"DEEP_ARCHIVE", ""},
+		{"COLD", ""},
+	}
+	for _, tt := range tests {
+		t.Setenv("ORIGINALS_STORAGE_CLASS", tt.class)
+		if got := TieringPolicyFromEnv().StorageClass; got != tt.want {
+			t.Errorf("ORIGINALS_STORAGE_CLASS=%q: StorageClass = %q, want %q", tt.class, got, tt.want)
+		}
+	}
+}
+
+func TestOriginalKey(t *testing.T) {
+	tests := []struct{ key, want string }{
+		{"sid/a.jpg", "sid/a.jpg"},
+		{"sid/enhanced/a.jpg", "sid/a.jpg"},
+		{"sid/enhanced/v3/a.jpg", "sid/a.jpg"},
+		{"sid/publish/job-1/03-a.jpg", "sid/a.jpg"},
+		{"sid/publish/job-1/12-03-trip.mp4", "sid/03-trip.mp4"},
+		{"sid/publish/job-1/a.jpg", "sid/a.jpg"},
+	}
+	for _, tt := range tests {
+		if got := OriginalKey(tt.key); got != tt.want {
+			t.Errorf("OriginalKey(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestResolveOriginalKey(t *testing.T) {
+	lister := &fakeLister{keys: []string{
+		"sid/a.dng",
+		"sid/ab.jpg",
+		"sid/b.jpg",
+		"sid/b.heic",
+		"sid/clip.mov",
+		"sid/enhanced/a.jpg",
+	}}
+	tests := []struct{ key, want string }{
+		{"sid/a.dng", "sid/a.dng"},                        // already an original
+		{"sid/enhanced/a.jpg", "sid/a.dng"},               // RAW original, .jpg copy
+		{"sid/publish/job-1/02-a.jpg", "sid/a.dng"},       // publish copy of the RAW
+		{"sid/enhanced/v2/b.jpg", "sid/b.jpg"},            // same extension preferred
+		{"sid/publish/job-1/01-clip.mp4", "sid/clip.mov"}, // transcoded video
+	}
+	for _, tt := range tests {
+		got, err := ResolveOriginalKey(context.Background(), lister, "bucket", tt.key)
+		if err != nil {
+			t.Errorf("ResolveOriginalKey(%q): %v", tt.key, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ResolveOriginalKey(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+
+	if _, err := ResolveOriginalKey(context.Background(), lister, "bucket", "sid/enhanced/missing.jpg"); err == nil {
+		t.Error("ResolveOriginalKey of a key with no original: want error")
+	}
+}
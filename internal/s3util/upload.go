@@ -15,7 +15,8 @@ import (
 // UploadCompressedVideo uploads a locally compressed video file to S3 under a
 // "compressed/" prefix, changing the extension to .webm.
 // Replaces uploadCompressedVideo in triage-lambda and selection-lambda.
-func UploadCompressedVideo(ctx context.Context, client *s3.Client, bucket, sessionID, originalKey, compressedPath string) (string, error) {
+// enc is the session's encryption (DDR-164).
+func UploadCompressedVideo(ctx context.Context, client *s3.Client, bucket, sessionID, originalKey, compressedPath string, enc Encryption) (string, error) {
 	filename := filepath.Base(originalKey)
 	baseName := strings.TrimSuffix(filename, filepath.Ext(filename))
 	compressedFilename := baseName + ".webm"
@@ -34,13 +35,13 @@ func UploadCompressedVideo(ctx context.Context, client *s3.Client, bucket, sessi
 	defer compressedFile.Close()
 
 	contentType := "video/webm"
-	_, err = client.PutObject(ctx, &s3.PutObjectInput{
+	_, err = client.PutObject(ctx, enc.Put(&s3.PutObjectInput{
 		Bucket:      &bucket,
 		Key:         &compressedKey,
 		Body:        compressedFile,
 		ContentType: &contentType,
 		Tagging:     ProjectTagging(),
-	})
+	}))
 	if err != nil {
 		return "", fmt.Errorf("failed to upload compressed video to S3: %w", err)
 	}
@@ -65,3 +66,27 @@ func GeneratePresignedURL(ctx context.Context, presignClient *s3.PresignClient,
 	}
 	return result.URL, nil
 }
+
+// BundleURLExpiry is how long a presigned download bundle URL stays valid.
+// The download results endpoint re-signs on every poll, so a client that
+// resumes after expiry fetches the results again for a fresh URL (DDR-099).
+const BundleURLExpiry = 1 * time.Hour
+
+// PresignBundleURL creates a pre-signed GET URL for a download bundle ZIP that
+// saves as filename. S3 serves ranged GETs on these URLs with Accept-Ranges,
+// Content-Length, and the object's ETag, so a download manager can resume
+// with "Range: bytes=N-" and "If-Range: <etag>" (DDR-099).
+func PresignBundleURL(ctx context.Context, presignClient *s3.PresignClient, bucket, key, filename string) (string, error) {
+	disposition := fmt.Sprintf(`attachment; filename="%s"`, filename)
+	contentType := "application/zip"
+	result, err := presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket:                     &bucket,
+		Key:                        &key,
+		ResponseContentDisposition: &disposition,
+		ResponseContentType:        &contentType,
+	}, s3.WithPresignExpires(BundleURLExpiry))
+	if err != nil {
+		return "", fmt.Errorf("presign bundle GetObject: %w", err)
+	}
+	return result.URL, nil
+}
@@ -0,0 +1,64 @@
+package sfnevents
+
+import "github.com/fpang/ai-social-media-helper/internal/sfnsim"
+
+// Contracts maps each Step Functions-invoked Lambda (by function name, as in
+// the deploy repo's CDK constructs) to the payload types its handler decodes
+// and returns. sfnsim.Check validates state machine definitions against it.
+var Contracts = map[string]sfnsim.Contract{
+	"AiSocialMediaTriageProcessor": {
+		Event: TriageEvent{},
+		Results: map[string]interface{}{
+			"triage-init-session":     TriageInitResult{},
+			"triage-prepare":          TriageInitResult{},
+			"triage-check-processing": TriageCheckProcessingResult{},
+			"triage-run":              TriageRunResult{}, // null unless economy_mode
+		},
+	},
+	"AiSocialMediaThumbnailProcessor": {
+		Event:  ThumbnailEvent{},
+		Result: ThumbnailResult{},
+	},
+	"AiSocialMediaSelectionProcessor": {
+		Event:  SelectionEvent{},
+		Result: SelectionResult{},
+	},
+	"AiSocialMediaEnhancementProcessor": {
+		Event:  EnhanceEvent{},
+		Result: EnhanceResult{},
+	},
+	"AiSocialMediaVideoProcessor": {
+		Event:  VideoEvent{},
+		Result: VideoResult{},
+	},
+	"AiSocialMediaPublishProcessor": {
+		Event: PublishEvent{},
+		Results: map[string]interface{}{
+			"publish-prepare":           PublishPrepareResult{},
+			"publish-create-containers": PublishCreateContainersResult{},
+			"publish-check-video":       PublishCheckVideoResult{},
+			"publish-check-approval":    PublishCheckApprovalResult{},
+			"publish-finalize":          nil,
+		},
+	},
+	"AiSocialMediaGeminiBatchPoll": {
+		Event:  PollInput{},
+		Result: PollOutput{},
+	},
+	"AiSocialMediaFBPrepProcessor": {
+		Event:  FBPrepEvent{},
+		Result: FBPrepOutput{},
+	},
+	"AiSocialMediaFBPrepGcsUpload": {
+		Event:  VideoToUpload{},
+		Result: UploadOutput{},
+	},
+	"AiSocialMediaFBPrepSubmitBatch": {
+		Event:  SubmitBatchEvent{},
+		Result: SubmitOutput{},
+	},
+	"AiSocialMediaFBPrepCollectBatch": {
+		Event:  CollectBatchEvent{},
+		Result: CollectOutput{},
+	},
+}
@@ -0,0 +1,136 @@
+package sfnevents
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fpang/ai-social-media-helper/internal/sfnsim"
+)
+
+// TestStateMachineContracts checks every checked-in definition against the
+// Lambda payload types. A failure here means a definition references a field
+// a handler does not send or read — the kind of drift that otherwise shows up
+// as a Choice branch that silently never matches (DDR-094).
+func TestStateMachineContracts(t *testing.T) {
+	files, err := filepath.Glob("../../statemachines/*.asl.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no state machine definitions found in statemachines/")
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			def, err := sfnsim.Parse(data)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			for _, v := range sfnsim.Check(def, Contracts) {
+				t.Error(v)
+			}
+		})
+	}
+}
+
+// TestDebugArtifactsReachWorkers checks that every Task that can keep debug
+// artifacts is handed the execution's debugArtifacts flag (DDR-106); without
+// it the handlers always see false and the feature only works from the CLI.
+func TestDebugArtifactsReachWorkers(t *testing.T) {
+	tests := []struct {
+		file, state string
+		field       func(*sfnsim.State) json.RawMessage
+	}{
+		{"triage.asl.json", "RunTriage", taskPayload},
+		{"selection.asl.json", "RunSelection", taskPayload},
+		{"enhancement.asl.json", "PhotoMap", func(st *sfnsim.State) json.RawMessage { return st.ItemSelector }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file+"/"+tt.state, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("../../statemachines", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			def, err := sfnsim.Parse(data)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			st := findState(def, tt.state)
+			if st == nil {
+				t.Fatalf("state %s not found", tt.state)
+			}
+			var payload map[string]interface{}
+			if err := json.Unmarshal(tt.field(st), &payload); err != nil {
+				t.Fatalf("payload: %v", err)
+			}
+			if got := payload["debugArtifacts.$"]; got != "$.debugArtifacts" {
+				t.Errorf("debugArtifacts.$ = %v, want $.debugArtifacts", got)
+			}
+		})
+	}
+}
+
+// TestPublishDefaultsLocationID checks that a publish execution whose input
+// predates DDR-138, such as a scheduled post saved before it, still runs and
+// publishes untagged instead of failing on the missing $.locationId.
+func TestPublishDefaultsLocationID(t *testing.T) {
+	data, err := os.ReadFile("../../statemachines/publish.asl.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	def, err := sfnsim.Parse(data)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	var prepared PublishEvent
+	inv := sfnsim.InvokerFunc(func(ctx context.Context, target string, payload json.RawMessage) (json.RawMessage, error) {
+		if err := json.Unmarshal(payload, &prepared); err != nil {
+			t.Fatalf("payload: %v", err)
+		}
+		return json.Marshal(PublishPrepareResult{SessionID: prepared.SessionID, JobID: prepared.JobID})
+	})
+	input := `{"sessionId":"s1","jobId":"pub-1","groupId":"g1","keys":["s1/a.jpg"],"caption":"hi",
+		"userTags":[],"collaborators":[],"dryRun":false,"platform":"instagram","targets":[],
+		"altText":[],"order":[],"requireApproval":false,"watermark":false}`
+	if _, err := sfnsim.New(inv).Run(context.Background(), "PublishPipeline", def, json.RawMessage(input)); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if prepared.Type != "publish-prepare" || prepared.LocationID != "" {
+		t.Errorf("prepare event = %+v, want publish-prepare with no location", prepared)
+	}
+}
+
+func taskPayload(st *sfnsim.State) json.RawMessage {
+	var params struct {
+		Payload json.RawMessage `json:"Payload"`
+	}
+	json.Unmarshal(st.Parameters, &params)
+	return params.Payload
+}
+
+// findState looks a state up by name in def and its Map and Parallel
+// sub-machines.
+func findState(def *sfnsim.Definition, name string) *sfnsim.State {
+	if def == nil {
+		return nil
+	}
+	if st, ok := def.States[name]; ok {
+		return st
+	}
+	for _, st := range def.States {
+		for _, sub := range append(st.Branches, st.Processor()) {
+			if found := findState(sub, name); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
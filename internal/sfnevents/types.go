@@ -0,0 +1,432 @@
+// Package sfnevents defines the payloads exchanged between the Step Functions
+// state machines and the Lambdas they invoke (DDR-094).
+//
+// The json tags here are the contract with the ASL definitions: a field a
+// definition references by path (e.g. "$.prep_result.Payload.batches_meta")
+// must exist here with the same name. Contracts maps each Lambda to its event
+// and result types so the checked-in definitions under statemachines/ can be
+// validated against them. The Lambda packages alias these types rather than
+// declaring their own.
+package sfnevents
+
+import (
+	"encoding/json"
+
+	"github.com/fpang/ai-social-media-helper/internal/ai"
+	"github.com/fpang/ai-social-media-helper/internal/instagram"
+	"github.com/fpang/ai-social-media-helper/internal/jobs"
+)
+
+// --- Triage pipeline (media-triage) ---
+
+// TriageEvent is the input from Step Functions.
+type TriageEvent struct {
+	Type              string   `json:"type"`
+	SessionID         string   `json:"sessionId"`
+	JobID             string   `json:"jobId"`
+	NoCache           bool     `json:"noCache,omitempty"`   // DDR-166
+	Keyframes         int      `json:"keyframes,omitempty"` // DDR-186: frames per video, 0 = whole clips
+	EconomyMode       bool     `json:"economy_mode,omitempty"`
+	ExpectedFileCount int      `json:"expectedFileCount,omitempty"`
+	VideoFileNames    []string `json:"videoFileNames,omitempty"`
+	DebugArtifacts    bool     `json:"debugArtifacts,omitempty"` // DDR-106
+
+	ai.ModelConfig // DDR-170: model, thinking, temperature, topP
+}
+
+// TriageRunResult is returned by triage-run when economy_mode is true.
+type TriageRunResult struct {
+	BatchJobID string `json:"batch_job_id,omitempty"`
+}
+
+// TriageInitResult is returned by the triage-init-session handler.
+type TriageInitResult struct {
+	SessionID string `json:"sessionId"`
+	JobID     string `json:"jobId"`
+	Model     string `json:"model"`
+}
+
+// TriageCheckProcessingResult is returned by the triage-check-processing handler.
+type TriageCheckProcessingResult struct {
+	SessionID      string `json:"sessionId"`
+	JobID          string `json:"jobId"`
+	Model          string `json:"model"`
+	AllProcessed   bool   `json:"allProcessed"`
+	ProcessedCount int    `json:"processedCount"`
+	ExpectedCount  int    `json:"expectedCount"`
+	ErrorCount     int    `json:"errorCount"`
+}
+
+// --- Selection pipeline (thumbnail-worker, selection-worker) ---
+
+// ThumbnailEvent is the input payload from Step Functions.
+// The Map state iterates over media keys and sends one event per file.
+type ThumbnailEvent struct {
+	SessionID string `json:"sessionId"`
+	Key       string `json:"key"`
+	Bucket    string `json:"bucket,omitempty"` // Optional override; defaults to MEDIA_BUCKET_NAME.
+}
+
+// ThumbnailResult is the output returned to Step Functions.
+// The Map state collects all results for the next state (Selection Lambda).
+type ThumbnailResult struct {
+	ThumbnailKey string `json:"thumbnailKey"`
+	OriginalKey  string `json:"originalKey"`
+	Success      bool   `json:"success"`
+	Error        string `json:"error,omitempty"`
+}
+
+// SelectionEvent is the input payload from Step Functions.
+// It is produced by the state machine after the thumbnail Map state completes.
+type SelectionEvent struct {
+	SessionID      string           `json:"sessionId"`
+	JobID          string           `json:"jobId"`
+	TripContext    string           `json:"tripContext"`
+	EconomyMode    bool             `json:"economy_mode,omitempty"`
+	MediaKeys      []string         `json:"mediaKeys"`
+	ThumbnailKeys  []ThumbnailEntry `json:"thumbnailKeys"`
+	Bucket         string           `json:"bucket,omitempty"`
+	DebugArtifacts bool             `json:"debugArtifacts,omitempty"` // DDR-106
+
+	// Type and Feedback are set only when the API invokes the Lambda
+	// directly with a "selection-feedback" event (DDR-177); Step Functions
+	// sends neither.
+	Type     string `json:"type,omitempty"`
+	Feedback string `json:"feedback,omitempty"`
+
+	ai.ModelConfig // DDR-170: model, thinking, temperature, topP
+}
+
+// ThumbnailEntry pairs an original media key with its generated thumbnail key.
+type ThumbnailEntry struct {
+	ThumbnailKey string `json:"thumbnailKey"`
+	OriginalKey  string `json:"originalKey"`
+}
+
+// SelectionResult is the output returned to Step Functions.
+type SelectionResult struct {
+	JobID            string `json:"jobId"`
+	SelectedCount    int    `json:"selectedCount"`
+	ExcludedCount    int    `json:"excludedCount"`
+	SceneGroupCount  int    `json:"sceneGroupCount"`
+	UnprocessedCount int    `json:"unprocessedCount,omitempty"` // DDR-134
+	BatchJobID       string `json:"batch_job_id,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+// --- Enhancement pipeline (enhance-worker, video-worker) ---
+
+// EnhanceEvent is the input payload from Step Functions or async invocation.
+// For Step Functions (initial enhancement): type is empty, key + itemIndex are set.
+// For async feedback (DDR-053): type is "enhancement-feedback", key + feedback are set.
+type EnhanceEvent struct {
+	Type      string `json:"type,omitempty"`
+	SessionID string `json:"sessionId"`
+	JobID     string `json:"jobId"`
+	Key       string `json:"key"`
+	ItemIndex int    `json:"itemIndex"`
+	Bucket    string `json:"bucket,omitempty"`
+	Feedback  string `json:"feedback,omitempty"` // DDR-053: enhancement feedback text
+
+	// DDR-182: set when the feedback round is one photo of a batch.
+	BatchID    string `json:"batchId,omitempty"`
+	BatchIndex int    `json:"batchIndex,omitempty"`
+
+	Priority       jobs.Priority `json:"priority,omitempty"`       // DDR-096: set on API dispatches only
+	DebugArtifacts bool          `json:"debugArtifacts,omitempty"` // DDR-106
+	SubjectPreset  string        `json:"subjectPreset,omitempty"`  // DDR-180: ai.SubjectPreset
+	UpscaleBelow   int           `json:"upscaleBelow,omitempty"`   // DDR-184: long-edge threshold; 0 = off
+
+	ai.ModelConfig // DDR-170: model, thinking, temperature, topP
+}
+
+// EnhanceResult is the output returned to Step Functions.
+type EnhanceResult struct {
+	OriginalKey      string `json:"originalKey"`
+	EnhancedKey      string `json:"enhancedKey"`
+	EnhancedThumbKey string `json:"enhancedThumbKey"`
+	Phase            string `json:"phase"`
+	Phase1Text       string `json:"phase1Text,omitempty"`
+	ImagenEdits      int    `json:"imagenEdits"`
+	Error            string `json:"error,omitempty"`
+}
+
+// VideoEvent is the input payload from Step Functions.
+// The Map state iterates over selected video keys and sends one event per video.
+type VideoEvent struct {
+	SessionID string `json:"sessionId"`
+	JobID     string `json:"jobId"`
+	Key       string `json:"key"`
+	ItemIndex int    `json:"itemIndex"`
+	Bucket    string `json:"bucket,omitempty"`
+}
+
+// VideoResult is the output returned to Step Functions.
+type VideoResult struct {
+	OriginalKey string `json:"originalKey"`
+	EnhancedKey string `json:"enhancedKey"`
+	Phase       string `json:"phase"`
+	Summary     string `json:"summary,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// --- Publish pipeline (publish-worker) ---
+
+// Publish platforms (DDR-147). An empty Platform means Instagram, as it did
+// before Facebook Pages were supported. Mastodon covers any server with the
+// Mastodon client API, Pixelfed included (DDR-151).
+const (
+	PlatformInstagram = "instagram"
+	PlatformFacebook  = "facebook"
+	PlatformMastodon  = "mastodon"
+)
+
+// PublishEvent is the input for every publish step; Type selects the step.
+type PublishEvent struct {
+	Type              string                `json:"type"`
+	SessionID         string                `json:"sessionId"`
+	JobID             string                `json:"jobId"`
+	GroupID           string                `json:"groupId,omitempty"`
+	Keys              []string              `json:"keys,omitempty"`
+	Caption           string                `json:"caption,omitempty"`
+	LocationID        string                `json:"locationId,omitempty"`    // DDR-138
+	UserTags          [][]instagram.UserTag `json:"userTags,omitempty"`      // DDR-141: per item, aligned with Keys
+	Collaborators     []string              `json:"collaborators,omitempty"` // DDR-141
+	ContainerIDs      []string              `json:"containerIDs,omitempty"`
+	VideoContainerIDs []string              `json:"videoContainerIDs,omitempty"`
+	IsCarousel        bool                  `json:"isCarousel,omitempty"`
+	RequireApproval   bool                  `json:"requireApproval,omitempty"` // DDR-121
+	Watermark         bool                  `json:"watermark,omitempty"`       // DDR-133
+	DryRun            bool                  `json:"dryRun,omitempty"`          // DDR-139
+	Platform          string                `json:"platform,omitempty"`        // DDR-147
+	AltText           []string              `json:"altText,omitempty"`         // DDR-151: per item, aligned with Keys
+	Targets           []PublishTarget       `json:"targets,omitempty"`         // DDR-153
+	Order             []int                 `json:"order,omitempty"`           // DDR-179: carousel order as indices into Keys
+}
+
+// PublishTarget is one platform a job cross-posts to (DDR-153), with its
+// caption variant and, once created, its containers. A target with Error
+// set has failed and is skipped by the later steps, so the other platforms
+// still publish. Jobs started before cross-posting have no targets; their
+// Platform, Caption and container IDs form the only one. FirstComment,
+// Instagram only, is posted as a comment once the post is live (DDR-163).
+type PublishTarget struct {
+	Platform          string   `json:"platform"`
+	Caption           string   `json:"caption"`
+	FirstComment      string   `json:"firstComment,omitempty"`
+	ContainerIDs      []string `json:"containerIDs,omitempty"`
+	VideoContainerIDs []string `json:"videoContainerIDs,omitempty"`
+	Error             string   `json:"error,omitempty"`
+}
+
+// PublishPrepareResult is returned by publish-prepare (DDR-129). Keys are
+// the items to publish, with converted copies substituted for originals
+// Instagram would reject. Ready is false when an item could not be fixed;
+// the worker has then recorded the per-item errors on the job.
+type PublishPrepareResult struct {
+	SessionID       string                `json:"sessionId"`
+	JobID           string                `json:"jobId"`
+	GroupID         string                `json:"groupId"`
+	Keys            []string              `json:"keys"`
+	Caption         string                `json:"caption"`
+	LocationID      string                `json:"locationId"`
+	UserTags        [][]instagram.UserTag `json:"userTags"`
+	Collaborators   []string              `json:"collaborators"`
+	RequireApproval bool                  `json:"requireApproval"`
+	DryRun          bool                  `json:"dryRun"`
+	Platform        string                `json:"platform"`
+	AltText         []string              `json:"altText"`
+	Targets         []PublishTarget       `json:"targets"`
+	Ready           bool                  `json:"ready"`
+}
+
+// PublishCreateContainersResult is returned by publish-create-containers.
+type PublishCreateContainersResult struct {
+	SessionID         string          `json:"sessionId"`
+	JobID             string          `json:"jobId"`
+	GroupID           string          `json:"groupId"`
+	Keys              []string        `json:"keys"`
+	Caption           string          `json:"caption"`
+	LocationID        string          `json:"locationId"`
+	Collaborators     []string        `json:"collaborators"`
+	ContainerIDs      []string        `json:"containerIDs"`
+	VideoContainerIDs []string        `json:"videoContainerIDs"`
+	HasVideos         bool            `json:"hasVideos"`
+	IsCarousel        bool            `json:"isCarousel"`
+	RequireApproval   bool            `json:"requireApproval"`
+	DryRun            bool            `json:"dryRun"`
+	Platform          string          `json:"platform"`
+	Targets           []PublishTarget `json:"targets"`
+}
+
+// PublishCheckVideoResult is returned by publish-check-video.
+type PublishCheckVideoResult struct {
+	SessionID         string          `json:"sessionId"`
+	JobID             string          `json:"jobId"`
+	GroupID           string          `json:"groupId"`
+	Keys              []string        `json:"keys"`
+	Caption           string          `json:"caption"`
+	LocationID        string          `json:"locationId"`
+	Collaborators     []string        `json:"collaborators"`
+	ContainerIDs      []string        `json:"containerIDs"`
+	VideoContainerIDs []string        `json:"videoContainerIDs"`
+	AllFinished       bool            `json:"allFinished"`
+	IsCarousel        bool            `json:"isCarousel"`
+	RequireApproval   bool            `json:"requireApproval"`
+	DryRun            bool            `json:"dryRun"`
+	Platform          string          `json:"platform"`
+	Targets           []PublishTarget `json:"targets"`
+}
+
+// PublishCheckApprovalResult is returned by publish-check-approval (DDR-121).
+// Approval is "pending", "approved", or "closed" when the job was rejected or
+// the approval window expired.
+type PublishCheckApprovalResult struct {
+	SessionID     string          `json:"sessionId"`
+	JobID         string          `json:"jobId"`
+	GroupID       string          `json:"groupId"`
+	Keys          []string        `json:"keys"`
+	Caption       string          `json:"caption"`
+	LocationID    string          `json:"locationId"`
+	Collaborators []string        `json:"collaborators"`
+	ContainerIDs  []string        `json:"containerIDs"`
+	IsCarousel    bool            `json:"isCarousel"`
+	DryRun        bool            `json:"dryRun"`
+	Platform      string          `json:"platform"`
+	Targets       []PublishTarget `json:"targets"`
+	Approval      string          `json:"approval"`
+	// ApprovalWaitSeconds is how long to wait before the next check while
+	// the approval is pending (store.PublishApproval.PollInterval).
+	ApprovalWaitSeconds int `json:"approvalWaitSeconds"`
+}
+
+// --- Gemini Batch poll (gemini-batch-poll) ---
+
+// PollInput is the input for the batch poll Lambda.
+type PollInput struct {
+	BatchJobID string `json:"batch_job_id"`
+}
+
+// PollOutput is the output from the batch poll Lambda.
+type PollOutput struct {
+	State   string          `json:"state"`
+	Results json.RawMessage `json:"results,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// --- FB Prep pipeline (fb-prep-lambda, fb-prep-gcs-upload, submit, collect) ---
+
+// FBPrepEvent lists the keys the FB Prep Lambda reads from its map input
+// (the handler decodes a map so it can also accept FBPrepInput directly).
+// Type selects fb-prep-feedback or fb-prep-mark-error; empty means run.
+type FBPrepEvent struct {
+	Type         string     `json:"type,omitempty"`
+	SessionID    string     `json:"sessionId"`
+	JobID        string     `json:"jobId"`
+	MediaKeys    []string   `json:"mediaKeys,omitempty"`
+	EconomyMode  bool       `json:"economyMode,omitempty"`
+	Feedback     string     `json:"feedback,omitempty"`
+	ItemIndex    int        `json:"itemIndex,omitempty"`
+	CollectError *StepError `json:"collectError,omitempty"`
+	BatchError   *StepError `json:"batchError,omitempty"`
+
+	Priority jobs.Priority `json:"priority,omitempty"` // DDR-096: set on API dispatches only
+}
+
+// StepError is the {Error, Cause} object a Catch writes at its ResultPath.
+type StepError struct {
+	Error string `json:"Error"`
+	Cause string `json:"Cause"`
+}
+
+// FBPrepMediaItem represents a single media item in the input.
+type FBPrepMediaItem struct {
+	S3Key     string `json:"s3_key"`
+	MediaType string `json:"media_type"` // "image" or "video"
+	GPS       *GPS   `json:"gps,omitempty"`
+	DateTaken string `json:"date_taken,omitempty"`
+	Filename  string `json:"filename"`
+}
+
+// GPS holds latitude and longitude.
+type GPS struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// FBPrepOutput is the Lambda output.
+type FBPrepOutput struct {
+	SessionID      string            `json:"session_id"`
+	Status         string            `json:"status"` // "complete" or "pending"
+	BatchJobID     string            `json:"batch_job_id,omitempty"`
+	BatchJobIDs    []string          `json:"batch_job_ids,omitempty"` // When multiple batches (>10 videos)
+	JobID          string            `json:"job_id,omitempty"`
+	VideosToUpload []VideoToUpload   `json:"videos_to_upload,omitempty"` // For Map: one Lambda per video
+	BatchesMeta    []FBPrepBatchMeta `json:"batches_meta,omitempty"`
+	LocationTags   map[string]string `json:"location_tags,omitempty"`
+	// Upload-video response:
+	GsURI            string `json:"gs_uri,omitempty"`
+	BatchIndex       int    `json:"batch_index,omitempty"`
+	ItemIndexInBatch int    `json:"item_index_in_batch,omitempty"`
+	S3Key            string `json:"s3_key,omitempty"`
+}
+
+// VideoToUpload is one video to upload to GCS (one Lambda invocation).
+type VideoToUpload struct {
+	S3Key            string `json:"s3_key"`
+	UseKey           string `json:"use_key"` // S3 key of downscaled video
+	JobID            string `json:"job_id"`
+	BatchIndex       int    `json:"batch_index"`
+	ItemIndexInBatch int    `json:"item_index_in_batch"`
+}
+
+// FBPrepBatchMeta holds batch metadata for submit step.
+type FBPrepBatchMeta struct {
+	BatchIndex  int               `json:"batch_index"`
+	MediaItems  []FBPrepMediaItem `json:"media_items"`
+	MetadataCtx string            `json:"metadata_ctx"`
+	BaseIndex   int               `json:"base_index"`
+	S3Keys      []string          `json:"s3_keys"`
+}
+
+// UploadOutput is the Lambda response for the GCS upload task. Its input is a
+// VideoToUpload.
+type UploadOutput struct {
+	GsURI            string `json:"gs_uri"`
+	BatchIndex       int    `json:"batch_index"`
+	ItemIndexInBatch int    `json:"item_index_in_batch"`
+	S3Key            string `json:"s3_key"`
+}
+
+// SubmitBatchEvent lists the keys the submit-batch Lambda reads.
+// GCSUploadResults holds the raw MapUploadVideos results (lambda:invoke
+// envelopes whose Payload is an UploadOutput).
+type SubmitBatchEvent struct {
+	SessionID        string            `json:"sessionId"`
+	JobID            string            `json:"jobId"`
+	BatchesMeta      []FBPrepBatchMeta `json:"batchesMeta"`
+	LocationTags     map[string]string `json:"locationTags,omitempty"`
+	GCSUploadResults json.RawMessage   `json:"gcsUploadResults,omitempty"`
+}
+
+// SubmitOutput is the Lambda response. JSON keys match Step Functions references.
+type SubmitOutput struct {
+	SessionID   string   `json:"session_id"`
+	Status      string   `json:"status"`
+	BatchJobID  string   `json:"batch_job_id,omitempty"`  // single batch
+	BatchJobIDs []string `json:"batch_job_ids,omitempty"` // multiple batches
+}
+
+// CollectBatchEvent lists the keys the collect-batch Lambda reads.
+type CollectBatchEvent struct {
+	SessionID   string   `json:"sessionId"`
+	JobID       string   `json:"jobId"`
+	BatchJobID  string   `json:"batchJobId,omitempty"`
+	BatchJobIDs []string `json:"batchJobIds,omitempty"`
+}
+
+// CollectOutput is the Lambda response.
+type CollectOutput struct {
+	SessionID string `json:"session_id"`
+	Status    string `json:"status"`
+}
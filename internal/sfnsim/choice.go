@@ -0,0 +1,248 @@
+package sfnsim
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// ChoiceRule is a Choice state rule: either a comparison against Variable, or
+// a boolean combination (And, Or, Not) of nested rules. Only top-level rules
+// carry Next.
+type ChoiceRule struct {
+	Variable string
+	Next     string
+	And      []ChoiceRule
+	Or       []ChoiceRule
+	Not      *ChoiceRule
+
+	// Operator is the comparison operator name (e.g. "NumericLessThanPath")
+	// and Operand its raw JSON value.
+	Operator string
+	Operand  json.RawMessage
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Each rule carries exactly one
+// comparison operator, so operators are collected generically rather than as
+// one struct field per operator.
+func (r *ChoiceRule) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for k, v := range fields {
+		var err error
+		switch k {
+		case "Variable":
+			err = json.Unmarshal(v, &r.Variable)
+		case "Next":
+			err = json.Unmarshal(v, &r.Next)
+		case "And":
+			err = json.Unmarshal(v, &r.And)
+		case "Or":
+			err = json.Unmarshal(v, &r.Or)
+		case "Not":
+			r.Not = &ChoiceRule{}
+			err = json.Unmarshal(v, r.Not)
+		case "Comment":
+		default:
+			if r.Operator != "" {
+				return fmt.Errorf("choice rule has multiple operators: %s, %s", r.Operator, k)
+			}
+			if _, ok := choiceOperators[strings.TrimSuffix(k, "Path")]; !ok {
+				return fmt.Errorf("unknown choice operator %q", k)
+			}
+			r.Operator, r.Operand = k, v
+		}
+		if err != nil {
+			return fmt.Errorf("choice rule %s: %w", k, err)
+		}
+	}
+
+	combinators := 0
+	for _, set := range []bool{r.And != nil, r.Or != nil, r.Not != nil, r.Operator != ""} {
+		if set {
+			combinators++
+		}
+	}
+	if combinators != 1 {
+		return fmt.Errorf("choice rule must have exactly one of And, Or, Not, or a comparison")
+	}
+	if r.Operator != "" && r.Variable == "" {
+		return fmt.Errorf("choice rule %s has no Variable", r.Operator)
+	}
+	return nil
+}
+
+// choiceOperators lists supported comparison operators (without the "Path"
+// suffix, which every comparison except the type tests also accepts).
+var choiceOperators = map[string]bool{
+	"StringEquals":               true,
+	"StringLessThan":             true,
+	"StringGreaterThan":          true,
+	"StringLessThanEquals":       true,
+	"StringGreaterThanEquals":    true,
+	"StringMatches":              true,
+	"NumericEquals":              true,
+	"NumericLessThan":            true,
+	"NumericGreaterThan":         true,
+	"NumericLessThanEquals":      true,
+	"NumericGreaterThanEquals":   true,
+	"BooleanEquals":              true,
+	"TimestampEquals":            true,
+	"TimestampLessThan":          true,
+	"TimestampGreaterThan":       true,
+	"TimestampLessThanEquals":    true,
+	"TimestampGreaterThanEquals": true,
+	"IsPresent":                  true,
+	"IsNull":                     true,
+	"IsString":                   true,
+	"IsNumeric":                  true,
+	"IsBoolean":                  true,
+	"IsTimestamp":                true,
+}
+
+// evaluate reports whether the rule matches the given state input.
+func (r *ChoiceRule) evaluate(data, ctxObj interface{}) (bool, error) {
+	switch {
+	case r.And != nil:
+		for i := range r.And {
+			ok, err := r.And[i].evaluate(data, ctxObj)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	case r.Or != nil:
+		for i := range r.Or {
+			ok, err := r.Or[i].evaluate(data, ctxObj)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	case r.Not != nil:
+		ok, err := r.Not.evaluate(data, ctxObj)
+		return !ok, err
+	}
+
+	var operand interface{}
+	if err := json.Unmarshal(r.Operand, &operand); err != nil {
+		return false, runtimeError("%s: invalid operand: %v", r.Operator, err)
+	}
+
+	// Type tests compare the operand (a boolean) against a property of the
+	// variable and tolerate a missing variable where IsPresent is concerned.
+	if r.Operator == "IsPresent" {
+		return pathExists(r.Variable, data, ctxObj) == (operand == true), nil
+	}
+
+	val, err := resolvePath(r.Variable, data, ctxObj)
+	if err != nil {
+		return false, err
+	}
+
+	op := r.Operator
+	if strings.HasSuffix(op, "Path") {
+		ref, ok := operand.(string)
+		if !ok {
+			return false, runtimeError("%s: operand must be a path", op)
+		}
+		if operand, err = resolvePath(ref, data, ctxObj); err != nil {
+			return false, err
+		}
+		op = strings.TrimSuffix(op, "Path")
+	}
+
+	switch op {
+	case "IsNull":
+		return (val == nil) == (operand == true), nil
+	case "IsString":
+		_, ok := val.(string)
+		return ok == (operand == true), nil
+	case "IsNumeric":
+		_, ok := val.(float64)
+		return ok == (operand == true), nil
+	case "IsBoolean":
+		_, ok := val.(bool)
+		return ok == (operand == true), nil
+	case "IsTimestamp":
+		_, ok := asTimestamp(val)
+		return ok == (operand == true), nil
+	case "BooleanEquals":
+		b, ok := val.(bool)
+		return ok && b == operand, nil
+	case "StringMatches":
+		s, ok1 := val.(string)
+		pattern, ok2 := operand.(string)
+		if !ok1 || !ok2 {
+			return false, nil
+		}
+		// ASL wildcards are "*" only; path.Match also treats "?" and "[" specially,
+		// so escape those first.
+		pattern = strings.NewReplacer("?", `\?`, "[", `\[`).Replace(pattern)
+		ok, _ := path.Match(pattern, s)
+		return ok, nil
+	}
+
+	var cmp int
+	switch {
+	case strings.HasPrefix(op, "String"):
+		a, ok1 := val.(string)
+		b, ok2 := operand.(string)
+		if !ok1 || !ok2 {
+			return false, nil
+		}
+		cmp = strings.Compare(a, b)
+	case strings.HasPrefix(op, "Numeric"):
+		a, ok1 := val.(float64)
+		b, ok2 := operand.(float64)
+		if !ok1 || !ok2 {
+			return false, nil
+		}
+		cmp = compareFloat(a, b)
+	case strings.HasPrefix(op, "Timestamp"):
+		a, ok1 := asTimestamp(val)
+		b, ok2 := asTimestamp(operand)
+		if !ok1 || !ok2 {
+			return false, nil
+		}
+		cmp = a.Compare(b)
+	default:
+		return false, runtimeError("unsupported choice operator %s", r.Operator)
+	}
+
+	switch {
+	case strings.HasSuffix(op, "LessThanEquals"):
+		return cmp <= 0, nil
+	case strings.HasSuffix(op, "GreaterThanEquals"):
+		return cmp >= 0, nil
+	case strings.HasSuffix(op, "LessThan"):
+		return cmp < 0, nil
+	case strings.HasSuffix(op, "GreaterThan"):
+		return cmp > 0, nil
+	default: // Equals
+		return cmp == 0, nil
+	}
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func asTimestamp(v interface{}) (time.Time, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	return t, err == nil
+}
@@ -0,0 +1,557 @@
+package sfnsim
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Contract describes the payloads a Lambda exchanges with Step Functions
+// (DDR-094). Values are zero values of the Go types the handler decodes and
+// returns; SchemaOf derives their shape from json tags.
+type Contract struct {
+	// Event is the handler's input type. Nil means the handler takes an
+	// untyped map, so payload keys are not checked.
+	Event interface{}
+
+	// Result is the handler's output type when it does not depend on the
+	// event. Nil (with no Results) means the result is not checked.
+	Result interface{}
+
+	// Results maps the event "type" field to the result type for handlers
+	// that dispatch on it. A nil entry means that event type returns no
+	// result. A Task sending a literal type not listed here is a violation.
+	Results map[string]interface{}
+}
+
+// Violation is one contract mismatch found by Check.
+type Violation struct {
+	State string // state path, e.g. "ThumbnailMap/GenerateThumbnail"
+	Msg   string
+}
+
+func (v Violation) String() string {
+	return v.State + ": " + v.Msg
+}
+
+// Check walks a definition statically and reports every reference that
+// cannot be satisfied by the contracted Lambda payloads: Task payload keys the
+// event struct does not have, literal values of the wrong JSON type, and
+// paths (Choice variables, ResultSelector, ItemsPath, Parameters, OutputPath)
+// into Lambda results that name fields the result struct does not have.
+//
+// Lambda tasks are matched to contracts by function name, exactly or by
+// substring, preferring the longest key. Shapes that cannot be known
+// statically (execution input, uncontracted tasks) are not checked.
+func Check(def *Definition, contracts map[string]Contract) []Violation {
+	c := &checker{contracts: contracts, visits: map[string]int{}, seen: map[string]bool{}}
+	c.machine(def, Any, mapContext(nil), "")
+
+	sort.Slice(c.violations, func(i, j int) bool {
+		return c.violations[i].String() < c.violations[j].String()
+	})
+	return c.violations
+}
+
+// maxVisitsPerState bounds how many distinct input shapes one state is
+// re-checked with, so loops whose shape keeps changing still terminate.
+const maxVisitsPerState = 4
+
+type checker struct {
+	contracts  map[string]Contract
+	violations []Violation
+	visits     map[string]int
+	seen       map[string]bool
+}
+
+func (c *checker) report(where, format string, args ...interface{}) {
+	v := Violation{State: where, Msg: fmt.Sprintf(format, args...)}
+	for _, existing := range c.violations {
+		if existing == v {
+			return
+		}
+	}
+	c.violations = append(c.violations, v)
+}
+
+type pending struct {
+	name  string
+	input *Schema
+}
+
+// machine checks a (sub-)machine and returns the merged shape of its outputs.
+func (c *checker) machine(def *Definition, input, ctx *Schema, prefix string) *Schema {
+	var output *Schema
+	queue := []pending{{def.StartAt, input}}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+
+		key := prefix + p.name
+		fp := key + "|" + p.input.String()
+		if c.seen[fp] || c.visits[key] >= maxVisitsPerState {
+			continue
+		}
+		c.seen[fp] = true
+		c.visits[key]++
+
+		st := def.States[p.name]
+		for _, t := range c.state(st, p.input, ctx, key) {
+			if t.name == "" {
+				output = mergeSchema(output, t.input)
+				continue
+			}
+			queue = append(queue, t)
+		}
+	}
+	if output == nil {
+		return Any
+	}
+	return output
+}
+
+// state checks one state and returns its transitions; a transition with an
+// empty name is the machine's output.
+func (c *checker) state(st *State, raw, ctx *Schema, where string) []pending {
+	input := c.selectPath(st.InputPath.orDefault(), raw, ctx, where, "InputPath")
+
+	switch st.Type {
+	case TypeSucceed:
+		return []pending{{"", c.selectPath(st.OutputPath.orDefault(), input, ctx, where, "OutputPath")}}
+	case TypeFail:
+		return nil
+	case TypeChoice:
+		for i := range st.Choices {
+			c.choiceRule(&st.Choices[i], input, ctx, where)
+		}
+		out := c.selectPath(st.OutputPath.orDefault(), input, ctx, where, "OutputPath")
+		var next []pending
+		for _, rule := range st.Choices {
+			next = append(next, pending{rule.Next, out})
+		}
+		if st.Default != "" {
+			next = append(next, pending{st.Default, out})
+		}
+		return next
+	case TypeWait:
+		if st.SecondsPath != "" {
+			c.expectKind(c.resolve(st.SecondsPath, input, ctx, where, "SecondsPath"), KindNumber, where, "SecondsPath "+st.SecondsPath)
+		}
+		if st.TimestampPath != "" {
+			c.expectKind(c.resolve(st.TimestampPath, input, ctx, where, "TimestampPath"), KindString, where, "TimestampPath "+st.TimestampPath)
+		}
+		return []pending{{nextOf(st), c.selectPath(st.OutputPath.orDefault(), input, ctx, where, "OutputPath")}}
+	}
+
+	var result *Schema
+	switch st.Type {
+	case TypePass:
+		switch {
+		case len(st.Result) > 0:
+			var v interface{}
+			_ = json.Unmarshal(st.Result, &v)
+			result = schemaOfValue(v)
+		case len(st.Parameters) > 0:
+			result = c.template(st.Parameters, input, ctx, where, "Parameters")
+		default:
+			result = input
+		}
+	case TypeTask:
+		result = c.task(st, input, ctx, where)
+	case TypeMap:
+		result = c.mapState(st, input, ctx, where)
+	case TypeParallel:
+		branchInput := input
+		if len(st.Parameters) > 0 {
+			branchInput = c.template(st.Parameters, input, ctx, where, "Parameters")
+		}
+		for i, b := range st.Branches {
+			c.machine(b, branchInput, ctx, fmt.Sprintf("%s[%d]/", where, i))
+		}
+		result = &Schema{Kind: KindArray, Elem: Any}
+	}
+
+	if len(st.ResultSelector) > 0 {
+		result = c.template(st.ResultSelector, result, ctx, where, "ResultSelector")
+	}
+
+	var next []pending
+	merged := c.applyResultPath(raw, result, st.ResultPath.orDefault(), where)
+	next = append(next, pending{nextOf(st), c.selectPath(st.OutputPath.orDefault(), merged, ctx, where, "OutputPath")})
+
+	errObj := &Schema{Kind: KindObject, Fields: map[string]*Schema{
+		"Error": {Kind: KindString},
+		"Cause": {Kind: KindString},
+	}}
+	for _, catcher := range st.Catch {
+		next = append(next, pending{catcher.Next, c.applyResultPath(raw, errObj, catcher.ResultPath.orDefault(), where)})
+	}
+	return next
+}
+
+func nextOf(st *State) string {
+	if st.End {
+		return ""
+	}
+	return st.Next
+}
+
+// task checks a Task's payload against the target's event contract and
+// returns the shape of its result.
+func (c *checker) task(st *State, input, ctx *Schema, where string) *Schema {
+	payloadTmpl := st.Parameters
+	target := st.Resource
+	isInvoke := st.Resource == lambdaInvokeResource
+
+	if isInvoke {
+		var params map[string]json.RawMessage
+		_ = json.Unmarshal(st.Parameters, &params)
+		if fn, ok := params["FunctionName"]; ok {
+			_ = json.Unmarshal(fn, &target)
+		} else if _, ok := params["FunctionName.$"]; ok {
+			target = ""
+		}
+		payloadTmpl = params["Payload"]
+		if p, ok := params["Payload.$"]; ok {
+			var expr string
+			_ = json.Unmarshal(p, &expr)
+			payloadTmpl, _ = json.Marshal(map[string]string{"$": expr})
+		}
+	} else if !strings.HasPrefix(st.Resource, "arn:aws:lambda:") {
+		// Other service integrations (nested executions, DynamoDB): only the
+		// parameter paths are checked.
+		if len(st.Parameters) > 0 {
+			c.template(st.Parameters, input, ctx, where, "Parameters")
+		}
+		return Any
+	}
+
+	// Payload shape: an explicit template, a whole-path reference, or the
+	// (InputPath-filtered) state input.
+	var payload *Schema
+	var payloadLiteral map[string]interface{}
+	switch {
+	case len(payloadTmpl) == 0:
+		payload = input
+	default:
+		var whole map[string]string
+		if err := json.Unmarshal(payloadTmpl, &whole); err == nil && len(whole) == 1 && whole["$"] != "" {
+			payload = c.resolve(whole["$"], input, ctx, where, "Payload.$")
+		} else {
+			payload = c.template(payloadTmpl, input, ctx, where, "Payload")
+			_ = json.Unmarshal(payloadTmpl, &payloadLiteral)
+		}
+	}
+
+	contract, name, ok := c.contractFor(target)
+	if !ok {
+		return wrapInvoke(Any, isInvoke)
+	}
+
+	if contract.Event != nil {
+		c.checkPayload(payload, SchemaOf(contract.Event), where, name)
+	}
+
+	var result *Schema
+	switch {
+	case contract.Results != nil:
+		eventType, _ := payloadLiteral["type"].(string)
+		if eventType == "" {
+			result = Any // type comes from a path; cannot pick statically
+			break
+		}
+		rt, ok := contract.Results[eventType]
+		if !ok {
+			c.report(where, "%s has no handler for event type %q", name, eventType)
+			result = Any
+			break
+		}
+		result = SchemaOf(rt)
+	case contract.Result != nil:
+		result = SchemaOf(contract.Result)
+	default:
+		result = Any
+	}
+	return wrapInvoke(result, isInvoke)
+}
+
+// wrapInvoke models the lambda:invoke integration's result envelope.
+func wrapInvoke(result *Schema, isInvoke bool) *Schema {
+	if !isInvoke {
+		return result
+	}
+	return &Schema{Kind: KindObject, Name: "lambda:invoke result", Fields: map[string]*Schema{
+		"ExecutedVersion":     {Kind: KindString},
+		"Payload":             result,
+		"StatusCode":          {Kind: KindNumber},
+		"SdkHttpMetadata":     Any,
+		"SdkResponseMetadata": Any,
+	}}
+}
+
+func (c *checker) contractFor(target string) (Contract, string, bool) {
+	fn := target
+	if _, rest, ok := strings.Cut(target, ":function:"); ok {
+		fn, _, _ = strings.Cut(rest, ":")
+	}
+	best := ""
+	for name := range c.contracts {
+		if (fn == name || strings.Contains(fn, name)) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return Contract{}, "", false
+	}
+	return c.contracts[best], best, true
+}
+
+// checkPayload reports payload fields the event struct does not declare and
+// known-kind mismatches.
+func (c *checker) checkPayload(payload, event *Schema, where, name string) {
+	if payload.Kind != KindObject {
+		if payload.Kind != KindAny {
+			c.report(where, "%s payload is %s, want object %s", name, payload.Kind, event.describe())
+		}
+		return
+	}
+	keys := make([]string, 0, len(payload.Fields))
+	for k := range payload.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		want, ok := event.Fields[k]
+		if !ok {
+			if !event.Open {
+				c.report(where, "payload field %q is not in %s (handler ignores it)", k, event.describe())
+			}
+			continue
+		}
+		got := payload.Fields[k]
+		if !kindsCompatible(got, want) {
+			c.report(where, "payload field %q is %s, %s.%s wants %s", k, got.Kind, event.describe(), k, want.Kind)
+		}
+	}
+}
+
+func kindsCompatible(got, want *Schema) bool {
+	if got.Kind == KindAny || want.Kind == KindAny || got.Kind == KindNull {
+		return true
+	}
+	return got.Kind == want.Kind
+}
+
+func (c *checker) mapState(st *State, input, ctx *Schema, where string) *Schema {
+	items := c.selectPath(st.ItemsPath.orDefault(), input, ctx, where, "ItemsPath")
+	elem := Any
+	switch items.Kind {
+	case KindArray:
+		elem = items.Elem
+	case KindAny:
+	default:
+		c.report(where, "ItemsPath %s is %s, not an array", st.ItemsPath.orDefault(), items.describe())
+	}
+
+	itemCtx := mapContext(elem)
+	itemInput := elem
+	selector := st.ItemSelector
+	if len(selector) == 0 {
+		selector = st.Parameters
+	}
+	if len(selector) > 0 {
+		itemInput = c.template(selector, input, itemCtx, where, "ItemSelector")
+	}
+
+	out := c.machine(st.Processor(), itemInput, itemCtx, where+"/")
+	return &Schema{Kind: KindArray, Elem: out}
+}
+
+// mapContext is the context object schema; only $$.Map.Item is modeled.
+func mapContext(item *Schema) *Schema {
+	ctx := &Schema{Kind: KindObject, Open: true, Fields: map[string]*Schema{}}
+	if item != nil {
+		ctx.Fields["Map"] = &Schema{Kind: KindObject, Fields: map[string]*Schema{
+			"Item": {Kind: KindObject, Fields: map[string]*Schema{
+				"Index": {Kind: KindNumber},
+				"Value": item,
+			}},
+		}}
+	}
+	return ctx
+}
+
+func (c *checker) choiceRule(r *ChoiceRule, input, ctx *Schema, where string) {
+	for i := range r.And {
+		c.choiceRule(&r.And[i], input, ctx, where)
+	}
+	for i := range r.Or {
+		c.choiceRule(&r.Or[i], input, ctx, where)
+	}
+	if r.Not != nil {
+		c.choiceRule(r.Not, input, ctx, where)
+	}
+	if r.Operator == "" {
+		return
+	}
+
+	if r.Operator == "IsPresent" {
+		// A missing field is the point of IsPresent, but a field that can
+		// never exist makes the rule constant.
+		if _, err := c.lookupRoot(r.Variable, input, ctx); err != nil {
+			c.report(where, "IsPresent on %s is always false: %v", r.Variable, err)
+		}
+		return
+	}
+
+	v := c.resolve(r.Variable, input, ctx, where, "Choice "+r.Operator)
+	op := strings.TrimSuffix(r.Operator, "Path")
+	var want Kind
+	switch {
+	case strings.HasPrefix(op, "String"), strings.HasPrefix(op, "Timestamp"):
+		want = KindString
+	case strings.HasPrefix(op, "Numeric"):
+		want = KindNumber
+	case op == "BooleanEquals":
+		want = KindBool
+	default:
+		return // type tests accept any kind
+	}
+	c.expectKind(v, want, where, fmt.Sprintf("Choice %s on %s", r.Operator, r.Variable))
+	if strings.HasSuffix(r.Operator, "Path") {
+		var ref string
+		_ = json.Unmarshal(r.Operand, &ref)
+		c.resolve(ref, input, ctx, where, "Choice "+r.Operator)
+	}
+}
+
+func (c *checker) expectKind(s *Schema, want Kind, where, what string) {
+	if s.Kind != KindAny && s.Kind != want {
+		c.report(where, "%s: value is %s, not %s (branch can never match)", what, s.Kind, want)
+	}
+}
+
+// template checks a payload template's paths and returns its shape.
+func (c *checker) template(raw json.RawMessage, data, ctx *Schema, where, field string) *Schema {
+	var t interface{}
+	if err := json.Unmarshal(raw, &t); err != nil {
+		c.report(where, "%s is not valid JSON", field)
+		return Any
+	}
+	return c.templateValue(t, data, ctx, where, field)
+}
+
+func (c *checker) templateValue(t interface{}, data, ctx *Schema, where, field string) *Schema {
+	switch v := t.(type) {
+	case map[string]interface{}:
+		out := &Schema{Kind: KindObject, Fields: make(map[string]*Schema, len(v))}
+		for k, val := range v {
+			if !strings.HasSuffix(k, ".$") {
+				out.Fields[k] = c.templateValue(val, data, ctx, where, field)
+				continue
+			}
+			expr, _ := val.(string)
+			name := strings.TrimSuffix(k, ".$")
+			if strings.HasPrefix(expr, "States.") {
+				c.intrinsicArgs(expr, data, ctx, where, field+"."+name)
+				out.Fields[name] = intrinsicSchema(expr)
+				continue
+			}
+			out.Fields[name] = c.resolve(expr, data, ctx, where, field+"."+name)
+		}
+		return out
+	case []interface{}:
+		out := &Schema{Kind: KindArray}
+		for _, e := range v {
+			out.Elem = mergeSchema(out.Elem, c.templateValue(e, data, ctx, where, field))
+		}
+		if out.Elem == nil {
+			out.Elem = Any
+		}
+		return out
+	}
+	return schemaOfValue(t)
+}
+
+// intrinsicArgs checks the path arguments of an intrinsic function call.
+func (c *checker) intrinsicArgs(expr string, data, ctx *Schema, where, field string) {
+	open := strings.IndexByte(expr, '(')
+	if open < 0 || !strings.HasSuffix(expr, ")") {
+		return
+	}
+	args, err := splitArgs(expr[open+1 : len(expr)-1])
+	if err != nil {
+		return
+	}
+	for _, a := range args {
+		switch {
+		case strings.HasPrefix(a, "$"):
+			c.resolve(a, data, ctx, where, field)
+		case strings.HasPrefix(a, "States."):
+			c.intrinsicArgs(a, data, ctx, where, field)
+		}
+	}
+}
+
+func intrinsicSchema(expr string) *Schema {
+	switch {
+	case strings.HasPrefix(expr, "States.Format("), strings.HasPrefix(expr, "States.JsonToString("):
+		return &Schema{Kind: KindString}
+	case strings.HasPrefix(expr, "States.ArrayLength("), strings.HasPrefix(expr, "States.MathAdd("):
+		return &Schema{Kind: KindNumber}
+	case strings.HasPrefix(expr, "States.Array("):
+		return &Schema{Kind: KindArray, Elem: Any}
+	}
+	return Any
+}
+
+// resolve looks up a path, reporting a violation (and returning Any) when it
+// cannot exist.
+func (c *checker) resolve(path string, data, ctx *Schema, where, field string) *Schema {
+	s, err := c.lookupRoot(path, data, ctx)
+	if err != nil {
+		c.report(where, "%s %s: %v", field, path, err)
+		return Any
+	}
+	return s
+}
+
+func (c *checker) lookupRoot(path string, data, ctx *Schema) (*Schema, error) {
+	if strings.HasPrefix(path, "$$") {
+		return ctx.lookup(path[1:])
+	}
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path must start with $")
+	}
+	return data.lookup(path)
+}
+
+func (c *checker) selectPath(path string, data, ctx *Schema, where, field string) *Schema {
+	switch path {
+	case "":
+		return &Schema{Kind: KindObject, Fields: map[string]*Schema{}}
+	case "$":
+		return data
+	}
+	return c.resolve(path, data, ctx, where, field)
+}
+
+func (c *checker) applyResultPath(raw, result *Schema, path, where string) *Schema {
+	switch path {
+	case "":
+		return raw
+	case "$":
+		return result
+	}
+	steps, err := parsePath(strings.TrimPrefix(path, "$"))
+	if err != nil || strings.HasPrefix(path, "$$") {
+		c.report(where, "invalid ResultPath %s", path)
+		return Any
+	}
+	if raw.Kind != KindObject && raw.Kind != KindAny {
+		c.report(where, "ResultPath %s: state input is %s, not an object", path, raw.describe())
+		return Any
+	}
+	return raw.withField(steps, result)
+}
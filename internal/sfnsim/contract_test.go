@@ -0,0 +1,151 @@
+package sfnsim
+
+import (
+	"strings"
+	"testing"
+)
+
+type testTriageEvent struct {
+	Type      string `json:"type"`
+	SessionID string `json:"sessionId"`
+	Label     string `json:"label,omitempty"`
+}
+
+type testPrepareResult struct {
+	SessionID string `json:"sessionId"`
+}
+
+type testCheckResult struct {
+	AllProcessed bool `json:"allProcessed"`
+}
+
+func triageContracts() map[string]Contract {
+	return map[string]Contract{
+		"TriageProcessor": {
+			Event: testTriageEvent{},
+			Results: map[string]interface{}{
+				"triage-prepare":          testPrepareResult{},
+				"triage-check-processing": testCheckResult{},
+				"triage-run":              nil,
+			},
+		},
+	}
+}
+
+func checkViolations(t *testing.T, contracts map[string]Contract) []string {
+	t.Helper()
+	def, err := Parse([]byte(triageDefinition))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	var out []string
+	for _, v := range Check(def, contracts) {
+		out = append(out, v.String())
+	}
+	return out
+}
+
+func TestCheckValidDefinition(t *testing.T) {
+	if v := checkViolations(t, triageContracts()); len(v) != 0 {
+		t.Errorf("unexpected violations:\n%s", strings.Join(v, "\n"))
+	}
+}
+
+func TestCheckReportsDrift(t *testing.T) {
+	type renamedCheckResult struct {
+		AllProcessed bool `json:"all_processed"`
+	}
+	type narrowEvent struct {
+		Type      string `json:"type"`
+		SessionID string `json:"sessionId"`
+	}
+	contracts := triageContracts()
+	c := contracts["TriageProcessor"]
+	c.Event = narrowEvent{}
+	c.Results["triage-check-processing"] = renamedCheckResult{}
+	delete(c.Results, "triage-run")
+	contracts["TriageProcessor"] = c
+
+	got := strings.Join(checkViolations(t, contracts), "\n")
+	for _, want := range []string{
+		`Check: ResultSelector.allProcessed $.Payload.allProcessed: field "allProcessed" does not exist in renamedCheckResult`,
+		`Run: payload field "label" is not in narrowEvent`,
+		`Run: TriageProcessor has no handler for event type "triage-run"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing violation %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestCheckChoiceKindMismatch(t *testing.T) {
+	type stringCheckResult struct {
+		AllProcessed string `json:"allProcessed"`
+	}
+	contracts := triageContracts()
+	contracts["TriageProcessor"].Results["triage-check-processing"] = stringCheckResult{}
+
+	got := strings.Join(checkViolations(t, contracts), "\n")
+	if !strings.Contains(got, "Ready?: Choice BooleanEquals on $.check.allProcessed: value is string") {
+		t.Errorf("expected kind mismatch, got:\n%s", got)
+	}
+}
+
+func TestCheckMapItemValue(t *testing.T) {
+	def, err := Parse([]byte(`{
+	  "StartAt": "Fan",
+	  "States": {
+	    "Fan": {
+	      "Type": "Map",
+	      "ItemsPath": "$.Payload.keys",
+	      "ItemSelector": {"key.$": "$$.Map.Item.Value", "index.$": "$$.Map.Item.Index"},
+	      "ItemProcessor": {
+	        "StartAt": "Work",
+	        "States": {
+	          "Work": {
+	            "Type": "Task",
+	            "Resource": "arn:aws:lambda:us-east-1:123:function:Worker",
+	            "End": true
+	          }
+	        }
+	      },
+	      "End": true
+	    }
+	  }
+	}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	type workerEvent struct {
+		Key string `json:"key"`
+	}
+	got := Check(def, map[string]Contract{"Worker": {Event: workerEvent{}}})
+	if len(got) != 1 || got[0].String() != `Fan/Work: payload field "index" is not in workerEvent (handler ignores it)` {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestSchemaOf(t *testing.T) {
+	type inner struct {
+		Shared string `json:"shared"`
+	}
+	type outer struct {
+		inner
+		Named   int    `json:"named,omitempty"`
+		Skipped string `json:"-"`
+		Plain   bool
+		Tags    map[string]string `json:"tags"`
+		Items   []inner           `json:"items"`
+	}
+	s := SchemaOf(outer{})
+	want := "{Plain:boolean,items:[{shared:string}],named:number,shared:string,tags:{,…}}"
+	if got := s.String(); got != want {
+		t.Errorf("SchemaOf = %s, want %s", got, want)
+	}
+	if _, err := s.lookup("$.tags.anything"); err != nil {
+		t.Errorf("map lookup: %v", err)
+	}
+	if _, err := s.lookup("$.items[0].missing"); err == nil {
+		t.Error("expected error for missing field in array element")
+	}
+}
@@ -0,0 +1,245 @@
+// Package sfnsim interprets Amazon States Language (ASL) state machine
+// definitions locally so pipeline changes can be exercised without deploying
+// (DDR-093).
+//
+// The simulator walks the same states Step Functions would — Task, Pass,
+// Choice, Wait, Map, Parallel, Succeed, Fail — applying InputPath, Parameters,
+// ResultSelector, ResultPath, and OutputPath processing, Retry and Catch, and
+// the Map context object ($$.Map.Item.Value / $$.Map.Item.Index). Task states
+// are dispatched to an Invoker, which the sfn-sim CLI backs with the real
+// Lambda handlers.
+//
+// Unsupported features (callback tokens, distributed Map item readers, JSONata)
+// fail the definition at parse time instead of being silently ignored.
+package sfnsim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// State types defined by the Amazon States Language.
+const (
+	TypeTask     = "Task"
+	TypePass     = "Pass"
+	TypeChoice   = "Choice"
+	TypeWait     = "Wait"
+	TypeMap      = "Map"
+	TypeParallel = "Parallel"
+	TypeSucceed  = "Succeed"
+	TypeFail     = "Fail"
+)
+
+// Definition is a parsed state machine (or Map/Parallel sub-machine).
+type Definition struct {
+	Comment        string            `json:"Comment,omitempty"`
+	StartAt        string            `json:"StartAt"`
+	States         map[string]*State `json:"States"`
+	TimeoutSeconds int               `json:"TimeoutSeconds,omitempty"`
+	QueryLanguage  string            `json:"QueryLanguage,omitempty"`
+}
+
+// State is a single ASL state. Fields not used by a state's Type are ignored.
+type State struct {
+	Type    string `json:"Type"`
+	Comment string `json:"Comment,omitempty"`
+	Next    string `json:"Next,omitempty"`
+	End     bool   `json:"End,omitempty"`
+
+	InputPath      OptionalPath    `json:"InputPath"`
+	OutputPath     OptionalPath    `json:"OutputPath"`
+	ResultPath     OptionalPath    `json:"ResultPath"`
+	Parameters     json.RawMessage `json:"Parameters,omitempty"`
+	ResultSelector json.RawMessage `json:"ResultSelector,omitempty"`
+
+	// Task
+	Resource string    `json:"Resource,omitempty"`
+	Retry    []Retrier `json:"Retry,omitempty"`
+	Catch    []Catcher `json:"Catch,omitempty"`
+
+	// Pass
+	Result json.RawMessage `json:"Result,omitempty"`
+
+	// Choice
+	Choices []ChoiceRule `json:"Choices,omitempty"`
+	Default string       `json:"Default,omitempty"`
+
+	// Wait
+	Seconds       *float64 `json:"Seconds,omitempty"`
+	SecondsPath   string   `json:"SecondsPath,omitempty"`
+	Timestamp     string   `json:"Timestamp,omitempty"`
+	TimestampPath string   `json:"TimestampPath,omitempty"`
+
+	// Map
+	ItemsPath      OptionalPath    `json:"ItemsPath"`
+	ItemSelector   json.RawMessage `json:"ItemSelector,omitempty"`
+	Iterator       *Definition     `json:"Iterator,omitempty"`
+	ItemProcessor  *Definition     `json:"ItemProcessor,omitempty"`
+	ItemReader     json.RawMessage `json:"ItemReader,omitempty"`
+	MaxConcurrency int             `json:"MaxConcurrency,omitempty"`
+
+	// Parallel
+	Branches []*Definition `json:"Branches,omitempty"`
+
+	// Fail
+	Error string `json:"Error,omitempty"`
+	Cause string `json:"Cause,omitempty"`
+}
+
+// Retrier is one entry of a Task's Retry array.
+type Retrier struct {
+	ErrorEquals     []string `json:"ErrorEquals"`
+	IntervalSeconds *float64 `json:"IntervalSeconds,omitempty"`
+	MaxAttempts     *int     `json:"MaxAttempts,omitempty"`
+	BackoffRate     *float64 `json:"BackoffRate,omitempty"`
+}
+
+// Catcher is one entry of a Task's Catch array.
+type Catcher struct {
+	ErrorEquals []string     `json:"ErrorEquals"`
+	Next        string       `json:"Next"`
+	ResultPath  OptionalPath `json:"ResultPath"`
+}
+
+// OptionalPath is a reference path field that distinguishes "absent" (use the
+// ASL default, "$") from an explicit JSON null (discard the input or result).
+type OptionalPath struct {
+	Set  bool
+	Null bool
+	Path string
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It is called for JSON null too.
+func (p *OptionalPath) UnmarshalJSON(data []byte) error {
+	p.Set = true
+	if string(data) == "null" {
+		p.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &p.Path)
+}
+
+// orDefault returns the path to apply, or "" when the field was explicitly null.
+func (p OptionalPath) orDefault() string {
+	if !p.Set {
+		return "$"
+	}
+	if p.Null {
+		return ""
+	}
+	return p.Path
+}
+
+// Parse decodes an ASL definition and validates its structure: every
+// transition target must exist, every non-terminal state must have a Next,
+// and Map/Parallel sub-machines must be valid in turn.
+func Parse(data []byte) (*Definition, error) {
+	var def Definition
+	if err := json.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("decode definition: %w", err)
+	}
+	if def.QueryLanguage != "" && def.QueryLanguage != "JSONPath" {
+		return nil, fmt.Errorf("query language %q not supported", def.QueryLanguage)
+	}
+	if err := def.validate(""); err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// Processor returns the sub-machine run for each Map item. ItemProcessor
+// replaced Iterator in ASL; both are accepted.
+func (s *State) Processor() *Definition {
+	if s.ItemProcessor != nil {
+		return s.ItemProcessor
+	}
+	return s.Iterator
+}
+
+func (d *Definition) validate(scope string) error {
+	if d.StartAt == "" {
+		return fmt.Errorf("%sStartAt is required", scope)
+	}
+	if _, ok := d.States[d.StartAt]; !ok {
+		return fmt.Errorf("%sStartAt %q is not a state", scope, d.StartAt)
+	}
+
+	for name, st := range d.States {
+		where := scope + name
+		if err := d.validateTransition(where, "Next", st.Next); err != nil {
+			return err
+		}
+		if err := d.validateTransition(where, "Default", st.Default); err != nil {
+			return err
+		}
+		for _, c := range st.Catch {
+			if err := d.validateTransition(where, "Catch.Next", c.Next); err != nil {
+				return err
+			}
+		}
+
+		switch st.Type {
+		case TypeTask, TypePass, TypeWait, TypeMap, TypeParallel:
+			if st.Next == "" && !st.End {
+				return fmt.Errorf("%s: %s state needs Next or End", where, st.Type)
+			}
+		case TypeChoice:
+			if len(st.Choices) == 0 {
+				return fmt.Errorf("%s: Choice state has no Choices", where)
+			}
+			for i, rule := range st.Choices {
+				if rule.Next == "" {
+					return fmt.Errorf("%s: Choices[%d] has no Next", where, i)
+				}
+				if err := d.validateTransition(where, "Choices.Next", rule.Next); err != nil {
+					return err
+				}
+			}
+		case TypeSucceed, TypeFail:
+		default:
+			return fmt.Errorf("%s: unknown state type %q", where, st.Type)
+		}
+
+		switch st.Type {
+		case TypeTask:
+			if st.Resource == "" {
+				return fmt.Errorf("%s: Task state has no Resource", where)
+			}
+			if strings.HasSuffix(st.Resource, ".waitForTaskToken") {
+				return fmt.Errorf("%s: callback task %q not supported", where, st.Resource)
+			}
+		case TypeMap:
+			if len(st.ItemReader) > 0 {
+				return fmt.Errorf("%s: distributed Map ItemReader not supported", where)
+			}
+			proc := st.Processor()
+			if proc == nil {
+				return fmt.Errorf("%s: Map state has no ItemProcessor", where)
+			}
+			if err := proc.validate(where + "/"); err != nil {
+				return err
+			}
+		case TypeParallel:
+			if len(st.Branches) == 0 {
+				return fmt.Errorf("%s: Parallel state has no Branches", where)
+			}
+			for i, b := range st.Branches {
+				if err := b.validate(fmt.Sprintf("%s[%d]/", where, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Definition) validateTransition(where, field, target string) error {
+	if target == "" {
+		return nil
+	}
+	if _, ok := d.States[target]; !ok {
+		return fmt.Errorf("%s: %s %q is not a state", where, field, target)
+	}
+	return nil
+}
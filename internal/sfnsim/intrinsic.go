@@ -0,0 +1,198 @@
+package sfnsim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// evalIntrinsic evaluates the subset of ASL intrinsic functions used by the
+// pipeline definitions: States.Format, States.StringToJson,
+// States.JsonToString, States.Array, States.ArrayLength, States.ArrayGetItem,
+// and States.MathAdd. Anything else is a runtime error rather than a silent
+// passthrough.
+func evalIntrinsic(expr string, data, ctxObj interface{}) (interface{}, error) {
+	open := strings.IndexByte(expr, '(')
+	if open < 0 || !strings.HasSuffix(expr, ")") {
+		return nil, runtimeError("malformed intrinsic %q", expr)
+	}
+	name := expr[:open]
+	rawArgs, err := splitArgs(expr[open+1 : len(expr)-1])
+	if err != nil {
+		return nil, runtimeError("intrinsic %q: %v", expr, err)
+	}
+
+	args := make([]interface{}, len(rawArgs))
+	for i, a := range rawArgs {
+		if args[i], err = evalArg(a, data, ctxObj); err != nil {
+			return nil, err
+		}
+	}
+
+	switch name {
+	case "States.Format":
+		if len(args) == 0 {
+			return nil, runtimeError("States.Format needs a template")
+		}
+		tmpl, ok := args[0].(string)
+		if !ok {
+			return nil, runtimeError("States.Format template must be a string")
+		}
+		var b strings.Builder
+		next := 1
+		for i := 0; i < len(tmpl); i++ {
+			if tmpl[i] == '{' && i+1 < len(tmpl) && tmpl[i+1] == '}' {
+				if next >= len(args) {
+					return nil, runtimeError("States.Format: not enough arguments")
+				}
+				b.WriteString(formatValue(args[next]))
+				next++
+				i++
+				continue
+			}
+			b.WriteByte(tmpl[i])
+		}
+		return b.String(), nil
+
+	case "States.StringToJson":
+		s, ok := singleString(args)
+		if !ok {
+			return nil, runtimeError("States.StringToJson needs one string argument")
+		}
+		var v interface{}
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			return nil, runtimeError("States.StringToJson: %v", err)
+		}
+		return v, nil
+
+	case "States.JsonToString":
+		if len(args) != 1 {
+			return nil, runtimeError("States.JsonToString needs one argument")
+		}
+		b, err := json.Marshal(args[0])
+		if err != nil {
+			return nil, runtimeError("States.JsonToString: %v", err)
+		}
+		return string(b), nil
+
+	case "States.Array":
+		return args, nil
+
+	case "States.ArrayLength":
+		if len(args) != 1 {
+			return nil, runtimeError("States.ArrayLength needs one argument")
+		}
+		arr, ok := args[0].([]interface{})
+		if !ok {
+			return nil, runtimeError("States.ArrayLength argument is not an array")
+		}
+		return float64(len(arr)), nil
+
+	case "States.ArrayGetItem":
+		if len(args) != 2 {
+			return nil, runtimeError("States.ArrayGetItem needs two arguments")
+		}
+		arr, ok := args[0].([]interface{})
+		idx, ok2 := args[1].(float64)
+		if !ok || !ok2 || int(idx) < 0 || int(idx) >= len(arr) {
+			return nil, runtimeError("States.ArrayGetItem: invalid array or index")
+		}
+		return arr[int(idx)], nil
+
+	case "States.MathAdd":
+		if len(args) != 2 {
+			return nil, runtimeError("States.MathAdd needs two arguments")
+		}
+		a, ok := args[0].(float64)
+		b, ok2 := args[1].(float64)
+		if !ok || !ok2 {
+			return nil, runtimeError("States.MathAdd arguments must be numbers")
+		}
+		return a + b, nil
+	}
+	return nil, runtimeError("intrinsic function %s not supported", name)
+}
+
+// splitArgs splits an intrinsic argument list on top-level commas, respecting
+// quoted strings and nested calls.
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	depth, start := 0, 0
+	inQuote := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && inQuote:
+			i++
+		case c == '\'':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			args = append(args, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if inQuote || depth != 0 {
+		return nil, fmt.Errorf("unbalanced argument list")
+	}
+	if tail := strings.TrimSpace(s[start:]); tail != "" || len(args) > 0 {
+		args = append(args, tail)
+	}
+	return args, nil
+}
+
+func evalArg(a string, data, ctxObj interface{}) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(a, "'"):
+		if len(a) < 2 || !strings.HasSuffix(a, "'") {
+			return nil, runtimeError("unterminated string literal %s", a)
+		}
+		return unescapeLiteral(a[1 : len(a)-1]), nil
+	case strings.HasPrefix(a, "States."):
+		return evalIntrinsic(a, data, ctxObj)
+	case strings.HasPrefix(a, "$"):
+		return resolvePath(a, data, ctxObj)
+	case a == "null":
+		return nil, nil
+	case a == "true", a == "false":
+		return a == "true", nil
+	}
+	n, err := strconv.ParseFloat(a, 64)
+	if err != nil {
+		return nil, runtimeError("invalid intrinsic argument %q", a)
+	}
+	return n, nil
+}
+
+func unescapeLiteral(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func singleString(args []interface{}) (string, bool) {
+	if len(args) != 1 {
+		return "", false
+	}
+	s, ok := args[0].(string)
+	return s, ok
+}
+
+// formatValue renders a States.Format argument the way Step Functions does:
+// strings verbatim, everything else as JSON.
+func formatValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,208 @@
+package sfnsim
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathStep is one segment of a reference path: an object field or array index.
+type pathStep struct {
+	field string
+	index int
+	isIdx bool
+}
+
+// parsePath splits a reference path ("$.a.b[0]", "$['a']") into steps. The
+// leading "$" (or "$$" for the context object) must already be stripped.
+func parsePath(rest string) ([]pathStep, error) {
+	var steps []pathStep
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty field name")
+			}
+			steps = append(steps, pathStep{field: rest[:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated bracket")
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			if len(inner) >= 2 && inner[0] == '\'' && inner[len(inner)-1] == '\'' {
+				steps = append(steps, pathStep{field: inner[1 : len(inner)-1]})
+				continue
+			}
+			n, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("unsupported index %q", inner)
+			}
+			steps = append(steps, pathStep{index: n, isIdx: true})
+		default:
+			return nil, fmt.Errorf("unexpected %q", rest[0])
+		}
+	}
+	return steps, nil
+}
+
+// resolvePath evaluates a reference path against the state data ("$...") or
+// the context object ("$$..."). A path that does not resolve is an error, as
+// it is in Step Functions (States.Runtime).
+func resolvePath(path string, data, ctxObj interface{}) (interface{}, error) {
+	root := data
+	rest := path
+	switch {
+	case strings.HasPrefix(path, "$$"):
+		root, rest = ctxObj, path[2:]
+	case strings.HasPrefix(path, "$"):
+		rest = path[1:]
+	default:
+		return nil, runtimeError("invalid path %q: must start with $", path)
+	}
+
+	steps, err := parsePath(rest)
+	if err != nil {
+		return nil, runtimeError("invalid path %q: %v", path, err)
+	}
+
+	cur := root
+	for _, st := range steps {
+		if st.isIdx {
+			arr, ok := cur.([]interface{})
+			if !ok || st.index < 0 || st.index >= len(arr) {
+				return nil, runtimeError("path %q: index %d not found", path, st.index)
+			}
+			cur = arr[st.index]
+			continue
+		}
+		obj, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, runtimeError("path %q: field %q not found", path, st.field)
+		}
+		v, ok := obj[st.field]
+		if !ok {
+			return nil, runtimeError("path %q: field %q not found", path, st.field)
+		}
+		cur = v
+	}
+	return cur, nil
+}
+
+// pathExists reports whether a path resolves, for the IsPresent choice operator.
+func pathExists(path string, data, ctxObj interface{}) bool {
+	_, err := resolvePath(path, data, ctxObj)
+	return err == nil
+}
+
+// applyResultPath places result into input at path, following ResultPath
+// semantics: "$" replaces the input, a nested path creates intermediate
+// objects, and "" (explicit null) discards the result. The input is copied,
+// never mutated, since Map iterations share it.
+func applyResultPath(input, result interface{}, path string) (interface{}, error) {
+	switch path {
+	case "":
+		return input, nil
+	case "$":
+		return result, nil
+	}
+	if !strings.HasPrefix(path, "$") || strings.HasPrefix(path, "$$") {
+		return nil, runtimeError("invalid ResultPath %q", path)
+	}
+	steps, err := parsePath(path[1:])
+	if err != nil {
+		return nil, runtimeError("invalid ResultPath %q: %v", path, err)
+	}
+	for _, st := range steps {
+		if st.isIdx {
+			return nil, runtimeError("ResultPath %q: array indexes not supported", path)
+		}
+	}
+
+	root, ok := input.(map[string]interface{})
+	if !ok {
+		return nil, runtimeError("ResultPath %q: state input is not an object", path)
+	}
+	out := copyObject(root)
+	cur := out
+	for i, st := range steps {
+		if i == len(steps)-1 {
+			cur[st.field] = result
+			break
+		}
+		next, ok := cur[st.field].(map[string]interface{})
+		if ok {
+			next = copyObject(next)
+		} else {
+			next = map[string]interface{}{}
+		}
+		cur[st.field] = next
+		cur = next
+	}
+	return out, nil
+}
+
+func copyObject(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m)+1)
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// renderTemplate evaluates a Parameters / ResultSelector / ItemSelector
+// payload template. Keys ending in ".$" take their value from a path or an
+// intrinsic function; all other values are copied literally.
+func renderTemplate(tmpl, data, ctxObj interface{}) (interface{}, error) {
+	switch t := tmpl.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			if strings.HasSuffix(k, ".$") {
+				expr, ok := v.(string)
+				if !ok {
+					return nil, runtimeError("field %q: value must be a path string", k)
+				}
+				val, err := evalExpression(expr, data, ctxObj)
+				if err != nil {
+					return nil, err
+				}
+				out[strings.TrimSuffix(k, ".$")] = val
+				continue
+			}
+			val, err := renderTemplate(v, data, ctxObj)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = val
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, v := range t {
+			val, err := renderTemplate(v, data, ctxObj)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = val
+		}
+		return out, nil
+	default:
+		return tmpl, nil
+	}
+}
+
+// evalExpression evaluates the value of a ".$" template field.
+func evalExpression(expr string, data, ctxObj interface{}) (interface{}, error) {
+	if strings.HasPrefix(expr, "States.") {
+		return evalIntrinsic(expr, data, ctxObj)
+	}
+	return resolvePath(expr, data, ctxObj)
+}
@@ -0,0 +1,273 @@
+package sfnsim
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Kind is the JSON type a Schema describes.
+type Kind int
+
+// JSON kinds. KindAny means the shape is unknown and every path into it is
+// accepted.
+const (
+	KindAny Kind = iota
+	KindObject
+	KindArray
+	KindString
+	KindNumber
+	KindBool
+	KindNull
+)
+
+func (k Kind) String() string {
+	return [...]string{"any", "object", "array", "string", "number", "boolean", "null"}[k]
+}
+
+// Schema is the static shape of a JSON value, derived from Go struct json tags
+// (SchemaOf) or from literal values in a definition. It is used to check that
+// state machine paths only reference fields the Lambda payloads actually have
+// (DDR-094).
+type Schema struct {
+	Kind   Kind
+	Name   string             // Go type name, for messages
+	Fields map[string]*Schema // KindObject
+	Open   bool               // KindObject: unknown fields are allowed (maps, partially known input)
+	Elem   *Schema            // KindArray element; KindObject value type when Open
+}
+
+// Any is the schema of a value whose shape is unknown.
+var Any = &Schema{Kind: KindAny}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	rawMessageType    = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// SchemaOf derives a schema from a Go value's type using encoding/json rules:
+// json tag names, "-" skipping, and embedded struct flattening. Types with a
+// custom MarshalJSON are treated as KindAny. A nil value yields KindNull,
+// for handlers that return no result.
+func SchemaOf(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{Kind: KindNull}
+	}
+	return schemaOfType(reflect.TypeOf(v), map[reflect.Type]*Schema{})
+}
+
+func schemaOfType(t reflect.Type, seen map[reflect.Type]*Schema) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if s, ok := seen[t]; ok {
+		return s // recursive type
+	}
+	if t == rawMessageType || t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return Any
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Kind: KindString, Name: t.Name()}
+	case reflect.Bool:
+		return &Schema{Kind: KindBool}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return &Schema{Kind: KindNumber}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Kind: KindString} // []byte is base64
+		}
+		return &Schema{Kind: KindArray, Elem: schemaOfType(t.Elem(), seen)}
+	case reflect.Map:
+		return &Schema{Kind: KindObject, Open: true, Elem: schemaOfType(t.Elem(), seen)}
+	case reflect.Struct:
+		s := &Schema{Kind: KindObject, Name: t.Name(), Fields: map[string]*Schema{}}
+		seen[t] = s
+		addStructFields(s, t, seen)
+		return s
+	}
+	return Any
+}
+
+func addStructFields(s *Schema, t reflect.Type, seen map[reflect.Type]*Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addStructFields(s, ft, seen)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Fields[name] = schemaOfType(f.Type, seen)
+	}
+}
+
+// schemaOfValue derives a schema from a decoded JSON literal.
+func schemaOfValue(v interface{}) *Schema {
+	switch t := v.(type) {
+	case nil:
+		return &Schema{Kind: KindNull}
+	case string:
+		return &Schema{Kind: KindString}
+	case float64:
+		return &Schema{Kind: KindNumber}
+	case bool:
+		return &Schema{Kind: KindBool}
+	case []interface{}:
+		if len(t) == 0 {
+			return &Schema{Kind: KindArray, Elem: Any}
+		}
+		elem := schemaOfValue(t[0])
+		for _, e := range t[1:] {
+			elem = mergeSchema(elem, schemaOfValue(e))
+		}
+		return &Schema{Kind: KindArray, Elem: elem}
+	case map[string]interface{}:
+		s := &Schema{Kind: KindObject, Fields: make(map[string]*Schema, len(t))}
+		for k, e := range t {
+			s.Fields[k] = schemaOfValue(e)
+		}
+		return s
+	}
+	return Any
+}
+
+// lookup resolves a "$"-rooted reference path against a schema. It returns an
+// error describing the first step that cannot exist.
+func (s *Schema) lookup(path string) (*Schema, error) {
+	steps, err := parsePath(strings.TrimPrefix(path, "$"))
+	if err != nil {
+		return nil, err
+	}
+	cur := s
+	for _, st := range steps {
+		switch {
+		case cur.Kind == KindAny:
+			return Any, nil
+		case st.isIdx:
+			if cur.Kind != KindArray {
+				return nil, fmt.Errorf("cannot index %s", cur.describe())
+			}
+			cur = cur.Elem
+		case cur.Kind != KindObject:
+			return nil, fmt.Errorf("field %q: %s is not an object", st.field, cur.describe())
+		default:
+			next, ok := cur.Fields[st.field]
+			if !ok {
+				if !cur.Open {
+					return nil, fmt.Errorf("field %q does not exist in %s", st.field, cur.describe())
+				}
+				next = cur.Elem
+				if next == nil {
+					next = Any
+				}
+			}
+			cur = next
+		}
+	}
+	return cur, nil
+}
+
+// withField returns a copy of s with the value at the (field-only) path
+// replaced, creating objects along the way (ResultPath semantics). Setting a
+// field on an unknown shape yields an open object, so the new field is known
+// while the rest stays unchecked.
+func (s *Schema) withField(steps []pathStep, v *Schema) *Schema {
+	if len(steps) == 0 {
+		return v
+	}
+	out := &Schema{Kind: KindObject, Open: true, Fields: map[string]*Schema{}}
+	if s.Kind == KindObject {
+		out.Name, out.Open, out.Elem = s.Name, s.Open, s.Elem
+		for k, f := range s.Fields {
+			out.Fields[k] = f
+		}
+	}
+	child, ok := out.Fields[steps[0].field]
+	if !ok {
+		child = Any
+	}
+	out.Fields[steps[0].field] = child.withField(steps[1:], v)
+	return out
+}
+
+// mergeSchema combines the shapes reaching a state along different paths.
+// Differing shapes degrade to KindAny rather than producing false positives.
+func mergeSchema(a, b *Schema) *Schema {
+	if a == nil {
+		return b
+	}
+	if b == nil || a.String() == b.String() {
+		return a
+	}
+	return Any
+}
+
+func (s *Schema) describe() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.Kind.String()
+}
+
+// String renders the schema compactly; it doubles as a fingerprint for
+// de-duplicating visits while walking a definition.
+func (s *Schema) String() string {
+	var b strings.Builder
+	s.write(&b, 0)
+	return b.String()
+}
+
+func (s *Schema) write(b *strings.Builder, depth int) {
+	if depth > 8 {
+		b.WriteString("…")
+		return
+	}
+	switch s.Kind {
+	case KindObject:
+		b.WriteByte('{')
+		keys := make([]string, 0, len(s.Fields))
+		for k := range s.Fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(k)
+			b.WriteByte(':')
+			s.Fields[k].write(b, depth+1)
+		}
+		if s.Open {
+			b.WriteString(",…")
+		}
+		b.WriteByte('}')
+	case KindArray:
+		b.WriteByte('[')
+		s.Elem.write(b, depth+1)
+		b.WriteByte(']')
+	default:
+		b.WriteString(s.Kind.String())
+	}
+}
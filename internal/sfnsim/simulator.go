@@ -0,0 +1,622 @@
+package sfnsim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// lambdaInvokeResource is the optimized Lambda service integration CDK's
+// LambdaInvoke task emits. Its result is wrapped in {"Payload": ...}.
+const lambdaInvokeResource = "arn:aws:states:::lambda:invoke"
+
+// DefaultMaxTransitions bounds a simulated execution so a polling loop whose
+// exit condition never becomes true fails fast instead of spinning forever.
+const DefaultMaxTransitions = 1000
+
+// Error is a named ASL error, as raised by a Fail state, a failed Task, or the
+// interpreter itself (States.Runtime). Retry and Catch match on Name.
+type Error struct {
+	Name  string
+	Cause string
+}
+
+func (e *Error) Error() string {
+	if e.Cause == "" {
+		return e.Name
+	}
+	return e.Name + ": " + e.Cause
+}
+
+func runtimeError(format string, args ...interface{}) error {
+	return &Error{Name: "States.Runtime", Cause: fmt.Sprintf(format, args...)}
+}
+
+// Invoker runs the work behind a Task state. For Lambda tasks target is the
+// function name or ARN and payload the event; for other service integrations
+// target is the Resource ARN and payload the rendered Parameters.
+//
+// Errors of type *Error keep their Name for Retry/Catch matching (e.g. a
+// Lambda errorType); any other error is reported as States.TaskFailed.
+type Invoker interface {
+	Invoke(ctx context.Context, target string, payload json.RawMessage) (json.RawMessage, error)
+}
+
+// InvokerFunc adapts a function to the Invoker interface.
+type InvokerFunc func(ctx context.Context, target string, payload json.RawMessage) (json.RawMessage, error)
+
+// Invoke calls f.
+func (f InvokerFunc) Invoke(ctx context.Context, target string, payload json.RawMessage) (json.RawMessage, error) {
+	return f(ctx, target, payload)
+}
+
+// Step describes one completed state transition, reported to OnStep.
+type Step struct {
+	Path   string // state name, prefixed by enclosing Map/Parallel states (e.g. "ProcessFiles[2]/Thumbnail")
+	Type   string
+	Input  interface{}
+	Output interface{}
+	Err    error
+}
+
+// Simulator runs ASL definitions against an Invoker.
+type Simulator struct {
+	invoker Invoker
+
+	// RealWaits makes Wait states and Retry intervals actually sleep. Off by
+	// default: simulated executions skip delays.
+	RealWaits bool
+
+	// MaxTransitions caps the total number of states entered, including
+	// Map iterations and Parallel branches. Zero means DefaultMaxTransitions.
+	MaxTransitions int
+
+	// OnStep, if set, is called after every state. It may be called
+	// concurrently from Map iterations and Parallel branches.
+	OnStep func(Step)
+
+	mu          sync.Mutex
+	transitions int
+}
+
+// New creates a Simulator dispatching Task states to inv.
+func New(inv Invoker) *Simulator {
+	return &Simulator{invoker: inv}
+}
+
+// Run executes def with the given JSON input and returns the execution output.
+// A failed execution returns an *Error with the ASL error name and cause.
+func (s *Simulator) Run(ctx context.Context, name string, def *Definition, input json.RawMessage) (json.RawMessage, error) {
+	var data interface{}
+	if len(input) > 0 {
+		if err := json.Unmarshal(input, &data); err != nil {
+			return nil, fmt.Errorf("decode input: %w", err)
+		}
+	} else {
+		data = map[string]interface{}{}
+	}
+
+	s.mu.Lock()
+	s.transitions = 0
+	s.mu.Unlock()
+
+	if def.TimeoutSeconds > 0 && s.RealWaits {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(def.TimeoutSeconds)*time.Second)
+		defer cancel()
+	}
+
+	start := time.Now().UTC().Format(time.RFC3339Nano)
+	ctxObj := map[string]interface{}{
+		"Execution": map[string]interface{}{
+			"Id":        "arn:aws:states:local:000000000000:execution:" + name + ":sim",
+			"Name":      "sim",
+			"Input":     data,
+			"StartTime": start,
+		},
+		"StateMachine": map[string]interface{}{
+			"Id":   "arn:aws:states:local:000000000000:stateMachine:" + name,
+			"Name": name,
+		},
+	}
+
+	out, err := s.runMachine(ctx, def, data, ctxObj, "")
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(out)
+}
+
+// runMachine walks one (sub-)machine from StartAt to a terminal state.
+func (s *Simulator) runMachine(ctx context.Context, def *Definition, data interface{}, ctxObj map[string]interface{}, prefix string) (interface{}, error) {
+	name := def.StartAt
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := s.countTransition(); err != nil {
+			return nil, err
+		}
+
+		st := def.States[name]
+		stateCtx := withState(ctxObj, name)
+		out, next, err := s.runState(ctx, st, data, stateCtx, prefix+name)
+		if s.OnStep != nil {
+			s.OnStep(Step{Path: prefix + name, Type: st.Type, Input: data, Output: out, Err: err})
+		}
+		if err != nil {
+			return nil, err
+		}
+		if next == "" {
+			return out, nil
+		}
+		data, name = out, next
+	}
+}
+
+func (s *Simulator) countTransition() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	limit := s.MaxTransitions
+	if limit <= 0 {
+		limit = DefaultMaxTransitions
+	}
+	s.transitions++
+	if s.transitions > limit {
+		return &Error{Name: "States.Runtime", Cause: fmt.Sprintf("exceeded %d state transitions — check for a polling loop that never exits", limit)}
+	}
+	return nil
+}
+
+// runState executes a single state and returns its output and the next state
+// name ("" when the machine ends).
+func (s *Simulator) runState(ctx context.Context, st *State, raw interface{}, ctxObj map[string]interface{}, where string) (interface{}, string, error) {
+	next := st.Next
+
+	switch st.Type {
+	case TypeSucceed:
+		out, err := s.filterIO(st, raw, ctxObj)
+		return out, "", err
+
+	case TypeFail:
+		return nil, "", &Error{Name: st.Error, Cause: st.Cause}
+
+	case TypeChoice:
+		input, err := selectPath(st.InputPath.orDefault(), raw, ctxObj)
+		if err != nil {
+			return nil, "", err
+		}
+		next = st.Default
+		for i := range st.Choices {
+			ok, err := st.Choices[i].evaluate(input, ctxObj)
+			if err != nil {
+				return nil, "", err
+			}
+			if ok {
+				next = st.Choices[i].Next
+				break
+			}
+		}
+		if next == "" {
+			return nil, "", &Error{Name: "States.NoChoiceMatched", Cause: where}
+		}
+		out, err := selectPath(st.OutputPath.orDefault(), input, ctxObj)
+		return out, next, err
+
+	case TypeWait:
+		input, err := selectPath(st.InputPath.orDefault(), raw, ctxObj)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := s.wait(ctx, st, input, ctxObj); err != nil {
+			return nil, "", err
+		}
+		out, err := selectPath(st.OutputPath.orDefault(), input, ctxObj)
+		return out, endOr(st, next), err
+	}
+
+	// Task, Pass, Map, and Parallel share the full input/output pipeline.
+	input, err := selectPath(st.InputPath.orDefault(), raw, ctxObj)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var result interface{}
+	switch st.Type {
+	case TypePass:
+		result, err = s.passResult(st, input, ctxObj)
+	case TypeTask:
+		result, err = s.runTaskWithRetry(ctx, st, input, ctxObj)
+	case TypeMap:
+		result, err = s.runMap(ctx, st, input, ctxObj, where)
+	case TypeParallel:
+		result, err = s.runParallel(ctx, st, input, ctxObj, where)
+	}
+
+	if err == nil && len(st.ResultSelector) > 0 {
+		result, err = renderRaw(st.ResultSelector, result, ctxObj)
+	}
+	if err != nil {
+		return s.catch(st, raw, err)
+	}
+
+	merged, err := applyResultPath(raw, result, st.ResultPath.orDefault())
+	if err != nil {
+		return nil, "", err
+	}
+	out, err := selectPath(st.OutputPath.orDefault(), merged, ctxObj)
+	return out, endOr(st, next), err
+}
+
+func endOr(st *State, next string) string {
+	if st.End {
+		return ""
+	}
+	return next
+}
+
+// filterIO applies only InputPath and OutputPath (Succeed states).
+func (s *Simulator) filterIO(st *State, raw interface{}, ctxObj map[string]interface{}) (interface{}, error) {
+	input, err := selectPath(st.InputPath.orDefault(), raw, ctxObj)
+	if err != nil {
+		return nil, err
+	}
+	return selectPath(st.OutputPath.orDefault(), input, ctxObj)
+}
+
+func (s *Simulator) passResult(st *State, input interface{}, ctxObj map[string]interface{}) (interface{}, error) {
+	if len(st.Result) > 0 {
+		var v interface{}
+		if err := json.Unmarshal(st.Result, &v); err != nil {
+			return nil, runtimeError("invalid Result: %v", err)
+		}
+		return v, nil
+	}
+	if len(st.Parameters) > 0 {
+		return renderRaw(st.Parameters, input, ctxObj)
+	}
+	return input, nil
+}
+
+// catch routes a state error to the first matching Catcher, placing the
+// error object into the raw state input at the catcher's ResultPath.
+func (s *Simulator) catch(st *State, raw interface{}, err error) (interface{}, string, error) {
+	var aslErr *Error
+	if !errors.As(err, &aslErr) {
+		return nil, "", err
+	}
+	for _, c := range st.Catch {
+		if !matchesError(c.ErrorEquals, aslErr) {
+			continue
+		}
+		errObj := map[string]interface{}{"Error": aslErr.Name, "Cause": aslErr.Cause}
+		out, perr := applyResultPath(raw, errObj, c.ResultPath.orDefault())
+		if perr != nil {
+			return nil, "", perr
+		}
+		return out, c.Next, nil
+	}
+	return nil, "", err
+}
+
+func matchesError(names []string, err *Error) bool {
+	for _, n := range names {
+		switch {
+		case n == "States.ALL", n == err.Name:
+			return true
+		case n == "States.TaskFailed" && err.Name != "States.Timeout" && !strings.HasPrefix(err.Name, "States.Runtime"):
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Simulator) runTaskWithRetry(ctx context.Context, st *State, input interface{}, ctxObj map[string]interface{}) (interface{}, error) {
+	attempts := make([]int, len(st.Retry))
+	for retryCount := 0; ; retryCount++ {
+		result, err := s.runTask(ctx, st, input, withRetryCount(ctxObj, retryCount))
+		if err == nil {
+			return result, nil
+		}
+		var aslErr *Error
+		if !errors.As(err, &aslErr) {
+			return nil, err
+		}
+
+		retried := false
+		for i, r := range st.Retry {
+			if !matchesError(r.ErrorEquals, aslErr) {
+				continue
+			}
+			maxAttempts := 3
+			if r.MaxAttempts != nil {
+				maxAttempts = *r.MaxAttempts
+			}
+			if attempts[i] >= maxAttempts {
+				break
+			}
+			if err := s.sleep(ctx, retryDelay(r, attempts[i])); err != nil {
+				return nil, err
+			}
+			attempts[i]++
+			retried = true
+			break
+		}
+		if !retried {
+			return nil, err
+		}
+	}
+}
+
+func retryDelay(r Retrier, attempt int) time.Duration {
+	interval, backoff := 1.0, 2.0
+	if r.IntervalSeconds != nil {
+		interval = *r.IntervalSeconds
+	}
+	if r.BackoffRate != nil {
+		backoff = *r.BackoffRate
+	}
+	return time.Duration(interval * math.Pow(backoff, float64(attempt)) * float64(time.Second))
+}
+
+// runTask renders the task parameters and dispatches to the Invoker.
+func (s *Simulator) runTask(ctx context.Context, st *State, input interface{}, ctxObj map[string]interface{}) (interface{}, error) {
+	params := input
+	if len(st.Parameters) > 0 {
+		var err error
+		if params, err = renderRaw(st.Parameters, input, ctxObj); err != nil {
+			return nil, err
+		}
+	}
+
+	target, payload := st.Resource, params
+	wrapPayload := false
+	switch {
+	case st.Resource == lambdaInvokeResource:
+		p, ok := params.(map[string]interface{})
+		if !ok {
+			return nil, runtimeError("lambda:invoke Parameters must be an object")
+		}
+		fn, _ := p["FunctionName"].(string)
+		if fn == "" {
+			return nil, runtimeError("lambda:invoke Parameters has no FunctionName")
+		}
+		target = fn
+		if pl, ok := p["Payload"]; ok {
+			payload = pl
+		} else {
+			payload = input
+		}
+		wrapPayload = true
+	}
+	// A plain Lambda ARN resource (or any other integration) gets the rendered
+	// parameters as its payload and returns the raw result.
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, runtimeError("encode task payload: %v", err)
+	}
+
+	out, err := s.invoker.Invoke(ctx, target, body)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var aslErr *Error
+		if errors.As(err, &aslErr) {
+			return nil, aslErr
+		}
+		return nil, &Error{Name: "States.TaskFailed", Cause: err.Error()}
+	}
+
+	var result interface{}
+	if len(out) > 0 {
+		if err := json.Unmarshal(out, &result); err != nil {
+			return nil, runtimeError("task %s returned invalid JSON: %v", target, err)
+		}
+	}
+	if wrapPayload {
+		return map[string]interface{}{
+			"ExecutedVersion": "$LATEST",
+			"Payload":         result,
+			"StatusCode":      float64(200),
+		}, nil
+	}
+	return result, nil
+}
+
+// runMap runs the item processor for each element of ItemsPath, honoring
+// MaxConcurrency (0 = unbounded). Results keep item order.
+func (s *Simulator) runMap(ctx context.Context, st *State, input interface{}, ctxObj map[string]interface{}, where string) (interface{}, error) {
+	itemsVal, err := selectPath(st.ItemsPath.orDefault(), input, ctxObj)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := itemsVal.([]interface{})
+	if !ok {
+		return nil, runtimeError("%s: ItemsPath did not resolve to an array", where)
+	}
+
+	selector := st.ItemSelector
+	if len(selector) == 0 {
+		selector = st.Parameters
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	limit := st.MaxConcurrency
+	if limit <= 0 || limit > len(items) {
+		limit = len(items)
+	}
+	sem := make(chan struct{}, max(limit, 1))
+	results := make([]interface{}, len(items))
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for i, item := range items {
+		itemCtx := copyObject(ctxObj)
+		itemCtx["Map"] = map[string]interface{}{
+			"Item": map[string]interface{}{"Index": float64(i), "Value": item},
+		}
+
+		itemInput := item
+		if len(selector) > 0 {
+			if itemInput, err = renderRaw(selector, input, itemCtx); err != nil {
+				errOnce.Do(func() { firstErr = err; cancel() })
+				break
+			}
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, itemInput interface{}, itemCtx map[string]interface{}) {
+			defer func() { <-sem; wg.Done() }()
+			out, err := s.runMachine(ctx, st.Processor(), itemInput, itemCtx, fmt.Sprintf("%s[%d]/", where, i))
+			if err != nil {
+				errOnce.Do(func() { firstErr = err; cancel() })
+				return
+			}
+			results[i] = out
+		}(i, itemInput, itemCtx)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return results, nil
+}
+
+// runParallel runs every branch against the same input and collects their
+// outputs in branch order.
+func (s *Simulator) runParallel(ctx context.Context, st *State, input interface{}, ctxObj map[string]interface{}, where string) (interface{}, error) {
+	branchInput := input
+	if len(st.Parameters) > 0 {
+		var err error
+		if branchInput, err = renderRaw(st.Parameters, input, ctxObj); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]interface{}, len(st.Branches))
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for i, branch := range st.Branches {
+		wg.Add(1)
+		go func(i int, branch *Definition) {
+			defer wg.Done()
+			out, err := s.runMachine(ctx, branch, branchInput, ctxObj, fmt.Sprintf("%s[%d]/", where, i))
+			if err != nil {
+				errOnce.Do(func() { firstErr = err; cancel() })
+				return
+			}
+			results[i] = out
+		}(i, branch)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return results, nil
+}
+
+func (s *Simulator) wait(ctx context.Context, st *State, input interface{}, ctxObj map[string]interface{}) error {
+	var d time.Duration
+	switch {
+	case st.Seconds != nil:
+		d = time.Duration(*st.Seconds * float64(time.Second))
+	case st.SecondsPath != "":
+		v, err := resolvePath(st.SecondsPath, input, ctxObj)
+		if err != nil {
+			return err
+		}
+		secs, ok := v.(float64)
+		if !ok {
+			return runtimeError("SecondsPath %q is not a number", st.SecondsPath)
+		}
+		d = time.Duration(secs * float64(time.Second))
+	case st.Timestamp != "", st.TimestampPath != "":
+		ts := st.Timestamp
+		if st.TimestampPath != "" {
+			v, err := resolvePath(st.TimestampPath, input, ctxObj)
+			if err != nil {
+				return err
+			}
+			ts, _ = v.(string)
+		}
+		t, ok := asTimestamp(ts)
+		if !ok {
+			return runtimeError("invalid Wait timestamp %q", ts)
+		}
+		d = time.Until(t)
+	}
+	return s.sleep(ctx, d)
+}
+
+func (s *Simulator) sleep(ctx context.Context, d time.Duration) error {
+	if !s.RealWaits || d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// selectPath applies InputPath/OutputPath/ItemsPath. An explicit null path
+// yields an empty object.
+func selectPath(path string, data, ctxObj interface{}) (interface{}, error) {
+	switch path {
+	case "":
+		return map[string]interface{}{}, nil
+	case "$":
+		return data, nil
+	}
+	return resolvePath(path, data, ctxObj)
+}
+
+func renderRaw(tmpl json.RawMessage, data, ctxObj interface{}) (interface{}, error) {
+	var t interface{}
+	if err := json.Unmarshal(tmpl, &t); err != nil {
+		return nil, runtimeError("invalid payload template: %v", err)
+	}
+	return renderTemplate(t, data, ctxObj)
+}
+
+func withState(ctxObj map[string]interface{}, name string) map[string]interface{} {
+	out := copyObject(ctxObj)
+	out["State"] = map[string]interface{}{
+		"Name":        name,
+		"EnteredTime": time.Now().UTC().Format(time.RFC3339Nano),
+		"RetryCount":  float64(0),
+	}
+	return out
+}
+
+func withRetryCount(ctxObj map[string]interface{}, n int) map[string]interface{} {
+	if n == 0 {
+		return ctxObj
+	}
+	out := copyObject(ctxObj)
+	state := copyObject(ctxObj["State"].(map[string]interface{}))
+	state["RetryCount"] = float64(n)
+	out["State"] = state
+	return out
+}
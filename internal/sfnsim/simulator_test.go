@@ -0,0 +1,232 @@
+package sfnsim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// triageDefinition mirrors the shape of the triage pipeline: prepare, then
+// poll check-processing until all files are processed, then run.
+const triageDefinition = `{
+  "StartAt": "Prepare",
+  "States": {
+    "Prepare": {
+      "Type": "Task",
+      "Resource": "arn:aws:states:::lambda:invoke",
+      "Parameters": {
+        "FunctionName": "arn:aws:lambda:us-east-1:123:function:TriageProcessor",
+        "Payload": {"type": "triage-prepare", "sessionId.$": "$.sessionId"}
+      },
+      "OutputPath": "$.Payload",
+      "Next": "Check"
+    },
+    "Check": {
+      "Type": "Task",
+      "Resource": "arn:aws:states:::lambda:invoke",
+      "Parameters": {
+        "FunctionName": "arn:aws:lambda:us-east-1:123:function:TriageProcessor",
+        "Payload": {"type": "triage-check-processing", "sessionId.$": "$.sessionId"}
+      },
+      "ResultSelector": {"allProcessed.$": "$.Payload.allProcessed"},
+      "ResultPath": "$.check",
+      "Next": "Ready?"
+    },
+    "Ready?": {
+      "Type": "Choice",
+      "Choices": [{"Variable": "$.check.allProcessed", "BooleanEquals": true, "Next": "Run"}],
+      "Default": "Wait"
+    },
+    "Wait": {"Type": "Wait", "Seconds": 5, "Next": "Check"},
+    "Run": {
+      "Type": "Task",
+      "Resource": "arn:aws:states:::lambda:invoke",
+      "Parameters": {
+        "FunctionName": "arn:aws:lambda:us-east-1:123:function:TriageProcessor",
+        "Payload": {"type": "triage-run", "sessionId.$": "$.sessionId", "label.$": "States.Format('run-{}', $.sessionId)"}
+      },
+      "OutputPath": "$.Payload",
+      "End": true
+    }
+  }
+}`
+
+func TestSimulatorPollingLoop(t *testing.T) {
+	def, err := Parse([]byte(triageDefinition))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	var calls []string
+	checks := 0
+	inv := InvokerFunc(func(ctx context.Context, target string, payload json.RawMessage) (json.RawMessage, error) {
+		if target != "arn:aws:lambda:us-east-1:123:function:TriageProcessor" {
+			t.Errorf("unexpected target %q", target)
+		}
+		var ev map[string]string
+		if err := json.Unmarshal(payload, &ev); err != nil {
+			t.Fatalf("payload: %v", err)
+		}
+		calls = append(calls, ev["type"])
+		switch ev["type"] {
+		case "triage-prepare":
+			return json.RawMessage(`{"sessionId":"` + ev["sessionId"] + `","fileCount":3}`), nil
+		case "triage-check-processing":
+			checks++
+			return json.RawMessage(`{"allProcessed":` + map[bool]string{true: "true", false: "false"}[checks >= 3] + `}`), nil
+		case "triage-run":
+			return json.RawMessage(`{"label":"` + ev["label"] + `"}`), nil
+		}
+		return nil, errors.New("unexpected event")
+	})
+
+	out, err := New(inv).Run(context.Background(), "TriagePipeline", def, json.RawMessage(`{"sessionId":"s1"}`))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if string(out) != `{"label":"run-s1"}` {
+		t.Errorf("unexpected output %s", out)
+	}
+	want := "triage-prepare,triage-check-processing,triage-check-processing,triage-check-processing,triage-run"
+	if got := strings.Join(calls, ","); got != want {
+		t.Errorf("calls = %s, want %s", got, want)
+	}
+}
+
+func TestSimulatorMaxTransitions(t *testing.T) {
+	def, err := Parse([]byte(triageDefinition))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	inv := InvokerFunc(func(ctx context.Context, target string, payload json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(`{"sessionId":"s1","allProcessed":false}`), nil
+	})
+
+	sim := New(inv)
+	sim.MaxTransitions = 20
+	_, err = sim.Run(context.Background(), "TriagePipeline", def, json.RawMessage(`{"sessionId":"s1"}`))
+	var aslErr *Error
+	if !errors.As(err, &aslErr) || aslErr.Name != "States.Runtime" {
+		t.Fatalf("expected States.Runtime error, got %v", err)
+	}
+}
+
+func TestSimulatorMapWithItemSelector(t *testing.T) {
+	def, err := Parse([]byte(`{
+	  "StartAt": "Thumbnails",
+	  "States": {
+	    "Thumbnails": {
+	      "Type": "Map",
+	      "ItemsPath": "$.imageKeys",
+	      "MaxConcurrency": 2,
+	      "ItemSelector": {"sessionId.$": "$.sessionId", "key.$": "$$.Map.Item.Value", "index.$": "$$.Map.Item.Index"},
+	      "ItemProcessor": {
+	        "StartAt": "Thumb",
+	        "States": {"Thumb": {"Type": "Task", "Resource": "arn:aws:lambda:us-east-1:123:function:Thumbnail", "End": true}}
+	      },
+	      "ResultPath": "$.thumbnails",
+	      "End": true
+	    }
+	  }
+	}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	var mu sync.Mutex
+	var steps []string
+	sim := New(InvokerFunc(func(ctx context.Context, target string, payload json.RawMessage) (json.RawMessage, error) {
+		var ev struct {
+			SessionID string  `json:"sessionId"`
+			Key       string  `json:"key"`
+			Index     float64 `json:"index"`
+		}
+		_ = json.Unmarshal(payload, &ev)
+		return json.Marshal(map[string]interface{}{"thumb": ev.SessionID + "/thumbnails/" + ev.Key, "index": ev.Index})
+	}))
+	sim.OnStep = func(s Step) {
+		mu.Lock()
+		steps = append(steps, s.Path)
+		mu.Unlock()
+	}
+
+	out, err := sim.Run(context.Background(), "Selection", def, json.RawMessage(`{"sessionId":"s1","imageKeys":["a.jpg","b.jpg","c.jpg"]}`))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var got struct {
+		SessionID  string `json:"sessionId"`
+		Thumbnails []struct {
+			Thumb string  `json:"thumb"`
+			Index float64 `json:"index"`
+		} `json:"thumbnails"`
+	}
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("output: %v", err)
+	}
+	if got.SessionID != "s1" || len(got.Thumbnails) != 3 {
+		t.Fatalf("unexpected output %s", out)
+	}
+	if got.Thumbnails[2].Thumb != "s1/thumbnails/c.jpg" || got.Thumbnails[2].Index != 2 {
+		t.Errorf("results out of order: %s", out)
+	}
+	if len(steps) != 4 {
+		t.Errorf("expected 3 iteration steps + 1 Map step, got %v", steps)
+	}
+}
+
+func TestSimulatorRetryAndCatch(t *testing.T) {
+	def, err := Parse([]byte(`{
+	  "StartAt": "Enhance",
+	  "States": {
+	    "Enhance": {
+	      "Type": "Task",
+	      "Resource": "arn:aws:lambda:us-east-1:123:function:Enhance",
+	      "Retry": [{"ErrorEquals": ["Throttled"], "MaxAttempts": 2}],
+	      "Catch": [{"ErrorEquals": ["States.ALL"], "ResultPath": "$.error", "Next": "Failed"}],
+	      "End": true
+	    },
+	    "Failed": {"Type": "Pass", "End": true}
+	  }
+	}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	attempts := 0
+	sim := New(InvokerFunc(func(ctx context.Context, target string, payload json.RawMessage) (json.RawMessage, error) {
+		attempts++
+		return nil, &Error{Name: "Throttled", Cause: "slow down"}
+	}))
+	out, err := sim.Run(context.Background(), "Enhancement", def, json.RawMessage(`{"jobId":"e1"}`))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 1 attempt + 2 retries, got %d", attempts)
+	}
+	if string(out) != `{"error":{"Cause":"slow down","Error":"Throttled"},"jobId":"e1"}` {
+		t.Errorf("unexpected output %s", out)
+	}
+}
+
+func TestParseRejectsDanglingTransition(t *testing.T) {
+	_, err := Parse([]byte(`{"StartAt":"A","States":{"A":{"Type":"Pass","Next":"Missing"}}}`))
+	if err == nil || !strings.Contains(err.Error(), "Missing") {
+		t.Fatalf("expected dangling Next error, got %v", err)
+	}
+}
+
+func TestResolvePathMissingField(t *testing.T) {
+	data := map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{"x"}}}
+	if v, err := resolvePath("$.a.b[0]", data, nil); err != nil || v != "x" {
+		t.Errorf("resolvePath = %v, %v", v, err)
+	}
+	if _, err := resolvePath("$.a.c", data, nil); err == nil {
+		t.Error("expected error for missing field")
+	}
+}
@@ -0,0 +1,69 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Brand kits (DDR-149) ---
+
+const skBrandKit = "BRANDKIT"
+
+// BrandKit is a user's brand identity (DynamoDB PK = USER#{sub},
+// SK = BRANDKIT). Like watermarks it has no TTL. The logo PNG is stored in
+// the brand assets bucket under LogoKey; the palette holds "#rrggbb" colors,
+// the first of which colors text overlays.
+type BrandKit struct {
+	LogoKey   string   `json:"logoKey,omitempty" dynamodbav:"logoKey,omitempty"`
+	Palette   []string `json:"palette,omitempty" dynamodbav:"palette,omitempty"`
+	Hashtags  []string `json:"hashtags,omitempty" dynamodbav:"hashtags,omitempty"`
+	SignOff   string   `json:"signOff,omitempty" dynamodbav:"signOff,omitempty"`
+	UpdatedAt int64    `json:"updatedAt,omitempty" dynamodbav:"updatedAt,omitempty"`
+}
+
+// PutBrandKit creates or replaces owner's brand kit. It bypasses putItem so
+// the record carries no expiresAt attribute.
+func (s *DynamoStore) PutBrandKit(ctx context.Context, owner string, k *BrandKit) error {
+	item, err := attributevalue.MarshalMap(k)
+	if err != nil {
+		return fmt.Errorf("marshal brand kit: %w", err)
+	}
+	item["PK"] = &types.AttributeValueMemberS{Value: userPK(owner)}
+	item["SK"] = &types.AttributeValueMemberS{Value: skBrandKit}
+
+	if _, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &s.tableName,
+		Item:      item,
+	}); err != nil {
+		return fmt.Errorf("put brand kit: %w", err)
+	}
+	log.Debug().Bool("logo", k.LogoKey != "").Int("colors", len(k.Palette)).Int("hashtags", len(k.Hashtags)).Msg("Brand kit persisted")
+	return nil
+}
+
+// GetBrandKit returns owner's brand kit, or nil, nil if none is set.
+func (s *DynamoStore) GetBrandKit(ctx context.Context, owner string) (*BrandKit, error) {
+	var k BrandKit
+	found, err := s.getItem(ctx, userPK(owner), skBrandKit, &k)
+	if err != nil {
+		return nil, fmt.Errorf("get brand kit: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &k, nil
+}
+
+// DeleteBrandKit removes owner's brand kit. Deleting a missing kit is not an
+// error.
+func (s *DynamoStore) DeleteBrandKit(ctx context.Context, owner string) error {
+	if err := s.deleteItem(ctx, userPK(owner), skBrandKit); err != nil {
+		return fmt.Errorf("delete brand kit: %w", err)
+	}
+	return nil
+}
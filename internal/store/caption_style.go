@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Caption style (DDR-159) ---
+
+const skCaptionStyle = "CAPTION_STYLE"
+
+// Emoji densities and formality levels of a CaptionStyle. The empty string
+// leaves the choice to the model.
+const (
+	EmojiNone  = "none"
+	EmojiLight = "light"
+	EmojiHeavy = "heavy"
+
+	FormalityCasual  = "casual"
+	FormalityNeutral = "neutral"
+	FormalityFormal  = "formal"
+)
+
+// CaptionStyle is a user's caption persona (DDR-159) (DynamoDB PK =
+// USER#{sub}, SK = CAPTION_STYLE), followed by every caption generated for
+// the user's sessions. Like brand kits it has no TTL. Language is a name or
+// tag such as "Japanese" or "zh-TW"; empty fields leave the choice to the
+// model.
+type CaptionStyle struct {
+	EmojiDensity string `json:"emojiDensity,omitempty" dynamodbav:"emojiDensity,omitempty"`
+	Language     string `json:"language,omitempty" dynamodbav:"language,omitempty"`
+	Formality    string `json:"formality,omitempty" dynamodbav:"formality,omitempty"`
+	SignOff      string `json:"signOff,omitempty" dynamodbav:"signOff,omitempty"`
+	UpdatedAt    int64  `json:"updatedAt,omitempty" dynamodbav:"updatedAt,omitempty"`
+}
+
+// PutCaptionStyle creates or replaces owner's caption style. It bypasses
+// putItem so the record carries no expiresAt attribute.
+func (s *DynamoStore) PutCaptionStyle(ctx context.Context, owner string, c *CaptionStyle) error {
+	item, err := attributevalue.MarshalMap(c)
+	if err != nil {
+		return fmt.Errorf("marshal caption style: %w", err)
+	}
+	item["PK"] = &types.AttributeValueMemberS{Value: userPK(owner)}
+	item["SK"] = &types.AttributeValueMemberS{Value: skCaptionStyle}
+
+	if _, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &s.tableName,
+		Item:      item,
+	}); err != nil {
+		return fmt.Errorf("put caption style: %w", err)
+	}
+	log.Debug().Str("emojiDensity", c.EmojiDensity).Str("language", c.Language).Str("formality", c.Formality).Msg("Caption style persisted")
+	return nil
+}
+
+// GetCaptionStyle returns owner's caption style, or nil, nil if none is set.
+func (s *DynamoStore) GetCaptionStyle(ctx context.Context, owner string) (*CaptionStyle, error) {
+	var c CaptionStyle
+	found, err := s.getItem(ctx, userPK(owner), skCaptionStyle, &c)
+	if err != nil {
+		return nil, fmt.Errorf("get caption style: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &c, nil
+}
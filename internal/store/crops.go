@@ -0,0 +1,83 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// --- Subject-aware crop suggestions (DDR-130) ---
+
+// CropJob holds crop suggestions for a set of photos
+// (DynamoDB SK = CROP#{jobId}).
+type CropJob struct {
+	ID         string           `json:"id" dynamodbav:"-"`
+	SessionID  string           `json:"-" dynamodbav:"-"`
+	Status     string           `json:"status" dynamodbav:"status"`
+	Items      []CropSuggestion `json:"items" dynamodbav:"items"`
+	Error      string           `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	RetryCount int              `json:"retryCount,omitempty" dynamodbav:"retryCount,omitempty"` // DDR-089
+}
+
+// CropSuggestion is the set of proposed crops for one photo. Width and Height
+// are the photo's displayed dimensions (EXIF orientation applied), which all
+// rectangles are relative to. Subject is empty when Gemini found no clear
+// subject and the crops are centred.
+type CropSuggestion struct {
+	Key     string     `json:"key" dynamodbav:"key"`
+	Width   int        `json:"width,omitempty" dynamodbav:"width,omitempty"`
+	Height  int        `json:"height,omitempty" dynamodbav:"height,omitempty"`
+	Subject *CropRect  `json:"subject,omitempty" dynamodbav:"subject,omitempty"`
+	Crops   []CropRect `json:"crops,omitempty" dynamodbav:"crops,omitempty"`
+	Error   string     `json:"error,omitempty" dynamodbav:"error,omitempty"`
+}
+
+// CropRect is a rectangle in displayed pixels. Aspect names the crop ratio
+// ("1:1", "4:5", "9:16"); Label names a detected subject.
+type CropRect struct {
+	Aspect string `json:"aspect,omitempty" dynamodbav:"aspect,omitempty"`
+	Label  string `json:"label,omitempty" dynamodbav:"label,omitempty"`
+	X      int    `json:"x" dynamodbav:"x"`
+	Y      int    `json:"y" dynamodbav:"y"`
+	W      int    `json:"w" dynamodbav:"w"`
+	H      int    `json:"h" dynamodbav:"h"`
+}
+
+// Crop returns the suggested crop of the given aspect for key, if any.
+func (j *CropJob) Crop(key, aspect string) (CropSuggestion, CropRect, bool) {
+	for _, item := range j.Items {
+		if item.Key != key {
+			continue
+		}
+		for _, c := range item.Crops {
+			if c.Aspect == aspect {
+				return item, c, true
+			}
+		}
+	}
+	return CropSuggestion{}, CropRect{}, false
+}
+
+func (s *DynamoStore) PutCropJob(ctx context.Context, sessionID string, job *CropJob) error {
+	if err := s.putJobItem(ctx, sessionPK(sessionID), skCrop+job.ID, job); err != nil {
+		return fmt.Errorf("put crop job %s/%s: %w", sessionID, job.ID, err)
+	}
+	s.touchSessionJob(ctx, sessionID, skCrop+job.ID, sessionJobUpdate{Status: job.Status, Error: &job.Error})
+	log.Debug().Str("sessionId", sessionID).Str("jobId", job.ID).Str("status", job.Status).Int("items", len(job.Items)).Msg("Crop job persisted")
+	return nil
+}
+
+func (s *DynamoStore) GetCropJob(ctx context.Context, sessionID, jobID string) (*CropJob, error) {
+	var job CropJob
+	found, err := s.getItem(ctx, sessionPK(sessionID), skCrop+jobID, &job)
+	if err != nil {
+		return nil, fmt.Errorf("get crop job %s/%s: %w", sessionID, jobID, err)
+	}
+	if !found {
+		return nil, nil
+	}
+	job.ID = jobID
+	job.SessionID = sessionID
+	return &job, nil
+}
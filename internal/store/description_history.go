@@ -0,0 +1,159 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Description feedback history (DDR-152) ---
+//
+// Each feedback round is its own item under the job's sort key:
+//
+//	DESC#{jobId}                job record, with feedbackRounds = n
+//	DESC#{jobId}#ROUND#0001     round 1
+//	DESC#{jobId}#ROUND#000n     round n
+//
+// so the job record stays small however many rounds the user asks for.
+// Only the most recent rounds keep the model's raw response; older rounds
+// keep the user's feedback only.
+
+const (
+	// skRoundInfix separates a description job ID from its round number.
+	skRoundInfix = "#ROUND#"
+
+	// DefaultRawResponseRounds is how many recent rounds keep their raw
+	// model response when DESCRIPTION_RAW_RESPONSE_ROUNDS is unset.
+	DefaultRawResponseRounds = 5
+)
+
+// RawResponseRoundsFromEnv reads DESCRIPTION_RAW_RESPONSE_ROUNDS, the number
+// of recent feedback rounds that keep their raw model response. Zero keeps
+// them all.
+func RawResponseRoundsFromEnv() int {
+	if v, err := strconv.Atoi(os.Getenv("DESCRIPTION_RAW_RESPONSE_ROUNDS")); err == nil && v >= 0 {
+		return v
+	}
+	return DefaultRawResponseRounds
+}
+
+// descriptionRound is the stored form of one feedback round.
+type descriptionRound struct {
+	Round         int    `dynamodbav:"round"`
+	UserFeedback  string `dynamodbav:"userFeedback"`
+	ModelResponse string `dynamodbav:"modelResponse,omitempty"`
+}
+
+func descRoundSK(jobID string, round int) string {
+	return fmt.Sprintf("%s%s%s%04d", skDesc, jobID, skRoundInfix, round)
+}
+
+// isDescRoundSK reports whether sk is a feedback round rather than a job.
+func isDescRoundSK(sk string) bool {
+	return strings.HasPrefix(sk, skDesc) && strings.Contains(sk, skRoundInfix)
+}
+
+// AppendDescriptionRound stores feedback round n of a description job. When
+// keepRaw > 0, the raw response of round n-keepRaw is dropped so that only
+// the latest keepRaw rounds carry one.
+func (s *DynamoStore) AppendDescriptionRound(ctx context.Context, sessionID, jobID string, round int, entry ConversationEntry, keepRaw int) error {
+	item := descriptionRound{Round: round, UserFeedback: entry.UserFeedback, ModelResponse: entry.ModelResponse}
+	if err := s.putItem(ctx, sessionPK(sessionID), descRoundSK(jobID, round), item); err != nil {
+		return fmt.Errorf("put description round %s/%s#%d: %w", sessionID, jobID, round, err)
+	}
+
+	if keepRaw > 0 && round > keepRaw {
+		old := round - keepRaw
+		_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName: aws.String(s.tableName),
+			Key: map[string]types.AttributeValue{
+				"PK": &types.AttributeValueMemberS{Value: sessionPK(sessionID)},
+				"SK": &types.AttributeValueMemberS{Value: descRoundSK(jobID, old)},
+			},
+			UpdateExpression:    aws.String("REMOVE modelResponse"),
+			ConditionExpression: aws.String("attribute_exists(PK)"),
+		})
+		var ccf *types.ConditionalCheckFailedException
+		if err != nil && !errors.As(err, &ccf) {
+			return fmt.Errorf("trim description round %s/%s#%d: %w", sessionID, jobID, old, err)
+		}
+	}
+
+	log.Debug().
+		Str("sessionId", sessionID).
+		Str("jobId", jobID).
+		Int("round", round).
+		Int("keepRaw", keepRaw).
+		Msg("Description round persisted")
+	return nil
+}
+
+// GetDescriptionHistory loads a description job's feedback rounds in order.
+// Rounds stored inline on jobs written before DDR-152 come first. Rounds
+// past the job's feedbackRounds, left over from a retried job, are ignored.
+// Returns nil, nil if the job does not exist.
+func (s *DynamoStore) GetDescriptionHistory(ctx context.Context, sessionID, jobID string) ([]ConversationEntry, error) {
+	jobSK := skDesc + jobID
+	items, err := s.queryBySKPrefix(ctx, sessionID, jobSK)
+	if err != nil {
+		return nil, fmt.Errorf("get description history %s/%s: %w", sessionID, jobID, err)
+	}
+
+	var job *DescriptionJob
+	var rounds []descriptionRound
+	for _, item := range items {
+		skAttr, ok := item["SK"].(*types.AttributeValueMemberS)
+		if !ok {
+			continue
+		}
+		switch sk := skAttr.Value; {
+		case sk == jobSK:
+			job = &DescriptionJob{}
+			if err := attributevalue.UnmarshalMap(item, job); err != nil {
+				return nil, fmt.Errorf("unmarshal description job %s/%s: %w", sessionID, jobID, err)
+			}
+		case strings.HasPrefix(sk, jobSK+skRoundInfix):
+			var r descriptionRound
+			if err := attributevalue.UnmarshalMap(item, &r); err != nil {
+				return nil, fmt.Errorf("unmarshal description round %s/%s: %w", sessionID, sk, err)
+			}
+			rounds = append(rounds, r)
+		}
+	}
+	if job == nil {
+		return nil, nil
+	}
+
+	history := assembleHistory(job.History, rounds, job.Rounds())
+	log.Debug().
+		Str("sessionId", sessionID).
+		Str("jobId", jobID).
+		Int("inline", len(job.History)).
+		Int("rounds", len(history)).
+		Msg("Description history loaded")
+	return history, nil
+}
+
+// assembleHistory orders the inline and per-item rounds of a job with n
+// feedback rounds. Inline entries are rounds 1..len(inline).
+func assembleHistory(inline []ConversationEntry, rounds []descriptionRound, n int) []ConversationEntry {
+	sort.Slice(rounds, func(i, j int) bool { return rounds[i].Round < rounds[j].Round })
+	history := append([]ConversationEntry(nil), inline...)
+	for _, r := range rounds {
+		if r.Round <= len(inline) || r.Round > n {
+			continue
+		}
+		history = append(history, ConversationEntry{UserFeedback: r.UserFeedback, ModelResponse: r.ModelResponse})
+	}
+	return history
+}
@@ -0,0 +1,49 @@
+package store
+
+import "testing"
+
+func TestAssembleHistory(t *testing.T) {
+	inline := []ConversationEntry{{UserFeedback: "shorter", ModelResponse: "r0"}}
+	rounds := []descriptionRound{
+		{Round: 3, UserFeedback: "add emoji", ModelResponse: "r2"},
+		{Round: 2, UserFeedback: "more fun"},
+		{Round: 1, UserFeedback: "duplicate of inline"},
+		{Round: 4, UserFeedback: "stale round from a retried job"},
+	}
+
+	got := assembleHistory(inline, rounds, 3)
+	want := []ConversationEntry{
+		{UserFeedback: "shorter", ModelResponse: "r0"},
+		{UserFeedback: "more fun"},
+		{UserFeedback: "add emoji", ModelResponse: "r2"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d rounds, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("round %d = %+v, want %+v", i+1, got[i], want[i])
+		}
+	}
+}
+
+func TestDescriptionRoundSK(t *testing.T) {
+	sk := descRoundSK("desc-abc", 12)
+	if sk != "DESC#desc-abc#ROUND#0012" {
+		t.Errorf("descRoundSK = %s", sk)
+	}
+	if !isDescRoundSK(sk) || isDescRoundSK("DESC#desc-abc") || isDescRoundSK("PUBLISH#pub-1#ROUND#0001") {
+		t.Error("isDescRoundSK misclassified a sort key")
+	}
+}
+
+func TestDescriptionJobRounds(t *testing.T) {
+	legacy := DescriptionJob{History: []ConversationEntry{{}, {}}}
+	if n := legacy.Rounds(); n != 2 {
+		t.Errorf("legacy job rounds = %d, want 2", n)
+	}
+	current := DescriptionJob{History: []ConversationEntry{{}, {}}, FeedbackRounds: 5}
+	if n := current.Rounds(); n != 5 {
+		t.Errorf("job rounds = %d, want 5", n)
+	}
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Download prewarming (DDR-101) ---
+
+// DownloadFingerprint identifies the bundles a download job produces: the
+// same keys (in any order) under the same group label and metadata policy
+// (DDR-135) yield the same ZIPs.
+func DownloadFingerprint(keys []string, groupLabel, metadataPolicy string) string {
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+
+	h := sha256.New()
+	h.Write([]byte(groupLabel))
+	for _, key := range sorted {
+		h.Write([]byte{0})
+		h.Write([]byte(key))
+	}
+	h.Write([]byte{1})
+	h.Write([]byte(metadataPolicy))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// FindDownloadJob returns a pending, processing, or complete download job in
+// the session with the given fingerprint, or nil if there is none. Errored
+// jobs are never returned, so a failed prewarm does not block a retry.
+func (s *DynamoStore) FindDownloadJob(ctx context.Context, sessionID, fingerprint string) (*DownloadJob, error) {
+	items, err := s.queryBySKPrefix(ctx, sessionID, skDownload)
+	if err != nil {
+		return nil, fmt.Errorf("find download job for %s: %w", sessionID, err)
+	}
+
+	for _, item := range items {
+		var job DownloadJob
+		if err := attributevalue.UnmarshalMap(item, &job); err != nil {
+			log.Warn().Err(err).Str("sessionId", sessionID).Msg("Failed to unmarshal download job, skipping")
+			continue
+		}
+		if job.Fingerprint != fingerprint || job.Status == "error" {
+			continue
+		}
+		if skAttr, ok := item["SK"].(*types.AttributeValueMemberS); ok {
+			job.ID = strings.TrimPrefix(skAttr.Value, skDownload)
+		}
+		job.SessionID = sessionID
+		log.Debug().Str("sessionId", sessionID).Str("jobId", job.ID).Str("status", job.Status).Bool("prewarm", job.Prewarm).Msg("FindDownloadJob: matching job found")
+		return &job, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,43 @@
+package store
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDownloadJobOmittedKeys(t *testing.T) {
+	job := &DownloadJob{
+		Bundles: []DownloadBundle{
+			{Name: "trip-images.zip", OmittedFiles: []string{"s/a.jpg", "s/b.jpg"}},
+			{Name: "trip-videos-1.zip"},
+			{Name: "trip-videos-2.zip", OmittedFiles: []string{"s/c.mp4"}},
+		},
+	}
+	want := []string{"s/a.jpg", "s/b.jpg", "s/c.mp4"}
+	if got := job.OmittedKeys(); !reflect.DeepEqual(got, want) {
+		t.Errorf("OmittedKeys() = %v, want %v", got, want)
+	}
+
+	if got := (&DownloadJob{}).OmittedKeys(); got != nil {
+		t.Errorf("OmittedKeys() on empty job = %v, want nil", got)
+	}
+}
+
+func TestDownloadFingerprint(t *testing.T) {
+	a := DownloadFingerprint([]string{"s/a.jpg", "s/b.mp4"}, "Tokyo", "strip-gps")
+	if b := DownloadFingerprint([]string{"s/b.mp4", "s/a.jpg"}, "Tokyo", "strip-gps"); a != b {
+		t.Errorf("fingerprint depends on key order: %s != %s", a, b)
+	}
+	if b := DownloadFingerprint([]string{"s/a.jpg", "s/b.mp4"}, "Kyoto", "strip-gps"); a == b {
+		t.Error("fingerprint ignores group label")
+	}
+	if b := DownloadFingerprint([]string{"s/a.jpg"}, "Tokyo", "strip-gps"); a == b {
+		t.Error("fingerprint ignores keys")
+	}
+	if b := DownloadFingerprint([]string{"s/a.jpgs/b.mp4"}, "Tokyo", "strip-gps"); a == b {
+		t.Error("fingerprint does not separate keys")
+	}
+	if b := DownloadFingerprint([]string{"s/a.jpg", "s/b.mp4"}, "Tokyo", "preserve"); a == b {
+		t.Error("fingerprint ignores metadata policy")
+	}
+}
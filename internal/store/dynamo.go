@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -25,6 +26,10 @@ const (
 	skFBPrep    = "FBPREP#"
 	skGroup     = "GROUP#"
 	skPublish   = "PUBLISH#"
+	skDispatch  = "DISPATCH#" // DDR-089: job dispatch payloads for retry
+	skMood      = "MOOD#"     // DDR-102: stylized cover variants
+	skCrop      = "CROP#"     // DDR-130: subject-aware crop suggestions
+	skLink      = "LINK#"     // DDR-131: proxy uploads linked to originals
 
 	// maxBatchWrite is the DynamoDB BatchWriteItem limit per call.
 	maxBatchWrite = 25
@@ -41,11 +46,32 @@ var stepToSKPrefix = map[string]string{
 	"publish":     skPublish,
 }
 
+// jobIDPrefixToSK maps job ID prefixes (from jobs.GenerateID) to sort key prefixes.
+// Lets job-agnostic operations such as retry (DDR-089) locate any job record.
+var jobIDPrefixToSK = map[string]string{
+	"triage-": skTriage,
+	"sel-":    skSelection,
+	"enh-":    skEnhance,
+	"dl-":     skDownload,
+	"desc-":   skDesc,
+	"fb-":     skFBPrep,
+	"pub-":    skPublish,
+	"mood-":   skMood,
+	"crop-":   skCrop,
+	"redact-": skRedact,
+}
+
 // DynamoStore implements SessionStore using AWS DynamoDB.
 // It uses the single-table design defined in DDR-039.
 type DynamoStore struct {
 	client    *dynamodb.Client
 	tableName string
+
+	// fileProcessing, when set, is cascaded by DeleteSession.
+	fileProcessing *FileProcessingStore
+
+	// owners caches each session's owner for touchSessionJob.
+	owners sync.Map
 }
 
 // Compile-time interface check.
@@ -90,18 +116,13 @@ func (s *DynamoStore) putItem(ctx context.Context, pk, sk string, data interface
 	log.Trace().Str("pk", pk).Str("sk", sk).Msg("putItem: marshaling and writing to DynamoDB")
 
 	start := time.Now()
-	item, err := attributevalue.MarshalMap(data)
+	item, err := marshalItem(pk, sk, data)
 	if err != nil {
 		log.Trace().Err(err).Str("pk", pk).Str("sk", sk).Msg("putItem: marshal failed")
-		return fmt.Errorf("marshal: %w", err)
+		return err
 	}
 	log.Trace().Str("pk", pk).Str("sk", sk).Msg("putItem: marshal completed")
 
-	// Add key and TTL attributes (overwrite any conflicting keys from the data).
-	item["PK"] = &types.AttributeValueMemberS{Value: pk}
-	item["SK"] = &types.AttributeValueMemberS{Value: sk}
-	item["expiresAt"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt(), 10)}
-
 	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
 		TableName: &s.tableName,
 		Item:      item,
@@ -115,6 +136,19 @@ func (s *DynamoStore) putItem(ctx context.Context, pk, sk string, data interface
 	return nil
 }
 
+// marshalItem renders data as the item stored at pk/sk, with the key and
+// TTL attributes overwriting any conflicting ones from the data.
+func marshalItem(pk, sk string, data interface{}) (map[string]types.AttributeValue, error) {
+	item, err := attributevalue.MarshalMap(data)
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+	item["PK"] = &types.AttributeValueMemberS{Value: pk}
+	item["SK"] = &types.AttributeValueMemberS{Value: sk}
+	item["expiresAt"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt(), 10)}
+	return item, nil
+}
+
 // getItem reads a single item from DynamoDB and unmarshals it into out.
 // Returns false if the item does not exist (out is not modified).
 func (s *DynamoStore) getItem(ctx context.Context, pk, sk string, out interface{}) (bool, error) {
@@ -211,53 +245,74 @@ func (s *DynamoStore) queryBySKPrefix(ctx context.Context, sessionID, skPrefix s
 // batchDeleteKeys deletes multiple items by their PK/SK keys.
 // Handles DynamoDB's 25-item-per-batch limit automatically.
 func (s *DynamoStore) batchDeleteKeys(ctx context.Context, keys []map[string]types.AttributeValue) error {
-	log.Debug().Int("keyCount", len(keys)).Msg("batchDeleteKeys: starting batch delete")
+	requests := make([]types.WriteRequest, len(keys))
+	for i, key := range keys {
+		requests[i] = types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}}
+	}
+	_, err := s.batchWrite(ctx, "delete", requests)
+	return err
+}
 
-	totalUnprocessed := 0
-	for i := 0; i < len(keys); i += maxBatchWrite {
-		end := i + maxBatchWrite
-		if end > len(keys) {
-			end = len(keys)
-		}
+// batchPutItems writes complete items, including their PK, SK, and TTL.
+// Handles DynamoDB's 25-item-per-batch limit automatically.
+func (s *DynamoStore) batchPutItems(ctx context.Context, items []map[string]types.AttributeValue) error {
+	requests := make([]types.WriteRequest, len(items))
+	for i, item := range items {
+		requests[i] = types.WriteRequest{PutRequest: &types.PutRequest{Item: item}}
+	}
+	remaining, err := s.batchWrite(ctx, "put", requests)
+	if err == nil && remaining > 0 {
+		err = fmt.Errorf("BatchWriteItem put: %d items unprocessed after retries", remaining)
+	}
+	return err
+}
 
-		var requests []types.WriteRequest
-		for _, key := range keys[i:end] {
-			requests = append(requests, types.WriteRequest{
-				DeleteRequest: &types.DeleteRequest{Key: key},
-			})
+// batchWrite sends requests in batches of maxBatchWrite, retrying
+// unprocessed items with exponential backoff. Returns the number of requests
+// still unprocessed after the retries.
+func (s *DynamoStore) batchWrite(ctx context.Context, op string, requests []types.WriteRequest) (int, error) {
+	log.Debug().Str("op", op).Int("keyCount", len(requests)).Msg("batchWrite: starting batch " + op)
+
+	totalUnprocessed, remaining := 0, 0
+	for i := 0; i < len(requests); i += maxBatchWrite {
+		end := i + maxBatchWrite
+		if end > len(requests) {
+			end = len(requests)
 		}
+		batch := requests[i:end]
 
 		result, err := s.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
 			RequestItems: map[string][]types.WriteRequest{
-				s.tableName: requests,
+				s.tableName: batch,
 			},
 		})
 		if err != nil {
-			log.Debug().Err(err).Int("keyCount", len(keys)).Int("unprocessedCount", totalUnprocessed).Msg("batchDeleteKeys: BatchWriteItem failed")
-			return fmt.Errorf("BatchWriteItem delete (%d items): %w", len(requests), err)
+			log.Debug().Err(err).Str("op", op).Int("keyCount", len(requests)).Int("unprocessedCount", totalUnprocessed).Msg("batchWrite: BatchWriteItem failed")
+			return remaining, fmt.Errorf("BatchWriteItem %s (%d items): %w", op, len(batch), err)
 		}
 
 		unprocessed := result.UnprocessedItems[s.tableName]
 		for retries := 0; len(unprocessed) > 0 && retries < 5; retries++ {
 			totalUnprocessed += len(unprocessed)
 			backoff := time.Duration(1<<retries) * 100 * time.Millisecond
-			log.Debug().Int("unprocessed", len(unprocessed)).Int("retry", retries+1).Dur("backoff", backoff).Msg("batchDeleteKeys: retrying unprocessed items")
+			log.Debug().Int("unprocessed", len(unprocessed)).Int("retry", retries+1).Dur("backoff", backoff).Msg("batchWrite: retrying unprocessed items")
 			time.Sleep(backoff)
 			retryResult, retryErr := s.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
 				RequestItems: map[string][]types.WriteRequest{s.tableName: unprocessed},
 			})
 			if retryErr != nil {
-				log.Warn().Err(retryErr).Int("unprocessed", len(unprocessed)).Msg("batchDeleteKeys: retry failed")
+				log.Warn().Err(retryErr).Int("unprocessed", len(unprocessed)).Msg("batchWrite: retry failed")
 				break
 			}
 			unprocessed = retryResult.UnprocessedItems[s.tableName]
 		}
 		if len(unprocessed) > 0 {
-			log.Warn().Int("remaining", len(unprocessed)).Msg("batchDeleteKeys: unprocessed items remain after retries")
+			log.Warn().Str("op", op).Int("remaining", len(unprocessed)).Msg("batchWrite: unprocessed items remain after retries")
+			remaining += len(unprocessed)
 		}
 	}
-	log.Debug().Int("keyCount", len(keys)).Int("unprocessedCount", totalUnprocessed).Msg("batchDeleteKeys: batch delete completed")
-	return nil
+	log.Debug().Str("op", op).Int("keyCount", len(requests)).Int("unprocessedCount", totalUnprocessed).Msg("batchWrite: batch " + op + " completed")
+	return remaining, nil
 }
 
 // --- Session operations ---
@@ -270,6 +325,13 @@ func (s *DynamoStore) PutSession(ctx context.Context, session *Session) error {
 	if err := s.putItem(ctx, sessionPK(session.ID), skMeta, session); err != nil {
 		return fmt.Errorf("put session %s: %w", session.ID, err)
 	}
+	s.owners.Store(session.ID, session.OwnerSub)
+	// Index the session under its owner for the session listing API (DDR-098).
+	if session.OwnerSub != "" {
+		if err := s.refreshSessionRef(ctx, session.ID); err != nil {
+			return err
+		}
+	}
 
 	log.Debug().Str("sessionId", session.ID).Str("status", session.Status).Msg("Session persisted to DynamoDB")
 	return nil
@@ -291,6 +353,16 @@ func (s *DynamoStore) GetSession(ctx context.Context, sessionID string) (*Sessio
 	return &session, nil
 }
 
+// SessionOwner returns the Cognito sub that owns the session, or "" if the
+// session has no owner (auth disabled, legacy) or no longer exists.
+func (s *DynamoStore) SessionOwner(ctx context.Context, sessionID string) (string, error) {
+	session, err := s.GetSession(ctx, sessionID)
+	if err != nil || session == nil {
+		return "", err
+	}
+	return session.OwnerSub, nil
+}
+
 // VerifySessionOwner checks that the session exists and is owned by the given sub.
 // Returns nil if ownership matches, a non-nil error otherwise.
 // If no META record exists yet, returns ErrSessionNotFound.
@@ -338,6 +410,52 @@ func (s *DynamoStore) UpdateSessionStatus(ctx context.Context, sessionID, status
 		return fmt.Errorf("update session status %s -> %s: %w", sessionID, status, err)
 	}
 
+	s.touchSessionRef(ctx, sessionID)
 	log.Debug().Str("sessionId", sessionID).Str("status", status).Msg("Session status updated")
 	return nil
 }
+
+func (s *DynamoStore) UpdateSessionMetadataPolicy(ctx context.Context, sessionID, policy string) error {
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: sessionPK(sessionID)},
+			"SK": &types.AttributeValueMemberS{Value: skMeta},
+		},
+		UpdateExpression: aws.String("SET metadataPolicy = :p"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":p": &types.AttributeValueMemberS{Value: policy},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("update session metadata policy %s -> %s: %w", sessionID, policy, err)
+	}
+
+	log.Debug().Str("sessionId", sessionID).Str("policy", policy).Msg("Session metadata policy updated")
+	return nil
+}
+
+// SetSessionKMSKey records the session's encryption key (DDR-164). The
+// condition keeps a second request from replacing a key objects may
+// already be encrypted under.
+func (s *DynamoStore) SetSessionKMSKey(ctx context.Context, sessionID, keyARN string) error {
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: sessionPK(sessionID)},
+			"SK": &types.AttributeValueMemberS{Value: skMeta},
+		},
+		UpdateExpression:    aws.String("SET kmsKeyId = :k"),
+		ConditionExpression: aws.String("attribute_exists(PK) AND attribute_not_exists(kmsKeyId)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":k": &types.AttributeValueMemberS{Value: keyARN},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("set session KMS key %s: %w", sessionID, err)
+	}
+
+	s.touchSessionRef(ctx, sessionID)
+	log.Debug().Str("sessionId", sessionID).Str("keyArn", keyARN).Msg("Session KMS key recorded")
+	return nil
+}
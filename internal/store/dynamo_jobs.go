@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -11,16 +12,20 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
 )
 
 // --- Triage job operations (DDR-050) ---
 
 func (s *DynamoStore) PutTriageJob(ctx context.Context, sessionID string, job *TriageJob) error {
 	sk := skTriage + job.ID
-	if err := s.putItem(ctx, sessionPK(sessionID), sk, job); err != nil {
+	s.attachTelemetry(ctx, sessionPK(sessionID), sk, &job.Telemetry)
+	if err := s.putJobItem(ctx, sessionPK(sessionID), sk, job); err != nil {
 		return fmt.Errorf("put triage job %s/%s: %w", sessionID, job.ID, err)
 	}
 
+	s.touchSessionJob(ctx, sessionID, sk, sessionJobUpdate{Status: job.Status, Error: &job.Error, FileCount: job.TotalFiles})
 	log.Debug().
 		Str("sessionId", sessionID).
 		Str("jobId", job.ID).
@@ -52,10 +57,12 @@ func (s *DynamoStore) GetTriageJob(ctx context.Context, sessionID, jobID string)
 
 func (s *DynamoStore) PutSelectionJob(ctx context.Context, sessionID string, job *SelectionJob) error {
 	sk := skSelection + job.ID
-	if err := s.putItem(ctx, sessionPK(sessionID), sk, job); err != nil {
+	s.attachTelemetry(ctx, sessionPK(sessionID), sk, &job.Telemetry)
+	if err := s.putJobItem(ctx, sessionPK(sessionID), sk, job); err != nil {
 		return fmt.Errorf("put selection job %s/%s: %w", sessionID, job.ID, err)
 	}
 
+	s.touchSessionJob(ctx, sessionID, sk, sessionJobUpdate{Status: job.Status, Error: &job.Error})
 	log.Debug().
 		Str("sessionId", sessionID).
 		Str("jobId", job.ID).
@@ -87,10 +94,14 @@ func (s *DynamoStore) GetSelectionJob(ctx context.Context, sessionID, jobID stri
 
 func (s *DynamoStore) PutEnhancementJob(ctx context.Context, sessionID string, job *EnhancementJob) error {
 	sk := skEnhance + job.ID
-	if err := s.putItem(ctx, sessionPK(sessionID), sk, job); err != nil {
+	if job.Telemetry == nil {
+		job.Telemetry = &metrics.JobTelemetry{} // addTelemetry adds to its fields
+	}
+	if err := s.putJobItem(ctx, sessionPK(sessionID), sk, job); err != nil {
 		return fmt.Errorf("put enhancement job %s/%s: %w", sessionID, job.ID, err)
 	}
 
+	s.touchSessionJob(ctx, sessionID, sk, sessionJobUpdate{Status: job.Status, Error: &job.Error})
 	log.Debug().
 		Str("sessionId", sessionID).
 		Str("jobId", job.ID).
@@ -150,6 +161,7 @@ func (s *DynamoStore) UpdateEnhancementItemResult(ctx context.Context, sessionID
 		return 0, 0, fmt.Errorf("UpdateEnhancementItemResult %s/%s[%d]: %w", sessionID, jobID, itemIndex, err)
 	}
 
+	s.addTelemetry(ctx, pk, sk)
 	newCount := extractIntAttr(result.Attributes, "completedCount")
 	totalCount := extractIntAttr(result.Attributes, "totalCount")
 
@@ -186,6 +198,7 @@ func (s *DynamoStore) UpdateEnhancementItemFields(ctx context.Context, sessionID
 	if err != nil {
 		return fmt.Errorf("UpdateEnhancementItemFields %s/%s[%d]: %w", sessionID, jobID, itemIndex, err)
 	}
+	s.addTelemetry(ctx, pk, sk)
 
 	log.Debug().
 		Str("sessionId", sessionID).Str("jobId", jobID).
@@ -207,7 +220,7 @@ func (s *DynamoStore) UpdateEnhancementStatus(ctx context.Context, sessionID, jo
 			"PK": &types.AttributeValueMemberS{Value: pk},
 			"SK": &types.AttributeValueMemberS{Value: sk},
 		},
-		UpdateExpression: aws.String("SET #st = :status"),
+		UpdateExpression:    aws.String("SET #st = :status"),
 		ConditionExpression: aws.String("completedCount >= totalCount"),
 		ExpressionAttributeNames: map[string]string{
 			"#st": "status",
@@ -220,6 +233,7 @@ func (s *DynamoStore) UpdateEnhancementStatus(ctx context.Context, sessionID, jo
 		return fmt.Errorf("UpdateEnhancementStatus %s/%s -> %s: %w", sessionID, jobID, status, err)
 	}
 
+	s.touchSessionJob(ctx, sessionID, sk, sessionJobUpdate{Status: status})
 	log.Debug().
 		Str("sessionId", sessionID).Str("jobId", jobID).Str("status", status).
 		Msg("Enhancement status updated atomically")
@@ -242,10 +256,12 @@ func extractIntAttr(attrs map[string]types.AttributeValue, key string) int {
 
 func (s *DynamoStore) PutDownloadJob(ctx context.Context, sessionID string, job *DownloadJob) error {
 	sk := skDownload + job.ID
-	if err := s.putItem(ctx, sessionPK(sessionID), sk, job); err != nil {
+	s.attachTelemetry(ctx, sessionPK(sessionID), sk, &job.Telemetry)
+	if err := s.putJobItem(ctx, sessionPK(sessionID), sk, job); err != nil {
 		return fmt.Errorf("put download job %s/%s: %w", sessionID, job.ID, err)
 	}
 
+	s.touchSessionJob(ctx, sessionID, sk, sessionJobUpdate{Status: job.Status})
 	log.Debug().
 		Str("sessionId", sessionID).
 		Str("jobId", job.ID).
@@ -276,10 +292,12 @@ func (s *DynamoStore) GetDownloadJob(ctx context.Context, sessionID, jobID strin
 
 func (s *DynamoStore) PutFBPrepJob(ctx context.Context, sessionID string, job *FBPrepJob) error {
 	sk := skFBPrep + job.ID
-	if err := s.putItem(ctx, sessionPK(sessionID), sk, job); err != nil {
+	s.attachTelemetry(ctx, sessionPK(sessionID), sk, &job.Telemetry)
+	if err := s.putJobItem(ctx, sessionPK(sessionID), sk, job); err != nil {
 		return fmt.Errorf("put FB prep job %s/%s: %w", sessionID, job.ID, err)
 	}
 
+	s.touchSessionJob(ctx, sessionID, sk, sessionJobUpdate{Status: job.Status, Error: &job.Error})
 	log.Debug().
 		Str("sessionId", sessionID).
 		Str("jobId", job.ID).
@@ -310,15 +328,17 @@ func (s *DynamoStore) GetFBPrepJob(ctx context.Context, sessionID, jobID string)
 
 func (s *DynamoStore) PutDescriptionJob(ctx context.Context, sessionID string, job *DescriptionJob) error {
 	sk := skDesc + job.ID
-	if err := s.putItem(ctx, sessionPK(sessionID), sk, job); err != nil {
+	s.attachTelemetry(ctx, sessionPK(sessionID), sk, &job.Telemetry)
+	if err := s.putJobItem(ctx, sessionPK(sessionID), sk, job); err != nil {
 		return fmt.Errorf("put description job %s/%s: %w", sessionID, job.ID, err)
 	}
 
+	s.touchSessionJob(ctx, sessionID, sk, sessionJobUpdate{Status: job.Status, Error: &job.Error})
 	log.Debug().
 		Str("sessionId", sessionID).
 		Str("jobId", job.ID).
 		Str("status", job.Status).
-		Int("historyLen", len(job.History)).
+		Int("feedbackRounds", job.Rounds()).
 		Msg("Description job persisted")
 	return nil
 }
@@ -344,10 +364,12 @@ func (s *DynamoStore) GetDescriptionJob(ctx context.Context, sessionID, jobID st
 
 func (s *DynamoStore) PutPublishJob(ctx context.Context, sessionID string, job *PublishJob) error {
 	sk := skPublish + job.ID
-	if err := s.putItem(ctx, sessionPK(sessionID), sk, job); err != nil {
+	s.attachTelemetry(ctx, sessionPK(sessionID), sk, &job.Telemetry)
+	if err := s.putJobItem(ctx, sessionPK(sessionID), sk, job); err != nil {
 		return fmt.Errorf("put publish job %s/%s: %w", sessionID, job.ID, err)
 	}
 
+	s.touchSessionJob(ctx, sessionID, sk, sessionJobUpdate{Status: job.Status, Error: &job.Error})
 	log.Debug().
 		Str("sessionId", sessionID).
 		Str("jobId", job.ID).
@@ -384,6 +406,7 @@ func (s *DynamoStore) PutPostGroup(ctx context.Context, sessionID string, group
 		return fmt.Errorf("put post group %s/%s: %w", sessionID, group.ID, err)
 	}
 
+	s.touchSessionRef(ctx, sessionID)
 	log.Debug().
 		Str("sessionId", sessionID).
 		Str("groupId", group.ID).
@@ -414,6 +437,7 @@ func (s *DynamoStore) GetPostGroups(ctx context.Context, sessionID string) ([]*P
 
 		groups = append(groups, &group)
 	}
+	SortPostGroups(groups)
 
 	return groups, nil
 }
@@ -423,10 +447,90 @@ func (s *DynamoStore) DeletePostGroup(ctx context.Context, sessionID, groupID st
 		return fmt.Errorf("delete post group %s/%s: %w", sessionID, groupID, err)
 	}
 
+	s.touchSessionRef(ctx, sessionID)
 	log.Debug().Str("sessionId", sessionID).Str("groupId", groupID).Msg("Post group deleted")
 	return nil
 }
 
+// ErrPostGroupNotFound is returned when a post group to update no longer
+// exists.
+var ErrPostGroupNotFound = errors.New("post group not found")
+
+// SetPostGroupOrderSuggestion records suggestion on an existing post group
+// (DDR-179). Only the suggestion is written, so edits made to the group while
+// the suggestion was being made are kept. Returns ErrPostGroupNotFound if the
+// group was deleted.
+func (s *DynamoStore) SetPostGroupOrderSuggestion(ctx context.Context, sessionID, groupID string, suggestion *CarouselOrderSuggestion) error {
+	av, err := attributevalue.Marshal(suggestion)
+	if err != nil {
+		return fmt.Errorf("marshal order suggestion %s/%s: %w", sessionID, groupID, err)
+	}
+	_, err = s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: sessionPK(sessionID)},
+			"SK": &types.AttributeValueMemberS{Value: skGroup + groupID},
+		},
+		UpdateExpression:    aws.String("SET orderSuggestion = :s"),
+		ConditionExpression: aws.String("attribute_exists(SK)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":s": av,
+		},
+	})
+	if err != nil {
+		var ccf *types.ConditionalCheckFailedException
+		if errors.As(err, &ccf) {
+			return fmt.Errorf("set order suggestion %s/%s: %w", sessionID, groupID, ErrPostGroupNotFound)
+		}
+		return fmt.Errorf("set order suggestion %s/%s: %w", sessionID, groupID, err)
+	}
+
+	log.Debug().Str("sessionId", sessionID).Str("groupId", groupID).Str("status", suggestion.Status).Msg("Post group order suggestion persisted")
+	return nil
+}
+
+// maxTransactItems is the most writes one DynamoDB transaction holds.
+const maxTransactItems = 100
+
+// ApplyPostGroupEdit applies edit's writes in one transaction: either every
+// group is written and deleted, or none is.
+func (s *DynamoStore) ApplyPostGroupEdit(ctx context.Context, sessionID string, edit PostGroupEdit) error {
+	if n := len(edit.Put) + len(edit.Delete); n == 0 {
+		return nil
+	} else if n > maxTransactItems {
+		return fmt.Errorf("post group edit of %s: %d writes, more than %d", sessionID, n, maxTransactItems)
+	}
+	pk := sessionPK(sessionID)
+	var actions []types.TransactWriteItem
+	for _, g := range edit.Put {
+		item, err := marshalItem(pk, skGroup+g.ID, g)
+		if err != nil {
+			return fmt.Errorf("post group edit of %s: %w", sessionID, err)
+		}
+		actions = append(actions, types.TransactWriteItem{Put: &types.Put{TableName: &s.tableName, Item: item}})
+	}
+	for _, id := range edit.Delete {
+		actions = append(actions, types.TransactWriteItem{Delete: &types.Delete{
+			TableName: &s.tableName,
+			Key: map[string]types.AttributeValue{
+				"PK": &types.AttributeValueMemberS{Value: pk},
+				"SK": &types.AttributeValueMemberS{Value: skGroup + id},
+			},
+		}})
+	}
+	if _, err := s.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: actions}); err != nil {
+		return fmt.Errorf("post group edit of %s: %w", sessionID, err)
+	}
+
+	s.touchSessionRef(ctx, sessionID)
+	log.Debug().
+		Str("sessionId", sessionID).
+		Int("put", len(edit.Put)).
+		Int("deleted", len(edit.Delete)).
+		Msg("Post group edit applied")
+	return nil
+}
+
 // --- Triage atomic counter operations (DDR-061) ---
 
 // IncrementTriageProcessedCount atomically increments the processedCount field
@@ -516,6 +620,7 @@ func (s *DynamoStore) UpdateTriagePhase(ctx context.Context, sessionID, jobID, p
 		return fmt.Errorf("update triage phase %s/%s: %w", sessionID, jobID, err)
 	}
 
+	s.touchSessionJob(ctx, sessionID, sk, sessionJobUpdate{Status: status})
 	log.Debug().Str("sessionId", sessionID).Str("jobId", jobID).Str("phase", phase).Str("status", status).Msg("Triage phase updated")
 	return nil
 }
@@ -603,6 +708,7 @@ func (s *DynamoStore) InvalidateDownstream(ctx context.Context, sessionID, fromS
 		return deletedSKs, fmt.Errorf("batch delete downstream state for %s from %s: %w", sessionID, fromStep, err)
 	}
 
+	s.touchSessionRef(ctx, sessionID)
 	log.Info().
 		Str("sessionId", sessionID).
 		Str("fromStep", fromStep).
@@ -612,3 +718,63 @@ func (s *DynamoStore) InvalidateDownstream(ctx context.Context, sessionID, fromS
 
 	return deletedSKs, nil
 }
+
+// attachTelemetry sets *dst to the record's totals before this invocation
+// plus the usage of the Collector carried by ctx, so every write from a
+// worker reports the job's usage so far, across all the invocations that
+// worked on it (DDR-118). The earlier totals are read from the record on the
+// invocation's first write to it. Writes from a context without a Collector
+// leave *dst unchanged.
+func (s *DynamoStore) attachTelemetry(ctx context.Context, pk, sk string, dst **metrics.JobTelemetry) {
+	c := metrics.CollectorFrom(ctx)
+	if c == nil {
+		return
+	}
+	base := c.Baseline(pk+"|"+sk, func() *metrics.JobTelemetry {
+		var stored struct {
+			Telemetry *metrics.JobTelemetry `dynamodbav:"perJobTelemetry"`
+		}
+		if _, err := s.getItem(ctx, pk, sk, &stored); err != nil {
+			log.Warn().Err(err).Str("pk", pk).Str("sk", sk).Msg("Failed to read job telemetry, counting this invocation only")
+		}
+		return stored.Telemetry
+	})
+	*dst = base.Plus(c.Snapshot())
+}
+
+// addTelemetry atomically adds the usage of the Collector carried by ctx
+// that this record has not been given yet. Records written item by item by
+// concurrent invocations use it instead of attachTelemetry, since reading
+// and rewriting the totals would lose the others' usage. Best effort: a
+// failure is logged and the usage is not counted.
+func (s *DynamoStore) addTelemetry(ctx context.Context, pk, sk string) {
+	c := metrics.CollectorFrom(ctx)
+	if c == nil {
+		return
+	}
+	t := c.Unreported(pk + "|" + sk)
+	if *t == (metrics.JobTelemetry{}) {
+		return
+	}
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: pk},
+			"SK": &types.AttributeValueMemberS{Value: sk},
+		},
+		UpdateExpression: aws.String("ADD #t.geminiCalls :calls, #t.geminiLatencyMs :latency, #t.s3Downloads :downloads, " +
+			"#t.bytesDownloaded :bytes, #t.ffmpegSeconds :ffmpeg, #t.geminiInputTokens :tokens"),
+		ExpressionAttributeNames: map[string]string{"#t": "perJobTelemetry"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":calls":     &types.AttributeValueMemberN{Value: strconv.FormatInt(t.GeminiCalls, 10)},
+			":latency":   &types.AttributeValueMemberN{Value: strconv.FormatInt(t.GeminiLatencyMs, 10)},
+			":downloads": &types.AttributeValueMemberN{Value: strconv.FormatInt(t.S3Downloads, 10)},
+			":bytes":     &types.AttributeValueMemberN{Value: strconv.FormatInt(t.BytesDownloaded, 10)},
+			":ffmpeg":    &types.AttributeValueMemberN{Value: strconv.FormatFloat(t.FFmpegSeconds, 'f', -1, 64)},
+			":tokens":    &types.AttributeValueMemberN{Value: strconv.FormatInt(t.GeminiInputTokens, 10)},
+		},
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("pk", pk).Str("sk", sk).Msg("Failed to add job telemetry")
+	}
+}
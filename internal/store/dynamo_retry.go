@@ -0,0 +1,209 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Job retry operations (DDR-089) ---
+
+// ErrRetryLimit is returned by IncrementJobRetryCount when the job has
+// already been retried the maximum number of times.
+var ErrRetryLimit = errors.New("job retry limit reached")
+
+// jobSK resolves the full sort key for a job from its ID prefix.
+func jobSK(jobID string) (string, error) {
+	for idPrefix, skPrefix := range jobIDPrefixToSK {
+		if strings.HasPrefix(jobID, idPrefix) {
+			return skPrefix + jobID, nil
+		}
+	}
+	return "", fmt.Errorf("unknown job ID prefix: %s", jobID)
+}
+
+func (s *DynamoStore) GetJobSummary(ctx context.Context, sessionID, jobID string) (*JobSummary, error) {
+	sk, err := jobSK(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	var summary JobSummary
+	found, err := s.getItem(ctx, sessionPK(sessionID), sk, &summary)
+	if err != nil {
+		return nil, fmt.Errorf("get job summary %s/%s: %w", sessionID, jobID, err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	summary.ID = jobID
+	return &summary, nil
+}
+
+func (s *DynamoStore) IncrementJobRetryCount(ctx context.Context, sessionID, jobID, status string, maxRetries int) (int, error) {
+	sk, err := jobSK(jobID)
+	if err != nil {
+		return 0, err
+	}
+
+	// The cap is part of the condition so that concurrent retries (the API
+	// endpoint and the DLQ redrive) cannot both pass a stale read of it.
+	result, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: sessionPK(sessionID)},
+			"SK": &types.AttributeValueMemberS{Value: sk},
+		},
+		UpdateExpression:    aws.String("SET #st = :status ADD retryCount :inc REMOVE #err"),
+		ConditionExpression: aws.String("attribute_exists(SK) AND (attribute_not_exists(retryCount) OR retryCount < :max)"),
+		ExpressionAttributeNames: map[string]string{
+			"#st":  "status",
+			"#err": "error",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: status},
+			":inc":    &types.AttributeValueMemberN{Value: "1"},
+			":max":    &types.AttributeValueMemberN{Value: strconv.Itoa(maxRetries)},
+		},
+		ReturnValues: types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		var ccf *types.ConditionalCheckFailedException
+		if errors.As(err, &ccf) {
+			return 0, fmt.Errorf("increment retryCount %s/%s: %w", sessionID, jobID, ErrRetryLimit)
+		}
+		return 0, fmt.Errorf("increment retryCount %s/%s: %w", sessionID, jobID, err)
+	}
+
+	newCount := 0
+	if attr, ok := result.Attributes["retryCount"]; ok {
+		if n, ok := attr.(*types.AttributeValueMemberN); ok {
+			if v, err := strconv.Atoi(n.Value); err == nil {
+				newCount = v
+			}
+		}
+	}
+
+	noError := ""
+	s.touchSessionJob(ctx, sessionID, sk, sessionJobUpdate{Status: status, Error: &noError, RetryCount: newCount})
+	log.Debug().Str("sessionId", sessionID).Str("jobId", jobID).Int("retryCount", newCount).Str("status", status).Msg("Job retryCount incremented")
+	return newCount, nil
+}
+
+// putJobItemAttempts bounds how often putJobItem rereads the retry count
+// after finding it higher than the record's.
+const putJobItemAttempts = 3
+
+// putJobItem writes a job record like putItem but keeps the retryCount that
+// IncrementJobRetryCount wrote. Workers rebuild their records from the Step
+// Functions event, so a plain put would reset the count, and a job that
+// keeps crashing would be redriven from the DLQ forever. The put is
+// conditioned on the stored count not exceeding the record's; when it does,
+// the stored count is read and the put tried again with it.
+func (s *DynamoStore) putJobItem(ctx context.Context, pk, sk string, data interface{}) error {
+	item, err := marshalItem(pk, sk, data)
+	if err != nil {
+		return err
+	}
+	count := 0
+	if n, ok := item["retryCount"].(*types.AttributeValueMemberN); ok {
+		count, _ = strconv.Atoi(n.Value)
+	}
+
+	for attempt := 1; ; attempt++ {
+		_, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName:           &s.tableName,
+			Item:                item,
+			ConditionExpression: aws.String("attribute_not_exists(retryCount) OR retryCount <= :rc"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":rc": &types.AttributeValueMemberN{Value: strconv.Itoa(count)},
+			},
+		})
+		var ccf *types.ConditionalCheckFailedException
+		if !errors.As(err, &ccf) || attempt == putJobItemAttempts {
+			if err != nil {
+				return fmt.Errorf("PutItem PK=%s SK=%s: %w", pk, sk, err)
+			}
+			return nil
+		}
+
+		var stored struct {
+			RetryCount int `dynamodbav:"retryCount"`
+		}
+		if _, err := s.getItem(ctx, pk, sk, &stored); err != nil {
+			return err
+		}
+		count = max(count, stored.RetryCount)
+		item["retryCount"] = &types.AttributeValueMemberN{Value: strconv.Itoa(count)}
+		log.Debug().Str("pk", pk).Str("sk", sk).Int("retryCount", count).Msg("putJobItem: keeping the stored retryCount")
+	}
+}
+
+func (s *DynamoStore) UpdateJobStatus(ctx context.Context, sessionID, jobID, status, errMsg string) error {
+	sk, err := jobSK(jobID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: sessionPK(sessionID)},
+			"SK": &types.AttributeValueMemberS{Value: sk},
+		},
+		UpdateExpression:    aws.String("SET #st = :status, #err = :err"),
+		ConditionExpression: aws.String("attribute_exists(SK)"),
+		ExpressionAttributeNames: map[string]string{
+			"#st":  "status",
+			"#err": "error",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: status},
+			":err":    &types.AttributeValueMemberS{Value: errMsg},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("update job status %s/%s: %w", sessionID, jobID, err)
+	}
+
+	s.touchSessionJob(ctx, sessionID, sk, sessionJobUpdate{Status: status, Error: &errMsg})
+	log.Debug().Str("sessionId", sessionID).Str("jobId", jobID).Str("status", status).Msg("Job status updated")
+	return nil
+}
+
+func (s *DynamoStore) PutDispatchRecord(ctx context.Context, sessionID string, rec *DispatchRecord) error {
+	if rec.CreatedAt == 0 {
+		rec.CreatedAt = time.Now().Unix()
+	}
+
+	sk := skDispatch + rec.JobID
+	if err := s.putItem(ctx, sessionPK(sessionID), sk, rec); err != nil {
+		return fmt.Errorf("put dispatch record %s/%s: %w", sessionID, rec.JobID, err)
+	}
+
+	log.Debug().Str("sessionId", sessionID).Str("jobId", rec.JobID).Str("eventType", rec.EventType).Msg("Dispatch record persisted")
+	return nil
+}
+
+func (s *DynamoStore) GetDispatchRecord(ctx context.Context, sessionID, jobID string) (*DispatchRecord, error) {
+	var rec DispatchRecord
+	found, err := s.getItem(ctx, sessionPK(sessionID), skDispatch+jobID, &rec)
+	if err != nil {
+		return nil, fmt.Errorf("get dispatch record %s/%s: %w", sessionID, jobID, err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	rec.JobID = jobID
+	return &rec, nil
+}
@@ -0,0 +1,163 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// incrementRetryCount applies IncrementJobRetryCount's conditional update to
+// the stored item. The fake serves one request at a time, the way DynamoDB
+// applies a condition atomically per item.
+func incrementRetryCount(f *fakeDynamo, req *fakeRequest) (any, error) {
+	if !strings.Contains(req.ConditionExpression, "retryCount < :max") {
+		return nil, errFakeUnsupported
+	}
+	item, ok := f.items[req.Key.key()]
+	count, _ := strconv.Atoi(item.N("retryCount"))
+	max, _ := strconv.Atoi(req.ExpressionAttributeValues.N(":max"))
+	if !ok || count >= max {
+		return nil, errFakeConditionFailed
+	}
+	updated := json.RawMessage(`{"N":"` + strconv.Itoa(count+1) + `"}`)
+	item["retryCount"] = updated
+	return map[string]any{"Attributes": fakeItem{"retryCount": updated}}, nil
+}
+
+// newRetryTestStore returns a store whose table holds the given jobs, by
+// session and sort key, at the given retry counts.
+func newRetryTestStore(t *testing.T, counts map[[2]string]int) *DynamoStore {
+	t.Helper()
+	table := newFakeDynamo()
+	table.on["UpdateItem"] = incrementRetryCount
+	for key, count := range counts {
+		table.put(sessionPK(key[0]), key[1], fakeItem{"retryCount": json.RawMessage(`{"N":"` + strconv.Itoa(count) + `"}`)})
+	}
+	return newFakeDynamoStore(t, table)
+}
+
+func TestIncrementJobRetryCountCap(t *testing.T) {
+	s := newRetryTestStore(t, map[[2]string]int{{"s1", skDownload + "dl-1"}: 0})
+	ctx := context.Background()
+
+	for want := 1; want <= 2; want++ {
+		got, err := s.IncrementJobRetryCount(ctx, "s1", "dl-1", "pending", 2)
+		if err != nil {
+			t.Fatalf("retry %d: %v", want, err)
+		}
+		if got != want {
+			t.Errorf("retry %d: retryCount = %d", want, got)
+		}
+	}
+	if _, err := s.IncrementJobRetryCount(ctx, "s1", "dl-1", "pending", 2); !errors.Is(err, ErrRetryLimit) {
+		t.Errorf("retry past the cap: err = %v, want ErrRetryLimit", err)
+	}
+	if _, err := s.IncrementJobRetryCount(ctx, "s1", "dl-missing", "pending", 2); !errors.Is(err, ErrRetryLimit) {
+		t.Errorf("retry of a missing job: err = %v, want ErrRetryLimit", err)
+	}
+}
+
+// TestIncrementJobRetryCountConcurrent checks that concurrent retries never
+// exceed the cap, which a read-then-compare check allowed.
+func TestIncrementJobRetryCountConcurrent(t *testing.T) {
+	s := newRetryTestStore(t, map[[2]string]int{{"s1", skEnhance + "enh-1"}: 1})
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded, limited := 0, 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.IncrementJobRetryCount(context.Background(), "s1", "enh-1", "pending", 3)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				succeeded++
+			case errors.Is(err, ErrRetryLimit):
+				limited++
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if succeeded != 2 || limited != 6 {
+		t.Errorf("succeeded = %d, limited = %d; want 2 and 6", succeeded, limited)
+	}
+}
+
+func TestJobSKCoversRetryableJobs(t *testing.T) {
+	tests := map[string]string{
+		"triage-abc": skTriage + "triage-abc",
+		"sel-abc":    skSelection + "sel-abc",
+		"enh-abc":    skEnhance + "enh-abc",
+		"dl-abc":     skDownload + "dl-abc",
+		"desc-abc":   skDesc + "desc-abc",
+		"fb-abc":     skFBPrep + "fb-abc",
+		"pub-abc":    skPublish + "pub-abc",
+		"mood-abc":   skMood + "mood-abc",
+		"crop-abc":   skCrop + "crop-abc",
+		"redact-abc": skRedact + "redact-abc",
+	}
+	for jobID, want := range tests {
+		got, err := jobSK(jobID)
+		if err != nil {
+			t.Errorf("jobSK(%q): %v", jobID, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("jobSK(%q) = %q, want %q", jobID, got, want)
+		}
+	}
+	if _, err := jobSK("unknown-abc"); err == nil {
+		t.Error("jobSK of an unknown prefix: want error")
+	}
+}
+
+// putIfRetryCountAtMost applies putJobItem's condition to the stored item.
+func putIfRetryCountAtMost(f *fakeDynamo, req *fakeRequest) (any, error) {
+	stored, _ := strconv.Atoi(f.items[req.Item.key()].N("retryCount"))
+	limit, _ := strconv.Atoi(req.ExpressionAttributeValues.N(":rc"))
+	if stored > limit {
+		return nil, errFakeConditionFailed
+	}
+	f.items[req.Item.key()] = req.Item
+	return struct{}{}, nil
+}
+
+// TestWorkerWriteKeepsRetryCount checks that a worker rewriting its job
+// record after a DLQ redrive keeps the count, so the MaxRetries cap trips.
+func TestWorkerWriteKeepsRetryCount(t *testing.T) {
+	table := newFakeDynamo()
+	table.on["UpdateItem"] = incrementRetryCount
+	table.on["PutItem"] = putIfRetryCountAtMost
+	s := newFakeDynamoStore(t, table)
+	ctx := context.Background()
+
+	if err := s.PutDownloadJob(ctx, "s1", &DownloadJob{ID: "dl-1", Status: "pending"}); err != nil {
+		t.Fatalf("PutDownloadJob: %v", err)
+	}
+	if _, err := s.IncrementJobRetryCount(ctx, "s1", "dl-1", "pending", 3); err != nil {
+		t.Fatalf("IncrementJobRetryCount: %v", err)
+	}
+	if err := s.PutDownloadJob(ctx, "s1", &DownloadJob{ID: "dl-1", Status: "processing"}); err != nil {
+		t.Fatalf("worker PutDownloadJob: %v", err)
+	}
+
+	job, err := s.GetDownloadJob(ctx, "s1", "dl-1")
+	if err != nil || job == nil {
+		t.Fatalf("GetDownloadJob = %v, %v", job, err)
+	}
+	if job.Status != "processing" || job.RetryCount != 1 {
+		t.Errorf("job = status %q, retryCount %d; want processing and 1", job.Status, job.RetryCount)
+	}
+	if _, err := s.IncrementJobRetryCount(ctx, "s1", "dl-1", "pending", 1); !errors.Is(err, ErrRetryLimit) {
+		t.Errorf("retry past the cap after a worker write: err = %v, want ErrRetryLimit", err)
+	}
+}
@@ -0,0 +1,177 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Enhancement pause/resume (DDR-095) ---
+
+// Enhancement job statuses. "paused" is set by the API; workers that see it
+// leave their item pending so a resumed run picks it up.
+const (
+	EnhancementStatusPending    = "pending"
+	EnhancementStatusProcessing = "processing"
+	EnhancementStatusPaused     = "paused"
+	EnhancementStatusComplete   = "complete"
+)
+
+// ErrStatusConflict is returned when a conditional status transition does not
+// apply because the job is not in the expected state.
+var ErrStatusConflict = errors.New("job status does not allow this transition")
+
+// PauseEnhancementJob sets a pending or processing job to "paused". Items
+// already being enhanced finish normally; items not yet started are skipped
+// by the workers. Returns ErrStatusConflict if the job is complete, errored,
+// or already paused.
+func (s *DynamoStore) PauseEnhancementJob(ctx context.Context, sessionID, jobID string) error {
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: sessionPK(sessionID)},
+			"SK": &types.AttributeValueMemberS{Value: skEnhance + jobID},
+		},
+		UpdateExpression:    aws.String("SET #st = :paused, pausedAt = :now"),
+		ConditionExpression: aws.String("#st IN (:pending, :processing) AND completedCount < totalCount"),
+		ExpressionAttributeNames: map[string]string{
+			"#st": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":paused":     &types.AttributeValueMemberS{Value: EnhancementStatusPaused},
+			":pending":    &types.AttributeValueMemberS{Value: EnhancementStatusPending},
+			":processing": &types.AttributeValueMemberS{Value: EnhancementStatusProcessing},
+			":now":        &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Unix(), 10)},
+		},
+	})
+	if err != nil {
+		return conditionalErr(fmt.Sprintf("PauseEnhancementJob %s/%s", sessionID, jobID), err)
+	}
+
+	log.Debug().Str("sessionId", sessionID).Str("jobId", jobID).Msg("Enhancement job paused")
+	return nil
+}
+
+// ResumeEnhancementJob sets a paused job back to "processing" and increments
+// resumeCount, which the caller uses to name the new pipeline execution.
+// Returns the new resume count, or ErrStatusConflict if the job is not paused.
+func (s *DynamoStore) ResumeEnhancementJob(ctx context.Context, sessionID, jobID string) (int, error) {
+	result, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: sessionPK(sessionID)},
+			"SK": &types.AttributeValueMemberS{Value: skEnhance + jobID},
+		},
+		UpdateExpression:    aws.String("SET #st = :processing ADD resumeCount :inc REMOVE pausedAt"),
+		ConditionExpression: aws.String("#st = :paused"),
+		ExpressionAttributeNames: map[string]string{
+			"#st": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":processing": &types.AttributeValueMemberS{Value: EnhancementStatusProcessing},
+			":paused":     &types.AttributeValueMemberS{Value: EnhancementStatusPaused},
+			":inc":        &types.AttributeValueMemberN{Value: "1"},
+		},
+		ReturnValues: types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		return 0, conditionalErr(fmt.Sprintf("ResumeEnhancementJob %s/%s", sessionID, jobID), err)
+	}
+
+	resumeCount := extractIntAttr(result.Attributes, "resumeCount")
+	log.Debug().Str("sessionId", sessionID).Str("jobId", jobID).Int("resumeCount", resumeCount).Msg("Enhancement job resumed")
+	return resumeCount, nil
+}
+
+// conditionalErr maps a failed condition expression to ErrStatusConflict.
+func conditionalErr(op string, err error) error {
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return fmt.Errorf("%s: %w", op, ErrStatusConflict)
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+// Checkpoint decides whether a pipeline invocation for key should run. It
+// resolves the item's index in Items (hint is the index the pipeline sent,
+// which is only correct for the original run) and returns run=false when the
+// job is paused or the item already finished, so a resumed run restarts
+// from the first unprocessed item without redoing finished ones.
+// index is -1 if key is not part of the job.
+func (j *EnhancementJob) Checkpoint(key string, hint int) (index int, run bool) {
+	index = j.itemIndex(key, hint)
+	if index < 0 || j.Status == EnhancementStatusPaused {
+		return index, false
+	}
+	return index, !j.Items[index].done()
+}
+
+// EnhancementJobReader reads an enhancement job record.
+type EnhancementJobReader interface {
+	GetEnhancementJob(ctx context.Context, sessionID, jobID string) (*EnhancementJob, error)
+}
+
+// CheckpointItem consults the job record before a worker processes an item.
+// It returns the item's index in the job, the item as stored and, when the
+// item should not run (job paused, or item already finished or skipped),
+// the phase to report back to Step Functions. Without a readable job record
+// the item runs as before, at the hinted index with a zero item.
+func CheckpointItem(ctx context.Context, jobs EnhancementJobReader, sessionID, jobID, key string, hint int) (int, EnhancementItem, string) {
+	job, err := jobs.GetEnhancementJob(ctx, sessionID, jobID)
+	if err != nil || job == nil {
+		log.Warn().Err(err).Str("jobId", jobID).Msg("Enhancement job not readable — skipping checkpoint")
+		return hint, EnhancementItem{}, ""
+	}
+	index, run := job.Checkpoint(key, hint)
+	switch {
+	case index < 0:
+		return hint, EnhancementItem{}, ""
+	case run:
+		return index, job.Items[index], ""
+	case job.Status == EnhancementStatusPaused:
+		return index, job.Items[index], EnhancementStatusPaused
+	default:
+		return index, job.Items[index], job.Items[index].Phase
+	}
+}
+
+// PendingKeys returns the keys of items that have no result yet, in job order.
+func (j *EnhancementJob) PendingKeys() []string {
+	var keys []string
+	for _, item := range j.Items {
+		if !item.done() {
+			keys = append(keys, item.Key)
+		}
+	}
+	return keys
+}
+
+func (j *EnhancementJob) itemIndex(key string, hint int) int {
+	if hint >= 0 && hint < len(j.Items) && j.Items[hint].Key == key {
+		return hint
+	}
+	for i, item := range j.Items {
+		if item.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// done reports whether the item reached a terminal phase. An item left in
+// an in-flight phase by an interrupted run is not done, so a resumed run
+// processes it again.
+func (it EnhancementItem) done() bool {
+	switch it.Phase {
+	case "complete", "error", "skipped", "feedback":
+		return true
+	}
+	return false
+}
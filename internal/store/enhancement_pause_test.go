@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func pauseTestJob(status string) *EnhancementJob {
+	return &EnhancementJob{
+		Status: status,
+		Items: []EnhancementItem{
+			{Key: "s/a.jpg", Phase: "complete"},
+			{Key: "s/b.jpg", Phase: "pending"},
+			{Key: "s/c.mp4", Phase: ""},
+			{Key: "s/d.jpg", Phase: "error"},
+			{Key: "s/e.jpg", Phase: "phase2"},
+			{Key: "s/f.jpg", Phase: "skipped"},
+		},
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    string
+		key       string
+		hint      int
+		wantIndex int
+		wantRun   bool
+	}{
+		{"pending item at hint", EnhancementStatusProcessing, "s/b.jpg", 1, 1, true},
+		{"stale hint resolved by key", EnhancementStatusProcessing, "s/c.mp4", 0, 2, true},
+		{"finished item skipped", EnhancementStatusProcessing, "s/a.jpg", 0, 0, false},
+		{"errored item skipped", EnhancementStatusProcessing, "s/d.jpg", 3, 3, false},
+		{"interrupted item rerun", EnhancementStatusProcessing, "s/e.jpg", 4, 4, true},
+		{"user-skipped item skipped", EnhancementStatusProcessing, "s/f.jpg", 5, 5, false},
+		{"paused job skips pending item", EnhancementStatusPaused, "s/b.jpg", 1, 1, false},
+		{"unknown key", EnhancementStatusProcessing, "s/x.jpg", 0, -1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, run := pauseTestJob(tt.status).Checkpoint(tt.key, tt.hint)
+			if index != tt.wantIndex || run != tt.wantRun {
+				t.Errorf("Checkpoint(%q, %d) = (%d, %v), want (%d, %v)",
+					tt.key, tt.hint, index, run, tt.wantIndex, tt.wantRun)
+			}
+		})
+	}
+}
+
+func TestPendingKeys(t *testing.T) {
+	got := pauseTestJob(EnhancementStatusPaused).PendingKeys()
+	want := []string{"s/b.jpg", "s/c.mp4", "s/e.jpg"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PendingKeys() = %v, want %v", got, want)
+	}
+}
+
+type fakeJobReader struct {
+	job *EnhancementJob
+	err error
+}
+
+func (f fakeJobReader) GetEnhancementJob(context.Context, string, string) (*EnhancementJob, error) {
+	return f.job, f.err
+}
+
+func TestCheckpointItem(t *testing.T) {
+	tests := []struct {
+		name      string
+		jobs      fakeJobReader
+		key       string
+		hint      int
+		wantIndex int
+		wantKey   string
+		wantSkip  string
+	}{
+		{"pending item runs", fakeJobReader{job: pauseTestJob(EnhancementStatusProcessing)}, "s/b.jpg", 0, 1, "s/b.jpg", ""},
+		{"finished item reports its phase", fakeJobReader{job: pauseTestJob(EnhancementStatusProcessing)}, "s/a.jpg", 0, 0, "s/a.jpg", "complete"},
+		{"paused job", fakeJobReader{job: pauseTestJob(EnhancementStatusPaused)}, "s/b.jpg", 1, 1, "s/b.jpg", EnhancementStatusPaused},
+		{"unknown key runs at hint", fakeJobReader{job: pauseTestJob(EnhancementStatusProcessing)}, "s/x.jpg", 2, 2, "", ""},
+		{"unreadable job runs at hint", fakeJobReader{err: errors.New("throttled")}, "s/a.jpg", 0, 0, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, item, skip := CheckpointItem(context.Background(), tt.jobs, "s", "enh-1", tt.key, tt.hint)
+			if index != tt.wantIndex || item.Key != tt.wantKey || skip != tt.wantSkip {
+				t.Errorf("CheckpointItem(%q, %d) = (%d, %q, %q), want (%d, %q, %q)",
+					tt.key, tt.hint, index, item.Key, skip, tt.wantIndex, tt.wantKey, tt.wantSkip)
+			}
+		})
+	}
+}
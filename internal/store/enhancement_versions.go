@@ -0,0 +1,74 @@
+package store
+
+import "fmt"
+
+// --- Enhancement versions (DDR-181) ---
+
+// VersionList returns the item's versions, oldest first. An item enhanced
+// before versions were recorded reports its current result as version 1.
+func (it EnhancementItem) VersionList() []EnhancementVersion {
+	if len(it.Versions) > 0 || it.EnhancedKey == "" {
+		return it.Versions
+	}
+	return []EnhancementVersion{{
+		Version:            1,
+		EnhancedKey:        it.EnhancedKey,
+		EnhancedThumbKey:   it.EnhancedThumbKey,
+		CleanKey:           it.CleanKey,
+		LegibilityWarnings: it.LegibilityWarnings,
+		Redactions:         it.Redactions,
+	}}
+}
+
+// Current returns the number of the version the item shows, or 0 if it has
+// no result yet.
+func (it EnhancementItem) Current() int {
+	if it.CurrentVersion > 0 {
+		return it.CurrentVersion
+	}
+	versions := it.VersionList()
+	if len(versions) == 0 {
+		return 0
+	}
+	return versions[len(versions)-1].Version
+}
+
+// NextVersion returns the number the item's next result is saved as. It
+// follows the newest version, not the current one, so a result made after a
+// rollback never replaces a later version.
+func (it EnhancementItem) NextVersion() int {
+	versions := it.VersionList()
+	if len(versions) == 0 {
+		return 1
+	}
+	return versions[len(versions)-1].Version + 1
+}
+
+// AddVersion records v as the item's newest version and makes it current.
+func (it *EnhancementItem) AddVersion(v EnhancementVersion) {
+	it.Versions = append(it.VersionList(), v)
+	it.setCurrent(v)
+}
+
+// RollBack makes version n the item's current result. Later versions are
+// kept, so the user can move forward again.
+func (it *EnhancementItem) RollBack(n int) error {
+	versions := it.VersionList()
+	for _, v := range versions {
+		if v.Version == n {
+			it.Versions = versions
+			it.setCurrent(v)
+			return nil
+		}
+	}
+	return fmt.Errorf("item %s has no version %d", it.Key, n)
+}
+
+func (it *EnhancementItem) setCurrent(v EnhancementVersion) {
+	it.EnhancedKey = v.EnhancedKey
+	it.EnhancedThumbKey = v.EnhancedThumbKey
+	it.CleanKey = v.CleanKey
+	it.LegibilityWarnings = v.LegibilityWarnings
+	it.Redactions = v.Redactions
+	it.CurrentVersion = v.Version
+}
@@ -0,0 +1,59 @@
+package store
+
+import "testing"
+
+func TestEnhancementVersions(t *testing.T) {
+	// Enhanced before versions were recorded: the result is version 1.
+	item := EnhancementItem{Key: "s/a.jpg", EnhancedKey: "s/enhanced/a.jpg", EnhancedThumbKey: "s/thumbnails/enhanced-a.jpg"}
+	if got := item.Current(); got != 1 {
+		t.Fatalf("Current() = %d, want 1", got)
+	}
+	if got := item.NextVersion(); got != 2 {
+		t.Fatalf("NextVersion() = %d, want 2", got)
+	}
+
+	item.AddVersion(EnhancementVersion{Version: 2, EnhancedKey: "s/enhanced/v2/a.jpg", Feedback: "brighter"})
+	item.AddVersion(EnhancementVersion{Version: 3, EnhancedKey: "s/enhanced/v3/a.jpg", Feedback: "less red"})
+	if len(item.Versions) != 3 || item.Versions[0].EnhancedKey != "s/enhanced/a.jpg" {
+		t.Fatalf("versions = %+v", item.Versions)
+	}
+	if item.EnhancedKey != "s/enhanced/v3/a.jpg" || item.Current() != 3 {
+		t.Errorf("current = %d %s, want 3", item.Current(), item.EnhancedKey)
+	}
+
+	if err := item.RollBack(1); err != nil {
+		t.Fatal(err)
+	}
+	if item.EnhancedKey != "s/enhanced/a.jpg" || item.EnhancedThumbKey != "s/thumbnails/enhanced-a.jpg" || item.Current() != 1 {
+		t.Errorf("after rollback: %+v", item)
+	}
+	if got := item.NextVersion(); got != 4 {
+		t.Errorf("NextVersion() after rollback = %d, want 4", got)
+	}
+	if err := item.RollBack(7); err == nil {
+		t.Error("rollback to a missing version accepted")
+	}
+}
+
+func TestEnhancementVersionsNoResult(t *testing.T) {
+	item := EnhancementItem{Key: "s/a.jpg", Phase: "error"}
+	if item.Current() != 0 || item.NextVersion() != 1 || len(item.VersionList()) != 0 {
+		t.Errorf("item without a result: current %d, next %d", item.Current(), item.NextVersion())
+	}
+}
+
+func TestEnhancementVersionsRedactions(t *testing.T) {
+	face := []RedactRegion{{Kind: "face", X: 10, Y: 10, W: 20, H: 20}}
+	item := EnhancementItem{Key: "s/a.jpg"}
+	item.AddVersion(EnhancementVersion{Version: 1, EnhancedKey: "s/enhanced/a.jpg", Redactions: face})
+	item.AddVersion(EnhancementVersion{Version: 2, EnhancedKey: "s/enhanced/v2/a.jpg"})
+	if len(item.Redactions) != 0 {
+		t.Errorf("version 2 redactions = %+v, want none", item.Redactions)
+	}
+	if err := item.RollBack(1); err != nil {
+		t.Fatal(err)
+	}
+	if len(item.Redactions) != 1 {
+		t.Errorf("after rollback redactions = %+v, want version 1's", item.Redactions)
+	}
+}
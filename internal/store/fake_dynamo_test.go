@@ -0,0 +1,235 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// fakeItem is an item in DynamoDB's JSON wire format: attribute name →
+// typed value, e.g. {"S":"x"} or {"N":"1"}.
+type fakeItem map[string]json.RawMessage
+
+// S returns a string attribute, or "" if the item has none by that name.
+func (it fakeItem) S(name string) string {
+	var v struct{ S string }
+	json.Unmarshal(it[name], &v)
+	return v.S
+}
+
+// N returns a number attribute as written, or "" if absent.
+func (it fakeItem) N(name string) string {
+	var v struct{ N string }
+	json.Unmarshal(it[name], &v)
+	return v.N
+}
+
+func (it fakeItem) key() string {
+	return it.S("PK") + "|" + it.S("SK")
+}
+
+// fakeRequest holds the fields of a DynamoDB request the fake reads.
+type fakeRequest struct {
+	Item                      fakeItem
+	Key                       fakeItem
+	KeyConditionExpression    string
+	UpdateExpression          string
+	ConditionExpression       string
+	ExpressionAttributeNames  map[string]string
+	ExpressionAttributeValues fakeItem
+	RequestItems              map[string][]struct {
+		PutRequest    *struct{ Item fakeItem }
+		DeleteRequest *struct{ Key fakeItem }
+	}
+	TransactItems []struct {
+		Put    *struct{ Item fakeItem }
+		Delete *struct{ Key fakeItem }
+	}
+}
+
+// fakeOp serves one operation. It runs with the table locked and returns
+// the response body, or an error whose text is the DynamoDB error type to
+// answer with, such as errFakeConditionFailed.
+type fakeOp func(f *fakeDynamo, req *fakeRequest) (any, error)
+
+var (
+	errFakeConditionFailed = errors.New("ConditionalCheckFailedException")
+	errFakeTxCanceled      = errors.New("TransactionCanceledException")
+	errFakeUnsupported     = errors.New("ValidationException")
+)
+
+// fakeDynamo is an in-memory DynamoDB endpoint for store tests. It keeps
+// items by PK and SK and serves GetItem, PutItem, DeleteItem, BatchWriteItem,
+// TransactWriteItems, and Query on PK with an optional begins_with on SK.
+// Tests that need conditions or update expressions applied register the
+// operation in on, which takes precedence over the built-in handler.
+type fakeDynamo struct {
+	mu    sync.Mutex
+	items map[string]fakeItem // "PK|SK" → item
+	calls map[string]int      // operation → requests served
+	on    map[string]fakeOp
+}
+
+func newFakeDynamo() *fakeDynamo {
+	return &fakeDynamo{items: map[string]fakeItem{}, calls: map[string]int{}, on: map[string]fakeOp{}}
+}
+
+// put stores an item under pk and sk with the given extra attributes.
+// Callers that run while the table is served must hold mu.
+func (f *fakeDynamo) put(pk, sk string, attrs fakeItem) {
+	item := fakeItem{
+		"PK": json.RawMessage(`{"S":"` + pk + `"}`),
+		"SK": json.RawMessage(`{"S":"` + sk + `"}`),
+	}
+	for name, v := range attrs {
+		item[name] = v
+	}
+	f.items[pk+"|"+sk] = item
+}
+
+// item returns the item stored under pk and sk, or nil.
+func (f *fakeDynamo) item(pk, sk string) fakeItem {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.items[pk+"|"+sk]
+}
+
+// served returns how many op requests the table has served.
+func (f *fakeDynamo) served(op string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[op]
+}
+
+// keys returns the "PK|SK" of every stored item, sorted.
+func (f *fakeDynamo) keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	keys := make([]string, 0, len(f.items))
+	for k := range f.items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (f *fakeDynamo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	target := r.Header.Get("X-Amz-Target")
+	op := target[strings.LastIndex(target, ".")+1:]
+	var req fakeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	f.calls[op]++
+	handle, ok := f.on[op]
+	if !ok {
+		handle, ok = fakeOps[op]
+	}
+	var resp any
+	err := errFakeUnsupported
+	if ok {
+		resp, err = handle(f, &req)
+	}
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"__type":  "com.amazonaws.dynamodb.v20120810#" + err.Error(),
+			"message": op + " failed in the fake table",
+		})
+		return
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+var keyConditionPrefix = regexp.MustCompile(`begins_with\(SK, (:\w+)\)`)
+
+var fakeOps = map[string]fakeOp{
+	"GetItem": func(f *fakeDynamo, req *fakeRequest) (any, error) {
+		if item, ok := f.items[req.Key.key()]; ok {
+			return map[string]any{"Item": item}, nil
+		}
+		return struct{}{}, nil
+	},
+	"PutItem": func(f *fakeDynamo, req *fakeRequest) (any, error) {
+		f.items[req.Item.key()] = req.Item
+		return struct{}{}, nil
+	},
+	"DeleteItem": func(f *fakeDynamo, req *fakeRequest) (any, error) {
+		delete(f.items, req.Key.key())
+		return struct{}{}, nil
+	},
+	"BatchWriteItem": func(f *fakeDynamo, req *fakeRequest) (any, error) {
+		for _, writes := range req.RequestItems {
+			for _, wr := range writes {
+				switch {
+				case wr.PutRequest != nil:
+					f.items[wr.PutRequest.Item.key()] = wr.PutRequest.Item
+				case wr.DeleteRequest != nil:
+					delete(f.items, wr.DeleteRequest.Key.key())
+				}
+			}
+		}
+		return struct{}{}, nil
+	},
+	"TransactWriteItems": func(f *fakeDynamo, req *fakeRequest) (any, error) {
+		for _, it := range req.TransactItems {
+			switch {
+			case it.Put != nil:
+				f.items[it.Put.Item.key()] = it.Put.Item
+			case it.Delete != nil:
+				delete(f.items, it.Delete.Key.key())
+			}
+		}
+		return struct{}{}, nil
+	},
+	"Query": func(f *fakeDynamo, req *fakeRequest) (any, error) {
+		pk := req.ExpressionAttributeValues.S(":pk")
+		prefix := ""
+		if m := keyConditionPrefix.FindStringSubmatch(req.KeyConditionExpression); m != nil {
+			prefix = req.ExpressionAttributeValues.S(m[1])
+		}
+		items := []fakeItem{}
+		for _, item := range f.items {
+			if item.S("PK") == pk && strings.HasPrefix(item.S("SK"), prefix) {
+				items = append(items, item)
+			}
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i].S("SK") < items[j].S("SK") })
+		return map[string]any{"Items": items, "Count": len(items)}, nil
+	},
+}
+
+// newFakeDynamoClient serves a DynamoDB client from table for the test's
+// lifetime.
+func newFakeDynamoClient(t *testing.T, table http.Handler) *dynamodb.Client {
+	t.Helper()
+	srv := httptest.NewServer(table)
+	t.Cleanup(srv.Close)
+	return dynamodb.New(dynamodb.Options{
+		Region:           "us-east-1",
+		BaseEndpoint:     aws.String(srv.URL),
+		Credentials:      aws.AnonymousCredentials{},
+		RetryMaxAttempts: 1,
+	})
+}
+
+// newFakeDynamoStore returns a DynamoStore backed by table.
+func newFakeDynamoStore(t *testing.T, table http.Handler) *DynamoStore {
+	t.Helper()
+	return NewDynamoStore(newFakeDynamoClient(t, table), "test-table")
+}
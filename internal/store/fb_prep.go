@@ -1,5 +1,7 @@
 package store
 
+import "github.com/fpang/ai-social-media-helper/internal/metrics"
+
 // FBPrepJob represents a Facebook post preparation job (DynamoDB SK = FBPREP#{jobId}).
 // Each job processes a batch of media items and produces captions, location tags, and timestamps.
 type FBPrepJob struct {
@@ -13,15 +15,18 @@ type FBPrepJob struct {
 	BatchJobIDs []string     `json:"batchJobIds,omitempty" dynamodbav:"batchJobIds,omitempty"` // When multiple batches (>10 videos)
 	// GCSPathsForCleanup holds gs:// URIs of videos uploaded for batch mode; deleted after collect.
 	GCSPathsForCleanup []string `json:"gcsPathsForCleanup,omitempty" dynamodbav:"gcsPathsForCleanup,omitempty"`
-	InputTokens  int         `json:"inputTokens,omitempty"  dynamodbav:"inputTokens,omitempty"`
-	OutputTokens int         `json:"outputTokens,omitempty" dynamodbav:"outputTokens,omitempty"`
+	InputTokens        int      `json:"inputTokens,omitempty"  dynamodbav:"inputTokens,omitempty"`
+	OutputTokens       int      `json:"outputTokens,omitempty" dynamodbav:"outputTokens,omitempty"`
 	// PreEnrichLocations stores Maps-verified location tags resolved before batch submission
 	// (DDR-085). Keys are string item indices ("0", "1", ...). Used to compare against
 	// batch model output in handleCollectBatch to evaluate pre-enrichment accuracy.
 	PreEnrichLocations map[string]string `json:"preEnrichLocations,omitempty" dynamodbav:"preEnrichLocations,omitempty"`
-	CreatedAt   string       `json:"createdAt" dynamodbav:"createdAt"`
-	UpdatedAt   string       `json:"updatedAt" dynamodbav:"updatedAt"`
-	Error       string       `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	CreatedAt          string            `json:"createdAt" dynamodbav:"createdAt"`
+	UpdatedAt          string            `json:"updatedAt" dynamodbav:"updatedAt"`
+	Error              string            `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	RetryCount         int               `json:"retryCount,omitempty" dynamodbav:"retryCount,omitempty"`
+	// Telemetry is the job's Gemini, S3 and ffmpeg usage so far (DDR-118).
+	Telemetry *metrics.JobTelemetry `json:"perJobTelemetry,omitempty" dynamodbav:"perJobTelemetry,omitempty"`
 }
 
 // FBPrepItem represents a single media item's Facebook prep output.
@@ -0,0 +1,147 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Batch enhancement feedback (DDR-182) ---
+
+const skFeedbackBatch = "ENHFB#"
+
+// Feedback batch and batch item statuses.
+const (
+	FeedbackBatchProcessing = "processing"
+	FeedbackBatchComplete   = "complete"
+	FeedbackBatchError      = "error"
+)
+
+// FeedbackRoundTimeout is how long a batch item may stay processing. The
+// enhance Lambda runs for at most 5 minutes and an async invocation is
+// tried up to three times, so a round still running after this has crashed.
+const FeedbackRoundTimeout = 30 * time.Minute
+
+// FeedbackBatch is one feedback instruction applied to several photos of an
+// enhancement job (DynamoDB SK = ENHFB#{batchId}). Each photo is a separate
+// feedback round; Items tracks them. Status and CompletedCount are not
+// stored but derived from the items by Settle.
+type FeedbackBatch struct {
+	ID             string              `json:"id" dynamodbav:"-"`
+	SessionID      string              `json:"-" dynamodbav:"-"`
+	JobID          string              `json:"jobId" dynamodbav:"jobId"`
+	Feedback       string              `json:"feedback" dynamodbav:"feedback"`
+	Status         string              `json:"status" dynamodbav:"-"`
+	Items          []FeedbackBatchItem `json:"items" dynamodbav:"items"`
+	CompletedCount int                 `json:"completedCount" dynamodbav:"-"`
+	CreatedAt      int64               `json:"createdAt" dynamodbav:"createdAt"` // Unix seconds
+}
+
+// FeedbackBatchItem is the state of one photo's feedback round. Version is
+// the enhancement version the round produced (DDR-181).
+type FeedbackBatchItem struct {
+	Key     string `json:"key" dynamodbav:"key"`
+	Status  string `json:"status" dynamodbav:"status"`
+	Version int    `json:"version,omitempty" dynamodbav:"version,omitempty"`
+	Error   string `json:"error,omitempty" dynamodbav:"error,omitempty"`
+}
+
+// Settle sets Status and CompletedCount from the items: processing while any
+// round runs, error if every round failed, complete otherwise.
+func (b *FeedbackBatch) Settle() {
+	done, failed := 0, 0
+	for _, item := range b.Items {
+		switch item.Status {
+		case FeedbackBatchComplete:
+			done++
+		case FeedbackBatchError:
+			done++
+			failed++
+		}
+	}
+	b.CompletedCount = done
+	switch {
+	case done < len(b.Items):
+		b.Status = FeedbackBatchProcessing
+	case failed == len(b.Items):
+		b.Status = FeedbackBatchError
+	default:
+		b.Status = FeedbackBatchComplete
+	}
+}
+
+// ExpireStale marks the items still processing FeedbackRoundTimeout after
+// the batch was created as failed, so a batch whose worker crashed settles.
+// A round that reports later overwrites its item.
+func (b *FeedbackBatch) ExpireStale(now time.Time) {
+	if now.Sub(time.Unix(b.CreatedAt, 0)) < FeedbackRoundTimeout {
+		return
+	}
+	for i, item := range b.Items {
+		if item.Status == FeedbackBatchProcessing {
+			b.Items[i] = FeedbackBatchItem{Key: item.Key, Status: FeedbackBatchError, Error: "feedback round timed out"}
+		}
+	}
+}
+
+func (s *DynamoStore) PutFeedbackBatch(ctx context.Context, sessionID string, batch *FeedbackBatch) error {
+	if err := s.putItem(ctx, sessionPK(sessionID), skFeedbackBatch+batch.ID, batch); err != nil {
+		return fmt.Errorf("put feedback batch %s/%s: %w", sessionID, batch.ID, err)
+	}
+	log.Debug().Str("sessionId", sessionID).Str("batchId", batch.ID).Int("items", len(batch.Items)).Msg("Feedback batch persisted")
+	return nil
+}
+
+// GetFeedbackBatch returns a batch with stale rounds expired and its status
+// settled, or nil if it does not exist.
+func (s *DynamoStore) GetFeedbackBatch(ctx context.Context, sessionID, batchID string) (*FeedbackBatch, error) {
+	var batch FeedbackBatch
+	found, err := s.getItem(ctx, sessionPK(sessionID), skFeedbackBatch+batchID, &batch)
+	if err != nil {
+		return nil, fmt.Errorf("get feedback batch %s/%s: %w", sessionID, batchID, err)
+	}
+	if !found {
+		return nil, nil
+	}
+	batch.ID = batchID
+	batch.SessionID = sessionID
+	batch.ExpireStale(time.Now())
+	batch.Settle()
+	return &batch, nil
+}
+
+// UpdateFeedbackBatchItem atomically sets one item of a batch. Rounds finish
+// concurrently, so each writes only its own index (the same approach as
+// UpdateEnhancementItemResult, DDR-061).
+func (s *DynamoStore) UpdateFeedbackBatchItem(ctx context.Context, sessionID, batchID string, itemIndex int, item FeedbackBatchItem) error {
+	itemAV, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("marshal feedback batch item: %w", err)
+	}
+
+	idx := strconv.Itoa(itemIndex)
+	_, err = s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: sessionPK(sessionID)},
+			"SK": &types.AttributeValueMemberS{Value: skFeedbackBatch + batchID},
+		},
+		UpdateExpression:    aws.String("SET items[" + idx + "] = :item"),
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":item": &types.AttributeValueMemberM{Value: itemAV},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("UpdateFeedbackBatchItem %s/%s[%d]: %w", sessionID, batchID, itemIndex, err)
+	}
+	log.Debug().Str("sessionId", sessionID).Str("batchId", batchID).Int("itemIndex", itemIndex).Str("status", item.Status).Msg("Feedback batch item updated")
+	return nil
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFeedbackBatchSettle(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []string
+		want      string
+		completed int
+	}{
+		{"all running", []string{"processing", "processing"}, FeedbackBatchProcessing, 0},
+		{"some running", []string{"complete", "error", "processing"}, FeedbackBatchProcessing, 2},
+		{"all done", []string{"complete", "complete"}, FeedbackBatchComplete, 2},
+		{"partly failed", []string{"complete", "error"}, FeedbackBatchComplete, 2},
+		{"all failed", []string{"error", "error"}, FeedbackBatchError, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch := &FeedbackBatch{}
+			for _, s := range tt.statuses {
+				batch.Items = append(batch.Items, FeedbackBatchItem{Status: s})
+			}
+			batch.Settle()
+			if batch.Status != tt.want || batch.CompletedCount != tt.completed {
+				t.Errorf("Settle() = %s, %d; want %s, %d", batch.Status, batch.CompletedCount, tt.want, tt.completed)
+			}
+		})
+	}
+}
+
+func TestFeedbackBatchExpireStale(t *testing.T) {
+	created := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	newBatch := func() *FeedbackBatch {
+		return &FeedbackBatch{CreatedAt: created.Unix(), Items: []FeedbackBatchItem{
+			{Key: "a.jpg", Status: FeedbackBatchComplete, Version: 2},
+			{Key: "b.jpg", Status: FeedbackBatchProcessing},
+		}}
+	}
+
+	batch := newBatch()
+	batch.ExpireStale(created.Add(FeedbackRoundTimeout - time.Minute))
+	if batch.Items[1].Status != FeedbackBatchProcessing {
+		t.Errorf("running round expired early: %+v", batch.Items[1])
+	}
+
+	batch = newBatch()
+	batch.ExpireStale(created.Add(FeedbackRoundTimeout))
+	batch.Settle()
+	if batch.Items[1].Status != FeedbackBatchError || batch.Items[1].Key != "b.jpg" || batch.Items[0].Version != 2 {
+		t.Errorf("items = %+v", batch.Items)
+	}
+	if batch.Status != FeedbackBatchComplete || batch.CompletedCount != 2 {
+		t.Errorf("settled as %s, %d", batch.Status, batch.CompletedCount)
+	}
+}
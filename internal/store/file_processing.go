@@ -33,8 +33,33 @@ type FileResult struct {
 	FileSize     int64             `json:"fileSize" dynamodbav:"fileSize"`
 	Converted    bool              `json:"converted" dynamodbav:"converted"`
 	Fingerprint  string            `json:"fingerprint,omitempty" dynamodbav:"fingerprint,omitempty"`
+	DHash        string            `json:"dhash,omitempty" dynamodbav:"dhash,omitempty"`               // perceptual hash of the thumbnail (DDR-110)
+	QualityIssue string            `json:"qualityIssue,omitempty" dynamodbav:"qualityIssue,omitempty"` // local quality check failure (DDR-112)
 	Metadata     map[string]string `json:"metadata,omitempty" dynamodbav:"metadata,omitempty"`
 	Error        string            `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	// Progress is the video compression percent while Status is
+	// "thumbnailed" (DDR-161).
+	Progress int `json:"progress,omitempty" dynamodbav:"progress,omitempty"`
+	// Sample is set for a video long enough that triage judges a sample of
+	// it instead of the whole clip (DDR-162).
+	Sample *VideoSample `json:"sample,omitempty" dynamodbav:"sample,omitempty"`
+	// ContentHash is the hex SHA-256 of the uploaded bytes, the key Gemini
+	// responses are cached under (DDR-166).
+	ContentHash string `json:"contentHash,omitempty" dynamodbav:"contentHash,omitempty"`
+}
+
+// VideoSample locates the triage sample of a long video: a JPEG sheet of
+// frames in S3 and the loudness of its audio in dBFS. The volumes are nil
+// when the video has no audio or it could not be measured. Keyframes marks
+// a sheet rendered for keyframe triage (DDR-186), which covers videos of
+// any length and tells Gemini the clip's duration.
+type VideoSample struct {
+	Key          string   `json:"key" dynamodbav:"key"`
+	OffsetsMs    []int64  `json:"offsetsMs" dynamodbav:"offsetsMs"`
+	MeanVolumeDB *float64 `json:"meanVolumeDb,omitempty" dynamodbav:"meanVolumeDb,omitempty"`
+	MaxVolumeDB  *float64 `json:"maxVolumeDb,omitempty" dynamodbav:"maxVolumeDb,omitempty"`
+	DurationMs   int64    `json:"durationMs,omitempty" dynamodbav:"durationMs,omitempty"`
+	Keyframes    bool     `json:"keyframes,omitempty" dynamodbav:"keyframes,omitempty"`
 }
 
 // FileProcessingStore provides operations on the dedicated media-file-processing
@@ -305,3 +330,211 @@ func (s *FileProcessingStore) GetSessionFileResults(ctx context.Context, session
 	log.Debug().Str("pk", pk).Int("resultCount", len(results)).Dur("duration", duration).Msg("GetSessionFileResults: query completed")
 	return results, nil
 }
+
+// skThumbIndex prefixes thumbnail existence index items (DDR-091).
+// Stored under PK={sessionId}, SK=thumb#{filename} alongside session file results.
+const skThumbIndex = "thumb#"
+
+// PutThumbnailIndex records that a pre-generated thumbnail exists for an
+// uploaded file (DDR-091). The index lives for SessionTTL rather than
+// FileProcessingTTL because thumbnails outlive triage processing.
+func (s *FileProcessingStore) PutThumbnailIndex(ctx context.Context, sessionID, filename, thumbnailKey string) error {
+	pk := sessionID
+	sk := skThumbIndex + filename
+
+	_, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &s.tableName,
+		Item: map[string]types.AttributeValue{
+			"PK":           &types.AttributeValueMemberS{Value: pk},
+			"SK":           &types.AttributeValueMemberS{Value: sk},
+			"thumbnailKey": &types.AttributeValueMemberS{Value: thumbnailKey},
+			"expiresAt":    &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt(), 10)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("PutItem thumbnail index PK=%s SK=%s: %w", pk, sk, err)
+	}
+	log.Debug().Str("pk", pk).Str("filename", filename).Str("thumbnailKey", thumbnailKey).Msg("Thumbnail index entry stored")
+	return nil
+}
+
+// GetThumbnailIndexEntry returns the indexed thumbnail key for one uploaded
+// file, or "" when none is indexed (DDR-091). Used by per-file callers that
+// have no use for the whole session index.
+func (s *FileProcessingStore) GetThumbnailIndexEntry(ctx context.Context, sessionID, filename string) (string, error) {
+	pk := sessionID
+	sk := skThumbIndex + filename
+
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: pk},
+			"SK": &types.AttributeValueMemberS{Value: sk},
+		},
+		ProjectionExpression: aws.String("thumbnailKey"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("GetItem thumbnail index PK=%s SK=%s: %w", pk, sk, err)
+	}
+	keyAttr, ok := result.Item["thumbnailKey"].(*types.AttributeValueMemberS)
+	if !ok {
+		return "", nil
+	}
+	return keyAttr.Value, nil
+}
+
+// GetThumbnailIndex returns every indexed thumbnail for a session as a
+// filename→thumbnailKey map (DDR-091). One query covers a whole session so
+// callers can cache the result and answer per-thumbnail lookups locally.
+func (s *FileProcessingStore) GetThumbnailIndex(ctx context.Context, sessionID string) (map[string]string, error) {
+	pk := sessionID
+
+	start := time.Now()
+	input := &dynamodb.QueryInput{
+		TableName:              &s.tableName,
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :skPrefix)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":       &types.AttributeValueMemberS{Value: pk},
+			":skPrefix": &types.AttributeValueMemberS{Value: skThumbIndex},
+		},
+		ProjectionExpression: aws.String("SK, thumbnailKey"),
+	}
+
+	index := make(map[string]string)
+	for {
+		result, err := s.client.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("Query thumbnail index PK=%s: %w", pk, err)
+		}
+		for _, item := range result.Items {
+			skAttr, ok := item["SK"].(*types.AttributeValueMemberS)
+			if !ok {
+				continue
+			}
+			keyAttr, ok := item["thumbnailKey"].(*types.AttributeValueMemberS)
+			if !ok || keyAttr.Value == "" {
+				continue
+			}
+			index[strings.TrimPrefix(skAttr.Value, skThumbIndex)] = keyAttr.Value
+		}
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+
+	log.Debug().Str("pk", pk).Int("entryCount", len(index)).Dur("duration", time.Since(start)).Msg("GetThumbnailIndex: query completed")
+	return index, nil
+}
+
+// sessionRows reads a session's rows: the session-level file results and
+// thumbnail index under PK={sessionId}, and the results and fingerprint
+// mappings of each triage job under PK={sessionId}#{jobId}. A non-empty
+// projection limits the attributes read.
+func (s *FileProcessingStore) sessionRows(ctx context.Context, sessionID string, triageJobIDs []string, projection string) ([]map[string]types.AttributeValue, error) {
+	pks := []string{sessionID}
+	for _, jobID := range triageJobIDs {
+		pks = append(pks, fileProcessingPK(sessionID, jobID))
+	}
+
+	var rows []map[string]types.AttributeValue
+	for _, pk := range pks {
+		input := &dynamodb.QueryInput{
+			TableName:              &s.tableName,
+			KeyConditionExpression: aws.String("PK = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: pk},
+			},
+		}
+		if projection != "" {
+			input.ProjectionExpression = aws.String(projection)
+		}
+		for {
+			result, err := s.client.Query(ctx, input)
+			if err != nil {
+				return nil, fmt.Errorf("Query file processing rows PK=%s: %w", pk, err)
+			}
+			rows = append(rows, result.Items...)
+			if result.LastEvaluatedKey == nil {
+				break
+			}
+			input.ExclusiveStartKey = result.LastEvaluatedKey
+		}
+	}
+	return rows, nil
+}
+
+// MoveSessionRecords copies the rows of sourceID and of the given triage
+// jobs to targetID (DDR-113), rekeying them and replacing sourceID in every
+// string as MergeSession does for the session table. Session-level rows
+// the target already has are kept (the target wins). The source rows are
+// left for DeleteSessionRecords. Returns the number of rows copied.
+func (s *FileProcessingStore) MoveSessionRecords(ctx context.Context, targetID, sourceID string, triageJobIDs []string) (int, error) {
+	rows, err := s.sessionRows(ctx, sourceID, triageJobIDs, "")
+	if err != nil {
+		return 0, err
+	}
+	targetRows, err := s.sessionRows(ctx, targetID, nil, "PK, SK")
+	if err != nil {
+		return 0, err
+	}
+	existing := make(map[string]bool, len(targetRows))
+	for _, row := range targetRows {
+		if sk, ok := row["SK"].(*types.AttributeValueMemberS); ok {
+			existing[sk.Value] = true
+		}
+	}
+
+	var moved []map[string]types.AttributeValue
+	for _, row := range rows {
+		pk, _ := row["PK"].(*types.AttributeValueMemberS)
+		sk, _ := row["SK"].(*types.AttributeValueMemberS)
+		if pk == nil || sk == nil || (pk.Value == sourceID && existing[sk.Value]) {
+			continue
+		}
+		out := make(map[string]types.AttributeValue, len(row))
+		for k, v := range row {
+			out[k] = replaceSessionID(v, sourceID, targetID)
+		}
+		out["PK"] = &types.AttributeValueMemberS{Value: targetID + strings.TrimPrefix(pk.Value, sourceID)}
+		out["SK"] = sk
+		moved = append(moved, out)
+	}
+	if len(moved) == 0 {
+		return 0, nil
+	}
+
+	table := &DynamoStore{client: s.client, tableName: s.tableName}
+	if err := table.batchPutItems(ctx, moved); err != nil {
+		return 0, fmt.Errorf("move file processing rows %s to %s: %w", sourceID, targetID, err)
+	}
+	log.Debug().Str("targetSessionId", targetID).Str("sourceSessionId", sourceID).Int("moved", len(moved)).Msg("MoveSessionRecords: file processing rows copied")
+	return len(moved), nil
+}
+
+// DeleteSessionRecords deletes a session's rows: the session-level file
+// results and thumbnail index under PK={sessionId}, and the results and
+// fingerprint mappings of each triage job under PK={sessionId}#{jobId}.
+// Returns the number of rows deleted.
+func (s *FileProcessingStore) DeleteSessionRecords(ctx context.Context, sessionID string, triageJobIDs []string) (int, error) {
+	rows, err := s.sessionRows(ctx, sessionID, triageJobIDs, "PK, SK")
+	if err != nil {
+		return 0, err
+	}
+	keys := make([]map[string]types.AttributeValue, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, map[string]types.AttributeValue{"PK": row["PK"], "SK": row["SK"]})
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	// The batching and retry logic is the session table's; only the table differs.
+	table := &DynamoStore{client: s.client, tableName: s.tableName}
+	if err := table.batchDeleteKeys(ctx, keys); err != nil {
+		return 0, fmt.Errorf("delete file processing rows for %s: %w", sessionID, err)
+	}
+
+	log.Debug().Str("sessionId", sessionID).Int("jobs", len(triageJobIDs)).Int("deleted", len(keys)).Msg("DeleteSessionRecords: file processing rows deleted")
+	return len(keys), nil
+}
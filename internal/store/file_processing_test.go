@@ -0,0 +1,34 @@
+package store
+
+import (
+	"context"
+	"testing"
+)
+
+func TestThumbnailIndexEntry(t *testing.T) {
+	table := newFakeDynamo()
+	s := NewFileProcessingStore(newFakeDynamoClient(t, table), "test-file-processing")
+	ctx := context.Background()
+
+	if err := s.PutThumbnailIndex(ctx, "s1", "IMG_0001.HEIC", "s1/thumbnails/IMG_0001.jpg"); err != nil {
+		t.Fatal(err)
+	}
+	item := table.item("s1", "thumb#IMG_0001.HEIC")
+	if item == nil {
+		t.Fatalf("index entry not stored under thumb#{filename}: %v", table.keys())
+	}
+	if item.N("expiresAt") == "" {
+		t.Error("index entry has no expiresAt")
+	}
+
+	got, err := s.GetThumbnailIndexEntry(ctx, "s1", "IMG_0001.HEIC")
+	if err != nil || got != "s1/thumbnails/IMG_0001.jpg" {
+		t.Errorf("indexed file: got %q, %v", got, err)
+	}
+	// The thumbnail worker generates a thumbnail on a miss.
+	for _, tc := range []struct{ session, file string }{{"s1", "IMG_0002.HEIC"}, {"s2", "IMG_0001.HEIC"}} {
+		if got, err := s.GetThumbnailIndexEntry(ctx, tc.session, tc.file); err != nil || got != "" {
+			t.Errorf("%s/%s: got %q, %v; want a miss", tc.session, tc.file, got, err)
+		}
+	}
+}
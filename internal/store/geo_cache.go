@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/fpang/ai-social-media-helper/internal/geo"
+)
+
+// --- Reverse geocoding cache (DDR-137) ---
+
+const (
+	geoPKPrefix = "GEO#"
+	skGeoPlace  = "PLACE"
+)
+
+// GeoCacheTTL is how long a reverse-geocoded place is kept. Place names
+// rarely change, and the cache is shared by every session and user.
+const GeoCacheTTL = 90 * 24 * time.Hour
+
+// GetPlace implements geo.Cache (DynamoDB PK = GEO#{geo.CacheKey},
+// SK = PLACE).
+func (s *DynamoStore) GetPlace(ctx context.Context, key string) (*geo.Place, bool, error) {
+	var p geo.Place
+	found, err := s.getItem(ctx, geoPKPrefix+key, skGeoPlace, &p)
+	if err != nil {
+		return nil, false, fmt.Errorf("get cached place: %w", err)
+	}
+	if !found {
+		return nil, false, nil
+	}
+	return &p, true, nil
+}
+
+// PutPlace implements geo.Cache. An empty place records a coordinate with
+// nothing there. It bypasses putItem to set GeoCacheTTL instead of SessionTTL.
+func (s *DynamoStore) PutPlace(ctx context.Context, key string, p *geo.Place) error {
+	item, err := attributevalue.MarshalMap(p)
+	if err != nil {
+		return fmt.Errorf("marshal place: %w", err)
+	}
+	item["PK"] = &types.AttributeValueMemberS{Value: geoPKPrefix + key}
+	item["SK"] = &types.AttributeValueMemberS{Value: skGeoPlace}
+	item["expiresAt"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Add(GeoCacheTTL).Unix(), 10)}
+
+	if _, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &s.tableName,
+		Item:      item,
+	}); err != nil {
+		return fmt.Errorf("put cached place: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Hashtag strategy (DDR-157) ---
+
+const skHashtags = "HASHTAGS"
+
+// HashtagSettings is a user's hashtag strategy (DynamoDB PK = USER#{sub},
+// SK = HASHTAGS), applied to every generated caption. Like brand kits it has
+// no TTL. Tags are stored without the leading '#'. A zero MaxCount means
+// the platform limit.
+type HashtagSettings struct {
+	MaxCount      int      `json:"maxCount,omitempty" dynamodbav:"maxCount,omitempty"`
+	Banned        []string `json:"banned,omitempty" dynamodbav:"banned,omitempty"`
+	AlwaysInclude []string `json:"alwaysInclude,omitempty" dynamodbav:"alwaysInclude,omitempty"`
+	UpdatedAt     int64    `json:"updatedAt,omitempty" dynamodbav:"updatedAt,omitempty"`
+}
+
+// PutHashtagSettings creates or replaces owner's hashtag strategy. It
+// bypasses putItem so the record carries no expiresAt attribute.
+func (s *DynamoStore) PutHashtagSettings(ctx context.Context, owner string, h *HashtagSettings) error {
+	item, err := attributevalue.MarshalMap(h)
+	if err != nil {
+		return fmt.Errorf("marshal hashtag settings: %w", err)
+	}
+	item["PK"] = &types.AttributeValueMemberS{Value: userPK(owner)}
+	item["SK"] = &types.AttributeValueMemberS{Value: skHashtags}
+
+	if _, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &s.tableName,
+		Item:      item,
+	}); err != nil {
+		return fmt.Errorf("put hashtag settings: %w", err)
+	}
+	log.Debug().Int("maxCount", h.MaxCount).Int("banned", len(h.Banned)).Int("alwaysInclude", len(h.AlwaysInclude)).Msg("Hashtag settings persisted")
+	return nil
+}
+
+// GetHashtagSettings returns owner's hashtag strategy, or nil, nil if none
+// is set.
+func (s *DynamoStore) GetHashtagSettings(ctx context.Context, owner string) (*HashtagSettings, error) {
+	var h HashtagSettings
+	found, err := s.getItem(ctx, userPK(owner), skHashtags, &h)
+	if err != nil {
+		return nil, fmt.Errorf("get hashtag settings: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &h, nil
+}
+
+// DeleteHashtagSettings removes owner's hashtag strategy. Deleting a missing
+// record is not an error.
+func (s *DynamoStore) DeleteHashtagSettings(ctx context.Context, owner string) error {
+	if err := s.deleteItem(ctx, userPK(owner), skHashtags); err != nil {
+		return fmt.Errorf("delete hashtag settings: %w", err)
+	}
+	return nil
+}
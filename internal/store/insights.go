@@ -0,0 +1,252 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Post-publish insights (DDR-146) ---
+
+const (
+	pkInsightsPosts   = "INSIGHTS#POSTS"
+	skInsightsPost    = "POST#"
+	pkInsightsPrefix  = "INSIGHTS#"
+	skInsightsSample  = "AT#"
+	insightsTimeWidth = 12 // zero-padded Unix seconds keep samples in time order
+)
+
+const (
+	// InsightsTrackingWindow is how long after publishing a post is polled.
+	// Instagram engagement has all but stopped by then.
+	InsightsTrackingWindow = 30 * 24 * time.Hour
+
+	// InsightsRetention is how long samples are kept after they are taken.
+	InsightsRetention = 90 * 24 * time.Hour
+
+	// Posts are sampled hourly while engagement moves fastest, then daily.
+	insightsEarlyPeriod   = 48 * time.Hour
+	insightsEarlyInterval = time.Hour
+	insightsLateInterval  = 24 * time.Hour
+
+	// insightsPollSlack lets an hourly poller take a sample a little early,
+	// so scheduling jitter does not push every sample back a whole run.
+	insightsPollSlack = 5 * time.Minute
+)
+
+// TrackedPost is a published Instagram post whose insights are being
+// collected (DynamoDB PK = INSIGHTS#POSTS, SK = POST#{mediaId}). The record
+// outlives tracking and expires with the post's last sample, so the post can
+// be looked up for as long as it has insights.
+type TrackedPost struct {
+	MediaID      string `json:"mediaId" dynamodbav:"-"`
+	OwnerSub     string `json:"-" dynamodbav:"ownerSub,omitempty"`
+	SessionID    string `json:"sessionId" dynamodbav:"sessionId"`
+	JobID        string `json:"jobId" dynamodbav:"jobId"`
+	PublishedAt  int64  `json:"publishedAt" dynamodbav:"publishedAt"` // Unix seconds
+	LastPolledAt int64  `json:"lastPolledAt,omitempty" dynamodbav:"lastPolledAt,omitempty"`
+	LastError    string `json:"lastError,omitempty" dynamodbav:"lastError,omitempty"`
+}
+
+// InsightsSample is one reading of a post's lifetime insights (DynamoDB
+// PK = INSIGHTS#{mediaId}, SK = AT#{unix}).
+type InsightsSample struct {
+	At          int64 `json:"at" dynamodbav:"at"` // Unix seconds
+	Reach       int64 `json:"reach" dynamodbav:"reach"`
+	Impressions int64 `json:"impressions" dynamodbav:"impressions"`
+	Likes       int64 `json:"likes" dynamodbav:"likes"`
+	Comments    int64 `json:"comments" dynamodbav:"comments"`
+	Saves       int64 `json:"saves" dynamodbav:"saves"`
+}
+
+// NextPollAt returns when the post should next be sampled: at once if it
+// never was, then hourly for its first two days and daily after that.
+func (p *TrackedPost) NextPollAt() time.Time {
+	if p.LastPolledAt == 0 {
+		return time.Unix(p.PublishedAt, 0)
+	}
+	interval := insightsLateInterval
+	if p.LastPolledAt-p.PublishedAt < int64(insightsEarlyPeriod/time.Second) {
+		interval = insightsEarlyInterval
+	}
+	return time.Unix(p.LastPolledAt, 0).Add(interval)
+}
+
+// Due reports whether the post should be sampled at now.
+func (p *TrackedPost) Due(now time.Time) bool {
+	return !p.Expired(now) && !now.Before(p.NextPollAt().Add(-insightsPollSlack))
+}
+
+// Expired reports whether tracking has ended. DynamoDB deletes expired
+// records lazily, so readers must check.
+func (p *TrackedPost) Expired(now time.Time) bool {
+	return now.After(time.Unix(p.PublishedAt, 0).Add(InsightsTrackingWindow))
+}
+
+// ExpiresAt returns when the tracking record is deleted: when the last
+// sample, taken as tracking ends, reaches InsightsRetention.
+func (p *TrackedPost) ExpiresAt() time.Time {
+	return time.Unix(p.PublishedAt, 0).Add(InsightsTrackingWindow + InsightsRetention)
+}
+
+// PutTrackedPost starts collecting insights for a published post.
+func (s *DynamoStore) PutTrackedPost(ctx context.Context, p *TrackedPost) error {
+	if p.PublishedAt == 0 {
+		p.PublishedAt = time.Now().Unix()
+	}
+	item, err := attributevalue.MarshalMap(p)
+	if err != nil {
+		return fmt.Errorf("marshal tracked post %s: %w", p.MediaID, err)
+	}
+	item["PK"] = &types.AttributeValueMemberS{Value: pkInsightsPosts}
+	item["SK"] = &types.AttributeValueMemberS{Value: skInsightsPost + p.MediaID}
+	item["expiresAt"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(p.ExpiresAt().Unix(), 10)}
+
+	if _, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &s.tableName,
+		Item:      item,
+	}); err != nil {
+		return fmt.Errorf("put tracked post %s: %w", p.MediaID, err)
+	}
+	log.Debug().Str("mediaId", p.MediaID).Str("jobId", p.JobID).Msg("Post insights tracking started")
+	return nil
+}
+
+// GetTrackedPost returns a tracked post, or nil, nil if it is not tracked.
+// Posts whose tracking has ended are still returned until DynamoDB deletes
+// them.
+func (s *DynamoStore) GetTrackedPost(ctx context.Context, mediaID string) (*TrackedPost, error) {
+	var p TrackedPost
+	found, err := s.getItem(ctx, pkInsightsPosts, skInsightsPost+mediaID, &p)
+	if err != nil {
+		return nil, fmt.Errorf("get tracked post %s: %w", mediaID, err)
+	}
+	if !found {
+		return nil, nil
+	}
+	p.MediaID = mediaID
+	return &p, nil
+}
+
+// ListTrackedPosts returns every post still within its tracking window.
+func (s *DynamoStore) ListTrackedPosts(ctx context.Context) ([]TrackedPost, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              &s.tableName,
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: pkInsightsPosts},
+			":sk": &types.AttributeValueMemberS{Value: skInsightsPost},
+		},
+	}
+
+	now := time.Now()
+	posts := []TrackedPost{}
+	for {
+		result, err := s.client.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("query tracked posts: %w", err)
+		}
+		for _, item := range result.Items {
+			var p TrackedPost
+			if err := attributevalue.UnmarshalMap(item, &p); err != nil {
+				return nil, fmt.Errorf("unmarshal tracked post: %w", err)
+			}
+			if sk, ok := item["SK"].(*types.AttributeValueMemberS); ok {
+				p.MediaID = strings.TrimPrefix(sk.Value, skInsightsPost)
+			}
+			if !p.Expired(now) {
+				posts = append(posts, p)
+			}
+		}
+		if result.LastEvaluatedKey == nil {
+			return posts, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// PutInsightsSample stores one reading of a post's insights and records the
+// poll on its tracking record. errMsg, when set, records a failed poll
+// instead and sample is ignored.
+func (s *DynamoStore) PutInsightsSample(ctx context.Context, mediaID string, sample *InsightsSample, errMsg string) error {
+	now := time.Now()
+	if errMsg == "" {
+		if sample.At == 0 {
+			sample.At = now.Unix()
+		}
+		item, err := attributevalue.MarshalMap(sample)
+		if err != nil {
+			return fmt.Errorf("marshal insights sample: %w", err)
+		}
+		item["PK"] = &types.AttributeValueMemberS{Value: pkInsightsPrefix + mediaID}
+		item["SK"] = &types.AttributeValueMemberS{Value: fmt.Sprintf("%s%0*d", skInsightsSample, insightsTimeWidth, sample.At)}
+		item["expiresAt"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Add(InsightsRetention).Unix(), 10)}
+		if _, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: &s.tableName,
+			Item:      item,
+		}); err != nil {
+			return fmt.Errorf("put insights sample for %s: %w", mediaID, err)
+		}
+	}
+
+	update := "SET lastPolledAt = :now REMOVE lastError"
+	values := map[string]types.AttributeValue{
+		":now": &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Unix(), 10)},
+	}
+	if errMsg != "" {
+		update = "SET lastPolledAt = :now, lastError = :err"
+		values[":err"] = &types.AttributeValueMemberS{Value: errMsg}
+	}
+	if _, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: pkInsightsPosts},
+			"SK": &types.AttributeValueMemberS{Value: skInsightsPost + mediaID},
+		},
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String("attribute_exists(PK)"),
+		ExpressionAttributeValues: values,
+	}); err != nil {
+		return fmt.Errorf("record insights poll for %s: %w", mediaID, err)
+	}
+	return nil
+}
+
+// ListInsightsSamples returns a post's insights time series, oldest first.
+func (s *DynamoStore) ListInsightsSamples(ctx context.Context, mediaID string) ([]InsightsSample, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              &s.tableName,
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: pkInsightsPrefix + mediaID},
+			":sk": &types.AttributeValueMemberS{Value: skInsightsSample},
+		},
+	}
+
+	samples := []InsightsSample{}
+	for {
+		result, err := s.client.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("query insights samples for %s: %w", mediaID, err)
+		}
+		for _, item := range result.Items {
+			var sample InsightsSample
+			if err := attributevalue.UnmarshalMap(item, &sample); err != nil {
+				return nil, fmt.Errorf("unmarshal insights sample: %w", err)
+			}
+			samples = append(samples, sample)
+		}
+		if result.LastEvaluatedKey == nil {
+			return samples, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrackedPostDue(t *testing.T) {
+	published := time.Unix(1_800_000_000, 0)
+	at := func(d time.Duration) time.Time { return published.Add(d) }
+
+	tests := []struct {
+		name       string
+		lastPolled time.Duration // since publish; 0 means never polled
+		now        time.Duration
+		want       bool
+	}{
+		{"never polled", 0, time.Minute, true},
+		{"early, polled recently", 3 * time.Hour, 3*time.Hour + 30*time.Minute, false},
+		{"early, an hour since last poll", 3 * time.Hour, 4 * time.Hour, true},
+		{"early, within poll slack", 3 * time.Hour, 4*time.Hour - 3*time.Minute, true},
+		{"late, an hour since last poll", 72 * time.Hour, 73 * time.Hour, false},
+		{"late, a day since last poll", 72 * time.Hour, 96 * time.Hour, true},
+		{"tracking window over", 29 * 24 * time.Hour, 31 * 24 * time.Hour, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &TrackedPost{PublishedAt: published.Unix()}
+			if tt.lastPolled != 0 {
+				p.LastPolledAt = at(tt.lastPolled).Unix()
+			}
+			if got := p.Due(at(tt.now)); got != tt.want {
+				t.Errorf("Due(+%s) with last poll at +%s = %v, want %v", tt.now, tt.lastPolled, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackedPostExpiresWithLastSample(t *testing.T) {
+	published := time.Unix(1_800_000_000, 0)
+	p := &TrackedPost{PublishedAt: published.Unix()}
+
+	lastSample := published.Add(InsightsTrackingWindow)
+	if got, want := p.ExpiresAt(), lastSample.Add(InsightsRetention); !got.Equal(want) {
+		t.Errorf("ExpiresAt() = %v, want %v", got, want)
+	}
+	if !p.Expired(lastSample.Add(time.Hour)) {
+		t.Error("tracking should end after the tracking window")
+	}
+}
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+)
+
+// geminiCalls returns the geminiCalls telemetry of the stored job, or "" if
+// the job has none yet.
+func geminiCalls(table *fakeDynamo, pk, sk string) string {
+	var telemetry struct {
+		M map[string]struct{ N string }
+	}
+	json.Unmarshal(table.item(pk, sk)["perJobTelemetry"], &telemetry)
+	return telemetry.M["geminiCalls"].N
+}
+
+func TestJobTelemetryAddsUpAcrossInvocations(t *testing.T) {
+	table := newFakeDynamo()
+	s := newFakeDynamoStore(t, table)
+	if err := s.PutSession(context.Background(), &Session{ID: "s1", Status: "active"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// invoke is one worker invocation: it makes calls Gemini calls, writing
+	// the job after each.
+	invoke := func(calls int) {
+		ctx, _ := metrics.WithCollector(context.Background())
+		for i := 0; i < calls; i++ {
+			metrics.RecordGeminiCall(ctx, time.Millisecond)
+			if err := s.PutDescriptionJob(ctx, "s1", &DescriptionJob{ID: "d1"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	invoke(2)
+	if calls, gets := geminiCalls(table, sessionPK("s1"), skDesc+"d1"), table.served("GetItem"); calls != "2" || gets != 1 {
+		t.Fatalf("after the first invocation: %s calls, %d reads; want 2 calls, 1 read", calls, gets)
+	}
+	// A feedback round adds to the job's totals rather than replacing them.
+	invoke(3)
+	if calls, gets := geminiCalls(table, sessionPK("s1"), skDesc+"d1"), table.served("GetItem"); calls != "5" || gets != 2 {
+		t.Errorf("after the second invocation: %s calls, %d reads; want 5 calls, 2 reads", calls, gets)
+	}
+}
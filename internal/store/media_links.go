@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Proxy uploads linked to originals (DDR-131) ---
+
+// MediaLink statuses.
+const (
+	// MediaLinkProxy: only the downscaled proxy is in S3.
+	MediaLinkProxy = "proxy"
+	// MediaLinkOriginal: the original has been uploaded as well.
+	MediaLinkOriginal = "original"
+)
+
+// MediaLink ties a downscaled proxy upload to the original it stands in for
+// (DynamoDB SK = LINK#{filename}). Triage and selection process ProxyKey like
+// any other upload; downloads and publish switch to OriginalKey once the
+// original has been uploaded.
+type MediaLink struct {
+	Filename            string `json:"filename" dynamodbav:"-"` // Derived from SK: the proxy's filename
+	ProxyKey            string `json:"proxyKey" dynamodbav:"proxyKey"`
+	OriginalKey         string `json:"originalKey" dynamodbav:"originalKey"`
+	OriginalContentType string `json:"originalContentType" dynamodbav:"originalContentType"`
+	OriginalBytes       int64  `json:"originalBytes,omitempty" dynamodbav:"originalBytes,omitempty"`
+	Status              string `json:"status" dynamodbav:"status"`
+}
+
+func (s *DynamoStore) PutMediaLink(ctx context.Context, sessionID string, link *MediaLink) error {
+	if err := s.putItem(ctx, sessionPK(sessionID), skLink+link.Filename, link); err != nil {
+		return fmt.Errorf("put media link %s/%s: %w", sessionID, link.Filename, err)
+	}
+	log.Debug().Str("sessionId", sessionID).Str("filename", link.Filename).Str("status", link.Status).Msg("Media link persisted")
+	return nil
+}
+
+func (s *DynamoStore) GetMediaLink(ctx context.Context, sessionID, filename string) (*MediaLink, error) {
+	var link MediaLink
+	found, err := s.getItem(ctx, sessionPK(sessionID), skLink+filename, &link)
+	if err != nil {
+		return nil, fmt.Errorf("get media link %s/%s: %w", sessionID, filename, err)
+	}
+	if !found {
+		return nil, nil
+	}
+	link.Filename = filename
+	return &link, nil
+}
+
+// ListMediaLinks returns every proxy/original link in the session.
+func (s *DynamoStore) ListMediaLinks(ctx context.Context, sessionID string) ([]MediaLink, error) {
+	items, err := s.queryBySKPrefix(ctx, sessionID, skLink)
+	if err != nil {
+		return nil, fmt.Errorf("list media links for %s: %w", sessionID, err)
+	}
+	links := make([]MediaLink, 0, len(items))
+	for _, item := range items {
+		var link MediaLink
+		if err := attributevalue.UnmarshalMap(item, &link); err != nil {
+			log.Warn().Err(err).Str("sessionId", sessionID).Msg("Failed to unmarshal media link, skipping")
+			continue
+		}
+		if skAttr, ok := item["SK"].(*types.AttributeValueMemberS); ok {
+			link.Filename = strings.TrimPrefix(skAttr.Value, skLink)
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// ResolveOriginals replaces each proxy key whose original has been uploaded
+// with the original's key. Other keys, including enhanced copies derived from
+// a proxy, are returned unchanged. pending counts the proxy keys still
+// waiting for their original.
+func ResolveOriginals(keys []string, links []MediaLink) (resolved []string, pending int) {
+	byProxy := make(map[string]MediaLink, len(links))
+	for _, link := range links {
+		byProxy[link.ProxyKey] = link
+	}
+	resolved = make([]string, len(keys))
+	for i, key := range keys {
+		resolved[i] = key
+		link, ok := byProxy[key]
+		switch {
+		case !ok:
+		case link.Status == MediaLinkOriginal:
+			resolved[i] = link.OriginalKey
+		default:
+			pending++
+		}
+	}
+	return resolved, pending
+}
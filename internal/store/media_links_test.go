@@ -0,0 +1,26 @@
+package store
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestResolveOriginals(t *testing.T) {
+	links := []MediaLink{
+		{Filename: "a.jpg", ProxyKey: "s/a.jpg", OriginalKey: "s/originals/a.heic", Status: MediaLinkOriginal},
+		{Filename: "b.jpg", ProxyKey: "s/b.jpg", OriginalKey: "s/originals/b.jpg", Status: MediaLinkProxy},
+	}
+	keys := []string{"s/a.jpg", "s/b.jpg", "s/c.jpg", "s/enhanced/a.jpg"}
+
+	got, pending := ResolveOriginals(keys, links)
+	want := []string{"s/originals/a.heic", "s/b.jpg", "s/c.jpg", "s/enhanced/a.jpg"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ResolveOriginals() = %v, want %v", got, want)
+	}
+	if pending != 1 {
+		t.Errorf("pending = %d, want 1", pending)
+	}
+	if keys[0] != "s/a.jpg" {
+		t.Error("ResolveOriginals modified its input")
+	}
+}
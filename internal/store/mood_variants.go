@@ -0,0 +1,148 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog/log"
+
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+)
+
+// --- Stylized cover variants (DDR-102) ---
+
+// MoodVariantJob is a request to render stylized variants of a cover image
+// (DynamoDB SK = MOOD#{jobId}).
+type MoodVariantJob struct {
+	ID         string        `json:"id" dynamodbav:"-"`
+	SessionID  string        `json:"-" dynamodbav:"-"`
+	Status     string        `json:"status" dynamodbav:"status"`
+	SourceKey  string        `json:"sourceKey" dynamodbav:"sourceKey"`
+	Variants   []MoodVariant `json:"variants" dynamodbav:"variants"`
+	Error      string        `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	RetryCount int           `json:"retryCount,omitempty" dynamodbav:"retryCount,omitempty"` // DDR-089
+	// Telemetry is the job's Gemini, S3 and ffmpeg usage so far (DDR-118).
+	Telemetry *metrics.JobTelemetry `json:"perJobTelemetry,omitempty" dynamodbav:"perJobTelemetry,omitempty"`
+}
+
+// MoodVariant is one generated rendition. Key points at the watermarked image
+// under {sessionId}/ai-generated/, never at enhanced/, so generated images do
+// not end up in download bundles or publish payloads unless the user adds them.
+type MoodVariant struct {
+	Style    string `json:"style" dynamodbav:"style"`
+	Key      string `json:"key,omitempty" dynamodbav:"key,omitempty"`
+	ThumbKey string `json:"thumbKey,omitempty" dynamodbav:"thumbKey,omitempty"`
+	Error    string `json:"error,omitempty" dynamodbav:"error,omitempty"`
+}
+
+func (s *DynamoStore) PutMoodVariantJob(ctx context.Context, sessionID string, job *MoodVariantJob) error {
+	s.attachTelemetry(ctx, sessionPK(sessionID), skMood+job.ID, &job.Telemetry)
+	if err := s.putJobItem(ctx, sessionPK(sessionID), skMood+job.ID, job); err != nil {
+		return fmt.Errorf("put mood variant job %s/%s: %w", sessionID, job.ID, err)
+	}
+	s.touchSessionJob(ctx, sessionID, skMood+job.ID, sessionJobUpdate{Status: job.Status, Error: &job.Error})
+	log.Debug().Str("sessionId", sessionID).Str("jobId", job.ID).Str("status", job.Status).Int("variants", len(job.Variants)).Msg("Mood variant job persisted")
+	return nil
+}
+
+func (s *DynamoStore) GetMoodVariantJob(ctx context.Context, sessionID, jobID string) (*MoodVariantJob, error) {
+	var job MoodVariantJob
+	found, err := s.getItem(ctx, sessionPK(sessionID), skMood+jobID, &job)
+	if err != nil {
+		return nil, fmt.Errorf("get mood variant job %s/%s: %w", sessionID, jobID, err)
+	}
+	if !found {
+		return nil, nil
+	}
+	job.ID = jobID
+	job.SessionID = sessionID
+	return &job, nil
+}
+
+// --- Daily generation budgets ---
+
+const skBudget = "BUDGET#"
+
+// MoodVariantBudget names the per-user daily budget for generated images
+// (DDR-102). The API reserves a unit per requested style; the Enhance Lambda
+// releases the units of styles it could not generate.
+const MoodVariantBudget = "mood-variants"
+
+// ErrBudgetExhausted is returned when a reservation would exceed the daily limit.
+var ErrBudgetExhausted = errors.New("daily budget exhausted")
+
+// ReserveDailyBudget atomically reserves n units of the named budget for
+// owner (a user sub, or a session ID when auth is off) for the current UTC
+// day. The counter lives on the owner's USER# partition and expires with the
+// usual TTL. Returns the units used today including this reservation, or
+// ErrBudgetExhausted if it would exceed limit.
+func (s *DynamoStore) ReserveDailyBudget(ctx context.Context, owner, name string, n, limit int) (int, error) {
+	if n > limit {
+		return 0, ErrBudgetExhausted
+	}
+	result, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           &s.tableName,
+		Key:                 budgetKey(owner, name),
+		UpdateExpression:    aws.String("ADD #used :n SET expiresAt = if_not_exists(expiresAt, :exp)"),
+		ConditionExpression: aws.String("attribute_not_exists(#used) OR #used <= :max"),
+		ExpressionAttributeNames: map[string]string{
+			"#used": "used",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":n":   &types.AttributeValueMemberN{Value: strconv.Itoa(n)},
+			":max": &types.AttributeValueMemberN{Value: strconv.Itoa(limit - n)},
+			":exp": &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt(), 10)},
+		},
+		ReturnValues: types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		var ccf *types.ConditionalCheckFailedException
+		if errors.As(err, &ccf) {
+			return 0, ErrBudgetExhausted
+		}
+		return 0, fmt.Errorf("reserve %s budget for %s: %w", name, owner, err)
+	}
+	return extractIntAttr(result.Attributes, "used"), nil
+}
+
+// ReleaseDailyBudget returns n units reserved by ReserveDailyBudget, for work
+// that was never started or failed. Best effort: a failed release only
+// under-counts the owner's remaining budget for the rest of the day. A
+// release after the day's counter expired, e.g. for a job that ran past
+// midnight UTC, is dropped rather than creating a negative counter.
+func (s *DynamoStore) ReleaseDailyBudget(ctx context.Context, owner, name string, n int) {
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           &s.tableName,
+		Key:                 budgetKey(owner, name),
+		UpdateExpression:    aws.String("ADD #used :n"),
+		ConditionExpression: aws.String("attribute_exists(#used)"),
+		ExpressionAttributeNames: map[string]string{
+			"#used": "used",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":n": &types.AttributeValueMemberN{Value: strconv.Itoa(-n)},
+		},
+	})
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		log.Debug().Str("owner", owner).Str("budget", name).Int("units", n).Msg("No budget counter to release into")
+		return
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("owner", owner).Str("budget", name).Int("units", n).Msg("Failed to release budget reservation")
+	}
+}
+
+func budgetKey(owner, name string) map[string]types.AttributeValue {
+	day := time.Now().UTC().Format("2006-01-02")
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: userPK(owner)},
+		"SK": &types.AttributeValueMemberS{Value: skBudget + name + "#" + day},
+	}
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// addBudget applies the budget updates' "ADD #used :n" to the stored counter,
+// with ReserveDailyBudget's limit check and ReleaseDailyBudget's existence
+// check.
+func addBudget(f *fakeDynamo, req *fakeRequest) (any, error) {
+	item, ok := f.items[req.Key.key()]
+	used, _ := strconv.Atoi(item.N("used"))
+	switch {
+	case strings.Contains(req.ConditionExpression, "<= :max"):
+		max, _ := strconv.Atoi(req.ExpressionAttributeValues.N(":max"))
+		if ok && used > max {
+			return nil, errFakeConditionFailed
+		}
+	case strings.Contains(req.ConditionExpression, "attribute_exists(#used)"):
+		if !ok {
+			return nil, errFakeConditionFailed
+		}
+	}
+	n, _ := strconv.Atoi(req.ExpressionAttributeValues.N(":n"))
+	used += n
+	f.put(req.Key.S("PK"), req.Key.S("SK"), fakeItem{"used": json.RawMessage(`{"N":"` + strconv.Itoa(used) + `"}`)})
+	return map[string]any{"Attributes": fakeItem{"used": f.items[req.Key.key()]["used"]}}, nil
+}
+
+func TestReleaseDailyBudgetRefundsFailedStyles(t *testing.T) {
+	table := newFakeDynamo()
+	table.on["UpdateItem"] = addBudget
+	s := newFakeDynamoStore(t, table)
+	ctx := context.Background()
+
+	if used, err := s.ReserveDailyBudget(ctx, "user-1", MoodVariantBudget, 4, 6); err != nil || used != 4 {
+		t.Fatalf("reserve 4: used = %d, err = %v", used, err)
+	}
+	if _, err := s.ReserveDailyBudget(ctx, "user-1", MoodVariantBudget, 3, 6); !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("reserve past the limit: err = %v, want ErrBudgetExhausted", err)
+	}
+	// Three of the four styles failed, so their units come back.
+	s.ReleaseDailyBudget(ctx, "user-1", MoodVariantBudget, 3)
+	if used, err := s.ReserveDailyBudget(ctx, "user-1", MoodVariantBudget, 3, 6); err != nil || used != 4 {
+		t.Errorf("reserve after the refund: used = %d, err = %v; want 4", used, err)
+	}
+}
+
+func TestReleaseDailyBudgetWithoutCounter(t *testing.T) {
+	table := newFakeDynamo()
+	table.on["UpdateItem"] = addBudget
+	s := newFakeDynamoStore(t, table)
+
+	// A job that ran past midnight UTC releases into a day with no counter.
+	s.ReleaseDailyBudget(context.Background(), "user-1", MoodVariantBudget, 2)
+	if keys := table.keys(); len(keys) != 0 {
+		t.Errorf("release created %v", keys)
+	}
+}
@@ -0,0 +1,202 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// --- Post group editing (DDR-178) ---
+
+// MaxPostGroupItems is the most media one post group holds, the size of an
+// Instagram carousel.
+const MaxPostGroupItems = 20
+
+// SortPostGroups orders a session's groups by Position, then by ID.
+func SortPostGroups(groups []*PostGroup) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Position != groups[j].Position {
+			return groups[i].Position < groups[j].Position
+		}
+		return groups[i].ID < groups[j].ID
+	})
+}
+
+// RenumberPostGroups sets each group's Position to its index, returning the
+// groups whose Position changed.
+func RenumberPostGroups(groups []*PostGroup) []*PostGroup {
+	var changed []*PostGroup
+	for i, g := range groups {
+		if g.Position != i {
+			g.Position = i
+			changed = append(changed, g)
+		}
+	}
+	return changed
+}
+
+// CheckPostGroupKeys returns an error unless keys can make up the group
+// groupID: between 1 and MaxPostGroupItems keys, none repeated and none in
+// another of groups. An empty groupID checks keys for a new group.
+func CheckPostGroupKeys(groups []*PostGroup, groupID string, keys []string) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("a post group needs at least one key")
+	}
+	if len(keys) > MaxPostGroupItems {
+		return fmt.Errorf("a post group holds at most %d items", MaxPostGroupItems)
+	}
+	owner := make(map[string]string)
+	for _, g := range groups {
+		if g.ID == groupID {
+			continue
+		}
+		for _, key := range g.MediaKeys {
+			owner[key] = g.ID
+		}
+	}
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if seen[key] {
+			return fmt.Errorf("key listed twice: %s", key)
+		}
+		seen[key] = true
+		if id, ok := owner[key]; ok {
+			return fmt.Errorf("key already in post group %s: %s", id, key)
+		}
+	}
+	return nil
+}
+
+// ReorderPostGroup sets the order of g's items. keys must be exactly g's
+// keys, in the new order.
+func ReorderPostGroup(g *PostGroup, keys []string) error {
+	if len(keys) != len(g.MediaKeys) {
+		return fmt.Errorf("reorder must list all %d of the group's keys", len(g.MediaKeys))
+	}
+	have := make(map[string]bool, len(g.MediaKeys))
+	for _, key := range g.MediaKeys {
+		have[key] = true
+	}
+	for _, key := range keys {
+		if !have[key] {
+			return fmt.Errorf("key not in group or listed twice: %s", key)
+		}
+		delete(have, key)
+	}
+	g.MediaKeys = append([]string(nil), keys...)
+	return nil
+}
+
+// SplitPostGroup moves g's items from index at onward into tail. at must
+// leave both groups at least one item.
+func SplitPostGroup(g, tail *PostGroup, at int) error {
+	if at < 1 || at >= len(g.MediaKeys) {
+		return fmt.Errorf("split index must be between 1 and %d", len(g.MediaKeys)-1)
+	}
+	tail.MediaKeys = append([]string(nil), g.MediaKeys[at:]...)
+	g.MediaKeys = g.MediaKeys[:at:at]
+	return nil
+}
+
+// MergePostGroups appends src's items to dst. The merged group may hold at
+// most MaxPostGroupItems.
+func MergePostGroups(dst, src *PostGroup) error {
+	if dst.ID == src.ID {
+		return fmt.Errorf("cannot merge a post group into itself")
+	}
+	if n := len(dst.MediaKeys) + len(src.MediaKeys); n > MaxPostGroupItems {
+		return fmt.Errorf("merged group would hold %d items, more than %d", n, MaxPostGroupItems)
+	}
+	dst.MediaKeys = append(dst.MediaKeys, src.MediaKeys...)
+	return nil
+}
+
+// PostGroupEdit is a set of post group writes that must apply together, so
+// that no key is ever in two groups or in none (see ApplyPostGroupEdit).
+type PostGroupEdit struct {
+	Put    []*PostGroup
+	Delete []string // IDs of the groups to delete
+}
+
+// put adds groups to the edit, each once.
+func (e *PostGroupEdit) put(groups ...*PostGroup) {
+	for _, g := range groups {
+		if !slices.Contains(e.Put, g) {
+			e.Put = append(e.Put, g)
+		}
+	}
+}
+
+// SplitPostGroupEdit splits g, one of groups, at index at into tail, placed
+// right after g. It returns the session's groups in their new order and the
+// writes that record the split.
+func SplitPostGroupEdit(groups []*PostGroup, g, tail *PostGroup, at int) ([]*PostGroup, PostGroupEdit, error) {
+	if err := SplitPostGroup(g, tail, at); err != nil {
+		return nil, PostGroupEdit{}, err
+	}
+	var ordered []*PostGroup
+	for _, other := range groups {
+		ordered = append(ordered, other)
+		if other == g {
+			ordered = append(ordered, tail)
+		}
+	}
+	var edit PostGroupEdit
+	edit.put(g)
+	edit.put(RenumberPostGroups(ordered)...)
+	return ordered, edit, nil
+}
+
+// MergePostGroupEdit appends src's items to dst, both of groups, and
+// deletes src. It returns the session's remaining groups in order and the
+// writes that record the merge.
+func MergePostGroupEdit(groups []*PostGroup, dst, src *PostGroup) ([]*PostGroup, PostGroupEdit, error) {
+	if err := MergePostGroups(dst, src); err != nil {
+		return nil, PostGroupEdit{}, err
+	}
+	remaining, edit := DeletePostGroupEdit(groups, src.ID)
+	edit.put(dst)
+	return remaining, edit, nil
+}
+
+// DeletePostGroupEdit deletes group id from groups and closes the gap it
+// leaves in the order. It returns the remaining groups in order and the
+// writes that record the deletion.
+func DeletePostGroupEdit(groups []*PostGroup, id string) ([]*PostGroup, PostGroupEdit) {
+	remaining := make([]*PostGroup, 0, len(groups))
+	for _, g := range groups {
+		if g.ID != id {
+			remaining = append(remaining, g)
+		}
+	}
+	edit := PostGroupEdit{Delete: []string{id}}
+	edit.put(RenumberPostGroups(remaining)...)
+	return remaining, edit
+}
+
+// SuggestedOrder returns the carousel order of the group's latest complete
+// suggestion (DDR-179) as indices into keys, opening item first. It fails
+// when there is no such suggestion or it was made for other keys than keys.
+func (g *PostGroup) SuggestedOrder(keys []string) ([]int, error) {
+	s := g.OrderSuggestion
+	if s == nil || s.Status != "complete" {
+		return nil, fmt.Errorf("post group %s has no completed order suggestion", g.ID)
+	}
+	if len(s.Keys) != len(keys) {
+		return nil, fmt.Errorf("the order suggestion is for %d items, not %d; suggest the order again", len(s.Keys), len(keys))
+	}
+	index := make(map[string]int, len(keys))
+	for i, key := range keys {
+		index[key] = i
+	}
+	order := make([]int, len(s.Keys))
+	for i, key := range s.Keys {
+		j, ok := index[key]
+		if !ok {
+			return nil, fmt.Errorf("the order suggestion does not match the post's items; suggest the order again")
+		}
+		delete(index, key)
+		order[i] = j
+	}
+	return order, nil
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestApplyPostGroupEdit(t *testing.T) {
+	table := newFakeDynamo()
+	s := newFakeDynamoStore(t, table)
+	table.put(sessionPK("s1"), skGroup+"grp-b", nil)
+	edit := PostGroupEdit{Put: []*PostGroup{{ID: "grp-a"}, {ID: "grp-c"}}, Delete: []string{"grp-b"}}
+	if err := s.ApplyPostGroupEdit(context.Background(), "s1", edit); err != nil {
+		t.Fatal(err)
+	}
+	if table.served("TransactWriteItems") != 1 {
+		t.Errorf("%d transactions, want 1", table.served("TransactWriteItems"))
+	}
+	if got := strings.Join(table.keys(), " "); got != "SESSION#s1|GROUP#grp-a SESSION#s1|GROUP#grp-c" {
+		t.Errorf("items = %s", got)
+	}
+
+	table.on["TransactWriteItems"] = func(*fakeDynamo, *fakeRequest) (any, error) { return nil, errFakeTxCanceled }
+	if err := s.ApplyPostGroupEdit(context.Background(), "s1", edit); err == nil {
+		t.Error("cancelled transaction reported as applied")
+	}
+
+	many := PostGroupEdit{Delete: make([]string, maxTransactItems+1)}
+	if err := s.ApplyPostGroupEdit(context.Background(), "s1", many); err == nil || table.served("TransactWriteItems") != 2 {
+		t.Errorf("oversized edit: err = %v, calls = %d", err, table.served("TransactWriteItems"))
+	}
+}
+
+// setOrderSuggestion applies SetPostGroupOrderSuggestion's conditional
+// "SET orderSuggestion = :s" to the stored item.
+func setOrderSuggestion(f *fakeDynamo, req *fakeRequest) (any, error) {
+	item, ok := f.items[req.Key.key()]
+	if !ok {
+		return nil, errFakeConditionFailed
+	}
+	item["orderSuggestion"] = req.ExpressionAttributeValues[":s"]
+	return struct{}{}, nil
+}
+
+func TestSetPostGroupOrderSuggestion(t *testing.T) {
+	table := newFakeDynamo()
+	table.on["UpdateItem"] = setOrderSuggestion
+	s := newFakeDynamoStore(t, table)
+	ctx := context.Background()
+	if err := s.PutPostGroup(ctx, "s1", &PostGroup{ID: "grp-a", MediaKeys: []string{"a.jpg", "b.jpg"}}); err != nil {
+		t.Fatal(err)
+	}
+	// The group is edited while the suggestion is being made.
+	if err := s.PutPostGroup(ctx, "s1", &PostGroup{ID: "grp-a", Name: "Day 1", MediaKeys: []string{"a.jpg", "b.jpg", "c.jpg"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	suggestion := &CarouselOrderSuggestion{Status: "complete", Keys: []string{"b.jpg", "a.jpg"}, CreatedAt: 100}
+	if err := s.SetPostGroupOrderSuggestion(ctx, "s1", "grp-a", suggestion); err != nil {
+		t.Fatal(err)
+	}
+	groups, err := s.GetPostGroups(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("%d groups, want 1", len(groups))
+	}
+	g := groups[0]
+	if g.Name != "Day 1" || len(g.MediaKeys) != 3 {
+		t.Errorf("group = %+v, want the edit kept", g)
+	}
+	if g.OrderSuggestion == nil || strings.Join(g.OrderSuggestion.Keys, " ") != "b.jpg a.jpg" || g.OrderSuggestion.CreatedAt != 100 {
+		t.Errorf("suggestion = %+v", g.OrderSuggestion)
+	}
+
+	err = s.SetPostGroupOrderSuggestion(ctx, "s1", "grp-gone", suggestion)
+	if !errors.Is(err, ErrPostGroupNotFound) {
+		t.Errorf("deleted group: err = %v, want ErrPostGroupNotFound", err)
+	}
+	if table.item(sessionPK("s1"), skGroup+"grp-gone") != nil {
+		t.Error("suggestion recreated a deleted group")
+	}
+}
@@ -0,0 +1,167 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSortAndRenumberPostGroups(t *testing.T) {
+	groups := []*PostGroup{{ID: "grp-c", Position: 2}, {ID: "grp-b", Position: 0}, {ID: "grp-a", Position: 0}}
+	SortPostGroups(groups)
+	if got := groups[0].ID + " " + groups[1].ID + " " + groups[2].ID; got != "grp-a grp-b grp-c" {
+		t.Errorf("order = %s", got)
+	}
+	changed := RenumberPostGroups(groups)
+	if len(changed) != 1 || groups[1].Position != 1 || groups[2].Position != 2 {
+		t.Errorf("renumbered %d groups, positions %d %d", len(changed), groups[1].Position, groups[2].Position)
+	}
+}
+
+func TestCheckPostGroupKeys(t *testing.T) {
+	groups := []*PostGroup{{ID: "grp-a", MediaKeys: []string{"a.jpg", "b.jpg"}}}
+	if err := CheckPostGroupKeys(groups, "", []string{"c.jpg"}); err != nil {
+		t.Errorf("new group: %v", err)
+	}
+	if err := CheckPostGroupKeys(groups, "grp-a", []string{"b.jpg", "a.jpg", "c.jpg"}); err != nil {
+		t.Errorf("keys of the group itself: %v", err)
+	}
+	if err := CheckPostGroupKeys(groups, "", []string{"b.jpg"}); err == nil || !strings.Contains(err.Error(), "grp-a") {
+		t.Errorf("key in another group: err = %v", err)
+	}
+	if err := CheckPostGroupKeys(groups, "", []string{"c.jpg", "c.jpg"}); err == nil {
+		t.Error("repeated key accepted")
+	}
+	if err := CheckPostGroupKeys(groups, "", nil); err == nil {
+		t.Error("empty group accepted")
+	}
+	many := make([]string, MaxPostGroupItems+1)
+	for i := range many {
+		many[i] = fmt.Sprintf("%d.jpg", i)
+	}
+	if err := CheckPostGroupKeys(nil, "", many); err == nil {
+		t.Error("oversized group accepted")
+	}
+}
+
+func TestReorderPostGroup(t *testing.T) {
+	g := &PostGroup{MediaKeys: []string{"a.jpg", "b.jpg", "c.jpg"}}
+	if err := ReorderPostGroup(g, []string{"c.jpg", "a.jpg", "b.jpg"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(g.MediaKeys, " "); got != "c.jpg a.jpg b.jpg" {
+		t.Errorf("keys = %s", got)
+	}
+	for _, keys := range [][]string{{"a.jpg", "b.jpg"}, {"a.jpg", "a.jpg", "b.jpg"}, {"a.jpg", "b.jpg", "d.jpg"}} {
+		if err := ReorderPostGroup(g, keys); err == nil {
+			t.Errorf("reorder to %v accepted", keys)
+		}
+	}
+}
+
+func TestSplitAndMergePostGroups(t *testing.T) {
+	g := &PostGroup{ID: "grp-a", MediaKeys: []string{"a.jpg", "b.jpg", "c.jpg"}}
+	tail := &PostGroup{ID: "grp-b"}
+	for _, at := range []int{0, 3} {
+		if err := SplitPostGroup(g, tail, at); err == nil {
+			t.Errorf("split at %d accepted", at)
+		}
+	}
+	if err := SplitPostGroup(g, tail, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(g.MediaKeys, " ") + " | " + strings.Join(tail.MediaKeys, " "); got != "a.jpg | b.jpg c.jpg" {
+		t.Errorf("split = %s", got)
+	}
+
+	if err := MergePostGroups(g, tail); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(g.MediaKeys, " "); got != "a.jpg b.jpg c.jpg" {
+		t.Errorf("merged keys = %s", got)
+	}
+	if err := MergePostGroups(g, g); err == nil {
+		t.Error("merge into itself accepted")
+	}
+	full := &PostGroup{ID: "grp-c", MediaKeys: make([]string, MaxPostGroupItems-2)}
+	if err := MergePostGroups(full, g); err == nil {
+		t.Error("oversized merge accepted")
+	}
+}
+
+func TestPostGroupSuggestedOrder(t *testing.T) {
+	g := &PostGroup{ID: "grp-a"}
+	if _, err := g.SuggestedOrder([]string{"a.jpg"}); err == nil {
+		t.Error("order without a suggestion accepted")
+	}
+	g.OrderSuggestion = &CarouselOrderSuggestion{Status: "complete", Keys: []string{"c.jpg", "a.jpg", "b.jpg"}}
+	order, err := g.SuggestedOrder([]string{"a.jpg", "b.jpg", "c.jpg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(order) != "[2 0 1]" {
+		t.Errorf("order = %v", order)
+	}
+	for _, keys := range [][]string{{"a.jpg", "b.jpg"}, {"a.jpg", "b.jpg", "d.jpg"}} {
+		if _, err := g.SuggestedOrder(keys); err == nil {
+			t.Errorf("suggestion applied to %v", keys)
+		}
+	}
+	g.OrderSuggestion.Status = "processing"
+	if _, err := g.SuggestedOrder([]string{"a.jpg", "b.jpg", "c.jpg"}); err == nil {
+		t.Error("unfinished suggestion applied")
+	}
+}
+
+func editSummary(groups []*PostGroup, edit PostGroupEdit) string {
+	var order, put []string
+	for _, g := range groups {
+		order = append(order, fmt.Sprintf("%s@%d", g.ID, g.Position))
+	}
+	for _, g := range edit.Put {
+		put = append(put, g.ID)
+	}
+	return strings.Join(order, " ") + " | put " + strings.Join(put, " ") + " | delete " + strings.Join(edit.Delete, " ")
+}
+
+func TestPostGroupEdits(t *testing.T) {
+	newGroups := func() []*PostGroup {
+		return []*PostGroup{
+			{ID: "grp-a", Position: 0, MediaKeys: []string{"a.jpg"}},
+			{ID: "grp-b", Position: 1, MediaKeys: []string{"b.jpg", "c.jpg"}},
+			{ID: "grp-c", Position: 2, MediaKeys: []string{"d.jpg"}},
+		}
+	}
+
+	groups := newGroups()
+	ordered, edit, err := SplitPostGroupEdit(groups, groups[1], &PostGroup{ID: "grp-new"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := editSummary(ordered, edit); got != "grp-a@0 grp-b@1 grp-new@2 grp-c@3 | put grp-b grp-new grp-c | delete " {
+		t.Errorf("split = %s", got)
+	}
+	if _, _, err := SplitPostGroupEdit(groups, groups[0], &PostGroup{ID: "grp-x"}, 1); err == nil {
+		t.Error("split of a one-item group accepted")
+	}
+
+	// Merging a group into a later one renumbers the destination too; it is
+	// written once.
+	groups = newGroups()
+	remaining, edit, err := MergePostGroupEdit(groups, groups[2], groups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := editSummary(remaining, edit); got != "grp-b@0 grp-c@1 | put grp-b grp-c | delete grp-a" {
+		t.Errorf("merge = %s", got)
+	}
+	if got := strings.Join(groups[2].MediaKeys, " "); got != "d.jpg a.jpg" {
+		t.Errorf("merged keys = %s", got)
+	}
+
+	groups = newGroups()
+	remaining, edit = DeletePostGroupEdit(groups, "grp-c")
+	if got := editSummary(remaining, edit); got != "grp-a@0 grp-b@1 | put  | delete grp-c" {
+		t.Errorf("delete = %s", got)
+	}
+}
@@ -0,0 +1,154 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Post group templates (DDR-122) ---
+
+const skTemplate = "TEMPLATE#"
+
+// MaxPostTemplates caps how many templates one user can keep.
+const MaxPostTemplates = 50
+
+// PostTemplate is a reusable post group format saved by a user (DynamoDB
+// PK = USER#{sub}, SK = TEMPLATE#{id}). Unlike session data it has no TTL:
+// templates exist to outlive the sessions they were made from.
+type PostTemplate struct {
+	ID         string `json:"id" dynamodbav:"-"`
+	Name       string `json:"name" dynamodbav:"name"`
+	GroupLabel string `json:"groupLabel,omitempty" dynamodbav:"groupLabel,omitempty"`
+	// CaptionSkeleton is an example or outline caption the description step
+	// follows for structure, tone and recurring lines.
+	CaptionSkeleton string   `json:"captionSkeleton,omitempty" dynamodbav:"captionSkeleton,omitempty"`
+	Hashtags        []string `json:"hashtags,omitempty" dynamodbav:"hashtags,omitempty"`
+	// EnhancementFeedback is applied to each photo of a group through the
+	// enhancement feedback flow, e.g. "warm tones, lift the shadows".
+	EnhancementFeedback string `json:"enhancementFeedback,omitempty" dynamodbav:"enhancementFeedback,omitempty"`
+	CreatedAt           int64  `json:"createdAt" dynamodbav:"createdAt"`
+	LastUsedAt          int64  `json:"lastUsedAt,omitempty" dynamodbav:"lastUsedAt,omitempty"`
+}
+
+// PutPostTemplate creates or replaces one of owner's templates. It bypasses
+// putItem so the record carries no expiresAt attribute.
+func (s *DynamoStore) PutPostTemplate(ctx context.Context, owner string, t *PostTemplate) error {
+	item, err := attributevalue.MarshalMap(t)
+	if err != nil {
+		return fmt.Errorf("marshal post template %s: %w", t.ID, err)
+	}
+	item["PK"] = &types.AttributeValueMemberS{Value: userPK(owner)}
+	item["SK"] = &types.AttributeValueMemberS{Value: skTemplate + t.ID}
+
+	if _, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &s.tableName,
+		Item:      item,
+	}); err != nil {
+		return fmt.Errorf("put post template %s: %w", t.ID, err)
+	}
+	log.Debug().Str("templateId", t.ID).Str("name", t.Name).Msg("Post template persisted")
+	return nil
+}
+
+// GetPostTemplate returns one of owner's templates, or nil, nil if it does
+// not exist.
+func (s *DynamoStore) GetPostTemplate(ctx context.Context, owner, templateID string) (*PostTemplate, error) {
+	var t PostTemplate
+	found, err := s.getItem(ctx, userPK(owner), skTemplate+templateID, &t)
+	if err != nil {
+		return nil, fmt.Errorf("get post template %s: %w", templateID, err)
+	}
+	if !found {
+		return nil, nil
+	}
+	t.ID = templateID
+	return &t, nil
+}
+
+// ListPostTemplates returns owner's templates, most recently used first.
+func (s *DynamoStore) ListPostTemplates(ctx context.Context, owner string) ([]PostTemplate, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              &s.tableName,
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: userPK(owner)},
+			":sk": &types.AttributeValueMemberS{Value: skTemplate},
+		},
+	}
+
+	templates := []PostTemplate{}
+	for {
+		result, err := s.client.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("query post templates: %w", err)
+		}
+		for _, item := range result.Items {
+			sk, ok := item["SK"].(*types.AttributeValueMemberS)
+			if !ok {
+				continue
+			}
+			var t PostTemplate
+			if err := attributevalue.UnmarshalMap(item, &t); err != nil {
+				log.Warn().Err(err).Str("sk", sk.Value).Msg("Failed to unmarshal post template, skipping")
+				continue
+			}
+			t.ID = strings.TrimPrefix(sk.Value, skTemplate)
+			templates = append(templates, t)
+		}
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+
+	sort.SliceStable(templates, func(i, j int) bool {
+		return templates[i].lastActive() > templates[j].lastActive()
+	})
+	return templates, nil
+}
+
+// DeletePostTemplate removes one of owner's templates. Deleting a missing
+// template is not an error.
+func (s *DynamoStore) DeletePostTemplate(ctx context.Context, owner, templateID string) error {
+	if err := s.deleteItem(ctx, userPK(owner), skTemplate+templateID); err != nil {
+		return fmt.Errorf("delete post template %s: %w", templateID, err)
+	}
+	return nil
+}
+
+// TouchPostTemplate records that a template was just applied, so the list
+// keeps recurring formats at the top. Best effort.
+func (s *DynamoStore) TouchPostTemplate(ctx context.Context, owner, templateID string) {
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: userPK(owner)},
+			"SK": &types.AttributeValueMemberS{Value: skTemplate + templateID},
+		},
+		UpdateExpression:    aws.String("SET lastUsedAt = :now"),
+		ConditionExpression: aws.String("attribute_exists(SK)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now": &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Unix(), 10)},
+		},
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("templateId", templateID).Msg("Failed to record post template use")
+	}
+}
+
+func (t *PostTemplate) lastActive() int64 {
+	if t.LastUsedAt > t.CreatedAt {
+		return t.LastUsedAt
+	}
+	return t.CreatedAt
+}
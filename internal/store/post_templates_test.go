@@ -0,0 +1,43 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPutPostTemplateHasNoExpiry(t *testing.T) {
+	table := newFakeDynamo()
+	s := newFakeDynamoStore(t, table)
+	if err := s.PutPostTemplate(context.Background(), "user-1", &PostTemplate{ID: "tpl-1", Name: "Dog Fridays", CreatedAt: 100}); err != nil {
+		t.Fatal(err)
+	}
+	item := table.item(userPK("user-1"), "TEMPLATE#tpl-1")
+	if item == nil {
+		t.Fatalf("template not stored under TEMPLATE#{id}: %v", table.keys())
+	}
+	if _, ok := item["expiresAt"]; ok {
+		t.Error("template was written with a TTL; templates must outlive their sessions")
+	}
+}
+
+func TestListPostTemplatesMostRecentlyUsedFirst(t *testing.T) {
+	table := newFakeDynamo()
+	s := newFakeDynamoStore(t, table)
+	table.put(userPK("user-1"), "TEMPLATE#old", fakeItem{"name": json.RawMessage(`{"S":"Old"}`), "createdAt": json.RawMessage(`{"N":"100"}`)})
+	table.put(userPK("user-1"), "TEMPLATE#used", fakeItem{"name": json.RawMessage(`{"S":"Used"}`), "createdAt": json.RawMessage(`{"N":"50"}`), "lastUsedAt": json.RawMessage(`{"N":"300"}`)})
+	table.put(userPK("user-1"), "TEMPLATE#new", fakeItem{"name": json.RawMessage(`{"S":"New"}`), "createdAt": json.RawMessage(`{"N":"200"}`)})
+
+	templates, err := s.ListPostTemplates(context.Background(), "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, tpl := range templates {
+		ids = append(ids, tpl.ID)
+	}
+	if got := strings.Join(ids, ","); got != "used,new,old" {
+		t.Errorf("order = %s, want used,new,old", got)
+	}
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// --- Provenance records (DDR-140) ---
+
+const skProvenance = "PROVENANCE"
+
+// ProvenanceSettings is a user's choice to embed provenance and AI-disclosure
+// metadata in enhanced and published photos (DynamoDB PK = USER#{sub},
+// SK = PROVENANCE). Like watermarks it has no TTL. Users without a record
+// get provenance embedded.
+type ProvenanceSettings struct {
+	Enabled   bool  `json:"enabled" dynamodbav:"enabled"`
+	UpdatedAt int64 `json:"updatedAt,omitempty" dynamodbav:"updatedAt,omitempty"`
+}
+
+// PutProvenanceSettings creates or replaces owner's provenance choice. It
+// bypasses putItem so the record carries no expiresAt attribute.
+func (s *DynamoStore) PutProvenanceSettings(ctx context.Context, owner string, p *ProvenanceSettings) error {
+	item, err := attributevalue.MarshalMap(p)
+	if err != nil {
+		return fmt.Errorf("marshal provenance settings: %w", err)
+	}
+	item["PK"] = &types.AttributeValueMemberS{Value: userPK(owner)}
+	item["SK"] = &types.AttributeValueMemberS{Value: skProvenance}
+
+	if _, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &s.tableName,
+		Item:      item,
+	}); err != nil {
+		return fmt.Errorf("put provenance settings: %w", err)
+	}
+	return nil
+}
+
+// GetProvenanceSettings returns owner's provenance choice, defaulting to
+// enabled when the user never made one.
+func (s *DynamoStore) GetProvenanceSettings(ctx context.Context, owner string) (*ProvenanceSettings, error) {
+	p := ProvenanceSettings{Enabled: true}
+	if _, err := s.getItem(ctx, userPK(owner), skProvenance, &p); err != nil {
+		return nil, fmt.Errorf("get provenance settings: %w", err)
+	}
+	return &p, nil
+}
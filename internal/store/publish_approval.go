@@ -0,0 +1,122 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Publish approval gate (DDR-121) ---
+
+const skApproval = "APPROVAL#"
+
+// Publish approval statuses. Only a pending approval can be decided.
+const (
+	ApprovalPending  = "pending"
+	ApprovalApproved = "approved"
+	ApprovalRejected = "rejected"
+)
+
+// PublishApprovalWindow is how long a publish job waits for a decision.
+// Instagram discards unpublished containers after 24 hours.
+const PublishApprovalWindow = 23 * time.Hour
+
+// PublishApproval is the sign-off a publish job waits for before finalizing
+// (DynamoDB SK = APPROVAL#{jobId}). It is kept apart from PublishJob because
+// the publish worker replaces the job record on every phase change.
+type PublishApproval struct {
+	JobID       string `json:"jobId" dynamodbav:"-"`
+	Status      string `json:"status" dynamodbav:"status"`
+	RequestedBy string `json:"requestedBy,omitempty" dynamodbav:"requestedBy,omitempty"`
+	RequestedAt int64  `json:"requestedAt" dynamodbav:"requestedAt"`
+	// TokenHash is the SHA-256 of the approval link's token; the token
+	// itself is only returned to the requester.
+	TokenHash string   `json:"-" dynamodbav:"tokenHash"`
+	Caption   string   `json:"caption" dynamodbav:"caption"`
+	Keys      []string `json:"keys" dynamodbav:"keys"`
+	DecidedBy string   `json:"decidedBy,omitempty" dynamodbav:"decidedBy,omitempty"`
+	DecidedAt int64    `json:"decidedAt,omitempty" dynamodbav:"decidedAt,omitempty"`
+	Via       string   `json:"via,omitempty" dynamodbav:"via,omitempty"` // "link", "approver" or "requester"
+}
+
+// Expired reports whether a pending approval has outlived PublishApprovalWindow.
+func (a *PublishApproval) Expired(now time.Time) bool {
+	return a.Status == ApprovalPending && now.Sub(time.Unix(a.RequestedAt, 0)) > PublishApprovalWindow
+}
+
+// Approval poll bounds. The publish state machine waits PollInterval
+// between approval checks; a fixed short wait over the whole window would
+// exceed the 25,000-event execution history limit.
+const (
+	minApprovalPoll = 30 * time.Second
+	maxApprovalPoll = 15 * time.Minute
+)
+
+// PollInterval is how long to wait before checking a pending approval
+// again: a tenth of the time it has been pending, between 30 seconds and 15
+// minutes. Early decisions are picked up quickly, and a wait over the whole
+// window takes about 130 checks.
+func (a *PublishApproval) PollInterval(now time.Time) time.Duration {
+	wait := now.Sub(time.Unix(a.RequestedAt, 0)) / 10
+	return min(max(wait, minApprovalPoll), maxApprovalPoll).Round(time.Second)
+}
+
+func (s *DynamoStore) PutPublishApproval(ctx context.Context, sessionID string, a *PublishApproval) error {
+	if err := s.putItem(ctx, sessionPK(sessionID), skApproval+a.JobID, a); err != nil {
+		return fmt.Errorf("put publish approval %s/%s: %w", sessionID, a.JobID, err)
+	}
+	log.Debug().Str("sessionId", sessionID).Str("jobId", a.JobID).Str("status", a.Status).Msg("Publish approval persisted")
+	return nil
+}
+
+// GetPublishApproval returns the approval for a publish job, or nil, nil if
+// the job was started without the gate.
+func (s *DynamoStore) GetPublishApproval(ctx context.Context, sessionID, jobID string) (*PublishApproval, error) {
+	var a PublishApproval
+	found, err := s.getItem(ctx, sessionPK(sessionID), skApproval+jobID, &a)
+	if err != nil {
+		return nil, fmt.Errorf("get publish approval %s/%s: %w", sessionID, jobID, err)
+	}
+	if !found {
+		return nil, nil
+	}
+	a.JobID = jobID
+	return &a, nil
+}
+
+// DecidePublishApproval moves a pending approval to decision ("approved" or
+// "rejected"). Returns ErrStatusConflict if it was already decided, so two
+// approvers racing cannot both act.
+func (s *DynamoStore) DecidePublishApproval(ctx context.Context, sessionID, jobID, decision, decidedBy, via string) error {
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: sessionPK(sessionID)},
+			"SK": &types.AttributeValueMemberS{Value: skApproval + jobID},
+		},
+		UpdateExpression:    aws.String("SET #st = :decision, decidedBy = :by, decidedAt = :now, via = :via"),
+		ConditionExpression: aws.String("#st = :pending"),
+		ExpressionAttributeNames: map[string]string{
+			"#st": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":decision": &types.AttributeValueMemberS{Value: decision},
+			":pending":  &types.AttributeValueMemberS{Value: ApprovalPending},
+			":by":       &types.AttributeValueMemberS{Value: decidedBy},
+			":via":      &types.AttributeValueMemberS{Value: via},
+			":now":      &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Unix(), 10)},
+		},
+	})
+	if err != nil {
+		return conditionalErr(fmt.Sprintf("DecidePublishApproval %s/%s", sessionID, jobID), err)
+	}
+
+	log.Info().Str("sessionId", sessionID).Str("jobId", jobID).Str("decision", decision).Str("via", via).Msg("Publish approval decided")
+	return nil
+}
@@ -0,0 +1,41 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPublishApprovalPollInterval(t *testing.T) {
+	requested := time.Unix(1_700_000_000, 0)
+	a := &PublishApproval{Status: ApprovalPending, RequestedAt: requested.Unix()}
+	tests := []struct {
+		pending time.Duration
+		want    time.Duration
+	}{
+		{0, 30 * time.Second},
+		{2 * time.Minute, 30 * time.Second},
+		{20 * time.Minute, 2 * time.Minute},
+		{3 * time.Hour, 15 * time.Minute},
+		{22 * time.Hour, 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := a.PollInterval(requested.Add(tt.pending)); got != tt.want {
+			t.Errorf("PollInterval after %s = %s, want %s", tt.pending, got, tt.want)
+		}
+	}
+}
+
+// TestPublishApprovalPollBound checks that waiting out the whole approval
+// window stays far below Step Functions' 25,000-event history limit, at
+// the ~6 history events each check-and-wait loop adds.
+func TestPublishApprovalPollBound(t *testing.T) {
+	requested := time.Unix(1_700_000_000, 0)
+	a := &PublishApproval{Status: ApprovalPending, RequestedAt: requested.Unix()}
+	checks := 0
+	for now := requested; !a.Expired(now); now = now.Add(a.PollInterval(now)) {
+		checks++
+	}
+	if events := checks * 6; events > 2000 {
+		t.Errorf("%d approval checks (~%d history events) over the window, want far below 25,000", checks, events)
+	}
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// --- Privacy redaction (DDR-185) ---
+
+const skRedact = "REDACT#"
+
+// RedactJob hides bystanders in one photo, outside an enhancement job
+// (DynamoDB SK = REDACT#{jobId}). RedactedKey is a copy; the original is
+// never modified.
+type RedactJob struct {
+	ID               string         `json:"id" dynamodbav:"-"`
+	SessionID        string         `json:"-" dynamodbav:"-"`
+	Status           string         `json:"status" dynamodbav:"status"`
+	Key              string         `json:"key" dynamodbav:"key"`
+	Mode             string         `json:"mode" dynamodbav:"mode"` // ai.RedactMode requested; Applied is what ran
+	Applied          string         `json:"applied,omitempty" dynamodbav:"applied,omitempty"`
+	RedactedKey      string         `json:"redactedKey,omitempty" dynamodbav:"redactedKey,omitempty"`
+	RedactedThumbKey string         `json:"redactedThumbKey,omitempty" dynamodbav:"redactedThumbKey,omitempty"`
+	Regions          []RedactRegion `json:"regions,omitempty" dynamodbav:"regions,omitempty"`
+	Error            string         `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	RetryCount       int            `json:"retryCount,omitempty" dynamodbav:"retryCount,omitempty"` // DDR-089
+}
+
+// RedactRegion is a bystander's face or a licence plate that was hidden,
+// in displayed pixels of the redacted photo.
+type RedactRegion struct {
+	Kind  string `json:"kind" dynamodbav:"kind"` // "face" or "plate"
+	Label string `json:"label,omitempty" dynamodbav:"label,omitempty"`
+	X     int    `json:"x" dynamodbav:"x"`
+	Y     int    `json:"y" dynamodbav:"y"`
+	W     int    `json:"w" dynamodbav:"w"`
+	H     int    `json:"h" dynamodbav:"h"`
+}
+
+func (s *DynamoStore) PutRedactJob(ctx context.Context, sessionID string, job *RedactJob) error {
+	if err := s.putJobItem(ctx, sessionPK(sessionID), skRedact+job.ID, job); err != nil {
+		return fmt.Errorf("put redact job %s/%s: %w", sessionID, job.ID, err)
+	}
+	log.Debug().Str("sessionId", sessionID).Str("jobId", job.ID).Str("status", job.Status).Int("regions", len(job.Regions)).Msg("Redact job persisted")
+	return nil
+}
+
+func (s *DynamoStore) GetRedactJob(ctx context.Context, sessionID, jobID string) (*RedactJob, error) {
+	var job RedactJob
+	found, err := s.getItem(ctx, sessionPK(sessionID), skRedact+jobID, &job)
+	if err != nil {
+		return nil, fmt.Errorf("get redact job %s/%s: %w", sessionID, jobID, err)
+	}
+	if !found {
+		return nil, nil
+	}
+	job.ID = jobID
+	job.SessionID = sessionID
+	return &job, nil
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// --- Gemini response cache (DDR-166) ---
+
+const (
+	responseCachePKPrefix = "GEMINI#"
+	skResponseCache       = "RESPONSE"
+)
+
+// ResponseCacheTTL is how long a cached Gemini result is kept. Keys change
+// with the content, prompt and model, so entries never go stale; the TTL
+// only bounds the table's growth.
+const ResponseCacheTTL = 30 * 24 * time.Hour
+
+type cachedResponse struct {
+	Data []byte `dynamodbav:"data"`
+}
+
+// GetCachedResponse implements ai.ResponseCache (DynamoDB
+// PK = GEMINI#{key}, SK = RESPONSE).
+func (s *DynamoStore) GetCachedResponse(ctx context.Context, key string) ([]byte, bool, error) {
+	var r cachedResponse
+	found, err := s.getItem(ctx, responseCachePKPrefix+key, skResponseCache, &r)
+	if err != nil {
+		return nil, false, fmt.Errorf("get cached response: %w", err)
+	}
+	if !found {
+		return nil, false, nil
+	}
+	return r.Data, true, nil
+}
+
+// PutCachedResponse implements ai.ResponseCache. It bypasses putItem to set
+// ResponseCacheTTL instead of SessionTTL.
+func (s *DynamoStore) PutCachedResponse(ctx context.Context, key string, data []byte) error {
+	item := map[string]types.AttributeValue{
+		"PK":        &types.AttributeValueMemberS{Value: responseCachePKPrefix + key},
+		"SK":        &types.AttributeValueMemberS{Value: skResponseCache},
+		"data":      &types.AttributeValueMemberB{Value: data},
+		"expiresAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Add(ResponseCacheTTL).Unix(), 10)},
+	}
+	if _, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &s.tableName,
+		Item:      item,
+	}); err != nil {
+		return fmt.Errorf("put cached response: %w", err)
+	}
+	return nil
+}
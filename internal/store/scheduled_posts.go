@@ -0,0 +1,279 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Scheduled publishing (DDR-144) ---
+
+// Scheduled post statuses. Only a scheduled post can be cancelled,
+// rescheduled or dispatched.
+const (
+	ScheduleStatusScheduled  = "scheduled"
+	ScheduleStatusDispatched = "dispatched"
+	ScheduleStatusCancelled  = "cancelled"
+	ScheduleStatusError      = "error"
+)
+
+const (
+	// ScheduledDueIndex is the sparse GSI of posts waiting to be dispatched:
+	// partition key "dueShard" (always scheduledDueValue), sort key
+	// "publishAt". The dueShard attribute only exists while a post is
+	// scheduled.
+	ScheduledDueIndex = "due-index"
+	scheduledDueValue = "DUE"
+
+	scheduledPKPrefix = "OWNER#"
+
+	// ScheduledPostRetention is how long a post is kept after its publish
+	// time, so users can see what went out.
+	ScheduledPostRetention = 7 * 24 * time.Hour
+)
+
+// ScheduledPost is a publish job waiting for its publish time in the
+// scheduled-posts table (PK = OWNER#{sub}, SK = {jobId}). Input is the
+// Publish Pipeline execution input, started unchanged at PublishAt.
+type ScheduledPost struct {
+	JobID        string `json:"id" dynamodbav:"-"`
+	OwnerSub     string `json:"-" dynamodbav:"ownerSub"`
+	SessionID    string `json:"sessionId" dynamodbav:"sessionId"`
+	GroupID      string `json:"groupId" dynamodbav:"groupId"`
+	PublishAt    int64  `json:"publishAt" dynamodbav:"publishAt"` // Unix seconds
+	Status       string `json:"status" dynamodbav:"status"`
+	Caption      string `json:"caption" dynamodbav:"caption"`
+	KeyCount     int    `json:"keyCount" dynamodbav:"keyCount"`
+	DryRun       bool   `json:"dryRun,omitempty" dynamodbav:"dryRun,omitempty"`
+	Input        string `json:"-" dynamodbav:"input"`
+	Attempts     int    `json:"attempts,omitempty" dynamodbav:"attempts,omitempty"`
+	CreatedAt    int64  `json:"createdAt" dynamodbav:"createdAt"`
+	DispatchedAt int64  `json:"dispatchedAt,omitempty" dynamodbav:"dispatchedAt,omitempty"`
+	Error        string `json:"error,omitempty" dynamodbav:"error,omitempty"`
+}
+
+// ScheduledPostStore provides operations on the dedicated scheduled-posts
+// DynamoDB table (DDR-144). The API writes and edits posts; the publish
+// scheduler Lambda dispatches them when due.
+type ScheduledPostStore struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+// NewScheduledPostStore creates a ScheduledPostStore for the given table.
+func NewScheduledPostStore(client *dynamodb.Client, tableName string) *ScheduledPostStore {
+	return &ScheduledPostStore{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+func scheduledPostKey(ownerSub, jobID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: scheduledPKPrefix + ownerSub},
+		"SK": &types.AttributeValueMemberS{Value: jobID},
+	}
+}
+
+func scheduledPostExpiresAt(publishAt int64) string {
+	return strconv.FormatInt(time.Unix(publishAt, 0).Add(ScheduledPostRetention).Unix(), 10)
+}
+
+// PutScheduledPost writes a new scheduled post and lists it in the due index.
+func (s *ScheduledPostStore) PutScheduledPost(ctx context.Context, p *ScheduledPost) error {
+	if p.CreatedAt == 0 {
+		p.CreatedAt = time.Now().Unix()
+	}
+	p.Status = ScheduleStatusScheduled
+	item, err := attributevalue.MarshalMap(p)
+	if err != nil {
+		return fmt.Errorf("marshal scheduled post: %w", err)
+	}
+	for k, v := range scheduledPostKey(p.OwnerSub, p.JobID) {
+		item[k] = v
+	}
+	item["dueShard"] = &types.AttributeValueMemberS{Value: scheduledDueValue}
+	item["expiresAt"] = &types.AttributeValueMemberN{Value: scheduledPostExpiresAt(p.PublishAt)}
+
+	if _, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &s.tableName,
+		Item:      item,
+	}); err != nil {
+		return fmt.Errorf("put scheduled post %s: %w", p.JobID, err)
+	}
+	log.Debug().Str("jobId", p.JobID).Int64("publishAt", p.PublishAt).Msg("Scheduled post persisted")
+	return nil
+}
+
+// GetScheduledPost returns one of the owner's scheduled posts, or nil if it
+// does not exist.
+func (s *ScheduledPostStore) GetScheduledPost(ctx context.Context, ownerSub, jobID string) (*ScheduledPost, error) {
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: &s.tableName,
+		Key:       scheduledPostKey(ownerSub, jobID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get scheduled post %s: %w", jobID, err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+	return unmarshalScheduledPost(result.Item)
+}
+
+// ListScheduledPosts returns the owner's posts, soonest publish time first.
+func (s *ScheduledPostStore) ListScheduledPosts(ctx context.Context, ownerSub string) ([]ScheduledPost, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              &s.tableName,
+		KeyConditionExpression: aws.String("PK = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: scheduledPKPrefix + ownerSub},
+		},
+	}
+	posts, err := s.queryScheduledPosts(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("list scheduled posts: %w", err)
+	}
+	sort.SliceStable(posts, func(i, j int) bool { return posts[i].PublishAt < posts[j].PublishAt })
+	return posts, nil
+}
+
+// ListDueScheduledPosts returns every scheduled post whose publish time is
+// at or before now, across all owners.
+func (s *ScheduledPostStore) ListDueScheduledPosts(ctx context.Context, now time.Time) ([]ScheduledPost, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              &s.tableName,
+		IndexName:              aws.String(ScheduledDueIndex),
+		KeyConditionExpression: aws.String("dueShard = :due AND publishAt <= :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":due": &types.AttributeValueMemberS{Value: scheduledDueValue},
+			":now": &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Unix(), 10)},
+		},
+	}
+	posts, err := s.queryScheduledPosts(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("list due scheduled posts: %w", err)
+	}
+	return posts, nil
+}
+
+// CancelScheduledPost cancels a scheduled post. Returns ErrStatusConflict if
+// it has already been dispatched or cancelled.
+func (s *ScheduledPostStore) CancelScheduledPost(ctx context.Context, ownerSub, jobID string) error {
+	return s.transition(ctx, ownerSub, jobID, "SET #st = :cancelled REMOVE dueShard", map[string]types.AttributeValue{
+		":cancelled": &types.AttributeValueMemberS{Value: ScheduleStatusCancelled},
+	})
+}
+
+// RescheduleScheduledPost moves a scheduled post to a new publish time.
+// Returns ErrStatusConflict if it is no longer scheduled.
+func (s *ScheduledPostStore) RescheduleScheduledPost(ctx context.Context, ownerSub, jobID string, publishAt time.Time) error {
+	return s.transition(ctx, ownerSub, jobID, "SET publishAt = :at, expiresAt = :exp", map[string]types.AttributeValue{
+		":at":  &types.AttributeValueMemberN{Value: strconv.FormatInt(publishAt.Unix(), 10)},
+		":exp": &types.AttributeValueMemberN{Value: scheduledPostExpiresAt(publishAt.Unix())},
+	})
+}
+
+// ClaimScheduledPost marks a due post dispatched so no other scheduler run
+// or API call acts on it. Returns ErrStatusConflict if it was cancelled or
+// claimed first.
+func (s *ScheduledPostStore) ClaimScheduledPost(ctx context.Context, ownerSub, jobID string) error {
+	return s.transition(ctx, ownerSub, jobID, "SET #st = :dispatched, dispatchedAt = :now ADD attempts :one REMOVE dueShard", map[string]types.AttributeValue{
+		":dispatched": &types.AttributeValueMemberS{Value: ScheduleStatusDispatched},
+		":now":        &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Unix(), 10)},
+		":one":        &types.AttributeValueMemberN{Value: "1"},
+	})
+}
+
+// ReleaseScheduledPost returns a claimed post to the due index after a
+// failed dispatch, so the next scheduler run retries it.
+func (s *ScheduledPostStore) ReleaseScheduledPost(ctx context.Context, ownerSub, jobID, errMsg string) error {
+	return s.setDispatchResult(ctx, ownerSub, jobID, "SET #st = :scheduled, dueShard = :due, #err = :err REMOVE dispatchedAt", map[string]types.AttributeValue{
+		":scheduled": &types.AttributeValueMemberS{Value: ScheduleStatusScheduled},
+		":due":       &types.AttributeValueMemberS{Value: scheduledDueValue},
+		":err":       &types.AttributeValueMemberS{Value: errMsg},
+	})
+}
+
+// FailScheduledPost records that a claimed post could not be dispatched and
+// will not be retried.
+func (s *ScheduledPostStore) FailScheduledPost(ctx context.Context, ownerSub, jobID, errMsg string) error {
+	return s.setDispatchResult(ctx, ownerSub, jobID, "SET #st = :error, #err = :err", map[string]types.AttributeValue{
+		":error": &types.AttributeValueMemberS{Value: ScheduleStatusError},
+		":err":   &types.AttributeValueMemberS{Value: errMsg},
+	})
+}
+
+// transition applies update to a post that is still scheduled.
+func (s *ScheduledPostStore) transition(ctx context.Context, ownerSub, jobID, update string, values map[string]types.AttributeValue) error {
+	values[":scheduled"] = &types.AttributeValueMemberS{Value: ScheduleStatusScheduled}
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 &s.tableName,
+		Key:                       scheduledPostKey(ownerSub, jobID),
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String("#st = :scheduled"),
+		ExpressionAttributeNames:  map[string]string{"#st": "status"},
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		return conditionalErr(fmt.Sprintf("update scheduled post %s", jobID), err)
+	}
+	return nil
+}
+
+// setDispatchResult applies update to a post this scheduler run claimed.
+func (s *ScheduledPostStore) setDispatchResult(ctx context.Context, ownerSub, jobID, update string, values map[string]types.AttributeValue) error {
+	values[":dispatched"] = &types.AttributeValueMemberS{Value: ScheduleStatusDispatched}
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 &s.tableName,
+		Key:                       scheduledPostKey(ownerSub, jobID),
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String("#st = :dispatched"),
+		ExpressionAttributeNames:  map[string]string{"#st": "status", "#err": "error"},
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		return conditionalErr(fmt.Sprintf("update scheduled post %s", jobID), err)
+	}
+	return nil
+}
+
+func (s *ScheduledPostStore) queryScheduledPosts(ctx context.Context, input *dynamodb.QueryInput) ([]ScheduledPost, error) {
+	var posts []ScheduledPost
+	for {
+		result, err := s.client.Query(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range result.Items {
+			p, err := unmarshalScheduledPost(item)
+			if err != nil {
+				return nil, err
+			}
+			posts = append(posts, *p)
+		}
+		if result.LastEvaluatedKey == nil {
+			return posts, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+func unmarshalScheduledPost(item map[string]types.AttributeValue) (*ScheduledPost, error) {
+	var p ScheduledPost
+	if err := attributevalue.UnmarshalMap(item, &p); err != nil {
+		return nil, fmt.Errorf("unmarshal scheduled post: %w", err)
+	}
+	if sk, ok := item["SK"].(*types.AttributeValueMemberS); ok {
+		p.JobID = sk.Value
+	}
+	return &p, nil
+}
@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var setStatus = regexp.MustCompile(`#st = (:\w+)`)
+
+// transitionSchedule applies the store's conditional status updates to the
+// stored post the way DynamoDB would: the update's "SET #st = :x" only lands
+// while the condition's status matches.
+func transitionSchedule(f *fakeDynamo, req *fakeRequest) (any, error) {
+	item, ok := f.items[req.Key.key()]
+	want := setStatus.FindStringSubmatch(req.ConditionExpression)
+	if !ok || want == nil || item.S("status") != req.ExpressionAttributeValues.S(want[1]) {
+		return nil, errFakeConditionFailed
+	}
+	if set := setStatus.FindStringSubmatch(req.UpdateExpression); set != nil {
+		item["status"] = req.ExpressionAttributeValues[set[1]]
+	}
+	return struct{}{}, nil
+}
+
+// newScheduleTestStore returns a store whose table holds user-1's posts at
+// the given statuses.
+func newScheduleTestStore(t *testing.T, statuses map[string]string) (*ScheduledPostStore, *fakeDynamo) {
+	t.Helper()
+	table := newFakeDynamo()
+	table.on["UpdateItem"] = transitionSchedule
+	for id, status := range statuses {
+		table.put(scheduledPKPrefix+"user-1", id, fakeItem{"status": json.RawMessage(`{"S":"` + status + `"}`)})
+	}
+	return NewScheduledPostStore(newFakeDynamoClient(t, table), "test-scheduled"), table
+}
+
+func scheduleStatus(table *fakeDynamo, id string) string {
+	return table.item(scheduledPKPrefix+"user-1", id).S("status")
+}
+
+func TestPutScheduledPostListsItAsDue(t *testing.T) {
+	s, table := newScheduleTestStore(t, nil)
+	publishAt := time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC).Unix()
+	if err := s.PutScheduledPost(context.Background(), &ScheduledPost{JobID: "pub-1", OwnerSub: "user-1", PublishAt: publishAt}); err != nil {
+		t.Fatal(err)
+	}
+	item := table.item(scheduledPKPrefix+"user-1", "pub-1")
+	if due := item.S("dueShard"); due != scheduledDueValue {
+		t.Errorf("dueShard = %q, want %q", due, scheduledDueValue)
+	}
+	if want := strconv.FormatInt(publishAt+int64(ScheduledPostRetention/time.Second), 10); item.N("expiresAt") != want {
+		t.Errorf("expiresAt = %s, want %s", item.N("expiresAt"), want)
+	}
+	if got := scheduleStatus(table, "pub-1"); got != ScheduleStatusScheduled {
+		t.Errorf("status = %q", got)
+	}
+}
+
+func TestScheduledPostTransitions(t *testing.T) {
+	ctx := context.Background()
+	s, table := newScheduleTestStore(t, map[string]string{
+		"cancel-me": ScheduleStatusScheduled,
+		"claim-me":  ScheduleStatusScheduled,
+	})
+
+	// A cancelled post can be neither cancelled again, rescheduled nor
+	// dispatched.
+	if err := s.CancelScheduledPost(ctx, "user-1", "cancel-me"); err != nil {
+		t.Fatal(err)
+	}
+	for name, err := range map[string]error{
+		"cancel":     s.CancelScheduledPost(ctx, "user-1", "cancel-me"),
+		"reschedule": s.RescheduleScheduledPost(ctx, "user-1", "cancel-me", time.Now().Add(time.Hour)),
+		"claim":      s.ClaimScheduledPost(ctx, "user-1", "cancel-me"),
+	} {
+		if !errors.Is(err, ErrStatusConflict) {
+			t.Errorf("%s after cancel: err = %v, want ErrStatusConflict", name, err)
+		}
+	}
+
+	// Only one scheduler run can claim a post.
+	if err := s.ClaimScheduledPost(ctx, "user-1", "claim-me"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ClaimScheduledPost(ctx, "user-1", "claim-me"); !errors.Is(err, ErrStatusConflict) {
+		t.Errorf("second claim: err = %v, want ErrStatusConflict", err)
+	}
+	if err := s.CancelScheduledPost(ctx, "user-1", "claim-me"); !errors.Is(err, ErrStatusConflict) {
+		t.Errorf("cancel after dispatch: err = %v, want ErrStatusConflict", err)
+	}
+
+	// A failed dispatch puts the post back for the next run.
+	if err := s.ReleaseScheduledPost(ctx, "user-1", "claim-me", "throttled"); err != nil {
+		t.Fatal(err)
+	}
+	if got := scheduleStatus(table, "claim-me"); got != ScheduleStatusScheduled {
+		t.Errorf("status after release = %q, want scheduled", got)
+	}
+	if err := s.ClaimScheduledPost(ctx, "user-1", "claim-me"); err != nil {
+		t.Errorf("claim after release: %v", err)
+	}
+	if err := s.FailScheduledPost(ctx, "user-1", "claim-me", "bad input"); err != nil {
+		t.Fatal(err)
+	}
+	if got := scheduleStatus(table, "claim-me"); got != ScheduleStatusError {
+		t.Errorf("status after fail = %q, want error", got)
+	}
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Selection re-run changes (DDR-158) ---
+
+// SelectionDiff is what changed between a selection job and the session's
+// previous completed selection job. Items are matched by S3 key.
+type SelectionDiff struct {
+	PreviousJobID string `json:"previousJobId" dynamodbav:"previousJobId"`
+	// Added holds items selected now but not before, in rank order.
+	Added []SelectionChange `json:"added,omitempty" dynamodbav:"added,omitempty"`
+	// Dropped holds items selected before but not now, in their previous
+	// rank order, with the reason the new job left them out.
+	Dropped []SelectionChange `json:"dropped,omitempty" dynamodbav:"dropped,omitempty"`
+	// Moved holds items selected both times at a different rank.
+	Moved []SelectionChange `json:"moved,omitempty" dynamodbav:"moved,omitempty"`
+	// Unchanged counts the items selected both times at the same rank.
+	Unchanged int `json:"unchanged" dynamodbav:"unchanged"`
+}
+
+// SelectionChange is one item of a SelectionDiff. Rank is 0 for a dropped
+// item, and PreviousRank 0 for an added one.
+type SelectionChange struct {
+	Key          string `json:"key" dynamodbav:"key"`
+	Filename     string `json:"filename" dynamodbav:"filename"`
+	Rank         int    `json:"rank,omitempty" dynamodbav:"rank,omitempty"`
+	PreviousRank int    `json:"previousRank,omitempty" dynamodbav:"previousRank,omitempty"`
+	Reason       string `json:"reason,omitempty" dynamodbav:"reason,omitempty"`
+}
+
+// DiffSelections compares a selection with an earlier one of the session.
+func DiffSelections(prev, cur *SelectionJob) *SelectionDiff {
+	d := &SelectionDiff{PreviousJobID: prev.ID}
+
+	prevRank := make(map[string]int, len(prev.Selected))
+	for _, it := range prev.Selected {
+		prevRank[itemKey(it.Key, it.Filename)] = it.Rank
+	}
+	curKeys := make(map[string]bool, len(cur.Selected))
+	for _, it := range cur.Selected {
+		k := itemKey(it.Key, it.Filename)
+		curKeys[k] = true
+		change := SelectionChange{Key: it.Key, Filename: it.Filename, Rank: it.Rank}
+		switch before, ok := prevRank[k]; {
+		case !ok:
+			d.Added = append(d.Added, change)
+		case before != it.Rank:
+			change.PreviousRank = before
+			d.Moved = append(d.Moved, change)
+		default:
+			d.Unchanged++
+		}
+	}
+
+	reasons := make(map[string]string, len(cur.Excluded)+len(cur.Unprocessed))
+	for _, it := range cur.Excluded {
+		reasons[itemKey(it.Key, it.Filename)] = it.Reason
+	}
+	for _, it := range cur.Unprocessed {
+		reasons[itemKey(it.Key, it.Filename)] = it.Reason
+	}
+	for _, it := range prev.Selected {
+		k := itemKey(it.Key, it.Filename)
+		if !curKeys[k] {
+			d.Dropped = append(d.Dropped, SelectionChange{
+				Key: it.Key, Filename: it.Filename, PreviousRank: it.Rank, Reason: reasons[k],
+			})
+		}
+	}
+	return d
+}
+
+// itemKey identifies an item across jobs: its S3 key, or its file name for
+// items recorded without one.
+func itemKey(key, filename string) string {
+	if key != "" {
+		return key
+	}
+	return filename
+}
+
+// SelectionChanges compares job with the session's most recently completed
+// earlier selection job. Jobs are ordered by CompletedAt, which job must
+// already carry. Returns nil, nil when there is no earlier job to compare
+// with, including jobs completed before DDR-158 recorded the time.
+func (s *DynamoStore) SelectionChanges(ctx context.Context, sessionID string, job *SelectionJob) (*SelectionDiff, error) {
+	if job.CompletedAt == 0 {
+		return nil, nil
+	}
+	items, err := s.queryBySKPrefix(ctx, sessionID, skSelection)
+	if err != nil {
+		return nil, fmt.Errorf("list selection jobs %s: %w", sessionID, err)
+	}
+
+	var prev *SelectionJob
+	for _, item := range items {
+		skAttr, ok := item["SK"].(*types.AttributeValueMemberS)
+		if !ok {
+			continue
+		}
+		id := strings.TrimPrefix(skAttr.Value, skSelection)
+		if id == job.ID {
+			continue
+		}
+		var candidate SelectionJob
+		if err := attributevalue.UnmarshalMap(item, &candidate); err != nil {
+			return nil, fmt.Errorf("unmarshal selection job %s/%s: %w", sessionID, id, err)
+		}
+		if candidate.Status != "complete" || candidate.CompletedAt == 0 || candidate.CompletedAt > job.CompletedAt {
+			continue
+		}
+		if prev == nil || candidate.CompletedAt > prev.CompletedAt {
+			candidate.ID = id
+			prev = &candidate
+		}
+	}
+	if prev == nil {
+		return nil, nil
+	}
+
+	d := DiffSelections(prev, job)
+	log.Debug().
+		Str("sessionId", sessionID).
+		Str("jobId", job.ID).
+		Str("previousJobId", prev.ID).
+		Int("added", len(d.Added)).
+		Int("dropped", len(d.Dropped)).
+		Int("moved", len(d.Moved)).
+		Msg("Selection changes computed")
+	return d, nil
+}
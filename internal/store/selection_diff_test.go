@@ -0,0 +1,49 @@
+package store
+
+import "testing"
+
+func TestDiffSelections(t *testing.T) {
+	prev := &SelectionJob{
+		ID: "sel-1",
+		Selected: []SelectedItem{
+			{Rank: 1, Key: "s/a.jpg", Filename: "a.jpg"},
+			{Rank: 2, Key: "s/b.jpg", Filename: "b.jpg"},
+			{Rank: 3, Key: "s/c.jpg", Filename: "c.jpg"},
+			{Rank: 4, Filename: "d.jpg"},
+		},
+	}
+	cur := &SelectionJob{
+		ID: "sel-2",
+		Selected: []SelectedItem{
+			{Rank: 1, Key: "s/a.jpg", Filename: "a.jpg"},
+			{Rank: 2, Key: "s/c.jpg", Filename: "c.jpg"},
+			{Rank: 3, Key: "s/e.jpg", Filename: "e.jpg"},
+			{Rank: 4, Filename: "d.jpg"},
+		},
+		Excluded: []ExcludedItem{{Key: "s/b.jpg", Filename: "b.jpg", Reason: "blurry"}},
+	}
+
+	d := DiffSelections(prev, cur)
+	if d.PreviousJobID != "sel-1" || d.Unchanged != 2 {
+		t.Errorf("PreviousJobID = %q, Unchanged = %d", d.PreviousJobID, d.Unchanged)
+	}
+	if len(d.Added) != 1 || d.Added[0].Filename != "e.jpg" || d.Added[0].Rank != 3 {
+		t.Errorf("Added = %+v", d.Added)
+	}
+	if len(d.Dropped) != 1 || d.Dropped[0].Filename != "b.jpg" || d.Dropped[0].PreviousRank != 2 || d.Dropped[0].Reason != "blurry" {
+		t.Errorf("Dropped = %+v", d.Dropped)
+	}
+	if len(d.Moved) != 1 || d.Moved[0].Filename != "c.jpg" || d.Moved[0].Rank != 2 || d.Moved[0].PreviousRank != 3 {
+		t.Errorf("Moved = %+v", d.Moved)
+	}
+}
+
+func TestDiffSelectionsUnprocessedReason(t *testing.T) {
+	prev := &SelectionJob{ID: "sel-1", Selected: []SelectedItem{{Rank: 1, Key: "s/a.mp4", Filename: "a.mp4"}}}
+	cur := &SelectionJob{ID: "sel-2", Unprocessed: []UnprocessedItem{{Key: "s/a.mp4", Filename: "a.mp4", Reason: "file too large"}}}
+
+	d := DiffSelections(prev, cur)
+	if len(d.Dropped) != 1 || d.Dropped[0].Reason != "file too large" || d.Dropped[0].Rank != 0 {
+		t.Errorf("Dropped = %+v", d.Dropped)
+	}
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+)
+
+// --- Selection score breakdown (DDR-150) ---
+
+// Selection criteria a selected item is scored against, and "overall", the
+// sum of all four.
+const (
+	CriterionComposition      = "composition"
+	CriterionStoryValue       = "storyValue"
+	CriterionUniqueness       = "uniqueness"
+	CriterionTechnicalQuality = "technicalQuality"
+	CriterionOverall          = "overall"
+)
+
+// SelectionScores rates a selected item against each criterion, 1–10.
+type SelectionScores struct {
+	Composition      int `json:"composition" dynamodbav:"composition"`
+	StoryValue       int `json:"storyValue" dynamodbav:"storyValue"`
+	Uniqueness       int `json:"uniqueness" dynamodbav:"uniqueness"`
+	TechnicalQuality int `json:"technicalQuality" dynamodbav:"technicalQuality"`
+}
+
+// Score returns the score for a criterion name, or false for an unknown name.
+func (s SelectionScores) Score(criterion string) (int, bool) {
+	switch criterion {
+	case CriterionComposition:
+		return s.Composition, true
+	case CriterionStoryValue:
+		return s.StoryValue, true
+	case CriterionUniqueness:
+		return s.Uniqueness, true
+	case CriterionTechnicalQuality:
+		return s.TechnicalQuality, true
+	case CriterionOverall:
+		return s.Composition + s.StoryValue + s.Uniqueness + s.TechnicalQuality, true
+	}
+	return 0, false
+}
+
+// SortSelectedBy orders items by a criterion, highest first. Items without
+// scores (selected before DDR-150, or added by an override) go last; ties
+// keep rank order.
+func SortSelectedBy(items []SelectedItem, criterion string) error {
+	if _, ok := (SelectionScores{}).Score(criterion); !ok {
+		return fmt.Errorf("unknown criterion %q", criterion)
+	}
+	score := func(it SelectedItem) int {
+		if it.Scores == nil {
+			return -1
+		}
+		v, _ := it.Scores.Score(criterion)
+		return v
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if a, b := score(items[i]), score(items[j]); a != b {
+			return a > b
+		}
+		return items[i].Rank < items[j].Rank
+	})
+	return nil
+}
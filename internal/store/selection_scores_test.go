@@ -0,0 +1,37 @@
+package store
+
+import "testing"
+
+func TestSortSelectedBy(t *testing.T) {
+	items := []SelectedItem{
+		{Rank: 1, Filename: "a.jpg", Scores: &SelectionScores{Composition: 6, StoryValue: 9, Uniqueness: 5, TechnicalQuality: 7}},
+		{Rank: 2, Filename: "b.jpg"},
+		{Rank: 3, Filename: "c.jpg", Scores: &SelectionScores{Composition: 9, StoryValue: 4, Uniqueness: 8, TechnicalQuality: 7}},
+		{Rank: 4, Filename: "d.jpg", Scores: &SelectionScores{Composition: 9, StoryValue: 6, Uniqueness: 6, TechnicalQuality: 6}},
+	}
+	for _, tc := range []struct {
+		criterion string
+		want      string
+	}{
+		{CriterionComposition, "c.jpg d.jpg a.jpg b.jpg"},
+		{CriterionStoryValue, "a.jpg d.jpg c.jpg b.jpg"},
+		{CriterionOverall, "c.jpg a.jpg d.jpg b.jpg"},
+	} {
+		if err := SortSelectedBy(items, tc.criterion); err != nil {
+			t.Fatal(err)
+		}
+		got := ""
+		for i, it := range items {
+			if i > 0 {
+				got += " "
+			}
+			got += it.Filename
+		}
+		if got != tc.want {
+			t.Errorf("sort by %s = %s, want %s", tc.criterion, got, tc.want)
+		}
+	}
+	if err := SortSelectedBy(items, "vibes"); err == nil {
+		t.Error("expected an error for an unknown criterion")
+	}
+}
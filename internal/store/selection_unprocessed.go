@@ -0,0 +1,37 @@
+package store
+
+import "path/filepath"
+
+// --- Files selection could not consider (DDR-134) ---
+
+// AddUnprocessed lists key under Unprocessed with reason.
+func (j *SelectionJob) AddUnprocessed(key, reason string) {
+	j.Unprocessed = append(j.Unprocessed, UnprocessedItem{Filename: filepath.Base(key), Key: key, Reason: reason})
+}
+
+// AddUnjudged lists under Unprocessed, with reason, each of keys the job
+// neither selected nor excluded and has not listed already: the files the
+// AI left out of its answer. Empty keys are skipped. It returns the keys it
+// added.
+func (j *SelectionJob) AddUnjudged(keys []string, reason string) []string {
+	known := make(map[string]bool, len(j.Selected)+len(j.Excluded)+len(j.Unprocessed))
+	for _, it := range j.Selected {
+		known[it.Key] = true
+	}
+	for _, it := range j.Excluded {
+		known[it.Key] = true
+	}
+	for _, it := range j.Unprocessed {
+		known[it.Key] = true
+	}
+	var added []string
+	for _, key := range keys {
+		if key == "" || known[key] {
+			continue
+		}
+		known[key] = true
+		j.AddUnprocessed(key, reason)
+		added = append(added, key)
+	}
+	return added
+}
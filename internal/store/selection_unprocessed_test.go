@@ -0,0 +1,32 @@
+package store
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelectionJobAddUnjudged(t *testing.T) {
+	job := &SelectionJob{
+		Selected:    []SelectedItem{{Key: "s/a.jpg"}},
+		Excluded:    []ExcludedItem{{Key: "s/b.jpg"}},
+		Unprocessed: []UnprocessedItem{{Filename: "c.heic", Key: "s/c.heic", Reason: "Could not be downloaded from storage"}},
+	}
+	added := job.AddUnjudged([]string{"s/a.jpg", "s/b.jpg", "s/c.heic", "s/d.jpg", "", "s/d.jpg", "s/e.mov"}, "Not evaluated by AI")
+	if got := strings.Join(added, ","); got != "s/d.jpg,s/e.mov" {
+		t.Errorf("added = %s, want s/d.jpg,s/e.mov", got)
+	}
+	if len(job.Unprocessed) != 3 {
+		t.Fatalf("Unprocessed = %+v", job.Unprocessed)
+	}
+	if job.Unprocessed[0].Reason != "Could not be downloaded from storage" {
+		t.Errorf("earlier reason replaced: %+v", job.Unprocessed[0])
+	}
+	if d := job.Unprocessed[1]; d.Filename != "d.jpg" || d.Key != "s/d.jpg" || d.Reason != "Not evaluated by AI" {
+		t.Errorf("Unprocessed[1] = %+v", d)
+	}
+
+	// A second round adds nothing already listed.
+	if added := job.AddUnjudged([]string{"s/d.jpg"}, "Not evaluated by AI"); len(added) != 0 || len(job.Unprocessed) != 3 {
+		t.Errorf("second round added %v, Unprocessed = %d", added, len(job.Unprocessed))
+	}
+}
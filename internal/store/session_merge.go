@@ -0,0 +1,373 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Duplicate session detection and merge (DDR-113) ---
+
+// skSignature holds a session's SessionSignature, written when triage runs.
+const skSignature = "SIGNATURE"
+
+// Duplicate detection thresholds. Each overlap is measured against the
+// smaller session, so a second session holding a subset of the first trip
+// still matches.
+const (
+	minFilenameOverlap    = 0.5
+	minFingerprintOverlap = 0.3
+	minCaptureOverlap     = 0.8
+	// minCaptureSpan pads single-moment sessions so a burst taken within a
+	// minute can still overlap a longer range.
+	minCaptureSpan = 60 * time.Second
+)
+
+// SessionSignature summarizes a session's uploads for duplicate detection.
+// Capture times are Unix seconds and zero when no file had a capture date.
+type SessionSignature struct {
+	Filenames       []string `dynamodbav:"filenames,stringset,omitempty"`
+	Fingerprints    []string `dynamodbav:"fingerprints,stringset,omitempty"`
+	EarliestCapture int64    `dynamodbav:"earliestCapture,omitempty"`
+	LatestCapture   int64    `dynamodbav:"latestCapture,omitempty"`
+}
+
+// DuplicateSession is another session of the same owner that probably holds
+// the same trip. Reasons are short, user-facing explanations of the match.
+type DuplicateSession struct {
+	SessionID string   `json:"sessionId" dynamodbav:"sessionId"`
+	CreatedAt int64    `json:"createdAt" dynamodbav:"createdAt"`
+	Reasons   []string `json:"reasons" dynamodbav:"reasons"`
+}
+
+// NewSessionSignature builds a signature from per-file processing results.
+// Filenames are lowercased; capture times come from the "date" metadata
+// written by media-process.
+func NewSessionSignature(results []FileResult) SessionSignature {
+	var sig SessionSignature
+	names := make(map[string]bool)
+	fps := make(map[string]bool)
+	for _, fr := range results {
+		if fr.Filename != "" {
+			names[strings.ToLower(fr.Filename)] = true
+		}
+		if fr.Fingerprint != "" {
+			fps[fr.Fingerprint] = true
+		}
+		t, err := time.Parse(time.RFC3339, fr.Metadata["date"])
+		if err != nil || t.IsZero() {
+			continue
+		}
+		if ts := t.Unix(); sig.EarliestCapture == 0 || ts < sig.EarliestCapture {
+			sig.EarliestCapture = ts
+		}
+		if ts := t.Unix(); ts > sig.LatestCapture {
+			sig.LatestCapture = ts
+		}
+	}
+	sig.Filenames = sortedKeys(names)
+	sig.Fingerprints = sortedKeys(fps)
+	return sig
+}
+
+// MatchSessions reports why two signatures look like the same trip. It
+// returns no reasons when they do not.
+func MatchSessions(a, b SessionSignature) []string {
+	var reasons []string
+	if n, frac := setOverlap(a.Filenames, b.Filenames); frac >= minFilenameOverlap {
+		reasons = append(reasons, fmt.Sprintf("%d matching filenames", n))
+	}
+	if n, frac := setOverlap(a.Fingerprints, b.Fingerprints); frac >= minFingerprintOverlap {
+		reasons = append(reasons, fmt.Sprintf("%d identical files", n))
+	}
+	if captureOverlap(a, b) >= minCaptureOverlap {
+		reasons = append(reasons, "overlapping capture dates")
+	}
+	return reasons
+}
+
+// setOverlap returns the size of the intersection and its fraction of the
+// smaller set. Both slices must be free of duplicates.
+func setOverlap(a, b []string) (int, float64) {
+	if len(a) == 0 || len(b) == 0 {
+		return 0, 0
+	}
+	in := make(map[string]bool, len(a))
+	for _, v := range a {
+		in[v] = true
+	}
+	n := 0
+	for _, v := range b {
+		if in[v] {
+			n++
+		}
+	}
+	return n, float64(n) / float64(min(len(a), len(b)))
+}
+
+// captureOverlap returns how much of the shorter capture range lies inside
+// the other, from 0 to 1. Sessions without capture dates never overlap.
+func captureOverlap(a, b SessionSignature) float64 {
+	if a.EarliestCapture == 0 || b.EarliestCapture == 0 {
+		return 0
+	}
+	pad := func(s SessionSignature) (int64, int64) {
+		start, end := s.EarliestCapture, s.LatestCapture
+		if span := int64(minCaptureSpan.Seconds()); end-start < span {
+			mid := (start + end) / 2
+			start, end = mid-span/2, mid+span/2
+		}
+		return start, end
+	}
+	as, ae := pad(a)
+	bs, be := pad(b)
+	overlap := min(ae, be) - max(as, bs)
+	if overlap <= 0 {
+		return 0
+	}
+	return float64(overlap) / float64(min(ae-as, be-bs))
+}
+
+func sortedKeys(m map[string]bool) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// PutSessionSignature stores a session's signature so later sessions of the
+// same owner can be compared against it.
+func (s *DynamoStore) PutSessionSignature(ctx context.Context, sessionID string, sig SessionSignature) error {
+	if err := s.putItem(ctx, sessionPK(sessionID), skSignature, sig); err != nil {
+		return fmt.Errorf("put session signature %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// GetSessionSignature returns a session's signature, or nil if triage has
+// not run for it.
+func (s *DynamoStore) GetSessionSignature(ctx context.Context, sessionID string) (*SessionSignature, error) {
+	var sig SessionSignature
+	found, err := s.getItem(ctx, sessionPK(sessionID), skSignature, &sig)
+	if err != nil {
+		return nil, fmt.Errorf("get session signature %s: %w", sessionID, err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &sig, nil
+}
+
+// FindDuplicateSessions compares sig with the signatures of the owner's other
+// sessions and returns the probable duplicates, newest first.
+func (s *DynamoStore) FindDuplicateSessions(ctx context.Context, ownerSub, sessionID string, sig SessionSignature) ([]DuplicateSession, error) {
+	if ownerSub == "" {
+		return nil, nil
+	}
+	refs, err := s.querySessionRefs(ctx, ownerSub)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []DuplicateSession
+	for _, ref := range refs {
+		if ref.ID == sessionID {
+			continue
+		}
+		other, err := s.GetSessionSignature(ctx, ref.ID)
+		if err != nil {
+			return nil, err
+		}
+		if other == nil {
+			continue
+		}
+		if reasons := MatchSessions(sig, *other); len(reasons) > 0 {
+			out = append(out, DuplicateSession{SessionID: ref.ID, CreatedAt: ref.CreatedAt, Reasons: reasons})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt > out[j].CreatedAt })
+	return out, nil
+}
+
+// MergeSession moves the job history of sourceID into targetID and deletes
+// sourceID. Every string in the moved items has sourceID replaced by
+// targetID, so S3 keys and thumbnail URLs follow objects the caller has
+// copied to the target prefix. Items whose sort key already exists in the
+// target are dropped (the target wins). The source's rows in the
+// file-processing table, when one is set, are moved the same way. Returns
+// the number of session-table items moved.
+//
+// The caller must copy S3 objects first and ensure no job is running in
+// either session.
+func (s *DynamoStore) MergeSession(ctx context.Context, targetID, sourceID string) (int, error) {
+	target, err := s.GetSession(ctx, targetID)
+	if err != nil {
+		return 0, err
+	}
+	source, err := s.GetSession(ctx, sourceID)
+	if err != nil {
+		return 0, err
+	}
+	if target == nil || source == nil {
+		return 0, fmt.Errorf("merge %s into %s: session not found", sourceID, targetID)
+	}
+
+	targetItems, err := s.querySession(ctx, targetID)
+	if err != nil {
+		return 0, fmt.Errorf("merge into %s: %w", targetID, err)
+	}
+	existing := make(map[string]bool, len(targetItems))
+	for _, item := range targetItems {
+		if sk, ok := item["SK"].(*types.AttributeValueMemberS); ok {
+			existing[sk.Value] = true
+		}
+	}
+
+	sourceItems, err := s.querySession(ctx, sourceID)
+	if err != nil {
+		return 0, fmt.Errorf("merge from %s: %w", sourceID, err)
+	}
+	var moved []map[string]types.AttributeValue
+	var triageJobIDs []string
+	skipped := 0
+	for _, item := range sourceItems {
+		sk, ok := item["SK"].(*types.AttributeValueMemberS)
+		if !ok || sk.Value == skMeta || sk.Value == skSignature {
+			continue
+		}
+		if existing[sk.Value] {
+			skipped++
+			continue
+		}
+		if strings.HasPrefix(sk.Value, skTriage) {
+			triageJobIDs = append(triageJobIDs, strings.TrimPrefix(sk.Value, skTriage))
+		}
+		out := make(map[string]types.AttributeValue, len(item))
+		for k, v := range item {
+			out[k] = replaceSessionID(v, sourceID, targetID)
+		}
+		out["PK"] = &types.AttributeValueMemberS{Value: sessionPK(targetID)}
+		out["SK"] = sk
+		moved = append(moved, out)
+	}
+	if err := s.batchPutItems(ctx, moved); err != nil {
+		return 0, fmt.Errorf("merge %s into %s: %w", sourceID, targetID, err)
+	}
+
+	// The file statuses, thumbnail index and per-job file results must be
+	// copied before DeleteSession removes them with the source.
+	fileRows := 0
+	if s.fileProcessing != nil {
+		if fileRows, err = s.fileProcessing.MoveSessionRecords(ctx, targetID, sourceID, triageJobIDs); err != nil {
+			return 0, fmt.Errorf("merge %s into %s: %w", sourceID, targetID, err)
+		}
+	}
+
+	// META: union the uploaded keys; keep the target's trip context unless empty.
+	seen := make(map[string]bool, len(target.UploadedKeys))
+	for _, k := range target.UploadedKeys {
+		seen[k] = true
+	}
+	for _, k := range source.UploadedKeys {
+		k = strings.Replace(k, sourceID, targetID, 1)
+		if !seen[k] {
+			seen[k] = true
+			target.UploadedKeys = append(target.UploadedKeys, k)
+		}
+	}
+	if target.TripContext == "" {
+		target.TripContext = source.TripContext
+	}
+	if err := s.PutSession(ctx, target); err != nil {
+		return len(moved), err
+	}
+
+	// Signature: union, so the merged session is matched as one trip.
+	if err := s.mergeSignatures(ctx, targetID, sourceID); err != nil {
+		log.Warn().Err(err).Str("sessionId", targetID).Msg("Failed to merge session signatures")
+	}
+
+	if _, err := s.DeleteSession(ctx, sourceID); err != nil {
+		return len(moved), err
+	}
+
+	log.Info().
+		Str("targetSessionId", targetID).
+		Str("sourceSessionId", sourceID).
+		Int("moved", len(moved)).
+		Int("skipped", skipped).
+		Int("fileRows", fileRows).
+		Msg("Session merged")
+	return len(moved), nil
+}
+
+func (s *DynamoStore) mergeSignatures(ctx context.Context, targetID, sourceID string) error {
+	a, err := s.GetSessionSignature(ctx, targetID)
+	if err != nil {
+		return err
+	}
+	b, err := s.GetSessionSignature(ctx, sourceID)
+	if err != nil || b == nil {
+		return err
+	}
+	if a == nil {
+		return s.PutSessionSignature(ctx, targetID, *b)
+	}
+	union := func(x, y []string) []string {
+		m := make(map[string]bool, len(x)+len(y))
+		for _, v := range append(x, y...) {
+			m[v] = true
+		}
+		return sortedKeys(m)
+	}
+	merged := SessionSignature{
+		Filenames:       union(a.Filenames, b.Filenames),
+		Fingerprints:    union(a.Fingerprints, b.Fingerprints),
+		EarliestCapture: a.EarliestCapture,
+		LatestCapture:   max(a.LatestCapture, b.LatestCapture),
+	}
+	if b.EarliestCapture != 0 && (merged.EarliestCapture == 0 || b.EarliestCapture < merged.EarliestCapture) {
+		merged.EarliestCapture = b.EarliestCapture
+	}
+	return s.PutSessionSignature(ctx, targetID, merged)
+}
+
+// replaceSessionID returns v with every occurrence of from replaced by to in
+// its strings, recursing into lists and maps. Session IDs are UUIDs, so they
+// do not occur by accident.
+func replaceSessionID(v types.AttributeValue, from, to string) types.AttributeValue {
+	switch t := v.(type) {
+	case *types.AttributeValueMemberS:
+		return &types.AttributeValueMemberS{Value: strings.ReplaceAll(t.Value, from, to)}
+	case *types.AttributeValueMemberSS:
+		out := make([]string, len(t.Value))
+		for i, s := range t.Value {
+			out[i] = strings.ReplaceAll(s, from, to)
+		}
+		return &types.AttributeValueMemberSS{Value: out}
+	case *types.AttributeValueMemberL:
+		out := make([]types.AttributeValue, len(t.Value))
+		for i, e := range t.Value {
+			out[i] = replaceSessionID(e, from, to)
+		}
+		return &types.AttributeValueMemberL{Value: out}
+	case *types.AttributeValueMemberM:
+		out := make(map[string]types.AttributeValue, len(t.Value))
+		for k, e := range t.Value {
+			out[k] = replaceSessionID(e, from, to)
+		}
+		return &types.AttributeValueMemberM{Value: out}
+	default:
+		return v
+	}
+}
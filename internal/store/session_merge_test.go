@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestMatchSessions(t *testing.T) {
+	trip := NewSessionSignature([]FileResult{
+		{Filename: "IMG_0001.HEIC", Fingerprint: "fp1", Metadata: map[string]string{"date": "2026-07-01T10:00:00Z"}},
+		{Filename: "IMG_0002.HEIC", Fingerprint: "fp2", Metadata: map[string]string{"date": "2026-07-03T18:00:00Z"}},
+		{Filename: "IMG_0003.HEIC", Fingerprint: "fp3"},
+	})
+
+	// Part of the same trip uploaded again, plus one new photo.
+	again := NewSessionSignature([]FileResult{
+		{Filename: "img_0002.heic", Fingerprint: "fp2", Metadata: map[string]string{"date": "2026-07-03T18:00:00Z"}},
+		{Filename: "IMG_0003.HEIC", Fingerprint: "fp3"},
+		{Filename: "IMG_0004.HEIC", Fingerprint: "fp4", Metadata: map[string]string{"date": "2026-07-02T12:00:00Z"}},
+	})
+	if got := MatchSessions(trip, again); len(got) != 3 {
+		t.Errorf("re-upload reasons = %v, want filenames, files, and dates", got)
+	}
+
+	// A later trip shares a camera-counter filename but nothing else.
+	later := NewSessionSignature([]FileResult{
+		{Filename: "IMG_0001.HEIC", Fingerprint: "fpX", Metadata: map[string]string{"date": "2026-09-01T10:00:00Z"}},
+		{Filename: "IMG_0100.HEIC", Fingerprint: "fpY", Metadata: map[string]string{"date": "2026-09-02T10:00:00Z"}},
+		{Filename: "IMG_0101.HEIC", Fingerprint: "fpZ"},
+	})
+	if got := MatchSessions(trip, later); len(got) != 0 {
+		t.Errorf("different trip matched: %v", got)
+	}
+}
+
+func TestReplaceSessionID(t *testing.T) {
+	from, to := "11111111-aaaa", "22222222-bbbb"
+	item := &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"key":   &types.AttributeValueMemberS{Value: from + "/IMG_1.jpg"},
+		"keys":  &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "/api/media/thumbnail?key=" + from + "/thumbnails/IMG_1.jpg"}}},
+		"count": &types.AttributeValueMemberN{Value: "3"},
+	}}
+
+	got := replaceSessionID(item, from, to).(*types.AttributeValueMemberM).Value
+	if s := got["key"].(*types.AttributeValueMemberS).Value; s != to+"/IMG_1.jpg" {
+		t.Errorf("key = %q", s)
+	}
+	if s := got["keys"].(*types.AttributeValueMemberL).Value[0].(*types.AttributeValueMemberS).Value; s != "/api/media/thumbnail?key="+to+"/thumbnails/IMG_1.jpg" {
+		t.Errorf("nested key = %q", s)
+	}
+	if n := got["count"].(*types.AttributeValueMemberN).Value; n != "3" {
+		t.Errorf("number changed to %q", n)
+	}
+}
+
+func TestMergeSessionMovesFileProcessingRows(t *testing.T) {
+	sessions, files := newFakeDynamo(), newFakeDynamo()
+	s := newFakeDynamoStore(t, sessions)
+	s.SetFileProcessingStore(NewFileProcessingStore(newFakeDynamoClient(t, files), "test-file-processing"))
+	ctx := context.Background()
+
+	for _, id := range []string{"src-session", "dst-session"} {
+		if err := s.PutSession(ctx, &Session{ID: id, Status: "active"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.PutTriageJob(ctx, "src-session", &TriageJob{ID: "triage-1", Status: "complete"}); err != nil {
+		t.Fatal(err)
+	}
+	thumb := func(key string) fakeItem {
+		return fakeItem{"thumbnailKey": json.RawMessage(`{"S":"` + key + `"}`)}
+	}
+	files.put("src-session", "file#a.jpg", fakeItem{"status": json.RawMessage(`{"S":"done"}`)})
+	files.put("src-session", "thumb#a.jpg", thumb("src-session/thumbnails/a.jpg"))
+	files.put("src-session", "thumb#b.jpg", thumb("src-session/thumbnails/b.jpg"))
+	files.put("src-session#triage-1", "a.jpg", fakeItem{"sessionId": json.RawMessage(`{"S":"src-session"}`)})
+	files.put("dst-session", "thumb#b.jpg", thumb("dst-session/thumbnails/b-target.jpg"))
+
+	if _, err := s.MergeSession(ctx, "dst-session", "src-session"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "dst-session#triage-1|a.jpg dst-session|file#a.jpg dst-session|thumb#a.jpg dst-session|thumb#b.jpg"
+	if got := strings.Join(files.keys(), " "); got != want {
+		t.Errorf("file-processing rows = %s, want %s", got, want)
+	}
+	if got := files.item("dst-session", "thumb#a.jpg").S("thumbnailKey"); got != "dst-session/thumbnails/a.jpg" {
+		t.Errorf("moved thumbnail key = %q, want it under the target prefix", got)
+	}
+	if got := files.item("dst-session", "thumb#b.jpg").S("thumbnailKey"); got != "dst-session/thumbnails/b-target.jpg" {
+		t.Errorf("target's own thumbnail = %q, want it kept", got)
+	}
+	if got := files.item("dst-session#triage-1", "a.jpg").S("sessionId"); got != "dst-session" {
+		t.Errorf("moved file result sessionId = %q", got)
+	}
+}
@@ -0,0 +1,474 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Session listing and deletion (DDR-098) ---
+
+const (
+	userPKPrefix = "USER#"
+	skSessionRef = "SESSION#"
+)
+
+// userPK returns the partition key of a user's session index.
+func userPK(ownerSub string) string {
+	return userPKPrefix + ownerSub
+}
+
+// jobSKToStep maps job sort key prefixes to the step names reported in
+// SessionRecord.Jobs. GROUP# and DISPATCH# items are not jobs.
+var jobSKToStep = map[string]string{
+	skTriage:    "triage",
+	skSelection: "selection",
+	skEnhance:   "enhancement",
+	skDownload:  "download",
+	skDesc:      "description",
+	skFBPrep:    "fb-prep",
+	skPublish:   "publish",
+	skMood:      "mood-variants",
+	skCrop:      "crop-suggestions",
+}
+
+// sessionRef is the owner's index row for a session. It carries the
+// session's summary so ListSessions reads every session with one Query
+// instead of describing each one.
+type sessionRef struct {
+	CreatedAt   int64  `dynamodbav:"createdAt"`
+	Status      string `dynamodbav:"status,omitempty"`
+	TripContext string `dynamodbav:"tripContext,omitempty"`
+	KMSKeyID    string `dynamodbav:"kmsKeyId,omitempty"`
+	FileCount   int    `dynamodbav:"fileCount,omitempty"`
+	GroupCount  int    `dynamodbav:"groupCount,omitempty"`
+	// JobsBySK holds one entry per job, keyed by the job's SK, so a job
+	// write updates its own entry without rewriting the row.
+	JobsBySK map[string]sessionRefJob `dynamodbav:"jobsBySk,omitempty"`
+	// Jobs is the list written before entries were keyed by SK.
+	Jobs []sessionRefJob `dynamodbav:"jobs,omitempty"`
+	// Summarized is false on rows written before the summary was stored.
+	Summarized bool `dynamodbav:"summarized,omitempty"`
+}
+
+// sessionRefJob is one entry of sessionRef.JobsBySK. Unlike JobSummary it
+// stores the job's ID and step, which the job's own item keeps in its SK.
+type sessionRefJob struct {
+	ID         string `dynamodbav:"id"`
+	Type       string `dynamodbav:"type"`
+	Status     string `dynamodbav:"status"`
+	Error      string `dynamodbav:"error,omitempty"`
+	RetryCount int    `dynamodbav:"retryCount,omitempty"`
+}
+
+func newSessionRef(rec *SessionRecord) *sessionRef {
+	ref := &sessionRef{
+		CreatedAt:   rec.CreatedAt,
+		Status:      rec.Status,
+		TripContext: rec.TripContext,
+		KMSKeyID:    rec.KMSKeyID,
+		FileCount:   rec.FileCount,
+		GroupCount:  rec.GroupCount,
+		JobsBySK:    make(map[string]sessionRefJob, len(rec.Jobs)),
+		Summarized:  true,
+	}
+	for _, job := range rec.Jobs {
+		ref.JobsBySK[stepSKPrefix(job.Type)+job.ID] = sessionRefJob{ID: job.ID, Type: job.Type, Status: job.Status, Error: job.Error, RetryCount: job.RetryCount}
+	}
+	return ref
+}
+
+// isSummarizedJobSK reports whether the job at sk is listed in
+// SessionRecord.Jobs.
+func isSummarizedJobSK(sk string) bool {
+	for prefix := range jobSKToStep {
+		if strings.HasPrefix(sk, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// stepSKPrefix is the inverse of jobSKToStep.
+func stepSKPrefix(step string) string {
+	for prefix, s := range jobSKToStep {
+		if s == step {
+			return prefix
+		}
+	}
+	return ""
+}
+
+func (ref *sessionRef) record(sessionID, ownerSub string) SessionRecord {
+	rec := SessionRecord{
+		ID:          sessionID,
+		OwnerSub:    ownerSub,
+		CreatedAt:   ref.CreatedAt,
+		Status:      ref.Status,
+		TripContext: ref.TripContext,
+		Encrypted:   ref.KMSKeyID != "",
+		KMSKeyID:    ref.KMSKeyID,
+		FileCount:   ref.FileCount,
+		GroupCount:  ref.GroupCount,
+		Jobs:        make([]JobSummary, 0, len(ref.JobsBySK)+len(ref.Jobs)),
+	}
+	jobs := ref.Jobs
+	if ref.JobsBySK != nil {
+		// Listed in SK order, as DescribeSession reads them.
+		sks := make([]string, 0, len(ref.JobsBySK))
+		for sk := range ref.JobsBySK {
+			sks = append(sks, sk)
+		}
+		sort.Strings(sks)
+		jobs = make([]sessionRefJob, len(sks))
+		for i, sk := range sks {
+			jobs[i] = ref.JobsBySK[sk]
+		}
+	}
+	for _, job := range jobs {
+		rec.Jobs = append(rec.Jobs, JobSummary{ID: job.ID, Type: job.Type, Status: job.Status, Error: job.Error, RetryCount: job.RetryCount})
+	}
+	return rec
+}
+
+// refreshSessionRef rewrites the owner's index row with the session's
+// current summary. The row takes META's TTL, so it expires with the session
+// rather than outliving it.
+func (s *DynamoStore) refreshSessionRef(ctx context.Context, sessionID string) error {
+	rec, err := s.DescribeSession(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("refresh session index %s: %w", sessionID, err)
+	}
+	if rec == nil || rec.OwnerSub == "" {
+		return nil
+	}
+
+	item, err := marshalItem(userPK(rec.OwnerSub), skSessionRef+sessionID, newSessionRef(rec))
+	if err != nil {
+		return fmt.Errorf("refresh session index %s: %w", sessionID, err)
+	}
+	if rec.expiresAt > 0 {
+		item["expiresAt"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(rec.expiresAt, 10)}
+	}
+	if _, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{TableName: &s.tableName, Item: item}); err != nil {
+		return fmt.Errorf("put session index %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// touchSessionRef refreshes the index row after a write that changed the
+// session's summary. Best effort: the write itself succeeded, and a stale
+// row is corrected by the session's next write.
+func (s *DynamoStore) touchSessionRef(ctx context.Context, sessionID string) {
+	if err := s.refreshSessionRef(ctx, sessionID); err != nil {
+		log.Warn().Err(err).Str("sessionId", sessionID).Msg("Failed to refresh session index row")
+	}
+}
+
+// sessionJobUpdate is the part of a job write that shows in the session's
+// index row.
+type sessionJobUpdate struct {
+	Status string
+	// Error, when set, replaces the entry's error.
+	Error *string
+	// RetryCount, when positive, replaces the entry's retry count.
+	RetryCount int
+	// FileCount is a triage job's file count, which the row takes only when
+	// it has none yet (see DescribeSession).
+	FileCount int
+}
+
+// touchSessionJob updates one job's entry in the owner's index row after a
+// write to that job. A job write thus costs one UpdateItem however large the
+// session is, and writers of different jobs do not overwrite each other's
+// entries. When the row has no entry for the job yet — its first write, or a
+// row written before entries were keyed — the row is rebuilt with
+// refreshSessionRef instead. Best effort, like touchSessionRef.
+func (s *DynamoStore) touchSessionJob(ctx context.Context, sessionID, jobSK string, update sessionJobUpdate) {
+	if !isSummarizedJobSK(jobSK) {
+		return
+	}
+	ownerSub, err := s.sessionOwner(ctx, sessionID)
+	if err != nil {
+		log.Warn().Err(err).Str("sessionId", sessionID).Msg("Failed to look up session owner for index row")
+		return
+	}
+	if ownerSub == "" {
+		return
+	}
+
+	set := []string{"#jobs.#sk.#st = :status"}
+	names := map[string]string{"#jobs": "jobsBySk", "#sk": jobSK, "#st": "status"}
+	values := map[string]types.AttributeValue{
+		":status": &types.AttributeValueMemberS{Value: update.Status},
+	}
+	if update.Error != nil {
+		set = append(set, "#jobs.#sk.#err = :err")
+		names["#err"] = "error"
+		values[":err"] = &types.AttributeValueMemberS{Value: *update.Error}
+	}
+	if update.RetryCount > 0 {
+		set = append(set, "#jobs.#sk.retryCount = :rc")
+		values[":rc"] = &types.AttributeValueMemberN{Value: strconv.Itoa(update.RetryCount)}
+	}
+	if update.FileCount > 0 {
+		set = append(set, "fileCount = if_not_exists(fileCount, :files)")
+		values[":files"] = &types.AttributeValueMemberN{Value: strconv.Itoa(update.FileCount)}
+	}
+
+	_, err = s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: userPK(ownerSub)},
+			"SK": &types.AttributeValueMemberS{Value: skSessionRef + sessionID},
+		},
+		UpdateExpression:          aws.String("SET " + strings.Join(set, ", ")),
+		ConditionExpression:       aws.String("attribute_exists(#jobs.#sk)"),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	})
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		s.touchSessionRef(ctx, sessionID)
+		return
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("sessionId", sessionID).Str("sk", jobSK).Msg("Failed to update job in session index row")
+	}
+}
+
+// sessionOwner returns the session's owner, reading META only the first
+// time a session is seen: a session's owner does not change. A session
+// without META is cached as ownerless until PutSession writes it.
+func (s *DynamoStore) sessionOwner(ctx context.Context, sessionID string) (string, error) {
+	if owner, ok := s.owners.Load(sessionID); ok {
+		return owner.(string), nil
+	}
+	session, err := s.GetSession(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
+	owner := ""
+	if session != nil {
+		owner = session.OwnerSub
+	}
+	s.owners.Store(sessionID, owner)
+	return owner, nil
+}
+
+func (s *DynamoStore) ListSessions(ctx context.Context, ownerSub string) ([]SessionRecord, error) {
+	records, err := s.querySessionRefs(ctx, ownerSub)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].CreatedAt > records[j].CreatedAt
+	})
+
+	log.Debug().Int("count", len(records)).Msg("ListSessions: sessions listed")
+	return records, nil
+}
+
+func (s *DynamoStore) DescribeSession(ctx context.Context, sessionID string) (*SessionRecord, error) {
+	items, err := s.querySession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("describe session %s: %w", sessionID, err)
+	}
+
+	rec := &SessionRecord{ID: sessionID, Jobs: []JobSummary{}}
+	found := false
+	triageFiles := 0
+	for _, item := range items {
+		skAttr, ok := item["SK"].(*types.AttributeValueMemberS)
+		if !ok {
+			continue
+		}
+		sk := skAttr.Value
+
+		switch {
+		case isDescRoundSK(sk):
+			// Description feedback rounds (DDR-152) belong to their job.
+		case sk == skMeta:
+			var meta Session
+			if err := attributevalue.UnmarshalMap(item, &meta); err != nil {
+				return nil, fmt.Errorf("unmarshal session %s: %w", sessionID, err)
+			}
+			found = true
+			rec.OwnerSub = meta.OwnerSub
+			rec.CreatedAt = meta.CreatedAt
+			rec.Status = meta.Status
+			rec.TripContext = meta.TripContext
+			rec.KMSKeyID = meta.KMSKeyID
+			rec.Encrypted = meta.KMSKeyID != ""
+			rec.FileCount = len(meta.UploadedKeys)
+			rec.expiresAt = int64(extractIntAttr(item, "expiresAt"))
+		case strings.HasPrefix(sk, skGroup):
+			rec.GroupCount++
+		default:
+			for prefix, step := range jobSKToStep {
+				if !strings.HasPrefix(sk, prefix) {
+					continue
+				}
+				var job JobSummary
+				if err := attributevalue.UnmarshalMap(item, &job); err != nil {
+					return nil, fmt.Errorf("unmarshal job %s/%s: %w", sessionID, sk, err)
+				}
+				job.ID = strings.TrimPrefix(sk, prefix)
+				job.Type = step
+				rec.Jobs = append(rec.Jobs, job)
+
+				if prefix == skTriage {
+					var triage TriageJob
+					if err := attributevalue.UnmarshalMap(item, &triage); err == nil && triage.TotalFiles > triageFiles {
+						triageFiles = triage.TotalFiles
+					}
+				}
+				break
+			}
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+	// Sessions created through triage record their files on the triage job
+	// rather than META.
+	if rec.FileCount == 0 {
+		rec.FileCount = triageFiles
+	}
+	return rec, nil
+}
+
+// SetFileProcessingStore makes DeleteSession also delete the session's rows
+// in the file-processing table (DDR-061): per-file results, fingerprint
+// mappings, and the thumbnail index (DDR-091).
+func (s *DynamoStore) SetFileProcessingStore(fp *FileProcessingStore) {
+	s.fileProcessing = fp
+}
+
+func (s *DynamoStore) DeleteSession(ctx context.Context, sessionID string) (int, error) {
+	session, err := s.GetSession(ctx, sessionID)
+	if err != nil {
+		return 0, err
+	}
+
+	s.owners.Delete(sessionID)
+	items, err := s.querySession(ctx, sessionID)
+	if err != nil {
+		return 0, fmt.Errorf("delete session %s: %w", sessionID, err)
+	}
+	keys := make([]map[string]types.AttributeValue, 0, len(items)+1)
+	var triageJobIDs []string
+	for _, item := range items {
+		keys = append(keys, map[string]types.AttributeValue{"PK": item["PK"], "SK": item["SK"]})
+		if sk, ok := item["SK"].(*types.AttributeValueMemberS); ok && strings.HasPrefix(sk.Value, skTriage) {
+			triageJobIDs = append(triageJobIDs, strings.TrimPrefix(sk.Value, skTriage))
+		}
+	}
+	if session != nil && session.OwnerSub != "" {
+		keys = append(keys, map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: userPK(session.OwnerSub)},
+			"SK": &types.AttributeValueMemberS{Value: skSessionRef + sessionID},
+		})
+	}
+
+	// File-processing rows go first: once the session's own items are
+	// deleted, the triage job IDs that key them are gone too.
+	fileRows := 0
+	if s.fileProcessing != nil {
+		if fileRows, err = s.fileProcessing.DeleteSessionRecords(ctx, sessionID, triageJobIDs); err != nil {
+			return 0, fmt.Errorf("delete session %s: %w", sessionID, err)
+		}
+	}
+	if len(keys) == 0 {
+		return fileRows, nil
+	}
+
+	if err := s.batchDeleteKeys(ctx, keys); err != nil {
+		return fileRows, fmt.Errorf("delete session %s: %w", sessionID, err)
+	}
+
+	log.Info().Str("sessionId", sessionID).Int("deleted", len(keys)).Int("fileRows", fileRows).Msg("Session deleted from DynamoDB")
+	return len(keys) + fileRows, nil
+}
+
+// querySessionRefs reads the owner's index rows. Rows written before the
+// summary was stored are described from the session's own items instead.
+func (s *DynamoStore) querySessionRefs(ctx context.Context, ownerSub string) ([]SessionRecord, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              &s.tableName,
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: userPK(ownerSub)},
+			":sk": &types.AttributeValueMemberS{Value: skSessionRef},
+		},
+	}
+
+	records := []SessionRecord{}
+	for {
+		result, err := s.client.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("query sessions for owner: %w", err)
+		}
+		for _, item := range result.Items {
+			sk, ok := item["SK"].(*types.AttributeValueMemberS)
+			if !ok {
+				continue
+			}
+			var ref sessionRef
+			if err := attributevalue.UnmarshalMap(item, &ref); err != nil {
+				return nil, fmt.Errorf("unmarshal session index %s: %w", sk.Value, err)
+			}
+			sessionID := strings.TrimPrefix(sk.Value, skSessionRef)
+			if ref.Summarized {
+				records = append(records, ref.record(sessionID, ownerSub))
+				continue
+			}
+			rec, err := s.DescribeSession(ctx, sessionID)
+			if err != nil {
+				return nil, err
+			}
+			if rec != nil {
+				records = append(records, *rec)
+			}
+		}
+		if result.LastEvaluatedKey == nil {
+			return records, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// querySession returns every item in a session's partition. queryBySKPrefix
+// cannot be used with an empty prefix: DynamoDB rejects empty key values.
+func (s *DynamoStore) querySession(ctx context.Context, sessionID string) ([]map[string]types.AttributeValue, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              &s.tableName,
+		KeyConditionExpression: aws.String("PK = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: sessionPK(sessionID)},
+		},
+	}
+
+	var allItems []map[string]types.AttributeValue
+	for {
+		result, err := s.client.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("Query PK=%s: %w", sessionPK(sessionID), err)
+		}
+		allItems = append(allItems, result.Items...)
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+	return allItems, nil
+}
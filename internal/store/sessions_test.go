@@ -0,0 +1,221 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// setSessionStatus applies UpdateSessionStatus's "SET #s = :s" to the stored
+// item, and touchSessionJob's update to the index row's job entries.
+func setSessionStatus(f *fakeDynamo, req *fakeRequest) (any, error) {
+	if _, ok := req.ExpressionAttributeNames["#jobs"]; ok {
+		return setSessionJob(f, req)
+	}
+	f.items[req.Key.key()]["status"] = req.ExpressionAttributeValues[":s"]
+	return struct{}{}, nil
+}
+
+// setSessionJob applies touchSessionJob's update of one entry of an index
+// row's jobsBySk, failing its condition when the row has no such entry.
+func setSessionJob(f *fakeDynamo, req *fakeRequest) (any, error) {
+	row, ok := f.items[req.Key.key()]
+	if !ok {
+		return nil, errFakeConditionFailed
+	}
+	var jobs struct {
+		M map[string]struct{ M fakeItem }
+	}
+	json.Unmarshal(row["jobsBySk"], &jobs)
+	sk := req.ExpressionAttributeNames["#sk"]
+	entry, ok := jobs.M[sk]
+	if !ok {
+		return nil, errFakeConditionFailed
+	}
+	for name, value := range map[string]string{"status": ":status", "error": ":err", "retryCount": ":rc"} {
+		if v, ok := req.ExpressionAttributeValues[value]; ok {
+			entry.M[name] = v
+		}
+	}
+	jobs.M[sk] = entry
+	row["jobsBySk"], _ = json.Marshal(jobs)
+	if v, ok := req.ExpressionAttributeValues[":files"]; ok && row["fileCount"] == nil {
+		row["fileCount"] = v
+	}
+	return struct{}{}, nil
+}
+
+func TestListSessionsReadsSummariesInOneQuery(t *testing.T) {
+	table := newFakeDynamo()
+	table.on["UpdateItem"] = setSessionStatus
+	s := newFakeDynamoStore(t, table)
+	ctx := context.Background()
+
+	for _, sess := range []*Session{
+		{ID: "s1", OwnerSub: "user-1", Status: "active", CreatedAt: 100, TripContext: "Kyoto"},
+		{ID: "s2", OwnerSub: "user-1", Status: "active", CreatedAt: 200, UploadedKeys: []string{"s2/a.jpg", "s2/b.jpg"}},
+		{ID: "s3", OwnerSub: "user-2", Status: "active", CreatedAt: 300},
+	} {
+		if err := s.PutSession(ctx, sess); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.PutTriageJob(ctx, "s1", &TriageJob{ID: "triage-1", Status: "complete", TotalFiles: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PutPostGroup(ctx, "s2", &PostGroup{ID: "grp-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateSessionStatus(ctx, "s2", "publishing"); err != nil {
+		t.Fatal(err)
+	}
+
+	table.mu.Lock()
+	table.calls = map[string]int{}
+	table.mu.Unlock()
+	records, err := s.ListSessions(ctx, "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.calls["Query"] != 1 || len(table.calls) != 1 {
+		t.Errorf("calls = %v, want a single Query", table.calls)
+	}
+
+	if len(records) != 2 || records[0].ID != "s2" || records[1].ID != "s1" {
+		t.Fatalf("records = %+v, want s2 then s1", records)
+	}
+	s2, s1 := records[0], records[1]
+	if s2.Status != "publishing" || s2.FileCount != 2 || s2.GroupCount != 1 || len(s2.Jobs) != 0 {
+		t.Errorf("s2 = %+v", s2)
+	}
+	if s1.TripContext != "Kyoto" || s1.FileCount != 3 || s1.OwnerSub != "user-1" {
+		t.Errorf("s1 = %+v", s1)
+	}
+	if len(s1.Jobs) != 1 || s1.Jobs[0] != (JobSummary{ID: "triage-1", Type: "triage", Status: "complete"}) {
+		t.Errorf("s1 jobs = %+v", s1.Jobs)
+	}
+}
+
+func TestJobWriteUpdatesOnlyItsIndexEntry(t *testing.T) {
+	table := newFakeDynamo()
+	table.on["UpdateItem"] = setSessionStatus
+	s := newFakeDynamoStore(t, table)
+	ctx := context.Background()
+
+	if err := s.PutSession(ctx, &Session{ID: "s1", OwnerSub: "user-1", Status: "active", CreatedAt: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PutTriageJob(ctx, "s1", &TriageJob{ID: "triage-1", Status: "complete", TotalFiles: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PutSelectionJob(ctx, "s1", &SelectionJob{ID: "sel-1", Status: "processing"}); err != nil {
+		t.Fatal(err)
+	}
+
+	table.mu.Lock()
+	table.calls = map[string]int{}
+	table.mu.Unlock()
+	if err := s.PutSelectionJob(ctx, "s1", &SelectionJob{ID: "sel-1", Status: "error", Error: "timed out"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateJobStatus(ctx, "s1", "triage-1", "processing", ""); err != nil {
+		t.Fatal(err)
+	}
+	if table.calls["Query"] != 0 || table.calls["PutItem"] != 1 || table.calls["UpdateItem"] != 3 {
+		t.Errorf("calls = %v, want the job writes plus one UpdateItem each on the index row", table.calls)
+	}
+
+	records, err := s.ListSessions(ctx, "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].FileCount != 3 {
+		t.Fatalf("records = %+v, want s1 with 3 files", records)
+	}
+	want := []JobSummary{
+		{ID: "sel-1", Type: "selection", Status: "error", Error: "timed out"},
+		{ID: "triage-1", Type: "triage", Status: "processing"},
+	}
+	if got := records[0].Jobs; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("jobs = %+v, want %+v", got, want)
+	}
+}
+
+func TestListSessionsDescribesLegacyIndexRows(t *testing.T) {
+	table := newFakeDynamo()
+	s := newFakeDynamoStore(t, table)
+	// Rows written before the summary was stored carry only createdAt.
+	table.put(sessionPK("s1"), skMeta, fakeItem{"ownerSub": json.RawMessage(`{"S":"user-1"}`), "status": json.RawMessage(`{"S":"active"}`), "createdAt": json.RawMessage(`{"N":"100"}`)})
+	table.put(userPK("user-1"), skSessionRef+"s1", fakeItem{"createdAt": json.RawMessage(`{"N":"100"}`)})
+	table.put(userPK("user-1"), skSessionRef+"gone", fakeItem{"createdAt": json.RawMessage(`{"N":"50"}`)})
+
+	records, err := s.ListSessions(context.Background(), "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].ID != "s1" || records[0].Status != "active" {
+		t.Errorf("records = %+v, want s1 described from its items and the expired session skipped", records)
+	}
+}
+
+func TestSessionIndexRowTakesMetaTTL(t *testing.T) {
+	table := newFakeDynamo()
+	s := newFakeDynamoStore(t, table)
+	table.put(sessionPK("s1"), skMeta, fakeItem{
+		"ownerSub":  json.RawMessage(`{"S":"user-1"}`),
+		"status":    json.RawMessage(`{"S":"active"}`),
+		"expiresAt": json.RawMessage(`{"N":"1234"}`),
+	})
+	table.on["UpdateItem"] = setSessionStatus
+
+	if err := s.UpdateSessionStatus(context.Background(), "s1", "done"); err != nil {
+		t.Fatal(err)
+	}
+	ref := table.item(userPK("user-1"), skSessionRef+"s1")
+	if ref == nil {
+		t.Fatal("index row not written")
+	}
+	if ref.N("expiresAt") != "1234" || ref.S("status") != "done" {
+		t.Errorf("index row expiresAt = %s, status = %q; want META's 1234 and done", ref.N("expiresAt"), ref.S("status"))
+	}
+}
+
+func TestDeleteSessionCascadesFileProcessingRows(t *testing.T) {
+	sessions, files := newFakeDynamo(), newFakeDynamo()
+	s := newFakeDynamoStore(t, sessions)
+	s.SetFileProcessingStore(NewFileProcessingStore(newFakeDynamoClient(t, files), "test-file-processing"))
+	ctx := context.Background()
+
+	if err := s.PutSession(ctx, &Session{ID: "s1", OwnerSub: "user-1", Status: "active"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PutTriageJob(ctx, "s1", &TriageJob{ID: "triage-1", Status: "complete"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range [][2]string{
+		{"s1", "file#a.jpg"},
+		{"s1", "thumb#a.jpg"},
+		{"s1#triage-1", "a.jpg"},
+		{"s1#triage-1", "fp#abc"},
+		{"s2", "thumb#a.jpg"},
+		{"s2#triage-9", "a.jpg"},
+	} {
+		files.put(key[0], key[1], nil)
+	}
+
+	deleted, err := s.DeleteSession(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// META, the triage job, and the index row, plus four file-processing rows.
+	if deleted != 7 {
+		t.Errorf("deleted = %d, want 7", deleted)
+	}
+	if got := sessions.keys(); len(got) != 0 {
+		t.Errorf("session table keeps %v", got)
+	}
+	if got := strings.Join(files.keys(), " "); got != "s2#triage-9|a.jpg s2|thumb#a.jpg" {
+		t.Errorf("file-processing table keeps %s, want only s2's rows", got)
+	}
+}
@@ -15,6 +15,8 @@ package store
 import (
 	"context"
 	"time"
+
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
 )
 
 // SessionTTL is the default time-to-live for all DynamoDB records.
@@ -41,10 +43,35 @@ type SessionStore interface {
 	// GetSession retrieves session metadata by ID. Returns nil, nil if not found.
 	GetSession(ctx context.Context, sessionID string) (*Session, error)
 
+	// SessionOwner returns the Cognito sub that owns a session, or "" if it
+	// has no owner or does not exist (DDR-105).
+	SessionOwner(ctx context.Context, sessionID string) (string, error)
+
 	// UpdateSessionStatus atomically updates the status field of a session
 	// without overwriting other fields. Uses DynamoDB UpdateItem.
 	UpdateSessionStatus(ctx context.Context, sessionID, status string) error
 
+	// UpdateSessionMetadataPolicy sets the session's metadata policy without
+	// overwriting other fields (DDR-135).
+	UpdateSessionMetadataPolicy(ctx context.Context, sessionID, policy string) error
+
+	// SetSessionKMSKey records the session's encryption key (DDR-164). It
+	// fails if the session already has one.
+	SetSessionKMSKey(ctx context.Context, sessionID, keyARN string) error
+
+	// ListSessions returns summaries of the sessions owned by ownerSub,
+	// newest first (DDR-098).
+	ListSessions(ctx context.Context, ownerSub string) ([]SessionRecord, error)
+
+	// DescribeSession summarizes a session's metadata and jobs.
+	// Returns nil, nil if the session does not exist (DDR-098).
+	DescribeSession(ctx context.Context, sessionID string) (*SessionRecord, error)
+
+	// DeleteSession deletes every DynamoDB item of a session, including META,
+	// the owner's index row, and its file-processing rows. Returns the number
+	// of items deleted (DDR-098).
+	DeleteSession(ctx context.Context, sessionID string) (int, error)
+
 	// --- Triage jobs (DDR-050) ---
 
 	// PutTriageJob creates or replaces a triage job record.
@@ -75,6 +102,10 @@ type SessionStore interface {
 	// GetSelectionJob retrieves a selection job. Returns nil, nil if not found.
 	GetSelectionJob(ctx context.Context, sessionID, jobID string) (*SelectionJob, error)
 
+	// SelectionChanges compares a completed selection job with the session's
+	// previous completed one. Returns nil, nil when there is none (DDR-158).
+	SelectionChanges(ctx context.Context, sessionID string, job *SelectionJob) (*SelectionDiff, error)
+
 	// --- Enhancement jobs ---
 
 	// PutEnhancementJob creates or replaces an enhancement job record.
@@ -98,6 +129,13 @@ type SessionStore interface {
 	// completedCount >= totalCount to prevent races.
 	UpdateEnhancementStatus(ctx context.Context, sessionID, jobID, status string) error
 
+	// PauseEnhancementJob sets a pending or processing job to "paused" (DDR-095).
+	PauseEnhancementJob(ctx context.Context, sessionID, jobID string) error
+
+	// ResumeEnhancementJob sets a paused job back to "processing" and returns
+	// the incremented resume count (DDR-095).
+	ResumeEnhancementJob(ctx context.Context, sessionID, jobID string) (int, error)
+
 	// --- Download jobs ---
 
 	// PutDownloadJob creates or replaces a download job record.
@@ -112,8 +150,16 @@ type SessionStore interface {
 	PutDescriptionJob(ctx context.Context, sessionID string, job *DescriptionJob) error
 
 	// GetDescriptionJob retrieves a description job. Returns nil, nil if not found.
+	// Feedback rounds are not loaded; see GetDescriptionHistory.
 	GetDescriptionJob(ctx context.Context, sessionID, jobID string) (*DescriptionJob, error)
 
+	// AppendDescriptionRound stores one feedback round of a description job
+	// and drops raw responses older than the last keepRaw rounds (DDR-152).
+	AppendDescriptionRound(ctx context.Context, sessionID, jobID string, round int, entry ConversationEntry, keepRaw int) error
+
+	// GetDescriptionHistory loads a description job's feedback rounds in order.
+	GetDescriptionHistory(ctx context.Context, sessionID, jobID string) ([]ConversationEntry, error)
+
 	// --- FB Prep jobs ---
 
 	// PutFBPrepJob creates or replaces an FB prep job record.
@@ -127,7 +173,8 @@ type SessionStore interface {
 	// PutPostGroup creates or replaces a post group record.
 	PutPostGroup(ctx context.Context, sessionID string, group *PostGroup) error
 
-	// GetPostGroups retrieves all post groups for a session.
+	// GetPostGroups retrieves all post groups for a session, ordered by
+	// Position (DDR-178).
 	GetPostGroups(ctx context.Context, sessionID string) ([]*PostGroup, error)
 
 	// DeletePostGroup deletes a single post group.
@@ -147,6 +194,28 @@ type SessionStore interface {
 	// Valid step names: "selection", "enhancement", "grouping", "download", "description", "publish".
 	// Returns the list of deleted sort key values for logging.
 	InvalidateDownstream(ctx context.Context, sessionID, fromStep string) ([]string, error)
+
+	// --- Job retry (DDR-089) ---
+
+	// GetJobSummary reads the status, error, and retry count of any job record,
+	// resolving the sort key from the job ID prefix. Returns nil, nil if not found.
+	GetJobSummary(ctx context.Context, sessionID, jobID string) (*JobSummary, error)
+
+	// IncrementJobRetryCount atomically increments retryCount, sets the job status,
+	// and clears the previous error. Returns the new retry count, or
+	// ErrRetryLimit when the job has already been retried maxRetries times
+	// (or its record no longer exists).
+	IncrementJobRetryCount(ctx context.Context, sessionID, jobID, status string, maxRetries int) (int, error)
+
+	// UpdateJobStatus atomically sets the status and error fields of any job record
+	// without overwriting results written by workers.
+	UpdateJobStatus(ctx context.Context, sessionID, jobID, status, errMsg string) error
+
+	// PutDispatchRecord stores the payload a job was dispatched with so it can be re-sent.
+	PutDispatchRecord(ctx context.Context, sessionID string, rec *DispatchRecord) error
+
+	// GetDispatchRecord retrieves a job's dispatch record. Returns nil, nil if not found.
+	GetDispatchRecord(ctx context.Context, sessionID, jobID string) (*DispatchRecord, error)
 }
 
 // --- Domain types ---
@@ -163,6 +232,32 @@ type Session struct {
 	TripContext  string   `json:"tripContext,omitempty" dynamodbav:"tripContext,omitempty"`
 	UploadedKeys []string `json:"uploadedKeys,omitempty" dynamodbav:"uploadedKeys,omitempty"`
 	CreatedAt    int64    `json:"createdAt" dynamodbav:"createdAt"`
+	// MetadataPolicy is the media.MetadataPolicy applied to downloads and
+	// Instagram uploads; empty means the default (DDR-135).
+	MetadataPolicy string `json:"metadataPolicy,omitempty" dynamodbav:"metadataPolicy,omitempty"`
+	// KMSKeyID is the ARN of the session's own KMS key when the user turned
+	// on encryption (DDR-164); empty means the bucket default.
+	KMSKeyID string `json:"kmsKeyId,omitempty" dynamodbav:"kmsKeyId,omitempty"`
+}
+
+// SessionRecord summarizes one session for the session listing API (DDR-098).
+//
+// DescribeSession computes it from the session's items. A copy is stored on
+// the owner's index row (PK = USER#{ownerSub}, SK = SESSION#{sessionId}),
+// rewritten after every write that changes it, which ListSessions reads.
+type SessionRecord struct {
+	ID          string       `json:"id" dynamodbav:"-"`
+	OwnerSub    string       `json:"-" dynamodbav:"-"`
+	CreatedAt   int64        `json:"createdAt" dynamodbav:"createdAt"`
+	Status      string       `json:"status" dynamodbav:"-"`
+	TripContext string       `json:"tripContext,omitempty" dynamodbav:"-"`
+	Encrypted   bool         `json:"encrypted,omitempty" dynamodbav:"-"` // DDR-164
+	KMSKeyID    string       `json:"-" dynamodbav:"-"`
+	FileCount   int          `json:"fileCount" dynamodbav:"-"`
+	GroupCount  int          `json:"groupCount" dynamodbav:"-"`
+	Jobs        []JobSummary `json:"jobs" dynamodbav:"-"`
+
+	expiresAt int64 // META's TTL, copied to the index row
 }
 
 // TriageJob represents AI triage results (DynamoDB SK = TRIAGE#{jobId}).
@@ -173,6 +268,13 @@ type TriageJob struct {
 	Status            string       `json:"status" dynamodbav:"status"`
 	Phase             string       `json:"phase,omitempty" dynamodbav:"phase,omitempty"`
 	Model             string       `json:"model,omitempty" dynamodbav:"model,omitempty"`
+	Thinking          string       `json:"thinking,omitempty" dynamodbav:"thinking,omitempty"`             // DDR-155
+	Temperature       *float32     `json:"temperature,omitempty" dynamodbav:"temperature,omitempty"`       // DDR-170
+	TopP              *float32     `json:"topP,omitempty" dynamodbav:"topP,omitempty"`                     // DDR-170
+	NoCache           bool         `json:"noCache,omitempty" dynamodbav:"noCache,omitempty"`               // DDR-166
+	Keyframes         int          `json:"keyframes,omitempty" dynamodbav:"keyframes,omitempty"`           // DDR-186: frames per video, 0 = whole clips
+	DebugArtifacts    bool         `json:"debugArtifacts,omitempty" dynamodbav:"debugArtifacts,omitempty"` // DDR-106
+	ModelsUsed        []string     `json:"modelsUsed,omitempty" dynamodbav:"modelsUsed,omitempty"`         // DDR-169: models that answered
 	TotalFiles        int          `json:"totalFiles,omitempty" dynamodbav:"totalFiles,omitempty"`
 	UploadedFiles     int          `json:"uploadedFiles,omitempty" dynamodbav:"uploadedFiles,omitempty"`
 	ExpectedFileCount int          `json:"expectedFileCount,omitempty" dynamodbav:"expectedFileCount,omitempty"`
@@ -183,6 +285,56 @@ type TriageJob struct {
 	RetryCount        int          `json:"retryCount,omitempty" dynamodbav:"retryCount,omitempty"`
 	TriageBatch       int          `json:"triageBatch,omitempty" dynamodbav:"triageBatch,omitempty"`
 	TriageBatchTotal  int          `json:"triageBatchTotal,omitempty" dynamodbav:"triageBatchTotal,omitempty"`
+	// FileProgress is each file's stage while the job is analyzing (DDR-174).
+	FileProgress []TriageFileProgress `json:"fileProgress,omitempty" dynamodbav:"fileProgress,omitempty"`
+	// OverridesRecordedAt is set once the user's keep/discard overrides have
+	// been sent to the RAG decision tables (DDR-100).
+	OverridesRecordedAt int64 `json:"overridesRecordedAt,omitempty" dynamodbav:"overridesRecordedAt,omitempty"`
+	// DuplicateSessions lists the owner's other sessions that probably hold
+	// the same trip, found when triage ran (DDR-113).
+	DuplicateSessions []DuplicateSession `json:"duplicateSessions,omitempty" dynamodbav:"duplicateSessions,omitempty"`
+	// Telemetry is the job's Gemini, S3 and ffmpeg usage so far (DDR-118).
+	Telemetry *metrics.JobTelemetry `json:"perJobTelemetry,omitempty" dynamodbav:"perJobTelemetry,omitempty"`
+	// VideoStats compares how the job's videos were judged and what was
+	// sent for them, recorded when triage completes (DDR-186).
+	VideoStats *TriageVideoStats `json:"videoStats,omitempty" dynamodbav:"videoStats,omitempty"`
+}
+
+// TriageVideoStats counts a completed triage job's videos by how Gemini saw
+// them, with the bytes uploaded and the bytes of frame sheets sent instead
+// (DDR-186).
+type TriageVideoStats struct {
+	Videos      int   `json:"videos" dynamodbav:"videos"`
+	Keyframed   int   `json:"keyframed,omitempty" dynamodbav:"keyframed,omitempty"`
+	Sampled     int   `json:"sampled,omitempty" dynamodbav:"sampled,omitempty"`
+	Kept        int   `json:"kept" dynamodbav:"kept"`
+	SourceBytes int64 `json:"sourceBytes" dynamodbav:"sourceBytes"`
+	SheetBytes  int64 `json:"sheetBytes,omitempty" dynamodbav:"sheetBytes,omitempty"`
+}
+
+// Triage file stages, in order (DDR-174).
+const (
+	TriageFileQueued     = "queued"     // waiting for its triage batch
+	TriageFileDownloaded = "downloaded" // fetched and being evaluated by Gemini
+	TriageFileAnalyzed   = "analyzed"   // verdict known
+)
+
+// TriageFileProgress is one file's stage in a running triage job.
+type TriageFileProgress struct {
+	Filename string `json:"filename" dynamodbav:"filename"`
+	Status   string `json:"status" dynamodbav:"status"`
+}
+
+// AnalyzedFiles returns the number of files in FileProgress whose verdict
+// is known.
+func (j *TriageJob) AnalyzedFiles() int {
+	n := 0
+	for _, f := range j.FileProgress {
+		if f.Status == TriageFileAnalyzed {
+			n++
+		}
+	}
+	return n
 }
 
 // TriageItem represents a single media item in triage results.
@@ -194,17 +346,72 @@ type TriageItem struct {
 	Saveable     bool   `json:"saveable" dynamodbav:"saveable"`
 	Reason       string `json:"reason" dynamodbav:"reason"`
 	ThumbnailURL string `json:"thumbnailUrl" dynamodbav:"thumbnailUrl"`
+	PreFiltered  bool   `json:"preFiltered,omitempty" dynamodbav:"preFiltered,omitempty"` // discarded by the local quality check (DDR-112)
+	Sampled      bool   `json:"sampled,omitempty" dynamodbav:"sampled,omitempty"`         // long video judged from sampled frames (DDR-162)
 }
 
 // SelectionJob represents AI selection results (DynamoDB SK = SELECTION#{jobId}).
 type SelectionJob struct {
-	ID          string         `json:"id" dynamodbav:"-"`
-	SessionID   string         `json:"-" dynamodbav:"-"`
-	Status      string         `json:"status" dynamodbav:"status"`
-	Selected    []SelectedItem `json:"selected,omitempty" dynamodbav:"selected,omitempty"`
-	Excluded    []ExcludedItem `json:"excluded,omitempty" dynamodbav:"excluded,omitempty"`
-	SceneGroups []SceneGroup   `json:"sceneGroups,omitempty" dynamodbav:"sceneGroups,omitempty"`
-	Error       string         `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	ID          string                `json:"id" dynamodbav:"-"`
+	SessionID   string                `json:"-" dynamodbav:"-"`
+	Status      string                `json:"status" dynamodbav:"status"`
+	Selected    []SelectedItem        `json:"selected,omitempty" dynamodbav:"selected,omitempty"`
+	Excluded    []ExcludedItem        `json:"excluded,omitempty" dynamodbav:"excluded,omitempty"`
+	SceneGroups []SceneGroup          `json:"sceneGroups,omitempty" dynamodbav:"sceneGroups,omitempty"`
+	Error       string                `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	RetryCount  int                   `json:"retryCount,omitempty" dynamodbav:"retryCount,omitempty"`
+	Telemetry   *metrics.JobTelemetry `json:"perJobTelemetry,omitempty" dynamodbav:"perJobTelemetry,omitempty"` // DDR-118
+	// Model, Thinking, Temperature and TopP are the Gemini settings the job
+	// ran with, for comparing runs (DDR-155, DDR-170). Thinking is empty,
+	// and Temperature and TopP nil, when the model's default was used.
+	Model       string   `json:"model,omitempty" dynamodbav:"model,omitempty"`
+	Thinking    string   `json:"thinking,omitempty" dynamodbav:"thinking,omitempty"`
+	Temperature *float32 `json:"temperature,omitempty" dynamodbav:"temperature,omitempty"`
+	TopP        *float32 `json:"topP,omitempty" dynamodbav:"topP,omitempty"`
+	// ModelsUsed lists the models that answered, which differ from Model
+	// when a fallback took over (DDR-169).
+	ModelsUsed []string `json:"modelsUsed,omitempty" dynamodbav:"modelsUsed,omitempty"`
+	// Unprocessed lists the files the AI never judged, so they are neither
+	// selected nor excluded (DDR-134).
+	Unprocessed []UnprocessedItem `json:"unprocessed,omitempty" dynamodbav:"unprocessed,omitempty"`
+	// CompletedAt (Unix seconds) orders a session's completed jobs, and
+	// Changes compares the job with the one completed before it (DDR-158).
+	CompletedAt int64          `json:"completedAt,omitempty" dynamodbav:"completedAt,omitempty"`
+	Changes     *SelectionDiff `json:"changes,omitempty" dynamodbav:"changes,omitempty"`
+	// TripContext is the context the job ran with, which its re-runs reuse.
+	// A re-run records the job it revises in RerunOf, its revision in
+	// Version (an original job is version 1 and stores 0), and the keys the
+	// user locked and excluded (DDR-176).
+	TripContext  string   `json:"tripContext,omitempty" dynamodbav:"tripContext,omitempty"`
+	RerunOf      string   `json:"rerunOf,omitempty" dynamodbav:"rerunOf,omitempty"`
+	Version      int      `json:"version,omitempty" dynamodbav:"version,omitempty"`
+	LockedKeys   []string `json:"lockedKeys,omitempty" dynamodbav:"lockedKeys,omitempty"`
+	ExcludedKeys []string `json:"excludedKeys,omitempty" dynamodbav:"excludedKeys,omitempty"`
+	// FeedbackHistory lists the user's feedback rounds, oldest first; each
+	// revised the job's results in place (DDR-177).
+	FeedbackHistory []SelectionFeedbackEntry `json:"feedbackHistory,omitempty" dynamodbav:"feedbackHistory,omitempty"`
+}
+
+// Revision returns the job's version, counting an original job as 1
+// (DDR-176).
+func (j *SelectionJob) Revision() int {
+	if j.Version < 1 {
+		return 1
+	}
+	return j.Version
+}
+
+// UnprocessedItem is a file left out of selection, with the reason.
+type UnprocessedItem struct {
+	Filename string `json:"filename" dynamodbav:"filename"`
+	Key      string `json:"key" dynamodbav:"key"`
+	Reason   string `json:"reason" dynamodbav:"reason"`
+}
+
+// SelectionFeedbackEntry records one round of selection feedback (DDR-177).
+type SelectionFeedbackEntry struct {
+	UserFeedback string `json:"userFeedback" dynamodbav:"userFeedback"`
+	CreatedAt    int64  `json:"createdAt" dynamodbav:"createdAt"` // Unix seconds
 }
 
 // SelectedItem represents a media item chosen by the AI.
@@ -218,6 +425,23 @@ type SelectedItem struct {
 	Justification  string `json:"justification" dynamodbav:"justification"`
 	ComparisonNote string `json:"comparisonNote,omitempty" dynamodbav:"comparisonNote,omitempty"`
 	ThumbnailURL   string `json:"thumbnailUrl" dynamodbav:"thumbnailUrl"`
+
+	Scores  *SelectionScores `json:"scores,omitempty" dynamodbav:"scores,omitempty"`   // DDR-150
+	AltText string           `json:"altText,omitempty" dynamodbav:"altText,omitempty"` // DDR-154: photos only
+	// Highlights are Reels clips cut from a selected video, best first
+	// (DDR-187): videos only.
+	Highlights []VideoHighlight `json:"highlights,omitempty" dynamodbav:"highlights,omitempty"`
+}
+
+// VideoHighlight is a 15–30 second clip of a selected video, re-encoded
+// for Reels and stored at Key ({sessionId}/highlights/...). StartMs and
+// EndMs locate it in the original video.
+type VideoHighlight struct {
+	Key     string `json:"key" dynamodbav:"key"`
+	StartMs int64  `json:"startMs" dynamodbav:"startMs"`
+	EndMs   int64  `json:"endMs" dynamodbav:"endMs"`
+	Reason  string `json:"reason,omitempty" dynamodbav:"reason,omitempty"`
+	Size    int64  `json:"size,omitempty" dynamodbav:"size,omitempty"`
 }
 
 // ExcludedItem represents a media item not chosen by the AI.
@@ -260,22 +484,100 @@ type EnhancementJob struct {
 	TotalCount     int               `json:"totalCount" dynamodbav:"totalCount"`
 	CompletedCount int               `json:"completedCount" dynamodbav:"completedCount"`
 	Error          string            `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	RetryCount     int               `json:"retryCount,omitempty" dynamodbav:"retryCount,omitempty"`
+	PausedAt       int64             `json:"pausedAt,omitempty" dynamodbav:"pausedAt,omitempty"`             // DDR-095
+	ResumeCount    int               `json:"resumeCount,omitempty" dynamodbav:"resumeCount,omitempty"`       // DDR-095
+	DebugArtifacts bool              `json:"debugArtifacts,omitempty" dynamodbav:"debugArtifacts,omitempty"` // DDR-106
+	Watermark      bool              `json:"watermark,omitempty" dynamodbav:"watermark,omitempty"`           // DDR-133
+
+	// Gemini settings the job asked for (DDR-170), kept for resumed runs
+	// and feedback; empty or nil for the defaults.
+	Model       string   `json:"model,omitempty" dynamodbav:"model,omitempty"`
+	Thinking    string   `json:"thinking,omitempty" dynamodbav:"thinking,omitempty"`
+	Temperature *float32 `json:"temperature,omitempty" dynamodbav:"temperature,omitempty"`
+	TopP        *float32 `json:"topP,omitempty" dynamodbav:"topP,omitempty"`
+
+	// SubjectPreset is the job's ai.SubjectPreset (DDR-180), kept for
+	// resumed runs; "" for the general pipeline.
+	SubjectPreset string `json:"subjectPreset,omitempty" dynamodbav:"subjectPreset,omitempty"`
+
+	// UpscaleBelow is the long edge in pixels under which a photo's result
+	// is upscaled (DDR-184); 0 when upscaling is off.
+	UpscaleBelow int `json:"upscaleBelow,omitempty" dynamodbav:"upscaleBelow,omitempty"`
+
+	// Telemetry is the usage of every item's invocations so far, added to
+	// atomically as items finish (DDR-118).
+	Telemetry *metrics.JobTelemetry `json:"perJobTelemetry,omitempty" dynamodbav:"perJobTelemetry,omitempty"`
 }
 
 // EnhancementItem tracks enhancement state for a single photo.
 type EnhancementItem struct {
-	Key              string          `json:"key" dynamodbav:"key"`
-	Filename         string          `json:"filename" dynamodbav:"filename"`
-	Phase            string          `json:"phase" dynamodbav:"phase"`
-	OriginalKey      string          `json:"originalKey" dynamodbav:"originalKey"`
-	EnhancedKey      string          `json:"enhancedKey,omitempty" dynamodbav:"enhancedKey,omitempty"`
-	OriginalThumbKey string          `json:"originalThumbKey,omitempty" dynamodbav:"originalThumbKey,omitempty"`
-	EnhancedThumbKey string          `json:"enhancedThumbKey,omitempty" dynamodbav:"enhancedThumbKey,omitempty"`
-	Phase1Text       string          `json:"phase1Text,omitempty" dynamodbav:"phase1Text,omitempty"`
-	Analysis         *AnalysisResult `json:"analysis,omitempty" dynamodbav:"analysis,omitempty"`
-	ImagenEdits      int             `json:"imagenEdits" dynamodbav:"imagenEdits"`
-	FeedbackHistory  []FeedbackEntry `json:"feedbackHistory,omitempty" dynamodbav:"feedbackHistory,omitempty"`
-	Error            string          `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	Key                string              `json:"key" dynamodbav:"key"`
+	Filename           string              `json:"filename" dynamodbav:"filename"`
+	Phase              string              `json:"phase" dynamodbav:"phase"`
+	OriginalKey        string              `json:"originalKey" dynamodbav:"originalKey"`
+	EnhancedKey        string              `json:"enhancedKey,omitempty" dynamodbav:"enhancedKey,omitempty"`
+	OriginalThumbKey   string              `json:"originalThumbKey,omitempty" dynamodbav:"originalThumbKey,omitempty"`
+	EnhancedThumbKey   string              `json:"enhancedThumbKey,omitempty" dynamodbav:"enhancedThumbKey,omitempty"`
+	CleanKey           string              `json:"cleanKey,omitempty" dynamodbav:"cleanKey,omitempty"` // DDR-133: enhanced copy before watermarking
+	Phase1Text         string              `json:"phase1Text,omitempty" dynamodbav:"phase1Text,omitempty"`
+	Analysis           *AnalysisResult     `json:"analysis,omitempty" dynamodbav:"analysis,omitempty"`
+	ImagenEdits        int                 `json:"imagenEdits" dynamodbav:"imagenEdits"`
+	FeedbackHistory    []FeedbackEntry     `json:"feedbackHistory,omitempty" dynamodbav:"feedbackHistory,omitempty"`
+	Error              string              `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	LegibilityWarnings []LegibilityWarning `json:"legibilityWarnings,omitempty" dynamodbav:"legibilityWarnings,omitempty"` // DDR-104
+	Preset             string              `json:"preset,omitempty" dynamodbav:"preset,omitempty"`                         // DDR-143: ai.EnhancementPreset; "" = full
+
+	// DDR-181: every result the item has had. The Enhanced*/CleanKey fields
+	// above point at CurrentVersion.
+	Versions       []EnhancementVersion `json:"versions,omitempty" dynamodbav:"versions,omitempty"`
+	CurrentVersion int                  `json:"currentVersion,omitempty" dynamodbav:"currentVersion,omitempty"`
+
+	// DDR-184: set when the result was upscaled.
+	Upscale *UpscaleInfo `json:"upscale,omitempty" dynamodbav:"upscale,omitempty"`
+
+	// DDR-185: bystanders hidden by the privacy preset in the current
+	// version. RedactionError is set, with the error phase, when they could
+	// not be detected; no result is stored then.
+	Redactions     []RedactRegion `json:"redactions,omitempty" dynamodbav:"redactions,omitempty"`
+	RedactionError string         `json:"redactionError,omitempty" dynamodbav:"redactionError,omitempty"`
+}
+
+// UpscaleInfo records the super-resolution step of an item (DDR-184): the
+// original photo's dimensions, the factor Imagen enlarged the pipeline's
+// result by, and the dimensions of the saved result.
+type UpscaleInfo struct {
+	Factor       int `json:"factor" dynamodbav:"factor"`
+	BeforeWidth  int `json:"beforeWidth" dynamodbav:"beforeWidth"`
+	BeforeHeight int `json:"beforeHeight" dynamodbav:"beforeHeight"`
+	AfterWidth   int `json:"afterWidth" dynamodbav:"afterWidth"`
+	AfterHeight  int `json:"afterHeight" dynamodbav:"afterHeight"`
+}
+
+// EnhancementVersion is one result of enhancing an item, kept so the user
+// can compare results and roll back (DDR-181). The pipeline's result is
+// version 1 and each feedback round adds the next.
+type EnhancementVersion struct {
+	Version            int                 `json:"version" dynamodbav:"version"`
+	EnhancedKey        string              `json:"enhancedKey" dynamodbav:"enhancedKey"`
+	EnhancedThumbKey   string              `json:"enhancedThumbKey,omitempty" dynamodbav:"enhancedThumbKey,omitempty"`
+	CleanKey           string              `json:"cleanKey,omitempty" dynamodbav:"cleanKey,omitempty"`
+	Feedback           string              `json:"feedback,omitempty" dynamodbav:"feedback,omitempty"` // the feedback that produced it; "" for the pipeline result
+	LegibilityWarnings []LegibilityWarning `json:"legibilityWarnings,omitempty" dynamodbav:"legibilityWarnings,omitempty"`
+	CreatedAt          int64               `json:"createdAt,omitempty" dynamodbav:"createdAt,omitempty"` // Unix seconds; 0 for results saved before versioning
+
+	// DDR-183: local adjustments that produced it, summarized.
+	Adjustments string `json:"adjustments,omitempty" dynamodbav:"adjustments,omitempty"`
+
+	// DDR-185: bystanders hidden in this version by the privacy preset.
+	Redactions []RedactRegion `json:"redactions,omitempty" dynamodbav:"redactions,omitempty"`
+}
+
+// LegibilityWarning flags text in an enhanced photo that is unlikely to stay
+// readable on Instagram. Mirrors media.LegibilityWarning for DynamoDB persistence.
+type LegibilityWarning struct {
+	Code    string `json:"code" dynamodbav:"code"`
+	Message string `json:"message" dynamodbav:"message"`
 }
 
 // AnalysisResult is the Phase 2 quality analysis output.
@@ -309,11 +611,27 @@ type FeedbackEntry struct {
 // DownloadJob represents a ZIP bundle creation job
 // (DynamoDB SK = DOWNLOAD#{jobId}).
 type DownloadJob struct {
-	ID        string           `json:"id" dynamodbav:"-"`
-	SessionID string           `json:"-" dynamodbav:"-"`
-	Status    string           `json:"status" dynamodbav:"status"`
-	Bundles   []DownloadBundle `json:"bundles,omitempty" dynamodbav:"bundles,omitempty"`
-	Error     string           `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	ID         string           `json:"id" dynamodbav:"-"`
+	SessionID  string           `json:"-" dynamodbav:"-"`
+	Status     string           `json:"status" dynamodbav:"status"`
+	GroupLabel string           `json:"groupLabel,omitempty" dynamodbav:"groupLabel,omitempty"`
+	Bundles    []DownloadBundle `json:"bundles,omitempty" dynamodbav:"bundles,omitempty"`
+	Error      string           `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	RetryCount int              `json:"retryCount,omitempty" dynamodbav:"retryCount,omitempty"`
+	RetryOf    string           `json:"retryOf,omitempty" dynamodbav:"retryOf,omitempty"` // DDR-097: source job of a retry-omitted job
+	// DDR-101: DownloadFingerprint of the job's keys and label, used to reuse
+	// a prewarmed job when the user later asks for the same bundle.
+	Fingerprint string `json:"fingerprint,omitempty" dynamodbav:"fingerprint,omitempty"`
+	Prewarm     bool   `json:"prewarm,omitempty" dynamodbav:"prewarm,omitempty"` // DDR-101: started ahead of the download screen
+	// DDR-135: metadata policy applied to the bundled photos, fixed at dispatch.
+	MetadataPolicy string `json:"metadataPolicy,omitempty" dynamodbav:"metadataPolicy,omitempty"`
+	// DDR-115: set while Status is "restoring" — every key of the job, so it
+	// can be dispatched again, and the archived keys still being restored.
+	Keys             []string `json:"-" dynamodbav:"keys,omitempty"`
+	RestoringKeys    []string `json:"restoringKeys,omitempty" dynamodbav:"restoringKeys,omitempty"`
+	RestoreCheckedAt int64    `json:"-" dynamodbav:"restoreCheckedAt,omitempty"` // unix seconds
+	// Telemetry is the job's Gemini, S3 and ffmpeg usage so far (DDR-118).
+	Telemetry *metrics.JobTelemetry `json:"perJobTelemetry,omitempty" dynamodbav:"perJobTelemetry,omitempty"`
 }
 
 // DownloadBundle represents a single ZIP archive in a download job.
@@ -325,25 +643,72 @@ type DownloadBundle struct {
 	FileCount   int    `json:"fileCount" dynamodbav:"fileCount"`
 	TotalSize   int64  `json:"totalSize" dynamodbav:"totalSize"`
 	ZipSize     int64  `json:"zipSize,omitempty" dynamodbav:"zipSize,omitempty"`
+	SHA256      string `json:"sha256,omitempty" dynamodbav:"sha256,omitempty"` // DDR-099: hex digest of the ZIP
+	ETag        string `json:"etag,omitempty" dynamodbav:"etag,omitempty"`     // DDR-099: If-Range validator for resumed downloads
 	Status      string `json:"status" dynamodbav:"bundleStatus"`
 	Error       string `json:"error,omitempty" dynamodbav:"bundleError,omitempty"`
+
+	// OmittedFiles lists the S3 keys that could not be read and are missing
+	// from the ZIP (DDR-097). FileCount counts only the files actually written.
+	OmittedFiles []string `json:"omittedFiles,omitempty" dynamodbav:"omittedFiles,omitempty"`
+}
+
+// OmittedKeys returns every key omitted from the job's bundles, in bundle order.
+func (j *DownloadJob) OmittedKeys() []string {
+	var keys []string
+	for _, b := range j.Bundles {
+		keys = append(keys, b.OmittedFiles...)
+	}
+	return keys
 }
 
 // DescriptionJob represents an AI caption generation job
 // (DynamoDB SK = DESC#{jobId}).
 type DescriptionJob struct {
-	ID          string              `json:"id" dynamodbav:"-"`
-	SessionID   string              `json:"-" dynamodbav:"-"`
-	Status      string              `json:"status" dynamodbav:"status"`
-	GroupLabel  string              `json:"groupLabel,omitempty" dynamodbav:"groupLabel,omitempty"`
-	TripContext string              `json:"tripContext,omitempty" dynamodbav:"tripContext,omitempty"`
-	MediaKeys   []string            `json:"mediaKeys,omitempty" dynamodbav:"mediaKeys,omitempty"`
-	Caption     string              `json:"caption,omitempty" dynamodbav:"caption,omitempty"`
-	Hashtags    []string            `json:"hashtags,omitempty" dynamodbav:"hashtags,omitempty"`
-	LocationTag string              `json:"locationTag,omitempty" dynamodbav:"locationTag,omitempty"`
-	RawResponse string              `json:"-" dynamodbav:"rawResponse,omitempty"`
-	History     []ConversationEntry `json:"history,omitempty" dynamodbav:"history,omitempty"`
-	Error       string              `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	ID          string                `json:"id" dynamodbav:"-"`
+	SessionID   string                `json:"-" dynamodbav:"-"`
+	Status      string                `json:"status" dynamodbav:"status"`
+	GroupLabel  string                `json:"groupLabel,omitempty" dynamodbav:"groupLabel,omitempty"`
+	TripContext string                `json:"tripContext,omitempty" dynamodbav:"tripContext,omitempty"`
+	MediaKeys   []string              `json:"mediaKeys,omitempty" dynamodbav:"mediaKeys,omitempty"`
+	Caption     string                `json:"caption,omitempty" dynamodbav:"caption,omitempty"`
+	Hashtags    []string              `json:"hashtags,omitempty" dynamodbav:"hashtags,omitempty"`
+	LocationTag string                `json:"locationTag,omitempty" dynamodbav:"locationTag,omitempty"`
+	RawResponse string                `json:"-" dynamodbav:"rawResponse,omitempty"`
+	History     []ConversationEntry   `json:"history,omitempty" dynamodbav:"history,omitempty"` // before DDR-152 only
+	Error       string                `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	RetryCount  int                   `json:"retryCount,omitempty" dynamodbav:"retryCount,omitempty"`
+	Telemetry   *metrics.JobTelemetry `json:"perJobTelemetry,omitempty" dynamodbav:"perJobTelemetry,omitempty"` // DDR-118
+
+	// Caption format from a post template (DDR-122), kept for feedback rounds.
+	CaptionSkeleton  string   `json:"captionSkeleton,omitempty" dynamodbav:"captionSkeleton,omitempty"`
+	TemplateHashtags []string `json:"templateHashtags,omitempty" dynamodbav:"templateHashtags,omitempty"`
+
+	// Brand kit guidance (DDR-149), kept for feedback rounds.
+	BrandHashtags []string `json:"brandHashtags,omitempty" dynamodbav:"brandHashtags,omitempty"`
+	SignOff       string   `json:"signOff,omitempty" dynamodbav:"signOff,omitempty"`
+
+	// AltText describes each of MediaKeys for screen readers (DDR-151).
+	AltText []string `json:"altText,omitempty" dynamodbav:"altText,omitempty"`
+
+	// Gemini settings the job asked for (DDR-170), kept for feedback
+	// rounds; empty or nil for the defaults. ModelsUsed lists the models
+	// that answered the latest round (DDR-169).
+	Model       string   `json:"model,omitempty" dynamodbav:"model,omitempty"`
+	Thinking    string   `json:"thinking,omitempty" dynamodbav:"thinking,omitempty"`
+	Temperature *float32 `json:"temperature,omitempty" dynamodbav:"temperature,omitempty"`
+	TopP        *float32 `json:"topP,omitempty" dynamodbav:"topP,omitempty"`
+	ModelsUsed  []string `json:"modelsUsed,omitempty" dynamodbav:"modelsUsed,omitempty"`
+
+	// FeedbackRounds counts the feedback rounds stored as separate items
+	// (DDR-152); load them with GetDescriptionHistory.
+	FeedbackRounds int `json:"feedbackRounds,omitempty" dynamodbav:"feedbackRounds,omitempty"`
+}
+
+// Rounds returns how many feedback rounds the job has had, counting
+// history stored inline by jobs written before DDR-152.
+func (j *DescriptionJob) Rounds() int {
+	return max(j.FeedbackRounds, len(j.History))
 }
 
 // ConversationEntry records one round of description feedback.
@@ -362,8 +727,66 @@ type PublishJob struct {
 	TotalItems      int      `json:"totalItems" dynamodbav:"totalItems"`
 	CompletedItems  int      `json:"completedItems" dynamodbav:"completedItems"`
 	InstagramPostID string   `json:"instagramPostId,omitempty" dynamodbav:"instagramPostId,omitempty"`
+	FacebookPostID  string   `json:"facebookPostId,omitempty" dynamodbav:"facebookPostId,omitempty"` // DDR-147
+	MastodonPostID  string   `json:"mastodonPostId,omitempty" dynamodbav:"mastodonPostId,omitempty"` // DDR-151
 	ContainerIDs    []string `json:"containerIds,omitempty" dynamodbav:"containerIds,omitempty"`
 	Error           string   `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	RetryCount      int      `json:"retryCount,omitempty" dynamodbav:"retryCount,omitempty"`
+	// ItemErrors lists the items that failed Instagram's media requirements
+	// and could not be converted (DDR-129).
+	ItemErrors []PublishItemError `json:"itemErrors,omitempty" dynamodbav:"itemErrors,omitempty"`
+	// Platforms reports each platform the job posts to (DDR-153). When some
+	// publish and others fail, Status is PublishPartiallyPublished.
+	Platforms []PlatformPublish `json:"platforms,omitempty" dynamodbav:"platforms,omitempty"`
+	// FirstCommentID is the Instagram comment holding the post's hashtags,
+	// and FirstCommentError why it could not be posted (DDR-163). The post
+	// itself stays published either way.
+	FirstCommentID    string `json:"firstCommentId,omitempty" dynamodbav:"firstCommentId,omitempty"`
+	FirstCommentError string `json:"firstCommentError,omitempty" dynamodbav:"firstCommentError,omitempty"`
+	// Telemetry is the job's Gemini, S3 and ffmpeg usage so far (DDR-118).
+	Telemetry *metrics.JobTelemetry `json:"perJobTelemetry,omitempty" dynamodbav:"perJobTelemetry,omitempty"`
+}
+
+// PublishPartiallyPublished is the status of a cross-posting job that
+// published to some of its platforms and failed on others (DDR-153).
+const PublishPartiallyPublished = "partially_published"
+
+// PlatformPublish is one platform's outcome in a publish job. Status is
+// "pending" until the platform publishes ("published") or fails ("error").
+type PlatformPublish struct {
+	Platform string `json:"platform" dynamodbav:"platform"`
+	Status   string `json:"status" dynamodbav:"status"`
+	PostID   string `json:"postId,omitempty" dynamodbav:"postId,omitempty"`
+	Error    string `json:"error,omitempty" dynamodbav:"error,omitempty"`
+}
+
+// PublishItemError explains why one item of a publish job cannot be posted.
+type PublishItemError struct {
+	Index int    `json:"index" dynamodbav:"index"` // 0-based position in the post
+	Key   string `json:"key" dynamodbav:"key"`
+	Error string `json:"error" dynamodbav:"error"`
+}
+
+// JobSummary is the status projection shared by every job record type.
+// Used by the retry endpoint and DLQ redrive handler (DDR-089), which must
+// inspect a job without knowing its concrete type.
+type JobSummary struct {
+	ID         string `json:"id" dynamodbav:"-"`
+	Type       string `json:"type,omitempty" dynamodbav:"-"` // step name, set by DescribeSession (DDR-098)
+	Status     string `json:"status" dynamodbav:"status"`
+	Error      string `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	RetryCount int    `json:"retryCount,omitempty" dynamodbav:"retryCount,omitempty"`
+}
+
+// DispatchRecord captures how an async job was dispatched
+// (DynamoDB SK = DISPATCH#{jobId}). Written by the API Lambda on every
+// lambda:Invoke so a failed job can be re-sent with its original payload (DDR-089).
+type DispatchRecord struct {
+	JobID     string `json:"jobId" dynamodbav:"-"`
+	Target    string `json:"target" dynamodbav:"target"`   // Lambda function ARN
+	EventType string `json:"type" dynamodbav:"eventType"`  // Event "type" field, e.g. "description"
+	Payload   string `json:"payload" dynamodbav:"payload"` // Original JSON event
+	CreatedAt int64  `json:"createdAt" dynamodbav:"createdAt"`
 }
 
 // PostGroup represents a user-created post group (DynamoDB SK = GROUP#{groupId}).
@@ -375,4 +798,28 @@ type PostGroup struct {
 	Caption         string   `json:"caption,omitempty" dynamodbav:"caption,omitempty"`
 	PublishStatus   string   `json:"publishStatus,omitempty" dynamodbav:"publishStatus,omitempty"`
 	InstagramPostID string   `json:"instagramPostId,omitempty" dynamodbav:"instagramPostId,omitempty"`
+
+	// Position orders the session's groups from 0. DescriptionJobID and
+	// PublishJobID are the latest jobs started from the group (DDR-178).
+	Position         int    `json:"position" dynamodbav:"position"`
+	CreatedAt        int64  `json:"createdAt,omitempty" dynamodbav:"createdAt,omitempty"`
+	DescriptionJobID string `json:"descriptionJobId,omitempty" dynamodbav:"descriptionJobId,omitempty"`
+	PublishJobID     string `json:"publishJobId,omitempty" dynamodbav:"publishJobId,omitempty"`
+
+	// OrderSuggestion is the latest AI-suggested carousel order (DDR-179).
+	OrderSuggestion *CarouselOrderSuggestion `json:"orderSuggestion,omitempty" dynamodbav:"orderSuggestion,omitempty"`
+}
+
+// CarouselOrderSuggestion is Gemini's suggested order for a post group's
+// items (DDR-179). Status is "processing", "complete" or "error". Keys hold
+// the group's keys in the suggested order, opening item first; they are the
+// keys the suggestion was made for, which later edits to the group may
+// change.
+type CarouselOrderSuggestion struct {
+	Status        string   `json:"status" dynamodbav:"status"`
+	Keys          []string `json:"keys,omitempty" dynamodbav:"keys,omitempty"`
+	OpeningReason string   `json:"openingReason,omitempty" dynamodbav:"openingReason,omitempty"`
+	Rationale     string   `json:"rationale,omitempty" dynamodbav:"rationale,omitempty"`
+	Error         string   `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	CreatedAt     int64    `json:"createdAt,omitempty" dynamodbav:"createdAt,omitempty"`
 }
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Triage overrides (DDR-100) ---
+
+// TriageOverride is a single item whose AI verdict the user reversed during
+// triage review. UserKeep is the user's verdict; the AI verdict is its inverse.
+type TriageOverride struct {
+	Item     TriageItem
+	UserKeep bool
+}
+
+// Overrides resolves the keys the user kept and discarded against the job's
+// AI verdicts. kept must name items the AI discarded and discarded must name
+// items the AI kept; a key that is not in the job, or that agrees with the AI,
+// is an error so a stale review page cannot record a bogus override.
+func (j *TriageJob) Overrides(kept, discarded []string) ([]TriageOverride, error) {
+	keep := make(map[string]TriageItem, len(j.Keep))
+	for _, item := range j.Keep {
+		keep[item.Key] = item
+	}
+	discard := make(map[string]TriageItem, len(j.Discard))
+	for _, item := range j.Discard {
+		discard[item.Key] = item
+	}
+
+	seen := make(map[string]bool, len(kept)+len(discarded))
+	out := make([]TriageOverride, 0, len(kept)+len(discarded))
+	for _, key := range kept {
+		item, ok := discard[key]
+		if !ok || seen[key] {
+			return nil, fmt.Errorf("kept key is not an AI discard: %s", key)
+		}
+		seen[key] = true
+		out = append(out, TriageOverride{Item: item, UserKeep: true})
+	}
+	for _, key := range discarded {
+		item, ok := keep[key]
+		if !ok || seen[key] {
+			return nil, fmt.Errorf("discarded key is not an AI keep: %s", key)
+		}
+		seen[key] = true
+		out = append(out, TriageOverride{Item: item, UserKeep: false})
+	}
+	return out, nil
+}
+
+// MarkTriageOverridesRecorded stamps overridesRecordedAt on a complete triage
+// job. Overrides are appended to the decision tables, so each job may record
+// them once; returns ErrStatusConflict if the job is not complete or its
+// overrides were already recorded.
+func (s *DynamoStore) MarkTriageOverridesRecorded(ctx context.Context, sessionID, jobID string) error {
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: sessionPK(sessionID)},
+			"SK": &types.AttributeValueMemberS{Value: skTriage + jobID},
+		},
+		UpdateExpression:    aws.String("SET overridesRecordedAt = :now"),
+		ConditionExpression: aws.String("#st = :complete AND attribute_not_exists(overridesRecordedAt)"),
+		ExpressionAttributeNames: map[string]string{
+			"#st": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":complete": &types.AttributeValueMemberS{Value: "complete"},
+			":now":      &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Unix(), 10)},
+		},
+	})
+	if err != nil {
+		return conditionalErr(fmt.Sprintf("MarkTriageOverridesRecorded %s/%s", sessionID, jobID), err)
+	}
+
+	log.Debug().Str("sessionId", sessionID).Str("jobId", jobID).Msg("Triage overrides recorded")
+	return nil
+}
@@ -0,0 +1,35 @@
+package store
+
+import "testing"
+
+func TestTriageJobOverrides(t *testing.T) {
+	job := &TriageJob{
+		Keep:    []TriageItem{{Key: "s/a.jpg", Reason: "sharp"}, {Key: "s/b.jpg"}},
+		Discard: []TriageItem{{Key: "s/c.jpg", Reason: "blurry"}},
+	}
+
+	got, err := job.Overrides([]string{"s/c.jpg"}, []string{"s/a.jpg"})
+	if err != nil {
+		t.Fatalf("Overrides: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d overrides, want 2", len(got))
+	}
+	if got[0].Item.Key != "s/c.jpg" || !got[0].UserKeep || got[0].Item.Reason != "blurry" {
+		t.Errorf("kept override = %+v", got[0])
+	}
+	if got[1].Item.Key != "s/a.jpg" || got[1].UserKeep {
+		t.Errorf("discarded override = %+v", got[1])
+	}
+
+	for name, tc := range map[string][2][]string{
+		"kept agrees with AI":      {{"s/a.jpg"}, nil},
+		"discarded agrees with AI": {nil, {"s/c.jpg"}},
+		"unknown key":              {{"s/z.jpg"}, nil},
+		"duplicate key":            {{"s/c.jpg", "s/c.jpg"}, nil},
+	} {
+		if _, err := job.Overrides(tc[0], tc[1]); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/fpang/ai-social-media-helper/internal/metrics"
+)
+
+// --- Gemini usage gauges (DDR-142) ---
+
+const (
+	pkUsageGemini = "USAGE#GEMINI"
+	skInFlight    = "INFLIGHT#"
+	skTokens      = "TOKENS#"
+)
+
+// ModelTokenTTL is how long daily token totals are kept.
+const ModelTokenTTL = 35 * 24 * time.Hour
+
+// ModelTokens is one model's token consumption for one UTC day (DynamoDB
+// PK = USAGE#GEMINI, SK = TOKENS#{day}#{model}).
+type ModelTokens struct {
+	Model        string `json:"model" dynamodbav:"model"`
+	InputTokens  int64  `json:"inputTokens" dynamodbav:"inputTokens"`
+	OutputTokens int64  `json:"outputTokens" dynamodbav:"outputTokens"`
+	Requests     int64  `json:"requests" dynamodbav:"requests"`
+}
+
+// GeminiInFlight is the in-flight Gemini request count summed over the
+// processes that reported within metrics.InFlightTTL.
+type GeminiInFlight struct {
+	InFlight  int64 `json:"inFlight"`
+	Instances int   `json:"instances"`
+}
+
+var _ metrics.UsageStore = (*DynamoStore)(nil)
+
+// PutGeminiInFlight implements metrics.UsageStore (PK = USAGE#GEMINI,
+// SK = INFLIGHT#{instance}). The record expires metrics.InFlightTTL after
+// its last update.
+func (s *DynamoStore) PutGeminiInFlight(ctx context.Context, instance string, inFlight int64) error {
+	now := time.Now()
+	_, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &s.tableName,
+		Item: map[string]types.AttributeValue{
+			"PK":        &types.AttributeValueMemberS{Value: pkUsageGemini},
+			"SK":        &types.AttributeValueMemberS{Value: skInFlight + instance},
+			"inFlight":  &types.AttributeValueMemberN{Value: strconv.FormatInt(inFlight, 10)},
+			"updatedAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Unix(), 10)},
+			"expiresAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Add(metrics.InFlightTTL).Unix(), 10)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("put in-flight count for %s: %w", instance, err)
+	}
+	return nil
+}
+
+// AddModelTokens implements metrics.UsageStore by atomically adding to the
+// model's daily totals.
+func (s *DynamoStore) AddModelTokens(ctx context.Context, day, model string, requests, input, output int64) error {
+	expires := time.Now().Add(ModelTokenTTL).Unix()
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &s.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: pkUsageGemini},
+			"SK": &types.AttributeValueMemberS{Value: skTokens + day + "#" + model},
+		},
+		UpdateExpression: aws.String("ADD inputTokens :in, outputTokens :out, #requests :requests SET #model = :model, expiresAt = if_not_exists(expiresAt, :exp)"),
+		ExpressionAttributeNames: map[string]string{
+			"#requests": "requests",
+			"#model":    "model",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":in":       &types.AttributeValueMemberN{Value: strconv.FormatInt(input, 10)},
+			":out":      &types.AttributeValueMemberN{Value: strconv.FormatInt(output, 10)},
+			":requests": &types.AttributeValueMemberN{Value: strconv.FormatInt(requests, 10)},
+			":model":    &types.AttributeValueMemberS{Value: model},
+			":exp":      &types.AttributeValueMemberN{Value: strconv.FormatInt(expires, 10)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("add %s tokens for %s: %w", model, day, err)
+	}
+	return nil
+}
+
+// GetGeminiInFlight sums the in-flight counts of processes that reported
+// within metrics.InFlightTTL. Expired records can outlive their TTL until
+// DynamoDB deletes them, so they are filtered here.
+func (s *DynamoStore) GetGeminiInFlight(ctx context.Context) (*GeminiInFlight, error) {
+	items, err := s.queryUsage(ctx, skInFlight)
+	if err != nil {
+		return nil, fmt.Errorf("query in-flight counts: %w", err)
+	}
+	cutoff := time.Now().Add(-metrics.InFlightTTL).Unix()
+	out := &GeminiInFlight{}
+	for _, item := range items {
+		var rec struct {
+			InFlight  int64 `dynamodbav:"inFlight"`
+			UpdatedAt int64 `dynamodbav:"updatedAt"`
+		}
+		if err := attributevalue.UnmarshalMap(item, &rec); err != nil || rec.UpdatedAt < cutoff {
+			continue
+		}
+		out.InFlight += rec.InFlight
+		out.Instances++
+	}
+	return out, nil
+}
+
+// ListModelTokens returns each model's token totals for day (YYYY-MM-DD,
+// UTC), sorted by model.
+func (s *DynamoStore) ListModelTokens(ctx context.Context, day string) ([]ModelTokens, error) {
+	items, err := s.queryUsage(ctx, skTokens+day+"#")
+	if err != nil {
+		return nil, fmt.Errorf("query model tokens for %s: %w", day, err)
+	}
+	out := make([]ModelTokens, 0, len(items))
+	for _, item := range items {
+		var t ModelTokens
+		if err := attributevalue.UnmarshalMap(item, &t); err != nil {
+			return nil, fmt.Errorf("unmarshal model tokens: %w", err)
+		}
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Model < out[j].Model })
+	return out, nil
+}
+
+// queryUsage returns the USAGE#GEMINI items whose SK begins with skPrefix.
+func (s *DynamoStore) queryUsage(ctx context.Context, skPrefix string) ([]map[string]types.AttributeValue, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              &s.tableName,
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :skPrefix)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":       &types.AttributeValueMemberS{Value: pkUsageGemini},
+			":skPrefix": &types.AttributeValueMemberS{Value: skPrefix},
+		},
+	}
+	var items []map[string]types.AttributeValue
+	for {
+		result, err := s.client.Query(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, result.Items...)
+		if result.LastEvaluatedKey == nil {
+			return items, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
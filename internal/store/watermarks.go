@@ -0,0 +1,69 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog/log"
+)
+
+// --- Watermarks (DDR-133) ---
+
+const skWatermark = "WATERMARK"
+
+// WatermarkSettings is a user's watermark (DynamoDB PK = USER#{sub},
+// SK = WATERMARK). Like post templates it has no TTL. The fields mirror
+// media.Overlay; Logo is a PNG stored as a binary attribute.
+type WatermarkSettings struct {
+	Text      string  `json:"text,omitempty" dynamodbav:"text,omitempty"`
+	Logo      []byte  `json:"logo,omitempty" dynamodbav:"logo,omitempty"`
+	Position  string  `json:"position,omitempty" dynamodbav:"position,omitempty"`
+	Opacity   float64 `json:"opacity,omitempty" dynamodbav:"opacity,omitempty"`
+	Scale     float64 `json:"scale,omitempty" dynamodbav:"scale,omitempty"`
+	UpdatedAt int64   `json:"updatedAt,omitempty" dynamodbav:"updatedAt,omitempty"`
+}
+
+// PutWatermark creates or replaces owner's watermark. It bypasses putItem so
+// the record carries no expiresAt attribute.
+func (s *DynamoStore) PutWatermark(ctx context.Context, owner string, w *WatermarkSettings) error {
+	item, err := attributevalue.MarshalMap(w)
+	if err != nil {
+		return fmt.Errorf("marshal watermark: %w", err)
+	}
+	item["PK"] = &types.AttributeValueMemberS{Value: userPK(owner)}
+	item["SK"] = &types.AttributeValueMemberS{Value: skWatermark}
+
+	if _, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &s.tableName,
+		Item:      item,
+	}); err != nil {
+		return fmt.Errorf("put watermark: %w", err)
+	}
+	log.Debug().Bool("logo", len(w.Logo) > 0).Str("position", w.Position).Msg("Watermark persisted")
+	return nil
+}
+
+// GetWatermark returns owner's watermark, or nil, nil if none is set.
+func (s *DynamoStore) GetWatermark(ctx context.Context, owner string) (*WatermarkSettings, error) {
+	var w WatermarkSettings
+	found, err := s.getItem(ctx, userPK(owner), skWatermark, &w)
+	if err != nil {
+		return nil, fmt.Errorf("get watermark: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &w, nil
+}
+
+// DeleteWatermark removes owner's watermark. Deleting a missing watermark is
+// not an error.
+func (s *DynamoStore) DeleteWatermark(ctx context.Context, owner string) error {
+	if err := s.deleteItem(ctx, userPK(owner), skWatermark); err != nil {
+		return fmt.Errorf("delete watermark: %w", err)
+	}
+	return nil
+}
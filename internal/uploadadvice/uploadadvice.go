@@ -0,0 +1,164 @@
+// Package uploadadvice estimates how long a set of files takes to upload
+// and recommends uploading originals or client-side-downscaled copies
+// (DDR-128). Triage and FB prep never look at more than a 1920px photo or a
+// 768px video, so on a slow link uploading originals only to have the
+// pipeline shrink them is wasted time.
+package uploadadvice
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+)
+
+const (
+	// advicePhotoMaxPx matches media-process targetResizePx: the largest
+	// photo the triage and FB prep models ever receive.
+	advicePhotoMaxPx = 1920
+	// adviceVideoMaxPx matches the gemini-analysis transcode profile width.
+	adviceVideoMaxPx = media.MaxResolution
+
+	// downscaledPhotoBytes is a typical 1920px JPEG at quality 85.
+	downscaledPhotoBytes int64 = 700 * 1024
+	// downscaledVideoBytesPerSec is ~1.5 Mbps, a 768px H.264 encode.
+	downscaledVideoBytesPerSec int64 = 190 * 1024
+	// assumedVideoBytesPerSec estimates duration when the client did not
+	// send one: ~16 Mbps, a phone's 1080p default.
+	assumedVideoBytesPerSec int64 = 2 * 1024 * 1024
+
+	// adviceMinUploadSeconds is the original-upload time below which the
+	// advisor never suggests downscaling — not worth the quality loss.
+	adviceMinUploadSeconds = 10 * 60
+	// adviceMinSavingRatio is the fraction of bytes a type must save
+	// before downscaling it is recommended.
+	adviceMinSavingRatio = 0.5
+)
+
+// TypeManifest describes the files of one media type.
+type TypeManifest struct {
+	Count int   `json:"count"`
+	Bytes int64 `json:"bytes"`
+	// DurationSeconds is the total video duration, when the client knows it.
+	DurationSeconds float64 `json:"durationSeconds,omitempty"`
+}
+
+// Request is the files to upload and the client's measured throughput.
+type Request struct {
+	// Workflow is "triage" (default), "fb-prep" or "selection".
+	Workflow              string       `json:"workflow"`
+	Images                TypeManifest `json:"images"`
+	Videos                TypeManifest `json:"videos"`
+	ThroughputBytesPerSec float64      `json:"throughputBytesPerSec"`
+}
+
+// TypeAdvice is the advice for one media type.
+type TypeAdvice struct {
+	Downscale bool `json:"downscale"`
+	// MaxDimension is the recommended longest edge in pixels; 0 means
+	// upload originals.
+	MaxDimension   int   `json:"maxDimension"`
+	OriginalBytes  int64 `json:"originalBytes"`
+	EstimatedBytes int64 `json:"estimatedBytes"`
+}
+
+// Response is the advice for a whole upload.
+type Response struct {
+	Recommendation     string     `json:"recommendation"` // "originals" or "downscale"
+	Reason             string     `json:"reason"`
+	OriginalSeconds    int        `json:"originalSeconds"`
+	RecommendedSeconds int        `json:"recommendedSeconds"`
+	Images             TypeAdvice `json:"images"`
+	Videos             TypeAdvice `json:"videos"`
+}
+
+// Advise applies the per-workflow resolution ceilings to the manifest
+// and recommends downscaling each media type whose upload would otherwise
+// be both long and mostly wasted.
+func Advise(req Request) (Response, error) {
+	var photoMax, videoMax int
+	switch req.Workflow {
+	case "", "triage", "fb-prep":
+		photoMax, videoMax = advicePhotoMaxPx, adviceVideoMaxPx
+	case "selection":
+		// Enhancement and publishing work from the original pixels.
+	default:
+		return Response{}, fmt.Errorf("unknown workflow: %s", req.Workflow)
+	}
+
+	images := TypeAdvice{OriginalBytes: req.Images.Bytes, EstimatedBytes: req.Images.Bytes}
+	if photoMax > 0 && req.Images.Count > 0 {
+		images.EstimatedBytes = min(req.Images.Bytes, int64(req.Images.Count)*downscaledPhotoBytes)
+		images.MaxDimension = photoMax
+	}
+
+	videos := TypeAdvice{OriginalBytes: req.Videos.Bytes, EstimatedBytes: req.Videos.Bytes}
+	if videoMax > 0 && req.Videos.Count > 0 {
+		seconds := req.Videos.DurationSeconds
+		if seconds == 0 {
+			seconds = float64(req.Videos.Bytes) / float64(assumedVideoBytesPerSec)
+		}
+		videos.EstimatedBytes = min(req.Videos.Bytes, int64(seconds*float64(downscaledVideoBytesPerSec)))
+		videos.MaxDimension = videoMax
+	}
+
+	throughput := req.ThroughputBytesPerSec
+	resp := Response{
+		Recommendation:  "originals",
+		OriginalSeconds: uploadSeconds(images.OriginalBytes+videos.OriginalBytes, throughput),
+	}
+
+	if resp.OriginalSeconds >= adviceMinUploadSeconds {
+		for _, t := range []*TypeAdvice{&images, &videos} {
+			t.Downscale = t.MaxDimension > 0 && t.OriginalBytes > 0 &&
+				float64(t.OriginalBytes-t.EstimatedBytes) >= adviceMinSavingRatio*float64(t.OriginalBytes)
+		}
+	}
+
+	recommended := images.OriginalBytes + videos.OriginalBytes
+	for _, t := range []*TypeAdvice{&images, &videos} {
+		if t.Downscale {
+			recommended -= t.OriginalBytes - t.EstimatedBytes
+		} else {
+			t.MaxDimension = 0
+			t.EstimatedBytes = t.OriginalBytes
+		}
+	}
+	resp.RecommendedSeconds = uploadSeconds(recommended, throughput)
+	resp.Images, resp.Videos = images, videos
+
+	switch {
+	case images.Downscale || videos.Downscale:
+		resp.Recommendation = "downscale"
+		resp.Reason = fmt.Sprintf("Originals would take about %s at your connection speed; downscaled copies take about %s and %s never uses more resolution.",
+			formatUploadDuration(resp.OriginalSeconds), formatUploadDuration(resp.RecommendedSeconds), workflowLabel(req.Workflow))
+	case photoMax == 0:
+		resp.Reason = "This workflow edits and publishes the original files."
+	case resp.OriginalSeconds < adviceMinUploadSeconds:
+		resp.Reason = fmt.Sprintf("Originals upload in about %s.", formatUploadDuration(resp.OriginalSeconds))
+	default:
+		resp.Reason = fmt.Sprintf("The files are already close to the size %s would use.", workflowLabel(req.Workflow))
+	}
+	return resp, nil
+}
+
+// uploadSeconds is bytes over throughput, rounded up.
+func uploadSeconds(bytes int64, throughput float64) int {
+	return int(math.Ceil(float64(bytes) / throughput))
+}
+
+// formatUploadDuration renders seconds as "45 min" or "2 h 10 min".
+func formatUploadDuration(seconds int) string {
+	minutes := (seconds + 59) / 60
+	if minutes < 60 {
+		return fmt.Sprintf("%d min", minutes)
+	}
+	return fmt.Sprintf("%d h %d min", minutes/60, minutes%60)
+}
+
+func workflowLabel(workflow string) string {
+	if workflow == "fb-prep" {
+		return "FB prep"
+	}
+	return "triage"
+}
@@ -0,0 +1,127 @@
+package uploadadvice
+
+import (
+	"testing"
+
+	"github.com/fpang/ai-social-media-helper/internal/media"
+)
+
+const (
+	kib = 1024
+	mib = 1024 * kib
+	gib = 1024 * mib
+)
+
+func TestAdvise(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+		want Response
+	}{
+		{
+			name: "fast link uploads originals",
+			req:  Request{Images: TypeManifest{Count: 100, Bytes: 100 * 5 * mib}, ThroughputBytesPerSec: 10 * mib},
+			want: Response{
+				Recommendation:     "originals",
+				Reason:             "Originals upload in about 1 min.",
+				OriginalSeconds:    50,
+				RecommendedSeconds: 50,
+				Images:             TypeAdvice{OriginalBytes: 100 * 5 * mib, EstimatedBytes: 100 * 5 * mib},
+			},
+		},
+		{
+			name: "slow link downscales photos for triage",
+			req:  Request{Workflow: "triage", Images: TypeManifest{Count: 100, Bytes: 100 * 5 * mib}, ThroughputBytesPerSec: 100 * kib},
+			want: Response{
+				Recommendation:     "downscale",
+				Reason:             "Originals would take about 1 h 26 min at your connection speed; downscaled copies take about 12 min and triage never uses more resolution.",
+				OriginalSeconds:    5120,
+				RecommendedSeconds: 700,
+				Images:             TypeAdvice{Downscale: true, MaxDimension: 1920, OriginalBytes: 100 * 5 * mib, EstimatedBytes: 100 * 700 * kib},
+			},
+		},
+		{
+			name: "selection always uploads originals",
+			req:  Request{Workflow: "selection", Images: TypeManifest{Count: 100, Bytes: 100 * 5 * mib}, ThroughputBytesPerSec: 100 * kib},
+			want: Response{
+				Recommendation:     "originals",
+				Reason:             "This workflow edits and publishes the original files.",
+				OriginalSeconds:    5120,
+				RecommendedSeconds: 5120,
+				Images:             TypeAdvice{OriginalBytes: 100 * 5 * mib, EstimatedBytes: 100 * 5 * mib},
+			},
+		},
+		{
+			name: "small photos are not worth downscaling",
+			req:  Request{Workflow: "fb-prep", Images: TypeManifest{Count: 1000, Bytes: 1000 * 800 * kib}, ThroughputBytesPerSec: 100 * kib},
+			want: Response{
+				Recommendation:     "originals",
+				Reason:             "The files are already close to the size FB prep would use.",
+				OriginalSeconds:    8000,
+				RecommendedSeconds: 8000,
+				Images:             TypeAdvice{OriginalBytes: 1000 * 800 * kib, EstimatedBytes: 1000 * 800 * kib},
+			},
+		},
+		{
+			name: "video duration is assumed from its size",
+			req:  Request{Videos: TypeManifest{Count: 1, Bytes: gib}, ThroughputBytesPerSec: mib},
+			want: Response{
+				Recommendation:     "downscale",
+				Reason:             "Originals would take about 18 min at your connection speed; downscaled copies take about 2 min and triage never uses more resolution.",
+				OriginalSeconds:    1024,
+				RecommendedSeconds: 95,
+				Videos:             TypeAdvice{Downscale: true, MaxDimension: media.MaxResolution, OriginalBytes: gib, EstimatedBytes: 512 * 190 * kib},
+			},
+		},
+		{
+			name: "only the type that saves is downscaled",
+			req: Request{
+				Images:                TypeManifest{Count: 100, Bytes: 100 * 600 * kib},
+				Videos:                TypeManifest{Count: 1, Bytes: gib, DurationSeconds: 100},
+				ThroughputBytesPerSec: mib,
+			},
+			want: Response{
+				Recommendation:     "downscale",
+				Reason:             "Originals would take about 19 min at your connection speed; downscaled copies take about 2 min and triage never uses more resolution.",
+				OriginalSeconds:    1083,
+				RecommendedSeconds: 78,
+				Images:             TypeAdvice{OriginalBytes: 100 * 600 * kib, EstimatedBytes: 100 * 600 * kib},
+				Videos:             TypeAdvice{Downscale: true, MaxDimension: media.MaxResolution, OriginalBytes: gib, EstimatedBytes: 100 * 190 * kib},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Advise(tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Advise() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdviseRejectsUnknownWorkflow(t *testing.T) {
+	if _, err := Advise(Request{Workflow: "enhance", ThroughputBytesPerSec: mib}); err == nil {
+		t.Error("unknown workflow accepted")
+	}
+}
+
+func TestFormatUploadDuration(t *testing.T) {
+	for seconds, want := range map[int]string{
+		0:    "0 min",
+		1:    "1 min",
+		60:   "1 min",
+		61:   "2 min",
+		3540: "59 min",
+		3541: "1 h 0 min",
+		3600: "1 h 0 min",
+		7800: "2 h 10 min",
+	} {
+		if got := formatUploadDuration(seconds); got != want {
+			t.Errorf("formatUploadDuration(%d) = %q, want %q", seconds, got, want)
+		}
+	}
+}
@@ -0,0 +1,1001 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Health returns the backend's build and configuration.
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	return getAs[Health](ctx, c, "/api/health", nil)
+}
+
+// --- Uploads ---
+
+// UploadURL returns a presigned S3 PUT URL for a file in the session.
+func (c *Client) UploadURL(ctx context.Context, sessionID, filename, contentType string) (*UploadURL, error) {
+	q := url.Values{"sessionId": {sessionID}, "filename": {filename}, "contentType": {contentType}}
+	return getAs[UploadURL](ctx, c, "/api/upload-url", q)
+}
+
+// UploadFile uploads the local file at path to the session with a single
+// presigned PUT and returns its S3 key. The content type is derived from the
+// file extension. Use the multipart endpoints for files over a few hundred MB.
+func (c *Client) UploadFile(ctx context.Context, sessionID, path string) (string, error) {
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		return "", fmt.Errorf("upload %s: unknown content type", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("upload %s: %w", path, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("upload %s: %w", path, err)
+	}
+
+	target, err := c.UploadURL(ctx, sessionID, filepath.Base(path), contentType)
+	if err != nil {
+		return "", err
+	}
+	if err := c.put(ctx, target.UploadURL, contentType, target.Headers, f, info.Size()); err != nil {
+		return "", fmt.Errorf("upload %s: %w", path, err)
+	}
+	return target.Key, nil
+}
+
+// put uploads body to a presigned S3 URL with the headers it was signed
+// for. It sends no API headers: the URL carries its own authorization.
+func (c *Client) put(ctx context.Context, presignedURL, contentType string, headers map[string]string, body io.Reader, size int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, presignedURL, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return readAPIError(resp)
+	}
+	return nil
+}
+
+// InitMultipartUpload starts a multipart upload and returns presigned part URLs.
+func (c *Client) InitMultipartUpload(ctx context.Context, req MultipartInitRequest) (*MultipartUpload, error) {
+	return postAs[MultipartUpload](ctx, c, "/api/upload-multipart/init", req)
+}
+
+// CompleteMultipartUpload assembles the uploaded parts and returns the object key.
+func (c *Client) CompleteMultipartUpload(ctx context.Context, sessionID, key, uploadID string, parts []CompletedPart) (string, error) {
+	req := struct {
+		SessionID string          `json:"sessionId"`
+		Key       string          `json:"key"`
+		UploadID  string          `json:"uploadId"`
+		Parts     []CompletedPart `json:"parts"`
+	}{sessionID, key, uploadID, parts}
+	var out struct {
+		Key string `json:"key"`
+	}
+	if err := c.postJSON(ctx, "/api/upload-multipart/complete", req, &out); err != nil {
+		return "", err
+	}
+	return out.Key, nil
+}
+
+// AbortMultipartUpload cancels a multipart upload and discards its parts.
+func (c *Client) AbortMultipartUpload(ctx context.Context, sessionID, key, uploadID string) error {
+	req := struct {
+		SessionID string `json:"sessionId"`
+		Key       string `json:"key"`
+		UploadID  string `json:"uploadId"`
+	}{sessionID, key, uploadID}
+	return c.postJSON(ctx, "/api/upload-multipart/abort", req, nil)
+}
+
+// UploadAdvice recommends uploading originals or downscaled copies for the
+// given manifest and measured throughput (DDR-128).
+func (c *Client) UploadAdvice(ctx context.Context, req UploadAdviceRequest) (*UploadAdvice, error) {
+	return postAs[UploadAdvice](ctx, c, "/api/upload/advice", req)
+}
+
+// RegisterProxyUpload records a downscaled proxy for an original that will
+// be uploaded later, or never, and returns a presigned PUT URL for the proxy
+// (DDR-131).
+func (c *Client) RegisterProxyUpload(ctx context.Context, req ProxyUploadRequest) (*ProxyUpload, error) {
+	return postAs[ProxyUpload](ctx, c, "/api/upload/proxy", req)
+}
+
+// OriginalUploadURL returns a presigned PUT URL for the original of the proxy
+// named filename. Upload it with the link's original content type.
+func (c *Client) OriginalUploadURL(ctx context.Context, sessionID, filename string) (*UploadURL, error) {
+	req := struct {
+		SessionID string `json:"sessionId"`
+		Filename  string `json:"filename"`
+	}{sessionID, filename}
+	return postAs[UploadURL](ctx, c, "/api/upload/original", req)
+}
+
+// CompleteOriginalUpload links an uploaded original to its proxy, so
+// downloads and publish use the original from then on.
+func (c *Client) CompleteOriginalUpload(ctx context.Context, sessionID, filename string) (*MediaLink, error) {
+	req := struct {
+		SessionID string `json:"sessionId"`
+		Filename  string `json:"filename"`
+	}{sessionID, filename}
+	return postAs[MediaLink](ctx, c, "/api/upload/original/complete", req)
+}
+
+// MediaLinks lists the session's proxy uploads and their originals.
+func (c *Client) MediaLinks(ctx context.Context, sessionID string) (*MediaLinks, error) {
+	return getAs[MediaLinks](ctx, c, "/api/upload/links", sessionQuery(sessionID))
+}
+
+// --- Triage ---
+
+// InitTriage creates a triage job before uploads finish (DDR-061). The
+// pipeline starts when FinalizeTriage is called.
+func (c *Client) InitTriage(ctx context.Context, req TriageInitRequest) (*TriageJobRef, error) {
+	return postAs[TriageJobRef](ctx, c, "/api/triage/init", req)
+}
+
+// UpdateTriageFiles changes the expected file count of an initialized job.
+func (c *Client) UpdateTriageFiles(ctx context.Context, sessionID, jobID string, expectedFileCount int) error {
+	req := struct {
+		SessionID         string `json:"sessionId"`
+		JobID             string `json:"jobId"`
+		ExpectedFileCount int    `json:"expectedFileCount"`
+	}{sessionID, jobID, expectedFileCount}
+	return c.postJSON(ctx, "/api/triage/update-files", req, nil)
+}
+
+// FinalizeTriage starts the pipeline for an initialized job once all uploads
+// are complete (DDR-067).
+func (c *Client) FinalizeTriage(ctx context.Context, sessionID, jobID string) (*TriageJobRef, error) {
+	req := struct {
+		SessionID string `json:"sessionId"`
+		JobID     string `json:"jobId"`
+	}{sessionID, jobID}
+	return postAs[TriageJobRef](ctx, c, "/api/triage/finalize", req)
+}
+
+// StartTriage triages every file already uploaded to the session.
+func (c *Client) StartTriage(ctx context.Context, req TriageStartRequest) (*JobStarted, error) {
+	return postAs[JobStarted](ctx, c, "/api/triage/start", req)
+}
+
+// TriageResults returns the current state of a triage job.
+func (c *Client) TriageResults(ctx context.Context, sessionID, jobID string) (*TriageResults, error) {
+	return getAs[TriageResults](ctx, c, jobPath("triage", jobID, "results"), sessionQuery(sessionID))
+}
+
+// TriageLogs returns pipeline log lines after since (Unix milliseconds; 0 for
+// the beginning).
+func (c *Client) TriageLogs(ctx context.Context, sessionID, jobID string, since int64) (*TriageLogs, error) {
+	q := sessionQuery(sessionID)
+	if since > 0 {
+		q.Set("since", strconv.FormatInt(since, 10))
+	}
+	return getAs[TriageLogs](ctx, c, jobPath("triage", jobID, "logs"), q)
+}
+
+// ConfirmTriage deletes the given discarded keys and cleans up the session's
+// remaining artifacts (DDR-059).
+func (c *Client) ConfirmTriage(ctx context.Context, sessionID, jobID string, deleteKeys []string) (*TriageConfirmResult, error) {
+	req := struct {
+		SessionID  string   `json:"sessionId"`
+		DeleteKeys []string `json:"deleteKeys"`
+	}{sessionID, deleteKeys}
+	return postAs[TriageConfirmResult](ctx, c, jobPath("triage", jobID, "confirm"), req)
+}
+
+// RecordTriageOverrides records the verdicts the user reversed (DDR-100):
+// kept lists AI discards the user kept, discarded lists AI keeps the user
+// discarded. It returns the number of overrides recorded.
+func (c *Client) RecordTriageOverrides(ctx context.Context, sessionID, jobID string, kept, discarded []string) (int, error) {
+	req := struct {
+		SessionID string   `json:"sessionId"`
+		Kept      []string `json:"kept"`
+		Discarded []string `json:"discarded"`
+	}{sessionID, kept, discarded}
+	var out struct {
+		Recorded int `json:"recorded"`
+	}
+	if err := c.postJSON(ctx, jobPath("triage", jobID, "override"), req, &out); err != nil {
+		return 0, err
+	}
+	return out.Recorded, nil
+}
+
+// FileStatuses returns the processing status of every file in the session.
+func (c *Client) FileStatuses(ctx context.Context, sessionID string) ([]FileStatus, error) {
+	var out struct {
+		FileStatuses []FileStatus `json:"fileStatuses"`
+	}
+	if err := c.getJSON(ctx, jobPath("sessions", sessionID, "file-status"), nil, &out); err != nil {
+		return nil, err
+	}
+	return out.FileStatuses, nil
+}
+
+// --- Selection ---
+
+// StartSelection starts AI selection over the session's kept media.
+func (c *Client) StartSelection(ctx context.Context, req SelectionStartRequest) (*JobStarted, error) {
+	return postAs[JobStarted](ctx, c, "/api/selection/start", req)
+}
+
+// SelectionResults returns the current state of a selection job.
+func (c *Client) SelectionResults(ctx context.Context, sessionID, jobID string) (*SelectionResults, error) {
+	return getAs[SelectionResults](ctx, c, jobPath("selection", jobID, "results"), sessionQuery(sessionID))
+}
+
+// RerunSelection starts a new selection job that revises the completed job
+// jobID around the user's locked and excluded picks (DDR-176).
+func (c *Client) RerunSelection(ctx context.Context, jobID string, req SelectionRerunRequest) (*SelectionRerunStarted, error) {
+	return postAs[SelectionRerunStarted](ctx, c, jobPath("selection", jobID, "rerun"), req)
+}
+
+// SelectionFeedback revises a completed selection in place from the user's
+// free-text feedback (DDR-177). Poll SelectionResults until it completes.
+func (c *Client) SelectionFeedback(ctx context.Context, sessionID, jobID, feedback string) error {
+	req := struct {
+		SessionID string `json:"sessionId"`
+		Feedback  string `json:"feedback"`
+	}{sessionID, feedback}
+	return c.postJSON(ctx, jobPath("selection", jobID, "feedback"), req, nil)
+}
+
+// SelectionResultsSorted is SelectionResults with the selected items ordered
+// by one of the Criterion* scores, highest first (DDR-150).
+func (c *Client) SelectionResultsSorted(ctx context.Context, sessionID, jobID, criterion string) (*SelectionResults, error) {
+	q := sessionQuery(sessionID)
+	q.Set("sort", criterion)
+	return getAs[SelectionResults](ctx, c, jobPath("selection", jobID, "results"), q)
+}
+
+// RecordOverride records a single add-back or removal during selection review.
+func (c *Client) RecordOverride(ctx context.Context, sessionID string, action OverrideAction) error {
+	return c.postJSON(ctx, jobPath("overrides", sessionID, ""), action, nil)
+}
+
+// FinalizeOverrides records the net selection changes when the user proceeds.
+func (c *Client) FinalizeOverrides(ctx context.Context, sessionID string, delta OverrideDelta) error {
+	return c.postJSON(ctx, jobPath("overrides", sessionID, "finalize"), delta, nil)
+}
+
+// --- Enhancement ---
+
+// StartEnhancement enhances the given photos and videos.
+func (c *Client) StartEnhancement(ctx context.Context, req EnhancementStartRequest) (*JobStarted, error) {
+	return postAs[JobStarted](ctx, c, "/api/enhance/start", req)
+}
+
+// EnhancementResults returns the current state of an enhancement job.
+func (c *Client) EnhancementResults(ctx context.Context, sessionID, jobID string) (*EnhancementResults, error) {
+	return getAs[EnhancementResults](ctx, c, jobPath("enhance", jobID, "results"), sessionQuery(sessionID))
+}
+
+// EnhancementFeedback asks for another round of edits on one photo.
+func (c *Client) EnhancementFeedback(ctx context.Context, sessionID, jobID, key, feedback string) error {
+	req := struct {
+		SessionID string `json:"sessionId"`
+		Key       string `json:"key"`
+		Feedback  string `json:"feedback"`
+	}{sessionID, key, feedback}
+	return c.postJSON(ctx, jobPath("enhance", jobID, "feedback"), req, nil)
+}
+
+// PauseEnhancement pauses a running enhancement job (DDR-095).
+func (c *Client) PauseEnhancement(ctx context.Context, sessionID, jobID string) (*EnhancementControl, error) {
+	return postAs[EnhancementControl](ctx, c, jobPath("enhance", jobID, "pause"), sessionBody(sessionID))
+}
+
+// ResumeEnhancement resumes a paused enhancement job (DDR-095).
+func (c *Client) ResumeEnhancement(ctx context.Context, sessionID, jobID string) (*EnhancementControl, error) {
+	return postAs[EnhancementControl](ctx, c, jobPath("enhance", jobID, "resume"), sessionBody(sessionID))
+}
+
+// StartFeedbackBatch applies one feedback instruction to several photos of an
+// enhancement job (DDR-182).
+func (c *Client) StartFeedbackBatch(ctx context.Context, jobID string, req FeedbackBatchRequest) (*FeedbackBatch, error) {
+	return postAs[FeedbackBatch](ctx, c, jobPath("enhance", jobID, "feedback-batch"), req)
+}
+
+// FeedbackBatchStatus returns the per-photo state of a feedback batch.
+func (c *Client) FeedbackBatchStatus(ctx context.Context, sessionID, jobID, batchID string) (*FeedbackBatch, error) {
+	return getAs[FeedbackBatch](ctx, c, jobPath("enhance", jobID, "feedback-batch"), url.Values{"sessionId": {sessionID}, "batchId": {batchID}})
+}
+
+// EnhancementVersions lists every result a photo has had, with preview URLs
+// (DDR-181). key is the item's original or current enhanced key.
+func (c *Client) EnhancementVersions(ctx context.Context, sessionID, jobID, key string) (*EnhancementVersions, error) {
+	return getAs[EnhancementVersions](ctx, c, jobPath("enhance", jobID, "versions"), url.Values{"sessionId": {sessionID}, "key": {key}})
+}
+
+// RollBackEnhancement makes an earlier version of a photo its current result
+// (DDR-181). No version is deleted.
+func (c *Client) RollBackEnhancement(ctx context.Context, sessionID, jobID, key string, version int) (*EnhancementItem, error) {
+	req := struct {
+		SessionID string `json:"sessionId"`
+		Key       string `json:"key"`
+		Version   int    `json:"version"`
+	}{sessionID, key, version}
+	return postAs[EnhancementItem](ctx, c, jobPath("enhance", jobID, "rollback"), req)
+}
+
+// AdjustMedia applies local adjustments to a photo of an enhancement job
+// without Gemini or Imagen (DDR-183). The result is saved asynchronously as
+// the photo's next version; poll EnhancementVersions for it.
+func (c *Client) AdjustMedia(ctx context.Context, sessionID, jobID, key string, adj Adjustments) (*AdjustStarted, error) {
+	req := struct {
+		SessionID   string      `json:"sessionId"`
+		JobID       string      `json:"jobId"`
+		Key         string      `json:"key"`
+		Adjustments Adjustments `json:"adjustments"`
+	}{sessionID, jobID, key, adj}
+	return postAs[AdjustStarted](ctx, c, "/api/media/adjust", req)
+}
+
+// --- Download ---
+
+// StartDownload bundles the given keys into ZIPs. An existing job for the
+// same keys and label is returned with Reused set (DDR-101).
+func (c *Client) StartDownload(ctx context.Context, req DownloadStartRequest) (*DownloadStarted, error) {
+	return postAs[DownloadStarted](ctx, c, "/api/download/start", req)
+}
+
+// DownloadResults returns the current state of a download job. Bundle URLs
+// are re-signed on every call (DDR-099).
+func (c *Client) DownloadResults(ctx context.Context, sessionID, jobID string) (*DownloadResults, error) {
+	return getAs[DownloadResults](ctx, c, jobPath("download", jobID, "results"), sessionQuery(sessionID))
+}
+
+// RetryOmittedDownload starts a job for the files a finished job could not
+// include (DDR-097).
+func (c *Client) RetryOmittedDownload(ctx context.Context, sessionID, jobID string) (*DownloadRetry, error) {
+	return postAs[DownloadRetry](ctx, c, jobPath("download", jobID, "retry-omitted"), sessionBody(sessionID))
+}
+
+// --- Description ---
+
+// GenerateDescription generates a caption for a post group.
+func (c *Client) GenerateDescription(ctx context.Context, req DescriptionRequest) (*JobStarted, error) {
+	return postAs[JobStarted](ctx, c, "/api/description/generate", req)
+}
+
+// DescriptionResults returns the current state of a description job.
+func (c *Client) DescriptionResults(ctx context.Context, sessionID, jobID string) (*DescriptionResults, error) {
+	return getAs[DescriptionResults](ctx, c, jobPath("description", jobID, "results"), sessionQuery(sessionID))
+}
+
+// DescriptionFeedback regenerates the caption with the user's feedback.
+func (c *Client) DescriptionFeedback(ctx context.Context, sessionID, jobID, feedback string) error {
+	req := struct {
+		SessionID string `json:"sessionId"`
+		Feedback  string `json:"feedback"`
+	}{sessionID, feedback}
+	return c.postJSON(ctx, jobPath("description", jobID, "feedback"), req, nil)
+}
+
+// --- FB prep ---
+
+// StartFBPrep prepares Facebook captions and metadata for the given keys.
+func (c *Client) StartFBPrep(ctx context.Context, sessionID string, keys []string, economyMode bool) (*FBPrepStarted, error) {
+	type mediaItem struct {
+		Key string `json:"key"`
+	}
+	req := struct {
+		SessionID   string      `json:"sessionId"`
+		MediaItems  []mediaItem `json:"mediaItems"`
+		EconomyMode bool        `json:"economyMode"`
+	}{SessionID: sessionID, EconomyMode: economyMode}
+	for _, k := range keys {
+		req.MediaItems = append(req.MediaItems, mediaItem{Key: k})
+	}
+	return postAs[FBPrepStarted](ctx, c, "/api/fb-prep/start", req)
+}
+
+// FBPrepResults returns the current state of an FB prep job.
+func (c *Client) FBPrepResults(ctx context.Context, sessionID, jobID string) (*FBPrepResults, error) {
+	return getAs[FBPrepResults](ctx, c, jobPath("fb-prep", jobID, "results"), sessionQuery(sessionID))
+}
+
+// FBPrepFeedback regenerates one item's output with the user's feedback.
+func (c *Client) FBPrepFeedback(ctx context.Context, sessionID, jobID string, itemIndex int, feedback string) error {
+	req := struct {
+		SessionID string `json:"sessionId"`
+		ItemIndex int    `json:"itemIndex"`
+		Feedback  string `json:"feedback"`
+	}{sessionID, itemIndex, feedback}
+	return c.postJSON(ctx, jobPath("fb-prep", jobID, "feedback"), req, nil)
+}
+
+// --- Publish ---
+
+// StartPublish publishes a post group to Instagram.
+func (c *Client) StartPublish(ctx context.Context, req PublishRequest) (*PublishStarted, error) {
+	return postAs[PublishStarted](ctx, c, "/api/publish/start", req)
+}
+
+// PublishStatus returns the current state of a publish job.
+func (c *Client) PublishStatus(ctx context.Context, sessionID, jobID string) (*PublishStatus, error) {
+	return getAs[PublishStatus](ctx, c, jobPath("publish", jobID, "status"), sessionQuery(sessionID))
+}
+
+// ApprovePublish lets a job held for approval go live (DDR-121). token is the
+// approval token from StartPublish; leave it empty to approve as a configured
+// approver.
+func (c *Client) ApprovePublish(ctx context.Context, sessionID, jobID, token string) error {
+	return c.decidePublish(ctx, sessionID, jobID, token, "approve")
+}
+
+// RejectPublish cancels a job held for approval (DDR-121).
+func (c *Client) RejectPublish(ctx context.Context, sessionID, jobID, token string) error {
+	return c.decidePublish(ctx, sessionID, jobID, token, "reject")
+}
+
+func (c *Client) decidePublish(ctx context.Context, sessionID, jobID, token, action string) error {
+	req := struct {
+		SessionID string `json:"sessionId"`
+		Token     string `json:"token,omitempty"`
+	}{sessionID, token}
+	return c.postJSON(ctx, jobPath("publish", jobID, action), req, nil)
+}
+
+// ScheduledPosts returns the signed-in user's scheduled posts, soonest first
+// (DDR-144).
+func (c *Client) ScheduledPosts(ctx context.Context) ([]ScheduledPost, error) {
+	var out struct {
+		Posts []ScheduledPost `json:"posts"`
+	}
+	if err := c.getJSON(ctx, "/api/publish/scheduled", nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Posts, nil
+}
+
+// CancelScheduledPost cancels a post that has not been dispatched yet.
+func (c *Client) CancelScheduledPost(ctx context.Context, jobID string) (*ScheduledPost, error) {
+	return postAs[ScheduledPost](ctx, c, jobPath("publish/scheduled", jobID, "cancel"), struct{}{})
+}
+
+// ReschedulePost moves a post that has not been dispatched yet to publishAt.
+func (c *Client) ReschedulePost(ctx context.Context, jobID string, publishAt time.Time) (*ScheduledPost, error) {
+	req := struct {
+		PublishAt time.Time `json:"publishAt"`
+	}{publishAt}
+	return postAs[ScheduledPost](ctx, c, jobPath("publish/scheduled", jobID, "reschedule"), req)
+}
+
+// PostInsights returns the insights collected for a published post, by the
+// InstagramPostID of its publish job (DDR-146).
+func (c *Client) PostInsights(ctx context.Context, instagramPostID string) (*PostInsights, error) {
+	return getAs[PostInsights](ctx, c, jobPath("posts", instagramPostID, "insights"), nil)
+}
+
+// SearchInstagramLocations returns the places a post can be tagged with that
+// match query (DDR-138).
+func (c *Client) SearchInstagramLocations(ctx context.Context, query string) ([]InstagramLocation, error) {
+	var out struct {
+		Locations []InstagramLocation `json:"locations"`
+	}
+	if err := c.getJSON(ctx, "/api/instagram/locations", url.Values{"q": {query}}, &out); err != nil {
+		return nil, err
+	}
+	return out.Locations, nil
+}
+
+// --- Mood variants ---
+
+// StartMoodVariants generates stylized variants of a cover image. Variants
+// count against a daily budget (DDR-102).
+func (c *Client) StartMoodVariants(ctx context.Context, sessionID, key string, styles []string) (*MoodVariantsStarted, error) {
+	req := struct {
+		SessionID string   `json:"sessionId"`
+		Key       string   `json:"key"`
+		Styles    []string `json:"styles"`
+	}{sessionID, key, styles}
+	return postAs[MoodVariantsStarted](ctx, c, "/api/mood-variants/start", req)
+}
+
+// MoodVariantResults returns the current state of a mood variant job.
+func (c *Client) MoodVariantResults(ctx context.Context, sessionID, jobID string) (*MoodVariantResults, error) {
+	return getAs[MoodVariantResults](ctx, c, jobPath("mood-variants", jobID, "results"), sessionQuery(sessionID))
+}
+
+// --- Crop suggestions ---
+
+// StartCropSuggestions proposes subject-aware 1:1, 4:5 and 9:16 crops for
+// up to 20 photos (DDR-130).
+func (c *Client) StartCropSuggestions(ctx context.Context, sessionID string, keys []string) (*JobStarted, error) {
+	req := struct {
+		SessionID string   `json:"sessionId"`
+		Keys      []string `json:"keys"`
+	}{sessionID, keys}
+	return postAs[JobStarted](ctx, c, "/api/crop/start", req)
+}
+
+// CropResults returns the current state of a crop suggestion job.
+func (c *Client) CropResults(ctx context.Context, sessionID, jobID string) (*CropResults, error) {
+	return getAs[CropResults](ctx, c, jobPath("crop", jobID, "results"), sessionQuery(sessionID))
+}
+
+// ApplyCrop crops a photo server-side to the suggested crop of the given
+// aspect. Publish the returned key in place of the original.
+func (c *Client) ApplyCrop(ctx context.Context, sessionID, key, jobID, aspect string) (*CroppedMedia, error) {
+	req := struct {
+		SessionID string `json:"sessionId"`
+		Key       string `json:"key"`
+		JobID     string `json:"jobId"`
+		Aspect    string `json:"aspect"`
+	}{sessionID, key, jobID, aspect}
+	return postAs[CroppedMedia](ctx, c, "/api/media/crop", req)
+}
+
+// ApplyCropRect crops a photo server-side to an explicit rectangle in
+// displayed pixels.
+func (c *Client) ApplyCropRect(ctx context.Context, sessionID, key string, rect CropRect) (*CroppedMedia, error) {
+	req := struct {
+		SessionID string   `json:"sessionId"`
+		Key       string   `json:"key"`
+		Rect      CropRect `json:"rect"`
+	}{sessionID, key, rect}
+	return postAs[CroppedMedia](ctx, c, "/api/media/crop", req)
+}
+
+// --- Privacy redaction ---
+
+// RedactMedia hides bystanders' faces and licence plates in a copy of a
+// photo (DDR-185). mode is "blur" (the default when empty) or "inpaint".
+func (c *Client) RedactMedia(ctx context.Context, sessionID, key, mode string) (*JobStarted, error) {
+	req := struct {
+		SessionID string `json:"sessionId"`
+		Key       string `json:"key"`
+		Mode      string `json:"mode,omitempty"`
+	}{sessionID, key, mode}
+	return postAs[JobStarted](ctx, c, "/api/media/redact", req)
+}
+
+// RedactResults returns the current state of a redaction job.
+func (c *Client) RedactResults(ctx context.Context, sessionID, jobID string) (*RedactResults, error) {
+	return getAs[RedactResults](ctx, c, jobPath("media/redact", jobID, "results"), sessionQuery(sessionID))
+}
+
+// --- Sessions ---
+
+// ListSessions returns the signed-in user's sessions, newest first.
+func (c *Client) ListSessions(ctx context.Context) ([]Session, error) {
+	var out struct {
+		Sessions []Session `json:"sessions"`
+	}
+	if err := c.getJSON(ctx, "/api/sessions", nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Sessions, nil
+}
+
+// Session returns a summary of one session.
+func (c *Client) Session(ctx context.Context, sessionID string) (*Session, error) {
+	return getAs[Session](ctx, c, jobPath("sessions", sessionID, ""), nil)
+}
+
+// DeleteSession deletes a session's files and records. This cannot be undone.
+func (c *Client) DeleteSession(ctx context.Context, sessionID string) (*SessionDeleted, error) {
+	var out SessionDeleted
+	if err := c.doJSON(ctx, http.MethodDelete, jobPath("sessions", sessionID, ""), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MergeSession moves sourceSessionID's files and job history into sessionID
+// and deletes the source session. This cannot be undone.
+func (c *Client) MergeSession(ctx context.Context, sessionID, sourceSessionID string) (*SessionMerged, error) {
+	return postAs[SessionMerged](ctx, c, jobPath("sessions", sessionID, "merge"), map[string]string{"sourceSessionId": sourceSessionID})
+}
+
+// MetadataPolicy returns the session's metadata policy for downloads and
+// Instagram uploads (DDR-135): "strip-gps", "strip-all" or "preserve".
+func (c *Client) MetadataPolicy(ctx context.Context, sessionID string) (string, error) {
+	var out struct {
+		MetadataPolicy string `json:"metadataPolicy"`
+	}
+	if err := c.getJSON(ctx, jobPath("sessions", sessionID, "metadata-policy"), nil, &out); err != nil {
+		return "", err
+	}
+	return out.MetadataPolicy, nil
+}
+
+// SetMetadataPolicy sets the session's metadata policy.
+func (c *Client) SetMetadataPolicy(ctx context.Context, sessionID, policy string) error {
+	return c.postJSON(ctx, jobPath("sessions", sessionID, "metadata-policy"), map[string]string{"metadataPolicy": policy}, nil)
+}
+
+// SessionEncrypted reports whether the session's files are encrypted under
+// their own KMS key (DDR-164).
+func (c *Client) SessionEncrypted(ctx context.Context, sessionID string) (bool, error) {
+	var out struct {
+		Encrypted bool `json:"encrypted"`
+	}
+	if err := c.getJSON(ctx, jobPath("sessions", sessionID, "encryption"), nil, &out); err != nil {
+		return false, err
+	}
+	return out.Encrypted, nil
+}
+
+// EncryptSession gives the session its own KMS key, deleted with the
+// session. It must be called before the first upload.
+func (c *Client) EncryptSession(ctx context.Context, sessionID string) error {
+	return c.postJSON(ctx, jobPath("sessions", sessionID, "encryption"), map[string]bool{"encrypted": true}, nil)
+}
+
+// InvalidateSession clears the state of fromStep and every later step, e.g.
+// when the user goes back and re-runs selection (DDR-037). It returns the
+// invalidated records.
+func (c *Client) InvalidateSession(ctx context.Context, sessionID, fromStep string) ([]string, error) {
+	req := struct {
+		SessionID string `json:"sessionId"`
+		FromStep  string `json:"fromStep"`
+	}{sessionID, fromStep}
+	var out struct {
+		Invalidated []string `json:"invalidated"`
+	}
+	if err := c.postJSON(ctx, "/api/session/invalidate", req, &out); err != nil {
+		return nil, err
+	}
+	return out.Invalidated, nil
+}
+
+// RetryJob re-dispatches a failed asynchronous job (DDR-089).
+func (c *Client) RetryJob(ctx context.Context, sessionID, jobID string) (*JobRetry, error) {
+	return postAs[JobRetry](ctx, c, jobPath("jobs", jobID, "retry"), sessionBody(sessionID))
+}
+
+// Job returns any job's status in a normalized envelope, whatever pipeline
+// started it (DDR-136).
+func (c *Client) Job(ctx context.Context, sessionID, jobID string) (*Job, error) {
+	return getAs[Job](ctx, c, jobPath("jobs", jobID, ""), sessionQuery(sessionID))
+}
+
+// --- Post groups (DDR-178) ---
+
+// PostGroups returns the session's post groups in order.
+func (c *Client) PostGroups(ctx context.Context, sessionID string) ([]PostGroup, error) {
+	var out postGroupList
+	if err := c.getJSON(ctx, "/api/groups", sessionQuery(sessionID), &out); err != nil {
+		return nil, err
+	}
+	return out.Groups, nil
+}
+
+// CreatePostGroup creates a post group of keys after the session's others.
+// An empty name becomes "Post N".
+func (c *Client) CreatePostGroup(ctx context.Context, sessionID, name string, keys []string) (*PostGroup, error) {
+	req := struct {
+		SessionID string   `json:"sessionId"`
+		Name      string   `json:"name,omitempty"`
+		Keys      []string `json:"keys"`
+	}{sessionID, name, keys}
+	return postAs[PostGroup](ctx, c, "/api/groups", req)
+}
+
+// RenamePostGroup renames a post group.
+func (c *Client) RenamePostGroup(ctx context.Context, sessionID, groupID, name string) (*PostGroup, error) {
+	req := struct {
+		SessionID string `json:"sessionId"`
+		Name      string `json:"name"`
+	}{sessionID, name}
+	return postAs[PostGroup](ctx, c, jobPath("groups", groupID, "rename"), req)
+}
+
+// ReorderPostGroup sets the order of a group's items; keys must be all of
+// its keys.
+func (c *Client) ReorderPostGroup(ctx context.Context, sessionID, groupID string, keys []string) (*PostGroup, error) {
+	req := struct {
+		SessionID string   `json:"sessionId"`
+		Keys      []string `json:"keys"`
+	}{sessionID, keys}
+	return postAs[PostGroup](ctx, c, jobPath("groups", groupID, "reorder"), req)
+}
+
+// SplitPostGroup moves a group's items from index at onward into a new
+// group right after it, and returns the session's groups.
+func (c *Client) SplitPostGroup(ctx context.Context, sessionID, groupID string, at int, name string) ([]PostGroup, error) {
+	req := struct {
+		SessionID string `json:"sessionId"`
+		At        int    `json:"at"`
+		Name      string `json:"name,omitempty"`
+	}{sessionID, at, name}
+	out, err := postAs[postGroupList](ctx, c, jobPath("groups", groupID, "split"), req)
+	if err != nil {
+		return nil, err
+	}
+	return out.Groups, nil
+}
+
+// MergePostGroups appends group sourceID's items to group groupID, deletes
+// sourceID, and returns the session's groups.
+func (c *Client) MergePostGroups(ctx context.Context, sessionID, groupID, sourceID string) ([]PostGroup, error) {
+	req := struct {
+		SessionID string `json:"sessionId"`
+		SourceID  string `json:"sourceId"`
+	}{sessionID, sourceID}
+	out, err := postAs[postGroupList](ctx, c, jobPath("groups", groupID, "merge"), req)
+	if err != nil {
+		return nil, err
+	}
+	return out.Groups, nil
+}
+
+// SuggestPostGroupOrder asks Gemini for the group's carousel order
+// (DDR-179). It returns the group with its orderSuggestion "processing";
+// poll PostGroups until the suggestion completes. tripContext is optional.
+func (c *Client) SuggestPostGroupOrder(ctx context.Context, sessionID, groupID, tripContext string) (*PostGroup, error) {
+	req := struct {
+		SessionID   string `json:"sessionId"`
+		TripContext string `json:"tripContext,omitempty"`
+	}{sessionID, tripContext}
+	return postAs[PostGroup](ctx, c, jobPath("groups", groupID, "suggest-order"), req)
+}
+
+// DeletePostGroup deletes a post group and returns the session's groups.
+func (c *Client) DeletePostGroup(ctx context.Context, sessionID, groupID string) ([]PostGroup, error) {
+	var out postGroupList
+	if err := c.doJSON(ctx, http.MethodDelete, jobPath("groups", groupID, ""), sessionQuery(sessionID), nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Groups, nil
+}
+
+type postGroupList struct {
+	Groups []PostGroup `json:"groups"`
+}
+
+// --- Post group templates ---
+
+// ListTemplates returns the signed-in user's post group templates, most
+// recently used first.
+func (c *Client) ListTemplates(ctx context.Context) ([]PostTemplate, error) {
+	var out struct {
+		Templates []PostTemplate `json:"templates"`
+	}
+	if err := c.getJSON(ctx, "/api/templates", nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Templates, nil
+}
+
+// SaveTemplate saves a new post group template and returns it with its ID.
+func (c *Client) SaveTemplate(ctx context.Context, t PostTemplate) (*PostTemplate, error) {
+	return postAs[PostTemplate](ctx, c, "/api/templates", t)
+}
+
+// DeleteTemplate deletes a post group template.
+func (c *Client) DeleteTemplate(ctx context.Context, templateID string) error {
+	return c.doJSON(ctx, http.MethodDelete, jobPath("templates", templateID, ""), nil, nil, nil)
+}
+
+// --- Watermark ---
+
+// Watermark returns the signed-in user's watermark (DDR-133). The API
+// answers 404 when none is set.
+func (c *Client) Watermark(ctx context.Context) (*Watermark, error) {
+	var out Watermark
+	if err := c.getJSON(ctx, "/api/watermark", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SaveWatermark creates or replaces the signed-in user's watermark.
+func (c *Client) SaveWatermark(ctx context.Context, w Watermark) (*Watermark, error) {
+	return postAs[Watermark](ctx, c, "/api/watermark", w)
+}
+
+// DeleteWatermark removes the signed-in user's watermark.
+func (c *Client) DeleteWatermark(ctx context.Context) error {
+	return c.doJSON(ctx, http.MethodDelete, "/api/watermark", nil, nil, nil)
+}
+
+// --- Brand kit ---
+
+// BrandKit returns the signed-in user's brand kit (DDR-149). The API answers
+// 404 when none is set.
+func (c *Client) BrandKit(ctx context.Context) (*BrandKit, error) {
+	var out BrandKit
+	if err := c.getJSON(ctx, "/api/brand-kit", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SaveBrandKit creates or replaces the signed-in user's brand kit.
+func (c *Client) SaveBrandKit(ctx context.Context, k BrandKitUpdate) (*BrandKit, error) {
+	return postAs[BrandKit](ctx, c, "/api/brand-kit", k)
+}
+
+// DeleteBrandKit removes the signed-in user's brand kit and its logo.
+func (c *Client) DeleteBrandKit(ctx context.Context) error {
+	return c.doJSON(ctx, http.MethodDelete, "/api/brand-kit", nil, nil, nil)
+}
+
+// --- Hashtag strategy ---
+
+// HashtagSettings returns the signed-in user's hashtag strategy (DDR-157).
+// The API answers 404 when none is set.
+func (c *Client) HashtagSettings(ctx context.Context) (*HashtagSettings, error) {
+	var out HashtagSettings
+	if err := c.getJSON(ctx, "/api/hashtag-settings", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SaveHashtagSettings creates or replaces the signed-in user's hashtag
+// strategy.
+func (c *Client) SaveHashtagSettings(ctx context.Context, h HashtagSettings) (*HashtagSettings, error) {
+	return postAs[HashtagSettings](ctx, c, "/api/hashtag-settings", h)
+}
+
+// DeleteHashtagSettings removes the signed-in user's hashtag strategy.
+func (c *Client) DeleteHashtagSettings(ctx context.Context) error {
+	return c.doJSON(ctx, http.MethodDelete, "/api/hashtag-settings", nil, nil, nil)
+}
+
+// --- Caption style ---
+
+// CaptionStyle returns the signed-in user's caption persona (DDR-159). The
+// API answers 404 when none is set.
+func (c *Client) CaptionStyle(ctx context.Context) (*CaptionStyle, error) {
+	var out CaptionStyle
+	if err := c.getJSON(ctx, "/api/settings/caption-style", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SaveCaptionStyle creates or replaces the signed-in user's caption persona.
+func (c *Client) SaveCaptionStyle(ctx context.Context, s CaptionStyle) (*CaptionStyle, error) {
+	var out CaptionStyle
+	if err := c.doJSON(ctx, http.MethodPut, "/api/settings/caption-style", nil, s, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// --- Provenance ---
+
+// Provenance returns whether the signed-in user's edited photos carry a
+// provenance record (DDR-140).
+func (c *Client) Provenance(ctx context.Context) (*ProvenanceSettings, error) {
+	var out ProvenanceSettings
+	if err := c.getJSON(ctx, "/api/provenance", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SetProvenance turns provenance records on or off for the signed-in user.
+func (c *Client) SetProvenance(ctx context.Context, enabled bool) (*ProvenanceSettings, error) {
+	return postAs[ProvenanceSettings](ctx, c, "/api/provenance", map[string]bool{"enabled": enabled})
+}
+
+// --- Feature flags ---
+
+// FeatureFlags returns the deployment's feature flags (DDR-132). Requires a
+// caller listed in the API's ADMIN_SUBS.
+func (c *Client) FeatureFlags(ctx context.Context) (map[string]bool, error) {
+	var out struct {
+		Flags map[string]bool `json:"flags"`
+	}
+	if err := c.getJSON(ctx, "/api/admin/flags", nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Flags, nil
+}
+
+// SetFeatureFlag turns a feature flag on or off for the whole deployment and
+// returns the updated flags.
+func (c *Client) SetFeatureFlag(ctx context.Context, name string, enabled bool) (map[string]bool, error) {
+	var out struct {
+		Flags map[string]bool `json:"flags"`
+	}
+	body := map[string]any{"name": name, "enabled": enabled}
+	if err := c.postJSON(ctx, "/api/admin/flags", body, &out); err != nil {
+		return nil, err
+	}
+	return out.Flags, nil
+}
+
+// --- Usage ---
+
+// Usage returns current Gemini concurrency, job queue depth, and the token
+// consumption per model on day (YYYY-MM-DD, UTC; "" for today) (DDR-142).
+// Requires a caller listed in the API's ADMIN_SUBS.
+func (c *Client) Usage(ctx context.Context, day string) (*UsageReport, error) {
+	var q url.Values
+	if day != "" {
+		q = url.Values{"day": {day}}
+	}
+	var out UsageReport
+	if err := c.getJSON(ctx, "/api/admin/usage", q, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StorageReport returns the latest storage reconciliation report (DDR-148).
+// Requires a caller listed in the API's ADMIN_SUBS.
+func (c *Client) StorageReport(ctx context.Context) (*StorageReport, error) {
+	var out StorageReport
+	if err := c.getJSON(ctx, "/api/admin/storage-report", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// --- Media ---
+
+// Thumbnail returns the thumbnail image for an S3 key.
+func (c *Client) Thumbnail(ctx context.Context, key string) ([]byte, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/api/media/thumbnail", url.Values{"key": {key}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// VideoPreview returns the frame strip JPEG for a video's S3 key.
+func (c *Client) VideoPreview(ctx context.Context, key string) ([]byte, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/api/media/preview", url.Values{"key": {key}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// FullImageURL returns a presigned GET URL for the full-resolution object.
+func (c *Client) FullImageURL(ctx context.Context, key string) (string, error) {
+	return c.presignedURL(ctx, "/api/media/full", key)
+}
+
+// CompressedVideoURL returns a presigned GET URL for a video's compressed
+// WebM, falling back to the original when none exists.
+func (c *Client) CompressedVideoURL(ctx context.Context, key string) (string, error) {
+	return c.presignedURL(ctx, "/api/media/compressed", key)
+}
+
+func (c *Client) presignedURL(ctx context.Context, path, key string) (string, error) {
+	var out struct {
+		URL string `json:"url"`
+	}
+	if err := c.getJSON(ctx, path, url.Values{"key": {key}}, &out); err != nil {
+		return "", err
+	}
+	return out.URL, nil
+}
+
+func sessionBody(sessionID string) any {
+	return struct {
+		SessionID string `json:"sessionId"`
+	}{sessionID}
+}
@@ -0,0 +1,321 @@
+// Package client is a typed Go client for the media API served by the
+// media-lambda (cmd/api). It wraps every /api endpoint with request and
+// response types, retries transient failures, and provides WaitFor helpers
+// that poll an asynchronous job until it finishes.
+//
+// The base URL is the CloudFront distribution, not API Gateway: CloudFront
+// adds the origin-verify header the API requires (DDR-028). Authenticated
+// endpoints need a Cognito ID token, supplied with WithToken or
+// WithTokenSource.
+//
+//	c := client.New("https://d1234.cloudfront.net", client.WithToken(idToken))
+//	job, err := c.StartTriage(ctx, client.TriageStartRequest{SessionID: sid})
+//	results, err := c.WaitForTriage(ctx, sid, job.ID)
+//
+// See DDR-108: Typed Go Client for the Media API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// defaultTimeout is the HTTP client timeout for a single attempt.
+	defaultTimeout = 30 * time.Second
+
+	// defaultPollInterval is how often the WaitFor helpers poll job status.
+	defaultPollInterval = 3 * time.Second
+
+	// userAgent identifies Go clients in the API's request logs (DDR-062).
+	userAgent = "ai-social-media-helper-go-client"
+)
+
+// RetryPolicy controls how failed requests are retried. Requests are retried
+// on network errors and on 429, 502, 503, and 504 responses, with exponential
+// backoff between attempts. POST requests start jobs and are not idempotent,
+// so they are only retried on 429 and 503, which mean the request was not
+// processed.
+type RetryPolicy struct {
+	MaxAttempts    int           // total attempts including the first; 1 disables retries
+	InitialBackoff time.Duration // wait before the second attempt
+	MaxBackoff     time.Duration // cap on the wait between attempts
+}
+
+// DefaultRetryPolicy is used unless WithRetryPolicy is given.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+}
+
+// TokenSource returns the bearer token for a request. It is called before
+// every attempt so that a refreshed token is picked up.
+type TokenSource func(ctx context.Context) (string, error)
+
+// Client calls the media API. It is safe for concurrent use.
+type Client struct {
+	httpClient   *http.Client
+	baseURL      string
+	token        TokenSource
+	retry        RetryPolicy
+	pollInterval time.Duration
+	version      string
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithToken authenticates every request with a fixed Cognito ID token.
+func WithToken(token string) Option {
+	return WithTokenSource(func(context.Context) (string, error) { return token, nil })
+}
+
+// WithTokenSource authenticates requests with a token from ts.
+func WithTokenSource(ts TokenSource) Option {
+	return func(c *Client) { c.token = ts }
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) { c.retry = p }
+}
+
+// WithPollInterval sets how often the WaitFor helpers poll.
+func WithPollInterval(d time.Duration) Option {
+	return func(c *Client) { c.pollInterval = d }
+}
+
+// WithClientVersion sets the X-Client-Version header sent with every request
+// (DDR-062), e.g. the calling program's commit hash.
+func WithClientVersion(v string) Option {
+	return func(c *Client) { c.version = v }
+}
+
+// New creates a client for the API at baseURL.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		httpClient:   &http.Client{Timeout: defaultTimeout},
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		retry:        DefaultRetryPolicy,
+		pollInterval: defaultPollInterval,
+		version:      "dev",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError is a non-2xx response from the API.
+type APIError struct {
+	StatusCode int
+	Message    string // the "error" field of the response, or the raw body
+	AppVersion string // X-App-Version of the backend that answered (DDR-062)
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("media api: %d %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 from the API.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsConflict reports whether err is a 409 from the API, returned when a job
+// is in the wrong state for the request (e.g. resuming a job that is not paused).
+func IsConflict(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict
+}
+
+// --- Request plumbing ---
+
+// getAs sends a GET and returns the decoded JSON response.
+func getAs[T any](ctx context.Context, c *Client, path string, query url.Values) (*T, error) {
+	var out T
+	if err := c.getJSON(ctx, path, query, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// postAs sends body as JSON and returns the decoded JSON response.
+func postAs[T any](ctx context.Context, c *Client, path string, body any) (*T, error) {
+	var out T
+	if err := c.postJSON(ctx, path, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// getJSON sends a GET and decodes the JSON response into out.
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, out any) error {
+	return c.doJSON(ctx, http.MethodGet, path, query, nil, out)
+}
+
+// postJSON sends body as JSON and decodes the JSON response into out.
+func (c *Client) postJSON(ctx context.Context, path string, body, out any) error {
+	return c.doJSON(ctx, http.MethodPost, path, nil, body, out)
+}
+
+func (c *Client) doJSON(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("%s %s: encode request: %w", method, path, err)
+		}
+	}
+	resp, err := c.do(ctx, method, path, query, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	// CloudFront serves the SPA's index.html for paths it does not route to
+	// the API, so a 200 is not proof of a JSON answer.
+	if ct := resp.Header.Get("Content-Type"); !strings.Contains(ct, "application/json") {
+		return fmt.Errorf("%s %s: expected JSON, got %q (is the base URL the CloudFront domain?)", method, path, ct)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: decode response: %w", method, path, err)
+	}
+	return nil
+}
+
+// do sends a request, retrying per the client's RetryPolicy, and returns the
+// first 2xx response. Non-2xx responses are returned as *APIError.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, payload []byte) (*http.Response, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	attempts := max(c.retry.MaxAttempts, 1)
+	backoff := c.retry.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(ctx, method, u, payload)
+		retryable, wait := c.shouldRetry(method, resp, err)
+		if err == nil && resp.StatusCode < 300 {
+			return resp, nil
+		}
+		if err == nil {
+			err = readAPIError(resp)
+		}
+		if !retryable || attempt >= attempts || ctx.Err() != nil {
+			if attempt > 1 {
+				return nil, fmt.Errorf("%s %s: after %d attempts: %w", method, path, attempt, err)
+			}
+			return nil, fmt.Errorf("%s %s: %w", method, path, err)
+		}
+
+		if wait == 0 {
+			wait = backoff
+			backoff = min(backoff*2, c.retry.MaxBackoff)
+		}
+		log.Debug().Err(err).Str("method", method).Str("path", path).Int("attempt", attempt).Dur("wait", wait).Msg("Retrying media API request")
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%s %s: %w", method, path, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, u string, payload []byte) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("X-Client-Version", c.version)
+	if c.token != nil {
+		token, err := c.token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get token: %w", err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+	return c.httpClient.Do(req)
+}
+
+// shouldRetry reports whether a failed attempt may be retried and, for 429
+// and 503 responses with a Retry-After header, how long to wait.
+func (c *Client) shouldRetry(method string, resp *http.Response, err error) (bool, time.Duration) {
+	if err != nil {
+		var netErr net.Error
+		return method != http.MethodPost && errors.As(err, &netErr), 0
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			return true, min(time.Duration(secs)*time.Second, c.retry.MaxBackoff)
+		}
+		return true, 0
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return method != http.MethodPost, 0
+	}
+	return false, 0
+}
+
+func readAPIError(resp *http.Response) error {
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(data)),
+		AppVersion: resp.Header.Get("X-App-Version"),
+	}
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		apiErr.Message = body.Error
+	}
+	return apiErr
+}
+
+// jobPath builds /api/{prefix}/{id}/{action} with the job ID escaped.
+func jobPath(prefix, id, action string) string {
+	p := "/api/" + prefix + "/" + url.PathEscape(id)
+	if action != "" {
+		p += "/" + action
+	}
+	return p
+}
+
+func sessionQuery(sessionID string) url.Values {
+	return url.Values{"sessionId": {sessionID}}
+}
@@ -0,0 +1,205 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a Client for server with fast retries and polling.
+func newTestClient(server *httptest.Server) *Client {
+	return New(server.URL,
+		WithHTTPClient(server.Client()),
+		WithToken("id-token"),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}),
+		WithPollInterval(time.Millisecond),
+	)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestStartTriage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/triage/start" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer id-token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var req TriageStartRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.SessionID != "sess-1" || !req.DebugArtifacts {
+			t.Errorf("unexpected body: %+v", req)
+		}
+		writeJSON(w, http.StatusAccepted, map[string]string{"id": "triage-abc"})
+	}))
+	defer server.Close()
+
+	job, err := newTestClient(server).StartTriage(context.Background(), TriageStartRequest{SessionID: "sess-1", DebugArtifacts: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.ID != "triage-abc" {
+		t.Errorf("expected triage-abc, got %s", job.ID)
+	}
+}
+
+func TestGetRetriesTransientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			writeJSON(w, http.StatusBadGateway, map[string]string{"error": "bad gateway"})
+			return
+		}
+		if r.URL.Query().Get("sessionId") != "sess-1" {
+			t.Errorf("sessionId missing from query: %s", r.URL.RawQuery)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"id": "sel-1", "status": "complete"})
+	}))
+	defer server.Close()
+
+	res, err := newTestClient(server).SelectionResults(context.Background(), "sess-1", "sel-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != StatusComplete || calls.Load() != 2 {
+		t.Errorf("status %s after %d calls, want complete after 2", res.Status, calls.Load())
+	}
+}
+
+func TestPostNotRetriedAfterGatewayTimeout(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		writeJSON(w, http.StatusGatewayTimeout, map[string]string{"error": "timeout"})
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(server).StartSelection(context.Background(), SelectionStartRequest{SessionID: "sess-1"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504 APIError, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("POST sent %d times, want 1", calls.Load())
+	}
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-App-Version", "abc123")
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(server).Session(context.Background(), "missing")
+	if !IsNotFound(err) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	var apiErr *APIError
+	errors.As(err, &apiErr)
+	if apiErr.Message != "not found" || apiErr.AppVersion != "abc123" {
+		t.Errorf("unexpected APIError: %+v", apiErr)
+	}
+}
+
+func TestRejectsNonJSONResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<!DOCTYPE html>"))
+	}))
+	defer server.Close()
+
+	if _, err := newTestClient(server).Health(context.Background()); err == nil {
+		t.Fatal("expected an error for an HTML response")
+	}
+}
+
+func TestWaitForTriage(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := StatusProcessing
+		if calls.Add(1) >= 3 {
+			status = StatusComplete
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"id": "triage-1", "status": status,
+			"keep": []map[string]any{{"key": "sess-1/a.jpg", "saveable": true}}, "discard": []any{},
+		})
+	}))
+	defer server.Close()
+
+	res, err := newTestClient(server).WaitForTriage(context.Background(), "sess-1", "triage-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls.Load() != 3 || len(res.Keep) != 1 || res.Keep[0].Key != "sess-1/a.jpg" {
+		t.Errorf("unexpected result after %d polls: %+v", calls.Load(), res)
+	}
+}
+
+func TestWaitForTriageJobError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"id": "triage-1", "status": "error", "error": "no media"})
+	}))
+	defer server.Close()
+
+	res, err := newTestClient(server).WaitForTriage(context.Background(), "sess-1", "triage-1")
+	var jobErr *JobError
+	if !errors.As(err, &jobErr) || jobErr.Message != "no media" {
+		t.Fatalf("expected JobError, got %v", err)
+	}
+	if res == nil || res.Status != StatusError {
+		t.Errorf("expected final results alongside the error, got %+v", res)
+	}
+}
+
+func TestWaitForRespectsContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"id": "dl-1", "status": "processing"})
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := newTestClient(server).WaitForDownload(ctx, "sess-1", "dl-1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestJobDecodesPayload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/jobs/sel-1" || r.URL.Query().Get("sessionId") != "sess-1" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"id": "sel-1", "type": "selection", "status": StatusComplete,
+			"payload": map[string]any{"id": "sel-1", "status": StatusComplete, "selected": []map[string]any{{"key": "sess-1/a.jpg", "rank": 1}}},
+		})
+	}))
+	defer server.Close()
+
+	job, err := newTestClient(server).Job(context.Background(), "sess-1", "sel-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.Type != "selection" || job.Progress != nil {
+		t.Errorf("unexpected envelope: %+v", job)
+	}
+	var res SelectionResults
+	if err := job.Decode(&res); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if len(res.Selected) != 1 || res.Selected[0].Key != "sess-1/a.jpg" {
+		t.Errorf("unexpected payload: %+v", res)
+	}
+}
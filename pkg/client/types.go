@@ -0,0 +1,1406 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job statuses shared by the asynchronous pipelines.
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusPaused     = "paused" // enhancement only (DDR-095)
+	StatusComplete   = "complete"
+	StatusError      = "error"
+	StatusPublished  = "published" // publish only (DDR-040)
+	StatusScheduled  = "scheduled" // publish only (DDR-144)
+
+	// StatusPartiallyPublished is a cross-posting job that published to
+	// some of its platforms and failed on others (DDR-153).
+	StatusPartiallyPublished = "partially_published"
+
+	// StatusPartial is a triage job still analyzing whose results already
+	// hold the verdicts of its finished batches (DDR-175).
+	StatusPartial = "partial"
+
+	// Publish approval gate (DDR-121).
+	StatusAwaitingApproval = "awaiting_approval"
+	StatusRejected         = "rejected"
+)
+
+// JobStarted is the response from the start endpoints that only return a job ID.
+type JobStarted struct {
+	ID string `json:"id"`
+}
+
+// JobTelemetry is the Gemini, S3 and ffmpeg usage of a job so far, reported
+// by the triage, selection, download and description results.
+type JobTelemetry struct {
+	GeminiCalls     int64   `json:"geminiCalls"`
+	GeminiLatencyMs int64   `json:"geminiLatencyMs"`
+	S3Downloads     int64   `json:"s3Downloads"`
+	BytesDownloaded int64   `json:"bytesDownloaded"`
+	FFmpegSeconds   float64 `json:"ffmpegSeconds"`
+	// GeminiInputTokens is the prompt tokens Gemini billed (DDR-186).
+	GeminiInputTokens int64 `json:"geminiInputTokens,omitempty"`
+}
+
+// Health is the response from GET /api/health.
+type Health struct {
+	Status              string `json:"status"`
+	Service             string `json:"service"`
+	CommitHash          string `json:"commitHash"`
+	BuildTime           string `json:"buildTime"`
+	InstagramConfigured bool   `json:"instagramConfigured"`
+}
+
+// --- Uploads (DDR-054) ---
+
+// UploadURL is a presigned S3 PUT URL from GET /api/upload-url. Headers
+// must be sent with the PUT; they are set for encrypted sessions (DDR-164).
+type UploadURL struct {
+	UploadURL string            `json:"uploadUrl"`
+	Key       string            `json:"key"`
+	Headers   map[string]string `json:"headers,omitempty"`
+}
+
+// MultipartInitRequest is the body of POST /api/upload-multipart/init.
+type MultipartInitRequest struct {
+	SessionID   string `json:"sessionId"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	FileSize    int64  `json:"fileSize"`
+	ChunkSize   int64  `json:"chunkSize"`
+	// ProxyFilename uploads the original of a registered proxy (DDR-131).
+	ProxyFilename string `json:"proxyFilename,omitempty"`
+}
+
+// MultipartPartURL is a presigned URL for one part of a multipart upload.
+type MultipartPartURL struct {
+	PartNumber int32  `json:"partNumber"`
+	URL        string `json:"url"`
+}
+
+// MultipartUpload is the response from POST /api/upload-multipart/init.
+type MultipartUpload struct {
+	UploadID string             `json:"uploadId"`
+	Key      string             `json:"key"`
+	PartURLs []MultipartPartURL `json:"partUrls"`
+}
+
+// CompletedPart is an uploaded part and the ETag S3 returned for it.
+type CompletedPart struct {
+	PartNumber int32  `json:"partNumber"`
+	ETag       string `json:"etag"`
+}
+
+// UploadTypeManifest is the count and total size of one media type.
+type UploadTypeManifest struct {
+	Count           int     `json:"count"`
+	Bytes           int64   `json:"bytes"`
+	DurationSeconds float64 `json:"durationSeconds,omitempty"`
+}
+
+// UploadAdviceRequest is the body of POST /api/upload/advice (DDR-128).
+type UploadAdviceRequest struct {
+	Workflow              string             `json:"workflow,omitempty"`
+	Images                UploadTypeManifest `json:"images"`
+	Videos                UploadTypeManifest `json:"videos"`
+	ThroughputBytesPerSec float64            `json:"throughputBytesPerSec"`
+}
+
+// UploadTypeAdvice is the advice for one media type. MaxDimension 0 means
+// upload originals.
+type UploadTypeAdvice struct {
+	Downscale      bool  `json:"downscale"`
+	MaxDimension   int   `json:"maxDimension"`
+	OriginalBytes  int64 `json:"originalBytes"`
+	EstimatedBytes int64 `json:"estimatedBytes"`
+}
+
+// UploadAdvice is the response from POST /api/upload/advice.
+type UploadAdvice struct {
+	Recommendation     string           `json:"recommendation"`
+	Reason             string           `json:"reason"`
+	OriginalSeconds    int              `json:"originalSeconds"`
+	RecommendedSeconds int              `json:"recommendedSeconds"`
+	Images             UploadTypeAdvice `json:"images"`
+	Videos             UploadTypeAdvice `json:"videos"`
+}
+
+// --- Proxy uploads (DDR-131) ---
+
+// ProxyUploadRequest is the body of POST /api/upload/proxy. OriginalFilename
+// defaults to Filename.
+type ProxyUploadRequest struct {
+	SessionID           string `json:"sessionId"`
+	Filename            string `json:"filename"`
+	ContentType         string `json:"contentType"`
+	OriginalFilename    string `json:"originalFilename,omitempty"`
+	OriginalContentType string `json:"originalContentType"`
+	OriginalBytes       int64  `json:"originalBytes,omitempty"`
+}
+
+// ProxyUpload is the response from POST /api/upload/proxy.
+type ProxyUpload struct {
+	UploadURL   string            `json:"uploadUrl"`
+	Key         string            `json:"key"`
+	OriginalKey string            `json:"originalKey"`
+	Headers     map[string]string `json:"headers,omitempty"`
+}
+
+// MediaLink ties a proxy upload to its original. Status is "proxy" until the
+// original has been uploaded, then "original".
+type MediaLink struct {
+	Filename            string `json:"filename"`
+	ProxyKey            string `json:"proxyKey"`
+	OriginalKey         string `json:"originalKey"`
+	OriginalContentType string `json:"originalContentType"`
+	OriginalBytes       int64  `json:"originalBytes,omitempty"`
+	Status              string `json:"status"`
+}
+
+// MediaLinks is the response from GET /api/upload/links.
+type MediaLinks struct {
+	Links            []MediaLink `json:"links"`
+	PendingOriginals int         `json:"pendingOriginals"`
+}
+
+// --- Triage (DDR-061, DDR-067) ---
+
+// TriageInitRequest is the body of POST /api/triage/init.
+type TriageInitRequest struct {
+	SessionID         string `json:"sessionId"`
+	ExpectedFileCount int    `json:"expectedFileCount"`
+	Model             string `json:"model,omitempty"`
+	Thinking          string `json:"thinking,omitempty"` // DDR-155
+	NoCache           bool   `json:"noCache,omitempty"`  // skip cached verdicts (DDR-166)
+	// Temperature (0–2) and TopP (above 0, at most 1) override the model's
+	// sampling defaults (DDR-170).
+	Temperature *float32 `json:"temperature,omitempty"`
+	TopP        *float32 `json:"topP,omitempty"`
+	// Keyframes (2–16) judges every video from that many frames instead of
+	// sending the clip; 0 sends whole videos (DDR-186).
+	Keyframes      int  `json:"keyframes,omitempty"`
+	DebugArtifacts bool `json:"debugArtifacts,omitempty"` // DDR-106
+}
+
+// TriageStartRequest is the body of POST /api/triage/start.
+type TriageStartRequest struct {
+	SessionID      string `json:"sessionId"`
+	Model          string `json:"model,omitempty"`
+	Thinking       string `json:"thinking,omitempty"`       // DDR-155
+	NoCache        bool   `json:"noCache,omitempty"`        // skip cached verdicts (DDR-166)
+	DebugArtifacts bool   `json:"debugArtifacts,omitempty"` // DDR-106
+	// Temperature and TopP override the model's sampling defaults (DDR-170).
+	Temperature *float32 `json:"temperature,omitempty"`
+	TopP        *float32 `json:"topP,omitempty"`
+}
+
+// TriageJobRef identifies a triage job created by init or finalize.
+type TriageJobRef struct {
+	ID        string `json:"id,omitempty"`    // set by init
+	JobID     string `json:"jobId,omitempty"` // set by finalize
+	SessionID string `json:"sessionId"`
+}
+
+// TriageItem is a single triage verdict.
+type TriageItem struct {
+	Media        int    `json:"media"`
+	Filename     string `json:"filename"`
+	Key          string `json:"key"`
+	ProcessedKey string `json:"processedKey,omitempty"`
+	Saveable     bool   `json:"saveable"`
+	Reason       string `json:"reason"`
+	ThumbnailURL string `json:"thumbnailUrl"`
+	PreFiltered  bool   `json:"preFiltered,omitempty"` // discarded by the local quality check without an AI call
+	Sampled      bool   `json:"sampled,omitempty"`     // long video judged from sampled frames and an audio summary
+}
+
+// FileStatus is the per-file processing status reported while a session's
+// uploads are processed (DDR-061).
+type FileStatus struct {
+	Key          string `json:"key"`
+	Filename     string `json:"filename"`
+	Status       string `json:"status"`
+	Converted    bool   `json:"converted"`
+	ThumbnailURL string `json:"thumbnailUrl,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// TriageFileProgress is one file's stage in a running triage job:
+// "queued", "downloaded" or "analyzed".
+type TriageFileProgress struct {
+	Filename string `json:"filename"`
+	Status   string `json:"status"`
+}
+
+// TriageResults is the response from GET /api/triage/{id}/results.
+type TriageResults struct {
+	ID                string       `json:"id"`
+	Status            string       `json:"status"`
+	Phase             string       `json:"phase,omitempty"`
+	TotalFiles        int          `json:"totalFiles,omitempty"`
+	UploadedFiles     int          `json:"uploadedFiles,omitempty"`
+	FileStatuses      []FileStatus `json:"fileStatuses,omitempty"`
+	ExpectedFileCount int          `json:"expectedFileCount,omitempty"`
+	ProcessedCount    int          `json:"processedCount,omitempty"`
+	TriageBatch       int          `json:"triageBatch,omitempty"`
+	TriageBatchTotal  int          `json:"triageBatchTotal,omitempty"`
+	Keep              []TriageItem `json:"keep"`
+	Discard           []TriageItem `json:"discard"`
+	Error             string       `json:"error,omitempty"`
+	// FileProgress is each file's stage while the job is analyzing, and
+	// AnalyzedFiles the number whose verdict is known (DDR-174).
+	FileProgress  []TriageFileProgress `json:"fileProgress,omitempty"`
+	AnalyzedFiles int                  `json:"analyzedFiles,omitempty"`
+	// Partial is set while Keep and Discard hold the verdicts of the
+	// batches finished so far (DDR-175).
+	Partial bool `json:"partial,omitempty"`
+	// DuplicateSessions lists the caller's other sessions that probably hold
+	// the same trip; see MergeSession.
+	DuplicateSessions []DuplicateSession `json:"duplicateSessions,omitempty"`
+	Telemetry         *JobTelemetry      `json:"perJobTelemetry,omitempty"`
+	// Model and Thinking are the Gemini model and thinking setting the job
+	// ran with (DDR-155); Thinking is empty for the model's default.
+	Model    string `json:"model,omitempty"`
+	Thinking string `json:"thinking,omitempty"`
+	// Temperature and TopP are set when the job overrode them (DDR-170).
+	Temperature *float32 `json:"temperature,omitempty"`
+	TopP        *float32 `json:"topP,omitempty"`
+	// ModelsUsed lists the models that answered, which differ from Model
+	// when a fallback took over (DDR-169).
+	ModelsUsed []string `json:"modelsUsed,omitempty"`
+	// Keyframes is the frames per video the job asked for, and VideoStats
+	// how its videos were judged once complete (DDR-186).
+	Keyframes  int               `json:"keyframes,omitempty"`
+	VideoStats *TriageVideoStats `json:"videoStats,omitempty"`
+}
+
+// TriageVideoStats counts a triage job's videos by how Gemini saw them,
+// with the bytes uploaded and the bytes of frame sheets sent instead.
+type TriageVideoStats struct {
+	Videos      int   `json:"videos"`
+	Keyframed   int   `json:"keyframed,omitempty"`
+	Sampled     int   `json:"sampled,omitempty"`
+	Kept        int   `json:"kept"`
+	SourceBytes int64 `json:"sourceBytes"`
+	SheetBytes  int64 `json:"sheetBytes,omitempty"`
+}
+
+// DuplicateSession is a probable duplicate of a triaged session.
+type DuplicateSession struct {
+	SessionID string   `json:"sessionId"`
+	CreatedAt int64    `json:"createdAt"` // Unix seconds
+	Reasons   []string `json:"reasons"`
+}
+
+// TriageConfirmResult is the response from POST /api/triage/{id}/confirm.
+type TriageConfirmResult struct {
+	Deleted        int      `json:"deleted"`
+	Errors         []string `json:"errors"`
+	ReclaimedBytes int64    `json:"reclaimedBytes"`
+}
+
+// TriageLogEntry is one CloudWatch log line from the triage pipeline.
+type TriageLogEntry struct {
+	Timestamp int64  `json:"timestamp"` // Unix milliseconds
+	Message   string `json:"message"`
+}
+
+// TriageLogs is the response from GET /api/triage/{id}/logs. Pass NextSince
+// to the next call to continue where this one left off.
+type TriageLogs struct {
+	Entries   []TriageLogEntry `json:"entries"`
+	NextSince int64            `json:"nextSince"`
+}
+
+// --- Selection (DDR-030) ---
+
+// SelectionStartRequest is the body of POST /api/selection/start.
+type SelectionStartRequest struct {
+	SessionID      string `json:"sessionId"`
+	TripContext    string `json:"tripContext"`
+	Model          string `json:"model,omitempty"`
+	Thinking       string `json:"thinking,omitempty"`       // DDR-155
+	DebugArtifacts bool   `json:"debugArtifacts,omitempty"` // DDR-106
+	// Temperature and TopP override the model's sampling defaults (DDR-170).
+	Temperature *float32 `json:"temperature,omitempty"`
+	TopP        *float32 `json:"topP,omitempty"`
+}
+
+// SelectionRerunRequest is the body of POST /api/selection/{id}/rerun
+// (DDR-176). Every key must be one of the job's media. TripContext and the
+// model settings default to the job's.
+type SelectionRerunRequest struct {
+	SessionID      string   `json:"sessionId"`
+	LockedKeys     []string `json:"lockedKeys,omitempty"`
+	ExcludedKeys   []string `json:"excludedKeys,omitempty"`
+	TripContext    string   `json:"tripContext,omitempty"`
+	Model          string   `json:"model,omitempty"`
+	Thinking       string   `json:"thinking,omitempty"`
+	Temperature    *float32 `json:"temperature,omitempty"`
+	TopP           *float32 `json:"topP,omitempty"`
+	DebugArtifacts bool     `json:"debugArtifacts,omitempty"`
+}
+
+// SelectionRerunStarted is the response from POST /api/selection/{id}/rerun.
+type SelectionRerunStarted struct {
+	ID      string `json:"id"`
+	Version int    `json:"version"`
+}
+
+// SelectedItem is a media item the AI selected.
+type SelectedItem struct {
+	Rank           int    `json:"rank"`
+	Media          int    `json:"media"`
+	Filename       string `json:"filename"`
+	Key            string `json:"key"`
+	Type           string `json:"type"` // "Photo" or "Video"
+	Scene          string `json:"scene"`
+	Justification  string `json:"justification"`
+	ComparisonNote string `json:"comparisonNote,omitempty"`
+	ThumbnailURL   string `json:"thumbnailUrl"`
+	// Scores break the pick down by criterion (DDR-150); nil for items
+	// selected by older jobs.
+	Scores *SelectionScores `json:"scores,omitempty"`
+	// AltText describes a selected photo for screen readers (DDR-154).
+	AltText string `json:"altText,omitempty"`
+	// Highlights are Reels clips cut from a selected video, best first
+	// (DDR-187); FullImageURL presigns a clip's Key.
+	Highlights []VideoHighlight `json:"highlights,omitempty"`
+}
+
+// VideoHighlight is a 15–30 second Reels clip of a selected video. StartMs
+// and EndMs locate it in the original video.
+type VideoHighlight struct {
+	Key     string `json:"key"`
+	StartMs int64  `json:"startMs"`
+	EndMs   int64  `json:"endMs"`
+	Reason  string `json:"reason,omitempty"`
+	Size    int64  `json:"size,omitempty"`
+}
+
+// Criteria accepted by SelectionResultsSorted (DDR-150). CriterionOverall
+// is the sum of the four scores.
+const (
+	CriterionComposition      = "composition"
+	CriterionStoryValue       = "storyValue"
+	CriterionUniqueness       = "uniqueness"
+	CriterionTechnicalQuality = "technicalQuality"
+	CriterionOverall          = "overall"
+)
+
+// SelectionScores rates a selected item against each criterion, 1–10.
+type SelectionScores struct {
+	Composition      int `json:"composition"`
+	StoryValue       int `json:"storyValue"`
+	Uniqueness       int `json:"uniqueness"`
+	TechnicalQuality int `json:"technicalQuality"`
+}
+
+// ExcludedItem is a media item the AI excluded.
+type ExcludedItem struct {
+	Media        int    `json:"media"`
+	Filename     string `json:"filename"`
+	Key          string `json:"key"`
+	Reason       string `json:"reason"`
+	Category     string `json:"category"`
+	DuplicateOf  string `json:"duplicateOf,omitempty"`
+	ThumbnailURL string `json:"thumbnailUrl"`
+}
+
+// SceneGroup is a scene the AI detected.
+type SceneGroup struct {
+	Name      string           `json:"name"`
+	GPS       string           `json:"gps,omitempty"`
+	TimeRange string           `json:"timeRange,omitempty"`
+	Items     []SceneGroupItem `json:"items"`
+}
+
+// SceneGroupItem is a media item within a scene group.
+type SceneGroupItem struct {
+	Media        int    `json:"media"`
+	Filename     string `json:"filename"`
+	Key          string `json:"key"`
+	Type         string `json:"type"`
+	Selected     bool   `json:"selected"`
+	Description  string `json:"description"`
+	ThumbnailURL string `json:"thumbnailUrl"`
+}
+
+// SelectionResults is the response from GET /api/selection/{id}/results.
+type SelectionResults struct {
+	ID          string         `json:"id"`
+	Status      string         `json:"status"`
+	Selected    []SelectedItem `json:"selected"`
+	Excluded    []ExcludedItem `json:"excluded"`
+	SceneGroups []SceneGroup   `json:"sceneGroups"`
+	Error       string         `json:"error,omitempty"`
+	Telemetry   *JobTelemetry  `json:"perJobTelemetry,omitempty"`
+	// Unprocessed lists files the AI never judged (DDR-134).
+	Unprocessed []UnprocessedItem `json:"unprocessed,omitempty"`
+	// Model and Thinking are the Gemini model and thinking setting the job
+	// ran with (DDR-155); Thinking is empty for the model's default.
+	Model    string `json:"model,omitempty"`
+	Thinking string `json:"thinking,omitempty"`
+	// Temperature and TopP are set when the job overrode them (DDR-170).
+	Temperature *float32 `json:"temperature,omitempty"`
+	TopP        *float32 `json:"topP,omitempty"`
+	// ModelsUsed lists the models that answered, which differ from Model
+	// when a fallback took over (DDR-169).
+	ModelsUsed []string `json:"modelsUsed,omitempty"`
+	// Changes compares the job with the session's previous completed
+	// selection, when there is one (DDR-158).
+	Changes *SelectionDiff `json:"changes,omitempty"`
+	// Version counts the job's revisions, from 1 for an original job.
+	// A re-run names the job it revises in RerunOf and lists the user's
+	// constraints (DDR-176).
+	Version      int      `json:"version,omitempty"`
+	RerunOf      string   `json:"rerunOf,omitempty"`
+	LockedKeys   []string `json:"lockedKeys,omitempty"`
+	ExcludedKeys []string `json:"excludedKeys,omitempty"`
+	// FeedbackHistory lists the feedback rounds that revised the job,
+	// oldest first (DDR-177).
+	FeedbackHistory []SelectionFeedbackEntry `json:"feedbackHistory,omitempty"`
+}
+
+// SelectionFeedbackEntry is one round of feedback on a selection (DDR-177).
+type SelectionFeedbackEntry struct {
+	UserFeedback string `json:"userFeedback"`
+	CreatedAt    int64  `json:"createdAt"` // Unix seconds
+}
+
+// SelectionDiff is what changed since the session's previous selection.
+type SelectionDiff struct {
+	PreviousJobID string            `json:"previousJobId"`
+	Added         []SelectionChange `json:"added,omitempty"`
+	Dropped       []SelectionChange `json:"dropped,omitempty"`
+	Moved         []SelectionChange `json:"moved,omitempty"`
+	Unchanged     int               `json:"unchanged"`
+}
+
+// SelectionChange is one item of a SelectionDiff. Rank is 0 for a dropped
+// item, and PreviousRank 0 for an added one.
+type SelectionChange struct {
+	Key          string `json:"key"`
+	Filename     string `json:"filename"`
+	Rank         int    `json:"rank,omitempty"`
+	PreviousRank int    `json:"previousRank,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+// UnprocessedItem is a file left out of selection, with the reason.
+type UnprocessedItem struct {
+	Filename string `json:"filename"`
+	Key      string `json:"key"`
+	Reason   string `json:"reason"`
+}
+
+// OverrideAction is the body of POST /api/overrides/{sessionId}.
+type OverrideAction struct {
+	Action    string `json:"action"` // "added_back" or "removed"
+	MediaKey  string `json:"mediaKey"`
+	Filename  string `json:"filename"`
+	MediaType string `json:"mediaType"`
+	AIReason  string `json:"aiReason,omitempty"`
+}
+
+// OverrideItem is one item in a finalized override delta.
+type OverrideItem struct {
+	MediaKey string `json:"mediaKey"`
+	Filename string `json:"filename"`
+	AIReason string `json:"aiReason,omitempty"`
+}
+
+// OverrideDelta is the body of POST /api/overrides/{sessionId}/finalize.
+type OverrideDelta struct {
+	Added   []OverrideItem `json:"added"`
+	Removed []OverrideItem `json:"removed"`
+}
+
+// --- Enhancement (DDR-031, DDR-095) ---
+
+// EnhancementStartRequest is the body of POST /api/enhance/start. Send Keys
+// for default settings or Items for per-item settings, not both.
+type EnhancementStartRequest struct {
+	SessionID      string                   `json:"sessionId"`
+	Keys           []string                 `json:"keys,omitempty"`
+	Items          []EnhancementItemOptions `json:"items,omitempty"`          // DDR-143
+	DebugArtifacts bool                     `json:"debugArtifacts,omitempty"` // DDR-106
+	Watermark      bool                     `json:"watermark,omitempty"`      // DDR-133
+	// SubjectPreset tunes the pipeline for the job's photos: "portrait",
+	// "landscape", "food" or "night" (DDR-180).
+	SubjectPreset string `json:"subjectPreset,omitempty"`
+	// UpscaleBelow upscales the result of each photo whose long edge is
+	// under this many pixels (512–4096) with Imagen; 0 is off (DDR-184).
+	UpscaleBelow int `json:"upscaleBelow,omitempty"`
+	// Model, Thinking, Temperature and TopP override the deployment's
+	// settings for the photo analysis calls (DDR-170).
+	Model       string   `json:"model,omitempty"`
+	Thinking    string   `json:"thinking,omitempty"`
+	Temperature *float32 `json:"temperature,omitempty"`
+	TopP        *float32 `json:"topP,omitempty"`
+}
+
+// EnhancementItemOptions is one item's settings when starting an enhancement
+// (DDR-143). Skip leaves the item unchanged. Preset is "full" (default),
+// "global" (no Imagen region edits), "light" (one global pass) or "privacy"
+// (full, then bystanders blurred; DDR-185); photos only. Items with a higher
+// Priority render first.
+type EnhancementItemOptions struct {
+	Key      string `json:"key"`
+	Skip     bool   `json:"skip,omitempty"`
+	Preset   string `json:"preset,omitempty"`
+	Priority int    `json:"priority,omitempty"`
+}
+
+// EnhancementItem is one photo's enhancement state.
+type EnhancementItem struct {
+	Key                string              `json:"key"`
+	Filename           string              `json:"filename"`
+	Phase              string              `json:"phase"`
+	OriginalKey        string              `json:"originalKey"`
+	EnhancedKey        string              `json:"enhancedKey,omitempty"`
+	OriginalThumbKey   string              `json:"originalThumbKey,omitempty"`
+	EnhancedThumbKey   string              `json:"enhancedThumbKey,omitempty"`
+	Phase1Text         string              `json:"phase1Text,omitempty"`
+	Analysis           *AnalysisResult     `json:"analysis,omitempty"`
+	ImagenEdits        int                 `json:"imagenEdits"`
+	FeedbackHistory    []FeedbackEntry     `json:"feedbackHistory,omitempty"`
+	Error              string              `json:"error,omitempty"`
+	LegibilityWarnings []LegibilityWarning `json:"legibilityWarnings,omitempty"` // DDR-104
+	Preset             string              `json:"preset,omitempty"`             // DDR-143
+
+	// Every result the item has had and the one it shows (DDR-181).
+	Versions       []EnhancementVersion `json:"versions,omitempty"`
+	CurrentVersion int                  `json:"currentVersion,omitempty"`
+
+	// Upscale is set when the result was upscaled (DDR-184).
+	Upscale *UpscaleInfo `json:"upscale,omitempty"`
+
+	// Redactions lists the bystanders the privacy preset hid in the current
+	// version (DDR-185). RedactionError is set on a failed item whose
+	// bystanders could not be detected.
+	Redactions     []RedactRegion `json:"redactions,omitempty"`
+	RedactionError string         `json:"redactionError,omitempty"`
+}
+
+// UpscaleInfo records a photo's super-resolution step (DDR-184): the
+// original's dimensions, the Imagen factor and the saved result's
+// dimensions.
+type UpscaleInfo struct {
+	Factor       int `json:"factor"`
+	BeforeWidth  int `json:"beforeWidth"`
+	BeforeHeight int `json:"beforeHeight"`
+	AfterWidth   int `json:"afterWidth"`
+	AfterHeight  int `json:"afterHeight"`
+}
+
+// EnhancementVersion is one result of enhancing a photo (DDR-181). Version 1
+// is the pipeline's result; each feedback round adds the next.
+type EnhancementVersion struct {
+	Version            int                 `json:"version"`
+	EnhancedKey        string              `json:"enhancedKey"`
+	EnhancedThumbKey   string              `json:"enhancedThumbKey,omitempty"`
+	CleanKey           string              `json:"cleanKey,omitempty"`
+	Feedback           string              `json:"feedback,omitempty"`
+	LegibilityWarnings []LegibilityWarning `json:"legibilityWarnings,omitempty"`
+	CreatedAt          int64               `json:"createdAt,omitempty"` // Unix seconds
+	// PreviewURL is a presigned URL of the full image, set by
+	// EnhancementVersions.
+	PreviewURL string `json:"previewUrl,omitempty"`
+
+	// Adjustments summarizes the local adjustments that produced it
+	// (DDR-183).
+	Adjustments string `json:"adjustments,omitempty"`
+
+	// Redactions lists the bystanders hidden in this version (DDR-185).
+	Redactions []RedactRegion `json:"redactions,omitempty"`
+}
+
+// EnhancementVersions is the response from GET /api/enhance/{id}/versions.
+type EnhancementVersions struct {
+	Key            string               `json:"key"`
+	CurrentVersion int                  `json:"currentVersion"`
+	Versions       []EnhancementVersion `json:"versions"`
+}
+
+// Adjustments are deterministic edits applied without Gemini or Imagen
+// (DDR-183): rotate, then crop, then tone. Brightness, Contrast and
+// Saturation range from -1 to 1; Rotate is clockwise degrees (0, 90, 180
+// or 270).
+type Adjustments struct {
+	Brightness float64     `json:"brightness,omitempty"`
+	Contrast   float64     `json:"contrast,omitempty"`
+	Saturation float64     `json:"saturation,omitempty"`
+	Rotate     int         `json:"rotate,omitempty"`
+	Crop       *AdjustCrop `json:"crop,omitempty"`
+}
+
+// AdjustCrop is a crop rectangle in displayed pixels of the rotated photo.
+type AdjustCrop struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// AdjustStarted is the response from POST /api/media/adjust. Version is
+// the version the adjusted photo will be saved as.
+type AdjustStarted struct {
+	Status  string `json:"status"`
+	Key     string `json:"key"`
+	Version int    `json:"version"`
+}
+
+// AnalysisResult is the phase 2 analysis of further improvements.
+type AnalysisResult struct {
+	OverallAssessment     string            `json:"overallAssessment"`
+	RemainingImprovements []ImprovementItem `json:"remainingImprovements,omitempty"`
+	ProfessionalScore     float64           `json:"professionalScore"`
+	TargetScore           float64           `json:"targetScore"`
+	NoFurtherEditsNeeded  bool              `json:"noFurtherEditsNeeded"`
+}
+
+// ImprovementItem is a single improvement recommendation.
+type ImprovementItem struct {
+	Type            string `json:"type"`
+	Description     string `json:"description"`
+	Region          string `json:"region"`
+	Impact          string `json:"impact"`
+	ImagenSuitable  bool   `json:"imagenSuitable"`
+	EditInstruction string `json:"editInstruction"`
+}
+
+// FeedbackEntry is one round of user feedback on an enhanced photo.
+type FeedbackEntry struct {
+	UserFeedback  string `json:"userFeedback"`
+	ModelResponse string `json:"modelResponse"`
+	Method        string `json:"method"`
+	Success       bool   `json:"success"`
+}
+
+// LegibilityWarning flags text likely to become unreadable (DDR-104).
+type LegibilityWarning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// EnhancementResults is the response from GET /api/enhance/{id}/results.
+type EnhancementResults struct {
+	ID             string            `json:"id"`
+	Status         string            `json:"status"`
+	Items          []EnhancementItem `json:"items"`
+	TotalCount     int               `json:"totalCount"`
+	CompletedCount int               `json:"completedCount"`
+	Error          string            `json:"error,omitempty"`
+	PausedAt       int64             `json:"pausedAt,omitempty"` // Unix seconds
+	// The job's model settings, set when they were overridden (DDR-170).
+	Model       string   `json:"model,omitempty"`
+	Thinking    string   `json:"thinking,omitempty"`
+	Temperature *float32 `json:"temperature,omitempty"`
+	TopP        *float32 `json:"topP,omitempty"`
+	// SubjectPreset is the job's subject preset, when one was set (DDR-180).
+	SubjectPreset string `json:"subjectPreset,omitempty"`
+	// UpscaleBelow is the job's upscale threshold, when one was set
+	// (DDR-184).
+	UpscaleBelow int           `json:"upscaleBelow,omitempty"`
+	Telemetry    *JobTelemetry `json:"perJobTelemetry,omitempty"`
+}
+
+// FeedbackBatchRequest is the body of POST /api/enhance/{id}/feedback-batch
+// (DDR-182).
+type FeedbackBatchRequest struct {
+	SessionID string   `json:"sessionId"`
+	Keys      []string `json:"keys"`
+	Feedback  string   `json:"feedback"`
+}
+
+// FeedbackBatch is one feedback instruction applied to several photos
+// (DDR-182). Status is processing until every photo's round has finished,
+// error if all of them failed, and complete otherwise.
+type FeedbackBatch struct {
+	ID             string              `json:"id"`
+	JobID          string              `json:"jobId"`
+	Feedback       string              `json:"feedback"`
+	Status         string              `json:"status"`
+	Items          []FeedbackBatchItem `json:"items"`
+	CompletedCount int                 `json:"completedCount"`
+	CreatedAt      int64               `json:"createdAt"` // Unix seconds
+}
+
+// FeedbackBatchItem is one photo's round in a feedback batch. Version is the
+// enhancement version it produced (DDR-181).
+type FeedbackBatchItem struct {
+	Key     string `json:"key"`
+	Status  string `json:"status"`
+	Version int    `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// EnhancementControl is the response from the pause and resume endpoints.
+type EnhancementControl struct {
+	ID           string `json:"id"`
+	Status       string `json:"status"`
+	PendingCount int    `json:"pendingCount,omitempty"` // resume only
+}
+
+// --- Download (DDR-034, DDR-097, DDR-101) ---
+
+// DownloadStartRequest is the body of POST /api/download/start.
+type DownloadStartRequest struct {
+	SessionID  string   `json:"sessionId"`
+	Keys       []string `json:"keys"`
+	GroupLabel string   `json:"groupLabel"`
+	Prewarm    bool     `json:"prewarm,omitempty"`
+}
+
+// DownloadStarted is the response from POST /api/download/start.
+type DownloadStarted struct {
+	ID     string `json:"id"`
+	Reused bool   `json:"reused,omitempty"`
+}
+
+// DownloadBundle is one ZIP bundle in a download job.
+type DownloadBundle struct {
+	Type         string   `json:"type"` // "images", "raw" or "videos"
+	Name         string   `json:"name"`
+	ZipKey       string   `json:"zipKey,omitempty"`
+	DownloadURL  string   `json:"downloadUrl,omitempty"`
+	FileCount    int      `json:"fileCount"`
+	TotalSize    int64    `json:"totalSize"`
+	ZipSize      int64    `json:"zipSize,omitempty"`
+	SHA256       string   `json:"sha256,omitempty"`
+	ETag         string   `json:"etag,omitempty"`
+	Status       string   `json:"status"`
+	Error        string   `json:"error,omitempty"`
+	OmittedFiles []string `json:"omittedFiles,omitempty"`
+}
+
+// DownloadResults is the response from GET /api/download/{id}/results.
+type DownloadResults struct {
+	ID      string           `json:"id"`
+	Status  string           `json:"status"`
+	Bundles []DownloadBundle `json:"bundles"`
+	Error   string           `json:"error,omitempty"`
+	RetryOf string           `json:"retryOf,omitempty"`
+	Prewarm bool             `json:"prewarm,omitempty"`
+	// RestoringKeys lists archived files still being restored while Status
+	// is "restoring" (DDR-115).
+	RestoringKeys []string      `json:"restoringKeys,omitempty"`
+	Telemetry     *JobTelemetry `json:"perJobTelemetry,omitempty"`
+}
+
+// DownloadRetry is the response from POST /api/download/{id}/retry-omitted.
+type DownloadRetry struct {
+	ID       string `json:"id"`
+	RetryOf  string `json:"retryOf"`
+	KeyCount int    `json:"keyCount"`
+}
+
+// --- Description (DDR-036) ---
+
+// DescriptionRequest is the body of POST /api/description/generate.
+type DescriptionRequest struct {
+	SessionID   string   `json:"sessionId"`
+	Keys        []string `json:"keys"`
+	GroupLabel  string   `json:"groupLabel"`
+	TripContext string   `json:"tripContext"`
+	TemplateID  string   `json:"templateId,omitempty"` // caption format to follow (DDR-122)
+	NoCache     bool     `json:"noCache,omitempty"`    // skip a cached caption (DDR-166)
+	// GroupID names a post group whose keys and name stand in for Keys and
+	// GroupLabel when those are empty (DDR-178).
+	GroupID string `json:"groupId,omitempty"`
+	// Model, Thinking, Temperature and TopP override the deployment's
+	// caption settings (DDR-170).
+	Model       string   `json:"model,omitempty"`
+	Thinking    string   `json:"thinking,omitempty"`
+	Temperature *float32 `json:"temperature,omitempty"`
+	TopP        *float32 `json:"topP,omitempty"`
+}
+
+// DescriptionResults is the response from GET /api/description/{id}/results.
+type DescriptionResults struct {
+	ID            string        `json:"id"`
+	Status        string        `json:"status"`
+	Caption       string        `json:"caption,omitempty"`
+	Hashtags      []string      `json:"hashtags,omitempty"`
+	LocationTag   string        `json:"locationTag,omitempty"`
+	AltText       []string      `json:"altText,omitempty"` // per item, aligned with the job's keys (DDR-151)
+	Model         string        `json:"model,omitempty"`   // set when overridden (DDR-170)
+	Thinking      string        `json:"thinking,omitempty"`
+	Temperature   *float32      `json:"temperature,omitempty"`
+	TopP          *float32      `json:"topP,omitempty"`
+	ModelsUsed    []string      `json:"modelsUsed,omitempty"` // Gemini models that answered the latest round (DDR-169)
+	FeedbackRound int           `json:"feedbackRound"`
+	Error         string        `json:"error,omitempty"`
+	Telemetry     *JobTelemetry `json:"perJobTelemetry,omitempty"`
+}
+
+// --- FB prep ---
+
+// FBPrepStarted is the response from POST /api/fb-prep/start.
+type FBPrepStarted struct {
+	ID        string `json:"id"`
+	SessionID string `json:"session_id"`
+	Status    string `json:"status"`
+}
+
+// FBPrepItem is one media item's Facebook prep output.
+type FBPrepItem struct {
+	ItemIndex          int    `json:"item_index"`
+	S3Key              string `json:"s3_key"`
+	Caption            string `json:"caption"`
+	LocationTag        string `json:"location_tag"`
+	DateTimestamp      string `json:"date_timestamp"`
+	LocationConfidence string `json:"location_confidence"`
+	Error              string `json:"error,omitempty"`
+}
+
+// FBPrepResults is the response from GET /api/fb-prep/{id}/results.
+type FBPrepResults struct {
+	ID             string        `json:"id"`
+	Status         string        `json:"status"`
+	CreatedAt      string        `json:"createdAt,omitempty"`
+	InputTokens    int           `json:"inputTokens,omitempty"`
+	OutputTokens   int           `json:"outputTokens,omitempty"`
+	Items          []FBPrepItem  `json:"items,omitempty"`
+	Error          string        `json:"error,omitempty"`
+	TotalCount     int           `json:"totalCount"`
+	CompletedCount int           `json:"completedCount"`
+	Stage          int           `json:"stage"`
+	Telemetry      *JobTelemetry `json:"perJobTelemetry,omitempty"`
+}
+
+// --- Publish (DDR-040) ---
+
+// PublishRequest is the body of POST /api/publish/start.
+type PublishRequest struct {
+	SessionID string   `json:"sessionId"`
+	GroupID   string   `json:"groupId"`
+	Keys      []string `json:"keys"`
+	Caption   string   `json:"caption"`
+	Hashtags  []string `json:"hashtags"`
+
+	// RequireApproval holds the post before it goes live until a second
+	// person approves it (DDR-121).
+	RequireApproval bool `json:"requireApproval,omitempty"`
+	// Watermark stamps the caller's watermark on every photo (DDR-133).
+	Watermark bool `json:"watermark,omitempty"`
+	// LocationID tags the post with a location from SearchInstagramLocations
+	// (DDR-138).
+	LocationID string `json:"locationId,omitempty"`
+	// DryRun runs the publish pipeline against a simulated Instagram and
+	// posts nothing (DDR-139).
+	DryRun bool `json:"dryRun,omitempty"`
+	// UserTags tag accounts on each item, in the order of Keys (DDR-141).
+	// Videos in a carousel cannot be tagged.
+	UserTags [][]UserTag `json:"userTags,omitempty"`
+	// Collaborators are usernames invited to co-author the post, at most 3.
+	Collaborators []string `json:"collaborators,omitempty"`
+	// PublishAt schedules the post instead of publishing now (DDR-144). It
+	// must fall before the session's media expire and cannot be combined
+	// with RequireApproval.
+	PublishAt *time.Time `json:"publishAt,omitempty"`
+	// Platform is PlatformInstagram (the default), PlatformFacebook
+	// (DDR-147) or PlatformMastodon (DDR-151). Facebook Page posts take no
+	// UserTags or Collaborators, and albums hold photos only. Mastodon posts
+	// take no LocationID either, and hold up to 4 photos or one video.
+	Platform string `json:"platform,omitempty"`
+	// BrandKit ends the caption with the caller's brand kit sign-off and
+	// uses its hashtags when Hashtags is empty (DDR-149).
+	BrandKit bool `json:"brandKit,omitempty"`
+	// AltText describes each item for screen readers, in the order of Keys
+	// (DDR-151). Every platform receives it (DDR-154).
+	AltText []string `json:"altText,omitempty"`
+	// DescriptionJobID takes the alt-text that description job generated
+	// when AltText is empty.
+	DescriptionJobID string `json:"descriptionJobId,omitempty"`
+	// SelectionJobID fills the photos still without alt-text from that
+	// selection job's picks, matched by file name (DDR-154).
+	SelectionJobID string `json:"selectionJobId,omitempty"`
+	// Platforms cross-posts the items to several platforms in one job, in
+	// place of Platform (DDR-153). Each setting above goes to the platforms
+	// that support it.
+	Platforms []string `json:"platforms,omitempty"`
+	// Captions holds caption variants by platform; platforms without one
+	// use Caption. Hashtags and the brand kit sign-off apply to each.
+	Captions map[string]string `json:"captions,omitempty"`
+	// HashtagsInComment posts the hashtags as the first comment of the
+	// Instagram post instead of in its caption (DDR-163). It needs
+	// Instagram among the platforms and at least one hashtag.
+	HashtagsInComment bool `json:"hashtagsInComment,omitempty"`
+	// UseSuggestedOrder publishes the items in the carousel order Gemini
+	// suggested for the stored post group GroupID (DDR-179). The suggestion
+	// must be complete and cover exactly Keys.
+	UseSuggestedOrder bool `json:"useSuggestedOrder,omitempty"`
+}
+
+// Publish platforms (DDR-147, DDR-151).
+const (
+	PlatformInstagram = "instagram"
+	PlatformFacebook  = "facebook"
+	PlatformMastodon  = "mastodon"
+)
+
+// UserTag tags an Instagram account on a photo. X and Y place the tag as
+// fractions of the width and height from the top-left corner.
+type UserTag struct {
+	Username string  `json:"username"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+}
+
+// InstagramLocation is a taggable place returned by SearchInstagramLocations.
+type InstagramLocation struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Street    string  `json:"street,omitempty"`
+	City      string  `json:"city,omitempty"`
+	Country   string  `json:"country,omitempty"`
+	Latitude  float64 `json:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
+}
+
+// PublishStarted is the response from POST /api/publish/start. ApprovalToken
+// is set only when approval was required; it is not returned again.
+type PublishStarted struct {
+	ID            string `json:"id"`
+	ApprovalToken string `json:"approvalToken,omitempty"`
+	PublishAt     int64  `json:"publishAt,omitempty"` // Unix seconds; set when scheduled
+}
+
+// ScheduledPost is a publish job waiting for its publish time (DDR-144).
+// Status is "scheduled", "dispatched", "cancelled" or "error"; once
+// dispatched, follow the job with PublishStatus.
+type ScheduledPost struct {
+	ID           string `json:"id"`
+	SessionID    string `json:"sessionId"`
+	GroupID      string `json:"groupId"`
+	PublishAt    int64  `json:"publishAt"` // Unix seconds
+	Status       string `json:"status"`
+	Caption      string `json:"caption"`
+	KeyCount     int    `json:"keyCount"`
+	DryRun       bool   `json:"dryRun,omitempty"`
+	Attempts     int    `json:"attempts,omitempty"`
+	CreatedAt    int64  `json:"createdAt"`              // Unix seconds
+	DispatchedAt int64  `json:"dispatchedAt,omitempty"` // Unix seconds
+	Error        string `json:"error,omitempty"`
+}
+
+// PostInsights is the insights time series of a published post (DDR-146).
+// Samples are hourly for the first two days, then daily for 30 days.
+type PostInsights struct {
+	Post struct {
+		MediaID      string `json:"mediaId"`
+		SessionID    string `json:"sessionId"`
+		JobID        string `json:"jobId"`
+		PublishedAt  int64  `json:"publishedAt"`            // Unix seconds
+		LastPolledAt int64  `json:"lastPolledAt,omitempty"` // Unix seconds
+		LastError    string `json:"lastError,omitempty"`
+	} `json:"post"`
+	Samples []InsightsSample `json:"samples"`
+	Latest  *InsightsSample  `json:"latest,omitempty"`
+}
+
+// InsightsSample is one reading of a post's lifetime insights.
+type InsightsSample struct {
+	At          int64 `json:"at"` // Unix seconds
+	Reach       int64 `json:"reach"`
+	Impressions int64 `json:"impressions"`
+	Likes       int64 `json:"likes"`
+	Comments    int64 `json:"comments"`
+	Saves       int64 `json:"saves"`
+}
+
+// PublishApproval is the sign-off state of a gated publish job.
+type PublishApproval struct {
+	Status      string   `json:"status"` // "pending", "approved" or "rejected"
+	RequestedBy string   `json:"requestedBy,omitempty"`
+	RequestedAt int64    `json:"requestedAt"`
+	Caption     string   `json:"caption"`
+	Keys        []string `json:"keys"`
+	DecidedBy   string   `json:"decidedBy,omitempty"`
+	DecidedAt   int64    `json:"decidedAt,omitempty"`
+	Via         string   `json:"via,omitempty"`
+}
+
+// PublishStatus is the response from GET /api/publish/{id}/status.
+type PublishStatus struct {
+	ID       string `json:"id"`
+	Status   string `json:"status"`
+	Phase    string `json:"phase"`
+	Progress struct {
+		Completed int `json:"completed"`
+		Total     int `json:"total"`
+	} `json:"progress"`
+	InstagramPostID string           `json:"instagramPostId,omitempty"`
+	FacebookPostID  string           `json:"facebookPostId,omitempty"` // DDR-147
+	MastodonPostID  string           `json:"mastodonPostId,omitempty"` // DDR-151
+	Error           string           `json:"error,omitempty"`
+	Approval        *PublishApproval `json:"approval,omitempty"`
+	Platforms       []PlatformStatus `json:"platforms,omitempty"` // DDR-153
+	// FirstCommentID is the Instagram comment holding the hashtags, and
+	// FirstCommentError why it could not be posted (DDR-163).
+	FirstCommentID    string        `json:"firstCommentId,omitempty"`
+	FirstCommentError string        `json:"firstCommentError,omitempty"`
+	Telemetry         *JobTelemetry `json:"perJobTelemetry,omitempty"`
+}
+
+// PlatformStatus is one platform's outcome in a publish job. Status is
+// "pending", "published" or "error".
+type PlatformStatus struct {
+	Platform string `json:"platform"`
+	Status   string `json:"status"`
+	PostID   string `json:"postId,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// --- Mood variants (DDR-102) ---
+
+// MoodVariantsStarted is the response from POST /api/mood-variants/start.
+type MoodVariantsStarted struct {
+	ID        string `json:"id"`
+	Remaining int    `json:"remaining"` // variants left in today's budget
+}
+
+// MoodVariant is one stylized rendition of a cover image.
+type MoodVariant struct {
+	Style        string `json:"style"`
+	Key          string `json:"key,omitempty"`
+	ThumbnailURL string `json:"thumbnailUrl,omitempty"`
+	AIGenerated  bool   `json:"aiGenerated"`
+	Error        string `json:"error,omitempty"`
+}
+
+// MoodVariantResults is the response from GET /api/mood-variants/{id}/results.
+type MoodVariantResults struct {
+	ID        string        `json:"id"`
+	Status    string        `json:"status"`
+	SourceKey string        `json:"sourceKey"`
+	Variants  []MoodVariant `json:"variants"`
+	Error     string        `json:"error,omitempty"`
+	Telemetry *JobTelemetry `json:"perJobTelemetry,omitempty"`
+}
+
+// --- Crop suggestions (DDR-130) ---
+
+// CropRect is a rectangle in a photo's displayed pixels. Aspect is set on
+// suggested crops ("1:1", "4:5", "9:16"); Label on a detected subject.
+type CropRect struct {
+	Aspect string `json:"aspect,omitempty"`
+	Label  string `json:"label,omitempty"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	W      int    `json:"w"`
+	H      int    `json:"h"`
+}
+
+// CropSuggestion is the set of proposed crops for one photo.
+type CropSuggestion struct {
+	Key     string     `json:"key"`
+	Width   int        `json:"width,omitempty"`
+	Height  int        `json:"height,omitempty"`
+	Subject *CropRect  `json:"subject,omitempty"`
+	Crops   []CropRect `json:"crops,omitempty"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// CropResults is the response from GET /api/crop/{id}/results.
+type CropResults struct {
+	ID     string           `json:"id"`
+	Status string           `json:"status"`
+	Items  []CropSuggestion `json:"items"`
+	Error  string           `json:"error,omitempty"`
+}
+
+// CroppedMedia is the response from POST /api/media/crop.
+type CroppedMedia struct {
+	Key          string `json:"key"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	ThumbnailURL string `json:"thumbnailUrl,omitempty"`
+}
+
+// --- Privacy redaction (DDR-185) ---
+
+// RedactRegion is a bystander's face ("face") or a licence plate ("plate")
+// that was hidden, in displayed pixels of the redacted photo.
+type RedactRegion struct {
+	Kind  string `json:"kind"`
+	Label string `json:"label,omitempty"`
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+	W     int    `json:"w"`
+	H     int    `json:"h"`
+}
+
+// RedactResults is the response from GET /api/media/redact/{id}/results.
+// Applied is "blur" when inpainting fell back to blurring. A complete job
+// with no regions found nothing to hide and has no RedactedKey.
+type RedactResults struct {
+	ID               string         `json:"id"`
+	Status           string         `json:"status"`
+	Key              string         `json:"key"`
+	Mode             string         `json:"mode"`
+	Applied          string         `json:"applied,omitempty"`
+	RedactedKey      string         `json:"redactedKey,omitempty"`
+	RedactedThumbKey string         `json:"redactedThumbKey,omitempty"`
+	Regions          []RedactRegion `json:"regions"`
+	Error            string         `json:"error,omitempty"`
+}
+
+// --- Sessions (DDR-037, DDR-098) ---
+
+// SessionJob is the status of one job in a session summary.
+type SessionJob struct {
+	ID         string `json:"id"`
+	Type       string `json:"type,omitempty"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	RetryCount int    `json:"retryCount,omitempty"`
+}
+
+// Session is a session summary from GET /api/sessions and /api/sessions/{id}.
+type Session struct {
+	ID          string       `json:"id"`
+	CreatedAt   int64        `json:"createdAt"` // Unix seconds
+	Status      string       `json:"status"`
+	TripContext string       `json:"tripContext,omitempty"`
+	Encrypted   bool         `json:"encrypted,omitempty"` // DDR-164
+	FileCount   int          `json:"fileCount"`
+	GroupCount  int          `json:"groupCount"`
+	Jobs        []SessionJob `json:"jobs"`
+}
+
+// SessionDeleted is the response from DELETE /api/sessions/{id}.
+type SessionDeleted struct {
+	ID             string `json:"id"`
+	DeletedObjects int    `json:"deletedObjects"`
+	DeletedItems   int    `json:"deletedItems"`
+}
+
+// SessionMerged is the response from POST /api/sessions/{id}/merge.
+type SessionMerged struct {
+	ID            string `json:"id"`
+	MergedFrom    string `json:"mergedFrom"`
+	CopiedObjects int    `json:"copiedObjects"`
+	MergedItems   int    `json:"mergedItems"`
+}
+
+// JobRetry is the response from POST /api/jobs/{id}/retry (DDR-089).
+type JobRetry struct {
+	ID         string `json:"id"`
+	RetryCount int    `json:"retryCount"`
+}
+
+// Job is the response from GET /api/jobs/{id} (DDR-136): any job's status in
+// one shape. Type is the pipeline step ("triage", "selection", "enhancement",
+// "download", "publish" or "description").
+type Job struct {
+	ID       string          `json:"id"`
+	Type     string          `json:"type"`
+	Status   string          `json:"status"`
+	Progress *JobProgress    `json:"progress,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Payload  json.RawMessage `json:"payload"`
+}
+
+// JobProgress counts finished units of work: files for triage, items for
+// enhancement and publish, bundles for download.
+type JobProgress struct {
+	Completed int `json:"completed"`
+	Total     int `json:"total"`
+}
+
+// Decode unmarshals the job's payload into v, typically the results type of
+// its pipeline, e.g. *SelectionResults for a "selection" job.
+func (j *Job) Decode(v any) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// --- Post groups (DDR-178) ---
+
+// PostGroup is one post of a session: up to 20 media keys in carousel
+// order. Groups are ordered by Position. DescriptionJobID and PublishJobID
+// are the latest jobs started from the group.
+type PostGroup struct {
+	ID               string   `json:"id"`
+	Name             string   `json:"name,omitempty"`
+	MediaKeys        []string `json:"mediaKeys,omitempty"`
+	Position         int      `json:"position"`
+	CreatedAt        int64    `json:"createdAt,omitempty"` // Unix seconds
+	DescriptionJobID string   `json:"descriptionJobId,omitempty"`
+	PublishJobID     string   `json:"publishJobId,omitempty"`
+
+	OrderSuggestion *CarouselOrderSuggestion `json:"orderSuggestion,omitempty"` // DDR-179
+}
+
+// CarouselOrderSuggestion is Gemini's suggested order for a post group's
+// items (DDR-179). Status is "processing", "complete" or "error". Keys are
+// the group's keys in the suggested order, cover first.
+type CarouselOrderSuggestion struct {
+	Status        string   `json:"status"`
+	Keys          []string `json:"keys,omitempty"`
+	OpeningReason string   `json:"openingReason,omitempty"`
+	Rationale     string   `json:"rationale,omitempty"`
+	Error         string   `json:"error,omitempty"`
+	CreatedAt     int64    `json:"createdAt,omitempty"` // Unix seconds
+}
+
+// --- Post group templates (DDR-122) ---
+
+// PostTemplate is a saved post group format: a label, a caption skeleton the
+// description step follows, hashtags it always includes, and enhancement
+// feedback to apply to each photo. Templates belong to the signed-in user
+// and do not expire.
+type PostTemplate struct {
+	ID                  string   `json:"id,omitempty"`
+	Name                string   `json:"name"`
+	GroupLabel          string   `json:"groupLabel,omitempty"`
+	CaptionSkeleton     string   `json:"captionSkeleton,omitempty"`
+	Hashtags            []string `json:"hashtags,omitempty"`
+	EnhancementFeedback string   `json:"enhancementFeedback,omitempty"`
+	CreatedAt           int64    `json:"createdAt,omitempty"`  // Unix seconds
+	LastUsedAt          int64    `json:"lastUsedAt,omitempty"` // Unix seconds
+}
+
+// Watermark is the signed-in user's watermark (DDR-133): a PNG logo or a
+// line of text. Position is bottom-right (default), bottom-left, top-right,
+// top-left or center; Opacity and Scale are 0–1, zero meaning the default.
+type Watermark struct {
+	Text      string  `json:"text,omitempty"`
+	Logo      []byte  `json:"logo,omitempty"` // PNG, at most 256 KB
+	Position  string  `json:"position,omitempty"`
+	Opacity   float64 `json:"opacity,omitempty"`
+	Scale     float64 `json:"scale,omitempty"`
+	UpdatedAt int64   `json:"updatedAt,omitempty"` // Unix seconds
+}
+
+// BrandKit is the signed-in user's brand identity (DDR-149). The logo is
+// stamped in place of the watermark's own mark and the first palette color
+// fills text watermarks; descriptions draw on the hashtag bank and end with
+// the sign-off.
+type BrandKit struct {
+	LogoKey   string   `json:"logoKey,omitempty"`
+	Palette   []string `json:"palette,omitempty"` // "#rrggbb", at most 6
+	Hashtags  []string `json:"hashtags,omitempty"`
+	SignOff   string   `json:"signOff,omitempty"`
+	UpdatedAt int64    `json:"updatedAt,omitempty"` // Unix seconds
+}
+
+// BrandKitUpdate is the body of SaveBrandKit. Without Logo the stored logo
+// is kept unless RemoveLogo is set.
+type BrandKitUpdate struct {
+	Logo       []byte   `json:"logo,omitempty"` // PNG, at most 256 KB
+	RemoveLogo bool     `json:"removeLogo,omitempty"`
+	Palette    []string `json:"palette,omitempty"`
+	Hashtags   []string `json:"hashtags,omitempty"`
+	SignOff    string   `json:"signOff,omitempty"`
+}
+
+// HashtagSettings is the signed-in user's hashtag strategy (DDR-157),
+// applied to the hashtags of every generated caption: banned tags are
+// dropped, AlwaysInclude tags are always kept, duplicates are removed
+// regardless of case, and at most MaxCount tags remain. Tags carry no
+// leading '#'.
+type HashtagSettings struct {
+	MaxCount      int      `json:"maxCount,omitempty"` // 0 = the platform limit of 30
+	Banned        []string `json:"banned,omitempty"`
+	AlwaysInclude []string `json:"alwaysInclude,omitempty"`
+	UpdatedAt     int64    `json:"updatedAt,omitempty"` // Unix seconds
+}
+
+// CaptionStyle is the signed-in user's caption persona (DDR-159), followed
+// by every generated caption. EmojiDensity is "none", "light" or "heavy";
+// Formality is "casual", "neutral" or "formal". Empty fields keep the
+// default voice. SignOff applies only when the brand kit has none.
+type CaptionStyle struct {
+	EmojiDensity string `json:"emojiDensity,omitempty"`
+	Language     string `json:"language,omitempty"`
+	Formality    string `json:"formality,omitempty"`
+	SignOff      string `json:"signOff,omitempty"`
+	UpdatedAt    int64  `json:"updatedAt,omitempty"` // Unix seconds
+}
+
+// ProvenanceSettings is the signed-in user's choice to embed a provenance
+// record, naming the tool and any AI edits, in enhanced and published
+// photos (DDR-140). It is on unless turned off.
+type ProvenanceSettings struct {
+	Enabled   bool  `json:"enabled"`
+	UpdatedAt int64 `json:"updatedAt,omitempty"` // Unix seconds
+}
+
+// UsageReport is the deployment's current Gemini usage (DDR-142).
+type UsageReport struct {
+	Gemini GeminiInFlight `json:"gemini"`
+	Queues []QueueDepth   `json:"queues"`
+	Tokens struct {
+		Day    string        `json:"day"` // YYYY-MM-DD, UTC
+		Models []ModelTokens `json:"models"`
+	} `json:"tokens"`
+}
+
+// GeminiInFlight is the number of Gemini requests in flight across the
+// Lambda instances that reported in the last 15 minutes.
+type GeminiInFlight struct {
+	InFlight  int64 `json:"inFlight"`
+	Instances int   `json:"instances"`
+}
+
+// QueueDepth is the approximate backlog of one job queue. Type is the worker
+// (description, download, enhance, fb-prep); Priority is batch or interactive.
+type QueueDepth struct {
+	Type       string `json:"type"`
+	Priority   string `json:"priority"`
+	Queued     int64  `json:"queued"`
+	InProgress int64  `json:"inProgress"`
+}
+
+// ModelTokens is one model's token consumption for one day.
+type ModelTokens struct {
+	Model        string `json:"model"`
+	InputTokens  int64  `json:"inputTokens"`
+	OutputTokens int64  `json:"outputTokens"`
+	Requests     int64  `json:"requests"`
+}
+
+// StorageReport is the latest reconciliation of the media bucket's S3
+// Inventory against the session table (DDR-148). Lists are capped at 1000
+// entries; the counts and byte totals cover everything found.
+type StorageReport struct {
+	GeneratedAt int64  `json:"generatedAt"` // Unix seconds
+	InventoryAt int64  `json:"inventoryAt"` // Unix seconds
+	Bucket      string `json:"bucket"`
+	Objects     int64  `json:"objects"`
+	Bytes       int64  `json:"bytes"`
+	Sessions    int    `json:"sessions"`
+	OtherBytes  int64  `json:"otherBytes"`
+
+	Unreferenced      []SessionStorage `json:"unreferenced"` // no session record
+	UnreferencedCount int              `json:"unreferencedCount"`
+	UnreferencedBytes int64            `json:"unreferencedBytes"`
+
+	NoJobs      []SessionStorage `json:"noJobs"` // storage but no job records
+	NoJobsCount int              `json:"noJobsCount"`
+	NoJobsBytes int64            `json:"noJobsBytes"`
+
+	MissingThumbnails     []MissingThumbnail `json:"missingThumbnails"`
+	MissingThumbnailCount int                `json:"missingThumbnailCount"`
+}
+
+// SessionStorage is the storage one session holds.
+type SessionStorage struct {
+	SessionID    string `json:"sessionId"`
+	Objects      int64  `json:"objects"`
+	Bytes        int64  `json:"bytes"`
+	LastModified int64  `json:"lastModified"` // Unix seconds, newest object
+}
+
+// MissingThumbnail is an uploaded original whose thumbnail is not in S3.
+type MissingThumbnail struct {
+	SessionID string `json:"sessionId"`
+	Key       string `json:"key"`
+	Thumbnail string `json:"thumbnail"`
+}
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// JobError is returned by the WaitFor helpers when a job finishes in the
+// error state. The final results are returned alongside it.
+type JobError struct {
+	JobID   string
+	Message string
+}
+
+func (e *JobError) Error() string {
+	return fmt.Sprintf("job %s failed: %s", e.JobID, e.Message)
+}
+
+// poll calls get every interval until done reports true, the context ends, or
+// get fails. Transient request failures are already retried by get.
+func poll[T any](ctx context.Context, interval time.Duration, get func(context.Context) (*T, error), done func(*T) bool) (*T, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		res, err := get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if done(res) {
+			return res, nil
+		}
+		select {
+		case <-ctx.Done():
+			return res, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// finished reports whether status is terminal for the pipelines that end in
+// complete or error.
+func finished(status string) bool {
+	return status == StatusComplete || status == StatusError
+}
+
+// jobErr returns a *JobError when status is error.
+func jobErr(id, status, msg string) error {
+	if status != StatusError {
+		return nil
+	}
+	return &JobError{JobID: id, Message: msg}
+}
+
+// WaitForTriage polls a triage job until it completes or fails. Use a context
+// deadline to bound the wait.
+func (c *Client) WaitForTriage(ctx context.Context, sessionID, jobID string) (*TriageResults, error) {
+	res, err := poll(ctx, c.pollInterval, func(ctx context.Context) (*TriageResults, error) {
+		return c.TriageResults(ctx, sessionID, jobID)
+	}, func(r *TriageResults) bool { return finished(r.Status) })
+	if err != nil {
+		return res, err
+	}
+	return res, jobErr(jobID, res.Status, res.Error)
+}
+
+// WaitForSelection polls a selection job until it completes or fails.
+func (c *Client) WaitForSelection(ctx context.Context, sessionID, jobID string) (*SelectionResults, error) {
+	res, err := poll(ctx, c.pollInterval, func(ctx context.Context) (*SelectionResults, error) {
+		return c.SelectionResults(ctx, sessionID, jobID)
+	}, func(r *SelectionResults) bool { return finished(r.Status) })
+	if err != nil {
+		return res, err
+	}
+	return res, jobErr(jobID, res.Status, res.Error)
+}
+
+// WaitForEnhancement polls an enhancement job until it completes, fails, or
+// is paused (DDR-095). A paused job is returned without error; check Status.
+func (c *Client) WaitForEnhancement(ctx context.Context, sessionID, jobID string) (*EnhancementResults, error) {
+	res, err := poll(ctx, c.pollInterval, func(ctx context.Context) (*EnhancementResults, error) {
+		return c.EnhancementResults(ctx, sessionID, jobID)
+	}, func(r *EnhancementResults) bool { return finished(r.Status) || r.Status == StatusPaused })
+	if err != nil {
+		return res, err
+	}
+	return res, jobErr(jobID, res.Status, res.Error)
+}
+
+// WaitForFeedbackBatch polls a feedback batch until every photo's round has
+// finished (DDR-182). Check each item for photos that failed.
+func (c *Client) WaitForFeedbackBatch(ctx context.Context, sessionID, jobID, batchID string) (*FeedbackBatch, error) {
+	res, err := poll(ctx, c.pollInterval, func(ctx context.Context) (*FeedbackBatch, error) {
+		return c.FeedbackBatchStatus(ctx, sessionID, jobID, batchID)
+	}, func(b *FeedbackBatch) bool { return finished(b.Status) })
+	if err != nil {
+		return res, err
+	}
+	return res, jobErr(batchID, res.Status, "every photo failed")
+}
+
+// WaitForDownload polls a download job until its bundles are ready or it fails.
+func (c *Client) WaitForDownload(ctx context.Context, sessionID, jobID string) (*DownloadResults, error) {
+	res, err := poll(ctx, c.pollInterval, func(ctx context.Context) (*DownloadResults, error) {
+		return c.DownloadResults(ctx, sessionID, jobID)
+	}, func(r *DownloadResults) bool { return finished(r.Status) })
+	if err != nil {
+		return res, err
+	}
+	return res, jobErr(jobID, res.Status, res.Error)
+}
+
+// WaitForDescription polls a description job until the caption is ready or
+// it fails. After DescriptionFeedback the job returns to processing, so wait
+// again for the revised caption.
+func (c *Client) WaitForDescription(ctx context.Context, sessionID, jobID string) (*DescriptionResults, error) {
+	res, err := poll(ctx, c.pollInterval, func(ctx context.Context) (*DescriptionResults, error) {
+		return c.DescriptionResults(ctx, sessionID, jobID)
+	}, func(r *DescriptionResults) bool { return finished(r.Status) })
+	if err != nil {
+		return res, err
+	}
+	return res, jobErr(jobID, res.Status, res.Error)
+}
+
+// WaitForFBPrep polls an FB prep job until it completes or fails.
+func (c *Client) WaitForFBPrep(ctx context.Context, sessionID, jobID string) (*FBPrepResults, error) {
+	res, err := poll(ctx, c.pollInterval, func(ctx context.Context) (*FBPrepResults, error) {
+		return c.FBPrepResults(ctx, sessionID, jobID)
+	}, func(r *FBPrepResults) bool { return finished(r.Status) })
+	if err != nil {
+		return res, err
+	}
+	return res, jobErr(jobID, res.Status, res.Error)
+}
+
+// WaitForMoodVariants polls a mood variant job until it completes or fails.
+func (c *Client) WaitForMoodVariants(ctx context.Context, sessionID, jobID string) (*MoodVariantResults, error) {
+	res, err := poll(ctx, c.pollInterval, func(ctx context.Context) (*MoodVariantResults, error) {
+		return c.MoodVariantResults(ctx, sessionID, jobID)
+	}, func(r *MoodVariantResults) bool { return finished(r.Status) })
+	if err != nil {
+		return res, err
+	}
+	return res, jobErr(jobID, res.Status, res.Error)
+}
+
+// WaitForCropSuggestions polls a crop suggestion job until it completes or fails.
+func (c *Client) WaitForCropSuggestions(ctx context.Context, sessionID, jobID string) (*CropResults, error) {
+	res, err := poll(ctx, c.pollInterval, func(ctx context.Context) (*CropResults, error) {
+		return c.CropResults(ctx, sessionID, jobID)
+	}, func(r *CropResults) bool { return finished(r.Status) })
+	if err != nil {
+		return res, err
+	}
+	return res, jobErr(jobID, res.Status, res.Error)
+}
+
+// WaitForPublish polls a publish job until the post is published, fails or
+// is rejected. A job awaiting approval keeps being polled (DDR-121). A
+// cross-posting job that published to only some platforms returns without
+// an error; its Platforms tell which failed (DDR-153).
+func (c *Client) WaitForPublish(ctx context.Context, sessionID, jobID string) (*PublishStatus, error) {
+	res, err := poll(ctx, c.pollInterval, func(ctx context.Context) (*PublishStatus, error) {
+		return c.PublishStatus(ctx, sessionID, jobID)
+	}, func(r *PublishStatus) bool {
+		return r.Status == StatusPublished || r.Status == StatusPartiallyPublished ||
+			r.Status == StatusError || r.Status == StatusRejected
+	})
+	if err != nil {
+		return res, err
+	}
+	if res.Status == StatusRejected {
+		return res, jobErr(jobID, StatusError, res.Error)
+	}
+	return res, jobErr(jobID, res.Status, res.Error)
+}
@@ -1,4 +1,5 @@
 import type {
+  AppStatus,
   BrowseResponse,
   PickRequest,
   PickResponse,
@@ -12,40 +13,74 @@ import type {
   TriageResults,
   TriageConfirmRequest,
   TriageConfirmResponse,
+  TriageExportRequest,
+  TriageExportResponse,
+  TriageOverrideRequest,
+  TriageOverrideResponse,
   TriageLogsResponse,
   UploadUrlResponse,
   FullImageResponse,
   SelectionStartRequest,
   SelectionStartResponse,
+  SelectionFeedbackRequest,
+  SelectionFeedbackResponse,
+  SelectionRerunRequest,
+  SelectionRerunResponse,
   SelectionResults,
   EnhancementStartRequest,
   EnhancementStartResponse,
   EnhancementResults,
   EnhancementFeedbackRequest,
   EnhancementFeedbackResponse,
+  EnhancementControlResponse,
+  EnhancementItem,
+  EnhancementVersionsResponse,
+  EnhancementRollbackRequest,
+  MediaAdjustRequest,
+  MediaAdjustResponse,
+  FeedbackBatch,
+  FeedbackBatchRequest,
   DownloadStartRequest,
+  MetadataPolicy,
   DownloadStartResponse,
+  DownloadRetryOmittedResponse,
   DownloadResults,
   DescriptionGenerateRequest,
   DescriptionGenerateResponse,
   DescriptionResults,
   DescriptionFeedbackRequest,
   DescriptionFeedbackResponse,
+  MoodVariantStartRequest,
+  MoodVariantStartResponse,
+  MoodVariantResults,
   FBPrepStartRequest,
   FBPrepStartResponse,
   FBPrepJob,
   FBPrepFeedbackRequest,
   FBPrepFeedbackResponse,
   PublishStartRequest,
+  InstagramLocation,
   PublishStartResponse,
+  ScheduledPost,
+  PostInsights,
   PublishStatus,
+  CropAspect,
+  CropResults,
+  CroppedMedia,
+  RedactMode,
+  RedactResults,
+  PostTemplate,
+  SavedPostGroup,
   MultipartInitRequest,
   MultipartInitResponse,
   MultipartCompleteRequest,
   MultipartCompleteResponse,
   MultipartAbortRequest,
+  UploadAdviceRequest,
+  UploadAdviceResponse,
   MultipartCompletedPart,
   FileProcessingStatus,
+  JobEnvelope,
 } from "../types/api";
 import { getIdToken } from "../auth/cognito";
 
@@ -129,6 +164,15 @@ export function pick(req: PickRequest): Promise<PickResponse> {
   });
 }
 
+/**
+ * Get local server health: version, Gemini key, ffmpeg, running jobs and
+ * free space on the volume holding path (local mode only, DDR-125).
+ */
+export function getStatus(path?: string): Promise<AppStatus> {
+  const query = path ? `?path=${encodeURIComponent(path)}` : "";
+  return fetchJSON<AppStatus>(`/api/status${query}`);
+}
+
 // --- Phase 2 (cloud mode) APIs ---
 
 /** Get a presigned S3 PUT URL for uploading a file (cloud mode only). */
@@ -141,16 +185,23 @@ export function getUploadUrl(
   return fetchJSON<UploadUrlResponse>(`/api/upload-url?${params}`);
 }
 
-/** Upload a file directly to S3 using a presigned PUT URL. */
+/**
+ * Upload a file directly to S3 using a presigned PUT URL, sending the
+ * headers the URL was signed for (DDR-164).
+ */
 export async function uploadToS3(
   uploadUrl: string,
   file: File,
   onProgress?: (loaded: number, total: number) => void,
+  headers?: Record<string, string>,
 ): Promise<void> {
   const { promise, resolve, reject } = Promise.withResolvers<void>();
   const xhr = new XMLHttpRequest();
   xhr.open("PUT", uploadUrl, true);
   xhr.setRequestHeader("Content-Type", file.type);
+  for (const [name, value] of Object.entries(headers ?? {})) {
+    xhr.setRequestHeader(name, value);
+  }
 
   if (onProgress) {
     xhr.upload.addEventListener("progress", (e) => {
@@ -214,6 +265,16 @@ export function abortMultipartUpload(
   });
 }
 
+/** Ask whether to upload originals or downscaled copies (DDR-128). */
+export function getUploadAdvice(
+  req: UploadAdviceRequest,
+): Promise<UploadAdviceResponse> {
+  return fetchJSON<UploadAdviceResponse>("/api/upload/advice", {
+    method: "POST",
+    body: JSON.stringify(req),
+  });
+}
+
 /**
  * Upload a single chunk to S3 using a presigned PUT URL.
  * Returns the ETag from the response headers (required for CompleteMultipartUpload).
@@ -422,6 +483,28 @@ export function confirmTriage(
   });
 }
 
+/** Hard-link or move the kept files into a folder (local mode only, DDR-160). */
+export function exportTriage(
+  id: string,
+  req: TriageExportRequest,
+): Promise<TriageExportResponse> {
+  return fetchJSON<TriageExportResponse>(`/api/triage/${id}/export`, {
+    method: "POST",
+    body: JSON.stringify(req),
+  });
+}
+
+/** Record the triage verdicts the user reversed so the preference profile learns from them (DDR-100). */
+export function recordTriageOverrides(
+  id: string,
+  req: TriageOverrideRequest,
+): Promise<TriageOverrideResponse> {
+  return fetchJSON<TriageOverrideResponse>(`/api/triage/${id}/override`, {
+    method: "POST",
+    body: JSON.stringify(req),
+  });
+}
+
 /** Get thumbnail URL for a media file. */
 export function thumbnailUrl(pathOrKey: string): string {
   if (isCloudMode) {
@@ -430,6 +513,11 @@ export function thumbnailUrl(pathOrKey: string): string {
   return `${BASE}/api/media/thumbnail?path=${encodeURIComponent(pathOrKey)}`;
 }
 
+/** Get the frame strip URL for a video (DDR-124). Cloud mode only. */
+export function videoPreviewUrl(key: string): string {
+  return `${BASE}/api/media/preview?key=${encodeURIComponent(key)}`;
+}
+
 /** Get full-resolution URL for a media file. */
 export function fullImageUrl(pathOrKey: string): string {
   if (isCloudMode) {
@@ -510,6 +598,31 @@ export function getSelectionResults(
   );
 }
 
+/** Re-run a completed selection around locked and excluded media (DDR-176). */
+export function rerunSelection(
+  id: string,
+  req: SelectionRerunRequest,
+): Promise<SelectionRerunResponse> {
+  return fetchJSON<SelectionRerunResponse>(`/api/selection/${id}/rerun`, {
+    method: "POST",
+    body: JSON.stringify(req),
+  });
+}
+
+/**
+ * Revise a completed selection from free-text feedback (DDR-177). Poll
+ * getSelectionResults until the job is complete again.
+ */
+export function submitSelectionFeedback(
+  id: string,
+  req: SelectionFeedbackRequest,
+): Promise<SelectionFeedbackResponse> {
+  return fetchJSON<SelectionFeedbackResponse>(`/api/selection/${id}/feedback`, {
+    method: "POST",
+    body: JSON.stringify(req),
+  });
+}
+
 // --- Enhancement APIs (DDR-031) ---
 
 /** Start a photo enhancement job for the given media keys. */
@@ -546,6 +659,97 @@ export function submitEnhancementFeedback(
   );
 }
 
+/** Apply one feedback instruction to several photos of a job (DDR-182). */
+export function submitEnhancementFeedbackBatch(
+  id: string,
+  req: FeedbackBatchRequest,
+): Promise<FeedbackBatch> {
+  return fetchJSON<FeedbackBatch>(`/api/enhance/${id}/feedback-batch`, {
+    method: "POST",
+    body: JSON.stringify(req),
+  });
+}
+
+/** Get the per-photo state of a feedback batch (poll until not "processing"). */
+export function getEnhancementFeedbackBatch(
+  id: string,
+  sessionId: string,
+  batchId: string,
+): Promise<FeedbackBatch> {
+  return fetchJSON<FeedbackBatch>(
+    `/api/enhance/${id}/feedback-batch?sessionId=${encodeURIComponent(sessionId)}&batchId=${encodeURIComponent(batchId)}`,
+  );
+}
+
+/** Pause an enhancement job; in-flight items finish, the rest wait (DDR-095). */
+export function pauseEnhancement(
+  id: string,
+  sessionId: string,
+): Promise<EnhancementControlResponse> {
+  return fetchJSON<EnhancementControlResponse>(`/api/enhance/${id}/pause`, {
+    method: "POST",
+    body: JSON.stringify({ sessionId }),
+  });
+}
+
+/** Resume a paused enhancement job from its first unprocessed item (DDR-095). */
+export function resumeEnhancement(
+  id: string,
+  sessionId: string,
+): Promise<EnhancementControlResponse> {
+  return fetchJSON<EnhancementControlResponse>(`/api/enhance/${id}/resume`, {
+    method: "POST",
+    body: JSON.stringify({ sessionId }),
+  });
+}
+
+/** List every result a photo has had, with preview URLs (DDR-181). */
+export function getEnhancementVersions(
+  id: string,
+  sessionId: string,
+  key: string,
+): Promise<EnhancementVersionsResponse> {
+  return fetchJSON<EnhancementVersionsResponse>(
+    `/api/enhance/${id}/versions?sessionId=${encodeURIComponent(sessionId)}&key=${encodeURIComponent(key)}`,
+  );
+}
+
+/** Make an earlier version of a photo its current result (DDR-181). */
+export function rollBackEnhancement(
+  id: string,
+  req: EnhancementRollbackRequest,
+): Promise<EnhancementItem> {
+  return fetchJSON<EnhancementItem>(`/api/enhance/${id}/rollback`, {
+    method: "POST",
+    body: JSON.stringify(req),
+  });
+}
+
+/**
+ * Apply local adjustments to an enhanced photo (DDR-183). The result is
+ * saved as its next version; poll getEnhancementVersions for it.
+ */
+export function adjustMedia(
+  req: MediaAdjustRequest,
+): Promise<MediaAdjustResponse> {
+  return fetchJSON<MediaAdjustResponse>("/api/media/adjust", {
+    method: "POST",
+    body: JSON.stringify(req),
+  });
+}
+
+// --- Generic job status (DDR-136) ---
+
+/** Get any job's status by ID; the job type comes from the ID prefix. */
+export function getJob<T = unknown>(
+  id: string,
+  sessionId: string,
+): Promise<JobEnvelope<T>> {
+  return fetchJSON<JobEnvelope<T>>(
+    `/api/jobs/${id}?sessionId=${encodeURIComponent(sessionId)}`,
+  );
+}
+
 // --- Download APIs (DDR-034) ---
 
 /** Start a download job to create ZIP bundles for a post group. */
@@ -568,6 +772,89 @@ export function getDownloadResults(
   );
 }
 
+/** Get the session's photo metadata policy (DDR-135). */
+export function getMetadataPolicy(
+  sessionId: string,
+): Promise<{ metadataPolicy: MetadataPolicy }> {
+  return fetchJSON<{ metadataPolicy: MetadataPolicy }>(
+    `/api/sessions/${encodeURIComponent(sessionId)}/metadata-policy`,
+  );
+}
+
+/** Set the session's photo metadata policy (DDR-135). */
+export function setMetadataPolicy(
+  sessionId: string,
+  metadataPolicy: MetadataPolicy,
+): Promise<{ metadataPolicy: MetadataPolicy }> {
+  return fetchJSON<{ metadataPolicy: MetadataPolicy }>(
+    `/api/sessions/${encodeURIComponent(sessionId)}/metadata-policy`,
+    {
+      method: "POST",
+      body: JSON.stringify({ metadataPolicy }),
+    },
+  );
+}
+
+/** Whether the session's files are encrypted under their own key (DDR-164). */
+export function getSessionEncryption(
+  sessionId: string,
+): Promise<{ encrypted: boolean }> {
+  return fetchJSON<{ encrypted: boolean }>(
+    `/api/sessions/${encodeURIComponent(sessionId)}/encryption`,
+  );
+}
+
+/**
+ * Give the session its own KMS key, deleted when the session is deleted.
+ * Must be called before the first upload (DDR-164).
+ */
+export function setSessionEncryption(
+  sessionId: string,
+  encrypted: boolean,
+): Promise<{ encrypted: boolean }> {
+  return fetchJSON<{ encrypted: boolean }>(
+    `/api/sessions/${encodeURIComponent(sessionId)}/encryption`,
+    {
+      method: "POST",
+      body: JSON.stringify({ encrypted }),
+    },
+  );
+}
+
+/** Start a new download job for the files a job left out of its ZIPs (DDR-097). */
+export function retryOmittedDownload(
+  id: string,
+  sessionId: string,
+): Promise<DownloadRetryOmittedResponse> {
+  return fetchJSON<DownloadRetryOmittedResponse>(
+    `/api/download/${id}/retry-omitted`,
+    {
+      method: "POST",
+      body: JSON.stringify({ sessionId }),
+    },
+  );
+}
+
+// --- Mood variants (DDR-102) ---
+
+export function startMoodVariants(
+  req: MoodVariantStartRequest,
+): Promise<MoodVariantStartResponse> {
+  return fetchJSON<MoodVariantStartResponse>("/api/mood-variants/start", {
+    method: "POST",
+    body: JSON.stringify(req),
+  });
+}
+
+export function getMoodVariantResults(
+  id: string,
+  sessionId: string,
+): Promise<MoodVariantResults> {
+  return fetchJSON<MoodVariantResults>(
+    `/api/mood-variants/${id}/results?sessionId=${encodeURIComponent(sessionId)}`,
+  );
+}
+
 // --- Description APIs (DDR-036) ---
 
 /** Generate an AI Instagram caption for a post group. */
@@ -604,6 +891,127 @@ export function submitDescriptionFeedback(
   );
 }
 
+// --- Post group template APIs (DDR-122) ---
+
+/** List the signed-in user's post templates, most recently used first. */
+export function listTemplates(): Promise<{ templates: PostTemplate[] }> {
+  return fetchJSON<{ templates: PostTemplate[] }>("/api/templates");
+}
+
+/** Save a new post template. */
+export function saveTemplate(
+  template: Omit<PostTemplate, "id" | "createdAt" | "lastUsedAt">,
+): Promise<PostTemplate> {
+  return fetchJSON<PostTemplate>("/api/templates", {
+    method: "POST",
+    body: JSON.stringify(template),
+  });
+}
+
+/** Delete a post template. */
+export function deleteTemplate(id: string): Promise<{ id: string }> {
+  return fetchJSON<{ id: string }>(`/api/templates/${encodeURIComponent(id)}`, {
+    method: "DELETE",
+  });
+}
+
+// --- Post group APIs (DDR-178) ---
+
+/** List a session's saved post groups in order. */
+export function listPostGroups(
+  sessionId: string,
+): Promise<{ groups: SavedPostGroup[] }> {
+  return fetchJSON<{ groups: SavedPostGroup[] }>(
+    `/api/groups?sessionId=${encodeURIComponent(sessionId)}`,
+  );
+}
+
+/** Create a post group from media keys, appended after the existing groups. */
+export function createPostGroup(
+  sessionId: string,
+  keys: string[],
+  name?: string,
+): Promise<SavedPostGroup> {
+  return fetchJSON<SavedPostGroup>("/api/groups", {
+    method: "POST",
+    body: JSON.stringify({ sessionId, keys, name }),
+  });
+}
+
+function postGroupAction<T>(
+  groupId: string,
+  action: string,
+  body: Record<string, unknown>,
+): Promise<T> {
+  return fetchJSON<T>(
+    `/api/groups/${encodeURIComponent(groupId)}/${action}`,
+    {
+      method: "POST",
+      body: JSON.stringify(body),
+    },
+  );
+}
+
+/** Rename a post group. */
+export function renamePostGroup(
+  sessionId: string,
+  groupId: string,
+  name: string,
+): Promise<SavedPostGroup> {
+  return postGroupAction(groupId, "rename", { sessionId, name });
+}
+
+/** Set the carousel order of a group's items; keys must be exactly its keys. */
+export function reorderPostGroup(
+  sessionId: string,
+  groupId: string,
+  keys: string[],
+): Promise<SavedPostGroup> {
+  return postGroupAction(groupId, "reorder", { sessionId, keys });
+}
+
+/** Move a group's items from index `at` onward into a new group after it. */
+export function splitPostGroup(
+  sessionId: string,
+  groupId: string,
+  at: number,
+  name?: string,
+): Promise<{ groups: SavedPostGroup[] }> {
+  return postGroupAction(groupId, "split", { sessionId, at, name });
+}
+
+/** Append the items of group `sourceId` to `groupId` and delete the source. */
+export function mergePostGroups(
+  sessionId: string,
+  groupId: string,
+  sourceId: string,
+): Promise<{ groups: SavedPostGroup[] }> {
+  return postGroupAction(groupId, "merge", { sessionId, sourceId });
+}
+
+/**
+ * Ask Gemini for the group's carousel order (DDR-179). The returned group's
+ * orderSuggestion is "processing"; poll listPostGroups until it completes.
+ */
+export function suggestPostGroupOrder(
+  sessionId: string,
+  groupId: string,
+  tripContext?: string,
+): Promise<SavedPostGroup> {
+  return postGroupAction(groupId, "suggest-order", { sessionId, tripContext });
+}
+
+/** Delete a post group; its media stays in the session. */
+export function deletePostGroup(
+  sessionId: string,
+  groupId: string,
+): Promise<{ groups: SavedPostGroup[] }> {
+  return fetchJSON<{ groups: SavedPostGroup[] }>(
+    `/api/groups/${encodeURIComponent(groupId)}?sessionId=${encodeURIComponent(sessionId)}`,
+    { method: "DELETE" },
+  );
+}
+
 // --- FB Prep APIs ---
 
 /** Start an FB prep job for the given media items. */
@@ -649,6 +1057,8 @@ export interface HealthResponse {
   commitHash: string;
   buildTime: string;
   instagramConfigured: boolean;
+  /** The deployment simulates Instagram (INSTAGRAM_MODE=mock, DDR-139). */
+  instagramMock?: boolean;
 }
 
 /** Check whether Instagram publishing is configured on the backend. */
@@ -676,6 +1086,124 @@ export function getPublishStatus(
   );
 }
 
+/**
+ * Approve or reject a publish job held for approval (DDR-121). The token comes
+ * from the approval link; without it the caller must be a configured approver.
+ */
+export function decidePublish(
+  id: string,
+  sessionId: string,
+  decision: "approve" | "reject",
+  token?: string,
+): Promise<{ id: string; approval: string }> {
+  return fetchJSON<{ id: string; approval: string }>(
+    `/api/publish/${id}/${decision}`,
+    {
+      method: "POST",
+      body: JSON.stringify({ sessionId, token }),
+    },
+  );
+}
+
+/** List the signed-in user's scheduled posts, soonest first (DDR-144). */
+export function listScheduledPosts(): Promise<{ posts: ScheduledPost[] }> {
+  return fetchJSON<{ posts: ScheduledPost[] }>("/api/publish/scheduled");
+}
+
+/** Cancel a scheduled post that has not been dispatched yet (DDR-144). */
+export function cancelScheduledPost(id: string): Promise<ScheduledPost> {
+  return fetchJSON<ScheduledPost>(`/api/publish/scheduled/${id}/cancel`, {
+    method: "POST",
+  });
+}
+
+/** Move a scheduled post to a new RFC 3339 publish time (DDR-144). */
+export function rescheduleScheduledPost(
+  id: string,
+  publishAt: string,
+): Promise<ScheduledPost> {
+  return fetchJSON<ScheduledPost>(`/api/publish/scheduled/${id}/reschedule`, {
+    method: "POST",
+    body: JSON.stringify({ publishAt }),
+  });
+}
+
+/** Get the insights collected for a published post by its Instagram ID (DDR-146). */
+export function getPostInsights(instagramPostId: string): Promise<PostInsights> {
+  return fetchJSON<PostInsights>(
+    `/api/posts/${encodeURIComponent(instagramPostId)}/insights`,
+  );
+}
+
+/** Search the places a post can be tagged with (DDR-138). */
+export function searchInstagramLocations(
+  query: string,
+): Promise<{ locations: InstagramLocation[] }> {
+  return fetchJSON<{ locations: InstagramLocation[] }>(
+    `/api/instagram/locations?q=${encodeURIComponent(query)}`,
+  );
+}
+
+// --- Crop suggestion APIs (DDR-130) ---
+
+/** Ask for subject-aware 1:1, 4:5 and 9:16 crops of up to 20 photos. */
+export function startCropSuggestions(
+  sessionId: string,
+  keys: string[],
+): Promise<{ id: string }> {
+  return fetchJSON<{ id: string }>("/api/crop/start", {
+    method: "POST",
+    body: JSON.stringify({ sessionId, keys }),
+  });
+}
+
+/** Get crop suggestions (poll until status is "complete" or "error"). */
+export function getCropResults(
+  id: string,
+  sessionId: string,
+): Promise<CropResults> {
+  return fetchJSON<CropResults>(
+    `/api/crop/${id}/results?sessionId=${encodeURIComponent(sessionId)}`,
+  );
+}
+
+/** Crop a photo server-side to a suggested crop; publish the returned key. */
+export function applyCrop(
+  sessionId: string,
+  key: string,
+  jobId: string,
+  aspect: CropAspect,
+): Promise<CroppedMedia> {
+  return fetchJSON<CroppedMedia>("/api/media/crop", {
+    method: "POST",
+    body: JSON.stringify({ sessionId, key, jobId, aspect }),
+  });
+}
+
+// --- Privacy redaction APIs (DDR-185) ---
+
+/** Hide bystanders' faces and licence plates in a copy of a photo. */
+export function redactMedia(
+  sessionId: string,
+  key: string,
+  mode: RedactMode = "blur",
+): Promise<{ id: string }> {
+  return fetchJSON<{ id: string }>("/api/media/redact", {
+    method: "POST",
+    body: JSON.stringify({ sessionId, key, mode }),
+  });
+}
+
+/** Get a redaction (poll until status is "complete" or "error"). */
+export function getRedactResults(
+  id: string,
+  sessionId: string,
+): Promise<RedactResults> {
+  return fetchJSON<RedactResults>(
+    `/api/media/redact/${id}/results?sessionId=${encodeURIComponent(sessionId)}`,
+  );
+}
+
 // --- Session Invalidation API (DDR-037) ---
 
 /** Request body for POST /api/session/invalidate. */
@@ -703,6 +1231,52 @@ export function invalidateSession(
   });
 }
 
+// --- Session management APIs (DDR-098) ---
+
+/** Status of one job in a session summary. */
+export interface SessionJobSummary {
+  id: string;
+  type: "triage" | "selection" | "enhancement" | "download" | "description" | "fb-prep" | "publish";
+  status: string;
+  error?: string;
+}
+
+/** A session summary from GET /api/sessions and GET /api/sessions/{id}. */
+export interface SessionSummary {
+  id: string;
+  /** Unix seconds. */
+  createdAt: number;
+  status: string;
+  tripContext?: string;
+  fileCount: number;
+  groupCount: number;
+  jobs: SessionJobSummary[];
+}
+
+/** Response from DELETE /api/sessions/{id}. */
+export interface DeleteSessionResponse {
+  id: string;
+  deletedObjects: number;
+  deletedItems: number;
+}
+
+/** List the signed-in user's sessions, newest first. */
+export function listSessions(): Promise<{ sessions: SessionSummary[] }> {
+  return fetchJSON<{ sessions: SessionSummary[] }>("/api/sessions");
+}
+
+/** Get a summary of one session. */
+export function getSession(id: string): Promise<SessionSummary> {
+  return fetchJSON<SessionSummary>(`/api/sessions/${id}`);
+}
+
+/** Delete a session's files and records. This cannot be undone. */
+export function deleteSession(id: string): Promise<DeleteSessionResponse> {
+  return fetchJSON<DeleteSessionResponse>(`/api/sessions/${id}`, {
+    method: "DELETE",
+  });
+}
+
 // --- Override capture APIs (RAG feedback) ---
 
 /** POST a single override action (real-time). */
@@ -35,6 +35,7 @@ import {
 import { LandingPage } from "./components/LandingPage";
 import { LoginForm } from "./components/LoginForm";
 import { MediaUploader } from "./components/MediaUploader";
+import { StatusBar } from "./components/StatusBar";
 import {
   PostGrouper,
   resetPostGrouperState,
@@ -626,10 +627,16 @@ export function App() {
           marginTop: "2rem",
         }}
       >
-        <span>v0.1.0</span>
-        <span>
-          <span style={{ color: "var(--color-success)" }}>●</span> System Operational
-        </span>
+        {isCloudMode ? (
+          <>
+            <span>v0.1.0</span>
+            <span>
+              <span style={{ color: "var(--color-success)" }}>●</span> System Operational
+            </span>
+          </>
+        ) : (
+          <StatusBar />
+        )}
       </footer>
     </div>
   );
@@ -0,0 +1,242 @@
+import { signal } from "@preact/signals";
+import { uploadSessionId } from "../app";
+import {
+  startCropSuggestions,
+  getCropResults,
+  applyCrop,
+  thumbnailUrl,
+} from "../api/client";
+import { groupableMedia } from "./PostGrouper";
+import type {
+  PostGroup,
+  CropAspect,
+  CropRect,
+  CropSuggestion,
+} from "../types/api";
+
+// --- Subject-aware crop suggestions (DDR-130) ---
+
+/** Crop suggestion job per post group. */
+interface GroupCropState {
+  jobId: string | null;
+  status: "idle" | "running" | "complete" | "error";
+  items: CropSuggestion[];
+  error: string | null;
+}
+
+const cropStates = signal<Record<string, GroupCropState>>({});
+
+/** Chosen aspect per photo key, per post group. Absent = publish the original. */
+const cropChoices = signal<Record<string, Record<string, CropAspect>>>({});
+
+/** Reset crop suggestions and choices (called with resetPublishState). */
+export function resetCropState() {
+  cropStates.value = {};
+  cropChoices.value = {};
+}
+
+function getCropState(groupId: string): GroupCropState {
+  return (
+    cropStates.value[groupId] ?? {
+      jobId: null,
+      status: "idle",
+      items: [],
+      error: null,
+    }
+  );
+}
+
+function setCropState(groupId: string, state: GroupCropState) {
+  cropStates.value = { ...cropStates.value, [groupId]: state };
+}
+
+function chooseCrop(groupId: string, key: string, aspect: CropAspect | null) {
+  const choices = { ...(cropChoices.value[groupId] ?? {}) };
+  if (aspect) {
+    choices[key] = aspect;
+  } else {
+    delete choices[key];
+  }
+  cropChoices.value = { ...cropChoices.value, [groupId]: choices };
+}
+
+async function suggestCrops(group: PostGroup) {
+  const sessionId = uploadSessionId.value;
+  if (!sessionId) return;
+
+  const photoKeys = group.keys.filter(
+    (key) => groupableMedia.value.find((m) => m.key === key)?.type === "Photo",
+  );
+  setCropState(group.id, { jobId: null, status: "running", items: [], error: null });
+
+  try {
+    const { id } = await startCropSuggestions(sessionId, photoKeys.slice(0, 20));
+    for (let i = 0; i < 100; i++) {
+      await new Promise((resolve) => setTimeout(resolve, 3000));
+      const result = await getCropResults(id, sessionId);
+      if (result.status === "complete" || result.status === "error") {
+        setCropState(group.id, {
+          jobId: id,
+          status: result.status,
+          items: result.items,
+          error: result.error ?? null,
+        });
+        return;
+      }
+      setCropState(group.id, { ...getCropState(group.id), jobId: id, items: result.items });
+    }
+    throw new Error("Crop suggestions timed out");
+  } catch (err) {
+    setCropState(group.id, {
+      ...getCropState(group.id),
+      status: "error",
+      error: err instanceof Error ? err.message : "Crop suggestions failed",
+    });
+  }
+}
+
+/**
+ * Returns the keys to publish for a group: each photo with a chosen crop is
+ * cropped server-side and replaced by the cropped copy.
+ */
+export async function resolvePublishKeys(group: PostGroup): Promise<string[]> {
+  const sessionId = uploadSessionId.value;
+  const { jobId } = getCropState(group.id);
+  const choices = cropChoices.value[group.id] ?? {};
+  if (!sessionId || !jobId || Object.keys(choices).length === 0) {
+    return group.keys;
+  }
+  const keys: string[] = [];
+  for (const key of group.keys) {
+    const aspect = choices[key];
+    keys.push(aspect ? (await applyCrop(sessionId, key, jobId, aspect)).key : key);
+  }
+  return keys;
+}
+
+// --- Sub-components ---
+
+/** A thumbnail showing only the given crop of the photo. */
+function CropPreview({
+  thumbKey,
+  item,
+  crop,
+}: {
+  thumbKey: string;
+  item: CropSuggestion;
+  crop: CropRect;
+}) {
+  const width = item.width ?? crop.w;
+  const height = item.height ?? crop.h;
+  const boxHeight = 4; // rem
+  const posX = width > crop.w ? (crop.x / (width - crop.w)) * 100 : 50;
+  const posY = height > crop.h ? (crop.y / (height - crop.h)) * 100 : 50;
+  return (
+    <div
+      style={{
+        height: `${boxHeight}rem`,
+        width: `${(boxHeight * crop.w) / crop.h}rem`,
+        backgroundImage: `url(${thumbnailUrl(thumbKey)})`,
+        backgroundSize: `${(width / crop.w) * 100}% ${(height / crop.h) * 100}%`,
+        backgroundPosition: `${posX}% ${posY}%`,
+        borderRadius: "3px",
+      }}
+    />
+  );
+}
+
+/** Crop suggestions for the photos of one post group. */
+export function CropPicker({ group }: { group: PostGroup }) {
+  const state = getCropState(group.id);
+  const choices = cropChoices.value[group.id] ?? {};
+  const hasPhotos = group.keys.some(
+    (key) => groupableMedia.value.find((m) => m.key === key)?.type === "Photo",
+  );
+  if (!hasPhotos) return null;
+
+  if (state.status === "idle") {
+    return (
+      <div style={{ marginBottom: "0.75rem" }}>
+        <button
+          class="outline"
+          style={{ fontSize: "0.75rem" }}
+          onClick={() => suggestCrops(group)}
+        >
+          Suggest crops for Instagram
+        </button>
+      </div>
+    );
+  }
+
+  return (
+    <div
+      style={{
+        padding: "0.5rem 0.75rem",
+        background: "var(--color-bg)",
+        borderRadius: "var(--radius)",
+        border: "1px solid var(--color-border)",
+        marginBottom: "0.75rem",
+        fontSize: "0.75rem",
+      }}
+    >
+      {state.status === "running" && (
+        <div style={{ color: "var(--color-text-secondary)", marginBottom: "0.5rem" }}>
+          Finding the subject of each photo... ({state.items.length} done)
+        </div>
+      )}
+      {state.error && (
+        <div style={{ color: "var(--color-danger)", marginBottom: "0.5rem" }}>
+          {state.error}
+        </div>
+      )}
+      {state.items.map((item) => {
+        const media = groupableMedia.value.find((m) => m.key === item.key);
+        if (!media) return null;
+        const chosen = choices[item.key] ?? null;
+        return (
+          <div
+            key={item.key}
+            style={{
+              display: "flex",
+              alignItems: "flex-end",
+              gap: "0.5rem",
+              marginBottom: "0.5rem",
+            }}
+          >
+            <button
+              class={chosen === null ? "" : "outline"}
+              style={{ fontSize: "0.75rem", height: "4rem" }}
+              title={media.filename}
+              onClick={() => chooseCrop(group.id, item.key, null)}
+            >
+              Original
+            </button>
+            {item.error && (
+              <span style={{ color: "var(--color-text-secondary)" }}>
+                {media.filename}: {item.error}
+              </span>
+            )}
+            {(item.crops ?? []).map((crop) => (
+              <button
+                key={crop.aspect}
+                class={chosen === crop.aspect ? "" : "outline"}
+                style={{
+                  padding: "0.25rem",
+                  display: "flex",
+                  flexDirection: "column",
+                  alignItems: "center",
+                  gap: "0.125rem",
+                }}
+                title={item.subject?.label ? `Centred on ${item.subject.label}` : "Centred"}
+                onClick={() => chooseCrop(group.id, item.key, crop.aspect!)}
+              >
+                <CropPreview thumbKey={media.thumbnailKey} item={item} crop={crop} />
+                <span style={{ fontSize: "0.75rem" }}>{crop.aspect}</span>
+              </button>
+            ))}
+          </div>
+        );
+      })}
+    </div>
+  );
+}
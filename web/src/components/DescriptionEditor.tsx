@@ -15,9 +15,11 @@ import {
   generateDescription,
   getDescriptionResults,
   submitDescriptionFeedback,
+  saveTemplate,
   thumbnailUrl,
 } from "../api/client";
 import { postGroups, groupableMedia } from "./PostGrouper";
+import { loadPostTemplates } from "./post-grouper/TemplatePicker";
 import { setGroupCaption } from "./PublishView";
 import type { PostGroup, GroupableMediaItem } from "../types/api";
 
@@ -56,6 +58,14 @@ const isEditing = signal(false);
 /** Copy-to-clipboard status. */
 const copyStatus = signal<"idle" | "copied">("idle");
 
+/** "Save as template" form for the current caption (DDR-122); null when closed. */
+const templateForm = signal<{
+  name: string;
+  enhancementFeedback: string;
+  status: "editing" | "saving" | "saved";
+  error: string | null;
+} | null>(null);
+
 /**
  * Reset all description editor state to initial values (DDR-037).
  * Called by the invalidation cascade when a previous step changes.
@@ -74,6 +84,7 @@ export function resetDescriptionState() {
   feedbackText.value = "";
   isEditing.value = false;
   copyStatus.value = "idle";
+  templateForm.value = null;
 }
 
 // --- Derived state ---
@@ -114,6 +125,7 @@ async function startGeneration() {
       groupLabel: group.label,
       tripContext: tripContext.value,
       economy_mode: economyMode.value,
+      templateId: group.templateId,
     });
 
     descriptionState.value = {
@@ -218,6 +230,105 @@ function copyToClipboard() {
   });
 }
 
+/**
+ * Save the current group's label, caption and hashtags as a post template
+ * (DDR-122). The caption becomes the skeleton later captions follow.
+ */
+async function saveAsTemplate() {
+  const form = templateForm.value;
+  const group = currentGroup.value;
+  const state = descriptionState.value;
+  if (!form || !group || !form.name.trim()) return;
+
+  templateForm.value = { ...form, status: "saving", error: null };
+  try {
+    await saveTemplate({
+      name: form.name.trim(),
+      groupLabel: group.label,
+      captionSkeleton: state.caption,
+      hashtags: state.hashtags,
+      enhancementFeedback: form.enhancementFeedback.trim() || undefined,
+    });
+    templateForm.value = { ...form, status: "saved", error: null };
+    loadPostTemplates(true);
+  } catch (err) {
+    templateForm.value = {
+      ...form,
+      status: "editing",
+      error: err instanceof Error ? err.message : "Failed to save template",
+    };
+  }
+}
+
+function TemplateForm() {
+  const form = templateForm.value;
+  if (!form) return null;
+  if (form.status === "saved") {
+    return (
+      <div class="card" style={{ marginBottom: "1rem", fontSize: "0.875rem", color: "var(--color-success)" }}>
+        Saved template &ldquo;{form.name.trim()}&rdquo;. Pick it for a group in the grouping step of a later session.
+      </div>
+    );
+  }
+
+  const inputStyle = {
+    padding: "0.5rem 0.75rem",
+    fontSize: "0.875rem",
+    border: "1px solid var(--color-border)",
+    borderRadius: "var(--radius)",
+    background: "var(--color-bg)",
+    color: "var(--color-text)",
+  };
+  return (
+    <div class="card" style={{ marginBottom: "1rem" }}>
+      <div style={{ fontSize: "0.875rem", fontWeight: 600, marginBottom: "0.25rem" }}>
+        Save as template
+      </div>
+      <div style={{ fontSize: "0.75rem", color: "var(--color-text-secondary)", marginBottom: "0.5rem" }}>
+        The group label, this caption (as a skeleton to follow) and its hashtags are saved for recurring posts.
+      </div>
+      <div style={{ display: "flex", flexDirection: "column", gap: "0.5rem" }}>
+        <input
+          type="text"
+          value={form.name}
+          maxLength={80}
+          placeholder='Template name, e.g. "Weekly dog photos"'
+          onInput={(e) => {
+            templateForm.value = { ...form, name: (e.target as HTMLInputElement).value };
+          }}
+          style={inputStyle}
+        />
+        <input
+          type="text"
+          value={form.enhancementFeedback}
+          maxLength={500}
+          placeholder="Optional enhancement preset, e.g. warm tones, lift the shadows"
+          onInput={(e) => {
+            templateForm.value = { ...form, enhancementFeedback: (e.target as HTMLInputElement).value };
+          }}
+          style={inputStyle}
+        />
+        {form.error && (
+          <div style={{ fontSize: "0.75rem", color: "var(--color-danger)" }}>{form.error}</div>
+        )}
+        <div style={{ display: "flex", justifyContent: "flex-end", gap: "0.5rem" }}>
+          <button class="outline" onClick={() => { templateForm.value = null; }} style={{ fontSize: "0.875rem" }}>
+            Cancel
+          </button>
+          <button
+            class="primary"
+            disabled={!form.name.trim() || form.status === "saving"}
+            onClick={() => saveAsTemplate()}
+            style={{ fontSize: "0.875rem" }}
+          >
+            {form.status === "saving" ? "Saving..." : "Save Template"}
+          </button>
+        </div>
+      </div>
+    </div>
+  );
+}
+
 function acceptAndContinue() {
   // Save caption data for the current group (used by PublishView)
   const group = currentGroup.value;
@@ -240,6 +351,7 @@ function acceptAndContinue() {
     };
     feedbackText.value = "";
     isEditing.value = false;
+    templateForm.value = null;
   } else {
     // All groups done — proceed to Instagram publishing (DDR-040)
     navigateToStep("instagram-publish");
@@ -710,6 +822,8 @@ export function DescriptionEditor() {
         </>
       )}
 
+      <TemplateForm />
+
       <ActionBar
         left={
           <div style={{ display: "flex", gap: "0.5rem" }}>
@@ -724,6 +838,17 @@ export function DescriptionEditor() {
                 <button class="outline" onClick={() => copyToClipboard()} style={{ fontSize: "0.875rem" }}>
                   {copyStatus.value === "copied" ? "Copied!" : "Copy to Clipboard"}
                 </button>
+                {!templateForm.value && (
+                  <button
+                    class="outline"
+                    onClick={() => {
+                      templateForm.value = { name: "", enhancementFeedback: "", status: "editing", error: null };
+                    }}
+                    style={{ fontSize: "0.875rem" }}
+                  >
+                    Save as Template
+                  </button>
+                )}
                 <button class="primary" onClick={() => acceptAndContinue()} style={{ fontSize: "0.875rem" }}>
                   {hasMoreGroups.value ? "Accept & Next Group" : "Done"}
                 </button>
@@ -1,10 +1,16 @@
 import { signal, computed } from "@preact/signals";
 import { navigateBack, navigateToStep, navigateToLanding, uploadSessionId, economyMode } from "../app";
-import { startDownload, getDownloadResults } from "../api/client";
+import {
+  startDownload,
+  getDownloadResults,
+  retryOmittedDownload,
+  getMetadataPolicy,
+  setMetadataPolicy,
+} from "../api/client";
 import { ActionBar } from "./shared/ActionBar";
 import { GroupCard } from "./download/GroupCard";
 import { postGroups, groupableMedia } from "./PostGrouper";
-import type { PostGroup, DownloadBundle } from "../types/api";
+import type { PostGroup, DownloadBundle, MetadataPolicy } from "../types/api";
 
 // --- State ---
 
@@ -14,6 +20,8 @@ interface GroupDownloadState {
   status: "idle" | "processing" | "complete" | "error";
   bundles: DownloadBundle[];
   error: string | null;
+  /** Files still being restored from archive storage (DDR-115). */
+  restoring?: number;
 }
 
 const downloadStates = signal<Record<string, GroupDownloadState>>({});
@@ -21,6 +29,60 @@ const downloadStates = signal<Record<string, GroupDownloadState>>({});
 /** Which group is currently expanded. */
 const expandedGroupId = signal<string | null>(null);
 
+const PREWARM_KEY = "prewarm_downloads";
+
+function loadPrewarm(): boolean {
+  try {
+    const stored = localStorage.getItem(PREWARM_KEY);
+    if (stored === null) return true; // default ON
+    return stored === "true";
+  } catch {
+    return true;
+  }
+}
+
+/**
+ * DDR-101: Start bundle creation when post groups are confirmed.
+ * Persisted in localStorage.
+ */
+export const prewarmEnabled = signal<boolean>(loadPrewarm());
+
+/** Set the prewarm option and persist it to localStorage. */
+export function setPrewarmEnabled(value: boolean) {
+  prewarmEnabled.value = value;
+  try {
+    localStorage.setItem(PREWARM_KEY, String(value));
+  } catch {
+    // ignore
+  }
+}
+
+/** The session's photo metadata policy (DDR-135); null until loaded. */
+const metadataPolicy = signal<MetadataPolicy | null>(null);
+let metadataPolicySession: string | null = null;
+
+/** Load the metadata policy once per session. */
+function loadMetadataPolicy(sessionId: string) {
+  if (metadataPolicySession === sessionId) return;
+  metadataPolicySession = sessionId;
+  getMetadataPolicy(sessionId)
+    .then((res) => (metadataPolicy.value = res.metadataPolicy))
+    .catch(() => (metadataPolicy.value = "strip-gps"));
+}
+
+/** Save a new metadata policy; bundles prepared afterwards use it. */
+async function changeMetadataPolicy(policy: MetadataPolicy) {
+  const sessionId = uploadSessionId.value;
+  if (!sessionId) return;
+  const previous = metadataPolicy.value;
+  metadataPolicy.value = policy;
+  try {
+    await setMetadataPolicy(sessionId, policy);
+  } catch {
+    metadataPolicy.value = previous;
+  }
+}
+
 /**
  * Reset all download state to initial values (DDR-037).
  * Called by the invalidation cascade when a previous step changes.
@@ -52,7 +114,7 @@ function setGroupState(groupId: string, state: GroupDownloadState) {
 
 // --- Actions ---
 
-async function handleDownload(group: PostGroup) {
+async function handleDownload(group: PostGroup, prewarm = false) {
   const sessionId = uploadSessionId.value;
   if (!sessionId) return;
 
@@ -64,54 +126,115 @@ async function handleDownload(group: PostGroup) {
   });
 
   try {
-    // Start the download job
+    // Start the download job. The server returns the existing job if these
+    // keys were already bundled, e.g. by a prewarm (DDR-101).
     const { id } = await startDownload({
       sessionId,
       keys: group.keys,
       groupLabel: group.label || "media",
       economy_mode: economyMode.value,
+      prewarm,
     });
 
+    await pollDownloadJob(group.id, id, sessionId, []);
+  } catch (err) {
+    if (prewarm) {
+      // A failed prewarm leaves the group for the user to start by hand.
+      console.warn("Download prewarm failed", err);
+      setGroupState(group.id, { jobId: null, status: "idle", bundles: [], error: null });
+      return;
+    }
     setGroupState(group.id, {
-      jobId: id,
-      status: "processing",
+      jobId: null,
+      status: "error",
       bundles: [],
-      error: null,
+      error: err instanceof Error ? err.message : "Download failed",
     });
+  }
+}
 
-    // Poll for results
-    const pollInterval = 2000; // 2 seconds
-    const maxPolls = 150; // 5 minutes max
-
-    for (let i = 0; i < maxPolls; i++) {
-      await new Promise((resolve) => setTimeout(resolve, pollInterval));
+/**
+ * Start bundle creation for every group in the background (DDR-101), so the
+ * ZIPs are ready by the time the user reaches this screen. Called when the
+ * post groups are confirmed; groups that already have a job are skipped.
+ */
+export function prewarmDownloads(groups: PostGroup[]) {
+  if (!prewarmEnabled.value) return;
+  for (const group of groups) {
+    if (getGroupState(group.id).status !== "idle") continue;
+    void handleDownload(group, true);
+  }
+}
 
-      const results = await getDownloadResults(id, sessionId);
+/**
+ * Retry the files a completed job left out of its ZIPs (DDR-097). The
+ * existing bundles stay downloadable; the retry job's bundles are appended.
+ */
+async function handleRetryOmitted(group: PostGroup) {
+  const sessionId = uploadSessionId.value;
+  const state = getGroupState(group.id);
+  if (!sessionId || !state.jobId) return;
 
-      setGroupState(group.id, {
-        jobId: id,
-        status:
-          results.status === "complete" || results.status === "error"
-            ? results.status
-            : "processing",
-        bundles: results.bundles ?? [],
-        error: results.error ?? null,
-      });
+  // The omitted files now belong to the retry job.
+  const previous = state.bundles.map((b) => ({ ...b, omittedFiles: undefined }));
 
-      if (results.status === "complete" || results.status === "error") {
-        break;
-      }
-    }
+  try {
+    const { id } = await retryOmittedDownload(state.jobId, sessionId);
+    await pollDownloadJob(group.id, id, sessionId, previous);
   } catch (err) {
     setGroupState(group.id, {
-      jobId: null,
+      ...state,
       status: "error",
-      bundles: [],
-      error: err instanceof Error ? err.message : "Download failed",
+      error: err instanceof Error ? err.message : "Retry failed",
     });
   }
 }
 
+/** Poll a download job until it finishes, showing its bundles after `previous`. */
+async function pollDownloadJob(
+  groupId: string,
+  id: string,
+  sessionId: string,
+  previous: DownloadBundle[],
+) {
+  setGroupState(groupId, {
+    jobId: id,
+    status: "processing",
+    bundles: previous,
+    error: null,
+  });
+
+  const pollInterval = 2000; // 2 seconds
+  const restorePollInterval = 30000; // restores take minutes to hours (DDR-115)
+  const maxPolls = 150; // 5 minutes max, not counting time spent restoring
+
+  let restoring = false;
+  for (let polls = 0; polls < maxPolls; ) {
+    await new Promise((resolve) =>
+      setTimeout(resolve, restoring ? restorePollInterval : pollInterval),
+    );
+
+    const results = await getDownloadResults(id, sessionId);
+    restoring = results.status === "restoring";
+    if (!restoring) polls++;
+
+    setGroupState(groupId, {
+      jobId: id,
+      status:
+        results.status === "complete" || results.status === "error"
+          ? results.status
+          : "processing",
+      bundles: [...previous, ...(results.bundles ?? [])],
+      error: results.error ?? null,
+      restoring: results.restoringKeys?.length,
+    });
+
+    if (results.status === "complete" || results.status === "error") {
+      break;
+    }
+  }
+}
+
 // --- Summary Stats ---
 
 const totalGroups = computed(() => postGroups.value.length);
@@ -125,6 +248,7 @@ const completedGroups = computed(
 
 export function DownloadView() {
   const groups = postGroups.value;
+  if (uploadSessionId.value) loadMetadataPolicy(uploadSessionId.value);
 
   return (
     <div>
@@ -163,6 +287,22 @@ export function DownloadView() {
           Photos are bundled into one ZIP. Videos are split into bundles
           under 375 MB each for fast downloads.
         </div>
+        <label style={{ fontSize: "0.75rem" }}>
+          Photo metadata:{" "}
+          <select
+            value={metadataPolicy.value ?? "strip-gps"}
+            disabled={metadataPolicy.value === null}
+            onChange={(e) =>
+              changeMetadataPolicy(
+                (e.target as HTMLSelectElement).value as MetadataPolicy,
+              )
+            }
+          >
+            <option value="strip-gps">Remove location</option>
+            <option value="strip-all">Remove all</option>
+            <option value="preserve">Keep all</option>
+          </select>
+        </label>
       </div>
 
       {/* Group list */}
@@ -177,6 +317,7 @@ export function DownloadView() {
             expandedGroupId.value = expandedGroupId.value === group.id ? null : group.id;
           }}
           onDownload={() => handleDownload(group)}
+          onRetryOmitted={() => handleRetryOmitted(group)}
         />
       ))}
 
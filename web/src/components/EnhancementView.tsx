@@ -25,7 +25,8 @@ import type { EnhancementResults } from "../types/api";
 
 // --- State ---
 
-const enhancementJobId = signal<string | null>(null);
+/** Enhancement job of this session; post templates apply their feedback through it (DDR-122). */
+export const enhancementJobId = signal<string | null>(null);
 const results = signal<EnhancementResults | null>(null);
 const error = signal<string | null>(null);
 
@@ -140,8 +141,9 @@ async function handleFeedback() {
 function handleProceed() {
   // Populate groupable media from enhancement results (DDR-033)
   const items = results.value?.items ?? [];
+  // Skipped items move on unchanged (DDR-143).
   groupableMedia.value = items
-    .filter((i) => i.phase === "complete" || i.phase === "feedback")
+    .filter((i) => i.phase === "complete" || i.phase === "feedback" || i.phase === "skipped")
     .map((i) => ({
       key: i.enhancedKey || i.key,
       filename: i.filename,
@@ -1,13 +1,13 @@
 import { signal } from "@preact/signals";
-import { useState } from "preact/hooks";
-import { initTriage, updateTriageFiles, finalizeTriageUploads, getTriageResults, startTriage } from "../api/client";
+import { useEffect, useState } from "preact/hooks";
+import { initTriage, updateTriageFiles, finalizeTriageUploads, getTriageResults, startTriage, getUploadAdvice, isVideoFile, setSessionEncryption } from "../api/client";
 import { createUploadEngine } from "../upload/uploadEngine";
 import { selectedPaths, uploadSessionId, triageJobId, navigateToStep, currentStep, fileHandles, economyMode } from "../app";
 import { syncUrlToStep } from "../router";
 import { getFilesFromDataTransfer } from "../utils/fileSystem";
 import { formatBytes, formatSpeed } from "../utils/format";
 import { formatElapsed } from "../hooks/useElapsedTimer";
-import type { FileProcessingStatus } from "../types/api";
+import type { FileProcessingStatus, UploadAdviceResponse, UploadTypeManifest } from "../types/api";
 import { MiniPipeline, type MiniPipelineStep } from "./shared/MiniPipeline";
 
 // Engine with dedup + speed tracking for triage upload (DDR-080)
@@ -39,6 +39,8 @@ interface FileWithLifecycle {
   error?: string;
   thumbnailUrl?: string;
   converted?: boolean;
+  /** Video compression percent while downsizing (DDR-161). */
+  progress?: number;
 }
 
 const triageInitialized = signal<boolean>(false);
@@ -51,6 +53,39 @@ const serverFileStatuses = signal<FileProcessingStatus[]>([]);
 const serverProcessedCount = signal<number>(0);
 const serverExpectedFileCount = signal<number>(0);
 
+/** Originals-vs-downscaled advice for the current batch (DDR-128). */
+const uploadAdvice = signal<UploadAdviceResponse | null>(null);
+const adviceRequested = signal<boolean>(false);
+
+/** Encrypt the new session under its own key (DDR-164); fixed once files are added. */
+const encryptSession = signal<boolean>(false);
+
+/**
+ * Ask the API whether this batch is worth uploading at full resolution,
+ * using the first measured upload speed. Requested once per batch; failures
+ * are ignored since the advice is only a hint.
+ */
+async function requestUploadAdvice() {
+  adviceRequested.value = true;
+  const images: UploadTypeManifest = { count: 0, bytes: 0 };
+  const videos: UploadTypeManifest = { count: 0, bytes: 0 };
+  for (const f of files.value) {
+    const t = isVideoFile(f.name) ? videos : images;
+    t.count++;
+    t.bytes += f.size;
+  }
+  try {
+    uploadAdvice.value = await getUploadAdvice({
+      workflow: "triage",
+      images,
+      videos,
+      throughputBytesPerSec: uploadSpeed.value,
+    });
+  } catch {
+    // Advice is optional; the upload continues either way.
+  }
+}
+
 function generateSessionId(): string {
   return crypto.randomUUID();
 }
@@ -127,7 +162,17 @@ async function handleBrowseFiles() {
 
 async function addFiles(newFiles: File[]) {
   if (!uploadSessionId.value) {
-    uploadSessionId.value = generateSessionId();
+    const newSessionId = generateSessionId();
+    // DDR-164: the key must exist before the first upload.
+    if (encryptSession.value) {
+      try {
+        await setSessionEncryption(newSessionId, true);
+      } catch (e) {
+        error.value = e instanceof Error ? e.message : "Failed to encrypt session";
+        return;
+      }
+    }
+    uploadSessionId.value = newSessionId;
     syncUrlToStep(currentStep.value, uploadSessionId.value);
   }
 
@@ -331,6 +376,7 @@ function getFilesWithLifecycle(): FileWithLifecycle[] {
           uploadProgress: 100,
           loaded: f.loaded,
           thumbnailUrl: serverStatus.thumbnailUrl,
+          progress: serverStatus.progress,
         };
       }
     }
@@ -354,6 +400,8 @@ function clearAll() {
   serverProcessedCount.value = 0;
   serverExpectedFileCount.value = 0;
   triageFinalized.value = false;
+  uploadAdvice.value = null;
+  adviceRequested.value = false;
 }
 
 /** Reset FileUploader state (called from navigateToLanding — DDR-042). */
@@ -366,6 +414,9 @@ export function resetFileUploaderState() {
   serverProcessedCount.value = 0;
   serverExpectedFileCount.value = 0;
   uploadSessionId.value = null;
+  uploadAdvice.value = null;
+  adviceRequested.value = false;
+  encryptSession.value = false;
 }
 
 /** Proceed to triage: start the triage job and navigate to processing (DDR-042). */
@@ -536,6 +587,7 @@ function FileCard({ f }: { f: FileWithLifecycle }) {
           marginTop: "0.25rem",
         }}>
           {statusCardLabel(f.lifecycleStatus)}
+          {f.lifecycleStatus === "thumbnailed" && f.progress != null && ` ${f.progress}%`}
         </div>
       </div>
     </div>
@@ -635,6 +687,12 @@ export function FileUploader() {
     return Math.ceil(remainingBytes / uploadSpeed.value);
   })();
 
+  const speedMeasured = anyUploading && uploadSpeed.value > 0;
+  useEffect(() => {
+    if (speedMeasured && !adviceRequested.value) requestUploadAdvice();
+  }, [speedMeasured]);
+  const advice = uploadAdvice.value;
+
   function onDragEnter(e: DragEvent) {
     e.preventDefault();
     setDragActive(true);
@@ -713,6 +771,27 @@ export function FileUploader() {
             }}>
               Files are processed securely and not stored permanently
             </p>
+            <label
+              onClick={(e) => e.stopPropagation()}
+              style={{
+                display: "flex",
+                alignItems: "center",
+                gap: "0.5rem",
+                fontSize: "0.75rem",
+                color: "var(--color-text-secondary)",
+                marginTop: "0.5rem",
+              }}
+              title="Files are encrypted with a key made for this session and destroyed when you delete it"
+            >
+              <input
+                type="checkbox"
+                checked={encryptSession.value}
+                onChange={(e) => {
+                  encryptSession.value = (e.target as HTMLInputElement).checked;
+                }}
+              />
+              Encrypt this session with its own key
+            </label>
           </div>
 
           <div class="sidebar-panel">
@@ -953,6 +1032,21 @@ export function FileUploader() {
               </div>
             </div>
 
+            {/* Upload advice (DDR-128) */}
+            {advice?.recommendation === "downscale" && (
+              <div class="sidebar-panel" style={{ border: "1px solid var(--color-warning)" }}>
+                <h3>Slow connection</h3>
+                <p style={{ fontSize: "0.875rem", margin: "0 0 0.5rem" }}>{advice.reason}</p>
+                <p style={{ fontSize: "0.8125rem", margin: 0, color: "var(--color-text-secondary)" }}>
+                  Consider cancelling and exporting
+                  {advice.images.downscale && ` photos at ${advice.images.maxDimension}px`}
+                  {advice.images.downscale && advice.videos.downscale && " and"}
+                  {advice.videos.downscale && ` videos at ${advice.videos.maxDimension}px`}
+                  {" "}on the long edge.
+                </p>
+              </div>
+            )}
+
             {/* Triage status */}
             {triageInitialized.value && (
               <div style={{
@@ -0,0 +1,177 @@
+import { signal } from "@preact/signals";
+import { searchInstagramLocations } from "../api/client";
+import type { PostGroup, InstagramLocation } from "../types/api";
+
+// --- Instagram location tag (DDR-138) ---
+
+/** Location search per post group. */
+interface GroupLocationState {
+  query: string;
+  status: "idle" | "searching" | "done" | "error";
+  results: InstagramLocation[];
+  error: string | null;
+}
+
+const locationStates = signal<Record<string, GroupLocationState>>({});
+
+/** Chosen location per post group. Absent = publish untagged. */
+const chosenLocations = signal<Record<string, InstagramLocation>>({});
+
+/** Reset location searches and choices (called with resetPublishState). */
+export function resetLocationState() {
+  locationStates.value = {};
+  chosenLocations.value = {};
+}
+
+/** The location ID to tag a group's post with, if one was chosen. */
+export function chosenLocationId(groupId: string): string | undefined {
+  return chosenLocations.value[groupId]?.id;
+}
+
+function getLocationState(groupId: string): GroupLocationState {
+  return (
+    locationStates.value[groupId] ?? {
+      query: "",
+      status: "idle",
+      results: [],
+      error: null,
+    }
+  );
+}
+
+function setLocationState(groupId: string, state: GroupLocationState) {
+  locationStates.value = { ...locationStates.value, [groupId]: state };
+}
+
+function chooseLocation(groupId: string, location: InstagramLocation | null) {
+  const chosen = { ...chosenLocations.value };
+  if (location) {
+    chosen[groupId] = location;
+  } else {
+    delete chosen[groupId];
+  }
+  chosenLocations.value = chosen;
+}
+
+async function searchLocations(groupId: string) {
+  const state = getLocationState(groupId);
+  const query = state.query.trim();
+  if (!query) return;
+
+  setLocationState(groupId, { ...state, status: "searching", error: null });
+  try {
+    const { locations } = await searchInstagramLocations(query);
+    setLocationState(groupId, {
+      ...getLocationState(groupId),
+      status: "done",
+      results: locations,
+    });
+  } catch (err) {
+    setLocationState(groupId, {
+      ...getLocationState(groupId),
+      status: "error",
+      results: [],
+      error: err instanceof Error ? err.message : "Location search failed",
+    });
+  }
+}
+
+function locationLabel(location: InstagramLocation): string {
+  return [location.name, location.city, location.country]
+    .filter((part) => part)
+    .join(", ");
+}
+
+/** Location tag search for one post group. */
+export function LocationPicker({ group }: { group: PostGroup }) {
+  const state = getLocationState(group.id);
+  const chosen = chosenLocations.value[group.id];
+
+  if (chosen) {
+    return (
+      <div
+        style={{
+          display: "flex",
+          alignItems: "center",
+          gap: "0.5rem",
+          marginBottom: "0.75rem",
+          fontSize: "0.75rem",
+        }}
+      >
+        <span>Location: {locationLabel(chosen)}</span>
+        <button
+          class="outline"
+          style={{ fontSize: "0.75rem" }}
+          onClick={() => chooseLocation(group.id, null)}
+        >
+          Remove
+        </button>
+      </div>
+    );
+  }
+
+  return (
+    <div style={{ marginBottom: "0.75rem", fontSize: "0.75rem" }}>
+      <form
+        style={{ display: "flex", gap: "0.5rem" }}
+        onSubmit={(e) => {
+          e.preventDefault();
+          searchLocations(group.id);
+        }}
+      >
+        <input
+          type="text"
+          placeholder="Tag a location"
+          value={state.query}
+          maxLength={100}
+          style={{ fontSize: "0.75rem", flex: 1 }}
+          onInput={(e) =>
+            setLocationState(group.id, {
+              ...state,
+              query: (e.target as HTMLInputElement).value,
+            })
+          }
+        />
+        <button
+          type="submit"
+          class="outline"
+          style={{ fontSize: "0.75rem" }}
+          disabled={state.status === "searching" || !state.query.trim()}
+        >
+          {state.status === "searching" ? "Searching..." : "Search"}
+        </button>
+      </form>
+      {state.error && (
+        <div style={{ color: "var(--color-danger)", marginTop: "0.375rem" }}>
+          {state.error}
+        </div>
+      )}
+      {state.status === "done" && state.results.length === 0 && (
+        <div style={{ color: "var(--color-text-secondary)", marginTop: "0.375rem" }}>
+          No taggable locations found.
+        </div>
+      )}
+      {state.results.length > 0 && (
+        <div
+          style={{
+            display: "flex",
+            flexWrap: "wrap",
+            gap: "0.25rem",
+            marginTop: "0.375rem",
+          }}
+        >
+          {state.results.map((location) => (
+            <button
+              key={location.id}
+              class="outline"
+              style={{ fontSize: "0.75rem" }}
+              onClick={() => chooseLocation(group.id, location)}
+            >
+              {locationLabel(location)}
+            </button>
+          ))}
+        </div>
+      )}
+    </div>
+  );
+}
@@ -0,0 +1,313 @@
+import { signal } from "@preact/signals";
+import { thumbnailUrl } from "../api/client";
+import { groupableMedia } from "./PostGrouper";
+import type { PostGroup, GroupableMediaItem, UserTag } from "../types/api";
+
+// --- Instagram user tags and collaborators (DDR-141) ---
+
+/** Instagram's limits, checked again by the API. */
+const MAX_TAGS_PER_PHOTO = 20;
+const MAX_COLLABORATORS = 3;
+const USERNAME_PATTERN = /^[A-Za-z0-9._]{1,30}$/;
+
+/** Tags and collaborators per post group. Tags are keyed by media key. */
+interface GroupPeopleState {
+  tags: Record<string, UserTag[]>;
+  collaborators: string[];
+  /** Photo being tagged and where it was clicked, awaiting a username. */
+  pending: { key: string; x: number; y: number } | null;
+  username: string;
+  collaboratorInput: string;
+  error: string | null;
+}
+
+const peopleStates = signal<Record<string, GroupPeopleState>>({});
+
+/** Reset tags and collaborators (called with resetPublishState). */
+export function resetPeopleTagState() {
+  peopleStates.value = {};
+}
+
+/** Tags for each of a group's items, in key order, or undefined when none. */
+export function groupUserTags(group: PostGroup): UserTag[][] | undefined {
+  const tags = getPeopleState(group.id).tags;
+  if (!Object.values(tags).some((t) => t.length > 0)) return undefined;
+  return group.keys.map((key) => tags[key] ?? []);
+}
+
+/** Collaborators invited to a group's post, or undefined when none. */
+export function groupCollaborators(groupId: string): string[] | undefined {
+  const collaborators = getPeopleState(groupId).collaborators;
+  return collaborators.length > 0 ? collaborators : undefined;
+}
+
+function getPeopleState(groupId: string): GroupPeopleState {
+  return (
+    peopleStates.value[groupId] ?? {
+      tags: {},
+      collaborators: [],
+      pending: null,
+      username: "",
+      collaboratorInput: "",
+      error: null,
+    }
+  );
+}
+
+function setPeopleState(groupId: string, state: GroupPeopleState) {
+  peopleStates.value = { ...peopleStates.value, [groupId]: state };
+}
+
+function normalizeUsername(input: string): string {
+  return input.trim().replace(/^@/, "");
+}
+
+function addTag(groupId: string) {
+  const state = getPeopleState(groupId);
+  if (!state.pending) return;
+  const username = normalizeUsername(state.username);
+  const existing = state.tags[state.pending.key] ?? [];
+  let error: string | null = null;
+  if (!USERNAME_PATTERN.test(username)) {
+    error = "Enter a valid Instagram username.";
+  } else if (existing.some((t) => t.username.toLowerCase() === username.toLowerCase())) {
+    error = `@${username} is already tagged on this photo.`;
+  } else if (existing.length >= MAX_TAGS_PER_PHOTO) {
+    error = `At most ${MAX_TAGS_PER_PHOTO} people can be tagged in one photo.`;
+  }
+  if (error) {
+    setPeopleState(groupId, { ...state, error });
+    return;
+  }
+  const { key, x, y } = state.pending;
+  setPeopleState(groupId, {
+    ...state,
+    tags: { ...state.tags, [key]: [...existing, { username, x, y }] },
+    pending: null,
+    username: "",
+    error: null,
+  });
+}
+
+function removeTag(groupId: string, key: string, username: string) {
+  const state = getPeopleState(groupId);
+  setPeopleState(groupId, {
+    ...state,
+    tags: {
+      ...state.tags,
+      [key]: (state.tags[key] ?? []).filter((t) => t.username !== username),
+    },
+  });
+}
+
+function addCollaborator(groupId: string) {
+  const state = getPeopleState(groupId);
+  const username = normalizeUsername(state.collaboratorInput);
+  let error: string | null = null;
+  if (!USERNAME_PATTERN.test(username)) {
+    error = "Enter a valid Instagram username.";
+  } else if (state.collaborators.some((c) => c.toLowerCase() === username.toLowerCase())) {
+    error = `@${username} is already invited.`;
+  } else if (state.collaborators.length >= MAX_COLLABORATORS) {
+    error = `At most ${MAX_COLLABORATORS} collaborators can be invited.`;
+  }
+  if (error) {
+    setPeopleState(groupId, { ...state, error });
+    return;
+  }
+  setPeopleState(groupId, {
+    ...state,
+    collaborators: [...state.collaborators, username],
+    collaboratorInput: "",
+    error: null,
+  });
+}
+
+function removeCollaborator(groupId: string, username: string) {
+  const state = getPeopleState(groupId);
+  setPeopleState(groupId, {
+    ...state,
+    collaborators: state.collaborators.filter((c) => c !== username),
+  });
+}
+
+const chipStyle = {
+  display: "inline-flex",
+  alignItems: "center",
+  gap: "0.25rem",
+  padding: "0.125rem 0.375rem",
+  background: "var(--color-primary-light)",
+  color: "var(--color-primary)",
+  borderRadius: "3px",
+};
+
+/**
+ * People tagging for one post group: click a photo to tag someone where they
+ * appear, and invite collaborators to co-author the post. Videos in a
+ * carousel cannot be tagged, so they are not offered.
+ */
+export function PeopleTagger({ group }: { group: PostGroup }) {
+  const state = getPeopleState(group.id);
+  const carousel = group.keys.length > 1;
+  const items = group.keys
+    .map((key) => groupableMedia.value.find((m) => m.key === key))
+    .filter((m): m is GroupableMediaItem => m !== undefined)
+    .filter((m) => m.type === "Photo" || !carousel);
+
+  return (
+    <div style={{ marginBottom: "0.75rem", fontSize: "0.75rem" }}>
+      {items.length > 0 && (
+        <>
+          <div style={{ color: "var(--color-text-secondary)", marginBottom: "0.375rem" }}>
+            Click a photo to tag someone.
+          </div>
+          <div style={{ display: "flex", flexWrap: "wrap", gap: "0.5rem" }}>
+            {items.map((item) => {
+              const tags = state.tags[item.key] ?? [];
+              const pending = state.pending?.key === item.key ? state.pending : null;
+              return (
+                <div key={item.key} style={{ width: "7rem" }}>
+                  <div
+                    style={{
+                      position: "relative",
+                      width: "7rem",
+                      height: "7rem",
+                      borderRadius: "3px",
+                      overflow: "hidden",
+                      background: "var(--color-surface-hover)",
+                      cursor: "crosshair",
+                    }}
+                    onClick={(e) => {
+                      const rect = (e.currentTarget as HTMLElement).getBoundingClientRect();
+                      const x = Math.min(1, Math.max(0, (e.clientX - rect.left) / rect.width));
+                      const y = Math.min(1, Math.max(0, (e.clientY - rect.top) / rect.height));
+                      setPeopleState(group.id, {
+                        ...state,
+                        pending: { key: item.key, x: Number(x.toFixed(3)), y: Number(y.toFixed(3)) },
+                        error: null,
+                      });
+                    }}
+                  >
+                    <img
+                      src={thumbnailUrl(item.thumbnailKey)}
+                      alt=""
+                      style={{ width: "100%", height: "100%", objectFit: "contain", display: "block" }}
+                    />
+                    {[...tags, ...(pending ? [{ username: "", ...pending }] : [])].map((tag) => (
+                      <span
+                        key={tag.username || "pending"}
+                        title={tag.username ? `@${tag.username}` : "New tag"}
+                        style={{
+                          position: "absolute",
+                          left: `${tag.x * 100}%`,
+                          top: `${tag.y * 100}%`,
+                          width: "0.5rem",
+                          height: "0.5rem",
+                          marginLeft: "-0.25rem",
+                          marginTop: "-0.25rem",
+                          borderRadius: "50%",
+                          background: tag.username ? "var(--color-primary)" : "#fff",
+                          border: "1px solid rgba(0,0,0,0.6)",
+                        }}
+                      />
+                    ))}
+                  </div>
+                  {pending && (
+                    <form
+                      style={{ display: "flex", gap: "0.25rem", marginTop: "0.25rem" }}
+                      onSubmit={(e) => {
+                        e.preventDefault();
+                        addTag(group.id);
+                      }}
+                    >
+                      <input
+                        type="text"
+                        placeholder="@username"
+                        value={state.username}
+                        maxLength={31}
+                        style={{ fontSize: "0.75rem", flex: 1, minWidth: 0 }}
+                        onInput={(e) =>
+                          setPeopleState(group.id, {
+                            ...state,
+                            username: (e.target as HTMLInputElement).value,
+                          })
+                        }
+                      />
+                      <button type="submit" class="outline" style={{ fontSize: "0.75rem" }}>
+                        Tag
+                      </button>
+                    </form>
+                  )}
+                  <div style={{ display: "flex", flexWrap: "wrap", gap: "0.25rem", marginTop: "0.25rem" }}>
+                    {tags.map((tag) => (
+                      <span key={tag.username} style={chipStyle}>
+                        @{tag.username}
+                        <button
+                          class="outline"
+                          style={{ fontSize: "0.625rem", padding: "0 0.25rem" }}
+                          onClick={() => removeTag(group.id, item.key, tag.username)}
+                        >
+                          ×
+                        </button>
+                      </span>
+                    ))}
+                  </div>
+                </div>
+              );
+            })}
+          </div>
+        </>
+      )}
+
+      <form
+        style={{ display: "flex", gap: "0.5rem", marginTop: "0.5rem" }}
+        onSubmit={(e) => {
+          e.preventDefault();
+          addCollaborator(group.id);
+        }}
+      >
+        <input
+          type="text"
+          placeholder="Invite a collaborator (@username)"
+          value={state.collaboratorInput}
+          maxLength={31}
+          style={{ fontSize: "0.75rem", flex: 1 }}
+          disabled={state.collaborators.length >= MAX_COLLABORATORS}
+          onInput={(e) =>
+            setPeopleState(group.id, {
+              ...state,
+              collaboratorInput: (e.target as HTMLInputElement).value,
+            })
+          }
+        />
+        <button
+          type="submit"
+          class="outline"
+          style={{ fontSize: "0.75rem" }}
+          disabled={!state.collaboratorInput.trim() || state.collaborators.length >= MAX_COLLABORATORS}
+        >
+          Invite
+        </button>
+      </form>
+      {state.collaborators.length > 0 && (
+        <div style={{ display: "flex", flexWrap: "wrap", gap: "0.25rem", marginTop: "0.375rem" }}>
+          {state.collaborators.map((username) => (
+            <span key={username} style={chipStyle}>
+              Collaborator @{username}
+              <button
+                class="outline"
+                style={{ fontSize: "0.625rem", padding: "0 0.25rem" }}
+                onClick={() => removeCollaborator(group.id, username)}
+              >
+                ×
+              </button>
+            </span>
+          ))}
+        </div>
+      )}
+      {state.error && (
+        <div style={{ color: "var(--color-danger)", marginTop: "0.375rem" }}>{state.error}</div>
+      )}
+    </div>
+  );
+}
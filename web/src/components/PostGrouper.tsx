@@ -2,8 +2,10 @@ import { computed } from "@preact/signals";
 import { navigateBack, navigateToStep, navigateToLanding } from "../app";
 import type { GroupableMediaItem } from "../types/api";
 import { ActionBar } from "./shared/ActionBar";
+import { prewarmDownloads, prewarmEnabled, setPrewarmEnabled } from "./DownloadView";
 import { MediaThumbnail } from "./post-grouper/MediaThumbnail";
 import { GroupIcon, NewGroupButton } from "./post-grouper/GroupIcon";
+import { TemplatePicker } from "./post-grouper/TemplatePicker";
 import {
   postGroups,
   groupableMedia,
@@ -125,6 +127,7 @@ function handleProceed() {
   const nonEmpty = postGroups.value.filter((g) => g.keys.length > 0);
   if (nonEmpty.length === 0) return;
   postGroups.value = nonEmpty;
+  prewarmDownloads(nonEmpty); // DDR-101
   navigateToStep("publish");
 }
 
@@ -377,6 +380,7 @@ export function PostGrouper() {
               >
                 Group label — describe this post (used for AI caption generation)
               </div>
+              <TemplatePicker group={currentGroup} />
               <textarea
                 value={currentGroup.label}
                 onInput={(e) =>
@@ -492,7 +496,18 @@ export function PostGrouper() {
           </span>
         }
         right={
-          <div style={{ display: "flex", gap: "0.75rem" }}>
+          <div style={{ display: "flex", gap: "0.75rem", alignItems: "center" }}>
+            <label
+              title="Start creating ZIP bundles as soon as you continue, so downloads are ready sooner"
+              style={{ display: "flex", alignItems: "center", gap: "0.375rem", fontSize: "0.75rem", color: "var(--color-text-secondary)" }}
+            >
+              <input
+                type="checkbox"
+                checked={prewarmEnabled.value}
+                onChange={() => setPrewarmEnabled(!prewarmEnabled.value)}
+              />
+              Prepare downloads in background
+            </label>
             <button class="outline" onClick={handleBack}>Back to Enhancement</button>
             <button class="outline" onClick={navigateToLanding}>Start Over</button>
             <button class="primary" onClick={handleProceed} disabled={nonEmptyGroups.length === 0}>
@@ -12,11 +12,16 @@ import { ElapsedTimer } from "./ProcessingIndicator";
 import {
   startPublish,
   getPublishStatus,
+  decidePublish,
   getHealth,
   thumbnailUrl,
 } from "../api/client";
 import { postGroups, groupableMedia } from "./PostGrouper";
-import type { PostGroup, GroupableMediaItem, PublishStatus } from "../types/api";
+import { CropPicker, resolvePublishKeys, resetCropState } from "./CropPicker";
+import { LocationPicker, chosenLocationId, resetLocationState } from "./LocationPicker";
+import { PeopleTagger, groupUserTags, groupCollaborators, resetPeopleTagState } from "./PeopleTagger";
+import { currentSelectionJobId } from "./SelectionView";
+import type { PostGroup, GroupableMediaItem, PublishStatus, PublishItemError } from "../types/api";
 
 // --- State ---
 
@@ -28,6 +33,14 @@ interface GroupPublishState {
   progress: { completed: number; total: number };
   instagramPostId: string | null;
   error: string | null;
+  /** Items that fail Instagram's media requirements (DDR-129). */
+  itemErrors: PublishItemError[];
+  /** Token for the approval link, when the job is held for approval (DDR-121). */
+  approvalToken: string | null;
+  /** Published against a simulated Instagram; nothing was posted (DDR-139). */
+  dryRun: boolean;
+  /** Why the hashtag comment failed after the post went up (DDR-163). */
+  firstCommentError: string | null;
   /** Caption and hashtags from the description step (stored for the publish request). */
   caption: string;
   hashtags: string[];
@@ -35,15 +48,30 @@ interface GroupPublishState {
 
 const publishStates = signal<Record<string, GroupPublishState>>({});
 
+/** Hold new publish jobs until a second person approves them (DDR-121). */
+const requireApproval = signal(false);
+
+/** Run new publish jobs against a simulated Instagram (DDR-139). */
+const dryRun = signal(false);
+
+/** Post the hashtags as the first comment instead of in the caption (DDR-163). */
+const hashtagsInComment = signal(false);
+
 /** Whether the backend has Instagram credentials configured. */
 const instagramConfigured = signal<boolean | null>(null); // null = not yet checked
 
+/** Whether the whole deployment simulates Instagram (INSTAGRAM_MODE=mock). */
+const instagramMock = signal(false);
+
 /**
  * Reset all publish state to initial values (DDR-037).
  * Called by the invalidation cascade when a previous step changes.
  */
 export function resetPublishState() {
   publishStates.value = {};
+  resetCropState();
+  resetLocationState();
+  resetPeopleTagState();
 }
 
 /** Check if Instagram is configured on the backend (called once on mount). */
@@ -52,6 +80,7 @@ async function checkInstagramStatus() {
   try {
     const health = await getHealth();
     instagramConfigured.value = health.instagramConfigured;
+    instagramMock.value = health.instagramMock ?? false;
   } catch {
     instagramConfigured.value = false;
   }
@@ -84,6 +113,10 @@ function getGroupState(groupId: string): GroupPublishState {
       progress: { completed: 0, total: 0 },
       instagramPostId: null,
       error: null,
+      itemErrors: [],
+      approvalToken: null,
+      dryRun: false,
+      firstCommentError: null,
       caption: "",
       hashtags: [],
     }
@@ -110,16 +143,24 @@ const publishedGroups = computed(
 
 function phaseLabel(phase: string): string {
   switch (phase) {
+    case "preparing_media":
+      return "Checking media against Instagram's requirements...";
     case "creating_containers":
       return "Uploading media to Instagram...";
     case "processing_videos":
       return "Processing videos on Instagram...";
+    case "awaiting_approval":
+      return "Waiting for approval...";
     case "creating_carousel":
       return "Creating carousel post...";
     case "publishing":
       return "Publishing...";
+    case "commenting":
+      return "Posting hashtags as the first comment...";
     case "published":
       return "Published!";
+    case "partially_published":
+      return "Published to some platforms; see the error for the rest.";
     default:
       return "Preparing...";
   }
@@ -141,29 +182,47 @@ async function handlePublish(group: PostGroup) {
     progress: { completed: 0, total: group.keys.length },
     instagramPostId: null,
     error: null,
+    itemErrors: [],
+    approvalToken: null,
+    dryRun: dryRun.value,
+    firstCommentError: null,
   });
 
   try {
-    const { id } = await startPublish({
+    const keys = await resolvePublishKeys(group);
+    const { id, approvalToken } = await startPublish({
       sessionId,
       groupId: group.id,
-      keys: group.keys,
+      keys,
       caption: state.caption,
       hashtags: state.hashtags,
       economy_mode: economyMode.value,
+      requireApproval: requireApproval.value,
+      locationId: chosenLocationId(group.id),
+      userTags: groupUserTags(group),
+      collaborators: groupCollaborators(group.id),
+      dryRun: dryRun.value,
+      hashtagsInComment: hashtagsInComment.value && state.hashtags.length > 0,
+      selectionJobId: currentSelectionJobId() ?? undefined,
     });
 
     setGroupState(group.id, {
       ...getGroupState(group.id),
       jobId: id,
+      approvalToken: approvalToken ?? null,
     });
 
     // Poll for status
     const pollInterval = 3000;
-    const maxPolls = 200; // 10 minutes max
+    const approvalPollInterval = 15000;
+    const maxPolls = 200; // 10 minutes max, not counting time awaiting approval
 
     for (let i = 0; i < maxPolls; i++) {
-      await new Promise((resolve) => setTimeout(resolve, pollInterval));
+      const awaiting = getGroupState(group.id).phase === "awaiting_approval";
+      await new Promise((resolve) =>
+        setTimeout(resolve, awaiting ? approvalPollInterval : pollInterval),
+      );
+      if (awaiting) i--;
 
       const result: PublishStatus = await getPublishStatus(id, sessionId);
 
@@ -172,18 +231,26 @@ async function handlePublish(group: PostGroup) {
         ...currentState,
         jobId: id,
         status:
-          result.status === "published"
+          result.status === "published" ||
+          result.status === "partially_published"
             ? "published"
-            : result.status === "error"
+            : result.status === "error" || result.status === "rejected"
               ? "error"
               : "publishing",
         phase: result.phase,
         progress: result.progress,
         instagramPostId: result.instagramPostId ?? null,
         error: result.error ?? null,
+        itemErrors: result.itemErrors ?? [],
+        firstCommentError: result.firstCommentError ?? null,
       });
 
-      if (result.status === "published" || result.status === "error") {
+      if (
+        result.status === "published" ||
+        result.status === "partially_published" ||
+        result.status === "error" ||
+        result.status === "rejected"
+      ) {
         break;
       }
     }
@@ -196,8 +263,120 @@ async function handlePublish(group: PostGroup) {
   }
 }
 
+/** Link that lets someone else approve a held publish job (DDR-121). */
+function approvalLink(jobId: string, token: string): string {
+  const session = encodeURIComponent(uploadSessionId.value ?? "");
+  return `${window.location.origin}/select/publish?session=${session}&approvePublish=${encodeURIComponent(jobId)}&token=${encodeURIComponent(token)}`;
+}
+
 // --- Sub-components ---
 
+/** Approval request opened from an approval link (DDR-121). */
+const approvalRequest = signal<{
+  jobId: string;
+  token: string;
+  status: PublishStatus | null;
+  decided: string | null;
+  error: string | null;
+} | null>(null);
+
+async function loadApprovalRequest() {
+  const params = new URLSearchParams(window.location.search);
+  const jobId = params.get("approvePublish");
+  const sessionId = uploadSessionId.value;
+  if (!jobId || !sessionId) return;
+  const token = params.get("token") ?? "";
+  approvalRequest.value = { jobId, token, status: null, decided: null, error: null };
+  try {
+    const status = await getPublishStatus(jobId, sessionId);
+    approvalRequest.value = { ...approvalRequest.value!, status };
+  } catch (err) {
+    approvalRequest.value = {
+      ...approvalRequest.value!,
+      error: err instanceof Error ? err.message : "Could not load the publish job",
+    };
+  }
+}
+
+async function handleDecision(decision: "approve" | "reject") {
+  const req = approvalRequest.value;
+  const sessionId = uploadSessionId.value;
+  if (!req || !sessionId) return;
+  try {
+    await decidePublish(req.jobId, sessionId, decision, req.token || undefined);
+    approvalRequest.value = {
+      ...req,
+      decided: decision === "approve" ? "Approved — the post will go live shortly." : "Rejected — the post will not be published.",
+      error: null,
+    };
+  } catch (err) {
+    approvalRequest.value = {
+      ...req,
+      error: err instanceof Error ? err.message : "Could not record the decision",
+    };
+  }
+}
+
+function ApprovalPanel() {
+  const req = approvalRequest.value;
+  if (!req) return null;
+  const approval = req.status?.approval;
+  const pending = approval?.status === "pending" && !req.decided;
+
+  return (
+    <div class="card" style={{ marginBottom: "1rem", border: "1px solid var(--color-primary)" }}>
+      <h3 style={{ margin: "0 0 0.5rem", fontSize: "1rem" }}>Approval requested</h3>
+      {approval && (
+        <>
+          <div style={{ fontSize: "0.75rem", color: "var(--color-text-secondary)", marginBottom: "0.5rem" }}>
+            {approval.keys.length} item{approval.keys.length !== 1 ? "s" : ""}
+            {approval.status !== "pending" && ` — ${approval.status}`}
+          </div>
+          <div
+            style={{
+              padding: "0.5rem 0.75rem",
+              background: "var(--color-bg)",
+              borderRadius: "var(--radius)",
+              border: "1px solid var(--color-border)",
+              fontSize: "0.875rem",
+              lineHeight: "1.5",
+              whiteSpace: "pre-wrap",
+              marginBottom: "0.75rem",
+            }}
+          >
+            {approval.caption || "(no caption)"}
+          </div>
+        </>
+      )}
+      {req.status && !approval && (
+        <div style={{ fontSize: "0.875rem", marginBottom: "0.5rem" }}>
+          This publish job does not need approval.
+        </div>
+      )}
+      {req.decided && (
+        <div style={{ fontSize: "0.875rem", color: "var(--color-success)", marginBottom: "0.5rem" }}>
+          {req.decided}
+        </div>
+      )}
+      {req.error && (
+        <div style={{ fontSize: "0.875rem", color: "var(--color-danger)", marginBottom: "0.5rem" }}>
+          {req.error}
+        </div>
+      )}
+      {pending && (
+        <div style={{ display: "flex", justifyContent: "flex-end", gap: "0.75rem" }}>
+          <button class="outline" onClick={() => handleDecision("reject")}>
+            Reject
+          </button>
+          <button class="primary" onClick={() => handleDecision("approve")}>
+            Approve and publish
+          </button>
+        </div>
+      )}
+    </div>
+  );
+}
+
 function GroupPublishCard({ group }: { group: PostGroup }) {
   const state = getGroupState(group.id);
   const isIdle = state.status === "idle";
@@ -442,6 +621,15 @@ function GroupPublishCard({ group }: { group: PostGroup }) {
         </div>
       )}
 
+      {/* Location tag (DDR-138) */}
+      {isIdle && <LocationPicker group={group} />}
+
+      {/* People tags and collaborators (DDR-141) */}
+      {isIdle && <PeopleTagger group={group} />}
+
+      {/* Crop suggestions (DDR-130) */}
+      {isIdle && <CropPicker group={group} />}
+
       {/* Publishing progress — DDR-056 elapsed timer */}
       {isPublishing && (
         <div
@@ -509,6 +697,33 @@ function GroupPublishCard({ group }: { group: PostGroup }) {
             }}
           />
           <style>{`@keyframes spin { to { transform: rotate(360deg); } }`}</style>
+          {state.jobId && state.approvalToken && state.phase !== "publishing" && (
+            <div
+              style={{
+                marginTop: "0.5rem",
+                fontSize: "0.75rem",
+                color: "var(--color-text-secondary)",
+                display: "flex",
+                alignItems: "center",
+                gap: "0.5rem",
+              }}
+            >
+              <span style={{ flex: 1 }}>
+                Send this link to the person who should approve the post.
+              </span>
+              <button
+                class="outline"
+                style={{ fontSize: "0.75rem" }}
+                onClick={() =>
+                  navigator.clipboard.writeText(
+                    approvalLink(state.jobId!, state.approvalToken!),
+                  )
+                }
+              >
+                Copy approval link
+              </button>
+            </div>
+          )}
         </div>
       )}
 
@@ -531,9 +746,11 @@ function GroupPublishCard({ group }: { group: PostGroup }) {
               fontWeight: 500,
             }}
           >
-            Successfully published to Instagram
+            {state.dryRun || instagramMock.value
+              ? "Simulated publish finished — nothing was posted"
+              : "Successfully published to Instagram"}
           </span>
-          {state.instagramPostId && (
+          {state.instagramPostId && !state.dryRun && !instagramMock.value && (
             <a
               href={`https://www.instagram.com/p/${state.instagramPostId}/`}
               target="_blank"
@@ -550,6 +767,17 @@ function GroupPublishCard({ group }: { group: PostGroup }) {
           )}
         </div>
       )}
+      {state.status === "published" && state.firstCommentError && (
+        <div
+          style={{
+            marginTop: "0.5rem",
+            fontSize: "0.75rem",
+            color: "var(--color-warning)",
+          }}
+        >
+          The post is up, but the hashtag comment failed: {state.firstCommentError}
+        </div>
+      )}
 
       {/* Error state */}
       {isError && (
@@ -570,6 +798,22 @@ function GroupPublishCard({ group }: { group: PostGroup }) {
           >
             {state.error}
           </div>
+          {state.itemErrors.length > 1 && (
+            <ul
+              style={{
+                fontSize: "0.8125rem",
+                color: "var(--color-text-secondary)",
+                margin: "0 0 0.5rem",
+                paddingLeft: "1.25rem",
+              }}
+            >
+              {state.itemErrors.map((e) => (
+                <li key={e.key}>
+                  Item {e.index + 1} ({e.key.split("/").pop()}): {e.error}
+                </li>
+              ))}
+            </ul>
+          )}
           <button
             class="outline"
             onClick={() => handlePublish(group)}
@@ -583,7 +827,7 @@ function GroupPublishCard({ group }: { group: PostGroup }) {
       {/* Publish button */}
       {isIdle && (
         <div style={{ display: "flex", alignItems: "center", justifyContent: "flex-end", gap: "0.75rem" }}>
-          {instagramConfigured.value === false && (
+          {instagramConfigured.value === false && !dryRun.value && (
             <span
               style={{
                 fontSize: "0.75rem",
@@ -596,14 +840,14 @@ function GroupPublishCard({ group }: { group: PostGroup }) {
           <button
             class="primary"
             onClick={() => handlePublish(group)}
-            disabled={!instagramConfigured.value}
+            disabled={!instagramConfigured.value && !dryRun.value}
             style={{
               fontSize: "0.875rem",
-              opacity: instagramConfigured.value ? 1 : 0.4,
-              cursor: instagramConfigured.value ? "pointer" : "not-allowed",
+              opacity: instagramConfigured.value || dryRun.value ? 1 : 0.4,
+              cursor: instagramConfigured.value || dryRun.value ? "pointer" : "not-allowed",
             }}
           >
-            Publish to Instagram
+            {dryRun.value ? "Simulate publish" : "Publish to Instagram"}
           </button>
         </div>
       )}
@@ -619,10 +863,29 @@ export function PublishView() {
   // Check if Instagram is configured on mount
   useEffect(() => {
     checkInstagramStatus();
+    loadApprovalRequest();
   }, []);
 
   return (
     <div>
+      <ApprovalPanel />
+
+      {/* Mock mode banner (DDR-139) */}
+      {instagramMock.value && (
+        <div
+          class="card"
+          style={{
+            marginBottom: "1rem",
+            padding: "0.75rem 1rem",
+            background: "rgba(255, 200, 50, 0.08)",
+            border: "1px solid rgba(255, 200, 50, 0.25)",
+            fontSize: "0.875rem",
+          }}
+        >
+          Instagram mock mode: publishing is simulated and nothing will be posted.
+        </div>
+      )}
+
       {/* Instagram not configured banner */}
       {instagramConfigured.value === false && (
         <div
@@ -684,6 +947,63 @@ export function PublishView() {
         >
           Each group is published as an Instagram carousel (or single post for 1 item).
         </div>
+        <label
+          style={{
+            display: "flex",
+            alignItems: "center",
+            gap: "0.375rem",
+            fontSize: "0.75rem",
+            color: "var(--color-text-secondary)",
+            cursor: "pointer",
+          }}
+        >
+          <input
+            type="checkbox"
+            checked={requireApproval.value}
+            onChange={(e) => {
+              requireApproval.value = (e.target as HTMLInputElement).checked;
+            }}
+          />
+          Require a second approval before posting
+        </label>
+        <label
+          style={{
+            display: "flex",
+            alignItems: "center",
+            gap: "0.375rem",
+            fontSize: "0.75rem",
+            color: "var(--color-text-secondary)",
+            cursor: "pointer",
+          }}
+        >
+          <input
+            type="checkbox"
+            checked={dryRun.value}
+            onChange={(e) => {
+              dryRun.value = (e.target as HTMLInputElement).checked;
+            }}
+          />
+          Dry run (simulate Instagram, post nothing)
+        </label>
+        <label
+          style={{
+            display: "flex",
+            alignItems: "center",
+            gap: "0.375rem",
+            fontSize: "0.75rem",
+            color: "var(--color-text-secondary)",
+            cursor: "pointer",
+          }}
+        >
+          <input
+            type="checkbox"
+            checked={hashtagsInComment.value}
+            onChange={(e) => {
+              hashtagsInComment.value = (e.target as HTMLInputElement).checked;
+            }}
+          />
+          Post hashtags as the first comment
+        </label>
       </div>
 
       {/* Group cards */}
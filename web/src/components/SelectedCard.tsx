@@ -127,6 +127,20 @@ export function SelectedCard({
         >
           {item.justification}
         </div>
+        {item.scores && (
+          <div
+            style={{
+              fontSize: "0.6875rem",
+              fontFamily: "var(--font-mono)",
+              color: "var(--color-text-secondary)",
+              marginTop: "0.25rem",
+            }}
+            title="Composition / story value / uniqueness / technical quality, 1–10 (DDR-150)"
+          >
+            C{item.scores.composition} S{item.scores.storyValue} U
+            {item.scores.uniqueness} T{item.scores.technicalQuality}
+          </div>
+        )}
         {item.comparisonNote && (
           <div
             style={{
@@ -139,6 +153,35 @@ export function SelectedCard({
             {item.comparisonNote}
           </div>
         )}
+        {/* Reels highlight clips (DDR-187) */}
+        {item.highlights && item.highlights.length > 0 && (
+          <div
+            style={{
+              display: "flex",
+              flexWrap: "wrap",
+              gap: "0.25rem",
+              marginTop: "0.25rem",
+            }}
+          >
+            {item.highlights.map((h, i) => (
+              <button
+                key={h.key}
+                class="outline"
+                title={h.reason}
+                onClick={() =>
+                  openMediaPlayer(
+                    h.key,
+                    "Video",
+                    `${item.filename} — highlight ${i + 1}`,
+                  )
+                }
+                style={{ padding: "0.125rem 0.375rem", fontSize: "0.6875rem" }}
+              >
+                ▶ {formatClipTime(h.startMs)}–{formatClipTime(h.endMs)}
+              </button>
+            ))}
+          </div>
+        )}
         {/* Remove from selection button */}
         <button
           class="outline"
@@ -156,3 +199,9 @@ export function SelectedCard({
     </div>
   );
 }
+
+/** Format a clip offset as m:ss. */
+function formatClipTime(ms: number): string {
+  const total = Math.floor(ms / 1000);
+  return `${Math.floor(total / 60)}:${String(total % 60).padStart(2, "0")}`;
+}
@@ -1,6 +1,8 @@
 import { signal } from "@preact/signals";
+import { useEffect } from "preact/hooks";
 import { selectedPaths, triageJobId, uploadSessionId, setStep, economyMode } from "../app";
 import { startTriage, isCloudMode } from "../api/client";
+import { appStatus, loadStatus, PreflightWarnings } from "./StatusBar";
 
 const loading = signal(false);
 const error = signal<string | null>(null);
@@ -35,6 +37,11 @@ function basename(pathOrKey: string): string {
 export function SelectedFiles() {
   const items = selectedPaths.value;
 
+  // Preflight check of the local server before a long run (DDR-125).
+  useEffect(() => {
+    if (!isCloudMode && items.length > 0) loadStatus(items[0]);
+  }, [items]);
+
   return (
     <div class="card">
       <h2>Files to triage</h2>
@@ -75,6 +82,8 @@ export function SelectedFiles() {
         ))}
       </div>
 
+      {!isCloudMode && <PreflightWarnings status={appStatus.value} />}
+
       {error.value && (
         <div
           style={{
@@ -29,6 +29,9 @@ import type {
   ExcludedItem,
   SelectionSceneGroup,
   SelectionResults,
+  SelectionSortCriterion,
+  UnprocessedItem,
+  SelectionDiff,
 } from "../types/api";
 
 // --- State ---
@@ -48,6 +51,14 @@ const excludedExpanded = signal(false);
 /** Whether the scene groups section is expanded. */
 const scenesExpanded = signal(false);
 
+/** Criterion the selected items are sorted by; null keeps the AI rank (DDR-150). */
+const sortCriterion = signal<SelectionSortCriterion | null>(null);
+
+/** The current selection job, whose picks carry alt-text for publish (DDR-154). */
+export function currentSelectionJobId(): string | null {
+  return selectionJobId.value;
+}
+
 /**
  * Reset all selection state to initial values (DDR-037).
  * Called by the invalidation cascade when a previous step changes.
@@ -60,6 +71,26 @@ export function resetSelectionState() {
   removedFromSelection.value = new Set();
   excludedExpanded.value = false;
   scenesExpanded.value = false;
+  sortCriterion.value = null;
+}
+
+/** An item's score for a criterion; items without scores sort last. */
+function criterionScore(item: SelectionItem, criterion: SelectionSortCriterion): number {
+  const s = item.scores;
+  if (!s) return -1;
+  if (criterion === "overall") {
+    return s.composition + s.storyValue + s.uniqueness + s.technicalQuality;
+  }
+  return s[criterion];
+}
+
+/** Sort selected items by the chosen criterion, highest first; ties keep rank order. */
+function sortSelected(items: SelectionItem[]): SelectionItem[] {
+  const criterion = sortCriterion.value;
+  if (!criterion) return items;
+  return [...items].sort(
+    (a, b) => criterionScore(b, criterion) - criterionScore(a, criterion) || a.rank - b.rank,
+  );
 }
 
 // --- Polling ---
@@ -481,6 +512,105 @@ function SceneGroupCard({ group }: { group: SelectionSceneGroup }) {
   );
 }
 
+function UnprocessedCard({ items }: { items: UnprocessedItem[] }) {
+  return (
+    <div
+      class="card"
+      style={{
+        marginBottom: "1.5rem",
+        borderLeft: "3px solid var(--color-warning)",
+      }}
+    >
+      <h2 style={{ color: "var(--color-warning)", marginBottom: "0.5rem" }}>
+        Not considered ({items.length})
+      </h2>
+      <p
+        style={{
+          fontSize: "0.75rem",
+          color: "var(--color-text-secondary)",
+          marginBottom: "0.5rem",
+        }}
+      >
+        The AI did not see these files, so they are neither selected nor
+        excluded. Re-upload them and run selection again to include them.
+      </p>
+      <ul style={{ fontSize: "0.875rem", margin: 0, paddingLeft: "1.25rem" }}>
+        {items.map((item) => (
+          <li key={item.key}>
+            {item.filename}
+            <span style={{ color: "var(--color-text-secondary)" }}>
+              {" "}
+              &mdash; {item.reason}
+            </span>
+          </li>
+        ))}
+      </ul>
+    </div>
+  );
+}
+
+/** What changed since the session's previous selection (DDR-158). */
+function ChangesCard({ diff }: { diff: SelectionDiff }) {
+  const added = diff.added || [];
+  const dropped = diff.dropped || [];
+  const moved = diff.moved || [];
+  if (added.length + dropped.length + moved.length === 0) {
+    return (
+      <div class="card" style={{ marginBottom: "1.5rem", fontSize: "0.875rem" }}>
+        Same selection as the previous run.
+      </div>
+    );
+  }
+  return (
+    <div
+      class="card"
+      style={{
+        marginBottom: "1.5rem",
+        borderLeft: "3px solid var(--color-primary)",
+      }}
+    >
+      <h2 style={{ color: "var(--color-primary)", marginBottom: "0.5rem" }}>
+        Changes since the previous run
+      </h2>
+      <p
+        style={{
+          fontSize: "0.75rem",
+          color: "var(--color-text-secondary)",
+          marginBottom: "0.5rem",
+        }}
+      >
+        {added.length} newly selected, {dropped.length} dropped, {moved.length}{" "}
+        moved, {diff.unchanged} unchanged.
+      </p>
+      <ul style={{ fontSize: "0.875rem", margin: 0, paddingLeft: "1.25rem" }}>
+        {added.map((item) => (
+          <li key={`added-${item.key}`}>
+            <strong style={{ color: "var(--color-success)" }}>+</strong>{" "}
+            {item.filename} (#{item.rank})
+          </li>
+        ))}
+        {dropped.map((item) => (
+          <li key={`dropped-${item.key}`}>
+            <strong style={{ color: "var(--color-danger)" }}>&minus;</strong>{" "}
+            {item.filename} (was #{item.previousRank})
+            {item.reason && (
+              <span style={{ color: "var(--color-text-secondary)" }}>
+                {" "}
+                &mdash; {item.reason}
+              </span>
+            )}
+          </li>
+        ))}
+        {moved.map((item) => (
+          <li key={`moved-${item.key}`}>
+            {item.filename} #{item.previousRank} &rarr; #{item.rank}
+          </li>
+        ))}
+      </ul>
+    </div>
+  );
+}
+
 // --- Main Component ---
 
 export function SelectionView() {
@@ -545,6 +675,8 @@ export function SelectionView() {
   const selected = getEffectiveSelected();
   const excluded = getEffectiveExcluded();
   const sceneGroups = results.value?.sceneGroups || [];
+  const unprocessed = results.value?.unprocessed || [];
+  const changes = results.value?.changes;
   const hasOverrides =
     addedToSelection.value.size > 0 || removedFromSelection.value.size > 0;
 
@@ -611,11 +743,43 @@ export function SelectionView() {
         </div>
       </div>
 
+      {/* What changed since the previous run (DDR-158) */}
+      {changes && <ChangesCard diff={changes} />}
+
+      {/* Files the AI never judged (DDR-134) */}
+      {unprocessed.length > 0 && <UnprocessedCard items={unprocessed} />}
+
       {/* Selected section */}
       <div class="card" style={{ marginBottom: "1.5rem" }}>
-        <h2 style={{ color: "var(--color-success)", marginBottom: "1rem" }}>
-          Selected ({selected.length})
-        </h2>
+        <div
+          style={{
+            display: "flex",
+            justifyContent: "space-between",
+            alignItems: "center",
+            marginBottom: "1rem",
+          }}
+        >
+          <h2 style={{ color: "var(--color-success)", margin: 0 }}>
+            Selected ({selected.length})
+          </h2>
+          <label style={{ fontSize: "0.75rem" }}>
+            Sort by:{" "}
+            <select
+              value={sortCriterion.value ?? "rank"}
+              onChange={(e) => {
+                const v = (e.target as HTMLSelectElement).value;
+                sortCriterion.value = v === "rank" ? null : (v as SelectionSortCriterion);
+              }}
+            >
+              <option value="rank">AI rank</option>
+              <option value="overall">Overall score</option>
+              <option value="composition">Composition</option>
+              <option value="storyValue">Story value</option>
+              <option value="uniqueness">Uniqueness</option>
+              <option value="technicalQuality">Technical quality</option>
+            </select>
+          </label>
+        </div>
         <div
           style={{
             display: "grid",
@@ -623,7 +787,7 @@ export function SelectionView() {
             gap: "0.75rem",
           }}
         >
-          {selected.map((item) => (
+          {sortSelected(selected).map((item) => (
             <SelectedCard
               key={item.key}
               item={item}
@@ -0,0 +1,111 @@
+import { signal } from "@preact/signals";
+import { useEffect } from "preact/hooks";
+import { getStatus } from "../api/client";
+import type { AppStatus } from "../types/api";
+
+// --- Local server status (DDR-125) ---
+
+/** Poll interval for the footer status bar. */
+const STATUS_POLL_MS = 30_000;
+
+/** Latest /api/status response; null until the first load. */
+export const appStatus = signal<AppStatus | null>(null);
+const statusError = signal(false);
+
+/** Refresh the status, optionally for the volume holding path. */
+export async function loadStatus(path?: string): Promise<AppStatus | null> {
+  try {
+    const status = await getStatus(path);
+    appStatus.value = status;
+    statusError.value = false;
+    return status;
+  } catch {
+    statusError.value = true;
+    return null;
+  }
+}
+
+/** Format a byte count as GB with one decimal. */
+export function formatGB(bytes: number): string {
+  return `${(bytes / 1024 ** 3).toFixed(1)} GB`;
+}
+
+/** Preflight warnings shown before a triage run. Renders nothing when clear. */
+export function PreflightWarnings({ status }: { status: AppStatus | null }) {
+  const warnings = status?.warnings ?? [];
+  const running = status?.jobs.length ?? 0;
+  if (warnings.length === 0 && running === 0) return null;
+
+  return (
+    <div
+      style={{
+        border: "1px solid var(--color-warning)",
+        borderRadius: "var(--radius)",
+        padding: "0.75rem",
+        marginBottom: "1rem",
+        fontSize: "0.875rem",
+      }}
+    >
+      {warnings.map((w) => (
+        <div key={w} style={{ color: "var(--color-warning)" }}>
+          ⚠ {w}
+        </div>
+      ))}
+      {running > 0 && (
+        <div style={{ color: "var(--color-text-secondary)" }}>
+          {running} triage job{running !== 1 ? "s are" : " is"} already
+          running; a new run will compete for the same Gemini quota.
+        </div>
+      )}
+    </div>
+  );
+}
+
+/** Footer status bar for the desktop (local mode) app. */
+export function StatusBar() {
+  useEffect(() => {
+    loadStatus(appStatus.value?.disk?.path);
+    const timer = setInterval(
+      () => loadStatus(appStatus.value?.disk?.path),
+      STATUS_POLL_MS,
+    );
+    return () => clearInterval(timer);
+  }, []);
+
+  const status = appStatus.value;
+  const healthy =
+    status !== null && !statusError.value && (status.warnings ?? []).length === 0;
+  const dot = statusError.value
+    ? "var(--color-danger)"
+    : healthy
+      ? "var(--color-success)"
+      : "var(--color-warning)";
+
+  return (
+    <>
+      <span>{status ? `${status.version} · ${status.model}` : "…"}</span>
+      <span style={{ display: "flex", gap: "1rem" }}>
+        {status && (
+          <>
+            <span>Gemini key {status.geminiKey.valid ? "OK" : "invalid"}</span>
+            <span>ffmpeg {status.ffmpeg && status.ffprobe ? "OK" : "missing"}</span>
+            {status.disk && <span>{formatGB(status.disk.freeBytes)} free</span>}
+            {status.jobs.length > 0 && (
+              <span>
+                {status.jobs.length} job{status.jobs.length !== 1 ? "s" : ""} running
+              </span>
+            )}
+          </>
+        )}
+        <span title={(status?.warnings ?? []).join("\n")}>
+          <span style={{ color: dot }}>●</span>{" "}
+          {statusError.value
+            ? "Server unreachable"
+            : healthy
+              ? "System Operational"
+              : "Check warnings"}
+        </span>
+      </span>
+    </>
+  );
+}
@@ -1,5 +1,11 @@
+import { useState } from "preact/hooks";
 import { openMediaPlayer } from "./MediaPlayer";
-import { isCloudMode, isVideoFile, thumbnailUrl } from "../api/client";
+import {
+  isCloudMode,
+  isVideoFile,
+  thumbnailUrl,
+  videoPreviewUrl,
+} from "../api/client";
 import type { TriageItem } from "../types/api";
 
 /** Get the identifier for a triage item (key in cloud mode, path in local). */
@@ -31,6 +37,16 @@ export function MediaCard({
   onToggle?: () => void;
   onReview?: () => void;
 }) {
+  // Videos show a strip of frames from across the clip on hover (DDR-124).
+  const [hovering, setHovering] = useState(false);
+  const [previewFailed, setPreviewFailed] = useState(false);
+  const showPreview =
+    hovering &&
+    !previewFailed &&
+    isCloudMode &&
+    !!item.key &&
+    isVideoFile(item.filename);
+
   return (
     <div
       onClick={selectable ? onToggle : undefined}
@@ -72,6 +88,8 @@ export function MediaCard({
           position: "relative",
           cursor: "zoom-in",
         }}
+        onMouseEnter={() => setHovering(true)}
+        onMouseLeave={() => setHovering(false)}
       >
         <img
           src={itemThumb(item)}
@@ -114,6 +132,21 @@ export function MediaCard({
             {isVideoFile(item.filename) ? item.filename : "No preview"}
           </span>
         </div>
+        {showPreview && (
+          <img
+            src={videoPreviewUrl(item.key!)}
+            alt={`${item.filename} preview`}
+            onError={() => setPreviewFailed(true)}
+            style={{
+              position: "absolute",
+              inset: 0,
+              width: "100%",
+              height: "100%",
+              objectFit: "contain",
+              background: "#000",
+            }}
+          />
+        )}
         {/* Video play icon overlay */}
         {isVideoFile(item.filename) && (
           <div
@@ -135,7 +168,7 @@ export function MediaCard({
             <svg width="14" height="14" viewBox="0 0 24 24" fill="currentColor">
               <polygon points="6,4 20,12 6,20" />
             </svg>
-            Video
+            {item.sampled ? "Video · sampled" : "Video"}
           </div>
         )}
         {selectable && (
@@ -9,13 +9,15 @@ import {
   getTriageResults,
   finalizeTriageUploads,
   confirmTriage,
+  exportTriage,
+  recordTriageOverrides,
   isCloudMode,
   isVideoFile,
   thumbnailUrl,
 } from "../api/client";
 import { MediaReviewModal } from "./MediaReviewModal";
 import { MediaCard, itemId } from "./TriageMediaCard";
-import type { TriageResults, TriageLogEntry } from "../types/api";
+import type { TriageResults, TriageLogEntry, TriageExportResponse } from "../types/api";
 
 const results = signal<TriageResults | null>(null);
 const selectedForDeletion = signal<Set<string>>(new Set());
@@ -37,6 +39,9 @@ const reviewModalIndex = signal<number | null>(null);
 const retryLoading = signal(false);
 const rawLogEntries = signal<TriageLogEntry[]>([]);
 const rawLogSince = signal<number>(0);
+/** DDR-160: Local export of kept files (hard link or move). */
+const exportLoading = signal(false);
+const exportResult = signal<TriageExportResponse | null>(null);
 
 function pollResults(id: string) {
   const sessionId = isCloudMode ? uploadSessionId.value ?? undefined : undefined;
@@ -121,11 +126,31 @@ function deselectAll() {
   selectedForDeletion.value = new Set();
 }
 
+/**
+ * DDR-100: Send the AI verdicts the user reversed to the learning feed.
+ * Best effort — a failure here must not block deletion.
+ */
+async function sendTriageOverrides(jobId: string, sessionId: string) {
+  if (!results.value) return;
+  const selected = selectedForDeletion.value;
+  const kept = (results.value.discard ?? []).map(itemId).filter((id) => !selected.has(id));
+  const discarded = (results.value.keep ?? []).map(itemId).filter((id) => selected.has(id));
+  if (kept.length === 0 && discarded.length === 0) return;
+  try {
+    await recordTriageOverrides(jobId, { sessionId, kept, discarded });
+  } catch (e) {
+    console.warn("Failed to record triage overrides", e);
+  }
+}
+
 async function handleConfirmDeletion() {
   if (!triageJobId.value) return;
   confirmLoading.value = true;
   localDeleteResult.value = null;
   try {
+    if (isCloudMode && uploadSessionId.value) {
+      await sendTriageOverrides(triageJobId.value, uploadSessionId.value);
+    }
     const ids = Array.from(selectedForDeletion.value);
     const req = isCloudMode
       ? { deleteKeys: ids, sessionId: uploadSessionId.value }
@@ -166,10 +191,31 @@ async function handleConfirmDeletion() {
   }
 }
 
+/** DDR-160: Hard-link or move the kept files into a folder picked natively. */
+async function handleExport(mode: "link" | "move") {
+  if (!triageJobId.value) return;
+  exportLoading.value = true;
+  exportResult.value = null;
+  try {
+    const res = await exportTriage(triageJobId.value, { mode });
+    if (!res.canceled) {
+      exportResult.value = res;
+    }
+    if (mode === "move" && res.exported) {
+      results.value = await getTriageResults(triageJobId.value);
+    }
+  } catch (e) {
+    error.value = e instanceof Error ? e.message : "Failed to export kept files";
+  } finally {
+    exportLoading.value = false;
+  }
+}
+
 function startOver() {
   results.value = null;
   selectedForDeletion.value = new Set();
   confirmResult.value = null;
+  exportResult.value = null;
   localDeleteResult.value = null;
   error.value = null;
   triageJobId.value = null;
@@ -188,6 +234,7 @@ function handleBack() {
   results.value = null;
   selectedForDeletion.value = new Set();
   confirmResult.value = null;
+  exportResult.value = null;
   localDeleteResult.value = null;
   error.value = null;
   triageJobId.value = null;
@@ -268,8 +315,15 @@ export function TriageView() {
       title = "Analyzing Media with AI";
       const batch = results.value?.triageBatch;
       const batchTotal = results.value?.triageBatchTotal;
-      if (batch && batchTotal) {
-        description = `Evaluating batch ${batch} of ${batchTotal} — waiting for Gemini AI response`;
+      const fileTotal = results.value?.fileProgress?.length ?? 0;
+      if (fileTotal > 0) {
+        const analyzed = results.value?.analyzedFiles ?? 0;
+        const inFlight = results.value?.fileProgress?.filter((f) => f.status === "downloaded").length ?? 0;
+        description = `${analyzed} of ${fileTotal} files evaluated` +
+          (inFlight > 0 ? ` — ${inFlight} with Gemini now` : "");
+        statusLabel = `analyzing (${analyzed}/${fileTotal})`;
+      } else if (batch && batchTotal) {
+        description = `${batch} of ${batchTotal} batches evaluated — waiting for Gemini AI responses`;
         statusLabel = `analyzing (batch ${batch}/${batchTotal})`;
       } else {
         description = "Sending query to Gemini and waiting for the AI to evaluate your media";
@@ -282,7 +336,13 @@ export function TriageView() {
       results.value?.totalFiles != null &&
       results.value.totalFiles > 0;
 
+    // DDR-174: per-file progress, when the job reports it, beats batch counts.
+    const showFileProgress =
+      phase === "analyzing" &&
+      (results.value?.fileProgress?.length ?? 0) > 0;
+
     const showBatchProgress =
+      !showFileProgress &&
       phase === "analyzing" &&
       results.value?.triageBatch != null &&
       results.value?.triageBatchTotal != null &&
@@ -299,11 +359,13 @@ export function TriageView() {
         fileCount={selectedPaths.value.length}
         completedCount={
           showUploadProgress ? (results.value?.uploadedFiles ?? 0) :
+          showFileProgress ? (results.value?.analyzedFiles ?? 0) :
           showBatchProgress ? results.value?.triageBatch :
           undefined
         }
         totalCount={
           showUploadProgress ? results.value?.totalFiles :
+          showFileProgress ? results.value?.fileProgress?.length :
           showBatchProgress ? results.value?.triageBatchTotal :
           undefined
         }
@@ -364,6 +426,8 @@ export function TriageView() {
   // Show results (guard against null arrays from Go nil-slice JSON encoding)
   const keep = results.value.keep ?? [];
   const discard = results.value.discard ?? [];
+  // DDR-175: verdicts of finished batches, shown while the rest are analyzed
+  const partial = results.value.status === "partial";
   const reasons = [...new Set(discard.map((i) => i.reason))];
   const filteredDiscard =
     reasonFilter.value === "all"
@@ -384,6 +448,14 @@ export function TriageView() {
         </div>
       )}
 
+      {partial && (
+        <div class="card" style={{ marginBottom: "1.5rem", fontSize: "0.875rem" }}>
+          <strong>Partial results</strong> — {results.value.analyzedFiles ?? keep.length + discard.length} of{" "}
+          {results.value.fileProgress?.length ?? results.value.totalFiles ?? "?"} files evaluated so far.
+          More verdicts appear as Gemini finishes each batch; deletion unlocks when analysis completes.
+        </div>
+      )}
+
       {/* Discard section */}
       <div class="card" style={{ marginBottom: "1.5rem" }}>
         <h2 style={{ display: "flex", alignItems: "center", gap: "0.5rem", marginBottom: "1rem" }}>
@@ -470,14 +542,51 @@ export function TriageView() {
               {keep.length}
             </span>
           </h2>
-          <button
-            class="outline"
-            onClick={() => { keepExpanded.value = !keepExpanded.value; }}
-            style={{ fontSize: "0.8rem" }}
-          >
-            {keepExpanded.value ? "Collapse" : "Expand"}
-          </button>
+          <div style={{ display: "flex", gap: "0.5rem" }}>
+            {!isCloudMode && !partial && keep.length > 0 && (
+              <>
+                <button
+                  class="outline"
+                  onClick={() => handleExport("link")}
+                  disabled={exportLoading.value}
+                  style={{ fontSize: "0.8rem" }}
+                  title="Hard-link the kept files into a folder without copying them"
+                >
+                  Export (link)
+                </button>
+                <button
+                  class="outline"
+                  onClick={() => handleExport("move")}
+                  disabled={exportLoading.value}
+                  style={{ fontSize: "0.8rem" }}
+                  title="Move the kept files into a folder"
+                >
+                  Export (move)
+                </button>
+              </>
+            )}
+            <button
+              class="outline"
+              onClick={() => { keepExpanded.value = !keepExpanded.value; }}
+              style={{ fontSize: "0.8rem" }}
+            >
+              {keepExpanded.value ? "Collapse" : "Expand"}
+            </button>
+          </div>
         </div>
+        {exportResult.value && (
+          <div style={{ fontSize: "0.875rem", marginTop: "0.5rem" }}>
+            Exported {exportResult.value.exported ?? 0} file(s) to {exportResult.value.destination}
+            {exportResult.value.manifest && (
+              <span style={{ color: "var(--color-text-secondary)" }}>
+                {" "}(manifest: {exportResult.value.manifest})
+              </span>
+            )}
+            {(exportResult.value.errors ?? []).map((msg) => (
+              <div key={msg} style={{ color: "var(--color-danger)" }}>{msg}</div>
+            ))}
+          </div>
+        )}
         {!keepExpanded.value ? (
           <p style={{ color: "var(--color-text-secondary)", fontSize: "0.875rem", marginTop: "0.5rem" }}>
             {keep.length} items will be kept
@@ -527,14 +636,14 @@ export function TriageView() {
           <button
             class="danger"
             onClick={handleConfirmDeletion}
-            disabled={selectedForDeletion.value.size === 0 || confirmLoading.value}
+            disabled={partial || selectedForDeletion.value.size === 0 || confirmLoading.value}
           >
             {confirmLoading.value ? "Deleting..." : `Delete ${selectedForDeletion.value.size} Selected`}
           </button>
           <button
             class="primary"
             onClick={handleConfirmDeletion}
-            disabled={selectedForDeletion.value.size === 0 || confirmLoading.value}
+            disabled={partial || selectedForDeletion.value.size === 0 || confirmLoading.value}
           >
             Confirm & Archive
           </button>
@@ -550,7 +659,7 @@ export function TriageView() {
             s3Key: isCloudMode ? (item.processedKey || item.key) : undefined,
             filename: item.filename,
             type: (isVideoFile(item.filename) ? "video" : "image") as "image" | "video",
-            reason: item.reason,
+            reason: item.sampled ? `${item.reason} (evaluated via sampling)` : item.reason,
           }))}
           initialIndex={reviewModalIndex.value}
           onClose={() => { reviewModalIndex.value = null; }}
@@ -48,7 +48,11 @@ export function BundleCard({ bundle }: { bundle: DownloadBundle }) {
               textTransform: "uppercase",
             }}
           >
-            {bundle.type === "images" ? "Photos" : "Videos"}
+            {bundle.type === "images"
+              ? "Photos"
+              : bundle.type === "raw"
+                ? "RAW"
+                : "Videos"}
           </span>
 
           <span
@@ -76,6 +80,35 @@ export function BundleCard({ bundle }: { bundle: DownloadBundle }) {
           )}
         </div>
 
+        {isComplete && bundle.sha256 && (
+          <div
+            style={{
+              fontSize: "0.6875rem",
+              color: "var(--color-text-secondary)",
+              fontFamily: "var(--font-mono)",
+              marginTop: "0.125rem",
+            }}
+            title={`SHA-256: ${bundle.sha256}`}
+          >
+            SHA-256 {bundle.sha256.slice(0, 16)}…
+          </div>
+        )}
+
+        {bundle.omittedFiles && bundle.omittedFiles.length > 0 && (
+          <div
+            style={{
+              fontSize: "0.75rem",
+              color: "var(--color-warning)",
+              marginTop: "0.25rem",
+            }}
+            title={bundle.omittedFiles.join("\n")}
+          >
+            {bundle.omittedFiles.length} file
+            {bundle.omittedFiles.length !== 1 ? "s" : ""} missing from this ZIP:{" "}
+            {bundle.omittedFiles.map((key) => key.split("/").pop()).join(", ")}
+          </div>
+        )}
+
         {isError && bundle.error && (
           <div
             style={{
@@ -11,6 +11,8 @@ export interface GroupDownloadState {
   status: "idle" | "processing" | "complete" | "error";
   bundles: DownloadBundle[];
   error: string | null;
+  /** Files still being restored from archive storage (DDR-115). */
+  restoring?: number;
 }
 
 interface GroupCardProps {
@@ -20,6 +22,8 @@ interface GroupCardProps {
   groupableMedia: GroupableMediaItem[];
   onToggleExpand: () => void;
   onDownload: () => void;
+  /** Retry the files a completed job left out of its ZIPs (DDR-097). */
+  onRetryOmitted: () => void;
 }
 
 export function GroupCard({
@@ -29,6 +33,7 @@ export function GroupCard({
   groupableMedia,
   onToggleExpand,
   onDownload,
+  onRetryOmitted,
 }: GroupCardProps) {
   const isIdle = state.status === "idle";
   const isProcessing = state.status === "processing";
@@ -54,6 +59,12 @@ export function GroupCard({
   ).length;
   const totalBundles = state.bundles.length;
 
+  // Files that could not be read when the ZIPs were built (DDR-097)
+  const omittedCount = state.bundles.reduce(
+    (n, b) => n + (b.omittedFiles?.length ?? 0),
+    0,
+  );
+
   return (
     <div
       class="card"
@@ -136,7 +147,9 @@ export function GroupCard({
                   fontWeight: 600,
                 }}
               >
-                Creating ZIPs... {completedBundles}/{totalBundles}
+                {state.restoring
+                  ? `Restoring ${state.restoring} from archive...`
+                  : `Creating ZIPs... ${completedBundles}/${totalBundles}`}
               </span>
             )}
           </div>
@@ -276,7 +289,7 @@ export function GroupCard({
             </div>
           )}
 
-          {isComplete && (
+          {isComplete && omittedCount === 0 && (
             <div
               style={{
                 marginTop: "0.75rem",
@@ -291,6 +304,39 @@ export function GroupCard({
             </div>
           )}
 
+          {isComplete && omittedCount > 0 && (
+            <div
+              style={{
+                marginTop: "0.75rem",
+                padding: "0.5rem 0.75rem",
+                background: "rgba(245, 158, 11, 0.08)",
+                borderRadius: "var(--radius)",
+                fontSize: "0.75rem",
+                color: "var(--color-warning)",
+                display: "flex",
+                alignItems: "center",
+                justifyContent: "space-between",
+                gap: "0.75rem",
+              }}
+            >
+              <span>
+                {omittedCount} file{omittedCount !== 1 ? "s" : ""} could not be
+                added to the ZIPs. Keep your originals until they are
+                downloaded.
+              </span>
+              <button
+                class="outline"
+                onClick={(e) => {
+                  e.stopPropagation();
+                  onRetryOmitted();
+                }}
+                style={{ fontSize: "0.75rem", flexShrink: 0 }}
+              >
+                Retry missing files
+              </button>
+            </div>
+          )}
+
           {isError && state.error && (
             <div
               style={{
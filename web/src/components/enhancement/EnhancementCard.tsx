@@ -17,6 +17,8 @@ export function getPhaseLabel(phase: string): string {
       return "Done";
     case "error":
       return "Error";
+    case "skipped":
+      return "Skipped";
     default:
       return phase;
   }
@@ -30,6 +32,7 @@ export function getPhaseColor(phase: string): string {
     case "error":
       return "var(--color-danger)";
     case "initial":
+    case "skipped":
       return "var(--color-text-secondary)";
     default:
       return "var(--color-primary)";
@@ -170,6 +173,18 @@ export function EnhancementCard({
             {item.error}
           </div>
         )}
+        {item.legibilityWarnings && item.legibilityWarnings.length > 0 && (
+          <div
+            title={item.legibilityWarnings.map((w) => w.message).join("\n")}
+            style={{
+              fontSize: "0.75rem",
+              color: "var(--color-warning)",
+              marginTop: "0.25rem",
+            }}
+          >
+            &#9888; Text may be hard to read on Instagram
+          </div>
+        )}
       </div>
     </div>
   );
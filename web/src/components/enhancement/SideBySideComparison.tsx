@@ -172,6 +172,22 @@ export function SideBySideComparison({
         </div>
       )}
 
+      {/* Legibility warnings (DDR-104) */}
+      {item.legibilityWarnings && item.legibilityWarnings.length > 0 && (
+        <div
+          style={{
+            marginBottom: "0.75rem",
+            fontSize: "0.75rem",
+            color: "var(--color-warning)",
+            lineHeight: 1.5,
+          }}
+        >
+          {item.legibilityWarnings.map((w) => (
+            <div key={w.code}>&#9888; {w.message}</div>
+          ))}
+        </div>
+      )}
+
       {/* Analysis details */}
       {item.analysis && !item.analysis.noFurtherEditsNeeded && (
         <div style={{ marginBottom: "0.75rem" }}>
@@ -0,0 +1,121 @@
+import { signal } from "@preact/signals";
+import { useEffect } from "preact/hooks";
+import { uploadSessionId } from "../../app";
+import { listTemplates, submitEnhancementFeedback } from "../../api/client";
+import { enhancementJobId } from "../EnhancementView";
+import { applyTemplate } from "./useGroupOperations";
+import type { PostGroup, PostTemplate } from "../../types/api";
+
+// --- Post group templates (DDR-122) ---
+
+/** The user's templates; null until loaded. */
+export const postTemplates = signal<PostTemplate[] | null>(null);
+
+/** Groups whose template enhancement feedback was already sent, by group ID. */
+const presetStatus = signal<Record<string, "sending" | "sent" | string>>({});
+
+/** Load the user's templates once per page load. Failures leave the picker hidden. */
+export async function loadPostTemplates(force = false) {
+  if (postTemplates.value !== null && !force) return;
+  try {
+    const { templates } = await listTemplates();
+    postTemplates.value = templates;
+  } catch {
+    postTemplates.value = [];
+  }
+}
+
+/**
+ * Send the template's enhancement feedback for every photo in the group,
+ * through the same per-photo feedback flow as the enhancement step.
+ */
+async function applyEnhancementPreset(group: PostGroup, feedback: string) {
+  const jobId = enhancementJobId.value;
+  const sessionId = uploadSessionId.value;
+  if (!jobId || !sessionId) return;
+
+  presetStatus.value = { ...presetStatus.value, [group.id]: "sending" };
+  try {
+    for (const key of group.keys) {
+      await submitEnhancementFeedback(jobId, { sessionId, key, feedback });
+    }
+    presetStatus.value = { ...presetStatus.value, [group.id]: "sent" };
+  } catch (err) {
+    presetStatus.value = {
+      ...presetStatus.value,
+      [group.id]: err instanceof Error ? err.message : "Failed to apply preset",
+    };
+  }
+}
+
+export function TemplatePicker({ group }: { group: PostGroup }) {
+  useEffect(() => {
+    loadPostTemplates();
+  }, []);
+
+  const templates = postTemplates.value;
+  if (!templates || templates.length === 0) return null;
+
+  const current = templates.find((t) => t.id === group.templateId) ?? null;
+  const status = presetStatus.value[group.id];
+
+  return (
+    <div
+      style={{
+        display: "flex",
+        alignItems: "center",
+        flexWrap: "wrap",
+        gap: "0.5rem",
+        marginBottom: "0.75rem",
+        fontSize: "0.75rem",
+        color: "var(--color-text-secondary)",
+      }}
+    >
+      <span>Template:</span>
+      <select
+        value={group.templateId ?? ""}
+        onChange={(e) => {
+          const id = (e.target as HTMLSelectElement).value;
+          applyTemplate(group.id, templates.find((t) => t.id === id) ?? null);
+        }}
+        style={{ fontSize: "0.75rem" }}
+      >
+        <option value="">None</option>
+        {templates.map((t) => (
+          <option key={t.id} value={t.id}>
+            {t.name}
+          </option>
+        ))}
+      </select>
+      {current?.hashtags && current.hashtags.length > 0 && (
+        <span>
+          {current.hashtags.map((h) => `#${h}`).join(" ")}
+        </span>
+      )}
+      {current?.enhancementFeedback && enhancementJobId.value && (
+        <>
+          {status === undefined && (
+            <button
+              class="outline"
+              style={{ fontSize: "0.75rem" }}
+              title={current.enhancementFeedback}
+              onClick={() => applyEnhancementPreset(group, current.enhancementFeedback!)}
+            >
+              Apply enhancement preset to {group.keys.length} photo
+              {group.keys.length !== 1 ? "s" : ""}
+            </button>
+          )}
+          {status === "sending" && <span>Sending enhancement preset...</span>}
+          {status === "sent" && (
+            <span style={{ color: "var(--color-success)" }}>
+              Enhancement preset queued
+            </span>
+          )}
+          {status !== undefined && status !== "sending" && status !== "sent" && (
+            <span style={{ color: "var(--color-danger)" }}>{status}</span>
+          )}
+        </>
+      )}
+    </div>
+  );
+}
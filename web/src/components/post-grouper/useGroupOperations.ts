@@ -3,6 +3,7 @@ import {
   selectedGroupId,
   getNextGroupId,
 } from "./state";
+import type { PostTemplate } from "../../types/api";
 
 /** Maximum items per Instagram carousel post. */
 export const MAX_ITEMS_PER_GROUP = 20;
@@ -33,6 +34,22 @@ export function updateGroupLabel(groupId: string, label: string) {
   );
 }
 
+/**
+ * Apply a post template to a group (DDR-122): fill the label if it is empty
+ * and remember the template so the description step follows its format.
+ */
+export function applyTemplate(groupId: string, template: PostTemplate | null) {
+  postGroups.value = postGroups.value.map((g) => {
+    if (g.id !== groupId) return g;
+    if (!template) return { ...g, templateId: undefined };
+    return {
+      ...g,
+      label: g.label.trim() ? g.label : template.groupLabel ?? "",
+      templateId: template.id,
+    };
+  });
+}
+
 export function addToGroup(groupId: string, key: string) {
   const group = postGroups.value.find((g) => g.id === groupId);
   if (!group) return;
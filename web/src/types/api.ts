@@ -29,12 +29,25 @@ export interface TriageItem {
   reason: string;
   /** Thumbnail URL: /api/media/thumbnail?path=... or ?key=... */
   thumbnailUrl: string;
+  /** Discarded by the local quality check without an AI call (DDR-112). */
+  preFiltered?: boolean;
+  /** Long video judged from sampled frames and an audio summary (DDR-162). */
+  sampled?: boolean;
+}
+
+/** One file's stage in a running triage job (DDR-174). */
+export interface TriageFileProgress {
+  filename: string;
+  status: "queued" | "downloaded" | "analyzed";
 }
 
 /** Response from GET /api/triage/:id/results. */
 export interface TriageResults {
   id: string;
-  status: "pending" | "processing" | "complete" | "error";
+  /** "partial" while keep and discard hold the verdicts known so far (DDR-175). */
+  status: "pending" | "processing" | "partial" | "complete" | "error";
+  /** Set while the status is "partial". */
+  partial?: boolean;
   /** Current sub-phase: uploading | gemini_processing | analyzing */
   phase?: "uploading" | "gemini_processing" | "analyzing";
   /** Total number of supported media files being processed. */
@@ -47,13 +60,62 @@ export interface TriageResults {
   expectedFileCount?: number;
   /** Files processed so far by MediaProcess Lambda (DDR-061). */
   processedCount?: number;
-  /** Current triage batch number (1-based, during analyzing phase). */
+  /** Triage batches finished so far (during analyzing phase; batches run in parallel, DDR-167). */
   triageBatch?: number;
   /** Total triage batches (during analyzing phase). */
   triageBatchTotal?: number;
+  /** Each file's stage while the job is analyzing (DDR-174). */
+  fileProgress?: TriageFileProgress[];
+  /** Files whose verdict is known so far. */
+  analyzedFiles?: number;
   keep: TriageItem[];
   discard: TriageItem[];
   error?: string;
+  /** Other sessions that probably hold the same trip (DDR-113). */
+  duplicateSessions?: DuplicateSession[];
+  /** Gemini, S3 and ffmpeg usage of the job so far (DDR-118). */
+  perJobTelemetry?: JobTelemetry;
+  /** Gemini model the job ran with. */
+  model?: string;
+  /** Thinking setting the job ran with; absent for the model's default (DDR-155). */
+  thinking?: string;
+  /** Sampling overrides the job ran with; absent for the model's defaults (DDR-170). */
+  temperature?: number;
+  topP?: number;
+  /** Models that answered, which differ from model when a fallback took over (DDR-169). */
+  modelsUsed?: string[];
+  /** Frames per video the job asked for; absent when videos were sent whole (DDR-186). */
+  keyframes?: number;
+  /** How the job's videos were judged, once complete (DDR-186). */
+  videoStats?: TriageVideoStats;
+}
+
+/** A triage job's videos by how Gemini saw them, with bytes uploaded and sent (DDR-186). */
+export interface TriageVideoStats {
+  videos: number;
+  keyframed?: number;
+  sampled?: number;
+  kept: number;
+  sourceBytes: number;
+  sheetBytes?: number;
+}
+
+/** Per-job usage counters written by the worker Lambdas (DDR-118). */
+export interface JobTelemetry {
+  geminiCalls: number;
+  geminiLatencyMs: number;
+  s3Downloads: number;
+  bytesDownloaded: number;
+  ffmpegSeconds: number;
+  /** Prompt tokens Gemini billed (DDR-186). */
+  geminiInputTokens?: number;
+}
+
+/** A probable duplicate session; merge it via POST /api/sessions/:id/merge. */
+export interface DuplicateSession {
+  sessionId: string;
+  createdAt: number;
+  reasons: string[];
 }
 
 /** Request body for POST /api/pick. */
@@ -67,6 +129,19 @@ export interface PickResponse {
   canceled: boolean;
 }
 
+/** Response from GET /api/status (local mode only, DDR-125). */
+export interface AppStatus {
+  version: string;
+  buildTime: string;
+  model: string;
+  geminiKey: { valid: boolean; checkedAt: string; error?: string };
+  ffmpeg: boolean;
+  ffprobe: boolean;
+  jobs: { id: string; status: string; files: number; createdAt: string }[];
+  disk: { path: string; totalBytes: number; freeBytes: number } | null;
+  warnings: string[] | null;
+}
+
 /** Request body for POST /api/triage/start. */
 export interface TriageStartRequest {
   /** Local filesystem paths (Phase 1). */
@@ -74,8 +149,23 @@ export interface TriageStartRequest {
   /** S3 session ID (Phase 2 — Lambda lists objects with this prefix). */
   sessionId?: string;
   model?: string;
+  /**
+   * Gemini thinking setting for this job: "minimal", "low", "medium",
+   * "high", "off", "dynamic" or a token budget such as "2048" (DDR-155).
+   */
+  thinking?: string;
+  /** Sampling temperature, 0 to 2; absent for the model's default (DDR-170). */
+  temperature?: number;
+  /** Nucleus sampling, above 0 and at most 1; absent for the model's default (DDR-170). */
+  topP?: number;
+  /** Ask Gemini again instead of reusing results cached for the same files (DDR-166). */
+  noCache?: boolean;
+  /** Judge every video from this many frames (2-16) instead of the clip (DDR-186). */
+  keyframes?: number;
   /** Economy mode: 50% cost savings, ~10 min processing. */
   economy_mode?: boolean;
+  /** Keep prompts, raw model responses, and intermediate media under the session's debug/ prefix (DDR-106). */
+  debugArtifacts?: boolean;
 }
 
 /** Response from POST /api/triage/start. */
@@ -94,8 +184,21 @@ export interface TriageInitRequest {
   sessionId: string;
   expectedFileCount: number;
   model?: string;
+  /**
+   * Gemini thinking setting for this job: "minimal", "low", "medium",
+   * "high", "off", "dynamic" or a token budget such as "2048" (DDR-155).
+   */
+  thinking?: string;
+  /** Sampling temperature, 0 to 2; absent for the model's default (DDR-170). */
+  temperature?: number;
+  /** Nucleus sampling, above 0 and at most 1; absent for the model's default (DDR-170). */
+  topP?: number;
+  /** Ask Gemini again instead of reusing results cached for the same files (DDR-166). */
+  noCache?: boolean;
   /** Economy mode: 50% cost savings, ~10 min processing. */
   economy_mode?: boolean;
+  /** Keep prompts, raw model responses, and intermediate media under the session's debug/ prefix (DDR-106). */
+  debugArtifacts?: boolean;
 }
 
 /** Request body for POST /api/triage/finalize (DDR-067). */
@@ -127,6 +230,8 @@ export interface FileProcessingStatus {
   converted: boolean;
   thumbnailUrl?: string;
   error?: string;
+  /** Video compression percent while "thumbnailed" (DDR-161). */
+  progress?: number;
 }
 
 /** Request body for POST /api/triage/:id/confirm. */
@@ -137,6 +242,20 @@ export interface TriageConfirmRequest {
   deleteKeys?: string[];
 }
 
+/** Request body for POST /api/triage/:id/override (DDR-100). */
+export interface TriageOverrideRequest {
+  sessionId: string;
+  /** Keys the AI discarded that the user kept. */
+  kept: string[];
+  /** Keys the AI kept that the user discarded. */
+  discarded: string[];
+}
+
+/** Response from POST /api/triage/:id/override. */
+export interface TriageOverrideResponse {
+  recorded: number;
+}
+
 /** Response from POST /api/triage/:id/confirm. */
 export interface TriageConfirmResponse {
   deleted: number;
@@ -145,6 +264,26 @@ export interface TriageConfirmResponse {
   reclaimedBytes: number;
 }
 
+/** Request body for POST /api/triage/:id/export (local mode only, DDR-160). */
+export interface TriageExportRequest {
+  /** "link" hard-links the files; "move" moves them. Neither copies bytes. */
+  mode: "link" | "move";
+  /** Kept files to export; defaults to all of them. */
+  paths?: string[];
+  /** Output folder; defaults to one chosen in the native picker. */
+  destination?: string;
+}
+
+/** Response from POST /api/triage/:id/export. */
+export interface TriageExportResponse {
+  canceled: boolean;
+  destination?: string;
+  exported?: number;
+  /** Path of the manifest written to the output folder. */
+  manifest?: string;
+  errors?: string[];
+}
+
 /** A single CloudWatch log entry from the triage Lambda. */
 export interface TriageLogEntry {
   timestamp: number;
@@ -161,6 +300,8 @@ export interface TriageLogsResponse {
 export interface UploadUrlResponse {
   uploadUrl: string;
   key: string;
+  /** Headers the PUT must send; set for encrypted sessions (DDR-164). */
+  headers?: Record<string, string>;
 }
 
 // --- Multipart Upload types (DDR-054) ---
@@ -213,6 +354,42 @@ export interface MultipartAbortRequest {
   uploadId: string;
 }
 
+// --- Upload advisor (DDR-128) ---
+
+/** Count and total size of one media type in the upload manifest. */
+export interface UploadTypeManifest {
+  count: number;
+  bytes: number;
+  /** Total video duration in seconds, when known. */
+  durationSeconds?: number;
+}
+
+/** Request body for POST /api/upload/advice. */
+export interface UploadAdviceRequest {
+  workflow?: "triage" | "fb-prep" | "selection";
+  images: UploadTypeManifest;
+  videos: UploadTypeManifest;
+  throughputBytesPerSec: number;
+}
+
+/** Per-type advice; maxDimension 0 means upload originals. */
+export interface UploadTypeAdvice {
+  downscale: boolean;
+  maxDimension: number;
+  originalBytes: number;
+  estimatedBytes: number;
+}
+
+/** Response from POST /api/upload/advice. */
+export interface UploadAdviceResponse {
+  recommendation: "originals" | "downscale";
+  reason: string;
+  originalSeconds: number;
+  recommendedSeconds: number;
+  images: UploadTypeAdvice;
+  videos: UploadTypeAdvice;
+}
+
 /** Response from GET /api/media/full when returning presigned URL (Phase 2). */
 export interface FullImageResponse {
   url: string;
@@ -225,8 +402,43 @@ export interface SelectionStartRequest {
   sessionId: string;
   tripContext: string;
   model?: string;
+  /**
+   * Gemini thinking setting for this job: "minimal", "low", "medium",
+   * "high", "off", "dynamic" or a token budget such as "2048" (DDR-155).
+   */
+  thinking?: string;
+  /** Sampling temperature, 0 to 2; absent for the model's default (DDR-170). */
+  temperature?: number;
+  /** Nucleus sampling, above 0 and at most 1; absent for the model's default (DDR-170). */
+  topP?: number;
   /** Economy mode: 50% cost savings, ~10 min processing. */
   economy_mode?: boolean;
+  /** Keep prompts, raw model responses, and intermediate media under the session's debug/ prefix (DDR-106). */
+  debugArtifacts?: boolean;
+}
+
+/**
+ * Request for POST /api/selection/{id}/rerun (DDR-176). Every key must be one
+ * of the job's media; tripContext and the model settings default to the job's.
+ */
+export interface SelectionRerunRequest {
+  sessionId: string;
+  /** Media that must be selected. */
+  lockedKeys?: string[];
+  /** Media that must not be selected. */
+  excludedKeys?: string[];
+  tripContext?: string;
+  model?: string;
+  thinking?: string;
+  temperature?: number;
+  topP?: number;
+  debugArtifacts?: boolean;
+}
+
+/** Response from POST /api/selection/{id}/rerun. */
+export interface SelectionRerunResponse {
+  id: string;
+  version: number;
 }
 
 /** Response from POST /api/selection/start. */
@@ -245,15 +457,43 @@ export interface SelectionItem {
   justification: string;
   comparisonNote?: string;
   thumbnailUrl: string;
+  /** Per-criterion breakdown, 1–10 each (DDR-150); absent on older jobs. */
+  scores?: SelectionScores;
+  /** Screen-reader description of a photo (DDR-154); absent on videos and older jobs. */
+  altText?: string;
+  /** Reels clips cut from a selected video, best first (DDR-187); fetch via /api/media/full. */
+  highlights?: VideoHighlight[];
 }
 
+/** A 15–30 second Reels clip of a selected video (DDR-187). */
+export interface VideoHighlight {
+  key: string;
+  /** Where the clip starts and ends in the original video. */
+  startMs: number;
+  endMs: number;
+  reason?: string;
+  size?: number;
+}
+
+/** How a selected item scored against each selection criterion (DDR-150). */
+export interface SelectionScores {
+  composition: number;
+  storyValue: number;
+  uniqueness: number;
+  technicalQuality: number;
+}
+
+/** A criterion to sort selected items by; "overall" sums the four. */
+export type SelectionSortCriterion = keyof SelectionScores | "overall";
+
 /** A media item excluded by the AI, with a reason. */
 export interface ExcludedItem {
   media: number;
   filename: string;
   key: string;
   reason: string;
-  category: "near-duplicate" | "quality-issue" | "content-mismatch" | "redundant-scene";
+  /** "user-excluded" marks media the user excluded from a re-run (DDR-176). */
+  category: "near-duplicate" | "quality-issue" | "content-mismatch" | "redundant-scene" | "user-excluded";
   duplicateOf?: string;
   thumbnailUrl: string;
 }
@@ -285,6 +525,72 @@ export interface SelectionResults {
   excluded: ExcludedItem[] | null;
   sceneGroups: SelectionSceneGroup[] | null;
   error?: string;
+  /** Gemini, S3 and ffmpeg usage of the job so far (DDR-118). */
+  perJobTelemetry?: JobTelemetry;
+  /** Files the AI never judged, with the reason (DDR-134). */
+  unprocessed?: UnprocessedItem[];
+  /** Gemini model the job ran with (DDR-155). */
+  model?: string;
+  /** Thinking setting the job ran with; absent for the model's default (DDR-155). */
+  thinking?: string;
+  /** Sampling overrides the job ran with; absent for the model's defaults (DDR-170). */
+  temperature?: number;
+  topP?: number;
+  /** Models that answered, which differ from model when a fallback took over (DDR-169). */
+  modelsUsed?: string[];
+  /** What changed since the session's previous selection (DDR-158). */
+  changes?: SelectionDiff;
+  /** Revision of the selection, from 1 for an original job (DDR-176). */
+  version?: number;
+  /** The job a re-run revises, and the user's constraints on it. */
+  rerunOf?: string;
+  lockedKeys?: string[];
+  excludedKeys?: string[];
+  /** Feedback rounds that revised the selection, oldest first (DDR-177). */
+  feedbackHistory?: SelectionFeedbackEntry[];
+}
+
+/** One round of free-text feedback on a selection (DDR-177). */
+export interface SelectionFeedbackEntry {
+  userFeedback: string;
+  /** Unix seconds. */
+  createdAt: number;
+}
+
+/** Request body for POST /api/selection/{id}/feedback (DDR-177). */
+export interface SelectionFeedbackRequest {
+  sessionId: string;
+  feedback: string;
+}
+
+/** Response from POST /api/selection/{id}/feedback. */
+export interface SelectionFeedbackResponse {
+  status: string;
+}
+
+/** Comparison with the session's previous completed selection (DDR-158). */
+export interface SelectionDiff {
+  previousJobId: string;
+  added?: SelectionChange[];
+  dropped?: SelectionChange[];
+  moved?: SelectionChange[];
+  unchanged: number;
+}
+
+/** One item of a SelectionDiff; rank is absent when dropped, previousRank when added. */
+export interface SelectionChange {
+  key: string;
+  filename: string;
+  rank?: number;
+  previousRank?: number;
+  reason?: string;
+}
+
+/** A file left out of selection (DDR-134). */
+export interface UnprocessedItem {
+  filename: string;
+  key: string;
+  reason: string;
 }
 
 // --- Enhancement types (DDR-031) ---
@@ -292,9 +598,46 @@ export interface SelectionResults {
 /** Request body for POST /api/enhance/start. */
 export interface EnhancementStartRequest {
   sessionId: string;
-  keys: string[];
+  /** Items to enhance with default settings. Send either keys or items. */
+  keys?: string[];
+  /** Per-item settings (DDR-143). */
+  items?: EnhancementItemOptions[];
   /** Economy mode: 50% cost savings, ~10 min processing. */
   economy_mode?: boolean;
+  /** Keep prompts, raw model responses, and intermediate media under the session's debug/ prefix (DDR-106). */
+  debugArtifacts?: boolean;
+  /** Tunes the pipeline for the job's photos (DDR-180). */
+  subjectPreset?: EnhancementSubjectPreset;
+  /** Upscale results of photos whose long edge is under this many pixels (512–4096) with Imagen; 0 is off (DDR-184). */
+  upscaleBelow?: number;
+  /** Gemini model for the photo analysis calls; must be on the server's allowlist (DDR-170). */
+  model?: string;
+  /** Gemini thinking setting, as for triage (DDR-155). */
+  thinking?: string;
+  /** Sampling temperature, 0 to 2; absent for the model's default (DDR-170). */
+  temperature?: number;
+  /** Nucleus sampling, above 0 and at most 1; absent for the model's default (DDR-170). */
+  topP?: number;
+}
+
+/**
+ * How much of the enhancement pipeline runs on a photo (DDR-143). "privacy"
+ * runs the full pipeline, then blurs bystanders' faces and plates (DDR-185).
+ */
+export type EnhancementPreset = "full" | "global" | "light" | "privacy";
+
+/** Kind of photos an enhancement job holds; absent for the general pipeline (DDR-180). */
+export type EnhancementSubjectPreset = "portrait" | "landscape" | "food" | "night";
+
+/** One item's settings when starting an enhancement (DDR-143). */
+export interface EnhancementItemOptions {
+  key: string;
+  /** Leave the item unchanged, e.g. because it was already edited by hand. */
+  skip?: boolean;
+  /** Photos only; defaults to "full". */
+  preset?: EnhancementPreset;
+  /** Higher renders first; ties keep request order. */
+  priority?: number;
 }
 
 /** Response from POST /api/enhance/start. */
@@ -333,7 +676,7 @@ export interface FeedbackEntry {
 export interface EnhancementItem {
   key: string;
   filename: string;
-  phase: "initial" | "phase1" | "phase2" | "phase3" | "feedback" | "complete" | "error";
+  phase: "initial" | "phase1" | "phase2" | "phase3" | "feedback" | "complete" | "error" | "skipped";
   originalKey: string;
   enhancedKey: string;
   originalThumbKey: string;
@@ -343,16 +686,128 @@ export interface EnhancementItem {
   imagenEdits: number;
   feedbackHistory: FeedbackEntry[];
   error?: string;
+  /** Text likely to become unreadable on Instagram (DDR-104). */
+  legibilityWarnings?: LegibilityWarning[];
+  /** Preset the item was enhanced with (DDR-143). */
+  preset?: EnhancementPreset;
+  /** Every result the item has had, oldest first (DDR-181). */
+  versions?: EnhancementVersion[];
+  /** The version the enhanced keys point at. */
+  currentVersion?: number;
+  /** Set when the result was upscaled (DDR-184). */
+  upscale?: UpscaleInfo;
+  /** Bystanders the privacy preset hid in the current version (DDR-185). */
+  redactions?: RedactRegion[];
+  /** Why the privacy preset failed: bystanders could not be detected. */
+  redactionError?: string;
+}
+
+/** A photo's super-resolution step: original size, Imagen factor, saved size (DDR-184). */
+export interface UpscaleInfo {
+  factor: 2 | 4;
+  beforeWidth: number;
+  beforeHeight: number;
+  afterWidth: number;
+  afterHeight: number;
+}
+
+/** One result of enhancing a photo; feedback rounds add versions (DDR-181). */
+export interface EnhancementVersion {
+  version: number;
+  enhancedKey: string;
+  enhancedThumbKey?: string;
+  cleanKey?: string;
+  /** The feedback that produced it; absent for the pipeline's result. */
+  feedback?: string;
+  legibilityWarnings?: LegibilityWarning[];
+  createdAt?: number;
+  /** Presigned full-image URL (versions endpoint only). */
+  previewUrl?: string;
+  /** Summary of the local adjustments that produced it (DDR-183). */
+  adjustments?: string;
+  /** Bystanders hidden in this version (DDR-185). */
+  redactions?: RedactRegion[];
+}
+
+/** Response from GET /api/enhance/{id}/versions. */
+export interface EnhancementVersionsResponse {
+  key: string;
+  currentVersion: number;
+  versions: EnhancementVersion[];
+}
+
+/** Request body for POST /api/enhance/{id}/rollback. */
+export interface EnhancementRollbackRequest {
+  sessionId: string;
+  key: string;
+  version: number;
+}
+
+/**
+ * Deterministic edits applied without Gemini or Imagen (DDR-183), in the
+ * order rotate, crop, tone. Tone values range from -1 to 1.
+ */
+export interface Adjustments {
+  brightness?: number;
+  contrast?: number;
+  saturation?: number;
+  /** Clockwise degrees. */
+  rotate?: 0 | 90 | 180 | 270;
+  /** Rectangle in displayed pixels of the rotated photo. */
+  crop?: { x: number; y: number; w: number; h: number };
+}
+
+/** Request body for POST /api/media/adjust. */
+export interface MediaAdjustRequest {
+  sessionId: string;
+  jobId: string;
+  key: string;
+  adjustments: Adjustments;
+}
+
+/** Response from POST /api/media/adjust. */
+export interface MediaAdjustResponse {
+  status: "processing";
+  key: string;
+  /** The version the adjusted photo will be saved as. */
+  version: number;
+}
+
+/** A legibility problem found in an enhanced photo's text (DDR-104). */
+export interface LegibilityWarning {
+  code: "low-contrast" | "small-text";
+  message: string;
 }
 
 /** Response from GET /api/enhance/{id}/results. */
 export interface EnhancementResults {
   id: string;
-  status: "pending" | "processing" | "complete" | "error";
+  status: "pending" | "processing" | "paused" | "complete" | "error";
   items: EnhancementItem[] | null;
   totalCount: number;
   completedCount: number;
   error?: string;
+  /** Unix seconds when the job was paused (DDR-095). */
+  pausedAt?: number;
+  /** Model settings the job ran with, when overridden (DDR-170). */
+  model?: string;
+  thinking?: string;
+  temperature?: number;
+  topP?: number;
+  /** Subject preset the job ran with (DDR-180). */
+  subjectPreset?: EnhancementSubjectPreset;
+  /** Upscale threshold the job ran with (DDR-184). */
+  upscaleBelow?: number;
+  /** Gemini, S3 and ffmpeg usage of the job so far (DDR-118). */
+  perJobTelemetry?: JobTelemetry;
+}
+
+/** Response from POST /api/enhance/{id}/pause and /resume (DDR-095). */
+export interface EnhancementControlResponse {
+  id: string;
+  status: "paused" | "processing";
+  /** Items the resumed run will process (resume only). */
+  pendingCount?: number;
 }
 
 /** Request body for POST /api/enhance/{id}/feedback. */
@@ -362,6 +817,34 @@ export interface EnhancementFeedbackRequest {
   feedback: string;
 }
 
+/** Request body for POST /api/enhance/{id}/feedback-batch (DDR-182). */
+export interface FeedbackBatchRequest {
+  sessionId: string;
+  keys: string[];
+  feedback: string;
+}
+
+/** One feedback instruction applied to several photos (DDR-182). */
+export interface FeedbackBatch {
+  id: string;
+  jobId: string;
+  feedback: string;
+  /** "error" only when every photo failed. */
+  status: "processing" | "complete" | "error";
+  items: FeedbackBatchItem[];
+  completedCount: number;
+  createdAt: number;
+}
+
+/** One photo's round in a feedback batch. */
+export interface FeedbackBatchItem {
+  key: string;
+  status: "processing" | "complete" | "error";
+  /** Enhancement version the round produced (DDR-181). */
+  version?: number;
+  error?: string;
+}
+
 /** Response from POST /api/enhance/{id}/feedback. */
 export interface EnhancementFeedbackResponse {
   status: string;
@@ -370,23 +853,30 @@ export interface EnhancementFeedbackResponse {
 // --- Download types (DDR-034) ---
 
 /** Request body for POST /api/download/start. */
+/** What embedded metadata survives in downloads and Instagram uploads (DDR-135). */
+export type MetadataPolicy = "strip-gps" | "strip-all" | "preserve";
+
 export interface DownloadStartRequest {
   sessionId: string;
   keys: string[];
   groupLabel: string;
   /** Economy mode: 50% cost savings, ~10 min processing. */
   economy_mode?: boolean;
+  /** Started ahead of the download screen when groups are confirmed (DDR-101). */
+  prewarm?: boolean;
 }
 
 /** Response from POST /api/download/start. */
 export interface DownloadStartResponse {
   id: string;
+  /** True when an existing job for the same keys and label was returned (DDR-101). */
+  reused?: boolean;
 }
 
 /** A single ZIP bundle in a download job. */
 export interface DownloadBundle {
-  /** Bundle type: "images" or "videos". */
-  type: "images" | "videos";
+  /** Bundle type: "images", "raw" (RAW originals, DDR-119) or "videos". */
+  type: "images" | "raw" | "videos";
   /** Display filename: e.g., "Tokyo Day 1-images.zip". */
   name: string;
   /** S3 key of the created ZIP (populated on completion). */
@@ -399,18 +889,39 @@ export interface DownloadBundle {
   totalSize: number;
   /** Size of the ZIP file in bytes (populated on completion). */
   zipSize: number;
+  /** Hex SHA-256 of the ZIP, for verifying a resumed download (DDR-099). */
+  sha256?: string;
+  /** S3 ETag of the ZIP; the If-Range validator for resumed downloads (DDR-099). */
+  etag?: string;
   /** Bundle creation status. */
   status: "pending" | "processing" | "complete" | "error";
   /** Error message if status is "error". */
   error?: string;
+  /** S3 keys that could not be read and are missing from the ZIP (DDR-097). */
+  omittedFiles?: string[];
 }
 
 /** Response from GET /api/download/{id}/results. */
 export interface DownloadResults {
   id: string;
-  status: "pending" | "processing" | "complete" | "error";
+  status: "pending" | "processing" | "restoring" | "complete" | "error";
   bundles: DownloadBundle[] | null;
   error?: string;
+  /** Source job ID when this job retries another job's omitted files (DDR-097). */
+  retryOf?: string;
+  /** True when the job was prewarmed at group confirmation (DDR-101). */
+  prewarm?: boolean;
+  /** Archived files still being restored while status is "restoring" (DDR-115). */
+  restoringKeys?: string[];
+  /** Gemini, S3 and ffmpeg usage of the job so far (DDR-118). */
+  perJobTelemetry?: JobTelemetry;
+}
+
+/** Response from POST /api/download/{id}/retry-omitted (DDR-097). */
+export interface DownloadRetryOmittedResponse {
+  id: string;
+  retryOf: string;
+  keyCount: number;
 }
 
 // --- Description types (DDR-036) ---
@@ -423,6 +934,20 @@ export interface DescriptionGenerateRequest {
   tripContext: string;
   /** Economy mode: 50% cost savings, ~10 min processing. */
   economy_mode?: boolean;
+  /** Post template whose caption format to follow (DDR-122). */
+  templateId?: string;
+  /** Saved post group to caption; fills keys and groupLabel when they are empty (DDR-178). */
+  groupId?: string;
+  /** Ask Gemini again instead of reusing results cached for the same files (DDR-166). */
+  noCache?: boolean;
+  /** Gemini model for the caption calls; must be on the server's allowlist (DDR-170). */
+  model?: string;
+  /** Gemini thinking setting, as for triage (DDR-155). */
+  thinking?: string;
+  /** Sampling temperature, 0 to 2; absent for the model's default (DDR-170). */
+  temperature?: number;
+  /** Nucleus sampling, above 0 and at most 1; absent for the model's default (DDR-170). */
+  topP?: number;
 }
 
 /** Response from POST /api/description/generate. */
@@ -437,8 +962,19 @@ export interface DescriptionResults {
   caption?: string;
   hashtags?: string[];
   locationTag?: string;
+  /** Screen-reader description of each item, aligned with the job's keys (DDR-151). */
+  altText?: string[];
+  /** Model settings the job ran with, when overridden (DDR-170). */
+  model?: string;
+  thinking?: string;
+  temperature?: number;
+  topP?: number;
+  /** Gemini models that answered the latest round (DDR-169). */
+  modelsUsed?: string[];
   feedbackRound: number;
   error?: string;
+  /** Gemini, S3 and ffmpeg usage of the job so far (DDR-118). */
+  perJobTelemetry?: JobTelemetry;
 }
 
 /** Request body for POST /api/description/{id}/feedback. */
@@ -452,6 +988,41 @@ export interface DescriptionFeedbackResponse {
   status: string;
 }
 
+// --- Mood variant types (DDR-102) ---
+
+/** Request body for POST /api/mood-variants/start. */
+export interface MoodVariantStartRequest {
+  sessionId: string;
+  key: string;
+  styles: string[];
+}
+
+/** Response from POST /api/mood-variants/start. */
+export interface MoodVariantStartResponse {
+  id: string;
+  remaining: number;
+}
+
+/** A single AI-generated stylized rendition of a cover image. */
+export interface MoodVariant {
+  style: string;
+  key?: string;
+  thumbnailUrl?: string;
+  aiGenerated: boolean;
+  error?: string;
+}
+
+/** Response from GET /api/mood-variants/{id}/results. */
+export interface MoodVariantResults {
+  id: string;
+  status: "pending" | "processing" | "complete" | "error";
+  sourceKey: string;
+  variants: MoodVariant[];
+  error?: string;
+  /** Gemini, S3 and ffmpeg usage of the job so far (DDR-118). */
+  perJobTelemetry?: JobTelemetry;
+}
+
 // --- FB Prep types ---
 
 /** Request body for POST /api/fb-prep/start. */
@@ -481,6 +1052,8 @@ export interface FBPrepJob {
   totalCount?: number;
   completedCount?: number;
   stage?: number;
+  /** Gemini, S3 and ffmpeg usage of the job so far (DDR-118). */
+  perJobTelemetry?: JobTelemetry;
 }
 
 /** A single media item's Facebook prep output. */
@@ -516,11 +1089,138 @@ export interface PublishStartRequest {
   hashtags: string[];
   /** Economy mode: 50% cost savings, ~10 min processing. */
   economy_mode?: boolean;
+  /** Hold the post until a second person approves it (DDR-121). */
+  requireApproval?: boolean;
+  /** Tag the post with a location from searchInstagramLocations (DDR-138). */
+  locationId?: string;
+  /** Simulate Instagram instead of posting (DDR-139). */
+  dryRun?: boolean;
+  /** Accounts tagged on each item, in the order of keys (DDR-141). */
+  userTags?: UserTag[][];
+  /** Usernames invited to co-author the post, at most 3 (DDR-141). */
+  collaborators?: string[];
+  /**
+   * Schedule the post for this RFC 3339 time instead of publishing now
+   * (DDR-144). Must be before the session's media expire.
+   */
+  publishAt?: string;
+  /**
+   * Where to post (DDR-147); defaults to "instagram". Facebook Page posts
+   * take no userTags or collaborators, and albums hold photos only.
+   * Mastodon posts (DDR-151) take no locationId either, and hold up to 4
+   * photos or one video.
+   */
+  platform?: PublishPlatform;
+  /**
+   * End the caption with the brand kit sign-off, and use its hashtags when
+   * hashtags is empty (DDR-149).
+   */
+  brandKit?: boolean;
+  /** Screen-reader description of each item, in the order of keys (DDR-151), sent to every platform (DDR-154). */
+  altText?: string[];
+  /** Take the alt-text this description job generated when altText is empty. */
+  descriptionJobId?: string;
+  /** Fill photos still without alt-text from this selection job's picks (DDR-154). */
+  selectionJobId?: string;
+  /**
+   * Cross-post to several platforms in one job, in place of platform
+   * (DDR-153). Each setting goes to the platforms that support it.
+   */
+  platforms?: PublishPlatform[];
+  /** Caption variants by platform; platforms without one use caption. */
+  captions?: Partial<Record<PublishPlatform, string>>;
+  /**
+   * Leave the hashtags out of the Instagram caption and post them as the
+   * first comment right after publishing (DDR-163).
+   */
+  hashtagsInComment?: boolean;
+  /** Post in the AI-suggested carousel order of the stored group groupId (DDR-179). */
+  useSuggestedOrder?: boolean;
+}
+
+/** A platform the publish pipeline posts to (DDR-147, DDR-151). */
+export type PublishPlatform = "instagram" | "facebook" | "mastodon";
+
+/** An account tagged on a photo; x and y are fractions from the top-left (DDR-141). */
+export interface UserTag {
+  username: string;
+  x: number;
+  y: number;
+}
+
+/** A taggable place from GET /api/instagram/locations (DDR-138). */
+export interface InstagramLocation {
+  id: string;
+  name: string;
+  street?: string;
+  city?: string;
+  country?: string;
+  latitude?: number;
+  longitude?: number;
 }
 
 /** Response from POST /api/publish/start. */
 export interface PublishStartResponse {
   id: string;
+  /** Token for the approval link; only returned when requireApproval was set. */
+  approvalToken?: string;
+  /** Unix seconds; only returned when publishAt was set (DDR-144). */
+  publishAt?: number;
+}
+
+/** A publish job waiting for its publish time (DDR-144). */
+export interface ScheduledPost {
+  id: string;
+  sessionId: string;
+  groupId: string;
+  /** Unix seconds. */
+  publishAt: number;
+  status: "scheduled" | "dispatched" | "cancelled" | "error";
+  caption: string;
+  keyCount: number;
+  dryRun?: boolean;
+  attempts?: number;
+  createdAt: number;
+  dispatchedAt?: number;
+  error?: string;
+}
+
+/** One reading of a published post's lifetime insights (DDR-146). */
+export interface InsightsSample {
+  /** Unix seconds. */
+  at: number;
+  reach: number;
+  impressions: number;
+  likes: number;
+  comments: number;
+  saves: number;
+}
+
+/** Response from GET /api/posts/{id}/insights (DDR-146). */
+export interface PostInsights {
+  post: {
+    mediaId: string;
+    sessionId: string;
+    jobId: string;
+    publishedAt: number;
+    lastPolledAt?: number;
+    lastError?: string;
+  };
+  /** Oldest first: hourly for two days, then daily for 30 days. */
+  samples: InsightsSample[];
+  latest?: InsightsSample;
+}
+
+/** Sign-off state of a publish job held for approval (DDR-121). */
+export interface PublishApproval {
+  status: "pending" | "approved" | "rejected";
+  requestedBy?: string;
+  requestedAt: number;
+  caption: string;
+  keys: string[];
+  decidedBy?: string;
+  decidedAt?: number;
+  via?: string;
 }
 
 /** Progress info for a publish job. */
@@ -529,13 +1229,117 @@ export interface PublishProgress {
   total: number;
 }
 
+/** An item that fails Instagram's media requirements (DDR-129). */
+export interface PublishItemError {
+  /** 0-based position in the post. */
+  index: number;
+  key: string;
+  error: string;
+}
+
 /** Response from GET /api/publish/{id}/status. */
 export interface PublishStatus {
   id: string;
-  status: "pending" | "creating_containers" | "processing_videos" | "creating_carousel" | "publishing" | "published" | "error";
+  status: "pending" | "preparing_media" | "creating_containers" | "processing_videos" | "awaiting_approval" | "creating_carousel" | "publishing" | "published" | "partially_published" | "rejected" | "error";
   phase: string;
   progress: PublishProgress;
   instagramPostId?: string;
+  /** Set instead of instagramPostId for Facebook Page posts (DDR-147). */
+  facebookPostId?: string;
+  /** Set instead of instagramPostId for Mastodon posts (DDR-151). */
+  mastodonPostId?: string;
+  error?: string;
+  itemErrors?: PublishItemError[];
+  approval?: PublishApproval;
+  /** Each platform's outcome (DDR-153). */
+  platforms?: PlatformPublishStatus[];
+  /** The hashtag comment, when hashtagsInComment was set (DDR-163). */
+  firstCommentId?: string;
+  /** Why the hashtag comment failed; the post itself stays up. */
+  firstCommentError?: string;
+  /** Gemini, S3 and ffmpeg usage of the job so far (DDR-118). */
+  perJobTelemetry?: JobTelemetry;
+}
+
+/** One platform's outcome in a publish job (DDR-153). */
+export interface PlatformPublishStatus {
+  platform: PublishPlatform;
+  status: "pending" | "published" | "error";
+  postId?: string;
+  error?: string;
+}
+
+// --- Crop suggestion types (DDR-130) ---
+
+/** Aspect ratios offered as crop suggestions. */
+export type CropAspect = "1:1" | "4:5" | "9:16";
+
+/** A rectangle in a photo's displayed pixels. */
+export interface CropRect {
+  /** Set on suggested crops. */
+  aspect?: CropAspect;
+  /** Set on a detected subject. */
+  label?: string;
+  x: number;
+  y: number;
+  w: number;
+  h: number;
+}
+
+/** Proposed crops for one photo. */
+export interface CropSuggestion {
+  key: string;
+  width?: number;
+  height?: number;
+  /** Absent when no clear subject was found and crops are centred. */
+  subject?: CropRect;
+  crops?: CropRect[];
+  error?: string;
+}
+
+/** Response from GET /api/crop/{id}/results. */
+export interface CropResults {
+  id: string;
+  status: "pending" | "processing" | "complete" | "error";
+  items: CropSuggestion[];
+  error?: string;
+}
+
+/** Response from POST /api/media/crop. */
+export interface CroppedMedia {
+  key: string;
+  width: number;
+  height: number;
+  thumbnailUrl?: string;
+}
+
+// --- Privacy redaction types (DDR-185) ---
+
+/** How bystanders are hidden: blurred in place, or removed with Imagen. */
+export type RedactMode = "blur" | "inpaint";
+
+/** A bystander's face or a licence plate that was hidden, in displayed pixels. */
+export interface RedactRegion {
+  kind: "face" | "plate";
+  label?: string;
+  x: number;
+  y: number;
+  w: number;
+  h: number;
+}
+
+/** Response from GET /api/media/redact/{id}/results. */
+export interface RedactResults {
+  id: string;
+  status: "pending" | "processing" | "complete" | "error";
+  key: string;
+  mode: RedactMode;
+  /** The mode that ran; "blur" when inpainting fell back. */
+  applied?: RedactMode;
+  /** Absent when nothing needed hiding. */
+  redactedKey?: string;
+  redactedThumbKey?: string;
+  regions: RedactRegion[];
   error?: string;
 }
 
@@ -549,6 +1353,53 @@ export interface PostGroup {
   label: string;
   /** S3 keys of enhanced media items in this group. */
   keys: string[];
+  /** Post template applied to this group; guides its caption (DDR-122). */
+  templateId?: string;
+}
+
+/** A saved post group format, reusable across sessions (DDR-122). */
+export interface PostTemplate {
+  id: string;
+  name: string;
+  groupLabel?: string;
+  /** Example or outline caption the description step follows. */
+  captionSkeleton?: string;
+  /** Hashtags (without '#') every caption from this template includes. */
+  hashtags?: string[];
+  /** Feedback applied to each photo of the group, e.g. "warm tones". */
+  enhancementFeedback?: string;
+  createdAt: number;
+  lastUsedAt?: number;
+}
+
+/** A post group saved on the server, from /api/groups (DDR-178). */
+export interface SavedPostGroup {
+  id: string;
+  name?: string;
+  /** Media keys in carousel order; at most 20. */
+  mediaKeys?: string[];
+  /** Order among the session's groups, from 0. */
+  position: number;
+  createdAt?: number;
+  /** Latest description job started from the group. */
+  descriptionJobId?: string;
+  /** Latest publish job started from the group. */
+  publishJobId?: string;
+  /** Latest AI-suggested carousel order (DDR-179). */
+  orderSuggestion?: CarouselOrderSuggestion;
+}
+
+/** Gemini's suggested order for a post group's items (DDR-179). */
+export interface CarouselOrderSuggestion {
+  status: "processing" | "complete" | "error";
+  /** The group's keys in the suggested order, cover first. */
+  keys?: string[];
+  /** Why the first item should open the carousel. */
+  openingReason?: string;
+  /** How the remaining items are ordered. */
+  rationale?: string;
+  error?: string;
+  createdAt?: number;
 }
 
 /** A media item available for grouping — carries display info from enhancement results. */
@@ -562,3 +1413,26 @@ export interface GroupableMediaItem {
   /** Media type. */
   type: "Photo" | "Video";
 }
+
+/** Type of a job served by GET /api/jobs/{id} (DDR-136). */
+export type JobType =
+  | "triage"
+  | "selection"
+  | "enhancement"
+  | "download"
+  | "publish"
+  | "description";
+
+/**
+ * Any job's status in one shape (DDR-136). `payload` is the job record,
+ * e.g. a SelectionResults for a "selection" job.
+ */
+export interface JobEnvelope<T = unknown> {
+  id: string;
+  type: JobType;
+  status: string;
+  /** Finished units of work; absent for single-step jobs. */
+  progress?: { completed: number; total: number };
+  error?: string;
+  payload: T;
+}
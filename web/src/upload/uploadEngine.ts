@@ -158,7 +158,7 @@ export function createUploadEngine(config: UploadEngineConfig = {}): UploadEngin
             progress: Math.round((loaded / total) * 100),
             loaded,
           });
-        });
+        }, res.headers);
       }
 
       updateFile(filename, {